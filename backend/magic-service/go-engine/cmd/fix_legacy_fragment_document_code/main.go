@@ -21,7 +21,6 @@ import (
 	knowledgebasedomain "magic/internal/domain/knowledge/knowledgebase/service"
 	"magic/internal/infrastructure/logging"
 	mysql "magic/internal/infrastructure/persistence/mysql"
-	"magic/internal/infrastructure/vectordb/qdrant"
 )
 
 const defaultSyncConcurrency = 4
@@ -43,10 +42,10 @@ type commandRunner struct {
 }
 
 type commandClients struct {
-	mysqlClient  *mysql.SQLCClient
-	redisClient  *redis.Client
-	qdrantClient *qdrant.Client
-	cleanup      cleanupGroup
+	mysqlClient   *mysql.SQLCClient
+	redisClient   *redis.Client
+	vectorClients *diinfra.VectorDBClients
+	cleanup       cleanupGroup
 }
 
 type cleanupGroup struct {
@@ -138,8 +137,8 @@ func newCommandRunner() (*commandRunner, error) {
 	knowledgeBaseRepo := diinfra.ProvideKnowledgeBaseRepository(clients.mysqlClient, clients.redisClient, logger.Named("knowledge_base_repo"))
 	fragmentRepo := diinfra.ProvideFragmentRepository(clients.mysqlClient, logger.Named("fragment_repo"))
 	documentRepo := diinfra.ProvideDocumentRepository(clients.mysqlClient, logger.Named("document_repo"))
-	vectorMgmtRepo := diinfra.ProvideVectorDBManagementRepository(clients.qdrantClient)
-	vectorDataRepo := diinfra.ProvideFragmentVectorDBDataRepository(clients.qdrantClient)
+	vectorMgmtRepo := diinfra.ProvideVectorDBManagementRepository(clients.vectorClients)
+	vectorDataRepo := diinfra.ProvideFragmentVectorDBDataRepository(clients.vectorClients)
 
 	embeddingDomainService := knowledge.ProvideEmbeddingDomainService(
 		embeddingCacheRepo,
@@ -219,12 +218,29 @@ func openCommandClients(cfg *autoloadcfg.Config, logger *logging.SugaredLogger)
 		return nil, fmt.Errorf("provide qdrant client: %w", err)
 	}
 
+	pgClient, pgCleanup, err := diinfra.ProvidePGVectorClient(cfg, logger.Named("pgvector"))
+	if err != nil {
+		qdrantCleanup()
+		redisCleanup()
+		mysqlCleanup()
+		return nil, fmt.Errorf("provide pgvector client: %w", err)
+	}
+
+	embeddedClient, embeddedCleanup, err := diinfra.ProvideEmbeddedVectorClient(cfg, logger.Named("embedded_vector"))
+	if err != nil {
+		pgCleanup()
+		qdrantCleanup()
+		redisCleanup()
+		mysqlCleanup()
+		return nil, fmt.Errorf("provide embedded vector client: %w", err)
+	}
+
 	return &commandClients{
-		mysqlClient:  mysqlClient,
-		redisClient:  redisClient,
-		qdrantClient: qdrantClient,
+		mysqlClient:   mysqlClient,
+		redisClient:   redisClient,
+		vectorClients: diinfra.ProvideVectorDBClients(qdrantClient, pgClient, embeddedClient),
 		cleanup: cleanupGroup{
-			funcs: []func(){mysqlCleanup, redisCleanup, qdrantCleanup},
+			funcs: []func(){mysqlCleanup, redisCleanup, qdrantCleanup, pgCleanup, embeddedCleanup},
 		},
 	}, nil
 }
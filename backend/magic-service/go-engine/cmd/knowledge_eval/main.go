@@ -0,0 +1,358 @@
+// Package main 提供知识库检索评测 CLI：维护 golden query 集、回放检索并对比评测 run。
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+
+	evalapp "magic/internal/application/knowledge/evaluation"
+	fragmentapp "magic/internal/application/knowledge/fragment/service"
+	configloader "magic/internal/config"
+	autoloadcfg "magic/internal/config/autoload"
+	diapp "magic/internal/di/app"
+	diinfra "magic/internal/di/infra"
+	knowledge "magic/internal/di/knowledge"
+	"magic/internal/infrastructure/logging"
+	mysql "magic/internal/infrastructure/persistence/mysql"
+)
+
+const (
+	actionSaveSet  = "save-set"
+	actionShowSet  = "show-set"
+	actionRun      = "run"
+	actionListRuns = "list-runs"
+	actionCompare  = "compare"
+)
+
+var (
+	errUnknownAction      = errors.New("unknown action")
+	errQueriesFileMissing = errors.New("queries_file is required for save-set")
+)
+
+type evaluationRunner interface {
+	SaveQuerySet(ctx context.Context, input evalapp.SaveQuerySetInput) ([]*evalapp.GoldenQuery, error)
+	ListQuerySet(ctx context.Context, input evalapp.QuerySetInput) ([]*evalapp.GoldenQuery, error)
+	RunEvaluation(ctx context.Context, input evalapp.RunInput) (*evalapp.RunResult, error)
+	ListRuns(ctx context.Context, input evalapp.ListRunsInput) ([]*evalapp.Run, error)
+	CompareRuns(ctx context.Context, input evalapp.CompareRunsInput) (*evalapp.RunDiff, error)
+}
+
+type commandOptions struct {
+	action            string
+	organizationCode  string
+	knowledgeBaseCode string
+	setCode           string
+	userID            string
+	queriesFile       string
+	label             string
+	topK              int
+	cutoffs           string
+	baselineRunID     int64
+	baseRunID         int64
+	targetRunID       int64
+	limit             int
+	offset            int
+}
+
+// goldenQueryFileItem 对应 queries_file 中的一条 golden query。
+type goldenQueryFileItem struct {
+	QueryKey              string   `json:"query_key"`
+	Query                 string   `json:"query"`
+	ExpectedDocumentCodes []string `json:"expected_document_codes"`
+	ExpectedFragmentIDs   []int64  `json:"expected_fragment_ids"`
+}
+
+type commandClients struct {
+	mysqlClient   *mysql.SQLCClient
+	redisClient   *redis.Client
+	vectorClients *diinfra.VectorDBClients
+	cleanup       cleanupGroup
+}
+
+type cleanupGroup struct {
+	funcs []func()
+}
+
+func (g cleanupGroup) Close() {
+	for i := len(g.funcs) - 1; i >= 0; i-- {
+		if g.funcs[i] != nil {
+			g.funcs[i]()
+		}
+	}
+}
+
+func main() {
+	options := parseFlags()
+	if err := run(context.Background(), options); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "knowledge evaluation failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func parseFlags() commandOptions {
+	var options commandOptions
+	flag.StringVar(&options.action, "action", actionRun, "One of save-set, show-set, run, list-runs, compare")
+	flag.StringVar(&options.organizationCode, "organization_code", "", "Organization code of the knowledge base")
+	flag.StringVar(&options.knowledgeBaseCode, "knowledge_base_code", "", "Knowledge base to evaluate")
+	flag.StringVar(&options.setCode, "set_code", "", "Golden query set code, empty means default")
+	flag.StringVar(&options.userID, "user_id", "", "User recorded as the operator")
+	flag.StringVar(&options.queriesFile, "queries_file", "", "JSON array of golden queries for save-set")
+	flag.StringVar(&options.label, "label", "", "Free-form label of the run, e.g. the tuning change under test")
+	flag.IntVar(&options.topK, "top_k", 0, "Number of hits retrieved per query, 0 means default")
+	flag.StringVar(&options.cutoffs, "cutoffs", "", "Comma separated k values, empty means 1,3,5,10")
+	flag.Int64Var(&options.baselineRunID, "baseline_run_id", 0, "Run to diff against, 0 means the previous run of the set")
+	flag.Int64Var(&options.baseRunID, "base_run_id", 0, "Base run for compare, 0 means the run before target")
+	flag.Int64Var(&options.targetRunID, "target_run_id", 0, "Target run for compare")
+	flag.IntVar(&options.limit, "limit", 0, "Page size for list-runs")
+	flag.IntVar(&options.offset, "offset", 0, "Page offset for list-runs")
+	flag.Parse()
+	return options
+}
+
+func run(ctx context.Context, options commandOptions) error {
+	cfg := configloader.New()
+	logger := logging.NewFromConfig(cfg.Logging).Named("cmd.knowledge_eval")
+
+	clients, err := openCommandClients(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer clients.cleanup.Close()
+
+	fragmentAppService := newFragmentAppService(cfg, clients, logger)
+	service := knowledge.ProvideEvaluationAppService(
+		diinfra.ProvideEvaluationRepository(clients.mysqlClient, logger.Named("evaluation_repo")),
+		fragmentAppService,
+		logger.Named("evaluation_service"),
+	)
+	return runWithService(ctx, service, options, os.Stdout)
+}
+
+func runWithService(ctx context.Context, service evaluationRunner, options commandOptions, out io.Writer) error {
+	querySet := evalapp.QuerySetInput{
+		OrganizationCode:  options.organizationCode,
+		KnowledgeBaseCode: options.knowledgeBaseCode,
+		SetCode:           options.setCode,
+	}
+
+	var (
+		result any
+		err    error
+	)
+	switch options.action {
+	case actionSaveSet:
+		result, err = saveQuerySet(ctx, service, querySet, options)
+	case actionShowSet:
+		result, err = service.ListQuerySet(ctx, querySet)
+	case actionRun:
+		var cutoffs []int
+		cutoffs, err = parseCutoffs(options.cutoffs)
+		if err == nil {
+			result, err = service.RunEvaluation(ctx, evalapp.RunInput{
+				QuerySetInput: querySet,
+				UserID:        options.userID,
+				Label:         options.label,
+				TopK:          options.topK,
+				Cutoffs:       cutoffs,
+				BaselineRunID: options.baselineRunID,
+			})
+		}
+	case actionListRuns:
+		result, err = service.ListRuns(ctx, evalapp.ListRunsInput{
+			QuerySetInput: querySet,
+			Limit:         options.limit,
+			Offset:        options.offset,
+		})
+	case actionCompare:
+		result, err = service.CompareRuns(ctx, evalapp.CompareRunsInput{
+			OrganizationCode: options.organizationCode,
+			BaseRunID:        options.baseRunID,
+			TargetRunID:      options.targetRunID,
+		})
+	default:
+		err = fmt.Errorf("%w: %q", errUnknownAction, options.action)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", options.action, err)
+	}
+
+	output, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal evaluation output: %w", err)
+	}
+	_, _ = out.Write(append(output, '\n'))
+	return nil
+}
+
+func saveQuerySet(
+	ctx context.Context,
+	service evaluationRunner,
+	querySet evalapp.QuerySetInput,
+	options commandOptions,
+) ([]*evalapp.GoldenQuery, error) {
+	if strings.TrimSpace(options.queriesFile) == "" {
+		return nil, errQueriesFileMissing
+	}
+	content, err := os.ReadFile(options.queriesFile)
+	if err != nil {
+		return nil, fmt.Errorf("read queries file: %w", err)
+	}
+	var items []goldenQueryFileItem
+	if err := json.Unmarshal(content, &items); err != nil {
+		return nil, fmt.Errorf("decode queries file: %w", err)
+	}
+
+	queries := make([]evalapp.GoldenQueryInput, 0, len(items))
+	for _, item := range items {
+		queries = append(queries, evalapp.GoldenQueryInput(item))
+	}
+	saved, err := service.SaveQuerySet(ctx, evalapp.SaveQuerySetInput{
+		QuerySetInput: querySet,
+		UserID:        options.userID,
+		Queries:       queries,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save query set: %w", err)
+	}
+	return saved, nil
+}
+
+func parseCutoffs(raw string) ([]int, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	parts := strings.Split(raw, ",")
+	cutoffs := make([]int, 0, len(parts))
+	for _, part := range parts {
+		value, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("parse cutoff %q: %w", part, err)
+		}
+		cutoffs = append(cutoffs, value)
+	}
+	return cutoffs, nil
+}
+
+// newFragmentAppService 按线上依赖图组装片段应用服务，保证回放走与线上一致的检索链路。
+func newFragmentAppService(
+	cfg *autoloadcfg.Config,
+	clients *commandClients,
+	logger *logging.SugaredLogger,
+) *fragmentapp.FragmentAppService {
+	rpcServer := diinfra.ProvideRPCServerOverIPC(cfg, logger.Named("ipc"))
+	accessTokenProvider := diinfra.ProvideAccessTokenProvider(cfg, rpcServer, logger.Named("access_token_provider"))
+	embeddingClientFactory := diinfra.ProvideEmbeddingClientFactory(cfg, rpcServer, logger.Named("embedding_client_factory"), accessTokenProvider)
+	defaultEmbeddingModel := diapp.ProvideEmbeddingDefaultModel(cfg)
+	embeddingService := diinfra.ProvideEmbeddingService(
+		cfg,
+		clients.redisClient,
+		embeddingClientFactory,
+		defaultEmbeddingModel,
+		logger.Named("embedding_service"),
+	)
+	embeddingCacheRepo := diinfra.ProvideEmbeddingCacheRepository(clients.mysqlClient, logger.Named("embedding_cache_repo"))
+	knowledgeBaseRepo := diinfra.ProvideKnowledgeBaseRepository(clients.mysqlClient, clients.redisClient, logger.Named("knowledge_base_repo"))
+	vectorMgmtRepo := diinfra.ProvideVectorDBManagementRepository(clients.vectorClients)
+
+	embeddingDomainService := knowledge.ProvideEmbeddingDomainService(
+		embeddingCacheRepo,
+		embeddingCacheRepo,
+		diinfra.ProvideEmbeddingRepository(embeddingService),
+		logger.Named("embedding_domain_service"),
+	)
+	retrievalDeps := knowledge.ProvideFragmentRetrievalDeps(
+		knowledge.ProvideFragmentRetrievalSegmenterProvider(),
+		diinfra.ProvideRerankClient(cfg, accessTokenProvider),
+		diinfra.ProvideChatCompletionClient(cfg, accessTokenProvider),
+		diinfra.ProvideMultiVectorClient(cfg, accessTokenProvider),
+		knowledge.ProvideFeedbackAggregateReader(
+			diinfra.ProvideFeedbackRepository(clients.mysqlClient, logger.Named("feedback_repo")),
+		),
+		diinfra.ProvideChunkContextCache(clients.redisClient),
+	)
+	fragmentDomainService := knowledge.ProvideFragmentDomainService(
+		diinfra.ProvideFragmentRepository(clients.mysqlClient, logger.Named("fragment_repo")),
+		embeddingDomainService,
+		knowledge.ProvideFragmentDomainInfra(
+			vectorMgmtRepo,
+			diinfra.ProvideFragmentVectorDBDataRepository(clients.vectorClients),
+			knowledgeBaseRepo,
+			knowledge.ProvideFragmentDomainConfig(defaultEmbeddingModel, cfg),
+			retrievalDeps,
+			logger.Named("fragment_domain_service"),
+		),
+	)
+	knowledgeBaseDomainService := knowledge.ProvideKnowledgeBaseDomainService(
+		knowledgeBaseRepo,
+		vectorMgmtRepo,
+		diinfra.ProvideEmbeddingDimensionResolver(cfg, embeddingService),
+		knowledge.ProvideKnowledgeBaseDomainConfig(defaultEmbeddingModel, cfg.Qdrant),
+		logger.Named("knowledge_base_domain_service"),
+	)
+	documentDomainService := knowledge.ProvideDocumentDomainService(
+		diinfra.ProvideDocumentRepository(clients.mysqlClient, logger.Named("document_repo")),
+		logger.Named("document_domain_service"),
+	)
+
+	// 评测只走检索链路，片段写入与第三方文档解析依赖无需装配。
+	return knowledge.ProvideFragmentAppService(
+		fragmentDomainService,
+		knowledgeBaseDomainService,
+		documentDomainService,
+		fragmentapp.AppDeps{},
+		clients.redisClient,
+		logger.Named("fragment_app_service"),
+	)
+}
+
+func openCommandClients(cfg *autoloadcfg.Config, logger *logging.SugaredLogger) (*commandClients, error) {
+	mysqlClient, mysqlCleanup, err := diinfra.ProvideMySQLSQLCClient(cfg, logger.Named("mysql"))
+	if err != nil {
+		return nil, fmt.Errorf("provide mysql client: %w", err)
+	}
+	cleanup := cleanupGroup{funcs: []func(){mysqlCleanup}}
+
+	redisClient, redisCleanup, err := diinfra.ProvideRedisClient(cfg, logger.Named("redis"))
+	if err != nil {
+		cleanup.Close()
+		return nil, fmt.Errorf("provide redis client: %w", err)
+	}
+	cleanup.funcs = append(cleanup.funcs, redisCleanup)
+
+	qdrantClient, qdrantCleanup, err := diinfra.ProvideQdrantClient(cfg, logger.Named("qdrant"))
+	if err != nil {
+		cleanup.Close()
+		return nil, fmt.Errorf("provide qdrant client: %w", err)
+	}
+	cleanup.funcs = append(cleanup.funcs, qdrantCleanup)
+
+	pgClient, pgCleanup, err := diinfra.ProvidePGVectorClient(cfg, logger.Named("pgvector"))
+	if err != nil {
+		cleanup.Close()
+		return nil, fmt.Errorf("provide pgvector client: %w", err)
+	}
+	cleanup.funcs = append(cleanup.funcs, pgCleanup)
+
+	embeddedClient, embeddedCleanup, err := diinfra.ProvideEmbeddedVectorClient(cfg, logger.Named("embedded_vector"))
+	if err != nil {
+		cleanup.Close()
+		return nil, fmt.Errorf("provide embedded vector client: %w", err)
+	}
+	cleanup.funcs = append(cleanup.funcs, embeddedCleanup)
+
+	return &commandClients{
+		mysqlClient:   mysqlClient,
+		redisClient:   redisClient,
+		vectorClients: diinfra.ProvideVectorDBClients(qdrantClient, pgClient, embeddedClient),
+		cleanup:       cleanup,
+	}, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	evalapp "magic/internal/application/knowledge/evaluation"
+)
+
+type evaluationRunnerStub struct {
+	lastSave evalapp.SaveQuerySetInput
+	lastRun  evalapp.RunInput
+	result   *evalapp.RunResult
+}
+
+func (s *evaluationRunnerStub) SaveQuerySet(
+	_ context.Context,
+	input evalapp.SaveQuerySetInput,
+) ([]*evalapp.GoldenQuery, error) {
+	s.lastSave = input
+	return []*evalapp.GoldenQuery{{ID: 1, QueryKey: input.Queries[0].QueryKey}}, nil
+}
+
+func (s *evaluationRunnerStub) ListQuerySet(context.Context, evalapp.QuerySetInput) ([]*evalapp.GoldenQuery, error) {
+	return []*evalapp.GoldenQuery{}, nil
+}
+
+func (s *evaluationRunnerStub) RunEvaluation(_ context.Context, input evalapp.RunInput) (*evalapp.RunResult, error) {
+	s.lastRun = input
+	return s.result, nil
+}
+
+func (s *evaluationRunnerStub) ListRuns(context.Context, evalapp.ListRunsInput) ([]*evalapp.Run, error) {
+	return []*evalapp.Run{}, nil
+}
+
+func (s *evaluationRunnerStub) CompareRuns(context.Context, evalapp.CompareRunsInput) (*evalapp.RunDiff, error) {
+	return &evalapp.RunDiff{}, nil
+}
+
+func TestRunWithServiceRunOutputsReport(t *testing.T) {
+	t.Parallel()
+
+	runner := &evaluationRunnerStub{result: &evalapp.RunResult{
+		Run:  &evalapp.Run{ID: 9, Metrics: evalapp.Metrics{QueryCount: 2, MRR: 0.75}},
+		Diff: &evalapp.RunDiff{BaseRunID: 8, TargetRunID: 9},
+	}}
+
+	var output bytes.Buffer
+	err := runWithService(context.Background(), runner, commandOptions{
+		action:            actionRun,
+		organizationCode:  "ORG1",
+		knowledgeBaseCode: "KB1",
+		label:             "fusion-0.7",
+		cutoffs:           "1, 5,10",
+	}, &output)
+	if err != nil {
+		t.Fatalf("runWithService() error = %v", err)
+	}
+	if got := runner.lastRun.Cutoffs; len(got) != 3 || got[1] != 5 {
+		t.Fatalf("unexpected cutoffs: %#v", got)
+	}
+	if runner.lastRun.Label != "fusion-0.7" || runner.lastRun.KnowledgeBaseCode != "KB1" {
+		t.Fatalf("unexpected run input: %#v", runner.lastRun)
+	}
+
+	var got struct {
+		Run struct {
+			ID      int64 `json:"id"`
+			Metrics struct {
+				MRR float64 `json:"mrr"`
+			} `json:"metrics"`
+		} `json:"run"`
+		Diff struct {
+			BaseRunID int64 `json:"base_run_id"`
+		} `json:"diff"`
+	}
+	if err := json.Unmarshal(output.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if got.Run.ID != 9 || got.Run.Metrics.MRR != 0.75 || got.Diff.BaseRunID != 8 {
+		t.Fatalf("unexpected output: %s", output.String())
+	}
+}
+
+func TestRunWithServiceSaveSetReadsQueriesFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "queries.json")
+	content := `[{"query_key":"q1","query":"reset password","expected_document_codes":["DOC1"],"expected_fragment_ids":[3]}]`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write queries file: %v", err)
+	}
+
+	runner := &evaluationRunnerStub{}
+	var output bytes.Buffer
+	err := runWithService(context.Background(), runner, commandOptions{
+		action:            actionSaveSet,
+		organizationCode:  "ORG1",
+		knowledgeBaseCode: "KB1",
+		userID:            "u1",
+		queriesFile:       path,
+	}, &output)
+	if err != nil {
+		t.Fatalf("runWithService() error = %v", err)
+	}
+	if len(runner.lastSave.Queries) != 1 || runner.lastSave.Queries[0].ExpectedFragmentIDs[0] != 3 || runner.lastSave.UserID != "u1" {
+		t.Fatalf("unexpected save input: %#v", runner.lastSave)
+	}
+}
+
+func TestRunWithServiceRejectsInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	runner := &evaluationRunnerStub{}
+	cases := []struct {
+		name    string
+		options commandOptions
+		wantErr error
+	}{
+		{name: "unknown action", options: commandOptions{action: "replay"}, wantErr: errUnknownAction},
+		{name: "missing queries file", options: commandOptions{action: actionSaveSet}, wantErr: errQueriesFileMissing},
+	}
+	for _, tc := range cases {
+		err := runWithService(context.Background(), runner, tc.options, &bytes.Buffer{})
+		if !errors.Is(err, tc.wantErr) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.wantErr, err)
+		}
+	}
+	if err := runWithService(context.Background(), runner, commandOptions{action: actionRun, cutoffs: "1,x"}, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected invalid cutoffs error")
+	}
+}
@@ -61,7 +61,7 @@ func run(ctx context.Context, apply, forceDeleteNonEmpty bool) error {
 	}
 	defer qdrantCleanup()
 
-	collectionRepo := infra.ProvideVectorDBManagementRepository(qdrantClient)
+	collectionRepo := infra.ProvideVectorDBManagementRepository(&infra.VectorDBClients{Qdrant: qdrantClient})
 	store := infra.ProvideKnowledgeRebuildStore(mysqlClient, redisClient, logger)
 	coordinator := infra.ProvideVectorRebuildCoordinator(redisClient, logger)
 	service := apprebuild.NewCleanupService(store, coordinator, collectionRepo, nil, logger.Named("knowledge.domainrebuild.CleanupService"))
@@ -137,8 +137,11 @@ func (s *DocumentAppService) executeSyncDocument(
 		if err != nil {
 			return s.failSync(ctx, doc, document.SyncFailureSplitFragments, err)
 		}
-		syncContentWordCount = document.CountSyncContentWordCount(content)
 		fragmentCount = len(fragments)
+		auxiliaryStartedAt := time.Now()
+		fragments = s.appendAuxiliaryFragments(ctx, runtimeKB, fragments, input.BusinessParams)
+		trace.log(ctx, "build_auxiliary_fragments", auxiliaryStartedAt, nil, "auxiliary_fragment_count", len(fragments)-fragmentCount)
+		syncContentWordCount = document.CountSyncContentWordCount(content)
 		syncReq = documentFragmentSyncRequest{
 			doc:            doc,
 			kb:             runtimeKB,
@@ -202,6 +205,7 @@ func (s *DocumentAppService) failSync(
 	); markErr != nil {
 		return errors.Join(failureErr, fmt.Errorf("failed to mark document sync failed: %w", markErr))
 	}
+	s.notifySyncResult(ctx, doc)
 	return failureErr
 }
 
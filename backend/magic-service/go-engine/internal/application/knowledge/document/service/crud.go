@@ -266,6 +266,30 @@ func (s *DocumentAppService) SetOriginalFileLinkProvider(provider originalFileLi
 	s.fileLinkProvider = provider
 }
 
+// SetGitFileReader 注入 Git 仓库文件读取能力。
+func (s *DocumentAppService) SetGitFileReader(reader documentdomain.GitFileReader) {
+	if s == nil {
+		return
+	}
+	s.gitFileReader = reader
+}
+
+// SetObjectStorageReader 注入对象存储对象读取能力。
+func (s *DocumentAppService) SetObjectStorageReader(reader documentdomain.ObjectStorageReader) {
+	if s == nil {
+		return
+	}
+	s.objectStorageReader = reader
+}
+
+// SetSyncResultObserver 注入文档同步终态观察者。
+func (s *DocumentAppService) SetSyncResultObserver(observer DocumentSyncResultObserver) {
+	if s == nil {
+		return
+	}
+	s.syncResultObserver = observer
+}
+
 func (s *DocumentAppService) inputToEntity(input *documentdomain.CreateManagedDocumentInput, kb *kbentity.KnowledgeBase, effectiveModel string) *docentity.KnowledgeBaseDocument {
 	return documentdomain.BuildDocumentForCreate(knowledgeBaseSnapshotFromDomain(kb), effectiveModel, input)
 }
@@ -43,6 +43,15 @@ var ErrDocumentSourcePrecheckFailed = errors.New("document source precheck faile
 // ErrDocumentAccessActorMissing 表示后台文档同步任务无法恢复权限主体。
 var ErrDocumentAccessActorMissing = errors.New("document access actor missing")
 
+// ErrArchiveEntryParserUnavailable 表示解析服务不支持从压缩包中解出条目。
+var ErrArchiveEntryParserUnavailable = errors.New("archive entry parser unavailable")
+
+// ErrGitFileReaderUnavailable 表示未注入 Git 仓库文件读取能力。
+var ErrGitFileReaderUnavailable = errors.New("git file reader unavailable")
+
+// ErrObjectStorageReaderUnavailable 表示未注入对象存储读取能力。
+var ErrObjectStorageReaderUnavailable = errors.New("object storage reader unavailable")
+
 var (
 	errDocumentFileNil      = errors.New("document file is nil")
 	errDocumentFileURLEmpty = errors.New("document file url is empty")
@@ -66,6 +75,9 @@ type DocumentAppService struct {
 	projectFilePort             documentdomain.ProjectFileResolver
 	projectFileMetadataReader   documentdomain.ProjectFileMetadataReader
 	projectFileContentPort      documentdomain.ProjectFileContentAccessor
+	gitFileReader               documentdomain.GitFileReader
+	objectStorageReader         documentdomain.ObjectStorageReader
+	syncResultObserver          DocumentSyncResultObserver
 	thirdPlatformProviders      *thirdplatformprovider.Registry
 	tokenizer                   *tokenizer.Service
 	syncScheduler               documentSyncScheduler
@@ -85,6 +97,13 @@ type DocumentAppService struct {
 	thirdFileSourceVersionStore ThirdFileSourceCacheVersionStore
 }
 
+// DocumentSyncResultObserver 观察文档同步终态（成功或失败已落库）的协作接口。
+//
+// 观察者在同步链路内同步调用，实现方需要自行控制耗时，且不能影响同步结果。
+type DocumentSyncResultObserver interface {
+	ObserveDocumentSyncResult(ctx context.Context, doc *docentity.KnowledgeBaseDocument)
+}
+
 type documentSyncScheduler interface {
 	Schedule(ctx context.Context, input *documentdomain.SyncDocumentInput)
 }
@@ -178,6 +197,15 @@ type fragmentDocumentSyncer interface {
 	SyncFragmentBatch(ctx context.Context, kb *sharedsnapshot.KnowledgeBaseRuntimeSnapshot, fragments []*fragmodel.KnowledgeBaseFragment, businessParams *ctxmeta.BusinessParams) error
 }
 
+type fragmentAuxiliaryBuilder interface {
+	BuildAuxiliaryFragments(
+		ctx context.Context,
+		cfg *shared.AuxiliaryFragmentConfig,
+		fragments []*fragmodel.KnowledgeBaseFragment,
+		businessParams *ctxmeta.BusinessParams,
+	) []*fragmodel.KnowledgeBaseFragment
+}
+
 type fragmentDocumentCleaner interface {
 	DeletePointData(ctx context.Context, collectionName, knowledgeCode, pointID string) error
 	DeletePointDataBatch(ctx context.Context, collectionName, knowledgeCode string, pointIDs []string) error
@@ -191,6 +219,7 @@ type fragmentDocumentService interface {
 	fragmentDocumentWriter
 	fragmentDocumentReader
 	fragmentDocumentSyncer
+	fragmentAuxiliaryBuilder
 	fragmentDocumentCleaner
 }
 
@@ -213,6 +242,15 @@ type documentParseService interface {
 	ResolveFileType(ctx context.Context, target string) (string, error)
 }
 
+// documentArchiveEntryParser 是压缩包展开文档的可选解析能力，由领域 ParseService 实现。
+type documentArchiveEntryParser interface {
+	ParseArchiveEntryWithOptions(
+		ctx context.Context,
+		source documentdomain.ArchiveEntrySource,
+		options documentdomain.ParseOptions,
+	) (*parseddocument.ParsedDocument, error)
+}
+
 type sourceBindingRepository interface {
 	sourcebindingrepository.Repository
 	sourcebindingrepository.ProjectFileBindingEligibilityReader
@@ -257,6 +257,66 @@ func TestDocumentAppServiceFailSyncDirectMarksFailed(t *testing.T) {
 	}
 }
 
+func TestDocumentAppServiceFailSyncNotifiesObserverOnlyWhenMarked(t *testing.T) {
+	t.Parallel()
+
+	observer := &internalSyncResultObserverStub{}
+	svc := &DocumentAppService{
+		domainService: &internalDocumentDomainServiceStub{},
+		logger:        logging.New(),
+	}
+	svc.SetSyncResultObserver(observer)
+
+	deferred := &docentity.KnowledgeBaseDocument{Code: "deferred", SyncStatus: shared.SyncStatusSyncing}
+	_ = svc.failSync(WithDeferredSyncFailureMark(context.Background()), deferred, documentdomain.SyncFailureParsing, errParseDocumentFailed)
+	if len(observer.docs) != 0 {
+		t.Fatalf("expected deferred failure not to notify observer, got %#v", observer.docs)
+	}
+
+	direct := &docentity.KnowledgeBaseDocument{Code: "direct", SyncStatus: shared.SyncStatusSyncing}
+	_ = svc.failSync(context.Background(), direct, documentdomain.SyncFailureParsing, errParseDocumentFailed)
+	if len(observer.docs) != 1 || observer.docs[0].Code != "direct" || observer.docs[0].SyncStatus != shared.SyncStatusSyncFailed {
+		t.Fatalf("expected observer to receive failed document, got %#v", observer.docs)
+	}
+}
+
+func TestDocumentAppServiceAppendAuxiliaryFragmentsUsesSeparateBudget(t *testing.T) {
+	t.Parallel()
+
+	auxiliary := make([]*fragmodel.KnowledgeBaseFragment, maxAuxiliaryFragmentsPerDocument+10)
+	for i := range auxiliary {
+		auxiliary[i] = &fragmodel.KnowledgeBaseFragment{Content: fmt.Sprintf("aux-%d", i)}
+	}
+	svc := &DocumentAppService{
+		fragmentService: &internalFragmentDocumentServiceStub{auxiliaryFragments: auxiliary},
+	}
+	kb := &kbentity.KnowledgeBase{FragmentConfig: &shared.FragmentConfig{
+		AuxiliaryFragments: &shared.AuxiliaryFragmentConfig{Enabled: true},
+	}}
+	source := []*fragmodel.KnowledgeBaseFragment{{Content: "source-1"}, {Content: "source-2"}}
+
+	fragments := svc.appendAuxiliaryFragments(context.Background(), kb, source, nil)
+	if len(fragments) != len(source)+maxAuxiliaryFragmentsPerDocument {
+		t.Fatalf("expected auxiliary fragments capped by their own budget, got %d", len(fragments))
+	}
+	if fragments[0].Content != "source-1" || fragments[len(source)].Content != "aux-0" {
+		t.Fatalf("expected source fragments to precede auxiliary fragments, got %q/%q", fragments[0].Content, fragments[len(source)].Content)
+	}
+
+	kb.FragmentConfig.AuxiliaryFragments.Enabled = false
+	if fragments := svc.appendAuxiliaryFragments(context.Background(), kb, source, nil); len(fragments) != len(source) {
+		t.Fatalf("expected disabled auxiliary config to keep source fragments only, got %d", len(fragments))
+	}
+}
+
+type internalSyncResultObserverStub struct {
+	docs []*docentity.KnowledgeBaseDocument
+}
+
+func (s *internalSyncResultObserverStub) ObserveDocumentSyncResult(_ context.Context, doc *docentity.KnowledgeBaseDocument) {
+	s.docs = append(s.docs, doc)
+}
+
 type internalParseServiceStub struct {
 	parseDocumentResult           *parseddocument.ParsedDocument
 	parseDocumentErr              error
@@ -378,6 +438,7 @@ func (s *internalThirdPlatformDocumentPortStub) Resolve(_ context.Context, input
 }
 
 type internalFragmentDocumentServiceStub struct {
+	auxiliaryFragments          []*fragmodel.KnowledgeBaseFragment
 	saveBatchCalls              int
 	updateCalls                 int
 	updateBatchCalls            int
@@ -492,6 +553,15 @@ func (s *internalFragmentDocumentServiceStub) ListExistingPointIDs(_ context.Con
 	return result, nil
 }
 
+func (s *internalFragmentDocumentServiceStub) BuildAuxiliaryFragments(
+	context.Context,
+	*shared.AuxiliaryFragmentConfig,
+	[]*fragmodel.KnowledgeBaseFragment,
+	*ctxmeta.BusinessParams,
+) []*fragmodel.KnowledgeBaseFragment {
+	return s.auxiliaryFragments
+}
+
 func (s *internalFragmentDocumentServiceStub) SyncFragmentBatch(
 	_ context.Context,
 	kb *sharedsnapshot.KnowledgeBaseRuntimeSnapshot,
@@ -884,6 +954,144 @@ func TestDocumentAppServiceParseDocumentContentFallbackToURL(t *testing.T) {
 	}
 }
 
+type internalArchiveParseServiceStub struct {
+	internalParseServiceStub
+	lastArchiveSource documentdomain.ArchiveEntrySource
+}
+
+func (s *internalArchiveParseServiceStub) ParseArchiveEntryWithOptions(
+	_ context.Context,
+	source documentdomain.ArchiveEntrySource,
+	options documentdomain.ParseOptions,
+) (*parseddocument.ParsedDocument, error) {
+	s.lastArchiveSource = source
+	s.lastParseOptions = options
+	return parseddocument.NewPlainTextParsedDocument("md", "archive entry"), nil
+}
+
+func TestDocumentAppServiceParseDocumentContentReadsArchiveEntry(t *testing.T) {
+	t.Parallel()
+
+	parseSvc := &internalArchiveParseServiceStub{}
+	svc := &DocumentAppService{
+		domainService: &internalDocumentDomainServiceStub{},
+		parseService:  parseSvc,
+		logger:        logging.New(),
+	}
+	doc := &docentity.KnowledgeBaseDocument{
+		Code:         "DOC-ARCHIVE",
+		DocumentFile: &docentity.File{Type: "external", URL: "uploads/batch.zip", Extension: "md"},
+		DocMetadata: map[string]any{
+			documentdomain.DocMetaArchiveEntryPath: "docs/readme.md",
+			documentdomain.DocMetaArchiveType:      "zip",
+		},
+	}
+
+	_, content, err := svc.parseDocumentContent(context.Background(), doc, nil, nil)
+	if err != nil {
+		t.Fatalf("parse archive entry document: %v", err)
+	}
+	if content != "archive entry" || parseSvc.parseDocumentWithOptionsCalls != 0 {
+		t.Fatalf("expected archive entry parse, content=%q direct_calls=%d", content, parseSvc.parseDocumentWithOptionsCalls)
+	}
+	if parseSvc.lastArchiveSource.ArchiveURL != "uploads/batch.zip" || parseSvc.lastArchiveSource.EntryPath != "docs/readme.md" ||
+		parseSvc.lastArchiveSource.ArchiveType != "zip" {
+		t.Fatalf("unexpected archive source: %#v", parseSvc.lastArchiveSource)
+	}
+
+	svc.parseService = &internalParseServiceStub{}
+	if _, _, err := svc.parseDocumentContent(context.Background(), doc, nil, nil); !errors.Is(err, ErrArchiveEntryParserUnavailable) {
+		t.Fatalf("expected ErrArchiveEntryParserUnavailable, got %v", err)
+	}
+}
+
+type internalGitFileReaderStub struct {
+	content    string
+	lastSource documentdomain.GitFileSource
+}
+
+func (s *internalGitFileReaderStub) ReadGitFile(_ context.Context, source documentdomain.GitFileSource) (io.ReadCloser, error) {
+	s.lastSource = source
+	return io.NopCloser(strings.NewReader(s.content)), nil
+}
+
+func TestDocumentAppServiceParseDocumentContentReadsGitFile(t *testing.T) {
+	t.Parallel()
+
+	parseSvc := &internalParseServiceStub{}
+	svc := &DocumentAppService{
+		domainService: &internalDocumentDomainServiceStub{},
+		parseService:  parseSvc,
+		logger:        logging.New(),
+	}
+	doc := &docentity.KnowledgeBaseDocument{
+		Code:         "DOC-GIT",
+		DocumentFile: &docentity.File{Type: "external", URL: "https://git.example.com/team/docs", Extension: "md"},
+		DocMetadata:  documentdomain.BuildGitFileDocMetadata("main", "docs/intro.md"),
+	}
+
+	if _, _, err := svc.parseDocumentContent(context.Background(), doc, nil, nil); !errors.Is(err, ErrGitFileReaderUnavailable) {
+		t.Fatalf("expected ErrGitFileReaderUnavailable, got %v", err)
+	}
+
+	reader := &internalGitFileReaderStub{content: "# Intro"}
+	svc.SetGitFileReader(reader)
+	_, content, err := svc.parseDocumentContent(context.Background(), doc, nil, nil)
+	if err != nil {
+		t.Fatalf("parse git document: %v", err)
+	}
+	if content != "# Intro" || parseSvc.parseDocumentReaderCalls != 1 || parseSvc.parseDocumentWithOptionsCalls != 0 {
+		t.Fatalf("expected git file reader parse, content=%q reader_calls=%d", content, parseSvc.parseDocumentReaderCalls)
+	}
+	if reader.lastSource.RepositoryURL != "https://git.example.com/team/docs" || reader.lastSource.Ref != "main" ||
+		reader.lastSource.Path != "docs/intro.md" {
+		t.Fatalf("unexpected git source: %#v", reader.lastSource)
+	}
+}
+
+type internalObjectStorageReaderStub struct {
+	content    string
+	lastSource documentdomain.ObjectStorageSource
+}
+
+func (s *internalObjectStorageReaderStub) ReadObject(_ context.Context, source documentdomain.ObjectStorageSource) (io.ReadCloser, error) {
+	s.lastSource = source
+	return io.NopCloser(strings.NewReader(s.content)), nil
+}
+
+func TestDocumentAppServiceParseDocumentContentReadsObjectStorage(t *testing.T) {
+	t.Parallel()
+
+	parseSvc := &internalParseServiceStub{}
+	svc := &DocumentAppService{
+		domainService: &internalDocumentDomainServiceStub{},
+		parseService:  parseSvc,
+		logger:        logging.New(),
+	}
+	doc := &docentity.KnowledgeBaseDocument{
+		Code:         "DOC-S3",
+		DocumentFile: &docentity.File{Type: "external", URL: "s3://reports/2026/q1.md", Extension: "md"},
+		DocMetadata:  documentdomain.BuildObjectStorageDocMetadata("reports", "2026/q1.md"),
+	}
+
+	if _, _, err := svc.parseDocumentContent(context.Background(), doc, nil, nil); !errors.Is(err, ErrObjectStorageReaderUnavailable) {
+		t.Fatalf("expected ErrObjectStorageReaderUnavailable, got %v", err)
+	}
+
+	reader := &internalObjectStorageReaderStub{content: "# Q1"}
+	svc.SetObjectStorageReader(reader)
+	_, content, err := svc.parseDocumentContent(context.Background(), doc, nil, nil)
+	if err != nil {
+		t.Fatalf("parse object storage document: %v", err)
+	}
+	if content != "# Q1" || parseSvc.parseDocumentReaderCalls != 1 || parseSvc.parseDocumentWithOptionsCalls != 0 {
+		t.Fatalf("expected object storage reader parse, content=%q reader_calls=%d", content, parseSvc.parseDocumentReaderCalls)
+	}
+	if reader.lastSource.Bucket != "reports" || reader.lastSource.Key != "2026/q1.md" {
+		t.Fatalf("unexpected object storage source: %#v", reader.lastSource)
+	}
+}
+
 func TestDocumentAppServiceParseDocumentContentPassesParseOptionsFromMetadata(t *testing.T) {
 	t.Parallel()
 
@@ -1064,7 +1272,7 @@ func TestDocumentAppServiceParseDocumentContentFailsUnsupportedFileTypePrecheck(
 	}
 	doc := &docentity.KnowledgeBaseDocument{
 		Code:         "DOC-UNSUPPORTED",
-		DocumentFile: &docentity.File{URL: "https://example.com/demo.js", Extension: "js"},
+		DocumentFile: &docentity.File{URL: "https://example.com/demo.svg", Extension: "svg"},
 	}
 
 	_, _, err := svc.parseDocumentContent(context.Background(), doc, nil, nil)
@@ -13,6 +13,7 @@ import (
 	document "magic/internal/domain/knowledge/document/service"
 	documentsplitter "magic/internal/domain/knowledge/document/splitter"
 	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	fragretrieval "magic/internal/domain/knowledge/fragment/retrieval"
 	fragdomain "magic/internal/domain/knowledge/fragment/service"
 	kbentity "magic/internal/domain/knowledge/knowledgebase/entity"
 	"magic/internal/domain/knowledge/shared"
@@ -25,6 +26,9 @@ import (
 const (
 	syncModeCreate = document.SyncModeCreate
 	syncModeResync = document.SyncModeResync
+
+	// maxAuxiliaryFragmentsPerDocument 是单文档摘要/问题辅助片段的独立预算，不占用源片段的 MaxFragmentsPerDocument 配额。
+	maxAuxiliaryFragmentsPerDocument = 500
 )
 
 func (s *DocumentAppService) buildFragments(
@@ -61,6 +65,8 @@ func (s *DocumentAppService) buildFragments(
 		Doc:          fragDocumentFromDomain(doc),
 		Chunks:       toFragmentTokenChunks(chunks),
 		SplitVersion: splitVersion,
+		// 上下文增强版本写入片段 metadata，开关变化时重同步指纹随之变化并触发重新向量化。
+		RetrievalTextVersion: resolveKnowledgeBaseRetrievalTextVersion(kb),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("build document fragments: %w", err)
@@ -71,6 +77,36 @@ func (s *DocumentAppService) buildFragments(
 	return fragments, nil
 }
 
+func resolveKnowledgeBaseRetrievalTextVersion(kb *kbentity.KnowledgeBase) string {
+	if kb == nil || kb.RetrieveConfig == nil {
+		return fragretrieval.RetrievalTextVersionV1
+	}
+	return fragretrieval.ResolveRetrievalTextVersion(kb.RetrieveConfig.ContextualEnrichment)
+}
+
+// appendAuxiliaryFragments 在知识库开启辅助片段时追加摘要/问题片段。
+//
+// 源片段已按 MaxFragmentsPerDocument 校验，辅助片段单独受 maxAuxiliaryFragmentsPerDocument 约束。
+//
+// 辅助片段与普通片段一起参与重同步比对，源内容变化后旧的辅助片段会随计划一并删除。
+func (s *DocumentAppService) appendAuxiliaryFragments(
+	ctx context.Context,
+	kb *kbentity.KnowledgeBase,
+	fragments []*fragmodel.KnowledgeBaseFragment,
+	businessParams *ctxmeta.BusinessParams,
+) []*fragmodel.KnowledgeBaseFragment {
+	if kb == nil || kb.FragmentConfig == nil || len(fragments) == 0 {
+		return fragments
+	}
+	cfg := kb.FragmentConfig.AuxiliaryFragments
+	if cfg == nil || !cfg.Enabled {
+		return fragments
+	}
+	auxiliary := s.fragmentService.BuildAuxiliaryFragments(ctx, cfg, fragments, businessParams)
+	auxiliary = auxiliary[:min(len(auxiliary), maxAuxiliaryFragmentsPerDocument)]
+	return append(fragments, auxiliary...)
+}
+
 func shouldForceAutoSplitForKnowledgeBase(kb *kbentity.KnowledgeBase) bool {
 	if kb == nil {
 		return false
@@ -510,6 +546,7 @@ func (s *DocumentAppService) finishSyncWithWordCount(ctx context.Context, doc *d
 	if err := s.domainService.MarkSynced(ctx, doc, wordCount); err != nil {
 		return fmt.Errorf("failed to mark document synced: %w", err)
 	}
+	s.notifySyncResult(ctx, doc)
 	if err := s.refreshKnowledgeBaseWordCount(ctx, doc); err != nil && s.logger != nil {
 		s.logger.KnowledgeWarnContext(
 			ctx,
@@ -522,6 +559,14 @@ func (s *DocumentAppService) finishSyncWithWordCount(ctx context.Context, doc *d
 	return nil
 }
 
+// notifySyncResult 在同步终态落库后通知观察者。
+func (s *DocumentAppService) notifySyncResult(ctx context.Context, doc *docentity.KnowledgeBaseDocument) {
+	if s == nil || s.syncResultObserver == nil || doc == nil {
+		return
+	}
+	s.syncResultObserver.ObserveDocumentSyncResult(ctx, doc)
+}
+
 func (s *DocumentAppService) refreshKnowledgeBaseWordCount(ctx context.Context, doc *docentity.KnowledgeBaseDocument) error {
 	if s == nil || doc == nil || s.domainService == nil || s.kbService == nil {
 		return nil
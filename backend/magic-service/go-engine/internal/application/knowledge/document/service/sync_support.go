@@ -64,7 +64,7 @@ func (s *DocumentAppService) parseDocumentContent(
 		return nil, "", document.NewSyncStageError(document.SyncFailureDocumentFileEmpty, ErrDocumentFileEmpty)
 	}
 
-	parsedDocument, err := s.parseService.ParseDocumentWithOptions(ctx, doc.DocumentFile.URL, doc.DocumentFile.Extension, parseOptions)
+	parsedDocument, err := s.parseDocumentFile(ctx, doc, parseOptions)
 	if err != nil {
 		return nil, "", document.NewSyncStageError(document.SyncFailureParsing, err)
 	}
@@ -78,6 +78,80 @@ func (s *DocumentAppService) parseDocumentContent(
 	return result.Parsed, result.Content, nil
 }
 
+// parseDocumentFile 按文档文件解析；压缩包展开的文档先从压缩包中解出对应条目，
+// Git 来源文档从仓库读取，对象存储来源文档从桶中读取。
+func (s *DocumentAppService) parseDocumentFile(
+	ctx context.Context,
+	doc *docentity.KnowledgeBaseDocument,
+	parseOptions document.ParseOptions,
+) (*parseddocument.ParsedDocument, error) {
+	if gitSource, ok := document.ResolveGitFileSource(doc); ok {
+		return s.parseGitFile(ctx, doc, gitSource, parseOptions)
+	}
+	if objectSource, ok := document.ResolveObjectStorageSource(doc); ok {
+		return s.parseObjectStorageFile(ctx, doc, objectSource, parseOptions)
+	}
+	source, ok := document.ResolveArchiveEntrySource(doc)
+	if !ok {
+		parsed, err := s.parseService.ParseDocumentWithOptions(ctx, doc.DocumentFile.URL, doc.DocumentFile.Extension, parseOptions)
+		if err != nil {
+			return nil, fmt.Errorf("parse document file: %w", err)
+		}
+		return parsed, nil
+	}
+	archiveParser, ok := s.parseService.(documentArchiveEntryParser)
+	if !ok {
+		return nil, ErrArchiveEntryParserUnavailable
+	}
+	parsed, err := archiveParser.ParseArchiveEntryWithOptions(ctx, source, parseOptions)
+	if err != nil {
+		return nil, fmt.Errorf("parse archive entry %s: %w", source.EntryPath, err)
+	}
+	return parsed, nil
+}
+
+func (s *DocumentAppService) parseGitFile(
+	ctx context.Context,
+	doc *docentity.KnowledgeBaseDocument,
+	source document.GitFileSource,
+	parseOptions document.ParseOptions,
+) (*parseddocument.ParsedDocument, error) {
+	if s.gitFileReader == nil {
+		return nil, ErrGitFileReaderUnavailable
+	}
+	reader, err := s.gitFileReader.ReadGitFile(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("read git file %s: %w", source.Path, err)
+	}
+	defer func() { _ = reader.Close() }()
+	parsed, err := s.parseService.ParseDocumentReaderWithOptions(ctx, source.Path, reader, doc.DocumentFile.Extension, parseOptions)
+	if err != nil {
+		return nil, fmt.Errorf("parse git file %s: %w", source.Path, err)
+	}
+	return parsed, nil
+}
+
+func (s *DocumentAppService) parseObjectStorageFile(
+	ctx context.Context,
+	doc *docentity.KnowledgeBaseDocument,
+	source document.ObjectStorageSource,
+	parseOptions document.ParseOptions,
+) (*parseddocument.ParsedDocument, error) {
+	if s.objectStorageReader == nil {
+		return nil, ErrObjectStorageReaderUnavailable
+	}
+	reader, err := s.objectStorageReader.ReadObject(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("read object %s/%s: %w", source.Bucket, source.Key, err)
+	}
+	defer func() { _ = reader.Close() }()
+	parsed, err := s.parseService.ParseDocumentReaderWithOptions(ctx, source.Key, reader, doc.DocumentFile.Extension, parseOptions)
+	if err != nil {
+		return nil, fmt.Errorf("parse object %s/%s: %w", source.Bucket, source.Key, err)
+	}
+	return parsed, nil
+}
+
 func (s *DocumentAppService) parseProjectFileDocumentContent(
 	ctx context.Context,
 	doc *docentity.KnowledgeBaseDocument,
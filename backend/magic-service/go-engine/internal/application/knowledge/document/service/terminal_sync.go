@@ -47,6 +47,7 @@ func (s *DocumentAppService) markTerminalDocumentSyncFailed(
 			if markErr := s.domainService.MarkSyncFailed(ctx, doc, message); markErr != nil {
 				return fmt.Errorf("mark terminal document sync failed: %w", markErr)
 			}
+			s.notifySyncResult(ctx, doc)
 			return nil
 		}
 		if isTerminalDocumentLookupError(err) {
@@ -60,6 +61,7 @@ func (s *DocumentAppService) markTerminalDocumentSyncFailed(
 	if err := s.domainService.MarkSyncFailed(ctx, doc, message); err != nil {
 		return fmt.Errorf("mark terminal document sync failed: %w", err)
 	}
+	s.notifySyncResult(ctx, doc)
 	return nil
 }
 
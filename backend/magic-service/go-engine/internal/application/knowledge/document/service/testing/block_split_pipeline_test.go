@@ -0,0 +1,116 @@
+package docapp_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appservice "magic/internal/application/knowledge/document/service"
+	"magic/internal/domain/knowledge/shared"
+	"magic/internal/domain/knowledge/shared/parseddocument"
+)
+
+func TestSplitParsedDocumentToChunksKeepsSubtitleTimestamps(t *testing.T) {
+	t.Parallel()
+	tokenizerSvc := newSharedTokenizerForTest(t)
+
+	parsed := &parseddocument.ParsedDocument{
+		SourceType: parseddocument.SourceSubtitle,
+		Blocks: []parseddocument.ParsedBlock{
+			{
+				Type:    parseddocument.BlockTypeSubtitleWindow,
+				Content: "大家好，欢迎参加周会\n先过一下上周的进度",
+				Metadata: map[string]any{
+					parseddocument.MetaStartMS: int64(1000),
+					parseddocument.MetaEndMS:   int64(8000),
+				},
+			},
+			{
+				Type:    parseddocument.BlockTypeSubtitleWindow,
+				Content: strings.Repeat("We reviewed the release checklist and assigned owners. ", 60),
+				Metadata: map[string]any{
+					parseddocument.MetaStartMS: int64(3_661_000),
+					parseddocument.MetaEndMS:   int64(3_720_000),
+				},
+			},
+		},
+	}
+
+	chunks, splitVersion, err := appservice.SplitParsedDocumentToChunksWithTokenizerForTest(context.Background(), appservice.SplitParsedDocumentToChunksForTestInput{
+		ParsedDocument: parsed,
+		SourceFileType: "srt",
+		RequestedMode:  shared.FragmentModeNormal,
+		SegmentConfig:  appservice.PreviewSegmentConfigForTest{ChunkSize: 200, Separator: "\n"},
+		Model:          "text-embedding-3-small",
+	}, tokenizerSvc)
+	if err != nil {
+		t.Fatalf("split parsed document: %v", err)
+	}
+	if splitVersion != "go_split_v1_subtitle" {
+		t.Fatalf("unexpected split version: %q", splitVersion)
+	}
+	if len(chunks) < 3 {
+		t.Fatalf("expected long window to split into multiple chunks, got %d", len(chunks))
+	}
+	if chunks[0].SectionTitle != "00:00:01 - 00:00:08" || chunks[0].Metadata[parseddocument.MetaStartMS] != int64(1000) ||
+		chunks[0].EffectiveSplitMode != "subtitle_window" {
+		t.Fatalf("unexpected first subtitle chunk: %#v", chunks[0])
+	}
+	for index, chunk := range chunks[1:] {
+		if chunk.SectionTitle != "01:01:01 - 01:02:00" || chunk.SectionChunkIndex != index ||
+			chunk.Metadata[parseddocument.MetaStartMS] != int64(3_661_000) ||
+			chunk.Metadata[parseddocument.MetaEndMS] != int64(3_720_000) {
+			t.Fatalf("subtitle chunk %d lost window metadata: %#v", index, chunk)
+		}
+	}
+}
+
+func TestSplitParsedDocumentToChunksKeepsNotebookCellsApart(t *testing.T) {
+	t.Parallel()
+	tokenizerSvc := newSharedTokenizerForTest(t)
+
+	parsed := &parseddocument.ParsedDocument{
+		SourceType: parseddocument.SourceNotebook,
+		Blocks: []parseddocument.ParsedBlock{
+			{
+				Type:    parseddocument.BlockTypeNotebookCell,
+				Content: "# 销量分析",
+				Metadata: map[string]any{
+					parseddocument.MetaNotebookCellIndex: 0,
+					parseddocument.MetaNotebookCellType:  "markdown",
+					parseddocument.MetaNotebookSection:   "销量分析",
+				},
+			},
+			{
+				Type:    parseddocument.BlockTypeNotebookCell,
+				Content: "```python\ndf.sum()\n```",
+				Metadata: map[string]any{
+					parseddocument.MetaNotebookCellIndex: 2,
+					parseddocument.MetaNotebookCellType:  "code",
+					parseddocument.MetaNotebookSection:   "销量分析",
+				},
+			},
+		},
+	}
+
+	chunks, splitVersion, err := appservice.SplitParsedDocumentToChunksWithTokenizerForTest(context.Background(), appservice.SplitParsedDocumentToChunksForTestInput{
+		ParsedDocument: parsed,
+		SourceFileType: "ipynb",
+		RequestedMode:  shared.FragmentModeNormal,
+		SegmentConfig:  appservice.PreviewSegmentConfigForTest{ChunkSize: 500, Separator: "\n"},
+		Model:          "text-embedding-3-small",
+	}, tokenizerSvc)
+	if err != nil {
+		t.Fatalf("split parsed document: %v", err)
+	}
+	if splitVersion != "go_split_v1_notebook" || len(chunks) != 2 {
+		t.Fatalf("unexpected notebook split result version=%q chunks=%#v", splitVersion, chunks)
+	}
+	for index, cellIndex := range []int{0, 2} {
+		chunk := chunks[index]
+		if chunk.Content != parsed.Blocks[index].Content || chunk.SectionPath != "销量分析" ||
+			chunk.Metadata[parseddocument.MetaNotebookCellIndex] != cellIndex || chunk.EffectiveSplitMode != "notebook_cell" {
+			t.Fatalf("unexpected notebook chunk %d: %#v", index, chunk)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package docapp_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	appservice "magic/internal/application/knowledge/document/service"
+	"magic/internal/domain/knowledge/shared"
+	"magic/internal/domain/knowledge/shared/parseddocument"
+)
+
+func TestSplitParsedDocumentToChunksKeepsCodeSymbolsAndLineRanges(t *testing.T) {
+	t.Parallel()
+	tokenizerSvc := newSharedTokenizerForTest(t)
+
+	bodyLines := []string{"func (s *Server) Start() error {"}
+	for index := range 60 {
+		bodyLines = append(bodyLines, fmt.Sprintf("\tif err := s.registerHandler(%d, \"route-%d\"); err != nil {\n\t\treturn err\n\t}", index, index))
+	}
+	bodyLines = append(bodyLines, "\treturn nil", "}")
+	body := strings.Join(bodyLines, "\n")
+	bodyEnd := 10 + strings.Count(body, "\n")
+	parsed := &parseddocument.ParsedDocument{
+		SourceType: parseddocument.SourceCode,
+		Blocks: []parseddocument.ParsedBlock{
+			{
+				Type:    parseddocument.BlockTypeCodeModule,
+				Content: "package server\n\nimport \"net/http\"",
+				Metadata: map[string]any{
+					parseddocument.MetaCodeFilePath:    "internal/server/server.go",
+					parseddocument.MetaCodeSymbol:      "",
+					parseddocument.MetaCodeSectionPath: "server",
+					parseddocument.MetaCodeStartLine:   1,
+					parseddocument.MetaCodeEndLine:     3,
+				},
+			},
+			{
+				Type:    parseddocument.BlockTypeCodeSymbol,
+				Content: body,
+				Metadata: map[string]any{
+					parseddocument.MetaCodeLanguage:    "go",
+					parseddocument.MetaCodeFilePath:    "internal/server/server.go",
+					parseddocument.MetaCodeSymbol:      "Server.Start",
+					parseddocument.MetaCodeSymbolKind:  "method",
+					parseddocument.MetaCodeSectionPath: "server/Server.Start",
+					parseddocument.MetaCodeStartLine:   10,
+					parseddocument.MetaCodeEndLine:     bodyEnd,
+				},
+			},
+		},
+	}
+
+	chunks, splitVersion, err := appservice.SplitParsedDocumentToChunksWithTokenizerForTest(context.Background(), appservice.SplitParsedDocumentToChunksForTestInput{
+		ParsedDocument: parsed,
+		SourceFileType: "go",
+		RequestedMode:  shared.FragmentModeNormal,
+		SegmentConfig:  appservice.PreviewSegmentConfigForTest{ChunkSize: 300, Separator: "\n"},
+		Model:          "text-embedding-3-small",
+	}, tokenizerSvc)
+	if err != nil {
+		t.Fatalf("split parsed document: %v", err)
+	}
+	if splitVersion != "go_split_v1_code" {
+		t.Fatalf("unexpected split version: %q", splitVersion)
+	}
+	if len(chunks) < 3 {
+		t.Fatalf("expected module chunk plus multiple method chunks, got %d", len(chunks))
+	}
+	if chunks[0].SectionPath != "server" || chunks[0].SectionTitle != "server" ||
+		chunks[0].Content != "File: internal/server/server.go\nSymbol: server\n\npackage server\n\nimport \"net/http\"" {
+		t.Fatalf("unexpected module chunk: %#v", chunks[0])
+	}
+
+	nextLine := 10
+	for index, chunk := range chunks[1:] {
+		if !strings.HasPrefix(chunk.Content, "File: internal/server/server.go\nSymbol: server/Server.Start\n\n") {
+			t.Fatalf("chunk %d missing code prefix: %q", index, chunk.Content)
+		}
+		if chunk.SectionPath != "server/Server.Start" || chunk.SectionTitle != "Server.Start" || chunk.SectionLevel != 2 ||
+			chunk.SectionChunkIndex != index || chunk.EffectiveSplitMode != "code_symbol" {
+			t.Fatalf("unexpected chunk %d section: %#v", index, chunk)
+		}
+		startLine, _ := chunk.Metadata[parseddocument.MetaCodeStartLine].(int)
+		endLine, _ := chunk.Metadata[parseddocument.MetaCodeEndLine].(int)
+		if startLine != nextLine || endLine < startLine {
+			t.Fatalf("chunk %d has unexpected line range %d-%d, want start %d", index, startLine, endLine, nextLine)
+		}
+		code := strings.TrimPrefix(chunk.Content, "File: internal/server/server.go\nSymbol: server/Server.Start\n\n")
+		if got := strings.Count(code, "\n") + 1; got != endLine-startLine+1 {
+			t.Fatalf("chunk %d line range %d-%d does not match %d lines", index, startLine, endLine, got)
+		}
+		nextLine = endLine + 1
+	}
+	if nextLine != bodyEnd+1 {
+		t.Fatalf("expected chunks to cover method through line %d, stopped at %d", bodyEnd, nextLine-1)
+	}
+}
@@ -3927,19 +3927,19 @@ func TestDocumentAppServiceNotifyProjectFileChangeNormalizesUnsupportedExtension
 		OrganizationCode: "ORG1",
 		ProjectID:        900,
 		ProjectFileID:    503,
-		FileName:         "demo.js",
-		FileExtension:    "js",
-		DocumentFile:     map[string]any{"type": "project_file", "name": "demo.js", "extension": "js"},
+		FileName:         "demo.svg",
+		FileExtension:    "svg",
+		DocumentFile:     map[string]any{"type": "project_file", "name": "demo.svg", "extension": "svg"},
 	})
 
 	if err := svc.NotifyProjectFileChange(context.Background(), &docdto.NotifyProjectFileChangeInput{ProjectFileID: 503}); err != nil {
 		t.Fatalf("NotifyProjectFileChange returned error: %v", err)
 	}
 	if len(domain.savedDocs) != 0 {
-		t.Fatalf("expected js project file not to auto-create document, got %#v", domain.savedDocs)
+		t.Fatalf("expected svg project file not to auto-create document, got %#v", domain.savedDocs)
 	}
 	if scheduler.scheduleCalls != 0 {
-		t.Fatalf("expected js project file not to schedule sync, got %#v", scheduler.inputs)
+		t.Fatalf("expected svg project file not to schedule sync, got %#v", scheduler.inputs)
 	}
 }
 
@@ -5164,6 +5164,15 @@ func (*fragmentDestroyServiceStub) ListExistingPointIDs(context.Context, string,
 	return map[string]struct{}{}, nil
 }
 
+func (*fragmentDestroyServiceStub) BuildAuxiliaryFragments(
+	context.Context,
+	*shared.AuxiliaryFragmentConfig,
+	[]*fragmodel.KnowledgeBaseFragment,
+	*ctxmeta.BusinessParams,
+) []*fragmodel.KnowledgeBaseFragment {
+	return nil
+}
+
 func (s *fragmentDestroyServiceStub) SyncFragmentBatch(
 	_ context.Context,
 	_ *sharedsnapshot.KnowledgeBaseRuntimeSnapshot,
@@ -0,0 +1,67 @@
+package docapp_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	appservice "magic/internal/application/knowledge/document/service"
+	"magic/internal/domain/knowledge/shared"
+	"magic/internal/domain/knowledge/shared/parseddocument"
+)
+
+func TestSplitParsedDocumentToChunksKeepsEmailMetadataOnEveryChunk(t *testing.T) {
+	t.Parallel()
+	tokenizerSvc := newSharedTokenizerForTest(t)
+
+	tags := []string{"email", "from:alice@example.com", "to:bob@example.com"}
+	parsed := &parseddocument.ParsedDocument{
+		SourceType: parseddocument.SourceEmail,
+		Blocks: []parseddocument.ParsedBlock{
+			{Type: parseddocument.BlockTypeEmailMessage, Content: "  "},
+			{
+				Type:    parseddocument.BlockTypeEmailMessage,
+				Content: strings.Repeat("Release checklist item with owner and due date.\n", 80),
+				Metadata: map[string]any{
+					parseddocument.MetaChunkType:    parseddocument.BlockTypeEmailMessage,
+					parseddocument.MetaEmailSubject: "Launch plan",
+					parseddocument.MetaEmailFrom:    "Alice <alice@example.com>",
+					parseddocument.MetaEmailTo:      []string{"bob@example.com"},
+					parseddocument.MetaEmailDate:    "2024-01-01T09:00:00Z",
+					parseddocument.MetaCreatedAtTS:  int64(1704099600),
+					parseddocument.MetaTags:         tags,
+				},
+			},
+		},
+	}
+
+	chunks, splitVersion, err := appservice.SplitParsedDocumentToChunksWithTokenizerForTest(context.Background(), appservice.SplitParsedDocumentToChunksForTestInput{
+		ParsedDocument: parsed,
+		SourceFileType: "eml",
+		RequestedMode:  shared.FragmentModeNormal,
+		SegmentConfig:  appservice.PreviewSegmentConfigForTest{ChunkSize: 300, Separator: "\n"},
+		Model:          "text-embedding-3-small",
+	}, tokenizerSvc)
+	if err != nil {
+		t.Fatalf("split parsed document: %v", err)
+	}
+	if splitVersion != "go_split_v1_email" {
+		t.Fatalf("unexpected split version: %q", splitVersion)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("expected long email to split into multiple chunks, got %d", len(chunks))
+	}
+	for index, chunk := range chunks {
+		if !strings.HasPrefix(chunk.Content, "Subject: Launch plan\nFrom: Alice <alice@example.com>\nTo: bob@example.com\nDate: 2024-01-01T09:00:00Z\n\n") {
+			t.Fatalf("chunk %d missing email header prefix: %q", index, chunk.Content)
+		}
+		if chunk.SectionChunkIndex != index || chunk.SectionTitle != "Launch plan" || chunk.EffectiveSplitMode != "email_message" {
+			t.Fatalf("unexpected chunk %d section: %#v", index, chunk)
+		}
+		if chunk.Metadata[parseddocument.MetaCreatedAtTS] != int64(1704099600) ||
+			!reflect.DeepEqual(chunk.Metadata[parseddocument.MetaTags], tags) {
+			t.Fatalf("chunk %d lost filter metadata: %#v", index, chunk.Metadata)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+// Package evaluation 编排知识库检索评测：维护 golden query 集、回放检索并生成可对比的指标报告。
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	evalentity "magic/internal/domain/knowledge/evaluation/entity"
+	evalrepository "magic/internal/domain/knowledge/evaluation/repository"
+	evalservice "magic/internal/domain/knowledge/evaluation/service"
+	"magic/internal/domain/knowledge/shared"
+	"magic/internal/infrastructure/logging"
+	"magic/pkg/convert"
+)
+
+const (
+	defaultListRunsLimit = 20
+	maxListRunsLimit     = 100
+)
+
+var (
+	// ErrEvaluationScopeRequired 表示缺少组织或知识库编码。
+	ErrEvaluationScopeRequired = errors.New("organization code and knowledge base code are required")
+	// ErrEvaluationUnavailable 表示评测服务未完成装配。
+	ErrEvaluationUnavailable = errors.New("knowledge evaluation service unavailable")
+)
+
+// 以下别名供接口层引用评测结果，避免其直接依赖领域包。
+type (
+	// GoldenQuery 评测集中的一条 golden query。
+	GoldenQuery = evalentity.GoldenQuery
+	// RetrievedHit 回放检索返回的一条结果。
+	RetrievedHit = evalentity.RetrievedHit
+	// Run 一次评测回放记录。
+	Run = evalentity.Run
+	// Metrics run 级汇总指标。
+	Metrics = evalentity.Metrics
+	// QueryResult 单条 query 的评测结果。
+	QueryResult = evalentity.QueryResult
+	// RunDiff 两次 run 的对比结果。
+	RunDiff = evalentity.RunDiff
+)
+
+// SearchInput 表示一次评测回放检索请求。
+type SearchInput struct {
+	OrganizationCode  string
+	UserID            string
+	KnowledgeBaseCode string
+	Query             string
+	TopK              int
+}
+
+// SimilaritySearcher 定义评测回放所需的检索能力，需与线上相似度检索走同一条链路。
+type SimilaritySearcher interface {
+	SearchForEvaluation(ctx context.Context, input SearchInput) ([]evalentity.RetrievedHit, error)
+}
+
+// GoldenQueryInput 表示待保存的一条 golden query。
+type GoldenQueryInput struct {
+	QueryKey              string
+	Query                 string
+	ExpectedDocumentCodes []string
+	ExpectedFragmentIDs   []int64
+}
+
+// QuerySetInput 定位一个知识库下的评测集。
+type QuerySetInput struct {
+	OrganizationCode  string
+	KnowledgeBaseCode string
+	SetCode           string
+}
+
+// SaveQuerySetInput 表示全量替换评测集的请求。
+type SaveQuerySetInput struct {
+	QuerySetInput
+	UserID  string
+	Queries []GoldenQueryInput
+}
+
+// RunInput 表示一次评测回放请求。
+type RunInput struct {
+	QuerySetInput
+	UserID string
+	Label  string
+	TopK   int
+	// Cutoffs 为需要统计的 k 值，为空时使用默认 1/3/5/10。
+	Cutoffs []int
+	// BaselineRunID 为对比基线；为 0 时自动取同一评测集的上一次 run。
+	BaselineRunID int64
+}
+
+// RunResult 表示评测回放结果，Diff 在不存在基线 run 时为空。
+type RunResult struct {
+	Run  *evalentity.Run     `json:"run"`
+	Diff *evalentity.RunDiff `json:"diff"`
+}
+
+// ListRunsInput 表示分页查询评测 run 的请求。
+type ListRunsInput struct {
+	QuerySetInput
+	Limit  int
+	Offset int
+}
+
+// CompareRunsInput 表示对比两次评测 run 的请求。
+type CompareRunsInput struct {
+	OrganizationCode string
+	BaseRunID        int64
+	TargetRunID      int64
+}
+
+// Service 提供知识库检索评测用例。
+type Service struct {
+	repo     evalrepository.Repository
+	searcher SimilaritySearcher
+	logger   *logging.SugaredLogger
+	now      func() time.Time
+}
+
+// NewService 创建检索评测应用服务。
+func NewService(repo evalrepository.Repository, searcher SimilaritySearcher, logger *logging.SugaredLogger) *Service {
+	return &Service{repo: repo, searcher: searcher, logger: logger, now: time.Now}
+}
+
+// SaveQuerySet 校验并全量替换评测集。
+func (s *Service) SaveQuerySet(ctx context.Context, input SaveQuerySetInput) ([]*evalentity.GoldenQuery, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, err
+	}
+	scope, err := resolveScope(input.QuerySetInput)
+	if err != nil {
+		return nil, err
+	}
+	if len(input.Queries) > evalentity.MaxQueriesPerSet {
+		return nil, fmt.Errorf("%w: %d > %d", evalentity.ErrTooManyGoldenQueries, len(input.Queries), evalentity.MaxQueriesPerSet)
+	}
+
+	userID := strings.TrimSpace(input.UserID)
+	queries := make([]evalentity.GoldenQuery, 0, len(input.Queries))
+	seen := make(map[string]struct{}, len(input.Queries))
+	for index, item := range input.Queries {
+		query := evalentity.GoldenQuery{
+			QueryKey:              item.QueryKey,
+			Query:                 item.Query,
+			ExpectedDocumentCodes: item.ExpectedDocumentCodes,
+			ExpectedFragmentIDs:   item.ExpectedFragmentIDs,
+			CreatedUID:            userID,
+			UpdatedUID:            userID,
+		}
+		if err := query.Normalize(); err != nil {
+			return nil, fmt.Errorf("golden query #%d: %w", index+1, err)
+		}
+		if _, exists := seen[query.QueryKey]; exists {
+			return nil, fmt.Errorf("%w: %s", evalentity.ErrDuplicateQueryKey, query.QueryKey)
+		}
+		seen[query.QueryKey] = struct{}{}
+		queries = append(queries, query)
+	}
+
+	saved, err := s.repo.ReplaceGoldenQueries(ctx, scope, queries)
+	if err != nil {
+		return nil, fmt.Errorf("save evaluation query set: %w", err)
+	}
+	return saved, nil
+}
+
+// ListQuerySet 列出评测集全部 query。
+func (s *Service) ListQuerySet(ctx context.Context, input QuerySetInput) ([]*evalentity.GoldenQuery, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, err
+	}
+	scope, err := resolveScope(input)
+	if err != nil {
+		return nil, err
+	}
+	queries, err := s.repo.ListGoldenQueries(ctx, scope)
+	if err != nil {
+		return nil, fmt.Errorf("list evaluation query set: %w", err)
+	}
+	return queries, nil
+}
+
+// RunEvaluation 逐条回放评测集 query，保存 run 并与基线 run 对比。
+//
+// 单条 query 检索失败只记录在该 query 结果中并按 0 分计入汇总；知识库不存在、
+// 已禁用或 ctx 取消会直接中止整次评测，避免写入一份全是失败的 run。
+func (s *Service) RunEvaluation(ctx context.Context, input RunInput) (*RunResult, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, err
+	}
+	scope, err := resolveScope(input.QuerySetInput)
+	if err != nil {
+		return nil, err
+	}
+	queries, err := s.repo.ListGoldenQueries(ctx, scope)
+	if err != nil {
+		return nil, fmt.Errorf("list evaluation query set: %w", err)
+	}
+	if len(queries) == 0 {
+		return nil, evalentity.ErrEmptyQuerySet
+	}
+
+	topK := evalentity.NormalizeTopK(input.TopK)
+	cutoffs := evalentity.NormalizeCutoffs(input.Cutoffs, topK)
+	run := evalentity.Run{
+		OrganizationCode:  scope.OrganizationCode,
+		KnowledgeBaseCode: scope.KnowledgeBaseCode,
+		SetCode:           scope.SetCode,
+		Label:             strings.TrimSpace(input.Label),
+		TopK:              topK,
+		CreatedUID:        strings.TrimSpace(input.UserID),
+		StartedAt:         s.now(),
+	}
+	run.Results, err = s.replayQueries(ctx, run, queries, cutoffs)
+	if err != nil {
+		return nil, err
+	}
+	run.Metrics = evalservice.Summarize(run.Results, cutoffs)
+	run.FinishedAt = s.now()
+
+	baseline, hasBaseline, err := s.resolveBaseline(ctx, scope, input.BaselineRunID)
+	if err != nil {
+		return nil, err
+	}
+	if hasBaseline {
+		run.BaselineRunID = baseline.ID
+	}
+
+	saved, err := s.repo.InsertRun(ctx, run)
+	if err != nil {
+		return nil, fmt.Errorf("save evaluation run: %w", err)
+	}
+	result := &RunResult{Run: saved}
+	if hasBaseline {
+		result.Diff, err = evalservice.DiffRuns(baseline, saved)
+		if err != nil {
+			return nil, fmt.Errorf("diff evaluation runs: %w", err)
+		}
+	}
+	return result, nil
+}
+
+func (s *Service) replayQueries(
+	ctx context.Context,
+	run evalentity.Run,
+	queries []*evalentity.GoldenQuery,
+	cutoffs []int,
+) ([]evalentity.QueryResult, error) {
+	results := make([]evalentity.QueryResult, 0, len(queries))
+	for _, query := range queries {
+		if query == nil {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("evaluation run interrupted: %w", err)
+		}
+		hits, err := s.searcher.SearchForEvaluation(ctx, SearchInput{
+			OrganizationCode:  run.OrganizationCode,
+			UserID:            run.CreatedUID,
+			KnowledgeBaseCode: run.KnowledgeBaseCode,
+			Query:             query.Query,
+			TopK:              run.TopK,
+		})
+		switch {
+		case errors.Is(err, shared.ErrKnowledgeBaseNotFound), errors.Is(err, shared.ErrKnowledgeBaseDisabled):
+			return nil, fmt.Errorf("replay evaluation query %q: %w", query.QueryKey, err)
+		case err != nil:
+			s.logger.KnowledgeWarnContext(ctx, "Evaluation query replay failed",
+				"knowledge_base_code", run.KnowledgeBaseCode, "query_key", query.QueryKey, "error", err)
+			results = append(results, evalservice.FailedQueryResult(*query, cutoffs, err))
+		default:
+			results = append(results, evalservice.ScoreQuery(*query, hits, cutoffs))
+		}
+	}
+	return results, nil
+}
+
+func (s *Service) resolveBaseline(
+	ctx context.Context,
+	scope evalrepository.QuerySetScope,
+	baselineRunID int64,
+) (*evalentity.Run, bool, error) {
+	if baselineRunID > 0 {
+		baseline, err := s.repo.GetRun(ctx, scope.OrganizationCode, baselineRunID)
+		if err != nil {
+			return nil, false, fmt.Errorf("load baseline evaluation run: %w", err)
+		}
+		return baseline, true, nil
+	}
+
+	// 新 run 尚未写入，ID 上界取最大值即可拿到该评测集最近一次 run。
+	baseline, err := s.repo.GetPreviousRun(ctx, scope, math.MaxInt64)
+	if errors.Is(err, evalentity.ErrRunNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("load previous evaluation run: %w", err)
+	}
+	return baseline, true, nil
+}
+
+// ListRuns 按时间倒序列出评测 run 摘要。
+func (s *Service) ListRuns(ctx context.Context, input ListRunsInput) ([]*evalentity.Run, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, err
+	}
+	scope, err := resolveScope(input.QuerySetInput)
+	if err != nil {
+		return nil, err
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultListRunsLimit
+	}
+	limit32, err := convert.SafeIntToInt32(min(limit, maxListRunsLimit), "limit")
+	if err != nil {
+		return nil, fmt.Errorf("convert evaluation runs limit: %w", err)
+	}
+	offset32, err := convert.SafeIntToInt32(max(input.Offset, 0), "offset")
+	if err != nil {
+		return nil, fmt.Errorf("convert evaluation runs offset: %w", err)
+	}
+	runs, err := s.repo.ListRuns(ctx, evalrepository.ListRunsQuery{Scope: scope, Limit: limit32, Offset: offset32})
+	if err != nil {
+		return nil, fmt.Errorf("list evaluation runs: %w", err)
+	}
+	return runs, nil
+}
+
+// CompareRuns 对比同一评测集的两次 run；BaseRunID 为 0 时取目标 run 的上一次 run。
+func (s *Service) CompareRuns(ctx context.Context, input CompareRunsInput) (*evalentity.RunDiff, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, err
+	}
+	organizationCode := strings.TrimSpace(input.OrganizationCode)
+	if organizationCode == "" || input.TargetRunID <= 0 {
+		return nil, ErrEvaluationScopeRequired
+	}
+	target, err := s.repo.GetRun(ctx, organizationCode, input.TargetRunID)
+	if err != nil {
+		return nil, fmt.Errorf("load target evaluation run: %w", err)
+	}
+
+	var base *evalentity.Run
+	if input.BaseRunID > 0 {
+		base, err = s.repo.GetRun(ctx, organizationCode, input.BaseRunID)
+	} else {
+		base, err = s.repo.GetPreviousRun(ctx, evalrepository.QuerySetScope{
+			OrganizationCode:  target.OrganizationCode,
+			KnowledgeBaseCode: target.KnowledgeBaseCode,
+			SetCode:           target.SetCode,
+		}, target.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load base evaluation run: %w", err)
+	}
+
+	diff, err := evalservice.DiffRuns(base, target)
+	if err != nil {
+		return nil, fmt.Errorf("diff evaluation runs: %w", err)
+	}
+	return diff, nil
+}
+
+func (s *Service) ensureReady() error {
+	if s == nil || s.repo == nil || s.searcher == nil {
+		return ErrEvaluationUnavailable
+	}
+	return nil
+}
+
+func resolveScope(input QuerySetInput) (evalrepository.QuerySetScope, error) {
+	organizationCode := strings.TrimSpace(input.OrganizationCode)
+	knowledgeBaseCode := strings.TrimSpace(input.KnowledgeBaseCode)
+	if organizationCode == "" || knowledgeBaseCode == "" {
+		return evalrepository.QuerySetScope{}, ErrEvaluationScopeRequired
+	}
+	setCode, err := evalentity.NormalizeSetCode(input.SetCode)
+	if err != nil {
+		return evalrepository.QuerySetScope{}, fmt.Errorf("resolve evaluation set: %w", err)
+	}
+	return evalrepository.QuerySetScope{
+		OrganizationCode:  organizationCode,
+		KnowledgeBaseCode: knowledgeBaseCode,
+		SetCode:           setCode,
+	}, nil
+}
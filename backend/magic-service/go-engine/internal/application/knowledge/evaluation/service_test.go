@@ -0,0 +1,203 @@
+package evaluation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	evalapp "magic/internal/application/knowledge/evaluation"
+	evalentity "magic/internal/domain/knowledge/evaluation/entity"
+	evalrepository "magic/internal/domain/knowledge/evaluation/repository"
+	"magic/internal/domain/knowledge/shared"
+	"magic/internal/infrastructure/logging"
+)
+
+var errSearchTimeout = errors.New("search timeout")
+
+type memoryEvaluationRepo struct {
+	queries []*evalentity.GoldenQuery
+	runs    []*evalentity.Run
+}
+
+func (r *memoryEvaluationRepo) ReplaceGoldenQueries(
+	_ context.Context,
+	scope evalrepository.QuerySetScope,
+	queries []evalentity.GoldenQuery,
+) ([]*evalentity.GoldenQuery, error) {
+	r.queries = r.queries[:0]
+	for index, query := range queries {
+		query.ID = int64(index + 1)
+		query.OrganizationCode = scope.OrganizationCode
+		query.KnowledgeBaseCode = scope.KnowledgeBaseCode
+		query.SetCode = scope.SetCode
+		r.queries = append(r.queries, &query)
+	}
+	return r.queries, nil
+}
+
+func (r *memoryEvaluationRepo) ListGoldenQueries(context.Context, evalrepository.QuerySetScope) ([]*evalentity.GoldenQuery, error) {
+	return r.queries, nil
+}
+
+func (r *memoryEvaluationRepo) InsertRun(_ context.Context, run evalentity.Run) (*evalentity.Run, error) {
+	run.ID = int64(len(r.runs) + 1)
+	r.runs = append(r.runs, &run)
+	return &run, nil
+}
+
+func (r *memoryEvaluationRepo) GetRun(_ context.Context, organizationCode string, id int64) (*evalentity.Run, error) {
+	for _, run := range r.runs {
+		if run.ID == id && run.OrganizationCode == organizationCode {
+			return run, nil
+		}
+	}
+	return nil, evalentity.ErrRunNotFound
+}
+
+func (r *memoryEvaluationRepo) GetPreviousRun(_ context.Context, _ evalrepository.QuerySetScope, beforeID int64) (*evalentity.Run, error) {
+	for index := len(r.runs) - 1; index >= 0; index-- {
+		if r.runs[index].ID < beforeID {
+			return r.runs[index], nil
+		}
+	}
+	return nil, evalentity.ErrRunNotFound
+}
+
+func (r *memoryEvaluationRepo) ListRuns(context.Context, evalrepository.ListRunsQuery) ([]*evalentity.Run, error) {
+	return r.runs, nil
+}
+
+type similaritySearcherStub struct {
+	hits   map[string][]evalentity.RetrievedHit
+	errs   map[string]error
+	inputs []evalapp.SearchInput
+}
+
+func (s *similaritySearcherStub) SearchForEvaluation(_ context.Context, input evalapp.SearchInput) ([]evalentity.RetrievedHit, error) {
+	s.inputs = append(s.inputs, input)
+	return s.hits[input.Query], s.errs[input.Query]
+}
+
+func newEvaluationService(searcher *similaritySearcherStub) (*evalapp.Service, *memoryEvaluationRepo) {
+	repo := &memoryEvaluationRepo{}
+	return evalapp.NewService(repo, searcher, logging.New()), repo
+}
+
+func saveDefaultQuerySet(t *testing.T, service *evalapp.Service) {
+	t.Helper()
+
+	_, err := service.SaveQuerySet(context.Background(), evalapp.SaveQuerySetInput{
+		QuerySetInput: evalapp.QuerySetInput{OrganizationCode: "ORG1", KnowledgeBaseCode: "KB1"},
+		UserID:        "user-1",
+		Queries: []evalapp.GoldenQueryInput{
+			{Query: "refund", ExpectedDocumentCodes: []string{"DOC-R"}},
+			{Query: "shipping", ExpectedFragmentIDs: []int64{9}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SaveQuerySet returned error: %v", err)
+	}
+}
+
+func TestServiceSaveQuerySetValidatesQueries(t *testing.T) {
+	t.Parallel()
+
+	service, _ := newEvaluationService(&similaritySearcherStub{})
+	scope := evalapp.QuerySetInput{OrganizationCode: "ORG1", KnowledgeBaseCode: "KB1"}
+	_, err := service.SaveQuerySet(context.Background(), evalapp.SaveQuerySetInput{
+		QuerySetInput: scope,
+		Queries: []evalapp.GoldenQueryInput{
+			{QueryKey: "k", Query: "a", ExpectedDocumentCodes: []string{"D"}},
+			{QueryKey: "k", Query: "b", ExpectedDocumentCodes: []string{"D"}},
+		},
+	})
+	if !errors.Is(err, evalentity.ErrDuplicateQueryKey) {
+		t.Fatalf("expected duplicate key error, got %v", err)
+	}
+	_, err = service.SaveQuerySet(context.Background(), evalapp.SaveQuerySetInput{
+		QuerySetInput: scope,
+		Queries:       []evalapp.GoldenQueryInput{{Query: "a"}},
+	})
+	if !errors.Is(err, evalentity.ErrGoldenQueryWithoutExpectation) {
+		t.Fatalf("expected missing expectation error, got %v", err)
+	}
+	if _, err := service.ListQuerySet(context.Background(), evalapp.QuerySetInput{OrganizationCode: "ORG1"}); !errors.Is(err, evalapp.ErrEvaluationScopeRequired) {
+		t.Fatalf("expected scope error, got %v", err)
+	}
+}
+
+func TestServiceRunEvaluationScoresAndDiffsAgainstPreviousRun(t *testing.T) {
+	t.Parallel()
+
+	searcher := &similaritySearcherStub{
+		hits: map[string][]evalentity.RetrievedHit{
+			"refund": {{FragmentID: 1, DocumentCode: "DOC-X"}, {FragmentID: 2, DocumentCode: "DOC-R"}},
+		},
+		errs: map[string]error{"shipping": errSearchTimeout},
+	}
+	service, repo := newEvaluationService(searcher)
+	saveDefaultQuerySet(t, service)
+
+	first, err := service.RunEvaluation(context.Background(), evalapp.RunInput{
+		QuerySetInput: evalapp.QuerySetInput{OrganizationCode: "ORG1", KnowledgeBaseCode: "KB1"},
+		Label:         "baseline",
+		TopK:          5,
+	})
+	if err != nil {
+		t.Fatalf("first RunEvaluation returned error: %v", err)
+	}
+	if first.Diff != nil || first.Run.Metrics.QueryCount != 2 || first.Run.Metrics.FailedCount != 1 || first.Run.Metrics.MRR != 0.25 {
+		t.Fatalf("unexpected first run: %#v", first.Run.Metrics)
+	}
+	if searcher.inputs[0].TopK != 5 || searcher.inputs[0].KnowledgeBaseCode != "KB1" || searcher.inputs[0].OrganizationCode != "ORG1" {
+		t.Fatalf("unexpected search input: %#v", searcher.inputs[0])
+	}
+
+	searcher.hits["refund"] = []evalentity.RetrievedHit{{FragmentID: 2, DocumentCode: "DOC-R"}}
+	second, err := service.RunEvaluation(context.Background(), evalapp.RunInput{
+		QuerySetInput: evalapp.QuerySetInput{OrganizationCode: "ORG1", KnowledgeBaseCode: "KB1"},
+		TopK:          5,
+	})
+	if err != nil {
+		t.Fatalf("second RunEvaluation returned error: %v", err)
+	}
+	if second.Run.BaselineRunID != first.Run.ID || second.Diff == nil {
+		t.Fatalf("expected diff against previous run, got %#v", second)
+	}
+	if len(second.Diff.Improved) != 1 || second.Diff.Improved[0].QueryKey != "refund" || second.Diff.MRR.Delta != 0.25 {
+		t.Fatalf("unexpected diff: %#v", second.Diff)
+	}
+
+	diff, err := service.CompareRuns(context.Background(), evalapp.CompareRunsInput{OrganizationCode: "ORG1", TargetRunID: second.Run.ID})
+	if err != nil || diff.BaseRunID != first.Run.ID {
+		t.Fatalf("CompareRuns returned diff=%#v err=%v", diff, err)
+	}
+	if len(repo.runs) != 2 {
+		t.Fatalf("expected two stored runs, got %d", len(repo.runs))
+	}
+}
+
+func TestServiceRunEvaluationAbortsWhenKnowledgeBaseUnavailable(t *testing.T) {
+	t.Parallel()
+
+	searcher := &similaritySearcherStub{errs: map[string]error{"refund": shared.ErrKnowledgeBaseDisabled}}
+	service, repo := newEvaluationService(searcher)
+	saveDefaultQuerySet(t, service)
+
+	_, err := service.RunEvaluation(context.Background(), evalapp.RunInput{
+		QuerySetInput: evalapp.QuerySetInput{OrganizationCode: "ORG1", KnowledgeBaseCode: "KB1"},
+	})
+	if !errors.Is(err, shared.ErrKnowledgeBaseDisabled) {
+		t.Fatalf("expected disabled knowledge base error, got %v", err)
+	}
+	if len(repo.runs) != 0 {
+		t.Fatalf("expected no stored run, got %d", len(repo.runs))
+	}
+
+	empty, _ := newEvaluationService(searcher)
+	if _, err := empty.RunEvaluation(context.Background(), evalapp.RunInput{
+		QuerySetInput: evalapp.QuerySetInput{OrganizationCode: "ORG1", KnowledgeBaseCode: "KB1"},
+	}); !errors.Is(err, evalentity.ErrEmptyQuerySet) {
+		t.Fatalf("expected empty query set error, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+// Package feedback 提供知识库检索结果相关性反馈的应用服务。
+package feedback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	feedbackentity "magic/internal/domain/knowledge/feedback/entity"
+	feedbackrepository "magic/internal/domain/knowledge/feedback/repository"
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/shared"
+	"magic/internal/infrastructure/logging"
+)
+
+var (
+	// ErrFeedbackUnavailable 表示反馈服务未完成装配。
+	ErrFeedbackUnavailable = errors.New("knowledge feedback unavailable")
+	// ErrFeedbackTargetMismatch 表示片段不属于给定的知识库或文档。
+	ErrFeedbackTargetMismatch = errors.New("feedback fragment does not belong to the given knowledge base or document")
+	// ErrInvalidSignal 表示反馈信号类型不受支持。
+	ErrInvalidSignal = feedbackentity.ErrInvalidSignal
+	// ErrRequestIDRequired 表示反馈缺少关联的检索请求 ID。
+	ErrRequestIDRequired = feedbackentity.ErrRequestIDRequired
+	// ErrRequestIDTooLong 表示检索请求 ID 超过长度上限。
+	ErrRequestIDTooLong = feedbackentity.ErrRequestIDTooLong
+	// ErrTargetRequired 表示反馈缺少知识库或文档编码。
+	ErrTargetRequired = feedbackentity.ErrTargetRequired
+)
+
+type fragmentFinder interface {
+	FindByID(ctx context.Context, id int64) (*fragmodel.KnowledgeBaseFragment, error)
+}
+
+// SubmitInput 表示一次反馈提交。
+//
+// RequestID 为产生该结果的相似度检索请求 ID；提供 FragmentID 时 DocumentCode 可省略，由片段补齐。
+type SubmitInput struct {
+	OrganizationCode  string
+	KnowledgeBaseCode string
+	DocumentCode      string
+	FragmentID        int64
+	RequestID         string
+	UserID            string
+	Signal            string
+}
+
+// Service 负责校验并落库相关性反馈。
+type Service struct {
+	repo      feedbackrepository.Store
+	fragments fragmentFinder
+	logger    *logging.SugaredLogger
+	now       func() time.Time
+}
+
+// NewService 创建相关性反馈应用服务。
+func NewService(repo feedbackrepository.Store, fragments fragmentFinder, logger *logging.SugaredLogger) *Service {
+	return &Service{repo: repo, fragments: fragments, logger: logger, now: time.Now}
+}
+
+// Submit 记录一条反馈；同一请求、用户、目标与信号重复提交时返回 false 且不重复计数。
+func (s *Service) Submit(ctx context.Context, input SubmitInput) (bool, error) {
+	if s == nil || s.repo == nil {
+		return false, ErrFeedbackUnavailable
+	}
+	feedback := &feedbackentity.Feedback{
+		OrganizationCode:  input.OrganizationCode,
+		KnowledgeBaseCode: input.KnowledgeBaseCode,
+		DocumentCode:      input.DocumentCode,
+		FragmentID:        input.FragmentID,
+		RequestID:         input.RequestID,
+		UserID:            input.UserID,
+		Signal:            input.Signal,
+		CreatedAt:         s.now(),
+	}
+	if err := s.resolveFragmentTarget(ctx, feedback); err != nil {
+		return false, err
+	}
+	if err := feedback.Normalize(); err != nil {
+		return false, fmt.Errorf("validate feedback: %w", err)
+	}
+
+	recorded, err := s.repo.SaveFeedback(ctx, feedback)
+	if err != nil {
+		return false, fmt.Errorf("save feedback: %w", err)
+	}
+	return recorded, nil
+}
+
+// resolveFragmentTarget 校验片段归属，并在调用方未给出文档编码时用片段所属文档补齐。
+func (s *Service) resolveFragmentTarget(ctx context.Context, feedback *feedbackentity.Feedback) error {
+	if feedback.FragmentID <= 0 || s.fragments == nil {
+		return nil
+	}
+	fragment, err := s.fragments.FindByID(ctx, feedback.FragmentID)
+	if err != nil {
+		return fmt.Errorf("find feedback fragment: %w", err)
+	}
+	if fragment == nil {
+		return shared.ErrFragmentNotFound
+	}
+	knowledgeBaseCode := strings.TrimSpace(feedback.KnowledgeBaseCode)
+	documentCode := strings.TrimSpace(feedback.DocumentCode)
+	if fragment.KnowledgeCode != knowledgeBaseCode || (documentCode != "" && fragment.DocumentCode != documentCode) {
+		return ErrFeedbackTargetMismatch
+	}
+	feedback.DocumentCode = fragment.DocumentCode
+	return nil
+}
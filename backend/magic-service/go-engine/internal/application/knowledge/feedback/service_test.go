@@ -0,0 +1,103 @@
+package feedback_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	feedbackapp "magic/internal/application/knowledge/feedback"
+	feedbackentity "magic/internal/domain/knowledge/feedback/entity"
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/infrastructure/logging"
+)
+
+type feedbackStoreStub struct {
+	saved    []*feedbackentity.Feedback
+	recorded bool
+}
+
+func (s *feedbackStoreStub) SaveFeedback(_ context.Context, feedback *feedbackentity.Feedback) (bool, error) {
+	s.saved = append(s.saved, feedback)
+	return s.recorded, nil
+}
+
+type fragmentFinderStub map[int64]*fragmodel.KnowledgeBaseFragment
+
+func (s fragmentFinderStub) FindByID(_ context.Context, id int64) (*fragmodel.KnowledgeBaseFragment, error) {
+	return s[id], nil
+}
+
+func newFeedbackService(store *feedbackStoreStub) *feedbackapp.Service {
+	fragments := fragmentFinderStub{
+		7: {ID: 7, KnowledgeCode: "KB1", DocumentCode: "DOC1"},
+	}
+	return feedbackapp.NewService(store, fragments, logging.New())
+}
+
+func TestServiceSubmitFillsDocumentFromFragment(t *testing.T) {
+	t.Parallel()
+
+	store := &feedbackStoreStub{recorded: true}
+	recorded, err := newFeedbackService(store).Submit(context.Background(), feedbackapp.SubmitInput{
+		OrganizationCode:  "ORG1",
+		KnowledgeBaseCode: "KB1",
+		FragmentID:        7,
+		RequestID:         "req-1",
+		UserID:            "u1",
+		Signal:            feedbackentity.SignalAnswered,
+	})
+	if err != nil || !recorded {
+		t.Fatalf("Submit() = %v, %v", recorded, err)
+	}
+	if len(store.saved) != 1 || store.saved[0].DocumentCode != "DOC1" || store.saved[0].CreatedAt.IsZero() {
+		t.Fatalf("unexpected saved feedback: %#v", store.saved)
+	}
+}
+
+func TestServiceSubmitRejectsInvalidFeedback(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		input feedbackapp.SubmitInput
+		want  error
+	}{
+		{
+			name: "fragment from another document",
+			input: feedbackapp.SubmitInput{
+				OrganizationCode: "ORG1", KnowledgeBaseCode: "KB1", DocumentCode: "DOC2", FragmentID: 7,
+				RequestID: "req-1", Signal: feedbackentity.SignalThumbsUp,
+			},
+			want: feedbackapp.ErrFeedbackTargetMismatch,
+		},
+		{
+			name: "unknown signal",
+			input: feedbackapp.SubmitInput{
+				OrganizationCode: "ORG1", KnowledgeBaseCode: "KB1", DocumentCode: "DOC1",
+				RequestID: "req-1", Signal: "love",
+			},
+			want: feedbackapp.ErrInvalidSignal,
+		},
+		{
+			name: "missing request id",
+			input: feedbackapp.SubmitInput{
+				OrganizationCode: "ORG1", KnowledgeBaseCode: "KB1", DocumentCode: "DOC1",
+				Signal: feedbackentity.SignalThumbsDown,
+			},
+			want: feedbackapp.ErrRequestIDRequired,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := &feedbackStoreStub{}
+			if _, err := newFeedbackService(store).Submit(context.Background(), tc.input); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			if len(store.saved) != 0 {
+				t.Fatalf("expected nothing saved, got %#v", store.saved)
+			}
+		})
+	}
+}
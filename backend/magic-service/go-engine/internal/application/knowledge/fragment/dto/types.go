@@ -102,6 +102,21 @@ type SimilarityResultDTO struct {
 	// 重新投影顶层 doc_type。DocumentType 继续表示内部精确文件类型。
 	DocType    int    `json:"doc_type"`
 	BusinessID string `json:"business_id"`
+	// ExpandedContext 仅在请求开启上下文扩展时返回。
+	ExpandedContext *ExpandedContextDTO `json:"expanded_context,omitempty"`
+}
+
+// ExpandedContextDTO 表示命中片段扩展后的父章节或相邻片段上下文。
+type ExpandedContextDTO struct {
+	Mode            string  `json:"mode"`
+	Content         string  `json:"content"`
+	FragmentIDs     []int64 `json:"fragment_ids"`
+	StartChunkIndex int     `json:"start_chunk_index"`
+	EndChunkIndex   int     `json:"end_chunk_index"`
+	TokenCount      int     `json:"token_count"`
+	Truncated       bool    `json:"truncated,omitempty"`
+	// MergedIntoID 非零表示该命中的上下文已被排名更高的命中覆盖，Content 为空。
+	MergedIntoID int64 `json:"merged_into_id,omitempty"`
 }
 
 // AgentSimilarityResultDTO 表示数字员工维度的知识检索结果。
@@ -112,6 +127,33 @@ type AgentSimilarityResultDTO struct {
 	Hits        []*SimilarityResultDTO `json:"hits"`
 }
 
+// AgentContextAssemblyResultDTO 表示按 token 预算组装好的数字员工检索上下文。
+type AgentContextAssemblyResultDTO struct {
+	QueryUsed      string                `json:"query_used"`
+	Strategy       string                `json:"strategy"`
+	Model          string                `json:"model"`
+	MaxTokens      int                   `json:"max_tokens"`
+	TokenCount     int                   `json:"token_count"`
+	Context        string                `json:"context"`
+	Citations      []*ContextCitationDTO `json:"citations"`
+	CandidateCount int                   `json:"candidate_count"`
+	DroppedCount   int                   `json:"dropped_count"`
+}
+
+// ContextCitationDTO 表示上下文中 [Index] 引用标记对应的来源片段。
+type ContextCitationDTO struct {
+	Index         int     `json:"index"`
+	CitationID    string  `json:"citation_id"`
+	FragmentID    int64   `json:"fragment_id"`
+	KnowledgeCode string  `json:"knowledge_code"`
+	DocumentCode  string  `json:"document_code"`
+	DocumentName  string  `json:"document_name"`
+	SectionPath   string  `json:"section_path,omitempty"`
+	FileKey       string  `json:"file_key,omitempty"`
+	Score         float64 `json:"score"`
+	TokenCount    int     `json:"token_count"`
+}
+
 // CreateFragmentInput 表示创建片段请求。
 type CreateFragmentInput struct {
 	OrganizationCode string
@@ -153,6 +195,21 @@ type SimilarityInput struct {
 	BusinessParams   *ctxmeta.BusinessParams
 	Filters          *SimilarityFilterInput
 	Debug            bool
+	ContextExpansion *ContextExpansionInput
+}
+
+const (
+	// ContextExpansionModeParent 表示按层级父节点扩展到整个章节。
+	ContextExpansionModeParent = "parent"
+	// ContextExpansionModeNeighbor 表示按 chunk_index 扩展到前后 N 个相邻片段。
+	ContextExpansionModeNeighbor = "neighbor"
+)
+
+// ContextExpansionInput 表示检索命中的小到大上下文扩展参数，为空时不扩展。
+type ContextExpansionInput struct {
+	Mode           string
+	NeighborWindow int
+	MaxTokens      int
 }
 
 // RuntimeSimilarityInput 表示 flow/teamshare runtime 多知识库检索请求。
@@ -166,6 +223,9 @@ type RuntimeSimilarityInput struct {
 	MetadataFilter   map[string]any
 	Debug            bool
 	BusinessParams   *ctxmeta.BusinessParams
+	ContextExpansion *ContextExpansionInput
+	// SkipQueryLog 为 true 时不记录检索日志，用于评测回放等非真实用户检索。
+	SkipQueryLog bool
 }
 
 // FlowVectorSimilarityByUserInput 表示按用户可读 flow 向量知识库检索请求。
@@ -214,6 +274,30 @@ type AgentSimilarityInput struct {
 	BusinessParams   *ctxmeta.BusinessParams
 }
 
+const (
+	// ContextAssemblyStrategyGreedy 表示按分数从高到低装填。
+	ContextAssemblyStrategyGreedy = "greedy"
+	// ContextAssemblyStrategyDiversity 表示按文档轮转装填，避免单篇文档占满预算。
+	ContextAssemblyStrategyDiversity = "diversity"
+	// ContextAssemblyStrategyMMR 表示按最大边际相关性装填，兼顾相关性与内容差异。
+	ContextAssemblyStrategyMMR = "mmr"
+)
+
+// AgentContextAssemblyInput 表示数字员工维度按 token 预算组装检索上下文的请求。
+type AgentContextAssemblyInput struct {
+	OrganizationCode string
+	UserID           string
+	AgentCode        string
+	Query            string
+	MaxTokens        int
+	Model            string
+	Strategy         string
+	MaxPerDocument   int
+	MMRLambda        *float64
+	CandidateTopK    int
+	BusinessParams   *ctxmeta.BusinessParams
+}
+
 // SimilarityFilterInput 表示片段相似度过滤条件。
 type SimilarityFilterInput struct {
 	DocumentCodes []string                  `json:"document_codes"`
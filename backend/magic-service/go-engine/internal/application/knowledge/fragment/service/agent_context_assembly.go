@@ -0,0 +1,329 @@
+package fragapp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	fragdto "magic/internal/application/knowledge/fragment/dto"
+	kshared "magic/internal/domain/knowledge/shared"
+	"magic/internal/pkg/tokenizer"
+)
+
+const (
+	defaultContextAssemblyMaxTokens         = 2000
+	maxContextAssemblyMaxTokens             = 32000
+	defaultContextAssemblyCandidateTopK     = 20
+	maxContextAssemblyCandidateTopK         = 50
+	defaultContextAssemblyDiversityPerDoc   = 2
+	defaultContextAssemblyMMRLambda         = 0.7
+	contextAssemblyBlockSeparator           = "\n\n"
+	contextAssemblySectionPathSeparator     = " › "
+	contextAssemblyMetadataSectionPathField = "section_path"
+)
+
+type contextAssemblyOptions struct {
+	maxTokens      int
+	model          string
+	strategy       string
+	maxPerDocument int
+	mmrLambda      float64
+	candidateTopK  int
+}
+
+// contextAssemblyCandidate 是去重后的候选片段，tokens 仅用于 MMR 相似度计算。
+type contextAssemblyCandidate struct {
+	hit         *fragdto.SimilarityResultDTO
+	documentKey similarityDocumentKey
+	relevance   float64
+	tokens      map[int]struct{}
+}
+
+// AssembleContextByAgent 按数字员工维度检索并在 token 预算内组装带引用标记的上下文块。
+func (s *FragmentAppService) AssembleContextByAgent(
+	ctx context.Context,
+	input *fragdto.AgentContextAssemblyInput,
+) (*fragdto.AgentContextAssemblyResultDTO, error) {
+	if input == nil {
+		return nil, kshared.ErrKnowledgeBaseNotFound
+	}
+	normalizedInput, err := validateAgentSimilarityInput(&fragdto.AgentSimilarityInput{
+		OrganizationCode: input.OrganizationCode,
+		UserID:           input.UserID,
+		AgentCode:        input.AgentCode,
+		Query:            input.Query,
+		BusinessParams:   input.BusinessParams,
+	})
+	if err != nil {
+		return nil, err
+	}
+	options := s.normalizeContextAssemblyOptions(input)
+	encoder, err := s.tokenizer.EncoderForModel(options.model)
+	if err != nil {
+		return nil, fmt.Errorf("resolve context assembly tokenizer: %w", err)
+	}
+
+	hits, err := s.searchAgentSimilarityHits(ctx, normalizedInput, options.candidateTopK)
+	if err != nil {
+		return nil, err
+	}
+	candidates := dedupeContextAssemblyCandidates(hits, encoder, options.strategy == fragdto.ContextAssemblyStrategyMMR)
+	ordered, capped := orderContextAssemblyCandidates(candidates, options)
+	result := packContextAssemblyBlocks(ordered, encoder, options.maxTokens)
+	result.QueryUsed = normalizedInput.query
+	result.Strategy = options.strategy
+	result.Model = encoder.ResolvedModel()
+	result.MaxTokens = options.maxTokens
+	result.CandidateCount = len(candidates)
+	result.DroppedCount += capped
+	return result, nil
+}
+
+func (s *FragmentAppService) normalizeContextAssemblyOptions(input *fragdto.AgentContextAssemblyInput) contextAssemblyOptions {
+	options := contextAssemblyOptions{
+		maxTokens:      input.MaxTokens,
+		model:          strings.TrimSpace(input.Model),
+		strategy:       strings.ToLower(strings.TrimSpace(input.Strategy)),
+		maxPerDocument: max(input.MaxPerDocument, 0),
+		mmrLambda:      defaultContextAssemblyMMRLambda,
+		candidateTopK:  input.CandidateTopK,
+	}
+	if options.maxTokens <= 0 {
+		options.maxTokens = defaultContextAssemblyMaxTokens
+	}
+	options.maxTokens = min(options.maxTokens, maxContextAssemblyMaxTokens)
+	if options.model == "" {
+		options.model = resolveDefaultEmbeddingModel(s.defaultEmbeddingModel)
+	}
+	switch options.strategy {
+	case fragdto.ContextAssemblyStrategyDiversity:
+		if options.maxPerDocument == 0 {
+			options.maxPerDocument = defaultContextAssemblyDiversityPerDoc
+		}
+	case fragdto.ContextAssemblyStrategyMMR:
+		if input.MMRLambda != nil {
+			options.mmrLambda = min(max(*input.MMRLambda, 0), 1)
+		}
+	default:
+		options.strategy = fragdto.ContextAssemblyStrategyGreedy
+	}
+	if options.candidateTopK <= 0 {
+		options.candidateTopK = defaultContextAssemblyCandidateTopK
+	}
+	options.candidateTopK = min(options.candidateTopK, maxContextAssemblyCandidateTopK)
+	return options
+}
+
+// dedupeContextAssemblyCandidates 按片段和归一化正文去重，hits 需已按分数降序排列。
+func dedupeContextAssemblyCandidates(
+	hits []*fragdto.SimilarityResultDTO,
+	encoder *tokenizer.Encoder,
+	withTokens bool,
+) []*contextAssemblyCandidate {
+	seenCitations := make(map[string]struct{}, len(hits))
+	seenContents := make(map[string]struct{}, len(hits))
+	candidates := make([]*contextAssemblyCandidate, 0, len(hits))
+	topScore := 0.0
+	for _, hit := range hits {
+		if hit == nil {
+			continue
+		}
+		content := strings.Join(strings.Fields(hit.Content), " ")
+		if content == "" {
+			continue
+		}
+		hit.CitationID = buildSimilarityCitationID(hit)
+		if _, ok := seenCitations[hit.CitationID]; ok {
+			continue
+		}
+		if _, ok := seenContents[content]; ok {
+			continue
+		}
+		seenCitations[hit.CitationID] = struct{}{}
+		seenContents[content] = struct{}{}
+		topScore = max(topScore, hit.Score)
+		candidate := &contextAssemblyCandidate{
+			hit:         hit,
+			documentKey: similarityDocumentKey{knowledgeBaseCode: hit.KnowledgeBaseCode, documentCode: hit.DocumentCode},
+		}
+		if withTokens {
+			candidate.tokens = contextAssemblyTokenSet(encoder.Encode(content))
+		}
+		candidates = append(candidates, candidate)
+	}
+	for _, candidate := range candidates {
+		candidate.relevance = 1
+		if topScore > 0 {
+			candidate.relevance = candidate.hit.Score / topScore
+		}
+	}
+	return candidates
+}
+
+// orderContextAssemblyCandidates 返回装填顺序以及因单文档上限被丢弃的数量。
+func orderContextAssemblyCandidates(
+	candidates []*contextAssemblyCandidate,
+	options contextAssemblyOptions,
+) ([]*contextAssemblyCandidate, int) {
+	var ordered []*contextAssemblyCandidate
+	switch options.strategy {
+	case fragdto.ContextAssemblyStrategyDiversity:
+		ordered = orderContextAssemblyByDocumentRoundRobin(candidates)
+	case fragdto.ContextAssemblyStrategyMMR:
+		ordered = orderContextAssemblyByMMR(candidates, options.mmrLambda)
+	default:
+		ordered = candidates
+	}
+	if options.maxPerDocument <= 0 {
+		return ordered, 0
+	}
+	perDocument := make(map[similarityDocumentKey]int, len(ordered))
+	capped := make([]*contextAssemblyCandidate, 0, len(ordered))
+	for _, candidate := range ordered {
+		if perDocument[candidate.documentKey] >= options.maxPerDocument {
+			continue
+		}
+		perDocument[candidate.documentKey]++
+		capped = append(capped, candidate)
+	}
+	return capped, len(ordered) - len(capped)
+}
+
+func orderContextAssemblyByDocumentRoundRobin(candidates []*contextAssemblyCandidate) []*contextAssemblyCandidate {
+	groups := make([][]*contextAssemblyCandidate, 0, len(candidates))
+	groupIndex := make(map[similarityDocumentKey]int, len(candidates))
+	for _, candidate := range candidates {
+		index, ok := groupIndex[candidate.documentKey]
+		if !ok {
+			index = len(groups)
+			groupIndex[candidate.documentKey] = index
+			groups = append(groups, nil)
+		}
+		groups[index] = append(groups[index], candidate)
+	}
+	ordered := make([]*contextAssemblyCandidate, 0, len(candidates))
+	for round := 0; len(ordered) < len(candidates); round++ {
+		for _, group := range groups {
+			if round < len(group) {
+				ordered = append(ordered, group[round])
+			}
+		}
+	}
+	return ordered
+}
+
+// orderContextAssemblyByMMR 使用 token 集合的 Jaccard 相似度近似片段间冗余度。
+func orderContextAssemblyByMMR(candidates []*contextAssemblyCandidate, lambda float64) []*contextAssemblyCandidate {
+	remaining := append([]*contextAssemblyCandidate(nil), candidates...)
+	ordered := make([]*contextAssemblyCandidate, 0, len(candidates))
+	for len(remaining) > 0 {
+		bestIndex := 0
+		bestScore := 0.0
+		for i, candidate := range remaining {
+			redundancy := 0.0
+			for _, selected := range ordered {
+				redundancy = max(redundancy, contextAssemblyJaccard(candidate.tokens, selected.tokens))
+			}
+			score := lambda*candidate.relevance - (1-lambda)*redundancy
+			if i == 0 || score > bestScore {
+				bestIndex, bestScore = i, score
+			}
+		}
+		ordered = append(ordered, remaining[bestIndex])
+		remaining = append(remaining[:bestIndex], remaining[bestIndex+1:]...)
+	}
+	return ordered
+}
+
+// packContextAssemblyBlocks 依次装填候选块，放不下的块跳过并继续尝试更短的后续块。
+func packContextAssemblyBlocks(
+	ordered []*contextAssemblyCandidate,
+	encoder *tokenizer.Encoder,
+	maxTokens int,
+) *fragdto.AgentContextAssemblyResultDTO {
+	separatorTokens := encoder.CountTokens(contextAssemblyBlockSeparator)
+	blocks := make([]string, 0, len(ordered))
+	result := &fragdto.AgentContextAssemblyResultDTO{Citations: []*fragdto.ContextCitationDTO{}}
+	for _, candidate := range ordered {
+		index := len(blocks) + 1
+		block := buildContextAssemblyBlock(index, candidate.hit)
+		blockTokens := encoder.CountTokens(block)
+		cost := blockTokens
+		if len(blocks) > 0 {
+			cost += separatorTokens
+		}
+		if result.TokenCount+cost > maxTokens {
+			result.DroppedCount++
+			continue
+		}
+		blocks = append(blocks, block)
+		result.TokenCount += cost
+		result.Citations = append(result.Citations, newContextCitationDTO(index, candidate.hit, blockTokens))
+	}
+	result.Context = strings.Join(blocks, contextAssemblyBlockSeparator)
+	return result
+}
+
+func buildContextAssemblyBlock(index int, hit *fragdto.SimilarityResultDTO) string {
+	var builder strings.Builder
+	builder.WriteString("[")
+	builder.WriteString(strconv.Itoa(index))
+	builder.WriteString("]")
+	if name := strings.TrimSpace(hit.DocumentName); name != "" {
+		builder.WriteString(" ")
+		builder.WriteString(name)
+	}
+	content := strings.TrimSpace(hit.Content)
+	// 展示正文已带章节标题时不在引用头重复输出。
+	if sectionPath := contextAssemblySectionPath(hit); sectionPath != "" && !strings.HasPrefix(content, sectionPath) {
+		builder.WriteString(contextAssemblySectionPathSeparator)
+		builder.WriteString(sectionPath)
+	}
+	builder.WriteString("\n")
+	builder.WriteString(content)
+	return builder.String()
+}
+
+func newContextCitationDTO(index int, hit *fragdto.SimilarityResultDTO, tokenCount int) *fragdto.ContextCitationDTO {
+	return &fragdto.ContextCitationDTO{
+		Index:         index,
+		CitationID:    hit.CitationID,
+		FragmentID:    hit.ID,
+		KnowledgeCode: hit.KnowledgeBaseCode,
+		DocumentCode:  hit.DocumentCode,
+		DocumentName:  hit.DocumentName,
+		SectionPath:   contextAssemblySectionPath(hit),
+		FileKey:       hit.FileKey,
+		Score:         hit.Score,
+		TokenCount:    tokenCount,
+	}
+}
+
+func contextAssemblySectionPath(hit *fragdto.SimilarityResultDTO) string {
+	return metadataStringValue(hit.Metadata, contextAssemblyMetadataSectionPathField)
+}
+
+func contextAssemblyTokenSet(tokens []int) map[int]struct{} {
+	set := make(map[int]struct{}, len(tokens))
+	for _, token := range tokens {
+		set[token] = struct{}{}
+	}
+	return set
+}
+
+func contextAssemblyJaccard(a, b map[int]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	intersection := 0
+	for token := range a {
+		if _, ok := b[token]; ok {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	fragdto "magic/internal/application/knowledge/fragment/dto"
 	kbaccess "magic/internal/domain/knowledge/access/service"
 	kbentity "magic/internal/domain/knowledge/knowledgebase/entity"
 	kbrepository "magic/internal/domain/knowledge/knowledgebase/repository"
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
 	kshared "magic/internal/domain/knowledge/shared"
 	"magic/internal/pkg/ctxmeta"
 )
@@ -26,6 +28,8 @@ type agentSimilarityInput struct {
 	agentCode        string
 	query            string
 	businessParams   *ctxmeta.BusinessParams
+	// searchedKnowledgeBaseCodes 记录实际检索的绑定知识库，供 query 日志统计零结果检索。
+	searchedKnowledgeBaseCodes []string
 }
 
 // SimilarityByAgent 按数字员工维度检索其已绑定知识库中的知识片段。
@@ -37,40 +41,77 @@ func (s *FragmentAppService) SimilarityByAgent(
 	if err != nil {
 		return nil, err
 	}
+	startedAt := time.Now()
+	hits, err := s.searchAgentSimilarityHits(ctx, normalizedInput, employeeKnowledgeTopK)
+	if err != nil {
+		return nil, err
+	}
+	if len(hits) > employeeKnowledgeTopK {
+		hits = hits[:employeeKnowledgeTopK]
+	}
+	s.recordQueryLog(ctx, querylogentity.Search{
+		OrganizationCode:   normalizedInput.organizationCode,
+		UserID:             normalizedInput.userID,
+		Source:             querylogentity.SourceSimilarityByAgent,
+		AgentCode:          normalizedInput.agentCode,
+		Query:              normalizedInput.query,
+		KnowledgeBaseCodes: normalizedInput.searchedKnowledgeBaseCodes,
+	}, hits, startedAt)
+	if len(hits) == 0 {
+		return &fragdto.AgentSimilarityResultDTO{
+			QueryUsed: normalizedInput.query,
+			Hits:      []*fragdto.SimilarityResultDTO{},
+		}, nil
+	}
+
+	for _, hit := range hits {
+		if hit == nil {
+			continue
+		}
+		hit.CitationID = buildSimilarityCitationID(hit)
+	}
+
+	return &fragdto.AgentSimilarityResultDTO{
+		QueryUsed:   normalizedInput.query,
+		HitCount:    len(hits),
+		ContextText: buildAgentSimilarityContextText(hits),
+		Hits:        hits,
+	}, nil
+}
+
+// searchAgentSimilarityHits 校验数字员工可访问后检索其绑定知识库，结果按分数降序排列。
+func (s *FragmentAppService) searchAgentSimilarityHits(
+	ctx context.Context,
+	input *agentSimilarityInput,
+	topK int,
+) ([]*fragdto.SimilarityResultDTO, error) {
 	if err := s.ensureAgentSimilarityDependencies(); err != nil {
 		return nil, err
 	}
-	if err := s.ensureAgentAccessible(
-		ctx,
-		normalizedInput.organizationCode,
-		normalizedInput.userID,
-		normalizedInput.agentCode,
-	); err != nil {
+	if err := s.ensureAgentAccessible(ctx, input.organizationCode, input.userID, input.agentCode); err != nil {
 		return nil, err
 	}
 
-	knowledgeBases, err := s.listBoundKnowledgeBasesByAgent(
-		ctx,
-		normalizedInput.organizationCode,
-		normalizedInput.userID,
-		normalizedInput.agentCode,
-	)
+	knowledgeBases, err := s.listBoundKnowledgeBasesByAgent(ctx, input.organizationCode, input.userID, input.agentCode)
 	if err != nil {
 		return nil, err
 	}
 	if len(knowledgeBases) == 0 {
-		return &fragdto.AgentSimilarityResultDTO{
-			QueryUsed: normalizedInput.query,
-			Hits:      []*fragdto.SimilarityResultDTO{},
-		}, nil
+		return nil, nil
+	}
+	for _, kb := range knowledgeBases {
+		if kb != nil {
+			input.searchedKnowledgeBaseCodes = append(input.searchedKnowledgeBaseCodes, kb.Code)
+		}
 	}
 
 	hits, err := s.collectAgentSimilarityHits(
 		ctx,
-		normalizedInput.organizationCode,
-		normalizedInput.query,
+		input.organizationCode,
+		input.query,
 		knowledgeBases,
-		normalizedInput.businessParams,
+		input.businessParams,
+		topK,
 	)
 	if err != nil {
 		return nil, err
@@ -92,23 +133,7 @@ func (s *FragmentAppService) SimilarityByAgent(
 			return strings.Compare(a.CitationID, b.CitationID)
 		}
 	})
-
-	if len(hits) > employeeKnowledgeTopK {
-		hits = hits[:employeeKnowledgeTopK]
-	}
-	for _, hit := range hits {
-		if hit == nil {
-			continue
-		}
-		hit.CitationID = buildSimilarityCitationID(hit)
-	}
-
-	return &fragdto.AgentSimilarityResultDTO{
-		QueryUsed:   normalizedInput.query,
-		HitCount:    len(hits),
-		ContextText: buildAgentSimilarityContextText(hits),
-		Hits:        hits,
-	}, nil
+	return hits, nil
 }
 
 func validateAgentSimilarityInput(input *fragdto.AgentSimilarityInput) (*agentSimilarityInput, error) {
@@ -246,8 +271,9 @@ func (s *FragmentAppService) collectAgentSimilarityHits(
 	query string,
 	knowledgeBases []*kbentity.KnowledgeBase,
 	businessParams *ctxmeta.BusinessParams,
+	topK int,
 ) ([]*fragdto.SimilarityResultDTO, error) {
-	hits := make([]*fragdto.SimilarityResultDTO, 0, employeeKnowledgeTopK)
+	hits := make([]*fragdto.SimilarityResultDTO, 0, topK)
 	for _, kb := range knowledgeBases {
 		if kb == nil {
 			continue
@@ -256,7 +282,7 @@ func (s *FragmentAppService) collectAgentSimilarityHits(
 			OrganizationCode: organizationCode,
 			KnowledgeCode:    kb.Code,
 			Query:            query,
-			TopK:             employeeKnowledgeTopK,
+			TopK:             topK,
 			BusinessParams:   businessParams,
 		})
 		if err != nil {
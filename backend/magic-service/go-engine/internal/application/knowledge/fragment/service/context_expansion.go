@@ -0,0 +1,291 @@
+package fragapp
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	fragdto "magic/internal/application/knowledge/fragment/dto"
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+)
+
+const (
+	defaultContextExpansionNeighborWindow = 1
+	maxContextExpansionNeighborWindow     = 10
+	defaultContextExpansionMaxTokens      = 4000
+	maxContextExpansionMaxTokens          = 32000
+	maxContextExpansionParentFragments    = 200
+	contextExpansionSeparator             = "\n\n"
+)
+
+// fragmentAppContextWindowReader 是可选能力，仓储不支持时检索结果不做上下文扩展。
+type fragmentAppContextWindowReader interface {
+	ListContextWindow(ctx context.Context, query fragmodel.ContextWindowQuery) ([]*fragmodel.KnowledgeBaseFragment, error)
+}
+
+// similarityContextExpander 在一次检索请求内按排名顺序扩展命中上下文。
+//
+// Token 预算在整个请求内共享；同一文档内已被更高排名命中输出过的片段不会重复输出。
+type similarityContextExpander struct {
+	service         *FragmentAppService
+	reader          fragmentAppContextWindowReader
+	options         fragdto.ContextExpansionInput
+	countTokens     func(string) int
+	remainingTokens int
+	emitted         map[similarityDocumentKey]map[int64]int64
+}
+
+type contextExpansionHit struct {
+	fragmentID    int64
+	documentKey   similarityDocumentKey
+	chunkIndex    int
+	hasChunkIndex bool
+	parentNodeID  string
+}
+
+func (s *FragmentAppService) newSimilarityContextExpander(input *fragdto.ContextExpansionInput) *similarityContextExpander {
+	options, ok := normalizeContextExpansionInput(input)
+	if !ok || s == nil || s.fragmentService == nil {
+		return nil
+	}
+	reader, ok := s.fragmentService.(fragmentAppContextWindowReader)
+	if !ok {
+		return nil
+	}
+	return &similarityContextExpander{
+		service:         s,
+		reader:          reader,
+		options:         options,
+		countTokens:     s.contextExpansionTokenCounter(),
+		remainingTokens: options.MaxTokens,
+		emitted:         map[similarityDocumentKey]map[int64]int64{},
+	}
+}
+
+func normalizeContextExpansionInput(input *fragdto.ContextExpansionInput) (fragdto.ContextExpansionInput, bool) {
+	if input == nil {
+		return fragdto.ContextExpansionInput{}, false
+	}
+	options := *input
+	options.Mode = strings.ToLower(strings.TrimSpace(options.Mode))
+	switch options.Mode {
+	case "":
+		return fragdto.ContextExpansionInput{}, false
+	case fragdto.ContextExpansionModeParent, fragdto.ContextExpansionModeNeighbor:
+	default:
+		return fragdto.ContextExpansionInput{}, false
+	}
+	if options.NeighborWindow <= 0 {
+		options.NeighborWindow = defaultContextExpansionNeighborWindow
+	}
+	options.NeighborWindow = min(options.NeighborWindow, maxContextExpansionNeighborWindow)
+	if options.MaxTokens <= 0 {
+		options.MaxTokens = defaultContextExpansionMaxTokens
+	}
+	options.MaxTokens = min(options.MaxTokens, maxContextExpansionMaxTokens)
+	return options, true
+}
+
+func (s *FragmentAppService) contextExpansionTokenCounter() func(string) int {
+	if s.tokenizer != nil {
+		if encoder, err := s.tokenizer.EncoderForModel(resolveDefaultEmbeddingModel(s.defaultEmbeddingModel)); err == nil {
+			return encoder.CountTokens
+		}
+	}
+	return utf8.RuneCountInString
+}
+
+// expand 为 dtos 填充 ExpandedContext；results 与 dtos 按下标一一对应。
+func (e *similarityContextExpander) expand(
+	ctx context.Context,
+	results []*fragmodel.SimilarityResult,
+	dtos []*fragdto.SimilarityResultDTO,
+) {
+	if e == nil {
+		return
+	}
+	for i, result := range results {
+		if i >= len(dtos) || dtos[i] == nil {
+			break
+		}
+		hit, ok := contextExpansionHitFromResult(result)
+		if !ok {
+			continue
+		}
+		mode, query, ok := e.buildWindowQuery(hit)
+		if !ok {
+			continue
+		}
+		window, err := e.reader.ListContextWindow(ctx, query)
+		if err != nil {
+			if e.service.logger != nil {
+				e.service.logger.KnowledgeWarnContext(
+					ctx,
+					"List similarity context window failed, keep hit content only",
+					"knowledge_code", hit.documentKey.knowledgeBaseCode,
+					"document_code", hit.documentKey.documentCode,
+					"fragment_id", hit.fragmentID,
+					"error", err,
+				)
+			}
+			continue
+		}
+		dtos[i].ExpandedContext = e.assemble(hit, mode, window)
+	}
+}
+
+func contextExpansionHitFromResult(result *fragmodel.SimilarityResult) (contextExpansionHit, bool) {
+	if result == nil || result.FragmentID <= 0 {
+		return contextExpansionHit{}, false
+	}
+	key := similarityDocumentKeyFromResult(result)
+	if key.knowledgeBaseCode == "" || key.documentCode == "" {
+		return contextExpansionHit{}, false
+	}
+	chunkIndex, hasChunkIndex := metadataIntLookup(result.Metadata, "chunk_index")
+	return contextExpansionHit{
+		fragmentID:    result.FragmentID,
+		documentKey:   key,
+		chunkIndex:    chunkIndex,
+		hasChunkIndex: hasChunkIndex,
+		parentNodeID:  metadataStringValue(result.Metadata, "parent_node_id"),
+	}, true
+}
+
+// buildWindowQuery 父章节模式缺少 parent_node_id 时回退为相邻片段模式。
+func (e *similarityContextExpander) buildWindowQuery(hit contextExpansionHit) (string, fragmodel.ContextWindowQuery, bool) {
+	query := fragmodel.ContextWindowQuery{
+		KnowledgeCode: hit.documentKey.knowledgeBaseCode,
+		DocumentCode:  hit.documentKey.documentCode,
+	}
+	if e.options.Mode == fragdto.ContextExpansionModeParent && hit.parentNodeID != "" {
+		query.ParentNodeID = hit.parentNodeID
+		query.Limit = maxContextExpansionParentFragments
+		return fragdto.ContextExpansionModeParent, query, true
+	}
+	if !hit.hasChunkIndex {
+		return "", fragmodel.ContextWindowQuery{}, false
+	}
+	query.StartChunkIndex = max(hit.chunkIndex-e.options.NeighborWindow, 0)
+	query.EndChunkIndex = hit.chunkIndex + e.options.NeighborWindow
+	query.Limit = query.EndChunkIndex - query.StartChunkIndex + 1
+	return fragdto.ContextExpansionModeNeighbor, query, true
+}
+
+// assemble 先放入命中片段，再按与命中片段的距离由近到远放入其余片段，直到预算耗尽。
+func (e *similarityContextExpander) assemble(
+	hit contextExpansionHit,
+	mode string,
+	window []*fragmodel.KnowledgeBaseFragment,
+) *fragdto.ExpandedContextDTO {
+	emitted := e.emitted[hit.documentKey]
+	if emitted == nil {
+		emitted = map[int64]int64{}
+		e.emitted[hit.documentKey] = emitted
+	}
+	if owner, ok := emitted[hit.fragmentID]; ok {
+		return &fragdto.ExpandedContextDTO{Mode: mode, MergedIntoID: owner}
+	}
+
+	hitChunkIndex := hit.chunkIndex
+	candidates := make([]*fragmodel.KnowledgeBaseFragment, 0, len(window))
+	for _, fragment := range window {
+		// 摘要/问题辅助片段排在源片段之后，相邻窗口可能取到，但它们不是原文，不参与拼接。
+		if fragment == nil || fragmodel.IsAuxiliaryFragment(fragment) {
+			continue
+		}
+		if fragment.ID == hit.fragmentID {
+			hitChunkIndex = fragment.ChunkIndex
+		}
+		if _, ok := emitted[fragment.ID]; !ok {
+			candidates = append(candidates, fragment)
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b *fragmodel.KnowledgeBaseFragment) int {
+		if a.ID == hit.fragmentID || b.ID == hit.fragmentID {
+			return contextExpansionHitFirst(a.ID == hit.fragmentID, b.ID == hit.fragmentID)
+		}
+		return cmp.Or(
+			cmp.Compare(contextExpansionDistance(a.ChunkIndex, hitChunkIndex), contextExpansionDistance(b.ChunkIndex, hitChunkIndex)),
+			cmp.Compare(a.ChunkIndex, b.ChunkIndex),
+		)
+	})
+
+	selected, tokenCount, truncated := e.selectWithinBudget(hit.fragmentID, candidates)
+	if len(selected) == 0 {
+		return nil
+	}
+	for _, fragment := range selected {
+		emitted[fragment.ID] = hit.fragmentID
+	}
+	return buildExpandedContextDTO(mode, selected, tokenCount, truncated)
+}
+
+func (e *similarityContextExpander) selectWithinBudget(
+	hitFragmentID int64,
+	candidates []*fragmodel.KnowledgeBaseFragment,
+) ([]*fragmodel.KnowledgeBaseFragment, int, bool) {
+	selected := make([]*fragmodel.KnowledgeBaseFragment, 0, len(candidates))
+	tokenCount := 0
+	truncated := false
+	for _, fragment := range candidates {
+		tokens := e.countTokens(fragment.Content)
+		// 命中片段本身始终保留，即使预算已耗尽。
+		if fragment.ID != hitFragmentID && tokens > e.remainingTokens {
+			truncated = true
+			continue
+		}
+		e.remainingTokens = max(e.remainingTokens-tokens, 0)
+		tokenCount += tokens
+		selected = append(selected, fragment)
+	}
+	return selected, tokenCount, truncated
+}
+
+func buildExpandedContextDTO(
+	mode string,
+	selected []*fragmodel.KnowledgeBaseFragment,
+	tokenCount int,
+	truncated bool,
+) *fragdto.ExpandedContextDTO {
+	slices.SortStableFunc(selected, func(a, b *fragmodel.KnowledgeBaseFragment) int {
+		return cmp.Compare(a.ChunkIndex, b.ChunkIndex)
+	})
+	contents := make([]string, 0, len(selected))
+	fragmentIDs := make([]int64, 0, len(selected))
+	for _, fragment := range selected {
+		fragmentIDs = append(fragmentIDs, fragment.ID)
+		if content := strings.TrimSpace(fragment.Content); content != "" {
+			contents = append(contents, content)
+		}
+	}
+	return &fragdto.ExpandedContextDTO{
+		Mode:            mode,
+		Content:         strings.Join(contents, contextExpansionSeparator),
+		FragmentIDs:     fragmentIDs,
+		StartChunkIndex: selected[0].ChunkIndex,
+		EndChunkIndex:   selected[len(selected)-1].ChunkIndex,
+		TokenCount:      tokenCount,
+		Truncated:       truncated,
+	}
+}
+
+func contextExpansionHitFirst(aIsHit, bIsHit bool) int {
+	switch {
+	case aIsHit && !bIsHit:
+		return -1
+	case !aIsHit && bIsHit:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func contextExpansionDistance(chunkIndex, hitChunkIndex int) int {
+	if chunkIndex >= hitChunkIndex {
+		return chunkIndex - hitChunkIndex
+	}
+	return hitChunkIndex - chunkIndex
+}
@@ -0,0 +1,41 @@
+package fragapp
+
+import (
+	"context"
+	"time"
+
+	fragdto "magic/internal/application/knowledge/fragment/dto"
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
+)
+
+// fragmentQueryLogRecorder 记录检索 query 日志，实现需自行采样、异步写入，不得阻塞检索。
+type fragmentQueryLogRecorder interface {
+	Record(ctx context.Context, search querylogentity.Search)
+}
+
+// recordQueryLog 把一次成功的检索交给日志记录器，未装配记录器时为空操作。
+func (s *FragmentAppService) recordQueryLog(
+	ctx context.Context,
+	search querylogentity.Search,
+	results []*fragdto.SimilarityResultDTO,
+	startedAt time.Time,
+) {
+	if s == nil || s.queryLogRecorder == nil {
+		return
+	}
+	search.Hits = make([]querylogentity.Hit, 0, len(results))
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		search.Hits = append(search.Hits, querylogentity.Hit{
+			KnowledgeBaseCode: result.KnowledgeBaseCode,
+			DocumentCode:      result.DocumentCode,
+			FragmentID:        result.ID,
+			Score:             result.Score,
+		})
+	}
+	search.StartedAt = startedAt
+	search.Latency = time.Since(startedAt)
+	s.queryLogRecorder.Record(ctx, search)
+}
@@ -17,6 +17,7 @@ import (
 	fragdomain "magic/internal/domain/knowledge/fragment/service"
 	kbentity "magic/internal/domain/knowledge/knowledgebase/entity"
 	kbrepository "magic/internal/domain/knowledge/knowledgebase/repository"
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
 	"magic/internal/domain/knowledge/shared"
 	"magic/internal/pkg/ctxmeta"
 	"magic/internal/pkg/logkey"
@@ -89,7 +90,28 @@ func (s *FragmentAppService) RuntimeSimilarity(
 		}
 	}
 
-	return s.runtimeSimilarityWithKnowledgeBases(ctx, input, knowledgeBases)
+	startedAt := time.Now()
+	results, err := s.runtimeSimilarityWithKnowledgeBases(ctx, input, knowledgeBases)
+	if err != nil {
+		return nil, err
+	}
+	if !input.SkipQueryLog {
+		s.recordQueryLog(ctx, querylogentity.Search{
+			OrganizationCode:   input.OrganizationCode,
+			UserID:             runtimeSimilarityUserID(input),
+			Source:             querylogentity.SourceRuntimeSimilarity,
+			Query:              resolveRuntimeEmbeddingQuery(input),
+			KnowledgeBaseCodes: knowledgeCodes,
+		}, results, startedAt)
+	}
+	return results, nil
+}
+
+func runtimeSimilarityUserID(input *fragdto.RuntimeSimilarityInput) string {
+	if input == nil || input.BusinessParams == nil {
+		return ""
+	}
+	return input.BusinessParams.UserID
 }
 
 // FlowVectorSimilarityByUser 按用户可读的已启用 flow 向量知识库执行相似度搜索。
@@ -157,11 +179,17 @@ func (s *FragmentAppService) runtimeSimilarityWithKnowledgeBases(
 	}
 
 	topK, scoreThreshold := resolveRuntimeSimilarityConfig(input, activeKnowledgeBases[0])
-	metadataFilter := buildRuntimeMetadataFilter(input.MetadataFilter)
+	plan := runtimeSimilarityPlan{
+		topK:           topK,
+		scoreThreshold: scoreThreshold,
+		metadataFilter: buildRuntimeMetadataFilter(input.MetadataFilter),
+		// 上下文扩展的 token 预算在多个知识库之间共享。
+		contextExpander: s.newSimilarityContextExpander(input.ContextExpansion),
+	}
 
 	results := make([]*fragdto.SimilarityResultDTO, 0, len(activeKnowledgeBases)*topK)
 	for _, kb := range activeKnowledgeBases {
-		partial, err := s.runtimeSimilarityByKnowledgeBase(ctx, kb, input, topK, scoreThreshold, metadataFilter)
+		partial, err := s.runtimeSimilarityByKnowledgeBase(ctx, kb, input, plan)
 		if err != nil {
 			return nil, err
 		}
@@ -420,35 +448,46 @@ func (s *FragmentAppService) runtimeCreateFragment(
 	return fragment, nil
 }
 
+// runtimeSimilarityPlan 聚合 runtime 多知识库检索中每个知识库共用的参数。
+type runtimeSimilarityPlan struct {
+	topK            int
+	scoreThreshold  float64
+	metadataFilter  *fragmodel.VectorFilter
+	contextExpander *similarityContextExpander
+}
+
 func (s *FragmentAppService) runtimeSimilarityByKnowledgeBase(
 	ctx context.Context,
 	kb *kbentity.KnowledgeBase,
 	input *fragdto.RuntimeSimilarityInput,
-	topK int,
-	scoreThreshold float64,
-	metadataFilter *fragmodel.VectorFilter,
+	plan runtimeSimilarityPlan,
 ) ([]*fragdto.SimilarityResultDTO, error) {
 	if kb == nil {
 		return nil, shared.ErrKnowledgeBaseNotFound
 	}
 
 	options := &fragretrieval.SimilaritySearchOptions{
-		HardFilter: metadataFilter,
+		HardFilter: plan.metadataFilter,
 		Debug:      input.Debug,
 	}
 	results, err := s.fragmentService.Similarity(ctx, knowledgeBaseSnapshotFromDomain(kb), fragretrieval.SimilarityRequest{
 		Query:                   input.Query,
 		EmbeddingQuery:          resolveRuntimeEmbeddingQuery(input),
-		TopK:                    topK,
+		TopK:                    plan.topK,
 		CandidateScoreThreshold: runtimeSimilarityCandidateScoreThreshold,
-		ResultScoreThreshold:    scoreThreshold,
+		ResultScoreThreshold:    plan.scoreThreshold,
 		BusinessParams:          input.BusinessParams,
 		Options:                 options,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to search runtime similarity: %w", err)
 	}
-	return s.similarityResultsToDTOs(ctx, kb, results, input.Debug)
+	dtos, err := s.similarityResultsToDTOs(ctx, kb, results, input.Debug)
+	if err != nil {
+		return nil, err
+	}
+	plan.contextExpander.expand(ctx, results, dtos)
+	return dtos, nil
 }
 
 func (s *FragmentAppService) similarityResultsToDTOs(
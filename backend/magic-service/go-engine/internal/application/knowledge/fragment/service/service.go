@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"golang.org/x/sync/singleflight"
 
@@ -24,6 +25,7 @@ import (
 	kbentity "magic/internal/domain/knowledge/knowledgebase/entity"
 	kbrepository "magic/internal/domain/knowledge/knowledgebase/repository"
 	knowledgebasedomain "magic/internal/domain/knowledge/knowledgebase/service"
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
 	"magic/internal/domain/knowledge/shared"
 	parseddocument "magic/internal/domain/knowledge/shared/parseddocument"
 	sharedroute "magic/internal/domain/knowledge/shared/route"
@@ -54,6 +56,7 @@ type FragmentAppService struct {
 	thirdPlatformProviders    *thirdplatformprovider.Registry
 	legacyThirdPlatformCompat *LegacyThirdPlatformFragmentCompat
 	userService               *userdomain.DomainService
+	queryLogRecorder          fragmentQueryLogRecorder
 	logger                    *logging.SugaredLogger
 	previewGroup              singleflight.Group
 }
@@ -195,6 +198,7 @@ type AppDeps struct {
 	Tokenizer                 *tokenizer.Service
 	UserService               *userdomain.DomainService
 	DefaultEmbeddingModel     string
+	QueryLogRecorder          fragmentQueryLogRecorder
 }
 
 // NewFragmentAppService 创建片段应用层服务
@@ -227,6 +231,7 @@ func NewFragmentAppService(
 		defaultEmbeddingModel:     resolveDefaultEmbeddingModel(deps.DefaultEmbeddingModel),
 		thirdPlatformProviders:    deps.ThirdPlatformProviders,
 		userService:               deps.UserService,
+		queryLogRecorder:          deps.QueryLogRecorder,
 		logger:                    logger,
 	}
 	service.legacyThirdPlatformCompat = NewLegacyThirdPlatformFragmentCompat(documentService, deps.ThirdPlatformProviders)
@@ -505,7 +510,19 @@ func (s *FragmentAppService) Similarity(ctx context.Context, input *fragdto.Simi
 	if err := s.authorizeSimilarityRead(ctx, input.OrganizationCode, userID, kb); err != nil {
 		return nil, err
 	}
-	return s.similarityByKnowledgeBase(ctx, kb, input)
+	startedAt := time.Now()
+	results, err := s.similarityByKnowledgeBase(ctx, kb, input)
+	if err != nil {
+		return nil, err
+	}
+	s.recordQueryLog(ctx, querylogentity.Search{
+		OrganizationCode:   input.OrganizationCode,
+		UserID:             userID,
+		Source:             querylogentity.SourceSimilarity,
+		Query:              input.Query,
+		KnowledgeBaseCodes: []string{kb.Code},
+	}, results, startedAt)
+	return results, nil
 }
 
 func (s *FragmentAppService) authorizeSimilarityRead(
@@ -630,7 +647,12 @@ func (s *FragmentAppService) similarityByKnowledgeBase(
 	if err != nil {
 		return nil, fmt.Errorf("failed to search similarity: %w", err)
 	}
-	return s.similarityResultsToDTOs(ctx, kb, results, input.Debug)
+	dtos, err := s.similarityResultsToDTOs(ctx, kb, results, input.Debug)
+	if err != nil {
+		return nil, err
+	}
+	s.newSimilarityContextExpander(input.ContextExpansion).expand(ctx, results, dtos)
+	return dtos, nil
 }
 
 func (s *FragmentAppService) entityToDTO(e *fragmodel.KnowledgeBaseFragment) *fragdto.FragmentDTO {
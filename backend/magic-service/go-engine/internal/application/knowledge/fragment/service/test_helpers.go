@@ -37,6 +37,7 @@ type AppServiceForTestOptions struct {
 	TeamshareTempCodeMapper   fragmentAppTeamshareTempCodeMapper
 	PermissionReader          kbaccess.PermissionReader
 	DefaultEmbeddingModel     string
+	QueryLogRecorder          fragmentQueryLogRecorder
 	Logger                    *logging.SugaredLogger
 }
 
@@ -105,6 +106,7 @@ func NewFragmentAppServiceForTest(tb testing.TB, opts AppServiceForTestOptions)
 		permissionReader:          opts.PermissionReader,
 		tokenizer:                 tokenizer.NewService(),
 		defaultEmbeddingModel:     opts.DefaultEmbeddingModel,
+		queryLogRecorder:          opts.QueryLogRecorder,
 		logger:                    opts.Logger,
 	}
 	service.legacyThirdPlatformCompat = NewLegacyThirdPlatformFragmentCompat(ds, opts.ThirdPlatformProviders)
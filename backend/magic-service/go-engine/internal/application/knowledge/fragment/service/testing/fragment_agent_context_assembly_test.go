@@ -0,0 +1,130 @@
+package fragapp_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	fragdto "magic/internal/application/knowledge/fragment/dto"
+	appservice "magic/internal/application/knowledge/fragment/service"
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	kbentity "magic/internal/domain/knowledge/knowledgebase/entity"
+)
+
+func TestFragmentAppServiceAssembleContextByAgentGreedyBudget(t *testing.T) {
+	t.Parallel()
+
+	result := runAgentContextAssembly(t, []*fragmodel.SimilarityResult{
+		contextAssemblyHit(11, "DOC1", "退款会原路返回", 0.9, map[string]any{"section_path": "售后 > 退款"}),
+		contextAssemblyHit(12, "DOC1", "退款会原路返回", 0.8, map[string]any{"section_path": "售后 > 退款"}),
+		contextAssemblyHit(13, "DOC2", strings.Repeat("long refund policy text ", 60), 0.7, nil),
+		contextAssemblyHit(14, "DOC3", "发货 48 小时内完成", 0.6, map[string]any{"section_path": "物流"}),
+	}, &fragdto.AgentContextAssemblyInput{MaxTokens: 80})
+
+	if got := contextAssemblyFragmentIDs(result); !slices.Equal(got, []int64{11, 14}) {
+		t.Fatalf("expected greedy packing to skip oversized block, got %v", got)
+	}
+	if result.CandidateCount != 3 || result.DroppedCount != 1 || result.Strategy != fragdto.ContextAssemblyStrategyGreedy {
+		t.Fatalf("unexpected assembly summary: %#v", result)
+	}
+	if result.TokenCount <= 0 || result.TokenCount > result.MaxTokens {
+		t.Fatalf("expected token count within budget, got %d/%d", result.TokenCount, result.MaxTokens)
+	}
+	if !strings.HasPrefix(result.Context, "[1] doc-DOC1.md\n售后 > 退款\n\n退款会原路返回") ||
+		!strings.Contains(result.Context, "[2] doc-DOC3.md\n物流\n\n发货 48 小时内完成") {
+		t.Fatalf("unexpected context block: %q", result.Context)
+	}
+	if result.Citations[0].CitationID != "KB1:DOC1:11" || result.Citations[0].SectionPath != "售后 > 退款" {
+		t.Fatalf("unexpected citation: %#v", result.Citations[0])
+	}
+}
+
+func TestFragmentAppServiceAssembleContextByAgentDiversityAndMMR(t *testing.T) {
+	t.Parallel()
+
+	diversity := runAgentContextAssembly(t, []*fragmodel.SimilarityResult{
+		contextAssemblyHit(11, "DOC1", "first refund answer", 0.9, nil),
+		contextAssemblyHit(12, "DOC1", "second refund answer", 0.85, nil),
+		contextAssemblyHit(13, "DOC1", "third refund answer", 0.8, nil),
+		contextAssemblyHit(21, "DOC2", "shipping answer", 0.5, nil),
+	}, &fragdto.AgentContextAssemblyInput{Strategy: "diversity"})
+	if got := contextAssemblyFragmentIDs(diversity); !slices.Equal(got, []int64{11, 21, 12}) {
+		t.Fatalf("expected per-document round robin with cap, got %v", got)
+	}
+	if diversity.DroppedCount != 1 {
+		t.Fatalf("expected capped candidate to be counted as dropped, got %#v", diversity)
+	}
+
+	lambda := 0.5
+	mmr := runAgentContextAssembly(t, []*fragmodel.SimilarityResult{
+		contextAssemblyHit(11, "DOC1", "apple banana cherry", 0.9, nil),
+		contextAssemblyHit(12, "DOC2", "apple banana cherry date", 0.88, nil),
+		contextAssemblyHit(13, "DOC3", "completely different topic zeta", 0.7, nil),
+	}, &fragdto.AgentContextAssemblyInput{Strategy: "MMR", MMRLambda: &lambda})
+	if got := contextAssemblyFragmentIDs(mmr); !slices.Equal(got, []int64{11, 13, 12}) {
+		t.Fatalf("expected mmr to demote near-duplicate content, got %v", got)
+	}
+}
+
+func runAgentContextAssembly(
+	t *testing.T,
+	hits []*fragmodel.SimilarityResult,
+	input *fragdto.AgentContextAssemblyInput,
+) *fragdto.AgentContextAssemblyResultDTO {
+	t.Helper()
+
+	kbList := []*kbentity.KnowledgeBase{{
+		Code:              testFragmentKnowledgeCode,
+		OrganizationCode:  testFragmentOrganization,
+		KnowledgeBaseType: kbentity.KnowledgeBaseTypeDigitalEmployee,
+		Model:             "text-embedding-3-small",
+	}}
+	fragmentSvc := &fragmentAppFragmentServiceStub{
+		similarityResultsByKB: map[string][]*fragmodel.SimilarityResult{testFragmentKnowledgeCode: hits},
+	}
+	svc := appservice.NewFragmentAppServiceForTest(t, appservice.AppServiceForTestOptions{
+		FragmentService: fragmentSvc,
+		KBService:       &fragmentAppKnowledgeReaderStub{listResult: kbList, listTotal: 1},
+		KnowledgeBaseBindingRepo: &fragmentAppKnowledgeBaseBindingReaderStub{
+			knowledgeBaseCodes: []string{testFragmentKnowledgeCode},
+		},
+		SuperMagicAgentAccess: &fragmentAppSuperMagicAgentAccessCheckerStub{
+			accessibleCodes: map[string]struct{}{"SMA-001": {}},
+		},
+		DefaultEmbeddingModel: "text-embedding-3-small",
+	})
+
+	input.OrganizationCode = testFragmentOrganization
+	input.UserID = "U1"
+	input.AgentCode = "SMA-001"
+	input.Query = "退款"
+	result, err := svc.AssembleContextByAgent(context.Background(), input)
+	if err != nil {
+		t.Fatalf("assemble context by agent failed: %v", err)
+	}
+	if fragmentSvc.lastSimilarityReq.TopK != 20 {
+		t.Fatalf("expected default candidate pool size, got %#v", fragmentSvc.lastSimilarityReq)
+	}
+	return result
+}
+
+func contextAssemblyHit(fragmentID int64, documentCode, content string, score float64, metadata map[string]any) *fragmodel.SimilarityResult {
+	return &fragmodel.SimilarityResult{
+		FragmentID:    fragmentID,
+		KnowledgeCode: testFragmentKnowledgeCode,
+		DocumentCode:  documentCode,
+		DocumentName:  "doc-" + documentCode + ".md",
+		Content:       content,
+		Score:         score,
+		Metadata:      metadata,
+	}
+}
+
+func contextAssemblyFragmentIDs(result *fragdto.AgentContextAssemblyResultDTO) []int64 {
+	ids := make([]int64, 0, len(result.Citations))
+	for _, citation := range result.Citations {
+		ids = append(ids, citation.FragmentID)
+	}
+	return ids
+}
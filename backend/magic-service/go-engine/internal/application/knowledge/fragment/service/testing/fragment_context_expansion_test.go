@@ -0,0 +1,199 @@
+package fragapp_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	fragdto "magic/internal/application/knowledge/fragment/dto"
+	appservice "magic/internal/application/knowledge/fragment/service"
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	kbentity "magic/internal/domain/knowledge/knowledgebase/entity"
+)
+
+type fragmentContextWindowServiceStub struct {
+	*fragmentAppFragmentServiceStub
+
+	fragmentsByDocument map[string][]*fragmodel.KnowledgeBaseFragment
+	queries             []fragmodel.ContextWindowQuery
+}
+
+func (s *fragmentContextWindowServiceStub) ListContextWindow(
+	_ context.Context,
+	query fragmodel.ContextWindowQuery,
+) ([]*fragmodel.KnowledgeBaseFragment, error) {
+	s.queries = append(s.queries, query)
+	window := make([]*fragmodel.KnowledgeBaseFragment, 0)
+	for _, fragment := range s.fragmentsByDocument[query.DocumentCode] {
+		if query.ParentNodeID != "" {
+			if fragment.Metadata["parent_node_id"] == query.ParentNodeID {
+				window = append(window, fragment)
+			}
+			continue
+		}
+		if fragment.ChunkIndex >= query.StartChunkIndex && fragment.ChunkIndex <= query.EndChunkIndex {
+			window = append(window, fragment)
+		}
+	}
+	return window, nil
+}
+
+func TestFragmentAppServiceSimilarityExpandsNeighborContext(t *testing.T) {
+	t.Parallel()
+
+	fragmentSvc := newFragmentContextWindowServiceStub([]*fragmodel.SimilarityResult{
+		contextExpansionResult(12, "DOC1", 2, ""),
+		contextExpansionResult(13, "DOC1", 3, ""),
+		contextExpansionResult(30, "DOC2", 0, ""),
+	})
+	results := runContextExpansionSimilarity(t, fragmentSvc, &fragdto.ContextExpansionInput{
+		Mode:           fragdto.ContextExpansionModeNeighbor,
+		NeighborWindow: 1,
+		MaxTokens:      40,
+	})
+
+	first := results[0].ExpandedContext
+	if first == nil || !slices.Equal(first.FragmentIDs, []int64{11, 12, 13}) ||
+		first.StartChunkIndex != 1 || first.EndChunkIndex != 3 || first.Truncated {
+		t.Fatalf("expected neighbor window around first hit, got %#v", first)
+	}
+	if first.Content != "chunk one\n\nchunk two\n\nchunk three" {
+		t.Fatalf("expected content ordered by chunk index, got %q", first.Content)
+	}
+	if second := results[1].ExpandedContext; second == nil || second.MergedIntoID != 12 || second.Content != "" {
+		t.Fatalf("expected overlapping hit to be merged into first hit, got %#v", second)
+	}
+	third := results[2].ExpandedContext
+	if third == nil || !slices.Equal(third.FragmentIDs, []int64{30}) || !third.Truncated {
+		t.Fatalf("expected token budget to keep only the hit fragment, got %#v", third)
+	}
+	if fragmentSvc.queries[0].StartChunkIndex != 1 || fragmentSvc.queries[0].EndChunkIndex != 3 {
+		t.Fatalf("unexpected context window query: %#v", fragmentSvc.queries[0])
+	}
+}
+
+func TestFragmentAppServiceSimilarityExpandsParentSection(t *testing.T) {
+	t.Parallel()
+
+	fragmentSvc := newFragmentContextWindowServiceStub([]*fragmodel.SimilarityResult{
+		contextExpansionResult(12, "DOC1", 2, "node-a"),
+		contextExpansionResult(30, "DOC2", 0, ""),
+	})
+	results := runContextExpansionSimilarity(t, fragmentSvc, &fragdto.ContextExpansionInput{
+		Mode: fragdto.ContextExpansionModeParent,
+	})
+
+	first := results[0].ExpandedContext
+	if first == nil || first.Mode != fragdto.ContextExpansionModeParent || !slices.Equal(first.FragmentIDs, []int64{11, 12}) {
+		t.Fatalf("expected parent section context, got %#v", first)
+	}
+	if second := results[1].ExpandedContext; second == nil || second.Mode != fragdto.ContextExpansionModeNeighbor {
+		t.Fatalf("expected hit without parent node to fall back to neighbors, got %#v", second)
+	}
+	if fragmentSvc.queries[0].ParentNodeID != "node-a" {
+		t.Fatalf("expected parent node query, got %#v", fragmentSvc.queries[0])
+	}
+
+	plain := runContextExpansionSimilarity(t, fragmentSvc, nil)
+	if plain[0].ExpandedContext != nil {
+		t.Fatalf("expected no expansion without context_expansion, got %#v", plain[0].ExpandedContext)
+	}
+}
+
+func TestFragmentAppServiceSimilarityContextSkipsAuxiliaryFragments(t *testing.T) {
+	t.Parallel()
+
+	fragmentSvc := newFragmentContextWindowServiceStub([]*fragmodel.SimilarityResult{
+		contextExpansionResult(13, "DOC1", 3, ""),
+	})
+	auxiliary := contextExpansionFragment(14, 4, "报销时限是多久？", "")
+	auxiliary.Metadata[fragmodel.MetadataAuxiliaryTypeKey] = fragmodel.AuxiliaryTypeQuestion
+	fragmentSvc.fragmentsByDocument["DOC1"] = append(fragmentSvc.fragmentsByDocument["DOC1"], auxiliary)
+
+	results := runContextExpansionSimilarity(t, fragmentSvc, &fragdto.ContextExpansionInput{
+		Mode:           fragdto.ContextExpansionModeNeighbor,
+		NeighborWindow: 1,
+		MaxTokens:      40,
+	})
+
+	expanded := results[0].ExpandedContext
+	if expanded == nil || !slices.Equal(expanded.FragmentIDs, []int64{12, 13}) {
+		t.Fatalf("expected auxiliary fragment excluded from neighbor context, got %#v", expanded)
+	}
+	if strings.Contains(expanded.Content, "报销时限") {
+		t.Fatalf("expected generated question not stitched into context, got %q", expanded.Content)
+	}
+}
+
+func newFragmentContextWindowServiceStub(results []*fragmodel.SimilarityResult) *fragmentContextWindowServiceStub {
+	return &fragmentContextWindowServiceStub{
+		fragmentAppFragmentServiceStub: &fragmentAppFragmentServiceStub{
+			similarityResultsByKB: map[string][]*fragmodel.SimilarityResult{testFragmentKnowledgeCode: results},
+		},
+		fragmentsByDocument: map[string][]*fragmodel.KnowledgeBaseFragment{
+			"DOC1": {
+				contextExpansionFragment(11, 1, "chunk one", "node-a"),
+				contextExpansionFragment(12, 2, "chunk two", "node-a"),
+				contextExpansionFragment(13, 3, "chunk three", "node-b"),
+			},
+			"DOC2": {
+				contextExpansionFragment(30, 0, "doc two hit", ""),
+				contextExpansionFragment(31, 1, strings.Repeat("long neighbor text ", 40), ""),
+			},
+		},
+	}
+}
+
+func runContextExpansionSimilarity(
+	t *testing.T,
+	fragmentSvc *fragmentContextWindowServiceStub,
+	expansion *fragdto.ContextExpansionInput,
+) []*fragdto.SimilarityResultDTO {
+	t.Helper()
+
+	svc := appservice.NewFragmentAppServiceForTest(t, appservice.AppServiceForTestOptions{
+		FragmentService: fragmentSvc,
+		KBService: &fragmentAppKnowledgeReaderStub{
+			showByCodeAndOrgResult: &kbentity.KnowledgeBase{
+				Code:             testFragmentKnowledgeCode,
+				OrganizationCode: testFragmentOrganization,
+			},
+		},
+		DefaultEmbeddingModel: "text-embedding-3-small",
+	})
+	results, err := svc.Similarity(context.Background(), &fragdto.SimilarityInput{
+		OrganizationCode: testFragmentOrganization,
+		KnowledgeCode:    testFragmentKnowledgeCode,
+		Query:            "chunk",
+		ContextExpansion: expansion,
+	})
+	if err != nil {
+		t.Fatalf("similarity failed: %v", err)
+	}
+	return results
+}
+
+func contextExpansionResult(fragmentID int64, documentCode string, chunkIndex int, parentNodeID string) *fragmodel.SimilarityResult {
+	metadata := map[string]any{"chunk_index": chunkIndex}
+	if parentNodeID != "" {
+		metadata["parent_node_id"] = parentNodeID
+	}
+	return &fragmodel.SimilarityResult{
+		FragmentID:    fragmentID,
+		KnowledgeCode: testFragmentKnowledgeCode,
+		DocumentCode:  documentCode,
+		Content:       "hit",
+		Score:         0.9,
+		Metadata:      metadata,
+	}
+}
+
+func contextExpansionFragment(id int64, chunkIndex int, content, parentNodeID string) *fragmodel.KnowledgeBaseFragment {
+	return &fragmodel.KnowledgeBaseFragment{
+		ID:         id,
+		ChunkIndex: chunkIndex,
+		Content:    content,
+		Metadata:   map[string]any{"parent_node_id": parentNodeID},
+	}
+}
@@ -0,0 +1,101 @@
+package fragapp_test
+
+import (
+	"context"
+	"testing"
+
+	fragdto "magic/internal/application/knowledge/fragment/dto"
+	appservice "magic/internal/application/knowledge/fragment/service"
+	docentity "magic/internal/domain/knowledge/document/entity"
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	kbentity "magic/internal/domain/knowledge/knowledgebase/entity"
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
+	"magic/internal/pkg/ctxmeta"
+)
+
+type queryLogRecorderStub struct {
+	searches []querylogentity.Search
+}
+
+func (r *queryLogRecorderStub) Record(_ context.Context, search querylogentity.Search) {
+	r.searches = append(r.searches, search)
+}
+
+func newQueryLogTestService(t *testing.T, recorder *queryLogRecorderStub) *appservice.FragmentAppService {
+	t.Helper()
+
+	return appservice.NewFragmentAppServiceForTest(t, appservice.AppServiceForTestOptions{
+		FragmentService: &fragmentAppFragmentServiceStub{
+			similarityResultsByKB: map[string][]*fragmodel.SimilarityResult{
+				testFragmentKnowledgeCode: {{
+					FragmentID:    41,
+					KnowledgeCode: testFragmentKnowledgeCode,
+					DocumentCode:  "DOC1",
+					DocumentName:  "doc-1.md",
+					DocumentType:  int(docentity.DocumentInputKindFile),
+					Content:       "reset password",
+					Score:         0.61,
+				}},
+			},
+		},
+		KBService: &fragmentAppKnowledgeReaderStub{
+			listResult: []*kbentity.KnowledgeBase{{
+				Code:             testFragmentKnowledgeCode,
+				OrganizationCode: testFragmentOrganization,
+				Enabled:          true,
+				Model:            "text-embedding-3-small",
+			}},
+			listTotal: 1,
+		},
+		DefaultEmbeddingModel: "text-embedding-3-small",
+		QueryLogRecorder:      recorder,
+	})
+}
+
+func TestFragmentAppServiceRuntimeSimilarityRecordsQueryLog(t *testing.T) {
+	t.Parallel()
+
+	recorder := &queryLogRecorderStub{}
+	svc := newQueryLogTestService(t, recorder)
+
+	results, err := svc.RuntimeSimilarity(context.Background(), &fragdto.RuntimeSimilarityInput{
+		OrganizationCode: testFragmentOrganization,
+		KnowledgeCodes:   []string{testFragmentKnowledgeCode},
+		Query:            "keyword",
+		Question:         "how to reset password",
+		BusinessParams:   &ctxmeta.BusinessParams{UserID: "u1"},
+	})
+	if err != nil {
+		t.Fatalf("runtime similarity failed: %v", err)
+	}
+	if len(recorder.searches) != 1 {
+		t.Fatalf("expected one recorded search, got %#v", recorder.searches)
+	}
+	search := recorder.searches[0]
+	if search.Source != querylogentity.SourceRuntimeSimilarity || search.Query != "how to reset password" ||
+		search.UserID != "u1" || len(search.KnowledgeBaseCodes) != 1 || search.StartedAt.IsZero() {
+		t.Fatalf("unexpected recorded search: %#v", search)
+	}
+	if len(search.Hits) != len(results) || search.Hits[0].DocumentCode != "DOC1" || search.Hits[0].Score != 0.61 {
+		t.Fatalf("unexpected recorded hits: %#v", search.Hits)
+	}
+}
+
+func TestFragmentAppServiceRuntimeSimilaritySkipsQueryLogWhenRequested(t *testing.T) {
+	t.Parallel()
+
+	recorder := &queryLogRecorderStub{}
+	svc := newQueryLogTestService(t, recorder)
+
+	if _, err := svc.RuntimeSimilarity(context.Background(), &fragdto.RuntimeSimilarityInput{
+		OrganizationCode: testFragmentOrganization,
+		KnowledgeCodes:   []string{testFragmentKnowledgeCode},
+		Query:            "keyword",
+		SkipQueryLog:     true,
+	}); err != nil {
+		t.Fatalf("runtime similarity failed: %v", err)
+	}
+	if len(recorder.searches) != 0 {
+		t.Fatalf("expected query log to be skipped, got %#v", recorder.searches)
+	}
+}
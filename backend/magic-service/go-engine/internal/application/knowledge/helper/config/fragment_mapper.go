@@ -50,6 +50,7 @@ func FragmentConfigEntityToDTO(cfg *shared.FragmentConfig) *FragmentConfigDTO {
 			KeepHierarchyInfo:  normalized.Hierarchy.KeepHierarchyInfo,
 		}
 	}
+	result.AuxiliaryFragments = auxiliaryFragmentConfigEntityToDTO(normalized.AuxiliaryFragments)
 	return result
 }
 
@@ -75,6 +76,7 @@ func FragmentConfigEntityToOutputDTO(cfg *shared.FragmentConfig) *FragmentConfig
 			KeepHierarchyInfo:  normalized.Hierarchy.KeepHierarchyInfo,
 		}
 	}
+	result.AuxiliaryFragments = auxiliaryFragmentConfigEntityToDTO(normalized.AuxiliaryFragments)
 	return result
 }
 
@@ -106,6 +108,10 @@ func NormalizeFragmentConfigOutputDTO(cfg *FragmentConfigOutputDTO) *FragmentCon
 		hierarchy.TextPreprocessRule = cloneIntSliceOrEmpty(cfg.Hierarchy.TextPreprocessRule)
 		result.Hierarchy = &hierarchy
 	}
+	if cfg.AuxiliaryFragments != nil {
+		auxiliaryFragments := *cfg.AuxiliaryFragments
+		result.AuxiliaryFragments = &auxiliaryFragments
+	}
 	return result
 }
 
@@ -137,9 +143,29 @@ func fragmentConfigDTOToEntityRaw(cfg *FragmentConfigDTO) *shared.FragmentConfig
 			KeepHierarchyInfo:  cfg.Hierarchy.KeepHierarchyInfo,
 		}
 	}
+	if cfg.AuxiliaryFragments != nil {
+		result.AuxiliaryFragments = &shared.AuxiliaryFragmentConfig{
+			Enabled:       cfg.AuxiliaryFragments.Enabled,
+			Scope:         shared.AuxiliaryFragmentScope(cfg.AuxiliaryFragments.Scope),
+			QuestionCount: cfg.AuxiliaryFragments.QuestionCount,
+			ModelName:     cfg.AuxiliaryFragments.ModelName,
+		}
+	}
 	return result
 }
 
+func auxiliaryFragmentConfigEntityToDTO(cfg *shared.AuxiliaryFragmentConfig) *AuxiliaryFragmentConfigDTO {
+	if cfg == nil {
+		return nil
+	}
+	return &AuxiliaryFragmentConfigDTO{
+		Enabled:       cfg.Enabled,
+		Scope:         string(cfg.Scope),
+		QuestionCount: cfg.QuestionCount,
+		ModelName:     cfg.ModelName,
+	}
+}
+
 func segmentRuleDTOToEntity(rule *SegmentRuleDTO) *shared.SegmentRule {
 	if rule == nil {
 		return nil
@@ -184,3 +184,28 @@ func TestFragmentConfigMapperNormalizesNilTextPreprocessRuleToEmptyArray(t *test
 		t.Fatalf("expected output hierarchy text preprocess rule to be empty slice, got %#v", outputDTO.Hierarchy.TextPreprocessRule)
 	}
 }
+
+func TestFragmentConfigMapperKeepsAuxiliaryFragments(t *testing.T) {
+	t.Parallel()
+
+	var dto FragmentConfigDTO
+	if err := json.Unmarshal([]byte(`{"mode":2,"auxiliary_fragments":{"enabled":true,"scope":"document","question_count":5,"model_name":"qwen-plus"}}`), &dto); err != nil {
+		t.Fatalf("unmarshal dto: %v", err)
+	}
+	cfg := FragmentConfigDTOToEntity(&dto)
+	if cfg == nil || cfg.AuxiliaryFragments == nil || !cfg.AuxiliaryFragments.Enabled {
+		t.Fatalf("expected auxiliary fragments config, got %#v", cfg)
+	}
+	if cfg.AuxiliaryFragments.Scope != shared.AuxiliaryFragmentScopeDocument || cfg.AuxiliaryFragments.QuestionCount != 5 {
+		t.Fatalf("unexpected auxiliary fragments config: %#v", cfg.AuxiliaryFragments)
+	}
+
+	dto.AuxiliaryFragments.QuestionCount = 1
+	if cfg.AuxiliaryFragments.QuestionCount != 5 {
+		t.Fatalf("expected auxiliary fragments config to be cloned, got %#v", cfg.AuxiliaryFragments)
+	}
+	outputDTO := FragmentConfigEntityToOutputDTO(cfg)
+	if outputDTO == nil || outputDTO.AuxiliaryFragments == nil || outputDTO.AuxiliaryFragments.ModelName != "qwen-plus" {
+		t.Fatalf("expected auxiliary fragments in output, got %#v", outputDTO)
+	}
+}
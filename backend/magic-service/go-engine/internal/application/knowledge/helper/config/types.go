@@ -220,19 +220,23 @@ func decodeCompatOptionalObjectField[T any](raw map[string]json.RawMessage, key
 
 // RetrieveConfigDTO 检索配置 DTO。
 type RetrieveConfigDTO struct {
-	Version               int                      `json:"version,omitempty"`
-	SearchMethod          string                   `json:"search_method,omitempty"`
-	TopK                  int                      `json:"top_k"`
-	ScoreThreshold        float64                  `json:"score_threshold"`
-	ScoreThresholdEnabled bool                     `json:"score_threshold_enabled"`
-	RerankingMode         string                   `json:"reranking_mode,omitempty"`
-	RerankingEnable       bool                     `json:"reranking_enable"`
-	Weights               *RetrieveWeightsDTO      `json:"weights,omitempty"`
-	RerankingModel        *RerankingModelConfigDTO `json:"reranking_model,omitempty"`
-	RerankEnabled         bool                     `json:"rerank_enabled,omitempty"`
-	HybridAlpha           float64                  `json:"hybrid_alpha,omitempty"`
-	HybridBeta            float64                  `json:"hybrid_beta,omitempty"`
-	HybridTopKMultiplier  int                      `json:"hybrid_top_k_multiplier,omitempty"`
+	Version               int                            `json:"version,omitempty"`
+	SearchMethod          string                         `json:"search_method,omitempty"`
+	TopK                  int                            `json:"top_k"`
+	ScoreThreshold        float64                        `json:"score_threshold"`
+	ScoreThresholdEnabled bool                           `json:"score_threshold_enabled"`
+	RerankingMode         string                         `json:"reranking_mode,omitempty"`
+	RerankingEnable       bool                           `json:"reranking_enable"`
+	Weights               *RetrieveWeightsDTO            `json:"weights,omitempty"`
+	RerankingModel        *RerankingModelConfigDTO       `json:"reranking_model,omitempty"`
+	RerankEnabled         bool                           `json:"rerank_enabled,omitempty"`
+	HybridAlpha           float64                        `json:"hybrid_alpha,omitempty"`
+	HybridBeta            float64                        `json:"hybrid_beta,omitempty"`
+	HybridTopKMultiplier  int                            `json:"hybrid_top_k_multiplier,omitempty"`
+	FusionAlgorithm       string                         `json:"fusion_algorithm,omitempty"`
+	FusionRRFK            int                            `json:"fusion_rrf_k,omitempty"`
+	QueryExpansion        *QueryExpansionConfigDTO       `json:"query_expansion,omitempty"`
+	ContextualEnrichment  *ContextualEnrichmentConfigDTO `json:"contextual_enrichment,omitempty"`
 }
 
 // UnmarshalJSON 兼容历史空对象脏值。
@@ -282,18 +286,6 @@ func (c *RetrieveConfigDTO) UnmarshalJSON(data []byte) error {
 	if err != nil {
 		return err
 	}
-	hybridAlpha, err := decodeCompatFloat64Field(raw, "hybrid_alpha")
-	if err != nil {
-		return err
-	}
-	hybridBeta, err := decodeCompatFloat64Field(raw, "hybrid_beta")
-	if err != nil {
-		return err
-	}
-	hybridTopKMultiplier, err := decodeCompatIntField(raw, "hybrid_top_k_multiplier")
-	if err != nil {
-		return err
-	}
 
 	*c = RetrieveConfigDTO{
 		Version:               version,
@@ -306,9 +298,40 @@ func (c *RetrieveConfigDTO) UnmarshalJSON(data []byte) error {
 		Weights:               weights,
 		RerankingModel:        rerankingModel,
 		RerankEnabled:         rerankEnabled,
-		HybridAlpha:           hybridAlpha,
-		HybridBeta:            hybridBeta,
-		HybridTopKMultiplier:  hybridTopKMultiplier,
+	}
+	if err := c.decodeFusionFields(raw); err != nil {
+		return err
+	}
+	queryExpansion, _, err := decodeCompatOptionalObjectField[QueryExpansionConfigDTO](raw, "query_expansion")
+	if err != nil {
+		return err
+	}
+	c.QueryExpansion = queryExpansion
+	contextualEnrichment, _, err := decodeCompatOptionalObjectField[ContextualEnrichmentConfigDTO](raw, "contextual_enrichment")
+	if err != nil {
+		return err
+	}
+	c.ContextualEnrichment = contextualEnrichment
+	return nil
+}
+
+// decodeFusionFields 解码混合检索融合相关字段。
+func (c *RetrieveConfigDTO) decodeFusionFields(raw map[string]json.RawMessage) error {
+	var err error
+	if c.HybridAlpha, err = decodeCompatFloat64Field(raw, "hybrid_alpha"); err != nil {
+		return err
+	}
+	if c.HybridBeta, err = decodeCompatFloat64Field(raw, "hybrid_beta"); err != nil {
+		return err
+	}
+	if c.HybridTopKMultiplier, err = decodeCompatIntField(raw, "hybrid_top_k_multiplier"); err != nil {
+		return err
+	}
+	if c.FusionAlgorithm, err = decodeCompatStringField(raw, "fusion_algorithm"); err != nil {
+		return err
+	}
+	if c.FusionRRFK, err = decodeCompatIntField(raw, "fusion_rrf_k"); err != nil {
+		return err
 	}
 	return nil
 }
@@ -388,6 +411,7 @@ func (c *KeywordWeightSettingDTO) UnmarshalJSON(data []byte) error {
 
 // GraphWeightSettingDTO 图检索权重配置 DTO。
 type GraphWeightSettingDTO struct {
+	Enabled           bool    `json:"enabled,omitempty"`
 	RelationWeight    float64 `json:"relation_weight"`
 	MaxDepth          int     `json:"max_depth"`
 	IncludeProperties bool    `json:"include_properties"`
@@ -402,6 +426,10 @@ func (c *GraphWeightSettingDTO) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	enabled, err := decodeCompatBoolField(raw, "enabled")
+	if err != nil {
+		return err
+	}
 	relationWeight, err := decodeCompatFloat64Field(raw, "relation_weight")
 	if err != nil {
 		return err
@@ -424,6 +452,7 @@ func (c *GraphWeightSettingDTO) UnmarshalJSON(data []byte) error {
 	}
 
 	*c = GraphWeightSettingDTO{
+		Enabled:           enabled,
 		RelationWeight:    relationWeight,
 		MaxDepth:          maxDepth,
 		IncludeProperties: includeProperties,
@@ -433,6 +462,43 @@ func (c *GraphWeightSettingDTO) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// QueryExpansionConfigDTO 多查询扩展与 HyDE 改写配置 DTO。
+type QueryExpansionConfigDTO struct {
+	Enabled         bool   `json:"enabled"`
+	MultiQueryCount int    `json:"multi_query_count,omitempty"`
+	HyDEEnabled     bool   `json:"hyde_enabled,omitempty"`
+	ModelName       string `json:"model_name,omitempty"`
+}
+
+// UnmarshalJSON 兼容历史空对象脏值。
+func (c *QueryExpansionConfigDTO) UnmarshalJSON(data []byte) error {
+	type alias QueryExpansionConfigDTO
+	var decoded alias
+	if err := unmarshalCompatStruct(data, "query expansion config", &decoded); err != nil {
+		return err
+	}
+	*c = QueryExpansionConfigDTO(decoded)
+	return nil
+}
+
+// ContextualEnrichmentConfigDTO 入库片段上下文增强配置 DTO。
+type ContextualEnrichmentConfigDTO struct {
+	Enabled   bool   `json:"enabled"`
+	Mode      string `json:"mode,omitempty"`
+	ModelName string `json:"model_name,omitempty"`
+}
+
+// UnmarshalJSON 兼容历史空对象脏值。
+func (c *ContextualEnrichmentConfigDTO) UnmarshalJSON(data []byte) error {
+	type alias ContextualEnrichmentConfigDTO
+	var decoded alias
+	if err := unmarshalCompatStruct(data, "contextual enrichment config", &decoded); err != nil {
+		return err
+	}
+	*c = ContextualEnrichmentConfigDTO(decoded)
+	return nil
+}
+
 // RerankingModelConfigDTO 重排序模型配置 DTO。
 type RerankingModelConfigDTO struct {
 	RerankingModelName    string `json:"reranking_model_name,omitempty"`
@@ -493,9 +559,10 @@ func (c *StrategyConfigDTO) UnmarshalJSON(data []byte) error {
 
 // FragmentConfigDTO 片段配置 DTO。
 type FragmentConfigDTO struct {
-	Mode      int                         `json:"mode"`
-	Normal    *NormalFragmentConfigDTO    `json:"normal,omitempty"`
-	Hierarchy *HierarchyFragmentConfigDTO `json:"hierarchy,omitempty"`
+	Mode               int                         `json:"mode"`
+	Normal             *NormalFragmentConfigDTO    `json:"normal,omitempty"`
+	Hierarchy          *HierarchyFragmentConfigDTO `json:"hierarchy,omitempty"`
+	AuxiliaryFragments *AuxiliaryFragmentConfigDTO `json:"auxiliary_fragments,omitempty"`
 }
 
 // UnmarshalJSON 兼容历史空对象脏值。
@@ -517,15 +584,39 @@ func (c *FragmentConfigDTO) UnmarshalJSON(data []byte) error {
 	if err != nil {
 		return err
 	}
+	auxiliaryFragments, _, err := decodeCompatOptionalObjectField[AuxiliaryFragmentConfigDTO](raw, "auxiliary_fragments")
+	if err != nil {
+		return err
+	}
 
 	*c = FragmentConfigDTO{
-		Mode:      mode,
-		Normal:    normal,
-		Hierarchy: hierarchy,
+		Mode:               mode,
+		Normal:             normal,
+		Hierarchy:          hierarchy,
+		AuxiliaryFragments: auxiliaryFragments,
 	}
 	return nil
 }
 
+// AuxiliaryFragmentConfigDTO 摘要与常见问题辅助片段配置 DTO。
+type AuxiliaryFragmentConfigDTO struct {
+	Enabled       bool   `json:"enabled"`
+	Scope         string `json:"scope,omitempty"`
+	QuestionCount int    `json:"question_count,omitempty"`
+	ModelName     string `json:"model_name,omitempty"`
+}
+
+// UnmarshalJSON 兼容历史空对象脏值。
+func (c *AuxiliaryFragmentConfigDTO) UnmarshalJSON(data []byte) error {
+	type alias AuxiliaryFragmentConfigDTO
+	var decoded alias
+	if err := unmarshalCompatStruct(data, "auxiliary fragment config", &decoded); err != nil {
+		return err
+	}
+	*c = AuxiliaryFragmentConfigDTO(decoded)
+	return nil
+}
+
 // NormalFragmentConfigDTO 普通分段配置 DTO。
 type NormalFragmentConfigDTO struct {
 	TextPreprocessRule []int           `json:"text_preprocess_rule"`
@@ -633,9 +724,10 @@ func (c *HierarchyFragmentConfigDTO) UnmarshalJSON(data []byte) error {
 
 // FragmentConfigOutputDTO 片段配置输出 DTO。
 type FragmentConfigOutputDTO struct {
-	Mode      int                            `json:"mode"`
-	Normal    *NormalFragmentConfigOutputDTO `json:"normal,omitempty"`
-	Hierarchy *HierarchyFragmentConfigDTO    `json:"hierarchy,omitempty"`
+	Mode               int                            `json:"mode"`
+	Normal             *NormalFragmentConfigOutputDTO `json:"normal,omitempty"`
+	Hierarchy          *HierarchyFragmentConfigDTO    `json:"hierarchy,omitempty"`
+	AuxiliaryFragments *AuxiliaryFragmentConfigDTO    `json:"auxiliary_fragments,omitempty"`
 }
 
 // NormalFragmentConfigOutputDTO 普通分段配置输出 DTO。
@@ -757,6 +849,8 @@ func RetrieveConfigDTOToEntity(cfg *RetrieveConfigDTO) *domainshared.RetrieveCon
 		HybridAlpha:           cfg.HybridAlpha,
 		HybridBeta:            cfg.HybridBeta,
 		HybridTopKMultiplier:  cfg.HybridTopKMultiplier,
+		FusionAlgorithm:       cfg.FusionAlgorithm,
+		FusionRRFK:            cfg.FusionRRFK,
 	}
 	if cfg.Weights != nil {
 		result.Weights = &domainshared.RetrieveWeights{}
@@ -774,6 +868,7 @@ func RetrieveConfigDTOToEntity(cfg *RetrieveConfigDTO) *domainshared.RetrieveCon
 		}
 		if cfg.Weights.GraphSetting != nil {
 			result.Weights.GraphSetting = &domainshared.GraphWeightSetting{
+				Enabled:           cfg.Weights.GraphSetting.Enabled,
 				RelationWeight:    cfg.Weights.GraphSetting.RelationWeight,
 				MaxDepth:          cfg.Weights.GraphSetting.MaxDepth,
 				IncludeProperties: cfg.Weights.GraphSetting.IncludeProperties,
@@ -788,6 +883,17 @@ func RetrieveConfigDTOToEntity(cfg *RetrieveConfigDTO) *domainshared.RetrieveCon
 			RerankingProviderName: cfg.RerankingModel.RerankingProviderName,
 		}
 	}
+	if cfg.QueryExpansion != nil {
+		queryExpansion := domainshared.QueryExpansionConfig(*cfg.QueryExpansion)
+		result.QueryExpansion = &queryExpansion
+	}
+	if cfg.ContextualEnrichment != nil {
+		result.ContextualEnrichment = &domainshared.ContextualEnrichmentConfig{
+			Enabled:   cfg.ContextualEnrichment.Enabled,
+			Mode:      domainshared.ContextualEnrichmentMode(cfg.ContextualEnrichment.Mode),
+			ModelName: cfg.ContextualEnrichment.ModelName,
+		}
+	}
 	return result
 }
 
@@ -808,6 +914,8 @@ func RetrieveConfigEntityToDTO(cfg *domainshared.RetrieveConfig) *RetrieveConfig
 		HybridAlpha:           cfg.HybridAlpha,
 		HybridBeta:            cfg.HybridBeta,
 		HybridTopKMultiplier:  cfg.HybridTopKMultiplier,
+		FusionAlgorithm:       cfg.FusionAlgorithm,
+		FusionRRFK:            cfg.FusionRRFK,
 	}
 	if cfg.Weights != nil {
 		result.Weights = &RetrieveWeightsDTO{}
@@ -825,6 +933,7 @@ func RetrieveConfigEntityToDTO(cfg *domainshared.RetrieveConfig) *RetrieveConfig
 		}
 		if cfg.Weights.GraphSetting != nil {
 			result.Weights.GraphSetting = &GraphWeightSettingDTO{
+				Enabled:           cfg.Weights.GraphSetting.Enabled,
 				RelationWeight:    cfg.Weights.GraphSetting.RelationWeight,
 				MaxDepth:          cfg.Weights.GraphSetting.MaxDepth,
 				IncludeProperties: cfg.Weights.GraphSetting.IncludeProperties,
@@ -839,5 +948,16 @@ func RetrieveConfigEntityToDTO(cfg *domainshared.RetrieveConfig) *RetrieveConfig
 			RerankingProviderName: cfg.RerankingModel.RerankingProviderName,
 		}
 	}
+	if cfg.QueryExpansion != nil {
+		queryExpansion := QueryExpansionConfigDTO(*cfg.QueryExpansion)
+		result.QueryExpansion = &queryExpansion
+	}
+	if cfg.ContextualEnrichment != nil {
+		result.ContextualEnrichment = &ContextualEnrichmentConfigDTO{
+			Enabled:   cfg.ContextualEnrichment.Enabled,
+			Mode:      string(cfg.ContextualEnrichment.Mode),
+			ModelName: cfg.ContextualEnrichment.ModelName,
+		}
+	}
 	return result
 }
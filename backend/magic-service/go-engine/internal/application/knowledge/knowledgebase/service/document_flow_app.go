@@ -47,6 +47,7 @@ type knowledgeBaseDocumentSyncScheduler interface {
 type KnowledgeBaseDocumentFlowApp struct {
 	support          *KnowledgeBaseAppService
 	managedDocuments knowledgeBaseManagedDocumentStore
+	archiveEntries   archiveEntryLister
 }
 
 // NewKnowledgeBaseDocumentFlowApp 创建知识库文档协作 flow app。
@@ -57,7 +58,7 @@ func NewKnowledgeBaseDocumentFlowApp(
 	syncScheduler knowledgeBaseDocumentSyncScheduler,
 	parseService *documentdomain.ParseService,
 ) *KnowledgeBaseDocumentFlowApp {
-	return &KnowledgeBaseDocumentFlowApp{
+	flow := &KnowledgeBaseDocumentFlowApp{
 		support: support,
 		managedDocuments: knowledgeBaseDomainManagedDocumentStore{
 			support:         support,
@@ -67,6 +68,10 @@ func NewKnowledgeBaseDocumentFlowApp(
 			parseService:    parseService,
 		},
 	}
+	if parseService != nil {
+		flow.archiveEntries = parseService
+	}
+	return flow
 }
 
 // SetDocumentFlowApp 注入知识库文档协作 flow app。
@@ -444,6 +449,7 @@ func (s knowledgeBaseDomainManagedDocumentStore) ScheduleManagedDocumentSync(ctx
 		Mode:              input.Mode,
 		Async:             true,
 		BusinessParams:    input.BusinessParams,
+		SourceOverride:    input.SourceOverride,
 	})
 }
 
@@ -0,0 +1,154 @@
+package kbapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"magic/internal/constants"
+	sourcebindingdomain "magic/internal/domain/knowledge/sourcebinding/entity"
+	"magic/internal/infrastructure/logging"
+	lockpkg "magic/internal/pkg/lock"
+	"magic/internal/pkg/logkey"
+)
+
+const (
+	// Git 同步巡检间隔；HEAD 未变化的绑定只做一次 fetch，不会触发重同步。
+	defaultGitSourceSyncCheckInterval = 5 * time.Minute
+	gitSourceSyncLockAcquireTimeout   = 2 * time.Second
+)
+
+var (
+	// ErrGitSourceSyncJobRunnerRequired 表示缺少单 pod 任务执行器。
+	ErrGitSourceSyncJobRunnerRequired = errors.New("git source sync single pod job runner is required")
+	// ErrGitSourceSyncRepositoryRequired 表示缺少 Git 绑定查询依赖。
+	ErrGitSourceSyncRepositoryRequired = errors.New("git source sync binding repository is required")
+)
+
+// GitSourceSyncBindingRepository 定义 Git 来源定时同步所需的来源绑定读写能力。
+type GitSourceSyncBindingRepository interface {
+	gitSyncBindingRepository
+	ListRealtimeBindingsByProvider(ctx context.Context, provider string) ([]sourcebindingdomain.Binding, error)
+}
+
+// GitSourceSyncService 定时按提交差异增量同步实时 Git 来源绑定。
+type GitSourceSyncService struct {
+	support       *KnowledgeBaseAppService
+	bindings      GitSourceSyncBindingRepository
+	jobRunner     lockpkg.SinglePodJobRunner
+	scheduler     *cron.Cron
+	checkInterval time.Duration
+	logger        *logging.SugaredLogger
+	now           func() time.Time
+}
+
+// NewGitSourceSyncService 创建 Git 来源定时同步服务。
+func NewGitSourceSyncService(
+	support *KnowledgeBaseAppService,
+	bindings GitSourceSyncBindingRepository,
+	jobRunner lockpkg.SinglePodJobRunner,
+	logger *logging.SugaredLogger,
+) (*GitSourceSyncService, error) {
+	if jobRunner == nil {
+		return nil, ErrGitSourceSyncJobRunnerRequired
+	}
+	if bindings == nil {
+		return nil, ErrGitSourceSyncRepositoryRequired
+	}
+	return &GitSourceSyncService{
+		support:       support,
+		bindings:      bindings,
+		jobRunner:     jobRunner,
+		scheduler:     cron.New(cron.WithLocation(time.Local)),
+		checkInterval: defaultGitSourceSyncCheckInterval,
+		logger:        logger,
+		now:           time.Now,
+	}, nil
+}
+
+// StartSyncDaemon 启动 Git 来源同步守护进程，直到 ctx 取消。
+func (s *GitSourceSyncService) StartSyncDaemon(ctx context.Context) error {
+	spec := "@every " + s.checkInterval.String()
+	if _, err := s.scheduler.AddFunc(spec, func() {
+		if err := s.runScheduledSync(ctx); err != nil {
+			s.logger.KnowledgeErrorContext(ctx, "Git source sync job failed", logkey.Error, err)
+		}
+	}); err != nil {
+		return fmt.Errorf("failed to schedule git source sync job: %w", err)
+	}
+	s.scheduler.Start()
+	s.logger.DebugContext(ctx, "Git source sync daemon started")
+
+	<-ctx.Done()
+	s.scheduler.Stop()
+	s.logger.DebugContext(ctx, "Git source sync daemon stopped")
+	return nil
+}
+
+// SyncDueBindings 同步所有 HEAD 已变化的实时 Git 绑定，返回实际同步的绑定数。
+//
+// 单个绑定失败只记录日志，不影响其他绑定；失败的绑定不会推进已同步提交，下次巡检会重试。
+func (s *GitSourceSyncService) SyncDueBindings(ctx context.Context) (int, error) {
+	flow, err := s.support.requireDocumentFlow()
+	if err != nil {
+		return 0, err
+	}
+	bindings, err := s.bindings.ListRealtimeBindingsByProvider(ctx, sourcebindingdomain.ProviderGit)
+	if err != nil {
+		return 0, fmt.Errorf("list realtime git bindings: %w", err)
+	}
+	synced := 0
+	for _, binding := range bindings {
+		if err := ctx.Err(); err != nil {
+			return synced, fmt.Errorf("sync git bindings: %w", err)
+		}
+		done, err := flow.syncGitBinding(ctx, binding, s.bindings, s.now())
+		if err != nil {
+			s.logger.KnowledgeWarnContext(
+				ctx,
+				"Sync git source binding failed",
+				"knowledge_base_code", binding.KnowledgeBaseCode,
+				"binding_id", binding.ID,
+				logkey.Error, err,
+			)
+			continue
+		}
+		if done {
+			synced++
+		}
+	}
+	return synced, nil
+}
+
+func (s *GitSourceSyncService) runScheduledSync(ctx context.Context) error {
+	result, err := s.jobRunner.Run(ctx, lockpkg.SinglePodJobRequest{
+		LockKey:        constants.KnowledgeGitSourceSyncJobLockKey,
+		AcquireTimeout: gitSourceSyncLockAcquireTimeout,
+	}, func(jobCtx context.Context) error {
+		synced, err := s.SyncDueBindings(jobCtx)
+		if synced > 0 {
+			s.logger.InfoContext(jobCtx, "Scheduled git source sync completed", "synced", synced)
+		}
+		return err
+	})
+
+	switch result.Status {
+	case lockpkg.SinglePodJobStatusSkippedLocked:
+		return nil
+	case lockpkg.SinglePodJobStatusSkippedRedisUnavailable, lockpkg.SinglePodJobStatusAbortedLockLost:
+		fields := []any{"lock_key", constants.KnowledgeGitSourceSyncJobLockKey, "status", result.Status}
+		if err != nil {
+			fields = append(fields, logkey.Error, err)
+		}
+		s.logger.KnowledgeWarnContext(ctx, "Skip scheduled git source sync because lock is unavailable", fields...)
+		return nil
+	default:
+		if err != nil {
+			return fmt.Errorf("run scheduled git source sync with distributed lock: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,154 @@
+package kbapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"magic/internal/constants"
+	sourcebindingdomain "magic/internal/domain/knowledge/sourcebinding/entity"
+	"magic/internal/infrastructure/logging"
+	lockpkg "magic/internal/pkg/lock"
+	"magic/internal/pkg/logkey"
+)
+
+const (
+	// 对象存储巡检间隔；每个绑定按 poll_interval_minutes 判断是否到期，未到期的绑定不会访问存储。
+	defaultObjectStorageSyncCheckInterval = time.Minute
+	objectStorageSyncLockAcquireTimeout   = 2 * time.Second
+)
+
+var (
+	// ErrObjectStorageSyncJobRunnerRequired 表示缺少单 pod 任务执行器。
+	ErrObjectStorageSyncJobRunnerRequired = errors.New("object storage sync single pod job runner is required")
+	// ErrObjectStorageSyncRepositoryRequired 表示缺少对象存储绑定查询依赖。
+	ErrObjectStorageSyncRepositoryRequired = errors.New("object storage sync binding repository is required")
+)
+
+// ObjectStorageSyncBindingRepository 定义对象存储来源定时同步所需的来源绑定查询能力。
+type ObjectStorageSyncBindingRepository interface {
+	sourceItemRefLister
+	ListRealtimeBindingsByProvider(ctx context.Context, provider string) ([]sourcebindingdomain.Binding, error)
+}
+
+// ObjectStorageSyncService 定时列举实时对象存储绑定，按 ETag 增量同步桶前缀下的对象。
+type ObjectStorageSyncService struct {
+	support       *KnowledgeBaseAppService
+	bindings      ObjectStorageSyncBindingRepository
+	jobRunner     lockpkg.SinglePodJobRunner
+	scheduler     *cron.Cron
+	checkInterval time.Duration
+	logger        *logging.SugaredLogger
+	now           func() time.Time
+}
+
+// NewObjectStorageSyncService 创建对象存储来源定时同步服务。
+func NewObjectStorageSyncService(
+	support *KnowledgeBaseAppService,
+	bindings ObjectStorageSyncBindingRepository,
+	jobRunner lockpkg.SinglePodJobRunner,
+	logger *logging.SugaredLogger,
+) (*ObjectStorageSyncService, error) {
+	if jobRunner == nil {
+		return nil, ErrObjectStorageSyncJobRunnerRequired
+	}
+	if bindings == nil {
+		return nil, ErrObjectStorageSyncRepositoryRequired
+	}
+	return &ObjectStorageSyncService{
+		support:       support,
+		bindings:      bindings,
+		jobRunner:     jobRunner,
+		scheduler:     cron.New(cron.WithLocation(time.Local)),
+		checkInterval: defaultObjectStorageSyncCheckInterval,
+		logger:        logger,
+		now:           time.Now,
+	}, nil
+}
+
+// StartSyncDaemon 启动对象存储来源同步守护进程，直到 ctx 取消。
+func (s *ObjectStorageSyncService) StartSyncDaemon(ctx context.Context) error {
+	spec := "@every " + s.checkInterval.String()
+	if _, err := s.scheduler.AddFunc(spec, func() {
+		if err := s.runScheduledSync(ctx); err != nil {
+			s.logger.KnowledgeErrorContext(ctx, "Object storage source sync job failed", logkey.Error, err)
+		}
+	}); err != nil {
+		return fmt.Errorf("failed to schedule object storage source sync job: %w", err)
+	}
+	s.scheduler.Start()
+	s.logger.DebugContext(ctx, "Object storage source sync daemon started")
+
+	<-ctx.Done()
+	s.scheduler.Stop()
+	s.logger.DebugContext(ctx, "Object storage source sync daemon stopped")
+	return nil
+}
+
+// SyncDueBindings 同步所有轮询到期的实时对象存储绑定，返回实际同步的绑定数。
+//
+// 单个绑定失败只记录日志，不影响其他绑定；失败的绑定不会刷新解析时间，下次巡检会重试。
+func (s *ObjectStorageSyncService) SyncDueBindings(ctx context.Context) (int, error) {
+	flow, err := s.support.requireDocumentFlow()
+	if err != nil {
+		return 0, err
+	}
+	bindings, err := s.bindings.ListRealtimeBindingsByProvider(ctx, sourcebindingdomain.ProviderObjectStorage)
+	if err != nil {
+		return 0, fmt.Errorf("list realtime object storage bindings: %w", err)
+	}
+	synced := 0
+	for _, binding := range bindings {
+		if err := ctx.Err(); err != nil {
+			return synced, fmt.Errorf("sync object storage bindings: %w", err)
+		}
+		done, err := flow.syncObjectStorageBinding(ctx, binding, s.bindings, s.now())
+		if err != nil {
+			s.logger.KnowledgeWarnContext(
+				ctx,
+				"Sync object storage source binding failed",
+				"knowledge_base_code", binding.KnowledgeBaseCode,
+				"binding_id", binding.ID,
+				logkey.Error, err,
+			)
+			continue
+		}
+		if done {
+			synced++
+		}
+	}
+	return synced, nil
+}
+
+func (s *ObjectStorageSyncService) runScheduledSync(ctx context.Context) error {
+	result, err := s.jobRunner.Run(ctx, lockpkg.SinglePodJobRequest{
+		LockKey:        constants.KnowledgeObjectStorageSyncJobLockKey,
+		AcquireTimeout: objectStorageSyncLockAcquireTimeout,
+	}, func(jobCtx context.Context) error {
+		synced, err := s.SyncDueBindings(jobCtx)
+		if synced > 0 {
+			s.logger.InfoContext(jobCtx, "Scheduled object storage source sync completed", "synced", synced)
+		}
+		return err
+	})
+
+	switch result.Status {
+	case lockpkg.SinglePodJobStatusSkippedLocked:
+		return nil
+	case lockpkg.SinglePodJobStatusSkippedRedisUnavailable, lockpkg.SinglePodJobStatusAbortedLockLost:
+		fields := []any{"lock_key", constants.KnowledgeObjectStorageSyncJobLockKey, "status", result.Status}
+		if err != nil {
+			fields = append(fields, logkey.Error, err)
+		}
+		s.logger.KnowledgeWarnContext(ctx, "Skip scheduled object storage source sync because lock is unavailable", fields...)
+		return nil
+	default:
+		if err != nil {
+			return fmt.Errorf("run scheduled object storage source sync with distributed lock: %w", err)
+		}
+		return nil
+	}
+}
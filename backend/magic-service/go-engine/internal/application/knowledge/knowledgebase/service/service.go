@@ -13,6 +13,7 @@ import (
 	userdomain "magic/internal/domain/contact/user"
 	kbaccess "magic/internal/domain/knowledge/access/service"
 	docentity "magic/internal/domain/knowledge/document/entity"
+	documentdomain "magic/internal/domain/knowledge/document/service"
 	fragdomain "magic/internal/domain/knowledge/fragment/service"
 	kbentity "magic/internal/domain/knowledge/knowledgebase/entity"
 	kbrepository "magic/internal/domain/knowledge/knowledgebase/repository"
@@ -53,6 +54,12 @@ var (
 	ErrKnowledgeBaseBindingRepositoryRequired = errors.New("knowledge base binding repository is required")
 	// ErrKnowledgeBaseThirdPlatformExpanderRequired 表示缺少第三方来源展开端口依赖。
 	ErrKnowledgeBaseThirdPlatformExpanderRequired = errors.New("knowledge base third-platform expander is required")
+	// ErrKnowledgeBaseWebsiteCrawlerRequired 表示缺少网站来源爬虫依赖。
+	ErrKnowledgeBaseWebsiteCrawlerRequired = errors.New("knowledge base website crawler is required")
+	// ErrKnowledgeBaseGitRepositoryRequired 表示缺少 Git 仓库来源依赖。
+	ErrKnowledgeBaseGitRepositoryRequired = errors.New("knowledge base git repository is required")
+	// ErrKnowledgeBaseObjectStorageRequired 表示缺少对象存储来源依赖。
+	ErrKnowledgeBaseObjectStorageRequired = errors.New("knowledge base object storage lister is required")
 	// ErrKnowledgeBaseSuperMagicAgentReaderRequired 表示缺少数字员工只读依赖。
 	ErrKnowledgeBaseSuperMagicAgentReaderRequired = errors.New("knowledge base super magic agent reader is required")
 	// ErrKnowledgeBaseSuperMagicAgentAccessCheckerRequired 表示缺少数字员工可管理性校验依赖。
@@ -79,6 +86,10 @@ var (
 	ErrMissingProjectSourceBindings = errors.New("missing_project_source_bindings")
 	// ErrUnsupportedSourceBindingProvider 表示当前来源提供方不受支持。
 	ErrUnsupportedSourceBindingProvider = errors.New("unsupported source binding provider")
+	// ErrUploadBatchArchiveListerRequired 表示展开上传压缩包缺少条目列举依赖。
+	ErrUploadBatchArchiveListerRequired = errors.New("upload batch archive lister is required")
+	// ErrUnsupportedUploadBatchArchive 表示上传批次的文件不是可展开的压缩包。
+	ErrUnsupportedUploadBatchArchive = errors.New("unsupported upload batch archive")
 	// ErrInvalidProjectRootRef 表示 project 绑定的 root_ref 非法。
 	ErrInvalidProjectRootRef = sourcebindingdomain.ErrInvalidProjectRootRef
 	// ErrInvalidSourceBindingNodesSourceType 表示来源绑定节点查询 source_type 非法。
@@ -194,6 +205,7 @@ type SyncDocumentInput struct {
 	Code              string
 	Mode              string
 	BusinessParams    *ctxmeta.BusinessParams
+	SourceOverride    *documentdomain.SourceOverride
 }
 
 type (
@@ -405,6 +417,9 @@ type KnowledgeBaseAppService struct {
 	projectFilePort              projectFileResolver
 	taskFileService              taskFileService
 	thirdPlatformExpander        thirdPlatformBindingExpander
+	websiteCrawler               sourcebindingdomain.WebsiteCrawler
+	gitRepository                sourcebindingdomain.GitRepository
+	objectStorage                sourcebindingdomain.ObjectStorageLister
 	teamshareTempCodes           TeamshareTempCodeMapper
 	knowledgeBaseBindings        knowledgeBaseBindingRepository
 	superMagicAgents             superMagicAgentReader
@@ -526,6 +541,30 @@ func (s *KnowledgeBaseAppService) SetThirdPlatformExpander(expander thirdPlatfor
 	s.thirdPlatformExpander = expander
 }
 
+// SetWebsiteCrawler 注入网站来源爬虫。
+func (s *KnowledgeBaseAppService) SetWebsiteCrawler(crawler sourcebindingdomain.WebsiteCrawler) {
+	if s == nil {
+		return
+	}
+	s.websiteCrawler = crawler
+}
+
+// SetGitRepository 注入 Git 仓库来源。
+func (s *KnowledgeBaseAppService) SetGitRepository(repository sourcebindingdomain.GitRepository) {
+	if s == nil {
+		return
+	}
+	s.gitRepository = repository
+}
+
+// SetObjectStorageLister 注入对象存储来源列举能力。
+func (s *KnowledgeBaseAppService) SetObjectStorageLister(lister sourcebindingdomain.ObjectStorageLister) {
+	if s == nil {
+		return
+	}
+	s.objectStorage = lister
+}
+
 // SetTeamshareTempCodeMapper 注入 Teamshare 临时 knowledge_code 映射依赖。
 func (s *KnowledgeBaseAppService) SetTeamshareTempCodeMapper(mapper TeamshareTempCodeMapper) {
 	if s == nil {
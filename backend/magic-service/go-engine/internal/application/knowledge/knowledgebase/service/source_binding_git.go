@@ -0,0 +1,249 @@
+package kbapp
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+	documentdomain "magic/internal/domain/knowledge/document/service"
+	sourcebindingdomain "magic/internal/domain/knowledge/sourcebinding/entity"
+	sourcebindingservice "magic/internal/domain/knowledge/sourcebinding/service"
+	"magic/internal/pkg/logkey"
+)
+
+const (
+	gitResolveReasonTree    = "git_tree"
+	gitFileDocumentFileType = "external"
+)
+
+// gitSyncBindingRepository 表示 Git 增量同步读取旧快照与回写已同步提交所需的能力。
+type gitSyncBindingRepository interface {
+	sourceItemRefLister
+	UpdateBindingSyncConfig(ctx context.Context, bindingID int64, syncConfig map[string]any) error
+}
+
+func (s *KnowledgeBaseDocumentFlowApp) resolveGitBindingDocuments(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	maxDocuments int,
+) ([]sourcebindingservice.ResolvedDocument, error) {
+	config, err := sourcebindingservice.ParseGitSourceConfig(binding)
+	if err != nil {
+		return nil, fmt.Errorf("parse git source config: %w", err)
+	}
+	tree, err := s.resolveGitTree(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return gitTreeResolvedDocuments(config, tree, maxDocuments), nil
+}
+
+func (s *KnowledgeBaseDocumentFlowApp) resolveGitTree(
+	ctx context.Context,
+	config sourcebindingservice.GitSourceConfig,
+) (*sourcebindingdomain.GitTree, error) {
+	if s == nil || s.support == nil || s.support.gitRepository == nil {
+		return nil, ErrKnowledgeBaseGitRepositoryRequired
+	}
+	tree, err := s.support.gitRepository.ResolveTree(ctx, config.RepositoryURL, config.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("resolve git tree: %w", err)
+	}
+	return tree, nil
+}
+
+// gitTreeResolvedDocuments 把文件树转换为文档物料；不支持的扩展名直接忽略，超限文件记录跳过原因。
+func gitTreeResolvedDocuments(
+	config sourcebindingservice.GitSourceConfig,
+	tree *sourcebindingdomain.GitTree,
+	maxDocuments int,
+) []sourcebindingservice.ResolvedDocument {
+	documents := make([]sourcebindingservice.ResolvedDocument, 0, len(tree.Files))
+	accepted := 0
+	for _, file := range tree.Files {
+		if !config.AllowsPath(file.Path) || !docentity.IsSupportedKnowledgeBaseFileExtension(sourceFileExtension(file.Path)) {
+			continue
+		}
+		document := gitFileResolvedDocument(config, tree.Commit, file)
+		switch {
+		case file.Size > config.MaxFileBytes:
+			document.SkipReason = sourcebindingservice.GitFileSkipTooLarge
+		case maxDocuments > 0 && accepted >= maxDocuments:
+			document.SkipReason = sourcebindingservice.GitFileSkipFileLimit
+		default:
+			accepted++
+		}
+		documents = append(documents, document)
+	}
+	return documents
+}
+
+func gitFileResolvedDocument(
+	config sourcebindingservice.GitSourceConfig,
+	commit string,
+	file sourcebindingdomain.GitTreeFile,
+) sourcebindingservice.ResolvedDocument {
+	name := path.Base(file.Path)
+	extension := sourceFileExtension(file.Path)
+	return sourcebindingservice.ResolvedDocument{
+		Name: name,
+		DocumentFile: &docentity.File{
+			Type:       gitFileDocumentFileType,
+			Name:       name,
+			URL:        sourcebindingdomain.RedactGitRepositoryURL(config.RepositoryURL),
+			Size:       file.Size,
+			Extension:  extension,
+			SourceType: sourcebindingdomain.ProviderGit,
+		},
+		DocumentType:  int(docentity.DocumentInputKindFile),
+		ItemRef:       sourcebindingservice.GitFileItemRef(config, file.Path),
+		GroupRef:      sourcebindingservice.GitFileGroupRef(file.Path),
+		Extension:     extension,
+		ResolveReason: gitResolveReasonTree,
+		SnapshotMeta:  sourcebindingservice.BuildGitFileSnapshotMeta(config, commit, file),
+		DocMetadata:   documentdomain.BuildGitFileDocMetadata(config.Ref, file.Path),
+	}
+}
+
+// syncGitBinding 在仓库 HEAD 变化时增量同步 Git 绑定：新增/删除文件走增量计划，内容变化的文件重同步。
+//
+// 变化文件优先按“已同步提交..HEAD”的 diff 计算；没有已同步提交或 diff 失败时，按来源项快照里的 blob SHA 比较。
+// 返回值表示本次是否实际执行了同步。
+func (s *KnowledgeBaseDocumentFlowApp) syncGitBinding(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	repo gitSyncBindingRepository,
+	now time.Time,
+) (bool, error) {
+	if err := s.requirePolledBindingDeps(); err != nil {
+		return false, err
+	}
+	binding = sourcebindingdomain.NormalizeBinding(binding)
+	config, err := sourcebindingservice.ParseGitSourceConfig(binding)
+	if err != nil {
+		return false, fmt.Errorf("parse git source config: %w", err)
+	}
+	tree, err := s.resolveGitTree(ctx, config)
+	if err != nil {
+		return false, err
+	}
+	if !config.SyncDue(tree.Commit) {
+		return false, nil
+	}
+	prepared, changedRefs, err := s.prepareGitBindingSync(ctx, binding, config, tree, repo, now)
+	if err != nil {
+		return false, err
+	}
+	stats, err := s.applyPolledBindingChange(ctx, binding, prepared, changedRefs, now)
+	if err != nil {
+		return false, err
+	}
+	if err := repo.UpdateBindingSyncConfig(ctx, binding.ID, sourcebindingservice.WithGitIndexedCommit(binding.SyncConfig, tree.Commit)); err != nil {
+		return false, fmt.Errorf("update git indexed commit: %w", err)
+	}
+	s.logInfo(
+		ctx,
+		"Git source binding synced",
+		"knowledge_base_code", binding.KnowledgeBaseCode,
+		"binding_id", binding.ID,
+		"from_commit", config.IndexedCommit,
+		"to_commit", tree.Commit,
+		"changed_paths", len(changedRefs),
+		"created", stats.created,
+		"deleted", stats.deleted,
+		"resynced", stats.resynced,
+	)
+	return true, nil
+}
+
+func (s *KnowledgeBaseDocumentFlowApp) prepareGitBindingSync(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	config sourcebindingservice.GitSourceConfig,
+	tree *sourcebindingdomain.GitTree,
+	repo gitSyncBindingRepository,
+	now time.Time,
+) (*preparedBindingChange, map[string]struct{}, error) {
+	state, err := s.loadPolledBindingState(ctx, binding)
+	if err != nil {
+		return nil, nil, err
+	}
+	// 必须在增量解析 upsert 来源项之前计算，否则旧 blob SHA 会被新快照覆盖。
+	changedRefs, err := s.gitChangedItemRefs(ctx, binding, config, tree, state.documents, repo)
+	if err != nil {
+		return nil, nil, err
+	}
+	prepared, err := s.planPolledBindingChange(
+		ctx,
+		binding,
+		state,
+		gitTreeResolvedDocuments(config, tree, knowledgeBaseMaterializeDocumentLimit),
+		now,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prepared, changedRefs, nil
+}
+
+// gitChangedItemRefs 返回内容发生变化的文件对应的来源项 item_ref 集合。
+func (s *KnowledgeBaseDocumentFlowApp) gitChangedItemRefs(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	config sourcebindingservice.GitSourceConfig,
+	tree *sourcebindingdomain.GitTree,
+	currentDocuments []*ManagedDocument,
+	repo gitSyncBindingRepository,
+) (map[string]struct{}, error) {
+	var changedPaths []string
+	if config.IndexedCommit != "" {
+		paths, err := s.support.gitRepository.ChangedPaths(ctx, config.RepositoryURL, config.IndexedCommit, tree.Commit)
+		if err != nil {
+			// 已同步提交可能因 force push 等原因不可达，退回按 blob 比较。
+			if s.support.logger != nil {
+				s.support.logger.KnowledgeWarnContext(
+					ctx,
+					"Diff git commits failed, fallback to blob compare",
+					"binding_id", binding.ID,
+					logkey.Error, err,
+				)
+			}
+		} else {
+			changedPaths = paths
+		}
+	}
+	if changedPaths == nil {
+		previousBlobs, err := previousGitBlobs(ctx, binding, currentDocuments, repo)
+		if err != nil {
+			return nil, err
+		}
+		changedPaths = sourcebindingservice.GitChangedPathsByBlob(previousBlobs, tree.Files)
+	}
+	changedRefs := make(map[string]struct{}, len(changedPaths))
+	for _, filePath := range changedPaths {
+		changedRefs[sourcebindingservice.GitFileItemRef(config, filePath)] = struct{}{}
+	}
+	return changedRefs, nil
+}
+
+// previousGitBlobs 把旧来源项快照转换为“文件路径 -> blob SHA”。
+func previousGitBlobs(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	currentDocuments []*ManagedDocument,
+	repo gitSyncBindingRepository,
+) (map[string]string, error) {
+	snapshots, err := previousSourceItemSnapshots(ctx, binding, currentDocuments, repo)
+	if err != nil {
+		return nil, err
+	}
+	previousBlobs := make(map[string]string, len(snapshots))
+	for _, snapshot := range snapshots {
+		if filePath, _ := snapshot[sourcebindingservice.GitSnapshotMetaPath].(string); filePath != "" {
+			previousBlobs[filePath] = sourcebindingservice.GitBlobSHAFromSnapshot(snapshot)
+		}
+	}
+	return previousBlobs, nil
+}
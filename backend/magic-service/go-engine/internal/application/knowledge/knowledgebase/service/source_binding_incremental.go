@@ -176,7 +176,7 @@ func (s *KnowledgeBaseDocumentFlowApp) executeBindingChange(
 	syncUserID := firstNonEmpty(userID, knowledgeBaseUpdatedUserID(kb), knowledgeBaseCreatedUserID(kb))
 	createdDocs, pendingSyncs, err := s.createBindingChangeDocuments(
 		ctx,
-		kb,
+		kb.Code,
 		organizationCode,
 		savedBindings,
 		prepared,
@@ -280,7 +280,7 @@ func (s *KnowledgeBaseDocumentFlowApp) destroyBindingChangeDocuments(
 
 func (s *KnowledgeBaseDocumentFlowApp) createBindingChangeDocuments(
 	ctx context.Context,
-	kb *kbentity.KnowledgeBase,
+	knowledgeBaseCode string,
 	organizationCode string,
 	savedBindings []sourcebindingentity.Binding,
 	prepared *preparedBindingChange,
@@ -314,12 +314,13 @@ func (s *KnowledgeBaseDocumentFlowApp) createBindingChangeDocuments(
 		created, err := s.managedDocuments.CreateManagedDocument(ctx, &CreateManagedDocumentInput{
 			OrganizationCode:  organizationCode,
 			UserID:            item.resolvedUserID,
-			KnowledgeBaseCode: kb.Code,
+			KnowledgeBaseCode: knowledgeBaseCode,
 			SourceBindingID:   bindingID,
 			SourceItemID:      target.SourceItemID,
 			AutoAdded:         item.document.AutoAdded,
 			Name:              item.document.Name,
 			DocType:           item.document.DocumentType,
+			DocMetadata:       cloneMap(item.document.DocMetadata),
 			DocumentFile:      cloneDocumentFile(documentFile),
 			ThirdPlatformType: incrementalMaterializedThirdPlatformType(target.BindingKey),
 			ThirdFileID:       item.sourceItem.ItemRef,
@@ -331,10 +332,10 @@ func (s *KnowledgeBaseDocumentFlowApp) createBindingChangeDocuments(
 		createdDocs = append(createdDocs, created)
 		pendingSyncs = append(pendingSyncs, &SyncDocumentInput{
 			OrganizationCode:  organizationCode,
-			KnowledgeBaseCode: kb.Code,
+			KnowledgeBaseCode: knowledgeBaseCode,
 			Code:              created.Code,
 			Mode:              knowledgeBaseSyncModeCreate,
-			BusinessParams:    texthelper.BuildCreateBusinessParams(organizationCode, syncUserID, kb.Code),
+			BusinessParams:    texthelper.BuildCreateBusinessParams(organizationCode, syncUserID, knowledgeBaseCode),
 		})
 	}
 	return createdDocs, pendingSyncs, nil
@@ -639,7 +640,8 @@ func incrementalMaterializedThirdPlatformType(bindingKey string) string {
 		return ""
 	}
 	switch parts[0] {
-	case "", sourcebindingentity.ProviderLocalUpload, sourcebindingentity.ProviderProject:
+	case "", sourcebindingentity.ProviderLocalUpload, sourcebindingentity.ProviderProject, sourcebindingentity.ProviderWebsite,
+		sourcebindingentity.ProviderGit, sourcebindingentity.ProviderObjectStorage, sourcebindingentity.ProviderWebhook:
 		return ""
 	default:
 		return parts[0]
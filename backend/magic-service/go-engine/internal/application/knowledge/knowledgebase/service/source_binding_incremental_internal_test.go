@@ -6,7 +6,6 @@ import (
 
 	bindingplan "magic/internal/domain/knowledge/binding_plan"
 	docentity "magic/internal/domain/knowledge/document/entity"
-	kbentity "magic/internal/domain/knowledge/knowledgebase/entity"
 	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
 	sourcebindingservice "magic/internal/domain/knowledge/sourcebinding/service"
 )
@@ -62,7 +61,7 @@ func TestCreateBindingChangeDocumentsUsesExplicitSyncUserID(t *testing.T) {
 
 	createdDocs, pendingSyncs, err := flow.createBindingChangeDocuments(
 		context.Background(),
-		&kbentity.KnowledgeBase{Code: knowledgeCode},
+		knowledgeCode,
 		organizationCode,
 		savedBindings,
 		prepared,
@@ -121,6 +121,9 @@ func (s *KnowledgeBaseDocumentFlowApp) materializeKnowledgeBaseDocumentsWithCoun
 		Bindings:            bindings,
 		MaxDocuments:        knowledgeBaseMaterializeDocumentLimit,
 		ScheduleSync:        options.ScheduleSync,
+		Progress: func(progress sourcebindingservice.MaterializationProgress) {
+			s.logMaterializationProgress(ctx, kb.Code, progress)
+		},
 	})
 	if err != nil {
 		return 0, fmt.Errorf("materialize source binding documents: %w", err)
@@ -131,6 +134,7 @@ func (s *KnowledgeBaseDocumentFlowApp) materializeKnowledgeBaseDocumentsWithCoun
 		"knowledge_base_code", kb.Code,
 		"created_documents", len(report.CreatedDocuments),
 		"scheduled_syncs", len(report.PendingSyncs),
+		"skipped_items", len(report.SkippedItems),
 	)
 	return len(report.CreatedDocuments), nil
 }
@@ -198,6 +202,7 @@ func (m sourceBindingManagedDocumentManager) CreateManagedDocument(
 		ProjectFileID:     input.ProjectFileID,
 		Name:              input.Name,
 		DocType:           input.DocType,
+		DocMetadata:       cloneMap(input.DocMetadata),
 		DocumentFile:      cloneDocumentFile(documentFile),
 		ThirdPlatformType: strings.TrimSpace(input.ThirdPlatformType),
 		ThirdFileID:       strings.TrimSpace(input.ThirdFileID),
@@ -258,7 +263,15 @@ func (s *KnowledgeBaseDocumentFlowApp) resolveBindingDocuments(
 	case sourcebindingdomain.ProviderTeamshare:
 		return s.resolveThirdPlatformBindingDocuments(ctx, binding, organizationCode, userID, maxDocuments)
 	case sourcebindingdomain.ProviderLocalUpload:
-		return s.resolveLocalUploadBindingDocuments(binding)
+		return s.resolveLocalUploadBindingDocuments(ctx, binding, maxDocuments)
+	case sourcebindingdomain.ProviderWebsite:
+		return s.resolveWebsiteBindingDocuments(ctx, binding, maxDocuments)
+	case sourcebindingdomain.ProviderGit:
+		return s.resolveGitBindingDocuments(ctx, binding, maxDocuments)
+	case sourcebindingdomain.ProviderObjectStorage:
+		return s.resolveObjectStorageBindingDocuments(ctx, binding, maxDocuments)
+	case sourcebindingdomain.ProviderWebhook:
+		return s.resolveWebhookBindingDocuments(ctx, binding, maxDocuments)
 	default:
 		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSourceBindingProvider, binding.Provider)
 	}
@@ -963,7 +976,9 @@ func documentdomainInferExtension(file *docentity.File) string {
 }
 
 func (s *KnowledgeBaseDocumentFlowApp) resolveLocalUploadBindingDocuments(
+	ctx context.Context,
 	binding sourcebindingdomain.Binding,
+	maxDocuments int,
 ) ([]sourcebindingservice.ResolvedDocument, error) {
 	rawDocumentFile, _ := binding.SyncConfig["document_file"].(map[string]any)
 	documentFile, _, err := mapToDocumentFile(rawDocumentFile)
@@ -978,6 +993,9 @@ func (s *KnowledgeBaseDocumentFlowApp) resolveLocalUploadBindingDocuments(
 			SourceType: sourcebindingdomain.ProviderLocalUpload,
 		}
 	}
+	if sourcebindingdomain.NormalizeRootType(binding.RootType) == sourcebindingdomain.RootTypeUploadBatch {
+		return s.resolveUploadBatchBindingDocuments(ctx, binding, documentFile, maxDocuments)
+	}
 	itemRef := firstNonEmpty(strings.TrimSpace(binding.RootRef), strings.TrimSpace(documentFile.URL), strings.TrimSpace(documentFile.Name))
 	return []sourcebindingservice.ResolvedDocument{{
 		Name:          firstNonEmpty(strings.TrimSpace(documentFile.Name), itemRef),
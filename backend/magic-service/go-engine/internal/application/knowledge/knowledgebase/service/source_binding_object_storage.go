@@ -0,0 +1,197 @@
+package kbapp
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+	documentdomain "magic/internal/domain/knowledge/document/service"
+	sourcebindingdomain "magic/internal/domain/knowledge/sourcebinding/entity"
+	sourcebindingservice "magic/internal/domain/knowledge/sourcebinding/service"
+)
+
+const (
+	objectStorageResolveReasonList = "object_list"
+	objectStorageDocumentFileType  = "external"
+)
+
+func (s *KnowledgeBaseDocumentFlowApp) resolveObjectStorageBindingDocuments(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	maxDocuments int,
+) ([]sourcebindingservice.ResolvedDocument, error) {
+	config, err := sourcebindingservice.ParseObjectStorageSourceConfig(binding)
+	if err != nil {
+		return nil, fmt.Errorf("parse object storage source config: %w", err)
+	}
+	objects, err := s.listObjectStorage(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return objectStorageResolvedDocuments(config, objects, maxDocuments), nil
+}
+
+func (s *KnowledgeBaseDocumentFlowApp) listObjectStorage(
+	ctx context.Context,
+	config sourcebindingservice.ObjectStorageSourceConfig,
+) ([]sourcebindingdomain.ObjectStorageObject, error) {
+	if s == nil || s.support == nil || s.support.objectStorage == nil {
+		return nil, ErrKnowledgeBaseObjectStorageRequired
+	}
+	objects, err := s.support.objectStorage.ListObjects(ctx, config.Bucket, config.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("list objects under %s: %w", config.RootRef(), err)
+	}
+	return objects, nil
+}
+
+// objectStorageResolvedDocuments 把列举结果转换为文档物料；不支持的扩展名直接忽略，超限对象记录跳过原因。
+func objectStorageResolvedDocuments(
+	config sourcebindingservice.ObjectStorageSourceConfig,
+	objects []sourcebindingdomain.ObjectStorageObject,
+	maxDocuments int,
+) []sourcebindingservice.ResolvedDocument {
+	sorted := slices.Clone(objects)
+	slices.SortFunc(sorted, func(left, right sourcebindingdomain.ObjectStorageObject) int {
+		return strings.Compare(left.Key, right.Key)
+	})
+	documents := make([]sourcebindingservice.ResolvedDocument, 0, len(sorted))
+	accepted := 0
+	for _, object := range sorted {
+		if !config.AllowsKey(object.Key) || !docentity.IsSupportedKnowledgeBaseFileExtension(sourceFileExtension(object.Key)) {
+			continue
+		}
+		document := objectStorageResolvedDocument(config.Bucket, object)
+		switch {
+		case object.Size > config.MaxObjectBytes:
+			document.SkipReason = sourcebindingservice.ObjectStorageSkipTooLarge
+		case maxDocuments > 0 && accepted >= maxDocuments:
+			document.SkipReason = sourcebindingservice.ObjectStorageSkipObjectLimit
+		default:
+			accepted++
+		}
+		documents = append(documents, document)
+	}
+	return documents
+}
+
+func objectStorageResolvedDocument(
+	bucket string,
+	object sourcebindingdomain.ObjectStorageObject,
+) sourcebindingservice.ResolvedDocument {
+	name := path.Base(object.Key)
+	extension := sourceFileExtension(object.Key)
+	return sourcebindingservice.ResolvedDocument{
+		Name: name,
+		DocumentFile: &docentity.File{
+			Type:       objectStorageDocumentFileType,
+			Name:       name,
+			URL:        documentdomain.ObjectStorageDocumentURL(bucket, object.Key),
+			Size:       object.Size,
+			Extension:  extension,
+			SourceType: sourcebindingdomain.ProviderObjectStorage,
+		},
+		DocumentType:  int(docentity.DocumentInputKindFile),
+		ItemRef:       sourcebindingservice.ObjectStorageItemRef(bucket, object.Key),
+		GroupRef:      sourcebindingservice.ObjectStorageGroupRef(object.Key),
+		Extension:     extension,
+		ResolveReason: objectStorageResolveReasonList,
+		SnapshotMeta:  sourcebindingservice.BuildObjectStorageSnapshotMeta(bucket, object),
+		DocMetadata:   documentdomain.BuildObjectStorageDocMetadata(bucket, object.Key),
+	}
+}
+
+// syncObjectStorageBinding 在轮询到期时重新列举桶前缀：新增/删除对象走增量计划，ETag 变化的对象重同步。
+//
+// 返回值表示本次是否实际执行了同步。
+func (s *KnowledgeBaseDocumentFlowApp) syncObjectStorageBinding(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	sourceItems sourceItemRefLister,
+	now time.Time,
+) (bool, error) {
+	if err := s.requirePolledBindingDeps(); err != nil {
+		return false, err
+	}
+	binding = sourcebindingdomain.NormalizeBinding(binding)
+	config, err := sourcebindingservice.ParseObjectStorageSourceConfig(binding)
+	if err != nil {
+		return false, fmt.Errorf("parse object storage source config: %w", err)
+	}
+	lastPolledAt, err := s.lastBindingResolvedAt(ctx, binding)
+	if err != nil {
+		return false, err
+	}
+	if !config.PollDue(lastPolledAt, now) {
+		return false, nil
+	}
+	objects, err := s.listObjectStorage(ctx, config)
+	if err != nil {
+		return false, err
+	}
+	prepared, changedRefs, err := s.prepareObjectStorageBindingSync(ctx, binding, config, objects, sourceItems, now)
+	if err != nil {
+		return false, err
+	}
+	stats, err := s.applyPolledBindingChange(ctx, binding, prepared, changedRefs, now)
+	if err != nil {
+		return false, err
+	}
+	s.logInfo(
+		ctx,
+		"Object storage source binding synced",
+		"knowledge_base_code", binding.KnowledgeBaseCode,
+		"binding_id", binding.ID,
+		"objects", len(objects),
+		"changed_objects", len(changedRefs),
+		"created", stats.created,
+		"deleted", stats.deleted,
+		"resynced", stats.resynced,
+	)
+	return true, nil
+}
+
+func (s *KnowledgeBaseDocumentFlowApp) prepareObjectStorageBindingSync(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	config sourcebindingservice.ObjectStorageSourceConfig,
+	objects []sourcebindingdomain.ObjectStorageObject,
+	sourceItems sourceItemRefLister,
+	now time.Time,
+) (*preparedBindingChange, map[string]struct{}, error) {
+	state, err := s.loadPolledBindingState(ctx, binding)
+	if err != nil {
+		return nil, nil, err
+	}
+	// 必须在增量解析 upsert 来源项之前读取，否则旧 ETag 会被本次列举结果覆盖。
+	snapshots, err := previousSourceItemSnapshots(ctx, binding, state.documents, sourceItems)
+	if err != nil {
+		return nil, nil, err
+	}
+	previousETags := make(map[string]string, len(snapshots))
+	for _, snapshot := range snapshots {
+		if key, _ := snapshot[sourcebindingservice.ObjectStorageSnapshotMetaKey].(string); key != "" {
+			previousETags[key] = sourcebindingservice.ObjectStorageETagFromSnapshot(snapshot)
+		}
+	}
+	changedRefs := make(map[string]struct{})
+	for _, key := range sourcebindingservice.ObjectStorageChangedKeys(previousETags, objects) {
+		changedRefs[sourcebindingservice.ObjectStorageItemRef(config.Bucket, key)] = struct{}{}
+	}
+
+	prepared, err := s.planPolledBindingChange(
+		ctx,
+		binding,
+		state,
+		objectStorageResolvedDocuments(config, objects, knowledgeBaseMaterializeDocumentLimit),
+		now,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prepared, changedRefs, nil
+}
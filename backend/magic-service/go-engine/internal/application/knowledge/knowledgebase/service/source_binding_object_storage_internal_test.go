@@ -0,0 +1,155 @@
+package kbapp
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+	documentdomain "magic/internal/domain/knowledge/document/service"
+	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
+	sourcebindingservice "magic/internal/domain/knowledge/sourcebinding/service"
+)
+
+type objectStorageListerStub struct {
+	objects    []sourcebindingentity.ObjectStorageObject
+	listCalls  int
+	lastBucket string
+	lastPrefix string
+}
+
+func (s *objectStorageListerStub) ListObjects(
+	_ context.Context,
+	bucket, prefix string,
+) ([]sourcebindingentity.ObjectStorageObject, error) {
+	s.listCalls++
+	s.lastBucket = bucket
+	s.lastPrefix = prefix
+	return s.objects, nil
+}
+
+func TestResolveObjectStorageBindingDocumentsFiltersObjects(t *testing.T) {
+	t.Parallel()
+
+	lister := &objectStorageListerStub{objects: []sourcebindingentity.ObjectStorageObject{
+		{Key: "exports/zz.md", ETag: "z1", Size: 40},
+		{Key: "exports/q1.csv", ETag: `"q1"`, Size: 10},
+		{Key: "exports/huge.pdf", ETag: "h1", Size: 2 << 20},
+		{Key: "exports/tmp/scratch.md", ETag: "t1", Size: 10},
+		{Key: "exports/tool.exe", ETag: "e1", Size: 10},
+		{Key: "exports/q2.md", ETag: "q2", Size: 20},
+	}}
+	flow := &KnowledgeBaseDocumentFlowApp{support: &KnowledgeBaseAppService{objectStorage: lister}}
+
+	documents, err := flow.resolveBindingDocuments(context.Background(), sourcebindingentity.Binding{
+		Provider: sourcebindingentity.ProviderObjectStorage,
+		RootType: sourcebindingentity.RootTypeBucketPrefix,
+		RootRef:  "s3://reports/exports/",
+		SyncConfig: map[string]any{
+			"exclude_patterns": []any{"tmp/**"},
+			"max_object_bytes": 1 << 20,
+		},
+	}, "ORG1", "U1", 2)
+	if err != nil {
+		t.Fatalf("resolve object storage binding: %v", err)
+	}
+	if lister.lastBucket != "reports" || lister.lastPrefix != "exports/" {
+		t.Fatalf("unexpected list scope %q %q", lister.lastBucket, lister.lastPrefix)
+	}
+	refs := make([]string, 0, len(documents))
+	for _, document := range documents {
+		refs = append(refs, document.ItemRef+"|"+document.SkipReason)
+	}
+	expected := []string{
+		"reports/exports/huge.pdf|" + sourcebindingservice.ObjectStorageSkipTooLarge,
+		"reports/exports/q1.csv|",
+		"reports/exports/q2.md|",
+		"reports/exports/zz.md|" + sourcebindingservice.ObjectStorageSkipObjectLimit,
+	}
+	if !slices.Equal(refs, expected) {
+		t.Fatalf("unexpected resolved refs: %v", refs)
+	}
+	report := documents[1]
+	documentFile, _ := report.DocumentFile.(*docentity.File)
+	if report.Name != "q1.csv" || report.GroupRef != "exports" || documentFile == nil ||
+		documentFile.URL != "s3://reports/exports/q1.csv" || documentFile.Extension != "csv" ||
+		report.DocMetadata[documentdomain.DocMetaObjectStorageKey] != "exports/q1.csv" ||
+		report.SnapshotMeta[sourcebindingservice.ObjectStorageSnapshotMetaETag] != "q1" {
+		t.Fatalf("unexpected object storage document: %#v file=%#v", report, documentFile)
+	}
+}
+
+func TestSyncObjectStorageBindingAppliesETagChanges(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	lastPolledAt := now.Add(-time.Hour)
+	binding := sourcebindingentity.Binding{
+		ID:                7,
+		OrganizationCode:  "ORG1",
+		KnowledgeBaseCode: "KB1",
+		Provider:          sourcebindingentity.ProviderObjectStorage,
+		RootType:          sourcebindingentity.RootTypeBucketPrefix,
+		RootRef:           "reports/2026/",
+		SyncMode:          sourcebindingentity.SyncModeRealtime,
+		Enabled:           true,
+		CreatedUID:        "U1",
+	}
+	snapshot := func(key, etag string) map[string]any {
+		return sourcebindingservice.BuildObjectStorageSnapshotMeta("reports", sourcebindingentity.ObjectStorageObject{Key: key, ETag: etag})
+	}
+	repo := &websiteRecrawlRepositoryStub{
+		bindingItems: []sourcebindingentity.BindingItem{
+			{BindingID: 7, SourceItemID: 1, LastResolvedAt: &lastPolledAt},
+			{BindingID: 7, SourceItemID: 2, LastResolvedAt: &lastPolledAt},
+			{BindingID: 7, SourceItemID: 3, LastResolvedAt: &lastPolledAt},
+		},
+		sourceItems: []sourcebindingentity.SourceItem{
+			{ID: 1, RootRef: "reports/2026/", ItemRef: "reports/2026/a.csv", SnapshotMeta: snapshot("2026/a.csv", "a1")},
+			{ID: 2, RootRef: "reports/2026/", ItemRef: "reports/2026/b.csv", SnapshotMeta: snapshot("2026/b.csv", "b1")},
+			{ID: 3, RootRef: "reports/2026/", ItemRef: "reports/2026/gone.csv", SnapshotMeta: snapshot("2026/gone.csv", "g1")},
+		},
+		nextItemID: 100,
+	}
+	store := &websiteRecrawlDocumentStore{documents: []*ManagedDocument{
+		{Code: "DOC-A", SourceBindingID: 7, SourceItemID: 1, ThirdFileID: "reports/2026/a.csv"},
+		{Code: "DOC-B", SourceBindingID: 7, SourceItemID: 2, ThirdFileID: "reports/2026/b.csv"},
+		{Code: "DOC-GONE", SourceBindingID: 7, SourceItemID: 3, ThirdFileID: "reports/2026/gone.csv"},
+	}}
+	lister := &objectStorageListerStub{objects: []sourcebindingentity.ObjectStorageObject{
+		{Key: "2026/a.csv", ETag: `"a1"`, Size: 10},
+		{Key: "2026/b.csv", ETag: `"b2"`, Size: 10},
+		{Key: "2026/new.csv", ETag: `"n1"`, Size: 10},
+	}}
+	flow := &KnowledgeBaseDocumentFlowApp{
+		support:          &KnowledgeBaseAppService{sourceBindingRepo: repo, objectStorage: lister},
+		managedDocuments: store,
+	}
+
+	done, err := flow.syncObjectStorageBinding(context.Background(), binding, repo, now)
+	if err != nil || !done {
+		t.Fatalf("sync object storage binding done=%v err=%v", done, err)
+	}
+	if !slices.Equal(store.destroyed, []string{"DOC-GONE"}) {
+		t.Fatalf("expected removed object document destroyed, got %v", store.destroyed)
+	}
+	if len(store.syncs) != 2 || store.syncs[0].Mode != knowledgeBaseSyncModeCreate ||
+		store.syncs[1].Code != "DOC-B" || store.syncs[1].Mode != knowledgeBaseSyncModeResync {
+		t.Fatalf("unexpected scheduled syncs: %#v", store.syncs)
+	}
+	itemIDs := make([]int64, 0, len(repo.replacedItems))
+	for _, item := range repo.replacedItems {
+		itemIDs = append(itemIDs, item.SourceItemID)
+	}
+	slices.Sort(itemIDs)
+	if !slices.Equal(itemIDs, []int64{1, 2, 101}) {
+		t.Fatalf("unexpected binding items: %v", itemIDs)
+	}
+
+	repo.bindingItems = repo.replacedItems
+	done, err = flow.syncObjectStorageBinding(context.Background(), binding, repo, now.Add(5*time.Minute))
+	if err != nil || done || lister.listCalls != 1 {
+		t.Fatalf("expected poll interval to skip listing, done=%v err=%v listCalls=%d", done, err, lister.listCalls)
+	}
+}
@@ -0,0 +1,238 @@
+package kbapp
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	bindingplan "magic/internal/domain/knowledge/binding_plan"
+	sourcebindingdomain "magic/internal/domain/knowledge/sourcebinding/entity"
+	sourcebindingservice "magic/internal/domain/knowledge/sourcebinding/service"
+	"magic/internal/pkg/filetype"
+)
+
+// 轮询型来源（Git、对象存储）在后台定时重新列出来源内容，复用增量计划完成新增/删除，
+// 并只为内容变化的来源项调度重同步。
+
+// sourceItemRefLister 表示按 item_ref 读取已物化来源项快照的能力。
+type sourceItemRefLister interface {
+	ListSourceItemsByOrganizationProviderAndItemRefs(
+		ctx context.Context,
+		organizationCode string,
+		provider string,
+		itemRefs []string,
+	) ([]sourcebindingdomain.SourceItem, error)
+}
+
+// staticDocumentResolver 把已经列出的文档物料交给增量解析服务，避免重复访问来源。
+type staticDocumentResolver struct {
+	documents []sourcebindingservice.ResolvedDocument
+}
+
+func (r staticDocumentResolver) ResolveBindingDocuments(
+	context.Context,
+	sourcebindingdomain.Binding,
+	string,
+	string,
+	int,
+) ([]sourcebindingservice.ResolvedDocument, error) {
+	return r.documents, nil
+}
+
+// polledBindingState 表示单个轮询绑定同步前的已物化状态。
+type polledBindingState struct {
+	items     []sourcebindingdomain.BindingItem
+	documents []*ManagedDocument
+}
+
+// polledBindingSyncStats 汇总单次轮询同步的变更数量。
+type polledBindingSyncStats struct {
+	created  int
+	deleted  int
+	resynced int
+}
+
+// sourceFileExtension 按路径或对象键的后缀返回规范化扩展名。
+func sourceFileExtension(filePath string) string {
+	return filetype.NormalizeExtension(path.Ext(filePath))
+}
+
+func (s *KnowledgeBaseDocumentFlowApp) requirePolledBindingDeps() error {
+	if s == nil || s.support == nil || s.support.sourceBindingRepo == nil {
+		return ErrKnowledgeBaseSourceBindingRepositoryRequired
+	}
+	if s.managedDocuments == nil {
+		return ErrKnowledgeBaseDocumentFlowRequired
+	}
+	return nil
+}
+
+func (s *KnowledgeBaseDocumentFlowApp) loadPolledBindingState(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+) (polledBindingState, error) {
+	items, err := s.support.sourceBindingRepo.ListBindingItemsByKnowledgeBase(ctx, binding.KnowledgeBaseCode)
+	if err != nil {
+		return polledBindingState{}, fmt.Errorf("list %s binding items: %w", binding.Provider, err)
+	}
+	state := polledBindingState{items: make([]sourcebindingdomain.BindingItem, 0, len(items))}
+	for _, item := range items {
+		if item.BindingID == binding.ID {
+			state.items = append(state.items, item)
+		}
+	}
+	state.documents, err = s.managedDocuments.ListManagedDocumentsBySourceBindingIDs(ctx, binding.KnowledgeBaseCode, []int64{binding.ID})
+	if err != nil {
+		return polledBindingState{}, fmt.Errorf("list %s managed documents: %w", binding.Provider, err)
+	}
+	return state, nil
+}
+
+// lastBindingResolvedAt 返回绑定下来源项最近一次解析时间，用于判断轮询是否到期。
+func (s *KnowledgeBaseDocumentFlowApp) lastBindingResolvedAt(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+) (*time.Time, error) {
+	items, err := s.support.sourceBindingRepo.ListBindingItemsByKnowledgeBase(ctx, binding.KnowledgeBaseCode)
+	if err != nil {
+		return nil, fmt.Errorf("list %s binding items: %w", binding.Provider, err)
+	}
+	var latest *time.Time
+	for _, item := range items {
+		if item.BindingID != binding.ID || item.LastResolvedAt == nil {
+			continue
+		}
+		if latest == nil || item.LastResolvedAt.After(*latest) {
+			latest = item.LastResolvedAt
+		}
+	}
+	return latest, nil
+}
+
+// previousSourceItemSnapshots 读取当前托管文档对应来源项的快照，按 item_ref 索引。
+//
+// 必须在增量解析 upsert 来源项之前调用，否则旧快照会被本次列出的结果覆盖。
+func previousSourceItemSnapshots(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	documents []*ManagedDocument,
+	lister sourceItemRefLister,
+) (map[string]map[string]any, error) {
+	itemRefs := make([]string, 0, len(documents))
+	for _, document := range documents {
+		if document != nil && strings.TrimSpace(document.ThirdFileID) != "" {
+			itemRefs = append(itemRefs, strings.TrimSpace(document.ThirdFileID))
+		}
+	}
+	snapshots := make(map[string]map[string]any, len(itemRefs))
+	if len(itemRefs) == 0 {
+		return snapshots, nil
+	}
+	items, err := lister.ListSourceItemsByOrganizationProviderAndItemRefs(ctx, binding.OrganizationCode, binding.Provider, itemRefs)
+	if err != nil {
+		return nil, fmt.Errorf("list %s source items: %w", binding.Provider, err)
+	}
+	for _, item := range items {
+		if item.RootRef != binding.RootRef {
+			continue
+		}
+		snapshots[item.ItemRef] = item.SnapshotMeta
+	}
+	return snapshots, nil
+}
+
+// planPolledBindingChange 把本次列出的文档物料解析为来源项，并与当前物化状态生成增量计划。
+func (s *KnowledgeBaseDocumentFlowApp) planPolledBindingChange(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	state polledBindingState,
+	documents []sourcebindingservice.ResolvedDocument,
+	now time.Time,
+) (*preparedBindingChange, error) {
+	resolvedBindings, err := sourcebindingservice.NewIncrementalResolutionService(
+		sourceBindingIncrementalResolutionRepository{repo: s.support.sourceBindingRepo},
+		staticDocumentResolver{documents: documents},
+		func() time.Time { return now },
+	).Resolve(ctx, sourcebindingservice.IncrementalResolveInput{
+		OrganizationCode:    binding.OrganizationCode,
+		KnowledgeBaseUserID: binding.UpdatedUID,
+		KnowledgeBaseOwner:  binding.CreatedUID,
+		Bindings:            []sourcebindingdomain.Binding{binding},
+		MaxDocuments:        knowledgeBaseMaterializeDocumentLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s source binding: %w", binding.Provider, err)
+	}
+	desiredBindings, resolvedItemsByID := toIncrementalResolvedBindings(resolvedBindings)
+	plan := bindingplan.Build(bindingplan.PlanInput{
+		CurrentBindings:  toBindingSnapshots([]sourcebindingdomain.Binding{binding}),
+		CurrentItems:     toBindingItemSnapshots(state.items),
+		CurrentDocuments: toManagedDocumentSnapshots(state.documents),
+		DesiredBindings:  desiredBindings,
+	})
+	return &preparedBindingChange{plan: plan, resolvedItemsByID: resolvedItemsByID}, nil
+}
+
+// applyPolledBindingChange 执行轮询增量计划：创建新增文档、删除消失的文档（含向量），
+// 对 changedRefs 命中的保留文档调度重同步，最后刷新 binding items。
+func (s *KnowledgeBaseDocumentFlowApp) applyPolledBindingChange(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	prepared *preparedBindingChange,
+	changedRefs map[string]struct{},
+	now time.Time,
+) (polledBindingSyncStats, error) {
+	syncUserID := cmp.Or(binding.UpdatedUID, binding.CreatedUID)
+	createdDocs, pendingSyncs, err := s.createBindingChangeDocuments(
+		ctx,
+		binding.KnowledgeBaseCode,
+		binding.OrganizationCode,
+		[]sourcebindingdomain.Binding{binding},
+		prepared,
+		syncUserID,
+	)
+	if err == nil {
+		err = s.destroyBindingChangeDocuments(ctx, binding.KnowledgeBaseCode, binding.OrganizationCode, prepared.plan.DeleteTargets)
+	}
+	if err != nil {
+		return polledBindingSyncStats{}, errors.Join(err, s.destroyCreatedManagedDocuments(ctx, binding.KnowledgeBaseCode, createdDocs))
+	}
+
+	stats := polledBindingSyncStats{created: len(createdDocs), deleted: len(prepared.plan.DeleteTargets)}
+	bindingKey := bindingplan.BindingKey(binding.Provider, binding.RootType, binding.RootRef)
+	for _, target := range prepared.plan.KeepTargets {
+		item, exists := prepared.resolvedItemsByID[resolvedItemKey(bindingKey, target.Document.SourceItemID)]
+		if !exists || item.sourceItem == nil {
+			continue
+		}
+		if _, changed := changedRefs[item.sourceItem.ItemRef]; !changed {
+			continue
+		}
+		pendingSyncs = append(pendingSyncs, websiteSyncInput(binding, target.Document.Code, knowledgeBaseSyncModeResync, syncUserID))
+		stats.resynced++
+	}
+
+	bindingItems := make([]sourcebindingdomain.BindingItem, 0)
+	for _, target := range prepared.plan.ApplyBindings {
+		for _, item := range target.DesiredItems {
+			resolvedAt := now
+			bindingItems = append(bindingItems, sourcebindingdomain.BindingItem{
+				BindingID:      binding.ID,
+				SourceItemID:   item.SourceItemID,
+				ResolveReason:  item.ResolveReason,
+				LastResolvedAt: &resolvedAt,
+			})
+		}
+	}
+	if err := s.support.sourceBindingRepo.ReplaceBindingItems(ctx, binding.ID, bindingItems); err != nil {
+		return polledBindingSyncStats{}, fmt.Errorf("replace %s binding items: %w", binding.Provider, err)
+	}
+	for _, input := range pendingSyncs {
+		s.managedDocuments.ScheduleManagedDocumentSync(ctx, input)
+	}
+	return stats, nil
+}
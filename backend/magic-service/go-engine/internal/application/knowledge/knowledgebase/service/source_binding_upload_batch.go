@@ -0,0 +1,163 @@
+package kbapp
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+	documentdomain "magic/internal/domain/knowledge/document/service"
+	sourcebindingdomain "magic/internal/domain/knowledge/sourcebinding/entity"
+	sourcebindingservice "magic/internal/domain/knowledge/sourcebinding/service"
+)
+
+const (
+	uploadBatchItemRefSeparator            = "!/"
+	uploadBatchEntryStatusExpanded         = "expanded"
+	uploadBatchEntryStatusSkipped          = "skipped"
+	uploadBatchSnapshotMetaEntryPath       = "archive_entry_path"
+	uploadBatchSnapshotMetaEntryStatus     = "archive_entry_status"
+	uploadBatchSnapshotMetaEntrySkipReason = "archive_entry_skip_reason"
+	uploadBatchSnapshotMetaEntrySize       = "archive_entry_size"
+	// materializeProgressLogInterval 控制大批量物化时的进度日志频率，避免每个条目打一条日志。
+	materializeProgressLogInterval = 50
+)
+
+type archiveEntryLister interface {
+	ListArchiveEntries(ctx context.Context, archiveURL, archiveType string) (*documentdomain.ArchiveListing, error)
+}
+
+// resolveUploadBatchBindingDocuments 把上传的压缩包展开为逐条目的文档物料。
+//
+// 每个受支持的条目成为一篇文档，GroupRef 取条目所在目录，形成与压缩包一致的虚拟目录树；
+// 文档仍指向压缩包本身，同步时按 doc_metadata 里的条目路径解出内容。
+// 不支持、超限或超出文档上限的条目带 SkipReason 返回，只记录来源项状态。
+func (s *KnowledgeBaseDocumentFlowApp) resolveUploadBatchBindingDocuments(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	archiveFile *docentity.File,
+	maxDocuments int,
+) ([]sourcebindingservice.ResolvedDocument, error) {
+	if s == nil || s.archiveEntries == nil {
+		return nil, ErrUploadBatchArchiveListerRequired
+	}
+	archiveType := documentdomainInferExtension(archiveFile)
+	if !documentdomain.IsArchiveFileType(archiveType) {
+		return nil, fmt.Errorf("%w: extension=%s", ErrUnsupportedUploadBatchArchive, archiveType)
+	}
+	listing, err := s.archiveEntries.ListArchiveEntries(ctx, archiveFile.URL, archiveType)
+	if err != nil {
+		return nil, fmt.Errorf("list upload batch archive entries: %w", err)
+	}
+
+	rootRef := firstNonEmpty(strings.TrimSpace(binding.RootRef), strings.TrimSpace(archiveFile.URL))
+	skipped := append([]documentdomain.ArchiveEntrySkip(nil), listing.Skipped...)
+	documents := make([]sourcebindingservice.ResolvedDocument, 0, len(listing.Entries)+len(listing.Skipped))
+	for index, entry := range listing.Entries {
+		if maxDocuments > 0 && index >= maxDocuments {
+			skipped = append(skipped, documentdomain.ArchiveEntrySkip{
+				Path:   entry.Path,
+				Size:   entry.Size,
+				Reason: documentdomain.ArchiveEntrySkipDocumentLimit,
+			})
+			continue
+		}
+		documents = append(documents, uploadBatchEntryDocument(rootRef, archiveType, archiveFile, entry))
+	}
+	expanded := len(documents)
+	for _, entry := range skipped {
+		documents = append(documents, skippedUploadBatchDocument(rootRef, entry))
+	}
+	s.logInfo(
+		ctx,
+		"Resolved upload batch archive",
+		"root_ref", rootRef,
+		"archive_type", archiveType,
+		"expanded_entries", expanded,
+		"skipped_entries", len(skipped),
+	)
+	return documents, nil
+}
+
+func uploadBatchEntryDocument(
+	rootRef string,
+	archiveType string,
+	archiveFile *docentity.File,
+	entry documentdomain.ArchiveEntry,
+) sourcebindingservice.ResolvedDocument {
+	documentFile := &docentity.File{
+		Type:            "external",
+		Name:            entry.Name,
+		URL:             archiveFile.URL,
+		Size:            entry.Size,
+		Extension:       entry.Extension,
+		SourceType:      sourcebindingdomain.ProviderLocalUpload,
+		KnowledgeBaseID: archiveFile.KnowledgeBaseID,
+	}
+	snapshotMeta := documentFileToMap(documentFile)
+	snapshotMeta[uploadBatchSnapshotMetaEntryPath] = entry.Path
+	snapshotMeta[uploadBatchSnapshotMetaEntryStatus] = uploadBatchEntryStatusExpanded
+	return sourcebindingservice.ResolvedDocument{
+		// 文档名保留包内相对路径，不同目录下的同名文件在文档列表里仍可区分。
+		Name:          entry.Path,
+		DocumentFile:  documentFile,
+		DocumentType:  int(docentity.DocumentInputKindFile),
+		ItemRef:       uploadBatchItemRef(rootRef, entry.Path),
+		GroupRef:      entry.Dir,
+		Extension:     entry.Extension,
+		ResolveReason: sourceBindingResolveReasonRoot,
+		SnapshotMeta:  snapshotMeta,
+		DocMetadata: map[string]any{
+			documentdomain.DocMetaArchiveEntryPath: entry.Path,
+			documentdomain.DocMetaArchiveType:      archiveType,
+		},
+	}
+}
+
+func skippedUploadBatchDocument(rootRef string, skipped documentdomain.ArchiveEntrySkip) sourcebindingservice.ResolvedDocument {
+	dir := path.Dir(skipped.Path)
+	if dir == "." {
+		dir = ""
+	}
+	return sourcebindingservice.ResolvedDocument{
+		Name:          skipped.Path,
+		ItemRef:       uploadBatchItemRef(rootRef, skipped.Path),
+		GroupRef:      dir,
+		Extension:     strings.TrimPrefix(path.Ext(skipped.Path), "."),
+		ResolveReason: sourceBindingResolveReasonRoot,
+		SkipReason:    skipped.Reason,
+		SnapshotMeta: map[string]any{
+			uploadBatchSnapshotMetaEntryPath:       skipped.Path,
+			uploadBatchSnapshotMetaEntryStatus:     uploadBatchEntryStatusSkipped,
+			uploadBatchSnapshotMetaEntrySkipReason: skipped.Reason,
+			uploadBatchSnapshotMetaEntrySize:       skipped.Size,
+		},
+	}
+}
+
+// uploadBatchItemRef 用 "压缩包!/条目路径" 标识条目，保证同一压缩包内条目唯一且可回溯来源。
+func uploadBatchItemRef(rootRef, entryPath string) string {
+	return rootRef + uploadBatchItemRefSeparator + entryPath
+}
+
+func (s *KnowledgeBaseDocumentFlowApp) logMaterializationProgress(
+	ctx context.Context,
+	knowledgeBaseCode string,
+	progress sourcebindingservice.MaterializationProgress,
+) {
+	if progress.Total < materializeProgressLogInterval {
+		return
+	}
+	if progress.Processed%materializeProgressLogInterval != 0 && progress.Processed != progress.Total {
+		return
+	}
+	s.logInfo(
+		ctx,
+		"Materializing source binding documents",
+		"knowledge_base_code", knowledgeBaseCode,
+		"binding_id", progress.BindingID,
+		"processed", progress.Processed,
+		"total", progress.Total,
+	)
+}
@@ -0,0 +1,100 @@
+package kbapp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+	documentdomain "magic/internal/domain/knowledge/document/service"
+	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
+)
+
+type archiveEntryListerStub struct {
+	listing     *documentdomain.ArchiveListing
+	archiveURL  string
+	archiveType string
+}
+
+func (s *archiveEntryListerStub) ListArchiveEntries(
+	_ context.Context,
+	archiveURL string,
+	archiveType string,
+) (*documentdomain.ArchiveListing, error) {
+	s.archiveURL = archiveURL
+	s.archiveType = archiveType
+	return s.listing, nil
+}
+
+func TestResolveLocalUploadBindingDocumentsExpandsUploadBatchArchive(t *testing.T) {
+	t.Parallel()
+
+	lister := &archiveEntryListerStub{listing: &documentdomain.ArchiveListing{
+		Entries: []documentdomain.ArchiveEntry{
+			{Path: "docs/api/spec.md", Dir: "docs/api", Name: "spec.md", Extension: "md", Size: 12},
+			{Path: "readme.txt", Name: "readme.txt", Extension: "txt", Size: 5},
+			{Path: "later.txt", Name: "later.txt", Extension: "txt", Size: 5},
+		},
+		Skipped: []documentdomain.ArchiveEntrySkip{
+			{Path: "tools/setup.exe", Size: 9, Reason: documentdomain.ArchiveEntrySkipUnsupported},
+		},
+	}}
+	flow := &KnowledgeBaseDocumentFlowApp{archiveEntries: lister}
+	binding := sourcebindingentity.Binding{
+		Provider: sourcebindingentity.ProviderLocalUpload,
+		RootType: sourcebindingentity.RootTypeUploadBatch,
+		RootRef:  "uploads/batch.zip",
+		SyncConfig: map[string]any{
+			"document_file": map[string]any{"name": "batch.zip", "url": "uploads/batch.zip"},
+		},
+	}
+
+	documents, err := flow.resolveLocalUploadBindingDocuments(context.Background(), binding, 2)
+	if err != nil {
+		t.Fatalf("resolve upload batch: %v", err)
+	}
+	if lister.archiveURL != "uploads/batch.zip" || lister.archiveType != "zip" {
+		t.Fatalf("unexpected lister call url=%q type=%q", lister.archiveURL, lister.archiveType)
+	}
+	if len(documents) != 4 {
+		t.Fatalf("expected 2 expanded and 2 skipped entries, got %#v", documents)
+	}
+
+	spec := documents[0]
+	documentFile, _ := spec.DocumentFile.(*docentity.File)
+	if spec.Name != "docs/api/spec.md" || spec.GroupRef != "docs/api" || spec.ItemRef != "uploads/batch.zip!/docs/api/spec.md" ||
+		spec.SkipReason != "" || documentFile == nil || documentFile.URL != "uploads/batch.zip" ||
+		documentFile.Extension != "md" || documentFile.Size != 12 {
+		t.Fatalf("unexpected expanded entry: %#v file=%#v", spec, documentFile)
+	}
+	if spec.DocMetadata[documentdomain.DocMetaArchiveEntryPath] != "docs/api/spec.md" ||
+		spec.DocMetadata[documentdomain.DocMetaArchiveType] != "zip" {
+		t.Fatalf("unexpected doc metadata: %#v", spec.DocMetadata)
+	}
+
+	reasons := map[string]string{}
+	for _, document := range documents[2:] {
+		reasons[document.Name] = document.SkipReason
+		if document.SnapshotMeta[uploadBatchSnapshotMetaEntryStatus] != uploadBatchEntryStatusSkipped {
+			t.Fatalf("expected skipped status in snapshot meta: %#v", document.SnapshotMeta)
+		}
+	}
+	if reasons["later.txt"] != documentdomain.ArchiveEntrySkipDocumentLimit ||
+		reasons["tools/setup.exe"] != documentdomain.ArchiveEntrySkipUnsupported {
+		t.Fatalf("unexpected skip reasons: %#v", reasons)
+	}
+}
+
+func TestResolveLocalUploadBindingDocumentsRejectsNonArchiveUploadBatch(t *testing.T) {
+	t.Parallel()
+
+	flow := &KnowledgeBaseDocumentFlowApp{archiveEntries: &archiveEntryListerStub{}}
+	_, err := flow.resolveLocalUploadBindingDocuments(context.Background(), sourcebindingentity.Binding{
+		Provider: sourcebindingentity.ProviderLocalUpload,
+		RootType: sourcebindingentity.RootTypeUploadBatch,
+		RootRef:  "uploads/readme.md",
+	}, 10)
+	if !errors.Is(err, ErrUnsupportedUploadBatchArchive) {
+		t.Fatalf("expected ErrUnsupportedUploadBatchArchive, got %v", err)
+	}
+}
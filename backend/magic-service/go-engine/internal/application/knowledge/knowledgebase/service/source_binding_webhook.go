@@ -0,0 +1,348 @@
+package kbapp
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+	documentdomain "magic/internal/domain/knowledge/document/service"
+	sourcebindingdomain "magic/internal/domain/knowledge/sourcebinding/entity"
+	sourcebindingservice "magic/internal/domain/knowledge/sourcebinding/service"
+)
+
+const (
+	webhookResolveReasonPush = "webhook_push"
+	webhookDocumentFileType  = "external"
+)
+
+var errWebhookSourceItemsMismatch = errors.New("webhook upserted source items mismatch")
+
+// 推送事件的处理结果。
+const (
+	WebhookEventStatusCreated   = "created"
+	WebhookEventStatusResynced  = "resynced"
+	WebhookEventStatusReplaced  = "replaced"
+	WebhookEventStatusUnchanged = "unchanged"
+	WebhookEventStatusDeleted   = "deleted"
+	WebhookEventStatusNotFound  = "not_found"
+	WebhookEventStatusSkipped   = "skipped"
+)
+
+// WebhookEventResult 描述单个推送事件的处理结果。
+type WebhookEventResult struct {
+	ExternalID   string
+	Action       string
+	Status       string
+	DocumentCode string
+}
+
+// webhookApplyState 汇总一批推送事件对绑定的变更。
+type webhookApplyState struct {
+	binding      sourcebindingdomain.Binding
+	config       sourcebindingservice.WebhookSourceConfig
+	syncUserID   string
+	now          time.Time
+	docsByRef    map[string]*ManagedDocument
+	bindingItems []sourcebindingdomain.BindingItem
+	destroyCodes []string
+	createdDocs  []*ManagedDocument
+	pendingSyncs []*SyncDocumentInput
+}
+
+// resolveWebhookBindingDocuments 在知识库更新重新解析绑定时保留已推送的文档。
+//
+// webhook 来源没有可列举的上游，只能以当前托管文档为准；绑定首次创建时为空，等待外部系统推送。
+func (s *KnowledgeBaseDocumentFlowApp) resolveWebhookBindingDocuments(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	maxDocuments int,
+) ([]sourcebindingservice.ResolvedDocument, error) {
+	config, err := sourcebindingservice.ParseWebhookSourceConfig(binding)
+	if err != nil {
+		return nil, fmt.Errorf("parse webhook source config: %w", err)
+	}
+	if s == nil || s.managedDocuments == nil {
+		return nil, ErrKnowledgeBaseDocumentFlowRequired
+	}
+	docs, err := s.managedDocuments.ListManagedDocumentsByKnowledgeBase(ctx, binding.KnowledgeBaseCode)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook managed documents: %w", err)
+	}
+	prefix := sourcebindingservice.WebhookItemRef(config.Channel, "")
+	pushed := make([]*ManagedDocument, 0, len(docs))
+	for _, doc := range docs {
+		if doc == nil || doc.DocumentFile == nil || doc.DocumentFile.SourceType != sourcebindingdomain.ProviderWebhook ||
+			!strings.HasPrefix(doc.ThirdFileID, prefix) {
+			continue
+		}
+		pushed = append(pushed, doc)
+	}
+	snapshots := map[string]map[string]any{}
+	if lister, ok := s.support.sourceBindingRepo.(sourceItemRefLister); ok {
+		if snapshots, err = previousSourceItemSnapshots(ctx, binding, pushed, lister); err != nil {
+			return nil, err
+		}
+	}
+	documents := make([]sourcebindingservice.ResolvedDocument, 0, len(pushed))
+	for _, doc := range pushed {
+		if len(documents) >= maxDocuments {
+			break
+		}
+		externalID := strings.TrimPrefix(doc.ThirdFileID, prefix)
+		snapshot := snapshots[doc.ThirdFileID]
+		if len(snapshot) == 0 {
+			snapshot = sourcebindingservice.BuildWebhookSnapshotMeta(sourcebindingservice.WebhookEvent{
+				ExternalID: externalID,
+				URL:        doc.DocumentFile.URL,
+			})
+		}
+		documents = append(documents, sourcebindingservice.ResolvedDocument{
+			Name:          doc.DocumentFile.Name,
+			DocumentFile:  cloneDocumentFile(doc.DocumentFile),
+			DocumentType:  int(docentity.DocumentInputKindFile),
+			ItemRef:       doc.ThirdFileID,
+			Extension:     doc.DocumentFile.Extension,
+			ResolveReason: webhookResolveReasonPush,
+			SnapshotMeta:  snapshot,
+			DocMetadata:   documentdomain.BuildWebhookDocMetadata(config.Channel, externalID),
+		})
+	}
+	return documents, nil
+}
+
+// applyWebhookEvents 把一批推送事件应用到绑定：新增/变更条目更新来源项并调度同步，删除事件移除文档（含向量）。
+//
+// 内联正文不落库，通过 source override 随同步任务下发。
+func (s *KnowledgeBaseDocumentFlowApp) applyWebhookEvents(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	config sourcebindingservice.WebhookSourceConfig,
+	events []sourcebindingservice.WebhookEvent,
+	sourceItems sourceItemRefLister,
+	now time.Time,
+) ([]WebhookEventResult, error) {
+	if err := s.requirePolledBindingDeps(); err != nil {
+		return nil, err
+	}
+	state, err := s.loadPolledBindingState(ctx, binding)
+	if err != nil {
+		return nil, err
+	}
+	// 必须在 upsert 来源项之前读取，否则旧哈希会被本次推送覆盖。
+	snapshots, err := previousSourceItemSnapshots(ctx, binding, state.documents, sourceItems)
+	if err != nil {
+		return nil, err
+	}
+	apply := &webhookApplyState{
+		binding:      binding,
+		config:       config,
+		syncUserID:   cmp.Or(binding.UpdatedUID, binding.CreatedUID),
+		now:          now,
+		docsByRef:    make(map[string]*ManagedDocument, len(state.documents)),
+		bindingItems: state.items,
+	}
+	for _, doc := range state.documents {
+		if doc != nil && strings.TrimSpace(doc.ThirdFileID) != "" {
+			apply.docsByRef[strings.TrimSpace(doc.ThirdFileID)] = doc
+		}
+	}
+
+	results := make([]WebhookEventResult, len(events))
+	changed := make([]int, 0, len(events))
+	for idx, event := range events {
+		results[idx] = WebhookEventResult{ExternalID: event.ExternalID, Action: event.Action}
+		doc := apply.docsByRef[sourcebindingservice.WebhookItemRef(config.Channel, event.ExternalID)]
+		switch {
+		case event.Action == sourcebindingservice.WebhookActionDelete:
+			results[idx].Status = apply.deleteDocument(doc)
+		case !docentity.IsSupportedKnowledgeBaseFileExtension(event.Extension):
+			results[idx].Status = WebhookEventStatusSkipped
+		case doc != nil && sourcebindingservice.WebhookEventUnchanged(snapshots[doc.ThirdFileID], event):
+			results[idx].Status = WebhookEventStatusUnchanged
+			results[idx].DocumentCode = doc.Code
+		default:
+			changed = append(changed, idx)
+		}
+	}
+	if err := s.upsertWebhookDocuments(ctx, apply, events, changed, results); err != nil {
+		return nil, err
+	}
+	if err := s.commitWebhookChanges(ctx, apply); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// upsertWebhookDocuments 为变更的推送条目刷新来源项，并创建、替换或重同步对应文档。
+func (s *KnowledgeBaseDocumentFlowApp) upsertWebhookDocuments(
+	ctx context.Context,
+	apply *webhookApplyState,
+	events []sourcebindingservice.WebhookEvent,
+	changed []int,
+	results []WebhookEventResult,
+) error {
+	if len(changed) == 0 {
+		return nil
+	}
+	items := make([]sourcebindingdomain.SourceItem, 0, len(changed))
+	for _, idx := range changed {
+		items = append(items, apply.sourceItem(events[idx]))
+	}
+	saved, err := s.support.sourceBindingRepo.UpsertSourceItems(ctx, items)
+	if err != nil {
+		return fmt.Errorf("upsert webhook source items: %w", err)
+	}
+	if len(saved) != len(items) || slices.Contains(saved, nil) {
+		return fmt.Errorf("%w: got %d want %d", errWebhookSourceItemsMismatch, len(saved), len(items))
+	}
+	for pos, idx := range changed {
+		event := events[idx]
+		apply.upsertBindingItem(saved[pos].ID)
+		doc := apply.docsByRef[sourcebindingservice.WebhookItemRef(apply.config.Channel, event.ExternalID)]
+		if doc != nil && webhookDocumentURL(doc) == event.URL {
+			apply.scheduleSync(doc.Code, knowledgeBaseSyncModeResync, event)
+			results[idx].Status = WebhookEventStatusResynced
+			results[idx].DocumentCode = doc.Code
+			continue
+		}
+		created, err := s.managedDocuments.CreateManagedDocument(ctx, apply.createInput(saved[pos], event))
+		if err != nil {
+			return errors.Join(
+				fmt.Errorf("create webhook managed document: %w", err),
+				s.destroyCreatedManagedDocuments(ctx, apply.binding.KnowledgeBaseCode, apply.createdDocs),
+			)
+		}
+		apply.createdDocs = append(apply.createdDocs, created)
+		apply.scheduleSync(created.Code, knowledgeBaseSyncModeCreate, event)
+		results[idx].Status = WebhookEventStatusCreated
+		results[idx].DocumentCode = created.Code
+		if doc != nil {
+			// 下载地址或正文来源变化时文档文件本身变了，旧文档连同向量一起替换掉。
+			apply.destroyCodes = append(apply.destroyCodes, doc.Code)
+			results[idx].Status = WebhookEventStatusReplaced
+		}
+	}
+	return nil
+}
+
+// commitWebhookChanges 删除被移除/替换的文档，刷新 binding items，最后调度同步。
+func (s *KnowledgeBaseDocumentFlowApp) commitWebhookChanges(ctx context.Context, apply *webhookApplyState) error {
+	binding := apply.binding
+	if len(apply.destroyCodes) > 0 {
+		if err := s.managedDocuments.DestroyManagedDocumentsByCodes(
+			ctx,
+			binding.KnowledgeBaseCode,
+			binding.OrganizationCode,
+			apply.destroyCodes,
+		); err != nil {
+			return errors.Join(
+				fmt.Errorf("destroy webhook managed documents: %w", err),
+				s.destroyCreatedManagedDocuments(ctx, binding.KnowledgeBaseCode, apply.createdDocs),
+			)
+		}
+	}
+	if err := s.support.sourceBindingRepo.ReplaceBindingItems(ctx, binding.ID, apply.bindingItems); err != nil {
+		return fmt.Errorf("replace webhook binding items: %w", err)
+	}
+	for _, input := range apply.pendingSyncs {
+		s.managedDocuments.ScheduleManagedDocumentSync(ctx, input)
+	}
+	return nil
+}
+
+func (a *webhookApplyState) deleteDocument(doc *ManagedDocument) string {
+	if doc == nil {
+		return WebhookEventStatusNotFound
+	}
+	a.destroyCodes = append(a.destroyCodes, doc.Code)
+	a.bindingItems = removeBindingItem(a.bindingItems, doc.SourceItemID)
+	delete(a.docsByRef, strings.TrimSpace(doc.ThirdFileID))
+	return WebhookEventStatusDeleted
+}
+
+func (a *webhookApplyState) sourceItem(event sourcebindingservice.WebhookEvent) sourcebindingdomain.SourceItem {
+	resolvedAt := a.now
+	return sourcebindingdomain.SourceItem{
+		OrganizationCode: a.binding.OrganizationCode,
+		Provider:         sourcebindingdomain.ProviderWebhook,
+		RootType:         sourcebindingdomain.RootTypeChannel,
+		RootRef:          a.config.Channel,
+		ItemType:         sourcebindingdomain.RootTypeFile,
+		ItemRef:          sourcebindingservice.WebhookItemRef(a.config.Channel, event.ExternalID),
+		DisplayName:      event.Name,
+		Extension:        event.Extension,
+		ContentHash:      event.ContentHash,
+		SnapshotMeta:     sourcebindingservice.BuildWebhookSnapshotMeta(event),
+		LastResolvedAt:   &resolvedAt,
+	}
+}
+
+func (a *webhookApplyState) upsertBindingItem(sourceItemID int64) {
+	resolvedAt := a.now
+	for idx := range a.bindingItems {
+		if a.bindingItems[idx].SourceItemID == sourceItemID {
+			a.bindingItems[idx].LastResolvedAt = &resolvedAt
+			return
+		}
+	}
+	a.bindingItems = append(a.bindingItems, sourcebindingdomain.BindingItem{
+		BindingID:      a.binding.ID,
+		SourceItemID:   sourceItemID,
+		ResolveReason:  webhookResolveReasonPush,
+		LastResolvedAt: &resolvedAt,
+	})
+}
+
+func (a *webhookApplyState) createInput(
+	item *sourcebindingdomain.SourceItem,
+	event sourcebindingservice.WebhookEvent,
+) *CreateManagedDocumentInput {
+	return &CreateManagedDocumentInput{
+		OrganizationCode:  a.binding.OrganizationCode,
+		UserID:            a.syncUserID,
+		KnowledgeBaseCode: a.binding.KnowledgeBaseCode,
+		SourceBindingID:   a.binding.ID,
+		SourceItemID:      item.ID,
+		Name:              event.Name,
+		DocType:           int(docentity.DocumentInputKindFile),
+		DocMetadata:       documentdomain.BuildWebhookDocMetadata(a.config.Channel, event.ExternalID),
+		DocumentFile: &docentity.File{
+			Type:       webhookDocumentFileType,
+			Name:       event.Name,
+			URL:        event.URL,
+			Extension:  event.Extension,
+			SourceType: sourcebindingdomain.ProviderWebhook,
+		},
+		ThirdFileID: item.ItemRef,
+	}
+}
+
+func (a *webhookApplyState) scheduleSync(code, mode string, event sourcebindingservice.WebhookEvent) {
+	input := websiteSyncInput(a.binding, code, mode, a.syncUserID)
+	if event.Content != "" {
+		input.SourceOverride = documentdomain.BuildWebhookSourceOverride(event.Content, event.ContentHash)
+	}
+	a.pendingSyncs = append(a.pendingSyncs, input)
+}
+
+func webhookDocumentURL(doc *ManagedDocument) string {
+	if doc == nil || doc.DocumentFile == nil {
+		return ""
+	}
+	return doc.DocumentFile.URL
+}
+
+func removeBindingItem(items []sourcebindingdomain.BindingItem, sourceItemID int64) []sourcebindingdomain.BindingItem {
+	result := items[:0:0]
+	for _, item := range items {
+		if item.SourceItemID != sourceItemID {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,284 @@
+package kbapp
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+	documentdomain "magic/internal/domain/knowledge/document/service"
+	"magic/internal/domain/knowledge/shared"
+	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
+	sourcebindingservice "magic/internal/domain/knowledge/sourcebinding/service"
+	"magic/internal/infrastructure/logging"
+	lockpkg "magic/internal/pkg/lock"
+)
+
+const webhookInternalTestSecret = "0123456789abcdef-secret"
+
+type webhookSourceRepositoryStub struct {
+	websiteRecrawlRepositoryStub
+
+	bindings []sourcebindingentity.Binding
+}
+
+func (r *webhookSourceRepositoryStub) ListBindingsByKnowledgeBase(
+	context.Context,
+	string,
+) ([]sourcebindingentity.Binding, error) {
+	return r.bindings, nil
+}
+
+type webhookCallbackNotifierStub struct {
+	targets []sourcebindingentity.WebhookCallbackTarget
+	events  []sourcebindingentity.WebhookCallbackEvent
+}
+
+func (n *webhookCallbackNotifierStub) NotifyWebhookCallback(
+	_ context.Context,
+	target sourcebindingentity.WebhookCallbackTarget,
+	event sourcebindingentity.WebhookCallbackEvent,
+) error {
+	n.targets = append(n.targets, target)
+	n.events = append(n.events, event)
+	return nil
+}
+
+type lockedSinglePodJobRunnerStub struct{}
+
+func (lockedSinglePodJobRunnerStub) Run(
+	context.Context,
+	lockpkg.SinglePodJobRequest,
+	lockpkg.SinglePodJobFunc,
+) (lockpkg.SinglePodJobResult, error) {
+	return lockpkg.SinglePodJobResult{Status: lockpkg.SinglePodJobStatusSkippedLocked}, nil
+}
+
+func webhookTestBinding() sourcebindingentity.Binding {
+	return sourcebindingentity.Binding{
+		ID:                7,
+		OrganizationCode:  "ORG1",
+		KnowledgeBaseCode: "KB1",
+		Provider:          sourcebindingentity.ProviderWebhook,
+		RootType:          sourcebindingentity.RootTypeChannel,
+		RootRef:           "cms",
+		SyncMode:          sourcebindingentity.SyncModeRealtime,
+		SyncConfig: map[string]any{
+			"signing_secret": webhookInternalTestSecret,
+			"callback_url":   "https://cms.example.com/hooks/knowledge",
+		},
+		Enabled:    true,
+		CreatedUID: "U1",
+	}
+}
+
+func TestApplyWebhookEventsDeduplicatesAndSchedulesSyncs(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	binding := webhookTestBinding()
+	config, err := sourcebindingservice.ParseWebhookSourceConfig(binding)
+	if err != nil {
+		t.Fatalf("parse config: %v", err)
+	}
+	repo := &websiteRecrawlRepositoryStub{
+		bindingItems: []sourcebindingentity.BindingItem{
+			{BindingID: 7, SourceItemID: 1}, {BindingID: 7, SourceItemID: 2},
+			{BindingID: 7, SourceItemID: 3}, {BindingID: 7, SourceItemID: 4},
+		},
+		sourceItems: []sourcebindingentity.SourceItem{
+			{ID: 1, RootRef: "cms", ItemRef: "cms:same", SnapshotMeta: map[string]any{"external_id": "same", "content_hash": "h-same"}},
+			{ID: 2, RootRef: "cms", ItemRef: "cms:edited", SnapshotMeta: map[string]any{"external_id": "edited", "content_hash": "h-old"}},
+			{ID: 3, RootRef: "cms", ItemRef: "cms:moved", SnapshotMeta: map[string]any{"url": "https://cms.example.com/v1.pdf"}},
+			{ID: 4, RootRef: "cms", ItemRef: "cms:gone"},
+		},
+		nextItemID: 100,
+	}
+	store := &websiteRecrawlDocumentStore{
+		bindingChangeManagedDocumentStore: bindingChangeManagedDocumentStore{createResult: &ManagedDocument{Code: "DOC-NEW"}},
+		documents: []*ManagedDocument{
+			{Code: "DOC-SAME", SourceItemID: 1, ThirdFileID: "cms:same", DocumentFile: &docentity.File{}},
+			{Code: "DOC-EDITED", SourceItemID: 2, ThirdFileID: "cms:edited", DocumentFile: &docentity.File{}},
+			{Code: "DOC-MOVED", SourceItemID: 3, ThirdFileID: "cms:moved", DocumentFile: &docentity.File{URL: "https://cms.example.com/v1.pdf"}},
+			{Code: "DOC-GONE", SourceItemID: 4, ThirdFileID: "cms:gone", DocumentFile: &docentity.File{}},
+		},
+	}
+	flow := &KnowledgeBaseDocumentFlowApp{
+		support:          &KnowledgeBaseAppService{sourceBindingRepo: repo},
+		managedDocuments: store,
+	}
+	events := []sourcebindingservice.WebhookEvent{
+		{Action: "upsert", ExternalID: "same", Name: "Same", Content: "same", Extension: "md", ContentHash: "h-same"},
+		{Action: "upsert", ExternalID: "edited", Name: "Edited", Content: "# v2", Extension: "md", ContentHash: "h-new"},
+		{Action: "upsert", ExternalID: "moved", Name: "Moved", URL: "https://cms.example.com/v2.pdf", Extension: "pdf"},
+		{Action: "upsert", ExternalID: "fresh", Name: "Fresh", Content: "# fresh", Extension: "md", ContentHash: "h-fresh"},
+		{Action: "upsert", ExternalID: "binary", Name: "Binary", URL: "https://cms.example.com/a.exe", Extension: "exe"},
+		{Action: "delete", ExternalID: "gone"},
+		{Action: "delete", ExternalID: "missing"},
+	}
+
+	results, err := flow.applyWebhookEvents(context.Background(), binding, config, events, repo, now)
+	if err != nil {
+		t.Fatalf("apply webhook events: %v", err)
+	}
+	wantStatuses := []string{
+		WebhookEventStatusUnchanged,
+		WebhookEventStatusResynced,
+		WebhookEventStatusReplaced,
+		WebhookEventStatusCreated,
+		WebhookEventStatusSkipped,
+		WebhookEventStatusDeleted,
+		WebhookEventStatusNotFound,
+	}
+	for idx, want := range wantStatuses {
+		if results[idx].Status != want {
+			t.Fatalf("event %s: expected status %q, got %#v", events[idx].ExternalID, want, results[idx])
+		}
+	}
+	if len(repo.upserted) != 3 || repo.upserted[0].ItemRef != "cms:edited" ||
+		repo.upserted[0].SnapshotMeta["content_hash"] != "h-new" {
+		t.Fatalf("expected only changed items to be upserted, got %#v", repo.upserted)
+	}
+	if len(store.destroyed) != 2 || store.destroyed[0] != "DOC-GONE" || store.destroyed[1] != "DOC-MOVED" {
+		t.Fatalf("expected deleted and replaced documents destroyed, got %#v", store.destroyed)
+	}
+	if len(store.createInputs) != 2 || store.createInputs[1].ThirdFileID != "cms:fresh" ||
+		store.createInputs[1].DocumentFile.SourceType != sourcebindingentity.ProviderWebhook ||
+		store.createInputs[1].DocMetadata[documentdomain.DocMetaWebhookExternalID] != "fresh" {
+		t.Fatalf("unexpected create inputs: %#v", store.createInputs)
+	}
+	if len(store.syncs) != 3 {
+		t.Fatalf("expected three syncs, got %#v", store.syncs)
+	}
+	edited, moved, fresh := store.syncs[0], store.syncs[1], store.syncs[2]
+	if edited.Code != "DOC-EDITED" || edited.Mode != knowledgeBaseSyncModeResync ||
+		edited.SourceOverride == nil || edited.SourceOverride.Content != "# v2" {
+		t.Fatalf("expected inline resync with override, got %#v", edited)
+	}
+	if moved.Mode != knowledgeBaseSyncModeCreate || moved.SourceOverride != nil {
+		t.Fatalf("expected url replacement without override, got %#v", moved)
+	}
+	if fresh.SourceOverride == nil || fresh.SourceOverride.Source != documentdomain.WebhookSourceOverrideSource {
+		t.Fatalf("expected inline create with webhook override, got %#v", fresh)
+	}
+	if len(repo.replacedItems) != 4 {
+		t.Fatalf("expected deleted item dropped and new item added, got %#v", repo.replacedItems)
+	}
+	for _, item := range repo.replacedItems {
+		if item.SourceItemID == 4 {
+			t.Fatalf("expected deleted item removed from binding, got %#v", repo.replacedItems)
+		}
+	}
+}
+
+func TestResolveWebhookBindingDocumentsKeepsPushedDocuments(t *testing.T) {
+	t.Parallel()
+
+	store := &webhookListDocumentStore{documents: []*ManagedDocument{
+		{Code: "DOC-A", ThirdFileID: "cms:a", DocumentFile: &docentity.File{Name: "A", Extension: "md", SourceType: "webhook"}},
+		{Code: "DOC-B", ThirdFileID: "other:b", DocumentFile: &docentity.File{Name: "B", SourceType: "webhook"}},
+		{Code: "DOC-C", ThirdFileID: "cms:c", DocumentFile: &docentity.File{Name: "C", SourceType: "local_upload"}},
+	}}
+	repo := &websiteRecrawlRepositoryStub{sourceItems: []sourcebindingentity.SourceItem{
+		{ID: 1, RootRef: "cms", ItemRef: "cms:a", SnapshotMeta: map[string]any{"content_hash": "h-a"}},
+	}}
+	flow := &KnowledgeBaseDocumentFlowApp{support: &KnowledgeBaseAppService{sourceBindingRepo: repo}, managedDocuments: store}
+
+	documents, err := flow.resolveBindingDocuments(context.Background(), webhookTestBinding(), "ORG1", "U1", 10)
+	if err != nil {
+		t.Fatalf("resolve webhook binding: %v", err)
+	}
+	if len(documents) != 1 || documents[0].ItemRef != "cms:a" || documents[0].SnapshotMeta["content_hash"] != "h-a" ||
+		documents[0].DocMetadata[documentdomain.DocMetaWebhookExternalID] != "a" {
+		t.Fatalf("expected only pushed documents of the channel with preserved snapshot, got %#v", documents)
+	}
+}
+
+func TestWebhookSourceServiceIngestVerifiesSignatureAndLock(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_780_000_000, 0)
+	body := []byte(`{"events":[{"action":"delete","external_id":"gone"}]}`)
+	repo := &webhookSourceRepositoryStub{bindings: []sourcebindingentity.Binding{webhookTestBinding()}}
+	store := &websiteRecrawlDocumentStore{}
+	support := &KnowledgeBaseAppService{sourceBindingRepo: repo, logger: logging.New()}
+	support.documentFlow = &KnowledgeBaseDocumentFlowApp{support: support, managedDocuments: store}
+	svc, err := NewWebhookSourceService(support, repo, lockpkg.NewLocalSinglePodJobRunner(), nil, logging.New())
+	if err != nil {
+		t.Fatalf("new webhook source service: %v", err)
+	}
+	svc.now = func() time.Time { return now }
+	input := WebhookIngestInput{
+		KnowledgeBaseCode: "KB1",
+		Channel:           "cms",
+		Timestamp:         strconv.FormatInt(now.Unix(), 10),
+		Signature:         sourcebindingentity.SignWebhookPayload(webhookInternalTestSecret, now.Unix(), body),
+		Body:              body,
+	}
+
+	results, err := svc.IngestWebhookEvents(context.Background(), input)
+	if err != nil || len(results) != 1 || results[0].Status != WebhookEventStatusNotFound {
+		t.Fatalf("unexpected ingest result: %#v err=%v", results, err)
+	}
+
+	tampered := input
+	tampered.Body = []byte(`{"events":[{"action":"delete","external_id":"other"}]}`)
+	if _, err := svc.IngestWebhookEvents(context.Background(), tampered); !errors.Is(err, sourcebindingservice.ErrWebhookSignatureInvalid) {
+		t.Fatalf("expected invalid signature, got %v", err)
+	}
+	unknown := input
+	unknown.Channel = "crm"
+	if _, err := svc.IngestWebhookEvents(context.Background(), unknown); !errors.Is(err, ErrWebhookSourceNotFound) {
+		t.Fatalf("expected unknown channel, got %v", err)
+	}
+	svc.jobRunner = lockedSinglePodJobRunnerStub{}
+	if _, err := svc.IngestWebhookEvents(context.Background(), input); !errors.Is(err, ErrWebhookSourceBusy) {
+		t.Fatalf("expected busy channel, got %v", err)
+	}
+}
+
+func TestWebhookSourceServiceObserveDocumentSyncResultNotifiesCallback(t *testing.T) {
+	t.Parallel()
+
+	repo := &webhookSourceRepositoryStub{bindings: []sourcebindingentity.Binding{webhookTestBinding()}}
+	notifier := &webhookCallbackNotifierStub{}
+	svc, err := NewWebhookSourceService(&KnowledgeBaseAppService{}, repo, lockpkg.NewLocalSinglePodJobRunner(), notifier, logging.New())
+	if err != nil {
+		t.Fatalf("new webhook source service: %v", err)
+	}
+	svc.now = func() time.Time { return time.Unix(1_780_000_000, 0) }
+
+	svc.ObserveDocumentSyncResult(context.Background(), &docentity.KnowledgeBaseDocument{
+		Code:              "DOC-1",
+		KnowledgeBaseCode: "KB1",
+		SourceBindingID:   7,
+		DocMetadata:       documentdomain.BuildWebhookDocMetadata("cms", "a-1"),
+		SyncStatus:        shared.SyncStatusSyncFailed,
+		SyncStatusMessage: "parsing failed",
+	})
+	svc.ObserveDocumentSyncResult(context.Background(), &docentity.KnowledgeBaseDocument{Code: "DOC-2", KnowledgeBaseCode: "KB1"})
+
+	if len(notifier.events) != 1 {
+		t.Fatalf("expected one callback for webhook document, got %#v", notifier.events)
+	}
+	event := notifier.events[0]
+	if event.Status != sourcebindingentity.WebhookCallbackStatusFailed || event.Message != "parsing failed" ||
+		event.ExternalID != "a-1" || event.DocumentCode != "DOC-1" || event.OccurredAt != 1_780_000_000 {
+		t.Fatalf("unexpected callback event: %#v", event)
+	}
+	if notifier.targets[0].URL != "https://cms.example.com/hooks/knowledge" || notifier.targets[0].SigningSecret != webhookInternalTestSecret {
+		t.Fatalf("unexpected callback target: %#v", notifier.targets[0])
+	}
+}
+
+type webhookListDocumentStore struct {
+	bindingChangeManagedDocumentStore
+
+	documents []*ManagedDocument
+}
+
+func (s *webhookListDocumentStore) ListManagedDocumentsByKnowledgeBase(context.Context, string) ([]*ManagedDocument, error) {
+	return s.documents, nil
+}
@@ -0,0 +1,367 @@
+package kbapp
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	texthelper "magic/internal/application/knowledge/helper/text"
+	docentity "magic/internal/domain/knowledge/document/entity"
+	sourcebindingdomain "magic/internal/domain/knowledge/sourcebinding/entity"
+	sourcebindingservice "magic/internal/domain/knowledge/sourcebinding/service"
+)
+
+const (
+	websiteResolveReasonCrawl   = "crawl"
+	websitePageDocumentFileType = "external"
+	websitePageExtension        = "html"
+)
+
+// websiteRecrawlState 汇总单个网站绑定重爬前的已物化状态。
+type websiteRecrawlState struct {
+	documentsByURL   map[string]*ManagedDocument
+	sourceItemsByURL map[string]sourcebindingdomain.SourceItem
+	validators       map[string]sourcebindingdomain.WebsitePageValidator
+}
+
+func (s *KnowledgeBaseDocumentFlowApp) resolveWebsiteBindingDocuments(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	maxDocuments int,
+) ([]sourcebindingservice.ResolvedDocument, error) {
+	config, err := sourcebindingdomain.ParseWebsiteCrawlConfig(binding)
+	if err != nil {
+		return nil, fmt.Errorf("parse website crawl config: %w", err)
+	}
+	result, err := s.crawlWebsite(ctx, config, maxDocuments, nil)
+	if err != nil {
+		return nil, err
+	}
+	documents := make([]sourcebindingservice.ResolvedDocument, 0, len(result.Pages)+len(result.Skipped))
+	for _, page := range result.Pages {
+		documents = append(documents, websitePageResolvedDocument(page))
+	}
+	for _, skipped := range result.Skipped {
+		documents = append(documents, sourcebindingservice.ResolvedDocument{
+			Name:          skipped.URL,
+			ItemRef:       skipped.URL,
+			GroupRef:      sourcebindingservice.WebsitePageGroupRef(skipped.URL),
+			Extension:     websitePageExtension,
+			ResolveReason: websiteResolveReasonCrawl,
+			SnapshotMeta:  map[string]any{sourcebindingservice.WebsiteSnapshotMetaURL: skipped.URL},
+			SkipReason:    skipped.Reason,
+		})
+	}
+	return documents, nil
+}
+
+func (s *KnowledgeBaseDocumentFlowApp) crawlWebsite(
+	ctx context.Context,
+	config sourcebindingdomain.WebsiteCrawlConfig,
+	maxDocuments int,
+	validators map[string]sourcebindingdomain.WebsitePageValidator,
+) (*sourcebindingdomain.WebsiteCrawlResult, error) {
+	if s == nil || s.support == nil || s.support.websiteCrawler == nil {
+		return nil, ErrKnowledgeBaseWebsiteCrawlerRequired
+	}
+	if maxDocuments > 0 {
+		config.MaxPages = min(config.MaxPages, maxDocuments)
+	}
+	result, err := s.support.websiteCrawler.Crawl(ctx, sourcebindingdomain.WebsiteCrawlRequest{
+		Config:     config,
+		Validators: validators,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("crawl website: %w", err)
+	}
+	return result, nil
+}
+
+func websitePageResolvedDocument(page sourcebindingdomain.WebsitePage) sourcebindingservice.ResolvedDocument {
+	name := cmp.Or(strings.TrimSpace(page.Title), page.URL)
+	return sourcebindingservice.ResolvedDocument{
+		Name: name,
+		DocumentFile: &docentity.File{
+			Type:       websitePageDocumentFileType,
+			Name:       name,
+			URL:        page.URL,
+			Extension:  websitePageExtension,
+			SourceType: sourcebindingdomain.ProviderWebsite,
+		},
+		DocumentType:  int(docentity.DocumentInputKindFile),
+		ItemRef:       page.URL,
+		GroupRef:      sourcebindingservice.WebsitePageGroupRef(page.URL),
+		Extension:     websitePageExtension,
+		ResolveReason: websiteResolveReasonCrawl,
+		SnapshotMeta:  sourcebindingservice.BuildWebsitePageSnapshotMeta(page),
+	}
+}
+
+// recrawlWebsiteBinding 在到期时重新爬取实时网站绑定，只为新增或变化的页面创建/重同步文档。
+//
+// 返回值表示本次是否实际执行了爬取。
+func (s *KnowledgeBaseDocumentFlowApp) recrawlWebsiteBinding(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	sourceItems sourceItemRefLister,
+	now time.Time,
+) (bool, error) {
+	if s == nil || s.support == nil || s.support.sourceBindingRepo == nil {
+		return false, ErrKnowledgeBaseSourceBindingRepositoryRequired
+	}
+	if s.managedDocuments == nil {
+		return false, ErrKnowledgeBaseDocumentFlowRequired
+	}
+	config, err := sourcebindingdomain.ParseWebsiteCrawlConfig(binding)
+	if err != nil {
+		return false, fmt.Errorf("parse website crawl config: %w", err)
+	}
+	lastCrawledAt, err := s.lastBindingResolvedAt(ctx, binding)
+	if err != nil {
+		return false, err
+	}
+	if !config.RecrawlDue(lastCrawledAt, now) {
+		return false, nil
+	}
+	state, err := s.loadWebsiteRecrawlState(ctx, binding, sourceItems)
+	if err != nil {
+		return false, err
+	}
+	result, err := s.crawlWebsite(ctx, config, knowledgeBaseMaterializeDocumentLimit, state.validators)
+	if err != nil {
+		return false, err
+	}
+	plan := sourcebindingservice.PlanWebsiteRecrawl(config, websiteKnownURLs(state), result)
+	if err := s.applyWebsiteRecrawlPlan(ctx, binding, state, plan, now); err != nil {
+		return false, err
+	}
+	s.logInfo(
+		ctx,
+		"Website source binding recrawled",
+		"knowledge_base_code", binding.KnowledgeBaseCode,
+		"binding_id", binding.ID,
+		"unchanged", len(plan.Unchanged),
+		"changed", len(plan.Changed),
+		"created", len(plan.Created),
+		"removed", len(plan.Removed),
+	)
+	return true, nil
+}
+
+func (s *KnowledgeBaseDocumentFlowApp) loadWebsiteRecrawlState(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	sourceItems sourceItemRefLister,
+) (websiteRecrawlState, error) {
+	documents, err := s.managedDocuments.ListManagedDocumentsBySourceBindingIDs(ctx, binding.KnowledgeBaseCode, []int64{binding.ID})
+	if err != nil {
+		return websiteRecrawlState{}, fmt.Errorf("list website managed documents: %w", err)
+	}
+	state := websiteRecrawlState{
+		documentsByURL:   make(map[string]*ManagedDocument, len(documents)),
+		sourceItemsByURL: make(map[string]sourcebindingdomain.SourceItem, len(documents)),
+		validators:       make(map[string]sourcebindingdomain.WebsitePageValidator, len(documents)),
+	}
+	urls := make([]string, 0, len(documents))
+	for _, document := range documents {
+		if document == nil || strings.TrimSpace(document.ThirdFileID) == "" {
+			continue
+		}
+		pageURL := strings.TrimSpace(document.ThirdFileID)
+		state.documentsByURL[pageURL] = document
+		urls = append(urls, pageURL)
+	}
+	if len(urls) == 0 {
+		return state, nil
+	}
+	items, err := sourceItems.ListSourceItemsByOrganizationProviderAndItemRefs(
+		ctx,
+		binding.OrganizationCode,
+		sourcebindingdomain.ProviderWebsite,
+		urls,
+	)
+	if err != nil {
+		return websiteRecrawlState{}, fmt.Errorf("list website source items: %w", err)
+	}
+	for _, item := range items {
+		if item.RootRef != binding.RootRef {
+			continue
+		}
+		state.sourceItemsByURL[item.ItemRef] = item
+		state.validators[item.ItemRef] = sourcebindingservice.WebsitePageValidatorFromSnapshot(item.SnapshotMeta)
+	}
+	return state, nil
+}
+
+func websiteKnownURLs(state websiteRecrawlState) []string {
+	urls := make([]string, 0, len(state.documentsByURL))
+	for pageURL := range state.documentsByURL {
+		urls = append(urls, pageURL)
+	}
+	return urls
+}
+
+func (s *KnowledgeBaseDocumentFlowApp) applyWebsiteRecrawlPlan(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	state websiteRecrawlState,
+	plan sourcebindingservice.WebsiteRecrawlPlan,
+	now time.Time,
+) error {
+	if err := s.destroyWebsiteRecrawlDocuments(ctx, binding, state, plan.Removed); err != nil {
+		return err
+	}
+	upserted, err := s.upsertWebsitePageSourceItems(ctx, binding, append(append([]sourcebindingdomain.WebsitePage(nil), plan.Changed...), plan.Created...), now)
+	if err != nil {
+		return err
+	}
+
+	syncUserID := cmp.Or(binding.UpdatedUID, binding.CreatedUID)
+	bindingItems := make([]sourcebindingdomain.BindingItem, 0, len(plan.Unchanged)+len(plan.Changed)+len(plan.Created))
+	pendingSyncs := make([]*SyncDocumentInput, 0, len(plan.Changed)+len(plan.Created))
+	for _, pageURL := range plan.Unchanged {
+		if item, exists := state.sourceItemsByURL[pageURL]; exists {
+			bindingItems = append(bindingItems, websiteBindingItem(binding.ID, item.ID, now))
+		}
+	}
+	for _, page := range plan.Changed {
+		bindingItems = append(bindingItems, websiteBindingItem(binding.ID, upserted[page.URL].ID, now))
+		pendingSyncs = append(pendingSyncs, websiteSyncInput(binding, state.documentsByURL[page.URL].Code, knowledgeBaseSyncModeResync, syncUserID))
+	}
+	createdItems, createdSyncs, err := s.createWebsitePageDocuments(ctx, binding, plan.Created, upserted, syncUserID, now)
+	if err != nil {
+		return err
+	}
+	bindingItems = append(bindingItems, createdItems...)
+	pendingSyncs = append(pendingSyncs, createdSyncs...)
+	if err := s.support.sourceBindingRepo.ReplaceBindingItems(ctx, binding.ID, bindingItems); err != nil {
+		return fmt.Errorf("replace website binding items: %w", err)
+	}
+	for _, input := range pendingSyncs {
+		s.managedDocuments.ScheduleManagedDocumentSync(ctx, input)
+	}
+	return nil
+}
+
+func (s *KnowledgeBaseDocumentFlowApp) createWebsitePageDocuments(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	pages []sourcebindingdomain.WebsitePage,
+	sourceItems map[string]*sourcebindingdomain.SourceItem,
+	syncUserID string,
+	now time.Time,
+) ([]sourcebindingdomain.BindingItem, []*SyncDocumentInput, error) {
+	bindingItems := make([]sourcebindingdomain.BindingItem, 0, len(pages))
+	pendingSyncs := make([]*SyncDocumentInput, 0, len(pages))
+	for _, page := range pages {
+		sourceItem := sourceItems[page.URL]
+		resolved := websitePageResolvedDocument(page)
+		documentFile, _ := resolved.DocumentFile.(*docentity.File)
+		created, err := s.managedDocuments.CreateManagedDocument(ctx, &CreateManagedDocumentInput{
+			OrganizationCode:  binding.OrganizationCode,
+			UserID:            syncUserID,
+			KnowledgeBaseCode: binding.KnowledgeBaseCode,
+			SourceBindingID:   binding.ID,
+			SourceItemID:      sourceItem.ID,
+			Name:              resolved.Name,
+			DocType:           resolved.DocumentType,
+			DocumentFile:      documentFile,
+			ThirdFileID:       sourceItem.ItemRef,
+			AutoSync:          false,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("create website managed document: %w", err)
+		}
+		bindingItems = append(bindingItems, websiteBindingItem(binding.ID, sourceItem.ID, now))
+		pendingSyncs = append(pendingSyncs, websiteSyncInput(binding, created.Code, knowledgeBaseSyncModeCreate, syncUserID))
+	}
+	return bindingItems, pendingSyncs, nil
+}
+
+func (s *KnowledgeBaseDocumentFlowApp) destroyWebsiteRecrawlDocuments(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	state websiteRecrawlState,
+	removedURLs []string,
+) error {
+	codes := make([]string, 0, len(removedURLs))
+	for _, pageURL := range removedURLs {
+		if document := state.documentsByURL[pageURL]; document != nil {
+			codes = append(codes, document.Code)
+		}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	if err := s.managedDocuments.DestroyManagedDocumentsByCodes(ctx, binding.KnowledgeBaseCode, binding.OrganizationCode, codes); err != nil {
+		return fmt.Errorf("destroy removed website documents: %w", err)
+	}
+	return nil
+}
+
+func (s *KnowledgeBaseDocumentFlowApp) upsertWebsitePageSourceItems(
+	ctx context.Context,
+	binding sourcebindingdomain.Binding,
+	pages []sourcebindingdomain.WebsitePage,
+	now time.Time,
+) (map[string]*sourcebindingdomain.SourceItem, error) {
+	result := make(map[string]*sourcebindingdomain.SourceItem, len(pages))
+	if len(pages) == 0 {
+		return result, nil
+	}
+	items := make([]sourcebindingdomain.SourceItem, 0, len(pages))
+	for _, page := range pages {
+		resolved := websitePageResolvedDocument(page)
+		resolvedAt := now
+		items = append(items, sourcebindingdomain.SourceItem{
+			OrganizationCode: binding.OrganizationCode,
+			Provider:         binding.Provider,
+			RootType:         binding.RootType,
+			RootRef:          binding.RootRef,
+			GroupRef:         resolved.GroupRef,
+			ItemType:         sourcebindingdomain.RootTypeFile,
+			ItemRef:          resolved.ItemRef,
+			DisplayName:      resolved.Name,
+			Extension:        resolved.Extension,
+			SnapshotMeta:     resolved.SnapshotMeta,
+			LastResolvedAt:   &resolvedAt,
+		})
+	}
+	upserted, err := s.support.sourceBindingRepo.UpsertSourceItems(ctx, items)
+	if err != nil {
+		return nil, fmt.Errorf("upsert website source items: %w", err)
+	}
+	for _, item := range upserted {
+		if item != nil {
+			result[item.ItemRef] = item
+		}
+	}
+	for _, page := range pages {
+		if result[page.URL] == nil {
+			return nil, fmt.Errorf("%w: %s", errIncrementalResolvedSourceItemNotFound, page.URL)
+		}
+	}
+	return result, nil
+}
+
+func websiteBindingItem(bindingID, sourceItemID int64, now time.Time) sourcebindingdomain.BindingItem {
+	resolvedAt := now
+	return sourcebindingdomain.BindingItem{
+		BindingID:      bindingID,
+		SourceItemID:   sourceItemID,
+		ResolveReason:  websiteResolveReasonCrawl,
+		LastResolvedAt: &resolvedAt,
+	}
+}
+
+func websiteSyncInput(binding sourcebindingdomain.Binding, code, mode, userID string) *SyncDocumentInput {
+	return &SyncDocumentInput{
+		OrganizationCode:  binding.OrganizationCode,
+		KnowledgeBaseCode: binding.KnowledgeBaseCode,
+		Code:              code,
+		Mode:              mode,
+		BusinessParams:    texthelper.BuildCreateBusinessParams(binding.OrganizationCode, userID, binding.KnowledgeBaseCode),
+	}
+}
@@ -0,0 +1,233 @@
+package kbapp
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
+	sourcebindingservice "magic/internal/domain/knowledge/sourcebinding/service"
+)
+
+type websiteCrawlerStub struct {
+	result  *sourcebindingentity.WebsiteCrawlResult
+	request sourcebindingentity.WebsiteCrawlRequest
+	calls   int
+}
+
+func (s *websiteCrawlerStub) Crawl(
+	_ context.Context,
+	request sourcebindingentity.WebsiteCrawlRequest,
+) (*sourcebindingentity.WebsiteCrawlResult, error) {
+	s.calls++
+	s.request = request
+	return s.result, nil
+}
+
+type websiteRecrawlRepositoryStub struct {
+	sourceBindingRepository
+
+	bindingItems  []sourcebindingentity.BindingItem
+	sourceItems   []sourcebindingentity.SourceItem
+	upserted      []sourcebindingentity.SourceItem
+	replacedItems []sourcebindingentity.BindingItem
+	nextItemID    int64
+}
+
+func (r *websiteRecrawlRepositoryStub) ListBindingItemsByKnowledgeBase(
+	context.Context,
+	string,
+) ([]sourcebindingentity.BindingItem, error) {
+	return r.bindingItems, nil
+}
+
+func (r *websiteRecrawlRepositoryStub) ListSourceItemsByOrganizationProviderAndItemRefs(
+	_ context.Context,
+	_ string,
+	_ string,
+	itemRefs []string,
+) ([]sourcebindingentity.SourceItem, error) {
+	items := make([]sourcebindingentity.SourceItem, 0, len(itemRefs))
+	for _, item := range r.sourceItems {
+		if slices.Contains(itemRefs, item.ItemRef) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (r *websiteRecrawlRepositoryStub) UpsertSourceItems(
+	_ context.Context,
+	items []sourcebindingentity.SourceItem,
+) ([]*sourcebindingentity.SourceItem, error) {
+	result := make([]*sourcebindingentity.SourceItem, 0, len(items))
+	for _, item := range items {
+		r.upserted = append(r.upserted, item)
+		for _, existing := range r.sourceItems {
+			if existing.ItemRef == item.ItemRef {
+				item.ID = existing.ID
+			}
+		}
+		if item.ID == 0 {
+			r.nextItemID++
+			item.ID = r.nextItemID
+		}
+		result = append(result, &item)
+	}
+	return result, nil
+}
+
+func (r *websiteRecrawlRepositoryStub) ReplaceBindingItems(
+	_ context.Context,
+	_ int64,
+	items []sourcebindingentity.BindingItem,
+) error {
+	r.replacedItems = items
+	return nil
+}
+
+type websiteRecrawlDocumentStore struct {
+	bindingChangeManagedDocumentStore
+
+	documents []*ManagedDocument
+	destroyed []string
+	syncs     []*SyncDocumentInput
+}
+
+func (s *websiteRecrawlDocumentStore) DestroyManagedDocumentsByCodes(_ context.Context, _, _ string, codes []string) error {
+	s.destroyed = append(s.destroyed, codes...)
+	return nil
+}
+
+func (s *websiteRecrawlDocumentStore) ScheduleManagedDocumentSync(_ context.Context, input *SyncDocumentInput) {
+	s.syncs = append(s.syncs, input)
+}
+
+func (s *websiteRecrawlDocumentStore) ListManagedDocumentsBySourceBindingIDs(
+	context.Context,
+	string,
+	[]int64,
+) ([]*ManagedDocument, error) {
+	return s.documents, nil
+}
+
+func TestResolveWebsiteBindingDocumentsMapsPagesAndSkips(t *testing.T) {
+	t.Parallel()
+
+	crawler := &websiteCrawlerStub{result: &sourcebindingentity.WebsiteCrawlResult{
+		Pages: []sourcebindingentity.WebsitePage{
+			{URL: "https://docs.example.com/guide/install", Title: "Install", ETag: `"v1"`},
+			{URL: "https://docs.example.com/faq"},
+		},
+		Skipped: []sourcebindingentity.WebsitePageSkip{
+			{URL: "https://docs.example.com/manual.pdf", Reason: sourcebindingentity.WebsitePageSkipUnsupportedContent},
+		},
+	}}
+	flow := &KnowledgeBaseDocumentFlowApp{support: &KnowledgeBaseAppService{websiteCrawler: crawler}}
+
+	documents, err := flow.resolveBindingDocuments(context.Background(), sourcebindingentity.Binding{
+		Provider:   sourcebindingentity.ProviderWebsite,
+		RootType:   sourcebindingentity.RootTypeSite,
+		RootRef:    "https://docs.example.com/",
+		SyncConfig: map[string]any{"max_pages": 500},
+	}, "ORG1", "U1", 100)
+	if err != nil {
+		t.Fatalf("resolve website binding: %v", err)
+	}
+	if crawler.request.Config.MaxPages != 100 {
+		t.Fatalf("expected max pages capped by document limit, got %d", crawler.request.Config.MaxPages)
+	}
+	if len(documents) != 3 {
+		t.Fatalf("unexpected resolved documents: %#v", documents)
+	}
+	install := documents[0]
+	documentFile, _ := install.DocumentFile.(*docentity.File)
+	if install.Name != "Install" || install.ItemRef != "https://docs.example.com/guide/install" ||
+		install.GroupRef != "docs.example.com/guide" || documentFile == nil ||
+		documentFile.URL != install.ItemRef || documentFile.Extension != "html" ||
+		install.SnapshotMeta[sourcebindingservice.WebsiteSnapshotMetaETag] != `"v1"` {
+		t.Fatalf("unexpected page document: %#v file=%#v", install, documentFile)
+	}
+	if documents[1].Name != "https://docs.example.com/faq" {
+		t.Fatalf("expected url as fallback name, got %q", documents[1].Name)
+	}
+	if documents[2].SkipReason != sourcebindingentity.WebsitePageSkipUnsupportedContent {
+		t.Fatalf("expected skipped pdf page, got %#v", documents[2])
+	}
+}
+
+func TestRecrawlWebsiteBindingAppliesIncrementalChanges(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	lastCrawledAt := now.Add(-25 * time.Hour)
+	binding := sourcebindingentity.Binding{
+		ID:                9,
+		OrganizationCode:  "ORG1",
+		KnowledgeBaseCode: "KB1",
+		Provider:          sourcebindingentity.ProviderWebsite,
+		RootType:          sourcebindingentity.RootTypeSite,
+		RootRef:           "https://docs.example.com/",
+		SyncMode:          sourcebindingentity.SyncModeRealtime,
+		CreatedUID:        "U1",
+	}
+	repo := &websiteRecrawlRepositoryStub{
+		bindingItems: []sourcebindingentity.BindingItem{{BindingID: 9, SourceItemID: 1, LastResolvedAt: &lastCrawledAt}},
+		sourceItems: []sourcebindingentity.SourceItem{
+			{ID: 1, RootRef: binding.RootRef, ItemRef: "https://docs.example.com/a", SnapshotMeta: map[string]any{"etag": `"a1"`}},
+			{ID: 2, RootRef: binding.RootRef, ItemRef: "https://docs.example.com/b", SnapshotMeta: map[string]any{"etag": `"b1"`}},
+			{ID: 3, RootRef: binding.RootRef, ItemRef: "https://docs.example.com/c"},
+		},
+		nextItemID: 100,
+	}
+	store := &websiteRecrawlDocumentStore{documents: []*ManagedDocument{
+		{Code: "DOC-A", ThirdFileID: "https://docs.example.com/a"},
+		{Code: "DOC-B", ThirdFileID: "https://docs.example.com/b"},
+		{Code: "DOC-C", ThirdFileID: "https://docs.example.com/c"},
+	}}
+	crawler := &websiteCrawlerStub{result: &sourcebindingentity.WebsiteCrawlResult{
+		Pages: []sourcebindingentity.WebsitePage{
+			{URL: "https://docs.example.com/a", NotModified: true, ETag: `"a1"`},
+			{URL: "https://docs.example.com/b", Title: "B", ETag: `"b2"`},
+			{URL: "https://docs.example.com/new", Title: "New"},
+		},
+		GoneURLs: []string{"https://docs.example.com/c"},
+	}}
+	flow := &KnowledgeBaseDocumentFlowApp{
+		support:          &KnowledgeBaseAppService{sourceBindingRepo: repo, websiteCrawler: crawler},
+		managedDocuments: store,
+	}
+
+	done, err := flow.recrawlWebsiteBinding(context.Background(), binding, repo, now)
+	if err != nil || !done {
+		t.Fatalf("recrawl website binding done=%v err=%v", done, err)
+	}
+	if crawler.request.Validators["https://docs.example.com/b"].ETag != `"b1"` {
+		t.Fatalf("expected stored validators to be sent, got %#v", crawler.request.Validators)
+	}
+	if !slices.Equal(store.destroyed, []string{"DOC-C"}) {
+		t.Fatalf("expected gone page document destroyed, got %v", store.destroyed)
+	}
+	if len(store.syncs) != 2 || store.syncs[0].Code != "DOC-B" || store.syncs[0].Mode != knowledgeBaseSyncModeResync ||
+		store.syncs[1].Mode != knowledgeBaseSyncModeCreate {
+		t.Fatalf("unexpected scheduled syncs: %#v", store.syncs)
+	}
+	if len(repo.upserted) != 2 || repo.upserted[0].SnapshotMeta[sourcebindingservice.WebsiteSnapshotMetaETag] != `"b2"` {
+		t.Fatalf("unexpected upserted source items: %#v", repo.upserted)
+	}
+	itemIDs := make([]int64, 0, len(repo.replacedItems))
+	for _, item := range repo.replacedItems {
+		itemIDs = append(itemIDs, item.SourceItemID)
+	}
+	if !slices.Equal(itemIDs, []int64{1, 2, 101}) {
+		t.Fatalf("unexpected binding items: %v", itemIDs)
+	}
+
+	repo.bindingItems = repo.replacedItems
+	done, err = flow.recrawlWebsiteBinding(context.Background(), binding, repo, now.Add(time.Hour))
+	if err != nil || done || crawler.calls != 1 {
+		t.Fatalf("expected recrawl to wait for interval, done=%v err=%v calls=%d", done, err, crawler.calls)
+	}
+}
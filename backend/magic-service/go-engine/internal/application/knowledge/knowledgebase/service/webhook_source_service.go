@@ -0,0 +1,238 @@
+package kbapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"magic/internal/constants"
+	docentity "magic/internal/domain/knowledge/document/entity"
+	documentdomain "magic/internal/domain/knowledge/document/service"
+	"magic/internal/domain/knowledge/shared"
+	sourcebindingdomain "magic/internal/domain/knowledge/sourcebinding/entity"
+	sourcebindingservice "magic/internal/domain/knowledge/sourcebinding/service"
+	"magic/internal/infrastructure/logging"
+	lockpkg "magic/internal/pkg/lock"
+	"magic/internal/pkg/logkey"
+)
+
+const (
+	// 同一绑定的推送串行处理；锁被占用时直接拒绝，由外部系统按退避重试。
+	webhookSourceLockTTL            = time.Minute
+	webhookSourceLockAcquireTimeout = 2 * time.Second
+)
+
+const (
+	// WebhookTimestampHeader 复用推送签名时间戳请求头。
+	WebhookTimestampHeader = sourcebindingdomain.WebhookTimestampHeader
+	// WebhookSignatureHeader 复用推送签名请求头。
+	WebhookSignatureHeader = sourcebindingdomain.WebhookSignatureHeader
+)
+
+var (
+	// ErrWebhookSourceJobRunnerRequired 表示缺少推送串行化所需的锁执行器。
+	ErrWebhookSourceJobRunnerRequired = errors.New("webhook source single pod job runner is required")
+	// ErrWebhookSourceRepositoryRequired 表示缺少 webhook 绑定查询依赖。
+	ErrWebhookSourceRepositoryRequired = errors.New("webhook source binding repository is required")
+	// ErrWebhookSourceNotFound 表示知识库下没有对应通道的 webhook 绑定。
+	ErrWebhookSourceNotFound = errors.New("webhook source not found")
+	// ErrWebhookSourceDisabled 表示 webhook 绑定已停用。
+	ErrWebhookSourceDisabled = errors.New("webhook source disabled")
+	// ErrWebhookSourceBusy 表示同一通道正在处理另一批推送。
+	ErrWebhookSourceBusy = errors.New("webhook source is processing another delivery")
+	// ErrInvalidWebhookSourceConfig 复用领域层 webhook 绑定配置非法错误。
+	ErrInvalidWebhookSourceConfig = sourcebindingdomain.ErrInvalidWebhookSourceConfig
+	// ErrWebhookSignatureInvalid 复用领域层推送签名无效错误。
+	ErrWebhookSignatureInvalid = sourcebindingservice.ErrWebhookSignatureInvalid
+	// ErrWebhookTimestampExpired 复用领域层推送时间戳过期错误。
+	ErrWebhookTimestampExpired = sourcebindingservice.ErrWebhookTimestampExpired
+	// ErrInvalidWebhookEvent 复用领域层推送事件非法错误。
+	ErrInvalidWebhookEvent = sourcebindingservice.ErrInvalidWebhookEvent
+)
+
+// WebhookSourceBindingRepository 定义 webhook 推送来源所需的来源绑定查询能力。
+type WebhookSourceBindingRepository interface {
+	sourceItemRefLister
+	ListBindingsByKnowledgeBase(ctx context.Context, knowledgeBaseCode string) ([]sourcebindingdomain.Binding, error)
+}
+
+// WebhookIngestInput 表示一次 webhook 推送请求。
+type WebhookIngestInput struct {
+	KnowledgeBaseCode string
+	Channel           string
+	Timestamp         string
+	Signature         string
+	Body              []byte
+}
+
+// WebhookSourceService 接收外部系统推送的条目变更，并在同步结束后回调通知结果。
+type WebhookSourceService struct {
+	support   *KnowledgeBaseAppService
+	bindings  WebhookSourceBindingRepository
+	jobRunner lockpkg.SinglePodJobRunner
+	notifier  sourcebindingdomain.WebhookCallbackNotifier
+	logger    *logging.SugaredLogger
+	now       func() time.Time
+}
+
+// NewWebhookSourceService 创建 webhook 推送来源服务。
+func NewWebhookSourceService(
+	support *KnowledgeBaseAppService,
+	bindings WebhookSourceBindingRepository,
+	jobRunner lockpkg.SinglePodJobRunner,
+	notifier sourcebindingdomain.WebhookCallbackNotifier,
+	logger *logging.SugaredLogger,
+) (*WebhookSourceService, error) {
+	if jobRunner == nil {
+		return nil, ErrWebhookSourceJobRunnerRequired
+	}
+	if bindings == nil {
+		return nil, ErrWebhookSourceRepositoryRequired
+	}
+	return &WebhookSourceService{
+		support:   support,
+		bindings:  bindings,
+		jobRunner: jobRunner,
+		notifier:  notifier,
+		logger:    logger,
+		now:       time.Now,
+	}, nil
+}
+
+// IngestWebhookEvents 校验推送签名后应用事件，返回每个事件的处理结果。
+func (s *WebhookSourceService) IngestWebhookEvents(ctx context.Context, input WebhookIngestInput) ([]WebhookEventResult, error) {
+	binding, config, err := s.findBinding(ctx, input.KnowledgeBaseCode, input.Channel)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.VerifyWebhookSignature(input.Timestamp, input.Signature, input.Body, s.now()); err != nil {
+		return nil, fmt.Errorf("verify webhook delivery: %w", err)
+	}
+	// 签名通过后再暴露绑定状态，避免未授权请求探测通道是否启用。
+	if !binding.Enabled {
+		return nil, ErrWebhookSourceDisabled
+	}
+	events, err := sourcebindingservice.ParseWebhookEvents(input.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parse webhook delivery: %w", err)
+	}
+	flow, err := s.support.requireDocumentFlow()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []WebhookEventResult
+	lockKey := constants.KnowledgeWebhookSourceLockKeyPrefix + strconv.FormatInt(binding.ID, 10)
+	status, err := s.jobRunner.Run(ctx, lockpkg.SinglePodJobRequest{
+		LockKey:        lockKey,
+		LockTTL:        webhookSourceLockTTL,
+		AcquireTimeout: webhookSourceLockAcquireTimeout,
+	}, func(jobCtx context.Context) error {
+		var applyErr error
+		results, applyErr = flow.applyWebhookEvents(jobCtx, binding, config, events, s.bindings, s.now())
+		return applyErr
+	})
+	if status.Status == lockpkg.SinglePodJobStatusSkippedLocked {
+		return nil, ErrWebhookSourceBusy
+	}
+	if err != nil {
+		return nil, fmt.Errorf("apply webhook events with distributed lock: %w", err)
+	}
+	s.logger.InfoContext(
+		ctx,
+		"Webhook source delivery applied",
+		"knowledge_base_code", binding.KnowledgeBaseCode,
+		"binding_id", binding.ID,
+		"channel", config.Channel,
+		"events", len(events),
+	)
+	return results, nil
+}
+
+// ObserveDocumentSyncResult 在推送来源文档同步结束后回调外部系统。
+//
+// 回调失败只记录日志，不影响文档同步结果；外部系统可以通过重新推送触发重试。
+func (s *WebhookSourceService) ObserveDocumentSyncResult(ctx context.Context, doc *docentity.KnowledgeBaseDocument) {
+	if s == nil || s.notifier == nil {
+		return
+	}
+	source, ok := documentdomain.ResolveWebhookDocumentSource(doc)
+	if !ok {
+		return
+	}
+	config, err := s.findBindingConfigByID(ctx, doc.KnowledgeBaseCode, doc.SourceBindingID)
+	if err != nil || config.CallbackURL == "" {
+		return
+	}
+	event := sourcebindingdomain.WebhookCallbackEvent{
+		Channel:           source.Channel,
+		KnowledgeBaseCode: doc.KnowledgeBaseCode,
+		ExternalID:        source.ExternalID,
+		DocumentCode:      doc.Code,
+		Status:            sourcebindingdomain.WebhookCallbackStatusSynced,
+		OccurredAt:        s.now().Unix(),
+	}
+	if doc.SyncStatus == shared.SyncStatusSyncFailed {
+		event.Status = sourcebindingdomain.WebhookCallbackStatusFailed
+		event.Message = doc.SyncStatusMessage
+	}
+	if err := s.notifier.NotifyWebhookCallback(ctx, config.CallbackTarget(), event); err != nil {
+		s.logger.KnowledgeWarnContext(
+			ctx,
+			"Notify webhook source callback failed",
+			"knowledge_base_code", doc.KnowledgeBaseCode,
+			"document_code", doc.Code,
+			"channel", source.Channel,
+			logkey.Error, err,
+		)
+	}
+}
+
+func (s *WebhookSourceService) findBinding(
+	ctx context.Context,
+	knowledgeBaseCode string,
+	channel string,
+) (sourcebindingdomain.Binding, sourcebindingservice.WebhookSourceConfig, error) {
+	bindings, err := s.bindings.ListBindingsByKnowledgeBase(ctx, strings.TrimSpace(knowledgeBaseCode))
+	if err != nil {
+		return sourcebindingdomain.Binding{}, sourcebindingservice.WebhookSourceConfig{}, fmt.Errorf("list webhook source bindings: %w", err)
+	}
+	channel = strings.TrimSpace(channel)
+	for _, binding := range bindings {
+		binding = sourcebindingdomain.NormalizeBinding(binding)
+		if binding.Provider != sourcebindingdomain.ProviderWebhook || binding.RootRef != channel {
+			continue
+		}
+		config, err := sourcebindingservice.ParseWebhookSourceConfig(binding)
+		if err != nil {
+			return sourcebindingdomain.Binding{}, sourcebindingservice.WebhookSourceConfig{}, fmt.Errorf("parse webhook source config: %w", err)
+		}
+		return binding, config, nil
+	}
+	return sourcebindingdomain.Binding{}, sourcebindingservice.WebhookSourceConfig{}, ErrWebhookSourceNotFound
+}
+
+func (s *WebhookSourceService) findBindingConfigByID(
+	ctx context.Context,
+	knowledgeBaseCode string,
+	bindingID int64,
+) (sourcebindingservice.WebhookSourceConfig, error) {
+	bindings, err := s.bindings.ListBindingsByKnowledgeBase(ctx, knowledgeBaseCode)
+	if err != nil {
+		return sourcebindingservice.WebhookSourceConfig{}, fmt.Errorf("list webhook source bindings: %w", err)
+	}
+	for _, binding := range bindings {
+		if binding.ID != bindingID || sourcebindingdomain.NormalizeProvider(binding.Provider) != sourcebindingdomain.ProviderWebhook {
+			continue
+		}
+		config, err := sourcebindingservice.ParseWebhookSourceConfig(binding)
+		if err != nil {
+			return sourcebindingservice.WebhookSourceConfig{}, fmt.Errorf("parse webhook source config: %w", err)
+		}
+		return config, nil
+	}
+	return sourcebindingservice.WebhookSourceConfig{}, ErrWebhookSourceNotFound
+}
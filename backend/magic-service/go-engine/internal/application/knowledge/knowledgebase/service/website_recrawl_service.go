@@ -0,0 +1,154 @@
+package kbapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"magic/internal/constants"
+	sourcebindingdomain "magic/internal/domain/knowledge/sourcebinding/entity"
+	"magic/internal/infrastructure/logging"
+	lockpkg "magic/internal/pkg/lock"
+	"magic/internal/pkg/logkey"
+)
+
+const (
+	// 重爬巡检间隔；每个绑定是否真正重爬由其 recrawl_interval_hours 决定。
+	defaultWebsiteRecrawlCheckInterval = 15 * time.Minute
+	websiteRecrawlLockAcquireTimeout   = 2 * time.Second
+)
+
+var (
+	// ErrWebsiteRecrawlJobRunnerRequired 表示缺少单 pod 任务执行器。
+	ErrWebsiteRecrawlJobRunnerRequired = errors.New("website recrawl single pod job runner is required")
+	// ErrWebsiteRecrawlRepositoryRequired 表示缺少网站绑定查询依赖。
+	ErrWebsiteRecrawlRepositoryRequired = errors.New("website recrawl binding repository is required")
+)
+
+// WebsiteRecrawlBindingRepository 定义网站定时重爬所需的来源绑定查询能力。
+type WebsiteRecrawlBindingRepository interface {
+	sourceItemRefLister
+	ListRealtimeBindingsByProvider(ctx context.Context, provider string) ([]sourcebindingdomain.Binding, error)
+}
+
+// WebsiteRecrawlService 定时重新爬取实时同步的网站来源绑定。
+type WebsiteRecrawlService struct {
+	support       *KnowledgeBaseAppService
+	bindings      WebsiteRecrawlBindingRepository
+	jobRunner     lockpkg.SinglePodJobRunner
+	scheduler     *cron.Cron
+	checkInterval time.Duration
+	logger        *logging.SugaredLogger
+	now           func() time.Time
+}
+
+// NewWebsiteRecrawlService 创建网站来源定时重爬服务。
+func NewWebsiteRecrawlService(
+	support *KnowledgeBaseAppService,
+	bindings WebsiteRecrawlBindingRepository,
+	jobRunner lockpkg.SinglePodJobRunner,
+	logger *logging.SugaredLogger,
+) (*WebsiteRecrawlService, error) {
+	if jobRunner == nil {
+		return nil, ErrWebsiteRecrawlJobRunnerRequired
+	}
+	if bindings == nil {
+		return nil, ErrWebsiteRecrawlRepositoryRequired
+	}
+	return &WebsiteRecrawlService{
+		support:       support,
+		bindings:      bindings,
+		jobRunner:     jobRunner,
+		scheduler:     cron.New(cron.WithLocation(time.Local)),
+		checkInterval: defaultWebsiteRecrawlCheckInterval,
+		logger:        logger,
+		now:           time.Now,
+	}, nil
+}
+
+// StartRecrawlDaemon 启动网站重爬守护进程，直到 ctx 取消。
+func (s *WebsiteRecrawlService) StartRecrawlDaemon(ctx context.Context) error {
+	spec := "@every " + s.checkInterval.String()
+	if _, err := s.scheduler.AddFunc(spec, func() {
+		if err := s.runScheduledRecrawl(ctx); err != nil {
+			s.logger.KnowledgeErrorContext(ctx, "Website recrawl job failed", logkey.Error, err)
+		}
+	}); err != nil {
+		return fmt.Errorf("failed to schedule website recrawl job: %w", err)
+	}
+	s.scheduler.Start()
+	s.logger.DebugContext(ctx, "Website recrawl daemon started")
+
+	<-ctx.Done()
+	s.scheduler.Stop()
+	s.logger.DebugContext(ctx, "Website recrawl daemon stopped")
+	return nil
+}
+
+// RecrawlDueBindings 重新爬取所有到期的实时网站绑定，返回实际重爬的绑定数。
+//
+// 单个绑定失败只记录日志，不影响其他绑定。
+func (s *WebsiteRecrawlService) RecrawlDueBindings(ctx context.Context) (int, error) {
+	flow, err := s.support.requireDocumentFlow()
+	if err != nil {
+		return 0, err
+	}
+	bindings, err := s.bindings.ListRealtimeBindingsByProvider(ctx, sourcebindingdomain.ProviderWebsite)
+	if err != nil {
+		return 0, fmt.Errorf("list realtime website bindings: %w", err)
+	}
+	recrawled := 0
+	for _, binding := range bindings {
+		if err := ctx.Err(); err != nil {
+			return recrawled, fmt.Errorf("recrawl website bindings: %w", err)
+		}
+		done, err := flow.recrawlWebsiteBinding(ctx, binding, s.bindings, s.now())
+		if err != nil {
+			s.logger.KnowledgeWarnContext(
+				ctx,
+				"Recrawl website source binding failed",
+				"knowledge_base_code", binding.KnowledgeBaseCode,
+				"binding_id", binding.ID,
+				logkey.Error, err,
+			)
+			continue
+		}
+		if done {
+			recrawled++
+		}
+	}
+	return recrawled, nil
+}
+
+func (s *WebsiteRecrawlService) runScheduledRecrawl(ctx context.Context) error {
+	result, err := s.jobRunner.Run(ctx, lockpkg.SinglePodJobRequest{
+		LockKey:        constants.KnowledgeWebsiteRecrawlJobLockKey,
+		AcquireTimeout: websiteRecrawlLockAcquireTimeout,
+	}, func(jobCtx context.Context) error {
+		recrawled, err := s.RecrawlDueBindings(jobCtx)
+		if recrawled > 0 {
+			s.logger.InfoContext(jobCtx, "Scheduled website recrawl completed", "recrawled", recrawled)
+		}
+		return err
+	})
+
+	switch result.Status {
+	case lockpkg.SinglePodJobStatusSkippedLocked:
+		return nil
+	case lockpkg.SinglePodJobStatusSkippedRedisUnavailable, lockpkg.SinglePodJobStatusAbortedLockLost:
+		fields := []any{"lock_key", constants.KnowledgeWebsiteRecrawlJobLockKey, "status", result.Status}
+		if err != nil {
+			fields = append(fields, logkey.Error, err)
+		}
+		s.logger.KnowledgeWarnContext(ctx, "Skip scheduled website recrawl because lock is unavailable", fields...)
+		return nil
+	default:
+		if err != nil {
+			return fmt.Errorf("run scheduled website recrawl with distributed lock: %w", err)
+		}
+		return nil
+	}
+}
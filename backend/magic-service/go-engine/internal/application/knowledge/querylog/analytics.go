@@ -0,0 +1,151 @@
+package querylog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
+	querylogrepository "magic/internal/domain/knowledge/querylog/repository"
+	"magic/internal/infrastructure/logging"
+	"magic/pkg/convert"
+)
+
+var (
+	// ErrAnalyticsScopeRequired 表示缺少组织或知识库编码。
+	ErrAnalyticsScopeRequired = errors.New("organization code and knowledge base code are required")
+	// ErrAnalyticsUnavailable 表示统计服务未完成装配。
+	ErrAnalyticsUnavailable = errors.New("knowledge query log analytics unavailable")
+	// ErrInvalidAnalyticsWindow 表示统计时间范围非法。
+	ErrInvalidAnalyticsWindow = querylogentity.ErrInvalidAnalyticsWindow
+	// ErrAnalyticsWindowTooLarge 表示统计时间跨度超过上限。
+	ErrAnalyticsWindowTooLarge = querylogentity.ErrAnalyticsWindowTooLarge
+)
+
+// 以下别名供接口层引用统计结果，避免其直接依赖领域包。
+type (
+	// QueryStat 按 query 聚合的检索统计。
+	QueryStat = querylogentity.QueryStat
+	// SlowQuery 一条慢检索记录。
+	SlowQuery = querylogentity.SlowQuery
+	// DocumentUsage 文档命中统计。
+	DocumentUsage = querylogentity.DocumentUsage
+	// UnusedDocument 窗口内未被命中的文档。
+	UnusedDocument = querylogentity.UnusedDocument
+)
+
+// AnalyticsInput 表示一个知识库的统计请求；时间范围缺省为最近 7 天。
+type AnalyticsInput struct {
+	OrganizationCode  string
+	KnowledgeBaseCode string
+	Since             time.Time
+	Until             time.Time
+	Limit             int
+}
+
+// AnalyticsService 提供基于检索日志的知识库用量统计。
+type AnalyticsService struct {
+	repo   querylogrepository.Analytics
+	logger *logging.SugaredLogger
+	now    func() time.Time
+}
+
+// NewAnalyticsService 创建检索日志统计应用服务。
+func NewAnalyticsService(repo querylogrepository.Analytics, logger *logging.SugaredLogger) *AnalyticsService {
+	return &AnalyticsService{repo: repo, logger: logger, now: time.Now}
+}
+
+// TopQueries 返回检索次数最多的 query。
+func (s *AnalyticsService) TopQueries(ctx context.Context, input AnalyticsInput) ([]QueryStat, error) {
+	query, err := s.resolveQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.TopQueries(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query top queries: %w", err)
+	}
+	return stats, nil
+}
+
+// ZeroResultQueries 返回没有任何命中的 query，用于发现知识缺口。
+func (s *AnalyticsService) ZeroResultQueries(ctx context.Context, input AnalyticsInput) ([]QueryStat, error) {
+	query, err := s.resolveQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.ZeroResultQueries(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query zero result queries: %w", err)
+	}
+	return stats, nil
+}
+
+// SlowestQueries 返回耗时最长的单次检索。
+func (s *AnalyticsService) SlowestQueries(ctx context.Context, input AnalyticsInput) ([]SlowQuery, error) {
+	query, err := s.resolveQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	queries, err := s.repo.SlowestQueries(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query slowest queries: %w", err)
+	}
+	return queries, nil
+}
+
+// MostRetrievedDocuments 返回被命中次数最多的文档。
+func (s *AnalyticsService) MostRetrievedDocuments(ctx context.Context, input AnalyticsInput) ([]DocumentUsage, error) {
+	query, err := s.resolveQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	documents, err := s.repo.MostRetrievedDocuments(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query most retrieved documents: %w", err)
+	}
+	return documents, nil
+}
+
+// NeverRetrievedDocuments 返回窗口内从未被命中的启用文档。
+//
+// 统计依赖采样日志，采样比例小于 1 时结果只能作为参考。
+func (s *AnalyticsService) NeverRetrievedDocuments(ctx context.Context, input AnalyticsInput) ([]UnusedDocument, error) {
+	query, err := s.resolveQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	documents, err := s.repo.NeverRetrievedDocuments(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query never retrieved documents: %w", err)
+	}
+	return documents, nil
+}
+
+func (s *AnalyticsService) resolveQuery(input AnalyticsInput) (querylogrepository.AnalyticsQuery, error) {
+	if s == nil || s.repo == nil {
+		return querylogrepository.AnalyticsQuery{}, ErrAnalyticsUnavailable
+	}
+	organizationCode := strings.TrimSpace(input.OrganizationCode)
+	knowledgeBaseCode := strings.TrimSpace(input.KnowledgeBaseCode)
+	if organizationCode == "" || knowledgeBaseCode == "" {
+		return querylogrepository.AnalyticsQuery{}, ErrAnalyticsScopeRequired
+	}
+	since, until, err := querylogentity.ResolveAnalyticsWindow(input.Since, input.Until, s.now())
+	if err != nil {
+		return querylogrepository.AnalyticsQuery{}, fmt.Errorf("resolve analytics window: %w", err)
+	}
+	limit, err := convert.SafeIntToInt32(querylogentity.NormalizeAnalyticsLimit(input.Limit), "limit")
+	if err != nil {
+		return querylogrepository.AnalyticsQuery{}, fmt.Errorf("convert analytics limit: %w", err)
+	}
+	return querylogrepository.AnalyticsQuery{
+		OrganizationCode:  organizationCode,
+		KnowledgeBaseCode: knowledgeBaseCode,
+		Since:             since,
+		Until:             until,
+		Limit:             limit,
+	}, nil
+}
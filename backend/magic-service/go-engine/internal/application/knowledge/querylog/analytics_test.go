@@ -0,0 +1,104 @@
+package querylog_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	querylogapp "magic/internal/application/knowledge/querylog"
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
+	querylogrepository "magic/internal/domain/knowledge/querylog/repository"
+	"magic/internal/infrastructure/logging"
+)
+
+type analyticsRepoStub struct {
+	last querylogrepository.AnalyticsQuery
+}
+
+func (r *analyticsRepoStub) TopQueries(
+	_ context.Context,
+	query querylogrepository.AnalyticsQuery,
+) ([]querylogentity.QueryStat, error) {
+	r.last = query
+	return []querylogentity.QueryStat{{QueryHash: "h1", SearchCount: 3}}, nil
+}
+
+func (r *analyticsRepoStub) ZeroResultQueries(
+	_ context.Context,
+	query querylogrepository.AnalyticsQuery,
+) ([]querylogentity.QueryStat, error) {
+	r.last = query
+	return []querylogentity.QueryStat{}, nil
+}
+
+func (r *analyticsRepoStub) SlowestQueries(
+	_ context.Context,
+	query querylogrepository.AnalyticsQuery,
+) ([]querylogentity.SlowQuery, error) {
+	r.last = query
+	return []querylogentity.SlowQuery{}, nil
+}
+
+func (r *analyticsRepoStub) MostRetrievedDocuments(
+	_ context.Context,
+	query querylogrepository.AnalyticsQuery,
+) ([]querylogentity.DocumentUsage, error) {
+	r.last = query
+	return []querylogentity.DocumentUsage{}, nil
+}
+
+func (r *analyticsRepoStub) NeverRetrievedDocuments(
+	_ context.Context,
+	query querylogrepository.AnalyticsQuery,
+) ([]querylogentity.UnusedDocument, error) {
+	r.last = query
+	return []querylogentity.UnusedDocument{}, nil
+}
+
+func TestAnalyticsServiceAppliesDefaultWindowAndLimit(t *testing.T) {
+	t.Parallel()
+
+	repo := &analyticsRepoStub{}
+	service := querylogapp.NewAnalyticsService(repo, logging.New())
+	stats, err := service.TopQueries(context.Background(), querylogapp.AnalyticsInput{
+		OrganizationCode:  " ORG1 ",
+		KnowledgeBaseCode: "KB1",
+		Limit:             1000,
+	})
+	if err != nil {
+		t.Fatalf("TopQueries() error = %v", err)
+	}
+	if len(stats) != 1 || stats[0].SearchCount != 3 {
+		t.Fatalf("unexpected stats: %#v", stats)
+	}
+	if repo.last.OrganizationCode != "ORG1" || repo.last.Limit != querylogentity.MaxAnalyticsLimit {
+		t.Fatalf("unexpected query: %#v", repo.last)
+	}
+	if window := repo.last.Until.Sub(repo.last.Since); window != querylogentity.DefaultAnalyticsWindow {
+		t.Fatalf("unexpected window: %s", window)
+	}
+}
+
+func TestAnalyticsServiceValidatesInput(t *testing.T) {
+	t.Parallel()
+
+	service := querylogapp.NewAnalyticsService(&analyticsRepoStub{}, logging.New())
+	if _, err := service.SlowestQueries(context.Background(), querylogapp.AnalyticsInput{OrganizationCode: "ORG1"}); !errors.Is(err, querylogapp.ErrAnalyticsScopeRequired) {
+		t.Fatalf("expected scope error, got %v", err)
+	}
+	now := time.Now()
+	_, err := service.NeverRetrievedDocuments(context.Background(), querylogapp.AnalyticsInput{
+		OrganizationCode:  "ORG1",
+		KnowledgeBaseCode: "KB1",
+		Since:             now,
+		Until:             now.Add(-time.Hour),
+	})
+	if !errors.Is(err, querylogapp.ErrInvalidAnalyticsWindow) {
+		t.Fatalf("expected invalid window error, got %v", err)
+	}
+	var nilService *querylogapp.AnalyticsService
+	if _, err := nilService.ZeroResultQueries(context.Background(), querylogapp.AnalyticsInput{}); !errors.Is(err, querylogapp.ErrAnalyticsUnavailable) {
+		t.Fatalf("expected unavailable error, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+// Package querylog 编排知识库检索 query 日志：采样、脱敏、异步落库与用量统计查询。
+package querylog
+
+import (
+	"context"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"magic/internal/domain/knowledge/fragment/retrieval"
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
+	querylogrepository "magic/internal/domain/knowledge/querylog/repository"
+	querylogservice "magic/internal/domain/knowledge/querylog/service"
+	"magic/internal/infrastructure/logging"
+	"magic/internal/pkg/ctxmeta"
+)
+
+const (
+	defaultMaxInFlight  = 16
+	defaultWriteTimeout = 3 * time.Second
+)
+
+// RecorderConfig 控制检索日志的采样与写入。
+type RecorderConfig struct {
+	Enabled bool
+	// SampleRate 为采样比例，取值 (0, 1]，超出范围按 1 处理。
+	SampleRate float64
+	// MaxInFlight 为同时进行的异步写入上限，达到上限时直接丢弃新日志。
+	MaxInFlight  int
+	WriteTimeout time.Duration
+}
+
+// Recorder 异步记录检索日志，写入失败或过载时只丢日志、不影响检索本身。
+type Recorder struct {
+	store        querylogrepository.Store
+	logger       *logging.SugaredLogger
+	sampleRate   float64
+	writeTimeout time.Duration
+	slots        chan struct{}
+	wg           sync.WaitGroup
+	sequence     atomic.Uint64
+	sample       func() float64
+	now          func() time.Time
+}
+
+// NewRecorder 创建检索日志记录器；未启用或缺少存储时返回 nil，nil 记录器的 Record 为空操作。
+func NewRecorder(store querylogrepository.Store, cfg RecorderConfig, logger *logging.SugaredLogger) *Recorder {
+	if !cfg.Enabled || store == nil {
+		return nil
+	}
+	sampleRate := cfg.SampleRate
+	if sampleRate <= 0 || sampleRate > 1 {
+		sampleRate = 1
+	}
+	maxInFlight := cfg.MaxInFlight
+	if maxInFlight <= 0 {
+		maxInFlight = defaultMaxInFlight
+	}
+	writeTimeout := cfg.WriteTimeout
+	if writeTimeout <= 0 {
+		writeTimeout = defaultWriteTimeout
+	}
+	return &Recorder{
+		store:        store,
+		logger:       logger,
+		sampleRate:   sampleRate,
+		writeTimeout: writeTimeout,
+		slots:        make(chan struct{}, maxInFlight),
+		sample:       rand.Float64,
+		now:          time.Now,
+	}
+}
+
+// Record 采样并异步写入一次检索日志。
+//
+// query 类型基于原始 query 计算，落库前再脱敏；写入使用脱离请求取消的独立超时上下文。
+func (r *Recorder) Record(ctx context.Context, search querylogentity.Search) {
+	if r == nil || search.OrganizationCode == "" {
+		return
+	}
+	if r.sampleRate < 1 && r.sample() >= r.sampleRate {
+		return
+	}
+	if search.StartedAt.IsZero() {
+		search.StartedAt = r.now()
+	}
+	logs := querylogservice.BuildQueryLogs(search, r.requestID(ctx), retrieval.ClassifyQueryType(search.Query))
+	if len(logs) == 0 {
+		return
+	}
+
+	select {
+	case r.slots <- struct{}{}:
+	default:
+		if r.logger != nil {
+			r.logger.KnowledgeWarnContext(ctx, "Query log dropped because writer is saturated",
+				"organization_code", search.OrganizationCode, "source", search.Source)
+		}
+		return
+	}
+
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.writeTimeout)
+	r.wg.Go(func() {
+		defer func() {
+			cancel()
+			<-r.slots
+		}()
+		if err := r.store.InsertQueryLogs(writeCtx, logs); err != nil && r.logger != nil {
+			r.logger.KnowledgeWarnContext(writeCtx, "Write query log failed",
+				"organization_code", search.OrganizationCode, "source", search.Source, "error", err)
+		}
+	})
+}
+
+// Wait 等待已提交的异步写入完成，供测试与调用方等待落库。
+func (r *Recorder) Wait() {
+	if r == nil {
+		return
+	}
+	r.wg.Wait()
+}
+
+func (r *Recorder) requestID(ctx context.Context) string {
+	if requestID, ok := ctxmeta.RequestIDFromContext(ctx); ok && requestID != "" {
+		return requestID
+	}
+	return "querylog-" + strconv.FormatInt(r.now().UnixNano(), 10) + "-" + strconv.FormatUint(r.sequence.Add(1), 10)
+}
@@ -0,0 +1,124 @@
+package querylog_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	querylogapp "magic/internal/application/knowledge/querylog"
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
+	"magic/internal/infrastructure/logging"
+	"magic/internal/pkg/ctxmeta"
+)
+
+type recordingStore struct {
+	mu      sync.Mutex
+	batches [][]querylogentity.QueryLog
+	block   chan struct{}
+	err     error
+}
+
+func (s *recordingStore) InsertQueryLogs(ctx context.Context, logs []querylogentity.QueryLog) error {
+	if s.block != nil {
+		select {
+		case <-s.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, logs)
+	return s.err
+}
+
+func (s *recordingStore) recorded() [][]querylogentity.QueryLog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.batches
+}
+
+func searchFixture() querylogentity.Search {
+	return querylogentity.Search{
+		OrganizationCode:   "ORG1",
+		UserID:             "u1",
+		Source:             querylogentity.SourceSimilarity,
+		Query:              "how to contact alice@example.com",
+		KnowledgeBaseCodes: []string{"KB1"},
+		Hits:               []querylogentity.Hit{{KnowledgeBaseCode: "KB1", DocumentCode: "DOC1", FragmentID: 1, Score: 0.8}},
+		Latency:            20 * time.Millisecond,
+	}
+}
+
+func TestNewRecorderDisabledIsNoop(t *testing.T) {
+	t.Parallel()
+
+	store := &recordingStore{}
+	recorder := querylogapp.NewRecorder(store, querylogapp.RecorderConfig{}, logging.New())
+	if recorder != nil {
+		t.Fatalf("expected nil recorder when disabled, got %#v", recorder)
+	}
+	recorder.Record(context.Background(), searchFixture())
+	recorder.Wait()
+	if len(store.recorded()) != 0 {
+		t.Fatalf("expected no writes, got %#v", store.recorded())
+	}
+}
+
+func TestRecorderWritesScrubbedLogAsynchronously(t *testing.T) {
+	t.Parallel()
+
+	store := &recordingStore{}
+	recorder := querylogapp.NewRecorder(store, querylogapp.RecorderConfig{Enabled: true, SampleRate: 1}, logging.New())
+	ctx, cancel := context.WithCancel(ctxmeta.WithRequestID(context.Background(), "req-9"))
+	recorder.Record(ctx, searchFixture())
+	cancel()
+	recorder.Wait()
+
+	batches := store.recorded()
+	if len(batches) != 1 || len(batches[0]) != 1 {
+		t.Fatalf("expected one log, got %#v", batches)
+	}
+	log := batches[0][0]
+	if log.Query != "how to contact [EMAIL]" || log.RequestID != "req-9" || log.QueryType == "" {
+		t.Fatalf("unexpected log: %#v", log)
+	}
+	if log.ResultCount != 1 || log.LatencyMS != 20 || log.CreatedAt.IsZero() {
+		t.Fatalf("unexpected log metrics: %#v", log)
+	}
+}
+
+func TestRecorderDropsLogsWhenSaturated(t *testing.T) {
+	t.Parallel()
+
+	store := &recordingStore{block: make(chan struct{})}
+	recorder := querylogapp.NewRecorder(store, querylogapp.RecorderConfig{
+		Enabled:      true,
+		MaxInFlight:  1,
+		WriteTimeout: time.Minute,
+	}, logging.New())
+
+	recorder.Record(context.Background(), searchFixture())
+	recorder.Record(context.Background(), searchFixture())
+	close(store.block)
+	recorder.Wait()
+
+	if got := len(store.recorded()); got != 1 {
+		t.Fatalf("expected saturated write to be dropped, got %d writes", got)
+	}
+}
+
+func TestRecorderSwallowsStoreError(t *testing.T) {
+	t.Parallel()
+
+	store := &recordingStore{err: errors.New("db down")}
+	recorder := querylogapp.NewRecorder(store, querylogapp.RecorderConfig{Enabled: true}, logging.New())
+	recorder.Record(context.Background(), searchFixture())
+	recorder.Wait()
+
+	if len(store.recorded()) != 1 {
+		t.Fatalf("expected attempted write, got %#v", store.recorded())
+	}
+}
@@ -339,7 +339,7 @@ func (r *Runner) validateBlueGreenTargetCollection(ctx context.Context, collecti
 	if err != nil {
 		return fmt.Errorf("get bluegreen target collection info: %w", err)
 	}
-	if info == nil || !info.HasNamedDenseVector || !info.HasSparseVector {
+	if info == nil || !info.HasNamedDenseVector || !info.HasSparseVector || info.StorageConfigMismatch {
 		return fmt.Errorf("%w: collection=%s", errBlueGreenTargetSchemaIncomplete, collectionName)
 	}
 	if info.Points <= 0 {
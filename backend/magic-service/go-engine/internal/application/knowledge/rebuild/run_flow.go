@@ -118,6 +118,8 @@ func (r *Runner) prepareRun(ctx context.Context, runID string, opts rebuilddto.R
 		selectedMode = rebuilddto.ModeBlueGreen
 	case !activeState.SchemaOK && selectedMode == rebuilddto.ModeInplace:
 		selectedMode = rebuilddto.ModeBlueGreen
+	case activeState.StorageOutdated && selectedMode == rebuilddto.ModeInplace:
+		selectedMode = rebuilddto.ModeBlueGreen
 	}
 
 	targetDimension, err := r.resolveTargetDimension(ctx, opts, selectedMode)
@@ -136,6 +136,7 @@ type activeCollectionState struct {
 	Bootstrap          bool
 	SchemaOK           bool
 	NeedsNormalization bool
+	StorageOutdated    bool
 }
 
 type resyncState struct {
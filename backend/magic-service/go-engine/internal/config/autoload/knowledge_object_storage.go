@@ -0,0 +1,11 @@
+package autoload
+
+// KnowledgeObjectStorageConfig 保存知识库对象存储来源（S3/MinIO）的连接配置。
+type KnowledgeObjectStorageConfig struct {
+	Endpoint              string `json:"endpoint"`
+	Region                string `json:"region"`
+	Identity              string `mapstructure:"accessKey" json:"identity"`
+	Signature             string `mapstructure:"secretKey" json:"signature"`
+	VirtualHostStyle      bool   `mapstructure:"virtualHostStyle" json:"virtual_host_style"`
+	RequestTimeoutSeconds int    `mapstructure:"requestTimeoutSeconds" json:"request_timeout_seconds"`
+}
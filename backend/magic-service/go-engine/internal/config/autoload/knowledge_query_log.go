@@ -0,0 +1,11 @@
+package autoload
+
+// KnowledgeQueryLogConfig 保存知识库检索 query 日志配置。
+type KnowledgeQueryLogConfig struct {
+	// Enabled 为 false 时不记录检索日志，统计接口返回空结果
+	Enabled bool `mapstructure:"enabled" json:"enabled"`
+	// SampleRate 是采样比例，取值 (0, 1]
+	SampleRate         float64 `mapstructure:"sampleRate" json:"sample_rate"`
+	MaxInFlight        int     `mapstructure:"maxInFlight" json:"max_in_flight"`
+	WriteTimeoutMillis int     `mapstructure:"writeTimeoutMillis" json:"write_timeout_millis"`
+}
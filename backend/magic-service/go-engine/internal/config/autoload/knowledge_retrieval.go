@@ -0,0 +1,38 @@
+package autoload
+
+// KnowledgeRetrievalConfig 保存知识库检索运行配置。
+type KnowledgeRetrievalConfig struct {
+	// RerankBaseURL 是重排服务基础 URL，为空时复用模型网关地址
+	RerankBaseURL       string `mapstructure:"rerankBaseUrl" json:"rerank_base_url"`
+	RerankTimeoutMillis int    `mapstructure:"rerankTimeoutMillis" json:"rerank_timeout_millis"`
+	RerankMaxCandidates int    `mapstructure:"rerankMaxCandidates" json:"rerank_max_candidates"`
+	// RerankScoreThreshold 是重排相关度阈值，按重排模型分数过滤，0 表示不过滤
+	RerankScoreThreshold float64 `mapstructure:"rerankScoreThreshold" json:"rerank_score_threshold"`
+	// QueryExpansionBaseURL 是查询改写对话模型服务地址，为空时复用模型网关地址
+	QueryExpansionBaseURL string `mapstructure:"queryExpansionBaseUrl" json:"query_expansion_base_url"`
+	// QueryExpansionModel 是知识库未指定 model_name 时使用的默认改写模型
+	QueryExpansionModel         string `mapstructure:"queryExpansionModel" json:"query_expansion_model"`
+	QueryExpansionTimeoutMillis int    `mapstructure:"queryExpansionTimeoutMillis" json:"query_expansion_timeout_millis"`
+	QueryExpansionMaxVariants   int    `mapstructure:"queryExpansionMaxVariants" json:"query_expansion_max_variants"`
+	// LateInteractionModel 是 ColBERT 类多向量模型，为空时关闭 late-interaction 多向量模式
+	LateInteractionModel string `mapstructure:"lateInteractionModel" json:"late_interaction_model"`
+	// LateInteractionBaseURL 是多向量编码服务地址，为空时复用模型网关地址
+	LateInteractionBaseURL string `mapstructure:"lateInteractionBaseUrl" json:"late_interaction_base_url"`
+	// LateInteractionDimension 是逐 token 向量维度，变更后需重建集合
+	LateInteractionDimension     int `mapstructure:"lateInteractionDimension" json:"late_interaction_dimension"`
+	LateInteractionTimeoutMillis int `mapstructure:"lateInteractionTimeoutMillis" json:"late_interaction_timeout_millis"`
+	LateInteractionMaxCandidates int `mapstructure:"lateInteractionMaxCandidates" json:"late_interaction_max_candidates"`
+	// FeedbackBoostEnabled 开启后按相关性反馈对检索结果做有界加权
+	FeedbackBoostEnabled bool `mapstructure:"feedbackBoostEnabled" json:"feedback_boost_enabled"`
+	// FeedbackMaxBoost 是单个结果的最大相对加成或惩罚比例
+	FeedbackMaxBoost      float64 `mapstructure:"feedbackMaxBoost" json:"feedback_max_boost"`
+	FeedbackTimeoutMillis int     `mapstructure:"feedbackTimeoutMillis" json:"feedback_timeout_millis"`
+	// ContextualEnrichmentModel 是知识库 contextual_enrichment.model_name 为空时生成片段上下文的默认模型
+	ContextualEnrichmentModel         string `mapstructure:"contextualEnrichmentModel" json:"contextual_enrichment_model"`
+	ContextualEnrichmentTimeoutMillis int    `mapstructure:"contextualEnrichmentTimeoutMillis" json:"contextual_enrichment_timeout_millis"`
+	ContextualEnrichmentConcurrency   int    `mapstructure:"contextualEnrichmentConcurrency" json:"contextual_enrichment_concurrency"`
+	// AuxiliaryFragmentModel 是知识库 fragment_config.auxiliary_fragments.model_name 为空时生成摘要/问题的默认模型
+	AuxiliaryFragmentModel         string `mapstructure:"auxiliaryFragmentModel" json:"auxiliary_fragment_model"`
+	AuxiliaryFragmentTimeoutMillis int    `mapstructure:"auxiliaryFragmentTimeoutMillis" json:"auxiliary_fragment_timeout_millis"`
+	AuxiliaryFragmentConcurrency   int    `mapstructure:"auxiliaryFragmentConcurrency" json:"auxiliary_fragment_concurrency"`
+}
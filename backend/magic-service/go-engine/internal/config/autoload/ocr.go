@@ -2,11 +2,21 @@ package autoload
 
 // OCRConfig 火山引擎 OCR 配置
 type OCRConfig struct {
-	Region                      string  `json:"region"`
-	Endpoint                    string  `json:"endpoint"`
-	MaxOCRPerFile               int     `mapstructure:"maxPerFile" json:"max_ocr_per_file"`
-	RateLimitEnabled            bool    `mapstructure:"rateLimitEnabled" json:"rate_limit_enabled"`
-	RateLimitQPS                float64 `mapstructure:"rateLimitQPS" json:"rate_limit_qps"`
-	RateLimitBurst              int     `mapstructure:"rateLimitBurst" json:"rate_limit_burst"`
-	RateLimitWaitTimeoutSeconds int     `mapstructure:"rateLimitWaitTimeoutSeconds" json:"rate_limit_wait_timeout_seconds"`
+	Region                      string         `json:"region"`
+	Endpoint                    string         `json:"endpoint"`
+	MaxOCRPerFile               int            `mapstructure:"maxPerFile" json:"max_ocr_per_file"`
+	RateLimitEnabled            bool           `mapstructure:"rateLimitEnabled" json:"rate_limit_enabled"`
+	RateLimitQPS                float64        `mapstructure:"rateLimitQPS" json:"rate_limit_qps"`
+	RateLimitBurst              int            `mapstructure:"rateLimitBurst" json:"rate_limit_burst"`
+	RateLimitWaitTimeoutSeconds int            `mapstructure:"rateLimitWaitTimeoutSeconds" json:"rate_limit_wait_timeout_seconds"`
+	Local                       LocalOCRConfig `json:"local"`
+}
+
+// LocalOCRConfig 自部署 OCR HTTP 服务配置，组织在 OCR 配置中选择 Local provider 时生效。
+type LocalOCRConfig struct {
+	Endpoint              string `json:"endpoint"`
+	Protocol              string `json:"protocol"`
+	Languages             string `json:"languages"`
+	RequestTimeoutSeconds int    `mapstructure:"requestTimeoutSeconds" json:"request_timeout_seconds"`
+	MaxSourceMB           int    `mapstructure:"maxSourceMB" json:"max_source_mb"`
 }
@@ -5,6 +5,7 @@ package autoload
 type Config struct {
 	Server                       ServerConfig                       `json:"server"`
 	Qdrant                       QdrantConfig                       `json:"qdrant"`
+	VectorDB                     VectorDBConfig                     `mapstructure:"vectorDb" json:"vector_db"`
 	Rebuild                      RebuildConfig                      `json:"rebuild"`
 	MySQL                        MySQLConfig                        `json:"mysql"`
 	Redis                        RedisConfig                        `json:"redis"`
@@ -21,6 +22,9 @@ type Config struct {
 	DocumentResourceLimits       DocumentResourceLimitsConfig       `mapstructure:"documentResourceLimits" json:"document_resource_limits"`
 	OfficeConversion             OfficeConversionConfig             `mapstructure:"officeConversion" json:"office_conversion"`
 	KnowledgeVisualUnderstanding KnowledgeVisualUnderstandingConfig `mapstructure:"knowledgeVisualUnderstanding" json:"knowledge_visual_understanding"`
+	KnowledgeRetrieval           KnowledgeRetrievalConfig           `mapstructure:"knowledgeRetrieval" json:"knowledge_retrieval"`
+	KnowledgeObjectStorage       KnowledgeObjectStorageConfig       `mapstructure:"knowledgeObjectStorage" json:"knowledge_object_storage"`
+	KnowledgeQueryLog            KnowledgeQueryLogConfig            `mapstructure:"knowledgeQueryLog" json:"knowledge_query_log"`
 }
 
 // LoggingConfig 提供应用日志设置。
@@ -0,0 +1,83 @@
+package autoload
+
+import "strings"
+
+const (
+	// VectorDBBackendQdrant 表示使用 Qdrant 作为向量库（默认）。
+	VectorDBBackendQdrant = "qdrant"
+	// VectorDBBackendPGVector 表示使用 PostgreSQL + pgvector 作为向量库。
+	VectorDBBackendPGVector = "pgvector"
+	// VectorDBBackendEmbedded 表示使用进程内嵌入式向量库，适用于单机、CI 与测试环境。
+	VectorDBBackendEmbedded = "embedded"
+)
+
+// VectorDBConfig 保存向量库后端选择与非 Qdrant 后端的连接设置。
+type VectorDBConfig struct {
+	// Backend 为 qdrant、pgvector 或 embedded，为空时使用 qdrant
+	Backend  string                 `json:"backend"`
+	PGVector PGVectorConfig         `mapstructure:"pgvector" json:"pgvector"`
+	Embedded EmbeddedVectorDBConfig `mapstructure:"embedded" json:"embedded"`
+	// Collection 是新建集合的存储参数与检索参数，蓝绿重建的新集合沿用同一份配置
+	Collection VectorCollectionConfig `mapstructure:"collection" json:"collection"`
+}
+
+// VectorCollectionConfig 保存集合级存储参数（量化、on_disk、HNSW）与对应的检索参数。
+type VectorCollectionConfig struct {
+	// OnDisk 为 true 时原始向量存放在磁盘，仅量化向量常驻内存
+	OnDisk       bool                     `mapstructure:"onDisk" json:"on_disk"`
+	HNSW         VectorHNSWConfig         `mapstructure:"hnsw" json:"hnsw"`
+	Quantization VectorQuantizationConfig `mapstructure:"quantization" json:"quantization"`
+}
+
+// VectorHNSWConfig 保存 HNSW 图参数，0 表示使用后端默认值。
+type VectorHNSWConfig struct {
+	M           int `json:"m"`
+	EfConstruct int `mapstructure:"efConstruct" json:"ef_construct"`
+	// EfSearch 为查询时的搜索宽度
+	EfSearch int `mapstructure:"efSearch" json:"ef_search"`
+}
+
+// VectorQuantizationConfig 保存向量量化参数。
+type VectorQuantizationConfig struct {
+	// Type 为 none、scalar、product 或 binary，为空时不量化
+	Type string `json:"type"`
+	// Quantile 仅对 scalar 生效，0 表示使用后端默认值
+	Quantile float64 `json:"quantile"`
+	// Compression 仅对 product 生效：x4、x8、x16、x32、x64
+	Compression string `json:"compression"`
+	AlwaysRAM   bool   `mapstructure:"alwaysRam" json:"always_ram"`
+	// Rescore 查询时是否用原始向量重打分，为空时开启
+	Rescore *bool `json:"rescore"`
+	// Oversampling 量化候选的过采样倍数，<=1 表示不过采样
+	Oversampling float64 `json:"oversampling"`
+}
+
+// PGVectorConfig 保存 pgvector 后端连接设置。
+type PGVectorConfig struct {
+	// DriverName 是 database/sql 注册的 PostgreSQL 驱动名，默认 pgx 已随引擎链接
+	DriverName   string `mapstructure:"driverName" json:"driver_name"`
+	DSN          string `mapstructure:"dsn" json:"dsn"`
+	Schema       string `mapstructure:"schema" json:"schema"`
+	MaxOpenConns int    `mapstructure:"maxOpenConns" json:"max_open_conns"`
+	MaxIdleConns int    `mapstructure:"maxIdleConns" json:"max_idle_conns"`
+}
+
+// EmbeddedVectorDBConfig 保存嵌入式向量库设置。
+type EmbeddedVectorDBConfig struct {
+	// DataDir 为空时仅保存在内存中
+	DataDir string `mapstructure:"dataDir" json:"data_dir"`
+	// ExactSearchThreshold 集合点数不超过该值时走精确扫描
+	ExactSearchThreshold int `mapstructure:"exactSearchThreshold" json:"exact_search_threshold"`
+}
+
+// EffectiveBackend 返回归一化后的向量库后端名，未知取值回退到 qdrant。
+func (c VectorDBConfig) EffectiveBackend() string {
+	switch strings.ToLower(strings.TrimSpace(c.Backend)) {
+	case VectorDBBackendPGVector:
+		return VectorDBBackendPGVector
+	case VectorDBBackendEmbedded:
+		return VectorDBBackendEmbedded
+	default:
+		return VectorDBBackendQdrant
+	}
+}
@@ -0,0 +1,25 @@
+package autoload_test
+
+import (
+	"testing"
+
+	autoloadcfg "magic/internal/config/autoload"
+)
+
+func TestVectorDBConfigEffectiveBackend(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"":            autoloadcfg.VectorDBBackendQdrant,
+		"qdrant":      autoloadcfg.VectorDBBackendQdrant,
+		" PGVector ":  autoloadcfg.VectorDBBackendPGVector,
+		"pgvector":    autoloadcfg.VectorDBBackendPGVector,
+		"Embedded":    autoloadcfg.VectorDBBackendEmbedded,
+		"unsupported": autoloadcfg.VectorDBBackendQdrant,
+	}
+	for backend, want := range tests {
+		if got := (autoloadcfg.VectorDBConfig{Backend: backend}).EffectiveBackend(); got != want {
+			t.Fatalf("backend %q: want %q, got %q", backend, want, got)
+		}
+	}
+}
@@ -10,4 +10,12 @@ const (
 	MagicAccessTokenInitLockTimeout = 30 * time.Second
 	// EmbeddingCacheCleanupJobLockKey 表示 embedding cache 定时清理任务的全局锁键。
 	EmbeddingCacheCleanupJobLockKey = "job:embedding_cache_cleanup"
+	// KnowledgeWebsiteRecrawlJobLockKey 表示知识库网站来源定时重爬任务的全局锁键。
+	KnowledgeWebsiteRecrawlJobLockKey = "job:knowledge_website_recrawl"
+	// KnowledgeGitSourceSyncJobLockKey 表示知识库 Git 来源定时增量同步任务的全局锁键。
+	KnowledgeGitSourceSyncJobLockKey = "job:knowledge_git_source_sync"
+	// KnowledgeObjectStorageSyncJobLockKey 表示知识库对象存储来源定时轮询同步任务的全局锁键。
+	KnowledgeObjectStorageSyncJobLockKey = "job:knowledge_object_storage_sync"
+	// KnowledgeWebhookSourceLockKeyPrefix 表示知识库 webhook 来源按绑定串行处理推送的锁键前缀。
+	KnowledgeWebhookSourceLockKeyPrefix = "knowledge_webhook_source:"
 )
@@ -77,6 +77,8 @@ const (
 	MethodFragmentRuntimeSimilarity = "svc.knowledge.fragment.runtimeSimilarity"
 	// MethodFragmentSimilarityByAgent 数字员工维度片段相似度搜索
 	MethodFragmentSimilarityByAgent = "svc.knowledge.fragment.similarityByAgent"
+	// MethodFragmentAssembleContextByAgent 数字员工维度按 token 预算组装检索上下文
+	MethodFragmentAssembleContextByAgent = "svc.knowledge.fragment.assembleContextByAgent"
 	// MethodFragmentFlowVectorSimilarityByUser 用户维度 flow 知识库相似度搜索
 	MethodFragmentFlowVectorSimilarityByUser = "svc.knowledge.fragment.flowVectorSimilarityByUser"
 	// MethodFragmentRuntimeCreate flow/teamshare runtime 片段写入
@@ -90,6 +92,31 @@ const (
 	// MethodFragmentPreviewHTTP 片段预览并返回最终 HTTP body
 	MethodFragmentPreviewHTTP = "svc.knowledge.fragment.preview_http"
 
+	// MethodEvaluationSaveQuerySet 全量保存知识库检索评测集
+	MethodEvaluationSaveQuerySet = "svc.knowledge.evaluation.saveQuerySet"
+	// MethodEvaluationListQuerySet 查询知识库检索评测集
+	MethodEvaluationListQuerySet = "svc.knowledge.evaluation.listQuerySet"
+	// MethodEvaluationRun 回放评测集并生成检索指标报告
+	MethodEvaluationRun = "svc.knowledge.evaluation.run"
+	// MethodEvaluationListRuns 查询评测 run 列表
+	MethodEvaluationListRuns = "svc.knowledge.evaluation.listRuns"
+	// MethodEvaluationCompareRuns 对比两次评测 run
+	MethodEvaluationCompareRuns = "svc.knowledge.evaluation.compareRuns"
+
+	// MethodQueryLogTopQueries 查询知识库高频检索 query
+	MethodQueryLogTopQueries = "svc.knowledge.queryLog.topQueries"
+	// MethodQueryLogZeroResultQueries 查询知识库零结果检索 query
+	MethodQueryLogZeroResultQueries = "svc.knowledge.queryLog.zeroResultQueries"
+	// MethodQueryLogSlowestQueries 查询知识库最慢的检索
+	MethodQueryLogSlowestQueries = "svc.knowledge.queryLog.slowestQueries"
+	// MethodQueryLogMostRetrievedDocuments 查询知识库被命中最多的文档
+	MethodQueryLogMostRetrievedDocuments = "svc.knowledge.queryLog.mostRetrievedDocuments"
+	// MethodQueryLogNeverRetrievedDocuments 查询知识库从未被命中的文档
+	MethodQueryLogNeverRetrievedDocuments = "svc.knowledge.queryLog.neverRetrievedDocuments"
+
+	// MethodFeedbackSubmit 提交检索结果相关性反馈
+	MethodFeedbackSubmit = "svc.knowledge.feedback.submit"
+
 	// MethodEmbeddingCompute 计算 Embedding
 	MethodEmbeddingCompute = "svc.knowledge.embedding.compute"
 	// MethodEmbeddingComputeBatch 批量计算 Embedding
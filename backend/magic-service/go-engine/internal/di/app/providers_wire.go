@@ -19,6 +19,7 @@ var ProviderSet = wire.NewSet(
 	diknowledge.ProvideKnowledgeBaseDomainService,
 	diknowledge.ProvideFragmentRetrievalSegmenterProvider,
 	diknowledge.ProvideFragmentDomainConfig,
+	diknowledge.ProvideFragmentRetrievalDeps,
 	diknowledge.ProvideFragmentDomainInfra,
 	diknowledge.ProvideFragmentDomainService,
 	diknowledge.ProvideDocumentDomainService,
@@ -31,9 +32,18 @@ var ProviderSet = wire.NewSet(
 	diknowledge.ProvideKnowledgeBaseAppDeps,
 	diknowledge.ProvideKnowledgeBaseDocumentFlowDeps,
 	diknowledge.ProvideKnowledgeBaseAppService,
+	diknowledge.ProvideWebsiteRecrawlService,
+	diknowledge.ProvideGitSourceSyncService,
+	diknowledge.ProvideObjectStorageSyncService,
+	diknowledge.ProvideWebhookSourceService,
 	diknowledge.ProvideFragmentAppRuntimeDeps,
 	diknowledge.ProvideFragmentAppDeps,
 	diknowledge.ProvideFragmentAppService,
+	diknowledge.ProvideEvaluationAppService,
+	diknowledge.ProvideQueryLogRecorder,
+	diknowledge.ProvideQueryLogAnalyticsService,
+	diknowledge.ProvideFeedbackAggregateReader,
+	diknowledge.ProvideFeedbackAppService,
 	diknowledge.ProvideEmbeddingAppService,
 	diknowledge.ProvideDocumentSyncRuntime,
 	diknowledge.ProvideDocumentAppDeps,
@@ -3,12 +3,16 @@ package infra
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
+	// 注册 pgvector 后端使用的 database/sql 驱动 "pgx"。
+	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -19,12 +23,20 @@ import (
 	docrepo "magic/internal/domain/knowledge/document/repository"
 	documentdomain "magic/internal/domain/knowledge/document/service"
 	embeddingdomain "magic/internal/domain/knowledge/embedding"
+	evalrepository "magic/internal/domain/knowledge/evaluation/repository"
+	feedbackrepository "magic/internal/domain/knowledge/feedback/repository"
 	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	querylogrepository "magic/internal/domain/knowledge/querylog/repository"
+	shared "magic/internal/domain/knowledge/shared"
 	taskfiledomain "magic/internal/domain/taskfile/service"
 	"magic/internal/infrastructure/external"
+	"magic/internal/infrastructure/external/gitrepo"
+	"magic/internal/infrastructure/external/objectstorage"
 	"magic/internal/infrastructure/external/ocr"
 	"magic/internal/infrastructure/external/vision"
+	"magic/internal/infrastructure/external/webhookcallback"
 	"magic/internal/infrastructure/health"
+	chunkcontextcache "magic/internal/infrastructure/knowledge/chunkcontextcache"
 	"magic/internal/infrastructure/knowledge/documentsync"
 	sourcecallbackcache "magic/internal/infrastructure/knowledge/sourcecallbackcache"
 	"magic/internal/infrastructure/logging"
@@ -36,9 +48,12 @@ import (
 	mysqldocumentrepo "magic/internal/infrastructure/persistence/mysql/knowledge/document"
 	mysqlocrcache "magic/internal/infrastructure/persistence/mysql/knowledge/document/ocrcache"
 	mysqlembeddingcache "magic/internal/infrastructure/persistence/mysql/knowledge/embeddingcache"
+	mysqlevalrepo "magic/internal/infrastructure/persistence/mysql/knowledge/evaluation"
+	mysqlfeedbackrepo "magic/internal/infrastructure/persistence/mysql/knowledge/feedback"
 	mysqlfragmentrepo "magic/internal/infrastructure/persistence/mysql/knowledge/fragment"
 	mysqlknowledgebase "magic/internal/infrastructure/persistence/mysql/knowledge/knowledgebase"
 	mysqlknowledgebasebinding "magic/internal/infrastructure/persistence/mysql/knowledge/knowledgebasebinding"
+	mysqlquerylogrepo "magic/internal/infrastructure/persistence/mysql/knowledge/querylog"
 	mysqlsourcebindingrepo "magic/internal/infrastructure/persistence/mysql/knowledge/sourcebinding"
 	mysqlsupermagicagentrepo "magic/internal/infrastructure/persistence/mysql/knowledge/supermagicagent"
 	mysqltransaction "magic/internal/infrastructure/persistence/mysql/knowledge/transaction"
@@ -49,18 +64,39 @@ import (
 	redisrebuild "magic/internal/infrastructure/persistence/redis/rebuild"
 	ipcclient "magic/internal/infrastructure/rpc/jsonrpc/client"
 	"magic/internal/infrastructure/transport/ipc/unixsocket"
+	"magic/internal/infrastructure/vectordb/embedded"
+	"magic/internal/infrastructure/vectordb/pgvector"
 	"magic/internal/infrastructure/vectordb/qdrant"
 	"magic/internal/pkg/lock"
 	"magic/internal/pkg/logkey"
 	"magic/internal/pkg/ratelimit"
 	"magic/internal/pkg/tokenizer"
+	"magic/pkg/convert"
 )
 
-// FragmentVectorDBDataRepository 表示片段向量数据仓储的具体 DI 输出类型。
+// FragmentVectorDBDataRepository 表示片段向量数据仓储的具体 DI 输出类型，按配置委托给 Qdrant、pgvector 或嵌入式向量库。
 type FragmentVectorDBDataRepository struct {
-	*qdrant.VectorDBDataRepository[fragmodel.FragmentPayload]
+	fragmentVectorDataStore
 }
 
+// fragmentVectorDataStore 是各向量库后端片段数据仓储的共同能力。
+type fragmentVectorDataStore interface {
+	fragmodel.VectorDBDataRepository[fragmodel.FragmentPayload]
+	shared.SparseBackendSelector
+	ListPointIDsByFilter(ctx context.Context, collection string, filter *fragmodel.VectorFilter, limit int) ([]string, error)
+}
+
+// VectorDBClients 汇总各向量库后端客户端，按配置只有一个非 nil。
+type VectorDBClients struct {
+	Qdrant   *qdrant.Client
+	PGVector *pgvector.Client
+	Embedded *embedded.Client
+}
+
+var errPGVectorDriverNotRegistered = errors.New("pgvector sql driver is not registered")
+
+var errLateInteractionDimensionRequired = errors.New("knowledgeRetrieval.lateInteractionDimension is required when lateInteractionModel is set")
+
 const (
 	defaultEmbeddingRateLimitQPS                = 30
 	defaultEmbeddingRateLimitBurst              = 30
@@ -154,6 +190,47 @@ func ProvideAccessTokenProvider(
 	return external.NewConfigFirstAccessTokenProvider(cfg.MagicModelGateway.MagicAccessToken, ipcProvider)
 }
 
+// ProvideRerankClient 提供交叉编码重排客户端，未单独配置地址时复用模型网关。
+func ProvideRerankClient(
+	cfg *autoloadcfg.Config,
+	accessTokenProvider external.AccessTokenProvider,
+) *external.RerankClient {
+	baseURL := strings.TrimSpace(cfg.KnowledgeRetrieval.RerankBaseURL)
+	if baseURL == "" {
+		baseURL = cfg.MagicModelGateway.BaseURL
+	}
+	return external.NewRerankClient(baseURL, accessTokenProvider)
+}
+
+// ProvideMultiVectorClient 提供 late-interaction 多向量编码客户端，未单独配置地址时复用模型网关。
+func ProvideMultiVectorClient(
+	cfg *autoloadcfg.Config,
+	accessTokenProvider external.AccessTokenProvider,
+) *external.MultiVectorClient {
+	baseURL := strings.TrimSpace(cfg.KnowledgeRetrieval.LateInteractionBaseURL)
+	if baseURL == "" {
+		baseURL = cfg.MagicModelGateway.BaseURL
+	}
+	return external.NewMultiVectorClient(baseURL, accessTokenProvider)
+}
+
+// ProvideChatCompletionClient 提供查询改写使用的对话补全客户端，未单独配置地址时复用模型网关。
+func ProvideChatCompletionClient(
+	cfg *autoloadcfg.Config,
+	accessTokenProvider external.AccessTokenProvider,
+) *external.ChatCompletionClient {
+	baseURL := strings.TrimSpace(cfg.KnowledgeRetrieval.QueryExpansionBaseURL)
+	if baseURL == "" {
+		baseURL = cfg.MagicModelGateway.BaseURL
+	}
+	return external.NewChatCompletionClient(baseURL, accessTokenProvider)
+}
+
+// ProvideChunkContextCache 提供入库上下文增强生成结果的 Redis 缓存。
+func ProvideChunkContextCache(redisClient *redis.Client) *chunkcontextcache.RedisCache {
+	return chunkcontextcache.NewRedisCache(redisClient)
+}
+
 // ProvideThirdPlatformDocumentPort 提供第三方文档解析端口实现（Go -> PHP IPC）。
 func ProvideThirdPlatformDocumentPort(
 	server *unixsocket.Server,
@@ -354,6 +431,30 @@ func ProvideOCRResultCacheRepository(
 	return mysqlocrcache.NewRepository(client, logger.Named("mysql.OCRResultCacheRepository"))
 }
 
+// ProvideEvaluationRepository 提供知识库检索评测仓储实现。
+func ProvideEvaluationRepository(
+	client *mysql.SQLCClient,
+	logger *logging.SugaredLogger,
+) evalrepository.Repository {
+	return mysqlevalrepo.NewRepository(client, logger.Named("mysql.EvaluationRepository"))
+}
+
+// ProvideQueryLogRepository 提供知识库检索 query 日志仓储实现。
+func ProvideQueryLogRepository(
+	client *mysql.SQLCClient,
+	logger *logging.SugaredLogger,
+) querylogrepository.Repository {
+	return mysqlquerylogrepo.NewRepository(client, logger.Named("mysql.QueryLogRepository"))
+}
+
+// ProvideFeedbackRepository 提供知识库检索相关性反馈仓储实现。
+func ProvideFeedbackRepository(
+	client *mysql.SQLCClient,
+	logger *logging.SugaredLogger,
+) feedbackrepository.Repository {
+	return mysqlfeedbackrepo.NewRepository(client, logger.Named("mysql.FeedbackRepository"))
+}
+
 // ProvideSourceBindingRepository 提供知识库来源绑定仓储实现。
 func ProvideSourceBindingRepository(
 	client *mysql.SQLCClient,
@@ -491,10 +592,21 @@ func ProvideRPCServerOverIPC(cfg *autoloadcfg.Config, logger *logging.SugaredLog
 	return unixsocket.NewServer(&cfg.IPC, logger.Named("unixsocket.Server"))
 }
 
-// ProvideQdrantClient 提供 Qdrant 客户端
+// ProvideQdrantClient 提供 Qdrant 客户端，向量库后端不是 Qdrant 时返回 nil。
 func ProvideQdrantClient(cfg *autoloadcfg.Config, logger *logging.SugaredLogger) (*qdrant.Client, func(), error) {
+	if cfg.VectorDB.EffectiveBackend() != autoloadcfg.VectorDBBackendQdrant {
+		return nil, func() {}, nil
+	}
 	qdrantLogger := logger.Named("qdrant.Client")
 	host, port, apiKey := resolveQdrantEndpoint(cfg.Qdrant)
+	collectionOptions, err := qdrantCollectionOptions(cfg.VectorDB.Collection)
+	if err != nil {
+		return nil, nil, err
+	}
+	collectionOptions.MultiVectorSize, err = qdrantMultiVectorSize(cfg.KnowledgeRetrieval)
+	if err != nil {
+		return nil, nil, err
+	}
 	clientCfg := &qdrant.Config{
 		Host:                host,
 		Port:                port,
@@ -503,6 +615,7 @@ func ProvideQdrantClient(cfg *autoloadcfg.Config, logger *logging.SugaredLogger)
 		MaxConcurrentWrites: cfg.Qdrant.MaxConcurrentWrites,
 		LogTimingEnabled:    cfg.Qdrant.LogTimingEnabled,
 		LogSlowThresholdMs:  cfg.Qdrant.LogSlowThresholdMs,
+		Collection:          collectionOptions,
 	}
 	client, err := qdrant.NewClient(clientCfg, qdrantLogger)
 	if err != nil {
@@ -516,6 +629,51 @@ func ProvideQdrantClient(cfg *autoloadcfg.Config, logger *logging.SugaredLogger)
 	return client, cleanup, nil
 }
 
+// qdrantCollectionOptions 把集合存储配置转换为 Qdrant 客户端参数。
+func qdrantCollectionOptions(cfg autoloadcfg.VectorCollectionConfig) (qdrant.CollectionOptions, error) {
+	m, err := convert.SafeIntToUint64(cfg.HNSW.M, "vectorDb.collection.hnsw.m")
+	if err != nil {
+		return qdrant.CollectionOptions{}, fmt.Errorf("invalid vector collection config: %w", err)
+	}
+	efConstruct, err := convert.SafeIntToUint64(cfg.HNSW.EfConstruct, "vectorDb.collection.hnsw.efConstruct")
+	if err != nil {
+		return qdrant.CollectionOptions{}, fmt.Errorf("invalid vector collection config: %w", err)
+	}
+	efSearch, err := convert.SafeIntToUint64(cfg.HNSW.EfSearch, "vectorDb.collection.hnsw.efSearch")
+	if err != nil {
+		return qdrant.CollectionOptions{}, fmt.Errorf("invalid vector collection config: %w", err)
+	}
+	return qdrant.CollectionOptions{
+		OnDisk:          cfg.OnDisk,
+		HNSWM:           m,
+		HNSWEfConstruct: efConstruct,
+		HNSWEfSearch:    efSearch,
+		Quantization: qdrant.QuantizationOptions{
+			Type:         cfg.Quantization.Type,
+			Quantile:     float32(cfg.Quantization.Quantile),
+			Compression:  cfg.Quantization.Compression,
+			AlwaysRAM:    cfg.Quantization.AlwaysRAM,
+			Rescore:      cfg.Quantization.Rescore,
+			Oversampling: cfg.Quantization.Oversampling,
+		},
+	}, nil
+}
+
+// qdrantMultiVectorSize 仅在配置了 late-interaction 模型时为集合声明多向量字段。
+func qdrantMultiVectorSize(cfg autoloadcfg.KnowledgeRetrievalConfig) (uint64, error) {
+	if strings.TrimSpace(cfg.LateInteractionModel) == "" {
+		return 0, nil
+	}
+	size, err := convert.SafeIntToUint64(cfg.LateInteractionDimension, "knowledgeRetrieval.lateInteractionDimension")
+	if err != nil {
+		return 0, fmt.Errorf("invalid late interaction config: %w", err)
+	}
+	if size == 0 {
+		return 0, errLateInteractionDimensionRequired
+	}
+	return size, nil
+}
+
 func resolveQdrantEndpoint(cfg autoloadcfg.QdrantConfig) (string, int, string) {
 	host := cfg.EffectiveHost()
 
@@ -527,15 +685,102 @@ func resolveQdrantEndpoint(cfg autoloadcfg.QdrantConfig) (string, int, string) {
 	return host, cfg.Port, apiKey
 }
 
-// ProvideVectorDBManagementRepository 提供向量数据库管理仓储
-func ProvideVectorDBManagementRepository(client *qdrant.Client) fragmodel.VectorDBManagementRepository {
-	return qdrant.NewVectorDBManagementRepository(client)
+// ProvidePGVectorClient 提供 pgvector 客户端，仅在向量库后端为 pgvector 时创建。
+//
+// 连接使用 database/sql，本包已链接 pgx 驱动（driverName=pgx）；配置其它驱动名时需同样注册。
+func ProvidePGVectorClient(cfg *autoloadcfg.Config, logger *logging.SugaredLogger) (*pgvector.Client, func(), error) {
+	if cfg.VectorDB.EffectiveBackend() != autoloadcfg.VectorDBBackendPGVector {
+		return nil, func() {}, nil
+	}
+	pgCfg := cfg.VectorDB.PGVector
+	driverName := strings.TrimSpace(pgCfg.DriverName)
+	if !slices.Contains(sql.Drivers(), driverName) {
+		return nil, nil, fmt.Errorf("%w: %q (registered: %v)", errPGVectorDriverNotRegistered, driverName, sql.Drivers())
+	}
+	db, err := sql.Open(driverName, pgCfg.DSN)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open pgvector database: %w", err)
+	}
+	if pgCfg.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(pgCfg.MaxOpenConns)
+	}
+	if pgCfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(pgCfg.MaxIdleConns)
+	}
+	pgLogger := logger.Named("pgvector.Client")
+	client, err := pgvector.NewClient(db, &pgvector.Config{Schema: pgCfg.Schema}, pgLogger)
+	if err != nil {
+		_ = db.Close()
+		return nil, nil, fmt.Errorf("failed to create pgvector client: %w", err)
+	}
+	if err := client.EnsureSchema(context.Background()); err != nil {
+		_ = client.Close()
+		return nil, nil, fmt.Errorf("failed to initialize pgvector schema: %w", err)
+	}
+	cleanup := func() {
+		if err := client.Close(); err != nil {
+			pgLogger.KnowledgeWarnContext(context.Background(), "failed to close pgvector client", logkey.Error, err)
+		}
+	}
+	return client, cleanup, nil
+}
+
+// ProvideEmbeddedVectorClient 提供嵌入式向量库客户端，仅在向量库后端为 embedded 时创建。
+func ProvideEmbeddedVectorClient(cfg *autoloadcfg.Config, logger *logging.SugaredLogger) (*embedded.Client, func(), error) {
+	if cfg.VectorDB.EffectiveBackend() != autoloadcfg.VectorDBBackendEmbedded {
+		return nil, func() {}, nil
+	}
+	embeddedLogger := logger.Named("embedded.VectorClient")
+	client, err := embedded.NewClient(&embedded.Config{
+		DataDir:              cfg.VectorDB.Embedded.DataDir,
+		ExactSearchThreshold: cfg.VectorDB.Embedded.ExactSearchThreshold,
+		HNSWM:                cfg.VectorDB.Collection.HNSW.M,
+		HNSWEfConstruction:   cfg.VectorDB.Collection.HNSW.EfConstruct,
+		HNSWEfSearch:         cfg.VectorDB.Collection.HNSW.EfSearch,
+	}, embeddedLogger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create embedded vector client: %w", err)
+	}
+	cleanup := func() {
+		if err := client.Close(); err != nil {
+			embeddedLogger.KnowledgeWarnContext(context.Background(), "failed to close embedded vector client", logkey.Error, err)
+		}
+	}
+	return client, cleanup, nil
+}
+
+// ProvideVectorDBClients 汇总各向量库后端客户端。
+func ProvideVectorDBClients(qdrantClient *qdrant.Client, pgClient *pgvector.Client, embeddedClient *embedded.Client) *VectorDBClients {
+	return &VectorDBClients{Qdrant: qdrantClient, PGVector: pgClient, Embedded: embeddedClient}
 }
 
-// ProvideFragmentVectorDBDataRepository 提供片段向量数据库数据仓储
-func ProvideFragmentVectorDBDataRepository(client *qdrant.Client) *FragmentVectorDBDataRepository {
-	return &FragmentVectorDBDataRepository{
-		VectorDBDataRepository: qdrant.NewVectorDBDataRepository[fragmodel.FragmentPayload](client),
+// ProvideVectorDBManagementRepository 提供向量数据库管理仓储，按已创建的后端客户端选择实现，默认 Qdrant。
+func ProvideVectorDBManagementRepository(clients *VectorDBClients) fragmodel.VectorDBManagementRepository {
+	switch {
+	case clients.PGVector != nil:
+		return pgvector.NewVectorDBManagementRepository(clients.PGVector)
+	case clients.Embedded != nil:
+		return embedded.NewVectorDBManagementRepository(clients.Embedded)
+	default:
+		return qdrant.NewVectorDBManagementRepository(clients.Qdrant)
+	}
+}
+
+// ProvideFragmentVectorDBDataRepository 提供片段向量数据库数据仓储，按已创建的后端客户端选择实现，默认 Qdrant。
+func ProvideFragmentVectorDBDataRepository(clients *VectorDBClients) *FragmentVectorDBDataRepository {
+	switch {
+	case clients.PGVector != nil:
+		return &FragmentVectorDBDataRepository{
+			fragmentVectorDataStore: pgvector.NewVectorDBDataRepository[fragmodel.FragmentPayload](clients.PGVector),
+		}
+	case clients.Embedded != nil:
+		return &FragmentVectorDBDataRepository{
+			fragmentVectorDataStore: embedded.NewVectorDBDataRepository[fragmodel.FragmentPayload](clients.Embedded),
+		}
+	default:
+		return &FragmentVectorDBDataRepository{
+			fragmentVectorDataStore: qdrant.NewVectorDBDataRepository[fragmodel.FragmentPayload](clients.Qdrant),
+		}
 	}
 }
 
@@ -545,7 +790,7 @@ func ProvideHealthCheckService(
 	mysqlClient *mysql.SQLCClient,
 	redisClient *redis.Client,
 	embeddingCacheRepo *mysqlembeddingcache.Repository,
-	qdrantClient *qdrant.Client,
+	vectorClients *VectorDBClients,
 	rabbitMQBroker *documentsync.RabbitMQBroker,
 ) *health.CheckService {
 	// 适配 redis.Client 以满足 health.RedisPinger 接口
@@ -555,6 +800,12 @@ func ProvideHealthCheckService(
 		"mysql": health.NewMySQLHealthChecker(mysqlClient.DB()),
 		"redis": health.NewRedisHealthChecker(redisPinger),
 	}
+	if vectorClients.PGVector != nil {
+		checkers["pgvector"] = vectorClients.PGVector
+	}
+	if vectorClients.Embedded != nil {
+		checkers["embedded_vector"] = vectorClients.Embedded
+	}
 	return health.NewHealthCheckService(
 		checkers,
 		embeddingCacheRepo,
@@ -568,7 +819,16 @@ func ProvideHealthCheckService(
 			return closeRedisClient(redisClient)
 		}),
 		newContextCloser("qdrant client", func(context.Context) error {
-			return closeQdrantClient(qdrantClient)
+			return closeQdrantClient(vectorClients.Qdrant)
+		}),
+		newContextCloser("pgvector client", func(context.Context) error {
+			if vectorClients.PGVector == nil {
+				return nil
+			}
+			return vectorClients.PGVector.Close()
+		}),
+		newContextCloser("embedded vector client", func(context.Context) error {
+			return vectorClients.Embedded.Close()
 		}),
 		newContextCloser("rabbitmq broker", func(context.Context) error {
 			if rabbitMQBroker == nil {
@@ -636,6 +896,32 @@ func (a *redisPingerAdapter) Ping(ctx context.Context) error {
 	return nil
 }
 
+// ProvideGitRepositoryClient 提供知识库 Git 来源仓库客户端，mirror 放在系统临时目录下。
+func ProvideGitRepositoryClient() *gitrepo.Client {
+	return gitrepo.NewClient("")
+}
+
+// ProvideObjectStorageClient 提供知识库对象存储来源客户端；未配置 endpoint 时绑定同步会返回未配置错误。
+func ProvideObjectStorageClient(cfg *autoloadcfg.Config) *objectstorage.Client {
+	if cfg == nil {
+		return objectstorage.NewClient(objectstorage.Config{})
+	}
+	storageCfg := cfg.KnowledgeObjectStorage
+	return objectstorage.NewClient(objectstorage.Config{
+		Endpoint:         storageCfg.Endpoint,
+		Region:           storageCfg.Region,
+		AccessKey:        storageCfg.Identity,
+		SecretKey:        storageCfg.Signature,
+		VirtualHostStyle: storageCfg.VirtualHostStyle,
+		RequestTimeout:   time.Duration(storageCfg.RequestTimeoutSeconds) * time.Second,
+	})
+}
+
+// ProvideWebhookCallbackClient 提供知识库 webhook 来源同步结果回调客户端。
+func ProvideWebhookCallbackClient() *webhookcallback.Client {
+	return webhookcallback.NewClient(0)
+}
+
 // ProvideVolcengineOCRClient 提供火山引擎 OCR 客户端。
 func ProvideVolcengineOCRClient(
 	cfg *autoloadcfg.Config,
@@ -659,6 +945,78 @@ func ProvideVolcengineOCRClient(
 	return client
 }
 
+// ProvideLocalOCRClient 提供自部署 OCR 客户端，PDF 先经 PDFium 逐页渲染为图片再识别。
+func ProvideLocalOCRClient(
+	cfg *autoloadcfg.Config,
+	redisClient *redis.Client,
+	configProvider documentdomain.OCRConfigProviderPort,
+	usageReporter documentdomain.OCRUsageReporterPort,
+	cacheRepo docrepo.OCRResultCacheRepository,
+	renderer *vision.PDFiumPageRenderer,
+	logger *logging.SugaredLogger,
+) *ocr.LocalOCRClient {
+	var localCfg autoloadcfg.LocalOCRConfig
+	if cfg != nil {
+		localCfg = cfg.OCR.Local
+	}
+	client := ocr.NewLocalOCRClient(configProvider, cacheRepo, ocr.LocalConfig{
+		Endpoint:       localCfg.Endpoint,
+		Protocol:       localCfg.Protocol,
+		Languages:      strings.Split(localCfg.Languages, ","),
+		RequestTimeout: time.Duration(localCfg.RequestTimeoutSeconds) * time.Second,
+		MaxSourceBytes: int64(localCfg.MaxSourceMB) << 20,
+	}, logger.Named("ocr.LocalOCRClient"))
+	client.SetUsageReporter(usageReporter)
+	if renderer != nil {
+		client.SetPDFPageImageRenderer(localOCRPDFPageRenderer{
+			renderer: renderer,
+			config:   ProvideKnowledgeVisualUnderstandingConfig(cfg),
+			limits:   documentResourceLimitsFromConfig(cfg),
+		})
+	}
+	limiter, err := ratelimit.NewRedisTokenBucket(redisClient, newOCRRateLimitConfig(cfg))
+	if err != nil {
+		client.SetRateLimiter(
+			unavailableRateLimiter{err: err},
+			newOCRClientRateLimitConfig(cfg),
+		)
+		return client
+	}
+	client.SetRateLimiter(limiter, newOCRClientRateLimitConfig(cfg))
+	return client
+}
+
+// ProvideOCRClient 提供按组织 OCR 配置选择火山或自部署 provider 的 OCR 客户端。
+func ProvideOCRClient(
+	configProvider documentdomain.OCRConfigProviderPort,
+	volcengineClient *ocr.VolcengineOCRClient,
+	localClient *ocr.LocalOCRClient,
+) *ocr.ProviderRoutingClient {
+	return ocr.NewProviderRoutingClient(configProvider, map[string]documentdomain.OCRSourceClient{
+		documentdomain.OCRProviderVolcengine: volcengineClient,
+		documentdomain.OCRProviderLocal:      localClient,
+	})
+}
+
+type localOCRPDFPageRenderer struct {
+	renderer *vision.PDFiumPageRenderer
+	config   vision.Config
+	limits   documentdomain.ResourceLimits
+}
+
+func (r localOCRPDFPageRenderer) RenderPDFPageImages(
+	ctx context.Context,
+	data []byte,
+	handle func(image []byte) error,
+) error {
+	if err := r.renderer.RenderPages(ctx, data, r.config, r.limits, func(page vision.RenderedPDFPage) error {
+		return handle(page.Image)
+	}); err != nil {
+		return fmt.Errorf("render pdf pages for local ocr: %w", err)
+	}
+	return nil
+}
+
 type unavailableRateLimiter struct {
 	err error
 }
@@ -752,7 +1110,7 @@ func ProvideModelVisualTextExtractor(
 func ProvideConfigurableVisualTextExtractor(
 	abilityConfigProvider *ipcclient.PHPAIAbilityConfigRPCClient,
 	modelConfigProvider *ipcclient.PHPModelCallConfigRPCClient,
-	ocrClient *ocr.VolcengineOCRClient,
+	ocrClient *ocr.ProviderRoutingClient,
 	modelExtractor *vision.ModelVisualTextExtractor,
 	logger *logging.SugaredLogger,
 ) documentdomain.VisualTextExtractor {
@@ -776,13 +1134,16 @@ func ProvideDocumentParsers(
 	xlsxParser := parser.NewXlsxParserWithVisualAndLimits(visualExtractor, maxOCRPerFile, resourceLimits)
 	docxParser := parser.NewDocxParserWithVisualLimit(visualExtractor, maxOCRPerFile, resourceLimits)
 	officeConverter := parser.NewLegacyOfficeConverter(officeConversionConfigFromAutoload(cfg))
-	return []documentdomain.Parser{
+	parsers := []documentdomain.Parser{
 		parser.NewCSVParserWithLimits(resourceLimits),
 		parser.NewLegacyXlsParser(officeConverter, xlsxParser),
 		xlsxParser,
 		parser.NewLegacyDocParser(officeConverter, docxParser),
 		docxParser,
 		parser.NewPptxParserWithVisualLimit(visualExtractor, maxOCRPerFile, resourceLimits),
+		parser.NewOpenDocumentParserWithVisualLimit(visualExtractor, maxOCRPerFile, resourceLimits),
+		parser.NewEpubParserWithVisualLimit(visualExtractor, maxOCRPerFile, resourceLimits),
+		parser.NewRTFParserWithVisualLimit(visualExtractor, maxOCRPerFile, resourceLimits),
 		parser.NewVisualTextParser(visualExtractor),
 		parser.NewPDFHybridParserWithVisualLimit(visualExtractor, maxOCRPerFile, resourceLimits),
 		parser.NewPlainTextParser(resourceLimits),
@@ -790,7 +1151,12 @@ func ProvideDocumentParsers(
 		parser.NewHTMLParserWithVisualAssets(fileFetcher, visualExtractor, maxOCRPerFile, resourceLimits),
 		parser.NewXMLParser(resourceLimits),
 		parser.NewJSONParser(resourceLimits),
+		parser.NewCodeParser(resourceLimits),
+		parser.NewNotebookParser(resourceLimits),
+		parser.NewSubtitleParser(resourceLimits),
 	}
+	// 邮件附件复用上面的解析器递归解析。
+	return append(parsers, parser.NewEmailParser(parsers, resourceLimits))
 }
 
 func officeConversionConfigFromAutoload(cfg *autoloadcfg.Config) parser.OfficeConversionConfig {
@@ -139,6 +139,41 @@ func TestProvideVolcengineOCRClientRedisUnavailableSkipsInvoke(t *testing.T) {
 	}
 }
 
+func TestProvideLocalOCRClientRedisUnavailableSkipsInvoke(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(`{"data":{"stdout":"ok","stderr":""}}`))
+	}))
+	defer server.Close()
+
+	cfg := &autoloadcfg.Config{}
+	cfg.OCR.Local.Endpoint = server.URL
+	client := infra.ProvideLocalOCRClient(cfg, nil, localOCRConfigProviderForTest{}, nil, nil, nil, logging.New())
+
+	_, err := client.OCRBytes(context.Background(), []byte("image"), "png")
+	if !errors.Is(err, ratelimit.ErrUnavailable) || !documentdomain.IsOCROverloaded(err) {
+		t.Fatalf("expected unavailable OCR overload error, got %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("expected local OCR invoke to be skipped when limiter is unavailable, got %d calls", calls.Load())
+	}
+}
+
+type localOCRConfigProviderForTest struct{}
+
+func (localOCRConfigProviderForTest) GetOCRConfig(context.Context) (*documentdomain.OCRAbilityConfig, error) {
+	return &documentdomain.OCRAbilityConfig{
+		Enabled:      true,
+		ProviderCode: documentdomain.OCRProviderLocal,
+		Providers: []documentdomain.OCRProviderConfig{
+			{Provider: documentdomain.OCRProviderLocal, Enable: true},
+		},
+	}, nil
+}
+
 type ocrConfigProviderForTest struct{}
 
 func (ocrConfigProviderForTest) GetOCRConfig(context.Context) (*documentdomain.OCRAbilityConfig, error) {
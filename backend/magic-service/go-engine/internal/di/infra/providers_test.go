@@ -124,7 +124,7 @@ func TestProvideHealthCheckServiceCloseClosesInfraClients(t *testing.T) {
 	logger := logging.New().Named("infra.providers.test")
 	mysqlClient := mysqlinfra.NewSQLCClientWithDB(db, logger.Named("mysql"), false)
 	embeddingCacheRepo := mysqlembeddingcache.NewRepository(mysqlClient, logger.Named("embeddingcache"))
-	svc := diinfra.ProvideHealthCheckService(mysqlClient, redisClient, embeddingCacheRepo, nil, nil)
+	svc := diinfra.ProvideHealthCheckService(mysqlClient, redisClient, embeddingCacheRepo, &diinfra.VectorDBClients{}, nil)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
@@ -169,7 +169,7 @@ func TestProvideHealthCheckServiceCloseIsIdempotentForClosedRedisClient(t *testi
 	logger := logging.New().Named("infra.providers.test")
 	mysqlClient := mysqlinfra.NewSQLCClientWithDB(db, logger.Named("mysql"), false)
 	embeddingCacheRepo := mysqlembeddingcache.NewRepository(mysqlClient, logger.Named("embeddingcache"))
-	svc := diinfra.ProvideHealthCheckService(mysqlClient, redisClient, embeddingCacheRepo, nil, nil)
+	svc := diinfra.ProvideHealthCheckService(mysqlClient, redisClient, embeddingCacheRepo, &diinfra.VectorDBClients{}, nil)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
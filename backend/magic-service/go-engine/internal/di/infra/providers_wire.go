@@ -9,9 +9,12 @@ import (
 	diknowledge "magic/internal/di/knowledge"
 	documentdomain "magic/internal/domain/knowledge/document/service"
 	"magic/internal/domain/knowledge/embedding"
+	fragretrieval "magic/internal/domain/knowledge/fragment/retrieval"
 	kbrepository "magic/internal/domain/knowledge/knowledgebase/repository"
 	"magic/internal/domain/knowledge/rebuild"
 	sharedroute "magic/internal/domain/knowledge/shared/route"
+	"magic/internal/infrastructure/external"
+	chunkcontextcache "magic/internal/infrastructure/knowledge/chunkcontextcache"
 	mysqldocumentrepo "magic/internal/infrastructure/persistence/mysql/knowledge/document"
 	mysqlembeddingcache "magic/internal/infrastructure/persistence/mysql/knowledge/embeddingcache"
 	mysqlfragmentrepo "magic/internal/infrastructure/persistence/mysql/knowledge/fragment"
@@ -47,6 +50,9 @@ var ProviderSet = wire.NewSet(
 	ProvideFragmentRepository,
 	ProvideDocumentRepository,
 	ProvideOCRResultCacheRepository,
+	ProvideEvaluationRepository,
+	ProvideQueryLogRepository,
+	ProvideFeedbackRepository,
 	ProvideSourceBindingRepository,
 	ProvideKnowledgeBaseBindingRepository,
 	ProvideSuperMagicAgentRepository,
@@ -55,12 +61,23 @@ var ProviderSet = wire.NewSet(
 	ProvideKnowledgeBaseWriteCoordinator,
 	ProvideManualFragmentCoordinator,
 	ProvideQdrantClient,
+	ProvidePGVectorClient,
+	ProvideEmbeddedVectorClient,
+	ProvideVectorDBClients,
 	ProvideVectorDBManagementRepository,
 	ProvideFragmentVectorDBDataRepository,
 	// RPC 服务（通过 IPC 传输）
 	ProvideRPCServerOverIPC,
 	// 外部客户端/服务
 	ProvideAccessTokenProvider,
+	ProvideRerankClient,
+	wire.Bind(new(fragretrieval.Reranker), new(*external.RerankClient)),
+	ProvideMultiVectorClient,
+	wire.Bind(new(fragretrieval.LateInteractionEncoder), new(*external.MultiVectorClient)),
+	ProvideChatCompletionClient,
+	wire.Bind(new(fragretrieval.ChatCompleter), new(*external.ChatCompletionClient)),
+	ProvideChunkContextCache,
+	wire.Bind(new(fragretrieval.ChunkContextCache), new(*chunkcontextcache.RedisCache)),
 	ProvideThirdPlatformDocumentPort,
 	ProvideProjectFilePort,
 	ProvideMagicFSFilePort,
@@ -76,6 +93,9 @@ var ProviderSet = wire.NewSet(
 	ProvideAIAbilityConfigProvider,
 	ProvideModelCallConfigProvider,
 	ProvideWebAuthProvider,
+	ProvideGitRepositoryClient,
+	ProvideObjectStorageClient,
+	ProvideWebhookCallbackClient,
 	wire.Bind(new(diknowledge.FragmentVectorDBDataRepository), new(*FragmentVectorDBDataRepository)),
 	wire.Bind(new(documentdomain.OCRConfigProviderPort), new(*ipcclient.PHPOCRConfigRPCClient)),
 	wire.Bind(new(documentdomain.OCRUsageReporterPort), new(*ipcclient.PHPOCRConfigRPCClient)),
@@ -87,6 +107,8 @@ var ProviderSet = wire.NewSet(
 	ProvideTokenizer,
 	// 文档解析
 	ProvideVolcengineOCRClient,
+	ProvideLocalOCRClient,
+	ProvideOCRClient,
 	ProvidePHPFileRPCClient,
 	wire.Bind(new(documentdomain.FileFetcher), new(*ipcclient.PHPFileRPCClient)),
 	ProvideKnowledgeVisualUnderstandingConfig,
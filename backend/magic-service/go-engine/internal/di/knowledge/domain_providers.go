@@ -2,6 +2,8 @@
 package knowledge
 
 import (
+	"time"
+
 	autoloadcfg "magic/internal/config/autoload"
 	docrepo "magic/internal/domain/knowledge/document/repository"
 	documentdomain "magic/internal/domain/knowledge/document/service"
@@ -23,9 +25,27 @@ type BaseDomainConfig struct {
 
 // FragmentDomainConfig 聚合片段领域服务运行参数。
 type FragmentDomainConfig struct {
-	DefaultEmbeddingModel string
-	SyncFragmentBatchSize int
-	SyncMemorySoftLimit   int64
+	DefaultEmbeddingModel  string
+	SyncFragmentBatchSize  int
+	SyncMemorySoftLimit    int64
+	RerankOptions          fragretrieval.RerankOptions
+	QueryExpansionOptions  fragretrieval.QueryExpansionOptions
+	LateInteractionOptions fragretrieval.LateInteractionOptions
+	FeedbackBoostOptions   fragretrieval.FeedbackBoostOptions
+	// ContextualEnrichmentOptions 控制入库时模型生成片段上下文。
+	ContextualEnrichmentOptions fragretrieval.ContextualEnrichmentOptions
+	// AuxiliaryFragmentOptions 控制入库时模型生成摘要/问题辅助片段。
+	AuxiliaryFragmentOptions fragretrieval.AuxiliaryFragmentOptions
+}
+
+// FragmentRetrievalDeps 聚合片段检索阶段依赖的可选组件。
+type FragmentRetrievalDeps struct {
+	SegmenterProvider      *fragretrieval.SegmenterProvider
+	Reranker               fragretrieval.Reranker
+	ChatCompleter          fragretrieval.ChatCompleter
+	LateInteractionEncoder fragretrieval.LateInteractionEncoder
+	FeedbackReader         fragretrieval.FeedbackAggregateReader
+	ChunkContextCache      fragretrieval.ChunkContextCache
 }
 
 // FragmentVectorDBDataRepository 表示片段向量数据仓储契约。
@@ -61,9 +81,81 @@ func ProvideFragmentDomainConfig(
 ) FragmentDomainConfig {
 	limits := documentSyncResourceLimitsFromConfig(cfg)
 	return FragmentDomainConfig{
-		DefaultEmbeddingModel: string(defaultEmbeddingModel),
-		SyncFragmentBatchSize: limits.SyncFragmentBatchSize,
-		SyncMemorySoftLimit:   limits.SyncMemorySoftLimitBytes,
+		DefaultEmbeddingModel:       string(defaultEmbeddingModel),
+		SyncFragmentBatchSize:       limits.SyncFragmentBatchSize,
+		SyncMemorySoftLimit:         limits.SyncMemorySoftLimitBytes,
+		RerankOptions:               rerankOptionsFromConfig(cfg),
+		QueryExpansionOptions:       queryExpansionOptionsFromConfig(cfg),
+		LateInteractionOptions:      lateInteractionOptionsFromConfig(cfg),
+		FeedbackBoostOptions:        feedbackBoostOptionsFromConfig(cfg),
+		ContextualEnrichmentOptions: contextualEnrichmentOptionsFromConfig(cfg),
+		AuxiliaryFragmentOptions:    auxiliaryFragmentOptionsFromConfig(cfg),
+	}
+}
+
+func rerankOptionsFromConfig(cfg *autoloadcfg.Config) fragretrieval.RerankOptions {
+	if cfg == nil {
+		return fragretrieval.RerankOptions{}
+	}
+	return fragretrieval.RerankOptions{
+		Timeout:        time.Duration(cfg.KnowledgeRetrieval.RerankTimeoutMillis) * time.Millisecond,
+		MaxCandidates:  cfg.KnowledgeRetrieval.RerankMaxCandidates,
+		ScoreThreshold: cfg.KnowledgeRetrieval.RerankScoreThreshold,
+	}
+}
+
+func queryExpansionOptionsFromConfig(cfg *autoloadcfg.Config) fragretrieval.QueryExpansionOptions {
+	if cfg == nil {
+		return fragretrieval.QueryExpansionOptions{}
+	}
+	return fragretrieval.QueryExpansionOptions{
+		Timeout:      time.Duration(cfg.KnowledgeRetrieval.QueryExpansionTimeoutMillis) * time.Millisecond,
+		DefaultModel: cfg.KnowledgeRetrieval.QueryExpansionModel,
+		MaxVariants:  cfg.KnowledgeRetrieval.QueryExpansionMaxVariants,
+	}
+}
+
+func contextualEnrichmentOptionsFromConfig(cfg *autoloadcfg.Config) fragretrieval.ContextualEnrichmentOptions {
+	if cfg == nil {
+		return fragretrieval.ContextualEnrichmentOptions{}
+	}
+	return fragretrieval.ContextualEnrichmentOptions{
+		Timeout:      time.Duration(cfg.KnowledgeRetrieval.ContextualEnrichmentTimeoutMillis) * time.Millisecond,
+		DefaultModel: cfg.KnowledgeRetrieval.ContextualEnrichmentModel,
+		Concurrency:  cfg.KnowledgeRetrieval.ContextualEnrichmentConcurrency,
+	}
+}
+
+func auxiliaryFragmentOptionsFromConfig(cfg *autoloadcfg.Config) fragretrieval.AuxiliaryFragmentOptions {
+	if cfg == nil {
+		return fragretrieval.AuxiliaryFragmentOptions{}
+	}
+	return fragretrieval.AuxiliaryFragmentOptions{
+		Timeout:      time.Duration(cfg.KnowledgeRetrieval.AuxiliaryFragmentTimeoutMillis) * time.Millisecond,
+		DefaultModel: cfg.KnowledgeRetrieval.AuxiliaryFragmentModel,
+		Concurrency:  cfg.KnowledgeRetrieval.AuxiliaryFragmentConcurrency,
+	}
+}
+
+func lateInteractionOptionsFromConfig(cfg *autoloadcfg.Config) fragretrieval.LateInteractionOptions {
+	if cfg == nil {
+		return fragretrieval.LateInteractionOptions{}
+	}
+	return fragretrieval.LateInteractionOptions{
+		Model:         cfg.KnowledgeRetrieval.LateInteractionModel,
+		Timeout:       time.Duration(cfg.KnowledgeRetrieval.LateInteractionTimeoutMillis) * time.Millisecond,
+		MaxCandidates: cfg.KnowledgeRetrieval.LateInteractionMaxCandidates,
+	}
+}
+
+func feedbackBoostOptionsFromConfig(cfg *autoloadcfg.Config) fragretrieval.FeedbackBoostOptions {
+	if cfg == nil {
+		return fragretrieval.FeedbackBoostOptions{}
+	}
+	return fragretrieval.FeedbackBoostOptions{
+		Enabled:  cfg.KnowledgeRetrieval.FeedbackBoostEnabled,
+		MaxBoost: cfg.KnowledgeRetrieval.FeedbackMaxBoost,
+		Timeout:  time.Duration(cfg.KnowledgeRetrieval.FeedbackTimeoutMillis) * time.Millisecond,
 	}
 }
 
@@ -99,24 +191,54 @@ func ProvideFragmentRetrievalSegmenterProvider() *fragretrieval.SegmenterProvide
 	return fragretrieval.NewDefaultSegmenterProvider()
 }
 
+// ProvideFragmentRetrievalDeps 提供片段检索阶段依赖。
+func ProvideFragmentRetrievalDeps(
+	segmenterProvider *fragretrieval.SegmenterProvider,
+	reranker fragretrieval.Reranker,
+	chatCompleter fragretrieval.ChatCompleter,
+	lateInteractionEncoder fragretrieval.LateInteractionEncoder,
+	feedbackReader fragretrieval.FeedbackAggregateReader,
+	chunkContextCache fragretrieval.ChunkContextCache,
+) FragmentRetrievalDeps {
+	return FragmentRetrievalDeps{
+		SegmenterProvider:      segmenterProvider,
+		Reranker:               reranker,
+		ChatCompleter:          chatCompleter,
+		LateInteractionEncoder: lateInteractionEncoder,
+		FeedbackReader:         feedbackReader,
+		ChunkContextCache:      chunkContextCache,
+	}
+}
+
 // ProvideFragmentDomainInfra 提供片段领域服务依赖的基础设施聚合。
 func ProvideFragmentDomainInfra(
 	vectorMgmtRepo fragmodel.VectorDBManagementRepository,
 	vectorDataRepo FragmentVectorDBDataRepository,
 	metaReader sharedroute.CollectionMetaReader,
 	cfg FragmentDomainConfig,
-	segmenterProvider *fragretrieval.SegmenterProvider,
+	retrievalDeps FragmentRetrievalDeps,
 	logger *logging.SugaredLogger,
 ) fragdomain.FragmentDomainInfra {
 	return fragdomain.FragmentDomainInfra{
-		VectorMgmtRepo:        vectorMgmtRepo,
-		VectorDataRepo:        vectorDataRepo,
-		MetaReader:            metaReader,
-		DefaultEmbeddingModel: cfg.DefaultEmbeddingModel,
-		SegmenterProvider:     segmenterProvider,
-		Logger:                logger,
-		SyncFragmentBatchSize: cfg.SyncFragmentBatchSize,
-		SyncMemorySoftLimit:   cfg.SyncMemorySoftLimit,
+		VectorMgmtRepo:              vectorMgmtRepo,
+		VectorDataRepo:              vectorDataRepo,
+		MetaReader:                  metaReader,
+		DefaultEmbeddingModel:       cfg.DefaultEmbeddingModel,
+		SegmenterProvider:           retrievalDeps.SegmenterProvider,
+		Logger:                      logger,
+		SyncFragmentBatchSize:       cfg.SyncFragmentBatchSize,
+		SyncMemorySoftLimit:         cfg.SyncMemorySoftLimit,
+		Reranker:                    retrievalDeps.Reranker,
+		RerankOptions:               cfg.RerankOptions,
+		ChatCompleter:               retrievalDeps.ChatCompleter,
+		QueryExpansionOptions:       cfg.QueryExpansionOptions,
+		LateInteractionEncoder:      retrievalDeps.LateInteractionEncoder,
+		LateInteractionOptions:      cfg.LateInteractionOptions,
+		FeedbackReader:              retrievalDeps.FeedbackReader,
+		FeedbackBoostOptions:        cfg.FeedbackBoostOptions,
+		ChunkContextCache:           retrievalDeps.ChunkContextCache,
+		ContextualEnrichmentOptions: cfg.ContextualEnrichmentOptions,
+		AuxiliaryFragmentOptions:    cfg.AuxiliaryFragmentOptions,
 	}
 }
 
@@ -0,0 +1,64 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+
+	evalapp "magic/internal/application/knowledge/evaluation"
+	fragdto "magic/internal/application/knowledge/fragment/dto"
+	fragmentapp "magic/internal/application/knowledge/fragment/service"
+	evalrepository "magic/internal/domain/knowledge/evaluation/repository"
+	"magic/internal/infrastructure/logging"
+	"magic/internal/pkg/ctxmeta"
+)
+
+// fragmentEvaluationSearcher 让评测回放复用线上 runtime 相似度检索链路。
+type fragmentEvaluationSearcher struct {
+	fragmentAppService *fragmentapp.FragmentAppService
+}
+
+func (s fragmentEvaluationSearcher) SearchForEvaluation(
+	ctx context.Context,
+	input evalapp.SearchInput,
+) ([]evalapp.RetrievedHit, error) {
+	results, err := s.fragmentAppService.RuntimeSimilarity(ctx, &fragdto.RuntimeSimilarityInput{
+		OrganizationCode: input.OrganizationCode,
+		KnowledgeCodes:   []string{input.KnowledgeBaseCode},
+		Query:            input.Query,
+		TopK:             input.TopK,
+		SkipQueryLog:     true,
+		BusinessParams: &ctxmeta.BusinessParams{
+			OrganizationCode: input.OrganizationCode,
+			UserID:           input.UserID,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("runtime similarity for evaluation: %w", err)
+	}
+
+	hits := make([]evalapp.RetrievedHit, 0, len(results))
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		hits = append(hits, evalapp.RetrievedHit{
+			FragmentID:   result.ID,
+			DocumentCode: result.DocumentCode,
+			Score:        result.Score,
+		})
+	}
+	return hits, nil
+}
+
+// ProvideEvaluationAppService 提供知识库检索评测应用服务。
+func ProvideEvaluationAppService(
+	repo evalrepository.Repository,
+	fragmentAppService *fragmentapp.FragmentAppService,
+	logger *logging.SugaredLogger,
+) *evalapp.Service {
+	var searcher evalapp.SimilaritySearcher
+	if fragmentAppService != nil {
+		searcher = fragmentEvaluationSearcher{fragmentAppService: fragmentAppService}
+	}
+	return evalapp.NewService(repo, searcher, logger)
+}
@@ -0,0 +1,59 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+
+	feedbackapp "magic/internal/application/knowledge/feedback"
+	feedbackrepository "magic/internal/domain/knowledge/feedback/repository"
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	fragretrieval "magic/internal/domain/knowledge/fragment/retrieval"
+	"magic/internal/infrastructure/logging"
+)
+
+// feedbackAggregateReader 把反馈仓储的聚合计数转换为检索排序使用的反馈聚合。
+type feedbackAggregateReader struct {
+	repo feedbackrepository.StatsReader
+}
+
+func (r feedbackAggregateReader) ListFeedbackAggregates(
+	ctx context.Context,
+	query fragretrieval.FeedbackAggregateQuery,
+) ([]fragretrieval.FeedbackAggregate, error) {
+	stats, err := r.repo.ListStatsByDocuments(ctx, feedbackrepository.StatsQuery{
+		OrganizationCode:  query.OrganizationCode,
+		KnowledgeBaseCode: query.KnowledgeBaseCode,
+		DocumentCodes:     query.DocumentCodes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list feedback stats: %w", err)
+	}
+	aggregates := make([]fragretrieval.FeedbackAggregate, 0, len(stats))
+	for _, stat := range stats {
+		aggregates = append(aggregates, fragretrieval.FeedbackAggregate{
+			DocumentCode:  stat.DocumentCode,
+			FragmentID:    stat.FragmentID,
+			PositiveCount: stat.PositiveCount,
+			NegativeCount: stat.NegativeCount,
+			AnsweredCount: stat.AnsweredCount,
+		})
+	}
+	return aggregates, nil
+}
+
+// ProvideFeedbackAggregateReader 将反馈仓储适配为检索排序使用的聚合读取端口。
+func ProvideFeedbackAggregateReader(repo feedbackrepository.Repository) fragretrieval.FeedbackAggregateReader {
+	if repo == nil {
+		return nil
+	}
+	return feedbackAggregateReader{repo: repo}
+}
+
+// ProvideFeedbackAppService 提供相关性反馈应用服务。
+func ProvideFeedbackAppService(
+	repo feedbackrepository.Repository,
+	fragmentRepo fragmodel.KnowledgeBaseFragmentRepository,
+	logger *logging.SugaredLogger,
+) *feedbackapp.Service {
+	return feedbackapp.NewService(repo, fragmentRepo, logger)
+}
@@ -0,0 +1,50 @@
+package knowledge
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	feedbackentity "magic/internal/domain/knowledge/feedback/entity"
+	feedbackrepository "magic/internal/domain/knowledge/feedback/repository"
+	fragretrieval "magic/internal/domain/knowledge/fragment/retrieval"
+)
+
+type feedbackStatsReaderStub struct {
+	query feedbackrepository.StatsQuery
+	stats []feedbackentity.Stat
+}
+
+func (s *feedbackStatsReaderStub) ListStatsByDocuments(
+	_ context.Context,
+	query feedbackrepository.StatsQuery,
+) ([]feedbackentity.Stat, error) {
+	s.query = query
+	return s.stats, nil
+}
+
+func TestFeedbackAggregateReaderAdaptsStats(t *testing.T) {
+	t.Parallel()
+
+	repo := &feedbackStatsReaderStub{stats: []feedbackentity.Stat{
+		{KnowledgeBaseCode: "KB1", DocumentCode: "DOC-1", FragmentID: 7, PositiveCount: 1, NegativeCount: 2, AnsweredCount: 3},
+	}}
+	aggregates, err := feedbackAggregateReader{repo: repo}.ListFeedbackAggregates(context.Background(), fragretrieval.FeedbackAggregateQuery{
+		OrganizationCode:  "ORG1",
+		KnowledgeBaseCode: "KB1",
+		DocumentCodes:     []string{"DOC-1"},
+	})
+	if err != nil {
+		t.Fatalf("list feedback aggregates: %v", err)
+	}
+	if repo.query.OrganizationCode != "ORG1" || repo.query.KnowledgeBaseCode != "KB1" || !slices.Equal(repo.query.DocumentCodes, []string{"DOC-1"}) {
+		t.Fatalf("unexpected stats query: %#v", repo.query)
+	}
+	want := fragretrieval.FeedbackAggregate{DocumentCode: "DOC-1", FragmentID: 7, PositiveCount: 1, NegativeCount: 2, AnsweredCount: 3}
+	if len(aggregates) != 1 || aggregates[0] != want {
+		t.Fatalf("unexpected aggregates: %#v", aggregates)
+	}
+	if ProvideFeedbackAggregateReader(nil) != nil {
+		t.Fatal("expected nil repository to disable feedback reader")
+	}
+}
@@ -15,6 +15,7 @@ import (
 	embeddingapp "magic/internal/application/knowledge/embedding/service"
 	fragmentapp "magic/internal/application/knowledge/fragment/service"
 	knowledgebaseapp "magic/internal/application/knowledge/knowledgebase/service"
+	querylogapp "magic/internal/application/knowledge/querylog"
 	revectorizeapp "magic/internal/application/knowledge/revectorize/service"
 	revectorizeshared "magic/internal/application/knowledge/shared/revectorize"
 	thirdplatformprovider "magic/internal/application/knowledge/shared/thirdplatformprovider"
@@ -29,6 +30,10 @@ import (
 	knowledgebasedomain "magic/internal/domain/knowledge/knowledgebase/service"
 	supermagicprojectdomain "magic/internal/domain/supermagicproject/service"
 	taskfiledomain "magic/internal/domain/taskfile/service"
+	"magic/internal/infrastructure/external/gitrepo"
+	"magic/internal/infrastructure/external/objectstorage"
+	"magic/internal/infrastructure/external/webcrawl"
+	"magic/internal/infrastructure/external/webhookcallback"
 	"magic/internal/infrastructure/knowledge/documentsync"
 	sourcecacheversion "magic/internal/infrastructure/knowledge/sourcecacheversion"
 	sourcecallbackcache "magic/internal/infrastructure/knowledge/sourcecallbackcache"
@@ -118,6 +123,8 @@ type BasePortDeps struct {
 	SuperMagicAgentPort         *ipcclient.PHPSuperMagicAgentRPCClient
 	ProjectFilePort             *ipcclient.PHPProjectFileRPCClient
 	ThirdPlatformPort           *ipcclient.PHPThirdPlatformDocumentRPCClient
+	GitRepository               *gitrepo.Client
+	ObjectStorage               *objectstorage.Client
 }
 
 // BaseBindingDeps 表示知识库产品线绑定相关依赖。
@@ -160,12 +167,16 @@ func ProvideKnowledgeBasePortDeps(
 	superMagicAgentPort *ipcclient.PHPSuperMagicAgentRPCClient,
 	projectFilePort *ipcclient.PHPProjectFileRPCClient,
 	thirdPlatformPort *ipcclient.PHPThirdPlatformDocumentRPCClient,
+	gitRepository *gitrepo.Client,
+	objectStorage *objectstorage.Client,
 ) BasePortDeps {
 	return BasePortDeps{
 		KnowledgeBasePermissionPort: knowledgeBasePermissionPort,
 		SuperMagicAgentPort:         superMagicAgentPort,
 		ProjectFilePort:             projectFilePort,
 		ThirdPlatformPort:           thirdPlatformPort,
+		GitRepository:               gitRepository,
+		ObjectStorage:               objectStorage,
 	}
 }
 
@@ -258,6 +269,9 @@ func ProvideKnowledgeBaseAppService(
 	appSvc.SetTaskFileService(deps.DomainDeps.TaskFileService)
 	appSvc.SetUserService(deps.DomainDeps.UserService)
 	appSvc.SetThirdPlatformExpander(deps.PortDeps.ThirdPlatformPort)
+	appSvc.SetWebsiteCrawler(webcrawl.NewCrawler(nil))
+	appSvc.SetGitRepository(deps.PortDeps.GitRepository)
+	appSvc.SetObjectStorageLister(deps.PortDeps.ObjectStorage)
 	appSvc.SetSourceBindingTreeRootCache(knowledgebaseapp.NewRedisSourceBindingTreeRootCache(redisClient))
 	appSvc.SetSuperMagicAgentReader(deps.SuperMagicAgentRepo)
 	appSvc.SetSuperMagicAgentAccessChecker(deps.PortDeps.SuperMagicAgentPort)
@@ -265,6 +279,65 @@ func ProvideKnowledgeBaseAppService(
 	return appSvc
 }
 
+// ProvideWebsiteRecrawlService 提供网站来源定时重爬服务。
+func ProvideWebsiteRecrawlService(
+	appSvc *knowledgebaseapp.KnowledgeBaseAppService,
+	sourceBindingRepo *mysqlsourcebindingrepo.Repository,
+	jobRunner lockpkg.SinglePodJobRunner,
+	logger *logging.SugaredLogger,
+) (*knowledgebaseapp.WebsiteRecrawlService, error) {
+	svc, err := knowledgebaseapp.NewWebsiteRecrawlService(appSvc, sourceBindingRepo, jobRunner, logger)
+	if err != nil {
+		return nil, fmt.Errorf("create website recrawl service: %w", err)
+	}
+	return svc, nil
+}
+
+// ProvideGitSourceSyncService 提供 Git 来源定时增量同步服务。
+func ProvideGitSourceSyncService(
+	appSvc *knowledgebaseapp.KnowledgeBaseAppService,
+	sourceBindingRepo *mysqlsourcebindingrepo.Repository,
+	jobRunner lockpkg.SinglePodJobRunner,
+	logger *logging.SugaredLogger,
+) (*knowledgebaseapp.GitSourceSyncService, error) {
+	svc, err := knowledgebaseapp.NewGitSourceSyncService(appSvc, sourceBindingRepo, jobRunner, logger)
+	if err != nil {
+		return nil, fmt.Errorf("create git source sync service: %w", err)
+	}
+	return svc, nil
+}
+
+// ProvideObjectStorageSyncService 提供对象存储来源定时轮询同步服务。
+func ProvideObjectStorageSyncService(
+	appSvc *knowledgebaseapp.KnowledgeBaseAppService,
+	sourceBindingRepo *mysqlsourcebindingrepo.Repository,
+	jobRunner lockpkg.SinglePodJobRunner,
+	logger *logging.SugaredLogger,
+) (*knowledgebaseapp.ObjectStorageSyncService, error) {
+	svc, err := knowledgebaseapp.NewObjectStorageSyncService(appSvc, sourceBindingRepo, jobRunner, logger)
+	if err != nil {
+		return nil, fmt.Errorf("create object storage sync service: %w", err)
+	}
+	return svc, nil
+}
+
+// ProvideWebhookSourceService 提供 webhook 推送来源服务，并注册为文档同步终态观察者以回调外部系统。
+func ProvideWebhookSourceService(
+	appSvc *knowledgebaseapp.KnowledgeBaseAppService,
+	docApp *documentapp.DocumentAppService,
+	sourceBindingRepo *mysqlsourcebindingrepo.Repository,
+	jobRunner lockpkg.SinglePodJobRunner,
+	notifier *webhookcallback.Client,
+	logger *logging.SugaredLogger,
+) (*knowledgebaseapp.WebhookSourceService, error) {
+	svc, err := knowledgebaseapp.NewWebhookSourceService(appSvc, sourceBindingRepo, jobRunner, notifier, logger)
+	if err != nil {
+		return nil, fmt.Errorf("create webhook source service: %w", err)
+	}
+	docApp.SetSyncResultObserver(svc)
+	return svc, nil
+}
+
 type knowledgeBasePermissionWriter struct {
 	port *ipcclient.PHPKnowledgeBasePermissionRPCClient
 }
@@ -334,7 +407,7 @@ func ProvideFragmentAppDeps(
 	runtimeDeps FragmentAppRuntimeDeps,
 	userService *userdomain.DomainService,
 ) fragmentapp.AppDeps {
-	return fragmentapp.AppDeps{
+	deps := fragmentapp.AppDeps{
 		ParseService:              parseService,
 		ProjectFileContentPort:    portDeps.ProjectFilePort,
 		ThirdPlatformDocumentPort: portDeps.ThirdPlatformPort,
@@ -349,6 +422,11 @@ func ProvideFragmentAppDeps(
 		UserService:               userService,
 		DefaultEmbeddingModel:     string(runtimeDeps.DefaultEmbeddingModel),
 	}
+	// 未启用时保持接口为 nil，避免装箱 nil 指针。
+	if runtimeDeps.QueryLogRecorder != nil {
+		deps.QueryLogRecorder = runtimeDeps.QueryLogRecorder
+	}
+	return deps
 }
 
 // FragmentAppRuntimeDeps 表示片段应用服务依赖的运行时对象。
@@ -356,6 +434,7 @@ type FragmentAppRuntimeDeps struct {
 	ManualFragmentCoordinator *mysqltransaction.ManualFragmentCoordinator
 	TokenizerService          *tokenizer.Service
 	DefaultEmbeddingModel     autoloadcfg.EmbeddingDefaultModel
+	QueryLogRecorder          *querylogapp.Recorder
 }
 
 // ProvideFragmentAppRuntimeDeps 提供片段应用服务运行时依赖。
@@ -363,11 +442,13 @@ func ProvideFragmentAppRuntimeDeps(
 	manualFragmentCoordinator *mysqltransaction.ManualFragmentCoordinator,
 	tokenizerService *tokenizer.Service,
 	defaultModel autoloadcfg.EmbeddingDefaultModel,
+	queryLogRecorder *querylogapp.Recorder,
 ) FragmentAppRuntimeDeps {
 	return FragmentAppRuntimeDeps{
 		ManualFragmentCoordinator: manualFragmentCoordinator,
 		TokenizerService:          tokenizerService,
 		DefaultEmbeddingModel:     defaultModel,
+		QueryLogRecorder:          queryLogRecorder,
 	}
 }
 
@@ -408,6 +489,8 @@ type DocumentAppRuntimeDeps struct {
 	SyncRuntime      *documentsync.Runtime
 	ProgressStore    *revectorizeshared.RedisProgressStore
 	RedisClient      *redis.Client
+	GitFileReader    *gitrepo.Client
+	ObjectReader     *objectstorage.Client
 }
 
 // ProvideDocumentAppRuntimeDeps 提供文档应用服务运行时依赖。
@@ -417,6 +500,8 @@ func ProvideDocumentAppRuntimeDeps(
 	syncRuntime *documentsync.Runtime,
 	progressStore *revectorizeshared.RedisProgressStore,
 	redisClient *redis.Client,
+	gitFileReader *gitrepo.Client,
+	objectReader *objectstorage.Client,
 ) DocumentAppRuntimeDeps {
 	return DocumentAppRuntimeDeps{
 		Config:           cfg,
@@ -424,6 +509,8 @@ func ProvideDocumentAppRuntimeDeps(
 		SyncRuntime:      syncRuntime,
 		ProgressStore:    progressStore,
 		RedisClient:      redisClient,
+		GitFileReader:    gitFileReader,
+		ObjectReader:     objectReader,
 	}
 }
 
@@ -602,6 +689,8 @@ func ProvideDocumentAppService(
 	)
 	appSvc.SetOriginalFileLinkProvider(runtimeDeps.FileLinkProvider)
 	appSvc.SetKnowledgeRevectorizeProgressStore(runtimeDeps.ProgressStore)
+	appSvc.SetGitFileReader(runtimeDeps.GitFileReader)
+	appSvc.SetObjectStorageReader(runtimeDeps.ObjectReader)
 	sourceCallbackCache := buildSourceCallbackRedisCache(runtimeDeps)
 	appSvc.SetSourceBindingCandidateCache(sourceCallbackCache)
 	appSvc.SetSourceCallbackSingleflight(sourceCallbackCache)
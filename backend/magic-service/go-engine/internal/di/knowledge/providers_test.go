@@ -87,6 +87,7 @@ func TestProvideServices(t *testing.T) {
 				nil,
 				nil,
 				autoloadcfg.EmbeddingDefaultModel("model-frag"),
+				nil,
 			),
 			nil,
 		),
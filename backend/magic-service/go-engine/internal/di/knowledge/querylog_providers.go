@@ -0,0 +1,36 @@
+package knowledge
+
+import (
+	"time"
+
+	querylogapp "magic/internal/application/knowledge/querylog"
+	autoloadcfg "magic/internal/config/autoload"
+	querylogrepository "magic/internal/domain/knowledge/querylog/repository"
+	"magic/internal/infrastructure/logging"
+)
+
+// ProvideQueryLogRecorder 提供检索 query 日志记录器，未启用时返回 nil。
+func ProvideQueryLogRecorder(
+	repo querylogrepository.Repository,
+	cfg *autoloadcfg.Config,
+	logger *logging.SugaredLogger,
+) *querylogapp.Recorder {
+	if cfg == nil {
+		return nil
+	}
+	queryLogCfg := cfg.KnowledgeQueryLog
+	return querylogapp.NewRecorder(repo, querylogapp.RecorderConfig{
+		Enabled:      queryLogCfg.Enabled,
+		SampleRate:   queryLogCfg.SampleRate,
+		MaxInFlight:  queryLogCfg.MaxInFlight,
+		WriteTimeout: time.Duration(queryLogCfg.WriteTimeoutMillis) * time.Millisecond,
+	}, logger.Named("knowledge.QueryLogRecorder"))
+}
+
+// ProvideQueryLogAnalyticsService 提供检索日志统计应用服务。
+func ProvideQueryLogAnalyticsService(
+	repo querylogrepository.Repository,
+	logger *logging.SugaredLogger,
+) *querylogapp.AnalyticsService {
+	return querylogapp.NewAnalyticsService(repo, logger)
+}
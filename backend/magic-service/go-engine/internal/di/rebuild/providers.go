@@ -21,7 +21,6 @@ import (
 	redisrebuild "magic/internal/infrastructure/persistence/redis/rebuild"
 	infrarebuild "magic/internal/infrastructure/rebuild"
 	ipcclient "magic/internal/infrastructure/rpc/jsonrpc/client"
-	"magic/internal/infrastructure/vectordb/qdrant"
 	"magic/internal/pkg/ctxmeta"
 	"magic/internal/pkg/knowledgeroute"
 )
@@ -193,7 +192,7 @@ func (a *vectorCollectionManagerAdapter) CollectionExists(ctx context.Context, n
 func (a *vectorCollectionManagerAdapter) GetCollectionInfo(ctx context.Context, name string) (*domainrebuild.VectorCollectionInfo, error) {
 	info, err := a.repo.GetCollectionInfo(ctx, name)
 	if err != nil {
-		if errors.Is(err, qdrant.ErrCollectionNotFound) {
+		if errors.Is(err, shared.ErrVectorCollectionNotFound) {
 			return &domainrebuild.VectorCollectionInfo{Name: name, VectorSize: 0}, nil
 		}
 		return nil, fmt.Errorf("get collection %s info: %w", name, err)
@@ -202,12 +201,14 @@ func (a *vectorCollectionManagerAdapter) GetCollectionInfo(ctx context.Context,
 		return &domainrebuild.VectorCollectionInfo{Name: name, VectorSize: 0}, nil
 	}
 	return &domainrebuild.VectorCollectionInfo{
-		Name:                info.Name,
-		VectorSize:          info.VectorSize,
-		Points:              info.Points,
-		HasNamedDenseVector: info.HasNamedDenseVector,
-		HasSparseVector:     info.HasSparseVector,
-		PayloadSchemaKeys:   append([]string(nil), info.PayloadSchemaKeys...),
+		Name:                  info.Name,
+		VectorSize:            info.VectorSize,
+		Points:                info.Points,
+		HasNamedDenseVector:   info.HasNamedDenseVector,
+		HasSparseVector:       info.HasSparseVector,
+		HasMultiVector:        info.HasMultiVector,
+		PayloadSchemaKeys:     append([]string(nil), info.PayloadSchemaKeys...),
+		StorageConfigMismatch: info.StorageConfigMismatch,
 	}, nil
 }
 
@@ -257,7 +258,7 @@ func (a *vectorCollectionManagerAdapter) ListCollections(ctx context.Context) ([
 
 func (a *vectorCollectionManagerAdapter) DeleteCollection(ctx context.Context, name string) error {
 	err := a.repo.DeleteCollection(ctx, name)
-	if err != nil && errors.Is(err, qdrant.ErrCollectionNotFound) {
+	if err != nil && errors.Is(err, shared.ErrVectorCollectionNotFound) {
 		return nil
 	}
 	if err != nil {
@@ -35,6 +35,30 @@ const (
 	DocTypePPT DocType = 12
 	// DocTypeJSON JSON。
 	DocTypeJSON DocType = 13
+	// DocTypeODT ODT。
+	DocTypeODT DocType = 14
+	// DocTypeODS ODS。
+	DocTypeODS DocType = 15
+	// DocTypeODP ODP。
+	DocTypeODP DocType = 16
+	// DocTypeEPUB EPUB。
+	DocTypeEPUB DocType = 17
+	// DocTypeRTF RTF。
+	DocTypeRTF DocType = 18
+	// DocTypeEML EML。
+	DocTypeEML DocType = 19
+	// DocTypeMBOX MBOX。
+	DocTypeMBOX DocType = 20
+	// DocTypeMSG Outlook MSG。
+	DocTypeMSG DocType = 21
+	// DocTypeCode 源代码（语言由扩展名区分）。
+	DocTypeCode DocType = 22
+	// DocTypeIPYNB Jupyter Notebook。
+	DocTypeIPYNB DocType = 23
+	// DocTypeSRT SRT 字幕。
+	DocTypeSRT DocType = 24
+	// DocTypeVTT WebVTT 字幕。
+	DocTypeVTT DocType = 25
 	// DocTypeCloudDocument 云文档。
 	DocTypeCloudDocument DocType = 1001
 	// DocTypeMultiTable 多表文档。
@@ -58,6 +82,18 @@ func DefaultDocumentListDocTypeValues() []uint32 {
 		uint32(DocTypeHTM),
 		uint32(DocTypePPT),
 		uint32(DocTypeJSON),
+		uint32(DocTypeODT),
+		uint32(DocTypeODS),
+		uint32(DocTypeODP),
+		uint32(DocTypeEPUB),
+		uint32(DocTypeRTF),
+		uint32(DocTypeEML),
+		uint32(DocTypeMBOX),
+		uint32(DocTypeMSG),
+		uint32(DocTypeCode),
+		uint32(DocTypeIPYNB),
+		uint32(DocTypeSRT),
+		uint32(DocTypeVTT),
 		uint32(DocTypeCloudDocument),
 		uint32(DocTypeMultiTable),
 	}
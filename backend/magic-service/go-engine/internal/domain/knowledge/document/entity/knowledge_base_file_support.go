@@ -8,7 +8,7 @@ import (
 
 // SupportedKnowledgeBaseFileExtensions 返回知识库白名单扩展名。
 func SupportedKnowledgeBaseFileExtensions() []string {
-	return []string{
+	extensions := []string{
 		"txt",
 		"md",
 		"html",
@@ -22,12 +22,24 @@ func SupportedKnowledgeBaseFileExtensions() []string {
 		"docx",
 		"doc",
 		"pptx",
+		"odt",
+		"ods",
+		"odp",
+		"epub",
+		"rtf",
+		"eml",
+		"mbox",
+		"msg",
+		"ipynb",
+		"srt",
+		"vtt",
 		"pdf",
 		"jpg",
 		"jpeg",
 		"png",
 		"bmp",
 	}
+	return append(extensions, filetype.SourceCodeExtensions()...)
 }
 
 // IsSupportedKnowledgeBaseFileExtension 判断知识库是否支持该扩展名。
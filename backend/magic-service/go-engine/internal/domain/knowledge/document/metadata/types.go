@@ -60,6 +60,30 @@ const (
 	DocTypePPT = docentity.DocTypePPT
 	// DocTypeJSON 表示 JSON。
 	DocTypeJSON = docentity.DocTypeJSON
+	// DocTypeODT 表示 ODT。
+	DocTypeODT = docentity.DocTypeODT
+	// DocTypeODS 表示 ODS。
+	DocTypeODS = docentity.DocTypeODS
+	// DocTypeODP 表示 ODP。
+	DocTypeODP = docentity.DocTypeODP
+	// DocTypeEPUB 表示 EPUB。
+	DocTypeEPUB = docentity.DocTypeEPUB
+	// DocTypeRTF 表示 RTF。
+	DocTypeRTF = docentity.DocTypeRTF
+	// DocTypeEML 表示 EML。
+	DocTypeEML = docentity.DocTypeEML
+	// DocTypeMBOX 表示 MBOX。
+	DocTypeMBOX = docentity.DocTypeMBOX
+	// DocTypeMSG 表示 Outlook MSG。
+	DocTypeMSG = docentity.DocTypeMSG
+	// DocTypeCode 表示源代码。
+	DocTypeCode = docentity.DocTypeCode
+	// DocTypeIPYNB 表示 Jupyter Notebook。
+	DocTypeIPYNB = docentity.DocTypeIPYNB
+	// DocTypeSRT 表示 SRT 字幕。
+	DocTypeSRT = docentity.DocTypeSRT
+	// DocTypeVTT 表示 WebVTT 字幕。
+	DocTypeVTT = docentity.DocTypeVTT
 	// DocTypeCloudDocument 表示云文档。
 	DocTypeCloudDocument = docentity.DocTypeCloudDocument
 	// DocTypeMultiTable 表示多表文档。
@@ -79,6 +103,10 @@ const (
 	ParsingTypeQuick = documentservice.ParsingTypeQuick
 	// ParsingTypePrecise 表示精细解析。
 	ParsingTypePrecise = documentservice.ParsingTypePrecise
+	// EmailGroupingMessage 表示邮件按单封拆块。
+	EmailGroupingMessage = documentservice.EmailGroupingMessage
+	// EmailGroupingThread 表示邮件按会话合并拆块。
+	EmailGroupingThread = documentservice.EmailGroupingThread
 )
 
 // ParsedDocument 复用统一解析结果。
@@ -95,10 +123,30 @@ const (
 	ParsedDocumentSourceText = parseddocument.SourceText
 	// ParsedDocumentSourceTabular 表示表格来源。
 	ParsedDocumentSourceTabular = parseddocument.SourceTabular
+	// ParsedDocumentSourceEmail 表示邮件来源。
+	ParsedDocumentSourceEmail = parseddocument.SourceEmail
+	// ParsedDocumentSourceCode 表示源代码来源。
+	ParsedDocumentSourceCode = parseddocument.SourceCode
+	// ParsedDocumentSourceNotebook 表示 Notebook 来源。
+	ParsedDocumentSourceNotebook = parseddocument.SourceNotebook
+	// ParsedDocumentSourceSubtitle 表示字幕来源。
+	ParsedDocumentSourceSubtitle = parseddocument.SourceSubtitle
 	// ParsedBlockTypeTableRow 表示表格行块。
 	ParsedBlockTypeTableRow = parseddocument.BlockTypeTableRow
 	// ParsedBlockTypeTableSummary 表示表摘要块。
 	ParsedBlockTypeTableSummary = parseddocument.BlockTypeTableSummary
+	// ParsedBlockTypeEmailMessage 表示单封邮件块。
+	ParsedBlockTypeEmailMessage = parseddocument.BlockTypeEmailMessage
+	// ParsedBlockTypeEmailThread 表示邮件会话块。
+	ParsedBlockTypeEmailThread = parseddocument.BlockTypeEmailThread
+	// ParsedBlockTypeCodeSymbol 表示源代码符号块。
+	ParsedBlockTypeCodeSymbol = parseddocument.BlockTypeCodeSymbol
+	// ParsedBlockTypeCodeModule 表示模块级代码块。
+	ParsedBlockTypeCodeModule = parseddocument.BlockTypeCodeModule
+	// ParsedBlockTypeNotebookCell 表示 Notebook 单元格块。
+	ParsedBlockTypeNotebookCell = parseddocument.BlockTypeNotebookCell
+	// ParsedBlockTypeSubtitleWindow 表示字幕时间窗口块。
+	ParsedBlockTypeSubtitleWindow = parseddocument.BlockTypeSubtitleWindow
 	// ParsedMetaSourceFormat 表示来源格式元数据键。
 	ParsedMetaSourceFormat = parseddocument.MetaSourceFormat
 	// ParsedMetaFileName 表示文件名元数据键。
@@ -131,6 +179,54 @@ const (
 	ParsedMetaTableRowCount = parseddocument.MetaTableRowCount
 	// ParsedMetaFields 表示字段元数据键。
 	ParsedMetaFields = parseddocument.MetaFields
+	// ParsedMetaCreatedAtTS 表示块时间元数据键。
+	ParsedMetaCreatedAtTS = parseddocument.MetaCreatedAtTS
+	// ParsedMetaTags 表示块标签元数据键。
+	ParsedMetaTags = parseddocument.MetaTags
+	// ParsedMetaEmailSubject 表示邮件主题元数据键。
+	ParsedMetaEmailSubject = parseddocument.MetaEmailSubject
+	// ParsedMetaEmailFrom 表示发件人元数据键。
+	ParsedMetaEmailFrom = parseddocument.MetaEmailFrom
+	// ParsedMetaEmailTo 表示收件人元数据键。
+	ParsedMetaEmailTo = parseddocument.MetaEmailTo
+	// ParsedMetaEmailCc 表示抄送元数据键。
+	ParsedMetaEmailCc = parseddocument.MetaEmailCc
+	// ParsedMetaEmailDate 表示邮件时间元数据键。
+	ParsedMetaEmailDate = parseddocument.MetaEmailDate
+	// ParsedMetaEmailMessageID 表示 Message-ID 元数据键。
+	ParsedMetaEmailMessageID = parseddocument.MetaEmailMessageID
+	// ParsedMetaEmailThreadID 表示会话 ID 元数据键。
+	ParsedMetaEmailThreadID = parseddocument.MetaEmailThreadID
+	// ParsedMetaEmailMessageCount 表示会话邮件数元数据键。
+	ParsedMetaEmailMessageCount = parseddocument.MetaEmailMessageCount
+	// ParsedMetaEmailAttachments 表示附件列表元数据键。
+	ParsedMetaEmailAttachments = parseddocument.MetaEmailAttachments
+	// ParsedMetaCodeLanguage 表示源代码语言元数据键。
+	ParsedMetaCodeLanguage = parseddocument.MetaCodeLanguage
+	// ParsedMetaCodeFilePath 表示源代码文件路径元数据键。
+	ParsedMetaCodeFilePath = parseddocument.MetaCodeFilePath
+	// ParsedMetaCodeSymbol 表示符号名元数据键。
+	ParsedMetaCodeSymbol = parseddocument.MetaCodeSymbol
+	// ParsedMetaCodeSymbolKind 表示符号类型元数据键。
+	ParsedMetaCodeSymbolKind = parseddocument.MetaCodeSymbolKind
+	// ParsedMetaCodeSectionPath 表示符号章节路径元数据键。
+	ParsedMetaCodeSectionPath = parseddocument.MetaCodeSectionPath
+	// ParsedMetaCodeStartLine 表示起始行号元数据键。
+	ParsedMetaCodeStartLine = parseddocument.MetaCodeStartLine
+	// ParsedMetaCodeEndLine 表示结束行号元数据键。
+	ParsedMetaCodeEndLine = parseddocument.MetaCodeEndLine
+	// ParsedMetaNotebookCellIndex 表示单元格序号元数据键。
+	ParsedMetaNotebookCellIndex = parseddocument.MetaNotebookCellIndex
+	// ParsedMetaNotebookCellType 表示单元格类型元数据键。
+	ParsedMetaNotebookCellType = parseddocument.MetaNotebookCellType
+	// ParsedMetaNotebookSection 表示单元格所属标题元数据键。
+	ParsedMetaNotebookSection = parseddocument.MetaNotebookSection
+	// ParsedMetaStartMS 表示媒体起始时间元数据键。
+	ParsedMetaStartMS = parseddocument.MetaStartMS
+	// ParsedMetaEndMS 表示媒体结束时间元数据键。
+	ParsedMetaEndMS = parseddocument.MetaEndMS
+	// ParsedMetaSubtitleCueCount 表示窗口内字幕条数元数据键。
+	ParsedMetaSubtitleCueCount = parseddocument.MetaSubtitleCueCount
 	// ParsedMetaEmbeddedImageCount 表示内嵌图片数量元数据键。
 	ParsedMetaEmbeddedImageCount = parseddocument.MetaEmbeddedImageCount
 	// ParsedMetaEmbeddedImageOCRSuccessCount 表示 OCR 成功数量元数据键。
@@ -182,6 +278,18 @@ type VisualTextExtractorPDFNativeBypassPolicy = documentservice.VisualTextExtrac
 // ProjectFileMetadataReader 复用项目文件元数据读取契约。
 type ProjectFileMetadataReader = documentservice.ProjectFileMetadataReader
 
+// GitFileSource 复用 Git 来源文档读取位置。
+type GitFileSource = documentservice.GitFileSource
+
+// GitFileReader 复用 Git 仓库文件读取契约。
+type GitFileReader = documentservice.GitFileReader
+
+// ObjectStorageSource 复用对象存储来源文档读取位置。
+type ObjectStorageSource = documentservice.ObjectStorageSource
+
+// ObjectStorageReader 复用对象存储对象读取契约。
+type ObjectStorageReader = documentservice.ObjectStorageReader
+
 // OCRResultCache 复用 OCR 缓存模型。
 type OCRResultCache = docentity.OCRResultCache
 
@@ -233,6 +341,8 @@ type Logger interface {
 var (
 	// ErrDocumentResourceLimitExceeded 表示文档同步命中资源限制。
 	ErrDocumentResourceLimitExceeded = documentservice.ErrDocumentResourceLimitExceeded
+	// ErrNoParserFound 表示没有解析器支持该文件类型。
+	ErrNoParserFound = documentservice.ErrNoParserFound
 	// ErrOCRDisabled 表示 OCR 能力关闭。
 	ErrOCRDisabled = documentservice.ErrOCRDisabled
 	// ErrOCRProviderNotFound 表示未找到启用的 OCR provider。
@@ -243,6 +353,8 @@ var (
 	ErrOCRCredentialsIncomplete = documentservice.ErrOCRCredentialsIncomplete
 	// ErrUnsupportedOCRFileType 表示 OCR 不支持当前文件类型。
 	ErrUnsupportedOCRFileType = documentservice.ErrUnsupportedOCRFileType
+	// ErrOCREndpointMissing 表示自部署 OCR 服务地址未配置。
+	ErrOCREndpointMissing = documentservice.ErrOCREndpointMissing
 	// ErrOCRCacheNotFound 表示 OCR 缓存未命中。
 	ErrOCRCacheNotFound = docrepo.ErrOCRCacheNotFound
 )
@@ -250,6 +362,8 @@ var (
 const (
 	// OCRProviderVolcengine 表示火山 OCR provider。
 	OCRProviderVolcengine = documentservice.OCRProviderVolcengine
+	// OCRProviderLocal 表示自部署 OCR provider。
+	OCRProviderLocal = documentservice.OCRProviderLocal
 	// AIAbilityCodeKnowledgeBaseVisualUnderstanding 表示知识库视觉理解能力配置。
 	AIAbilityCodeKnowledgeBaseVisualUnderstanding = documentservice.AIAbilityCodeKnowledgeBaseVisualUnderstanding
 	// DefaultModelTypeLLM 表示模型网关的默认 LLM 模型类型。
@@ -280,6 +394,11 @@ func DefaultParseOptions() ParseOptions {
 	return documentservice.DefaultParseOptions()
 }
 
+// NormalizeEmailGrouping 归一化邮件拆块粒度。
+func NormalizeEmailGrouping(grouping string) string {
+	return documentservice.NormalizeEmailGrouping(grouping)
+}
+
 // DefaultResourceLimits 返回默认文档同步资源限制。
 func DefaultResourceLimits() ResourceLimits {
 	return documentservice.DefaultResourceLimits()
@@ -449,6 +568,11 @@ func NewParseService(fileFetcher FileFetcher, parsers []Parser, logger Logger) *
 	return documentservice.NewParseService(fileFetcher, parsers, logger)
 }
 
+// NewParseServiceWithLimits 创建带资源限制的解析服务。
+func NewParseServiceWithLimits(fileFetcher FileFetcher, parsers []Parser, logger Logger, limits ResourceLimits) *ParseService {
+	return documentservice.NewParseServiceWithLimits(fileFetcher, parsers, logger, limits)
+}
+
 func normalizeDocumentFileType(v any) string {
 	switch value := v.(type) {
 	case string:
@@ -0,0 +1,248 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"magic/internal/pkg/filetype"
+)
+
+const (
+	// defaultArchiveCacheTTL 覆盖一批展开文档的同步周期，过期后重新下载。
+	defaultArchiveCacheTTL = 10 * time.Minute
+	// defaultArchiveCacheMaxBytes 限制缓存常驻内存，约可容纳两个满额解压的 tar 包。
+	defaultArchiveCacheMaxBytes int64 = 512 * 1024 * 1024
+)
+
+// archiveSnapshot 是一次下载得到的压缩包视图。
+//
+// zip 支持随机读取，只保留原始字节；tar 系只能顺序解压，一次遍历后保留可展开条目内容，
+// 避免每个展开文档重复下载并重复解压整个压缩流。
+type archiveSnapshot struct {
+	archiveType string
+	listing     *ArchiveListing
+	raw         []byte
+	contents    map[string][]byte
+	bytes       int64
+}
+
+type archiveCacheItem struct {
+	snapshot  *archiveSnapshot
+	expiresAt time.Time
+}
+
+// archiveSnapshotCache 按“压缩包地址 + 格式 + 源文件大小”缓存压缩包视图，并合并并发加载。
+//
+// 上传的压缩包对象 key 不复用，源文件大小用于识别同 key 覆盖写入；无法读取大小时仅靠 TTL 兜底。
+type archiveSnapshotCache struct {
+	mu       sync.Mutex
+	group    singleflight.Group
+	ttl      time.Duration
+	maxBytes int64
+	now      func() time.Time
+	items    map[string]archiveCacheItem
+	order    []string
+	bytes    int64
+}
+
+func newArchiveSnapshotCache(ttl time.Duration, maxBytes int64) *archiveSnapshotCache {
+	return &archiveSnapshotCache{
+		ttl:      ttl,
+		maxBytes: maxBytes,
+		now:      time.Now,
+		items:    make(map[string]archiveCacheItem),
+	}
+}
+
+func (c *archiveSnapshotCache) load(key string, loader func() (*archiveSnapshot, error)) (*archiveSnapshot, error) {
+	if c == nil {
+		return loader()
+	}
+	if snapshot, ok := c.get(key); ok {
+		return snapshot, nil
+	}
+	value, err, _ := c.group.Do(key, func() (any, error) {
+		if snapshot, ok := c.get(key); ok {
+			return snapshot, nil
+		}
+		snapshot, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		c.put(key, snapshot)
+		return snapshot, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load archive snapshot: %w", err)
+	}
+	snapshot, _ := value.(*archiveSnapshot)
+	return snapshot, nil
+}
+
+func (c *archiveSnapshotCache) get(key string) (*archiveSnapshot, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(item.expiresAt) {
+		c.removeLocked(key)
+		return nil, false
+	}
+	return item.snapshot, true
+}
+
+func (c *archiveSnapshotCache) put(key string, snapshot *archiveSnapshot) {
+	if snapshot == nil || snapshot.bytes > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeLocked(key)
+	now := c.now()
+	for _, existing := range append([]string(nil), c.order...) {
+		if !now.Before(c.items[existing].expiresAt) {
+			c.removeLocked(existing)
+		}
+	}
+	for len(c.order) > 0 && c.bytes+snapshot.bytes > c.maxBytes {
+		c.removeLocked(c.order[0])
+	}
+	c.items[key] = archiveCacheItem{snapshot: snapshot, expiresAt: now.Add(c.ttl)}
+	c.order = append(c.order, key)
+	c.bytes += snapshot.bytes
+}
+
+func (c *archiveSnapshotCache) removeLocked(key string) {
+	item, ok := c.items[key]
+	if !ok {
+		return
+	}
+	delete(c.items, key)
+	c.bytes -= item.snapshot.bytes
+	for i, existing := range c.order {
+		if existing == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// loadArchiveSnapshot 读取（或复用缓存的）压缩包视图。
+func (s *ParseService) loadArchiveSnapshot(ctx context.Context, archiveURL, archiveType string) (*archiveSnapshot, error) {
+	archiveURL = strings.TrimSpace(archiveURL)
+	if archiveURL == "" {
+		return nil, ErrArchiveEntrySourceRequired
+	}
+	archiveType = filetype.NormalizeExtension(archiveType)
+	version := "unknown"
+	if size, ok := s.trySourceSize(ctx, archiveURL); ok {
+		if err := CheckDocumentSourceSize(size, s.ResourceLimits()); err != nil {
+			return nil, err
+		}
+		version = strconv.FormatInt(size, 10)
+	}
+	key := strings.Join([]string{archiveURL, archiveType, version}, "\x00")
+	return s.archiveCache.load(key, func() (*archiveSnapshot, error) {
+		raw, err := s.fetchArchive(ctx, archiveURL)
+		if err != nil {
+			return nil, err
+		}
+		return buildArchiveSnapshot(raw, archiveType, s.ResourceLimits())
+	})
+}
+
+// buildArchiveSnapshot 遍历一次压缩包，生成条目清单；tar 系同时读出可展开条目的内容。
+func buildArchiveSnapshot(raw []byte, archiveType string, limits ResourceLimits) (*archiveSnapshot, error) {
+	snapshot := &archiveSnapshot{archiveType: archiveType, listing: &ArchiveListing{}}
+	keepContents := archiveType != archiveTypeZip
+	if keepContents {
+		snapshot.contents = make(map[string][]byte)
+	} else {
+		snapshot.raw = raw
+		snapshot.bytes = int64(len(raw))
+	}
+	entryLimit := NormalizeResourceLimits(limits).MaxArchiveEntryBytes
+	var total int64
+	err := walkArchive(raw, archiveType, func(header archiveEntryHeader, open func() (io.Reader, error)) error {
+		entry, ok := snapshot.listing.add(header, &total, limits)
+		if !ok || !keepContents {
+			return nil
+		}
+		reader, err := open()
+		if err != nil {
+			return fmt.Errorf("open archive entry %s: %w", entry.Path, err)
+		}
+		// 条目头声明的大小可能被篡改：按单条目限制多读一个字节留给解析时识别超限，并按实际字节核对总量。
+		content, err := io.ReadAll(io.LimitReader(reader, entryLimit+1))
+		if err != nil {
+			return fmt.Errorf("read archive entry %s: %w", entry.Path, err)
+		}
+		snapshot.bytes += int64(len(content))
+		if err := CheckArchiveUncompressedSize(snapshot.bytes, limits); err != nil {
+			return fmt.Errorf("check archive %s: %w", entry.Path, err)
+		}
+		snapshot.contents[entry.Path] = content
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// add 按展开规则登记一个条目头，返回条目是否可展开。
+func (l *ArchiveListing) add(header archiveEntryHeader, total *int64, limits ResourceLimits) (ArchiveEntry, bool) {
+	if !header.regular {
+		return ArchiveEntry{}, false
+	}
+	entryPath, ok := normalizeArchiveEntryPath(header.name)
+	if !ok {
+		l.Skipped = append(l.Skipped, ArchiveEntrySkip{Path: header.name, Size: header.size, Reason: ArchiveEntrySkipUnsafePath})
+		return ArchiveEntry{}, false
+	}
+	if isArchiveNoiseEntry(entryPath) {
+		return ArchiveEntry{}, false
+	}
+	if reason := archiveEntrySkipReason(entryPath, header.size, *total, limits); reason != "" {
+		l.Skipped = append(l.Skipped, ArchiveEntrySkip{Path: entryPath, Size: header.size, Reason: reason})
+		return ArchiveEntry{}, false
+	}
+	*total += header.size
+	entry := newArchiveEntry(entryPath, header.size)
+	l.Entries = append(l.Entries, entry)
+	return entry, true
+}
+
+// entryContent 返回条目内容：zip 按需随机读取，tar 系直接取遍历时保留的内容。
+func (a *archiveSnapshot) entryContent(entryPath string, limits ResourceLimits) ([]byte, error) {
+	if a.contents == nil {
+		return extractArchiveEntry(a.raw, a.archiveType, entryPath, limits)
+	}
+	content, ok := a.contents[entryPath]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrArchiveEntryNotFound, entryPath)
+	}
+	if err := CheckArchiveEntrySize(int64(len(content)), limits); err != nil {
+		return nil, fmt.Errorf("check archive entry %s: %w", entryPath, err)
+	}
+	return content, nil
+}
+
+func cloneArchiveListing(listing *ArchiveListing) *ArchiveListing {
+	if listing == nil {
+		return &ArchiveListing{}
+	}
+	return &ArchiveListing{
+		Entries: append([]ArchiveEntry(nil), listing.Entries...),
+		Skipped: append([]ArchiveEntrySkip(nil), listing.Skipped...),
+	}
+}
@@ -0,0 +1,380 @@
+package document
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+	parseddocument "magic/internal/domain/knowledge/shared/parseddocument"
+	"magic/internal/pkg/filetype"
+)
+
+const (
+	// DocMetaArchiveEntryPath 记录压缩包展开文档在包内的相对路径。
+	DocMetaArchiveEntryPath = "archive_entry_path"
+	// DocMetaArchiveType 记录压缩包展开文档所属压缩包的格式。
+	DocMetaArchiveType = "archive_type"
+)
+
+// 压缩包条目被跳过的原因。
+const (
+	ArchiveEntrySkipUnsafePath     = "unsafe_path"
+	ArchiveEntrySkipUnsupported    = "unsupported_extension"
+	ArchiveEntrySkipNestedArchive  = "nested_archive"
+	ArchiveEntrySkipTooLarge       = "entry_too_large"
+	ArchiveEntrySkipBudgetExceeded = "archive_budget_exceeded"
+	ArchiveEntrySkipDocumentLimit  = "document_limit_exceeded"
+)
+
+const (
+	archiveTypeZip                = "zip"
+	archiveTypeTar                = "tar"
+	archiveTypeTarGzip            = "tgz"
+	archiveTypeGzip               = "gz"
+	archiveMacOSMetadataDirectory = "__MACOSX"
+
+	// tar 头块中 ustar 魔数的位置（POSIX 与 GNU 格式均以 "ustar" 开头）。
+	tarHeaderBlockSize = 512
+	tarMagicOffset     = 257
+	tarMagic           = "ustar"
+)
+
+var (
+	// ErrUnsupportedArchiveType 表示压缩包格式不支持展开。
+	ErrUnsupportedArchiveType = errors.New("unsupported archive type")
+	// ErrArchiveEntryNotFound 表示压缩包中找不到指定条目。
+	ErrArchiveEntryNotFound = errors.New("archive entry not found")
+	// ErrArchiveEntrySourceRequired 表示压缩包来源缺失。
+	ErrArchiveEntrySourceRequired = errors.New("archive entry source is required")
+
+	errArchiveWalkStopped = errors.New("archive walk stopped")
+)
+
+// ArchiveEntry 描述压缩包内一个可展开为知识库文档的文件。
+type ArchiveEntry struct {
+	Path      string
+	Dir       string
+	Name      string
+	Extension string
+	Size      int64
+}
+
+// ArchiveEntrySkip 描述一个未展开的压缩包条目及原因。
+type ArchiveEntrySkip struct {
+	Path   string
+	Size   int64
+	Reason string
+}
+
+// ArchiveListing 是压缩包展开前的条目清单。
+type ArchiveListing struct {
+	Entries []ArchiveEntry
+	Skipped []ArchiveEntrySkip
+}
+
+// ArchiveEntrySource 描述一个压缩包展开文档的解析来源。
+type ArchiveEntrySource struct {
+	ArchiveURL  string
+	ArchiveType string
+	EntryPath   string
+}
+
+type archiveEntryHeader struct {
+	name    string
+	size    int64
+	regular bool
+}
+
+type archiveEntryVisitor func(header archiveEntryHeader, open func() (io.Reader, error)) error
+
+// IsArchiveFileType 判断扩展名是否为可展开的压缩包格式。
+func IsArchiveFileType(fileType string) bool {
+	switch filetype.NormalizeExtension(fileType) {
+	case archiveTypeZip, archiveTypeTar, archiveTypeTarGzip, archiveTypeGzip:
+		return true
+	default:
+		return false
+	}
+}
+
+// ResolveArchiveEntrySource 从文档元数据中识别压缩包展开文档。
+func ResolveArchiveEntrySource(doc *docentity.KnowledgeBaseDocument) (ArchiveEntrySource, bool) {
+	if doc == nil || doc.DocumentFile == nil || len(doc.DocMetadata) == 0 {
+		return ArchiveEntrySource{}, false
+	}
+	entryPath, _ := doc.DocMetadata[DocMetaArchiveEntryPath].(string)
+	archiveType, _ := doc.DocMetadata[DocMetaArchiveType].(string)
+	source := ArchiveEntrySource{
+		ArchiveURL:  strings.TrimSpace(doc.DocumentFile.URL),
+		ArchiveType: filetype.NormalizeExtension(archiveType),
+		EntryPath:   strings.TrimSpace(entryPath),
+	}
+	if source.ArchiveURL == "" || source.EntryPath == "" || !IsArchiveFileType(source.ArchiveType) {
+		return ArchiveEntrySource{}, false
+	}
+	return source, true
+}
+
+// ListArchiveEntries 下载压缩包并列出可展开的条目。
+//
+// 路径穿越、不支持的扩展名、嵌套压缩包以及超出单条目/总量限制的条目记录到 Skipped，由调用方决定如何呈现。
+// __MACOSX 与隐藏文件属于打包工具噪声，直接忽略。清单与条目内容一起进入压缩包缓存，
+// 同一批展开文档随后同步时无需重复下载与解压。
+func (s *ParseService) ListArchiveEntries(ctx context.Context, archiveURL, archiveType string) (*ArchiveListing, error) {
+	snapshot, err := s.loadArchiveSnapshot(ctx, archiveURL, archiveType)
+	if err != nil {
+		return nil, err
+	}
+	return cloneArchiveListing(snapshot.listing), nil
+}
+
+// ParseArchiveEntryWithOptions 从压缩包中解出单个条目并按其扩展名解析。
+func (s *ParseService) ParseArchiveEntryWithOptions(
+	ctx context.Context,
+	source ArchiveEntrySource,
+	options ParseOptions,
+) (*parseddocument.ParsedDocument, error) {
+	entryPath, ok := normalizeArchiveEntryPath(source.EntryPath)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrArchiveEntryNotFound, source.EntryPath)
+	}
+	normalizedFileType := filetype.NormalizeExtension(path.Ext(entryPath))
+	parser, err := s.resolveParser(normalizedFileType)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := s.loadArchiveSnapshot(ctx, source.ArchiveURL, source.ArchiveType)
+	if err != nil {
+		return nil, err
+	}
+	content, err := snapshot.entryContent(entryPath, s.ResourceLimits())
+	if err != nil {
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "Starting archive entry parsing", "url", source.ArchiveURL, "entry", entryPath, "type", normalizedFileType)
+	return s.parseWithReader(ctx, parser, entryPath, bytes.NewReader(content), normalizedFileType, options)
+}
+
+// fetchArchive 下载压缩包原始字节；来源地址与大小预检由 loadArchiveSnapshot 负责。
+func (s *ParseService) fetchArchive(ctx context.Context, archiveURL string) ([]byte, error) {
+	reader, err := s.fileFetcher.Fetch(ctx, archiveURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch archive: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+	source, err := io.ReadAll(NewSourceSizeLimitedReader(reader, s.ResourceLimits()))
+	if err != nil {
+		return nil, fmt.Errorf("read archive: %w", err)
+	}
+	return source, nil
+}
+
+func extractArchiveEntry(archive []byte, archiveType, entryPath string, limits ResourceLimits) ([]byte, error) {
+	var content []byte
+	err := walkArchive(archive, archiveType, func(header archiveEntryHeader, open func() (io.Reader, error)) error {
+		if !header.regular {
+			return nil
+		}
+		if normalized, ok := normalizeArchiveEntryPath(header.name); !ok || normalized != entryPath {
+			return nil
+		}
+		if err := CheckArchiveEntrySize(header.size, limits); err != nil {
+			return fmt.Errorf("check archive entry %s: %w", entryPath, err)
+		}
+		reader, err := open()
+		if err != nil {
+			return fmt.Errorf("open archive entry %s: %w", entryPath, err)
+		}
+		// 条目头声明的大小可能被篡改，按限制多读一个字节来识别实际超限。
+		content, err = io.ReadAll(io.LimitReader(reader, NormalizeResourceLimits(limits).MaxArchiveEntryBytes+1))
+		if err != nil {
+			return fmt.Errorf("read archive entry %s: %w", entryPath, err)
+		}
+		if err := CheckArchiveEntrySize(int64(len(content)), limits); err != nil {
+			return fmt.Errorf("check archive entry %s: %w", entryPath, err)
+		}
+		return errArchiveWalkStopped
+	})
+	if err != nil {
+		return nil, err
+	}
+	if content == nil {
+		return nil, fmt.Errorf("%w: %s", ErrArchiveEntryNotFound, entryPath)
+	}
+	return content, nil
+}
+
+func walkArchive(archive []byte, archiveType string, visit archiveEntryVisitor) error {
+	var err error
+	switch filetype.NormalizeExtension(archiveType) {
+	case archiveTypeZip:
+		err = walkZipArchive(archive, visit)
+	case archiveTypeTar:
+		err = walkTarArchive(bytes.NewReader(archive), visit)
+	case archiveTypeTarGzip, archiveTypeGzip:
+		gzipReader, gzipErr := gzip.NewReader(bytes.NewReader(archive))
+		if gzipErr != nil {
+			return fmt.Errorf("open gzip archive: %w", gzipErr)
+		}
+		defer func() { _ = gzipReader.Close() }()
+		tarReader, tarErr := requireTarStream(gzipReader)
+		if tarErr != nil {
+			return tarErr
+		}
+		err = walkTarArchive(tarReader, visit)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedArchiveType, archiveType)
+	}
+	if errors.Is(err, errArchiveWalkStopped) {
+		return nil
+	}
+	return err
+}
+
+// requireTarStream 确认 gzip 解压后是 tar 流；单文件 .gz 不是压缩包，无法展开为多个文档。
+func requireTarStream(source io.Reader) (io.Reader, error) {
+	reader := bufio.NewReaderSize(source, tarHeaderBlockSize)
+	header, err := reader.Peek(tarHeaderBlockSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("read gzip archive: %w", err)
+	}
+	if len(header) < tarMagicOffset+len(tarMagic) || string(header[tarMagicOffset:tarMagicOffset+len(tarMagic)]) != tarMagic {
+		return nil, fmt.Errorf("%w: gzip content is not a tar archive", ErrUnsupportedArchiveType)
+	}
+	return reader, nil
+}
+
+func walkZipArchive(archive []byte, visit archiveEntryVisitor) error {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return fmt.Errorf("open zip archive: %w", err)
+	}
+	for _, file := range reader.File {
+		header := archiveEntryHeader{
+			name:    decodeZipEntryName(file),
+			size:    clampArchiveSize(file.UncompressedSize64),
+			regular: file.Mode().IsRegular(),
+		}
+		var opened io.ReadCloser
+		err := visit(header, func() (io.Reader, error) {
+			rc, openErr := file.Open()
+			if openErr != nil {
+				return nil, fmt.Errorf("open zip entry: %w", openErr)
+			}
+			opened = rc
+			return rc, nil
+		})
+		if opened != nil {
+			_ = opened.Close()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkTarArchive(source io.Reader, visit archiveEntryVisitor) error {
+	reader := tar.NewReader(source)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read tar archive: %w", err)
+		}
+		entry := archiveEntryHeader{
+			name:    header.Name,
+			size:    header.Size,
+			regular: header.Typeflag == tar.TypeReg,
+		}
+		if err := visit(entry, func() (io.Reader, error) { return reader, nil }); err != nil {
+			return err
+		}
+	}
+}
+
+// decodeZipEntryName 兼容 Windows 中文环境打出的 GBK 文件名（未设置 UTF-8 标志位）。
+func decodeZipEntryName(file *zip.File) string {
+	if !file.NonUTF8 || utf8.ValidString(file.Name) {
+		return file.Name
+	}
+	decoded, err := simplifiedchinese.GB18030.NewDecoder().String(file.Name)
+	if err != nil {
+		return file.Name
+	}
+	return decoded
+}
+
+// normalizeArchiveEntryPath 规整条目路径，拒绝绝对路径与 ".." 穿越。
+func normalizeArchiveEntryPath(name string) (string, bool) {
+	name = strings.ReplaceAll(strings.TrimSpace(name), "\\", "/")
+	if name == "" || strings.HasPrefix(name, "/") || (len(name) > 1 && name[1] == ':') {
+		return "", false
+	}
+	cleaned := path.Clean(name)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", false
+	}
+	return cleaned, true
+}
+
+func isArchiveNoiseEntry(entryPath string) bool {
+	for segment := range strings.SplitSeq(entryPath, "/") {
+		if segment == archiveMacOSMetadataDirectory || strings.HasPrefix(segment, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+func archiveEntrySkipReason(entryPath string, size, total int64, limits ResourceLimits) string {
+	extension := filetype.NormalizeExtension(path.Ext(entryPath))
+	switch {
+	case IsArchiveFileType(extension):
+		return ArchiveEntrySkipNestedArchive
+	case !IsSupportedKnowledgeBaseFileExtension(extension):
+		return ArchiveEntrySkipUnsupported
+	case CheckArchiveEntrySize(size, limits) != nil:
+		return ArchiveEntrySkipTooLarge
+	case size > math.MaxInt64-total || CheckArchiveUncompressedSize(total+size, limits) != nil:
+		return ArchiveEntrySkipBudgetExceeded
+	default:
+		return ""
+	}
+}
+
+func newArchiveEntry(entryPath string, size int64) ArchiveEntry {
+	dir := path.Dir(entryPath)
+	if dir == "." {
+		dir = ""
+	}
+	return ArchiveEntry{
+		Path:      entryPath,
+		Dir:       dir,
+		Name:      path.Base(entryPath),
+		Extension: filetype.NormalizeExtension(path.Ext(entryPath)),
+		Size:      size,
+	}
+}
+
+func clampArchiveSize(size uint64) int64 {
+	if size > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(size)
+}
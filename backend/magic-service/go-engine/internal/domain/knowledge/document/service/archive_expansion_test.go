@@ -0,0 +1,241 @@
+package document_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+	documentdomain "magic/internal/domain/knowledge/document/service"
+	"magic/internal/infrastructure/logging"
+)
+
+func TestParseServiceListArchiveEntriesKeepsFolderTreeAndReportsSkips(t *testing.T) {
+	t.Parallel()
+
+	archive := buildZipArchiveForTest(t, []archiveTestFile{
+		{name: "docs/readme.md", content: "# Readme"},
+		{name: "docs/api/spec.txt", content: "spec body"},
+		{name: "docs/big.md", content: strings.Repeat("x", 64)},
+		{name: "../escape.md", content: "evil"},
+		{name: "__MACOSX/docs/._a.md", content: "noise"},
+		{name: "docs/.DS_Store", content: "noise"},
+		{name: "tools/setup.exe", content: "binary"},
+		{name: "bundle/nested.zip", content: "zip"},
+		{name: "docs/empty-folder/"},
+		{name: "notes/2024/summary.md", content: "summary"},
+	})
+	limits := documentdomain.DefaultResourceLimits()
+	limits.MaxArchiveEntryBytes = 32
+	svc := documentdomain.NewParseServiceWithLimits(archiveFetcherForTest(archive), nil, logging.New(), limits)
+
+	listing, err := svc.ListArchiveEntries(context.Background(), "uploads/batch.zip", "zip")
+	if err != nil {
+		t.Fatalf("list archive entries: %v", err)
+	}
+	entries := map[string]documentdomain.ArchiveEntry{}
+	for _, entry := range listing.Entries {
+		entries[entry.Path] = entry
+	}
+	if len(entries) != 3 {
+		t.Fatalf("unexpected entries: %#v", listing.Entries)
+	}
+	if spec := entries["docs/api/spec.txt"]; spec.Dir != "docs/api" || spec.Name != "spec.txt" || spec.Extension != "txt" || spec.Size != 9 {
+		t.Fatalf("unexpected nested entry: %#v", spec)
+	}
+	if readme := entries["docs/readme.md"]; readme.Dir != "docs" {
+		t.Fatalf("unexpected readme entry: %#v", readme)
+	}
+
+	reasons := map[string]string{}
+	for _, skipped := range listing.Skipped {
+		reasons[skipped.Path] = skipped.Reason
+	}
+	expected := map[string]string{
+		"docs/big.md":       documentdomain.ArchiveEntrySkipTooLarge,
+		"../escape.md":      documentdomain.ArchiveEntrySkipUnsafePath,
+		"tools/setup.exe":   documentdomain.ArchiveEntrySkipUnsupported,
+		"bundle/nested.zip": documentdomain.ArchiveEntrySkipNestedArchive,
+	}
+	if len(reasons) != len(expected) {
+		t.Fatalf("unexpected skipped entries: %#v", listing.Skipped)
+	}
+	for entryPath, reason := range expected {
+		if reasons[entryPath] != reason {
+			t.Fatalf("expected %s skipped with %s, got %#v", entryPath, reason, listing.Skipped)
+		}
+	}
+}
+
+func TestParseServiceParseArchiveEntryFromTarGzip(t *testing.T) {
+	t.Parallel()
+
+	archive := buildTarGzipArchiveForTest(t, []archiveTestFile{
+		{name: "./manual/intro.txt", content: "hello archive"},
+		{name: "manual/other.txt", content: "other"},
+	})
+	svc := documentdomain.NewParseService(archiveFetcherForTest(archive), []documentdomain.Parser{
+		&parseTestParser{supported: "txt"},
+	}, logging.New())
+
+	listing, err := svc.ListArchiveEntries(context.Background(), "uploads/manual.tgz", "tgz")
+	if err != nil {
+		t.Fatalf("list tar.gz entries: %v", err)
+	}
+	if len(listing.Entries) != 2 || listing.Entries[0].Path != "manual/intro.txt" {
+		t.Fatalf("unexpected tar.gz entries: %#v", listing.Entries)
+	}
+
+	doc := &docentity.KnowledgeBaseDocument{
+		DocumentFile: &docentity.File{URL: "uploads/manual.tgz", Extension: "txt"},
+		DocMetadata: map[string]any{
+			documentdomain.DocMetaArchiveEntryPath: "manual/intro.txt",
+			documentdomain.DocMetaArchiveType:      "tgz",
+		},
+	}
+	source, ok := documentdomain.ResolveArchiveEntrySource(doc)
+	if !ok {
+		t.Fatal("expected archive entry source")
+	}
+	parsed, err := svc.ParseArchiveEntryWithOptions(context.Background(), source, documentdomain.DefaultParseOptions())
+	if err != nil {
+		t.Fatalf("parse archive entry: %v", err)
+	}
+	if parsed.PlainText != "hello archive" {
+		t.Fatalf("unexpected parsed entry: %#v", parsed)
+	}
+
+	source.EntryPath = "manual/missing.txt"
+	if _, err := svc.ParseArchiveEntryWithOptions(context.Background(), source, documentdomain.DefaultParseOptions()); !errors.Is(err, documentdomain.ErrArchiveEntryNotFound) {
+		t.Fatalf("expected missing entry error, got %v", err)
+	}
+	if _, ok := documentdomain.ResolveArchiveEntrySource(&docentity.KnowledgeBaseDocument{DocumentFile: &docentity.File{URL: "a.txt"}}); ok {
+		t.Fatal("expected plain document not to resolve as archive entry")
+	}
+}
+
+func TestParseServiceArchiveFetchedOncePerBatch(t *testing.T) {
+	t.Parallel()
+
+	archive := buildTarGzipArchiveForTest(t, []archiveTestFile{
+		{name: "manual/intro.txt", content: "hello archive"},
+		{name: "manual/other.txt", content: "other"},
+	})
+	var fetches atomic.Int32
+	var archiveSize atomic.Int64
+	archiveSize.Store(int64(len(archive)))
+	fetcher := &parseTestFetcher{
+		fetchFn: func(context.Context, string) (io.ReadCloser, error) {
+			fetches.Add(1)
+			return io.NopCloser(bytes.NewReader(archive)), nil
+		},
+		fileSizeFn: func(context.Context, string) (int64, error) {
+			return archiveSize.Load(), nil
+		},
+	}
+	svc := documentdomain.NewParseService(fetcher, []documentdomain.Parser{&parseTestParser{supported: "txt"}}, logging.New())
+
+	if _, err := svc.ListArchiveEntries(context.Background(), "uploads/manual.tgz", "tgz"); err != nil {
+		t.Fatalf("list tar.gz entries: %v", err)
+	}
+	for _, entryPath := range []string{"manual/intro.txt", "manual/other.txt"} {
+		source := documentdomain.ArchiveEntrySource{ArchiveURL: "uploads/manual.tgz", ArchiveType: "tgz", EntryPath: entryPath}
+		if _, err := svc.ParseArchiveEntryWithOptions(context.Background(), source, documentdomain.DefaultParseOptions()); err != nil {
+			t.Fatalf("parse archive entry %s: %v", entryPath, err)
+		}
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Fatalf("expected archive to be fetched once for listing and all entries, got %d", got)
+	}
+
+	archiveSize.Add(1)
+	source := documentdomain.ArchiveEntrySource{ArchiveURL: "uploads/manual.tgz", ArchiveType: "tgz", EntryPath: "manual/intro.txt"}
+	if _, err := svc.ParseArchiveEntryWithOptions(context.Background(), source, documentdomain.DefaultParseOptions()); err != nil {
+		t.Fatalf("parse archive entry after overwrite: %v", err)
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Fatalf("expected changed source size to refetch archive, got %d fetches", got)
+	}
+}
+
+func TestParseServiceRejectsPlainGzipFile(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	if _, err := gzipWriter.Write([]byte("just a compressed text file")); err != nil {
+		t.Fatalf("write gzip content: %v", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatalf("close gzip writer: %v", err)
+	}
+	svc := documentdomain.NewParseService(archiveFetcherForTest(buffer.Bytes()), nil, logging.New())
+
+	if _, err := svc.ListArchiveEntries(context.Background(), "uploads/notes.txt.gz", "gz"); !errors.Is(err, documentdomain.ErrUnsupportedArchiveType) {
+		t.Fatalf("expected plain gzip file to be rejected as unsupported archive, got %v", err)
+	}
+}
+
+type archiveTestFile struct {
+	name    string
+	content string
+}
+
+func archiveFetcherForTest(archive []byte) *parseTestFetcher {
+	return &parseTestFetcher{
+		fetchFn: func(context.Context, string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(archive)), nil
+		},
+	}
+}
+
+func buildZipArchiveForTest(t *testing.T, files []archiveTestFile) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for _, file := range files {
+		entry, err := writer.Create(file.name)
+		if err != nil {
+			t.Fatalf("create zip entry %s: %v", file.name, err)
+		}
+		if _, err := entry.Write([]byte(file.content)); err != nil {
+			t.Fatalf("write zip entry %s: %v", file.name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close zip writer: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func buildTarGzipArchiveForTest(t *testing.T, files []archiveTestFile) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, file := range files {
+		header := &tar.Header{Name: file.name, Mode: 0o600, Size: int64(len(file.content)), Typeflag: tar.TypeReg}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatalf("write tar header %s: %v", file.name, err)
+		}
+		if _, err := tarWriter.Write([]byte(file.content)); err != nil {
+			t.Fatalf("write tar entry %s: %v", file.name, err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("close tar writer: %v", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatalf("close gzip writer: %v", err)
+	}
+	return buffer.Bytes()
+}
@@ -163,5 +163,9 @@ func cloneFragmentConfig(cfg *shared.FragmentConfig) *shared.FragmentConfig {
 		hierarchy.TextPreprocessRule = append([]int(nil), cfg.Hierarchy.TextPreprocessRule...)
 		clone.Hierarchy = &hierarchy
 	}
+	if cfg.AuxiliaryFragments != nil {
+		auxiliaryFragments := *cfg.AuxiliaryFragments
+		clone.AuxiliaryFragments = &auxiliaryFragments
+	}
 	return &clone
 }
@@ -0,0 +1,56 @@
+package document
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+)
+
+const (
+	// DocMetaGitPath 记录 Git 来源文档在仓库内的相对路径。
+	DocMetaGitPath = "git_path"
+	// DocMetaGitRef 记录 Git 来源文档跟踪的分支或标签，空值表示默认分支。
+	DocMetaGitRef = "git_ref"
+)
+
+// GitFileSource 描述一个 Git 来源文档的读取位置。
+type GitFileSource struct {
+	RepositoryURL string
+	Ref           string
+	Path          string
+}
+
+// GitFileReader 定义从仓库读取单个文件内容的能力。
+//
+// 读取的是 ref 最近一次拉取到的版本，重同步时无需更新文档元数据即可拿到新内容。
+type GitFileReader interface {
+	ReadGitFile(ctx context.Context, source GitFileSource) (io.ReadCloser, error)
+}
+
+// BuildGitFileDocMetadata 构造 Git 来源文档的元数据。
+func BuildGitFileDocMetadata(ref, filePath string) map[string]any {
+	return map[string]any{
+		DocMetaGitRef:  ref,
+		DocMetaGitPath: filePath,
+	}
+}
+
+// ResolveGitFileSource 从文档元数据中识别 Git 来源文档，仓库地址取自 document_file.url。
+func ResolveGitFileSource(doc *docentity.KnowledgeBaseDocument) (GitFileSource, bool) {
+	if doc == nil || doc.DocumentFile == nil || len(doc.DocMetadata) == 0 {
+		return GitFileSource{}, false
+	}
+	filePath, _ := doc.DocMetadata[DocMetaGitPath].(string)
+	ref, _ := doc.DocMetadata[DocMetaGitRef].(string)
+	source := GitFileSource{
+		RepositoryURL: strings.TrimSpace(doc.DocumentFile.URL),
+		Ref:           strings.TrimSpace(ref),
+		Path:          strings.TrimSpace(filePath),
+	}
+	if source.RepositoryURL == "" || source.Path == "" {
+		return GitFileSource{}, false
+	}
+	return source, true
+}
@@ -0,0 +1,32 @@
+package document_test
+
+import (
+	"testing"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+	documentdomain "magic/internal/domain/knowledge/document/service"
+)
+
+func TestResolveGitFileSourceAndSkipsURLPrecheck(t *testing.T) {
+	t.Parallel()
+
+	doc := &docentity.KnowledgeBaseDocument{
+		DocumentFile: &docentity.File{URL: "https://git.example.com/team/docs", Extension: "md"},
+		DocMetadata:  documentdomain.BuildGitFileDocMetadata("main", "docs/intro.md"),
+	}
+	source, ok := documentdomain.ResolveGitFileSource(doc)
+	if !ok || source.RepositoryURL != "https://git.example.com/team/docs" || source.Ref != "main" || source.Path != "docs/intro.md" {
+		t.Fatalf("unexpected git file source: %#v ok=%v", source, ok)
+	}
+	if decision := documentdomain.ResolveSourcePreflightPolicy(doc, nil, false); !decision.SkipValidation {
+		t.Fatalf("expected git document to skip url precheck, got %#v", decision)
+	}
+
+	plain := &docentity.KnowledgeBaseDocument{DocumentFile: &docentity.File{URL: "uploads/a.md"}}
+	if _, ok := documentdomain.ResolveGitFileSource(plain); ok {
+		t.Fatal("expected plain document not to resolve as git file")
+	}
+	if decision := documentdomain.ResolveSourcePreflightPolicy(plain, nil, false); !decision.RequireURLSource {
+		t.Fatalf("expected plain document to require url precheck, got %#v", decision)
+	}
+}
@@ -0,0 +1,57 @@
+package document
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+)
+
+const (
+	// DocMetaObjectStorageBucket 记录对象存储来源文档所在的桶。
+	DocMetaObjectStorageBucket = "object_storage_bucket"
+	// DocMetaObjectStorageKey 记录对象存储来源文档的对象键。
+	DocMetaObjectStorageKey = "object_storage_key"
+)
+
+// ObjectStorageSource 描述一个对象存储来源文档的读取位置。
+type ObjectStorageSource struct {
+	Bucket string
+	Key    string
+}
+
+// ObjectStorageReader 定义从 S3 兼容存储读取单个对象内容的能力。
+type ObjectStorageReader interface {
+	ReadObject(ctx context.Context, source ObjectStorageSource) (io.ReadCloser, error)
+}
+
+// BuildObjectStorageDocMetadata 构造对象存储来源文档的元数据。
+func BuildObjectStorageDocMetadata(bucket, key string) map[string]any {
+	return map[string]any{
+		DocMetaObjectStorageBucket: bucket,
+		DocMetaObjectStorageKey:    key,
+	}
+}
+
+// ObjectStorageDocumentURL 返回对象在 document_file.url 中的展示地址，形如 "s3://bucket/key"。
+func ObjectStorageDocumentURL(bucket, key string) string {
+	return "s3://" + bucket + "/" + key
+}
+
+// ResolveObjectStorageSource 从文档元数据中识别对象存储来源文档。
+func ResolveObjectStorageSource(doc *docentity.KnowledgeBaseDocument) (ObjectStorageSource, bool) {
+	if doc == nil || doc.DocumentFile == nil || len(doc.DocMetadata) == 0 {
+		return ObjectStorageSource{}, false
+	}
+	bucket, _ := doc.DocMetadata[DocMetaObjectStorageBucket].(string)
+	key, _ := doc.DocMetadata[DocMetaObjectStorageKey].(string)
+	source := ObjectStorageSource{
+		Bucket: strings.TrimSpace(bucket),
+		Key:    strings.TrimSpace(key),
+	}
+	if source.Bucket == "" || source.Key == "" {
+		return ObjectStorageSource{}, false
+	}
+	return source, true
+}
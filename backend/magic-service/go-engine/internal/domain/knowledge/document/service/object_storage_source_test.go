@@ -0,0 +1,32 @@
+package document_test
+
+import (
+	"testing"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+	documentdomain "magic/internal/domain/knowledge/document/service"
+)
+
+func TestResolveObjectStorageSourceAndSkipsURLPrecheck(t *testing.T) {
+	t.Parallel()
+
+	doc := &docentity.KnowledgeBaseDocument{
+		DocumentFile: &docentity.File{URL: documentdomain.ObjectStorageDocumentURL("reports", "2026/q1.csv"), Extension: "csv"},
+		DocMetadata:  documentdomain.BuildObjectStorageDocMetadata("reports", "2026/q1.csv"),
+	}
+	if doc.DocumentFile.URL != "s3://reports/2026/q1.csv" {
+		t.Fatalf("unexpected object storage url %q", doc.DocumentFile.URL)
+	}
+	source, ok := documentdomain.ResolveObjectStorageSource(doc)
+	if !ok || source.Bucket != "reports" || source.Key != "2026/q1.csv" {
+		t.Fatalf("unexpected object storage source: %#v ok=%v", source, ok)
+	}
+	if decision := documentdomain.ResolveSourcePreflightPolicy(doc, nil, false); !decision.SkipValidation {
+		t.Fatalf("expected object storage document to skip url precheck, got %#v", decision)
+	}
+
+	doc.DocMetadata = map[string]any{documentdomain.DocMetaObjectStorageBucket: "reports"}
+	if _, ok := documentdomain.ResolveObjectStorageSource(doc); ok {
+		t.Fatal("expected document without object key not to resolve as object storage source")
+	}
+}
@@ -22,11 +22,15 @@ var (
 	ErrOCRCredentialsIncomplete = errors.New("ocr provider credentials are incomplete")
 	// ErrUnsupportedOCRFileType 表示 OCR 不支持当前文件类型。
 	ErrUnsupportedOCRFileType = errors.New("ocr file type is not supported")
+	// ErrOCREndpointMissing 表示自部署 OCR 服务地址未配置。
+	ErrOCREndpointMissing = errors.New("ocr provider endpoint is not configured")
 )
 
 const (
-	// OCRProviderVolcengine 表示当前支持的火山 OCR provider。
+	// OCRProviderVolcengine 表示火山 OCR provider。
 	OCRProviderVolcengine = "Volcengine"
+	// OCRProviderLocal 表示自部署 OCR HTTP 服务 provider，适用于离线部署。
+	OCRProviderLocal = "Local"
 )
 
 // OCRConfigProviderPort 定义 OCR 配置真值获取能力。
@@ -42,9 +46,12 @@ type OCRAbilityConfig struct {
 }
 
 // OCRProviderConfig 描述单个 OCR provider 配置。
+//
+// Endpoint 仅自部署 provider 使用，为空时回退到 Go 本地配置的默认地址。
 type OCRProviderConfig struct {
 	Provider  string `json:"provider"`
 	Enable    bool   `json:"enable"`
+	Endpoint  string `json:"endpoint,omitempty"`
 	AccessKey string `json:"-"`
 	SecretKey string `json:"-"`
 }
@@ -100,10 +107,12 @@ func (c OCRProviderConfig) MarshalJSON() ([]byte, error) {
 	type safeOCRProviderConfig struct {
 		Provider string `json:"provider"`
 		Enable   bool   `json:"enable"`
+		Endpoint string `json:"endpoint,omitempty"`
 	}
 	payload, err := json.Marshal(safeOCRProviderConfig{
 		Provider: c.Provider,
 		Enable:   c.Enable,
+		Endpoint: c.Endpoint,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("marshal ocr provider config: %w", err)
@@ -116,6 +125,7 @@ func (c *OCRProviderConfig) UnmarshalJSON(data []byte) error {
 	type wireOCRProviderConfig struct {
 		Provider  string `json:"provider"`
 		Enable    bool   `json:"enable"`
+		Endpoint  string `json:"endpoint"`
 		AccessKey string `json:"access_key"`
 		SecretKey string `json:"secret_key"`
 	}
@@ -125,6 +135,7 @@ func (c *OCRProviderConfig) UnmarshalJSON(data []byte) error {
 	}
 	c.Provider = decoded.Provider
 	c.Enable = decoded.Enable
+	c.Endpoint = decoded.Endpoint
 	c.AccessKey = decoded.AccessKey
 	c.SecretKey = decoded.SecretKey
 	return nil
@@ -174,10 +185,12 @@ func IsOCROverloaded(err error) bool {
 	return errors.As(err, &overload)
 }
 
-// ResolveVolcengineConfig 校验 OCR 能力并提取火山 OCR 配置。
-func (c *OCRAbilityConfig) ResolveVolcengineConfig() (*shared.OCRConfig, string, error) {
+// ResolveProviderCode 校验 OCR 能力并返回当前组织生效的 provider 编码。
+//
+// 配置的 provider_code 未启用时回退到第一个启用的 provider。
+func (c *OCRAbilityConfig) ResolveProviderCode() (string, error) {
 	if c == nil || !c.Enabled {
-		return nil, "", ErrOCRDisabled
+		return "", ErrOCRDisabled
 	}
 	providerCode := strings.TrimSpace(c.ProviderCode)
 	if !hasEnabledOCRProvider(c.Providers, providerCode) {
@@ -185,28 +198,60 @@ func (c *OCRAbilityConfig) ResolveVolcengineConfig() (*shared.OCRConfig, string,
 			providerCode = fallback
 		}
 	}
-	if !strings.EqualFold(providerCode, OCRProviderVolcengine) {
-		return nil, "", ErrOCRProviderUnsupported
+	if providerCode == "" {
+		return "", ErrOCRProviderNotFound
 	}
+	return providerCode, nil
+}
 
-	for _, provider := range c.Providers {
-		if !provider.Enable || !strings.EqualFold(strings.TrimSpace(provider.Provider), OCRProviderVolcengine) {
-			continue
-		}
-
-		accessKey := strings.TrimSpace(provider.AccessKey)
-		secretKey := strings.TrimSpace(provider.SecretKey)
-		if accessKey == "" || secretKey == "" {
-			return nil, "", ErrOCRCredentialsIncomplete
-		}
+// ResolveVolcengineConfig 校验 OCR 能力并提取火山 OCR 配置。
+func (c *OCRAbilityConfig) ResolveVolcengineConfig() (*shared.OCRConfig, string, error) {
+	provider, err := c.resolveEnabledProvider(OCRProviderVolcengine)
+	if err != nil {
+		return nil, "", err
+	}
+	accessKey := strings.TrimSpace(provider.AccessKey)
+	secretKey := strings.TrimSpace(provider.SecretKey)
+	if accessKey == "" || secretKey == "" {
+		return nil, "", ErrOCRCredentialsIncomplete
+	}
+	return &shared.OCRConfig{
+		Identity:  accessKey,
+		Signature: secretKey,
+	}, OCRProviderVolcengine, nil
+}
 
-		return &shared.OCRConfig{
-			Identity:  accessKey,
-			Signature: secretKey,
-		}, OCRProviderVolcengine, nil
+// ResolveLocalConfig 校验 OCR 能力并提取自部署 OCR 配置。
+//
+// 自部署服务凭证可选：access_key 作为 Bearer token 透传，endpoint 为空时由调用方回退默认地址。
+func (c *OCRAbilityConfig) ResolveLocalConfig() (*shared.OCRConfig, string, error) {
+	provider, err := c.resolveEnabledProvider(OCRProviderLocal)
+	if err != nil {
+		return nil, "", err
 	}
+	return &shared.OCRConfig{
+		Identity: strings.TrimSpace(provider.AccessKey),
+		Endpoint: strings.TrimSpace(provider.Endpoint),
+	}, OCRProviderLocal, nil
+}
 
-	return nil, "", ErrOCRProviderNotFound
+func (c *OCRAbilityConfig) resolveEnabledProvider(expected string) (OCRProviderConfig, error) {
+	providerCode, err := c.ResolveProviderCode()
+	if errors.Is(err, ErrOCRProviderNotFound) {
+		return OCRProviderConfig{}, ErrOCRProviderUnsupported
+	}
+	if err != nil {
+		return OCRProviderConfig{}, err
+	}
+	if !strings.EqualFold(providerCode, expected) {
+		return OCRProviderConfig{}, ErrOCRProviderUnsupported
+	}
+	for _, provider := range c.Providers {
+		if provider.Enable && strings.EqualFold(strings.TrimSpace(provider.Provider), expected) {
+			return provider, nil
+		}
+	}
+	return OCRProviderConfig{}, ErrOCRProviderNotFound
 }
 
 func hasEnabledOCRProvider(providers []OCRProviderConfig, providerCode string) bool {
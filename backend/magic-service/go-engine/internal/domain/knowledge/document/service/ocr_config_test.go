@@ -123,6 +123,37 @@ func TestOCRAbilityConfigResolveVolcengineConfig_Errors(t *testing.T) {
 	}
 }
 
+func TestOCRAbilityConfigResolveLocalConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg := &documentdomain.OCRAbilityConfig{
+		Enabled:      true,
+		ProviderCode: "local",
+		Providers: []documentdomain.OCRProviderConfig{
+			{Provider: documentdomain.OCRProviderVolcengine, Enable: true, AccessKey: "ak", SecretKey: "sk"},
+			{Provider: documentdomain.OCRProviderLocal, Enable: true, Endpoint: " http://ocr.internal:8866 ", AccessKey: "token"},
+		},
+	}
+	providerCode, err := cfg.ResolveProviderCode()
+	if err != nil || providerCode != "local" {
+		t.Fatalf("unexpected provider code %q err=%v", providerCode, err)
+	}
+	ocrConfig, provider, err := cfg.ResolveLocalConfig()
+	if err != nil {
+		t.Fatalf("ResolveLocalConfig returned error: %v", err)
+	}
+	if provider != documentdomain.OCRProviderLocal || ocrConfig.Endpoint != "http://ocr.internal:8866" || ocrConfig.Identity != "token" {
+		t.Fatalf("unexpected local config: provider=%q endpoint=%q", provider, ocrConfig.Endpoint)
+	}
+	if _, _, err := cfg.ResolveVolcengineConfig(); !errors.Is(err, documentdomain.ErrOCRProviderUnsupported) {
+		t.Fatalf("expected volcengine to be unsupported when local is selected, got %v", err)
+	}
+	empty := &documentdomain.OCRAbilityConfig{Enabled: true}
+	if _, _, err := empty.ResolveLocalConfig(); !errors.Is(err, documentdomain.ErrOCRProviderUnsupported) {
+		t.Fatalf("expected unsupported without providers, got %v", err)
+	}
+}
+
 func TestOCRAbilityConfigSerializationDoesNotExposeCredentials(t *testing.T) {
 	t.Parallel()
 
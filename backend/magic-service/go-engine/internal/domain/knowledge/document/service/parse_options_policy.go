@@ -14,6 +14,11 @@ const (
 	ParsingTypeQuick = 0
 	// ParsingTypePrecise 表示精细解析。
 	ParsingTypePrecise = 1
+
+	// EmailGroupingMessage 表示邮件按单封拆块。
+	EmailGroupingMessage = "message"
+	// EmailGroupingThread 表示邮件按会话合并拆块。
+	EmailGroupingThread = "thread"
 )
 
 // ParseOptions 表示文档解析链路需要的选项。
@@ -22,6 +27,7 @@ type ParseOptions struct {
 	ImageExtraction bool
 	TableExtraction bool
 	ImageOCR        bool
+	EmailGrouping   string
 }
 
 // DefaultParseOptions 返回保持现有行为的默认解析选项。
@@ -31,6 +37,7 @@ func DefaultParseOptions() ParseOptions {
 		ImageExtraction: true,
 		TableExtraction: true,
 		ImageOCR:        true,
+		EmailGrouping:   EmailGroupingMessage,
 	}
 }
 
@@ -72,6 +79,7 @@ func ResolveDocumentParseOptionsFromMetadata(metadata map[string]any) ParseOptio
 	if hasParsingType {
 		options.ParsingType = normalizeParsingType(parsingType)
 	}
+	options.EmailGrouping = NormalizeEmailGrouping(strategyString(strategy, "email_grouping"))
 
 	if options.ParsingType == ParsingTypeQuick {
 		options.ImageExtraction = false
@@ -86,6 +94,14 @@ func ResolveDocumentParseOptionsFromMetadata(metadata map[string]any) ParseOptio
 	return options
 }
 
+// NormalizeEmailGrouping 规整邮件拆块粒度，未知值回退为按单封邮件。
+func NormalizeEmailGrouping(value string) string {
+	if strings.EqualFold(strings.TrimSpace(value), EmailGroupingThread) {
+		return EmailGroupingThread
+	}
+	return EmailGroupingMessage
+}
+
 func normalizeParsingType(value int) int {
 	if value == ParsingTypeQuick {
 		return ParsingTypeQuick
@@ -106,12 +122,17 @@ func BuildParseStrategyConfigValue(options ParseOptions) map[string]any {
 		normalized.TableExtraction = options.TableExtraction
 		normalized.ImageOCR = options.ImageOCR
 	}
-	return map[string]any{
+	value := map[string]any{
 		"parsing_type":     normalized.ParsingType,
 		"image_extraction": normalized.ImageExtraction,
 		"table_extraction": normalized.TableExtraction,
 		"image_ocr":        normalized.ImageOCR,
 	}
+	// 只有显式选择按会话拆块时才落库，避免给所有文档的 strategy_config 增加无意义字段。
+	if NormalizeEmailGrouping(options.EmailGrouping) == EmailGroupingThread {
+		value["email_grouping"] = EmailGroupingThread
+	}
+	return value
 }
 
 func strategyBoolWithAliases(strategy map[string]any, defaultValue bool, keys ...string) bool {
@@ -28,6 +28,7 @@ type ParseService struct {
 	parsers        []Parser
 	logger         parseLogger
 	resourceLimits ResourceLimits
+	archiveCache   *archiveSnapshotCache
 }
 
 type parseLogger interface {
@@ -65,6 +66,7 @@ func NewParseServiceWithLimits(
 		parsers:        parsers,
 		logger:         logger,
 		resourceLimits: NormalizeResourceLimits(resourceLimits),
+		archiveCache:   newArchiveSnapshotCache(defaultArchiveCacheTTL, defaultArchiveCacheMaxBytes),
 	}
 }
 
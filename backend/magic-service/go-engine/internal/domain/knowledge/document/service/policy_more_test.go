@@ -153,7 +153,7 @@ func TestKnowledgeBaseFileSupportPolicies(t *testing.T) {
 	}
 	supportedExts := document.SupportedKnowledgeBaseFileExtensions()
 	if !slices.Contains(supportedExts, "docx") || !slices.Contains(supportedExts, "doc") ||
-		!slices.Contains(supportedExts, "xls") || slices.Contains(supportedExts, "js") {
+		!slices.Contains(supportedExts, "xls") || !slices.Contains(supportedExts, "go") || slices.Contains(supportedExts, "svg") {
 		t.Fatalf("unexpected supported extensions: %#v", supportedExts)
 	}
 	if err := document.ValidateKnowledgeBaseDocumentFileSupport(&docentity.File{Name: "demo.docx"}); err != nil {
@@ -164,11 +164,11 @@ func TestKnowledgeBaseFileSupportPolicies(t *testing.T) {
 	}
 	normalizedUnsupported := document.NormalizeKnowledgeBaseProjectFileMeta(&projectfile.Meta{
 		Status:        projectfile.ResolveStatusActive,
-		FileName:      "demo.js",
-		FileExtension: "js",
+		FileName:      "demo.svg",
+		FileExtension: "svg",
 	})
 	if normalizedUnsupported == nil || normalizedUnsupported.Status != projectfile.ResolveStatusUnsupported {
-		t.Fatalf("expected js project file to normalize as unsupported, got %#v", normalizedUnsupported)
+		t.Fatalf("expected svg project file to normalize as unsupported, got %#v", normalizedUnsupported)
 	}
 	normalizedSupported := document.NormalizeKnowledgeBaseProjectFileMeta(&projectfile.Meta{
 		Status:        projectfile.ResolveStatusActive,
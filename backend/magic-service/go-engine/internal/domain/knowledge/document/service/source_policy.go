@@ -318,6 +318,14 @@ func ResolveSourcePreflightPolicy(
 	if ShouldResolveThirdPlatformDocument(doc) && hasThirdPlatformResolver {
 		return SourcePreflightDecision{SkipValidation: true}
 	}
+	// Git 来源的 document_file.url 是仓库地址，文件可读性留给解析阶段从仓库读取时确认。
+	if _, ok := ResolveGitFileSource(doc); ok {
+		return SourcePreflightDecision{SkipValidation: true}
+	}
+	// 对象存储来源同理：s3:// 地址只用于展示，内容在解析阶段通过对象存储客户端读取。
+	if _, ok := ResolveObjectStorageSource(doc); ok {
+		return SourcePreflightDecision{SkipValidation: true}
+	}
 	return SourcePreflightDecision{RequireURLSource: true}
 }
 
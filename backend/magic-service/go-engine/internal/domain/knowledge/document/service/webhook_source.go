@@ -0,0 +1,57 @@
+package document
+
+import (
+	"strings"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+)
+
+const (
+	// DocMetaWebhookChannel 记录推送来源文档所属的 webhook 通道。
+	DocMetaWebhookChannel = "webhook_channel"
+	// DocMetaWebhookExternalID 记录推送来源文档在外部系统中的条目 ID。
+	DocMetaWebhookExternalID = "webhook_external_id"
+
+	// WebhookSourceOverrideSource 标记由 webhook 内联正文注入的 source override。
+	WebhookSourceOverrideSource = "webhook"
+)
+
+// WebhookDocumentSource 描述推送来源文档对应的外部条目。
+type WebhookDocumentSource struct {
+	Channel    string
+	ExternalID string
+}
+
+// BuildWebhookDocMetadata 构造推送来源文档的元数据。
+func BuildWebhookDocMetadata(channel, externalID string) map[string]any {
+	return map[string]any{
+		DocMetaWebhookChannel:    channel,
+		DocMetaWebhookExternalID: externalID,
+	}
+}
+
+// BuildWebhookSourceOverride 把推送的内联正文包装成同步时注入的 source override。
+func BuildWebhookSourceOverride(content, contentHash string) *SourceOverride {
+	return &SourceOverride{
+		Content:     content,
+		Source:      WebhookSourceOverrideSource,
+		ContentHash: contentHash,
+	}
+}
+
+// ResolveWebhookDocumentSource 从文档元数据中识别推送来源文档。
+func ResolveWebhookDocumentSource(doc *docentity.KnowledgeBaseDocument) (WebhookDocumentSource, bool) {
+	if doc == nil || doc.SourceBindingID <= 0 || len(doc.DocMetadata) == 0 {
+		return WebhookDocumentSource{}, false
+	}
+	channel, _ := doc.DocMetadata[DocMetaWebhookChannel].(string)
+	externalID, _ := doc.DocMetadata[DocMetaWebhookExternalID].(string)
+	source := WebhookDocumentSource{
+		Channel:    strings.TrimSpace(channel),
+		ExternalID: strings.TrimSpace(externalID),
+	}
+	if source.Channel == "" || source.ExternalID == "" {
+		return WebhookDocumentSource{}, false
+	}
+	return source, true
+}
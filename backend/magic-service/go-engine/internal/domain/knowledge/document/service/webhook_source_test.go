@@ -0,0 +1,31 @@
+package document_test
+
+import (
+	"testing"
+
+	docentity "magic/internal/domain/knowledge/document/entity"
+	documentdomain "magic/internal/domain/knowledge/document/service"
+)
+
+func TestResolveWebhookDocumentSourceRequiresBindingAndExternalID(t *testing.T) {
+	t.Parallel()
+
+	doc := &docentity.KnowledgeBaseDocument{
+		SourceBindingID: 9,
+		DocMetadata:     documentdomain.BuildWebhookDocMetadata("cms", "article-1"),
+	}
+	source, ok := documentdomain.ResolveWebhookDocumentSource(doc)
+	if !ok || source.Channel != "cms" || source.ExternalID != "article-1" {
+		t.Fatalf("unexpected webhook source: %#v ok=%v", source, ok)
+	}
+
+	doc.SourceBindingID = 0
+	if _, ok := documentdomain.ResolveWebhookDocumentSource(doc); ok {
+		t.Fatal("expected document without source binding not to resolve as webhook source")
+	}
+
+	override := documentdomain.BuildWebhookSourceOverride("# Refund policy", "h1")
+	if decision := documentdomain.ResolveSourcePreflightPolicy(&docentity.KnowledgeBaseDocument{}, override, false); !decision.SkipValidation {
+		t.Fatalf("expected inline webhook content to skip url precheck, got %#v", decision)
+	}
+}
@@ -0,0 +1,93 @@
+package splitter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	parseddocument "magic/internal/domain/knowledge/shared/parseddocument"
+)
+
+const (
+	splitVersionGoNotebookV1 = "go_split_v1_notebook"
+	splitVersionGoSubtitleV1 = "go_split_v1_subtitle"
+	splitModeNotebookCell    = "notebook_cell"
+	splitModeSubtitleWindow  = "subtitle_window"
+)
+
+// blockChunkSection 描述一个块切出的所有子片共享的章节信息。
+type blockChunkSection struct {
+	path  string
+	title string
+	mode  string
+}
+
+// splitBlocksIndependently 让每个块独立按 token 切分且子片不跨块，
+// 块 metadata（单元格序号、时间戳等）原样复制到每个子片，保证命中后能定位回源位置。
+func splitBlocksIndependently(
+	ctx context.Context,
+	input parsedDocumentChunkInput,
+	resolveSection func(block parseddocument.ParsedBlock) blockChunkSection,
+) ([]tokenChunk, error) {
+	chunks := make([]tokenChunk, 0, len(input.Parsed.Blocks))
+	for _, block := range input.Parsed.Blocks {
+		if strings.TrimSpace(block.Content) == "" {
+			continue
+		}
+		if err := ensureChunkLimitHasRoom(input.MaxChunks, len(chunks)); err != nil {
+			return nil, err
+		}
+		segmentConfig := input.SegmentConfig
+		segmentConfig.MaxChunks = remainingChunkLimit(input.MaxChunks, len(chunks))
+		bodyChunks, err := splitContentByTokenPipeline(ctx, block.Content, segmentConfig, input.Model, input.TokenizerService, input.Logger)
+		if err != nil {
+			return nil, err
+		}
+		section := resolveSection(block)
+		for index, body := range bodyChunks {
+			content := strings.TrimSpace(body.Content)
+			if err := appendTokenChunkWithLimit(&chunks, tokenChunk{
+				Content:            content,
+				TokenCount:         countTextTokens(content, input.Model, input.TokenizerService),
+				SectionPath:        section.path,
+				SectionLevel:       1,
+				SectionTitle:       section.title,
+				SectionChunkIndex:  index,
+				EffectiveSplitMode: section.mode,
+				Metadata:           cloneChunkMetadata(block.Metadata),
+			}, input.MaxChunks); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return chunks, nil
+}
+
+// splitNotebookBlocks 每个单元格独立成片，章节取单元格之前最近的 Markdown 标题。
+func splitNotebookBlocks(ctx context.Context, input parsedDocumentChunkInput) ([]tokenChunk, error) {
+	return splitBlocksIndependently(ctx, input, func(block parseddocument.ParsedBlock) blockChunkSection {
+		section := metadataString(block.Metadata, parseddocument.MetaNotebookSection)
+		return blockChunkSection{path: section, title: section, mode: splitModeNotebookCell}
+	})
+}
+
+// splitSubtitleBlocks 每个字幕时间窗口独立成片，章节标题为窗口时间范围。
+func splitSubtitleBlocks(ctx context.Context, input parsedDocumentChunkInput) ([]tokenChunk, error) {
+	return splitBlocksIndependently(ctx, input, func(block parseddocument.ParsedBlock) blockChunkSection {
+		title := formatMediaTimestamp(metadataInt(block.Metadata, parseddocument.MetaStartMS)) +
+			" - " + formatMediaTimestamp(metadataInt(block.Metadata, parseddocument.MetaEndMS))
+		return blockChunkSection{path: title, title: title, mode: splitModeSubtitleWindow}
+	})
+}
+
+// formatMediaTimestamp 把毫秒格式化为 HH:MM:SS。
+func formatMediaTimestamp(ms int) string {
+	const (
+		msPerSecond    = 1000
+		secondsPerMin  = 60
+		secondsPerHour = 3600
+		minutesPerHour = 60
+	)
+	seconds := max(ms, 0) / msPerSecond
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/secondsPerHour, seconds/secondsPerMin%minutesPerHour, seconds%secondsPerMin)
+}
@@ -0,0 +1,133 @@
+package splitter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	parseddocument "magic/internal/domain/knowledge/shared/parseddocument"
+)
+
+const (
+	splitVersionGoCodeV1 = "go_split_v1_code"
+	splitModeCodeSymbol  = "code_symbol"
+	// codeChunkMinBodyTokens 保证文件路径前缀很长时代码切片仍有可用空间。
+	codeChunkMinBodyTokens = 200
+)
+
+// codeLineWindow 是符号块内按行打包出的一段代码，offset 为块内 0 起始行号。
+type codeLineWindow struct {
+	content     string
+	startOffset int
+	endOffset   int
+}
+
+// splitCodeBlocks 按符号块独立切片：短符号整块成片，长符号按整行打包，
+// 保证切分点不落在行中间，并在块连续时为每个子片重算起止行号。
+func splitCodeBlocks(ctx context.Context, input parsedDocumentChunkInput) ([]tokenChunk, error) {
+	chunks := make([]tokenChunk, 0, len(input.Parsed.Blocks))
+	limit := normalizeSegmentChunkSize(input.SegmentConfig.ChunkSize)
+	for _, block := range input.Parsed.Blocks {
+		if strings.TrimSpace(block.Content) == "" {
+			continue
+		}
+		if err := ensureChunkLimitHasRoom(input.MaxChunks, len(chunks)); err != nil {
+			return nil, err
+		}
+		metadata := cloneChunkMetadata(block.Metadata)
+		prefix := buildCodeChunkPrefix(metadata)
+		budget := max(limit-countTextTokens(prefix, input.Model, input.TokenizerService), codeChunkMinBodyTokens)
+		windows, err := packCodeLines(ctx, input, block.Content, budget, len(chunks))
+		if err != nil {
+			return nil, err
+		}
+		startLine := metadataInt(metadata, parseddocument.MetaCodeStartLine)
+		endLine := metadataInt(metadata, parseddocument.MetaCodeEndLine)
+		// 容器符号扣除成员后行号不连续，此时子片沿用整块行号范围。
+		contiguous := startLine > 0 && endLine-startLine+1 == strings.Count(block.Content, "\n")+1
+		symbol := metadataString(metadata, parseddocument.MetaCodeSymbol)
+		for index, window := range windows {
+			chunkMetadata := cloneChunkMetadata(metadata)
+			if contiguous {
+				chunkMetadata[parseddocument.MetaCodeStartLine] = startLine + window.startOffset
+				chunkMetadata[parseddocument.MetaCodeEndLine] = startLine + window.endOffset
+			}
+			content := prefix + window.content
+			if err := appendTokenChunkWithLimit(&chunks, tokenChunk{
+				Content:            content,
+				TokenCount:         countTextTokens(content, input.Model, input.TokenizerService),
+				SectionPath:        metadataString(metadata, parseddocument.MetaCodeSectionPath),
+				SectionLevel:       strings.Count(symbol, ".") + 1,
+				SectionTitle:       firstNonEmptyString(symbol, metadataString(metadata, parseddocument.MetaCodeSectionPath)),
+				SectionChunkIndex:  index,
+				EffectiveSplitMode: splitModeCodeSymbol,
+				Metadata:           chunkMetadata,
+			}, input.MaxChunks); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return chunks, nil
+}
+
+// packCodeLines 按整行贪心打包到预算内；单行超过预算时退回 token 切分，子片共享该行行号。
+func packCodeLines(
+	ctx context.Context,
+	input parsedDocumentChunkInput,
+	content string,
+	budget int,
+	existingChunks int,
+) ([]codeLineWindow, error) {
+	lines := strings.Split(content, "\n")
+	windows := make([]codeLineWindow, 0, 1)
+	appendWindow := func(start, end int) {
+		text := strings.TrimRight(strings.Join(lines[start:end+1], "\n"), " \t\n")
+		if strings.TrimSpace(text) != "" {
+			windows = append(windows, codeLineWindow{content: text, startOffset: start, endOffset: end})
+		}
+	}
+	start := 0
+	tokens := 0
+	for index, line := range lines {
+		lineTokens := countTextTokens(line, input.Model, input.TokenizerService)
+		if index > start && tokens+lineTokens > budget {
+			appendWindow(start, index-1)
+			start = index
+			tokens = 0
+		}
+		if lineTokens <= budget {
+			tokens += lineTokens
+			continue
+		}
+		segmentConfig := input.SegmentConfig
+		segmentConfig.ChunkSize = budget
+		segmentConfig.MaxChunks = remainingChunkLimit(input.MaxChunks, existingChunks+len(windows))
+		pieces, err := splitContentByTokenPipeline(ctx, line, segmentConfig, input.Model, input.TokenizerService, input.Logger)
+		if err != nil {
+			return nil, fmt.Errorf("split oversized code line: %w", err)
+		}
+		for _, piece := range pieces {
+			windows = append(windows, codeLineWindow{content: piece.Content, startOffset: index, endOffset: index})
+		}
+		start = index + 1
+	}
+	if start < len(lines) {
+		appendWindow(start, len(lines)-1)
+	}
+	return windows, nil
+}
+
+func buildCodeChunkPrefix(metadata map[string]any) string {
+	var builder strings.Builder
+	if filePath := metadataString(metadata, parseddocument.MetaCodeFilePath); filePath != "" {
+		_, _ = fmt.Fprintf(&builder, "File: %s\n", filePath)
+	}
+	if sectionPath := metadataString(metadata, parseddocument.MetaCodeSectionPath); sectionPath != "" {
+		_, _ = fmt.Fprintf(&builder, "Symbol: %s\n", sectionPath)
+	}
+	if builder.Len() == 0 {
+		return ""
+	}
+	builder.WriteByte('\n')
+	return builder.String()
+}
@@ -0,0 +1,85 @@
+package splitter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	parseddocument "magic/internal/domain/knowledge/shared/parseddocument"
+)
+
+const (
+	splitVersionGoEmailV1 = "go_split_v1_email"
+	splitModeEmailMessage = "email_message"
+	splitModeEmailThread  = "email_thread"
+	// emailChunkMinBodyTokens 保证邮件头前缀很长时正文切片仍有可用空间。
+	emailChunkMinBodyTokens = 200
+)
+
+// splitEmailBlocks 按邮件/会话块独立切片，每个子块都带上邮件头前缀和块 metadata，
+// 使 created_at_ts、tags 等字段能落到每个片段上参与过滤。
+func splitEmailBlocks(ctx context.Context, input parsedDocumentChunkInput) ([]tokenChunk, error) {
+	chunks := make([]tokenChunk, 0, len(input.Parsed.Blocks))
+	limit := normalizeSegmentChunkSize(input.SegmentConfig.ChunkSize)
+	for _, block := range input.Parsed.Blocks {
+		if strings.TrimSpace(block.Content) == "" {
+			continue
+		}
+		if err := ensureChunkLimitHasRoom(input.MaxChunks, len(chunks)); err != nil {
+			return nil, err
+		}
+		metadata := cloneChunkMetadata(block.Metadata)
+		prefix := buildEmailChunkPrefix(metadata)
+		segmentConfig := input.SegmentConfig
+		segmentConfig.ChunkSize = max(limit-countTextTokens(prefix, input.Model, input.TokenizerService), emailChunkMinBodyTokens)
+		segmentConfig.MaxChunks = remainingChunkLimit(input.MaxChunks, len(chunks))
+		bodyChunks, err := splitContentByTokenPipeline(ctx, block.Content, segmentConfig, input.Model, input.TokenizerService, input.Logger)
+		if err != nil {
+			return nil, err
+		}
+		for index, body := range bodyChunks {
+			content := strings.TrimSpace(prefix + strings.TrimSpace(body.Content))
+			if err := appendTokenChunkWithLimit(&chunks, tokenChunk{
+				Content:            content,
+				TokenCount:         countTextTokens(content, input.Model, input.TokenizerService),
+				SectionPath:        metadataString(metadata, parseddocument.MetaEmailSubject),
+				SectionLevel:       1,
+				SectionTitle:       metadataString(metadata, parseddocument.MetaEmailSubject),
+				SectionChunkIndex:  index,
+				EffectiveSplitMode: resolveEmailSplitMode(block.Type),
+				Metadata:           cloneChunkMetadata(metadata),
+			}, input.MaxChunks); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return chunks, nil
+}
+
+func resolveEmailSplitMode(blockType string) string {
+	if blockType == parseddocument.BlockTypeEmailThread {
+		return splitModeEmailThread
+	}
+	return splitModeEmailMessage
+}
+
+func buildEmailChunkPrefix(metadata map[string]any) string {
+	var builder strings.Builder
+	if subject := metadataString(metadata, parseddocument.MetaEmailSubject); subject != "" {
+		_, _ = fmt.Fprintf(&builder, "Subject: %s\n", subject)
+	}
+	if from := metadataString(metadata, parseddocument.MetaEmailFrom); from != "" {
+		_, _ = fmt.Fprintf(&builder, "From: %s\n", from)
+	}
+	if to := metadataStringList(metadata, parseddocument.MetaEmailTo); len(to) > 0 {
+		_, _ = fmt.Fprintf(&builder, "To: %s\n", strings.Join(to, ", "))
+	}
+	if date := metadataString(metadata, parseddocument.MetaEmailDate); date != "" {
+		_, _ = fmt.Fprintf(&builder, "Date: %s\n", date)
+	}
+	if builder.Len() == 0 {
+		return ""
+	}
+	builder.WriteByte('\n')
+	return builder.String()
+}
@@ -78,8 +78,14 @@ func (d docxStyleHierarchyDetector) Name() string {
 	return hierarchyDetectorDocxStyle
 }
 
+// Supports 富文本解析器统一把标题样式/大纲级别渲染为 Markdown 标题行，ODT/ODP/EPUB/RTF 与 DOCX 同口径。
 func (d docxStyleHierarchyDetector) Supports(sourceFileType string) bool {
-	return sourceFileType == "docx"
+	switch sourceFileType {
+	case "docx", "odt", "odp", "epub", "rtf":
+		return true
+	default:
+		return false
+	}
 }
 
 func (d docxStyleHierarchyDetector) Detect(content string) ([]hierarchyHeading, error) {
@@ -34,6 +34,34 @@ type parsedDocumentChunkInput struct {
 }
 
 func splitParsedDocumentToChunks(ctx context.Context, input parsedDocumentChunkInput) ([]tokenChunk, string, error) {
+	if input.Parsed != nil && len(input.Parsed.Blocks) > 0 {
+		switch input.Parsed.SourceType {
+		case parseddocument.SourceEmail:
+			chunks, err := splitEmailBlocks(ctx, input)
+			if err != nil {
+				return nil, "", err
+			}
+			return chunks, splitVersionGoEmailV1, nil
+		case parseddocument.SourceCode:
+			chunks, err := splitCodeBlocks(ctx, input)
+			if err != nil {
+				return nil, "", err
+			}
+			return chunks, splitVersionGoCodeV1, nil
+		case parseddocument.SourceNotebook:
+			chunks, err := splitNotebookBlocks(ctx, input)
+			if err != nil {
+				return nil, "", err
+			}
+			return chunks, splitVersionGoNotebookV1, nil
+		case parseddocument.SourceSubtitle:
+			chunks, err := splitSubtitleBlocks(ctx, input)
+			if err != nil {
+				return nil, "", err
+			}
+			return chunks, splitVersionGoSubtitleV1, nil
+		}
+	}
 	if input.Parsed == nil || input.Parsed.SourceType != parseddocument.SourceTabular || len(input.Parsed.Blocks) == 0 {
 		content := ""
 		if input.Parsed != nil {
@@ -527,7 +555,7 @@ func firstNonEmptyString(values ...string) string {
 
 func isExcelLikeTabularSource(sourceFormat string) bool {
 	switch strings.ToLower(strings.TrimSpace(sourceFormat)) {
-	case "xlsx", "xlsm", "xls":
+	case "xlsx", "xlsm", "xls", "ods":
 		return true
 	default:
 		return false
@@ -0,0 +1,242 @@
+// Package entity 定义知识库检索评测领域模型。
+package entity
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultSetCode 是未指定评测集时使用的默认评测集编码。
+	DefaultSetCode = "default"
+	// DefaultTopK 是评测回放检索时的默认召回条数。
+	DefaultTopK = 10
+	// MaxTopK 是评测回放检索允许的最大召回条数。
+	MaxTopK = 100
+	// MaxQueriesPerSet 是单个评测集允许保存的最大 query 数。
+	MaxQueriesPerSet = 500
+
+	maxSetCodeLength  = 64
+	maxQueryKeyLength = 128
+)
+
+// DefaultCutoffs 是默认统计的 k 值。
+var DefaultCutoffs = []int{1, 3, 5, 10}
+
+var (
+	// ErrInvalidSetCode 表示评测集编码非法。
+	ErrInvalidSetCode = errors.New("invalid evaluation set code")
+	// ErrEmptyGoldenQuery 表示 golden query 缺少 query 文本。
+	ErrEmptyGoldenQuery = errors.New("golden query text is required")
+	// ErrGoldenQueryWithoutExpectation 表示 golden query 没有任何期望命中。
+	ErrGoldenQueryWithoutExpectation = errors.New("golden query requires expected document codes or fragment ids")
+	// ErrInvalidQueryKey 表示 golden query 的 key 非法。
+	ErrInvalidQueryKey = errors.New("invalid golden query key")
+	// ErrDuplicateQueryKey 表示同一评测集内存在重复 query key。
+	ErrDuplicateQueryKey = errors.New("duplicate golden query key")
+	// ErrTooManyGoldenQueries 表示评测集 query 数超过上限。
+	ErrTooManyGoldenQueries = errors.New("too many golden queries in one set")
+	// ErrEmptyQuerySet 表示评测集没有可回放的 query。
+	ErrEmptyQuerySet = errors.New("evaluation query set is empty")
+	// ErrRunNotFound 表示评测 run 不存在。
+	ErrRunNotFound = errors.New("evaluation run not found")
+	// ErrRunScopeMismatch 表示对比的两个 run 不属于同一知识库评测集。
+	ErrRunScopeMismatch = errors.New("evaluation runs belong to different knowledge base sets")
+)
+
+// GoldenQuery 表示一条带期望命中的评测 query。
+//
+// 期望命中以文档编码和片段 ID 两种粒度给出：片段 ID 精确到切片，
+// 文档编码只要求召回该文档的任意片段，适合切片策略会变化的场景。
+type GoldenQuery struct {
+	ID                    int64     `json:"id"`
+	OrganizationCode      string    `json:"organization_code"`
+	KnowledgeBaseCode     string    `json:"knowledge_base_code"`
+	SetCode               string    `json:"set_code"`
+	QueryKey              string    `json:"query_key"`
+	Query                 string    `json:"query"`
+	ExpectedDocumentCodes []string  `json:"expected_document_codes"`
+	ExpectedFragmentIDs   []int64   `json:"expected_fragment_ids"`
+	CreatedUID            string    `json:"created_uid"`
+	UpdatedUID            string    `json:"updated_uid"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// RetrievedHit 表示回放检索返回的一条结果。
+type RetrievedHit struct {
+	FragmentID   int64   `json:"fragment_id"`
+	DocumentCode string  `json:"document_code"`
+	Score        float64 `json:"score"`
+}
+
+// CutoffMetrics 表示某个 k 值下的指标。
+type CutoffMetrics struct {
+	K       int     `json:"k"`
+	Recall  float64 `json:"recall"`
+	NDCG    float64 `json:"ndcg"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// Metrics 表示一次评测 run 的汇总指标，各值为所有 query 的算术平均。
+type Metrics struct {
+	QueryCount  int             `json:"query_count"`
+	FailedCount int             `json:"failed_count"`
+	MRR         float64         `json:"mrr"`
+	Cutoffs     []CutoffMetrics `json:"cutoffs"`
+}
+
+// QueryResult 表示单条 golden query 的回放结果。
+type QueryResult struct {
+	QueryKey          string          `json:"query_key"`
+	Query             string          `json:"query"`
+	ExpectedCount     int             `json:"expected_count"`
+	FirstRelevantRank int             `json:"first_relevant_rank"`
+	ReciprocalRank    float64         `json:"reciprocal_rank"`
+	Cutoffs           []CutoffMetrics `json:"cutoffs"`
+	Hits              []RetrievedHit  `json:"hits"`
+	Error             string          `json:"error,omitempty"`
+}
+
+// Run 表示一次评测集回放。
+type Run struct {
+	ID                int64         `json:"id"`
+	OrganizationCode  string        `json:"organization_code"`
+	KnowledgeBaseCode string        `json:"knowledge_base_code"`
+	SetCode           string        `json:"set_code"`
+	Label             string        `json:"label"`
+	TopK              int           `json:"top_k"`
+	BaselineRunID     int64         `json:"baseline_run_id"`
+	Metrics           Metrics       `json:"metrics"`
+	Results           []QueryResult `json:"results,omitempty"`
+	CreatedUID        string        `json:"created_uid"`
+	StartedAt         time.Time     `json:"started_at"`
+	FinishedAt        time.Time     `json:"finished_at"`
+}
+
+// NormalizeSetCode 规范化评测集编码，空值回落到默认评测集。
+func NormalizeSetCode(setCode string) (string, error) {
+	trimmed := strings.TrimSpace(setCode)
+	if trimmed == "" {
+		return DefaultSetCode, nil
+	}
+	if len(trimmed) > maxSetCodeLength {
+		return "", ErrInvalidSetCode
+	}
+	return trimmed, nil
+}
+
+// NormalizeTopK 规范化回放检索条数。
+func NormalizeTopK(topK int) int {
+	switch {
+	case topK <= 0:
+		return DefaultTopK
+	case topK > MaxTopK:
+		return MaxTopK
+	default:
+		return topK
+	}
+}
+
+// NormalizeCutoffs 去重并升序 k 值，丢弃非法值与超过 topK 的值。
+func NormalizeCutoffs(cutoffs []int, topK int) []int {
+	if len(cutoffs) == 0 {
+		cutoffs = DefaultCutoffs
+	}
+	normalized := make([]int, 0, len(cutoffs))
+	for _, k := range cutoffs {
+		if k <= 0 || k > topK || slices.Contains(normalized, k) {
+			continue
+		}
+		normalized = append(normalized, k)
+	}
+	if len(normalized) == 0 {
+		normalized = append(normalized, topK)
+	}
+	slices.Sort(normalized)
+	return normalized
+}
+
+// Normalize 校验并规范化 golden query，QueryKey 为空时以 query 文本作为 key。
+func (q *GoldenQuery) Normalize() error {
+	q.Query = strings.TrimSpace(q.Query)
+	if q.Query == "" {
+		return ErrEmptyGoldenQuery
+	}
+	q.QueryKey = strings.TrimSpace(q.QueryKey)
+	if q.QueryKey == "" {
+		q.QueryKey = q.Query
+	}
+	if len(q.QueryKey) > maxQueryKeyLength {
+		return ErrInvalidQueryKey
+	}
+	q.ExpectedDocumentCodes = normalizeDocumentCodes(q.ExpectedDocumentCodes)
+	q.ExpectedFragmentIDs = normalizeFragmentIDs(q.ExpectedFragmentIDs)
+	if len(q.ExpectedDocumentCodes) == 0 && len(q.ExpectedFragmentIDs) == 0 {
+		return ErrGoldenQueryWithoutExpectation
+	}
+	return nil
+}
+
+func normalizeDocumentCodes(codes []string) []string {
+	normalized := make([]string, 0, len(codes))
+	for _, code := range codes {
+		trimmed := strings.TrimSpace(code)
+		if trimmed == "" || slices.Contains(normalized, trimmed) {
+			continue
+		}
+		normalized = append(normalized, trimmed)
+	}
+	return normalized
+}
+
+func normalizeFragmentIDs(ids []int64) []int64 {
+	normalized := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 || slices.Contains(normalized, id) {
+			continue
+		}
+		normalized = append(normalized, id)
+	}
+	return normalized
+}
+
+// MetricDelta 表示同一指标在基线 run 与目标 run 之间的变化。
+type MetricDelta struct {
+	Base   float64 `json:"base"`
+	Target float64 `json:"target"`
+	Delta  float64 `json:"delta"`
+}
+
+// CutoffDelta 表示某个 k 值下各指标的变化。
+type CutoffDelta struct {
+	K       int         `json:"k"`
+	Recall  MetricDelta `json:"recall"`
+	NDCG    MetricDelta `json:"ndcg"`
+	HitRate MetricDelta `json:"hit_rate"`
+}
+
+// QueryChange 表示单条 query 在两次 run 之间的排序质量变化。
+type QueryChange struct {
+	QueryKey             string  `json:"query_key"`
+	Query                string  `json:"query"`
+	BaseReciprocalRank   float64 `json:"base_reciprocal_rank"`
+	TargetReciprocalRank float64 `json:"target_reciprocal_rank"`
+	BaseNDCG             float64 `json:"base_ndcg"`
+	TargetNDCG           float64 `json:"target_ndcg"`
+}
+
+// RunDiff 表示两次评测 run 的对比结果。
+type RunDiff struct {
+	BaseRunID      int64         `json:"base_run_id"`
+	TargetRunID    int64         `json:"target_run_id"`
+	MRR            MetricDelta   `json:"mrr"`
+	Cutoffs        []CutoffDelta `json:"cutoffs"`
+	Improved       []QueryChange `json:"improved"`
+	Regressed      []QueryChange `json:"regressed"`
+	UnchangedCount int           `json:"unchanged_count"`
+	AddedQueries   []string      `json:"added_queries"`
+	RemovedQueries []string      `json:"removed_queries"`
+}
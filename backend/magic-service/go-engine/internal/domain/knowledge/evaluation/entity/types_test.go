@@ -0,0 +1,48 @@
+package entity_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	evalentity "magic/internal/domain/knowledge/evaluation/entity"
+)
+
+func TestGoldenQueryNormalize(t *testing.T) {
+	t.Parallel()
+
+	query := evalentity.GoldenQuery{
+		Query:                 "  refund policy ",
+		ExpectedDocumentCodes: []string{" DOC1 ", "", "DOC1"},
+		ExpectedFragmentIDs:   []int64{3, 0, 3, -1},
+	}
+	if err := query.Normalize(); err != nil {
+		t.Fatalf("Normalize returned error: %v", err)
+	}
+	if query.QueryKey != "refund policy" || !slices.Equal(query.ExpectedDocumentCodes, []string{"DOC1"}) ||
+		!slices.Equal(query.ExpectedFragmentIDs, []int64{3}) {
+		t.Fatalf("unexpected normalized query: %#v", query)
+	}
+
+	missing := evalentity.GoldenQuery{Query: "q", ExpectedDocumentCodes: []string{" "}}
+	if err := missing.Normalize(); !errors.Is(err, evalentity.ErrGoldenQueryWithoutExpectation) {
+		t.Fatalf("expected missing expectation error, got %v", err)
+	}
+}
+
+func TestNormalizeCutoffs(t *testing.T) {
+	t.Parallel()
+
+	if got := evalentity.NormalizeCutoffs(nil, 5); !slices.Equal(got, []int{1, 3, 5}) {
+		t.Fatalf("unexpected default cutoffs: %v", got)
+	}
+	if got := evalentity.NormalizeCutoffs([]int{20, 0, 2}, 8); !slices.Equal(got, []int{2}) {
+		t.Fatalf("unexpected filtered cutoffs: %v", got)
+	}
+	if got := evalentity.NormalizeCutoffs([]int{50}, 8); !slices.Equal(got, []int{8}) {
+		t.Fatalf("unexpected fallback cutoffs: %v", got)
+	}
+	if got := evalentity.NormalizeTopK(0); got != evalentity.DefaultTopK {
+		t.Fatalf("unexpected default topK: %d", got)
+	}
+}
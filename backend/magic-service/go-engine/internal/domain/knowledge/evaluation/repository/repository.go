@@ -0,0 +1,49 @@
+// Package repository 定义知识库检索评测领域的持久化端口。
+package repository
+
+import (
+	"context"
+
+	evalentity "magic/internal/domain/knowledge/evaluation/entity"
+)
+
+// QuerySetScope 定位一个知识库下的评测集。
+type QuerySetScope struct {
+	OrganizationCode  string
+	KnowledgeBaseCode string
+	SetCode           string
+}
+
+// ListRunsQuery 表示按评测集分页查询 run 的条件。
+type ListRunsQuery struct {
+	Scope  QuerySetScope
+	Limit  int32
+	Offset int32
+}
+
+// GoldenQueryStore 定义 golden query 持久化能力。
+type GoldenQueryStore interface {
+	// ReplaceGoldenQueries 以全量替换方式保存评测集，返回写入后的 query 列表。
+	ReplaceGoldenQueries(
+		ctx context.Context,
+		scope QuerySetScope,
+		queries []evalentity.GoldenQuery,
+	) ([]*evalentity.GoldenQuery, error)
+	ListGoldenQueries(ctx context.Context, scope QuerySetScope) ([]*evalentity.GoldenQuery, error)
+}
+
+// RunStore 定义评测 run 持久化能力。
+type RunStore interface {
+	InsertRun(ctx context.Context, run evalentity.Run) (*evalentity.Run, error)
+	GetRun(ctx context.Context, organizationCode string, id int64) (*evalentity.Run, error)
+	// GetPreviousRun 返回同一评测集中 ID 小于 beforeID 的最近一次 run，不存在时返回 ErrRunNotFound。
+	GetPreviousRun(ctx context.Context, scope QuerySetScope, beforeID int64) (*evalentity.Run, error)
+	// ListRuns 按 ID 倒序列出 run 摘要，不包含逐 query 结果。
+	ListRuns(ctx context.Context, query ListRunsQuery) ([]*evalentity.Run, error)
+}
+
+// Repository 聚合检索评测所需的全部持久化能力。
+type Repository interface {
+	GoldenQueryStore
+	RunStore
+}
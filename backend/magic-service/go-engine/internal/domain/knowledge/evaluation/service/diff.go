@@ -0,0 +1,136 @@
+package service
+
+import (
+	"slices"
+
+	evalentity "magic/internal/domain/knowledge/evaluation/entity"
+)
+
+// 指标变化小于该阈值时视为持平，避免浮点误差把同一排序判成回退。
+const metricChangeEpsilon = 1e-9
+
+// DiffRuns 对比基线 run 与目标 run。
+//
+// 汇总指标只对比两次 run 都统计过的 k 值；单条 query 以两次 run 共有的最大 k 的 nDCG
+// 判断改善或回退，nDCG 持平时再比较倒数排名。只存在于一侧的 query 单独列出。
+func DiffRuns(base, target *evalentity.Run) (*evalentity.RunDiff, error) {
+	if base == nil || target == nil {
+		return nil, evalentity.ErrRunNotFound
+	}
+	if base.OrganizationCode != target.OrganizationCode ||
+		base.KnowledgeBaseCode != target.KnowledgeBaseCode ||
+		base.SetCode != target.SetCode {
+		return nil, evalentity.ErrRunScopeMismatch
+	}
+
+	diff := &evalentity.RunDiff{
+		BaseRunID:      base.ID,
+		TargetRunID:    target.ID,
+		MRR:            newMetricDelta(base.Metrics.MRR, target.Metrics.MRR),
+		Cutoffs:        []evalentity.CutoffDelta{},
+		Improved:       []evalentity.QueryChange{},
+		Regressed:      []evalentity.QueryChange{},
+		AddedQueries:   []string{},
+		RemovedQueries: []string{},
+	}
+	commonK := 0
+	for _, targetCutoff := range target.Metrics.Cutoffs {
+		baseCutoff, ok := findCutoff(base.Metrics.Cutoffs, targetCutoff.K)
+		if !ok {
+			continue
+		}
+		commonK = max(commonK, targetCutoff.K)
+		diff.Cutoffs = append(diff.Cutoffs, evalentity.CutoffDelta{
+			K:       targetCutoff.K,
+			Recall:  newMetricDelta(baseCutoff.Recall, targetCutoff.Recall),
+			NDCG:    newMetricDelta(baseCutoff.NDCG, targetCutoff.NDCG),
+			HitRate: newMetricDelta(baseCutoff.HitRate, targetCutoff.HitRate),
+		})
+	}
+
+	diffQueryResults(diff, base.Results, target.Results, commonK)
+	return diff, nil
+}
+
+func diffQueryResults(diff *evalentity.RunDiff, baseResults, targetResults []evalentity.QueryResult, k int) {
+	baseByKey := make(map[string]evalentity.QueryResult, len(baseResults))
+	for _, result := range baseResults {
+		baseByKey[result.QueryKey] = result
+	}
+
+	seen := make(map[string]struct{}, len(targetResults))
+	for _, targetResult := range targetResults {
+		seen[targetResult.QueryKey] = struct{}{}
+		baseResult, ok := baseByKey[targetResult.QueryKey]
+		if !ok {
+			diff.AddedQueries = append(diff.AddedQueries, targetResult.QueryKey)
+			continue
+		}
+		change := evalentity.QueryChange{
+			QueryKey:             targetResult.QueryKey,
+			Query:                targetResult.Query,
+			BaseReciprocalRank:   baseResult.ReciprocalRank,
+			TargetReciprocalRank: targetResult.ReciprocalRank,
+			BaseNDCG:             queryNDCG(baseResult, k),
+			TargetNDCG:           queryNDCG(targetResult, k),
+		}
+		switch compareQueryChange(change) {
+		case 1:
+			diff.Improved = append(diff.Improved, change)
+		case -1:
+			diff.Regressed = append(diff.Regressed, change)
+		default:
+			diff.UnchangedCount++
+		}
+	}
+	for _, baseResult := range baseResults {
+		if _, ok := seen[baseResult.QueryKey]; !ok {
+			diff.RemovedQueries = append(diff.RemovedQueries, baseResult.QueryKey)
+		}
+	}
+
+	// 变化最大的 query 排在前面，便于直接定位调参影响。
+	slices.SortStableFunc(diff.Improved, func(a, b evalentity.QueryChange) int {
+		return compareFloatDesc(a.TargetNDCG-a.BaseNDCG, b.TargetNDCG-b.BaseNDCG)
+	})
+	slices.SortStableFunc(diff.Regressed, func(a, b evalentity.QueryChange) int {
+		return compareFloatDesc(a.BaseNDCG-a.TargetNDCG, b.BaseNDCG-b.TargetNDCG)
+	})
+}
+
+func compareQueryChange(change evalentity.QueryChange) int {
+	if delta := change.TargetNDCG - change.BaseNDCG; delta > metricChangeEpsilon {
+		return 1
+	} else if delta < -metricChangeEpsilon {
+		return -1
+	}
+	if delta := change.TargetReciprocalRank - change.BaseReciprocalRank; delta > metricChangeEpsilon {
+		return 1
+	} else if delta < -metricChangeEpsilon {
+		return -1
+	}
+	return 0
+}
+
+func queryNDCG(result evalentity.QueryResult, k int) float64 {
+	cutoff, ok := findCutoff(result.Cutoffs, k)
+	if !ok {
+		return 0
+	}
+	return cutoff.NDCG
+}
+
+func newMetricDelta(base, target float64) evalentity.MetricDelta {
+	return evalentity.MetricDelta{Base: base, Target: target, Delta: target - base}
+}
+
+func compareFloatDesc(a, b float64) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	default:
+		return 0
+	}
+}
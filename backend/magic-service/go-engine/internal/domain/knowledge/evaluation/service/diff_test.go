@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	evalentity "magic/internal/domain/knowledge/evaluation/entity"
+	evalservice "magic/internal/domain/knowledge/evaluation/service"
+)
+
+func evaluationRun(id int64, results ...evalentity.QueryResult) *evalentity.Run {
+	cutoffs := []int{1, 3}
+	return &evalentity.Run{
+		ID:                id,
+		OrganizationCode:  "ORG1",
+		KnowledgeBaseCode: "KB1",
+		SetCode:           evalentity.DefaultSetCode,
+		Metrics:           evalservice.Summarize(results, cutoffs),
+		Results:           results,
+	}
+}
+
+func scoredQuery(key string, hits ...int64) evalentity.QueryResult {
+	retrieved := make([]evalentity.RetrievedHit, 0, len(hits))
+	for _, id := range hits {
+		retrieved = append(retrieved, evalentity.RetrievedHit{FragmentID: id})
+	}
+	return evalservice.ScoreQuery(
+		evalentity.GoldenQuery{QueryKey: key, Query: key, ExpectedFragmentIDs: []int64{1}},
+		retrieved,
+		[]int{1, 3},
+	)
+}
+
+func TestDiffRunsClassifiesQueryChanges(t *testing.T) {
+	t.Parallel()
+
+	base := evaluationRun(1,
+		scoredQuery("improved", 9, 1),
+		scoredQuery("regressed", 1),
+		scoredQuery("same", 1),
+		scoredQuery("removed", 1),
+	)
+	target := evaluationRun(2,
+		scoredQuery("improved", 1),
+		scoredQuery("regressed", 9, 8, 7),
+		scoredQuery("same", 1),
+		scoredQuery("added", 1),
+	)
+
+	diff, err := evalservice.DiffRuns(base, target)
+	if err != nil {
+		t.Fatalf("DiffRuns returned error: %v", err)
+	}
+	if diff.BaseRunID != 1 || diff.TargetRunID != 2 || len(diff.Cutoffs) != 2 {
+		t.Fatalf("unexpected diff header: %#v", diff)
+	}
+	if len(diff.Improved) != 1 || diff.Improved[0].QueryKey != "improved" {
+		t.Fatalf("unexpected improved queries: %#v", diff.Improved)
+	}
+	if len(diff.Regressed) != 1 || diff.Regressed[0].QueryKey != "regressed" {
+		t.Fatalf("unexpected regressed queries: %#v", diff.Regressed)
+	}
+	if diff.UnchangedCount != 1 || len(diff.AddedQueries) != 1 || diff.AddedQueries[0] != "added" ||
+		len(diff.RemovedQueries) != 1 || diff.RemovedQueries[0] != "removed" {
+		t.Fatalf("unexpected membership changes: %#v", diff)
+	}
+	assertMetric(t, "mrr delta", diff.MRR.Delta, diff.MRR.Target-diff.MRR.Base)
+}
+
+func TestDiffRunsRejectsDifferentScopes(t *testing.T) {
+	t.Parallel()
+
+	other := evaluationRun(2)
+	other.SetCode = "regression"
+	if _, err := evalservice.DiffRuns(evaluationRun(1), other); !errors.Is(err, evalentity.ErrRunScopeMismatch) {
+		t.Fatalf("expected scope mismatch, got %v", err)
+	}
+	if _, err := evalservice.DiffRuns(nil, other); !errors.Is(err, evalentity.ErrRunNotFound) {
+		t.Fatalf("expected run not found, got %v", err)
+	}
+}
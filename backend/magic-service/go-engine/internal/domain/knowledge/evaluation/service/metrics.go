@@ -0,0 +1,154 @@
+// Package service 实现知识库检索评测的指标计算与 run 对比规则。
+package service
+
+import (
+	"math"
+
+	evalentity "magic/internal/domain/knowledge/evaluation/entity"
+)
+
+// ScoreQuery 按 golden query 的期望命中计算单条 query 的排序指标。
+//
+// 相关性按二元判定：命中期望片段 ID，或命中期望文档的任意片段即为相关。
+// 每个期望目标只计一次增益，避免同一文档的多个片段重复抬高 recall 与 nDCG。
+func ScoreQuery(query evalentity.GoldenQuery, hits []evalentity.RetrievedHit, cutoffs []int) evalentity.QueryResult {
+	gains := relevanceGains(query, hits)
+	expectedCount := len(query.ExpectedFragmentIDs) + len(query.ExpectedDocumentCodes)
+
+	result := evalentity.QueryResult{
+		QueryKey:      query.QueryKey,
+		Query:         query.Query,
+		ExpectedCount: expectedCount,
+		Hits:          hits,
+		Cutoffs:       make([]evalentity.CutoffMetrics, 0, len(cutoffs)),
+	}
+	for index, relevant := range gains {
+		if relevant {
+			result.FirstRelevantRank = index + 1
+			result.ReciprocalRank = 1 / float64(index+1)
+			break
+		}
+	}
+	for _, k := range cutoffs {
+		result.Cutoffs = append(result.Cutoffs, cutoffMetrics(gains, k, expectedCount))
+	}
+	return result
+}
+
+// FailedQueryResult 构造检索失败的 query 结果，失败 query 的各项指标按 0 计入汇总。
+func FailedQueryResult(query evalentity.GoldenQuery, cutoffs []int, err error) evalentity.QueryResult {
+	result := ScoreQuery(query, nil, cutoffs)
+	result.Hits = []evalentity.RetrievedHit{}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Summarize 汇总各 query 结果得到 run 级指标。
+func Summarize(results []evalentity.QueryResult, cutoffs []int) evalentity.Metrics {
+	metrics := evalentity.Metrics{
+		QueryCount: len(results),
+		Cutoffs:    make([]evalentity.CutoffMetrics, 0, len(cutoffs)),
+	}
+	for _, k := range cutoffs {
+		metrics.Cutoffs = append(metrics.Cutoffs, evalentity.CutoffMetrics{K: k})
+	}
+	if len(results) == 0 {
+		return metrics
+	}
+
+	for _, result := range results {
+		if result.Error != "" {
+			metrics.FailedCount++
+		}
+		metrics.MRR += result.ReciprocalRank
+		for index := range metrics.Cutoffs {
+			cutoff, ok := findCutoff(result.Cutoffs, metrics.Cutoffs[index].K)
+			if !ok {
+				continue
+			}
+			metrics.Cutoffs[index].Recall += cutoff.Recall
+			metrics.Cutoffs[index].NDCG += cutoff.NDCG
+			metrics.Cutoffs[index].HitRate += cutoff.HitRate
+		}
+	}
+
+	count := float64(len(results))
+	metrics.MRR /= count
+	for index := range metrics.Cutoffs {
+		metrics.Cutoffs[index].Recall /= count
+		metrics.Cutoffs[index].NDCG /= count
+		metrics.Cutoffs[index].HitRate /= count
+	}
+	return metrics
+}
+
+func relevanceGains(query evalentity.GoldenQuery, hits []evalentity.RetrievedHit) []bool {
+	pendingFragments := make(map[int64]struct{}, len(query.ExpectedFragmentIDs))
+	for _, id := range query.ExpectedFragmentIDs {
+		pendingFragments[id] = struct{}{}
+	}
+	pendingDocuments := make(map[string]struct{}, len(query.ExpectedDocumentCodes))
+	for _, code := range query.ExpectedDocumentCodes {
+		pendingDocuments[code] = struct{}{}
+	}
+
+	gains := make([]bool, len(hits))
+	for index, hit := range hits {
+		if _, ok := pendingFragments[hit.FragmentID]; ok {
+			delete(pendingFragments, hit.FragmentID)
+			gains[index] = true
+			continue
+		}
+		if _, ok := pendingDocuments[hit.DocumentCode]; ok {
+			delete(pendingDocuments, hit.DocumentCode)
+			gains[index] = true
+		}
+	}
+	return gains
+}
+
+func cutoffMetrics(gains []bool, k, expectedCount int) evalentity.CutoffMetrics {
+	metrics := evalentity.CutoffMetrics{K: k}
+	if expectedCount == 0 {
+		return metrics
+	}
+
+	matched := 0
+	dcg := 0.0
+	for index := 0; index < k && index < len(gains); index++ {
+		if !gains[index] {
+			continue
+		}
+		matched++
+		dcg += discount(index)
+	}
+	idealDCG := 0.0
+	for index := range min(k, expectedCount) {
+		idealDCG += discount(index)
+	}
+
+	metrics.Recall = float64(matched) / float64(expectedCount)
+	if idealDCG > 0 {
+		metrics.NDCG = dcg / idealDCG
+	}
+	if matched > 0 {
+		metrics.HitRate = 1
+	}
+	return metrics
+}
+
+// discount 返回第 index+1 位的对数折损。
+func discount(index int) float64 {
+	return 1 / math.Log2(float64(index)+2)
+}
+
+func findCutoff(cutoffs []evalentity.CutoffMetrics, k int) (evalentity.CutoffMetrics, bool) {
+	for _, cutoff := range cutoffs {
+		if cutoff.K == k {
+			return cutoff, true
+		}
+	}
+	return evalentity.CutoffMetrics{}, false
+}
@@ -0,0 +1,81 @@
+package service_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	evalentity "magic/internal/domain/knowledge/evaluation/entity"
+	evalservice "magic/internal/domain/knowledge/evaluation/service"
+)
+
+const metricTolerance = 1e-9
+
+func assertMetric(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > metricTolerance {
+		t.Fatalf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestScoreQueryComputesRankMetrics(t *testing.T) {
+	t.Parallel()
+
+	query := evalentity.GoldenQuery{
+		QueryKey:              "q1",
+		Query:                 "how to reset password",
+		ExpectedDocumentCodes: []string{"DOC-A"},
+		ExpectedFragmentIDs:   []int64{42},
+	}
+	hits := []evalentity.RetrievedHit{
+		{FragmentID: 7, DocumentCode: "DOC-X"},
+		{FragmentID: 11, DocumentCode: "DOC-A"},
+		{FragmentID: 12, DocumentCode: "DOC-A"},
+		{FragmentID: 42, DocumentCode: "DOC-B"},
+	}
+
+	result := evalservice.ScoreQuery(query, hits, []int{1, 3, 5})
+	if result.FirstRelevantRank != 2 || result.ExpectedCount != 2 {
+		t.Fatalf("unexpected rank summary: %#v", result)
+	}
+	assertMetric(t, "reciprocal rank", result.ReciprocalRank, 0.5)
+
+	at1, at3, at5 := result.Cutoffs[0], result.Cutoffs[1], result.Cutoffs[2]
+	assertMetric(t, "recall@1", at1.Recall, 0)
+	assertMetric(t, "hit@1", at1.HitRate, 0)
+	// 同一文档的第二个片段不重复计分。
+	assertMetric(t, "recall@3", at3.Recall, 0.5)
+	assertMetric(t, "hit@3", at3.HitRate, 1)
+	assertMetric(t, "recall@5", at5.Recall, 1)
+
+	idealDCG := 1 + 1/math.Log2(3)
+	assertMetric(t, "ndcg@3", at3.NDCG, (1/math.Log2(3))/idealDCG)
+	assertMetric(t, "ndcg@5", at5.NDCG, (1/math.Log2(3)+1/math.Log2(5))/idealDCG)
+}
+
+func TestSummarizeAveragesQueriesAndCountsFailures(t *testing.T) {
+	t.Parallel()
+
+	cutoffs := []int{1, 3}
+	perfect := evalservice.ScoreQuery(
+		evalentity.GoldenQuery{QueryKey: "q1", ExpectedFragmentIDs: []int64{1}},
+		[]evalentity.RetrievedHit{{FragmentID: 1}},
+		cutoffs,
+	)
+	failed := evalservice.FailedQueryResult(
+		evalentity.GoldenQuery{QueryKey: "q2", ExpectedDocumentCodes: []string{"DOC"}},
+		cutoffs,
+		errors.New("vector store unavailable"),
+	)
+
+	metrics := evalservice.Summarize([]evalentity.QueryResult{perfect, failed}, cutoffs)
+	if metrics.QueryCount != 2 || metrics.FailedCount != 1 || failed.Error == "" {
+		t.Fatalf("unexpected counts: %#v", metrics)
+	}
+	assertMetric(t, "mrr", metrics.MRR, 0.5)
+	for _, cutoff := range metrics.Cutoffs {
+		assertMetric(t, "recall", cutoff.Recall, 0.5)
+		assertMetric(t, "ndcg", cutoff.NDCG, 0.5)
+		assertMetric(t, "hit rate", cutoff.HitRate, 0.5)
+	}
+}
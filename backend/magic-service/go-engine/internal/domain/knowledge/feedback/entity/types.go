@@ -0,0 +1,105 @@
+// Package entity 定义知识库检索结果相关性反馈的领域模型。
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// 反馈信号类型，与 agent 界面收集的交互一一对应。
+const (
+	SignalThumbsUp   = "thumbs_up"
+	SignalThumbsDown = "thumbs_down"
+	SignalAnswered   = "answered"
+)
+
+// MaxRequestIDLength 是反馈关联的检索请求 ID 最大长度。
+const MaxRequestIDLength = 64
+
+var (
+	// ErrInvalidSignal 表示反馈信号类型不受支持。
+	ErrInvalidSignal = errors.New("invalid feedback signal")
+	// ErrRequestIDRequired 表示反馈缺少关联的检索请求 ID。
+	ErrRequestIDRequired = errors.New("feedback request id is required")
+	// ErrRequestIDTooLong 表示检索请求 ID 超过长度上限。
+	ErrRequestIDTooLong = errors.New("feedback request id is too long")
+	// ErrTargetRequired 表示反馈缺少知识库或文档编码。
+	ErrTargetRequired = errors.New("feedback knowledge base code and document code are required")
+)
+
+// Feedback 表示一次针对检索结果的相关性反馈。
+//
+// FragmentID 为 0 时表示针对整篇文档的反馈。
+type Feedback struct {
+	ID                int64
+	OrganizationCode  string
+	KnowledgeBaseCode string
+	DocumentCode      string
+	FragmentID        int64
+	RequestID         string
+	UserID            string
+	Signal            string
+	CreatedAt         time.Time
+}
+
+// Normalize 规整字段并校验反馈是否合法。
+func (f *Feedback) Normalize() error {
+	f.OrganizationCode = strings.TrimSpace(f.OrganizationCode)
+	f.KnowledgeBaseCode = strings.TrimSpace(f.KnowledgeBaseCode)
+	f.DocumentCode = strings.TrimSpace(f.DocumentCode)
+	f.RequestID = strings.TrimSpace(f.RequestID)
+	f.UserID = strings.TrimSpace(f.UserID)
+	f.Signal = strings.ToLower(strings.TrimSpace(f.Signal))
+	if f.FragmentID < 0 {
+		f.FragmentID = 0
+	}
+
+	if !IsValidSignal(f.Signal) {
+		return ErrInvalidSignal
+	}
+	if f.RequestID == "" {
+		return ErrRequestIDRequired
+	}
+	if len(f.RequestID) > MaxRequestIDLength {
+		return ErrRequestIDTooLong
+	}
+	if f.OrganizationCode == "" || f.KnowledgeBaseCode == "" || f.DocumentCode == "" {
+		return ErrTargetRequired
+	}
+	return nil
+}
+
+// IsValidSignal 判断反馈信号类型是否受支持。
+func IsValidSignal(signal string) bool {
+	switch signal {
+	case SignalThumbsUp, SignalThumbsDown, SignalAnswered:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stat 表示按片段或文档聚合的反馈计数，FragmentID 为 0 时为文档级汇总。
+type Stat struct {
+	KnowledgeBaseCode string
+	DocumentCode      string
+	FragmentID        int64
+	PositiveCount     int64
+	NegativeCount     int64
+	AnsweredCount     int64
+}
+
+// StatDelta 返回一条反馈对聚合计数的增量。
+func StatDelta(signal string) (positive, negative, answered int64) {
+	switch signal {
+	case SignalThumbsUp:
+		return 1, 0, 0
+	case SignalThumbsDown:
+		return 0, 1, 0
+	case SignalAnswered:
+		return 0, 0, 1
+	default:
+		return 0, 0, 0
+	}
+}
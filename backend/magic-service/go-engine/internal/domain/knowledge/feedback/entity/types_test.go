@@ -0,0 +1,80 @@
+package entity_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	feedbackentity "magic/internal/domain/knowledge/feedback/entity"
+)
+
+func TestFeedbackNormalizeTrimsFields(t *testing.T) {
+	t.Parallel()
+
+	feedback := &feedbackentity.Feedback{
+		OrganizationCode:  " ORG1 ",
+		KnowledgeBaseCode: " KB1 ",
+		DocumentCode:      " DOC1 ",
+		FragmentID:        -3,
+		RequestID:         " req-1 ",
+		Signal:            " Thumbs_Up ",
+	}
+	if err := feedback.Normalize(); err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if feedback.Signal != feedbackentity.SignalThumbsUp || feedback.RequestID != "req-1" || feedback.FragmentID != 0 {
+		t.Fatalf("unexpected normalized feedback: %#v", feedback)
+	}
+}
+
+func TestFeedbackNormalizeRejectsInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	valid := func() *feedbackentity.Feedback {
+		return &feedbackentity.Feedback{
+			OrganizationCode:  "ORG1",
+			KnowledgeBaseCode: "KB1",
+			DocumentCode:      "DOC1",
+			RequestID:         "req-1",
+			Signal:            feedbackentity.SignalAnswered,
+		}
+	}
+	cases := []struct {
+		name   string
+		mutate func(*feedbackentity.Feedback)
+		want   error
+	}{
+		{name: "signal", mutate: func(f *feedbackentity.Feedback) { f.Signal = "meh" }, want: feedbackentity.ErrInvalidSignal},
+		{name: "request id", mutate: func(f *feedbackentity.Feedback) { f.RequestID = " " }, want: feedbackentity.ErrRequestIDRequired},
+		{
+			name: "request id length",
+			mutate: func(f *feedbackentity.Feedback) {
+				f.RequestID = strings.Repeat("r", feedbackentity.MaxRequestIDLength+1)
+			},
+			want: feedbackentity.ErrRequestIDTooLong,
+		},
+		{name: "document", mutate: func(f *feedbackentity.Feedback) { f.DocumentCode = "" }, want: feedbackentity.ErrTargetRequired},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			feedback := valid()
+			tc.mutate(feedback)
+			if err := feedback.Normalize(); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestStatDelta(t *testing.T) {
+	t.Parallel()
+
+	if positive, negative, answered := feedbackentity.StatDelta(feedbackentity.SignalThumbsDown); positive != 0 || negative != 1 || answered != 0 {
+		t.Fatalf("unexpected thumbs down delta: %d %d %d", positive, negative, answered)
+	}
+	if positive, negative, answered := feedbackentity.StatDelta(feedbackentity.SignalAnswered); positive != 0 || negative != 0 || answered != 1 {
+		t.Fatalf("unexpected answered delta: %d %d %d", positive, negative, answered)
+	}
+}
@@ -0,0 +1,35 @@
+// Package repository 定义相关性反馈领域的持久化端口。
+package repository
+
+import (
+	"context"
+
+	feedbackentity "magic/internal/domain/knowledge/feedback/entity"
+)
+
+// StatsQuery 表示按文档批量读取反馈聚合的条件。
+type StatsQuery struct {
+	OrganizationCode  string
+	KnowledgeBaseCode string
+	DocumentCodes     []string
+}
+
+// Store 定义反馈写入能力。
+type Store interface {
+	// SaveFeedback 写入反馈并在同一事务内累加片段与文档聚合计数。
+	//
+	// 同一检索请求、用户、目标与信号重复提交时不重复计数，返回 false。
+	SaveFeedback(ctx context.Context, feedback *feedbackentity.Feedback) (bool, error)
+}
+
+// StatsReader 定义反馈聚合读取能力。
+type StatsReader interface {
+	// ListStatsByDocuments 返回给定文档的文档级与片段级聚合计数。
+	ListStatsByDocuments(ctx context.Context, query StatsQuery) ([]feedbackentity.Stat, error)
+}
+
+// Repository 聚合反馈所需的全部持久化能力。
+type Repository interface {
+	Store
+	StatsReader
+}
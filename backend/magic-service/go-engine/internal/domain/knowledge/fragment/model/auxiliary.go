@@ -0,0 +1,70 @@
+package model
+
+import "strings"
+
+const (
+	// AuxiliaryTypeSummary 标记模型生成的章节/文档摘要辅助片段。
+	AuxiliaryTypeSummary = "summary"
+	// AuxiliaryTypeQuestion 标记模型生成的常见问题辅助片段。
+	AuxiliaryTypeQuestion = "question"
+
+	// MetadataAuxiliaryTypeKey 记录辅助片段类型，普通片段不含该字段。
+	MetadataAuxiliaryTypeKey = "auxiliary_type"
+	// MetadataAuxiliarySourcePointIDsKey 记录辅助片段关联的源片段 point_id，检索命中时据此回溯。
+	MetadataAuxiliarySourcePointIDsKey = "auxiliary_source_point_ids"
+
+	metadataExtKey = "ext"
+)
+
+// AuxiliaryFragmentType 返回 metadata 中记录的辅助片段类型，普通片段返回空串。
+//
+// 向量 payload 会把非过滤字段收进 ext，这里同时兼容顶层与 ext 两种位置。
+func AuxiliaryFragmentType(metadata map[string]any) string {
+	if value, ok := auxiliaryMetadataValue(metadata, MetadataAuxiliaryTypeKey).(string); ok {
+		return strings.TrimSpace(value)
+	}
+	return ""
+}
+
+// AuxiliarySourcePointIDs 返回辅助片段关联的源片段 point_id，顺序即生成时的优先级。
+func AuxiliarySourcePointIDs(metadata map[string]any) []string {
+	var values []string
+	switch raw := auxiliaryMetadataValue(metadata, MetadataAuxiliarySourcePointIDsKey).(type) {
+	case []string:
+		values = raw
+	case []any:
+		values = make([]string, 0, len(raw))
+		for _, item := range raw {
+			if text, ok := item.(string); ok {
+				values = append(values, text)
+			}
+		}
+	default:
+		return nil
+	}
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+// IsAuxiliaryFragment 判断片段是否为模型生成的辅助片段。
+func IsAuxiliaryFragment(fragment *KnowledgeBaseFragment) bool {
+	return fragment != nil && AuxiliaryFragmentType(fragment.Metadata) != ""
+}
+
+func auxiliaryMetadataValue(metadata map[string]any, key string) any {
+	if len(metadata) == 0 {
+		return nil
+	}
+	if value, ok := metadata[key]; ok && value != nil {
+		return value
+	}
+	if ext, ok := metadata[metadataExtKey].(map[string]any); ok {
+		return ext[key]
+	}
+	return nil
+}
@@ -11,6 +11,8 @@ const (
 	DefaultDenseVectorName = shared.DefaultDenseVectorName
 	// DefaultSparseVectorName 表示默认 sparse named vector 名称。
 	DefaultSparseVectorName = shared.DefaultSparseVectorName
+	// DefaultMultiVectorName 表示默认 late-interaction 多向量名称。
+	DefaultMultiVectorName = shared.DefaultMultiVectorName
 	// DefaultSparseModelName 表示默认 Qdrant BM25 模型名称。
 	DefaultSparseModelName = shared.DefaultSparseModelName
 	// SparseBackendClientBM25QdrantIDFV1 表示客户端构造 sparse vector、Qdrant 负责 IDF 的后端版本。
@@ -44,6 +46,18 @@ type MissingDocumentCodeQuery struct {
 	Limit            int
 }
 
+// ContextWindowQuery 描述命中片段上下文扩展的查询范围。
+//
+// ParentNodeID 非空时按层级父节点取同一章节的全部片段，否则按 chunk_index 闭区间取相邻片段。
+type ContextWindowQuery struct {
+	KnowledgeCode   string
+	DocumentCode    string
+	ParentNodeID    string
+	StartChunkIndex int
+	EndChunkIndex   int
+	Limit           int
+}
+
 // VectorFilter 复用共享向量过滤条件。
 type VectorFilter = shared.VectorFilter
 
@@ -74,6 +88,9 @@ type DenseSearchRequest = shared.DenseSearchRequest
 // SparseSearchRequest 复用共享 sparse 检索请求。
 type SparseSearchRequest = shared.SparseSearchRequest
 
+// MultiVectorSearchRequest 复用共享多向量检索请求。
+type MultiVectorSearchRequest = shared.MultiVectorSearchRequest
+
 // VectorSearchResult 复用共享向量搜索结果。
 type VectorSearchResult[T any] = shared.VectorSearchResult[T]
 
@@ -122,6 +139,11 @@ type KnowledgeBaseFragmentReader interface {
 	ListMissingDocumentCode(ctx context.Context, query MissingDocumentCodeQuery) ([]*KnowledgeBaseFragment, error)
 }
 
+// KnowledgeBaseFragmentContextReader 片段上下文窗口读取接口，仅部分仓储实现。
+type KnowledgeBaseFragmentContextReader interface {
+	ListContextWindow(ctx context.Context, query ContextWindowQuery) ([]*KnowledgeBaseFragment, error)
+}
+
 // KnowledgeBaseFragmentRepository 片段仓储接口。
 type KnowledgeBaseFragmentRepository interface {
 	KnowledgeBaseFragmentWriter
@@ -143,6 +165,9 @@ type VectorDBManagementRepository = shared.VectorDBManagementRepository
 // VectorDBDataRepository 复用共享向量数据契约。
 type VectorDBDataRepository[T any] = shared.VectorDBDataRepository[T]
 
+// VectorDBMultiVectorRepository 复用共享多向量数据契约。
+type VectorDBMultiVectorRepository[T any] = shared.VectorDBMultiVectorRepository[T]
+
 // VectorDimensionMismatchError 复用共享向量维度不匹配错误。
 type VectorDimensionMismatchError = shared.VectorDimensionMismatchError
 
@@ -33,6 +33,7 @@ type HybridSearchConfigForTest struct {
 	SparseWeight         float64
 	HybridAlpha          float64
 	LegacyWeightUpgraded bool
+	FusionAlgorithm      string
 }
 
 func FuseHybridResultsForTest(
@@ -41,12 +42,24 @@ func FuseHybridResultsForTest(
 	denseWeight float64,
 	sparseWeight float64,
 ) []*shared.VectorSearchResult[fragmodel.FragmentPayload] {
-	return fuseHybridResults(denseResults, sparseResults, hybridSearchConfig{
+	return fuseHybridResults(denseResults, sparseResults, nil, hybridSearchConfig{
 		DenseWeight:  denseWeight,
 		SparseWeight: sparseWeight,
 	})
 }
 
+func FuseHybridResultsWithStrategyForTest(
+	denseResults []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+	sparseResults []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+	strategy FusionStrategy,
+) []*shared.VectorSearchResult[fragmodel.FragmentPayload] {
+	return fuseHybridResults(denseResults, sparseResults, nil, hybridSearchConfig{
+		DenseWeight:  defaultDenseWeight,
+		SparseWeight: defaultSparseWeight,
+		Fusion:       strategy,
+	})
+}
+
 func ScoreSimilarityResultsForTest(
 	query string,
 	results []*shared.VectorSearchResult[fragmodel.FragmentPayload],
@@ -102,6 +115,25 @@ func ScoreSimilarityResultsWithThresholdAndDebugForTest(
 	})
 }
 
+func ScoreSimilarityResultsWithFeedbackForTest(
+	query string,
+	results []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+	kb any,
+	topK int,
+	reader FeedbackAggregateReader,
+	options FeedbackBoostOptions,
+) []*fragmodel.SimilarityResult {
+	service := NewService(nil, nil, Infra{FeedbackReader: reader, FeedbackBoostOptions: options})
+	profile := buildSimilarityQueryProfile(query, query, service.newRetrievalAnalyzer())
+	return service.scoreSimilarityResults(context.Background(), profile, results, *snapshotKnowledgeBaseForTest(kb), topK, similarityResultOptions{
+		Trace: similaritySearchTrace{
+			SparseBackend: SparseBackendQdrantBM25ZHV1,
+			QueryProfile:  profile,
+			QueryType:     profile.QueryType,
+		},
+	})
+}
+
 func SearchSimilarityCandidatesForTest(
 	ctx context.Context,
 	service *Service,
@@ -121,6 +153,7 @@ func ResolveHybridSearchConfigForTest(topK int, kb any) HybridSearchConfigForTes
 		SparseWeight:         config.SparseWeight,
 		HybridAlpha:          config.EffectiveHybridAlpha,
 		LegacyWeightUpgraded: config.LegacyWeightUpgraded,
+		FusionAlgorithm:      fusionStrategyOrDefault(config.Fusion).Name(),
 	}
 }
 
@@ -0,0 +1,391 @@
+package retrieval
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/shared"
+	"magic/internal/pkg/ctxmeta"
+)
+
+const (
+	// AuxiliaryFragmentVersionV1 标记当前摘要/问题辅助片段的生成协议，调整提示词时递增以失效缓存。
+	AuxiliaryFragmentVersionV1 = "auxiliary_v1"
+
+	defaultAuxiliaryFragmentTimeout     = 30 * time.Second
+	defaultAuxiliaryFragmentConcurrency = 2
+	defaultAuxiliaryQuestionCount       = 3
+	maxAuxiliaryQuestionCount           = 10
+	maxAuxiliaryGroupChunks             = 8
+	maxAuxiliaryGroupInputRunes         = 6000
+	maxAuxiliaryGroupsPerDocument       = 50
+	maxAuxiliarySummaryRunes            = 300
+	maxAuxiliaryQuestionRunes           = 120
+	auxiliaryFragmentTemperature        = 0.3
+	auxiliaryFragmentMaxTokens          = 1024
+	auxiliaryCacheKeyFixedParts         = 5
+
+	auxiliaryFragmentPrompt = "你是知识库入库助手。下面给出文档标题、章节路径和若干编号片段。" +
+		"请只输出一个 JSON 对象：{\"summary\": \"...\", \"questions\": [{\"question\": \"...\", \"chunk\": 1}]}。" +
+		"summary 用不超过 150 字概括这些片段；questions 给出 %d 个用户最可能提出、且能由片段直接回答的问题，" +
+		"chunk 为能回答该问题的片段编号。不要输出 JSON 以外的内容。"
+)
+
+var errAuxiliaryGenerationUnparsable = errors.New("auxiliary fragment generation is not valid json")
+
+// AuxiliaryFragmentOptions 表示摘要/问题辅助片段生成的运行参数。
+type AuxiliaryFragmentOptions struct {
+	Timeout      time.Duration
+	DefaultModel string
+	Concurrency  int
+}
+
+// AuxiliaryEntry 表示一条待入库的辅助片段内容，SourceIndexes 指向入参片段下标，首个即首选回溯目标。
+type AuxiliaryEntry struct {
+	Type          string
+	Content       string
+	SourceIndexes []int
+}
+
+type auxiliaryGroup struct {
+	indexes     []int
+	sectionPath string
+}
+
+type auxiliaryGeneration struct {
+	Summary   string              `json:"summary"`
+	Questions []auxiliaryQuestion `json:"questions"`
+}
+
+type auxiliaryQuestion struct {
+	Question string `json:"question"`
+	Chunk    int    `json:"chunk,omitempty"`
+}
+
+// UnmarshalJSON 兼容模型直接返回问题字符串数组。
+func (q *auxiliaryQuestion) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*q = auxiliaryQuestion{Question: text}
+		return nil
+	}
+	type alias auxiliaryQuestion
+	var decoded alias
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("unmarshal auxiliary question: %w", err)
+	}
+	*q = auxiliaryQuestion(decoded)
+	return nil
+}
+
+func normalizeAuxiliaryFragmentOptions(options AuxiliaryFragmentOptions) AuxiliaryFragmentOptions {
+	if options.Timeout <= 0 {
+		options.Timeout = defaultAuxiliaryFragmentTimeout
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = defaultAuxiliaryFragmentConcurrency
+	}
+	options.DefaultModel = strings.TrimSpace(options.DefaultModel)
+	return options
+}
+
+func normalizeAuxiliaryQuestionCount(count int) int {
+	if count <= 0 {
+		return defaultAuxiliaryQuestionCount
+	}
+	return min(count, maxAuxiliaryQuestionCount)
+}
+
+// GenerateAuxiliaryEntries 按配置将片段分组，为每组生成一条摘要与若干常见问题。
+//
+// 结果按分组内容缓存，内容不变时重同步不再调用模型；单组生成失败只跳过该组，不影响同步。
+func (s *Service) GenerateAuxiliaryEntries(
+	ctx context.Context,
+	cfg *shared.AuxiliaryFragmentConfig,
+	fragments []*fragmodel.KnowledgeBaseFragment,
+	businessParams *ctxmeta.BusinessParams,
+) []AuxiliaryEntry {
+	model := s.resolveAuxiliaryFragmentModel(cfg)
+	if model == "" {
+		return nil
+	}
+	groups := buildAuxiliaryGroups(fragments, cfg.Scope)
+	if len(groups) == 0 {
+		return nil
+	}
+	questionCount := normalizeAuxiliaryQuestionCount(cfg.QuestionCount)
+	startedAt := time.Now()
+
+	keys := make(map[int]string, len(groups))
+	for index, group := range groups {
+		keys[index] = buildAuxiliaryCacheKey(model, questionCount, fragments, group)
+	}
+	cached := s.loadCachedChunkContexts(ctx, keys)
+	generations := make(map[int]auxiliaryGeneration, len(groups))
+	missing := make([]int, 0, len(groups))
+	for index := range groups {
+		if generation, ok := parseAuxiliaryGeneration(cached[keys[index]]); ok {
+			generations[index] = generation
+			continue
+		}
+		missing = append(missing, index)
+	}
+
+	generated := s.generateAuxiliaryGenerations(ctx, model, questionCount, fragments, groups, missing, businessParams)
+	toCache := make(map[string]string, len(generated))
+	for index, generation := range generated {
+		generations[index] = generation
+		if encoded, err := json.Marshal(generation); err == nil {
+			toCache[keys[index]] = string(encoded)
+		}
+	}
+	s.storeChunkContexts(ctx, toCache)
+
+	entries := make([]AuxiliaryEntry, 0, len(groups)*(questionCount+1))
+	for index, group := range groups {
+		if generation, ok := generations[index]; ok {
+			entries = append(entries, buildAuxiliaryEntries(generation, group, questionCount)...)
+		}
+	}
+	s.logChunkContextStage(ctx, "Knowledge auxiliary fragments resolved", startedAt,
+		"model", model,
+		"group_count", len(groups),
+		"cache_hit", len(groups)-len(missing),
+		"generated", len(generated),
+		"entry_count", len(entries),
+	)
+	return entries
+}
+
+func (s *Service) resolveAuxiliaryFragmentModel(cfg *shared.AuxiliaryFragmentConfig) string {
+	if s == nil || s.chatCompleter == nil || cfg == nil || !cfg.Enabled {
+		return ""
+	}
+	if model := strings.TrimSpace(cfg.ModelName); model != "" {
+		return model
+	}
+	return s.auxiliaryFragmentOptions.DefaultModel
+}
+
+// buildAuxiliaryGroups 按章节（或整篇文档）连续分组，单组片段数与输入长度受预算限制。
+func buildAuxiliaryGroups(
+	fragments []*fragmodel.KnowledgeBaseFragment,
+	scope shared.AuxiliaryFragmentScope,
+) []auxiliaryGroup {
+	splitBySection := scope != shared.AuxiliaryFragmentScopeDocument
+	groups := make([]auxiliaryGroup, 0)
+	var (
+		current      auxiliaryGroup
+		currentRunes int
+	)
+	flush := func() {
+		if len(current.indexes) > 0 {
+			groups = append(groups, current)
+		}
+		current = auxiliaryGroup{}
+		currentRunes = 0
+	}
+	for index, fragment := range fragments {
+		if fragment == nil || fragmodel.IsAuxiliaryFragment(fragment) || strings.TrimSpace(fragment.Content) == "" {
+			continue
+		}
+		sectionPath := resolveSectionPath(fragment.SectionPath, fragment.Metadata)
+		contentRunes := min(utf8.RuneCountInString(fragment.Content), maxAuxiliaryGroupInputRunes)
+		if len(current.indexes) > 0 && ((splitBySection && sectionPath != current.sectionPath) ||
+			len(current.indexes) >= maxAuxiliaryGroupChunks ||
+			currentRunes+contentRunes > maxAuxiliaryGroupInputRunes) {
+			flush()
+		}
+		if len(current.indexes) == 0 {
+			current.sectionPath = sectionPath
+		}
+		current.indexes = append(current.indexes, index)
+		currentRunes += contentRunes
+	}
+	flush()
+	if len(groups) > maxAuxiliaryGroupsPerDocument {
+		groups = groups[:maxAuxiliaryGroupsPerDocument]
+	}
+	return groups
+}
+
+// buildAuxiliaryCacheKey 以协议版本、模型、问题数与分组内各片段内容哈希作为缓存键。
+func buildAuxiliaryCacheKey(
+	model string,
+	questionCount int,
+	fragments []*fragmodel.KnowledgeBaseFragment,
+	group auxiliaryGroup,
+) string {
+	parts := make([]string, 0, len(group.indexes)+auxiliaryCacheKeyFixedParts)
+	parts = append(parts,
+		AuxiliaryFragmentVersionV1,
+		model,
+		strconv.Itoa(questionCount),
+		fragmentDocumentName(fragments[group.indexes[0]]),
+		group.sectionPath,
+	)
+	for _, index := range group.indexes {
+		fragment := fragments[index]
+		contentHash := strings.TrimSpace(fragment.ContentHash)
+		if contentHash == "" {
+			sum := sha256.Sum256([]byte(fragment.Content))
+			contentHash = hex.EncodeToString(sum[:])
+		}
+		parts = append(parts, contentHash)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// buildAuxiliaryEntries 摘要关联组内全部片段；问题优先关联模型指明的片段，编号无效时关联全组。
+func buildAuxiliaryEntries(generation auxiliaryGeneration, group auxiliaryGroup, questionCount int) []AuxiliaryEntry {
+	entries := make([]AuxiliaryEntry, 0, questionCount+1)
+	if generation.Summary != "" {
+		entries = append(entries, AuxiliaryEntry{
+			Type:          fragmodel.AuxiliaryTypeSummary,
+			Content:       generation.Summary,
+			SourceIndexes: slices.Clone(group.indexes),
+		})
+	}
+	seen := make(map[string]struct{}, len(generation.Questions))
+	for _, question := range generation.Questions {
+		if len(seen) >= questionCount {
+			break
+		}
+		if _, exists := seen[question.Question]; exists || question.Question == "" {
+			continue
+		}
+		seen[question.Question] = struct{}{}
+		sourceIndexes := slices.Clone(group.indexes)
+		if question.Chunk >= 1 && question.Chunk <= len(group.indexes) {
+			sourceIndexes = []int{group.indexes[question.Chunk-1]}
+		}
+		entries = append(entries, AuxiliaryEntry{
+			Type:          fragmodel.AuxiliaryTypeQuestion,
+			Content:       question.Question,
+			SourceIndexes: sourceIndexes,
+		})
+	}
+	return entries
+}
+
+// parseAuxiliaryGeneration 解析模型输出或缓存值，容忍 JSON 前后的说明文字与代码块标记。
+func parseAuxiliaryGeneration(raw string) (auxiliaryGeneration, bool) {
+	start := strings.Index(raw, "{")
+	end := strings.LastIndex(raw, "}")
+	if start < 0 || end <= start {
+		return auxiliaryGeneration{}, false
+	}
+	var decoded auxiliaryGeneration
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &decoded); err != nil {
+		return auxiliaryGeneration{}, false
+	}
+	generation := auxiliaryGeneration{
+		Summary:   truncateRunes(normalizeWhitespace(decoded.Summary), maxAuxiliarySummaryRunes),
+		Questions: make([]auxiliaryQuestion, 0, len(decoded.Questions)),
+	}
+	for _, question := range decoded.Questions {
+		text := truncateRunes(normalizeWhitespace(question.Question), maxAuxiliaryQuestionRunes)
+		if text == "" {
+			continue
+		}
+		generation.Questions = append(generation.Questions, auxiliaryQuestion{Question: text, Chunk: question.Chunk})
+	}
+	return generation, generation.Summary != "" || len(generation.Questions) > 0
+}
+
+// generateAuxiliaryGenerations 以有限并发调用对话模型，失败的分组不返回结果。
+func (s *Service) generateAuxiliaryGenerations(
+	ctx context.Context,
+	model string,
+	questionCount int,
+	fragments []*fragmodel.KnowledgeBaseFragment,
+	groups []auxiliaryGroup,
+	indexes []int,
+	businessParams *ctxmeta.BusinessParams,
+) map[int]auxiliaryGeneration {
+	if len(indexes) == 0 {
+		return nil
+	}
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result = make(map[int]auxiliaryGeneration, len(indexes))
+		slots  = make(chan struct{}, s.auxiliaryFragmentOptions.Concurrency)
+	)
+	for _, index := range indexes {
+		group := groups[index]
+		slots <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			generation, err := s.generateAuxiliaryGeneration(ctx, model, questionCount, fragments, group, businessParams)
+			if err != nil {
+				s.logChunkContextWarn(ctx, "Knowledge auxiliary fragment generation failed",
+					"model", model,
+					"section_path", group.sectionPath,
+					"chunk_count", len(group.indexes),
+					"error", err,
+				)
+				return
+			}
+			mu.Lock()
+			result[index] = generation
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	return result
+}
+
+func (s *Service) generateAuxiliaryGeneration(
+	ctx context.Context,
+	model string,
+	questionCount int,
+	fragments []*fragmodel.KnowledgeBaseFragment,
+	group auxiliaryGroup,
+	businessParams *ctxmeta.BusinessParams,
+) (auxiliaryGeneration, error) {
+	generateCtx, cancel := context.WithTimeout(ctx, s.auxiliaryFragmentOptions.Timeout)
+	defer cancel()
+	var input strings.Builder
+	input.WriteString("文档标题：")
+	input.WriteString(fragmentDocumentName(fragments[group.indexes[0]]))
+	if group.sectionPath != "" {
+		input.WriteString("\n章节路径：")
+		input.WriteString(group.sectionPath)
+	}
+	for position, index := range group.indexes {
+		fmt.Fprintf(&input, "\n[片段 %d]\n", position+1)
+		input.WriteString(truncateRunes(strings.TrimSpace(fragments[index].Content), maxAuxiliaryGroupInputRunes))
+	}
+
+	content, err := s.chatCompleter.CompleteChat(generateCtx, ChatCompletionRequest{
+		Model: model,
+		Messages: []ChatMessage{
+			{Role: chatRoleSystem, Content: fmt.Sprintf(auxiliaryFragmentPrompt, questionCount)},
+			{Role: chatRoleUser, Content: input.String()},
+		},
+		Temperature:    auxiliaryFragmentTemperature,
+		MaxTokens:      auxiliaryFragmentMaxTokens,
+		BusinessParams: businessParams,
+	})
+	if err != nil {
+		return auxiliaryGeneration{}, fmt.Errorf("complete auxiliary fragments: %w", err)
+	}
+	generation, ok := parseAuxiliaryGeneration(content)
+	if !ok {
+		return auxiliaryGeneration{}, errAuxiliaryGenerationUnparsable
+	}
+	return generation, nil
+}
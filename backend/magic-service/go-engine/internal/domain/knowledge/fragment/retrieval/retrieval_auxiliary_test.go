@@ -0,0 +1,256 @@
+package retrieval_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/fragment/retrieval"
+	"magic/internal/domain/knowledge/shared"
+	sharedsnapshot "magic/internal/domain/knowledge/shared/snapshot"
+)
+
+const auxiliaryGenerationForTest = `好的：{"summary":"差旅报销的申请时限与审批流程。",` +
+	`"questions":[{"question":"差旅报销的申请期限是多久？","chunk":1},{"question":"报销需要谁审批？","chunk":9}]}`
+
+type fragmentReaderStub struct {
+	mu        sync.Mutex
+	fragments map[string]*fragmodel.KnowledgeBaseFragment
+	requested [][]string
+}
+
+func (s *fragmentReaderStub) FindByID(context.Context, int64) (*fragmodel.KnowledgeBaseFragment, error) {
+	return nil, nil
+}
+
+func (s *fragmentReaderStub) FindByPointIDs(_ context.Context, pointIDs []string) ([]*fragmodel.KnowledgeBaseFragment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requested = append(s.requested, slices.Clone(pointIDs))
+	result := make([]*fragmodel.KnowledgeBaseFragment, 0, len(pointIDs))
+	for _, pointID := range pointIDs {
+		if fragment, ok := s.fragments[pointID]; ok {
+			result = append(result, fragment)
+		}
+	}
+	return result, nil
+}
+
+func (s *fragmentReaderStub) FindByIDs(context.Context, []int64) ([]*fragmodel.KnowledgeBaseFragment, error) {
+	return nil, nil
+}
+
+func (s *fragmentReaderStub) List(context.Context, *fragmodel.Query) ([]*fragmodel.KnowledgeBaseFragment, int64, error) {
+	return nil, 0, nil
+}
+
+func (s *fragmentReaderStub) ListByDocument(
+	context.Context,
+	string,
+	string,
+	int,
+	int,
+) ([]*fragmodel.KnowledgeBaseFragment, int64, error) {
+	return nil, 0, nil
+}
+
+func (s *fragmentReaderStub) ListByKnowledgeBase(
+	context.Context,
+	string,
+	int,
+	int,
+) ([]*fragmodel.KnowledgeBaseFragment, int64, error) {
+	return nil, 0, nil
+}
+
+func (s *fragmentReaderStub) ListPendingSync(context.Context, string, int) ([]*fragmodel.KnowledgeBaseFragment, error) {
+	return nil, nil
+}
+
+func (s *fragmentReaderStub) CountByKnowledgeBase(context.Context, string) (int64, error) {
+	return 0, nil
+}
+
+func (s *fragmentReaderStub) CountSyncedByKnowledgeBase(context.Context, string) (int64, error) {
+	return 0, nil
+}
+
+func (s *fragmentReaderStub) ListMissingDocumentCode(
+	context.Context,
+	fragmodel.MissingDocumentCodeQuery,
+) ([]*fragmodel.KnowledgeBaseFragment, error) {
+	return nil, nil
+}
+
+func auxiliarySourceFragmentsForTest() []*fragmodel.KnowledgeBaseFragment {
+	return []*fragmodel.KnowledgeBaseFragment{
+		{DocumentName: "差旅报销制度", Content: "申请期限为 30 天", ContentHash: "hash-1", SectionPath: "报销 > 时限", ChunkIndex: 0},
+		{DocumentName: "差旅报销制度", Content: "由部门负责人审批", ContentHash: "hash-2", SectionPath: "报销 > 时限", ChunkIndex: 1},
+		{DocumentName: "差旅报销制度", Content: "住宿标准按城市分级", ContentHash: "hash-3", SectionPath: "标准 > 住宿", ChunkIndex: 2},
+	}
+}
+
+func TestGenerateAuxiliaryEntriesBuildsSummaryAndQuestionsWithCache(t *testing.T) {
+	t.Parallel()
+
+	chat := &chatCompleterStub{paraphrases: auxiliaryGenerationForTest}
+	cache := &chunkContextCacheStub{}
+	service := retrieval.NewService(nil, nil, retrieval.Infra{
+		ChatCompleter:            chat,
+		ChunkContextCache:        cache,
+		AuxiliaryFragmentOptions: retrieval.AuxiliaryFragmentOptions{DefaultModel: "qwen-plus"},
+	})
+	cfg := &shared.AuxiliaryFragmentConfig{Enabled: true, QuestionCount: 2}
+
+	first := service.GenerateAuxiliaryEntries(context.Background(), cfg, auxiliarySourceFragmentsForTest(), nil)
+	second := service.GenerateAuxiliaryEntries(context.Background(), cfg, auxiliarySourceFragmentsForTest(), nil)
+
+	if chat.callCount() != 2 {
+		t.Fatalf("expected one generation per section on first build and cache hits afterwards, got %d calls", chat.callCount())
+	}
+	if len(first) != 6 || len(second) != len(first) {
+		t.Fatalf("expected summary and two questions per section, got %#v / %#v", first, second)
+	}
+	summary := first[0]
+	if summary.Type != fragmodel.AuxiliaryTypeSummary || summary.Content != "差旅报销的申请时限与审批流程。" ||
+		!slices.Equal(summary.SourceIndexes, []int{0, 1}) {
+		t.Fatalf("unexpected section summary entry: %#v", summary)
+	}
+	if question := first[1]; question.Type != fragmodel.AuxiliaryTypeQuestion || !slices.Equal(question.SourceIndexes, []int{0}) {
+		t.Fatalf("expected question to link the chunk named by the model, got %#v", question)
+	}
+	if question := first[2]; !slices.Equal(question.SourceIndexes, []int{0, 1}) {
+		t.Fatalf("expected invalid chunk number to link the whole group, got %#v", question)
+	}
+	if tail := first[3]; !slices.Equal(tail.SourceIndexes, []int{2}) {
+		t.Fatalf("expected second section to be generated separately, got %#v", tail)
+	}
+	if chat.calls[0].Model != "qwen-plus" {
+		t.Fatalf("expected default auxiliary model, got %q", chat.calls[0].Model)
+	}
+}
+
+func TestGenerateAuxiliaryEntriesSkipsDisabledConfigAndFailedGeneration(t *testing.T) {
+	t.Parallel()
+
+	chat := &chatCompleterStub{err: errChatCompleterUnavailable}
+	cache := &chunkContextCacheStub{}
+	service := retrieval.NewService(nil, nil, retrieval.Infra{ChatCompleter: chat, ChunkContextCache: cache})
+
+	disabled := service.GenerateAuxiliaryEntries(
+		context.Background(),
+		&shared.AuxiliaryFragmentConfig{ModelName: "qwen-plus"},
+		auxiliarySourceFragmentsForTest(),
+		nil,
+	)
+	if len(disabled) != 0 || chat.callCount() != 0 {
+		t.Fatalf("expected disabled config not to generate, got %#v with %d calls", disabled, chat.callCount())
+	}
+
+	failed := service.GenerateAuxiliaryEntries(
+		context.Background(),
+		&shared.AuxiliaryFragmentConfig{Enabled: true, Scope: shared.AuxiliaryFragmentScopeDocument, ModelName: "qwen-plus"},
+		auxiliarySourceFragmentsForTest(),
+		nil,
+	)
+	if len(failed) != 0 || chat.callCount() != 1 {
+		t.Fatalf("expected document scope failure to yield nothing after one call, got %#v with %d calls", failed, chat.callCount())
+	}
+	if len(cache.contexts) != 0 {
+		t.Fatalf("expected failed generation not to be cached, got %#v", cache.contexts)
+	}
+}
+
+func auxiliarySearchResultForTest(id string, score float64, sourcePointIDs ...string) *shared.VectorSearchResult[fragmodel.FragmentPayload] {
+	result := expansionSearchResult(id, "DOC-1", score, "差旅报销的申请期限是多久？")
+	result.Payload.Metadata = map[string]any{
+		"ext": map[string]any{
+			fragmodel.MetadataAuxiliaryTypeKey:           fragmodel.AuxiliaryTypeQuestion,
+			fragmodel.MetadataAuxiliarySourcePointIDsKey: sourcePointIDs,
+		},
+	}
+	return result
+}
+
+func runAuxiliarySimilarityForTest(
+	t *testing.T,
+	reader *fragmentReaderStub,
+	hits []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+) []*fragmodel.SimilarityResult {
+	t.Helper()
+
+	service := retrieval.NewService(
+		reader,
+		embeddingServiceStub{},
+		retrieval.Infra{
+			VectorDataRepo:        &vectorDataRepoStub{denseResponsePlan: [][]*shared.VectorSearchResult[fragmodel.FragmentPayload]{hits}},
+			MetaReader:            managedBM25MetaReader(),
+			DefaultEmbeddingModel: "text-embedding-3-small",
+		},
+	)
+	results, err := service.Similarity(
+		context.Background(),
+		&sharedsnapshot.KnowledgeBaseRuntimeSnapshot{Code: "KB1"},
+		retrieval.SimilarityRequest{Query: "报销期限", TopK: 3, CandidateScoreThreshold: 0.1},
+	)
+	if err != nil {
+		t.Fatalf("Similarity returned error: %v", err)
+	}
+	return results
+}
+
+func TestSimilarityResolvesAuxiliaryHitToFetchedSourceFragment(t *testing.T) {
+	t.Parallel()
+
+	reader := &fragmentReaderStub{fragments: map[string]*fragmodel.KnowledgeBaseFragment{
+		"SOURCE-1": {
+			ID:            7,
+			PointID:       "SOURCE-1",
+			KnowledgeCode: "KB1",
+			DocumentCode:  "DOC-1",
+			DocumentName:  "差旅报销制度",
+			Content:       "申请期限为 30 天",
+			Metadata:      map[string]any{},
+		},
+	}}
+
+	results := runAuxiliarySimilarityForTest(t, reader, []*shared.VectorSearchResult[fragmodel.FragmentPayload]{
+		auxiliarySearchResultForTest("AUX-1", 0.91, "SOURCE-1"),
+	})
+
+	if len(results) != 1 {
+		t.Fatalf("expected auxiliary hit to resolve to one source result, got %#v", results)
+	}
+	result := results[0]
+	if result.Content != "申请期限为 30 天" || result.FragmentID != 7 {
+		t.Fatalf("expected source fragment content, got %#v", result)
+	}
+	match, ok := result.Metadata[retrieval.MetadataAuxiliaryMatchKey].(map[string]any)
+	if !ok || match["type"] != fragmodel.AuxiliaryTypeQuestion || match["point_id"] != "AUX-1" {
+		t.Fatalf("expected auxiliary match metadata, got %#v", result.Metadata)
+	}
+	if len(reader.requested) != 1 || !slices.Equal(reader.requested[0], []string{"SOURCE-1"}) {
+		t.Fatalf("expected missing source to be fetched once, got %#v", reader.requested)
+	}
+}
+
+func TestSimilarityMergesAuxiliaryHitWithRecalledSource(t *testing.T) {
+	t.Parallel()
+
+	reader := &fragmentReaderStub{}
+	source := expansionSearchResult("SOURCE-1", "DOC-1", 0.62, "申请期限为 30 天")
+
+	results := runAuxiliarySimilarityForTest(t, reader, []*shared.VectorSearchResult[fragmodel.FragmentPayload]{
+		source,
+		auxiliarySearchResultForTest("AUX-1", 0.91, "SOURCE-1", "SOURCE-MISSING"),
+	})
+
+	if len(results) != 1 || results[0].Content != "申请期限为 30 天" {
+		t.Fatalf("expected auxiliary hit to merge into recalled source, got %#v", results)
+	}
+	if _, ok := results[0].Metadata[retrieval.MetadataAuxiliaryMatchKey]; !ok {
+		t.Fatalf("expected merged result to keep auxiliary match metadata, got %#v", results[0].Metadata)
+	}
+}
@@ -0,0 +1,327 @@
+package retrieval
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/shared"
+	sharedsnapshot "magic/internal/domain/knowledge/shared/snapshot"
+	"magic/internal/pkg/ctxmeta"
+	"magic/internal/pkg/logkey"
+)
+
+const (
+	// RetrievalTextVersionContextualV1 标记拼接文档标题前缀的上下文增强检索文本。
+	RetrievalTextVersionContextualV1 = "contextual_v1"
+	// RetrievalTextVersionContextualLLMV1 标记在标题前缀之外再拼接模型生成上下文的检索文本。
+	RetrievalTextVersionContextualLLMV1 = "contextual_llm_v1"
+
+	defaultChunkContextTimeout     = 10 * time.Second
+	defaultChunkContextConcurrency = 4
+	maxChunkContextRunes           = 200
+	maxChunkContextInputRunes      = 4000
+	chunkContextTemperature        = 0.2
+	chunkContextMaxTokens          = 256
+	chunkContextPartCapacity       = 3
+
+	chunkContextPrompt = "你是知识库入库助手。下面给出文档标题、章节路径和其中的一个片段。" +
+		"请用一句话（不超过 80 字）说明该片段在文档中的上下文，例如它属于哪项制度、产品或流程，" +
+		"以便单独检索该片段时也能理解。只输出这句话，不要复述片段内容。"
+)
+
+// ChunkContextCache 定义模型生成片段上下文的缓存能力，key 由内容哈希等输入派生。
+type ChunkContextCache interface {
+	GetChunkContexts(ctx context.Context, keys []string) (map[string]string, error)
+	SetChunkContexts(ctx context.Context, contexts map[string]string) error
+}
+
+// ContextualEnrichmentOptions 表示入库上下文增强的运行参数。
+type ContextualEnrichmentOptions struct {
+	Timeout      time.Duration
+	DefaultModel string
+	Concurrency  int
+}
+
+func normalizeContextualEnrichmentOptions(options ContextualEnrichmentOptions) ContextualEnrichmentOptions {
+	if options.Timeout <= 0 {
+		options.Timeout = defaultChunkContextTimeout
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = defaultChunkContextConcurrency
+	}
+	options.DefaultModel = strings.TrimSpace(options.DefaultModel)
+	return options
+}
+
+// ResolveRetrievalTextVersion 根据知识库配置返回入库片段应使用的检索文本版本。
+func ResolveRetrievalTextVersion(cfg *shared.ContextualEnrichmentConfig) string {
+	if cfg == nil || !cfg.Enabled {
+		return RetrievalTextVersionV1
+	}
+	if shared.ContextualEnrichmentMode(strings.TrimSpace(string(cfg.Mode))) == shared.ContextualEnrichmentModeLLM {
+		return RetrievalTextVersionContextualLLMV1
+	}
+	return RetrievalTextVersionContextualV1
+}
+
+// FragmentRetrievalTextVersion 返回片段 metadata 记录的检索文本版本，缺省为 v1。
+func FragmentRetrievalTextVersion(fragment *fragmodel.KnowledgeBaseFragment) string {
+	if fragment == nil {
+		return RetrievalTextVersionV1
+	}
+	switch version := metadataStringValue(fragment.Metadata, "retrieval_text_version"); version {
+	case RetrievalTextVersionContextualV1, RetrievalTextVersionContextualLLMV1:
+		return version
+	default:
+		return RetrievalTextVersionV1
+	}
+}
+
+// BuildEmbeddingTexts 构建片段的向量化文本，顺序与入参一致。
+//
+// 上下文增强版本的片段会拼接文档标题，LLM 版本再拼接模型生成的一句上下文；
+// 生成失败时退化为仅标题前缀，不影响同步。展示内容始终是原文。
+func (s *Service) BuildEmbeddingTexts(
+	ctx context.Context,
+	kb *sharedsnapshot.KnowledgeBaseRuntimeSnapshot,
+	fragments []*fragmodel.KnowledgeBaseFragment,
+	businessParams *ctxmeta.BusinessParams,
+) []string {
+	analyzer := s.newRetrievalAnalyzer()
+	chunkContexts := s.resolveChunkContexts(ctx, kb, fragments, businessParams)
+	texts := make([]string, len(fragments))
+	for i, fragment := range fragments {
+		baseText := buildRetrievalTextFromFragmentWithAnalyzer(fragment, analyzer)
+		if FragmentRetrievalTextVersion(fragment) == RetrievalTextVersionV1 {
+			texts[i] = baseText
+			continue
+		}
+		texts[i] = buildContextualRetrievalText(fragmentDocumentName(fragment), chunkContexts[i], baseText)
+	}
+	return texts
+}
+
+func buildContextualRetrievalText(documentName, chunkContext, baseText string) string {
+	parts := make([]string, 0, chunkContextPartCapacity)
+	if documentName = strings.TrimSpace(documentName); documentName != "" {
+		parts = append(parts, documentName)
+	}
+	if chunkContext = strings.TrimSpace(chunkContext); chunkContext != "" {
+		parts = append(parts, chunkContext)
+	}
+	if baseText != "" {
+		parts = append(parts, baseText)
+	}
+	return normalizeWhitespace(strings.Join(parts, "\n"))
+}
+
+func fragmentDocumentName(fragment *fragmodel.KnowledgeBaseFragment) string {
+	if fragment == nil {
+		return ""
+	}
+	if name := strings.TrimSpace(fragment.DocumentName); name != "" {
+		return name
+	}
+	return metadataStringValue(fragment.Metadata, "document_name")
+}
+
+// resolveChunkContexts 返回 LLM 版本片段的上下文说明（按入参下标），优先读缓存，未命中再调用模型。
+func (s *Service) resolveChunkContexts(
+	ctx context.Context,
+	kb *sharedsnapshot.KnowledgeBaseRuntimeSnapshot,
+	fragments []*fragmodel.KnowledgeBaseFragment,
+	businessParams *ctxmeta.BusinessParams,
+) map[int]string {
+	model := s.resolveChunkContextModel(kb)
+	if model == "" {
+		return nil
+	}
+	keys := make(map[int]string)
+	for i, fragment := range fragments {
+		if FragmentRetrievalTextVersion(fragment) == RetrievalTextVersionContextualLLMV1 {
+			keys[i] = buildChunkContextCacheKey(model, fragment)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	startedAt := time.Now()
+	cached := s.loadCachedChunkContexts(ctx, keys)
+	result := make(map[int]string, len(keys))
+	missing := make([]int, 0, len(keys))
+	for index, key := range keys {
+		if chunkContext, ok := cached[key]; ok {
+			result[index] = chunkContext
+			continue
+		}
+		missing = append(missing, index)
+	}
+
+	generated := s.generateChunkContexts(ctx, model, fragments, missing, businessParams)
+	toCache := make(map[string]string, len(generated))
+	for index, chunkContext := range generated {
+		result[index] = chunkContext
+		toCache[keys[index]] = chunkContext
+	}
+	s.storeChunkContexts(ctx, toCache)
+
+	s.logChunkContextStage(ctx, "Knowledge chunk contexts resolved", startedAt,
+		"model", model,
+		"fragment_count", len(keys),
+		"cache_hit", len(keys)-len(missing),
+		"generated", len(generated),
+	)
+	return result
+}
+
+func (s *Service) resolveChunkContextModel(kb *sharedsnapshot.KnowledgeBaseRuntimeSnapshot) string {
+	if s == nil || s.chatCompleter == nil {
+		return ""
+	}
+	if kb != nil && kb.RetrieveConfig != nil && kb.RetrieveConfig.ContextualEnrichment != nil {
+		if model := strings.TrimSpace(kb.RetrieveConfig.ContextualEnrichment.ModelName); model != "" {
+			return model
+		}
+	}
+	return s.contextualEnrichmentOptions.DefaultModel
+}
+
+// buildChunkContextCacheKey 以模型、文档标题、章节路径与片段内容哈希作为缓存键，内容不变时重同步直接命中。
+func buildChunkContextCacheKey(model string, fragment *fragmodel.KnowledgeBaseFragment) string {
+	contentHash := strings.TrimSpace(fragment.ContentHash)
+	if contentHash == "" {
+		sum := sha256.Sum256([]byte(fragment.Content))
+		contentHash = hex.EncodeToString(sum[:])
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		RetrievalTextVersionContextualLLMV1,
+		model,
+		fragmentDocumentName(fragment),
+		resolveSectionPath(fragment.SectionPath, fragment.Metadata),
+		contentHash,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Service) loadCachedChunkContexts(ctx context.Context, keys map[int]string) map[string]string {
+	if s.chunkContextCache == nil {
+		return nil
+	}
+	lookup := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lookup = append(lookup, key)
+	}
+	cached, err := s.chunkContextCache.GetChunkContexts(ctx, lookup)
+	if err != nil {
+		s.logChunkContextWarn(ctx, "Knowledge chunk context cache read failed", "error", err)
+		return nil
+	}
+	return cached
+}
+
+func (s *Service) storeChunkContexts(ctx context.Context, contexts map[string]string) {
+	if s.chunkContextCache == nil || len(contexts) == 0 {
+		return
+	}
+	if err := s.chunkContextCache.SetChunkContexts(ctx, contexts); err != nil {
+		s.logChunkContextWarn(ctx, "Knowledge chunk context cache write failed", "error", err)
+	}
+}
+
+// generateChunkContexts 以有限并发调用对话模型，失败的片段不返回结果。
+func (s *Service) generateChunkContexts(
+	ctx context.Context,
+	model string,
+	fragments []*fragmodel.KnowledgeBaseFragment,
+	indexes []int,
+	businessParams *ctxmeta.BusinessParams,
+) map[int]string {
+	if len(indexes) == 0 {
+		return nil
+	}
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result = make(map[int]string, len(indexes))
+		slots  = make(chan struct{}, s.contextualEnrichmentOptions.Concurrency)
+	)
+	for _, index := range indexes {
+		fragment := fragments[index]
+		slots <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			chunkContext, err := s.generateChunkContext(ctx, model, fragment, businessParams)
+			if err != nil {
+				s.logChunkContextWarn(ctx, "Knowledge chunk context generation failed",
+					"model", model,
+					"fragment_id", fragment.ID,
+					"error", err,
+				)
+				return
+			}
+			if chunkContext == "" {
+				return
+			}
+			mu.Lock()
+			result[index] = chunkContext
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	return result
+}
+
+func (s *Service) generateChunkContext(
+	ctx context.Context,
+	model string,
+	fragment *fragmodel.KnowledgeBaseFragment,
+	businessParams *ctxmeta.BusinessParams,
+) (string, error) {
+	generateCtx, cancel := context.WithTimeout(ctx, s.contextualEnrichmentOptions.Timeout)
+	defer cancel()
+	var input strings.Builder
+	input.WriteString("文档标题：")
+	input.WriteString(fragmentDocumentName(fragment))
+	if sectionPath := resolveSectionPath(fragment.SectionPath, fragment.Metadata); sectionPath != "" {
+		input.WriteString("\n章节路径：")
+		input.WriteString(sectionPath)
+	}
+	input.WriteString("\n片段：\n")
+	input.WriteString(truncateRunes(strings.TrimSpace(fragment.Content), maxChunkContextInputRunes))
+
+	content, err := s.chatCompleter.CompleteChat(generateCtx, ChatCompletionRequest{
+		Model: model,
+		Messages: []ChatMessage{
+			{Role: chatRoleSystem, Content: chunkContextPrompt},
+			{Role: chatRoleUser, Content: input.String()},
+		},
+		Temperature:    chunkContextTemperature,
+		MaxTokens:      chunkContextMaxTokens,
+		BusinessParams: businessParams,
+	})
+	if err != nil {
+		return "", fmt.Errorf("complete chunk context: %w", err)
+	}
+	return truncateRunes(normalizeWhitespace(content), maxChunkContextRunes), nil
+}
+
+func (s *Service) logChunkContextStage(ctx context.Context, message string, startedAt time.Time, fields ...any) {
+	if s.logger == nil {
+		return
+	}
+	s.logger.InfoContext(ctx, message, append([]any{logkey.DurationMS, logkey.DurationToMS(time.Since(startedAt))}, fields...)...)
+}
+
+func (s *Service) logChunkContextWarn(ctx context.Context, message string, fields ...any) {
+	if s.logger == nil {
+		return
+	}
+	s.logger.KnowledgeWarnContext(ctx, message, fields...)
+}
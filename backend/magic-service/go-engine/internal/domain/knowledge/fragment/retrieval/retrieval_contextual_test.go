@@ -0,0 +1,162 @@
+package retrieval_test
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"sync"
+	"testing"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/fragment/retrieval"
+	"magic/internal/domain/knowledge/shared"
+	sharedsnapshot "magic/internal/domain/knowledge/shared/snapshot"
+)
+
+type chunkContextCacheStub struct {
+	mu       sync.Mutex
+	contexts map[string]string
+	gets     int
+}
+
+func (c *chunkContextCacheStub) GetChunkContexts(_ context.Context, keys []string) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gets++
+	result := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if value, ok := c.contexts[key]; ok {
+			result[key] = value
+		}
+	}
+	return result, nil
+}
+
+func (c *chunkContextCacheStub) SetChunkContexts(_ context.Context, contexts map[string]string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.contexts == nil {
+		c.contexts = make(map[string]string, len(contexts))
+	}
+	maps.Copy(c.contexts, contexts)
+	return nil
+}
+
+func contextualFragmentForTest(version string) *fragmodel.KnowledgeBaseFragment {
+	return &fragmodel.KnowledgeBaseFragment{
+		DocumentName: "差旅报销制度",
+		Content:      "申请期限为 30 天",
+		ContentHash:  "hash-1",
+		SectionPath:  "报销 > 时限",
+		Metadata:     map[string]any{"retrieval_text_version": version},
+	}
+}
+
+func contextualEnrichmentKnowledgeBase(mode shared.ContextualEnrichmentMode) *sharedsnapshot.KnowledgeBaseRuntimeSnapshot {
+	return &sharedsnapshot.KnowledgeBaseRuntimeSnapshot{
+		Code: "KB1",
+		RetrieveConfig: &shared.RetrieveConfig{
+			ContextualEnrichment: &shared.ContextualEnrichmentConfig{Enabled: true, Mode: mode, ModelName: "qwen-plus"},
+		},
+	}
+}
+
+func TestResolveRetrievalTextVersion(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		cfg  *shared.ContextualEnrichmentConfig
+		want string
+	}{
+		{name: "nil", cfg: nil, want: retrieval.RetrievalTextVersionV1},
+		{name: "disabled", cfg: &shared.ContextualEnrichmentConfig{Mode: shared.ContextualEnrichmentModeLLM}, want: retrieval.RetrievalTextVersionV1},
+		{name: "default prefix", cfg: &shared.ContextualEnrichmentConfig{Enabled: true}, want: retrieval.RetrievalTextVersionContextualV1},
+		{name: "llm", cfg: &shared.ContextualEnrichmentConfig{Enabled: true, Mode: shared.ContextualEnrichmentModeLLM}, want: retrieval.RetrievalTextVersionContextualLLMV1},
+	}
+	for _, tc := range cases {
+		if got := retrieval.ResolveRetrievalTextVersion(tc.cfg); got != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestBuildEmbeddingTextsAddsDocumentPrefixOnlyForContextualVersion(t *testing.T) {
+	t.Parallel()
+
+	chat := &chatCompleterStub{paraphrases: "should not be used"}
+	service := retrieval.NewService(nil, nil, retrieval.Infra{ChatCompleter: chat})
+	plain := contextualFragmentForTest(retrieval.RetrievalTextVersionV1)
+	prefixed := contextualFragmentForTest(retrieval.RetrievalTextVersionContextualV1)
+
+	texts := service.BuildEmbeddingTexts(
+		context.Background(),
+		contextualEnrichmentKnowledgeBase(shared.ContextualEnrichmentModePrefix),
+		[]*fragmodel.KnowledgeBaseFragment{plain, prefixed},
+		nil,
+	)
+
+	if texts[0] != service.BuildRetrievalTextFromFragment(plain) {
+		t.Fatalf("expected v1 text to stay unchanged, got %q", texts[0])
+	}
+	if !strings.HasPrefix(texts[1], "差旅报销制度 ") || !strings.HasSuffix(texts[1], texts[0]) {
+		t.Fatalf("expected document title prefix before retrieval text, got %q", texts[1])
+	}
+	if chat.callCount() != 0 {
+		t.Fatalf("expected prefix mode not to call chat model, got %d calls", chat.callCount())
+	}
+	if prefixed.Content != "申请期限为 30 天" {
+		t.Fatalf("expected display content to stay original, got %q", prefixed.Content)
+	}
+}
+
+func TestBuildEmbeddingTextsGeneratesAndCachesChunkContext(t *testing.T) {
+	t.Parallel()
+
+	chat := &chatCompleterStub{paraphrases: "本段规定差旅费用报销的申请时限。"}
+	cache := &chunkContextCacheStub{}
+	service := retrieval.NewService(nil, nil, retrieval.Infra{ChatCompleter: chat, ChunkContextCache: cache})
+	kb := contextualEnrichmentKnowledgeBase(shared.ContextualEnrichmentModeLLM)
+
+	first := service.BuildEmbeddingTexts(context.Background(), kb, []*fragmodel.KnowledgeBaseFragment{
+		contextualFragmentForTest(retrieval.RetrievalTextVersionContextualLLMV1),
+	}, nil)
+	second := service.BuildEmbeddingTexts(context.Background(), kb, []*fragmodel.KnowledgeBaseFragment{
+		contextualFragmentForTest(retrieval.RetrievalTextVersionContextualLLMV1),
+	}, nil)
+
+	if !strings.Contains(first[0], "本段规定差旅费用报销的申请时限。") || !strings.Contains(first[0], "申请期限为 30 天") {
+		t.Fatalf("expected generated context and content in embedding text, got %q", first[0])
+	}
+	if second[0] != first[0] {
+		t.Fatalf("expected cached context to produce identical text, got %q vs %q", second[0], first[0])
+	}
+	if chat.callCount() != 1 {
+		t.Fatalf("expected second build to hit cache, got %d chat calls", chat.callCount())
+	}
+	if chat.calls[0].Model != "qwen-plus" {
+		t.Fatalf("expected knowledge base model, got %q", chat.calls[0].Model)
+	}
+}
+
+func TestBuildEmbeddingTextsFallsBackToPrefixWhenGenerationFails(t *testing.T) {
+	t.Parallel()
+
+	chat := &chatCompleterStub{err: errChatCompleterUnavailable}
+	cache := &chunkContextCacheStub{}
+	service := retrieval.NewService(nil, nil, retrieval.Infra{ChatCompleter: chat, ChunkContextCache: cache})
+
+	texts := service.BuildEmbeddingTexts(
+		context.Background(),
+		contextualEnrichmentKnowledgeBase(shared.ContextualEnrichmentModeLLM),
+		[]*fragmodel.KnowledgeBaseFragment{contextualFragmentForTest(retrieval.RetrievalTextVersionContextualLLMV1)},
+		nil,
+	)
+
+	if !strings.HasPrefix(texts[0], "差旅报销制度 ") || !strings.Contains(texts[0], "申请期限为 30 天") {
+		t.Fatalf("expected title prefix fallback, got %q", texts[0])
+	}
+	if len(cache.contexts) != 0 {
+		t.Fatalf("expected failed generation not to be cached, got %#v", cache.contexts)
+	}
+}
@@ -0,0 +1,231 @@
+package retrieval
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	sharedsnapshot "magic/internal/domain/knowledge/shared/snapshot"
+)
+
+const (
+	// GraphEntitiesMetadataKey 表示 payload metadata 中的实体节点字段。
+	GraphEntitiesMetadataKey = "graph_entities"
+	// GraphRelationsMetadataKey 表示 payload metadata 中的实体关系边字段。
+	GraphRelationsMetadataKey = "graph_relations"
+	// GraphEntitiesFilterKey 表示图召回使用的 payload 过滤字段。
+	GraphEntitiesFilterKey = "metadata." + GraphEntitiesMetadataKey
+
+	graphRelationSeparator    = "|"
+	maxFragmentGraphEntities  = 32
+	maxFragmentGraphRelations = 64
+	maxQueryGraphEntities     = 16
+	minGraphEntityRunes       = 2
+	maxGraphEntityRunes       = 48
+	maxGraphOrgPrefixRunes    = 12
+)
+
+var (
+	graphQuotedEntityRegex   = regexp.MustCompile(`[《「『“"【]([^《》「」『』“”"【】\n]{2,48})[》」』”"】]`)
+	graphLatinEntityRegex    = regexp.MustCompile(`\b[A-Z][A-Za-z0-9&\-]*(?:\s+[A-Z][A-Za-z0-9&\-]*)*\b`)
+	graphCodeEntityRegex     = regexp.MustCompile(`\b[A-Za-z][A-Za-z0-9_\-]*\d[A-Za-z0-9_\-]*\b|\b\d+[A-Za-z][A-Za-z0-9_\-]*\b`)
+	graphOrgSuffixRegex      = regexp.MustCompile(`(?:有限责任公司|股份有限公司|有限公司|股份公司|集团|公司|银行|大学|学院|医院|研究院|研究所|事务所|委员会|协会)`)
+	graphPropertyLineRegex   = regexp.MustCompile(`^\s*([^\s:]{1,12})\s*:\s*(.{2,48}?)\s*$`)
+	graphLatinStopEntityTerm = map[string]struct{}{
+		"a": {}, "an": {}, "and": {}, "for": {}, "in": {}, "it": {}, "of": {}, "on": {}, "or": {},
+		"the": {}, "this": {}, "that": {}, "to": {}, "we": {}, "you": {}, "if": {}, "is": {},
+	}
+)
+
+// graphOrgBoundaryRunes 是向左扩展机构名时遇到即停止的虚词。
+const graphOrgBoundaryRunes = "与和及跟同在是由从向对给被把等的了"
+
+// FragmentGraph 表示从单个片段抽取的轻量实体关系图。
+type FragmentGraph struct {
+	Entities  []string
+	Relations []string
+}
+
+// GraphRetrievalEnabled 判断知识库是否开启图召回通道。
+func GraphRetrievalEnabled(kb *sharedsnapshot.KnowledgeBaseRuntimeSnapshot) bool {
+	if kb == nil || kb.RetrieveConfig == nil || kb.RetrieveConfig.Weights == nil {
+		return false
+	}
+	setting := kb.RetrieveConfig.Weights.GraphSetting
+	return setting != nil && setting.Enabled && setting.RelationWeight > 0
+}
+
+// BuildFragmentGraph 从片段标题、正文与表格主键中抽取实体，并以片段内共现构建关系边。
+func BuildFragmentGraph(fragment *fragmodel.KnowledgeBaseFragment) FragmentGraph {
+	if fragment == nil {
+		return FragmentGraph{}
+	}
+	sources := []string{fragment.DocumentName, fragment.SectionTitle, fragment.Content}
+	entities := extractGraphEntities(sources...)
+	for _, key := range metadataStringListValue(fragment.Metadata, ParsedMetaPrimaryKeys) {
+		entities = appendGraphEntity(entities, key)
+	}
+	if len(entities) > maxFragmentGraphEntities {
+		entities = entities[:maxFragmentGraphEntities]
+	}
+	return FragmentGraph{
+		Entities:  entities,
+		Relations: buildGraphRelations(entities),
+	}
+}
+
+// AttachFragmentGraph 将片段实体关系写入向量 payload metadata。
+func AttachFragmentGraph(payload *fragmodel.FragmentPayload, graph FragmentGraph) {
+	if payload == nil || len(graph.Entities) == 0 {
+		return
+	}
+	if payload.Metadata == nil {
+		payload.Metadata = map[string]any{}
+	}
+	payload.Metadata[GraphEntitiesMetadataKey] = slices.Clone(graph.Entities)
+	if len(graph.Relations) > 0 {
+		payload.Metadata[GraphRelationsMetadataKey] = slices.Clone(graph.Relations)
+	}
+}
+
+// extractQueryGraphEntities 抽取查询侧实体；查询通常不带引号或后缀，因此补充分词结果，
+// 由 payload keyword 精确匹配完成与索引侧实体的求交。
+func extractQueryGraphEntities(query string, analyzer retrievalAnalyzer) []string {
+	entities := extractGraphEntities(query)
+	for _, term := range analyzer.retrievalTerms(query) {
+		if len(entities) >= maxQueryGraphEntities {
+			break
+		}
+		entities = appendGraphEntity(entities, term)
+	}
+	if len(entities) > maxQueryGraphEntities {
+		entities = entities[:maxQueryGraphEntities]
+	}
+	return entities
+}
+
+func extractGraphEntities(texts ...string) []string {
+	entities := make([]string, 0, maxFragmentGraphEntities)
+	for _, text := range texts {
+		// normalizeRetrievalText 会折叠换行，属性行需要先按行切分再归一化。
+		for _, rawLine := range strings.Split(text, "\n") {
+			line := normalizeRetrievalText(rawLine)
+			if line == "" {
+				continue
+			}
+			entities = appendGraphEntitiesFromLine(entities, line)
+		}
+	}
+	return entities
+}
+
+func appendGraphEntitiesFromLine(entities []string, line string) []string {
+	for _, match := range graphQuotedEntityRegex.FindAllStringSubmatch(line, -1) {
+		entities = appendGraphEntity(entities, match[1])
+	}
+	if match := graphPropertyLineRegex.FindStringSubmatch(line); match != nil {
+		entities = appendGraphEntity(entities, match[2])
+	}
+	for _, org := range extractGraphOrganizations(line) {
+		entities = appendGraphEntity(entities, org)
+	}
+	for _, match := range graphLatinEntityRegex.FindAllString(line, -1) {
+		entities = appendGraphEntity(entities, match)
+	}
+	for _, match := range graphCodeEntityRegex.FindAllString(line, -1) {
+		entities = appendGraphEntity(entities, match)
+	}
+	return entities
+}
+
+// extractGraphOrganizations 以机构后缀为锚点向左扩展，直到遇到虚词、标点或长度上限。
+func extractGraphOrganizations(text string) []string {
+	locations := graphOrgSuffixRegex.FindAllStringIndex(text, -1)
+	if len(locations) == 0 {
+		return nil
+	}
+	organizations := make([]string, 0, len(locations)*2)
+	for _, location := range locations {
+		start := location[0]
+		prefixRunes := 0
+		for start > 0 && prefixRunes < maxGraphOrgPrefixRunes {
+			r, size := utf8.DecodeLastRuneInString(text[:start])
+			if !unicode.Is(unicode.Han, r) && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				break
+			}
+			if strings.ContainsRune(graphOrgBoundaryRunes, r) {
+				break
+			}
+			start -= size
+			prefixRunes++
+		}
+		if prefixRunes < minGraphEntityRunes {
+			continue
+		}
+		organizations = append(organizations, text[start:location[1]])
+		// 去掉后缀的主体名也作为节点，便于“华为”与“华为公司”互相连通。
+		organizations = append(organizations, text[start:location[0]])
+	}
+	return organizations
+}
+
+func appendGraphEntity(entities []string, raw string) []string {
+	entity := normalizeGraphEntity(raw)
+	if entity == "" || slices.Contains(entities, entity) {
+		return entities
+	}
+	return append(entities, entity)
+}
+
+func normalizeGraphEntity(raw string) string {
+	entity := strings.ToLower(strings.TrimSpace(normalizeRetrievalText(raw)))
+	entity = strings.Trim(entity, " .,;:!?，。；：！？、")
+	runeCount := utf8.RuneCountInString(entity)
+	if runeCount < minGraphEntityRunes || runeCount > maxGraphEntityRunes {
+		return ""
+	}
+	if _, stop := graphLatinStopEntityTerm[entity]; stop {
+		return ""
+	}
+	if strings.Contains(entity, graphRelationSeparator) || !hasTokenContentRune(entity) || isNumericGraphEntity(entity) {
+		return ""
+	}
+	return entity
+}
+
+func isNumericGraphEntity(entity string) bool {
+	for _, r := range entity {
+		if !unicode.IsDigit(r) && r != '.' && r != '-' && r != ',' {
+			return false
+		}
+	}
+	return true
+}
+
+func buildGraphRelations(entities []string) []string {
+	relations := make([]string, 0, min(maxFragmentGraphRelations, len(entities)*(len(entities)-1)/2))
+	for i := 0; i < len(entities) && len(relations) < maxFragmentGraphRelations; i++ {
+		for j := i + 1; j < len(entities) && len(relations) < maxFragmentGraphRelations; j++ {
+			relations = append(relations, graphRelationKey(entities[i], entities[j]))
+		}
+	}
+	return relations
+}
+
+func graphRelationKey(left, right string) string {
+	if right < left {
+		left, right = right, left
+	}
+	return left + graphRelationSeparator + right
+}
+
+func splitGraphRelation(relation string) (string, string, bool) {
+	left, right, ok := strings.Cut(relation, graphRelationSeparator)
+	if !ok || left == "" || right == "" {
+		return "", "", false
+	}
+	return left, right, true
+}
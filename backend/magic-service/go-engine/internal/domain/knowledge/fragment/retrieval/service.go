@@ -14,6 +14,7 @@ import (
 // EmbeddingService 定义检索服务依赖的向量生成能力。
 type EmbeddingService interface {
 	GetEmbedding(ctx context.Context, text, model string, businessParams *ctxmeta.BusinessParams) ([]float64, error)
+	GetEmbeddings(ctx context.Context, texts []string, model string, businessParams *ctxmeta.BusinessParams) ([][]float64, error)
 }
 
 // Infra 聚合检索领域服务所需的基础设施依赖。
@@ -23,19 +24,48 @@ type Infra struct {
 	DefaultEmbeddingModel string
 	Logger                *logging.SugaredLogger
 	SegmenterProvider     *SegmenterProvider
+	Reranker              Reranker
+	RerankOptions         RerankOptions
+	ChatCompleter         ChatCompleter
+	QueryExpansionOptions QueryExpansionOptions
+	// LateInteractionEncoder 与 LateInteractionOptions.Model 同时配置且向量库支持多向量时启用 MaxSim 重打分。
+	LateInteractionEncoder LateInteractionEncoder
+	LateInteractionOptions LateInteractionOptions
+	// FeedbackReader 与 FeedbackBoostOptions.Enabled 同时配置时按相关性反馈调整排序。
+	FeedbackReader       FeedbackAggregateReader
+	FeedbackBoostOptions FeedbackBoostOptions
+	// ChunkContextCache 缓存入库上下文增强时模型生成的片段上下文，可为空。
+	ChunkContextCache           ChunkContextCache
+	ContextualEnrichmentOptions ContextualEnrichmentOptions
+	// AuxiliaryFragmentOptions 控制入库时生成摘要/问题辅助片段的模型调用，结果同样缓存在 ChunkContextCache。
+	AuxiliaryFragmentOptions AuxiliaryFragmentOptions
 }
 
 // Service 提供片段检索增强相关的领域能力。
 type Service struct {
-	repo                  fragmodel.KnowledgeBaseFragmentReader
-	embeddingSvc          EmbeddingService
-	vectorDataRepo        shared.VectorDBDataRepository[fragmodel.FragmentPayload]
-	sparseBackendSelector shared.SparseBackendSelector
-	metaReader            sharedroute.CollectionMetaReader
-	defaultEmbeddingModel string
-	logger                *logging.SugaredLogger
-	segmenterProvider     *SegmenterProvider
-	tokenPolicyProvider   *retrievalTokenPolicyProvider
+	repo                   fragmodel.KnowledgeBaseFragmentReader
+	embeddingSvc           EmbeddingService
+	vectorDataRepo         shared.VectorDBDataRepository[fragmodel.FragmentPayload]
+	sparseBackendSelector  shared.SparseBackendSelector
+	metaReader             sharedroute.CollectionMetaReader
+	defaultEmbeddingModel  string
+	logger                 *logging.SugaredLogger
+	segmenterProvider      *SegmenterProvider
+	tokenPolicyProvider    *retrievalTokenPolicyProvider
+	reranker               Reranker
+	rerankOptions          RerankOptions
+	chatCompleter          ChatCompleter
+	queryExpansionOptions  QueryExpansionOptions
+	multiVectorRepo        shared.VectorDBMultiVectorRepository[fragmodel.FragmentPayload]
+	lateInteractionEncoder LateInteractionEncoder
+	lateInteractionOptions LateInteractionOptions
+	feedbackReader         FeedbackAggregateReader
+	feedbackBoostOptions   FeedbackBoostOptions
+	chunkContextCache      ChunkContextCache
+	// contextualEnrichmentOptions 控制入库上下文增强的模型调用。
+	contextualEnrichmentOptions ContextualEnrichmentOptions
+	// auxiliaryFragmentOptions 控制摘要/问题辅助片段的模型调用。
+	auxiliaryFragmentOptions AuxiliaryFragmentOptions
 }
 
 // NewService 创建检索领域服务。
@@ -50,16 +80,31 @@ func NewService(
 		segmenterProvider = newDefaultRetrievalSegmenterProvider()
 	}
 	sparseBackendSelector, _ := any(infra.VectorDataRepo).(shared.SparseBackendSelector)
+	multiVectorRepo, _ := any(infra.VectorDataRepo).(shared.VectorDBMultiVectorRepository[fragmodel.FragmentPayload])
 	return &Service{
-		repo:                  repo,
-		embeddingSvc:          embeddingSvc,
-		vectorDataRepo:        infra.VectorDataRepo,
-		sparseBackendSelector: sparseBackendSelector,
-		metaReader:            infra.MetaReader,
-		defaultEmbeddingModel: infra.DefaultEmbeddingModel,
-		logger:                infra.Logger,
-		segmenterProvider:     segmenterProvider,
-		tokenPolicyProvider:   defaultRetrievalTokenPolicyProvider,
+		repo:                   repo,
+		embeddingSvc:           embeddingSvc,
+		vectorDataRepo:         infra.VectorDataRepo,
+		sparseBackendSelector:  sparseBackendSelector,
+		metaReader:             infra.MetaReader,
+		defaultEmbeddingModel:  infra.DefaultEmbeddingModel,
+		logger:                 infra.Logger,
+		segmenterProvider:      segmenterProvider,
+		tokenPolicyProvider:    defaultRetrievalTokenPolicyProvider,
+		reranker:               infra.Reranker,
+		rerankOptions:          normalizeRerankOptions(infra.RerankOptions),
+		chatCompleter:          infra.ChatCompleter,
+		queryExpansionOptions:  normalizeQueryExpansionOptions(infra.QueryExpansionOptions),
+		multiVectorRepo:        multiVectorRepo,
+		lateInteractionEncoder: infra.LateInteractionEncoder,
+		lateInteractionOptions: normalizeLateInteractionOptions(infra.LateInteractionOptions),
+		feedbackReader:         infra.FeedbackReader,
+		feedbackBoostOptions:   normalizeFeedbackBoostOptions(infra.FeedbackBoostOptions),
+		chunkContextCache:      infra.ChunkContextCache,
+		contextualEnrichmentOptions: normalizeContextualEnrichmentOptions(
+			infra.ContextualEnrichmentOptions,
+		),
+		auxiliaryFragmentOptions: normalizeAuxiliaryFragmentOptions(infra.AuxiliaryFragmentOptions),
 	}
 }
 
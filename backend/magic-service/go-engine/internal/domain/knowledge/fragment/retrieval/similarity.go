@@ -6,6 +6,7 @@ import (
 	"maps"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -34,7 +35,15 @@ type scoredResult struct {
 	fusionScoreNorm float64
 	channelPresence string
 	finalScore      float64
+	feedbackBoost   float64
+	// feedbackEnabled 表示反馈加权已开启，此时即使调整量为 0 也输出分项打分。
+	feedbackEnabled bool
 	stableKey       string
+	// preRerankScore 为重排前的融合（或 late-interaction）分，结果阈值按该量纲比较。
+	preRerankScore float64
+	reranked       bool
+	// lateInteractionUnscored 表示同批已有候选被 MaxSim 打分而本候选没有，排序时整体落在打分候选之后。
+	lateInteractionUnscored bool
 }
 
 type candidateAnalysisSnapshot struct {
@@ -58,6 +67,9 @@ type similaritySearchTrace struct {
 	QueryType        string
 	QueryProfile     similarityQueryProfile
 	TokenPolicyDebug map[string]any
+	QueryExpansion   queryExpansion
+	// VectorCollectionName 为本次检索实际使用的向量集合，供 late-interaction 重打分复用。
+	VectorCollectionName string
 }
 
 type similarityResultOptions struct {
@@ -90,6 +102,8 @@ type similaritySearchContext struct {
 	denseCandidateThreshold  float64
 	sparseCandidateThreshold float64
 	denseOnly                bool
+	graphEntities            []string
+	expansion                queryExpansion
 }
 
 type similaritySingleQueryInput struct {
@@ -98,12 +112,14 @@ type similaritySingleQueryInput struct {
 	Model                         string
 	SparseBackend                 string
 	QueryProfile                  similarityQueryProfile
+	QueryVector                   []float64
 	Hybrid                        hybridSearchConfig
 	DenseCandidateScoreThreshold  float64
 	SparseCandidateScoreThreshold float64
 	Filter                        *shared.VectorFilter
 	BusinessParams                *ctxmeta.BusinessParams
 	DenseOnly                     bool
+	GraphEntities                 []string
 }
 
 // Similarity 相似度搜索
@@ -130,6 +146,9 @@ func (s *Service) Similarity(
 		"top_k", req.TopK,
 		"query_type", trace.QueryType,
 	})
+	results = s.resolveAuxiliaryCandidates(ctx, results)
+	results = s.lateInteractionRescore(ctx, req, trace, results)
+	results = s.rerankSimilarityCandidates(ctx, *kbSnapshot, req, trace.QueryProfile, results)
 	scored := s.scoreSimilarityResults(ctx, trace.QueryProfile, results, *kbSnapshot, req.TopK, similarityResultOptions{
 		ResultScoreThreshold: req.ResultScoreThreshold,
 		SearchOptions:        req.Options,
@@ -149,13 +168,14 @@ func (s *Service) searchSimilarityCandidates(
 	}
 
 	trace := similaritySearchTrace{
-		UsedQueries:     searchCtx.rewrite.Used,
-		RewrittenQuery:  searchCtx.rewrite.Rewritten,
-		PipelineVersion: RetrievalPipelineVersionV1,
-		DenseOnly:       searchCtx.denseOnly,
-		SparseBackend:   searchCtx.sparseBackend,
-		QueryType:       searchCtx.queryProfile.QueryType,
-		QueryProfile:    searchCtx.queryProfile,
+		UsedQueries:          searchCtx.rewrite.Used,
+		RewrittenQuery:       searchCtx.rewrite.Rewritten,
+		PipelineVersion:      RetrievalPipelineVersionV1,
+		DenseOnly:            searchCtx.denseOnly,
+		SparseBackend:        searchCtx.sparseBackend,
+		QueryType:            searchCtx.queryProfile.QueryType,
+		QueryProfile:         searchCtx.queryProfile,
+		VectorCollectionName: searchCtx.vectorCollectionName,
 		AppliedFilter: FilterPlanTrace{
 			Hard: vectorFilterDebugView(searchCtx.filterPlan.Hard),
 			Soft: vectorFilterDebugView(searchCtx.filterPlan.Soft),
@@ -164,6 +184,8 @@ func (s *Service) searchSimilarityCandidates(
 	if req.Options != nil && req.Options.Debug {
 		trace.TokenPolicyDebug = s.buildTokenPolicyDebug(searchCtx.queryProfile.RawQuery)
 	}
+	searchCtx.expansion = s.expandSimilarityQuery(ctx, *kb, searchCtx.queryProfile.RawQuery, req.BusinessParams)
+	trace.QueryExpansion = searchCtx.expansion
 
 	results, err := s.runEnhancedSimilaritySearch(ctx, searchCtx)
 	if err != nil {
@@ -190,6 +212,11 @@ func (s *Service) buildSimilaritySearchContext(
 	analyzer := s.newRetrievalAnalyzer()
 	profileQuery := resolveProfileQuery(req)
 	queryProfile := buildSimilarityQueryProfile(profileQuery, resolveEmbeddingQuery(req), analyzer)
+	hybrid := resolveHybridSearchConfig(req.TopK, *kbSnapshot)
+	var graphEntities []string
+	if hybrid.Graph.Enabled {
+		graphEntities = extractQueryGraphEntities(queryProfile.RawQuery, analyzer)
+	}
 	return similaritySearchContext{
 		request: req,
 		// dense recall 与 sparse recall 都必须消费同一份运行时路由，避免 term namespace 和物理集合漂移。
@@ -200,10 +227,11 @@ func (s *Service) buildSimilaritySearchContext(
 		queryProfile:             queryProfile,
 		rewrite:                  buildQueryVariants(queryProfile.RawQuery),
 		filterPlan:               mergeSimilarityHardFilter(buildFilterPlan(*kbSnapshot, explicitFilters, queryProfile.RawQuery), req.Options),
-		hybrid:                   resolveHybridSearchConfig(req.TopK, *kbSnapshot),
+		hybrid:                   hybrid,
 		denseCandidateThreshold:  resolveDenseCandidateScoreThreshold(req.CandidateScoreThreshold),
 		sparseCandidateThreshold: resolveSparseCandidateScoreThreshold(),
 		denseOnly:                shouldUseDenseOnlySimilarityQuery(queryProfile.RawQuery, analyzer) || !supportsSparseBackend(resolvedRoute.SparseBackend),
+		graphEntities:            graphEntities,
 	}
 }
 
@@ -236,6 +264,7 @@ func (s *Service) runEnhancedSimilaritySearch(
 		Filter:                        primaryFilter,
 		BusinessParams:                searchCtx.request.BusinessParams,
 		DenseOnly:                     searchCtx.denseOnly,
+		GraphEntities:                 searchCtx.graphEntities,
 	})
 	if err != nil {
 		return nil, err
@@ -257,12 +286,14 @@ func (s *Service) runEnhancedSimilaritySearch(
 			Filter:                        searchCtx.filterPlan.Hard,
 			BusinessParams:                searchCtx.request.BusinessParams,
 			DenseOnly:                     searchCtx.denseOnly,
+			GraphEntities:                 searchCtx.graphEntities,
 		})
 		if searchErr != nil {
 			return nil, searchErr
 		}
 		mergeSimilarityResults(mergedResults, passTwo)
 	}
+	mergeSimilarityResults(mergedResults, s.searchExpandedQueries(ctx, searchCtx, primaryFilter))
 
 	return collectSimilarityResults(mergedResults), nil
 }
@@ -296,6 +327,7 @@ func (s *Service) logCandidateSearchConfig(
 		"sparse_top_k", searchCtx.hybrid.SparseTopK,
 		"candidate_cap", candidateCap,
 		"dense_only", searchCtx.denseOnly,
+		"fusion_algorithm", fusionStrategyOrDefault(searchCtx.hybrid.Fusion).Name(),
 		"query_type", searchCtx.queryProfile.QueryType,
 		"hard_filter_keys", vectorFilterKeys(searchCtx.filterPlan.Hard),
 		"soft_filter_keys", vectorFilterKeys(searchCtx.filterPlan.Soft),
@@ -329,12 +361,24 @@ func (s *Service) searchSingleSimilarityQuery(
 ) ([]*shared.VectorSearchResult[fragmodel.FragmentPayload], error) {
 	var denseResults []*shared.VectorSearchResult[fragmodel.FragmentPayload]
 	var sparseResults []*shared.VectorSearchResult[fragmodel.FragmentPayload]
+	var graphResults []*shared.VectorSearchResult[fragmodel.FragmentPayload]
 
 	group, groupCtx := errgroup.WithContext(ctx)
-	group.Go(func() error {
+	// 稠密通道与图通道共享同一次查询向量计算。
+	embedQuery := sync.OnceValues(func() ([]float64, error) {
+		if len(input.QueryVector) > 0 {
+			return input.QueryVector, nil
+		}
 		queryVector, err := s.embeddingSvc.GetEmbedding(groupCtx, input.QueryProfile.DenseQuery, input.Model, input.BusinessParams)
 		if err != nil {
-			return fmt.Errorf("failed to compute query embedding: %w", err)
+			return nil, fmt.Errorf("failed to compute query embedding: %w", err)
+		}
+		return queryVector, nil
+	})
+	group.Go(func() error {
+		queryVector, err := embedQuery()
+		if err != nil {
+			return err
 		}
 		denseResults, err = s.vectorDataRepo.SearchDenseWithFilter(groupCtx, shared.DenseSearchRequest{
 			Collection:     input.VectorCollectionName,
@@ -349,6 +393,12 @@ func (s *Service) searchSingleSimilarityQuery(
 		}
 		return nil
 	})
+	if input.Hybrid.Graph.Enabled && len(input.GraphEntities) > 0 {
+		group.Go(func() error {
+			graphResults = s.searchGraphChannel(groupCtx, input, embedQuery)
+			return nil
+		})
+	}
 	if !input.DenseOnly {
 		group.Go(func() error {
 			sparseRequest, ok := s.buildSparseSearchRequest(groupCtx, input)
@@ -367,7 +417,7 @@ func (s *Service) searchSingleSimilarityQuery(
 		return nil, fmt.Errorf("search similarity query: %w", err)
 	}
 
-	partial := fuseHybridResults(denseResults, sparseResults, input.Hybrid)
+	partial := fuseHybridResults(denseResults, sparseResults, graphResults, input.Hybrid)
 	normalizeSimilaritySearchResults(partial)
 	return partial, nil
 }
@@ -523,6 +573,7 @@ func (s *Service) scoreSimilarityResults(
 		queryType = classifySimilarityQueryType(resolveRankingQuery(queryProfile), s.newRetrievalAnalyzer())
 	}
 	scored := buildHybridScoredResults(results)
+	s.applyFeedbackBoost(ctx, kb, results, scored)
 	preFilterCount := len(scored)
 	if topK <= 0 {
 		topK = 10
@@ -533,6 +584,7 @@ func (s *Service) scoreSimilarityResults(
 
 	appliedThreshold := resultOptions.ResultScoreThreshold
 	scored, appliedThreshold = applyResultScoreThresholdWithFallback(scored, topK, appliedThreshold)
+	scored = applyRerankScoreThreshold(scored, s.rerankScoreThreshold())
 	if len(scored) > topK {
 		scored = scored[:topK]
 	}
@@ -561,6 +613,10 @@ func (s *Service) scoreSimilarityResults(
 
 func buildHybridScoredResults(results []*shared.VectorSearchResult[fragmodel.FragmentPayload]) []scoredResult {
 	scored := make([]scoredResult, len(results))
+	lateInteractionApplied := slices.ContainsFunc(results, func(result *shared.VectorSearchResult[fragmodel.FragmentPayload]) bool {
+		_, ok := metadataFloat64ValueWithPresence(result.Metadata, "late_interaction_score")
+		return ok
+	})
 	for i, result := range results {
 		hybridScore, hasHybridScore := metadataFloat64ValueWithPresence(result.Metadata, "hybrid_score")
 		if !hasHybridScore {
@@ -575,6 +631,17 @@ func buildHybridScoredResults(results []*shared.VectorSearchResult[fragmodel.Fra
 		if !hasFusionScoreNorm {
 			fusionScoreNorm = hybridScore
 		}
+		finalScore := hybridScore
+		// 优先级：交叉编码重排 > late-interaction MaxSim > 融合分，融合分仅保留用于观测。
+		lateInteractionScore, hasLateInteractionScore := metadataFloat64ValueWithPresence(result.Metadata, "late_interaction_score")
+		if hasLateInteractionScore {
+			finalScore = lateInteractionScore
+		}
+		preRerankScore := finalScore
+		rerankScore, hasRerankScore := metadataFloat64ValueWithPresence(result.Metadata, "rerank_score")
+		if hasRerankScore {
+			finalScore = rerankScore
+		}
 		scored[i] = scoredResult{
 			index:           i,
 			hybridScore:     hybridScore,
@@ -583,7 +650,11 @@ func buildHybridScoredResults(results []*shared.VectorSearchResult[fragmodel.Fra
 			fusionScoreNorm: fusionScoreNorm,
 			channelPresence: metadataStringValue(result.Metadata, "channel_presence"),
 			stableKey:       hybridResultKey(result),
-			finalScore:      hybridScore,
+			finalScore:      finalScore,
+			preRerankScore:  preRerankScore,
+			reranked:        hasRerankScore,
+			// 重排后只按重排分比较；未重排时 MaxSim 与融合分量纲不同，不能混排。
+			lateInteractionUnscored: lateInteractionApplied && !hasLateInteractionScore && !hasRerankScore,
 		}
 	}
 	return scored
@@ -659,6 +730,13 @@ func buildSimilarityMetadata(
 		"rewritten_query": trace.RewrittenQuery,
 		"used_queries":    trace.UsedQueries,
 	}
+	if !trace.QueryExpansion.empty() {
+		cloned["query_expansion"] = map[string]any{
+			"model":                 trace.QueryExpansion.Model,
+			"expanded_queries":      trace.QueryExpansion.Paraphrases,
+			"hypothetical_document": trace.QueryExpansion.HypotheticalDocument,
+		}
+	}
 	if len(trace.TokenPolicyDebug) > 0 {
 		cloned["token_policy_debug"] = trace.TokenPolicyDebug
 	}
@@ -688,11 +766,48 @@ func buildRetrievalRanking(
 		RRFScore:             metadataFloat64Value(metadata, "rrf_score"),
 		Dense:                buildRetrievalChannelScore(metadata, "dense"),
 		Sparse:               buildRetrievalChannelScore(metadata, "sparse"),
+		Graph:                buildRetrievalChannelScore(metadata, "graph"),
+		GraphEntities:        metadataStringListValue(metadata, graphMatchedEntitiesMetadataKey),
+		Rerank:               buildRetrievalChannelScore(metadata, "rerank"),
+		RerankModel:          metadataStringValue(metadata, "rerank_model"),
+		LateInteraction:      buildRetrievalChannelScore(metadata, "late_interaction"),
+		LateInteractionModel: metadataStringValue(metadata, "late_interaction_model"),
 		BM25Query:            buildBM25QueryObservation(bm25QueryProfile, trace.SparseBackend, newRetrievalAnalyzer()),
 	}
+	if scoredItem.feedbackEnabled {
+		ranking.Debug = buildFeedbackRankingDebug(metadata, queryProfile, scoredItem)
+	}
 	return ranking
 }
 
+// buildFeedbackRankingDebug 在反馈加权开启时输出分项打分，便于解释排序变化。
+func buildFeedbackRankingDebug(
+	metadata map[string]any,
+	queryProfile similarityQueryProfile,
+	scoredItem scoredResult,
+) *RankingDebug {
+	rerankScore := metadataFloat64Value(metadata, "rerank_score")
+	return &RankingDebug{
+		QueryType:   queryProfile.QueryType,
+		RerankScore: rerankScore,
+		ScoreBreakdown: ScoreBreakdown{
+			FusionAlgorithm:      metadataStringValue(metadata, "fusion_algorithm"),
+			HybridAlpha:          metadataFloat64Value(metadata, "hybrid_alpha"),
+			HybridScore:          scoredItem.hybridScore,
+			FusionScoreNorm:      scoredItem.fusionScoreNorm,
+			RerankScore:          rerankScore,
+			DenseScore:           scoredItem.denseScore,
+			SparseScore:          scoredItem.sparseScore,
+			RRFScore:             metadataFloat64Value(metadata, "rrf_score"),
+			FeedbackBoost:        scoredItem.feedbackBoost,
+			RankingScore:         scoredItem.finalScore,
+			QueryType:            queryProfile.QueryType,
+			ChannelPresence:      scoredItem.channelPresence,
+			LegacyWeightUpgraded: metadataBoolValue(metadata, "legacy_weight_upgraded"),
+		},
+	}
+}
+
 func resolveRetrievalPipelineVersion(trace similaritySearchTrace) string {
 	if trace.PipelineVersion != "" {
 		return string(trace.PipelineVersion)
@@ -730,10 +845,23 @@ func cleanupSimilarityRankingMetadata(metadata map[string]any) {
 		"sparse_score",
 		"sparse_score_norm",
 		"sparse_contribution",
+		"graph_rank",
+		"graph_score",
+		"graph_contribution",
+		"graph_depth",
+		graphMatchedEntitiesMetadataKey,
+		GraphEntitiesMetadataKey,
+		GraphRelationsMetadataKey,
 		"channel_presence",
 		"legacy_weight_upgraded",
 		"query_type",
 		"rerank_score",
+		"rerank_rank",
+		"rerank_model",
+		"late_interaction_score",
+		"late_interaction_rank",
+		"late_interaction_maxsim",
+		"late_interaction_model",
 		"support_score",
 		"score_breakdown",
 		"retrieval_pipeline_version",
@@ -757,6 +885,11 @@ func resolveRankingQuery(profile similarityQueryProfile) string {
 func sortSimilarityScores(scored []scoredResult, _ sharedsnapshot.KnowledgeBaseRuntimeSnapshot) {
 	slices.SortStableFunc(scored, func(a, b scoredResult) int {
 		switch {
+		case a.lateInteractionUnscored != b.lateInteractionUnscored:
+			if a.lateInteractionUnscored {
+				return 1
+			}
+			return -1
 		case a.finalScore > b.finalScore:
 			return -1
 		case a.finalScore < b.finalScore:
@@ -832,7 +965,30 @@ func applyResultScoreThreshold(scored []scoredResult, threshold float64) []score
 
 	filtered := scored[:0]
 	for _, item := range scored {
-		if item.finalScore < threshold {
+		if item.resultThresholdScore() < threshold {
+			continue
+		}
+		filtered = append(filtered, item)
+	}
+	return filtered
+}
+
+// resultThresholdScore 返回与结果阈值同量纲的分数：重排分与融合分量纲不同，重排结果按重排前分数比较。
+func (r scoredResult) resultThresholdScore() float64 {
+	if r.reranked {
+		return r.preRerankScore
+	}
+	return r.finalScore
+}
+
+// applyRerankScoreThreshold 按重排模型相关度过滤已重排的结果，阈值不大于 0 时不过滤。
+func applyRerankScoreThreshold(scored []scoredResult, threshold float64) []scoredResult {
+	if len(scored) == 0 || threshold <= 0 {
+		return scored
+	}
+	filtered := scored[:0]
+	for _, item := range scored {
+		if item.reranked && item.finalScore < threshold {
 			continue
 		}
 		filtered = append(filtered, item)
@@ -876,6 +1032,7 @@ func resolveHybridSearchConfig(topK int, kb sharedsnapshot.KnowledgeBaseRuntimeS
 
 	if kb.RetrieveConfig == nil {
 		config.EffectiveHybridAlpha = config.DenseWeight
+		config.Fusion = RelativeScoreFusion{}
 		return config
 	}
 	if multiplier := kb.RetrieveConfig.HybridTopKMultiplier; multiplier > 0 {
@@ -884,6 +1041,8 @@ func resolveHybridSearchConfig(topK int, kb sharedsnapshot.KnowledgeBaseRuntimeS
 		config.SparseTopK = candidateTopK
 	}
 	config.EffectiveHybridAlpha = config.DenseWeight
+	config.Fusion = resolveFusionStrategy(kb.RetrieveConfig)
+	config.Graph = resolveGraphSearchConfig(config.DenseTopK, kb)
 	return config
 }
 
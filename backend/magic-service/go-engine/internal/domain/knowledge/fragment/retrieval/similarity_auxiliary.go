@@ -0,0 +1,157 @@
+package retrieval
+
+import (
+	"context"
+
+	fragmetadata "magic/internal/domain/knowledge/fragment/metadata"
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/shared"
+)
+
+// MetadataAuxiliaryMatchKey 记录结果由哪条摘要/问题辅助片段命中后回溯而来。
+const MetadataAuxiliaryMatchKey = "auxiliary_match"
+
+// resolveAuxiliaryCandidates 将命中的摘要/问题辅助片段回溯为源片段。
+//
+// 源片段已在候选中时按 point_id 合并并保留高分，否则回库读取源片段补入；
+// 源片段已不存在的辅助命中直接丢弃，结果中不会出现辅助片段本身。
+func (s *Service) resolveAuxiliaryCandidates(
+	ctx context.Context,
+	results []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+) []*shared.VectorSearchResult[fragmodel.FragmentPayload] {
+	auxiliary := make([]*shared.VectorSearchResult[fragmodel.FragmentPayload], 0)
+	regular := make([]*shared.VectorSearchResult[fragmodel.FragmentPayload], 0, len(results))
+	primary := make(map[string]*shared.VectorSearchResult[fragmodel.FragmentPayload], len(results))
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		if auxiliaryCandidateType(result) != "" {
+			auxiliary = append(auxiliary, result)
+			continue
+		}
+		regular = append(regular, result)
+		if result.ID != "" {
+			primary[result.ID] = result
+		}
+	}
+	if len(auxiliary) == 0 {
+		return results
+	}
+	merged := make(map[string]*shared.VectorSearchResult[fragmodel.FragmentPayload], len(results))
+	mergeSimilarityResults(merged, regular)
+
+	missing := make([]string, 0, len(auxiliary))
+	seen := make(map[string]struct{}, len(auxiliary))
+	for _, result := range auxiliary {
+		for _, pointID := range auxiliaryCandidateSourcePointIDs(result) {
+			if _, exists := primary[pointID]; exists {
+				continue
+			}
+			if _, exists := seen[pointID]; exists {
+				continue
+			}
+			seen[pointID] = struct{}{}
+			missing = append(missing, pointID)
+		}
+	}
+	fetched := s.loadAuxiliarySourcePayloads(ctx, missing)
+	for _, result := range auxiliary {
+		resolved := resolveAuxiliaryCandidate(result, primary, fetched)
+		if resolved == nil {
+			continue
+		}
+		mergeSimilarityResults(merged, []*shared.VectorSearchResult[fragmodel.FragmentPayload]{resolved})
+	}
+	return collectSimilarityResults(merged)
+}
+
+func auxiliaryCandidateType(result *shared.VectorSearchResult[fragmodel.FragmentPayload]) string {
+	if auxiliaryType := fragmodel.AuxiliaryFragmentType(result.Metadata); auxiliaryType != "" {
+		return auxiliaryType
+	}
+	return fragmodel.AuxiliaryFragmentType(result.Payload.Metadata)
+}
+
+func auxiliaryCandidateSourcePointIDs(result *shared.VectorSearchResult[fragmodel.FragmentPayload]) []string {
+	if pointIDs := fragmodel.AuxiliarySourcePointIDs(result.Metadata); len(pointIDs) > 0 {
+		return pointIDs
+	}
+	return fragmodel.AuxiliarySourcePointIDs(result.Payload.Metadata)
+}
+
+// loadAuxiliarySourcePayloads 回库读取未被召回的源片段，读取失败时仅记录日志。
+func (s *Service) loadAuxiliarySourcePayloads(ctx context.Context, pointIDs []string) map[string]fragmodel.FragmentPayload {
+	if s == nil || s.repo == nil || len(pointIDs) == 0 {
+		return nil
+	}
+	fragments, err := s.repo.FindByPointIDs(ctx, pointIDs)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.KnowledgeWarnContext(ctx, "Load auxiliary fragment sources failed, drop auxiliary hits",
+				"point_count", len(pointIDs),
+				"error", err,
+			)
+		}
+		return nil
+	}
+	payloads := make(map[string]fragmodel.FragmentPayload, len(fragments))
+	for _, fragment := range fragments {
+		if fragment == nil || fragment.PointID == "" || fragmodel.IsAuxiliaryFragment(fragment) {
+			continue
+		}
+		if payload := fragmetadata.BuildFragmentPayload(fragment); payload != nil {
+			payloads[fragment.PointID] = *payload
+		}
+	}
+	return payloads
+}
+
+// resolveAuxiliaryCandidate 优先回溯到已召回且得分最高的源片段，否则取首个可读取的源片段。
+//
+// 回溯结果沿用辅助命中的召回分与通道信息，内容与 payload 替换为源片段。
+func resolveAuxiliaryCandidate(
+	result *shared.VectorSearchResult[fragmodel.FragmentPayload],
+	primary map[string]*shared.VectorSearchResult[fragmodel.FragmentPayload],
+	fetched map[string]fragmodel.FragmentPayload,
+) *shared.VectorSearchResult[fragmodel.FragmentPayload] {
+	var (
+		targetID      string
+		targetContent string
+		target        fragmodel.FragmentPayload
+		bestScore     float64
+		hasPrimary    bool
+	)
+	for _, pointID := range auxiliaryCandidateSourcePointIDs(result) {
+		if existing, ok := primary[pointID]; ok && (!hasPrimary || existing.Score > bestScore) {
+			targetID, targetContent, target, bestScore, hasPrimary = pointID, existing.Content, existing.Payload, existing.Score, true
+			continue
+		}
+		if payload, ok := fetched[pointID]; ok && targetID == "" {
+			targetID, targetContent, target = pointID, payload.Content, payload
+		}
+	}
+	if targetID == "" {
+		return nil
+	}
+
+	resolved := cloneVectorSearchResult(result)
+	resolved.ID = targetID
+	resolved.Content = targetContent
+	resolved.Payload = target
+	fragmetadata.ApplyPayloadMetadataContract(&resolved.Payload)
+	metadata := cloneMetadata(resolved.Payload.Metadata)
+	for key, value := range result.Metadata {
+		if _, fromPayload := result.Payload.Metadata[key]; fromPayload || key == MetadataFallbackFlagsKey {
+			continue
+		}
+		metadata[key] = value
+	}
+	metadata[MetadataAuxiliaryMatchKey] = map[string]any{
+		"type":     auxiliaryCandidateType(result),
+		"content":  result.Content,
+		"point_id": result.ID,
+	}
+	resolved.Metadata = metadata
+	return resolved
+}
@@ -844,6 +844,14 @@ func (embeddingServiceStub) GetEmbedding(context.Context, string, string, *ctxme
 	return []float64{0.1, 0.2}, nil
 }
 
+func (embeddingServiceStub) GetEmbeddings(_ context.Context, texts []string, _ string, _ *ctxmeta.BusinessParams) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i := range texts {
+		vectors[i] = []float64{0.1, 0.2}
+	}
+	return vectors, nil
+}
+
 type recordingEmbeddingServiceStub struct {
 	mu      sync.Mutex
 	queries []string
@@ -861,6 +869,22 @@ func (s *recordingEmbeddingServiceStub) GetEmbedding(
 	return []float64{0.1, 0.2}, nil
 }
 
+func (s *recordingEmbeddingServiceStub) GetEmbeddings(
+	_ context.Context,
+	texts []string,
+	_ string,
+	_ *ctxmeta.BusinessParams,
+) ([][]float64, error) {
+	s.mu.Lock()
+	s.queries = append(s.queries, texts...)
+	s.mu.Unlock()
+	vectors := make([][]float64, len(texts))
+	for i := range texts {
+		vectors[i] = []float64{0.1, 0.2}
+	}
+	return vectors, nil
+}
+
 type vectorDataRepoStub struct {
 	denseCalls  atomic.Int32
 	sparseCalls atomic.Int32
@@ -0,0 +1,199 @@
+package retrieval
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/shared"
+	sharedsnapshot "magic/internal/domain/knowledge/shared/snapshot"
+	"magic/internal/pkg/logkey"
+)
+
+const (
+	defaultFeedbackMaxBoost   = 0.15
+	maxFeedbackMaxBoost       = 0.5
+	defaultFeedbackPriorCount = 5
+	defaultFeedbackTimeout    = 500 * time.Millisecond
+	// feedbackAnsweredWeight 表示“答案来自该片段”相对于点赞的权重。
+	feedbackAnsweredWeight = 2
+	// feedbackDocumentWeight 表示文档级反馈外溢到同文档其它片段的权重。
+	feedbackDocumentWeight = 0.5
+)
+
+// FeedbackAggregateQuery 表示按文档批量读取反馈聚合的条件。
+type FeedbackAggregateQuery struct {
+	OrganizationCode  string
+	KnowledgeBaseCode string
+	DocumentCodes     []string
+}
+
+// FeedbackAggregate 表示片段或文档级的反馈聚合计数；FragmentID 为 0 时表示文档级。
+type FeedbackAggregate struct {
+	DocumentCode  string
+	FragmentID    int64
+	PositiveCount int64
+	NegativeCount int64
+	AnsweredCount int64
+}
+
+// FeedbackAggregateReader 定义检索排序读取相关性反馈聚合的能力。
+type FeedbackAggregateReader interface {
+	ListFeedbackAggregates(ctx context.Context, query FeedbackAggregateQuery) ([]FeedbackAggregate, error)
+}
+
+// FeedbackBoostOptions 表示反馈加权排序的运行参数，Enabled 为 false 时关闭。
+//
+// MaxBoost 是单个结果排序分可获得的最大加分或扣分；PriorCount 为平滑先验，反馈越少影响越小。
+type FeedbackBoostOptions struct {
+	Enabled    bool
+	MaxBoost   float64
+	PriorCount float64
+	Timeout    time.Duration
+}
+
+func normalizeFeedbackBoostOptions(options FeedbackBoostOptions) FeedbackBoostOptions {
+	if options.MaxBoost <= 0 {
+		options.MaxBoost = defaultFeedbackMaxBoost
+	}
+	options.MaxBoost = min(options.MaxBoost, maxFeedbackMaxBoost)
+	if options.PriorCount <= 0 {
+		options.PriorCount = defaultFeedbackPriorCount
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaultFeedbackTimeout
+	}
+	return options
+}
+
+func (s *Service) feedbackBoostEnabled() bool {
+	return s != nil && s.feedbackReader != nil && s.feedbackBoostOptions.Enabled
+}
+
+// applyFeedbackBoost 按片段与文档的历史反馈对候选分数做有界调整。
+//
+// 读取失败或超时时保持原排序；调整量是 [-MaxBoost, MaxBoost] 内的加性项，
+// 原始分数接近 0 时同样生效。开启后每个候选都会在分项打分中输出反馈贡献，未命中反馈时为 0。
+func (s *Service) applyFeedbackBoost(
+	ctx context.Context,
+	kb sharedsnapshot.KnowledgeBaseRuntimeSnapshot,
+	results []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+	scored []scoredResult,
+) {
+	if !s.feedbackBoostEnabled() || len(scored) == 0 {
+		return
+	}
+	for i := range scored {
+		scored[i].feedbackEnabled = true
+	}
+	if strings.TrimSpace(kb.Code) == "" {
+		return
+	}
+	documentCodes := feedbackDocumentCodes(results, scored)
+	if len(documentCodes) == 0 {
+		return
+	}
+
+	startedAt := time.Now()
+	readCtx, cancel := context.WithTimeout(ctx, s.feedbackBoostOptions.Timeout)
+	defer cancel()
+	aggregates, err := s.feedbackReader.ListFeedbackAggregates(readCtx, FeedbackAggregateQuery{
+		OrganizationCode:  kb.OrganizationCode,
+		KnowledgeBaseCode: kb.Code,
+		DocumentCodes:     documentCodes,
+	})
+	if err != nil {
+		if s.logger != nil {
+			s.logger.KnowledgeWarnContext(
+				ctx,
+				"Knowledge similarity feedback boost skipped",
+				logkey.DurationMS, logkey.DurationToMS(time.Since(startedAt)),
+				"document_count", len(documentCodes),
+				"error", err,
+			)
+		}
+		return
+	}
+	if len(aggregates) == 0 {
+		return
+	}
+
+	index := newFeedbackAggregateIndex(aggregates)
+	for i := range scored {
+		payload := results[scored[i].index].Payload
+		boost := index.boost(payload.DocumentCode, payload.FragmentID, s.feedbackBoostOptions)
+		if boost == 0 {
+			continue
+		}
+		scored[i].feedbackBoost = boost
+		scored[i].finalScore += boost
+	}
+}
+
+func feedbackDocumentCodes(
+	results []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+	scored []scoredResult,
+) []string {
+	seen := make(map[string]struct{}, len(scored))
+	codes := make([]string, 0, len(scored))
+	for _, item := range scored {
+		code := strings.TrimSpace(results[item.index].Payload.DocumentCode)
+		if code == "" {
+			continue
+		}
+		if _, ok := seen[code]; ok {
+			continue
+		}
+		seen[code] = struct{}{}
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+type feedbackFragmentKey struct {
+	documentCode string
+	fragmentID   int64
+}
+
+type feedbackAggregateIndex struct {
+	documents map[string]FeedbackAggregate
+	fragments map[feedbackFragmentKey]FeedbackAggregate
+}
+
+func newFeedbackAggregateIndex(aggregates []FeedbackAggregate) feedbackAggregateIndex {
+	index := feedbackAggregateIndex{
+		documents: make(map[string]FeedbackAggregate),
+		fragments: make(map[feedbackFragmentKey]FeedbackAggregate),
+	}
+	for _, aggregate := range aggregates {
+		if aggregate.FragmentID <= 0 {
+			index.documents[aggregate.DocumentCode] = aggregate
+			continue
+		}
+		index.fragments[feedbackFragmentKey{documentCode: aggregate.DocumentCode, fragmentID: aggregate.FragmentID}] = aggregate
+	}
+	return index
+}
+
+// boost 返回 [-MaxBoost, MaxBoost] 内的加性调整量。
+func (i feedbackAggregateIndex) boost(documentCode string, fragmentID int64, options FeedbackBoostOptions) float64 {
+	documentCode = strings.TrimSpace(documentCode)
+	signal := feedbackDocumentWeight * feedbackSignal(i.documents[documentCode], options.PriorCount)
+	if fragmentID > 0 {
+		signal += feedbackSignal(i.fragments[feedbackFragmentKey{documentCode: documentCode, fragmentID: fragmentID}], options.PriorCount)
+	}
+	signal = max(-1, min(1, signal))
+	return signal * options.MaxBoost
+}
+
+// feedbackSignal 把计数折算为 (-1, 1) 内的净倾向，先验计数让少量反馈只产生小幅影响。
+func feedbackSignal(aggregate FeedbackAggregate, priorCount float64) float64 {
+	positive := float64(aggregate.PositiveCount) + feedbackAnsweredWeight*float64(aggregate.AnsweredCount)
+	negative := float64(aggregate.NegativeCount)
+	total := positive + negative
+	if total == 0 {
+		return 0
+	}
+	return (positive - negative) / (total + priorCount)
+}
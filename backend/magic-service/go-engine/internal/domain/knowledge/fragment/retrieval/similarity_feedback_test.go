@@ -0,0 +1,133 @@
+package retrieval_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	retrieval "magic/internal/domain/knowledge/fragment/retrieval"
+	shared "magic/internal/domain/knowledge/shared"
+	sharedsnapshot "magic/internal/domain/knowledge/shared/snapshot"
+)
+
+var errFeedbackStatsUnavailable = errors.New("feedback stats unavailable")
+
+type feedbackAggregateReaderStub struct {
+	aggregates []retrieval.FeedbackAggregate
+	err        error
+	queries    []retrieval.FeedbackAggregateQuery
+}
+
+func (s *feedbackAggregateReaderStub) ListFeedbackAggregates(
+	_ context.Context,
+	query retrieval.FeedbackAggregateQuery,
+) ([]retrieval.FeedbackAggregate, error) {
+	s.queries = append(s.queries, query)
+	return s.aggregates, s.err
+}
+
+func feedbackCandidatesForTest() []*shared.VectorSearchResult[fragmodel.FragmentPayload] {
+	return []*shared.VectorSearchResult[fragmodel.FragmentPayload]{
+		{
+			ID:      "point-1",
+			Score:   0.80,
+			Content: "退款流程说明",
+			Payload: fragmodel.FragmentPayload{DocumentCode: "DOC-1", FragmentID: 1, SectionPath: "退款"},
+		},
+		{
+			ID:      "point-2",
+			Score:   0.78,
+			Content: "退款到账时间",
+			Payload: fragmodel.FragmentPayload{DocumentCode: "DOC-2", FragmentID: 2, SectionPath: "到账"},
+		},
+	}
+}
+
+func TestScoreSimilarityResultsAppliesBoundedFeedbackBoost(t *testing.T) {
+	t.Parallel()
+
+	reader := &feedbackAggregateReaderStub{aggregates: []retrieval.FeedbackAggregate{
+		{DocumentCode: "DOC-1", FragmentID: 1, NegativeCount: 20},
+		{DocumentCode: "DOC-1", NegativeCount: 20},
+		{DocumentCode: "DOC-2", FragmentID: 2, AnsweredCount: 3},
+	}}
+	kb := &sharedsnapshot.KnowledgeBaseRuntimeSnapshot{Code: "KB1", OrganizationCode: "ORG1"}
+	options := retrieval.FeedbackBoostOptions{Enabled: true, MaxBoost: 0.2}
+
+	results := retrieval.ScoreSimilarityResultsWithFeedbackForTest("退款", feedbackCandidatesForTest(), kb, 2, reader, options)
+	if len(results) != 2 || results[0].DocumentCode != "DOC-2" {
+		t.Fatalf("expected positive feedback to promote DOC-2, got %#v", results)
+	}
+	if len(reader.queries) != 1 || reader.queries[0].KnowledgeBaseCode != "KB1" || reader.queries[0].OrganizationCode != "ORG1" ||
+		len(reader.queries[0].DocumentCodes) != 2 {
+		t.Fatalf("unexpected stats query: %#v", reader.queries)
+	}
+
+	promoted := requireRetrievalRanking(t, results[0].Metadata)
+	demoted := requireRetrievalRanking(t, results[1].Metadata)
+	if promoted.Debug == nil || demoted.Debug == nil {
+		t.Fatalf("expected feedback score breakdown, got %#v / %#v", promoted, demoted)
+	}
+	promotedBoost := promoted.Debug.ScoreBreakdown.FeedbackBoost
+	demotedBoost := demoted.Debug.ScoreBreakdown.FeedbackBoost
+	if promotedBoost <= 0 || promotedBoost > options.MaxBoost {
+		t.Fatalf("expected bounded positive boost, got %v", promotedBoost)
+	}
+	if demotedBoost >= 0 || demotedBoost < -options.MaxBoost {
+		t.Fatalf("expected bounded penalty, got %v", demotedBoost)
+	}
+	if promoted.Debug.ScoreBreakdown.RankingScore != results[0].Score {
+		t.Fatalf("expected ranking score to match result score, got %#v", promoted.Debug.ScoreBreakdown)
+	}
+}
+
+func TestScoreSimilarityResultsKeepsOrderWhenFeedbackUnavailable(t *testing.T) {
+	t.Parallel()
+
+	kb := &sharedsnapshot.KnowledgeBaseRuntimeSnapshot{Code: "KB1", OrganizationCode: "ORG1"}
+	failing := &feedbackAggregateReaderStub{err: errFeedbackStatsUnavailable}
+	results := retrieval.ScoreSimilarityResultsWithFeedbackForTest(
+		"退款", feedbackCandidatesForTest(), kb, 2, failing, retrieval.FeedbackBoostOptions{Enabled: true},
+	)
+	if len(results) != 2 || results[0].DocumentCode != "DOC-1" || results[0].Score != 0.80 {
+		t.Fatalf("expected fusion order to be preserved, got %#v", results)
+	}
+	if ranking := requireRetrievalRanking(t, results[0].Metadata); ranking.Debug == nil || ranking.Debug.ScoreBreakdown.FeedbackBoost != 0 {
+		t.Fatalf("expected zero feedback contribution in breakdown, got %#v", ranking.Debug)
+	}
+
+	disabled := &feedbackAggregateReaderStub{aggregates: []retrieval.FeedbackAggregate{{DocumentCode: "DOC-2", AnsweredCount: 10}}}
+	results = retrieval.ScoreSimilarityResultsWithFeedbackForTest(
+		"退款", feedbackCandidatesForTest(), kb, 2, disabled, retrieval.FeedbackBoostOptions{},
+	)
+	if len(disabled.queries) != 0 {
+		t.Fatalf("expected disabled feedback boost to skip reads, got %#v", disabled.queries)
+	}
+	if ranking := requireRetrievalRanking(t, results[0].Metadata); ranking.Debug != nil {
+		t.Fatalf("expected no feedback breakdown when disabled, got %#v", ranking.Debug)
+	}
+}
+
+func TestScoreSimilarityResultsFeedbackBoostIsAdditive(t *testing.T) {
+	t.Parallel()
+
+	candidates := feedbackCandidatesForTest()
+	candidates[0].Score = 0.02
+	candidates[1].Score = 0
+	reader := &feedbackAggregateReaderStub{aggregates: []retrieval.FeedbackAggregate{
+		{DocumentCode: "DOC-2", FragmentID: 2, AnsweredCount: 10},
+	}}
+	kb := &sharedsnapshot.KnowledgeBaseRuntimeSnapshot{Code: "KB1", OrganizationCode: "ORG1"}
+
+	results := retrieval.ScoreSimilarityResultsWithFeedbackForTest(
+		"退款", candidates, kb, 2, reader, retrieval.FeedbackBoostOptions{Enabled: true, MaxBoost: 0.2},
+	)
+	if len(results) != 2 || results[0].DocumentCode != "DOC-2" {
+		t.Fatalf("expected feedback to promote a near-zero score, got %#v", results)
+	}
+	untouched := requireRetrievalRanking(t, results[1].Metadata)
+	if untouched.Debug == nil || untouched.Debug.ScoreBreakdown.FeedbackBoost != 0 {
+		t.Fatalf("expected zero feedback contribution for result without feedback, got %#v", untouched.Debug)
+	}
+}
@@ -0,0 +1,219 @@
+package retrieval
+
+import (
+	"math"
+	"strings"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/shared"
+)
+
+const (
+	// FusionAlgorithmRelativeScore 表示按通道 min-max 归一化后加权求和。
+	FusionAlgorithmRelativeScore = "relative_score"
+	// FusionAlgorithmRRF 表示倒数排名融合（Reciprocal Rank Fusion）。
+	FusionAlgorithmRRF = "rrf"
+	// FusionAlgorithmDBSF 表示基于分布的分数融合（Distribution-Based Score Fusion）。
+	FusionAlgorithmDBSF = "dbsf"
+
+	// DefaultRRFK 是 RRF 的默认平滑常数，与原论文及主流向量库默认值一致。
+	DefaultRRFK = 60
+
+	dbsfSigmaSpan = 3
+)
+
+// FusionStrategy 定义多路召回通道的分数融合策略。
+//
+// 各策略只负责把单个通道内的候选映射为 [0,1] 的归一化分数，
+// 通道间统一按权重求和，便于在不改动调用方的情况下切换算法做 A/B。
+type FusionStrategy interface {
+	// Name 返回写入 Ranking.FusionAlgorithm 的算法名。
+	Name() string
+	// NormalizeChannel 按通道内排序返回每条候选的归一化分数，下标与入参一一对应。
+	NormalizeChannel(scores []float64) []float64
+}
+
+// RelativeScoreFusion 使用通道内 min-max 归一化，通道只有单一分值时视为满分。
+type RelativeScoreFusion struct{}
+
+// Name 返回算法名。
+func (RelativeScoreFusion) Name() string {
+	return FusionAlgorithmRelativeScore
+}
+
+// NormalizeChannel 执行 min-max 归一化。
+func (RelativeScoreFusion) NormalizeChannel(scores []float64) []float64 {
+	normalized := make([]float64, len(scores))
+	if len(scores) == 0 {
+		return normalized
+	}
+	minScore, maxScore := scores[0], scores[0]
+	for _, score := range scores[1:] {
+		minScore = min(minScore, score)
+		maxScore = max(maxScore, score)
+	}
+	for i, score := range scores {
+		normalized[i] = relativeScoreValue(score, minScore, maxScore, maxScore != minScore)
+	}
+	return normalized
+}
+
+func relativeScoreValue(score, minScore, maxScore float64, hasRange bool) float64 {
+	if !hasRange {
+		return 1
+	}
+	return (score - minScore) / (maxScore - minScore)
+}
+
+// ReciprocalRankFusion 只使用通道内排名：1/(k+rank)，并按首位得分缩放到 [0,1]。
+type ReciprocalRankFusion struct {
+	K int
+}
+
+// Name 返回算法名。
+func (ReciprocalRankFusion) Name() string {
+	return FusionAlgorithmRRF
+}
+
+// NormalizeChannel 将排名映射为缩放后的 RRF 分数，使其与相关性阈值保持可比。
+func (f ReciprocalRankFusion) NormalizeChannel(scores []float64) []float64 {
+	k := float64(f.effectiveK())
+	normalized := make([]float64, len(scores))
+	for rank := range scores {
+		normalized[rank] = (k + 1) / (k + float64(rank) + 1)
+	}
+	return normalized
+}
+
+func (f ReciprocalRankFusion) effectiveK() int {
+	if f.K <= 0 {
+		return DefaultRRFK
+	}
+	return f.K
+}
+
+func (f ReciprocalRankFusion) rawScore(rank int) float64 {
+	return 1 / float64(f.effectiveK()+rank+1)
+}
+
+// DistributionScoreFusion 以通道分数的均值 ±3σ 作为归一化区间，降低离群分数对 min-max 的放大。
+type DistributionScoreFusion struct{}
+
+// Name 返回算法名。
+func (DistributionScoreFusion) Name() string {
+	return FusionAlgorithmDBSF
+}
+
+// NormalizeChannel 执行 DBSF 归一化并截断到 [0,1]。
+func (DistributionScoreFusion) NormalizeChannel(scores []float64) []float64 {
+	normalized := make([]float64, len(scores))
+	if len(scores) == 0 {
+		return normalized
+	}
+	var sum float64
+	for _, score := range scores {
+		sum += score
+	}
+	mean := sum / float64(len(scores))
+	var variance float64
+	for _, score := range scores {
+		variance += (score - mean) * (score - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(scores)))
+	if stdDev == 0 {
+		for i := range normalized {
+			normalized[i] = 1
+		}
+		return normalized
+	}
+	lower := mean - dbsfSigmaSpan*stdDev
+	span := 2 * dbsfSigmaSpan * stdDev
+	for i, score := range scores {
+		normalized[i] = min(max((score-lower)/span, 0), 1)
+	}
+	return normalized
+}
+
+// NewFusionStrategy 按算法名创建融合策略，未知或空算法名回退到 relative_score。
+func NewFusionStrategy(algorithm string, rrfK int) FusionStrategy {
+	switch strings.ToLower(strings.TrimSpace(algorithm)) {
+	case FusionAlgorithmRRF, "reciprocal_rank_fusion":
+		return ReciprocalRankFusion{K: rrfK}
+	case FusionAlgorithmDBSF, "distribution_based_score_fusion":
+		return DistributionScoreFusion{}
+	default:
+		return RelativeScoreFusion{}
+	}
+}
+
+func resolveFusionStrategy(cfg *shared.RetrieveConfig) FusionStrategy {
+	if cfg == nil {
+		return RelativeScoreFusion{}
+	}
+	return NewFusionStrategy(cfg.FusionAlgorithm, cfg.FusionRRFK)
+}
+
+func fusionStrategyOrDefault(strategy FusionStrategy) FusionStrategy {
+	if strategy == nil {
+		return RelativeScoreFusion{}
+	}
+	return strategy
+}
+
+// applyFusionStrategy 计算稠密与稀疏通道的归一化分数与加权贡献。
+func applyFusionStrategy(
+	results []*hybridSearchResult,
+	denseResults []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+	sparseResults []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+	config hybridSearchConfig,
+) {
+	strategy := fusionStrategyOrDefault(config.Fusion)
+	denseNorms := normalizeFusionChannel(strategy, denseResults)
+	sparseNorms := normalizeFusionChannel(strategy, sparseResults)
+	rrf, isRRF := strategy.(ReciprocalRankFusion)
+	for _, item := range results {
+		if item == nil {
+			continue
+		}
+		item.rrfScore = 0
+		if item.denseRank >= 0 && item.denseRank < len(denseNorms) {
+			item.denseScoreNorm = denseNorms[item.denseRank]
+			item.denseContribution = item.denseScoreNorm * config.DenseWeight
+			if isRRF {
+				item.rrfScore += rrf.rawScore(item.denseRank)
+			}
+		}
+		if item.sparseRank >= 0 && item.sparseRank < len(sparseNorms) {
+			item.sparseScoreNorm = sparseNorms[item.sparseRank]
+			item.sparseContribution = item.sparseScoreNorm * config.SparseWeight
+			if isRRF {
+				item.rrfScore += rrf.rawScore(item.sparseRank)
+			}
+		}
+		item.hybridScore = item.denseContribution + item.sparseContribution
+		item.fusionScoreNorm = item.hybridScore
+	}
+}
+
+// normalizeFusionChannel 按通道原始顺序返回归一化分数，nil 候选不参与归一化且保持 0 分占位。
+func normalizeFusionChannel(
+	strategy FusionStrategy,
+	results []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+) []float64 {
+	scores := make([]float64, 0, len(results))
+	positions := make([]int, 0, len(results))
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		scores = append(scores, result.Score)
+		positions = append(positions, i)
+	}
+	normalized := make([]float64, len(results))
+	for i, score := range strategy.NormalizeChannel(scores) {
+		if i < len(positions) {
+			normalized[positions[i]] = score
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,102 @@
+package retrieval_test
+
+import (
+	"math"
+	"testing"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/fragment/retrieval"
+	"magic/internal/domain/knowledge/shared"
+)
+
+func TestNewFusionStrategySelectsAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"":                       retrieval.FusionAlgorithmRelativeScore,
+		"unknown":                retrieval.FusionAlgorithmRelativeScore,
+		"RRF":                    retrieval.FusionAlgorithmRRF,
+		"reciprocal_rank_fusion": retrieval.FusionAlgorithmRRF,
+		"dbsf":                   retrieval.FusionAlgorithmDBSF,
+	}
+	for input, expected := range cases {
+		if got := retrieval.NewFusionStrategy(input, 0).Name(); got != expected {
+			t.Fatalf("NewFusionStrategy(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestReciprocalRankFusionUsesRankAndConfiguredK(t *testing.T) {
+	t.Parallel()
+
+	normalized := retrieval.ReciprocalRankFusion{K: 1}.NormalizeChannel([]float64{0.9, 0.1, 0.05})
+	expected := []float64{1, 2.0 / 3.0, 0.5}
+	for i := range expected {
+		if math.Abs(normalized[i]-expected[i]) > 1e-9 {
+			t.Fatalf("unexpected rrf normalization: %#v", normalized)
+		}
+	}
+
+	fused := retrieval.FuseHybridResultsWithStrategyForTest(
+		fusionResults([]string{"a", "b"}, []float64{0.9, 0.8}),
+		fusionResults([]string{"b", "a"}, []float64{12, 3}),
+		retrieval.ReciprocalRankFusion{K: 60},
+	)
+	if len(fused) != 2 {
+		t.Fatalf("expected two fused results, got %d", len(fused))
+	}
+	for _, result := range fused {
+		if result.Metadata["fusion_algorithm"] != retrieval.FusionAlgorithmRRF {
+			t.Fatalf("expected rrf algorithm in metadata, got %v", result.Metadata["fusion_algorithm"])
+		}
+		if rrf, _ := result.Metadata["rrf_score"].(float64); math.Abs(rrf-(1.0/61+1.0/62)) > 1e-9 {
+			t.Fatalf("expected raw rrf score for %s, got %v", result.ID, rrf)
+		}
+	}
+}
+
+func TestDistributionScoreFusionDampensOutliers(t *testing.T) {
+	t.Parallel()
+
+	scores := []float64{100, 1, 0.9, 0.8, 0.7}
+	relative := retrieval.RelativeScoreFusion{}.NormalizeChannel(scores)
+	dbsf := retrieval.DistributionScoreFusion{}.NormalizeChannel(scores)
+	if dbsf[1] <= relative[1] || dbsf[1] <= dbsf[4] {
+		t.Fatalf("expected dbsf to keep non-outliers away from zero while preserving order, relative=%#v dbsf=%#v", relative, dbsf)
+	}
+	for _, score := range dbsf {
+		if score < 0 || score > 1 {
+			t.Fatalf("expected dbsf scores within [0,1], got %#v", dbsf)
+		}
+	}
+
+	flat := retrieval.DistributionScoreFusion{}.NormalizeChannel([]float64{0.5, 0.5})
+	if flat[0] != 1 || flat[1] != 1 {
+		t.Fatalf("expected flat channel to normalize to 1, got %#v", flat)
+	}
+}
+
+func TestResolveHybridSearchConfigUsesKnowledgeBaseFusionAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	config := retrieval.ResolveHybridSearchConfigForTest(10, &struct {
+		RetrieveConfig *shared.RetrieveConfig
+	}{
+		RetrieveConfig: &shared.RetrieveConfig{FusionAlgorithm: "dbsf"},
+	})
+	if config.FusionAlgorithm != retrieval.FusionAlgorithmDBSF {
+		t.Fatalf("expected dbsf fusion, got %q", config.FusionAlgorithm)
+	}
+}
+
+func fusionResults(ids []string, scores []float64) []*shared.VectorSearchResult[fragmodel.FragmentPayload] {
+	results := make([]*shared.VectorSearchResult[fragmodel.FragmentPayload], 0, len(ids))
+	for i, id := range ids {
+		results = append(results, &shared.VectorSearchResult[fragmodel.FragmentPayload]{
+			ID:      id,
+			Score:   scores[i],
+			Content: id,
+		})
+	}
+	return results
+}
@@ -0,0 +1,286 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/shared"
+	sharedsnapshot "magic/internal/domain/knowledge/shared/snapshot"
+	"magic/internal/pkg/logkey"
+)
+
+const (
+	graphMatchedEntitiesMetadataKey = "graph_matched_entities"
+
+	defaultGraphMaxDepth      = 2
+	maxGraphDepth             = 3
+	maxGraphRetryCount        = 3
+	defaultGraphSearchTimeout = 2 * time.Second
+	graphStructuralWeight     = 0.8
+	graphDenseWeight          = 0.2
+	graphCoverageWeight       = 0.5
+	graphDepthDecay           = 0.5
+)
+
+type graphSearchConfig struct {
+	Enabled           bool
+	Weight            float64
+	MaxDepth          int
+	TopK              int
+	IncludeProperties bool
+	Timeout           time.Duration
+	RetryCount        int
+}
+
+type graphCandidate struct {
+	result         *shared.VectorSearchResult[fragmodel.FragmentPayload]
+	depth          int
+	entities       []string
+	matched        []string
+	matchedPortion float64
+}
+
+func resolveGraphSearchConfig(candidateTopK int, kb sharedsnapshot.KnowledgeBaseRuntimeSnapshot) graphSearchConfig {
+	if !GraphRetrievalEnabled(&kb) {
+		return graphSearchConfig{}
+	}
+	setting := kb.RetrieveConfig.Weights.GraphSetting
+	config := graphSearchConfig{
+		Enabled:           true,
+		Weight:            min(max(setting.RelationWeight, 0), 1),
+		MaxDepth:          setting.MaxDepth,
+		TopK:              candidateTopK,
+		IncludeProperties: setting.IncludeProperties,
+		Timeout:           defaultGraphSearchTimeout,
+		RetryCount:        min(max(setting.RetryCount, 0), maxGraphRetryCount),
+	}
+	if config.MaxDepth <= 0 {
+		config.MaxDepth = defaultGraphMaxDepth
+	}
+	config.MaxDepth = min(config.MaxDepth, maxGraphDepth)
+	if setting.Timeout > 0 {
+		config.Timeout = time.Duration(setting.Timeout * float64(time.Second))
+	}
+	return config
+}
+
+// searchGraphChannel 以查询实体为起点沿共现关系逐跳扩展，召回与查询实体相连的片段。
+//
+// 每一跳都复用稠密向量检索并以 graph_entities 作为硬过滤，因此无需独立图存储；
+// 图通道失败或超时只记录告警，不影响稠密与稀疏通道结果。
+func (s *Service) searchGraphChannel(
+	ctx context.Context,
+	input similaritySingleQueryInput,
+	embed func() ([]float64, error),
+) []*shared.VectorSearchResult[fragmodel.FragmentPayload] {
+	config := input.Hybrid.Graph
+	if !config.Enabled || len(input.GraphEntities) == 0 {
+		return nil
+	}
+	startedAt := time.Now()
+	graphCtx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	queryVector, err := embed()
+	if err != nil {
+		s.logGraphChannelFailure(ctx, startedAt, err)
+		return nil
+	}
+
+	visited := make(map[string]struct{}, maxQueryGraphEntities)
+	candidates := make(map[string]*graphCandidate, config.TopK)
+	frontier := input.GraphEntities
+	for depth := 1; depth <= config.MaxDepth && len(frontier) > 0; depth++ {
+		hits, hopErr := s.searchGraphHop(graphCtx, input, queryVector, frontier)
+		if hopErr != nil {
+			s.logGraphChannelFailure(ctx, startedAt, hopErr)
+			break
+		}
+		for _, entity := range frontier {
+			visited[entity] = struct{}{}
+		}
+		frontier = collectGraphHop(candidates, hits, frontier, visited, depth)
+	}
+
+	results := scoreGraphCandidates(candidates, input.GraphEntities, config)
+	s.logSimilarityStage(ctx, "Knowledge similarity graph channel completed", startedAt, []any{
+		"query_entity_count", len(input.GraphEntities),
+		"result_count", len(results),
+	})
+	return results
+}
+
+func (s *Service) searchGraphHop(
+	ctx context.Context,
+	input similaritySingleQueryInput,
+	queryVector []float64,
+	frontier []string,
+) ([]*shared.VectorSearchResult[fragmodel.FragmentPayload], error) {
+	request := shared.DenseSearchRequest{
+		Collection: input.VectorCollectionName,
+		VectorName: DefaultDenseVectorName,
+		Vector:     queryVector,
+		TopK:       input.Hybrid.Graph.TopK,
+		Filter: mergeVectorFilters(input.Filter, &shared.VectorFilter{
+			Must: []shared.FieldFilter{{
+				Key:   GraphEntitiesFilterKey,
+				Match: shared.Match{InStrings: slices.Clone(frontier)},
+			}},
+		}),
+	}
+	var lastErr error
+	for attempt := 0; attempt <= input.Hybrid.Graph.RetryCount; attempt++ {
+		results, err := s.vectorDataRepo.SearchDenseWithFilter(ctx, request)
+		if err == nil {
+			return results, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, fmt.Errorf("graph hop search: %w", lastErr)
+}
+
+// collectGraphHop 记录本跳命中的片段，并返回由关系边引出的下一跳实体。
+func collectGraphHop(
+	candidates map[string]*graphCandidate,
+	hits []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+	frontier []string,
+	visited map[string]struct{},
+	depth int,
+) []string {
+	next := make([]string, 0, maxQueryGraphEntities)
+	for _, hit := range hits {
+		key := hybridResultKey(hit)
+		if key == "" {
+			continue
+		}
+		entities := metadataStringListValue(hit.Payload.Metadata, GraphEntitiesMetadataKey)
+		matched := intersectGraphEntities(entities, frontier)
+		if len(matched) == 0 {
+			continue
+		}
+		if _, exists := candidates[key]; !exists {
+			candidates[key] = &graphCandidate{
+				result:         cloneVectorSearchResult(hit),
+				depth:          depth,
+				entities:       entities,
+				matched:        matched,
+				matchedPortion: float64(len(matched)) / float64(len(frontier)),
+			}
+		}
+		for _, relation := range metadataStringListValue(hit.Payload.Metadata, GraphRelationsMetadataKey) {
+			left, right, ok := splitGraphRelation(relation)
+			if !ok {
+				continue
+			}
+			for _, neighbour := range graphRelationNeighbours(left, right, matched) {
+				if _, seen := visited[neighbour]; seen || slices.Contains(next, neighbour) || len(next) >= maxQueryGraphEntities {
+					continue
+				}
+				next = append(next, neighbour)
+			}
+		}
+	}
+	return next
+}
+
+func graphRelationNeighbours(left, right string, matched []string) []string {
+	neighbours := make([]string, 0, 2)
+	if slices.Contains(matched, left) {
+		neighbours = append(neighbours, right)
+	}
+	if slices.Contains(matched, right) {
+		neighbours = append(neighbours, left)
+	}
+	return neighbours
+}
+
+// scoreGraphCandidates 综合片段内实体覆盖、同文档实体覆盖与跳数衰减计算图通道分数。
+//
+// 同文档覆盖用于跨片段问题：例如“同时提及供应商 X 与产品 Y 的合同”，X 与 Y 分布在同一文档不同片段时仍可召回。
+func scoreGraphCandidates(
+	candidates map[string]*graphCandidate,
+	queryEntities []string,
+	config graphSearchConfig,
+) []*shared.VectorSearchResult[fragmodel.FragmentPayload] {
+	if len(candidates) == 0 || len(queryEntities) == 0 {
+		return nil
+	}
+	documentEntities := make(map[string]map[string]struct{}, len(candidates))
+	for _, candidate := range candidates {
+		documentCode := strings.TrimSpace(candidate.result.Payload.DocumentCode)
+		if documentEntities[documentCode] == nil {
+			documentEntities[documentCode] = map[string]struct{}{}
+		}
+		for _, entity := range candidate.entities {
+			documentEntities[documentCode][entity] = struct{}{}
+		}
+	}
+
+	results := make([]*shared.VectorSearchResult[fragmodel.FragmentPayload], 0, len(candidates))
+	for _, candidate := range candidates {
+		docEntities := documentEntities[strings.TrimSpace(candidate.result.Payload.DocumentCode)]
+		documentCovered := 0
+		for _, entity := range queryEntities {
+			if _, ok := docEntities[entity]; ok {
+				documentCovered++
+			}
+		}
+		documentCoverage := float64(documentCovered) / float64(len(queryEntities))
+		structural := (graphCoverageWeight*candidate.matchedPortion + (1-graphCoverageWeight)*documentCoverage) *
+			math.Pow(graphDepthDecay, float64(candidate.depth-1))
+		result := candidate.result
+		result.Score = graphStructuralWeight*structural + graphDenseWeight*min(max(result.Score, 0), 1)
+		if result.Metadata == nil {
+			result.Metadata = map[string]any{}
+		}
+		result.Metadata["graph_depth"] = candidate.depth
+		if config.IncludeProperties {
+			result.Metadata[graphMatchedEntitiesMetadataKey] = slices.Clone(candidate.matched)
+		}
+		results = append(results, result)
+	}
+	slices.SortFunc(results, func(a, b *shared.VectorSearchResult[fragmodel.FragmentPayload]) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		default:
+			return strings.Compare(hybridResultKey(a), hybridResultKey(b))
+		}
+	})
+	if config.TopK > 0 && len(results) > config.TopK {
+		results = results[:config.TopK]
+	}
+	return results
+}
+
+func intersectGraphEntities(entities, targets []string) []string {
+	matched := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if slices.Contains(entities, target) {
+			matched = append(matched, target)
+		}
+	}
+	return matched
+}
+
+func (s *Service) logGraphChannelFailure(ctx context.Context, startedAt time.Time, err error) {
+	if s == nil || s.logger == nil {
+		return
+	}
+	s.logger.KnowledgeWarnContext(
+		ctx,
+		"Knowledge similarity graph channel failed, fallback to dense and sparse channels",
+		logkey.DurationMS, logkey.DurationToMS(time.Since(startedAt)),
+		"error", err,
+	)
+}
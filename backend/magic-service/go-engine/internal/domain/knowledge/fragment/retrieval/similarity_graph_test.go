@@ -0,0 +1,168 @@
+package retrieval_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/fragment/retrieval"
+	"magic/internal/domain/knowledge/shared"
+	sharedsnapshot "magic/internal/domain/knowledge/shared/snapshot"
+)
+
+func TestBuildFragmentGraphExtractsEntitiesAndRelations(t *testing.T) {
+	t.Parallel()
+
+	graph := retrieval.BuildFragmentGraph(&fragmodel.KnowledgeBaseFragment{
+		DocumentName: "采购合同",
+		SectionTitle: "供货条款",
+		Content:      "甲方与华为技术有限公司签订《年度框架协议》，采购产品型号 X200。\n供应商：深圳星河电子\nDelivered by Acme Logistics.",
+	})
+
+	for _, expected := range []string{"华为技术有限公司", "华为技术", "年度框架协议", "x200", "深圳星河电子", "acme logistics"} {
+		if !slices.Contains(graph.Entities, expected) {
+			t.Fatalf("expected entity %q in %#v", expected, graph.Entities)
+		}
+	}
+	if !slices.Contains(graph.Relations, "x200|华为技术有限公司") {
+		t.Fatalf("expected co-occurrence relation, got %#v", graph.Relations)
+	}
+}
+
+func TestSearchSimilarityCandidatesFusesGraphChannelWhenEnabled(t *testing.T) {
+	t.Parallel()
+
+	vectorRepo := &graphVectorRepoStub{
+		dense: []*shared.VectorSearchResult[fragmodel.FragmentPayload]{
+			graphSearchResult("p1", "DOC-A", 0.9, nil, nil),
+		},
+		graphHits: []*shared.VectorSearchResult[fragmodel.FragmentPayload]{
+			graphSearchResult("p2", "DOC-B", 0.4, []string{"acme", "x200"}, []string{"acme|x200"}),
+		},
+	}
+	service := retrieval.NewService(
+		nil,
+		embeddingServiceStub{},
+		retrieval.Infra{
+			VectorDataRepo:        vectorRepo,
+			MetaReader:            managedBM25MetaReader(),
+			DefaultEmbeddingModel: "text-embedding-3-small",
+		},
+	)
+
+	kb := &sharedsnapshot.KnowledgeBaseRuntimeSnapshot{
+		Code: "KB1",
+		RetrieveConfig: &shared.RetrieveConfig{
+			TopK: 4,
+			Weights: &shared.RetrieveWeights{
+				GraphSetting: &shared.GraphWeightSetting{
+					Enabled:           true,
+					RelationWeight:    0.5,
+					MaxDepth:          1,
+					IncludeProperties: true,
+				},
+			},
+		},
+	}
+	results, err := retrieval.SearchSimilarityCandidatesForTest(context.Background(), service, kb, retrieval.SimilarityRequest{
+		Query:                   "哪些合同同时提到 Acme 和 X200",
+		TopK:                    4,
+		CandidateScoreThreshold: 0.1,
+	})
+	if err != nil {
+		t.Fatalf("SearchSimilarityCandidatesForTest returned error: %v", err)
+	}
+
+	var graphResult *shared.VectorSearchResult[fragmodel.FragmentPayload]
+	for _, result := range results {
+		if result.ID == "p2" {
+			graphResult = result
+		}
+	}
+	if graphResult == nil {
+		t.Fatalf("expected graph-only candidate in results, got %#v", results)
+	}
+	if presence := graphResult.Metadata["channel_presence"]; presence != "graph_only" {
+		t.Fatalf("expected graph_only presence, got %v", presence)
+	}
+	if graphResult.Metadata["graph_rank"] != 1 {
+		t.Fatalf("expected graph rank 1, got %v", graphResult.Metadata["graph_rank"])
+	}
+	if !vectorRepo.sawGraphFilter {
+		t.Fatal("expected graph hop to filter on graph entities")
+	}
+}
+
+func TestSearchSimilarityCandidatesSkipsGraphChannelByDefault(t *testing.T) {
+	t.Parallel()
+
+	vectorRepo := &graphVectorRepoStub{}
+	service := retrieval.NewService(
+		nil,
+		embeddingServiceStub{},
+		retrieval.Infra{
+			VectorDataRepo:        vectorRepo,
+			MetaReader:            managedBM25MetaReader(),
+			DefaultEmbeddingModel: "text-embedding-3-small",
+		},
+	)
+	_, err := retrieval.SearchSimilarityCandidatesForTest(context.Background(), service, &struct{ Code string }{Code: "KB1"}, retrieval.SimilarityRequest{
+		Query: "Acme X200",
+		TopK:  4,
+	})
+	if err != nil {
+		t.Fatalf("SearchSimilarityCandidatesForTest returned error: %v", err)
+	}
+	if vectorRepo.sawGraphFilter {
+		t.Fatal("expected graph channel to stay disabled without graph_setting.enabled")
+	}
+}
+
+type graphVectorRepoStub struct {
+	vectorDataRepoStub
+
+	dense          []*shared.VectorSearchResult[fragmodel.FragmentPayload]
+	graphHits      []*shared.VectorSearchResult[fragmodel.FragmentPayload]
+	sawGraphFilter bool
+}
+
+func (s *graphVectorRepoStub) SearchDenseWithFilter(
+	_ context.Context,
+	request shared.DenseSearchRequest,
+) ([]*shared.VectorSearchResult[fragmodel.FragmentPayload], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if request.Filter != nil {
+		for _, filter := range request.Filter.Must {
+			if filter.Key == retrieval.GraphEntitiesFilterKey {
+				s.sawGraphFilter = true
+				return s.graphHits, nil
+			}
+		}
+	}
+	return s.dense, nil
+}
+
+func graphSearchResult(
+	id string,
+	documentCode string,
+	score float64,
+	entities []string,
+	relations []string,
+) *shared.VectorSearchResult[fragmodel.FragmentPayload] {
+	metadata := map[string]any{}
+	if len(entities) > 0 {
+		metadata[retrieval.GraphEntitiesMetadataKey] = entities
+		metadata[retrieval.GraphRelationsMetadataKey] = relations
+	}
+	return &shared.VectorSearchResult[fragmodel.FragmentPayload]{
+		ID:      id,
+		Score:   score,
+		Content: id,
+		Payload: fragmodel.FragmentPayload{
+			DocumentCode: documentCode,
+			Metadata:     metadata,
+		},
+	}
+}
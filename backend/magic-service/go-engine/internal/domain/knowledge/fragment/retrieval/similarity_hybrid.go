@@ -9,13 +9,12 @@ import (
 )
 
 const (
-	hybridFusionAlgorithmRelativeScore = "relative_score"
-	defaultDenseWeight                 = 0.55
-	defaultSparseWeight                = 0.45
-	defaultDenseTopKScale              = 2
-	defaultSparseTopKScale             = 3
-	minDenseCandidateTopK              = 20
-	minSparseCandidateTopK             = 30
+	defaultDenseWeight     = 0.55
+	defaultSparseWeight    = 0.45
+	defaultDenseTopKScale  = 2
+	defaultSparseTopKScale = 3
+	minDenseCandidateTopK  = 20
+	minSparseCandidateTopK = 30
 )
 
 type hybridSearchResult struct {
@@ -28,8 +27,12 @@ type hybridSearchResult struct {
 	sparseScoreNorm    float64
 	denseContribution  float64
 	sparseContribution float64
+	graphScore         float64
+	graphContribution  float64
 	denseRank          int
 	sparseRank         int
+	graphRank          int
+	graphMatched       []string
 	fusionScoreNorm    float64
 }
 
@@ -40,22 +43,27 @@ type hybridSearchConfig struct {
 	SparseWeight         float64
 	EffectiveHybridAlpha float64
 	LegacyWeightUpgraded bool
+	Fusion               FusionStrategy
+	Graph                graphSearchConfig
 }
 
 func fuseHybridResults(
 	denseResults []*shared.VectorSearchResult[fragmodel.FragmentPayload],
 	sparseResults []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+	graphResults []*shared.VectorSearchResult[fragmodel.FragmentPayload],
 	config hybridSearchConfig,
 ) []*shared.VectorSearchResult[fragmodel.FragmentPayload] {
-	fused := make(map[string]*hybridSearchResult, len(denseResults)+len(sparseResults))
+	fused := make(map[string]*hybridSearchResult, len(denseResults)+len(sparseResults)+len(graphResults))
 	mergeHybridChannel(fused, denseResults, "dense")
 	mergeHybridChannel(fused, sparseResults, "sparse")
+	mergeHybridChannel(fused, graphResults, "graph")
 
 	results := make([]*hybridSearchResult, 0, len(fused))
 	for _, item := range fused {
 		results = append(results, item)
 	}
-	applyRelativeScoreFusion(results, denseResults, sparseResults, config)
+	applyFusionStrategy(results, denseResults, sparseResults, config)
+	applyGraphChannelFusion(results, len(graphResults) > 0, config)
 	for _, item := range results {
 		finalizeHybridResult(item, config)
 	}
@@ -74,6 +82,10 @@ func fuseHybridResults(
 			return -1
 		case a.sparseScore < b.sparseScore:
 			return 1
+		case a.graphScore > b.graphScore:
+			return -1
+		case a.graphScore < b.graphScore:
+			return 1
 		default:
 			return strings.Compare(hybridResultKey(a.result), hybridResultKey(b.result))
 		}
@@ -105,10 +117,14 @@ func mergeHybridChannel(
 				result:     cloneVectorSearchResult(result),
 				denseRank:  -1,
 				sparseRank: -1,
+				graphRank:  -1,
 			}
 			fused[key] = entry
 		}
 		updateHybridChannelScore(entry, channel, rank, result.Score)
+		if channel == "graph" {
+			entry.graphMatched = metadataStringListValue(result.Metadata, graphMatchedEntitiesMetadataKey)
+		}
 		if entry.result.Score < result.Score {
 			entry.result = cloneVectorSearchResult(result)
 		}
@@ -123,67 +139,34 @@ func updateHybridChannelScore(entry *hybridSearchResult, channel string, rank in
 	case "sparse":
 		entry.sparseScore = max(entry.sparseScore, score)
 		entry.sparseRank = minRank(entry.sparseRank, rank)
+	case "graph":
+		entry.graphScore = max(entry.graphScore, score)
+		entry.graphRank = minRank(entry.graphRank, rank)
 	}
 }
 
-func applyRelativeScoreFusion(
-	results []*hybridSearchResult,
-	denseResults []*shared.VectorSearchResult[fragmodel.FragmentPayload],
-	sparseResults []*shared.VectorSearchResult[fragmodel.FragmentPayload],
-	config hybridSearchConfig,
-) {
-	denseMin, denseMax, denseHasResults, denseHasRange := relativeScoreBounds(denseResults)
-	sparseMin, sparseMax, sparseHasResults, sparseHasRange := relativeScoreBounds(sparseResults)
+// applyGraphChannelFusion 在图通道有结果时按 RelationWeight 让出稠密与稀疏通道的权重。
+//
+// 图通道分数已是 [0,1] 的结构化分数，直接参与加权而不再做 min-max 归一化。
+func applyGraphChannelFusion(results []*hybridSearchResult, hasGraphResults bool, config hybridSearchConfig) {
+	if !hasGraphResults || config.Graph.Weight <= 0 {
+		return
+	}
+	graphWeight := config.Graph.Weight
 	for _, item := range results {
 		if item == nil {
 			continue
 		}
-		if item.denseRank >= 0 && denseHasResults {
-			item.denseScoreNorm = relativeScoreValue(item.denseScore, denseMin, denseMax, denseHasRange)
-			item.denseContribution = item.denseScoreNorm * config.DenseWeight
-		}
-		if item.sparseRank >= 0 && sparseHasResults {
-			item.sparseScoreNorm = relativeScoreValue(item.sparseScore, sparseMin, sparseMax, sparseHasRange)
-			item.sparseContribution = item.sparseScoreNorm * config.SparseWeight
+		item.denseContribution *= 1 - graphWeight
+		item.sparseContribution *= 1 - graphWeight
+		if item.graphRank >= 0 {
+			item.graphContribution = item.graphScore * graphWeight
 		}
-		item.hybridScore = item.denseContribution + item.sparseContribution
+		item.hybridScore = item.denseContribution + item.sparseContribution + item.graphContribution
 		item.fusionScoreNorm = item.hybridScore
-		item.rrfScore = 0
 	}
 }
 
-func relativeScoreBounds(results []*shared.VectorSearchResult[fragmodel.FragmentPayload]) (
-	minScore float64,
-	maxScore float64,
-	hasResults bool,
-	hasRange bool,
-) {
-	for _, result := range results {
-		if result == nil {
-			continue
-		}
-		if !hasResults {
-			minScore = result.Score
-			maxScore = result.Score
-			hasResults = true
-			continue
-		}
-		minScore = min(minScore, result.Score)
-		maxScore = max(maxScore, result.Score)
-	}
-	if !hasResults {
-		return 0, 0, false, false
-	}
-	return minScore, maxScore, true, maxScore != minScore
-}
-
-func relativeScoreValue(score, minScore, maxScore float64, hasRange bool) float64 {
-	if !hasRange {
-		return 1
-	}
-	return (score - minScore) / (maxScore - minScore)
-}
-
 func finalizeHybridResult(item *hybridSearchResult, config hybridSearchConfig) {
 	if item == nil || item.result == nil {
 		return
@@ -191,7 +174,7 @@ func finalizeHybridResult(item *hybridSearchResult, config hybridSearchConfig) {
 	if item.result.Metadata == nil {
 		item.result.Metadata = map[string]any{}
 	}
-	item.result.Metadata["fusion_algorithm"] = hybridFusionAlgorithmRelativeScore
+	item.result.Metadata["fusion_algorithm"] = fusionStrategyOrDefault(config.Fusion).Name()
 	item.result.Metadata["hybrid_score"] = item.hybridScore
 	item.result.Metadata["hybrid_alpha"] = config.EffectiveHybridAlpha
 	item.result.Metadata["rrf_score"] = item.rrfScore
@@ -210,10 +193,29 @@ func finalizeHybridResult(item *hybridSearchResult, config hybridSearchConfig) {
 	if item.sparseRank >= 0 {
 		item.result.Metadata["sparse_rank"] = item.sparseRank + 1
 	}
+	if item.graphRank >= 0 {
+		item.result.Metadata["graph_score"] = item.graphScore
+		item.result.Metadata["graph_contribution"] = item.graphContribution
+		item.result.Metadata["graph_rank"] = item.graphRank + 1
+		if len(item.graphMatched) > 0 {
+			item.result.Metadata[graphMatchedEntitiesMetadataKey] = slices.Clone(item.graphMatched)
+		}
+	}
 	item.result.Score = item.hybridScore
 }
 
 func hybridChannelPresence(item *hybridSearchResult) string {
+	presence := textChannelPresence(item)
+	if item == nil || item.graphRank < 0 {
+		return presence
+	}
+	if presence == "" {
+		return "graph_only"
+	}
+	return presence + "+graph"
+}
+
+func textChannelPresence(item *hybridSearchResult) string {
 	switch {
 	case item == nil:
 		return ""
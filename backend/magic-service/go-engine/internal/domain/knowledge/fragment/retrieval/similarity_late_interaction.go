@@ -0,0 +1,280 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/shared"
+	"magic/internal/pkg/ctxmeta"
+	"magic/internal/pkg/logkey"
+)
+
+const (
+	// MultiVectorInputQuery 表示按查询侧编码（ColBERT 查询会做 [Q] 标记与补齐）。
+	MultiVectorInputQuery = "query"
+	// MultiVectorInputDocument 表示按文档侧编码。
+	MultiVectorInputDocument = "document"
+
+	defaultLateInteractionTimeout       = 3 * time.Second
+	defaultLateInteractionMaxCandidates = 50
+	maxLateInteractionDocumentRunes     = 2000
+)
+
+// ErrLateInteractionResultInvalid 表示 late-interaction 编码结果与输入数量不一致。
+var ErrLateInteractionResultInvalid = errors.New("late interaction result invalid")
+
+// LateInteractionEncoder 定义 ColBERT 类 late-interaction 模型的逐 token 向量编码能力。
+type LateInteractionEncoder interface {
+	EncodeMultiVectors(ctx context.Context, req MultiVectorEncodeRequest) ([][][]float64, error)
+}
+
+// MultiVectorEncodeRequest 表示一次逐 token 向量编码请求，返回值与 Texts 一一对应。
+type MultiVectorEncodeRequest struct {
+	Model          string
+	InputType      string
+	Texts          []string
+	BusinessParams *ctxmeta.BusinessParams
+}
+
+// LateInteractionOptions 表示 late-interaction 多向量模式的运行参数，Model 为空时关闭。
+type LateInteractionOptions struct {
+	Model         string
+	Timeout       time.Duration
+	MaxCandidates int
+}
+
+func normalizeLateInteractionOptions(options LateInteractionOptions) LateInteractionOptions {
+	options.Model = strings.TrimSpace(options.Model)
+	if options.Timeout <= 0 {
+		options.Timeout = defaultLateInteractionTimeout
+	}
+	if options.MaxCandidates <= 0 {
+		options.MaxCandidates = defaultLateInteractionMaxCandidates
+	}
+	return options
+}
+
+// LateInteractionEnabled 判断是否配置了 late-interaction 模型且向量库支持多向量。
+func (s *Service) LateInteractionEnabled() bool {
+	return s != nil && s.lateInteractionEncoder != nil && s.multiVectorRepo != nil && s.lateInteractionOptions.Model != ""
+}
+
+// StoreFragmentMultiVectors 为已写入的片段点补写逐 token 多向量。
+//
+// 未启用多向量模式时直接返回；集合尚未重建出多向量字段时返回 shared.ErrMultiVectorNotConfigured。
+func (s *Service) StoreFragmentMultiVectors(
+	ctx context.Context,
+	collection string,
+	fragments []*fragmodel.KnowledgeBaseFragment,
+	businessParams *ctxmeta.BusinessParams,
+) error {
+	if !s.LateInteractionEnabled() || len(fragments) == 0 {
+		return nil
+	}
+	pointIDs := make([]string, 0, len(fragments))
+	texts := make([]string, 0, len(fragments))
+	for _, fragment := range fragments {
+		if fragment == nil || strings.TrimSpace(fragment.PointID) == "" {
+			continue
+		}
+		text := buildLateInteractionDocument(fragment.SectionTitle, fragment.Content)
+		if text == "" {
+			continue
+		}
+		pointIDs = append(pointIDs, fragment.PointID)
+		texts = append(texts, text)
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+	vectors, err := s.encodeMultiVectors(ctx, MultiVectorInputDocument, texts, businessParams)
+	if err != nil {
+		return err
+	}
+	if err := s.multiVectorRepo.StoreMultiVectors(ctx, collection, pointIDs, vectors); err != nil {
+		return fmt.Errorf("store late interaction vectors: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) encodeMultiVectors(
+	ctx context.Context,
+	inputType string,
+	texts []string,
+	businessParams *ctxmeta.BusinessParams,
+) ([][][]float64, error) {
+	vectors, err := s.lateInteractionEncoder.EncodeMultiVectors(ctx, MultiVectorEncodeRequest{
+		Model:          s.lateInteractionOptions.Model,
+		InputType:      inputType,
+		Texts:          texts,
+		BusinessParams: businessParams,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode late interaction vectors: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("%w: expected %d results, got %d", ErrLateInteractionResultInvalid, len(texts), len(vectors))
+	}
+	return vectors, nil
+}
+
+// lateInteractionRescore 用 MaxSim 对融合后的前 N 个候选重新打分。
+//
+// 与交叉编码重排一致：失败、超时或无候选被打分时保留融合排序；
+// 成功后被打分的候选排在前面，分数取 MaxSim 除以查询 token 数，即每个查询 token 的平均最大余弦相似度；
+// 窗口内缺少多向量的候选与窗口外的候选按融合顺序追加在后，不因重打分丢失召回。
+func (s *Service) lateInteractionRescore(
+	ctx context.Context,
+	req SimilarityRequest,
+	trace similaritySearchTrace,
+	results []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+) []*shared.VectorSearchResult[fragmodel.FragmentPayload] {
+	if !s.LateInteractionEnabled() || len(results) == 0 || strings.TrimSpace(trace.VectorCollectionName) == "" {
+		return results
+	}
+	query := strings.TrimSpace(trace.QueryProfile.RawQuery)
+	if query == "" {
+		query = strings.TrimSpace(trace.QueryProfile.DenseQuery)
+	}
+	if query == "" {
+		return results
+	}
+	candidates := results[:min(s.lateInteractionOptions.MaxCandidates, len(results))]
+	pointIDs := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate != nil && strings.TrimSpace(candidate.ID) != "" {
+			pointIDs = append(pointIDs, candidate.ID)
+		}
+	}
+	if len(pointIDs) == 0 {
+		return results
+	}
+
+	startedAt := time.Now()
+	rescoreCtx, cancel := context.WithTimeout(ctx, s.lateInteractionOptions.Timeout)
+	defer cancel()
+	scores, queryTokens, err := s.searchLateInteractionScores(rescoreCtx, trace.VectorCollectionName, query, pointIDs, req.BusinessParams)
+	if err == nil && len(scores) == 0 {
+		err = fmt.Errorf("%w: no candidate scored", ErrLateInteractionResultInvalid)
+	}
+	if err != nil {
+		if s.logger != nil {
+			s.logger.KnowledgeWarnContext(
+				ctx,
+				"Knowledge similarity late interaction rescore failed, fallback to fusion ranking",
+				logkey.DurationMS, logkey.DurationToMS(time.Since(startedAt)),
+				"late_interaction_model", s.lateInteractionOptions.Model,
+				"candidate_count", len(pointIDs),
+				"error", err,
+			)
+		}
+		return results
+	}
+
+	rescored := applyLateInteractionScores(results, len(candidates), scores, queryTokens, s.lateInteractionOptions.Model)
+	s.logSimilarityStage(ctx, "Knowledge similarity late interaction rescore completed", startedAt, []any{
+		"late_interaction_model", s.lateInteractionOptions.Model,
+		"candidate_count", len(pointIDs),
+		"scored_count", len(scores),
+		"result_count", len(rescored),
+		"query_token_count", queryTokens,
+	})
+	return rescored
+}
+
+func (s *Service) searchLateInteractionScores(
+	ctx context.Context,
+	collection string,
+	query string,
+	pointIDs []string,
+	businessParams *ctxmeta.BusinessParams,
+) (map[string]float64, int, error) {
+	vectors, err := s.encodeMultiVectors(ctx, MultiVectorInputQuery, []string{query}, businessParams)
+	if err != nil {
+		return nil, 0, err
+	}
+	queryVectors := vectors[0]
+	if len(queryVectors) == 0 {
+		return nil, 0, fmt.Errorf("%w: empty query vectors", ErrLateInteractionResultInvalid)
+	}
+	matches, err := s.multiVectorRepo.SearchMultiVectorWithFilter(ctx, shared.MultiVectorSearchRequest{
+		Collection: collection,
+		VectorName: shared.DefaultMultiVectorName,
+		Vectors:    queryVectors,
+		PointIDs:   pointIDs,
+		TopK:       len(pointIDs),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("search late interaction vectors: %w", err)
+	}
+	scores := make(map[string]float64, len(matches))
+	for _, match := range matches {
+		if match != nil && strings.TrimSpace(match.ID) != "" {
+			scores[match.ID] = match.Score
+		}
+	}
+	return scores, len(queryVectors), nil
+}
+
+func applyLateInteractionScores(
+	results []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+	candidateCount int,
+	scores map[string]float64,
+	queryTokens int,
+	model string,
+) []*shared.VectorSearchResult[fragmodel.FragmentPayload] {
+	rescored := make([]*shared.VectorSearchResult[fragmodel.FragmentPayload], 0, len(scores))
+	unscored := make([]*shared.VectorSearchResult[fragmodel.FragmentPayload], 0, len(results))
+	for i, candidate := range results {
+		if candidate == nil {
+			continue
+		}
+		maxSim, ok := scores[candidate.ID]
+		if !ok || i >= candidateCount {
+			unscored = append(unscored, candidate)
+			continue
+		}
+		cloned := cloneVectorSearchResult(candidate)
+		if cloned.Metadata == nil {
+			cloned.Metadata = map[string]any{}
+		}
+		score := maxSim / float64(max(queryTokens, 1))
+		cloned.Metadata["late_interaction_score"] = score
+		cloned.Metadata["late_interaction_maxsim"] = maxSim
+		cloned.Metadata["late_interaction_model"] = model
+		cloned.Score = score
+		rescored = append(rescored, cloned)
+	}
+	// 稳定排序，同分时保持融合排序。
+	slices.SortStableFunc(rescored, func(a, b *shared.VectorSearchResult[fragmodel.FragmentPayload]) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		default:
+			return 0
+		}
+	})
+	for i, item := range rescored {
+		item.Metadata["late_interaction_rank"] = i + 1
+	}
+	return append(rescored, unscored...)
+}
+
+func buildLateInteractionDocument(title, content string) string {
+	content = strings.TrimSpace(content)
+	if title = strings.TrimSpace(title); title != "" && !strings.HasPrefix(content, title) {
+		content = strings.TrimSpace(title + "\n" + content)
+	}
+	if utf8.RuneCountInString(content) <= maxLateInteractionDocumentRunes {
+		return content
+	}
+	return string([]rune(content)[:maxLateInteractionDocumentRunes])
+}
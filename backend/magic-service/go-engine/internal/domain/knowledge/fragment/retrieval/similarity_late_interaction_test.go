@@ -0,0 +1,262 @@
+package retrieval_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	retrieval "magic/internal/domain/knowledge/fragment/retrieval"
+	shared "magic/internal/domain/knowledge/shared"
+	sharedsnapshot "magic/internal/domain/knowledge/shared/snapshot"
+)
+
+var errMultiVectorSearchUnavailable = errors.New("multi vector search unavailable")
+
+type lateInteractionEncoderStub struct {
+	mu       sync.Mutex
+	requests []retrieval.MultiVectorEncodeRequest
+}
+
+func (s *lateInteractionEncoderStub) EncodeMultiVectors(
+	_ context.Context,
+	req retrieval.MultiVectorEncodeRequest,
+) ([][][]float64, error) {
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+	vectors := make([][][]float64, len(req.Texts))
+	for i := range req.Texts {
+		vectors[i] = [][]float64{{1, 0}, {0, 1}}
+	}
+	return vectors, nil
+}
+
+func (s *lateInteractionEncoderStub) recordedRequests() []retrieval.MultiVectorEncodeRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]retrieval.MultiVectorEncodeRequest(nil), s.requests...)
+}
+
+type multiVectorRepoStub struct {
+	*vectorDataRepoStub
+
+	mu             sync.Mutex
+	stored         map[string][][]float64
+	searchRequests []shared.MultiVectorSearchRequest
+	searchResults  []*shared.VectorSearchResult[fragmodel.FragmentPayload]
+	searchErr      error
+}
+
+func (s *multiVectorRepoStub) StoreMultiVectors(_ context.Context, _ string, pointIDs []string, multiVectors [][][]float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stored == nil {
+		s.stored = map[string][][]float64{}
+	}
+	for i, pointID := range pointIDs {
+		s.stored[pointID] = multiVectors[i]
+	}
+	return nil
+}
+
+func (s *multiVectorRepoStub) SearchMultiVectorWithFilter(
+	_ context.Context,
+	request shared.MultiVectorSearchRequest,
+) ([]*shared.VectorSearchResult[fragmodel.FragmentPayload], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.searchRequests = append(s.searchRequests, request)
+	return s.searchResults, s.searchErr
+}
+
+func TestSimilarityLateInteractionRescoresFusedCandidates(t *testing.T) {
+	t.Parallel()
+
+	repo := newLateInteractionRepoForTest()
+	repo.searchResults = []*shared.VectorSearchResult[fragmodel.FragmentPayload]{
+		{ID: "point-second", Score: 1.8},
+		{ID: "point-first", Score: 1.2},
+	}
+	encoder := &lateInteractionEncoderStub{}
+	results := runLateInteractionSimilarityForTest(t, newLateInteractionServiceForTest(repo, encoder))
+
+	requests := encoder.recordedRequests()
+	if len(requests) != 1 || requests[0].InputType != retrieval.MultiVectorInputQuery || requests[0].Model != "jina-colbert-v2" {
+		t.Fatalf("unexpected encode requests: %#v", requests)
+	}
+	if len(repo.searchRequests) != 1 || len(repo.searchRequests[0].PointIDs) != 2 || repo.searchRequests[0].TopK != 2 {
+		t.Fatalf("expected MaxSim search over fused candidates, got %#v", repo.searchRequests)
+	}
+	if len(results) != 2 || results[0].DocumentCode != "doc-second" || results[0].Score != 0.9 {
+		t.Fatalf("expected late interaction order with per-token score, got %#v", results)
+	}
+	ranking, ok := results[0].Metadata["retrieval_ranking"].(retrieval.Ranking)
+	if !ok {
+		t.Fatalf("expected retrieval_ranking metadata, got %#v", results[0].Metadata)
+	}
+	if ranking.LateInteraction == nil || ranking.LateInteraction.Rank == nil || *ranking.LateInteraction.Rank != 1 {
+		t.Fatalf("expected late interaction channel score, got %#v", ranking.LateInteraction)
+	}
+	if ranking.LateInteractionModel != "jina-colbert-v2" {
+		t.Fatalf("expected late interaction model, got %q", ranking.LateInteractionModel)
+	}
+	if _, exists := results[0].Metadata["late_interaction_score"]; exists {
+		t.Fatalf("expected raw late interaction metadata to be cleaned up, got %#v", results[0].Metadata)
+	}
+}
+
+func TestSimilarityLateInteractionFallsBackToFusionRankingOnError(t *testing.T) {
+	t.Parallel()
+
+	repo := newLateInteractionRepoForTest()
+	repo.searchErr = errMultiVectorSearchUnavailable
+	results := runLateInteractionSimilarityForTest(t, newLateInteractionServiceForTest(repo, &lateInteractionEncoderStub{}))
+	if len(results) != 2 || results[0].DocumentCode != "doc-first" {
+		t.Fatalf("expected fusion ranking to be kept, got %#v", results)
+	}
+	ranking, ok := results[0].Metadata["retrieval_ranking"].(retrieval.Ranking)
+	if !ok || ranking.LateInteraction != nil {
+		t.Fatalf("expected no late interaction observation on fallback, got %#v", results[0].Metadata)
+	}
+}
+
+func TestSimilarityLateInteractionKeepsCandidatesWithoutMultiVectors(t *testing.T) {
+	t.Parallel()
+
+	repo := newLateInteractionRepoForTest()
+	// point-first 没有写入多向量，MaxSim 只返回 point-second。
+	repo.searchResults = []*shared.VectorSearchResult[fragmodel.FragmentPayload]{{ID: "point-second", Score: 0.6}}
+	results := runLateInteractionSimilarityForTest(t, newLateInteractionServiceForTest(repo, &lateInteractionEncoderStub{}))
+
+	if len(repo.searchRequests) != 1 || len(repo.searchRequests[0].PointIDs) != 2 {
+		t.Fatalf("expected MaxSim search over both candidates, got %#v", repo.searchRequests)
+	}
+	if len(results) != 2 || results[0].DocumentCode != "doc-second" || results[1].DocumentCode != "doc-first" {
+		t.Fatalf("expected unscored candidate appended after rescored one, got %#v", results)
+	}
+	ranking, ok := results[1].Metadata["retrieval_ranking"].(retrieval.Ranking)
+	if !ok || ranking.LateInteraction != nil {
+		t.Fatalf("expected no late interaction observation for unscored candidate, got %#v", results[1].Metadata)
+	}
+}
+
+func TestSimilarityLateInteractionKeepsCandidatesBeyondWindow(t *testing.T) {
+	t.Parallel()
+
+	repo := newLateInteractionRepoForTest()
+	repo.searchResults = []*shared.VectorSearchResult[fragmodel.FragmentPayload]{{ID: "point-first", Score: 0.2}}
+	service := newLateInteractionServiceWithOptionsForTest(repo, &lateInteractionEncoderStub{}, retrieval.LateInteractionOptions{
+		Model:         "jina-colbert-v2",
+		MaxCandidates: 1,
+	})
+	results := runLateInteractionSimilarityForTest(t, service)
+
+	if len(repo.searchRequests) != 1 || len(repo.searchRequests[0].PointIDs) != 1 {
+		t.Fatalf("expected MaxSim search limited to the window, got %#v", repo.searchRequests)
+	}
+	if len(results) != 2 || results[0].DocumentCode != "doc-first" || results[1].DocumentCode != "doc-second" {
+		t.Fatalf("expected fused candidate beyond window to be kept, got %#v", results)
+	}
+}
+
+func TestStoreFragmentMultiVectorsEncodesDocuments(t *testing.T) {
+	t.Parallel()
+
+	repo := newLateInteractionRepoForTest()
+	encoder := &lateInteractionEncoderStub{}
+	service := newLateInteractionServiceForTest(repo, encoder)
+	err := service.StoreFragmentMultiVectors(context.Background(), "kb_collection", []*fragmodel.KnowledgeBaseFragment{
+		{PointID: "p1", SectionTitle: "退款", Content: "退款会原路返回"},
+		{PointID: "", Content: "缺少 point id 的片段不写入"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("StoreFragmentMultiVectors returned error: %v", err)
+	}
+	requests := encoder.recordedRequests()
+	if len(requests) != 1 || requests[0].InputType != retrieval.MultiVectorInputDocument || len(requests[0].Texts) != 1 {
+		t.Fatalf("unexpected encode requests: %#v", requests)
+	}
+	if len(repo.stored) != 1 || len(repo.stored["p1"]) != 2 {
+		t.Fatalf("unexpected stored multi vectors: %#v", repo.stored)
+	}
+
+	disabled := retrieval.NewService(nil, embeddingServiceStub{}, retrieval.Infra{VectorDataRepo: repo})
+	if disabled.LateInteractionEnabled() {
+		t.Fatal("expected late interaction to be disabled without encoder and model")
+	}
+}
+
+func newLateInteractionRepoForTest() *multiVectorRepoStub {
+	return &multiVectorRepoStub{vectorDataRepoStub: &vectorDataRepoStub{
+		denseResponsePlan: [][]*shared.VectorSearchResult[fragmodel.FragmentPayload]{{
+			{
+				ID:      "point-first",
+				Score:   0.88,
+				Content: "退货需要在七天内提交申请",
+				Payload: fragmodel.FragmentPayload{
+					FragmentID:    1,
+					DocumentCode:  "doc-first",
+					KnowledgeCode: "KB1",
+					Metadata:      map[string]any{},
+				},
+				Metadata: map[string]any{},
+			},
+			{
+				ID:      "point-second",
+				Score:   0.61,
+				Content: "退款会在审核通过后原路返回",
+				Payload: fragmodel.FragmentPayload{
+					FragmentID:    2,
+					DocumentCode:  "doc-second",
+					KnowledgeCode: "KB1",
+					Metadata:      map[string]any{},
+				},
+				Metadata: map[string]any{},
+			},
+		}},
+	}}
+}
+
+func newLateInteractionServiceForTest(repo *multiVectorRepoStub, encoder retrieval.LateInteractionEncoder) *retrieval.Service {
+	return newLateInteractionServiceWithOptionsForTest(repo, encoder, retrieval.LateInteractionOptions{Model: "jina-colbert-v2"})
+}
+
+func newLateInteractionServiceWithOptionsForTest(
+	repo *multiVectorRepoStub,
+	encoder retrieval.LateInteractionEncoder,
+	options retrieval.LateInteractionOptions,
+) *retrieval.Service {
+	return retrieval.NewService(
+		nil,
+		embeddingServiceStub{},
+		retrieval.Infra{
+			VectorDataRepo:         repo,
+			MetaReader:             managedBM25MetaReader(),
+			DefaultEmbeddingModel:  "text-embedding-3-small",
+			LateInteractionEncoder: encoder,
+			LateInteractionOptions: options,
+		},
+	)
+}
+
+func runLateInteractionSimilarityForTest(t *testing.T, service *retrieval.Service) []*fragmodel.SimilarityResult {
+	t.Helper()
+
+	cfg := shared.DefaultRetrieveConfig()
+	cfg.RerankingEnable = false
+	results, err := service.Similarity(
+		context.Background(),
+		&sharedsnapshot.KnowledgeBaseRuntimeSnapshot{Code: "KB1", RetrieveConfig: cfg},
+		retrieval.SimilarityRequest{
+			Query:                   "退款多久到账",
+			TopK:                    2,
+			CandidateScoreThreshold: 0.1,
+		},
+	)
+	if err != nil {
+		t.Fatalf("Similarity returned error: %v", err)
+	}
+	return results
+}
@@ -0,0 +1,331 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/shared"
+	sharedsnapshot "magic/internal/domain/knowledge/shared/snapshot"
+	"magic/internal/pkg/ctxmeta"
+	"magic/internal/pkg/logkey"
+)
+
+const (
+	chatRoleSystem = "system"
+	chatRoleUser   = "user"
+
+	defaultQueryExpansionTimeout     = 5 * time.Second
+	defaultQueryExpansionCount       = 3
+	defaultQueryExpansionMaxVariants = 5
+	maxExpandedQueryRunes            = 200
+	maxHypotheticalDocumentRunes     = 800
+	queryExpansionTemperature        = 0.7
+	hydeTemperature                  = 0.3
+	queryExpansionMaxTokens          = 512
+
+	multiQueryExpansionPrompt = "你是知识库检索的查询改写助手。请为用户问题生成 %d 个语义等价但措辞不同的检索查询，" +
+		"可以补全省略的主语、展开缩写或换用同义词。每行输出一个查询，不要编号，不要解释。"
+	hydeExpansionPrompt = "请直接写一段可能出现在知识库文档中、能够回答用户问题的简短段落，不超过 200 字。" +
+		"不要复述问题，不要说明这是假设内容。"
+)
+
+// ErrQueryExpansionEmbeddingMismatch 表示查询变体与返回向量数量不一致。
+var ErrQueryExpansionEmbeddingMismatch = errors.New("query expansion embedding count mismatch")
+
+var queryExpansionLinePrefixRegex = regexp.MustCompile(`^\s*(?:[-*•]+|\d+[.)、:]|[(（]\d+[)）])\s*`)
+
+// ChatCompleter 定义查询改写依赖的对话模型能力。
+type ChatCompleter interface {
+	CompleteChat(ctx context.Context, req ChatCompletionRequest) (string, error)
+}
+
+// ChatMessage 表示一条对话消息。
+type ChatMessage struct {
+	Role    string
+	Content string
+}
+
+// ChatCompletionRequest 表示一次对话补全请求。
+type ChatCompletionRequest struct {
+	Model          string
+	Messages       []ChatMessage
+	Temperature    float64
+	MaxTokens      int
+	BusinessParams *ctxmeta.BusinessParams
+}
+
+// QueryExpansionOptions 表示查询扩展阶段的运行参数。
+type QueryExpansionOptions struct {
+	Timeout      time.Duration
+	DefaultModel string
+	MaxVariants  int
+}
+
+type queryExpansionPlan struct {
+	model           string
+	multiQueryCount int
+	hyde            bool
+}
+
+// queryExpansion 表示模型生成的查询变体。
+type queryExpansion struct {
+	Model                string
+	Paraphrases          []string
+	HypotheticalDocument string
+}
+
+func (e queryExpansion) empty() bool {
+	return len(e.Paraphrases) == 0 && e.HypotheticalDocument == ""
+}
+
+type expandedQueryVariant struct {
+	profile   similarityQueryProfile
+	denseOnly bool
+}
+
+func normalizeQueryExpansionOptions(options QueryExpansionOptions) QueryExpansionOptions {
+	if options.Timeout <= 0 {
+		options.Timeout = defaultQueryExpansionTimeout
+	}
+	if options.MaxVariants <= 0 {
+		options.MaxVariants = defaultQueryExpansionMaxVariants
+	}
+	options.DefaultModel = strings.TrimSpace(options.DefaultModel)
+	return options
+}
+
+// resolveQueryExpansionPlan 只在知识库显式开启且可解析出对话模型时启用查询扩展。
+func resolveQueryExpansionPlan(
+	kb sharedsnapshot.KnowledgeBaseRuntimeSnapshot,
+	options QueryExpansionOptions,
+) (queryExpansionPlan, bool) {
+	if kb.RetrieveConfig == nil || kb.RetrieveConfig.QueryExpansion == nil || !kb.RetrieveConfig.QueryExpansion.Enabled {
+		return queryExpansionPlan{}, false
+	}
+	cfg := kb.RetrieveConfig.QueryExpansion
+	options = normalizeQueryExpansionOptions(options)
+	model := strings.TrimSpace(cfg.ModelName)
+	if model == "" {
+		model = options.DefaultModel
+	}
+	if model == "" {
+		return queryExpansionPlan{}, false
+	}
+	count := cfg.MultiQueryCount
+	if count <= 0 && !cfg.HyDEEnabled {
+		count = defaultQueryExpansionCount
+	}
+	count = min(max(count, 0), options.MaxVariants)
+	if count == 0 && !cfg.HyDEEnabled {
+		return queryExpansionPlan{}, false
+	}
+	return queryExpansionPlan{model: model, multiQueryCount: count, hyde: cfg.HyDEEnabled}, true
+}
+
+// expandSimilarityQuery 调用对话模型生成改写查询与假设文档（HyDE）。
+//
+// 两类生成并行执行，任一失败只记录告警并保留另一类结果，检索本身不受影响。
+func (s *Service) expandSimilarityQuery(
+	ctx context.Context,
+	kb sharedsnapshot.KnowledgeBaseRuntimeSnapshot,
+	query string,
+	businessParams *ctxmeta.BusinessParams,
+) queryExpansion {
+	query = strings.TrimSpace(query)
+	if s == nil || s.chatCompleter == nil || query == "" {
+		return queryExpansion{}
+	}
+	plan, ok := resolveQueryExpansionPlan(kb, s.queryExpansionOptions)
+	if !ok {
+		return queryExpansion{}
+	}
+
+	startedAt := time.Now()
+	expandCtx, cancel := context.WithTimeout(ctx, normalizeQueryExpansionOptions(s.queryExpansionOptions).Timeout)
+	defer cancel()
+
+	expansion := queryExpansion{Model: plan.model}
+	var wg sync.WaitGroup
+	if plan.multiQueryCount > 0 {
+		wg.Go(func() {
+			content, err := s.chatCompleter.CompleteChat(expandCtx, ChatCompletionRequest{
+				Model: plan.model,
+				Messages: []ChatMessage{
+					{Role: chatRoleSystem, Content: fmt.Sprintf(multiQueryExpansionPrompt, plan.multiQueryCount)},
+					{Role: chatRoleUser, Content: query},
+				},
+				Temperature:    queryExpansionTemperature,
+				MaxTokens:      queryExpansionMaxTokens,
+				BusinessParams: businessParams,
+			})
+			if err != nil {
+				s.logQueryExpansionFailure(ctx, "multi_query", plan.model, startedAt, err)
+				return
+			}
+			expansion.Paraphrases = parseExpandedQueries(content, query, plan.multiQueryCount)
+		})
+	}
+	if plan.hyde {
+		wg.Go(func() {
+			content, err := s.chatCompleter.CompleteChat(expandCtx, ChatCompletionRequest{
+				Model: plan.model,
+				Messages: []ChatMessage{
+					{Role: chatRoleSystem, Content: hydeExpansionPrompt},
+					{Role: chatRoleUser, Content: query},
+				},
+				Temperature:    hydeTemperature,
+				MaxTokens:      queryExpansionMaxTokens,
+				BusinessParams: businessParams,
+			})
+			if err != nil {
+				s.logQueryExpansionFailure(ctx, "hyde", plan.model, startedAt, err)
+				return
+			}
+			expansion.HypotheticalDocument = truncateRunes(strings.TrimSpace(content), maxHypotheticalDocumentRunes)
+		})
+	}
+	wg.Wait()
+
+	s.logSimilarityStage(ctx, "Knowledge similarity query expansion completed", startedAt, []any{
+		"expansion_model", plan.model,
+		"paraphrase_count", len(expansion.Paraphrases),
+		"hyde", expansion.HypotheticalDocument != "",
+	})
+	return expansion
+}
+
+// searchExpandedQueries 批量生成变体向量后并行检索，结果与原始查询候选合并。
+func (s *Service) searchExpandedQueries(
+	ctx context.Context,
+	searchCtx similaritySearchContext,
+	filter *shared.VectorFilter,
+) []*shared.VectorSearchResult[fragmodel.FragmentPayload] {
+	variants := s.buildExpandedQueryVariants(searchCtx)
+	if len(variants) == 0 {
+		return nil
+	}
+	startedAt := time.Now()
+	texts := make([]string, len(variants))
+	for i, variant := range variants {
+		texts[i] = variant.profile.DenseQuery
+	}
+	vectors, err := s.embeddingSvc.GetEmbeddings(ctx, texts, searchCtx.model, searchCtx.request.BusinessParams)
+	if err == nil && len(vectors) != len(variants) {
+		err = fmt.Errorf("%w: expected %d vectors, got %d", ErrQueryExpansionEmbeddingMismatch, len(variants), len(vectors))
+	}
+	if err != nil {
+		s.logQueryExpansionFailure(ctx, "embedding", searchCtx.expansion.Model, startedAt, err)
+		return nil
+	}
+
+	variantResults := make([][]*shared.VectorSearchResult[fragmodel.FragmentPayload], len(variants))
+	var wg sync.WaitGroup
+	for i, variant := range variants {
+		wg.Go(func() {
+			results, searchErr := s.searchSingleSimilarityQuery(ctx, similaritySingleQueryInput{
+				VectorCollectionName:          searchCtx.vectorCollectionName,
+				TermCollectionName:            searchCtx.termCollectionName,
+				Model:                         searchCtx.model,
+				SparseBackend:                 searchCtx.sparseBackend,
+				QueryProfile:                  variant.profile,
+				QueryVector:                   vectors[i],
+				Hybrid:                        searchCtx.hybrid,
+				DenseCandidateScoreThreshold:  searchCtx.denseCandidateThreshold,
+				SparseCandidateScoreThreshold: searchCtx.sparseCandidateThreshold,
+				Filter:                        filter,
+				BusinessParams:                searchCtx.request.BusinessParams,
+				DenseOnly:                     variant.denseOnly,
+			})
+			if searchErr != nil {
+				s.logQueryExpansionFailure(ctx, "search", searchCtx.expansion.Model, startedAt, searchErr)
+				return
+			}
+			variantResults[i] = results
+		})
+	}
+	wg.Wait()
+
+	merged := make(map[string]*shared.VectorSearchResult[fragmodel.FragmentPayload])
+	for _, results := range variantResults {
+		mergeSimilarityResults(merged, results)
+	}
+	s.logSimilarityStage(ctx, "Knowledge similarity expanded queries searched", startedAt, []any{
+		"variant_count", len(variants),
+		"candidate_count", len(merged),
+	})
+	return collectSimilarityResults(merged)
+}
+
+func (s *Service) buildExpandedQueryVariants(searchCtx similaritySearchContext) []expandedQueryVariant {
+	if searchCtx.expansion.empty() {
+		return nil
+	}
+	analyzer := s.newRetrievalAnalyzer()
+	variants := make([]expandedQueryVariant, 0, len(searchCtx.expansion.Paraphrases)+1)
+	for _, paraphrase := range searchCtx.expansion.Paraphrases {
+		variants = append(variants, expandedQueryVariant{
+			profile:   buildSimilarityQueryProfile(paraphrase, paraphrase, analyzer),
+			denseOnly: searchCtx.denseOnly || shouldUseDenseOnlySimilarityQuery(paraphrase, analyzer),
+		})
+	}
+	if doc := searchCtx.expansion.HypotheticalDocument; doc != "" {
+		// HyDE 只替换稠密向量，稀疏通道已由原始查询覆盖，避免假设文档中的臆造词干扰关键词召回。
+		variants = append(variants, expandedQueryVariant{
+			profile:   buildSimilarityQueryProfile(searchCtx.queryProfile.RawQuery, doc, analyzer),
+			denseOnly: true,
+		})
+	}
+	return variants
+}
+
+// parseExpandedQueries 解析模型按行输出的改写查询，去掉编号并剔除与原问题重复的行。
+func parseExpandedQueries(content, original string, limit int) []string {
+	normalizedOriginal := normalizeDenseSimilarityQuery(original)
+	queries := make([]string, 0, limit)
+	seen := map[string]struct{}{normalizedOriginal: {}}
+	for _, line := range strings.Split(content, "\n") {
+		if len(queries) >= limit {
+			break
+		}
+		query := strings.TrimSpace(queryExpansionLinePrefixRegex.ReplaceAllString(line, ""))
+		query = strings.Trim(query, "\"'“”")
+		if query == "" || utf8.RuneCountInString(query) > maxExpandedQueryRunes {
+			continue
+		}
+		key := normalizeDenseSimilarityQuery(query)
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		queries = append(queries, query)
+	}
+	return queries
+}
+
+func truncateRunes(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return string([]rune(text)[:limit])
+}
+
+func (s *Service) logQueryExpansionFailure(ctx context.Context, stage, model string, startedAt time.Time, err error) {
+	if s == nil || s.logger == nil {
+		return
+	}
+	s.logger.KnowledgeWarnContext(
+		ctx,
+		"Knowledge similarity query expansion failed, fallback to original query",
+		logkey.DurationMS, logkey.DurationToMS(time.Since(startedAt)),
+		"stage", stage,
+		"expansion_model", model,
+		"error", err,
+	)
+}
@@ -0,0 +1,196 @@
+package retrieval_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/fragment/retrieval"
+	"magic/internal/domain/knowledge/shared"
+	sharedsnapshot "magic/internal/domain/knowledge/shared/snapshot"
+)
+
+var errChatCompleterUnavailable = errors.New("chat completer unavailable")
+
+func TestSimilaritySearchesExpandedQueriesAndHyDE(t *testing.T) {
+	t.Parallel()
+
+	embeddingSvc := &recordingEmbeddingServiceStub{}
+	vectorRepo := &vectorDataRepoStub{
+		denseResponsePlan: [][]*shared.VectorSearchResult[fragmodel.FragmentPayload]{
+			{expansionSearchResult("POINT-1", "DOC-1", 0.92, "退款流程说明")},
+			{expansionSearchResult("POINT-2", "DOC-2", 0.88, "售后退款时效")},
+			{expansionSearchResult("POINT-2", "DOC-2", 0.88, "售后退款时效")},
+			{expansionSearchResult("POINT-2", "DOC-2", 0.88, "售后退款时效")},
+		},
+	}
+	chat := &chatCompleterStub{
+		paraphrases: "1. 如何申请退款\n2. 退款需要多长时间\n退款流程是什么",
+		hyde:        "用户提交退款申请后客服会在三个工作日内审核并原路退回款项",
+	}
+	service := newQueryExpansionServiceForTest(embeddingSvc, vectorRepo, chat)
+
+	results, err := service.Similarity(context.Background(), queryExpansionKnowledgeBase(), retrieval.SimilarityRequest{
+		Query:                   "退款流程是什么",
+		TopK:                    2,
+		CandidateScoreThreshold: 0.1,
+		Options:                 &retrieval.SimilaritySearchOptions{Debug: true},
+	})
+	if err != nil {
+		t.Fatalf("Similarity returned error: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected original and expanded candidates, got %#v", results)
+	}
+	if got := chat.callCount(); got != 2 {
+		t.Fatalf("expected multi-query and hyde chat calls, got %d", got)
+	}
+
+	embeddingSvc.mu.Lock()
+	queries := append([]string(nil), embeddingSvc.queries...)
+	embeddingSvc.mu.Unlock()
+	for _, expected := range []string{"如何申请退款", "退款需要多长时间", chat.hyde} {
+		if !slices.Contains(queries, expected) {
+			t.Fatalf("expected %q to be embedded, got %#v", expected, queries)
+		}
+	}
+	if slices.Contains(queries[1:], "退款流程是什么") {
+		t.Fatalf("expected paraphrase equal to original query to be dropped, got %#v", queries)
+	}
+
+	expansion, ok := results[0].Metadata["query_expansion"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected query_expansion debug metadata, got %#v", results[0].Metadata)
+	}
+	if expansion["model"] != "qwen-plus" || expansion["hypothetical_document"] != chat.hyde {
+		t.Fatalf("unexpected query_expansion metadata: %#v", expansion)
+	}
+}
+
+func TestSimilarityFallsBackToOriginalQueryWhenExpansionFails(t *testing.T) {
+	t.Parallel()
+
+	embeddingSvc := &recordingEmbeddingServiceStub{}
+	vectorRepo := &vectorDataRepoStub{
+		denseResponsePlan: [][]*shared.VectorSearchResult[fragmodel.FragmentPayload]{
+			{expansionSearchResult("POINT-1", "DOC-1", 0.92, "退款流程说明")},
+		},
+	}
+	chat := &chatCompleterStub{err: errChatCompleterUnavailable}
+	service := newQueryExpansionServiceForTest(embeddingSvc, vectorRepo, chat)
+
+	results, err := service.Similarity(context.Background(), queryExpansionKnowledgeBase(), retrieval.SimilarityRequest{
+		Query:                   "退款流程是什么",
+		TopK:                    2,
+		CandidateScoreThreshold: 0.1,
+		Options:                 &retrieval.SimilaritySearchOptions{Debug: true},
+	})
+	if err != nil {
+		t.Fatalf("Similarity returned error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected original query result only, got %#v", results)
+	}
+	if _, exists := results[0].Metadata["query_expansion"]; exists {
+		t.Fatalf("expected no query_expansion metadata after failure, got %#v", results[0].Metadata)
+	}
+	if got := vectorRepo.denseCalls.Load(); got != 1 {
+		t.Fatalf("expected only the original dense search, got %d", got)
+	}
+}
+
+func TestSimilaritySkipsExpansionWhenDisabled(t *testing.T) {
+	t.Parallel()
+
+	chat := &chatCompleterStub{paraphrases: "如何申请退款"}
+	service := newQueryExpansionServiceForTest(&recordingEmbeddingServiceStub{}, &vectorDataRepoStub{}, chat)
+	_, err := service.Similarity(context.Background(), &sharedsnapshot.KnowledgeBaseRuntimeSnapshot{Code: "KB1"}, retrieval.SimilarityRequest{
+		Query: "退款流程是什么",
+		TopK:  2,
+	})
+	if err != nil {
+		t.Fatalf("Similarity returned error: %v", err)
+	}
+	if got := chat.callCount(); got != 0 {
+		t.Fatalf("expected no chat calls without query_expansion.enabled, got %d", got)
+	}
+}
+
+type chatCompleterStub struct {
+	paraphrases string
+	hyde        string
+	err         error
+
+	mu    sync.Mutex
+	calls []retrieval.ChatCompletionRequest
+}
+
+func (s *chatCompleterStub) CompleteChat(_ context.Context, req retrieval.ChatCompletionRequest) (string, error) {
+	s.mu.Lock()
+	s.calls = append(s.calls, req)
+	s.mu.Unlock()
+	if s.err != nil {
+		return "", s.err
+	}
+	if len(req.Messages) > 0 && strings.Contains(req.Messages[0].Content, "假设") {
+		return s.hyde, nil
+	}
+	return s.paraphrases, nil
+}
+
+func (s *chatCompleterStub) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.calls)
+}
+
+func newQueryExpansionServiceForTest(
+	embeddingSvc retrieval.EmbeddingService,
+	vectorRepo *vectorDataRepoStub,
+	chat retrieval.ChatCompleter,
+) *retrieval.Service {
+	return retrieval.NewService(
+		nil,
+		embeddingSvc,
+		retrieval.Infra{
+			VectorDataRepo:        vectorRepo,
+			MetaReader:            managedBM25MetaReader(),
+			DefaultEmbeddingModel: "text-embedding-3-small",
+			ChatCompleter:         chat,
+		},
+	)
+}
+
+func queryExpansionKnowledgeBase() *sharedsnapshot.KnowledgeBaseRuntimeSnapshot {
+	return &sharedsnapshot.KnowledgeBaseRuntimeSnapshot{
+		Code: "KB1",
+		RetrieveConfig: &shared.RetrieveConfig{
+			TopK: 2,
+			QueryExpansion: &shared.QueryExpansionConfig{
+				Enabled:         true,
+				MultiQueryCount: 3,
+				HyDEEnabled:     true,
+				ModelName:       "qwen-plus",
+			},
+		},
+	}
+}
+
+func expansionSearchResult(
+	id string,
+	documentCode string,
+	score float64,
+	content string,
+) *shared.VectorSearchResult[fragmodel.FragmentPayload] {
+	return &shared.VectorSearchResult[fragmodel.FragmentPayload]{
+		ID:      id,
+		Score:   score,
+		Content: content,
+		Payload: fragmodel.FragmentPayload{DocumentCode: documentCode},
+	}
+}
@@ -81,6 +81,11 @@ func buildSparseQueryText(tokens []AnalyzedToken) string {
 	return normalizeWhitespace(strings.Join(analyzedTokenTerms(tokens), " "))
 }
 
+// ClassifyQueryType 返回检索链路对 query 的分类（short_keyword / mixed_code_nl / natural_language）。
+func ClassifyQueryType(query string) string {
+	return classifySimilarityQueryType(normalizeDenseSimilarityQuery(query), newRetrievalAnalyzer())
+}
+
 func classifySimilarityQueryType(query string, analyzer retrievalAnalyzer) string {
 	normalized := normalizeRetrievalText(query)
 	if normalized == "" {
@@ -0,0 +1,242 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/shared"
+	sharedsnapshot "magic/internal/domain/knowledge/shared/snapshot"
+	"magic/internal/pkg/ctxmeta"
+	"magic/internal/pkg/logkey"
+)
+
+const (
+	// RerankingModeModel 表示使用重排模型对候选进行重排，与 PHP RerankMode::RERANKING_MODEL 对齐。
+	RerankingModeModel = "reranking_model"
+
+	defaultRerankTimeout       = 3 * time.Second
+	defaultRerankMaxCandidates = 30
+	rerankCandidateTopKScale   = 3
+	maxRerankDocumentRunes     = 2000
+)
+
+// ErrRerankResultInvalid 表示重排模型返回的结果无法映射回候选。
+var ErrRerankResultInvalid = errors.New("rerank result invalid")
+
+// Reranker 定义交叉编码重排模型能力。
+type Reranker interface {
+	Rerank(ctx context.Context, req RerankRequest) ([]RerankResult, error)
+}
+
+// RerankRequest 表示一次交叉编码重排请求。
+type RerankRequest struct {
+	Model          string
+	Provider       string
+	Query          string
+	Documents      []string
+	TopN           int
+	BusinessParams *ctxmeta.BusinessParams
+}
+
+// RerankResult 表示单条候选的重排得分，Index 对应 RerankRequest.Documents 下标。
+type RerankResult struct {
+	Index          int
+	RelevanceScore float64
+}
+
+// RerankOptions 表示重排阶段的运行参数。
+//
+// ScoreThreshold 作用于重排模型相关度，与按融合分量纲设置的结果阈值相互独立，不大于 0 时不过滤。
+type RerankOptions struct {
+	Timeout        time.Duration
+	MaxCandidates  int
+	ScoreThreshold float64
+}
+
+type rerankPlan struct {
+	model    string
+	provider string
+	topN     int
+}
+
+func normalizeRerankOptions(options RerankOptions) RerankOptions {
+	if options.Timeout <= 0 {
+		options.Timeout = defaultRerankTimeout
+	}
+	if options.MaxCandidates <= 0 {
+		options.MaxCandidates = defaultRerankMaxCandidates
+	}
+	return options
+}
+
+func (s *Service) rerankScoreThreshold() float64 {
+	if s == nil {
+		return 0
+	}
+	return s.rerankOptions.ScoreThreshold
+}
+
+// resolveRerankPlan 只在知识库显式选择重排模型模式且配置了模型时启用外部重排。
+func resolveRerankPlan(kb sharedsnapshot.KnowledgeBaseRuntimeSnapshot, topK int, options RerankOptions) (rerankPlan, bool) {
+	cfg := kb.RetrieveConfig
+	if cfg == nil || (!cfg.RerankingEnable && !cfg.RerankEnabled) {
+		return rerankPlan{}, false
+	}
+	if strings.TrimSpace(cfg.RerankingMode) != RerankingModeModel || cfg.RerankingModel == nil {
+		return rerankPlan{}, false
+	}
+	model := strings.TrimSpace(cfg.RerankingModel.RerankingModelName)
+	if model == "" {
+		return rerankPlan{}, false
+	}
+	if topK <= 0 {
+		topK = 10
+	}
+	options = normalizeRerankOptions(options)
+	return rerankPlan{
+		model:    model,
+		provider: strings.TrimSpace(cfg.RerankingModel.RerankingProviderName),
+		topN:     min(max(topK*rerankCandidateTopKScale, topK), options.MaxCandidates),
+	}, true
+}
+
+// rerankSimilarityCandidates 使用外部重排模型对融合后的前 N 个候选重新打分。
+//
+// 重排失败、超时或返回异常时保持原有融合排序，检索请求本身不失败。
+// 重排成功后只保留参与重排的候选，避免模型相关度分数与融合分数混排。
+func (s *Service) rerankSimilarityCandidates(
+	ctx context.Context,
+	kb sharedsnapshot.KnowledgeBaseRuntimeSnapshot,
+	req SimilarityRequest,
+	queryProfile similarityQueryProfile,
+	results []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+) []*shared.VectorSearchResult[fragmodel.FragmentPayload] {
+	if s == nil || s.reranker == nil || len(results) == 0 {
+		return results
+	}
+	plan, ok := resolveRerankPlan(kb, req.TopK, s.rerankOptions)
+	if !ok {
+		return results
+	}
+	query := strings.TrimSpace(queryProfile.RawQuery)
+	if query == "" {
+		query = strings.TrimSpace(queryProfile.DenseQuery)
+	}
+	if query == "" {
+		return results
+	}
+
+	candidates := results[:min(plan.topN, len(results))]
+	documents := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		documents[i] = buildRerankDocument(candidate)
+	}
+
+	startedAt := time.Now()
+	rerankCtx, cancel := context.WithTimeout(ctx, normalizeRerankOptions(s.rerankOptions).Timeout)
+	defer cancel()
+	rerankResults, err := s.reranker.Rerank(rerankCtx, RerankRequest{
+		Model:          plan.model,
+		Provider:       plan.provider,
+		Query:          query,
+		Documents:      documents,
+		TopN:           len(documents),
+		BusinessParams: req.BusinessParams,
+	})
+	if err == nil {
+		err = validateRerankResults(rerankResults, len(candidates))
+	}
+	if err != nil {
+		if s.logger != nil {
+			s.logger.KnowledgeWarnContext(
+				ctx,
+				"Knowledge similarity rerank failed, fallback to fusion ranking",
+				logkey.DurationMS, logkey.DurationToMS(time.Since(startedAt)),
+				"rerank_model", plan.model,
+				"candidate_count", len(candidates),
+				"error", err,
+			)
+		}
+		return results
+	}
+
+	reranked := applyRerankResults(candidates, rerankResults, plan.model)
+	s.logSimilarityStage(ctx, "Knowledge similarity rerank completed", startedAt, []any{
+		"rerank_model", plan.model,
+		"candidate_count", len(candidates),
+		"result_count", len(reranked),
+	})
+	return reranked
+}
+
+func buildRerankDocument(result *shared.VectorSearchResult[fragmodel.FragmentPayload]) string {
+	if result == nil {
+		return ""
+	}
+	content := strings.TrimSpace(result.Content)
+	if title := strings.TrimSpace(result.Payload.SectionTitle); title != "" && !strings.HasPrefix(content, title) {
+		content = title + "\n" + content
+	}
+	if utf8.RuneCountInString(content) <= maxRerankDocumentRunes {
+		return content
+	}
+	return string([]rune(content)[:maxRerankDocumentRunes])
+}
+
+func validateRerankResults(results []RerankResult, candidateCount int) error {
+	if len(results) == 0 {
+		return fmt.Errorf("%w: empty results", ErrRerankResultInvalid)
+	}
+	for _, result := range results {
+		if result.Index < 0 || result.Index >= candidateCount {
+			return fmt.Errorf("%w: index %d out of range %d", ErrRerankResultInvalid, result.Index, candidateCount)
+		}
+	}
+	return nil
+}
+
+func applyRerankResults(
+	candidates []*shared.VectorSearchResult[fragmodel.FragmentPayload],
+	rerankResults []RerankResult,
+	model string,
+) []*shared.VectorSearchResult[fragmodel.FragmentPayload] {
+	ordered := slices.Clone(rerankResults)
+	slices.SortStableFunc(ordered, func(a, b RerankResult) int {
+		switch {
+		case a.RelevanceScore > b.RelevanceScore:
+			return -1
+		case a.RelevanceScore < b.RelevanceScore:
+			return 1
+		default:
+			return a.Index - b.Index
+		}
+	})
+
+	seen := make(map[int]struct{}, len(ordered))
+	reranked := make([]*shared.VectorSearchResult[fragmodel.FragmentPayload], 0, len(ordered))
+	for _, item := range ordered {
+		if _, ok := seen[item.Index]; ok {
+			continue
+		}
+		seen[item.Index] = struct{}{}
+		candidate := cloneVectorSearchResult(candidates[item.Index])
+		if candidate == nil {
+			continue
+		}
+		if candidate.Metadata == nil {
+			candidate.Metadata = map[string]any{}
+		}
+		candidate.Metadata["rerank_score"] = item.RelevanceScore
+		candidate.Metadata["rerank_rank"] = len(reranked) + 1
+		candidate.Metadata["rerank_model"] = model
+		candidate.Score = item.RelevanceScore
+		reranked = append(reranked, candidate)
+	}
+	return reranked
+}
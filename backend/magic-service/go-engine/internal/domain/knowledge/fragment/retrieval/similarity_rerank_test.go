@@ -0,0 +1,247 @@
+package retrieval_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	retrieval "magic/internal/domain/knowledge/fragment/retrieval"
+	shared "magic/internal/domain/knowledge/shared"
+	sharedsnapshot "magic/internal/domain/knowledge/shared/snapshot"
+)
+
+var errRerankUnavailable = errors.New("rerank unavailable")
+
+type rerankerStub struct {
+	mu       sync.Mutex
+	requests []retrieval.RerankRequest
+	results  []retrieval.RerankResult
+	err      error
+	block    bool
+}
+
+func (s *rerankerStub) Rerank(ctx context.Context, req retrieval.RerankRequest) ([]retrieval.RerankResult, error) {
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+	if s.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return s.results, s.err
+}
+
+func (s *rerankerStub) recordedRequests() []retrieval.RerankRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]retrieval.RerankRequest(nil), s.requests...)
+}
+
+func TestSimilarityRerankReordersFusedCandidates(t *testing.T) {
+	t.Parallel()
+
+	reranker := &rerankerStub{results: []retrieval.RerankResult{
+		{Index: 1, RelevanceScore: 0.97},
+		{Index: 0, RelevanceScore: 0.41},
+	}}
+	results := runRerankSimilarityForTest(t, reranker, rerankRetrieveConfigForTest(), time.Second)
+
+	requests := reranker.recordedRequests()
+	if len(requests) != 1 {
+		t.Fatalf("expected one rerank request, got %d", len(requests))
+	}
+	if requests[0].Model != "bge-reranker-v2-m3" || requests[0].Provider != "MagicAI" || len(requests[0].Documents) != 2 {
+		t.Fatalf("unexpected rerank request: %#v", requests[0])
+	}
+	if len(results) != 2 || results[0].DocumentCode != "doc-second" || results[0].Score != 0.97 {
+		t.Fatalf("expected reranked order, got %#v", results)
+	}
+	ranking, ok := results[0].Metadata["retrieval_ranking"].(retrieval.Ranking)
+	if !ok {
+		t.Fatalf("expected retrieval_ranking metadata, got %#v", results[0].Metadata)
+	}
+	if ranking.Rerank == nil || ranking.Rerank.Score != 0.97 || ranking.Rerank.Rank == nil || *ranking.Rerank.Rank != 1 {
+		t.Fatalf("expected rerank channel score, got %#v", ranking.Rerank)
+	}
+	if ranking.RerankModel != "bge-reranker-v2-m3" {
+		t.Fatalf("expected rerank model, got %q", ranking.RerankModel)
+	}
+	if _, exists := results[0].Metadata["rerank_score"]; exists {
+		t.Fatalf("expected raw rerank metadata to be cleaned up, got %#v", results[0].Metadata)
+	}
+}
+
+func TestSimilarityRerankFallsBackToFusionRankingOnError(t *testing.T) {
+	t.Parallel()
+
+	reranker := &rerankerStub{err: errRerankUnavailable}
+	results := runRerankSimilarityForTest(t, reranker, rerankRetrieveConfigForTest(), time.Second)
+	assertFusionRankingKept(t, results)
+}
+
+func TestSimilarityRerankFallsBackToFusionRankingOnTimeout(t *testing.T) {
+	t.Parallel()
+
+	reranker := &rerankerStub{block: true}
+	results := runRerankSimilarityForTest(t, reranker, rerankRetrieveConfigForTest(), 10*time.Millisecond)
+	assertFusionRankingKept(t, results)
+}
+
+func TestSimilarityRerankFallsBackOnOutOfRangeIndex(t *testing.T) {
+	t.Parallel()
+
+	reranker := &rerankerStub{results: []retrieval.RerankResult{{Index: 5, RelevanceScore: 0.9}}}
+	results := runRerankSimilarityForTest(t, reranker, rerankRetrieveConfigForTest(), time.Second)
+	assertFusionRankingKept(t, results)
+}
+
+func TestSimilarityRerankSkippedForWeightedScoreMode(t *testing.T) {
+	t.Parallel()
+
+	cfg := rerankRetrieveConfigForTest()
+	cfg.RerankingMode = "weighted_score"
+	reranker := &rerankerStub{results: []retrieval.RerankResult{{Index: 1, RelevanceScore: 0.9}}}
+	results := runRerankSimilarityForTest(t, reranker, cfg, time.Second)
+	if len(reranker.recordedRequests()) != 0 {
+		t.Fatal("expected weighted_score mode to skip reranker")
+	}
+	assertFusionRankingKept(t, results)
+}
+
+func TestSimilarityRerankAppliesResultThresholdToFusionScore(t *testing.T) {
+	t.Parallel()
+
+	reranker := &rerankerStub{results: []retrieval.RerankResult{
+		{Index: 1, RelevanceScore: 0.97},
+		{Index: 0, RelevanceScore: 0.05},
+	}}
+	results := runRerankSimilarityWithThresholdForTest(
+		t, reranker, rerankRetrieveConfigForTest(), retrieval.RerankOptions{Timeout: time.Second}, 0.3,
+	)
+
+	// 结果阈值按融合分比较：doc-first 融合分高于阈值而保留，doc-second 即使重排分高也因融合分过低被过滤。
+	if len(results) != 1 || results[0].DocumentCode != "doc-first" || results[0].Score != 0.05 {
+		t.Fatalf("expected result threshold to compare fusion scores, got %#v", results)
+	}
+}
+
+func TestSimilarityRerankAppliesSeparateRerankThreshold(t *testing.T) {
+	t.Parallel()
+
+	reranker := &rerankerStub{results: []retrieval.RerankResult{
+		{Index: 1, RelevanceScore: 0.97},
+		{Index: 0, RelevanceScore: 0.05},
+	}}
+	results := runRerankSimilarityWithThresholdForTest(
+		t, reranker, rerankRetrieveConfigForTest(), retrieval.RerankOptions{Timeout: time.Second, ScoreThreshold: 0.2}, 0,
+	)
+
+	if len(results) != 1 || results[0].DocumentCode != "doc-second" {
+		t.Fatalf("expected rerank threshold to drop low relevance candidate, got %#v", results)
+	}
+}
+
+func assertFusionRankingKept(t *testing.T, results []*fragmodel.SimilarityResult) {
+	t.Helper()
+	if len(results) != 2 || results[0].DocumentCode != "doc-first" {
+		t.Fatalf("expected fusion ranking to be kept, got %#v", results)
+	}
+	ranking, ok := results[0].Metadata["retrieval_ranking"].(retrieval.Ranking)
+	if !ok {
+		t.Fatalf("expected retrieval_ranking metadata, got %#v", results[0].Metadata)
+	}
+	if ranking.Rerank != nil || ranking.RerankModel != "" {
+		t.Fatalf("expected no rerank observation on fallback, got %#v", ranking)
+	}
+}
+
+func rerankRetrieveConfigForTest() *shared.RetrieveConfig {
+	cfg := shared.DefaultRetrieveConfig()
+	cfg.RerankingEnable = true
+	cfg.RerankingMode = retrieval.RerankingModeModel
+	cfg.RerankingModel = &shared.RerankingModelConfig{
+		RerankingModelName:    "bge-reranker-v2-m3",
+		RerankingProviderName: "MagicAI",
+	}
+	return cfg
+}
+
+func runRerankSimilarityForTest(
+	t *testing.T,
+	reranker retrieval.Reranker,
+	retrieveConfig *shared.RetrieveConfig,
+	timeout time.Duration,
+) []*fragmodel.SimilarityResult {
+	t.Helper()
+	return runRerankSimilarityWithThresholdForTest(t, reranker, retrieveConfig, retrieval.RerankOptions{Timeout: timeout}, 0)
+}
+
+func runRerankSimilarityWithThresholdForTest(
+	t *testing.T,
+	reranker retrieval.Reranker,
+	retrieveConfig *shared.RetrieveConfig,
+	options retrieval.RerankOptions,
+	resultScoreThreshold float64,
+) []*fragmodel.SimilarityResult {
+	t.Helper()
+
+	vectorRepo := &vectorDataRepoStub{
+		denseResponsePlan: [][]*shared.VectorSearchResult[fragmodel.FragmentPayload]{{
+			{
+				ID:      "point-first",
+				Score:   0.88,
+				Content: "退货需要在七天内提交申请",
+				Payload: fragmodel.FragmentPayload{
+					FragmentID:    1,
+					DocumentCode:  "doc-first",
+					KnowledgeCode: "KB1",
+					SectionPath:   "售后 > 退货",
+					Metadata:      map[string]any{},
+				},
+				Metadata: map[string]any{},
+			},
+			{
+				ID:      "point-second",
+				Score:   0.61,
+				Content: "退款会在审核通过后原路返回",
+				Payload: fragmodel.FragmentPayload{
+					FragmentID:    2,
+					DocumentCode:  "doc-second",
+					KnowledgeCode: "KB1",
+					SectionPath:   "售后 > 退款",
+					Metadata:      map[string]any{},
+				},
+				Metadata: map[string]any{},
+			},
+		}},
+	}
+	service := retrieval.NewService(
+		nil,
+		embeddingServiceStub{},
+		retrieval.Infra{
+			VectorDataRepo:        vectorRepo,
+			MetaReader:            managedBM25MetaReader(),
+			DefaultEmbeddingModel: "text-embedding-3-small",
+			Reranker:              reranker,
+			RerankOptions:         options,
+		},
+	)
+
+	results, err := service.Similarity(
+		context.Background(),
+		&sharedsnapshot.KnowledgeBaseRuntimeSnapshot{Code: "KB1", RetrieveConfig: retrieveConfig},
+		retrieval.SimilarityRequest{
+			Query:                   "退款多久到账",
+			TopK:                    2,
+			CandidateScoreThreshold: 0.1,
+			ResultScoreThreshold:    resultScoreThreshold,
+		},
+	)
+	if err != nil {
+		t.Fatalf("Similarity returned error: %v", err)
+	}
+	return results
+}
@@ -75,6 +75,7 @@ type ScoreBreakdown struct {
 	SupportScore         float64 `json:"support_score"`
 	SecondaryRankScore   float64 `json:"secondary_rank_score"`
 	RerankScore          float64 `json:"rerank_score"`
+	FeedbackBoost        float64 `json:"feedback_boost"`
 	DenseScore           float64 `json:"dense_score"`
 	SparseScore          float64 `json:"sparse_score"`
 	RRFScore             float64 `json:"rrf_score"`
@@ -122,6 +123,12 @@ type Ranking struct {
 	RRFScore             float64       `json:"rrf_score"`
 	Dense                *ChannelScore `json:"dense,omitempty"`
 	Sparse               *ChannelScore `json:"sparse,omitempty"`
+	Graph                *ChannelScore `json:"graph,omitempty"`
+	GraphEntities        []string      `json:"graph_entities,omitempty"`
+	Rerank               *ChannelScore `json:"rerank,omitempty"`
+	RerankModel          string        `json:"rerank_model,omitempty"`
+	LateInteraction      *ChannelScore `json:"late_interaction,omitempty"`
+	LateInteractionModel string        `json:"late_interaction_model,omitempty"`
 	BM25Query            BM25Query     `json:"bm25_query"`
 	Debug                *RankingDebug `json:"debug,omitempty"`
 }
@@ -0,0 +1,110 @@
+package fragdomain
+
+import (
+	"context"
+	"maps"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	fragretrieval "magic/internal/domain/knowledge/fragment/retrieval"
+	"magic/internal/domain/knowledge/shared"
+	"magic/internal/pkg/ctxmeta"
+)
+
+// auxiliaryDroppedMetadataKeys 是源片段上只描述自身切分位置的字段，辅助片段不继承。
+var auxiliaryDroppedMetadataKeys = []string{
+	"token_count",
+	"tree_node_id",
+	"parent_node_id",
+	"section_chunk_index",
+}
+
+// BuildAuxiliaryFragments 按知识库切片配置为文档片段生成摘要/问题辅助片段，未开启或生成失败时返回空。
+func (s *FragmentDomainService) BuildAuxiliaryFragments(
+	ctx context.Context,
+	cfg *shared.AuxiliaryFragmentConfig,
+	fragments []*fragmodel.KnowledgeBaseFragment,
+	businessParams *ctxmeta.BusinessParams,
+) []*fragmodel.KnowledgeBaseFragment {
+	if s == nil || s.retrievalSvc == nil || cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	entries := s.retrievalSvc.GenerateAuxiliaryEntries(ctx, cfg, fragments, businessParams)
+	return AssembleAuxiliaryFragments(fragments, entries)
+}
+
+// AssembleAuxiliaryFragments 将生成的摘要/问题组装为辅助片段。
+//
+// 辅助片段继承首个源片段的文档与章节信息，chunk_index 排在全部源片段之后，
+// 保证源片段的 identity 与 point_id 不受影响；metadata 记录类型与源片段 point_id 供检索回溯。
+func AssembleAuxiliaryFragments(
+	sources []*fragmodel.KnowledgeBaseFragment,
+	entries []fragretrieval.AuxiliaryEntry,
+) []*fragmodel.KnowledgeBaseFragment {
+	if len(sources) == 0 || len(entries) == 0 {
+		return nil
+	}
+	nextChunkIndex := 0
+	for _, source := range sources {
+		if source != nil {
+			nextChunkIndex = max(nextChunkIndex, source.ChunkIndex+1)
+		}
+	}
+
+	fragments := make([]*fragmodel.KnowledgeBaseFragment, 0, len(entries))
+	for _, entry := range entries {
+		sourcePointIDs := make([]string, 0, len(entry.SourceIndexes))
+		var primary *fragmodel.KnowledgeBaseFragment
+		for _, index := range entry.SourceIndexes {
+			if index < 0 || index >= len(sources) || sources[index] == nil || sources[index].PointID == "" {
+				continue
+			}
+			if primary == nil {
+				primary = sources[index]
+			}
+			sourcePointIDs = append(sourcePointIDs, sources[index].PointID)
+		}
+		if primary == nil || entry.Content == "" {
+			continue
+		}
+		fragments = append(fragments, assembleAuxiliaryFragment(primary, entry, sourcePointIDs, nextChunkIndex))
+		nextChunkIndex++
+	}
+	return fragments
+}
+
+func assembleAuxiliaryFragment(
+	primary *fragmodel.KnowledgeBaseFragment,
+	entry fragretrieval.AuxiliaryEntry,
+	sourcePointIDs []string,
+	chunkIndex int,
+) *fragmodel.KnowledgeBaseFragment {
+	contentHash := hashFragmentContent(entry.Content)
+	metadata := maps.Clone(primary.Metadata)
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	for _, key := range auxiliaryDroppedMetadataKeys {
+		delete(metadata, key)
+	}
+	metadata["chunk_index"] = chunkIndex
+	metadata["content_hash"] = contentHash
+	// 辅助片段本身已是模型生成的浓缩文本，只保留标题前缀，不再额外生成片段上下文。
+	if fragretrieval.FragmentRetrievalTextVersion(primary) != fragretrieval.RetrievalTextVersionV1 {
+		metadata["retrieval_text_version"] = fragretrieval.RetrievalTextVersionContextualV1
+	}
+	metadata[fragmodel.MetadataAuxiliaryTypeKey] = entry.Type
+	metadata[fragmodel.MetadataAuxiliarySourcePointIDsKey] = sourcePointIDs
+
+	fragment := fragmodel.NewFragment(primary.KnowledgeCode, primary.DocumentCode, entry.Content, metadata, primary.UpdatedUID)
+	fragment.OrganizationCode = primary.OrganizationCode
+	fragment.DocumentName = primary.DocumentName
+	fragment.DocumentType = primary.DocumentType
+	fragment.ChunkIndex = chunkIndex
+	fragment.ContentHash = contentHash
+	fragment.SplitVersion = primary.SplitVersion
+	fragment.SectionPath = primary.SectionPath
+	fragment.SectionTitle = primary.SectionTitle
+	fragment.SectionLevel = primary.SectionLevel
+	fragment.PointID = buildPointID(primary.KnowledgeCode, primary.DocumentCode, buildFragmentChunkIdentityKey(contentHash, chunkIndex))
+	return fragment
+}
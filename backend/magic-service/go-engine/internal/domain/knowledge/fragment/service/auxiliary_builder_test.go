@@ -0,0 +1,63 @@
+package fragdomain_test
+
+import (
+	"slices"
+	"testing"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	fragretrieval "magic/internal/domain/knowledge/fragment/retrieval"
+	fragdomain "magic/internal/domain/knowledge/fragment/service"
+)
+
+func TestAssembleAuxiliaryFragmentsAppendsAfterSourcesAndLinksPointIDs(t *testing.T) {
+	t.Parallel()
+
+	sources := []*fragmodel.KnowledgeBaseFragment{
+		{
+			KnowledgeCode: "KB-1",
+			DocumentCode:  "DOC-1",
+			DocumentName:  "差旅报销制度",
+			Content:       "申请期限为 30 天",
+			ChunkIndex:    0,
+			SectionPath:   "报销 > 时限",
+			PointID:       "POINT-0",
+			Metadata:      map[string]any{"token_count": 8, "section_chunk_index": 0, "retrieval_text_version": fragretrieval.RetrievalTextVersionContextualLLMV1},
+		},
+		{KnowledgeCode: "KB-1", DocumentCode: "DOC-1", Content: "由部门负责人审批", ChunkIndex: 1, PointID: "POINT-1"},
+	}
+
+	fragments := fragdomain.AssembleAuxiliaryFragments(sources, []fragretrieval.AuxiliaryEntry{
+		{Type: fragmodel.AuxiliaryTypeSummary, Content: "差旅报销的申请时限与审批流程。", SourceIndexes: []int{0, 1}},
+		{Type: fragmodel.AuxiliaryTypeQuestion, Content: "报销需要谁审批？", SourceIndexes: []int{1}},
+		{Type: fragmodel.AuxiliaryTypeQuestion, Content: "无效的源片段", SourceIndexes: []int{5}},
+	})
+
+	if len(fragments) != 2 {
+		t.Fatalf("expected entries without valid sources to be dropped, got %#v", fragments)
+	}
+	summary := fragments[0]
+	if summary.ChunkIndex != 2 || fragments[1].ChunkIndex != 3 {
+		t.Fatalf("expected auxiliary chunk indexes after sources, got %d and %d", summary.ChunkIndex, fragments[1].ChunkIndex)
+	}
+	if !fragmodel.IsAuxiliaryFragment(summary) || fragmodel.AuxiliaryFragmentType(summary.Metadata) != fragmodel.AuxiliaryTypeSummary {
+		t.Fatalf("expected summary auxiliary metadata, got %#v", summary.Metadata)
+	}
+	if got := fragmodel.AuxiliarySourcePointIDs(summary.Metadata); !slices.Equal(got, []string{"POINT-0", "POINT-1"}) {
+		t.Fatalf("unexpected source point ids: %#v", got)
+	}
+	if _, ok := summary.Metadata["token_count"]; ok {
+		t.Fatalf("expected source-only metadata to be dropped, got %#v", summary.Metadata)
+	}
+	if summary.Metadata["retrieval_text_version"] != fragretrieval.RetrievalTextVersionContextualV1 {
+		t.Fatalf("expected auxiliary fragment to skip llm context, got %#v", summary.Metadata)
+	}
+	if summary.SectionPath != "报销 > 时限" || summary.DocumentName != "差旅报销制度" {
+		t.Fatalf("expected document and section info from primary source, got %#v", summary)
+	}
+	if summary.PointID == "" || summary.PointID == sources[0].PointID || summary.PointID == fragments[1].PointID {
+		t.Fatalf("expected distinct auxiliary point id, got %q", summary.PointID)
+	}
+	if fragments[1].SectionPath != "" || fragments[1].Metadata["retrieval_text_version"] != nil {
+		t.Fatalf("expected question to inherit from its own source, got %#v", fragments[1])
+	}
+}
@@ -1,6 +1,7 @@
 package fragdomain
 
 import (
+	"cmp"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -21,6 +22,8 @@ type DocumentFragmentAssembleInput struct {
 	Doc          *fragmodel.KnowledgeBaseDocument
 	Chunks       []TokenChunk
 	SplitVersion string
+	// RetrievalTextVersion 为空时使用 v1；开启上下文增强的知识库由调用方按配置解析。
+	RetrievalTextVersion string
 }
 
 // AssembleDocumentFragments 基于既有 chunk 组装持久化片段。
@@ -29,13 +32,15 @@ func AssembleDocumentFragments(input DocumentFragmentAssembleInput) ([]*fragmode
 	if doc == nil {
 		return nil, nil
 	}
-	return assembleDocumentFragments(doc, input.Chunks, input.SplitVersion), nil
+	retrievalTextVersion := cmp.Or(strings.TrimSpace(input.RetrievalTextVersion), fragretrieval.RetrievalTextVersionV1)
+	return assembleDocumentFragments(doc, input.Chunks, input.SplitVersion, retrievalTextVersion), nil
 }
 
 func assembleDocumentFragments(
 	doc *fragmodel.KnowledgeBaseDocument,
 	chunks []TokenChunk,
 	splitVersion string,
+	retrievalTextVersion string,
 ) []*fragmodel.KnowledgeBaseFragment {
 	createdAtUnix := time.Now().Unix()
 	fragments := make([]*fragmodel.KnowledgeBaseFragment, 0, len(chunks))
@@ -60,7 +65,7 @@ func assembleDocumentFragments(
 			ChunkIndex:           index,
 			ContentHash:          contentHash,
 			SplitVersion:         splitVersion,
-			RetrievalTextVersion: fragretrieval.RetrievalTextVersionV1,
+			RetrievalTextVersion: retrievalTextVersion,
 			SectionPath:          chunk.SectionPath,
 			SectionTitle:         chunk.SectionTitle,
 			SectionLevel:         chunk.SectionLevel,
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	fragretrieval "magic/internal/domain/knowledge/fragment/retrieval"
 	fragdomain "magic/internal/domain/knowledge/fragment/service"
 	sharedsnapshot "magic/internal/domain/knowledge/shared/snapshot"
 )
@@ -187,3 +188,39 @@ func TestAssembleDocumentFragmentsBuildsStableThirdPlatformFileKey(t *testing.T)
 		t.Fatalf("expected stable third-platform file_key, got %#v", fragments)
 	}
 }
+
+func TestAssembleDocumentFragmentsStampsRetrievalTextVersion(t *testing.T) {
+	t.Parallel()
+
+	doc := &fragmodel.KnowledgeBaseDocument{
+		KnowledgeDocumentSnapshot: sharedsnapshot.KnowledgeDocumentSnapshot{
+			KnowledgeBaseCode: "KB-1",
+			Name:              "差旅报销制度.md",
+			Code:              "DOC-1",
+		},
+	}
+	chunks := []fragdomain.TokenChunk{{Content: "申请期限为 30 天", TokenCount: 6}}
+
+	defaulted, err := fragdomain.AssembleDocumentFragments(fragdomain.DocumentFragmentAssembleInput{Doc: doc, Chunks: chunks})
+	if err != nil {
+		t.Fatalf("AssembleDocumentFragments returned error: %v", err)
+	}
+	if got := defaulted[0].Metadata["retrieval_text_version"]; got != fragretrieval.RetrievalTextVersionV1 {
+		t.Fatalf("expected default retrieval text version v1, got %#v", got)
+	}
+
+	contextual, err := fragdomain.AssembleDocumentFragments(fragdomain.DocumentFragmentAssembleInput{
+		Doc:                  doc,
+		Chunks:               chunks,
+		RetrievalTextVersion: fragretrieval.RetrievalTextVersionContextualV1,
+	})
+	if err != nil {
+		t.Fatalf("AssembleDocumentFragments returned error: %v", err)
+	}
+	if got := contextual[0].Metadata["retrieval_text_version"]; got != fragretrieval.RetrievalTextVersionContextualV1 {
+		t.Fatalf("expected contextual retrieval text version, got %#v", got)
+	}
+	if contextual[0].Content != "申请期限为 30 天" {
+		t.Fatalf("expected original content, got %q", contextual[0].Content)
+	}
+}
@@ -43,6 +43,21 @@ type FragmentDomainInfra struct {
 	SyncMemorySoftLimit   int64
 	MemoryReader          memoryguard.Reader
 	MemoryPollInterval    time.Duration
+	Reranker              fragretrieval.Reranker
+	RerankOptions         fragretrieval.RerankOptions
+	ChatCompleter         fragretrieval.ChatCompleter
+	QueryExpansionOptions fragretrieval.QueryExpansionOptions
+	// LateInteractionEncoder 与 LateInteractionOptions 控制可选的多向量写入与 MaxSim 重打分。
+	LateInteractionEncoder fragretrieval.LateInteractionEncoder
+	LateInteractionOptions fragretrieval.LateInteractionOptions
+	// FeedbackReader 与 FeedbackBoostOptions 控制可选的相关性反馈加权排序。
+	FeedbackReader       fragretrieval.FeedbackAggregateReader
+	FeedbackBoostOptions fragretrieval.FeedbackBoostOptions
+	// ChunkContextCache 与 ContextualEnrichmentOptions 控制入库时可选的模型生成片段上下文。
+	ChunkContextCache           fragretrieval.ChunkContextCache
+	ContextualEnrichmentOptions fragretrieval.ContextualEnrichmentOptions
+	// AuxiliaryFragmentOptions 控制入库时生成摘要/问题辅助片段的模型调用。
+	AuxiliaryFragmentOptions fragretrieval.AuxiliaryFragmentOptions
 }
 
 type fragmentCountStatsRepository interface {
@@ -77,6 +92,7 @@ var (
 	errThirdFileBackfillUnsupported              = errors.New("repository does not support third-file backfill")
 	errFragmentPointFilterScanUnsupported        = errors.New("vector repository does not support filter scan")
 	errFragmentDocumentSeekPaginationUnsupported = errors.New("repository does not support document seek pagination")
+	errFragmentContextWindowUnsupported          = errors.New("repository does not support fragment context window")
 )
 
 // NewFragmentDomainService 创建片段领域服务
@@ -98,11 +114,22 @@ func NewFragmentDomainService(
 		memoryPollInterval:    normalizeFragmentBatchMemoryPollInterval(infra.MemoryPollInterval),
 	}
 	service.retrievalSvc = fragretrieval.NewService(service.repo, service.embeddingSvc, fragretrieval.Infra{
-		VectorDataRepo:        service.vectorDataRepo,
-		MetaReader:            infra.MetaReader,
-		DefaultEmbeddingModel: service.defaultEmbeddingModel,
-		Logger:                service.logger,
-		SegmenterProvider:     infra.SegmenterProvider,
+		VectorDataRepo:              service.vectorDataRepo,
+		MetaReader:                  infra.MetaReader,
+		DefaultEmbeddingModel:       service.defaultEmbeddingModel,
+		Logger:                      service.logger,
+		SegmenterProvider:           infra.SegmenterProvider,
+		Reranker:                    infra.Reranker,
+		RerankOptions:               infra.RerankOptions,
+		ChatCompleter:               infra.ChatCompleter,
+		QueryExpansionOptions:       infra.QueryExpansionOptions,
+		LateInteractionEncoder:      infra.LateInteractionEncoder,
+		LateInteractionOptions:      infra.LateInteractionOptions,
+		FeedbackReader:              infra.FeedbackReader,
+		FeedbackBoostOptions:        infra.FeedbackBoostOptions,
+		ChunkContextCache:           infra.ChunkContextCache,
+		ContextualEnrichmentOptions: infra.ContextualEnrichmentOptions,
+		AuxiliaryFragmentOptions:    infra.AuxiliaryFragmentOptions,
 	})
 	return service
 }
@@ -290,6 +317,22 @@ func (s *FragmentDomainService) ListByDocumentAfterID(
 	return fragments, nil
 }
 
+// ListContextWindow 查询命中片段所在章节或相邻区间的片段，用于检索结果上下文扩展。
+func (s *FragmentDomainService) ListContextWindow(
+	ctx context.Context,
+	query fragmodel.ContextWindowQuery,
+) ([]*fragmodel.KnowledgeBaseFragment, error) {
+	reader, ok := s.repo.(fragmodel.KnowledgeBaseFragmentContextReader)
+	if !ok {
+		return nil, fmt.Errorf("failed to list fragment context window: %w", errFragmentContextWindowUnsupported)
+	}
+	fragments, err := reader.ListContextWindow(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fragment context window: %w", err)
+	}
+	return fragments, nil
+}
+
 // DeleteByDocument 根据知识库和文档删除所有片段。
 func (s *FragmentDomainService) DeleteByDocument(ctx context.Context, knowledgeCode, documentCode string) error {
 	if err := s.repo.DeleteByDocument(ctx, knowledgeCode, documentCode); err != nil {
@@ -9,6 +9,7 @@ import (
 	fragmetadata "magic/internal/domain/knowledge/fragment/metadata"
 	fragmodel "magic/internal/domain/knowledge/fragment/model"
 	fragretrieval "magic/internal/domain/knowledge/fragment/retrieval"
+	"magic/internal/domain/knowledge/shared"
 	sharedsnapshot "magic/internal/domain/knowledge/shared/snapshot"
 	"magic/internal/pkg/ctxmeta"
 	"magic/internal/pkg/memoryguard"
@@ -45,6 +46,8 @@ type fragmentBatchSyncRuntime struct {
 	model          string
 	sparseBackend  string
 	businessParams *ctxmeta.BusinessParams
+	graphEnabled   bool
+	kb             *sharedsnapshot.KnowledgeBaseRuntimeSnapshot
 }
 
 // SyncFragment 同步片段到向量库（核心逻辑）
@@ -65,7 +68,7 @@ func (s *FragmentDomainService) SyncFragment(
 	}
 
 	if len(fragment.Vector) == 0 {
-		retrievalText := s.retrievalSvc.BuildRetrievalTextFromFragment(fragment)
+		retrievalText := s.retrievalSvc.BuildEmbeddingTexts(ctx, kb, []*fragmodel.KnowledgeBaseFragment{fragment}, businessParams)[0]
 		embedding, err := s.embeddingSvc.GetEmbedding(ctx, retrievalText, model, businessParams)
 		if err != nil {
 			fragment.MarkSyncFailed(err.Error())
@@ -74,7 +77,7 @@ func (s *FragmentDomainService) SyncFragment(
 		}
 		fragment.SetVector(embedding)
 		if fragment.Metadata != nil {
-			fragment.Metadata["retrieval_text_version"] = fragretrieval.RetrievalTextVersionV1
+			fragment.Metadata["retrieval_text_version"] = fragretrieval.FragmentRetrievalTextVersion(fragment)
 		}
 
 		if err := s.repo.UpdateVector(ctx, fragment.ID, embedding); err != nil {
@@ -82,13 +85,18 @@ func (s *FragmentDomainService) SyncFragment(
 		}
 	}
 
-	payload := fragmetadata.BuildFragmentPayload(fragment)
+	payload := buildFragmentStorePayload(fragment, fragretrieval.GraphRetrievalEnabled(kb))
 	sparseInput := s.retrievalSvc.BuildSparseInputFromFragment(fragment, resolvedRoute.SparseBackend)
 	if err := s.vectorDataRepo.StoreHybridPoint(ctx, collectionName, fragment.PointID, fragment.Vector, sparseInput, *payload); err != nil {
 		fragment.MarkSyncFailed(err.Error())
 		_ = s.repo.UpdateSyncStatus(ctx, fragment)
 		return fmt.Errorf("failed to store point: %w", err)
 	}
+	if err := s.storeLateInteractionVectors(ctx, collectionName, []*fragmodel.KnowledgeBaseFragment{fragment}, businessParams); err != nil {
+		fragment.MarkSyncFailed(err.Error())
+		_ = s.repo.UpdateSyncStatus(ctx, fragment)
+		return err
+	}
 
 	fragment.MarkSynced()
 	if err := s.repo.UpdateSyncStatus(ctx, fragment); err != nil {
@@ -136,6 +144,8 @@ func (s *FragmentDomainService) SyncFragmentBatch(
 		model:          model,
 		sparseBackend:  resolvedRoute.SparseBackend,
 		businessParams: businessParams,
+		graphEnabled:   fragretrieval.GraphRetrievalEnabled(kb),
+		kb:             kb,
 	}
 	for batchStart, batchIndex := 0, 1; batchStart < len(fragments); batchStart, batchIndex = batchStart+batchSize, batchIndex+1 {
 		batchEnd := min(batchStart+batchSize, len(fragments))
@@ -184,38 +194,39 @@ func (s *FragmentDomainService) syncFragmentBatchChunk(
 	return nil
 }
 
-func (s *FragmentDomainService) populateBatchEmbeddings(ctx context.Context, model string, fragments []*fragmodel.KnowledgeBaseFragment, businessParams *ctxmeta.BusinessParams) (int, error) {
-	textsToEmbed, fragmentsNeedingEmbedding := s.collectEmbeddingTargets(fragments)
-	if len(textsToEmbed) == 0 {
+func (s *FragmentDomainService) populateBatchEmbeddings(
+	ctx context.Context,
+	runtime fragmentBatchSyncRuntime,
+	fragments []*fragmodel.KnowledgeBaseFragment,
+) (int, error) {
+	fragmentsNeedingEmbedding := collectEmbeddingTargets(fragments)
+	if len(fragmentsNeedingEmbedding) == 0 {
 		return 0, nil
 	}
 
-	embeddings, err := s.embeddingSvc.GetEmbeddings(ctx, textsToEmbed, model, businessParams)
+	textsToEmbed := s.retrievalSvc.BuildEmbeddingTexts(ctx, runtime.kb, fragmentsNeedingEmbedding, runtime.businessParams)
+	embeddings, err := s.embeddingSvc.GetEmbeddings(ctx, textsToEmbed, runtime.model, runtime.businessParams)
 	if err != nil {
 		return 0, fmt.Errorf("failed to compute batch embeddings: %w", err)
 	}
 	for i, fragment := range fragmentsNeedingEmbedding {
 		fragment.SetVector(embeddings[i])
 		if fragment.Metadata != nil {
-			fragment.Metadata["retrieval_text_version"] = fragretrieval.RetrievalTextVersionV1
+			fragment.Metadata["retrieval_text_version"] = fragretrieval.FragmentRetrievalTextVersion(fragment)
 		}
 	}
 	return len(textsToEmbed), nil
 }
 
-func (s *FragmentDomainService) collectEmbeddingTargets(
-	fragments []*fragmodel.KnowledgeBaseFragment,
-) ([]string, []*fragmodel.KnowledgeBaseFragment) {
-	textsToEmbed := make([]string, 0, len(fragments))
+func collectEmbeddingTargets(fragments []*fragmodel.KnowledgeBaseFragment) []*fragmodel.KnowledgeBaseFragment {
 	fragmentsNeedingEmbedding := make([]*fragmodel.KnowledgeBaseFragment, 0, len(fragments))
 	for _, fragment := range fragments {
 		if len(fragment.Vector) != 0 {
 			continue
 		}
-		textsToEmbed = append(textsToEmbed, s.retrievalSvc.BuildRetrievalTextFromFragment(fragment))
 		fragmentsNeedingEmbedding = append(fragmentsNeedingEmbedding, fragment)
 	}
-	return textsToEmbed, fragmentsNeedingEmbedding
+	return fragmentsNeedingEmbedding
 }
 
 func (s *FragmentDomainService) markFragmentsSyncingWithTrace(
@@ -254,7 +265,7 @@ func (s *FragmentDomainService) populateBatchEmbeddingsWithTrace(
 		)...,
 	)
 	startedAt := time.Now()
-	embeddedCount, err := s.populateBatchEmbeddings(ctx, runtime.model, fragments, runtime.businessParams)
+	embeddedCount, err := s.populateBatchEmbeddings(ctx, runtime, fragments)
 	trace.log(
 		ctx,
 		"populate_batch_embeddings",
@@ -284,7 +295,7 @@ func (s *FragmentDomainService) buildBatchStorePayloadsWithTrace(
 		)...,
 	)
 	startedAt := time.Now()
-	batch := s.buildBatchStorePayloads(fragments, runtime.sparseBackend)
+	batch := s.buildBatchStorePayloads(fragments, runtime.sparseBackend, runtime.graphEnabled)
 	trace.log(
 		ctx,
 		"build_batch_store_payloads",
@@ -315,6 +326,9 @@ func (s *FragmentDomainService) storeBatchPointsWithTrace(
 	)
 	startedAt := time.Now()
 	err := s.storeBatchPoints(ctx, runtime.model, fragments, runtime.collectionName, batch)
+	if err == nil {
+		err = s.storeLateInteractionVectors(ctx, runtime.collectionName, fragments, runtime.businessParams)
+	}
 	trace.log(
 		ctx,
 		"store_batch_points",
@@ -348,7 +362,11 @@ func (s *FragmentDomainService) markFragmentsSyncedWithTrace(
 	)
 }
 
-func (s *FragmentDomainService) buildBatchStorePayloads(fragments []*fragmodel.KnowledgeBaseFragment, sparseBackend string) batchVectorWrite {
+func (s *FragmentDomainService) buildBatchStorePayloads(
+	fragments []*fragmodel.KnowledgeBaseFragment,
+	sparseBackend string,
+	graphEnabled bool,
+) batchVectorWrite {
 	pointIDs := make([]string, len(fragments))
 	denseVectors := make([][]float64, len(fragments))
 	sparseInputs := make([]*fragmodel.SparseInput, len(fragments))
@@ -357,7 +375,7 @@ func (s *FragmentDomainService) buildBatchStorePayloads(fragments []*fragmodel.K
 		pointIDs[i] = fragment.PointID
 		denseVectors[i] = fragment.Vector
 		sparseInputs[i] = s.retrievalSvc.BuildSparseInputFromFragment(fragment, sparseBackend)
-		payloads[i] = *fragmetadata.BuildFragmentPayload(fragment)
+		payloads[i] = *buildFragmentStorePayload(fragment, graphEnabled)
 	}
 	return batchVectorWrite{
 		pointIDs:     pointIDs,
@@ -367,6 +385,15 @@ func (s *FragmentDomainService) buildBatchStorePayloads(fragments []*fragmodel.K
 	}
 }
 
+// buildFragmentStorePayload 构建向量点 payload；开启图召回的知识库额外写入实体关系。
+func buildFragmentStorePayload(fragment *fragmodel.KnowledgeBaseFragment, graphEnabled bool) *fragmodel.FragmentPayload {
+	payload := fragmetadata.BuildFragmentPayload(fragment)
+	if graphEnabled {
+		fragretrieval.AttachFragmentGraph(payload, fragretrieval.BuildFragmentGraph(fragment))
+	}
+	return payload
+}
+
 func normalizeSyncFragmentBatchSize(size int) int {
 	if size <= 0 {
 		return defaultSyncFragmentBatchSize
@@ -645,6 +672,24 @@ func (s *FragmentDomainService) storeBatchPoints(
 	return nil
 }
 
+// storeLateInteractionVectors 在 dense / sparse 点写入后补写多向量；集合尚未重建出多向量字段时跳过，等待 rebuild 切换。
+func (s *FragmentDomainService) storeLateInteractionVectors(
+	ctx context.Context,
+	collectionName string,
+	fragments []*fragmodel.KnowledgeBaseFragment,
+	businessParams *ctxmeta.BusinessParams,
+) error {
+	err := s.retrievalSvc.StoreFragmentMultiVectors(ctx, collectionName, fragments, businessParams)
+	if errors.Is(err, shared.ErrMultiVectorNotConfigured) {
+		s.logger.DebugContext(ctx, "Skipped late interaction vectors because collection has no multi-vector field", "collection_name", collectionName)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store late interaction vectors: %w", err)
+	}
+	return nil
+}
+
 func (s *FragmentDomainService) logBatchDimensionMismatch(ctx context.Context, model, collectionName string, fragments []*fragmodel.KnowledgeBaseFragment, err error) {
 	var dimErr *fragmodel.VectorDimensionMismatchError
 	if !errors.As(err, &dimErr) {
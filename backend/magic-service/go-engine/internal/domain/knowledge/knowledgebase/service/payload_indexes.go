@@ -14,6 +14,7 @@ func ExpectedPayloadIndexSpecs() []shared.PayloadIndexSpec {
 		{FieldName: "section_title", Kind: shared.PayloadIndexKindKeyword},
 		{FieldName: "business_id", Kind: shared.PayloadIndexKindKeyword},
 		{FieldName: "metadata.tags", Kind: shared.PayloadIndexKindKeyword},
+		{FieldName: "metadata.graph_entities", Kind: shared.PayloadIndexKindKeyword},
 		{FieldName: "document_type", Kind: shared.PayloadIndexKindInteger},
 		{FieldName: "metadata.section_level", Kind: shared.PayloadIndexKindInteger},
 		{FieldName: "metadata.created_at_ts", Kind: shared.PayloadIndexKindInteger},
@@ -0,0 +1,138 @@
+// Package entity 定义知识库检索 query 日志与用量统计的领域模型。
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// 检索入口来源，与 FragmentAppService 的公开检索方法一一对应。
+const (
+	SourceSimilarity        = "similarity"
+	SourceSimilarityByAgent = "similarity_by_agent"
+	SourceRuntimeSimilarity = "runtime_similarity"
+)
+
+const (
+	// MaxQueryRunes 是落库 query 文本的最大字符数，超出部分截断。
+	MaxQueryRunes = 500
+	// DefaultAnalyticsWindow 是未指定时间范围时的统计窗口。
+	DefaultAnalyticsWindow = 7 * 24 * time.Hour
+	// MaxAnalyticsWindow 是单次统计允许的最大时间跨度。
+	MaxAnalyticsWindow = 90 * 24 * time.Hour
+	// DefaultAnalyticsLimit 是统计结果默认条数。
+	DefaultAnalyticsLimit = 20
+	// MaxAnalyticsLimit 是统计结果最大条数。
+	MaxAnalyticsLimit = 100
+)
+
+var (
+	// ErrInvalidAnalyticsWindow 表示统计时间范围非法。
+	ErrInvalidAnalyticsWindow = errors.New("invalid query log analytics window")
+	// ErrAnalyticsWindowTooLarge 表示统计时间跨度超过上限。
+	ErrAnalyticsWindowTooLarge = errors.New("query log analytics window is too large")
+)
+
+// Hit 表示一次检索返回的一条结果。
+type Hit struct {
+	KnowledgeBaseCode string
+	DocumentCode      string
+	FragmentID        int64
+	Score             float64
+}
+
+// Search 表示一次检索调用，是日志记录器的输入。
+type Search struct {
+	OrganizationCode   string
+	UserID             string
+	Source             string
+	AgentCode          string
+	Query              string
+	KnowledgeBaseCodes []string
+	Hits               []Hit
+	Latency            time.Duration
+	StartedAt          time.Time
+}
+
+// QueryLog 表示落库的一条 query 日志。
+//
+// 一次检索覆盖多个知识库时按知识库拆成多条，RequestID 相同，便于按知识库统计。
+type QueryLog struct {
+	ID                int64
+	RequestID         string
+	OrganizationCode  string
+	KnowledgeBaseCode string
+	UserID            string
+	Source            string
+	AgentCode         string
+	Query             string
+	QueryHash         string
+	QueryType         string
+	ResultCount       int
+	TopScore          float64
+	LatencyMS         int64
+	Hits              []Hit
+	CreatedAt         time.Time
+}
+
+// QueryStat 表示按 query 聚合的检索次数统计。
+type QueryStat struct {
+	QueryHash       string    `json:"query_hash"`
+	Query           string    `json:"query"`
+	SearchCount     int64     `json:"search_count"`
+	ZeroResultCount int64     `json:"zero_result_count"`
+	AvgLatencyMS    float64   `json:"avg_latency_ms"`
+	LastSearchedAt  time.Time `json:"last_searched_at"`
+}
+
+// SlowQuery 表示一条慢检索记录。
+type SlowQuery struct {
+	ID          int64     `json:"id"`
+	Query       string    `json:"query"`
+	QueryType   string    `json:"query_type"`
+	Source      string    `json:"source"`
+	ResultCount int       `json:"result_count"`
+	LatencyMS   int64     `json:"latency_ms"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DocumentUsage 表示文档被检索命中的统计。
+type DocumentUsage struct {
+	DocumentCode    string    `json:"document_code"`
+	HitCount        int64     `json:"hit_count"`
+	QueryCount      int64     `json:"query_count"`
+	AvgScore        float64   `json:"avg_score"`
+	LastRetrievedAt time.Time `json:"last_retrieved_at"`
+}
+
+// UnusedDocument 表示统计窗口内从未被检索命中的文档。
+type UnusedDocument struct {
+	DocumentCode string    `json:"document_code"`
+	DocumentName string    `json:"document_name"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ResolveAnalyticsWindow 规范化统计时间范围：缺省截止时间为 now，缺省起点为截止前 7 天。
+func ResolveAnalyticsWindow(since, until, now time.Time) (time.Time, time.Time, error) {
+	if until.IsZero() {
+		until = now
+	}
+	if since.IsZero() {
+		since = until.Add(-DefaultAnalyticsWindow)
+	}
+	if !since.Before(until) {
+		return time.Time{}, time.Time{}, ErrInvalidAnalyticsWindow
+	}
+	if until.Sub(since) > MaxAnalyticsWindow {
+		return time.Time{}, time.Time{}, ErrAnalyticsWindowTooLarge
+	}
+	return since, until, nil
+}
+
+// NormalizeAnalyticsLimit 将统计条数限制在 [1, MaxAnalyticsLimit]，非正数回落到默认值。
+func NormalizeAnalyticsLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultAnalyticsLimit
+	}
+	return min(limit, MaxAnalyticsLimit)
+}
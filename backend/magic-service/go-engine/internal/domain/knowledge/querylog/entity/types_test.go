@@ -0,0 +1,46 @@
+package entity_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
+)
+
+func TestResolveAnalyticsWindowDefaultsToLastWeek(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 6, 8, 0, 0, 0, 0, time.UTC)
+	since, until, err := querylogentity.ResolveAnalyticsWindow(time.Time{}, time.Time{}, now)
+	if err != nil {
+		t.Fatalf("ResolveAnalyticsWindow() error = %v", err)
+	}
+	if !until.Equal(now) || !since.Equal(now.Add(-querylogentity.DefaultAnalyticsWindow)) {
+		t.Fatalf("unexpected window: %s - %s", since, until)
+	}
+}
+
+func TestResolveAnalyticsWindowRejectsInvalidRange(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 6, 8, 0, 0, 0, 0, time.UTC)
+	if _, _, err := querylogentity.ResolveAnalyticsWindow(now, now, now); !errors.Is(err, querylogentity.ErrInvalidAnalyticsWindow) {
+		t.Fatalf("expected invalid window, got %v", err)
+	}
+	tooEarly := now.Add(-querylogentity.MaxAnalyticsWindow - time.Hour)
+	if _, _, err := querylogentity.ResolveAnalyticsWindow(tooEarly, now, now); !errors.Is(err, querylogentity.ErrAnalyticsWindowTooLarge) {
+		t.Fatalf("expected window too large, got %v", err)
+	}
+}
+
+func TestNormalizeAnalyticsLimit(t *testing.T) {
+	t.Parallel()
+
+	cases := map[int]int{0: querylogentity.DefaultAnalyticsLimit, -1: querylogentity.DefaultAnalyticsLimit, 5: 5, 1000: querylogentity.MaxAnalyticsLimit}
+	for input, want := range cases {
+		if got := querylogentity.NormalizeAnalyticsLimit(input); got != want {
+			t.Fatalf("NormalizeAnalyticsLimit(%d) = %d, want %d", input, got, want)
+		}
+	}
+}
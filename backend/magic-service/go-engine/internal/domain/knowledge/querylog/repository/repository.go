@@ -0,0 +1,40 @@
+// Package repository 定义检索 query 日志领域的持久化端口。
+package repository
+
+import (
+	"context"
+	"time"
+
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
+)
+
+// AnalyticsQuery 表示一个知识库在时间窗口内的统计条件。
+type AnalyticsQuery struct {
+	OrganizationCode  string
+	KnowledgeBaseCode string
+	Since             time.Time
+	Until             time.Time
+	Limit             int32
+}
+
+// Store 定义 query 日志写入能力。
+type Store interface {
+	// InsertQueryLogs 在同一事务内写入日志及其命中明细。
+	InsertQueryLogs(ctx context.Context, logs []querylogentity.QueryLog) error
+}
+
+// Analytics 定义基于 query 日志的统计查询能力。
+type Analytics interface {
+	TopQueries(ctx context.Context, query AnalyticsQuery) ([]querylogentity.QueryStat, error)
+	ZeroResultQueries(ctx context.Context, query AnalyticsQuery) ([]querylogentity.QueryStat, error)
+	SlowestQueries(ctx context.Context, query AnalyticsQuery) ([]querylogentity.SlowQuery, error)
+	MostRetrievedDocuments(ctx context.Context, query AnalyticsQuery) ([]querylogentity.DocumentUsage, error)
+	// NeverRetrievedDocuments 返回窗口内未被任何检索命中的启用文档。
+	NeverRetrievedDocuments(ctx context.Context, query AnalyticsQuery) ([]querylogentity.UnusedDocument, error)
+}
+
+// Repository 聚合 query 日志所需的全部持久化能力。
+type Repository interface {
+	Store
+	Analytics
+}
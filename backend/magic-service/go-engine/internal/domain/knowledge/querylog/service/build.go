@@ -0,0 +1,75 @@
+package service
+
+import (
+	"slices"
+
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
+)
+
+// BuildQueryLogs 把一次检索按知识库拆成多条日志。
+//
+// query 已脱敏，queryType 由调用方基于原始 query 计算；命中结果按知识库归属分配，
+// 不属于任何已知知识库的命中归入第一个知识库。
+func BuildQueryLogs(search querylogentity.Search, requestID, queryType string) []querylogentity.QueryLog {
+	kbCodes := uniqueNonEmpty(search.KnowledgeBaseCodes)
+	for _, hit := range search.Hits {
+		if hit.KnowledgeBaseCode != "" && !slices.Contains(kbCodes, hit.KnowledgeBaseCode) {
+			kbCodes = append(kbCodes, hit.KnowledgeBaseCode)
+		}
+	}
+	if len(kbCodes) == 0 {
+		return nil
+	}
+
+	query := ScrubQuery(search.Query)
+	queryHash := HashQuery(query)
+	latencyMS := search.Latency.Milliseconds()
+	logs := make([]querylogentity.QueryLog, 0, len(kbCodes))
+	hitsByKB := make(map[string][]querylogentity.Hit, len(kbCodes))
+	for _, hit := range search.Hits {
+		kbCode := hit.KnowledgeBaseCode
+		if kbCode == "" {
+			kbCode = kbCodes[0]
+			hit.KnowledgeBaseCode = kbCode
+		}
+		hitsByKB[kbCode] = append(hitsByKB[kbCode], hit)
+	}
+	for _, kbCode := range kbCodes {
+		hits := hitsByKB[kbCode]
+		logs = append(logs, querylogentity.QueryLog{
+			RequestID:         requestID,
+			OrganizationCode:  search.OrganizationCode,
+			KnowledgeBaseCode: kbCode,
+			UserID:            search.UserID,
+			Source:            search.Source,
+			AgentCode:         search.AgentCode,
+			Query:             query,
+			QueryHash:         queryHash,
+			QueryType:         queryType,
+			ResultCount:       len(hits),
+			TopScore:          topScore(hits),
+			LatencyMS:         latencyMS,
+			Hits:              hits,
+			CreatedAt:         search.StartedAt,
+		})
+	}
+	return logs
+}
+
+func uniqueNonEmpty(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" && !slices.Contains(result, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func topScore(hits []querylogentity.Hit) float64 {
+	best := 0.0
+	for _, hit := range hits {
+		best = max(best, hit.Score)
+	}
+	return best
+}
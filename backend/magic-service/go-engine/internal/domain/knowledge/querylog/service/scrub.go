@@ -0,0 +1,73 @@
+// Package service 提供检索 query 日志的脱敏与组装规则。
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
+)
+
+const phoneReplacement = "[PHONE]"
+
+// piiPatterns 按顺序替换，身份证、银行卡需先于手机号匹配，避免长数字被部分替换。
+var piiPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`), "[EMAIL]"},
+	{regexp.MustCompile(`\b\d{17}[\dXx]\b`), "[ID]"},
+	{regexp.MustCompile(`\b\d{16,19}\b`), "[CARD]"},
+	{regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), "[IP]"},
+}
+
+// phonePattern 匹配可带 86 前缀的手机号。RE2 不支持环视，\b 又会漏掉 "8613812345678"，
+// 因此前一个字符用 (^|\D) 消费匹配，后一个字符由 scrubPhones 手动判断不是数字。
+var phonePattern = regexp.MustCompile(`(^|\D)((?:\+?86[\s\-]?)?1[3-9]\d{9})`)
+
+// ScrubQuery 脱敏 query 中的邮箱、身份证、银行卡、IP 与手机号，并截断到 MaxQueryRunes。
+func ScrubQuery(query string) string {
+	scrubbed := strings.TrimSpace(query)
+	for _, item := range piiPatterns {
+		scrubbed = item.pattern.ReplaceAllString(scrubbed, item.replacement)
+	}
+	scrubbed = scrubPhones(scrubbed)
+	runes := []rune(scrubbed)
+	if len(runes) > querylogentity.MaxQueryRunes {
+		scrubbed = string(runes[:querylogentity.MaxQueryRunes])
+	}
+	return scrubbed
+}
+
+func scrubPhones(query string) string {
+	matches := phonePattern.FindAllStringSubmatchIndex(query, -1)
+	if len(matches) == 0 {
+		return query
+	}
+	var builder strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[4], match[5]
+		if end < len(query) && isASCIIDigit(query[end]) {
+			continue
+		}
+		builder.WriteString(query[last:start])
+		builder.WriteString(phoneReplacement)
+		last = end
+	}
+	builder.WriteString(query[last:])
+	return builder.String()
+}
+
+func isASCIIDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// HashQuery 返回归一化 query 的 sha256，用于聚合大小写、空白不同的同一 query。
+func HashQuery(query string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
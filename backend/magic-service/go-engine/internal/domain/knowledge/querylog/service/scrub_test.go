@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
+	querylogservice "magic/internal/domain/knowledge/querylog/service"
+)
+
+func TestScrubQueryMasksPII(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"联系 alice.w@example.com 重置密码":      "联系 [EMAIL] 重置密码",
+		"手机13812345678无法登录":                "手机[PHONE]无法登录",
+		"+86 13812345678 的订单":              "[PHONE] 的订单",
+		"回拨 8613812345678 未接通":             "回拨 [PHONE] 未接通",
+		"13812345678,13912345678":          "[PHONE],[PHONE]",
+		"订单号 138123456789":                 "订单号 138123456789",
+		"身份证 11010519491231002X 校验失败":      "身份证 [ID] 校验失败",
+		"卡号 6222021234567890123 扣款":        "卡号 [CARD] 扣款",
+		"服务器 10.0.12.8 连接超时":               "服务器 [IP] 连接超时",
+		"error code 500 on /api/v2/search": "error code 500 on /api/v2/search",
+	}
+	for input, want := range cases {
+		if got := querylogservice.ScrubQuery(input); got != want {
+			t.Fatalf("ScrubQuery(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestScrubQueryTruncatesByRune(t *testing.T) {
+	t.Parallel()
+
+	got := querylogservice.ScrubQuery(strings.Repeat("检索", querylogentity.MaxQueryRunes))
+	if len([]rune(got)) != querylogentity.MaxQueryRunes {
+		t.Fatalf("expected %d runes, got %d", querylogentity.MaxQueryRunes, len([]rune(got)))
+	}
+}
+
+func TestHashQueryIgnoresCaseAndWhitespace(t *testing.T) {
+	t.Parallel()
+
+	if querylogservice.HashQuery("Reset  Password") != querylogservice.HashQuery(" reset password ") {
+		t.Fatal("expected normalized queries to share a hash")
+	}
+	if querylogservice.HashQuery("reset password") == querylogservice.HashQuery("reset passwords") {
+		t.Fatal("expected different queries to differ")
+	}
+}
+
+func TestBuildQueryLogsSplitsByKnowledgeBase(t *testing.T) {
+	t.Parallel()
+
+	startedAt := time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC)
+	logs := querylogservice.BuildQueryLogs(querylogentity.Search{
+		OrganizationCode:   "ORG1",
+		UserID:             "u1",
+		Source:             querylogentity.SourceRuntimeSimilarity,
+		Query:              "mail bob@example.com",
+		KnowledgeBaseCodes: []string{"KB1", "KB2", "KB1", ""},
+		Hits: []querylogentity.Hit{
+			{KnowledgeBaseCode: "KB1", DocumentCode: "DOC1", FragmentID: 1, Score: 0.4},
+			{KnowledgeBaseCode: "KB1", DocumentCode: "DOC2", FragmentID: 2, Score: 0.9},
+			{DocumentCode: "DOC3", FragmentID: 3, Score: 0.5},
+		},
+		Latency:   1500 * time.Millisecond,
+		StartedAt: startedAt,
+	}, "req-1", "natural_language")
+
+	if len(logs) != 2 {
+		t.Fatalf("expected 2 logs, got %#v", logs)
+	}
+	first, second := logs[0], logs[1]
+	if first.KnowledgeBaseCode != "KB1" || first.ResultCount != 3 || first.TopScore != 0.9 {
+		t.Fatalf("unexpected KB1 log: %#v", first)
+	}
+	if second.KnowledgeBaseCode != "KB2" || second.ResultCount != 0 || second.TopScore != 0 {
+		t.Fatalf("unexpected KB2 log: %#v", second)
+	}
+	if first.Query != "mail [EMAIL]" || first.QueryHash != second.QueryHash || first.RequestID != "req-1" {
+		t.Fatalf("unexpected query fields: %#v", first)
+	}
+	if first.LatencyMS != 1500 || !first.CreatedAt.Equal(startedAt) || first.QueryType != "natural_language" {
+		t.Fatalf("unexpected metadata: %#v", first)
+	}
+	if first.Hits[2].KnowledgeBaseCode != "KB1" {
+		t.Fatalf("expected orphan hit assigned to first kb: %#v", first.Hits[2])
+	}
+}
+
+func TestBuildQueryLogsSkipsSearchWithoutKnowledgeBase(t *testing.T) {
+	t.Parallel()
+
+	if logs := querylogservice.BuildQueryLogs(querylogentity.Search{Query: "q"}, "req", ""); logs != nil {
+		t.Fatalf("expected nil logs, got %#v", logs)
+	}
+}
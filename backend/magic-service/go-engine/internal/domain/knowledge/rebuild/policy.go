@@ -48,6 +48,8 @@ type ActiveCollectionState struct {
 	Bootstrap          bool
 	SchemaOK           bool
 	NeedsNormalization bool
+	// StorageOutdated 表示活动集合的存储参数已偏离当前配置，只能通过蓝绿重建生效
+	StorageOutdated bool
 }
 
 // ResyncSummary 描述重同步结果摘要。
@@ -165,6 +167,7 @@ func ResolveActiveCollectionState(
 			state.Dimension = info.VectorSize
 		}
 		state.SchemaOK = info.HasNamedDenseVector && info.HasSparseVector
+		state.StorageOutdated = info.StorageConfigMismatch
 	} else if state.Bootstrap {
 		state.SchemaOK = true
 	}
@@ -225,7 +228,8 @@ func BuildReusableTargetSlotPlan(exists bool, info *VectorCollectionInfo, target
 	if !exists {
 		return TargetSlotPlan{Create: true}
 	}
-	if info == nil || info.VectorSize != targetDimension || !info.HasNamedDenseVector || !info.HasSparseVector {
+	// 存储参数（量化、on_disk、HNSW）变更后需要重建槽位，新配置才能随蓝绿切换生效。
+	if info == nil || info.VectorSize != targetDimension || !info.HasNamedDenseVector || !info.HasSparseVector || info.StorageConfigMismatch {
 		return TargetSlotPlan{Recreate: true}
 	}
 	return TargetSlotPlan{ClearPoints: true}
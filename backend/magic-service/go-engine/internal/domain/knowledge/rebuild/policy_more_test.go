@@ -69,3 +69,28 @@ func TestResolveActiveCollectionStateAndValidation(t *testing.T) {
 		t.Fatal("fixed collection should not need normalization")
 	}
 }
+
+func TestStorageConfigMismatchForcesSlotRecreate(t *testing.T) {
+	t.Parallel()
+
+	info := &rebuild.VectorCollectionInfo{VectorSize: 1024, HasNamedDenseVector: true, HasSparseVector: true}
+	if plan := rebuild.BuildReusableTargetSlotPlan(true, info, 1024); !plan.ClearPoints || plan.Recreate {
+		t.Fatalf("expected matching slot to be reused, got %#v", plan)
+	}
+
+	info.StorageConfigMismatch = true
+	if plan := rebuild.BuildReusableTargetSlotPlan(true, info, 1024); !plan.Recreate {
+		t.Fatalf("expected storage mismatch to recreate slot, got %#v", plan)
+	}
+
+	state := rebuild.ResolveActiveCollectionState(
+		sharedroute.CollectionMeta{Exists: true, Model: "m1", VectorDimension: 1024, PhysicalCollectionName: "active"},
+		"alias",
+		"active",
+		info,
+		"active",
+	)
+	if !state.StorageOutdated {
+		t.Fatalf("expected active collection to be marked storage outdated: %#v", state)
+	}
+}
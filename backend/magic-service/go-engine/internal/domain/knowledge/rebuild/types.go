@@ -117,5 +117,8 @@ type VectorCollectionInfo struct {
 	Points              int64
 	HasNamedDenseVector bool
 	HasSparseVector     bool
+	HasMultiVector      bool
 	PayloadSchemaKeys   []string
+	// StorageConfigMismatch 表示集合存储参数（含 late-interaction 多向量字段）已偏离当前配置
+	StorageConfigMismatch bool
 }
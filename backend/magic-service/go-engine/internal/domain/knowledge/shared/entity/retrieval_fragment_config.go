@@ -7,19 +7,38 @@ import (
 
 // RetrieveConfig 检索配置，与 PHP RetrieveConfig 字段完全对齐。
 type RetrieveConfig struct {
-	Version               int                   `json:"version,omitempty"`
-	SearchMethod          string                `json:"search_method,omitempty"`
-	TopK                  int                   `json:"top_k"`
-	ScoreThreshold        float64               `json:"score_threshold"`
-	ScoreThresholdEnabled bool                  `json:"score_threshold_enabled"`
-	RerankingMode         string                `json:"reranking_mode,omitempty"`
-	RerankingEnable       bool                  `json:"reranking_enable"`
-	Weights               *RetrieveWeights      `json:"weights,omitempty"`
-	RerankingModel        *RerankingModelConfig `json:"reranking_model,omitempty"`
-	RerankEnabled         bool                  `json:"rerank_enabled,omitempty"`
-	HybridAlpha           float64               `json:"hybrid_alpha,omitempty"`
-	HybridBeta            float64               `json:"hybrid_beta,omitempty"`
-	HybridTopKMultiplier  int                   `json:"hybrid_top_k_multiplier,omitempty"`
+	Version               int                         `json:"version,omitempty"`
+	SearchMethod          string                      `json:"search_method,omitempty"`
+	TopK                  int                         `json:"top_k"`
+	ScoreThreshold        float64                     `json:"score_threshold"`
+	ScoreThresholdEnabled bool                        `json:"score_threshold_enabled"`
+	RerankingMode         string                      `json:"reranking_mode,omitempty"`
+	RerankingEnable       bool                        `json:"reranking_enable"`
+	Weights               *RetrieveWeights            `json:"weights,omitempty"`
+	RerankingModel        *RerankingModelConfig       `json:"reranking_model,omitempty"`
+	RerankEnabled         bool                        `json:"rerank_enabled,omitempty"`
+	HybridAlpha           float64                     `json:"hybrid_alpha,omitempty"`
+	HybridBeta            float64                     `json:"hybrid_beta,omitempty"`
+	HybridTopKMultiplier  int                         `json:"hybrid_top_k_multiplier,omitempty"`
+	FusionAlgorithm       string                      `json:"fusion_algorithm,omitempty"`
+	FusionRRFK            int                         `json:"fusion_rrf_k,omitempty"`
+	QueryExpansion        *QueryExpansionConfig       `json:"query_expansion,omitempty"`
+	ContextualEnrichment  *ContextualEnrichmentConfig `json:"contextual_enrichment,omitempty"`
+}
+
+// ContextualEnrichmentConfig 入库片段上下文增强配置。
+type ContextualEnrichmentConfig struct {
+	Enabled   bool   `json:"enabled"`
+	Mode      string `json:"mode,omitempty"`
+	ModelName string `json:"model_name,omitempty"`
+}
+
+// QueryExpansionConfig 多查询扩展与 HyDE 改写配置。
+type QueryExpansionConfig struct {
+	Enabled         bool   `json:"enabled"`
+	MultiQueryCount int    `json:"multi_query_count,omitempty"`
+	HyDEEnabled     bool   `json:"hyde_enabled,omitempty"`
+	ModelName       string `json:"model_name,omitempty"`
 }
 
 // RetrieveWeights 检索权重配置，与 PHP weights 字段对齐。
@@ -43,6 +62,7 @@ type KeywordWeightSetting struct {
 
 // GraphWeightSetting 图检索权重配置。
 type GraphWeightSetting struct {
+	Enabled           bool    `json:"enabled,omitempty"`
 	RelationWeight    float64 `json:"relation_weight"`
 	MaxDepth          int     `json:"max_depth"`
 	IncludeProperties bool    `json:"include_properties"`
@@ -79,9 +99,18 @@ const (
 
 // FragmentConfig 片段配置。
 type FragmentConfig struct {
-	Mode      FragmentMode             `json:"mode"`
-	Normal    *NormalFragmentConfig    `json:"normal,omitempty"`
-	Hierarchy *HierarchyFragmentConfig `json:"hierarchy,omitempty"`
+	Mode               FragmentMode             `json:"mode"`
+	Normal             *NormalFragmentConfig    `json:"normal,omitempty"`
+	Hierarchy          *HierarchyFragmentConfig `json:"hierarchy,omitempty"`
+	AuxiliaryFragments *AuxiliaryFragmentConfig `json:"auxiliary_fragments,omitempty"`
+}
+
+// AuxiliaryFragmentConfig 摘要与常见问题辅助片段配置。
+type AuxiliaryFragmentConfig struct {
+	Enabled       bool   `json:"enabled"`
+	Scope         string `json:"scope,omitempty"`
+	QuestionCount int    `json:"question_count,omitempty"`
+	ModelName     string `json:"model_name,omitempty"`
 }
 
 // NormalFragmentConfig 普通分段配置。
@@ -9,4 +9,6 @@ var (
 	ErrEmbeddingModelNotAllowed = errors.New("embedding model is not allowed")
 	// ErrDocumentFileEmpty 表示文档文件为空。
 	ErrDocumentFileEmpty = errors.New("document file is empty")
+	// ErrVectorCollectionNotFound 表示向量集合（或 alias）不存在，各向量库后端共用。
+	ErrVectorCollectionNotFound = errors.New("collection not found")
 )
@@ -41,5 +41,9 @@ func CloneFragmentConfig(cfg *FragmentConfig) *FragmentConfig {
 		hierarchy.TextPreprocessRule = append([]int(nil), cfg.Hierarchy.TextPreprocessRule...)
 		cloned.Hierarchy = &hierarchy
 	}
+	if cfg.AuxiliaryFragments != nil {
+		auxiliaryFragments := *cfg.AuxiliaryFragments
+		cloned.AuxiliaryFragments = &auxiliaryFragments
+	}
 	return cloned
 }
@@ -41,6 +41,14 @@ func CloneRetrieveConfig(cfg *RetrieveConfig) *RetrieveConfig {
 		rerankingModel := *cfg.RerankingModel
 		cloned.RerankingModel = &rerankingModel
 	}
+	if cfg.QueryExpansion != nil {
+		queryExpansion := *cfg.QueryExpansion
+		cloned.QueryExpansion = &queryExpansion
+	}
+	if cfg.ContextualEnrichment != nil {
+		contextualEnrichment := *cfg.ContextualEnrichment
+		cloned.ContextualEnrichment = &contextualEnrichment
+	}
 	return &cloned
 }
 
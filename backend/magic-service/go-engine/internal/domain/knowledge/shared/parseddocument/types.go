@@ -8,10 +8,30 @@ const (
 	SourceText = "text"
 	// SourceTabular 表示结构化表格解析结果。
 	SourceTabular = "tabular"
+	// SourceEmail 表示邮件解析结果，每个块是一封邮件或一个会话。
+	SourceEmail = "email"
+	// SourceCode 表示源代码解析结果，每个块是一个符号（函数/类型/类）或模块级代码段。
+	SourceCode = "code"
+	// SourceNotebook 表示 Jupyter Notebook 解析结果，每个块是一个单元格。
+	SourceNotebook = "notebook"
+	// SourceSubtitle 表示字幕解析结果，每个块是一个时间窗口内合并的字幕。
+	SourceSubtitle = "subtitle"
 	// BlockTypeTableRow 表示表格行块。
 	BlockTypeTableRow = "table_row"
 	// BlockTypeTableSummary 表示表摘要块。
 	BlockTypeTableSummary = "table_summary"
+	// BlockTypeEmailMessage 表示单封邮件块。
+	BlockTypeEmailMessage = "email_message"
+	// BlockTypeEmailThread 表示邮件会话块。
+	BlockTypeEmailThread = "email_thread"
+	// BlockTypeCodeSymbol 表示源代码符号块。
+	BlockTypeCodeSymbol = "code_symbol"
+	// BlockTypeCodeModule 表示符号之外的模块级代码块（import、常量等）。
+	BlockTypeCodeModule = "code_module"
+	// BlockTypeNotebookCell 表示 Notebook 单元格块。
+	BlockTypeNotebookCell = "notebook_cell"
+	// BlockTypeSubtitleWindow 表示字幕时间窗口块。
+	BlockTypeSubtitleWindow = "subtitle_window"
 	// MetaSourceFormat 表示来源文件格式 metadata 键。
 	MetaSourceFormat = "source_format"
 	// MetaFileName 表示源文件名 metadata 键。
@@ -44,6 +64,54 @@ const (
 	MetaTableRowCount = "table_row_count"
 	// MetaFields 表示字段列表 metadata 键。
 	MetaFields = "fields"
+	// MetaCreatedAtTS 表示块自身时间（Unix 秒）metadata 键，与片段 created_at_ts 同名以参与时间范围过滤。
+	MetaCreatedAtTS = "created_at_ts"
+	// MetaTags 表示块标签 metadata 键，与片段 tags 同名以参与标签过滤。
+	MetaTags = "tags"
+	// MetaEmailSubject 表示邮件主题 metadata 键。
+	MetaEmailSubject = "email_subject"
+	// MetaEmailFrom 表示发件人 metadata 键。
+	MetaEmailFrom = "email_from"
+	// MetaEmailTo 表示收件人列表 metadata 键。
+	MetaEmailTo = "email_to"
+	// MetaEmailCc 表示抄送列表 metadata 键。
+	MetaEmailCc = "email_cc"
+	// MetaEmailDate 表示邮件时间（RFC3339）metadata 键。
+	MetaEmailDate = "email_date"
+	// MetaEmailMessageID 表示 Message-ID metadata 键。
+	MetaEmailMessageID = "email_message_id"
+	// MetaEmailThreadID 表示会话 ID metadata 键。
+	MetaEmailThreadID = "email_thread_id"
+	// MetaEmailMessageCount 表示会话内邮件数 metadata 键。
+	MetaEmailMessageCount = "email_message_count"
+	// MetaEmailAttachments 表示附件文件名列表 metadata 键。
+	MetaEmailAttachments = "email_attachments"
+	// MetaCodeLanguage 表示源代码语言 metadata 键。
+	MetaCodeLanguage = "code_language"
+	// MetaCodeFilePath 表示源代码文件路径 metadata 键。
+	MetaCodeFilePath = "code_file_path"
+	// MetaCodeSymbol 表示符号名（如 Type.Method）metadata 键。
+	MetaCodeSymbol = "code_symbol"
+	// MetaCodeSymbolKind 表示符号类型（function/method/type/class/module）metadata 键。
+	MetaCodeSymbolKind = "code_symbol_kind"
+	// MetaCodeSectionPath 表示 package/Type.Method 形式的章节路径 metadata 键。
+	MetaCodeSectionPath = "code_section_path"
+	// MetaCodeStartLine 表示起始行号（从 1 开始）metadata 键。
+	MetaCodeStartLine = "code_start_line"
+	// MetaCodeEndLine 表示结束行号（含）metadata 键。
+	MetaCodeEndLine = "code_end_line"
+	// MetaNotebookCellIndex 表示单元格序号（从 0 开始）metadata 键。
+	MetaNotebookCellIndex = "cell_index"
+	// MetaNotebookCellType 表示单元格类型（markdown/code/raw）metadata 键。
+	MetaNotebookCellType = "cell_type"
+	// MetaNotebookSection 表示单元格所属的最近一级 Markdown 标题 metadata 键。
+	MetaNotebookSection = "notebook_section"
+	// MetaStartMS 表示媒体起始时间（毫秒）metadata 键。
+	MetaStartMS = "start_ms"
+	// MetaEndMS 表示媒体结束时间（毫秒）metadata 键。
+	MetaEndMS = "end_ms"
+	// MetaSubtitleCueCount 表示窗口内字幕条数 metadata 键。
+	MetaSubtitleCueCount = "subtitle_cue_count"
 	// MetaEmbeddedImageCount 表示文档内嵌图片总数 metadata 键。
 	MetaEmbeddedImageCount = "embedded_image_count"
 	// MetaEmbeddedImageOCRSuccessCount 表示文档内嵌图片 OCR 成功数 metadata 键。
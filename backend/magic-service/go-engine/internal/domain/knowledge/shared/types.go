@@ -227,6 +227,36 @@ type RetrieveConfig struct {
 	HybridAlpha           float64               `json:"hybrid_alpha,omitempty"`
 	HybridBeta            float64               `json:"hybrid_beta,omitempty"`
 	HybridTopKMultiplier  int                   `json:"hybrid_top_k_multiplier,omitempty"`
+	FusionAlgorithm       string                `json:"fusion_algorithm,omitempty"`
+	FusionRRFK            int                   `json:"fusion_rrf_k,omitempty"`
+	QueryExpansion        *QueryExpansionConfig `json:"query_expansion,omitempty"`
+	// ContextualEnrichment 作用于入库阶段：向量化前为片段拼接文档级上下文。
+	ContextualEnrichment *ContextualEnrichmentConfig `json:"contextual_enrichment,omitempty"`
+}
+
+// ContextualEnrichmentMode 表示片段上下文增强方式。
+type ContextualEnrichmentMode string
+
+const (
+	// ContextualEnrichmentModePrefix 使用文档标题与章节路径拼接确定性前缀。
+	ContextualEnrichmentModePrefix ContextualEnrichmentMode = "prefix"
+	// ContextualEnrichmentModeLLM 额外调用对话模型为片段生成一句上下文说明。
+	ContextualEnrichmentModeLLM ContextualEnrichmentMode = "llm"
+)
+
+// ContextualEnrichmentConfig 描述入库时的片段上下文增强配置，原文展示不受影响。
+type ContextualEnrichmentConfig struct {
+	Enabled   bool                     `json:"enabled"`
+	Mode      ContextualEnrichmentMode `json:"mode,omitempty"`
+	ModelName string                   `json:"model_name,omitempty"`
+}
+
+// QueryExpansionConfig 描述检索前的多查询扩展与 HyDE 改写配置。
+type QueryExpansionConfig struct {
+	Enabled         bool   `json:"enabled"`
+	MultiQueryCount int    `json:"multi_query_count,omitempty"`
+	HyDEEnabled     bool   `json:"hyde_enabled,omitempty"`
+	ModelName       string `json:"model_name,omitempty"`
 }
 
 // RetrieveWeights 描述检索阶段各召回通道的权重配置。
@@ -250,6 +280,8 @@ type KeywordWeightSetting struct {
 
 // GraphWeightSetting 描述图召回权重配置。
 type GraphWeightSetting struct {
+	// Enabled 控制是否在同步时抽取实体关系并启用图召回通道。
+	Enabled           bool    `json:"enabled,omitempty"`
 	RelationWeight    float64 `json:"relation_weight"`
 	MaxDepth          int     `json:"max_depth"`
 	IncludeProperties bool    `json:"include_properties"`
@@ -289,6 +321,26 @@ type FragmentConfig struct {
 	Mode      FragmentMode             `json:"mode"`
 	Normal    *NormalFragmentConfig    `json:"normal,omitempty"`
 	Hierarchy *HierarchyFragmentConfig `json:"hierarchy,omitempty"`
+	// AuxiliaryFragments 为空或未开启时不生成摘要/问题辅助片段。
+	AuxiliaryFragments *AuxiliaryFragmentConfig `json:"auxiliary_fragments,omitempty"`
+}
+
+// AuxiliaryFragmentScope 表示辅助片段的生成粒度。
+type AuxiliaryFragmentScope string
+
+const (
+	// AuxiliaryFragmentScopeSection 按章节分组生成，章节过长时继续按预算拆分。
+	AuxiliaryFragmentScopeSection AuxiliaryFragmentScope = "section"
+	// AuxiliaryFragmentScopeDocument 忽略章节边界，按预算对整篇文档连续分组生成。
+	AuxiliaryFragmentScopeDocument AuxiliaryFragmentScope = "document"
+)
+
+// AuxiliaryFragmentConfig 描述入库时由对话模型生成摘要与常见问题辅助片段的配置。
+type AuxiliaryFragmentConfig struct {
+	Enabled       bool                   `json:"enabled"`
+	Scope         AuxiliaryFragmentScope `json:"scope,omitempty"`
+	QuestionCount int                    `json:"question_count,omitempty"`
+	ModelName     string                 `json:"model_name,omitempty"`
 }
 
 // NormalFragmentConfig 描述普通切片模式配置。
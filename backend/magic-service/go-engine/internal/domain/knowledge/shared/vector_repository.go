@@ -2,10 +2,14 @@ package shared
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 )
 
+// ErrMultiVectorNotConfigured 表示目标集合未声明 late-interaction 多向量字段，需要重建集合后才能写入。
+var ErrMultiVectorNotConfigured = errors.New("collection has no multi-vector field")
+
 const (
 	// SparseBackendSelectionReasonExplicitRequested 表示显式指定的 backend 可直接使用。
 	SparseBackendSelectionReasonExplicitRequested = "explicit_requested"
@@ -67,6 +71,13 @@ type VectorDBDataRepository[T any] interface {
 	SearchSparseWithFilter(ctx context.Context, request SparseSearchRequest) ([]*VectorSearchResult[T], error)
 }
 
+// VectorDBMultiVectorRepository 定义 late-interaction 多向量写入与 MaxSim 检索能力，仅部分后端支持。
+type VectorDBMultiVectorRepository[T any] interface {
+	// StoreMultiVectors 只更新已存在点的多向量字段，不影响 dense / sparse 向量与 payload
+	StoreMultiVectors(ctx context.Context, collection string, pointIDs []string, multiVectors [][][]float64) error
+	SearchMultiVectorWithFilter(ctx context.Context, request MultiVectorSearchRequest) ([]*VectorSearchResult[T], error)
+}
+
 // SparseBackendSelection 描述一次 sparse backend 选择结果。
 type SparseBackendSelection struct {
 	Requested      string `json:"requested,omitempty"`
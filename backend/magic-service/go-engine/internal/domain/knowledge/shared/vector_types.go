@@ -6,6 +6,8 @@ const (
 	DefaultDenseVectorName = "dense_embedding"
 	// DefaultSparseVectorName 表示默认 sparse named vector 名称。
 	DefaultSparseVectorName = "sparse_terms"
+	// DefaultMultiVectorName 表示 late-interaction（ColBERT 类）逐 token 多向量名称。
+	DefaultMultiVectorName = "late_interaction"
 	// DefaultSparseModelName 表示默认 Qdrant BM25 模型名称。
 	DefaultSparseModelName = "qdrant/bm25"
 	// SparseBackendClientBM25QdrantIDFV1 表示客户端构造 sparse vector、Qdrant 负责 IDF 的后端版本。
@@ -53,6 +55,9 @@ type VectorCollectionInfo struct {
 	HasNamedDenseVector bool   `json:"has_named_dense_vector"`
 	HasSparseVector     bool   `json:"has_sparse_vector"`
 	PayloadSchemaKeys   []string
+	HasMultiVector      bool `json:"has_multi_vector"`
+	// StorageConfigMismatch 表示集合的量化 / on_disk / HNSW 参数与当前配置不一致，复用前需要重建
+	StorageConfigMismatch bool `json:"storage_config_mismatch"`
 }
 
 // SparseVector 表示稀疏向量。
@@ -95,6 +100,19 @@ type SparseSearchRequest struct {
 	Filter         *VectorFilter   `json:"filter,omitempty"`
 }
 
+// MultiVectorSearchRequest 表示 late-interaction 多向量 MaxSim 检索请求。
+//
+// PointIDs 非空时只在这些点内打分，用于对已召回候选做 late-interaction 重排。
+type MultiVectorSearchRequest struct {
+	Collection     string        `json:"collection"`
+	VectorName     string        `json:"vector_name,omitempty"`
+	Vectors        [][]float64   `json:"vectors,omitempty"`
+	PointIDs       []string      `json:"point_ids,omitempty"`
+	TopK           int           `json:"top_k"`
+	ScoreThreshold float64       `json:"score_threshold"`
+	Filter         *VectorFilter `json:"filter,omitempty"`
+}
+
 // VectorSearchResult 向量搜索结果。
 type VectorSearchResult[T any] struct {
 	ID       string         `json:"id"`
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// ObjectStorageObject 表示前缀下列出的一个对象。
+type ObjectStorageObject struct {
+	Key          string
+	ETag         string
+	Size         int64
+	LastModified time.Time
+}
+
+// ObjectStorageLister 定义按桶与前缀列出对象的能力。
+type ObjectStorageLister interface {
+	// ListObjects 返回 prefix 下的全部对象（已处理分页），不包含目录占位对象。
+	ListObjects(ctx context.Context, bucket, prefix string) ([]ObjectStorageObject, error)
+}
+
+// NormalizeObjectStorageETag 去掉 ETag 两侧的引号，便于跨接口比较。
+func NormalizeObjectStorageETag(etag string) string {
+	return strings.Trim(strings.TrimSpace(etag), `"`)
+}
@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SyncConfigStrings 读取 sync_config 中的字符串列表，兼容 JSON 数组与逗号分隔字符串。
+func SyncConfigStrings(raw any) []string {
+	var values []string
+	switch typed := raw.(type) {
+	case []string:
+		values = typed
+	case []any:
+		for _, item := range typed {
+			if value, ok := item.(string); ok {
+				values = append(values, value)
+			}
+		}
+	case string:
+		values = strings.Split(typed, ",")
+	}
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+// ClampSyncConfigInt 读取 sync_config 中的整数并限制在 [minValue, maxValue]，缺失或非法时返回 fallback。
+func ClampSyncConfigInt(raw any, fallback, minValue, maxValue int) int {
+	value, ok := syncConfigFloat(raw)
+	if !ok {
+		return fallback
+	}
+	switch {
+	case value < float64(minValue):
+		return minValue
+	case value > float64(maxValue):
+		return maxValue
+	default:
+		return int(value)
+	}
+}
+
+func syncConfigFloat(raw any) (float64, bool) {
+	switch typed := raw.(type) {
+	case int:
+		return float64(typed), true
+	case int64:
+		return float64(typed), true
+	case float64:
+		return typed, true
+	case string:
+		value, err := strconv.ParseFloat(strings.TrimSpace(typed), 64)
+		return value, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func syncConfigBool(raw any, fallback bool) bool {
+	switch typed := raw.(type) {
+	case bool:
+		return typed
+	case string:
+		value, err := strconv.ParseBool(strings.TrimSpace(typed))
+		if err != nil {
+			return fallback
+		}
+		return value
+	default:
+		return fallback
+	}
+}
+
+// CompilePathGlobs 把路径 glob 编译为正则：** 匹配任意层级，* 与 ? 不跨越 "/"。
+func CompilePathGlobs(patterns []string) ([]*regexp.Regexp, error) {
+	matchers := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		var builder strings.Builder
+		builder.WriteString("^")
+		for index := 0; index < len(pattern); index++ {
+			switch {
+			case strings.HasPrefix(pattern[index:], "**"):
+				builder.WriteString(".*")
+				index++
+			case pattern[index] == '*':
+				builder.WriteString("[^/]*")
+			case pattern[index] == '?':
+				builder.WriteString("[^/]")
+			default:
+				builder.WriteString(regexp.QuoteMeta(pattern[index : index+1]))
+			}
+		}
+		builder.WriteString("$")
+		matcher, err := regexp.Compile(builder.String())
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", pattern, err)
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+// MatchAnyPathGlob 判断路径是否命中任一 glob。
+func MatchAnyPathGlob(matchers []*regexp.Regexp, targetPath string) bool {
+	for _, matcher := range matchers {
+		if matcher.MatchString(targetPath) {
+			return true
+		}
+	}
+	return false
+}
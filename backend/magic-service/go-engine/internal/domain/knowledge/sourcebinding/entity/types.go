@@ -14,6 +14,14 @@ const (
 	ProviderTeamshare = "teamshare"
 	// ProviderLocalUpload 表示本地上传来源。
 	ProviderLocalUpload = "local_upload"
+	// ProviderWebsite 表示网站爬取来源。
+	ProviderWebsite = "website"
+	// ProviderGit 表示 Git 仓库来源。
+	ProviderGit = "git"
+	// ProviderObjectStorage 表示 S3 兼容对象存储来源（S3、MinIO 等）。
+	ProviderObjectStorage = "object_storage"
+	// ProviderWebhook 表示由外部系统通过签名 HTTP 回调推送内容的来源。
+	ProviderWebhook = "webhook"
 
 	// RootTypeProject 表示绑定根节点为项目。
 	RootTypeProject = "project"
@@ -25,6 +33,14 @@ const (
 	RootTypeUploadBatch = "upload_batch"
 	// RootTypeFile 表示绑定根节点为文件。
 	RootTypeFile = "file"
+	// RootTypeSite 表示绑定根节点为网站，root_ref 为主种子 URL。
+	RootTypeSite = "site"
+	// RootTypeRepository 表示绑定根节点为 Git 仓库，root_ref 为仓库地址或本地路径。
+	RootTypeRepository = "repository"
+	// RootTypeBucketPrefix 表示绑定根节点为对象存储桶内前缀，root_ref 形如 "bucket/prefix"。
+	RootTypeBucketPrefix = "bucket_prefix"
+	// RootTypeChannel 表示绑定根节点为推送通道，root_ref 为知识库内唯一的通道名。
+	RootTypeChannel = "channel"
 
 	// TargetTypeFolder 表示绑定目标是目录。
 	TargetTypeFolder = "folder"
@@ -50,6 +66,14 @@ var (
 	ErrTargetTypeInvalid = errors.New("source binding target type is invalid")
 	// ErrSyncModeInvalid 表示绑定 sync_mode 非法。
 	ErrSyncModeInvalid = errors.New("source binding sync mode is invalid")
+	// ErrInvalidWebsiteCrawlConfig 表示网站绑定的爬取配置非法。
+	ErrInvalidWebsiteCrawlConfig = errors.New("invalid website crawl config")
+	// ErrInvalidGitSourceConfig 表示 Git 绑定的仓库配置非法。
+	ErrInvalidGitSourceConfig = errors.New("invalid git source config")
+	// ErrInvalidObjectStorageSourceConfig 表示对象存储绑定的桶/前缀配置非法。
+	ErrInvalidObjectStorageSourceConfig = errors.New("invalid object storage source config")
+	// ErrInvalidWebhookSourceConfig 表示 webhook 推送绑定的通道或签名配置非法。
+	ErrInvalidWebhookSourceConfig = errors.New("invalid webhook source config")
 	// ErrTargetsNotAllowed 表示当前来源类型不允许带来源绑定。
 	ErrTargetsNotAllowed = errors.New("source bindings are not allowed for current source type")
 )
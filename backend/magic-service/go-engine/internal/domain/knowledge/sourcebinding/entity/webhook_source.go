@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+const (
+	// WebhookTimestampHeader 携带签名使用的 Unix 秒时间戳，推送与回调共用。
+	WebhookTimestampHeader = "X-Magic-Webhook-Timestamp"
+	// WebhookSignatureHeader 携带 "sha256=" 前缀的 HMAC 签名，推送与回调共用。
+	WebhookSignatureHeader = "X-Magic-Webhook-Signature"
+
+	webhookSignaturePrefix = "sha256="
+)
+
+const (
+	// WebhookCallbackStatusSynced 表示推送条目已完成同步。
+	WebhookCallbackStatusSynced = "synced"
+	// WebhookCallbackStatusFailed 表示推送条目同步失败。
+	WebhookCallbackStatusFailed = "failed"
+)
+
+// WebhookCallbackTarget 表示同步结果的回调目标与签名密钥。
+//
+// URL 只在绑定校验时检查了 http(s) 协议与主机名，不限制内网地址；
+// 投递方需要按出站策略校验，并依赖部署侧的出口网络隔离防范 SSRF。
+type WebhookCallbackTarget struct {
+	URL           string
+	SigningSecret string
+}
+
+// WebhookCallbackEvent 表示回调给外部系统的条目同步结果。
+type WebhookCallbackEvent struct {
+	Channel           string `json:"channel"`
+	KnowledgeBaseCode string `json:"knowledge_base_code"`
+	ExternalID        string `json:"external_id"`
+	DocumentCode      string `json:"document_code"`
+	Status            string `json:"status"`
+	Message           string `json:"message,omitempty"`
+	OccurredAt        int64  `json:"occurred_at"`
+}
+
+// WebhookCallbackNotifier 定义把条目同步结果签名后回调给外部系统的能力。
+type WebhookCallbackNotifier interface {
+	NotifyWebhookCallback(ctx context.Context, target WebhookCallbackTarget, event WebhookCallbackEvent) error
+}
+
+// SignWebhookPayload 计算推送与回调共用的签名：HMAC-SHA256(secret, "<timestamp>.<body>")，形如 "sha256=<hex>"。
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,235 @@
+package entity
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	websiteSyncConfigSeedURLs          = "seed_urls"
+	websiteSyncConfigIncludePatterns   = "include_patterns"
+	websiteSyncConfigExcludePatterns   = "exclude_patterns"
+	websiteSyncConfigMaxDepth          = "max_depth"
+	websiteSyncConfigMaxPages          = "max_pages"
+	websiteSyncConfigRespectRobotsTxt  = "respect_robots_txt"
+	websiteSyncConfigUseSitemap        = "use_sitemap"
+	websiteSyncConfigRequestsPerSecond = "requests_per_second"
+	websiteSyncConfigRecrawlHours      = "recrawl_interval_hours"
+
+	defaultWebsiteMaxDepth          = 3
+	maxWebsiteMaxDepth              = 10
+	defaultWebsiteMaxPages          = 200
+	maxWebsiteMaxPages              = 1000
+	defaultWebsiteRequestsPerSecond = 1.0
+	maxWebsiteRequestsPerSecond     = 10.0
+	defaultWebsiteRecrawlHours      = 24
+	minWebsiteRecrawlHours          = 1
+)
+
+const (
+	// WebsitePageSkipRobotsDisallowed 表示页面被 robots.txt 禁止抓取。
+	WebsitePageSkipRobotsDisallowed = "robots_disallowed"
+	// WebsitePageSkipUnsupportedContent 表示页面不是可解析的 HTML 内容。
+	WebsitePageSkipUnsupportedContent = "unsupported_content_type"
+	// WebsitePageSkipFetchFailed 表示页面抓取失败。
+	WebsitePageSkipFetchFailed = "fetch_failed"
+	// WebsitePageSkipPageLimit 表示页面超出 max_pages 上限。
+	WebsitePageSkipPageLimit = "page_limit_exceeded"
+)
+
+// ErrInvalidWebsiteURL 表示网站 URL 不是合法的 http/https 地址。
+var ErrInvalidWebsiteURL = errors.New("invalid website url")
+
+// WebsiteCrawlConfig 表示网站绑定的爬取配置，由 binding.sync_config 解析而来。
+type WebsiteCrawlConfig struct {
+	SeedURLs          []string
+	IncludePatterns   []string
+	ExcludePatterns   []string
+	MaxDepth          int
+	MaxPages          int
+	RespectRobotsTxt  bool
+	UseSitemap        bool
+	RequestsPerSecond float64
+	RecrawlInterval   time.Duration
+
+	allowedHosts    []string
+	includeMatchers []*regexp.Regexp
+	excludeMatchers []*regexp.Regexp
+}
+
+// WebsitePageValidator 表示上次抓取页面时记录的 HTTP 缓存校验值。
+type WebsitePageValidator struct {
+	ETag         string
+	LastModified string
+}
+
+// WebsiteCrawlRequest 表示一次网站爬取请求。
+//
+// Validators 以规范化 URL 为键，爬虫据此发起条件请求，未变化页面返回 NotModified。
+type WebsiteCrawlRequest struct {
+	Config     WebsiteCrawlConfig
+	Validators map[string]WebsitePageValidator
+}
+
+// WebsitePage 表示一次爬取得到的页面。
+type WebsitePage struct {
+	URL          string
+	Title        string
+	Depth        int
+	ContentType  string
+	ETag         string
+	LastModified string
+	NotModified  bool
+}
+
+// WebsitePageSkip 表示被跳过的页面及原因。
+type WebsitePageSkip struct {
+	URL    string
+	Reason string
+}
+
+// WebsiteCrawlResult 表示一次网站爬取结果。
+//
+// GoneURLs 只包含明确返回 404/410 的已知页面；因深度或页数上限没有访问到的页面不在其中。
+type WebsiteCrawlResult struct {
+	Pages    []WebsitePage
+	GoneURLs []string
+	Skipped  []WebsitePageSkip
+}
+
+// WebsiteCrawler 定义网站爬取能力。
+type WebsiteCrawler interface {
+	Crawl(ctx context.Context, request WebsiteCrawlRequest) (*WebsiteCrawlResult, error)
+}
+
+// ParseWebsiteCrawlConfig 从网站绑定解析爬取配置并补齐默认值。
+//
+// root_ref 总是第一个种子 URL；爬取范围限定在种子 URL 所在的主机内。
+func ParseWebsiteCrawlConfig(binding Binding) (WebsiteCrawlConfig, error) {
+	syncConfig := binding.SyncConfig
+	seeds, err := parseWebsiteSeedURLs(binding.RootRef, syncConfig[websiteSyncConfigSeedURLs])
+	if err != nil {
+		return WebsiteCrawlConfig{}, err
+	}
+	config := WebsiteCrawlConfig{
+		SeedURLs:          seeds,
+		IncludePatterns:   SyncConfigStrings(syncConfig[websiteSyncConfigIncludePatterns]),
+		ExcludePatterns:   SyncConfigStrings(syncConfig[websiteSyncConfigExcludePatterns]),
+		MaxDepth:          ClampSyncConfigInt(syncConfig[websiteSyncConfigMaxDepth], defaultWebsiteMaxDepth, 0, maxWebsiteMaxDepth),
+		MaxPages:          ClampSyncConfigInt(syncConfig[websiteSyncConfigMaxPages], defaultWebsiteMaxPages, 1, maxWebsiteMaxPages),
+		RespectRobotsTxt:  syncConfigBool(syncConfig[websiteSyncConfigRespectRobotsTxt], true),
+		UseSitemap:        syncConfigBool(syncConfig[websiteSyncConfigUseSitemap], true),
+		RequestsPerSecond: websiteConfigRequestsPerSecond(syncConfig[websiteSyncConfigRequestsPerSecond]),
+		RecrawlInterval: time.Duration(ClampSyncConfigInt(
+			syncConfig[websiteSyncConfigRecrawlHours],
+			defaultWebsiteRecrawlHours,
+			minWebsiteRecrawlHours,
+			math.MaxInt32,
+		)) * time.Hour,
+	}
+	if config.includeMatchers, err = CompilePathGlobs(config.IncludePatterns); err != nil {
+		return WebsiteCrawlConfig{}, fmt.Errorf("%w: %w", ErrInvalidWebsiteCrawlConfig, err)
+	}
+	if config.excludeMatchers, err = CompilePathGlobs(config.ExcludePatterns); err != nil {
+		return WebsiteCrawlConfig{}, fmt.Errorf("%w: %w", ErrInvalidWebsiteCrawlConfig, err)
+	}
+	for _, seed := range seeds {
+		host := websiteURLHost(seed)
+		if !slices.Contains(config.allowedHosts, host) {
+			config.allowedHosts = append(config.allowedHosts, host)
+		}
+	}
+	return config, nil
+}
+
+// AllowsURL 判断 URL 是否在爬取范围内：主机必须属于种子主机，路径需命中 include 且不命中 exclude。
+func (c WebsiteCrawlConfig) AllowsURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || !slices.Contains(c.allowedHosts, strings.ToLower(parsed.Host)) {
+		return false
+	}
+	urlPath := cmp.Or(parsed.EscapedPath(), "/")
+	if len(c.includeMatchers) > 0 && !MatchAnyPathGlob(c.includeMatchers, urlPath) {
+		return false
+	}
+	return !MatchAnyPathGlob(c.excludeMatchers, urlPath)
+}
+
+// RecrawlDue 判断实时网站绑定是否到了重新爬取的时间。
+func (c WebsiteCrawlConfig) RecrawlDue(lastCrawledAt *time.Time, now time.Time) bool {
+	if lastCrawledAt == nil || lastCrawledAt.IsZero() {
+		return true
+	}
+	interval := c.RecrawlInterval
+	if interval <= 0 {
+		interval = time.Duration(defaultWebsiteRecrawlHours) * time.Hour
+	}
+	return !now.Before(lastCrawledAt.Add(interval))
+}
+
+// NormalizeWebsiteURL 规范化页面 URL，作为来源项 item_ref 使用。
+//
+// 统一小写 scheme/host、去掉 fragment、空路径补 "/"，保证同一页面在多次爬取中引用稳定。
+func NormalizeWebsiteURL(rawURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidWebsiteURL, err)
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("%w: %s", ErrInvalidWebsiteURL, rawURL)
+	}
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	parsed.User = nil
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+	return parsed.String(), nil
+}
+
+func parseWebsiteSeedURLs(rootRef string, raw any) ([]string, error) {
+	candidates := append([]string{rootRef}, SyncConfigStrings(raw)...)
+	seeds := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if strings.TrimSpace(candidate) == "" {
+			continue
+		}
+		normalized, err := NormalizeWebsiteURL(candidate)
+		if err != nil {
+			return nil, fmt.Errorf("%w: seed_urls: %w", ErrInvalidWebsiteCrawlConfig, err)
+		}
+		if !slices.Contains(seeds, normalized) {
+			seeds = append(seeds, normalized)
+		}
+	}
+	if len(seeds) == 0 {
+		return nil, fmt.Errorf("%w: seed_urls is empty", ErrInvalidWebsiteCrawlConfig)
+	}
+	return seeds, nil
+}
+
+func websiteConfigRequestsPerSecond(raw any) float64 {
+	value, ok := syncConfigFloat(raw)
+	if !ok || value <= 0 {
+		return defaultWebsiteRequestsPerSecond
+	}
+	return min(value, maxWebsiteRequestsPerSecond)
+}
+
+func websiteURLHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Host)
+}
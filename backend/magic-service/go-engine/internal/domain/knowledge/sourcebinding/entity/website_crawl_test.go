@@ -0,0 +1,66 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"magic/internal/domain/knowledge/sourcebinding/entity"
+)
+
+func TestParseWebsiteCrawlConfigAppliesDefaultsAndScope(t *testing.T) {
+	t.Parallel()
+
+	config, err := entity.ParseWebsiteCrawlConfig(entity.Binding{
+		Provider: entity.ProviderWebsite,
+		RootType: entity.RootTypeSite,
+		RootRef:  "HTTPS://Docs.Example.com#top",
+		SyncConfig: map[string]any{
+			"seed_urls":        []any{"https://blog.example.com/posts/"},
+			"include_patterns": []any{"/docs/**", "/posts/*"},
+			"exclude_patterns": "/docs/internal/**",
+			"max_pages":        5000,
+			"max_depth":        "2",
+		},
+	})
+	if err != nil {
+		t.Fatalf("parse website crawl config: %v", err)
+	}
+	if len(config.SeedURLs) != 2 || config.SeedURLs[0] != "https://docs.example.com/" {
+		t.Fatalf("unexpected seeds: %#v", config.SeedURLs)
+	}
+	if config.MaxPages != 1000 || config.MaxDepth != 2 || !config.RespectRobotsTxt || !config.UseSitemap ||
+		config.RecrawlInterval != 24*time.Hour {
+		t.Fatalf("unexpected defaults: %#v", config)
+	}
+
+	cases := map[string]bool{
+		"https://docs.example.com/docs/guide/install": true,
+		"https://blog.example.com/posts/hello":        true,
+		"https://blog.example.com/posts/2024/hello":   false,
+		"https://docs.example.com/docs/internal/keys": false,
+		"https://other.example.com/docs/guide":        false,
+	}
+	for rawURL, expected := range cases {
+		if got := config.AllowsURL(rawURL); got != expected {
+			t.Fatalf("AllowsURL(%q) = %v, want %v", rawURL, got, expected)
+		}
+	}
+}
+
+func TestWebsiteCrawlConfigRecrawlDue(t *testing.T) {
+	t.Parallel()
+
+	config, err := entity.ParseWebsiteCrawlConfig(entity.Binding{
+		RootRef:    "https://docs.example.com/",
+		SyncConfig: map[string]any{"recrawl_interval_hours": 6},
+	})
+	if err != nil {
+		t.Fatalf("parse website crawl config: %v", err)
+	}
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-5 * time.Hour)
+	stale := now.Add(-6 * time.Hour)
+	if !config.RecrawlDue(nil, now) || config.RecrawlDue(&recent, now) || !config.RecrawlDue(&stale, now) {
+		t.Fatal("unexpected recrawl decisions")
+	}
+}
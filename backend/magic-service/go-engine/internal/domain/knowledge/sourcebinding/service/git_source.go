@@ -0,0 +1,179 @@
+package sourcebinding
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
+)
+
+const (
+	gitSyncConfigRef             = "ref"
+	gitSyncConfigIncludePatterns = "include_patterns"
+	gitSyncConfigExcludePatterns = "exclude_patterns"
+	gitSyncConfigMaxFileBytes    = "max_file_bytes"
+	// GitSyncConfigIndexedCommit 记录最近一次完成同步的提交 SHA，保存在 binding.sync_config 中。
+	GitSyncConfigIndexedCommit = "indexed_commit"
+
+	defaultGitMaxFileBytes = 5 << 20
+	maxGitMaxFileBytes     = 50 << 20
+)
+
+const (
+	// GitSnapshotMetaPath 表示文件在仓库内的相对路径。
+	GitSnapshotMetaPath = "path"
+	// GitSnapshotMetaRef 表示同步时使用的分支或标签。
+	GitSnapshotMetaRef = "ref"
+	// GitSnapshotMetaCommit 表示解析文件时的提交 SHA。
+	GitSnapshotMetaCommit = "commit"
+	// GitSnapshotMetaBlobSHA 表示文件内容的 blob SHA。
+	GitSnapshotMetaBlobSHA = "blob_sha"
+	// GitSnapshotMetaSize 表示文件字节数。
+	GitSnapshotMetaSize = "size"
+)
+
+const (
+	// GitFileSkipTooLarge 表示文件超过 max_file_bytes。
+	GitFileSkipTooLarge = "file_too_large"
+	// GitFileSkipFileLimit 表示文件超出单次物化的文档上限。
+	GitFileSkipFileLimit = "file_limit_exceeded"
+)
+
+// ErrInvalidGitRef 表示分支或标签名非法。
+var ErrInvalidGitRef = errors.New("invalid git ref")
+
+// gitRefPattern 只接受常见的分支/标签字符，避免把任意参数传给 git 命令。
+var gitRefPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// GitSourceConfig 表示 Git 绑定的仓库配置，由 binding.root_ref 与 sync_config 解析而来。
+type GitSourceConfig struct {
+	RepositoryURL   string
+	Ref             string
+	IncludePatterns []string
+	ExcludePatterns []string
+	MaxFileBytes    int64
+	IndexedCommit   string
+
+	includeMatchers []*regexp.Regexp
+	excludeMatchers []*regexp.Regexp
+}
+
+// ParseGitSourceConfig 从 Git 绑定解析仓库配置并补齐默认值。
+func ParseGitSourceConfig(binding sourcebindingentity.Binding) (GitSourceConfig, error) {
+	repositoryURL, err := sourcebindingentity.NormalizeGitRepositoryURL(binding.RootRef)
+	if err != nil {
+		return GitSourceConfig{}, fmt.Errorf("%w: root_ref: %w", sourcebindingentity.ErrInvalidGitSourceConfig, err)
+	}
+	syncConfig := binding.SyncConfig
+	ref, _ := syncConfig[gitSyncConfigRef].(string)
+	ref = strings.TrimSpace(ref)
+	if ref != "" && (!gitRefPattern.MatchString(ref) || strings.Contains(ref, "..")) {
+		return GitSourceConfig{}, fmt.Errorf("%w: %w: %q", sourcebindingentity.ErrInvalidGitSourceConfig, ErrInvalidGitRef, ref)
+	}
+	indexedCommit, _ := syncConfig[GitSyncConfigIndexedCommit].(string)
+	config := GitSourceConfig{
+		RepositoryURL:   repositoryURL,
+		Ref:             ref,
+		IncludePatterns: sourcebindingentity.SyncConfigStrings(syncConfig[gitSyncConfigIncludePatterns]),
+		ExcludePatterns: sourcebindingentity.SyncConfigStrings(syncConfig[gitSyncConfigExcludePatterns]),
+		MaxFileBytes: int64(sourcebindingentity.ClampSyncConfigInt(
+			syncConfig[gitSyncConfigMaxFileBytes],
+			defaultGitMaxFileBytes,
+			1,
+			maxGitMaxFileBytes,
+		)),
+		IndexedCommit: strings.TrimSpace(indexedCommit),
+	}
+	if config.includeMatchers, err = sourcebindingentity.CompilePathGlobs(config.IncludePatterns); err != nil {
+		return GitSourceConfig{}, fmt.Errorf("%w: %w", sourcebindingentity.ErrInvalidGitSourceConfig, err)
+	}
+	if config.excludeMatchers, err = sourcebindingentity.CompilePathGlobs(config.ExcludePatterns); err != nil {
+		return GitSourceConfig{}, fmt.Errorf("%w: %w", sourcebindingentity.ErrInvalidGitSourceConfig, err)
+	}
+	return config, nil
+}
+
+// AllowsPath 判断仓库内文件是否在同步范围内：需命中 include 且不命中 exclude。
+func (c GitSourceConfig) AllowsPath(filePath string) bool {
+	if len(c.includeMatchers) > 0 && !sourcebindingentity.MatchAnyPathGlob(c.includeMatchers, filePath) {
+		return false
+	}
+	return !sourcebindingentity.MatchAnyPathGlob(c.excludeMatchers, filePath)
+}
+
+// SyncDue 判断仓库 HEAD 是否相对已同步提交发生了变化。
+func (c GitSourceConfig) SyncDue(headCommit string) bool {
+	return c.IndexedCommit == "" || c.IndexedCommit != strings.TrimSpace(headCommit)
+}
+
+// GitFileItemRef 返回文件来源项的 item_ref，形如 "https://git.example.com/docs#main:guide/intro.md"。
+//
+// 来源项按组织与 provider 全局唯一，因此需要带上仓库地址与 ref；未指定 ref 时记为 HEAD。
+// 仓库地址中的凭据会被去掉，避免随来源项落库。
+func GitFileItemRef(config GitSourceConfig, filePath string) string {
+	ref := config.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return sourcebindingentity.RedactGitRepositoryURL(config.RepositoryURL) + "#" + ref + ":" + filePath
+}
+
+// GitFileGroupRef 返回文件所在目录；仓库根目录下的文件返回空串。
+func GitFileGroupRef(filePath string) string {
+	dir := path.Dir(filePath)
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	return dir
+}
+
+// BuildGitFileSnapshotMeta 构造文件来源项的快照元数据。
+func BuildGitFileSnapshotMeta(config GitSourceConfig, commit string, file sourcebindingentity.GitTreeFile) map[string]any {
+	return map[string]any{
+		GitSnapshotMetaPath:    file.Path,
+		GitSnapshotMetaRef:     config.Ref,
+		GitSnapshotMetaCommit:  commit,
+		GitSnapshotMetaBlobSHA: file.BlobSHA,
+		GitSnapshotMetaSize:    file.Size,
+	}
+}
+
+// GitBlobSHAFromSnapshot 从来源项快照中读取文件 blob SHA。
+func GitBlobSHAFromSnapshot(snapshotMeta map[string]any) string {
+	blobSHA, _ := snapshotMeta[GitSnapshotMetaBlobSHA].(string)
+	return blobSHA
+}
+
+// WithGitIndexedCommit 返回记录了最新已同步提交的 sync_config 副本。
+func WithGitIndexedCommit(syncConfig map[string]any, commit string) map[string]any {
+	updated := make(map[string]any, len(syncConfig)+1)
+	maps.Copy(updated, syncConfig)
+	updated[GitSyncConfigIndexedCommit] = commit
+	return updated
+}
+
+// GitChangedPathsByBlob 在缺少可比较的提交时，按 blob SHA 找出内容变化或新增的文件。
+//
+// previousBlobs 以文件路径为键；未出现在当前文件树中的旧路径同样视为变化（已删除）。
+func GitChangedPathsByBlob(previousBlobs map[string]string, files []sourcebindingentity.GitTreeFile) []string {
+	changed := make([]string, 0)
+	current := make(map[string]struct{}, len(files))
+	for _, file := range files {
+		current[file.Path] = struct{}{}
+		if previous, exists := previousBlobs[file.Path]; !exists || previous != file.BlobSHA {
+			changed = append(changed, file.Path)
+		}
+	}
+	for filePath := range previousBlobs {
+		if _, exists := current[filePath]; !exists {
+			changed = append(changed, filePath)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
@@ -146,6 +146,8 @@ func (s *IncrementalResolutionService) resolvePendingBindings(
 			if err != nil {
 				return nil, nil, fmt.Errorf("resolve source binding documents: %w", err)
 			}
+			// 跳过项的状态只在全量物化时记录，增量计划只关心会产生文档的来源项。
+			documents, _ = partitionResolvedDocuments(documents)
 			pending.resolvedUserID = resolvedUserID
 			pending.documents = documents
 			for docIdx, document := range documents {
@@ -281,6 +283,7 @@ func cloneIncrementalSourceItem(item *sourcebindingentity.SourceItem) *sourcebin
 func cloneResolvedDocument(document ResolvedDocument) ResolvedDocument {
 	cloned := document
 	cloned.SnapshotMeta = cloneObjectMap(document.SnapshotMeta)
+	cloned.DocMetadata = cloneObjectMap(document.DocMetadata)
 	return cloned
 }
 
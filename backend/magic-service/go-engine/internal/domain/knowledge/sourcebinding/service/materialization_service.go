@@ -27,6 +27,9 @@ type ResolvedDocument struct {
 	ResolveReason string
 	AutoAdded     bool
 	SnapshotMeta  map[string]any
+	DocMetadata   map[string]any
+	// SkipReason 非空时只记录来源项状态，不创建托管文档（如压缩包中不支持的条目）。
+	SkipReason string
 }
 
 // CreateManagedDocumentInput 描述创建托管文档的最小输入。
@@ -40,6 +43,7 @@ type CreateManagedDocumentInput struct {
 	ProjectFileID     int64
 	Name              string
 	DocType           int
+	DocMetadata       map[string]any
 	DocumentFile      any
 	ThirdPlatformType string
 	ThirdFileID       string
@@ -56,6 +60,22 @@ type ManagedDocument struct {
 type MaterializationReport struct {
 	CreatedDocuments []*ManagedDocument
 	PendingSyncs     []SyncRequest
+	SkippedItems     []SkippedItem
+}
+
+// SkippedItem 表示解析出但未物化为文档的来源项。
+type SkippedItem struct {
+	BindingID int64
+	ItemRef   string
+	Name      string
+	Reason    string
+}
+
+// MaterializationProgress 表示单个 binding 的物化进度。
+type MaterializationProgress struct {
+	BindingID int64
+	Processed int
+	Total     int
 }
 
 // SyncRequest 表示需要触发的文档同步请求。
@@ -76,6 +96,8 @@ type MaterializationInput struct {
 	Bindings            []sourcebindingentity.Binding
 	MaxDocuments        int
 	ScheduleSync        bool
+	// Progress 可选，每处理完一个来源项回调一次，用于大批量展开时汇报进度。
+	Progress func(MaterializationProgress)
 }
 
 // DocumentResolver 定义 binding -> 文档物料的解析能力。
@@ -146,7 +168,8 @@ func (s *MaterializationService) Preflight(ctx context.Context, input Materializ
 		if err != nil {
 			return fmt.Errorf("resolve source binding documents: %w", err)
 		}
-		remaining -= minInt(len(items), remaining)
+		materializable, _ := partitionResolvedDocuments(items)
+		remaining -= minInt(len(materializable), remaining)
 	}
 	return nil
 }
@@ -167,6 +190,7 @@ func (s *MaterializationService) MaterializeWithReport(
 ) (MaterializationReport, error) {
 	createdDocuments := make([]*ManagedDocument, 0)
 	pendingSyncs := make([]SyncRequest, 0)
+	skippedItems := make([]SkippedItem, 0)
 	remaining := normalizeMaterializationDocumentLimit(input.MaxDocuments)
 
 	for _, binding := range input.Bindings {
@@ -177,7 +201,7 @@ func (s *MaterializationService) MaterializeWithReport(
 			break
 		}
 
-		bindingCreated, bindingSyncs, err := s.materializeBinding(ctx, input, binding, remaining)
+		bindingResult, err := s.materializeBinding(ctx, input, binding, remaining)
 		if err != nil {
 			return MaterializationReport{}, s.rollbackMaterializedDocuments(
 				ctx,
@@ -187,9 +211,10 @@ func (s *MaterializationService) MaterializeWithReport(
 			)
 		}
 
-		createdDocuments = append(createdDocuments, bindingCreated...)
-		pendingSyncs = append(pendingSyncs, bindingSyncs...)
-		remaining -= len(bindingCreated)
+		createdDocuments = append(createdDocuments, bindingResult.createdDocuments...)
+		pendingSyncs = append(pendingSyncs, bindingResult.pendingSyncs...)
+		skippedItems = append(skippedItems, bindingResult.skippedItems...)
+		remaining -= len(bindingResult.createdDocuments)
 	}
 
 	if input.ScheduleSync {
@@ -201,26 +226,38 @@ func (s *MaterializationService) MaterializeWithReport(
 	return MaterializationReport{
 		CreatedDocuments: append([]*ManagedDocument(nil), createdDocuments...),
 		PendingSyncs:     append([]SyncRequest(nil), pendingSyncs...),
+		SkippedItems:     append([]SkippedItem(nil), skippedItems...),
 	}, nil
 }
 
+type bindingMaterializationResult struct {
+	createdDocuments []*ManagedDocument
+	pendingSyncs     []SyncRequest
+	skippedItems     []SkippedItem
+}
+
 func (s *MaterializationService) materializeBinding(
 	ctx context.Context,
 	input MaterializationInput,
 	binding sourcebindingentity.Binding,
 	maxDocuments int,
-) ([]*ManagedDocument, []SyncRequest, error) {
-	resolvedUserID, items, err := s.resolveBindingDocumentsWithUser(ctx, input, binding, maxDocuments)
+) (bindingMaterializationResult, error) {
+	resolvedUserID, resolved, err := s.resolveBindingDocumentsWithUser(ctx, input, binding, maxDocuments)
 	if err != nil {
-		return nil, nil, fmt.Errorf("resolve source binding documents: %w", err)
+		return bindingMaterializationResult{}, fmt.Errorf("resolve source binding documents: %w", err)
 	}
+	items, skipped := partitionResolvedDocuments(resolved)
 	if maxDocuments > 0 && len(items) > maxDocuments {
 		items = append([]ResolvedDocument(nil), items[:maxDocuments]...)
 	}
 
-	createdDocuments := make([]*ManagedDocument, 0, len(items))
-	pendingSyncs := make([]SyncRequest, 0, len(items))
+	result := bindingMaterializationResult{
+		createdDocuments: make([]*ManagedDocument, 0, len(items)),
+		pendingSyncs:     make([]SyncRequest, 0, len(items)),
+		skippedItems:     make([]SkippedItem, 0, len(skipped)),
+	}
 	bindingItems := make([]sourcebindingentity.BindingItem, 0, len(items))
+	total := len(items) + len(skipped)
 
 	for _, item := range items {
 		createdDocument, bindingItem, syncRequest, itemErr := s.materializeResolvedDocument(
@@ -231,18 +268,31 @@ func (s *MaterializationService) materializeBinding(
 			item,
 		)
 		if itemErr != nil {
-			return createdDocuments, pendingSyncs, itemErr
+			return result, itemErr
 		}
 
 		bindingItems = append(bindingItems, bindingItem)
-		createdDocuments = append(createdDocuments, createdDocument)
-		pendingSyncs = append(pendingSyncs, syncRequest)
+		result.createdDocuments = append(result.createdDocuments, createdDocument)
+		result.pendingSyncs = append(result.pendingSyncs, syncRequest)
+		reportMaterializationProgress(input, binding, len(result.createdDocuments), total)
+	}
+	for _, item := range skipped {
+		if _, err := s.repo.UpsertSourceItem(ctx, s.buildSourceItem(input, binding, item)); err != nil {
+			return result, fmt.Errorf("upsert skipped source item: %w", err)
+		}
+		result.skippedItems = append(result.skippedItems, SkippedItem{
+			BindingID: binding.ID,
+			ItemRef:   item.ItemRef,
+			Name:      item.Name,
+			Reason:    item.SkipReason,
+		})
+		reportMaterializationProgress(input, binding, len(result.createdDocuments)+len(result.skippedItems), total)
 	}
 
 	if err := s.repo.ReplaceBindingItems(ctx, binding.ID, bindingItems); err != nil {
-		return createdDocuments, pendingSyncs, fmt.Errorf("replace source binding items: %w", err)
+		return result, fmt.Errorf("replace source binding items: %w", err)
 	}
-	return createdDocuments, pendingSyncs, nil
+	return result, nil
 }
 
 func (s *MaterializationService) materializeResolvedDocument(
@@ -252,20 +302,8 @@ func (s *MaterializationService) materializeResolvedDocument(
 	resolvedUserID string,
 	item ResolvedDocument,
 ) (*ManagedDocument, sourcebindingentity.BindingItem, SyncRequest, error) {
-	resolvedAt := s.now()
-	sourceItem, err := s.repo.UpsertSourceItem(ctx, sourcebindingentity.SourceItem{
-		OrganizationCode: strings.TrimSpace(input.OrganizationCode),
-		Provider:         binding.Provider,
-		RootType:         binding.RootType,
-		RootRef:          binding.RootRef,
-		GroupRef:         item.GroupRef,
-		ItemType:         sourcebindingentity.RootTypeFile,
-		ItemRef:          item.ItemRef,
-		DisplayName:      item.Name,
-		Extension:        item.Extension,
-		SnapshotMeta:     cloneMaterializationMap(item.SnapshotMeta),
-		LastResolvedAt:   &resolvedAt,
-	})
+	sourceItemInput := s.buildSourceItem(input, binding, item)
+	sourceItem, err := s.repo.UpsertSourceItem(ctx, sourceItemInput)
 	if err != nil {
 		return nil, sourcebindingentity.BindingItem{}, SyncRequest{}, fmt.Errorf("upsert source item: %w", err)
 	}
@@ -280,6 +318,7 @@ func (s *MaterializationService) materializeResolvedDocument(
 		ProjectFileID:     item.ProjectFileID,
 		Name:              item.Name,
 		DocType:           item.DocumentType,
+		DocMetadata:       cloneMaterializationMap(item.DocMetadata),
 		DocumentFile:      item.DocumentFile,
 		ThirdPlatformType: materializedThirdPlatformType(binding.Provider),
 		ThirdFileID:       strings.TrimSpace(sourceItem.ItemRef),
@@ -291,16 +330,58 @@ func (s *MaterializationService) materializeResolvedDocument(
 	}
 
 	return createdDocument, sourcebindingentity.BindingItem{
-			BindingID:      binding.ID,
-			SourceItemID:   sourceItem.ID,
-			ResolveReason:  item.ResolveReason,
-			LastResolvedAt: &resolvedAt,
-		}, SyncRequest{
-			OrganizationCode:  input.OrganizationCode,
-			KnowledgeBaseCode: input.KnowledgeBaseCode,
-			Code:              createdDocument.Code,
-			UserID:            resolvedUserID,
-		}, nil
+		BindingID:      binding.ID,
+		SourceItemID:   sourceItem.ID,
+		ResolveReason:  item.ResolveReason,
+		LastResolvedAt: sourceItemInput.LastResolvedAt,
+	}, SyncRequest{
+		OrganizationCode:  input.OrganizationCode,
+		KnowledgeBaseCode: input.KnowledgeBaseCode,
+		Code:              createdDocument.Code,
+		UserID:            resolvedUserID,
+	}, nil
+}
+
+func (s *MaterializationService) buildSourceItem(
+	input MaterializationInput,
+	binding sourcebindingentity.Binding,
+	item ResolvedDocument,
+) sourcebindingentity.SourceItem {
+	resolvedAt := s.now()
+	return sourcebindingentity.SourceItem{
+		OrganizationCode: strings.TrimSpace(input.OrganizationCode),
+		Provider:         binding.Provider,
+		RootType:         binding.RootType,
+		RootRef:          binding.RootRef,
+		GroupRef:         item.GroupRef,
+		ItemType:         sourcebindingentity.RootTypeFile,
+		ItemRef:          item.ItemRef,
+		DisplayName:      item.Name,
+		Extension:        item.Extension,
+		SnapshotMeta:     cloneMaterializationMap(item.SnapshotMeta),
+		LastResolvedAt:   &resolvedAt,
+	}
+}
+
+// partitionResolvedDocuments 拆分需要创建文档的物料与只记录状态的跳过项。
+func partitionResolvedDocuments(items []ResolvedDocument) ([]ResolvedDocument, []ResolvedDocument) {
+	materializable := make([]ResolvedDocument, 0, len(items))
+	skipped := make([]ResolvedDocument, 0)
+	for _, item := range items {
+		if strings.TrimSpace(item.SkipReason) != "" {
+			skipped = append(skipped, item)
+			continue
+		}
+		materializable = append(materializable, item)
+	}
+	return materializable, skipped
+}
+
+func reportMaterializationProgress(input MaterializationInput, binding sourcebindingentity.Binding, processed, total int) {
+	if input.Progress == nil {
+		return
+	}
+	input.Progress(MaterializationProgress{BindingID: binding.ID, Processed: processed, Total: total})
 }
 
 // ShouldRetryResolve 判断当前错误是否应尝试切换候选操作者重试。
@@ -389,7 +470,8 @@ func cloneMaterializationMap(input map[string]any) map[string]any {
 
 func materializedThirdPlatformType(provider string) string {
 	switch strings.TrimSpace(provider) {
-	case "", sourcebindingentity.ProviderLocalUpload, sourcebindingentity.ProviderProject:
+	case "", sourcebindingentity.ProviderLocalUpload, sourcebindingentity.ProviderProject, sourcebindingentity.ProviderWebsite,
+		sourcebindingentity.ProviderGit, sourcebindingentity.ProviderObjectStorage, sourcebindingentity.ProviderWebhook:
 		return ""
 	default:
 		return strings.ToLower(strings.TrimSpace(provider))
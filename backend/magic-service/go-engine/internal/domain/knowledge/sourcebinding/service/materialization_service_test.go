@@ -230,3 +230,56 @@ func TestMaterializationServiceMaterializeRespectsKnowledgeBaseDocumentLimit(t *
 		t.Fatalf("expected only one binding item replace call, got %#v", repo.replaceCalls)
 	}
 }
+
+func TestMaterializationServiceRecordsSkippedItemsAndReportsProgress(t *testing.T) {
+	t.Parallel()
+
+	resolver := &materializationResolverStub{
+		resultsByUser: map[string][]sourcebindingservice.ResolvedDocument{
+			testBindingUpdater: {
+				{Name: "a.md", DocumentType: 1, ItemRef: "batch.zip!/docs/a.md", GroupRef: "docs", DocMetadata: map[string]any{"archive_entry_path": "docs/a.md"}},
+				{Name: "setup.exe", ItemRef: "batch.zip!/tools/setup.exe", GroupRef: "tools", SkipReason: "unsupported_extension"},
+				{Name: "b.md", DocumentType: 1, ItemRef: "batch.zip!/b.md"},
+			},
+		},
+	}
+	repo := &materializationRepoStub{}
+	docManager := &managedDocumentManagerStub{}
+	svc := sourcebindingservice.NewMaterializationService(repo, resolver, docManager, nil)
+
+	progress := make([]sourcebindingservice.MaterializationProgress, 0)
+	report, err := svc.MaterializeWithReport(context.Background(), sourcebindingservice.MaterializationInput{
+		KnowledgeBaseCode: "KB-1",
+		OrganizationCode:  "ORG-1",
+		Bindings: []sourcebinding.Binding{{
+			ID:         21,
+			Provider:   sourcebinding.ProviderLocalUpload,
+			RootType:   sourcebinding.RootTypeUploadBatch,
+			RootRef:    "batch.zip",
+			Enabled:    true,
+			UpdatedUID: testBindingUpdater,
+		}},
+		Progress: func(update sourcebindingservice.MaterializationProgress) {
+			progress = append(progress, update)
+		},
+	})
+	if err != nil {
+		t.Fatalf("MaterializeWithReport returned error: %v", err)
+	}
+	if len(report.CreatedDocuments) != 2 || len(docManager.created) != 2 {
+		t.Fatalf("expected two documents, got %#v", docManager.created)
+	}
+	if docManager.created[0].DocMetadata["archive_entry_path"] != "docs/a.md" {
+		t.Fatalf("expected doc metadata to be forwarded, got %#v", docManager.created[0].DocMetadata)
+	}
+	if len(report.SkippedItems) != 1 || report.SkippedItems[0].Reason != "unsupported_extension" ||
+		report.SkippedItems[0].BindingID != 21 || report.SkippedItems[0].ItemRef != "batch.zip!/tools/setup.exe" {
+		t.Fatalf("unexpected skipped items: %#v", report.SkippedItems)
+	}
+	if len(repo.replaceCalls) != 1 || len(repo.replaceCalls[0]) != 2 {
+		t.Fatalf("expected skipped item to stay out of binding items, got %#v", repo.replaceCalls)
+	}
+	if len(progress) != 3 || progress[2].Processed != 3 || progress[2].Total != 3 {
+		t.Fatalf("unexpected progress updates: %#v", progress)
+	}
+}
@@ -0,0 +1,206 @@
+package sourcebinding
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
+)
+
+const (
+	objectStorageSyncConfigIncludePatterns = "include_patterns"
+	objectStorageSyncConfigExcludePatterns = "exclude_patterns"
+	objectStorageSyncConfigMaxObjectBytes  = "max_object_bytes"
+	objectStorageSyncConfigPollMinutes     = "poll_interval_minutes"
+
+	defaultObjectStorageMaxObjectBytes = 20 << 20
+	maxObjectStorageMaxObjectBytes     = 200 << 20
+	defaultObjectStoragePollMinutes    = 15
+	minObjectStoragePollMinutes        = 1
+	maxObjectStoragePollMinutes        = 7 * 24 * 60
+
+	objectStorageURIScheme = "s3://"
+)
+
+const (
+	// ObjectStorageSnapshotMetaBucket 表示对象所在的桶。
+	ObjectStorageSnapshotMetaBucket = "bucket"
+	// ObjectStorageSnapshotMetaKey 表示对象键。
+	ObjectStorageSnapshotMetaKey = "key"
+	// ObjectStorageSnapshotMetaETag 表示对象的 ETag，用于判断内容是否变化。
+	ObjectStorageSnapshotMetaETag = "etag"
+	// ObjectStorageSnapshotMetaSize 表示对象字节数。
+	ObjectStorageSnapshotMetaSize = "size"
+	// ObjectStorageSnapshotMetaLastModified 表示对象最后修改时间（RFC3339）。
+	ObjectStorageSnapshotMetaLastModified = "last_modified"
+)
+
+const (
+	// ObjectStorageSkipTooLarge 表示对象超过 max_object_bytes。
+	ObjectStorageSkipTooLarge = "object_too_large"
+	// ObjectStorageSkipObjectLimit 表示对象超出单次物化的文档上限。
+	ObjectStorageSkipObjectLimit = "object_limit_exceeded"
+)
+
+// ErrInvalidObjectStorageBucket 表示桶名不符合 S3 命名规则。
+var ErrInvalidObjectStorageBucket = errors.New("invalid object storage bucket")
+
+// objectStorageBucketPattern 按 S3 桶命名规则校验：3-63 位小写字母、数字、点与短横线。
+var objectStorageBucketPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// ObjectStorageSourceConfig 表示对象存储绑定的桶/前缀配置，由 binding.root_ref 与 sync_config 解析而来。
+type ObjectStorageSourceConfig struct {
+	Bucket          string
+	Prefix          string
+	IncludePatterns []string
+	ExcludePatterns []string
+	MaxObjectBytes  int64
+	PollInterval    time.Duration
+
+	includeMatchers []*regexp.Regexp
+	excludeMatchers []*regexp.Regexp
+}
+
+// ParseObjectStorageSourceConfig 从对象存储绑定解析桶/前缀配置并补齐默认值。
+//
+// root_ref 支持 "bucket"、"bucket/prefix" 与 "s3://bucket/prefix" 三种写法。
+func ParseObjectStorageSourceConfig(binding sourcebindingentity.Binding) (ObjectStorageSourceConfig, error) {
+	bucket, prefix, err := SplitObjectStorageRootRef(binding.RootRef)
+	if err != nil {
+		return ObjectStorageSourceConfig{}, fmt.Errorf("%w: root_ref: %w", sourcebindingentity.ErrInvalidObjectStorageSourceConfig, err)
+	}
+	syncConfig := binding.SyncConfig
+	pollMinutes := sourcebindingentity.ClampSyncConfigInt(
+		syncConfig[objectStorageSyncConfigPollMinutes],
+		defaultObjectStoragePollMinutes,
+		minObjectStoragePollMinutes,
+		maxObjectStoragePollMinutes,
+	)
+	config := ObjectStorageSourceConfig{
+		Bucket:          bucket,
+		Prefix:          prefix,
+		IncludePatterns: sourcebindingentity.SyncConfigStrings(syncConfig[objectStorageSyncConfigIncludePatterns]),
+		ExcludePatterns: sourcebindingentity.SyncConfigStrings(syncConfig[objectStorageSyncConfigExcludePatterns]),
+		MaxObjectBytes: int64(sourcebindingentity.ClampSyncConfigInt(
+			syncConfig[objectStorageSyncConfigMaxObjectBytes],
+			defaultObjectStorageMaxObjectBytes,
+			1,
+			maxObjectStorageMaxObjectBytes,
+		)),
+		PollInterval: time.Duration(pollMinutes) * time.Minute,
+	}
+	if config.includeMatchers, err = sourcebindingentity.CompilePathGlobs(config.IncludePatterns); err != nil {
+		return ObjectStorageSourceConfig{}, fmt.Errorf("%w: %w", sourcebindingentity.ErrInvalidObjectStorageSourceConfig, err)
+	}
+	if config.excludeMatchers, err = sourcebindingentity.CompilePathGlobs(config.ExcludePatterns); err != nil {
+		return ObjectStorageSourceConfig{}, fmt.Errorf("%w: %w", sourcebindingentity.ErrInvalidObjectStorageSourceConfig, err)
+	}
+	return config, nil
+}
+
+// SplitObjectStorageRootRef 把 root_ref 拆成桶名与前缀；前缀去掉开头的 "/"，保留调用方写的结尾 "/"。
+func SplitObjectStorageRootRef(rootRef string) (string, string, error) {
+	trimmed := strings.TrimSpace(rootRef)
+	trimmed = strings.TrimPrefix(trimmed, objectStorageURIScheme)
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(trimmed, "/"), "/")
+	if !objectStorageBucketPattern.MatchString(bucket) || strings.Contains(bucket, "..") {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidObjectStorageBucket, bucket)
+	}
+	return bucket, strings.TrimLeft(prefix, "/"), nil
+}
+
+// RootRef 返回规范化后的 root_ref，形如 "bucket/prefix"。
+func (c ObjectStorageSourceConfig) RootRef() string {
+	if c.Prefix == "" {
+		return c.Bucket
+	}
+	return c.Bucket + "/" + c.Prefix
+}
+
+// AllowsKey 判断对象是否在同步范围内：以 "/" 结尾的目录占位对象不同步，
+// include/exclude 规则按相对前缀的路径匹配。
+func (c ObjectStorageSourceConfig) AllowsKey(key string) bool {
+	if key == "" || strings.HasSuffix(key, "/") || !strings.HasPrefix(key, c.Prefix) {
+		return false
+	}
+	relative := strings.TrimPrefix(strings.TrimPrefix(key, c.Prefix), "/")
+	if len(c.includeMatchers) > 0 && !sourcebindingentity.MatchAnyPathGlob(c.includeMatchers, relative) {
+		return false
+	}
+	return !sourcebindingentity.MatchAnyPathGlob(c.excludeMatchers, relative)
+}
+
+// PollDue 判断实时对象存储绑定是否到了重新列举的时间。
+func (c ObjectStorageSourceConfig) PollDue(lastPolledAt *time.Time, now time.Time) bool {
+	if lastPolledAt == nil || lastPolledAt.IsZero() {
+		return true
+	}
+	interval := c.PollInterval
+	if interval <= 0 {
+		interval = time.Duration(defaultObjectStoragePollMinutes) * time.Minute
+	}
+	return !now.Before(lastPolledAt.Add(interval))
+}
+
+// ObjectStorageItemRef 返回对象来源项的 item_ref，形如 "bucket/reports/2026/q1.csv"。
+//
+// 来源项按组织与 provider 全局唯一，因此需要带上桶名。
+func ObjectStorageItemRef(bucket, key string) string {
+	return bucket + "/" + key
+}
+
+// ObjectStorageGroupRef 返回对象所在的“目录”；位于桶根的对象返回空串。
+func ObjectStorageGroupRef(key string) string {
+	dir := path.Dir(key)
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	return dir
+}
+
+// BuildObjectStorageSnapshotMeta 构造对象来源项的快照元数据。
+func BuildObjectStorageSnapshotMeta(bucket string, object sourcebindingentity.ObjectStorageObject) map[string]any {
+	snapshot := map[string]any{
+		ObjectStorageSnapshotMetaBucket: bucket,
+		ObjectStorageSnapshotMetaKey:    object.Key,
+		ObjectStorageSnapshotMetaETag:   sourcebindingentity.NormalizeObjectStorageETag(object.ETag),
+		ObjectStorageSnapshotMetaSize:   object.Size,
+	}
+	if !object.LastModified.IsZero() {
+		snapshot[ObjectStorageSnapshotMetaLastModified] = object.LastModified.UTC().Format(time.RFC3339)
+	}
+	return snapshot
+}
+
+// ObjectStorageETagFromSnapshot 从来源项快照中读取对象 ETag。
+func ObjectStorageETagFromSnapshot(snapshotMeta map[string]any) string {
+	etag, _ := snapshotMeta[ObjectStorageSnapshotMetaETag].(string)
+	return sourcebindingentity.NormalizeObjectStorageETag(etag)
+}
+
+// ObjectStorageChangedKeys 按 ETag 找出内容变化、新增或已删除的对象键。
+//
+// previousETags 以对象键为键；未出现在本次列举结果中的旧对象同样视为变化（已删除）。
+func ObjectStorageChangedKeys(previousETags map[string]string, objects []sourcebindingentity.ObjectStorageObject) []string {
+	changed := make([]string, 0)
+	current := make(map[string]struct{}, len(objects))
+	for _, object := range objects {
+		current[object.Key] = struct{}{}
+		previous, exists := previousETags[object.Key]
+		if !exists || sourcebindingentity.NormalizeObjectStorageETag(previous) != sourcebindingentity.NormalizeObjectStorageETag(object.ETag) {
+			changed = append(changed, object.Key)
+		}
+	}
+	for key := range previousETags {
+		if _, exists := current[key]; !exists {
+			changed = append(changed, key)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
@@ -0,0 +1,132 @@
+package sourcebinding_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	sourcebinding "magic/internal/domain/knowledge/sourcebinding/entity"
+	sourcebindingservice "magic/internal/domain/knowledge/sourcebinding/service"
+)
+
+func TestParseObjectStorageSourceConfigAppliesDefaultsAndScope(t *testing.T) {
+	t.Parallel()
+
+	config, err := sourcebindingservice.ParseObjectStorageSourceConfig(sourcebinding.Binding{
+		Provider: sourcebinding.ProviderObjectStorage,
+		RootType: sourcebinding.RootTypeBucketPrefix,
+		RootRef:  " s3://data-exports/reports/ ",
+		SyncConfig: map[string]any{
+			"include_patterns":      []any{"**.csv", "*.pdf"},
+			"exclude_patterns":      "tmp/**",
+			"max_object_bytes":      1 << 40,
+			"poll_interval_minutes": 0,
+		},
+	})
+	if err != nil {
+		t.Fatalf("parse object storage source config: %v", err)
+	}
+	if config.Bucket != "data-exports" || config.Prefix != "reports/" || config.RootRef() != "data-exports/reports/" ||
+		config.MaxObjectBytes != 200<<20 || config.PollInterval != time.Minute {
+		t.Fatalf("unexpected config: %#v", config)
+	}
+	cases := map[string]bool{
+		"reports/2026/q1.csv":  true,
+		"reports/summary.pdf":  true,
+		"reports/2026/a.pdf":   false,
+		"reports/tmp/x.csv":    false,
+		"reports/2026/":        false,
+		"other/2026/q1.csv":    false,
+		"reports/readme.plain": false,
+	}
+	for key, expected := range cases {
+		if got := config.AllowsKey(key); got != expected {
+			t.Fatalf("AllowsKey(%q) = %v, want %v", key, got, expected)
+		}
+	}
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	last := now.Add(-30 * time.Second)
+	if !config.PollDue(nil, now) || config.PollDue(&last, now) || !config.PollDue(&last, now.Add(time.Minute)) {
+		t.Fatal("unexpected poll due decisions")
+	}
+}
+
+func TestSplitObjectStorageRootRef(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string][2]string{
+		"reports":                 {"reports", ""},
+		"reports/2026/":           {"reports", "2026/"},
+		"s3://reports//2026/june": {"reports", "2026/june"},
+	}
+	for rootRef, expected := range cases {
+		bucket, prefix, err := sourcebindingservice.SplitObjectStorageRootRef(rootRef)
+		if err != nil || bucket != expected[0] || prefix != expected[1] {
+			t.Fatalf("SplitObjectStorageRootRef(%q) = %q, %q, %v; want %v", rootRef, bucket, prefix, err, expected)
+		}
+	}
+	for _, rootRef := range []string{"", "ab", "Reports/2026", "-reports", "bad..bucket/x"} {
+		if _, _, err := sourcebindingservice.SplitObjectStorageRootRef(rootRef); !errors.Is(err, sourcebindingservice.ErrInvalidObjectStorageBucket) {
+			t.Fatalf("expected ErrInvalidObjectStorageBucket for %q, got %v", rootRef, err)
+		}
+	}
+}
+
+func TestValidateBindingsObjectStorageRejectsInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	err := sourcebindingservice.ValidateBindings(sourcebinding.SemanticLegacy, []sourcebinding.Binding{{
+		Provider: sourcebinding.ProviderObjectStorage,
+		RootType: sourcebinding.RootTypeBucketPrefix,
+		RootRef:  "UPPER/reports",
+		SyncMode: sourcebinding.SyncModeRealtime,
+	}})
+	if !errors.Is(err, sourcebinding.ErrInvalidObjectStorageSourceConfig) {
+		t.Fatalf("expected ErrInvalidObjectStorageSourceConfig, got %v", err)
+	}
+
+	err = sourcebindingservice.ValidateBindings(sourcebinding.SemanticLegacy, []sourcebinding.Binding{{
+		Provider: sourcebinding.ProviderObjectStorage,
+		RootType: sourcebinding.RootTypeRepository,
+		RootRef:  "reports",
+		SyncMode: sourcebinding.SyncModeRealtime,
+	}})
+	if !errors.Is(err, sourcebinding.ErrSemanticMismatch) {
+		t.Fatalf("expected ErrSemanticMismatch, got %v", err)
+	}
+}
+
+func TestObjectStorageChangedKeysAndSnapshot(t *testing.T) {
+	t.Parallel()
+
+	changed := sourcebindingservice.ObjectStorageChangedKeys(
+		map[string]string{"a.csv": "etag-a", "b.csv": `"etag-b1"`, "gone.csv": "etag-g"},
+		[]sourcebinding.ObjectStorageObject{
+			{Key: "a.csv", ETag: `"etag-a"`},
+			{Key: "b.csv", ETag: `"etag-b2"`},
+			{Key: "new.csv", ETag: `"etag-n"`},
+		},
+	)
+	if !slices.Equal(changed, []string{"b.csv", "gone.csv", "new.csv"}) {
+		t.Fatalf("unexpected changed keys: %v", changed)
+	}
+
+	snapshot := sourcebindingservice.BuildObjectStorageSnapshotMeta("reports", sourcebinding.ObjectStorageObject{
+		Key:          "2026/q1.csv",
+		ETag:         `"abc-2"`,
+		Size:         42,
+		LastModified: time.Date(2026, 4, 1, 8, 0, 0, 0, time.FixedZone("CST", 8*3600)),
+	})
+	if sourcebindingservice.ObjectStorageETagFromSnapshot(snapshot) != "abc-2" ||
+		snapshot[sourcebindingservice.ObjectStorageSnapshotMetaLastModified] != "2026-04-01T00:00:00Z" {
+		t.Fatalf("unexpected snapshot: %#v", snapshot)
+	}
+	if got := sourcebindingservice.ObjectStorageItemRef("reports", "2026/q1.csv"); got != "reports/2026/q1.csv" {
+		t.Fatalf("unexpected item ref %q", got)
+	}
+	if got := sourcebindingservice.ObjectStorageGroupRef("2026/q1.csv"); got != "2026" {
+		t.Fatalf("unexpected group ref %q", got)
+	}
+}
@@ -66,10 +66,42 @@ func validateLegacyBinding(idx int, binding sourcebindingentity.Binding) error {
 
 	switch provider {
 	case sourcebindingentity.ProviderLocalUpload:
-		if rootType != sourcebindingentity.RootTypeFile {
+		if rootType != sourcebindingentity.RootTypeFile && rootType != sourcebindingentity.RootTypeUploadBatch {
 			return fmt.Errorf("%w: source_bindings[%d]", sourcebindingentity.ErrSemanticMismatch, idx)
 		}
 		return nil
+	case sourcebindingentity.ProviderWebsite:
+		if rootType != sourcebindingentity.RootTypeSite {
+			return fmt.Errorf("%w: source_bindings[%d]", sourcebindingentity.ErrSemanticMismatch, idx)
+		}
+		if _, err := sourcebindingentity.ParseWebsiteCrawlConfig(binding); err != nil {
+			return fmt.Errorf("source_bindings[%d].sync_config: %w", idx, err)
+		}
+		return nil
+	case sourcebindingentity.ProviderGit:
+		if rootType != sourcebindingentity.RootTypeRepository {
+			return fmt.Errorf("%w: source_bindings[%d]", sourcebindingentity.ErrSemanticMismatch, idx)
+		}
+		if _, err := ParseGitSourceConfig(binding); err != nil {
+			return fmt.Errorf("source_bindings[%d].sync_config: %w", idx, err)
+		}
+		return nil
+	case sourcebindingentity.ProviderObjectStorage:
+		if rootType != sourcebindingentity.RootTypeBucketPrefix {
+			return fmt.Errorf("%w: source_bindings[%d]", sourcebindingentity.ErrSemanticMismatch, idx)
+		}
+		if _, err := ParseObjectStorageSourceConfig(binding); err != nil {
+			return fmt.Errorf("source_bindings[%d].sync_config: %w", idx, err)
+		}
+		return nil
+	case sourcebindingentity.ProviderWebhook:
+		if rootType != sourcebindingentity.RootTypeChannel {
+			return fmt.Errorf("%w: source_bindings[%d]", sourcebindingentity.ErrSemanticMismatch, idx)
+		}
+		if _, err := ParseWebhookSourceConfig(binding); err != nil {
+			return fmt.Errorf("source_bindings[%d].sync_config: %w", idx, err)
+		}
+		return nil
 	case sourcebindingentity.ProviderTeamshare:
 		if rootType != sourcebindingentity.RootTypeFile && rootType != sourcebindingentity.RootTypeKnowledgeBase {
 			return fmt.Errorf("%w: source_bindings[%d]", sourcebindingentity.ErrSemanticMismatch, idx)
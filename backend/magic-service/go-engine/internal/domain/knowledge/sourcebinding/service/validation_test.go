@@ -79,3 +79,25 @@ func TestValidateBindingsRejectsDuplicateRoot(t *testing.T) {
 		t.Fatalf("expected ErrDuplicateBindingRoot, got %v", err)
 	}
 }
+
+func TestValidateBindingsAcceptsLocalUploadBatch(t *testing.T) {
+	t.Parallel()
+
+	err := sourcebindingservice.ValidateBindings(sourcebinding.SemanticLegacy, []sourcebinding.Binding{{
+		Provider: sourcebinding.ProviderLocalUpload,
+		RootType: sourcebinding.RootTypeUploadBatch,
+		RootRef:  "uploads/batch.zip",
+	}})
+	if err != nil {
+		t.Fatalf("expected upload batch binding to be valid, got %v", err)
+	}
+
+	err = sourcebindingservice.ValidateBindings(sourcebinding.SemanticLegacy, []sourcebinding.Binding{{
+		Provider: sourcebinding.ProviderLocalUpload,
+		RootType: sourcebinding.RootTypeProject,
+		RootRef:  "uploads/batch.zip",
+	}})
+	if !errors.Is(err, sourcebinding.ErrSemanticMismatch) {
+		t.Fatalf("expected ErrSemanticMismatch, got %v", err)
+	}
+}
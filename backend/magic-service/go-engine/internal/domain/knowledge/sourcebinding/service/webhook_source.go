@@ -0,0 +1,272 @@
+package sourcebinding
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
+	"magic/internal/pkg/filetype"
+)
+
+const (
+	webhookSyncConfigSigningSecret = "signing_secret"
+	webhookSyncConfigCallbackURL   = "callback_url"
+
+	minWebhookSigningSecretLength = 16
+	defaultWebhookInlineExtension = "md"
+	maxWebhookExternalIDLength    = 255
+
+	// WebhookSignatureTolerance 是签名时间戳与服务端时间允许的最大偏差，超出即视为重放。
+	WebhookSignatureTolerance = 5 * time.Minute
+	// MaxWebhookEventsPerRequest 是单次推送允许携带的事件数上限。
+	MaxWebhookEventsPerRequest = 100
+	// MaxWebhookInlineContentBytes 是单个事件内联正文的字节上限，更大的内容需要改用下载地址。
+	MaxWebhookInlineContentBytes = 5 << 20
+)
+
+const (
+	// WebhookActionUpsert 表示新增或更新外部条目。
+	WebhookActionUpsert = "upsert"
+	// WebhookActionDelete 表示删除外部条目。
+	WebhookActionDelete = "delete"
+)
+
+const (
+	// WebhookSnapshotMetaExternalID 表示外部系统中的条目 ID。
+	WebhookSnapshotMetaExternalID = "external_id"
+	// WebhookSnapshotMetaContentHash 表示最近一次推送的内容哈希，用于去重。
+	WebhookSnapshotMetaContentHash = "content_hash"
+	// WebhookSnapshotMetaURL 表示最近一次推送的下载地址；内联正文为空。
+	WebhookSnapshotMetaURL = "url"
+)
+
+var (
+	// ErrWebhookSignatureInvalid 表示推送请求签名缺失或不匹配。
+	ErrWebhookSignatureInvalid = errors.New("webhook signature is invalid")
+	// ErrWebhookTimestampExpired 表示推送请求时间戳缺失或超出允许偏差。
+	ErrWebhookTimestampExpired = errors.New("webhook timestamp is missing or expired")
+	// ErrInvalidWebhookEvent 表示推送事件格式非法。
+	ErrInvalidWebhookEvent = errors.New("invalid webhook event")
+)
+
+// webhookChannelPattern 限制通道名只包含小写字母、数字、短横线与下划线，便于直接放进 URL 路径。
+var webhookChannelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// WebhookSourceConfig 表示 webhook 推送绑定的配置，由 binding.root_ref 与 sync_config 解析而来。
+type WebhookSourceConfig struct {
+	Channel       string
+	SigningSecret string
+	CallbackURL   string
+}
+
+// WebhookEvent 表示外部系统推送的一条 upsert/delete 事件。
+//
+// upsert 事件必须且只能携带 Content（内联正文）或 URL（下载地址）之一。
+// URL 与普通 URL 文档走同一文件下载链路，这里只校验 http(s) 协议与主机名，
+// 不拦截内网地址；SSRF 防护依赖部署侧的出口网络策略。
+type WebhookEvent struct {
+	Action      string
+	ExternalID  string
+	Name        string
+	Content     string
+	URL         string
+	Extension   string
+	ContentHash string
+}
+
+type webhookEventBatchPayload struct {
+	Events []webhookEventPayload `json:"events"`
+}
+
+type webhookEventPayload struct {
+	Action      string `json:"action"`
+	ExternalID  string `json:"external_id"`
+	Name        string `json:"name"`
+	Content     string `json:"content"`
+	URL         string `json:"url"`
+	Extension   string `json:"extension"`
+	ContentHash string `json:"content_hash"`
+}
+
+// ParseWebhookSourceConfig 从 webhook 绑定解析通道与签名配置。
+//
+// callback_url 只校验 http(s) 协议与主机名，不解析也不拦截内网地址；
+// 回调客户端按出站策略再次校验，内网隔离依赖部署侧的出口网络策略。
+func ParseWebhookSourceConfig(binding sourcebindingentity.Binding) (WebhookSourceConfig, error) {
+	channel := strings.TrimSpace(binding.RootRef)
+	if !webhookChannelPattern.MatchString(channel) {
+		return WebhookSourceConfig{}, fmt.Errorf("%w: root_ref %q", sourcebindingentity.ErrInvalidWebhookSourceConfig, channel)
+	}
+	secret, _ := binding.SyncConfig[webhookSyncConfigSigningSecret].(string)
+	secret = strings.TrimSpace(secret)
+	if len(secret) < minWebhookSigningSecretLength {
+		return WebhookSourceConfig{}, fmt.Errorf(
+			"%w: %s must be at least %d characters",
+			sourcebindingentity.ErrInvalidWebhookSourceConfig,
+			webhookSyncConfigSigningSecret,
+			minWebhookSigningSecretLength,
+		)
+	}
+	callbackURL, _ := binding.SyncConfig[webhookSyncConfigCallbackURL].(string)
+	callbackURL = strings.TrimSpace(callbackURL)
+	if callbackURL != "" && !isHTTPURL(callbackURL) {
+		return WebhookSourceConfig{}, fmt.Errorf("%w: %s %q", sourcebindingentity.ErrInvalidWebhookSourceConfig, webhookSyncConfigCallbackURL, callbackURL)
+	}
+	return WebhookSourceConfig{Channel: channel, SigningSecret: secret, CallbackURL: callbackURL}, nil
+}
+
+// CallbackTarget 返回同步结果的回调目标；未配置 callback_url 时 URL 为空。
+func (c WebhookSourceConfig) CallbackTarget() sourcebindingentity.WebhookCallbackTarget {
+	return sourcebindingentity.WebhookCallbackTarget{URL: c.CallbackURL, SigningSecret: c.SigningSecret}
+}
+
+// VerifyWebhookSignature 校验推送请求的时间戳与签名。
+//
+// 时间戳为 Unix 秒；与 now 相差超过 WebhookSignatureTolerance 的请求按重放拒绝。
+func (c WebhookSourceConfig) VerifyWebhookSignature(timestamp, signature string, body []byte, now time.Time) error {
+	unixSeconds, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return ErrWebhookTimestampExpired
+	}
+	skew := now.Sub(time.Unix(unixSeconds, 0))
+	if skew > WebhookSignatureTolerance || skew < -WebhookSignatureTolerance {
+		return ErrWebhookTimestampExpired
+	}
+	expected := sourcebindingentity.SignWebhookPayload(c.SigningSecret, unixSeconds, body)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(strings.TrimSpace(signature)))) {
+		return ErrWebhookSignatureInvalid
+	}
+	return nil
+}
+
+// ParseWebhookEvents 解析并规范化推送事件；同一 external_id 在一次推送中出现多次时只保留最后一条。
+func ParseWebhookEvents(body []byte) ([]WebhookEvent, error) {
+	var payload webhookEventBatchPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWebhookEvent, err)
+	}
+	if len(payload.Events) == 0 || len(payload.Events) > MaxWebhookEventsPerRequest {
+		return nil, fmt.Errorf("%w: events must contain 1-%d items", ErrInvalidWebhookEvent, MaxWebhookEventsPerRequest)
+	}
+	events := make([]WebhookEvent, 0, len(payload.Events))
+	positions := make(map[string]int, len(payload.Events))
+	for idx, raw := range payload.Events {
+		event, err := normalizeWebhookEvent(raw)
+		if err != nil {
+			return nil, fmt.Errorf("events[%d]: %w", idx, err)
+		}
+		if position, exists := positions[event.ExternalID]; exists {
+			events[position] = event
+			continue
+		}
+		positions[event.ExternalID] = len(events)
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func normalizeWebhookEvent(raw webhookEventPayload) (WebhookEvent, error) {
+	event := WebhookEvent{
+		Action:      strings.ToLower(strings.TrimSpace(raw.Action)),
+		ExternalID:  strings.TrimSpace(raw.ExternalID),
+		Name:        strings.TrimSpace(raw.Name),
+		Content:     raw.Content,
+		URL:         strings.TrimSpace(raw.URL),
+		ContentHash: strings.ToLower(strings.TrimSpace(raw.ContentHash)),
+	}
+	if event.ExternalID == "" || len(event.ExternalID) > maxWebhookExternalIDLength {
+		return WebhookEvent{}, fmt.Errorf("%w: external_id must be 1-%d characters", ErrInvalidWebhookEvent, maxWebhookExternalIDLength)
+	}
+	switch event.Action {
+	case WebhookActionDelete:
+		return WebhookEvent{Action: event.Action, ExternalID: event.ExternalID}, nil
+	case WebhookActionUpsert:
+	default:
+		return WebhookEvent{}, fmt.Errorf("%w: unsupported action %q", ErrInvalidWebhookEvent, raw.Action)
+	}
+
+	hasContent := strings.TrimSpace(event.Content) != ""
+	switch {
+	case hasContent == (event.URL != ""):
+		return WebhookEvent{}, fmt.Errorf("%w: upsert requires exactly one of content or url", ErrInvalidWebhookEvent)
+	case hasContent && len(event.Content) > MaxWebhookInlineContentBytes:
+		return WebhookEvent{}, fmt.Errorf("%w: content exceeds %d bytes", ErrInvalidWebhookEvent, MaxWebhookInlineContentBytes)
+	case !hasContent && !isHTTPURL(event.URL):
+		return WebhookEvent{}, fmt.Errorf("%w: url must be http or https", ErrInvalidWebhookEvent)
+	}
+	if event.Name == "" {
+		event.Name = event.ExternalID
+	}
+	event.Extension = resolveWebhookEventExtension(raw.Extension, event)
+	if hasContent {
+		// 内联正文的哈希总是由服务端计算，避免调用方传错哈希导致内容变化被误判为未变化。
+		sum := sha256.Sum256([]byte(event.Content))
+		event.ContentHash = hex.EncodeToString(sum[:])
+	}
+	return event, nil
+}
+
+func resolveWebhookEventExtension(explicit string, event WebhookEvent) string {
+	if extension := filetype.NormalizeExtension(explicit); extension != "" {
+		return extension
+	}
+	if extension := filetype.NormalizeExtension(path.Ext(event.Name)); extension != "" {
+		return extension
+	}
+	if event.URL != "" {
+		if parsed, err := url.Parse(event.URL); err == nil {
+			if extension := filetype.NormalizeExtension(path.Ext(parsed.Path)); extension != "" {
+				return extension
+			}
+		}
+	}
+	if event.Content != "" {
+		return defaultWebhookInlineExtension
+	}
+	return ""
+}
+
+// isHTTPURL 与内容加载器的出站策略保持一致：只接受带主机名的绝对 http(s) 地址。
+func isHTTPURL(raw string) bool {
+	parsed, err := url.ParseRequestURI(raw)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// WebhookItemRef 返回推送条目的 item_ref，形如 "channel:external_id"。
+//
+// 来源项按组织与 provider 全局唯一，因此需要带上通道名。
+func WebhookItemRef(channel, externalID string) string {
+	return channel + ":" + externalID
+}
+
+// BuildWebhookSnapshotMeta 构造推送条目的来源项快照元数据。
+func BuildWebhookSnapshotMeta(event WebhookEvent) map[string]any {
+	return map[string]any{
+		WebhookSnapshotMetaExternalID:  event.ExternalID,
+		WebhookSnapshotMetaContentHash: event.ContentHash,
+		WebhookSnapshotMetaURL:         event.URL,
+	}
+}
+
+// WebhookEventUnchanged 判断 upsert 事件与上次推送的快照是否一致；没有内容哈希的事件总是视为变化。
+func WebhookEventUnchanged(snapshotMeta map[string]any, event WebhookEvent) bool {
+	if event.ContentHash == "" || len(snapshotMeta) == 0 {
+		return false
+	}
+	previousHash, _ := snapshotMeta[WebhookSnapshotMetaContentHash].(string)
+	previousURL, _ := snapshotMeta[WebhookSnapshotMetaURL].(string)
+	return previousHash == event.ContentHash && previousURL == event.URL
+}
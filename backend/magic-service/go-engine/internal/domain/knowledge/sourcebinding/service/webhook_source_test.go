@@ -0,0 +1,129 @@
+package sourcebinding_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	sourcebinding "magic/internal/domain/knowledge/sourcebinding/entity"
+	sourcebindingservice "magic/internal/domain/knowledge/sourcebinding/service"
+)
+
+const webhookTestSecret = "0123456789abcdef-secret"
+
+func TestParseWebhookSourceConfigValidatesChannelAndSecret(t *testing.T) {
+	t.Parallel()
+
+	config, err := sourcebindingservice.ParseWebhookSourceConfig(sourcebinding.Binding{
+		Provider: sourcebinding.ProviderWebhook,
+		RootType: sourcebinding.RootTypeChannel,
+		RootRef:  " cms-articles ",
+		SyncConfig: map[string]any{
+			"signing_secret": webhookTestSecret,
+			"callback_url":   "https://cms.example.com/hooks/knowledge",
+		},
+	})
+	if err != nil {
+		t.Fatalf("parse webhook source config: %v", err)
+	}
+	if config.Channel != "cms-articles" || config.CallbackURL != "https://cms.example.com/hooks/knowledge" {
+		t.Fatalf("unexpected config: %#v", config)
+	}
+
+	invalid := []sourcebinding.Binding{
+		{RootRef: "CMS Articles", SyncConfig: map[string]any{"signing_secret": webhookTestSecret}},
+		{RootRef: "cms", SyncConfig: map[string]any{"signing_secret": "short"}},
+		{RootRef: "cms", SyncConfig: map[string]any{"signing_secret": webhookTestSecret, "callback_url": "ftp://cms/hook"}},
+	}
+	for _, binding := range invalid {
+		if _, err := sourcebindingservice.ParseWebhookSourceConfig(binding); !errors.Is(err, sourcebinding.ErrInvalidWebhookSourceConfig) {
+			t.Fatalf("expected invalid webhook config for %#v, got %v", binding, err)
+		}
+	}
+}
+
+func TestWebhookSignatureVerification(t *testing.T) {
+	t.Parallel()
+
+	config := sourcebindingservice.WebhookSourceConfig{Channel: "cms", SigningSecret: webhookTestSecret}
+	now := time.Unix(1_780_000_000, 0)
+	body := []byte(`{"events":[]}`)
+	signature := sourcebinding.SignWebhookPayload(webhookTestSecret, now.Unix(), body)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	if err := config.VerifyWebhookSignature(timestamp, signature, body, now.Add(time.Minute)); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+	if err := config.VerifyWebhookSignature(timestamp, signature, []byte(`{"events":[{}]}`), now); !errors.Is(err, sourcebindingservice.ErrWebhookSignatureInvalid) {
+		t.Fatalf("expected tampered body to be rejected, got %v", err)
+	}
+	if err := config.VerifyWebhookSignature(timestamp, signature, body, now.Add(6*time.Minute)); !errors.Is(err, sourcebindingservice.ErrWebhookTimestampExpired) {
+		t.Fatalf("expected replayed request to be rejected, got %v", err)
+	}
+	if err := config.VerifyWebhookSignature("", signature, body, now); !errors.Is(err, sourcebindingservice.ErrWebhookTimestampExpired) {
+		t.Fatalf("expected missing timestamp to be rejected, got %v", err)
+	}
+}
+
+func TestParseWebhookEventsNormalizesAndDeduplicates(t *testing.T) {
+	t.Parallel()
+
+	events, err := sourcebindingservice.ParseWebhookEvents([]byte(`{"events":[
+		{"action":"UPSERT","external_id":"a-1","name":"Refund policy","content":"# v1"},
+		{"action":"upsert","external_id":"b-2","url":"https://cms.example.com/export/b-2.pdf","content_hash":"ABC"},
+		{"action":"upsert","external_id":"a-1","name":"Refund policy","content":"# v2"},
+		{"action":"delete","external_id":"c-3","content":"ignored"}
+	]}`))
+	if err != nil {
+		t.Fatalf("parse webhook events: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected duplicate external ids to collapse, got %#v", events)
+	}
+	inline, download, deleted := events[0], events[1], events[2]
+	if inline.Action != sourcebindingservice.WebhookActionUpsert || inline.Content != "# v2" || inline.Extension != "md" ||
+		len(inline.ContentHash) != 64 {
+		t.Fatalf("unexpected inline event: %#v", inline)
+	}
+	if download.Name != "b-2" || download.Extension != "pdf" || download.ContentHash != "abc" {
+		t.Fatalf("unexpected download event: %#v", download)
+	}
+	if deleted.Action != sourcebindingservice.WebhookActionDelete || deleted.Content != "" {
+		t.Fatalf("unexpected delete event: %#v", deleted)
+	}
+
+	invalid := []string{
+		`{"events":[]}`,
+		`{"events":[{"action":"move","external_id":"a"}]}`,
+		`{"events":[{"action":"upsert","external_id":"a"}]}`,
+		`{"events":[{"action":"upsert","external_id":"a","content":"x","url":"https://cms/x"}]}`,
+		`{"events":[{"action":"upsert","external_id":"a","url":"file:///etc/passwd"}]}`,
+		`{"events":[{"action":"delete","external_id":" "}]}`,
+		`not json`,
+	}
+	for _, body := range invalid {
+		if _, err := sourcebindingservice.ParseWebhookEvents([]byte(body)); !errors.Is(err, sourcebindingservice.ErrInvalidWebhookEvent) {
+			t.Fatalf("expected invalid webhook event for %s, got %v", body, err)
+		}
+	}
+}
+
+func TestWebhookEventUnchangedComparesHashAndURL(t *testing.T) {
+	t.Parallel()
+
+	event := sourcebindingservice.WebhookEvent{ExternalID: "a", ContentHash: "h1"}
+	snapshot := sourcebindingservice.BuildWebhookSnapshotMeta(event)
+	if !sourcebindingservice.WebhookEventUnchanged(snapshot, event) {
+		t.Fatal("expected identical hash to be unchanged")
+	}
+	if sourcebindingservice.WebhookEventUnchanged(snapshot, sourcebindingservice.WebhookEvent{ExternalID: "a", ContentHash: "h2"}) {
+		t.Fatal("expected different hash to be changed")
+	}
+	if sourcebindingservice.WebhookEventUnchanged(snapshot, sourcebindingservice.WebhookEvent{ExternalID: "a", URL: "https://x/a"}) {
+		t.Fatal("expected event without hash to be changed")
+	}
+	if got := sourcebindingservice.WebhookItemRef("cms", "a/1"); got != "cms:a/1" {
+		t.Fatalf("unexpected item ref %q", got)
+	}
+}
@@ -0,0 +1,57 @@
+package sourcebinding
+
+import (
+	"cmp"
+	"net/url"
+	"path"
+	"strings"
+
+	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
+)
+
+const (
+	// WebsiteSnapshotMetaURL 表示页面快照中的规范化 URL。
+	WebsiteSnapshotMetaURL = "url"
+	// WebsiteSnapshotMetaTitle 表示页面快照中的标题。
+	WebsiteSnapshotMetaTitle = "title"
+	// WebsiteSnapshotMetaDepth 表示页面距种子 URL 的链接深度。
+	WebsiteSnapshotMetaDepth = "depth"
+	// WebsiteSnapshotMetaContentType 表示页面响应的 Content-Type。
+	WebsiteSnapshotMetaContentType = "content_type"
+	// WebsiteSnapshotMetaETag 表示页面响应的 ETag。
+	WebsiteSnapshotMetaETag = "etag"
+	// WebsiteSnapshotMetaLastModified 表示页面响应的 Last-Modified。
+	WebsiteSnapshotMetaLastModified = "last_modified"
+)
+
+// WebsitePageGroupRef 返回页面所在的虚拟目录，形如 "docs.example.com/guide"。
+func WebsitePageGroupRef(pageURL string) string {
+	parsed, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	dir := path.Dir(cmp.Or(parsed.Path, "/"))
+	if strings.HasSuffix(parsed.Path, "/") {
+		dir = strings.TrimSuffix(parsed.Path, "/")
+	}
+	return strings.TrimSuffix(strings.ToLower(parsed.Host)+dir, "/")
+}
+
+// BuildWebsitePageSnapshotMeta 构造页面来源项的快照元数据，保存下次条件请求所需的校验值。
+func BuildWebsitePageSnapshotMeta(page sourcebindingentity.WebsitePage) map[string]any {
+	return map[string]any{
+		WebsiteSnapshotMetaURL:          page.URL,
+		WebsiteSnapshotMetaTitle:        page.Title,
+		WebsiteSnapshotMetaDepth:        page.Depth,
+		WebsiteSnapshotMetaContentType:  page.ContentType,
+		WebsiteSnapshotMetaETag:         page.ETag,
+		WebsiteSnapshotMetaLastModified: page.LastModified,
+	}
+}
+
+// WebsitePageValidatorFromSnapshot 从来源项快照中读取缓存校验值。
+func WebsitePageValidatorFromSnapshot(snapshotMeta map[string]any) sourcebindingentity.WebsitePageValidator {
+	etag, _ := snapshotMeta[WebsiteSnapshotMetaETag].(string)
+	lastModified, _ := snapshotMeta[WebsiteSnapshotMetaLastModified].(string)
+	return sourcebindingentity.WebsitePageValidator{ETag: etag, LastModified: lastModified}
+}
@@ -0,0 +1,91 @@
+package sourcebinding_test
+
+import (
+	"errors"
+	"testing"
+
+	sourcebinding "magic/internal/domain/knowledge/sourcebinding/entity"
+	sourcebindingservice "magic/internal/domain/knowledge/sourcebinding/service"
+)
+
+func TestValidateBindingsWebsiteRejectsInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	err := sourcebindingservice.ValidateBindings(sourcebinding.SemanticLegacy, []sourcebinding.Binding{{
+		Provider:   sourcebinding.ProviderWebsite,
+		RootType:   sourcebinding.RootTypeSite,
+		RootRef:    "ftp://docs.example.com/",
+		SyncMode:   sourcebinding.SyncModeRealtime,
+		SyncConfig: map[string]any{},
+	}})
+	if !errors.Is(err, sourcebinding.ErrInvalidWebsiteCrawlConfig) {
+		t.Fatalf("expected ErrInvalidWebsiteCrawlConfig, got %v", err)
+	}
+
+	err = sourcebindingservice.ValidateBindings(sourcebinding.SemanticLegacy, []sourcebinding.Binding{{
+		Provider: sourcebinding.ProviderWebsite,
+		RootType: sourcebinding.RootTypeFile,
+		RootRef:  "https://docs.example.com/",
+		SyncMode: sourcebinding.SyncModeRealtime,
+	}})
+	if !errors.Is(err, sourcebinding.ErrSemanticMismatch) {
+		t.Fatalf("expected ErrSemanticMismatch, got %v", err)
+	}
+}
+
+func TestWebsitePageGroupRefAndValidators(t *testing.T) {
+	t.Parallel()
+
+	if got := sourcebindingservice.WebsitePageGroupRef("https://Docs.Example.com/guide/install"); got != "docs.example.com/guide" {
+		t.Fatalf("unexpected group ref: %q", got)
+	}
+	if got := sourcebindingservice.WebsitePageGroupRef("https://docs.example.com/"); got != "docs.example.com" {
+		t.Fatalf("unexpected root group ref: %q", got)
+	}
+	meta := sourcebindingservice.BuildWebsitePageSnapshotMeta(sourcebinding.WebsitePage{
+		URL: "https://docs.example.com/", ETag: `"v1"`, LastModified: "Mon, 02 Jan 2026 00:00:00 GMT",
+	})
+	validator := sourcebindingservice.WebsitePageValidatorFromSnapshot(meta)
+	if validator.ETag != `"v1"` || validator.LastModified == "" {
+		t.Fatalf("unexpected validator: %#v", validator)
+	}
+}
+
+func TestPlanWebsiteRecrawlKeepsUnvisitedPagesInScope(t *testing.T) {
+	t.Parallel()
+
+	config, err := sourcebinding.ParseWebsiteCrawlConfig(sourcebinding.Binding{
+		RootRef:    "https://docs.example.com/",
+		SyncConfig: map[string]any{"exclude_patterns": []any{"/legacy/**"}},
+	})
+	if err != nil {
+		t.Fatalf("parse website crawl config: %v", err)
+	}
+	plan := sourcebindingservice.PlanWebsiteRecrawl(config, []string{
+		"https://docs.example.com/a",
+		"https://docs.example.com/b",
+		"https://docs.example.com/c",
+		"https://docs.example.com/d",
+		"https://docs.example.com/legacy/e",
+	}, &sourcebinding.WebsiteCrawlResult{
+		Pages: []sourcebinding.WebsitePage{
+			{URL: "https://docs.example.com/a", NotModified: true},
+			{URL: "https://docs.example.com/b", ETag: `"b2"`},
+			{URL: "https://docs.example.com/new"},
+		},
+		GoneURLs: []string{"https://docs.example.com/c"},
+	})
+
+	if len(plan.Changed) != 1 || plan.Changed[0].URL != "https://docs.example.com/b" {
+		t.Fatalf("unexpected changed pages: %#v", plan.Changed)
+	}
+	if len(plan.Created) != 1 || plan.Created[0].URL != "https://docs.example.com/new" {
+		t.Fatalf("unexpected created pages: %#v", plan.Created)
+	}
+	if len(plan.Unchanged) != 2 || plan.Unchanged[0] != "https://docs.example.com/a" || plan.Unchanged[1] != "https://docs.example.com/d" {
+		t.Fatalf("unexpected unchanged pages: %#v", plan.Unchanged)
+	}
+	if len(plan.Removed) != 2 || plan.Removed[0] != "https://docs.example.com/c" || plan.Removed[1] != "https://docs.example.com/legacy/e" {
+		t.Fatalf("unexpected removed pages: %#v", plan.Removed)
+	}
+}
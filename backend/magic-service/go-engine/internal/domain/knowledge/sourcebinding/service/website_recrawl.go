@@ -0,0 +1,76 @@
+package sourcebinding
+
+import (
+	"slices"
+	"strings"
+
+	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
+)
+
+// WebsiteRecrawlPlan 表示一次网站重新爬取后托管文档需要执行的变更。
+type WebsiteRecrawlPlan struct {
+	// Unchanged 为内容未变化、直接沿用现有文档的页面 URL。
+	Unchanged []string
+	// Changed 为内容已变化、需要重新同步现有文档的页面。
+	Changed []sourcebindingentity.WebsitePage
+	// Created 为本次新发现、需要创建文档的页面。
+	Created []sourcebindingentity.WebsitePage
+	// Removed 为已下线或移出爬取范围、需要删除文档的页面 URL。
+	Removed []string
+}
+
+// PlanWebsiteRecrawl 对比已物化页面与本次爬取结果，得到增量变更计划。
+//
+// 因为 304 页面不会展开链接，本次未访问到的已知页面只要仍在爬取范围内且未返回 404/410，就继续保留。
+func PlanWebsiteRecrawl(
+	config sourcebindingentity.WebsiteCrawlConfig,
+	knownURLs []string,
+	result *sourcebindingentity.WebsiteCrawlResult,
+) WebsiteRecrawlPlan {
+	plan := WebsiteRecrawlPlan{}
+	known := make(map[string]struct{}, len(knownURLs))
+	for _, pageURL := range knownURLs {
+		if pageURL = strings.TrimSpace(pageURL); pageURL != "" {
+			known[pageURL] = struct{}{}
+		}
+	}
+	if result == nil {
+		result = &sourcebindingentity.WebsiteCrawlResult{}
+	}
+
+	visited := make(map[string]struct{}, len(result.Pages))
+	for _, page := range result.Pages {
+		if _, exists := visited[page.URL]; exists {
+			continue
+		}
+		visited[page.URL] = struct{}{}
+		_, isKnown := known[page.URL]
+		switch {
+		case isKnown && page.NotModified:
+			plan.Unchanged = append(plan.Unchanged, page.URL)
+		case isKnown:
+			plan.Changed = append(plan.Changed, page)
+		case !page.NotModified:
+			plan.Created = append(plan.Created, page)
+		}
+	}
+	for _, pageURL := range result.GoneURLs {
+		if _, isKnown := known[pageURL]; isKnown {
+			visited[pageURL] = struct{}{}
+			plan.Removed = append(plan.Removed, pageURL)
+		}
+	}
+	for pageURL := range known {
+		if _, exists := visited[pageURL]; exists {
+			continue
+		}
+		if config.AllowsURL(pageURL) {
+			plan.Unchanged = append(plan.Unchanged, pageURL)
+			continue
+		}
+		plan.Removed = append(plan.Removed, pageURL)
+	}
+	slices.Sort(plan.Unchanged)
+	slices.Sort(plan.Removed)
+	return plan
+}
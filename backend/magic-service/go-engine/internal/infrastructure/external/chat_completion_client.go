@@ -0,0 +1,124 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	fragretrieval "magic/internal/domain/knowledge/fragment/retrieval"
+)
+
+var (
+	// ErrChatCompletionRequestFailed 表示对话补全 API 请求失败
+	ErrChatCompletionRequestFailed = errors.New("chat completion request failed")
+	// ErrChatCompletionEmptyResponse 表示对话补全 API 未返回任何候选
+	ErrChatCompletionEmptyResponse = errors.New("chat completion empty response")
+)
+
+const defaultChatCompletionHTTPTimeout = 30 * time.Second
+
+// ChatCompletionClient 调用模型网关的 OpenAI 兼容 /v1/chat/completions 接口。
+type ChatCompletionClient struct {
+	// gateway 复用模型网关客户端的 URL 校验、令牌解析与发送逻辑
+	gateway *OpenAIEmbeddingClient
+}
+
+// NewChatCompletionClient 创建对话补全客户端，baseURL 通常指向模型网关。
+func NewChatCompletionClient(baseURL string, accessTokenProvider AccessTokenProvider) *ChatCompletionClient {
+	gateway := NewOpenAIEmbeddingClient(baseURL, accessTokenProvider)
+	gateway.httpClient.Timeout = defaultChatCompletionHTTPTimeout
+	return &ChatCompletionClient{gateway: gateway}
+}
+
+type chatCompletionMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatCompletionRequest struct {
+	Model          string                  `json:"model"`
+	Messages       []chatCompletionMessage `json:"messages"`
+	Temperature    float64                 `json:"temperature"`
+	MaxTokens      int                     `json:"max_tokens,omitempty"`
+	Stream         bool                    `json:"stream"`
+	BusinessParams map[string]string       `json:"business_params,omitempty"`
+}
+
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+// CompleteChat 发送非流式对话补全请求并返回首个候选文本。
+func (c *ChatCompletionClient) CompleteChat(ctx context.Context, input fragretrieval.ChatCompletionRequest) (string, error) {
+	reqBody := chatCompletionRequest{
+		Model:       input.Model,
+		Messages:    make([]chatCompletionMessage, 0, len(input.Messages)),
+		Temperature: input.Temperature,
+		MaxTokens:   input.MaxTokens,
+	}
+	for _, message := range input.Messages {
+		reqBody.Messages = append(reqBody.Messages, chatCompletionMessage{Role: message.Role, Content: message.Content})
+	}
+	if input.BusinessParams != nil && !input.BusinessParams.IsEmpty() {
+		reqBody.BusinessParams = input.BusinessParams.ToMap()
+	}
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	chatURL, err := c.gateway.joinStableURL("/v1/chat/completions")
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, chatURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if accessToken, tokenErr := c.gateway.resolveAccessToken(ctx); tokenErr == nil {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	}
+	if input.BusinessParams != nil {
+		if orgCode := input.BusinessParams.GetOrganizationCode(); orgCode != "" {
+			req.Header.Set("Magic-Organization-Code", orgCode)
+			req.Header.Set("Magic-Organization-Id", orgCode)
+		}
+		if input.BusinessParams.UserID != "" {
+			req.Header.Set("Magic-User-Id", input.BusinessParams.UserID)
+		}
+	}
+
+	resp, err := c.gateway.send(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("%w with status %d: %s", ErrChatCompletionRequestFailed, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var chatResp chatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(chatResp.Choices) == 0 {
+		return "", ErrChatCompletionEmptyResponse
+	}
+	return chatResp.Choices[0].Message.Content, nil
+}
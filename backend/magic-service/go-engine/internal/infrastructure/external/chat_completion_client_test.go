@@ -0,0 +1,93 @@
+package external_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fragretrieval "magic/internal/domain/knowledge/fragment/retrieval"
+	"magic/internal/infrastructure/external"
+	"magic/internal/pkg/ctxmeta"
+)
+
+func TestChatCompletionClient_CompleteChat(t *testing.T) {
+	t.Parallel()
+	errCh := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Method != http.MethodPost {
+			errCh <- fmt.Errorf("%w: %s %s", errUnexpectedRequest, r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token123" {
+			errCh <- fmt.Errorf("%w: auth=%s", errUnexpectedRequest, got)
+		}
+		if got := r.Header.Get("Magic-Organization-Code"); got != "org" {
+			errCh <- fmt.Errorf("%w: org_code=%s", errUnexpectedRequest, got)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			errCh <- err
+		}
+		messages, _ := body["messages"].([]any)
+		if body["model"] != "gpt-4o-mini" || body["stream"] != false || len(messages) != 2 {
+			errCh <- fmt.Errorf("%w: body=%v", errUnexpectedRequest, body)
+		}
+		_, _ = io.WriteString(w, `{"choices":[{"message":{"role":"assistant","content":"退款需要多久\n如何申请退款"}}]}`)
+	}))
+	defer srv.Close()
+
+	client := external.NewChatCompletionClient(srv.URL, fakeTokenProvider{token: "token123"})
+	content, err := client.CompleteChat(context.Background(), fragretrieval.ChatCompletionRequest{
+		Model: "gpt-4o-mini",
+		Messages: []fragretrieval.ChatMessage{
+			{Role: "system", Content: "rewrite"},
+			{Role: "user", Content: "退款流程"},
+		},
+		Temperature:    0.3,
+		MaxTokens:      256,
+		BusinessParams: &ctxmeta.BusinessParams{OrganizationCode: "org"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case reqErr := <-errCh:
+		t.Fatalf("unexpected request: %v", reqErr)
+	default:
+	}
+	if content != "退款需要多久\n如何申请退款" {
+		t.Fatalf("unexpected content: %q", content)
+	}
+}
+
+func TestChatCompletionClient_CompleteChatErrors(t *testing.T) {
+	t.Parallel()
+	statusSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = io.WriteString(w, "upstream down")
+	}))
+	defer statusSrv.Close()
+	emptySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"choices":[]}`)
+	}))
+	defer emptySrv.Close()
+
+	request := fragretrieval.ChatCompletionRequest{
+		Model:    "m",
+		Messages: []fragretrieval.ChatMessage{{Role: "user", Content: "q"}},
+	}
+	_, err := external.NewChatCompletionClient(statusSrv.URL, fakeTokenProvider{token: "token123"}).
+		CompleteChat(context.Background(), request)
+	if !errors.Is(err, external.ErrChatCompletionRequestFailed) {
+		t.Fatalf("expected ErrChatCompletionRequestFailed, got %v", err)
+	}
+	_, err = external.NewChatCompletionClient(emptySrv.URL, fakeTokenProvider{token: "token123"}).
+		CompleteChat(context.Background(), request)
+	if !errors.Is(err, external.ErrChatCompletionEmptyResponse) {
+		t.Fatalf("expected ErrChatCompletionEmptyResponse, got %v", err)
+	}
+}
@@ -46,19 +46,36 @@ type ContentLoader struct {
 
 // NewContentLoader 创建新的内容加载器
 func NewContentLoader() *ContentLoader {
-	return &ContentLoader{
-		client: &http.Client{
-			Timeout: defaultTimeout,
-			CheckRedirect: func(_ *http.Request, via []*http.Request) error {
-				if len(via) >= maxRedirects {
-					return fmt.Errorf("%w: %d", ErrTooManyRedirects, maxRedirects)
-				}
-				return nil
-			},
+	return &ContentLoader{client: NewEgressHTTPClient(defaultTimeout)}
+}
+
+// NewEgressHTTPClient 创建遵循内容加载器出站策略的 HTTP client：
+// 限制超时与重定向次数，且每一跳都必须是带主机名的 http(s) 地址。
+//
+// 该策略不拦截内网地址，SSRF 防护依赖部署侧的出口网络隔离。
+func NewEgressHTTPClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("%w: %d", ErrTooManyRedirects, maxRedirects)
+			}
+			if _, err := NormalizeEgressURL(req.URL.String()); err != nil {
+				return err
+			}
+			return nil
 		},
 	}
 }
 
+// NormalizeEgressURL 按内容加载器的出站策略校验并规范化 URL，只接受带主机名的 http(s) 地址。
+func NormalizeEgressURL(rawURL string) (string, error) {
+	return normalizeTargetURL(rawURL)
+}
+
 // NewContentLoaderWithClient 使用自定义 HTTP client 创建内容加载器。
 // client 为 nil 时回退到默认 client。
 func NewContentLoaderWithClient(client *http.Client) *ContentLoader {
@@ -0,0 +1,250 @@
+// Package gitrepo 通过 git 命令行拉取知识库 Git 来源仓库。
+package gitrepo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
+)
+
+const (
+	defaultMirrorDirName = "magic-git-sources"
+	defaultFetchInterval = 30 * time.Second
+	commandTimeout       = 5 * time.Minute
+	symlinkFileMode      = "120000"
+	treeEntryTypeBlob    = "blob"
+)
+
+// gitURLCredentialPattern 匹配 git 输出中带凭据的 URL userinfo 段。
+var gitURLCredentialPattern = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/@\s]+@`)
+
+var (
+	errGitCommandFailed   = errors.New("git command failed")
+	errGitRefNotFound     = errors.New("git ref not found")
+	errMalformedTreeEntry = errors.New("malformed git tree entry")
+)
+
+// 编译时检查是否实现了领域端口
+var (
+	_ sourcebindingentity.GitRepository = (*Client)(nil)
+	_ documentdomain.GitFileReader      = (*Client)(nil)
+)
+
+// Client 在本地维护仓库的 mirror 克隆，按需 fetch 后读取文件树、差异与文件内容。
+//
+// 同一仓库的 fetch 串行执行，且在 fetchInterval 内只拉取一次，避免批量重同步时反复访问远端。
+// mirror 按去掉凭据的仓库地址定位：绑定侧用带凭据的 root_ref 克隆并刷新远端地址，
+// 文档侧只持有脱敏地址，读取时复用同一个 mirror 中 origin 记录的凭据。
+type Client struct {
+	baseDir       string
+	fetchInterval time.Duration
+	now           func() time.Time
+
+	mu      sync.Mutex
+	mirrors map[string]*mirrorState
+}
+
+type mirrorState struct {
+	mu        sync.Mutex
+	fetchedAt time.Time
+}
+
+// NewClient 创建 Git 仓库客户端；baseDir 为空时把 mirror 放在系统临时目录下。
+func NewClient(baseDir string) *Client {
+	if strings.TrimSpace(baseDir) == "" {
+		baseDir = filepath.Join(os.TempDir(), defaultMirrorDirName)
+	}
+	return &Client{
+		baseDir:       baseDir,
+		fetchInterval: defaultFetchInterval,
+		now:           time.Now,
+		mirrors:       make(map[string]*mirrorState),
+	}
+}
+
+// ResolveTree 拉取仓库最新内容，返回 ref 指向的提交及其中的普通文件（忽略符号链接与子模块）。
+func (c *Client) ResolveTree(ctx context.Context, repositoryURL, ref string) (*sourcebindingentity.GitTree, error) {
+	dir, err := c.syncMirror(ctx, repositoryURL, true)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := c.resolveCommit(ctx, dir, ref)
+	if err != nil {
+		return nil, err
+	}
+	output, err := runGit(ctx, dir, "ls-tree", "-r", "-l", "-z", "--full-tree", commit)
+	if err != nil {
+		return nil, err
+	}
+	files, err := parseTreeEntries(output)
+	if err != nil {
+		return nil, err
+	}
+	return &sourcebindingentity.GitTree{Commit: commit, Files: files}, nil
+}
+
+// ChangedPaths 返回两次提交之间变化的文件路径；重命名按删除旧路径加新增新路径处理。
+func (c *Client) ChangedPaths(ctx context.Context, repositoryURL, fromCommit, toCommit string) ([]string, error) {
+	dir, err := c.syncMirror(ctx, repositoryURL, false)
+	if err != nil {
+		return nil, err
+	}
+	output, err := runGit(ctx, dir, "diff", "--name-only", "-z", "--no-renames", fromCommit, toCommit, "--")
+	if err != nil {
+		return nil, err
+	}
+	return splitNullTerminated(output), nil
+}
+
+// ReadGitFile 读取 ref 最近一次拉取到的版本中的单个文件。
+func (c *Client) ReadGitFile(ctx context.Context, source documentdomain.GitFileSource) (io.ReadCloser, error) {
+	dir, err := c.syncMirror(ctx, source.RepositoryURL, false)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := c.resolveCommit(ctx, dir, source.Ref)
+	if err != nil {
+		return nil, err
+	}
+	content, err := runGit(ctx, dir, "cat-file", "blob", commit+":"+strings.TrimPrefix(source.Path, "/"))
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// syncMirror 确保仓库 mirror 存在；force 为 true 或距上次 fetch 超过间隔时重新拉取。
+func (c *Client) syncMirror(ctx context.Context, repositoryURL string, force bool) (string, error) {
+	repositoryURL, err := sourcebindingentity.NormalizeGitRepositoryURL(repositoryURL)
+	if err != nil {
+		return "", fmt.Errorf("normalize git repository url: %w", err)
+	}
+	redactedURL := sourcebindingentity.RedactGitRepositoryURL(repositoryURL)
+	dir := c.mirrorDir(redactedURL)
+	state := c.mirrorState(dir)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if _, statErr := os.Stat(dir); statErr != nil {
+		if err := os.MkdirAll(c.baseDir, 0o750); err != nil {
+			return "", fmt.Errorf("create git mirror base dir: %w", err)
+		}
+		if _, err := runGit(ctx, c.baseDir, "clone", "--mirror", "--quiet", "--", repositoryURL, dir); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", fmt.Errorf("clone git repository %s: %w", redactedURL, err)
+		}
+		state.fetchedAt = c.now()
+		return dir, nil
+	}
+	if !force && c.now().Sub(state.fetchedAt) < c.fetchInterval {
+		return dir, nil
+	}
+	// 带凭据的地址只来自绑定配置，凭据轮换后需要同步到 mirror 的 origin。
+	if repositoryURL != redactedURL {
+		if _, err := runGit(ctx, dir, "remote", "set-url", "origin", repositoryURL); err != nil {
+			return "", fmt.Errorf("update git remote %s: %w", redactedURL, err)
+		}
+	}
+	if _, err := runGit(ctx, dir, "fetch", "--prune", "--quiet", "origin"); err != nil {
+		return "", fmt.Errorf("fetch git repository %s: %w", redactedURL, err)
+	}
+	state.fetchedAt = c.now()
+	return dir, nil
+}
+
+func (c *Client) mirrorDir(repositoryURL string) string {
+	sum := sha256.Sum256([]byte(repositoryURL))
+	return filepath.Join(c.baseDir, hex.EncodeToString(sum[:12])+".git")
+}
+
+func (c *Client) mirrorState(dir string) *mirrorState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state, exists := c.mirrors[dir]
+	if !exists {
+		state = &mirrorState{}
+		c.mirrors[dir] = state
+	}
+	return state
+}
+
+func (c *Client) resolveCommit(ctx context.Context, dir, ref string) (string, error) {
+	if strings.TrimSpace(ref) == "" {
+		ref = "HEAD"
+	}
+	output, err := runGit(ctx, dir, "rev-parse", "--verify", "--quiet", "--end-of-options", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", errGitRefNotFound, ref, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// 禁止交互式凭据输入，私有仓库需把凭据放在 URL 或系统凭据助手中。
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: git %s: %w: %s", errGitCommandFailed, args[0], err, redactGitOutput(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// redactGitOutput 去掉 git 输出中 URL 携带的凭据，避免随错误信息进入日志或接口响应。
+func redactGitOutput(output string) string {
+	return gitURLCredentialPattern.ReplaceAllString(strings.TrimSpace(output), "${1}")
+}
+
+// parseTreeEntries 解析 `git ls-tree -r -l -z` 输出：<mode> <type> <object> <size>\t<path>\0。
+func parseTreeEntries(output []byte) ([]sourcebindingentity.GitTreeFile, error) {
+	entries := splitNullTerminated(output)
+	files := make([]sourcebindingentity.GitTreeFile, 0, len(entries))
+	for _, entry := range entries {
+		meta, filePath, found := strings.Cut(entry, "\t")
+		fields := strings.Fields(meta)
+		if !found || len(fields) != 4 {
+			return nil, fmt.Errorf("%w: %q", errMalformedTreeEntry, entry)
+		}
+		if fields[1] != treeEntryTypeBlob || fields[0] == symlinkFileMode {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", errMalformedTreeEntry, entry, err)
+		}
+		files = append(files, sourcebindingentity.GitTreeFile{Path: filePath, BlobSHA: fields[2], Size: size})
+	}
+	return files, nil
+}
+
+func splitNullTerminated(output []byte) []string {
+	parts := strings.Split(string(output), "\x00")
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
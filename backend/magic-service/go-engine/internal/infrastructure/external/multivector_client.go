@@ -0,0 +1,127 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	fragretrieval "magic/internal/domain/knowledge/fragment/retrieval"
+)
+
+// ErrMultiVectorRequestFailed 表示多向量编码 API 请求失败
+var ErrMultiVectorRequestFailed = errors.New("multi vector request failed")
+
+const defaultMultiVectorHTTPTimeout = 30 * time.Second
+
+// MultiVectorClient 调用 Jina 兼容的 /v1/multi-vector 接口，返回 ColBERT 类模型的逐 token 向量。
+type MultiVectorClient struct {
+	// gateway 复用模型网关客户端的 URL 校验、令牌解析与发送逻辑
+	gateway *OpenAIEmbeddingClient
+}
+
+// NewMultiVectorClient 创建多向量编码客户端，baseURL 通常指向模型网关。
+func NewMultiVectorClient(baseURL string, accessTokenProvider AccessTokenProvider) *MultiVectorClient {
+	gateway := NewOpenAIEmbeddingClient(baseURL, accessTokenProvider)
+	gateway.httpClient.Timeout = defaultMultiVectorHTTPTimeout
+	return &MultiVectorClient{gateway: gateway}
+}
+
+type multiVectorRequest struct {
+	Model          string            `json:"model"`
+	Input          []string          `json:"input"`
+	InputType      string            `json:"input_type,omitempty"`
+	EmbeddingType  string            `json:"embedding_type"`
+	BusinessParams map[string]string `json:"business_params,omitempty"`
+}
+
+type multiVectorResponseItem struct {
+	Index      int         `json:"index"`
+	Embeddings [][]float64 `json:"embeddings"`
+}
+
+type multiVectorResponse struct {
+	Data []multiVectorResponseItem `json:"data"`
+}
+
+// EncodeMultiVectors 批量编码逐 token 向量，结果按输入顺序返回。
+func (c *MultiVectorClient) EncodeMultiVectors(
+	ctx context.Context,
+	input fragretrieval.MultiVectorEncodeRequest,
+) ([][][]float64, error) {
+	if len(input.Texts) == 0 {
+		return nil, nil
+	}
+	reqBody := multiVectorRequest{
+		Model:         input.Model,
+		Input:         input.Texts,
+		InputType:     input.InputType,
+		EmbeddingType: "float",
+	}
+	if input.BusinessParams != nil && !input.BusinessParams.IsEmpty() {
+		reqBody.BusinessParams = input.BusinessParams.ToMap()
+	}
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	multiVectorURL, err := c.gateway.joinStableURL("/v1/multi-vector")
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, multiVectorURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if accessToken, tokenErr := c.gateway.resolveAccessToken(ctx); tokenErr == nil {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	}
+	if input.BusinessParams != nil {
+		if orgCode := input.BusinessParams.GetOrganizationCode(); orgCode != "" {
+			req.Header.Set("Magic-Organization-Code", orgCode)
+			req.Header.Set("Magic-Organization-Id", orgCode)
+		}
+		if input.BusinessParams.UserID != "" {
+			req.Header.Set("Magic-User-Id", input.BusinessParams.UserID)
+		}
+	}
+
+	resp, err := c.gateway.send(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%w with status %d: %s", ErrMultiVectorRequestFailed, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var multiVectorResp multiVectorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&multiVectorResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	results := make([][][]float64, len(input.Texts))
+	for _, item := range multiVectorResp.Data {
+		if item.Index < 0 || item.Index >= len(results) {
+			return nil, fmt.Errorf("%w: index %d out of range", ErrMultiVectorRequestFailed, item.Index)
+		}
+		results[item.Index] = item.Embeddings
+	}
+	for i, vectors := range results {
+		if len(vectors) == 0 {
+			return nil, fmt.Errorf("%w: missing embeddings for index %d", ErrMultiVectorRequestFailed, i)
+		}
+	}
+	return results, nil
+}
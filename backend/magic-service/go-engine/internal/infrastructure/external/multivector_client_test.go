@@ -0,0 +1,70 @@
+package external_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fragretrieval "magic/internal/domain/knowledge/fragment/retrieval"
+	"magic/internal/infrastructure/external"
+)
+
+func TestMultiVectorClient_EncodeMultiVectors(t *testing.T) {
+	t.Parallel()
+	errCh := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/multi-vector" || r.Method != http.MethodPost {
+			errCh <- fmt.Errorf("%w: %s %s", errUnexpectedRequest, r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			errCh <- err
+		}
+		if body["model"] != "jina-colbert-v2" || body["input_type"] != "query" || body["embedding_type"] != "float" {
+			errCh <- fmt.Errorf("%w: body=%v", errUnexpectedRequest, body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"data":[{"index":1,"embeddings":[[0,1]]},{"index":0,"embeddings":[[1,0],[0.5,0.5]]}]}`)
+	}))
+	defer srv.Close()
+
+	client := external.NewMultiVectorClient(srv.URL, fakeTokenProvider{token: "token123"})
+	vectors, err := client.EncodeMultiVectors(context.Background(), fragretrieval.MultiVectorEncodeRequest{
+		Model:     "jina-colbert-v2",
+		InputType: fragretrieval.MultiVectorInputQuery,
+		Texts:     []string{"退款", "发货"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case srvErr := <-errCh:
+		t.Fatalf("unexpected request: %v", srvErr)
+	default:
+	}
+	if len(vectors) != 2 || len(vectors[0]) != 2 || len(vectors[1]) != 1 || vectors[1][0][1] != 1 {
+		t.Fatalf("unexpected vectors: %#v", vectors)
+	}
+}
+
+func TestMultiVectorClient_EncodeMultiVectorsMissingIndex(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"data":[{"index":0,"embeddings":[[1,0]]}]}`)
+	}))
+	defer srv.Close()
+
+	client := external.NewMultiVectorClient(srv.URL, fakeTokenProvider{token: "token123"})
+	_, err := client.EncodeMultiVectors(context.Background(), fragretrieval.MultiVectorEncodeRequest{
+		Model: "m",
+		Texts: []string{"a", "b"},
+	})
+	if !errors.Is(err, external.ErrMultiVectorRequestFailed) {
+		t.Fatalf("expected ErrMultiVectorRequestFailed, got %v", err)
+	}
+}
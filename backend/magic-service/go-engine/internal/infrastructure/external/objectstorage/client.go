@@ -0,0 +1,226 @@
+// Package objectstorage 通过 S3 兼容协议（AWS S3、MinIO 等）读取知识库对象存储来源。
+package objectstorage
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
+)
+
+const (
+	defaultRegion         = "us-east-1"
+	defaultRequestTimeout = 60 * time.Second
+	listPageSize          = "1000"
+	maxListResponseBytes  = 16 << 20
+	maxErrorResponseBytes = 64 << 10
+	// maxListedObjects 防止超大前缀拖垮同步；列举不完整时宁可报错，也不能把未列出的对象当成已删除。
+	maxListedObjects = 100000
+)
+
+var (
+	// ErrNotConfigured 表示未配置对象存储 endpoint。
+	ErrNotConfigured = errors.New("knowledge object storage is not configured")
+
+	errUnexpectedStatus = errors.New("unexpected object storage response status")
+	errTooManyObjects   = errors.New("too many objects under prefix")
+	errInvalidEndpoint  = errors.New("invalid object storage endpoint")
+)
+
+// 编译时检查是否实现了领域端口
+var (
+	_ sourcebindingentity.ObjectStorageLister = (*Client)(nil)
+	_ documentdomain.ObjectStorageReader      = (*Client)(nil)
+)
+
+// Config 表示 S3 兼容存储的连接配置。
+type Config struct {
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
+	// VirtualHostStyle 为 true 时使用 "bucket.endpoint/key" 寻址，否则使用 MinIO 默认的 path-style。
+	VirtualHostStyle bool
+	RequestTimeout   time.Duration
+}
+
+// Client 是基于标准库实现的最小 S3 客户端，只支持 ListObjectsV2 与 GetObject，请求使用 SigV4 签名。
+type Client struct {
+	endpoint         *url.URL
+	endpointErr      error
+	region           string
+	credentials      credentials
+	virtualHostStyle bool
+	httpClient       *http.Client
+	now              func() time.Time
+}
+
+// NewClient 创建对象存储客户端；endpoint 为空时客户端可以注入，但调用会返回 ErrNotConfigured。
+func NewClient(cfg Config) *Client {
+	timeout := cfg.RequestTimeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	client := &Client{
+		region:           strings.TrimSpace(cfg.Region),
+		credentials:      credentials{accessKey: strings.TrimSpace(cfg.AccessKey), secretKey: strings.TrimSpace(cfg.SecretKey)},
+		virtualHostStyle: cfg.VirtualHostStyle,
+		httpClient:       &http.Client{Timeout: timeout},
+		now:              time.Now,
+	}
+	if client.region == "" {
+		client.region = defaultRegion
+	}
+	client.endpoint, client.endpointErr = parseEndpoint(cfg.Endpoint)
+	return client
+}
+
+func parseEndpoint(raw string) (*url.URL, error) {
+	trimmed := strings.TrimRight(strings.TrimSpace(raw), "/")
+	if trimmed == "" {
+		return nil, ErrNotConfigured
+	}
+	if !strings.Contains(trimmed, "://") {
+		trimmed = "https://" + trimmed
+	}
+	endpoint, err := url.Parse(trimmed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidEndpoint, err)
+	}
+	if (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("%w: %q", errInvalidEndpoint, raw)
+	}
+	return endpoint, nil
+}
+
+type listBucketResult struct {
+	IsTruncated           bool          `xml:"IsTruncated"`
+	NextContinuationToken string        `xml:"NextContinuationToken"`
+	Contents              []listContent `xml:"Contents"`
+}
+
+type listContent struct {
+	Key          string    `xml:"Key"`
+	ETag         string    `xml:"ETag"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+type errorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// ListObjects 按 ListObjectsV2 分页列出前缀下的对象，跳过以 "/" 结尾的目录占位对象。
+func (c *Client) ListObjects(ctx context.Context, bucket, prefix string) ([]sourcebindingentity.ObjectStorageObject, error) {
+	objects := make([]sourcebindingentity.ObjectStorageObject, 0)
+	continuationToken := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "max-keys": {listPageSize}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+		page, err := c.listPage(ctx, bucket, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, content := range page.Contents {
+			if strings.HasSuffix(content.Key, "/") {
+				continue
+			}
+			objects = append(objects, sourcebindingentity.ObjectStorageObject{
+				Key:          content.Key,
+				ETag:         sourcebindingentity.NormalizeObjectStorageETag(content.ETag),
+				Size:         content.Size,
+				LastModified: content.LastModified,
+			})
+		}
+		if len(objects) > maxListedObjects {
+			return nil, fmt.Errorf("%w: %s/%s exceeds %d", errTooManyObjects, bucket, prefix, maxListedObjects)
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		continuationToken = page.NextContinuationToken
+	}
+}
+
+func (c *Client) listPage(ctx context.Context, bucket string, query url.Values) (*listBucketResult, error) {
+	resp, err := c.do(ctx, bucket, "", query)
+	if err != nil {
+		return nil, fmt.Errorf("list objects in %s: %w", bucket, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var page listBucketResult
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, maxListResponseBytes)).Decode(&page); err != nil {
+		return nil, fmt.Errorf("decode list objects response: %w", err)
+	}
+	return &page, nil
+}
+
+// ReadObject 读取单个对象内容，调用方负责关闭返回的 ReadCloser。
+func (c *Client) ReadObject(ctx context.Context, source documentdomain.ObjectStorageSource) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, source.Bucket, source.Key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get object %s/%s: %w", source.Bucket, source.Key, err)
+	}
+	return resp.Body, nil
+}
+
+// do 发送签名后的 GET 请求；非 2xx 响应会读取 S3 错误码后关闭响应体并返回错误。
+func (c *Client) do(ctx context.Context, bucket, key string, query url.Values) (*http.Response, error) {
+	if c.endpointErr != nil {
+		return nil, c.endpointErr
+	}
+	requestURL := c.objectURL(bucket, key, query)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("build object storage request: %w", err)
+	}
+	// NewRequest 会重新解析 URL，这里恢复签名时使用的编码形式，确保线上路径与签名一致。
+	req.URL = requestURL
+	signRequest(req, c.credentials, c.region, c.now())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send object storage request: %w", err)
+	}
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return resp, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var failure errorResponse
+	_ = xml.NewDecoder(io.LimitReader(resp.Body, maxErrorResponseBytes)).Decode(&failure)
+	return nil, fmt.Errorf("%w: %d %s %s", errUnexpectedStatus, resp.StatusCode, failure.Code, failure.Message)
+}
+
+func (c *Client) objectURL(bucket, key string, query url.Values) *url.URL {
+	host := c.endpoint.Host
+	objectPath := "/" + bucket
+	if c.virtualHostStyle {
+		host = bucket + "." + host
+		objectPath = ""
+	}
+	if key != "" {
+		objectPath += "/" + key
+	}
+	basePath := strings.TrimRight(c.endpoint.Path, "/")
+	return &url.URL{
+		Scheme:   c.endpoint.Scheme,
+		Host:     host,
+		Path:     basePath + objectPath,
+		RawPath:  uriEncode(basePath+objectPath, false),
+		RawQuery: canonicalQueryString(query),
+	}
+}
@@ -0,0 +1,102 @@
+package objectstorage_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+	"magic/internal/infrastructure/external/objectstorage"
+)
+
+const listPageTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Name>reports</Name>
+  <IsTruncated>%t</IsTruncated>
+  <NextContinuationToken>%s</NextContinuationToken>
+  %s
+</ListBucketResult>`
+
+func listContent(key, etag string, size int) string {
+	return fmt.Sprintf(
+		"<Contents><Key>%s</Key><ETag>&quot;%s&quot;</ETag><Size>%d</Size><LastModified>2026-04-01T08:00:00.000Z</LastModified></Contents>",
+		key, etag, size,
+	)
+}
+
+func TestClientListObjectsPaginatesWithPathStyle(t *testing.T) {
+	t.Parallel()
+
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/reports" || r.URL.Query().Get("list-type") != "2" || r.URL.Query().Get("prefix") != "2026/" {
+			http.Error(w, "unexpected request "+r.URL.String(), http.StatusBadRequest)
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AK/") || r.Header.Get("X-Amz-Date") == "" {
+			http.Error(w, "missing signature", http.StatusForbidden)
+			return
+		}
+		token := r.URL.Query().Get("continuation-token")
+		tokens = append(tokens, token)
+		if token == "" {
+			_, _ = fmt.Fprintf(w, listPageTemplate, true, "page-2", listContent("2026/", "dir", 0)+listContent("2026/q1.csv", "e1", 10))
+			return
+		}
+		_, _ = fmt.Fprintf(w, listPageTemplate, false, "", listContent("2026/q2 final.csv", "e2", 20))
+	}))
+	defer server.Close()
+
+	client := objectstorage.NewClient(objectstorage.Config{Endpoint: server.URL, AccessKey: "AK", SecretKey: "SK"})
+	objects, err := client.ListObjects(context.Background(), "reports", "2026/")
+	if err != nil {
+		t.Fatalf("list objects: %v", err)
+	}
+	if len(tokens) != 2 || tokens[1] != "page-2" {
+		t.Fatalf("unexpected continuation tokens: %v", tokens)
+	}
+	if len(objects) != 2 || objects[0].Key != "2026/q1.csv" || objects[0].ETag != "e1" || objects[0].Size != 10 ||
+		objects[1].Key != "2026/q2 final.csv" || objects[1].LastModified.IsZero() {
+		t.Fatalf("unexpected objects: %#v", objects)
+	}
+}
+
+func TestClientReadObjectAndErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() == "/reports/2026/q1%20final.md" {
+			_, _ = io.WriteString(w, "# Q1")
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, "<Error><Code>NoSuchKey</Code><Message>missing</Message></Error>")
+	}))
+	defer server.Close()
+
+	client := objectstorage.NewClient(objectstorage.Config{Endpoint: server.URL})
+	reader, err := client.ReadObject(context.Background(), documentdomain.ObjectStorageSource{Bucket: "reports", Key: "2026/q1 final.md"})
+	if err != nil {
+		t.Fatalf("read object: %v", err)
+	}
+	content, _ := io.ReadAll(reader)
+	_ = reader.Close()
+	if string(content) != "# Q1" {
+		t.Fatalf("unexpected content %q", content)
+	}
+
+	_, err = client.ReadObject(context.Background(), documentdomain.ObjectStorageSource{Bucket: "reports", Key: "gone.md"})
+	if err == nil || !strings.Contains(err.Error(), "NoSuchKey") {
+		t.Fatalf("expected NoSuchKey error, got %v", err)
+	}
+
+	unconfigured := objectstorage.NewClient(objectstorage.Config{})
+	if _, err := unconfigured.ListObjects(context.Background(), "reports", ""); !errors.Is(err, objectstorage.ErrNotConfigured) {
+		t.Fatalf("expected ErrNotConfigured, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package objectstorage
+
+import (
+	"cmp"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	signingAlgorithm  = "AWS4-HMAC-SHA256"
+	signingService    = "s3"
+	signingTerminator = "aws4_request"
+	amzDateLayout     = "20060102T150405Z"
+	amzDayLayout      = "20060102"
+	headerAmzDate     = "X-Amz-Date"
+	headerContentHash = "X-Amz-Content-Sha256"
+	signedHeaderNames = "host;x-amz-content-sha256;x-amz-date"
+	// emptyPayloadHash 是空请求体的 SHA256，本客户端只发送 GET 请求。
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+type credentials struct {
+	accessKey string
+	secretKey string
+}
+
+// signRequest 按 AWS Signature Version 4 为无请求体的请求签名；未配置访问密钥时按匿名请求发送。
+func signRequest(req *http.Request, creds credentials, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(amzDateLayout)
+	req.Header.Set(headerAmzDate, amzDate)
+	req.Header.Set(headerContentHash, emptyPayloadHash)
+	if creds.accessKey == "" || creds.secretKey == "" {
+		return
+	}
+	scope := strings.Join([]string{now.Format(amzDayLayout), region, signingService, signingTerminator}, "/")
+	signature := computeSignature(req, creds.secretKey, region, amzDate, scope)
+	req.Header.Set("Authorization", signingAlgorithm+
+		" Credential="+creds.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaderNames+
+		", Signature="+signature)
+}
+
+func computeSignature(req *http.Request, secretKey, region, amzDate, scope string) string {
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		cmp.Or(req.URL.EscapedPath(), "/"),
+		canonicalQueryString(req.URL.Query()),
+		"host:" + requestHost(req) + "\n" +
+			"x-amz-content-sha256:" + emptyPayloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaderNames,
+		emptyPayloadHash,
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		signingAlgorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), amzDate[:len(amzDayLayout)])
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, signingService)
+	key = hmacSHA256(key, signingTerminator)
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func requestHost(req *http.Request) string {
+	if req.Host != "" {
+		return req.Host
+	}
+	return req.URL.Host
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQueryString 按参数名排序并使用 SigV4 的 URI 编码规则拼接查询串。
+func canonicalQueryString(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := slices.Clone(query[key])
+		slices.Sort(values)
+		for _, value := range values {
+			parts = append(parts, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode 只保留 RFC 3986 非保留字符，其余按 UTF-8 字节百分号编码；encodeSlash 为 false 时保留 "/"。
+func uriEncode(value string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+	var builder strings.Builder
+	for index := range len(value) {
+		ch := value[index]
+		switch {
+		case ch >= 'A' && ch <= 'Z', ch >= 'a' && ch <= 'z', ch >= '0' && ch <= '9',
+			ch == '-', ch == '_', ch == '.', ch == '~':
+			builder.WriteByte(ch)
+		case ch == '/' && !encodeSlash:
+			builder.WriteByte(ch)
+		default:
+			builder.WriteByte('%')
+			builder.WriteByte(hexDigits[ch>>4])
+			builder.WriteByte(hexDigits[ch&0x0f])
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,437 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+	"magic/internal/domain/knowledge/shared"
+	"magic/internal/infrastructure/logging"
+)
+
+const (
+	// LocalProtocolTesseract 表示 tesseract-server 兼容协议：multipart 上传 file 与 options。
+	LocalProtocolTesseract = "tesseract"
+	// LocalProtocolPaddle 表示 PaddleOCR hub serving 兼容协议：JSON base64 images。
+	LocalProtocolPaddle = "paddle"
+
+	localBytesCacheMod            = "ocr:local:bytes"
+	defaultLocalRequestTimeout    = 120 * time.Second
+	defaultLocalMaxSourceBytes    = 50 << 20
+	maxLocalErrorBodyBytes        = 4 << 10
+	maxLocalResponseBytes         = 32 << 20
+	localPaddleSuccessStatus      = "000"
+	localPageSeparator            = "\n\n"
+	localTesseractOptionsFormName = "options"
+	localFileFormName             = "file"
+	defaultLocalLanguage          = "eng"
+	localRenderedPageFileType     = "jpg"
+)
+
+var (
+	errLocalOCRSourceTooLarge   = errors.New("local ocr source exceeds size limit")
+	errLocalOCRUnexpectedStatus = errors.New("local ocr unexpected response status")
+	errLocalOCRDownloadFailed   = errors.New("download ocr source failed")
+	errLocalOCRResponseTooLarge = errors.New("local ocr response exceeds size limit")
+)
+
+// LocalConfig 描述自部署 OCR 服务的运行参数，endpoint 可被组织 OCR 配置覆盖。
+type LocalConfig struct {
+	Endpoint       string
+	Protocol       string
+	Languages      []string
+	RequestTimeout time.Duration
+	MaxSourceBytes int64
+}
+
+// PDFPageImageRenderer 将 PDF 逐页渲染为图片，供只接受图片的 OCR 服务识别扫描件。
+type PDFPageImageRenderer interface {
+	RenderPDFPageImages(ctx context.Context, data []byte, handle func(image []byte) error) error
+}
+
+// LocalOCRClient 调用自部署 OCR HTTP 服务（PaddleOCR / tesseract-server 兼容），适用于离线部署。
+type LocalOCRClient struct {
+	ocrRuntime
+
+	configProvider documentdomain.OCRConfigProviderPort
+	config         LocalConfig
+	httpClient     *http.Client
+	pdfRenderer    PDFPageImageRenderer
+	invokeOCR      func(ctx context.Context, config *shared.OCRConfig, data []byte, fileType string) (string, error)
+}
+
+// NewLocalOCRClient 创建自部署 OCR 客户端。
+func NewLocalOCRClient(
+	configProvider documentdomain.OCRConfigProviderPort,
+	cacheRepo documentdomain.OCRResultCacheRepository,
+	config LocalConfig,
+	logger *logging.SugaredLogger,
+) *LocalOCRClient {
+	config = normalizeLocalConfig(config)
+	client := &LocalOCRClient{
+		ocrRuntime: ocrRuntime{
+			cacheRepo:  cacheRepo,
+			logger:     logger,
+			cacheModel: localBytesCacheMod,
+		},
+		configProvider: configProvider,
+		config:         config,
+		httpClient:     &http.Client{Timeout: config.RequestTimeout},
+	}
+	client.invokeOCR = client.callLocalOCR
+	return client
+}
+
+// SetUsageReporter 注入可选的 OCR 用量上报器。
+func (c *LocalOCRClient) SetUsageReporter(reporter documentdomain.OCRUsageReporterPort) {
+	if c == nil {
+		return
+	}
+	c.usageReporter = reporter
+}
+
+// SetRateLimiter 注入可选的 OCR 限流器，与火山 OCR 共用同一令牌桶配置。
+func (c *LocalOCRClient) SetRateLimiter(limiter RateLimiter, config RateLimitConfig) {
+	if c == nil {
+		return
+	}
+	c.setRateLimiter(limiter, config)
+}
+
+// SetPDFPageImageRenderer 注入可选的 PDF 逐页渲染器；未注入时 PDF 原样提交给 OCR 服务。
+func (c *LocalOCRClient) SetPDFPageImageRenderer(renderer PDFPageImageRenderer) {
+	if c == nil {
+		return
+	}
+	c.pdfRenderer = renderer
+}
+
+// OCR 下载文件 URL 后交给自部署服务识别，离线部署下 URL 指向内网文件存储。
+func (c *LocalOCRClient) OCR(ctx context.Context, fileURL, fileType string) (string, error) {
+	if c == nil || c.configProvider == nil {
+		return "", documentdomain.ErrOCRDisabled
+	}
+	if _, err := normalizeOCRFileType(fileType); err != nil {
+		return "", err
+	}
+	data, err := c.download(ctx, fileURL)
+	if err != nil {
+		return "", newOCRExecutionError(err)
+	}
+	return c.recognize(ctx, data, fileType, ocrCallTypeURL)
+}
+
+// OCRSource 基于已下载的源文件内容执行 OCR，并按内容 hash 缓存结果。
+func (c *LocalOCRClient) OCRSource(ctx context.Context, _ string, file io.Reader, fileType string) (string, error) {
+	if c == nil || c.configProvider == nil {
+		return "", documentdomain.ErrOCRDisabled
+	}
+	if file == nil {
+		return "", fmt.Errorf("%w: empty source", ErrOCRFailed)
+	}
+	if _, err := normalizeOCRFileType(fileType); err != nil {
+		return "", err
+	}
+	data, err := readLimited(file, c.config.MaxSourceBytes)
+	if err != nil {
+		return "", fmt.Errorf("read ocr source: %w", err)
+	}
+	return c.recognize(ctx, data, fileType, ocrCallTypeSource)
+}
+
+// OCRBytes 解析图片字节流获取文本。
+func (c *LocalOCRClient) OCRBytes(ctx context.Context, data []byte, fileType string) (string, error) {
+	if c == nil || c.configProvider == nil {
+		return "", documentdomain.ErrOCRDisabled
+	}
+	ocrFileType, err := normalizeOCRFileType(fileType)
+	if err != nil {
+		return "", err
+	}
+	if ocrFileType != normalizedOCRImageType {
+		return "", fmt.Errorf("%w: %s", documentdomain.ErrUnsupportedOCRFileType, fileType)
+	}
+	return c.recognize(ctx, data, fileType, ocrCallTypeBytes)
+}
+
+func (c *LocalOCRClient) recognize(ctx context.Context, data []byte, fileType, callType string) (string, error) {
+	if len(data) == 0 {
+		return "", fmt.Errorf("%w: empty source", ErrOCRFailed)
+	}
+	ocrFileType, err := normalizeOCRFileType(fileType)
+	if err != nil {
+		return "", err
+	}
+	ocrConfig, provider, err := c.resolveConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	cacheHash := buildOCRBytesCacheHash(provider, fileType, data)
+	if cached, ok := c.lookupCachedBytesPayload(ctx, cacheHash); ok {
+		return cached, nil
+	}
+
+	if err := c.waitRateLimit(ctx, provider); err != nil {
+		return "", err
+	}
+	content, pageCount, err := c.invoke(ctx, ocrConfig, data, normalizeCacheFileType(fileType), ocrFileType)
+	if err != nil {
+		if c.logger != nil {
+			c.logger.KnowledgeErrorContext(ctx, "自部署 OCR 调用失败", "provider", provider, "file_type", ocrFileType, "endpoint", ocrConfig.Endpoint, "error", err)
+		}
+		return "", newOCRExecutionError(fmt.Errorf("invoke local ocr: %w", err))
+	}
+
+	c.reportOCRUsage(ctx, provider, ocrFileType, callType, pageCount)
+	c.persistCachedBytesPayload(ctx, cacheHash, provider, fileType, content)
+	return content, nil
+}
+
+func (c *LocalOCRClient) resolveConfig(ctx context.Context) (*shared.OCRConfig, string, error) {
+	abilityConfig, err := c.configProvider.GetOCRConfig(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("load ocr config: %w", err)
+	}
+	ocrConfig, provider, err := abilityConfig.ResolveLocalConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("resolve ocr provider config: %w", err)
+	}
+	if ocrConfig.Endpoint == "" {
+		ocrConfig.Endpoint = c.config.Endpoint
+	}
+	if ocrConfig.Endpoint == "" {
+		return nil, "", fmt.Errorf("resolve ocr provider config: %w", documentdomain.ErrOCREndpointMissing)
+	}
+	return ocrConfig, provider, nil
+}
+
+// invoke 返回识别文本与计费页数；配置了渲染器时 PDF 逐页转图片识别。
+func (c *LocalOCRClient) invoke(
+	ctx context.Context,
+	config *shared.OCRConfig,
+	data []byte,
+	fileType string,
+	ocrFileType string,
+) (string, int, error) {
+	if ocrFileType != normalizedOCRPDFType || c.pdfRenderer == nil {
+		content, err := c.invokeOCR(ctx, config, data, fileType)
+		if err != nil {
+			return "", 0, err
+		}
+		pageCount := c.resolveOCRSourcePageCount(ctx, bytes.NewReader(data), ocrFileType, int64(len(data)))
+		return content, pageCount, nil
+	}
+
+	pages := make([]string, 0)
+	err := c.pdfRenderer.RenderPDFPageImages(ctx, data, func(image []byte) error {
+		text, err := c.invokeOCR(ctx, config, image, localRenderedPageFileType)
+		if err != nil {
+			return err
+		}
+		pages = append(pages, strings.TrimSpace(text))
+		return nil
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("ocr rendered pdf pages: %w", err)
+	}
+	return strings.Join(pages, localPageSeparator), len(pages), nil
+}
+
+func (c *LocalOCRClient) callLocalOCR(
+	ctx context.Context,
+	config *shared.OCRConfig,
+	data []byte,
+	fileType string,
+) (string, error) {
+	contentType, body, err := c.buildRequestBody(data, fileType)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.Endpoint, body)
+	if err != nil {
+		return "", fmt.Errorf("build local ocr request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if config.Identity != "" {
+		req.Header.Set("Authorization", "Bearer "+config.Identity)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("call local ocr: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, maxLocalResponseBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("read local ocr response: %w", err)
+	}
+	if len(payload) > maxLocalResponseBytes {
+		return "", fmt.Errorf("%w: %d bytes", errLocalOCRResponseTooLarge, maxLocalResponseBytes)
+	}
+	if resp.StatusCode != http.StatusOK {
+		failure := fmt.Errorf("%w: %d %s", errLocalOCRUnexpectedStatus, resp.StatusCode, truncateLocalErrorBody(payload))
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			return "", fmt.Errorf("%w", documentdomain.NewOCROverloadedError(documentdomain.OCRProviderLocal, failure))
+		}
+		return "", failure
+	}
+	return parseLocalOCRResponse(payload)
+}
+
+func (c *LocalOCRClient) buildRequestBody(data []byte, fileType string) (string, io.Reader, error) {
+	if c.config.Protocol == LocalProtocolPaddle {
+		payload, err := json.Marshal(map[string][]string{
+			"images": {base64.StdEncoding.EncodeToString(data)},
+		})
+		if err != nil {
+			return "", nil, fmt.Errorf("marshal local ocr request: %w", err)
+		}
+		return "application/json", bytes.NewReader(payload), nil
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	options, err := json.Marshal(map[string][]string{"languages": c.config.Languages})
+	if err != nil {
+		return "", nil, fmt.Errorf("marshal local ocr options: %w", err)
+	}
+	if err := writer.WriteField(localTesseractOptionsFormName, string(options)); err != nil {
+		return "", nil, fmt.Errorf("write local ocr options: %w", err)
+	}
+	part, err := writer.CreateFormFile(localFileFormName, "source."+fileType)
+	if err != nil {
+		return "", nil, fmt.Errorf("create local ocr file part: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", nil, fmt.Errorf("write local ocr file part: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", nil, fmt.Errorf("close local ocr multipart body: %w", err)
+	}
+	return writer.FormDataContentType(), &buf, nil
+}
+
+func (c *LocalOCRClient) download(ctx context.Context, fileURL string) ([]byte, error) {
+	if strings.TrimSpace(fileURL) == "" {
+		return nil, fmt.Errorf("%w: empty url", errLocalOCRDownloadFailed)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build ocr source request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errLocalOCRDownloadFailed, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", errLocalOCRDownloadFailed, resp.StatusCode)
+	}
+	return readLimited(resp.Body, c.config.MaxSourceBytes)
+}
+
+// parseLocalOCRResponse 兼容 tesseract-server、PaddleOCR hub serving 与纯 text/markdown 响应。
+func parseLocalOCRResponse(payload []byte) (string, error) {
+	var result struct {
+		Text     string `json:"text"`
+		Markdown string `json:"markdown"`
+		Status   string `json:"status"`
+		Msg      string `json:"msg"`
+		Data     struct {
+			Stdout string `json:"stdout"`
+			Stderr string `json:"stderr"`
+		} `json:"data"`
+		Results [][]struct {
+			Text string `json:"text"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(payload, &result); err != nil {
+		return "", fmt.Errorf("unmarshal local ocr response: %w", err)
+	}
+	if result.Status != "" && result.Status != localPaddleSuccessStatus {
+		return "", fmt.Errorf("%w: status=%s, message=%s", ErrOCRFailed, result.Status, result.Msg)
+	}
+	switch {
+	case result.Markdown != "":
+		return result.Markdown, nil
+	case result.Text != "":
+		return result.Text, nil
+	case result.Data.Stdout != "":
+		return result.Data.Stdout, nil
+	case len(result.Results) > 0:
+		lines := make([]string, 0)
+		for _, image := range result.Results {
+			for _, line := range image {
+				lines = append(lines, line.Text)
+			}
+		}
+		return strings.Join(lines, "\n"), nil
+	case result.Data.Stderr != "":
+		return "", fmt.Errorf("%w: %s", ErrOCRFailed, strings.TrimSpace(result.Data.Stderr))
+	default:
+		return "", nil
+	}
+}
+
+func readLimited(reader io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("read ocr source: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: %d bytes", errLocalOCRSourceTooLarge, limit)
+	}
+	return data, nil
+}
+
+func truncateLocalErrorBody(payload []byte) string {
+	if len(payload) > maxLocalErrorBodyBytes {
+		payload = payload[:maxLocalErrorBodyBytes]
+	}
+	return strings.TrimSpace(string(payload))
+}
+
+func normalizeLocalConfig(config LocalConfig) LocalConfig {
+	config.Endpoint = strings.TrimSpace(config.Endpoint)
+	config.Protocol = strings.ToLower(strings.TrimSpace(config.Protocol))
+	if config.Protocol != LocalProtocolPaddle {
+		config.Protocol = LocalProtocolTesseract
+	}
+	languages := make([]string, 0, len(config.Languages))
+	for _, language := range config.Languages {
+		if trimmed := strings.TrimSpace(language); trimmed != "" {
+			languages = append(languages, trimmed)
+		}
+	}
+	if len(languages) == 0 {
+		languages = []string{defaultLocalLanguage}
+	}
+	config.Languages = languages
+	if config.RequestTimeout <= 0 {
+		config.RequestTimeout = defaultLocalRequestTimeout
+	}
+	if config.MaxSourceBytes <= 0 {
+		config.MaxSourceBytes = defaultLocalMaxSourceBytes
+	}
+	return config
+}
+
+// SetInvokeHookForTest 设置自部署 OCR 调用测试钩子，fileType 为原始扩展名或渲染页的 jpg。
+func (c *LocalOCRClient) SetInvokeHookForTest(fn func(context.Context, string, []byte, string) (string, error)) {
+	if c == nil || fn == nil {
+		return
+	}
+	c.invokeOCR = func(ctx context.Context, config *shared.OCRConfig, data []byte, fileType string) (string, error) {
+		return fn(ctx, config.Endpoint, data, fileType)
+	}
+}
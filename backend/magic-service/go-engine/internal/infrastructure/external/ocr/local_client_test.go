@@ -0,0 +1,262 @@
+package ocr_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+	ocr "magic/internal/infrastructure/external/ocr"
+	"magic/internal/infrastructure/logging"
+	"magic/internal/pkg/ratelimit"
+)
+
+func enabledLocalOCRConfig(endpoint string) *documentdomain.OCRAbilityConfig {
+	return &documentdomain.OCRAbilityConfig{
+		Enabled:      true,
+		ProviderCode: documentdomain.OCRProviderLocal,
+		Providers: []documentdomain.OCRProviderConfig{
+			{Provider: documentdomain.OCRProviderLocal, Enable: true, Endpoint: endpoint, AccessKey: "local-token"},
+		},
+	}
+}
+
+type pdfPageImageRendererStub struct {
+	pages [][]byte
+}
+
+func (s *pdfPageImageRendererStub) RenderPDFPageImages(_ context.Context, _ []byte, handle func([]byte) error) error {
+	for _, page := range s.pages {
+		if err := handle(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestLocalOCRClientTesseractProtocolUploadsFileAndCaches(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Authorization") != "Bearer local-token" {
+			t.Errorf("unexpected authorization header %q", r.Header.Get("Authorization"))
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("read multipart file: %v", err)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		if string(data) != "image-bytes" || header.Filename != "source.png" {
+			t.Errorf("unexpected upload %q name=%q", string(data), header.Filename)
+		}
+		if r.FormValue("options") != `{"languages":["chi_sim","eng"]}` {
+			t.Errorf("unexpected options %q", r.FormValue("options"))
+		}
+		_, _ = w.Write([]byte(`{"data":{"stdout":"你好 world","stderr":""}}`))
+	}))
+	defer server.Close()
+
+	reporter := &ocrUsageReporterStub{}
+	client := ocr.NewLocalOCRClient(
+		&ocrConfigProviderStub{cfg: enabledLocalOCRConfig("")},
+		newOCRCacheRepoStub(),
+		ocr.LocalConfig{Endpoint: server.URL, Languages: []string{"chi_sim", " eng "}, RequestTimeout: time.Second},
+		logging.New(),
+	)
+	client.SetUsageReporter(reporter)
+
+	for range 2 {
+		content, err := client.OCRBytes(newOCRUsageTestContext(), []byte("image-bytes"), "png")
+		if err != nil {
+			t.Fatalf("OCRBytes returned error: %v", err)
+		}
+		if content != "你好 world" {
+			t.Fatalf("unexpected OCR content: %q", content)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected second call to hit cache, got %d server calls", calls)
+	}
+	reports := reporter.snapshot()
+	if len(reports) != 1 || reports[0].Provider != documentdomain.OCRProviderLocal || reports[0].PageCount != 1 || reports[0].CallType != "bytes" {
+		t.Fatalf("unexpected usage reports: %#v", reports)
+	}
+}
+
+func TestLocalOCRClientPaddleProtocolJoinsRecognizedLines(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Images []string `json:"images"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode paddle request: %v", err)
+		}
+		if len(request.Images) != 1 || request.Images[0] != base64.StdEncoding.EncodeToString([]byte("scan")) {
+			t.Errorf("unexpected paddle images: %#v", request.Images)
+		}
+		_, _ = w.Write([]byte(`{"msg":"","status":"000","results":[[{"text":"line 1"},{"text":"line 2"}]]}`))
+	}))
+	defer server.Close()
+
+	client := ocr.NewLocalOCRClient(
+		&ocrConfigProviderStub{cfg: enabledLocalOCRConfig(server.URL)},
+		nil,
+		ocr.LocalConfig{Protocol: "Paddle"},
+		logging.New(),
+	)
+	content, err := client.OCRSource(context.Background(), "", bytes.NewReader([]byte("scan")), "jpg")
+	if err != nil {
+		t.Fatalf("OCRSource returned error: %v", err)
+	}
+	if content != "line 1\nline 2" {
+		t.Fatalf("unexpected OCR content: %q", content)
+	}
+}
+
+func TestLocalOCRClientRendersPDFPagesWhenRendererConfigured(t *testing.T) {
+	t.Parallel()
+
+	reporter := &ocrUsageReporterStub{}
+	client := ocr.NewLocalOCRClient(
+		&ocrConfigProviderStub{cfg: enabledLocalOCRConfig("http://ocr.internal")},
+		nil,
+		ocr.LocalConfig{},
+		logging.New(),
+	)
+	client.SetUsageReporter(reporter)
+	client.SetPDFPageImageRenderer(&pdfPageImageRendererStub{pages: [][]byte{[]byte("p1"), []byte("p2")}})
+	client.SetInvokeHookForTest(func(_ context.Context, endpoint string, data []byte, fileType string) (string, error) {
+		if endpoint != "http://ocr.internal" || fileType != "jpg" {
+			t.Fatalf("unexpected invoke endpoint=%q fileType=%q", endpoint, fileType)
+		}
+		return "text-" + string(data), nil
+	})
+
+	content, err := client.OCRSource(newOCRUsageTestContext(), "", bytes.NewReader([]byte("%PDF-1.7")), "pdf")
+	if err != nil {
+		t.Fatalf("OCRSource returned error: %v", err)
+	}
+	if content != "text-p1\n\ntext-p2" {
+		t.Fatalf("unexpected OCR content: %q", content)
+	}
+	reports := reporter.snapshot()
+	if len(reports) != 1 || reports[0].PageCount != 2 || reports[0].FileType != normalizedOCRPDFFileType {
+		t.Fatalf("unexpected usage reports: %#v", reports)
+	}
+}
+
+func TestLocalOCRClientErrors(t *testing.T) {
+	t.Parallel()
+
+	missingEndpoint := ocr.NewLocalOCRClient(
+		&ocrConfigProviderStub{cfg: enabledLocalOCRConfig("")},
+		nil,
+		ocr.LocalConfig{},
+		logging.New(),
+	)
+	if _, err := missingEndpoint.OCRBytes(context.Background(), []byte("x"), "png"); !errors.Is(err, documentdomain.ErrOCREndpointMissing) {
+		t.Fatalf("expected endpoint missing error, got %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	overloaded := ocr.NewLocalOCRClient(
+		&ocrConfigProviderStub{cfg: enabledLocalOCRConfig(server.URL)},
+		nil,
+		ocr.LocalConfig{},
+		logging.New(),
+	)
+	_, err := overloaded.OCRBytes(context.Background(), []byte("x"), "png")
+	if !documentdomain.IsOCROverloaded(err) {
+		t.Fatalf("expected overload error, got %v", err)
+	}
+	var userMessage executionUserMessageProvider
+	if !errors.As(err, &userMessage) || userMessage.ExecutionUserMessage() == "" {
+		t.Fatalf("expected execution user message, got %v", err)
+	}
+
+	volcengineSelected := ocr.NewLocalOCRClient(
+		&ocrConfigProviderStub{cfg: enabledVolcengineOCRConfig()},
+		nil,
+		ocr.LocalConfig{Endpoint: server.URL},
+		logging.New(),
+	)
+	if _, err := volcengineSelected.OCRBytes(context.Background(), []byte("x"), "png"); !errors.Is(err, documentdomain.ErrOCRProviderUnsupported) {
+		t.Fatalf("expected unsupported provider error, got %v", err)
+	}
+}
+
+func TestLocalOCRClientWaitsForRateLimitBeforeInvoke(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"data":{"stdout":"text","stderr":""}}`))
+	}))
+	defer server.Close()
+
+	limiter := &ocrRateLimiterStub{}
+	client := ocr.NewLocalOCRClient(
+		&ocrConfigProviderStub{cfg: enabledLocalOCRConfig(server.URL)},
+		nil,
+		ocr.LocalConfig{},
+		logging.New(),
+	)
+	client.SetRateLimiter(limiter, ocr.RateLimitConfig{Key: "ocr:local", WaitTimeout: time.Second})
+	if _, err := client.OCRBytes(context.Background(), []byte("x"), "png"); err != nil {
+		t.Fatalf("OCRBytes returned error: %v", err)
+	}
+	if limiter.calls != 1 || limiter.key != "ocr:local" || limiter.timeout != time.Second || calls != 1 {
+		t.Fatalf("expected one rate limit wait before invoke, limiter=%#v calls=%d", limiter, calls)
+	}
+
+	limiter.err = ratelimit.ErrWaitTimeout
+	_, err := client.OCRBytes(context.Background(), []byte("y"), "png")
+	if !documentdomain.IsOCROverloaded(err) || !errors.Is(err, ratelimit.ErrWaitTimeout) {
+		t.Fatalf("expected rate limit overload error, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected OCR invoke to be skipped after limiter timeout, got %d calls", calls)
+	}
+}
+
+func TestLocalOCRClientRejectsOversizedResponse(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		chunk := bytes.Repeat([]byte("a"), 1<<20)
+		for range 33 {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	client := ocr.NewLocalOCRClient(
+		&ocrConfigProviderStub{cfg: enabledLocalOCRConfig(server.URL)},
+		nil,
+		ocr.LocalConfig{RequestTimeout: 5 * time.Second},
+		logging.New(),
+	)
+	_, err := client.OCRBytes(context.Background(), []byte("x"), "png")
+	if err == nil || !strings.Contains(err.Error(), "response exceeds size limit") {
+		t.Fatalf("expected oversized response to fail, got %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+	"magic/internal/infrastructure/logging"
+	"magic/internal/pkg/ctxmeta"
+	"magic/internal/pkg/ratelimit"
+)
+
+// ocrRuntime 汇总各 OCR provider 共用的结果缓存、用量上报与限流能力。
+type ocrRuntime struct {
+	cacheRepo     documentdomain.OCRResultCacheRepository
+	logger        *logging.SugaredLogger
+	usageReporter documentdomain.OCRUsageReporterPort
+	cacheModel    string
+	rateLimiter   RateLimiter
+	rateLimit     RateLimitConfig
+}
+
+func (r *ocrRuntime) setRateLimiter(limiter RateLimiter, config RateLimitConfig) {
+	r.rateLimiter = limiter
+	r.rateLimit = normalizeRateLimitConfig(config)
+}
+
+func (r *ocrRuntime) waitRateLimit(ctx context.Context, provider string) error {
+	if r == nil || r.rateLimiter == nil {
+		return nil
+	}
+	config := normalizeRateLimitConfig(r.rateLimit)
+	result, err := r.rateLimiter.Wait(ctx, config.Key, config.WaitTimeout)
+	if err == nil {
+		r.logRateLimitAcquired(ctx, provider, config, result)
+		return nil
+	}
+	r.logRateLimitFailed(ctx, provider, config, result, err)
+	return newOCRExecutionError(
+		documentdomain.NewOCROverloadedError(
+			provider,
+			fmt.Errorf("wait ocr rate limit token: %w", err),
+		),
+	)
+}
+
+func (r *ocrRuntime) logRateLimitAcquired(
+	ctx context.Context,
+	provider string,
+	config RateLimitConfig,
+	result ratelimit.Result,
+) {
+	if r == nil || r.logger == nil || result.Waited <= 0 {
+		return
+	}
+	r.logger.InfoContext(
+		ctx,
+		"Acquire ocr rate limit token",
+		"provider", provider,
+		"key", config.Key,
+		"wait_timeout_ms", config.WaitTimeout.Milliseconds(),
+		"waited_ms", result.Waited.Milliseconds(),
+		"remaining_tokens", result.Remaining,
+	)
+}
+
+func (r *ocrRuntime) logRateLimitFailed(
+	ctx context.Context,
+	provider string,
+	config RateLimitConfig,
+	result ratelimit.Result,
+	err error,
+) {
+	if r == nil || r.logger == nil {
+		return
+	}
+	message := "Wait ocr rate limit token failed"
+	if errors.Is(err, ratelimit.ErrWaitTimeout) {
+		message = "OCR rate limit wait timeout"
+	}
+	r.logger.KnowledgeWarnContext(
+		ctx,
+		message,
+		"provider", provider,
+		"key", config.Key,
+		"wait_timeout_ms", config.WaitTimeout.Milliseconds(),
+		"waited_ms", result.Waited.Milliseconds(),
+		"retry_after_ms", result.RetryAfter.Milliseconds(),
+		"remaining_tokens", result.Remaining,
+		"error", err,
+	)
+}
+
+func (r *ocrRuntime) reportOCRUsage(
+	ctx context.Context,
+	provider string,
+	fileType string,
+	callType string,
+	pageCount int,
+) {
+	if r == nil || r.usageReporter == nil {
+		return
+	}
+	usageMeta, ok := documentdomain.OCRUsageContextFromContext(ctx)
+	if !ok {
+		r.logSkipOCRUsageReport(ctx, provider, fileType, callType, "usage context missing")
+		return
+	}
+	usage := documentdomain.OCRUsage{
+		EventID:           uuid.NewString(),
+		Provider:          strings.TrimSpace(provider),
+		OrganizationCode:  strings.TrimSpace(usageMeta.OrganizationCode),
+		UserID:            strings.TrimSpace(usageMeta.UserID),
+		PageCount:         normalizeOCRUsagePageCount(fileType, pageCount),
+		FileType:          strings.TrimSpace(fileType),
+		BusinessID:        strings.TrimSpace(usageMeta.BusinessID),
+		SourceID:          strings.TrimSpace(usageMeta.SourceID),
+		KnowledgeBaseCode: strings.TrimSpace(usageMeta.KnowledgeBaseCode),
+		DocumentCode:      strings.TrimSpace(usageMeta.DocumentCode),
+		CallType:          strings.TrimSpace(callType),
+	}
+	if usage.BusinessID == "" {
+		usage.BusinessID = usage.KnowledgeBaseCode
+	}
+	if usage.SourceID == "" {
+		usage.SourceID = usage.DocumentCode
+	}
+	if requestID, found := ctxmeta.RequestIDFromContext(ctx); found {
+		usage.RequestID = requestID
+	}
+	if usage.Provider == "" || usage.OrganizationCode == "" || usage.UserID == "" || usage.PageCount <= 0 {
+		r.logSkipOCRUsageReport(ctx, provider, fileType, callType, "usage required field missing")
+		return
+	}
+	if err := r.usageReporter.ReportOCRUsage(ctx, usage); err != nil && r.logger != nil {
+		r.logger.KnowledgeWarnContext(
+			ctx,
+			"Report ocr usage failed",
+			"provider", usage.Provider,
+			"file_type", usage.FileType,
+			"call_type", usage.CallType,
+			"page_count", usage.PageCount,
+			"knowledge_base_code", usage.KnowledgeBaseCode,
+			"document_code", usage.DocumentCode,
+			"error", err,
+		)
+	}
+}
+
+func (r *ocrRuntime) logSkipOCRUsageReport(ctx context.Context, provider, fileType, callType, reason string) {
+	if r == nil || r.logger == nil {
+		return
+	}
+	r.logger.KnowledgeWarnContext(
+		ctx,
+		"Skip ocr usage report",
+		"provider", provider,
+		"file_type", fileType,
+		"call_type", callType,
+		"reason", reason,
+	)
+}
+
+func (r *ocrRuntime) resolveOCRSourcePageCount(
+	ctx context.Context,
+	file io.Reader,
+	fileType string,
+	size int64,
+) int {
+	if fileType != normalizedOCRPDFType {
+		return 1
+	}
+	pageCount, err := readPDFPageCount(file, size)
+	if err != nil {
+		if r != nil && r.logger != nil {
+			r.logger.KnowledgeWarnContext(
+				ctx,
+				"Read ocr pdf page count failed, fallback to one page",
+				"file_type", fileType,
+				"source_size", size,
+				"error", err,
+			)
+		}
+		return 1
+	}
+	return normalizeOCRUsagePageCount(fileType, pageCount)
+}
+
+func (r *ocrRuntime) lookupCachedBytesPayload(ctx context.Context, cacheHash string) (string, bool) {
+	if r == nil || r.cacheRepo == nil || strings.TrimSpace(cacheHash) == "" {
+		return "", false
+	}
+	cached, err := r.cacheRepo.FindBytesCache(ctx, cacheHash, r.cacheModel)
+	if err != nil {
+		if errors.Is(err, documentdomain.ErrOCRCacheNotFound) {
+			return "", false
+		}
+		if r.logger != nil {
+			r.logger.KnowledgeWarnContext(ctx, "读取 OCR 字节流缓存失败，跳过缓存", "text_hash", cacheHash, "error", err)
+		}
+		return "", false
+	}
+	if cached == nil {
+		return "", false
+	}
+	if err := r.cacheRepo.Touch(ctx, cached.ID); err != nil && r.logger != nil {
+		r.logger.KnowledgeWarnContext(ctx, "更新 OCR 字节流缓存访问统计失败", "cache_id", cached.ID, "error", err)
+	}
+	return cached.Content, true
+}
+
+func (r *ocrRuntime) persistCachedBytesPayload(
+	ctx context.Context,
+	cacheHash, provider, fileType, content string,
+) {
+	if r == nil || r.cacheRepo == nil || strings.TrimSpace(cacheHash) == "" {
+		return
+	}
+	if err := r.cacheRepo.UpsertBytesCache(ctx, &documentdomain.OCRResultCache{
+		TextHash:       cacheHash,
+		EmbeddingModel: r.cacheModel,
+		Content:        content,
+		FileType:       normalizeCacheFileType(fileType),
+	}); err != nil && r.logger != nil {
+		r.logger.KnowledgeWarnContext(ctx, "写入 OCR 字节流缓存失败", "provider", provider, "text_hash", cacheHash, "error", err)
+	}
+}
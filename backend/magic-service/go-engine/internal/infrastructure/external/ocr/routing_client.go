@@ -0,0 +1,95 @@
+package ocr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+)
+
+// 编译时检查各 provider 客户端均支持源文件 OCR。
+var (
+	_ documentdomain.OCRSourceClient = (*VolcengineOCRClient)(nil)
+	_ documentdomain.OCRSourceClient = (*LocalOCRClient)(nil)
+	_ documentdomain.OCRSourceClient = (*ProviderRoutingClient)(nil)
+)
+
+// ProviderRoutingClient 按组织 OCR 配置中生效的 provider 分发到对应 OCR 客户端。
+type ProviderRoutingClient struct {
+	configProvider documentdomain.OCRConfigProviderPort
+	clients        map[string]documentdomain.OCRSourceClient
+}
+
+// NewProviderRoutingClient 创建 OCR provider 路由客户端，clients 以 provider 编码为键且不区分大小写。
+func NewProviderRoutingClient(
+	configProvider documentdomain.OCRConfigProviderPort,
+	clients map[string]documentdomain.OCRSourceClient,
+) *ProviderRoutingClient {
+	normalized := make(map[string]documentdomain.OCRSourceClient, len(clients))
+	for provider, client := range clients {
+		if client != nil {
+			normalized[strings.ToLower(strings.TrimSpace(provider))] = client
+		}
+	}
+	return &ProviderRoutingClient{configProvider: configProvider, clients: normalized}
+}
+
+// OCR 解析文件 URL 获取文本。
+func (c *ProviderRoutingClient) OCR(ctx context.Context, fileURL, fileType string) (string, error) {
+	client, err := c.resolveClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	content, err := client.OCR(ctx, fileURL, fileType)
+	if err != nil {
+		return "", fmt.Errorf("route ocr: %w", err)
+	}
+	return content, nil
+}
+
+// OCRSource 基于已下载的源文件内容执行 OCR。
+func (c *ProviderRoutingClient) OCRSource(ctx context.Context, fileURL string, file io.Reader, fileType string) (string, error) {
+	client, err := c.resolveClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	content, err := client.OCRSource(ctx, fileURL, file, fileType)
+	if err != nil {
+		return "", fmt.Errorf("route ocr source: %w", err)
+	}
+	return content, nil
+}
+
+// OCRBytes 解析图片字节流获取文本。
+func (c *ProviderRoutingClient) OCRBytes(ctx context.Context, data []byte, fileType string) (string, error) {
+	client, err := c.resolveClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	content, err := client.OCRBytes(ctx, data, fileType)
+	if err != nil {
+		return "", fmt.Errorf("route ocr bytes: %w", err)
+	}
+	return content, nil
+}
+
+func (c *ProviderRoutingClient) resolveClient(ctx context.Context) (documentdomain.OCRSourceClient, error) {
+	if c == nil || c.configProvider == nil {
+		return nil, documentdomain.ErrOCRDisabled
+	}
+	abilityConfig, err := c.configProvider.GetOCRConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load ocr config: %w", err)
+	}
+	providerCode, err := abilityConfig.ResolveProviderCode()
+	if err != nil {
+		return nil, fmt.Errorf("resolve ocr provider: %w", err)
+	}
+	client, ok := c.clients[strings.ToLower(providerCode)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", documentdomain.ErrOCRProviderUnsupported, providerCode)
+	}
+	return client, nil
+}
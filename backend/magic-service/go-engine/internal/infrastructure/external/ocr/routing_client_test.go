@@ -0,0 +1,45 @@
+package ocr_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+	ocr "magic/internal/infrastructure/external/ocr"
+	"magic/internal/infrastructure/logging"
+)
+
+func TestProviderRoutingClientDispatchesByOrganizationProvider(t *testing.T) {
+	t.Parallel()
+
+	provider := &ocrConfigProviderStub{cfg: enabledLocalOCRConfig("http://ocr.internal")}
+	local := ocr.NewLocalOCRClient(provider, nil, ocr.LocalConfig{}, logging.New())
+	local.SetInvokeHookForTest(func(context.Context, string, []byte, string) (string, error) {
+		return "local", nil
+	})
+	volcengine := ocr.NewVolcengineOCRClient(provider, nil, logging.New())
+	volcengine.SetInvokeBytesHookForTest(func(context.Context, []byte, string) (string, error) {
+		return "volcengine", nil
+	})
+	router := ocr.NewProviderRoutingClient(provider, map[string]documentdomain.OCRSourceClient{
+		documentdomain.OCRProviderVolcengine: volcengine,
+		documentdomain.OCRProviderLocal:      local,
+	})
+
+	content, err := router.OCRBytes(context.Background(), []byte("x"), "png")
+	if err != nil || content != "local" {
+		t.Fatalf("expected local provider, got %q err=%v", content, err)
+	}
+
+	provider.cfg = enabledVolcengineOCRConfig()
+	content, err = router.OCRBytes(context.Background(), []byte("x"), "png")
+	if err != nil || content != "volcengine" {
+		t.Fatalf("expected volcengine provider, got %q err=%v", content, err)
+	}
+
+	provider.cfg = &documentdomain.OCRAbilityConfig{Enabled: true, ProviderCode: "Official"}
+	if _, err := router.OCRBytes(context.Background(), []byte("x"), "png"); !errors.Is(err, documentdomain.ErrOCRProviderUnsupported) {
+		t.Fatalf("expected unsupported provider error, got %v", err)
+	}
+}
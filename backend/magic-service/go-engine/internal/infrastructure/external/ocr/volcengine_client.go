@@ -17,7 +17,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/ledongthuc/pdf"
 	"github.com/volcengine/volc-sdk-golang/base"
 	"github.com/volcengine/volc-sdk-golang/service/visual"
@@ -26,7 +25,6 @@ import (
 	documentdomain "magic/internal/domain/knowledge/document/metadata"
 	"magic/internal/domain/knowledge/shared"
 	"magic/internal/infrastructure/logging"
-	"magic/internal/pkg/ctxmeta"
 	"magic/internal/pkg/ratelimit"
 )
 
@@ -82,14 +80,11 @@ type RateLimitConfig struct {
 
 // VolcengineOCRClient 火山引擎 OCR 客户端
 type VolcengineOCRClient struct {
+	ocrRuntime
+
 	configProvider documentdomain.OCRConfigProviderPort
-	cacheRepo      documentdomain.OCRResultCacheRepository
-	logger         *logging.SugaredLogger
 	invokeOCRByURL func(context.Context, *shared.OCRConfig, string, string) (string, error)
 	invokeOCRBytes func(context.Context, *shared.OCRConfig, []byte, string) (string, error)
-	rateLimiter    RateLimiter
-	rateLimit      RateLimitConfig
-	usageReporter  documentdomain.OCRUsageReporterPort
 }
 
 // NewVolcengineOCRClient 创建火山引擎 OCR 客户端
@@ -99,9 +94,12 @@ func NewVolcengineOCRClient(
 	logger *logging.SugaredLogger,
 ) *VolcengineOCRClient {
 	return &VolcengineOCRClient{
+		ocrRuntime: ocrRuntime{
+			cacheRepo:  cacheRepo,
+			logger:     logger,
+			cacheModel: volcengineBytesCacheMod,
+		},
 		configProvider: configProvider,
-		cacheRepo:      cacheRepo,
-		logger:         logger,
 		invokeOCRByURL: callVolcengineOCR,
 		invokeOCRBytes: callVolcengineOCRBytes,
 	}
@@ -112,8 +110,7 @@ func (c *VolcengineOCRClient) SetRateLimiter(limiter RateLimiter, config RateLim
 	if c == nil {
 		return
 	}
-	c.rateLimiter = limiter
-	c.rateLimit = normalizeRateLimitConfig(config)
+	c.setRateLimiter(limiter, config)
 }
 
 // SetUsageReporter injects an optional OCR usage reporter.
@@ -261,120 +258,6 @@ func (c *VolcengineOCRClient) OCRBytes(ctx context.Context, data []byte, fileTyp
 	return content, nil
 }
 
-func (c *VolcengineOCRClient) reportOCRUsage(
-	ctx context.Context,
-	provider string,
-	fileType string,
-	callType string,
-	pageCount int,
-) {
-	if c == nil || c.usageReporter == nil {
-		return
-	}
-	usageMeta, ok := documentdomain.OCRUsageContextFromContext(ctx)
-	if !ok {
-		c.logSkipOCRUsageReport(ctx, provider, fileType, callType, "usage context missing")
-		return
-	}
-	usage := documentdomain.OCRUsage{
-		EventID:           uuid.NewString(),
-		Provider:          strings.TrimSpace(provider),
-		OrganizationCode:  strings.TrimSpace(usageMeta.OrganizationCode),
-		UserID:            strings.TrimSpace(usageMeta.UserID),
-		PageCount:         normalizeOCRUsagePageCount(fileType, pageCount),
-		FileType:          strings.TrimSpace(fileType),
-		BusinessID:        strings.TrimSpace(usageMeta.BusinessID),
-		SourceID:          strings.TrimSpace(usageMeta.SourceID),
-		KnowledgeBaseCode: strings.TrimSpace(usageMeta.KnowledgeBaseCode),
-		DocumentCode:      strings.TrimSpace(usageMeta.DocumentCode),
-		CallType:          strings.TrimSpace(callType),
-	}
-	if usage.BusinessID == "" {
-		usage.BusinessID = usage.KnowledgeBaseCode
-	}
-	if usage.SourceID == "" {
-		usage.SourceID = usage.DocumentCode
-	}
-	if requestID, found := ctxmeta.RequestIDFromContext(ctx); found {
-		usage.RequestID = requestID
-	}
-	if usage.Provider == "" || usage.OrganizationCode == "" || usage.UserID == "" || usage.PageCount <= 0 {
-		c.logSkipOCRUsageReport(ctx, provider, fileType, callType, "usage required field missing")
-		return
-	}
-	if err := c.usageReporter.ReportOCRUsage(ctx, usage); err != nil && c.logger != nil {
-		c.logger.KnowledgeWarnContext(
-			ctx,
-			"Report ocr usage failed",
-			"provider", usage.Provider,
-			"file_type", usage.FileType,
-			"call_type", usage.CallType,
-			"page_count", usage.PageCount,
-			"knowledge_base_code", usage.KnowledgeBaseCode,
-			"document_code", usage.DocumentCode,
-			"error", err,
-		)
-	}
-}
-
-func (c *VolcengineOCRClient) logSkipOCRUsageReport(ctx context.Context, provider, fileType, callType, reason string) {
-	if c == nil || c.logger == nil {
-		return
-	}
-	c.logger.KnowledgeWarnContext(
-		ctx,
-		"Skip ocr usage report",
-		"provider", provider,
-		"file_type", fileType,
-		"call_type", callType,
-		"reason", reason,
-	)
-}
-
-func (c *VolcengineOCRClient) resolveOCRSourcePageCount(
-	ctx context.Context,
-	file io.Reader,
-	fileType string,
-	size int64,
-) int {
-	if fileType != normalizedOCRPDFType {
-		return 1
-	}
-	pageCount, err := readPDFPageCount(file, size)
-	if err != nil {
-		if c != nil && c.logger != nil {
-			c.logger.KnowledgeWarnContext(
-				ctx,
-				"Read ocr pdf page count failed, fallback to one page",
-				"file_type", fileType,
-				"source_size", size,
-				"error", err,
-			)
-		}
-		return 1
-	}
-	return normalizeOCRUsagePageCount(fileType, pageCount)
-}
-
-func (c *VolcengineOCRClient) waitRateLimit(ctx context.Context, provider string) error {
-	if c == nil || c.rateLimiter == nil {
-		return nil
-	}
-	config := normalizeRateLimitConfig(c.rateLimit)
-	result, err := c.rateLimiter.Wait(ctx, config.Key, config.WaitTimeout)
-	if err == nil {
-		c.logRateLimitAcquired(ctx, provider, config, result)
-		return nil
-	}
-	c.logRateLimitFailed(ctx, provider, config, result, err)
-	return newOCRExecutionError(
-		documentdomain.NewOCROverloadedError(
-			provider,
-			fmt.Errorf("wait ocr rate limit token: %w", err),
-		),
-	)
-}
-
 func normalizeRateLimitConfig(config RateLimitConfig) RateLimitConfig {
 	if strings.TrimSpace(config.Key) == "" {
 		config.Key = defaultRateLimitKey
@@ -385,93 +268,6 @@ func normalizeRateLimitConfig(config RateLimitConfig) RateLimitConfig {
 	return config
 }
 
-func (c *VolcengineOCRClient) logRateLimitAcquired(
-	ctx context.Context,
-	provider string,
-	config RateLimitConfig,
-	result ratelimit.Result,
-) {
-	if c == nil || c.logger == nil || result.Waited <= 0 {
-		return
-	}
-	c.logger.InfoContext(
-		ctx,
-		"Acquire ocr rate limit token",
-		"provider", provider,
-		"key", config.Key,
-		"wait_timeout_ms", config.WaitTimeout.Milliseconds(),
-		"waited_ms", result.Waited.Milliseconds(),
-		"remaining_tokens", result.Remaining,
-	)
-}
-
-func (c *VolcengineOCRClient) logRateLimitFailed(
-	ctx context.Context,
-	provider string,
-	config RateLimitConfig,
-	result ratelimit.Result,
-	err error,
-) {
-	if c == nil || c.logger == nil {
-		return
-	}
-	message := "Wait ocr rate limit token failed"
-	if errors.Is(err, ratelimit.ErrWaitTimeout) {
-		message = "OCR rate limit wait timeout"
-	}
-	c.logger.KnowledgeWarnContext(
-		ctx,
-		message,
-		"provider", provider,
-		"key", config.Key,
-		"wait_timeout_ms", config.WaitTimeout.Milliseconds(),
-		"waited_ms", result.Waited.Milliseconds(),
-		"retry_after_ms", result.RetryAfter.Milliseconds(),
-		"remaining_tokens", result.Remaining,
-		"error", err,
-	)
-}
-
-func (c *VolcengineOCRClient) lookupCachedBytesPayload(ctx context.Context, cacheHash string) (string, bool) {
-	if c == nil || c.cacheRepo == nil || strings.TrimSpace(cacheHash) == "" {
-		return "", false
-	}
-	cached, err := c.cacheRepo.FindBytesCache(ctx, cacheHash, volcengineBytesCacheMod)
-	if err != nil {
-		if errors.Is(err, documentdomain.ErrOCRCacheNotFound) {
-			return "", false
-		}
-		if c.logger != nil {
-			c.logger.KnowledgeWarnContext(ctx, "读取 OCR 字节流缓存失败，跳过缓存", "text_hash", cacheHash, "error", err)
-		}
-		return "", false
-	}
-	if cached == nil {
-		return "", false
-	}
-	if err := c.cacheRepo.Touch(ctx, cached.ID); err != nil && c.logger != nil {
-		c.logger.KnowledgeWarnContext(ctx, "更新 OCR 字节流缓存访问统计失败", "cache_id", cached.ID, "error", err)
-	}
-	return cached.Content, true
-}
-
-func (c *VolcengineOCRClient) persistCachedBytesPayload(
-	ctx context.Context,
-	cacheHash, provider, fileType, content string,
-) {
-	if c == nil || c.cacheRepo == nil || strings.TrimSpace(cacheHash) == "" {
-		return
-	}
-	if err := c.cacheRepo.UpsertBytesCache(ctx, &documentdomain.OCRResultCache{
-		TextHash:       cacheHash,
-		EmbeddingModel: volcengineBytesCacheMod,
-		Content:        content,
-		FileType:       normalizeCacheFileType(fileType),
-	}); err != nil && c.logger != nil {
-		c.logger.KnowledgeWarnContext(ctx, "写入 OCR 字节流缓存失败", "provider", provider, "text_hash", cacheHash, "error", err)
-	}
-}
-
 func buildOCRBytesCacheHash(provider, fileType string, data []byte) string {
 	keyPrefix := provider + "\n" + normalizeCacheFileType(fileType) + "\n"
 	hash := sha256.New()
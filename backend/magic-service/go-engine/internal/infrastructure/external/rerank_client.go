@@ -0,0 +1,138 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	fragretrieval "magic/internal/domain/knowledge/fragment/retrieval"
+)
+
+// ErrRerankRequestFailed 表示重排 API 请求失败
+var ErrRerankRequestFailed = errors.New("rerank request failed")
+
+const defaultRerankHTTPTimeout = 10 * time.Second
+
+// RerankClient 调用 OpenAI/Jina/Cohere 兼容的 /v1/rerank 接口实现交叉编码重排。
+type RerankClient struct {
+	// gateway 复用模型网关客户端的 URL 校验、令牌解析与发送逻辑
+	gateway *OpenAIEmbeddingClient
+}
+
+// NewRerankClient 创建重排客户端，baseURL 通常指向模型网关。
+func NewRerankClient(baseURL string, accessTokenProvider AccessTokenProvider) *RerankClient {
+	gateway := NewOpenAIEmbeddingClient(baseURL, accessTokenProvider)
+	gateway.httpClient.Timeout = defaultRerankHTTPTimeout
+	return &RerankClient{gateway: gateway}
+}
+
+type rerankRequest struct {
+	Model string `json:"model"`
+	// Provider 为知识库指定的重排服务商，为空时由网关按模型选择默认服务商。
+	Provider        string            `json:"provider,omitempty"`
+	Query           string            `json:"query"`
+	Documents       []string          `json:"documents"`
+	TopN            int               `json:"top_n,omitempty"`
+	ReturnDocuments bool              `json:"return_documents"`
+	BusinessParams  map[string]string `json:"business_params,omitempty"`
+}
+
+type rerankResponseItem struct {
+	Index          int      `json:"index"`
+	RelevanceScore *float64 `json:"relevance_score"`
+	Score          *float64 `json:"score"`
+}
+
+// rerankResponse 兼容 Jina/Cohere 的 results 与部分网关返回的 data 字段。
+type rerankResponse struct {
+	Results []rerankResponseItem `json:"results"`
+	Data    []rerankResponseItem `json:"data"`
+}
+
+// Rerank 对候选文本进行交叉编码重排。
+func (c *RerankClient) Rerank(ctx context.Context, input fragretrieval.RerankRequest) ([]fragretrieval.RerankResult, error) {
+	if len(input.Documents) == 0 {
+		return nil, nil
+	}
+	reqBody := rerankRequest{
+		Model:     input.Model,
+		Provider:  strings.TrimSpace(input.Provider),
+		Query:     input.Query,
+		Documents: input.Documents,
+		TopN:      input.TopN,
+	}
+	if input.BusinessParams != nil && !input.BusinessParams.IsEmpty() {
+		reqBody.BusinessParams = input.BusinessParams.ToMap()
+	}
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	rerankURL, err := c.gateway.joinStableURL("/v1/rerank")
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rerankURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if accessToken, tokenErr := c.gateway.resolveAccessToken(ctx); tokenErr == nil {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	}
+	if input.BusinessParams != nil {
+		if orgCode := input.BusinessParams.GetOrganizationCode(); orgCode != "" {
+			req.Header.Set("Magic-Organization-Code", orgCode)
+			req.Header.Set("Magic-Organization-Id", orgCode)
+		}
+		if input.BusinessParams.UserID != "" {
+			req.Header.Set("Magic-User-Id", input.BusinessParams.UserID)
+		}
+	}
+
+	resp, err := c.gateway.send(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%w with status %d: %s", ErrRerankRequestFailed, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var rerankResp rerankResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rerankResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	items := rerankResp.Results
+	if len(items) == 0 {
+		items = rerankResp.Data
+	}
+
+	results := make([]fragretrieval.RerankResult, 0, len(items))
+	for _, item := range items {
+		score := item.RelevanceScore
+		if score == nil {
+			score = item.Score
+		}
+		if score == nil {
+			return nil, fmt.Errorf("%w: missing relevance score for index %d", ErrRerankRequestFailed, item.Index)
+		}
+		results = append(results, fragretrieval.RerankResult{
+			Index:          item.Index,
+			RelevanceScore: *score,
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,116 @@
+package external_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fragretrieval "magic/internal/domain/knowledge/fragment/retrieval"
+	"magic/internal/infrastructure/external"
+	"magic/internal/pkg/ctxmeta"
+)
+
+func TestRerankClient_Rerank(t *testing.T) {
+	t.Parallel()
+	errCh := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/rerank" || r.Method != http.MethodPost {
+			errCh <- fmt.Errorf("%w: %s %s", errUnexpectedRequest, r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token123" {
+			errCh <- fmt.Errorf("%w: auth=%s", errUnexpectedRequest, got)
+		}
+		if got := r.Header.Get("Magic-Organization-Code"); got != "org" {
+			errCh <- fmt.Errorf("%w: org_code=%s", errUnexpectedRequest, got)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			errCh <- err
+		}
+		if body["model"] != "bge-reranker" || body["provider"] != "jina" || body["query"] != "退款流程" || body["top_n"] != float64(2) {
+			errCh <- fmt.Errorf("%w: body=%v", errUnexpectedRequest, body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"results":[{"index":1,"relevance_score":0.92},{"index":0,"relevance_score":0.13}]}`)
+	}))
+	defer srv.Close()
+
+	client := external.NewRerankClient(srv.URL, fakeTokenProvider{token: "token123"})
+	results, err := client.Rerank(context.Background(), fragretrieval.RerankRequest{
+		Model:          "bge-reranker",
+		Provider:       "jina",
+		Query:          "退款流程",
+		Documents:      []string{"发货说明", "退款流程说明"},
+		TopN:           2,
+		BusinessParams: &ctxmeta.BusinessParams{OrganizationID: "org"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case srvErr := <-errCh:
+		t.Fatalf("unexpected request: %v", srvErr)
+	default:
+	}
+	if len(results) != 2 || results[0].Index != 1 || results[0].RelevanceScore != 0.92 || results[1].Index != 0 {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+}
+
+func TestRerankClient_RerankAcceptsDataScoreResponse(t *testing.T) {
+	t.Parallel()
+	errCh := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			errCh <- err
+		}
+		if _, exists := body["provider"]; exists {
+			errCh <- fmt.Errorf("%w: expected default provider to be omitted, body=%v", errUnexpectedRequest, body)
+		}
+		_, _ = io.WriteString(w, `{"data":[{"index":0,"score":0.5}]}`)
+	}))
+	defer srv.Close()
+
+	client := external.NewRerankClient(srv.URL, fakeTokenProvider{token: "token123"})
+	results, err := client.Rerank(context.Background(), fragretrieval.RerankRequest{
+		Model:     "m",
+		Query:     "q",
+		Documents: []string{"d"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case srvErr := <-errCh:
+		t.Fatalf("unexpected request: %v", srvErr)
+	default:
+	}
+	if len(results) != 1 || results[0].RelevanceScore != 0.5 {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+}
+
+func TestRerankClient_RerankStatusError(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = io.WriteString(w, "upstream down")
+	}))
+	defer srv.Close()
+
+	client := external.NewRerankClient(srv.URL, fakeTokenProvider{token: "token123"})
+	_, err := client.Rerank(context.Background(), fragretrieval.RerankRequest{
+		Model:     "m",
+		Query:     "q",
+		Documents: []string{"d"},
+	})
+	if !errors.Is(err, external.ErrRerankRequestFailed) {
+		t.Fatalf("expected ErrRerankRequestFailed, got %v", err)
+	}
+}
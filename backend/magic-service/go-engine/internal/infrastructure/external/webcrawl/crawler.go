@@ -0,0 +1,349 @@
+// Package webcrawl 提供知识库网站来源的爬虫实现。
+package webcrawl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+
+	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
+)
+
+const (
+	defaultTimeout  = 30 * time.Second
+	maxRedirects    = 10
+	maxPageBytes    = 5 * 1024 * 1024
+	maxHTMLDepth    = 200
+	userAgent       = "Magic-WebCrawler/1.0"
+	robotsUserAgent = "magic-webcrawler"
+)
+
+var (
+	errTooManyRedirects = errors.New("stopped after too many redirects")
+	errUnexpectedStatus = errors.New("unexpected status code")
+)
+
+// 编译时检查是否实现了领域端口
+var _ sourcebindingentity.WebsiteCrawler = (*Crawler)(nil)
+
+// Crawler 按广度优先爬取网站页面，遵守 robots.txt 并按主机限速。
+type Crawler struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewCrawler 创建网站爬虫；client 为 nil 时使用默认超时与重定向上限。
+func NewCrawler(client *http.Client) *Crawler {
+	if client == nil {
+		client = &http.Client{
+			Timeout: defaultTimeout,
+			CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("%w: %d", errTooManyRedirects, maxRedirects)
+				}
+				return nil
+			},
+		}
+	}
+	return &Crawler{client: client, now: time.Now}
+}
+
+type crawlQueueItem struct {
+	url   string
+	depth int
+	seed  bool
+}
+
+type crawlRun struct {
+	crawler    *Crawler
+	config     sourcebindingentity.WebsiteCrawlConfig
+	validators map[string]sourcebindingentity.WebsitePageValidator
+	limiter    *hostLimiter
+	robots     map[string]*robotsRules
+	queue      []crawlQueueItem
+	enqueued   map[string]struct{}
+	result     *sourcebindingentity.WebsiteCrawlResult
+}
+
+// Crawl 从种子 URL 与 sitemap 出发爬取页面。
+//
+// 已知页面携带 ETag/Last-Modified 发起条件请求，304 时返回 NotModified 且不再展开其链接；
+// 因此未变化页面上新增的链接依赖 sitemap 或其他已变化页面发现。
+func (c *Crawler) Crawl(
+	ctx context.Context,
+	request sourcebindingentity.WebsiteCrawlRequest,
+) (*sourcebindingentity.WebsiteCrawlResult, error) {
+	run := &crawlRun{
+		crawler:    c,
+		config:     request.Config,
+		validators: request.Validators,
+		limiter:    newHostLimiter(request.Config.RequestsPerSecond, c.now),
+		robots:     map[string]*robotsRules{},
+		enqueued:   map[string]struct{}{},
+		result:     &sourcebindingentity.WebsiteCrawlResult{},
+	}
+	for _, seed := range request.Config.SeedURLs {
+		run.enqueue(crawlQueueItem{url: seed, seed: true})
+	}
+	if request.Config.UseSitemap {
+		if err := run.enqueueSitemapURLs(ctx); err != nil {
+			return nil, err
+		}
+	}
+	for len(run.queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("crawl website: %w", err)
+		}
+		item := run.queue[0]
+		run.queue = run.queue[1:]
+		if len(run.result.Pages) >= run.config.MaxPages {
+			run.skip(item.url, sourcebindingentity.WebsitePageSkipPageLimit)
+			continue
+		}
+		if err := run.visit(ctx, item); err != nil {
+			return nil, err
+		}
+	}
+	return run.result, nil
+}
+
+func (r *crawlRun) enqueue(item crawlQueueItem) {
+	normalized, err := sourcebindingentity.NormalizeWebsiteURL(item.url)
+	if err != nil {
+		return
+	}
+	if _, exists := r.enqueued[normalized]; exists {
+		return
+	}
+	// 种子即使不命中 include 也要访问，作为导航页展开链接，但不产出文档。
+	if !item.seed && !r.config.AllowsURL(normalized) {
+		return
+	}
+	r.enqueued[normalized] = struct{}{}
+	item.url = normalized
+	r.queue = append(r.queue, item)
+}
+
+func (r *crawlRun) skip(pageURL, reason string) {
+	r.result.Skipped = append(r.result.Skipped, sourcebindingentity.WebsitePageSkip{URL: pageURL, Reason: reason})
+}
+
+func (r *crawlRun) visit(ctx context.Context, item crawlQueueItem) error {
+	allowed, err := r.robotsAllowed(ctx, item.url)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		r.skip(item.url, sourcebindingentity.WebsitePageSkipRobotsDisallowed)
+		return nil
+	}
+	resp, err := r.fetchPage(ctx, item.url)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("crawl website: %w", ctx.Err())
+		}
+		r.skip(item.url, sourcebindingentity.WebsitePageSkipFetchFailed)
+		return nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	emit := r.config.AllowsURL(item.url)
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		if emit {
+			r.result.Pages = append(r.result.Pages, r.notModifiedPage(item))
+		}
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		if _, known := r.validators[item.url]; known {
+			r.result.GoneURLs = append(r.result.GoneURLs, item.url)
+		}
+		r.skip(item.url, sourcebindingentity.WebsitePageSkipFetchFailed)
+		return nil
+	case resp.StatusCode != http.StatusOK:
+		r.skip(item.url, sourcebindingentity.WebsitePageSkipFetchFailed)
+		return nil
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if !isHTMLContentType(contentType) {
+		r.skip(item.url, sourcebindingentity.WebsitePageSkipUnsupportedContent)
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		r.skip(item.url, sourcebindingentity.WebsitePageSkipFetchFailed)
+		return nil
+	}
+	title, links := extractTitleAndLinks(string(body))
+	if emit {
+		r.result.Pages = append(r.result.Pages, sourcebindingentity.WebsitePage{
+			URL:          item.url,
+			Title:        title,
+			Depth:        item.depth,
+			ContentType:  contentType,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		})
+	}
+	if item.depth < r.config.MaxDepth {
+		r.enqueueLinks(resp.Request.URL, links, item.depth+1)
+	}
+	return nil
+}
+
+func (r *crawlRun) notModifiedPage(item crawlQueueItem) sourcebindingentity.WebsitePage {
+	validator := r.validators[item.url]
+	return sourcebindingentity.WebsitePage{
+		URL:          item.url,
+		Depth:        item.depth,
+		ETag:         validator.ETag,
+		LastModified: validator.LastModified,
+		NotModified:  true,
+	}
+}
+
+func (r *crawlRun) enqueueLinks(base *url.URL, links []string, depth int) {
+	for _, link := range links {
+		resolved, err := base.Parse(link)
+		if err != nil {
+			continue
+		}
+		r.enqueue(crawlQueueItem{url: resolved.String(), depth: depth})
+	}
+}
+
+func (r *crawlRun) fetchPage(ctx context.Context, pageURL string) (*http.Response, error) {
+	if err := r.limiter.Wait(ctx, hostOf(pageURL)); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create page request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.5")
+	if validator, ok := r.validators[pageURL]; ok {
+		if validator.ETag != "" {
+			req.Header.Set("If-None-Match", validator.ETag)
+		}
+		if validator.LastModified != "" {
+			req.Header.Set("If-Modified-Since", validator.LastModified)
+		}
+	}
+	resp, err := r.crawler.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch page: %w", err)
+	}
+	return resp, nil
+}
+
+func (r *crawlRun) fetchText(ctx context.Context, rawURL string, limit int64) (string, error) {
+	if err := r.limiter.Wait(ctx, hostOf(rawURL)); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := r.crawler.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch %s: %w", rawURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %d", errUnexpectedStatus, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", rawURL, err)
+	}
+	return string(body), nil
+}
+
+func isHTMLContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// extractTitleAndLinks 提取页面标题与 a[href] 链接，忽略锚点、脚本与邮件链接。
+func extractTitleAndLinks(content string) (string, []string) {
+	root, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return "", nil
+	}
+	var (
+		title string
+		links []string
+		walk  func(*html.Node, int)
+	)
+	walk = func(node *html.Node, depth int) {
+		if depth > maxHTMLDepth {
+			return
+		}
+		if node.Type == html.ElementNode {
+			switch node.Data {
+			case "title":
+				if title == "" && node.FirstChild != nil {
+					title = strings.Join(strings.Fields(node.FirstChild.Data), " ")
+				}
+			case "a":
+				if href := htmlAttr(node, "href"); isCrawlableHref(href) {
+					links = append(links, href)
+				}
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child, depth+1)
+		}
+	}
+	walk(root, 0)
+	return title, links
+}
+
+func htmlAttr(node *html.Node, name string) string {
+	for _, attr := range node.Attr {
+		if strings.EqualFold(attr.Key, name) {
+			return strings.TrimSpace(attr.Val)
+		}
+	}
+	return ""
+}
+
+func isCrawlableHref(href string) bool {
+	if href == "" || strings.HasPrefix(href, "#") {
+		return false
+	}
+	lower := strings.ToLower(href)
+	return !strings.HasPrefix(lower, "javascript:") &&
+		!strings.HasPrefix(lower, "mailto:") &&
+		!strings.HasPrefix(lower, "tel:")
+}
+
+// siteURL 返回与页面同源的站点级地址，例如 robots.txt 与 sitemap.xml。
+func siteURL(pageURL, sitePath string) string {
+	parsed, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Scheme + "://" + parsed.Host + sitePath
+}
+
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Host)
+}
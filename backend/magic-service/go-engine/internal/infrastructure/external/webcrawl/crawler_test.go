@@ -0,0 +1,167 @@
+package webcrawl_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
+	"magic/internal/infrastructure/external/webcrawl"
+)
+
+type fakeSitePage struct {
+	contentType string
+	body        string
+	etag        string
+}
+
+type fakeSite struct {
+	mu        sync.Mutex
+	pages     map[string]fakeSitePage
+	requested []string
+}
+
+func (s *fakeSite) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	s.requested = append(s.requested, req.URL.Path)
+	s.mu.Unlock()
+
+	page, exists := s.pages[req.URL.Path]
+	header := make(http.Header)
+	status := http.StatusOK
+	switch {
+	case !exists:
+		status = http.StatusNotFound
+	case page.etag != "" && req.Header.Get("If-None-Match") == page.etag:
+		status = http.StatusNotModified
+	default:
+		header.Set("Content-Type", page.contentType)
+		if page.etag != "" {
+			header.Set("ETag", page.etag)
+		}
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(page.body)),
+		Request:    req,
+	}, nil
+}
+
+func newFakeDocsSite() *fakeSite {
+	const htmlType = "text/html; charset=utf-8"
+	return &fakeSite{pages: map[string]fakeSitePage{
+		"/robots.txt": {contentType: "text/plain", body: "User-agent: *\nDisallow: /docs/private\n"},
+		"/sitemap.xml": {contentType: "application/xml", body: `<?xml version="1.0"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>https://docs.example.com/docs/orphan</loc></url></urlset>`},
+		"/": {contentType: htmlType, body: `<html><head><title>Home</title></head><body>
+<a href="/docs/intro">Intro</a><a href="/blog/post">Blog</a><a href="https://other.example.com/x">Other</a></body></html>`},
+		"/docs/intro": {contentType: htmlType, etag: `"intro-v1"`, body: `<html><head><title> Getting
+  Started </title></head><body><a href="guide#install">Guide</a><a href="/docs/private/key">Key</a>
+<a href="/docs/manual.pdf">PDF</a><a href="mailto:help@example.com">Mail</a></body></html>`},
+		"/docs/guide":       {contentType: htmlType, body: `<html><head><title>Guide</title></head><body><a href="/docs/deep">Deep</a></body></html>`},
+		"/docs/deep":        {contentType: htmlType, body: `<html><head><title>Deep</title></head></html>`},
+		"/docs/orphan":      {contentType: htmlType, body: `<html><head><title>Orphan</title></head></html>`},
+		"/docs/manual.pdf":  {contentType: "application/pdf", body: "%PDF"},
+		"/docs/private/key": {contentType: htmlType, body: "secret"},
+		"/blog/post":        {contentType: htmlType, body: "blog"},
+	}}
+}
+
+func websiteConfigForTest(t *testing.T, syncConfig map[string]any) sourcebindingentity.WebsiteCrawlConfig {
+	t.Helper()
+
+	config, err := sourcebindingentity.ParseWebsiteCrawlConfig(sourcebindingentity.Binding{
+		Provider:   sourcebindingentity.ProviderWebsite,
+		RootType:   sourcebindingentity.RootTypeSite,
+		RootRef:    "https://docs.example.com/",
+		SyncConfig: syncConfig,
+	})
+	if err != nil {
+		t.Fatalf("parse website crawl config: %v", err)
+	}
+	config.RequestsPerSecond = 1000
+	return config
+}
+
+func TestCrawlerCrawlsWithinScopeAndRespectsRobots(t *testing.T) {
+	t.Parallel()
+
+	site := newFakeDocsSite()
+	crawler := webcrawl.NewCrawler(&http.Client{Transport: site})
+	config := websiteConfigForTest(t, map[string]any{
+		"include_patterns": []any{"/docs/**"},
+		"max_depth":        2,
+	})
+
+	result, err := crawler.Crawl(context.Background(), sourcebindingentity.WebsiteCrawlRequest{Config: config})
+	if err != nil {
+		t.Fatalf("crawl: %v", err)
+	}
+	titles := map[string]string{}
+	for _, page := range result.Pages {
+		titles[page.URL] = page.Title
+	}
+	expected := map[string]string{
+		"https://docs.example.com/docs/orphan": "Orphan",
+		"https://docs.example.com/docs/intro":  "Getting Started",
+		"https://docs.example.com/docs/guide":  "Guide",
+	}
+	if len(titles) != len(expected) {
+		t.Fatalf("unexpected crawled pages: %#v", result.Pages)
+	}
+	for pageURL, title := range expected {
+		if titles[pageURL] != title {
+			t.Fatalf("expected %s titled %q, got %#v", pageURL, title, titles)
+		}
+	}
+
+	reasons := map[string]string{}
+	for _, skipped := range result.Skipped {
+		reasons[skipped.URL] = skipped.Reason
+	}
+	if reasons["https://docs.example.com/docs/private/key"] != sourcebindingentity.WebsitePageSkipRobotsDisallowed ||
+		reasons["https://docs.example.com/docs/manual.pdf"] != sourcebindingentity.WebsitePageSkipUnsupportedContent {
+		t.Fatalf("unexpected skipped pages: %#v", result.Skipped)
+	}
+	if slices.Contains(site.requested, "/docs/private/key") || slices.Contains(site.requested, "/docs/deep") ||
+		slices.Contains(site.requested, "/blog/post") {
+		t.Fatalf("crawler fetched out-of-scope pages: %v", site.requested)
+	}
+}
+
+func TestCrawlerUsesConditionalRequestsForKnownPages(t *testing.T) {
+	t.Parallel()
+
+	site := newFakeDocsSite()
+	crawler := webcrawl.NewCrawler(&http.Client{Transport: site})
+	config := websiteConfigForTest(t, map[string]any{
+		"include_patterns": []any{"/docs/**"},
+		"use_sitemap":      false,
+		"max_pages":        2,
+	})
+
+	result, err := crawler.Crawl(context.Background(), sourcebindingentity.WebsiteCrawlRequest{
+		Config: config,
+		Validators: map[string]sourcebindingentity.WebsitePageValidator{
+			"https://docs.example.com/docs/intro":   {ETag: `"intro-v1"`},
+			"https://docs.example.com/docs/removed": {ETag: `"removed-v1"`},
+		},
+	})
+	if err != nil {
+		t.Fatalf("crawl: %v", err)
+	}
+	if len(result.Pages) != 1 || !result.Pages[0].NotModified || result.Pages[0].ETag != `"intro-v1"` {
+		t.Fatalf("expected intro to be reported as not modified, got %#v", result.Pages)
+	}
+	if len(result.GoneURLs) != 0 {
+		t.Fatalf("pages not revisited must not be reported gone: %#v", result.GoneURLs)
+	}
+	if slices.Contains(site.requested, "/docs/guide") {
+		t.Fatalf("links of unchanged pages should not be expanded: %v", site.requested)
+	}
+}
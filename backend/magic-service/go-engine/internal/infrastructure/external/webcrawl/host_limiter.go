@@ -0,0 +1,71 @@
+package webcrawl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// hostLimiter 按主机控制请求间隔，保证同一主机的请求速率不超过配置值。
+type hostLimiter struct {
+	mu        sync.Mutex
+	interval  time.Duration
+	intervals map[string]time.Duration
+	next      map[string]time.Time
+	now       func() time.Time
+}
+
+func newHostLimiter(requestsPerSecond float64, now func() time.Time) *hostLimiter {
+	interval := time.Duration(0)
+	if requestsPerSecond > 0 {
+		interval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+	return &hostLimiter{
+		interval:  interval,
+		intervals: map[string]time.Duration{},
+		next:      map[string]time.Time{},
+		now:       now,
+	}
+}
+
+// SetHostInterval 用 robots.txt 的 Crawl-delay 放宽指定主机的请求间隔，只会调大不会调小。
+func (l *hostLimiter) SetHostInterval(host string, interval time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if interval > l.interval {
+		l.intervals[host] = interval
+	}
+}
+
+// Wait 阻塞到该主机允许下一次请求为止。
+func (l *hostLimiter) Wait(ctx context.Context, host string) error {
+	delay := l.reserve(host)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("wait host rate limit: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *hostLimiter) reserve(host string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	interval := l.interval
+	if hostInterval, exists := l.intervals[host]; exists {
+		interval = hostInterval
+	}
+	now := l.now()
+	start := now
+	if next, exists := l.next[host]; exists && next.After(now) {
+		start = next
+	}
+	l.next[host] = start.Add(interval)
+	return start.Sub(now)
+}
@@ -0,0 +1,168 @@
+package webcrawl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxRobotsBytes = 512 * 1024
+
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+// robotsRules 表示某个主机 robots.txt 中对本爬虫生效的规则组。
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+	sitemaps   []string
+}
+
+// robotsAllowed 判断 URL 是否允许抓取；robots.txt 不可用时按允许处理。
+func (r *crawlRun) robotsAllowed(ctx context.Context, pageURL string) (bool, error) {
+	if !r.config.RespectRobotsTxt {
+		return true, nil
+	}
+	rules, err := r.robotsFor(ctx, pageURL)
+	if err != nil {
+		return false, err
+	}
+	return rules.allows(robotsTarget(pageURL)), nil
+}
+
+func robotsTarget(pageURL string) string {
+	parsed, err := url.Parse(pageURL)
+	if err != nil {
+		return "/"
+	}
+	target := parsed.EscapedPath()
+	if parsed.RawQuery != "" {
+		target += "?" + parsed.RawQuery
+	}
+	return target
+}
+
+func (r *crawlRun) robotsFor(ctx context.Context, pageURL string) (*robotsRules, error) {
+	host := hostOf(pageURL)
+	if rules, exists := r.robots[host]; exists {
+		return rules, nil
+	}
+	content, err := r.fetchText(ctx, siteURL(pageURL, "/robots.txt"), maxRobotsBytes)
+	if err != nil && ctx.Err() != nil {
+		return nil, fmt.Errorf("fetch robots.txt: %w", ctx.Err())
+	}
+	rules := parseRobots(content, robotsUserAgent)
+	if rules.crawlDelay > 0 {
+		r.limiter.SetHostInterval(host, rules.crawlDelay)
+	}
+	r.robots[host] = rules
+	return rules, nil
+}
+
+// parseRobots 解析 robots.txt，优先使用点名本爬虫的规则组，否则使用 "*" 组。
+func parseRobots(content, agent string) *robotsRules {
+	specific, wildcard := &robotsRules{}, &robotsRules{}
+	var (
+		current      []*robotsRules
+		inAgentBlock bool
+		matched      bool
+		sitemaps     []string
+	)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			if !inAgentBlock {
+				current = nil
+			}
+			inAgentBlock = true
+			switch name := strings.ToLower(value); {
+			case name == "*":
+				current = append(current, wildcard)
+			case name != "" && (strings.Contains(agent, name) || strings.Contains(name, agent)):
+				current = append(current, specific)
+				matched = true
+			}
+		case "allow", "disallow":
+			inAgentBlock = false
+			for _, group := range current {
+				group.rules = append(group.rules, robotsRule{pattern: value, allow: key == "allow"})
+			}
+		case "crawl-delay":
+			inAgentBlock = false
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				for _, group := range current {
+					group.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		case "sitemap":
+			sitemaps = append(sitemaps, value)
+		}
+	}
+	result := wildcard
+	if matched {
+		result = specific
+	}
+	result.sitemaps = sitemaps
+	return result
+}
+
+// allows 按最长匹配原则判断路径是否允许，长度相同时 Allow 优先；空 Disallow 表示不限制。
+func (r *robotsRules) allows(target string) bool {
+	bestLength := -1
+	allowed := true
+	for _, rule := range r.rules {
+		if rule.pattern == "" {
+			continue
+		}
+		if !robotsPatternMatches(rule.pattern, target) {
+			continue
+		}
+		if length := len(rule.pattern); length > bestLength || (length == bestLength && rule.allow) {
+			bestLength = length
+			allowed = rule.allow
+		}
+	}
+	return allowed
+}
+
+// robotsPatternMatches 支持 robots.txt 的 "*" 通配与 "$" 结尾锚定。
+func robotsPatternMatches(pattern, target string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(target, parts[0]) {
+		return false
+	}
+	position := len(parts[0])
+	for _, part := range parts[1:] {
+		index := strings.Index(target[position:], part)
+		if index < 0 {
+			return false
+		}
+		position += index + len(part)
+	}
+	if !anchored {
+		return true
+	}
+	if len(parts) == 1 {
+		return target == parts[0]
+	}
+	return strings.HasSuffix(target, parts[len(parts)-1])
+}
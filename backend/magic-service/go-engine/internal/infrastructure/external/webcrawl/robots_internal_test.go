@@ -0,0 +1,41 @@
+package webcrawl
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRobotsPrefersSpecificAgentGroup(t *testing.T) {
+	t.Parallel()
+
+	rules := parseRobots(`
+User-agent: *
+Disallow: /
+
+User-agent: Magic-WebCrawler
+Allow: /docs/*.html$
+Disallow: /docs/
+Crawl-delay: 2
+
+Sitemap: https://example.com/sitemap.xml
+`, robotsUserAgent)
+
+	if !rules.allows("/docs/intro.html") || rules.allows("/docs/intro.html?v=1") || rules.allows("/docs/raw") {
+		t.Fatalf("unexpected robots decisions for rules %#v", rules.rules)
+	}
+	if !rules.allows("/blog") {
+		t.Fatal("wildcard group must not apply when a specific group matches")
+	}
+	if rules.crawlDelay != 2*time.Second || len(rules.sitemaps) != 1 {
+		t.Fatalf("unexpected crawl delay or sitemaps: %#v", rules)
+	}
+}
+
+func TestRobotsRulesLongestMatchWins(t *testing.T) {
+	t.Parallel()
+
+	rules := parseRobots("User-agent: *\nDisallow: /private\nAllow: /private/public\nDisallow:\n", robotsUserAgent)
+	if rules.allows("/private/key") || !rules.allows("/private/public/page") || !rules.allows("/") {
+		t.Fatalf("unexpected robots decisions for rules %#v", rules.rules)
+	}
+}
@@ -0,0 +1,87 @@
+package webcrawl
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+const (
+	maxSitemapBytes      = 10 * 1024 * 1024
+	maxNestedSitemaps    = 20
+	defaultSitemapPath   = "/sitemap.xml"
+	sitemapURLSetElement = "urlset"
+)
+
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapLocator `xml:"url"`
+	Sitemaps []sitemapLocator `xml:"sitemap"`
+}
+
+type sitemapLocator struct {
+	Loc string `xml:"loc"`
+}
+
+// enqueueSitemapURLs 读取种子主机的 sitemap（robots.txt 声明的优先，其次 /sitemap.xml），
+// 把其中在爬取范围内的 URL 作为深度 0 的入口。sitemap 索引只展开一层。
+func (r *crawlRun) enqueueSitemapURLs(ctx context.Context) error {
+	visitedHosts := map[string]struct{}{}
+	for _, seed := range r.config.SeedURLs {
+		host := hostOf(seed)
+		if _, exists := visitedHosts[host]; exists {
+			continue
+		}
+		visitedHosts[host] = struct{}{}
+		rules, err := r.robotsFor(ctx, seed)
+		if err != nil {
+			return err
+		}
+		sitemapURLs := rules.sitemaps
+		if len(sitemapURLs) == 0 {
+			sitemapURLs = []string{siteURL(seed, defaultSitemapPath)}
+		}
+		if err := r.enqueueSitemaps(ctx, sitemapURLs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *crawlRun) enqueueSitemaps(ctx context.Context, sitemapURLs []string) error {
+	pending := append([]string(nil), sitemapURLs...)
+	for fetched := 0; len(pending) > 0 && fetched < maxNestedSitemaps; fetched++ {
+		sitemapURL := pending[0]
+		pending = pending[1:]
+		content, err := r.fetchText(ctx, sitemapURL, maxSitemapBytes)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("fetch sitemap: %w", ctx.Err())
+			}
+			continue
+		}
+		document, ok := parseSitemap(content)
+		if !ok {
+			continue
+		}
+		if document.XMLName.Local == sitemapURLSetElement {
+			for _, locator := range document.URLs {
+				r.enqueue(crawlQueueItem{url: strings.TrimSpace(locator.Loc)})
+			}
+			continue
+		}
+		for _, locator := range document.Sitemaps {
+			pending = append(pending, strings.TrimSpace(locator.Loc))
+		}
+	}
+	return nil
+}
+
+func parseSitemap(content string) (sitemapDocument, bool) {
+	var document sitemapDocument
+	if err := xml.Unmarshal([]byte(content), &document); err != nil {
+		return sitemapDocument{}, false
+	}
+	return document, true
+}
@@ -0,0 +1,82 @@
+// Package webhookcallback 把知识库 webhook 来源条目的同步结果签名后回调给外部系统。
+package webhookcallback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
+	"magic/internal/infrastructure/external"
+)
+
+const (
+	defaultRequestTimeout = 10 * time.Second
+	maxDrainResponseBytes = 64 << 10
+)
+
+var errUnexpectedStatus = errors.New("unexpected webhook callback response status")
+
+// 编译时检查是否实现了领域端口
+var _ sourcebindingentity.WebhookCallbackNotifier = (*Client)(nil)
+
+// Client 使用绑定的签名密钥对回调请求体做 HMAC 签名后投递到 callback_url。
+//
+// 回调地址由知识库管理员配置，投递前按内容加载器的出站策略校验协议与主机名，
+// 重定向同样逐跳校验；内网地址不在此拦截，SSRF 防护依赖部署侧的出口网络隔离。
+type Client struct {
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewClient 创建 webhook 回调客户端；timeout 非正数时使用默认超时。
+func NewClient(timeout time.Duration) *Client {
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &Client{
+		httpClient: external.NewEgressHTTPClient(timeout),
+		now:        time.Now,
+	}
+}
+
+// NotifyWebhookCallback 向外部系统投递一条同步结果，非 2xx 响应视为失败。
+func (c *Client) NotifyWebhookCallback(
+	ctx context.Context,
+	target sourcebindingentity.WebhookCallbackTarget,
+	event sourcebindingentity.WebhookCallbackEvent,
+) error {
+	callbackURL, err := external.NormalizeEgressURL(target.URL)
+	if err != nil {
+		return fmt.Errorf("validate webhook callback url: %w", err)
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal webhook callback: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build webhook callback request: %w", err)
+	}
+	timestamp := c.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(sourcebindingentity.WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(sourcebindingentity.WebhookSignatureHeader, sourcebindingentity.SignWebhookPayload(target.SigningSecret, timestamp, body))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send webhook callback: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainResponseBytes))
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %d", errUnexpectedStatus, resp.StatusCode)
+	}
+	return nil
+}
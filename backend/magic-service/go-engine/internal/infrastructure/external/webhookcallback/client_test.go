@@ -0,0 +1,100 @@
+package webhookcallback_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	sourcebindingentity "magic/internal/domain/knowledge/sourcebinding/entity"
+	"magic/internal/infrastructure/external"
+	"magic/internal/infrastructure/external/webhookcallback"
+)
+
+const callbackTestSecret = "0123456789abcdef-secret"
+
+func TestClientNotifyWebhookCallbackSignsBody(t *testing.T) {
+	t.Parallel()
+
+	var received sourcebindingentity.WebhookCallbackEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(sourcebindingentity.WebhookTimestampHeader), 10, 64)
+		expected := sourcebindingentity.SignWebhookPayload(callbackTestSecret, timestamp, body)
+		if r.Header.Get(sourcebindingentity.WebhookSignatureHeader) != expected {
+			http.Error(w, "signature mismatch", http.StatusUnauthorized)
+			return
+		}
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := webhookcallback.NewClient(time.Second)
+	err := client.NotifyWebhookCallback(context.Background(), sourcebindingentity.WebhookCallbackTarget{
+		URL:           server.URL,
+		SigningSecret: callbackTestSecret,
+	}, sourcebindingentity.WebhookCallbackEvent{
+		Channel:      "cms",
+		ExternalID:   "a-1",
+		DocumentCode: "DOC-1",
+		Status:       sourcebindingentity.WebhookCallbackStatusSynced,
+	})
+	if err != nil {
+		t.Fatalf("notify webhook callback: %v", err)
+	}
+	if received.ExternalID != "a-1" || received.Status != sourcebindingentity.WebhookCallbackStatusSynced {
+		t.Fatalf("unexpected callback payload: %#v", received)
+	}
+}
+
+func TestClientNotifyWebhookCallbackRejectsNon2xx(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := webhookcallback.NewClient(time.Second).NotifyWebhookCallback(
+		context.Background(),
+		sourcebindingentity.WebhookCallbackTarget{URL: server.URL, SigningSecret: callbackTestSecret},
+		sourcebindingentity.WebhookCallbackEvent{ExternalID: "a-1"},
+	)
+	if err == nil {
+		t.Fatal("expected non-2xx callback response to fail")
+	}
+}
+
+func TestClientNotifyWebhookCallbackAppliesEgressPolicy(t *testing.T) {
+	t.Parallel()
+
+	client := webhookcallback.NewClient(time.Second)
+	err := client.NotifyWebhookCallback(
+		context.Background(),
+		sourcebindingentity.WebhookCallbackTarget{URL: "file:///etc/passwd", SigningSecret: callbackTestSecret},
+		sourcebindingentity.WebhookCallbackEvent{ExternalID: "a-1"},
+	)
+	if !errors.Is(err, external.ErrURLSchemeUnsupported) {
+		t.Fatalf("expected ErrURLSchemeUnsupported, got %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "ftp://internal.example.com/hook", http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	err = client.NotifyWebhookCallback(
+		context.Background(),
+		sourcebindingentity.WebhookCallbackTarget{URL: server.URL, SigningSecret: callbackTestSecret},
+		sourcebindingentity.WebhookCallbackEvent{ExternalID: "a-1"},
+	)
+	if !errors.Is(err, external.ErrURLSchemeUnsupported) {
+		t.Fatalf("expected redirect to non-http scheme to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+// Package chunkcontextcache 提供入库上下文增强生成结果的 Redis 缓存实现。
+package chunkcontextcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultChunkContextTTL     = 30 * 24 * time.Hour
+	redisChunkContextKeyPrefix = "knowledge:chunk_context:"
+	chunkContextMGetBatchSize  = 256
+)
+
+var errChunkContextRedisRequired = errors.New("chunk context cache redis client is required")
+
+// RedisCache 按内容哈希缓存模型生成的片段上下文，重同步时未变化的片段无需再次调用模型。
+type RedisCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisCache 创建片段上下文 Redis 缓存；client 为空时返回 nil，调用方按未配置缓存处理。
+func NewRedisCache(client *redis.Client) *RedisCache {
+	if client == nil {
+		return nil
+	}
+	return &RedisCache{client: client, ttl: defaultChunkContextTTL}
+}
+
+// GetChunkContexts 批量读取缓存，只返回命中的 key。
+func (c *RedisCache) GetChunkContexts(ctx context.Context, keys []string) (map[string]string, error) {
+	if c == nil || c.client == nil {
+		return nil, errChunkContextRedisRequired
+	}
+	result := make(map[string]string, len(keys))
+	for start := 0; start < len(keys); start += chunkContextMGetBatchSize {
+		batch := keys[start:min(start+chunkContextMGetBatchSize, len(keys))]
+		redisKeys := make([]string, len(batch))
+		for i, key := range batch {
+			redisKeys[i] = redisChunkContextKey(key)
+		}
+		values, err := c.client.MGet(ctx, redisKeys...).Result()
+		if err != nil {
+			return nil, fmt.Errorf("get chunk contexts: %w", err)
+		}
+		for i, value := range values {
+			text, ok := value.(string)
+			if !ok || strings.TrimSpace(text) == "" {
+				continue
+			}
+			result[batch[i]] = text
+		}
+	}
+	return result, nil
+}
+
+// SetChunkContexts 批量写入缓存并刷新 TTL。
+func (c *RedisCache) SetChunkContexts(ctx context.Context, contexts map[string]string) error {
+	if c == nil || c.client == nil {
+		return errChunkContextRedisRequired
+	}
+	if len(contexts) == 0 {
+		return nil
+	}
+	pipe := c.client.Pipeline()
+	for key, value := range contexts {
+		pipe.Set(ctx, redisChunkContextKey(key), value, c.ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("set chunk contexts: %w", err)
+	}
+	return nil
+}
+
+func redisChunkContextKey(key string) string {
+	return redisChunkContextKeyPrefix + strings.TrimSpace(key)
+}
@@ -0,0 +1,55 @@
+package chunkcontextcache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	chunkcontextcache "magic/internal/infrastructure/knowledge/chunkcontextcache"
+)
+
+func TestRedisCacheSetAndGetChunkContexts(t *testing.T) {
+	t.Parallel()
+
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctx := context.Background()
+	cache := chunkcontextcache.NewRedisCache(client)
+	if err := cache.SetChunkContexts(ctx, map[string]string{"hash-1": "差旅报销时限"}); err != nil {
+		t.Fatalf("set chunk contexts: %v", err)
+	}
+
+	got, err := cache.GetChunkContexts(ctx, []string{"hash-1", "hash-2"})
+	if err != nil {
+		t.Fatalf("get chunk contexts: %v", err)
+	}
+	if len(got) != 1 || got["hash-1"] != "差旅报销时限" {
+		t.Fatalf("expected only cached key to be returned, got %#v", got)
+	}
+
+	ttl, err := client.TTL(ctx, "knowledge:chunk_context:hash-1").Result()
+	if err != nil {
+		t.Fatalf("read ttl: %v", err)
+	}
+	if ttl <= 29*24*time.Hour || ttl > 30*24*time.Hour {
+		t.Fatalf("expected ttl close to 30 days, got %s", ttl)
+	}
+}
+
+func TestNewRedisCacheWithoutClientReturnsNil(t *testing.T) {
+	t.Parallel()
+
+	if cache := chunkcontextcache.NewRedisCache(nil); cache != nil {
+		t.Fatalf("expected nil cache, got %#v", cache)
+	}
+}
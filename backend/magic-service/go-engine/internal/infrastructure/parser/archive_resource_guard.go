@@ -39,6 +39,31 @@ func checkZipArchiveLimits(files []*zip.File, limits documentdomain.ResourceLimi
 	return nil
 }
 
+// archiveEntryBudget 累计非 zip 容器（邮件附件、MSG 复合文档流）展开后的体积，沿用压缩包单条目与总量限制。
+type archiveEntryBudget struct {
+	limits documentdomain.ResourceLimits
+	total  int64
+}
+
+func newArchiveEntryBudget(limits documentdomain.ResourceLimits) *archiveEntryBudget {
+	return &archiveEntryBudget{limits: documentdomain.NormalizeResourceLimits(limits)}
+}
+
+func (b *archiveEntryBudget) consume(name string, size int64) error {
+	if err := documentdomain.CheckArchiveEntrySize(size, b.limits); err != nil {
+		return fmt.Errorf("check archive entry %s: %w", name, err)
+	}
+	if size > math.MaxInt64-b.total {
+		b.total = math.MaxInt64
+	} else {
+		b.total += size
+	}
+	if err := documentdomain.CheckArchiveUncompressedSize(b.total, b.limits); err != nil {
+		return fmt.Errorf("check archive uncompressed size: %w", err)
+	}
+	return nil
+}
+
 func checkOfficeZipArchiveLimits(files []*zip.File, limits documentdomain.ResourceLimits) error {
 	if err := checkZipArchiveLimits(files, limits); err != nil {
 		return err
@@ -56,7 +81,7 @@ func checkOfficeZipArchiveLimits(files []*zip.File, limits documentdomain.Resour
 
 func isOfficeEmbeddedAssetEntry(name string) bool {
 	normalized := strings.ToLower(path.Clean(strings.TrimSpace(name)))
-	return strings.Contains(normalized, "/media/")
+	return strings.Contains(normalized, "/media/") || strings.HasPrefix(normalized, "pictures/")
 }
 
 func readZipEntryWithArchiveLimit(files []*zip.File, entryPath string, limits documentdomain.ResourceLimits) ([]byte, error) {
@@ -0,0 +1,102 @@
+package docparser
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"strings"
+)
+
+// segmentGoCode 用 go/ast 按顶层函数、方法和类型声明切分；解析失败时返回 ok=false 交给括号启发式。
+func segmentGoCode(source []byte) (string, []codeSymbol, bool) {
+	fileSet := token.NewFileSet()
+	file, err := goparser.ParseFile(fileSet, "", source, goparser.ParseComments|goparser.SkipObjectResolution)
+	if err != nil {
+		return "", nil, false
+	}
+	symbols := make([]codeSymbol, 0, len(file.Decls))
+	for _, decl := range file.Decls {
+		var (
+			name string
+			kind string
+			doc  *ast.CommentGroup
+		)
+		switch typed := decl.(type) {
+		case *ast.FuncDecl:
+			name, kind = resolveGoFuncSymbol(typed)
+			doc = typed.Doc
+		case *ast.GenDecl:
+			if typed.Tok != token.TYPE {
+				continue
+			}
+			name, kind = resolveGoTypeSymbol(typed)
+			doc = typed.Doc
+		default:
+			continue
+		}
+		start := decl.Pos()
+		if doc != nil {
+			start = doc.Pos()
+		}
+		symbols = append(symbols, codeSymbol{
+			name: name,
+			kind: kind,
+			ranges: []codeLineRange{{
+				start: fileSet.Position(start).Line - 1,
+				end:   fileSet.Position(decl.End()).Line - 1,
+			}},
+		})
+	}
+	return file.Name.Name, symbols, true
+}
+
+func resolveGoFuncSymbol(decl *ast.FuncDecl) (string, string) {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return decl.Name.Name, codeKindFunction
+	}
+	receiver := resolveGoReceiverTypeName(decl.Recv.List[0].Type)
+	if receiver == "" {
+		return decl.Name.Name, codeKindMethod
+	}
+	return receiver + "." + decl.Name.Name, codeKindMethod
+}
+
+func resolveGoReceiverTypeName(expr ast.Expr) string {
+	switch typed := expr.(type) {
+	case *ast.Ident:
+		return typed.Name
+	case *ast.StarExpr:
+		return resolveGoReceiverTypeName(typed.X)
+	case *ast.ParenExpr:
+		return resolveGoReceiverTypeName(typed.X)
+	case *ast.IndexExpr:
+		return resolveGoReceiverTypeName(typed.X)
+	case *ast.IndexListExpr:
+		return resolveGoReceiverTypeName(typed.X)
+	default:
+		return ""
+	}
+}
+
+// resolveGoTypeSymbol 单个类型按 struct/interface 细分；type (...) 分组整体作为一个符号。
+func resolveGoTypeSymbol(decl *ast.GenDecl) (string, string) {
+	names := make([]string, 0, len(decl.Specs))
+	kind := "type"
+	for _, spec := range decl.Specs {
+		typeSpec, ok := spec.(*ast.TypeSpec)
+		if !ok {
+			continue
+		}
+		names = append(names, typeSpec.Name.Name)
+		if len(decl.Specs) != 1 {
+			continue
+		}
+		switch typeSpec.Type.(type) {
+		case *ast.StructType:
+			kind = "struct"
+		case *ast.InterfaceType:
+			kind = "interface"
+		}
+	}
+	return strings.Join(names, ","), kind
+}
@@ -0,0 +1,161 @@
+package docparser
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	codeFamilyGo     = "go"
+	codeFamilyBrace  = "brace"
+	codeFamilyIndent = "indent"
+
+	codeKindFunction = "function"
+	codeKindMethod   = "method"
+	codeKindModule   = "module"
+
+	// codeSignatureMaxLines 是声明行之后等待 "{" 出现的最大行数，超过即视为无函数体的声明。
+	codeSignatureMaxLines = 8
+	// codeCharLiteralMaxRunes 用于区分字符字面量与 Rust 生命周期等单引号用法。
+	codeCharLiteralMaxRunes = 4
+)
+
+var (
+	codeContainerPattern = regexp.MustCompile(`^\s*(?:@\w+(?:\([^)]*\))?\s+)*(?:(?:export|default|public|private|protected|internal|abstract|final|sealed|static|partial|open|data|inner|declare|readonly|unsafe|pub(?:\([^)]*\))?)\s+)*(class|interface|struct|enum|trait|object|namespace|record|union)\s+([A-Za-z_$][\w$]*)`)
+	codeRustImplPattern  = regexp.MustCompile(`^\s*(?:unsafe\s+)?impl(?:\s*<[^>]*>)?\s+([\w:]+)(?:<[^>]*>)?(?:\s+for\s+([\w:]+))?`)
+	codeKeywordFunc      = regexp.MustCompile(`^\s*(?:@\w+(?:\([^)]*\))?\s+)*(?:[\w$]+(?:\([^)]*\))?\s+)*?(?:function|func|fun|fn|def)\s*\*?\s*(?:\([^)]*\)\s*)?(?:<[^>]*>\s*)?(?:[\w$]+\.)?([A-Za-z_$][\w$]*)\s*[(<\[:=]`)
+	codeArrowFunc        = regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*(?::[^=]+)?=>|[A-Za-z_$][\w$]*\s*=>)`)
+	codeJSMethod         = regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|async|readonly|override|abstract|get|set)\s+)*\*?([A-Za-z_$][\w$]*)\s*(?:<[^>]*>)?\s*\([^;]*$`)
+	codeCSignature       = regexp.MustCompile(`^\s*((?:[\w:<>\[\],*&~?]+\s+)+)[*&]*([A-Za-z_~][\w:~]*)\s*\([^;]*$`)
+	codeModulePattern    = regexp.MustCompile(`(?m)^\s*(?:package|namespace)\s+([A-Za-z_][\w.\\]*)`)
+)
+
+// codeLanguage 描述一种语言的切分方式；括号族语言按声明正则 + 花括号深度启发式切分。
+type codeLanguage struct {
+	name               string
+	family             string
+	keywordFuncs       bool
+	arrowFuncs         bool
+	jsMethods          bool
+	cSignatures        bool
+	rustImpl           bool
+	singleQuoteStrings bool
+	backtickStrings    bool
+	hashComments       bool
+}
+
+// codeDecl 是一行声明的识别结果。
+type codeDecl struct {
+	name      string
+	kind      string
+	container bool
+}
+
+func resolveCodeLanguage(fileType string) (codeLanguage, bool) {
+	switch strings.ToLower(strings.TrimSpace(fileType)) {
+	case "go":
+		return codeLanguage{name: "go", family: codeFamilyGo, keywordFuncs: true, backtickStrings: true}, true
+	case "py":
+		return codeLanguage{name: "python", family: codeFamilyIndent}, true
+	case "js", "jsx", "mjs", "cjs":
+		return newJSCodeLanguage("javascript"), true
+	case "ts", "tsx":
+		return newJSCodeLanguage("typescript"), true
+	case "java":
+		return codeLanguage{name: "java", family: codeFamilyBrace, cSignatures: true}, true
+	case "cs":
+		return codeLanguage{name: "csharp", family: codeFamilyBrace, cSignatures: true}, true
+	case "c", "h":
+		return codeLanguage{name: "c", family: codeFamilyBrace, cSignatures: true}, true
+	case "cc", "cpp", "cxx", "hpp", "hh":
+		return codeLanguage{name: "cpp", family: codeFamilyBrace, cSignatures: true}, true
+	case "kt", "kts":
+		return codeLanguage{name: "kotlin", family: codeFamilyBrace, keywordFuncs: true}, true
+	case "scala":
+		return codeLanguage{name: "scala", family: codeFamilyBrace, keywordFuncs: true}, true
+	case "swift":
+		return codeLanguage{name: "swift", family: codeFamilyBrace, keywordFuncs: true}, true
+	case "rs":
+		return codeLanguage{name: "rust", family: codeFamilyBrace, keywordFuncs: true, rustImpl: true}, true
+	case "php":
+		return codeLanguage{name: "php", family: codeFamilyBrace, keywordFuncs: true, singleQuoteStrings: true, hashComments: true}, true
+	default:
+		return codeLanguage{}, false
+	}
+}
+
+func newJSCodeLanguage(name string) codeLanguage {
+	return codeLanguage{
+		name:               name,
+		family:             codeFamilyBrace,
+		keywordFuncs:       true,
+		arrowFuncs:         true,
+		jsMethods:          true,
+		singleQuoteStrings: true,
+		backtickStrings:    true,
+	}
+}
+
+// matchDecl 识别一行是否是声明；member 为 true 时按类体内成员识别。
+func (l codeLanguage) matchDecl(line string, member bool) (codeDecl, bool) {
+	if match := codeContainerPattern.FindStringSubmatch(line); match != nil {
+		return codeDecl{name: match[2], kind: match[1], container: true}, true
+	}
+	if l.rustImpl {
+		if match := codeRustImplPattern.FindStringSubmatch(line); match != nil {
+			return codeDecl{name: lastCodePathSegment(cmp.Or(match[2], match[1])), kind: "impl", container: true}, true
+		}
+	}
+	kind := codeKindFunction
+	if member {
+		kind = codeKindMethod
+	}
+	for _, name := range l.matchFunctionNames(line, member) {
+		if name != "" && !isCodeKeyword(name) {
+			return codeDecl{name: strings.ReplaceAll(name, "::", "."), kind: kind}, true
+		}
+	}
+	return codeDecl{}, false
+}
+
+func (l codeLanguage) matchFunctionNames(line string, member bool) []string {
+	names := make([]string, 0, 1)
+	if l.keywordFuncs {
+		if match := codeKeywordFunc.FindStringSubmatch(line); match != nil {
+			names = append(names, match[1])
+		}
+	}
+	if l.arrowFuncs {
+		if match := codeArrowFunc.FindStringSubmatch(line); match != nil {
+			names = append(names, match[1])
+		}
+	}
+	if l.jsMethods && member {
+		if match := codeJSMethod.FindStringSubmatch(line); match != nil {
+			names = append(names, match[1])
+		}
+	}
+	if l.cSignatures {
+		if match := codeCSignature.FindStringSubmatch(line); match != nil && !isCodeKeyword(strings.Fields(match[1])[0]) {
+			names = append(names, match[2])
+		}
+	}
+	return names
+}
+
+func isCodeKeyword(word string) bool {
+	return slices.Contains([]string{
+		"if", "else", "for", "foreach", "while", "do", "switch", "case", "return", "catch", "try",
+		"new", "delete", "throw", "sizeof", "typeof", "await", "yield", "using", "lock", "match",
+		"function",
+	}, word)
+}
+
+func lastCodePathSegment(value string) string {
+	if index := strings.LastIndex(value, "::"); index >= 0 {
+		return value[index+2:]
+	}
+	return value
+}
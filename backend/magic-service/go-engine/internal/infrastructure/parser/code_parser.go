@@ -0,0 +1,272 @@
+package docparser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+)
+
+// CodeParser 解析源代码，按函数/类型/类边界输出符号块并记录符号名、文件路径和行号。
+type CodeParser struct {
+	limits documentdomain.ResourceLimits
+}
+
+var errCodeSourceNotText = errors.New("source code is not valid utf-8 text")
+
+// NewCodeParser 创建源代码解析器。
+func NewCodeParser(resourceLimits ...documentdomain.ResourceLimits) *CodeParser {
+	limits := documentdomain.DefaultResourceLimits()
+	if len(resourceLimits) > 0 {
+		limits = resourceLimits[0]
+	}
+	return &CodeParser{limits: documentdomain.NormalizeResourceLimits(limits)}
+}
+
+// Parse 解析源代码并返回原文。
+func (p *CodeParser) Parse(ctx context.Context, fileURL string, file io.Reader, fileType string) (string, error) {
+	parsed, err := p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, documentdomain.DefaultParseOptions())
+	if err != nil {
+		return "", err
+	}
+	return parsed.BestEffortText(), nil
+}
+
+// ParseWithOptions 按解析选项解析源代码。
+func (p *CodeParser) ParseWithOptions(
+	ctx context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+	options documentdomain.ParseOptions,
+) (string, error) {
+	parsed, err := p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, options)
+	if err != nil {
+		return "", err
+	}
+	return parsed.BestEffortText(), nil
+}
+
+// ParseDocument 解析源代码并返回结构化结果。
+func (p *CodeParser) ParseDocument(
+	ctx context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+) (*documentdomain.ParsedDocument, error) {
+	return p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, documentdomain.DefaultParseOptions())
+}
+
+// ParseDocumentWithOptions 按解析选项解析源代码并返回结构化结果。
+//
+// Go 使用 go/ast 精确切分，其余语言按花括号或缩进启发式切分；符号之外的 import、常量等
+// 连续代码合并为模块块，保证全文每一行都落在某个块里。
+func (p *CodeParser) ParseDocumentWithOptions(
+	_ context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+	_ documentdomain.ParseOptions,
+) (*documentdomain.ParsedDocument, error) {
+	language, ok := resolveCodeLanguage(fileType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", documentdomain.ErrNoParserFound, fileType)
+	}
+	source, err := io.ReadAll(documentdomain.NewSourceSizeLimitedReader(file, p.limits))
+	if err != nil {
+		return nil, fmt.Errorf("read source code failed: %w", err)
+	}
+	if err := documentdomain.CheckPlainTextBytes(source, p.limits, "parse_code"); err != nil {
+		return nil, fmt.Errorf("check source code size: %w", err)
+	}
+	if !utf8.Valid(source) {
+		return nil, errCodeSourceNotText
+	}
+	content := strings.ReplaceAll(string(source), "\r\n", "\n")
+	lines := strings.Split(content, "\n")
+	filePath := resolveCodeFilePath(fileURL)
+
+	module, symbols := segmentCodeSource(language, []byte(content), lines)
+	if module == "" {
+		module = resolveCodeModuleName(content, filePath, language)
+	}
+	blocks, err := p.buildCodeBlocks(codeDocumentInput{
+		language: language.name,
+		filePath: filePath,
+		module:   module,
+		lines:    lines,
+		symbols:  symbols,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &documentdomain.ParsedDocument{
+		SourceType: documentdomain.ParsedDocumentSourceCode,
+		PlainText:  content,
+		Blocks:     blocks,
+		DocumentMeta: map[string]any{
+			documentdomain.ParsedMetaSourceFormat: strings.ToLower(strings.TrimSpace(fileType)),
+			documentdomain.ParsedMetaCodeLanguage: language.name,
+			documentdomain.ParsedMetaCodeFilePath: filePath,
+			"code_symbol_count":                   len(symbols),
+		},
+	}, nil
+}
+
+// Supports 检查是否支持该文件类型。
+func (p *CodeParser) Supports(fileType string) bool {
+	_, ok := resolveCodeLanguage(fileType)
+	return ok
+}
+
+// NeedsResolvedURL 源代码解析只依赖文件流。
+func (p *CodeParser) NeedsResolvedURL() bool {
+	return false
+}
+
+type codeDocumentInput struct {
+	language string
+	filePath string
+	module   string
+	lines    []string
+	symbols  []codeSymbol
+}
+
+func segmentCodeSource(language codeLanguage, source []byte, lines []string) (string, []codeSymbol) {
+	switch language.family {
+	case codeFamilyGo:
+		if module, symbols, ok := segmentGoCode(source); ok {
+			return module, symbols
+		}
+		// 语法不完整的 Go 文件退回括号启发式，包名仍按 package 子句识别。
+		return "", segmentBraceCode(lines, language)
+	case codeFamilyIndent:
+		return "", segmentIndentCode(lines)
+	default:
+		return "", segmentBraceCode(lines, language)
+	}
+}
+
+// buildCodeBlocks 把符号与未被覆盖的连续行组装成按行号排序的块。
+func (p *CodeParser) buildCodeBlocks(input codeDocumentInput) ([]documentdomain.ParsedBlock, error) {
+	symbols := append(slices.Clone(input.symbols), collectCodeModuleSymbols(input.lines, input.symbols)...)
+	slices.SortStableFunc(symbols, func(left, right codeSymbol) int {
+		return left.ranges[0].start - right.ranges[0].start
+	})
+
+	counter := newStructuredParseCounter(p.limits, "parse_code_blocks")
+	blocks := make([]documentdomain.ParsedBlock, 0, len(symbols))
+	for _, symbol := range symbols {
+		symbol.ranges = trimCodeBlankRanges(input.lines, symbol.ranges)
+		if len(symbol.ranges) == 0 {
+			continue
+		}
+		content := joinCodeRanges(input.lines, symbol.ranges)
+		if err := counter.observe(); err != nil {
+			return nil, err
+		}
+		blockType := documentdomain.ParsedBlockTypeCodeSymbol
+		sectionPath := input.module
+		if symbol.kind == codeKindModule {
+			blockType = documentdomain.ParsedBlockTypeCodeModule
+		} else {
+			sectionPath = input.module + "/" + symbol.name
+		}
+		blocks = append(blocks, documentdomain.ParsedBlock{
+			Type:    blockType,
+			Content: content,
+			Metadata: map[string]any{
+				documentdomain.ParsedMetaChunkType:       blockType,
+				documentdomain.ParsedMetaCodeLanguage:    input.language,
+				documentdomain.ParsedMetaCodeFilePath:    input.filePath,
+				documentdomain.ParsedMetaCodeSymbol:      symbol.name,
+				documentdomain.ParsedMetaCodeSymbolKind:  symbol.kind,
+				documentdomain.ParsedMetaCodeSectionPath: sectionPath,
+				documentdomain.ParsedMetaCodeStartLine:   symbol.ranges[0].start + 1,
+				documentdomain.ParsedMetaCodeEndLine:     symbol.ranges[len(symbol.ranges)-1].end + 1,
+			},
+		})
+	}
+	return blocks, nil
+}
+
+// collectCodeModuleSymbols 把不属于任何符号的连续行收成模块块。
+func collectCodeModuleSymbols(lines []string, symbols []codeSymbol) []codeSymbol {
+	covered := make([]bool, len(lines))
+	for _, symbol := range symbols {
+		for _, lineRange := range symbol.ranges {
+			for index := max(lineRange.start, 0); index <= lineRange.end && index < len(lines); index++ {
+				covered[index] = true
+			}
+		}
+	}
+	modules := make([]codeSymbol, 0)
+	start := -1
+	for index := 0; index <= len(lines); index++ {
+		if index == len(lines) || covered[index] {
+			if start >= 0 {
+				modules = append(modules, codeSymbol{kind: codeKindModule, ranges: []codeLineRange{{start: start, end: index - 1}}})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 && strings.TrimSpace(lines[index]) != "" {
+			start = index
+		}
+	}
+	return modules
+}
+
+// trimCodeBlankRanges 去掉区间首尾空行并丢弃全空区间，容器扣除成员后常会剩下空行碎片。
+func trimCodeBlankRanges(lines []string, ranges []codeLineRange) []codeLineRange {
+	trimmed := make([]codeLineRange, 0, len(ranges))
+	for _, lineRange := range ranges {
+		start := max(lineRange.start, 0)
+		end := min(lineRange.end, len(lines)-1)
+		for start <= end && strings.TrimSpace(lines[start]) == "" {
+			start++
+		}
+		for end >= start && strings.TrimSpace(lines[end]) == "" {
+			end--
+		}
+		if start <= end {
+			trimmed = append(trimmed, codeLineRange{start: start, end: end})
+		}
+	}
+	return trimmed
+}
+
+func joinCodeRanges(lines []string, ranges []codeLineRange) string {
+	parts := make([]string, 0, len(ranges))
+	for _, lineRange := range ranges {
+		parts = append(parts, strings.Join(lines[lineRange.start:lineRange.end+1], "\n"))
+	}
+	return strings.Join(parts, "\n")
+}
+
+func resolveCodeFilePath(fileURL string) string {
+	trimmed := strings.TrimSpace(fileURL)
+	if parsed, err := url.Parse(trimmed); err == nil && parsed.Path != "" {
+		trimmed = parsed.Path
+	}
+	return strings.TrimLeft(trimmed, "/")
+}
+
+// resolveCodeModuleName 优先取 package/namespace 声明，否则用文件名（不含扩展名）。
+func resolveCodeModuleName(content, filePath string, language codeLanguage) string {
+	if match := codeModulePattern.FindStringSubmatch(content); match != nil {
+		return strings.ReplaceAll(strings.TrimSuffix(match[1], ";"), "\\", ".")
+	}
+	base := path.Base(filePath)
+	if base == "." || base == "/" || base == "" {
+		return language.name
+	}
+	return strings.TrimSuffix(base, path.Ext(base))
+}
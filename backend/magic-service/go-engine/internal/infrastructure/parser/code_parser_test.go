@@ -0,0 +1,201 @@
+package docparser_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+	parser "magic/internal/infrastructure/parser"
+	"magic/internal/pkg/filetype"
+)
+
+const codeTestGoSource = `package server
+
+import "net/http"
+
+// Server 对外提供 HTTP 服务。
+type Server struct {
+	addr string
+}
+
+// Start 启动服务。
+func (s *Server) Start() error {
+	return http.ListenAndServe(s.addr, nil)
+}
+
+func NewServer(addr string) *Server {
+	return &Server{addr: addr}
+}
+`
+
+const codeTestPythonSource = `import os
+
+
+class Repo:
+    """仓库封装。"""
+
+    def __init__(self, path):
+        self.path = path
+
+    @property
+    def name(self):
+        # 取目录名
+        return os.path.basename(self.path)
+
+
+def main():
+    print(Repo(".").name)
+`
+
+const codeTestTypeScriptSource = `import { api } from "./api";
+
+export class UserService {
+  private cache = new Map<string, string>();
+
+  // 读取用户，字符串里的 "}" 不影响配对。
+  async load(id: string): Promise<string> {
+    const text = "} not a brace {";
+    return api.get(id + text);
+  }
+
+  save(id: string) {
+    /* } */
+    this.cache.set(id, ` + "`${id}}`" + `);
+  }
+}
+
+export const format = (value: string) => {
+  return value.trim();
+};
+`
+
+const codeTestJavaSource = `package com.example.shop;
+
+import java.util.List;
+
+public class OrderService {
+    @Override
+    public String toString() {
+        return "OrderService{}";
+    }
+
+    private int total(List<Integer> prices) {
+        int sum = 0;
+        for (int price : prices) {
+            sum += price;
+        }
+        return sum;
+    }
+}
+`
+
+type codeTestBlock struct {
+	sectionPath string
+	kind        string
+	startLine   int
+	endLine     int
+}
+
+func parseCodeBlocks(t *testing.T, fileURL, source, fileType string) (*documentdomain.ParsedDocument, map[string]codeTestBlock) {
+	t.Helper()
+
+	parsed, err := parser.NewCodeParser().ParseDocument(context.Background(), fileURL, strings.NewReader(source), fileType)
+	if err != nil {
+		t.Fatalf("parse %s: %v", fileType, err)
+	}
+	if parsed.SourceType != documentdomain.ParsedDocumentSourceCode || parsed.PlainText != source {
+		t.Fatalf("unexpected parsed document: %#v", parsed)
+	}
+	blocks := make(map[string]codeTestBlock, len(parsed.Blocks))
+	for _, block := range parsed.Blocks {
+		symbol, _ := block.Metadata[documentdomain.ParsedMetaCodeSymbol].(string)
+		sectionPath, _ := block.Metadata[documentdomain.ParsedMetaCodeSectionPath].(string)
+		kind, _ := block.Metadata[documentdomain.ParsedMetaCodeSymbolKind].(string)
+		startLine, _ := block.Metadata[documentdomain.ParsedMetaCodeStartLine].(int)
+		endLine, _ := block.Metadata[documentdomain.ParsedMetaCodeEndLine].(int)
+		if block.Metadata[documentdomain.ParsedMetaCodeFilePath] == "" {
+			t.Fatalf("expected file path metadata, got %#v", block.Metadata)
+		}
+		blocks[symbol] = codeTestBlock{sectionPath: sectionPath, kind: kind, startLine: startLine, endLine: endLine}
+	}
+	return parsed, blocks
+}
+
+func assertCodeBlock(t *testing.T, blocks map[string]codeTestBlock, symbol string, expected codeTestBlock) {
+	t.Helper()
+
+	actual, ok := blocks[symbol]
+	if !ok {
+		t.Fatalf("expected symbol %q in %#v", symbol, blocks)
+	}
+	if actual != expected {
+		t.Fatalf("unexpected block for %q: got %#v want %#v", symbol, actual, expected)
+	}
+}
+
+func TestCodeParser_ParseGoUsesASTSymbols(t *testing.T) {
+	t.Parallel()
+
+	parsed, blocks := parseCodeBlocks(t, "https://example.com/repo/internal/server/server.go?sig=1", codeTestGoSource, "go")
+	if parsed.Blocks[0].Type != documentdomain.ParsedBlockTypeCodeModule {
+		t.Fatalf("expected leading module block, got %#v", parsed.Blocks[0])
+	}
+	if got := parsed.Blocks[0].Metadata[documentdomain.ParsedMetaCodeFilePath]; got != "repo/internal/server/server.go" {
+		t.Fatalf("unexpected file path %v", got)
+	}
+	assertCodeBlock(t, blocks, "", codeTestBlock{sectionPath: "server", kind: "module", startLine: 1, endLine: 3})
+	assertCodeBlock(t, blocks, "Server", codeTestBlock{sectionPath: "server/Server", kind: "struct", startLine: 5, endLine: 8})
+	assertCodeBlock(t, blocks, "Server.Start", codeTestBlock{sectionPath: "server/Server.Start", kind: "method", startLine: 10, endLine: 13})
+	assertCodeBlock(t, blocks, "NewServer", codeTestBlock{sectionPath: "server/NewServer", kind: "function", startLine: 15, endLine: 17})
+	if !strings.HasPrefix(parsed.Blocks[2].Content, "// Start 启动服务。") {
+		t.Fatalf("expected doc comment attached to method, got %q", parsed.Blocks[2].Content)
+	}
+}
+
+func TestCodeParser_ParsePythonUsesIndentation(t *testing.T) {
+	t.Parallel()
+
+	_, blocks := parseCodeBlocks(t, "tools/repo.py", codeTestPythonSource, "py")
+	assertCodeBlock(t, blocks, "Repo", codeTestBlock{sectionPath: "repo/Repo", kind: "class", startLine: 4, endLine: 5})
+	assertCodeBlock(t, blocks, "Repo.__init__", codeTestBlock{sectionPath: "repo/Repo.__init__", kind: "method", startLine: 7, endLine: 8})
+	assertCodeBlock(t, blocks, "Repo.name", codeTestBlock{sectionPath: "repo/Repo.name", kind: "method", startLine: 10, endLine: 13})
+	assertCodeBlock(t, blocks, "main", codeTestBlock{sectionPath: "repo/main", kind: "function", startLine: 16, endLine: 17})
+}
+
+func TestCodeParser_ParseBraceLanguagesIgnoresBracesInStringsAndComments(t *testing.T) {
+	t.Parallel()
+
+	_, tsBlocks := parseCodeBlocks(t, "src/user_service.ts", codeTestTypeScriptSource, "ts")
+	assertCodeBlock(t, tsBlocks, "UserService.load", codeTestBlock{
+		sectionPath: "user_service/UserService.load", kind: "method", startLine: 6, endLine: 10,
+	})
+	assertCodeBlock(t, tsBlocks, "UserService.save", codeTestBlock{
+		sectionPath: "user_service/UserService.save", kind: "method", startLine: 12, endLine: 15,
+	})
+	assertCodeBlock(t, tsBlocks, "format", codeTestBlock{sectionPath: "user_service/format", kind: "function", startLine: 18, endLine: 20})
+
+	_, javaBlocks := parseCodeBlocks(t, "src/OrderService.java", codeTestJavaSource, "java")
+	assertCodeBlock(t, javaBlocks, "OrderService.toString", codeTestBlock{
+		sectionPath: "com.example.shop/OrderService.toString", kind: "method", startLine: 6, endLine: 9,
+	})
+	assertCodeBlock(t, javaBlocks, "OrderService.total", codeTestBlock{
+		sectionPath: "com.example.shop/OrderService.total", kind: "method", startLine: 11, endLine: 17,
+	})
+}
+
+func TestCodeParser_SupportsSourceCodeExtensions(t *testing.T) {
+	t.Parallel()
+
+	codeParser := parser.NewCodeParser()
+	for _, extension := range filetype.SourceCodeExtensions() {
+		if !codeParser.Supports(extension) {
+			t.Fatalf("expected %s to be supported", extension)
+		}
+	}
+	for _, extension := range []string{"txt", "md", "json"} {
+		if codeParser.Supports(extension) {
+			t.Fatalf("expected %s not to be supported", extension)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package docparser
+
+import (
+	"regexp"
+	"strings"
+)
+
+const codeIndentTabWidth = 4
+
+var (
+	codePythonDeclPattern  = regexp.MustCompile(`^\s*(?:async\s+)?(def|class)\s+([A-Za-z_]\w*)`)
+	codeTripleQuotePattern = regexp.MustCompile(`"""|'''`)
+)
+
+// codeLineRange 是 0 起始、两端包含的行区间。
+type codeLineRange struct {
+	start int
+	end   int
+}
+
+// codeSymbol 是切分出的一个符号；容器类符号的 ranges 会扣掉成员所在行。
+type codeSymbol struct {
+	name   string
+	kind   string
+	ranges []codeLineRange
+}
+
+// codeBraceDepths 记录每行开始与结束时的花括号深度，已忽略字符串与注释中的括号。
+type codeBraceDepths struct {
+	before []int
+	after  []int
+}
+
+type codeBraceScanState struct {
+	inBlockComment bool
+	stringDelim    rune
+}
+
+func scanCodeBraceDepths(lines []string, language codeLanguage) codeBraceDepths {
+	depths := codeBraceDepths{before: make([]int, len(lines)), after: make([]int, len(lines))}
+	state := codeBraceScanState{}
+	depth := 0
+	for index, line := range lines {
+		depths.before[index] = depth
+		depth = state.scanLine([]rune(line), depth, language)
+		depths.after[index] = depth
+	}
+	return depths
+}
+
+func (s *codeBraceScanState) scanLine(runes []rune, depth int, language codeLanguage) int {
+	for index := 0; index < len(runes); index++ {
+		current := runes[index]
+		next := rune(0)
+		if index+1 < len(runes) {
+			next = runes[index+1]
+		}
+		switch {
+		case s.inBlockComment:
+			if current == '*' && next == '/' {
+				s.inBlockComment = false
+				index++
+			}
+		case s.stringDelim != 0:
+			if current == '\\' {
+				index++
+			} else if current == s.stringDelim {
+				s.stringDelim = 0
+			}
+		case current == '/' && next == '/', current == '#' && language.hashComments:
+			return s.endLine(depth)
+		case current == '/' && next == '*':
+			s.inBlockComment = true
+			index++
+		case current == '"', current == '`' && language.backtickStrings, current == '\'' && language.singleQuoteStrings:
+			s.stringDelim = current
+		case current == '\'':
+			index = skipCodeCharLiteral(runes, index)
+		case current == '{':
+			depth++
+		case current == '}':
+			depth = max(depth-1, 0)
+		}
+	}
+	return s.endLine(depth)
+}
+
+// endLine 普通引号字符串不跨行，行尾时复位；反引号字符串允许跨行。
+func (s *codeBraceScanState) endLine(depth int) int {
+	if s.stringDelim != 0 && s.stringDelim != '`' {
+		s.stringDelim = 0
+	}
+	return depth
+}
+
+func skipCodeCharLiteral(runes []rune, index int) int {
+	for offset := 1; offset <= codeCharLiteralMaxRunes && index+offset < len(runes); offset++ {
+		switch runes[index+offset] {
+		case '\\':
+			offset++
+		case '\'':
+			return index + offset
+		}
+	}
+	return index
+}
+
+// segmentBraceCode 在顶层识别声明并按花括号配对找到结束行，容器声明再向下识别一层成员。
+func segmentBraceCode(lines []string, language codeLanguage) []codeSymbol {
+	depths := scanCodeBraceDepths(lines, language)
+	symbols := make([]codeSymbol, 0)
+	floor := 0
+	for index := 0; index < len(lines); index++ {
+		if depths.before[index] != 0 {
+			continue
+		}
+		decl, ok := language.matchDecl(lines[index], false)
+		if !ok {
+			continue
+		}
+		end := findCodeBraceBlockEnd(lines, depths, index, 0)
+		start := attachCodeLeadingLines(lines, index, floor)
+		if decl.container && end > index {
+			members := segmentBraceMembers(lines, depths, language, decl.name, codeLineRange{start: index + 1, end: end - 1})
+			symbols = append(symbols, codeSymbol{
+				name:   decl.name,
+				kind:   decl.kind,
+				ranges: subtractCodeRanges(codeLineRange{start: start, end: end}, members),
+			})
+			symbols = append(symbols, members...)
+		} else {
+			symbols = append(symbols, codeSymbol{name: decl.name, kind: decl.kind, ranges: []codeLineRange{{start: start, end: end}}})
+		}
+		index = end
+		floor = end + 1
+	}
+	return symbols
+}
+
+func segmentBraceMembers(
+	lines []string,
+	depths codeBraceDepths,
+	language codeLanguage,
+	container string,
+	body codeLineRange,
+) []codeSymbol {
+	members := make([]codeSymbol, 0)
+	floor := body.start
+	for index := body.start; index <= body.end; index++ {
+		if depths.before[index] != 1 {
+			continue
+		}
+		decl, ok := language.matchDecl(lines[index], true)
+		if !ok {
+			continue
+		}
+		end := min(findCodeBraceBlockEnd(lines, depths, index, 1), body.end)
+		members = append(members, codeSymbol{
+			name:   container + "." + decl.name,
+			kind:   decl.kind,
+			ranges: []codeLineRange{{start: attachCodeLeadingLines(lines, index, floor), end: end}},
+		})
+		index = end
+		floor = end + 1
+	}
+	return members
+}
+
+// findCodeBraceBlockEnd 从声明行开始找到深度回落到 base 的行；未出现 "{" 时按语句或签名结束。
+func findCodeBraceBlockEnd(lines []string, depths codeBraceDepths, start, base int) int {
+	opened := false
+	for index := start; index < len(lines); index++ {
+		if depths.after[index] > base {
+			opened = true
+		}
+		if opened && depths.after[index] <= base {
+			return index
+		}
+		if !opened && strings.HasSuffix(strings.TrimSpace(lines[index]), ";") {
+			return index
+		}
+		if !opened && index-start >= codeSignatureMaxLines {
+			return start
+		}
+	}
+	if !opened {
+		return start
+	}
+	return len(lines) - 1
+}
+
+// attachCodeLeadingLines 把紧贴声明的注释、注解和装饰器并入符号。
+func attachCodeLeadingLines(lines []string, index, floor int) int {
+	start := index
+	for start-1 >= floor && isCodeLeadingLine(lines[start-1]) {
+		start--
+	}
+	return start
+}
+
+func isCodeLeadingLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	// "#" 只接受 Python/PHP 注释和 Rust 属性，避免把 C 预处理指令并入函数。
+	for _, prefix := range []string{"//", "/*", "*", "@", "#[", "# ", "##"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return trimmed == "#"
+}
+
+func subtractCodeRanges(outer codeLineRange, members []codeSymbol) []codeLineRange {
+	ranges := make([]codeLineRange, 0, len(members)+1)
+	cursor := outer.start
+	for _, member := range members {
+		for _, memberRange := range member.ranges {
+			if memberRange.start > cursor {
+				ranges = append(ranges, codeLineRange{start: cursor, end: memberRange.start - 1})
+			}
+			cursor = max(cursor, memberRange.end+1)
+		}
+	}
+	if cursor <= outer.end {
+		ranges = append(ranges, codeLineRange{start: cursor, end: outer.end})
+	}
+	return ranges
+}
+
+// segmentIndentCode 按缩进切分 Python：顶层 def/class 为符号，class 体内同一缩进的 def 为成员。
+func segmentIndentCode(lines []string) []codeSymbol {
+	significant := markCodeIndentSignificantLines(lines)
+	symbols := make([]codeSymbol, 0)
+	floor := 0
+	for index := 0; index < len(lines); index++ {
+		if !significant[index] || codeIndentWidth(lines[index]) != 0 {
+			continue
+		}
+		match := codePythonDeclPattern.FindStringSubmatch(lines[index])
+		if match == nil {
+			continue
+		}
+		end := findCodeIndentBlockEnd(lines, significant, index, 0)
+		start := attachCodeLeadingLines(lines, index, floor)
+		if match[1] != "class" {
+			symbols = append(symbols, codeSymbol{name: match[2], kind: codeKindFunction, ranges: []codeLineRange{{start: start, end: end}}})
+		} else {
+			members := segmentIndentMembers(lines, significant, match[2], codeLineRange{start: index + 1, end: end})
+			symbols = append(symbols, codeSymbol{
+				name:   match[2],
+				kind:   "class",
+				ranges: subtractCodeRanges(codeLineRange{start: start, end: end}, members),
+			})
+			symbols = append(symbols, members...)
+		}
+		index = end
+		floor = end + 1
+	}
+	return symbols
+}
+
+func segmentIndentMembers(lines []string, significant []bool, container string, body codeLineRange) []codeSymbol {
+	bodyIndent := -1
+	members := make([]codeSymbol, 0)
+	floor := body.start
+	for index := body.start; index <= body.end; index++ {
+		if !significant[index] {
+			continue
+		}
+		indent := codeIndentWidth(lines[index])
+		if bodyIndent < 0 {
+			bodyIndent = indent
+		}
+		if indent != bodyIndent {
+			continue
+		}
+		match := codePythonDeclPattern.FindStringSubmatch(lines[index])
+		if match == nil {
+			continue
+		}
+		kind := codeKindMethod
+		if match[1] == "class" {
+			kind = "class"
+		}
+		end := findCodeIndentBlockEnd(lines, significant, index, bodyIndent)
+		members = append(members, codeSymbol{
+			name:   container + "." + match[2],
+			kind:   kind,
+			ranges: []codeLineRange{{start: attachCodeLeadingLines(lines, index, floor), end: end}},
+		})
+		index = end
+		floor = end + 1
+	}
+	return members
+}
+
+// findCodeIndentBlockEnd 返回缩进块最后一个非空行；下一条缩进不大于 base 的有效行即块外。
+func findCodeIndentBlockEnd(lines []string, significant []bool, start, base int) int {
+	end := start
+	for index := start + 1; index < len(lines); index++ {
+		if strings.TrimSpace(lines[index]) == "" {
+			continue
+		}
+		if significant[index] && codeIndentWidth(lines[index]) <= base {
+			break
+		}
+		if !significant[index] && !isCodeContinuationLine(lines[index], base) {
+			continue
+		}
+		end = index
+	}
+	return end
+}
+
+// isCodeContinuationLine 判断非有效行是否仍属于当前块：多行字符串内部一律属于，注释行需缩进大于 base。
+func isCodeContinuationLine(line string, base int) bool {
+	return !strings.HasPrefix(strings.TrimSpace(line), "#") || codeIndentWidth(line) > base
+}
+
+// markCodeIndentSignificantLines 标记参与缩进判断的行：跳过空行、注释行和多行字符串内部。
+func markCodeIndentSignificantLines(lines []string) []bool {
+	significant := make([]bool, len(lines))
+	inTriple := ""
+	for index, line := range lines {
+		startsInside := inTriple != ""
+		for _, delimiter := range codeTripleQuotePattern.FindAllString(line, -1) {
+			switch inTriple {
+			case "":
+				inTriple = delimiter
+			case delimiter:
+				inTriple = ""
+			}
+		}
+		trimmed := strings.TrimSpace(line)
+		significant[index] = !startsInside && trimmed != "" && !strings.HasPrefix(trimmed, "#")
+	}
+	return significant
+}
+
+func codeIndentWidth(line string) int {
+	width := 0
+	for _, char := range line {
+		switch char {
+		case ' ':
+			width++
+		case '\t':
+			width += codeIndentTabWidth
+		default:
+			return width
+		}
+	}
+	return width
+}
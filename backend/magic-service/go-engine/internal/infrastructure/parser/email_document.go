@@ -0,0 +1,246 @@
+package docparser
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+)
+
+var emailReplySubjectPattern = regexp.MustCompile(`(?i)^\s*((re|fw|fwd|aw|sv|回复|答复|转发)\s*(\[\d+\])?\s*[:：]\s*)+`)
+
+// emailThread 是按 References / In-Reply-To 归并后的邮件会话，消息按时间升序。
+type emailThread struct {
+	id       string
+	messages []emailRenderedMessage
+}
+
+func buildEmailParsedDocument(
+	fileType string,
+	rendered []emailRenderedMessage,
+	grouping string,
+) *documentdomain.ParsedDocument {
+	threads := groupEmailThreads(rendered)
+	blocks := make([]documentdomain.ParsedBlock, 0, len(rendered))
+	for _, thread := range threads {
+		if grouping == documentdomain.EmailGroupingThread {
+			blocks = append(blocks, buildEmailThreadBlock(thread))
+			continue
+		}
+		for _, item := range thread.messages {
+			blocks = append(blocks, buildEmailMessageBlock(thread.id, item))
+		}
+	}
+
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		if text := strings.TrimSpace(renderEmailBlockHeader(block.Metadata) + block.Content); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return &documentdomain.ParsedDocument{
+		SourceType: documentdomain.ParsedDocumentSourceEmail,
+		PlainText:  strings.Join(parts, "\n\n"),
+		Blocks:     blocks,
+		DocumentMeta: map[string]any{
+			documentdomain.ParsedMetaSourceFormat: strings.ToLower(strings.TrimSpace(fileType)),
+			"email_message_count":                 len(rendered),
+			"email_thread_count":                  len(threads),
+			"email_grouping":                      grouping,
+		},
+	}
+}
+
+func buildEmailMessageBlock(threadID string, item emailRenderedMessage) documentdomain.ParsedBlock {
+	message := item.message
+	metadata := map[string]any{
+		documentdomain.ParsedMetaChunkType:         documentdomain.ParsedBlockTypeEmailMessage,
+		documentdomain.ParsedMetaEmailSubject:      message.subject,
+		documentdomain.ParsedMetaEmailFrom:         message.from.String(),
+		documentdomain.ParsedMetaEmailTo:           formatEmailAddresses(message.to),
+		documentdomain.ParsedMetaEmailCc:           formatEmailAddresses(message.cc),
+		documentdomain.ParsedMetaEmailMessageID:    message.messageID,
+		documentdomain.ParsedMetaEmailThreadID:     threadID,
+		documentdomain.ParsedMetaEmailMessageCount: 1,
+		documentdomain.ParsedMetaEmailAttachments:  slices.Clone(item.attachmentNames),
+		documentdomain.ParsedMetaTags:              buildEmailTags([]*emailMessage{message}),
+	}
+	setEmailDateMetadata(metadata, message.date)
+	return documentdomain.ParsedBlock{
+		Type:     documentdomain.ParsedBlockTypeEmailMessage,
+		Content:  item.content,
+		Metadata: metadata,
+	}
+}
+
+// buildEmailThreadBlock 合并会话内全部邮件；主题与时间取首封邮件，收件人与附件取并集。
+func buildEmailThreadBlock(thread emailThread) documentdomain.ParsedBlock {
+	first := thread.messages[0].message
+	messages := make([]*emailMessage, 0, len(thread.messages))
+	sections := make([]string, 0, len(thread.messages))
+	var to, cc []string
+	var attachments []string
+	for _, item := range thread.messages {
+		messages = append(messages, item.message)
+		to = appendUniqueStrings(to, formatEmailAddresses(item.message.to)...)
+		cc = appendUniqueStrings(cc, formatEmailAddresses(item.message.cc)...)
+		attachments = append(attachments, item.attachmentNames...)
+		sections = append(sections, renderEmailThreadSection(item))
+	}
+	metadata := map[string]any{
+		documentdomain.ParsedMetaChunkType:         documentdomain.ParsedBlockTypeEmailThread,
+		documentdomain.ParsedMetaEmailSubject:      first.subject,
+		documentdomain.ParsedMetaEmailFrom:         first.from.String(),
+		documentdomain.ParsedMetaEmailTo:           to,
+		documentdomain.ParsedMetaEmailCc:           cc,
+		documentdomain.ParsedMetaEmailMessageID:    first.messageID,
+		documentdomain.ParsedMetaEmailThreadID:     thread.id,
+		documentdomain.ParsedMetaEmailMessageCount: len(thread.messages),
+		documentdomain.ParsedMetaEmailAttachments:  attachments,
+		documentdomain.ParsedMetaTags:              buildEmailTags(messages),
+	}
+	setEmailDateMetadata(metadata, first.date)
+	return documentdomain.ParsedBlock{
+		Type:     documentdomain.ParsedBlockTypeEmailThread,
+		Content:  strings.Join(filterNonEmptyStrings(sections), "\n\n"),
+		Metadata: metadata,
+	}
+}
+
+func renderEmailThreadSection(item emailRenderedMessage) string {
+	lines := make([]string, 0, 3)
+	if from := item.message.from.String(); from != "" {
+		lines = append(lines, "From: "+from)
+	}
+	if !item.message.date.IsZero() {
+		lines = append(lines, "Date: "+item.message.date.UTC().Format(time.RFC3339))
+	}
+	lines = append(lines, "")
+	return strings.TrimSpace(strings.Join(lines, "\n") + "\n" + item.content)
+}
+
+func setEmailDateMetadata(metadata map[string]any, date time.Time) {
+	if date.IsZero() {
+		return
+	}
+	metadata[documentdomain.ParsedMetaEmailDate] = date.UTC().Format(time.RFC3339)
+	metadata[documentdomain.ParsedMetaCreatedAtTS] = date.Unix()
+}
+
+// buildEmailTags 生成可供 SimilarityFilters.Tags 精确匹配的标签，地址统一小写。
+func buildEmailTags(messages []*emailMessage) []string {
+	tags := []string{emailTagDefault}
+	for _, message := range messages {
+		if address := normalizeEmailTagAddress(message.from); address != "" {
+			tags = appendUniqueStrings(tags, emailTagFromPrefix+address)
+		}
+		for _, recipient := range message.to {
+			if address := normalizeEmailTagAddress(recipient); address != "" {
+				tags = appendUniqueStrings(tags, emailTagToPrefix+address)
+			}
+		}
+		for _, recipient := range message.cc {
+			if address := normalizeEmailTagAddress(recipient); address != "" {
+				tags = appendUniqueStrings(tags, emailTagCcPrefix+address)
+			}
+		}
+	}
+	return tags
+}
+
+func normalizeEmailTagAddress(address emailAddress) string {
+	return strings.ToLower(strings.TrimSpace(address.address))
+}
+
+func renderEmailBlockHeader(metadata map[string]any) string {
+	lines := make([]string, 0, 4)
+	if subject, _ := metadata[documentdomain.ParsedMetaEmailSubject].(string); subject != "" {
+		lines = append(lines, "Subject: "+subject)
+	}
+	if from, _ := metadata[documentdomain.ParsedMetaEmailFrom].(string); from != "" {
+		lines = append(lines, "From: "+from)
+	}
+	if to, _ := metadata[documentdomain.ParsedMetaEmailTo].([]string); len(to) > 0 {
+		lines = append(lines, "To: "+strings.Join(to, ", "))
+	}
+	if date, _ := metadata[documentdomain.ParsedMetaEmailDate].(string); date != "" {
+		lines = append(lines, "Date: "+date)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n\n"
+}
+
+// groupEmailThreads 按时间排序后归并会话：优先沿 References / In-Reply-To 找到已知父邮件，
+// 缺少引用头的回复邮件再按去掉 Re:/Fwd: 前缀后的主题归并。
+func groupEmailThreads(rendered []emailRenderedMessage) []emailThread {
+	ordered := slices.Clone(rendered)
+	slices.SortStableFunc(ordered, func(left, right emailRenderedMessage) int {
+		return left.message.date.Compare(right.message.date)
+	})
+
+	keyByID := make(map[string]string, len(ordered))
+	keyBySubject := make(map[string]string, len(ordered))
+	indexByKey := make(map[string]int, len(ordered))
+	threads := make([]emailThread, 0, len(ordered))
+	for index, item := range ordered {
+		key := resolveEmailThreadKey(item.message, keyByID, keyBySubject)
+		if key == "" {
+			key = "message-" + strconv.Itoa(index+1)
+		}
+		if item.message.messageID != "" {
+			keyByID[item.message.messageID] = key
+		}
+		if subject := normalizeEmailThreadSubject(item.message.subject); subject != "" {
+			if _, exists := keyBySubject[subject]; !exists {
+				keyBySubject[subject] = key
+			}
+		}
+		if position, exists := indexByKey[key]; exists {
+			threads[position].messages = append(threads[position].messages, item)
+			continue
+		}
+		indexByKey[key] = len(threads)
+		threads = append(threads, emailThread{id: key, messages: []emailRenderedMessage{item}})
+	}
+	return threads
+}
+
+func resolveEmailThreadKey(message *emailMessage, keyByID, keyBySubject map[string]string) string {
+	parents := slices.Clone(message.references)
+	if message.inReplyTo != "" {
+		parents = append(parents, message.inReplyTo)
+	}
+	for _, parent := range slices.Backward(parents) {
+		if key, ok := keyByID[parent]; ok {
+			return key
+		}
+	}
+	if len(parents) > 0 {
+		return parents[0]
+	}
+	if emailReplySubjectPattern.MatchString(message.subject) {
+		if key, ok := keyBySubject[normalizeEmailThreadSubject(message.subject)]; ok {
+			return key
+		}
+	}
+	return message.messageID
+}
+
+func normalizeEmailThreadSubject(subject string) string {
+	subject = emailReplySubjectPattern.ReplaceAllString(subject, "")
+	return strings.ToLower(strings.Join(strings.Fields(subject), " "))
+}
+
+func appendUniqueStrings(values []string, items ...string) []string {
+	for _, item := range items {
+		if !slices.Contains(values, item) {
+			values = append(values, item)
+		}
+	}
+	return values
+}
@@ -0,0 +1,49 @@
+package docparser
+
+import (
+	"bytes"
+	"regexp"
+)
+
+var mboxEscapedFromPattern = regexp.MustCompile(`^>+From `)
+
+// splitMboxMessages 按 mboxrd 约定拆分邮件：分隔行是文件开头或空行之后的 "From "，
+// 正文里被转义的 ">From " 去掉一层 ">"。
+func splitMboxMessages(source []byte) [][]byte {
+	lines := bytes.SplitAfter(source, []byte("\n"))
+	messages := make([][]byte, 0)
+	var current []byte
+	inMessage := false
+	previousBlank := true
+	for _, line := range lines {
+		trimmed := bytes.TrimRight(line, "\r\n")
+		if previousBlank && bytes.HasPrefix(line, []byte("From ")) {
+			if inMessage {
+				messages = appendMboxMessage(messages, current)
+			}
+			current = nil
+			inMessage = true
+			previousBlank = false
+			continue
+		}
+		previousBlank = len(trimmed) == 0
+		if !inMessage {
+			continue
+		}
+		if mboxEscapedFromPattern.Match(line) {
+			line = line[1:]
+		}
+		current = append(current, line...)
+	}
+	if inMessage {
+		messages = appendMboxMessage(messages, current)
+	}
+	return messages
+}
+
+func appendMboxMessage(messages [][]byte, message []byte) [][]byte {
+	if len(bytes.TrimSpace(message)) == 0 {
+		return messages
+	}
+	return append(messages, message)
+}
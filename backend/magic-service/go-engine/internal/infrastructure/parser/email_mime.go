@@ -0,0 +1,315 @@
+package docparser
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/text/encoding/htmlindex"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+)
+
+const (
+	// emailMaxMIMEDepth 限制 multipart 与内嵌 message/rfc822 的总嵌套层数。
+	emailMaxMIMEDepth = 8
+
+	emailMediaTypePlain   = "text/plain"
+	emailMediaTypeHTML    = "text/html"
+	emailMediaTypeMessage = "message/rfc822"
+)
+
+var emailIDPattern = regexp.MustCompile(`<([^<>\s]+)>`)
+
+// emailHeader 同时适配 mail.Header 与 textproto.MIMEHeader。
+type emailHeader interface {
+	Get(key string) string
+}
+
+// emailPartContent 是 MIME 子树展开后的正文与附件。
+type emailPartContent struct {
+	body        string
+	isHTML      bool
+	attachments []emailAttachment
+}
+
+type emailMIMEReader struct {
+	limits      documentdomain.ResourceLimits
+	wordDecoder *mime.WordDecoder
+}
+
+func parseMIMEMessage(ctx context.Context, raw []byte, limits documentdomain.ResourceLimits, depth int) (*emailMessage, error) {
+	reader := emailMIMEReader{limits: limits, wordDecoder: newEmailWordDecoder()}
+	return reader.readMessage(ctx, raw, depth)
+}
+
+func newEmailWordDecoder() *mime.WordDecoder {
+	return &mime.WordDecoder{CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+		encoding, err := htmlindex.Get(charset)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported email charset %q: %w", charset, err)
+		}
+		return encoding.NewDecoder().Reader(input), nil
+	}}
+}
+
+func (r emailMIMEReader) readMessage(ctx context.Context, raw []byte, depth int) (*emailMessage, error) {
+	message, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("read email message: %w", err)
+	}
+	header := message.Header
+	result := &emailMessage{
+		subject:    r.decodeHeader(header.Get("Subject")),
+		to:         r.parseAddresses(header.Get("To")),
+		cc:         r.parseAddresses(header.Get("Cc")),
+		messageID:  firstEmailID(header.Get("Message-ID")),
+		inReplyTo:  firstEmailID(header.Get("In-Reply-To")),
+		references: parseEmailIDs(header.Get("References")),
+	}
+	if from := r.parseAddresses(header.Get("From")); len(from) > 0 {
+		result.from = from[0]
+	}
+	if date, err := mail.ParseDate(header.Get("Date")); err == nil {
+		result.date = date
+	}
+
+	content, err := r.walkPart(ctx, header, message.Body, depth)
+	if err != nil {
+		return nil, err
+	}
+	result.body = content.body
+	result.attachments = content.attachments
+	return result, nil
+}
+
+func (r emailMIMEReader) walkPart(ctx context.Context, header emailHeader, body io.Reader, depth int) (emailPartContent, error) {
+	mediaType, params := parseEmailMediaType(header.Get("Content-Type"))
+	disposition, dispositionParams := parseEmailMediaType(header.Get("Content-Disposition"))
+	filename := r.decodeHeader(cmp.Or(dispositionParams["filename"], params["name"]))
+	decoded := decodeEmailTransferEncoding(body, header.Get("Content-Transfer-Encoding"))
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		if depth >= emailMaxMIMEDepth {
+			return emailPartContent{}, nil
+		}
+		return r.walkMultipart(ctx, mediaType, params["boundary"], decoded, depth+1)
+	case mediaType == emailMediaTypeMessage:
+		return r.walkEmbeddedMessage(ctx, decoded, depth+1)
+	case disposition == "attachment" || filename != "" || !strings.HasPrefix(mediaType, "text/"):
+		data, err := readAllWithLimit(decoded, 0, r.limits, parserSizeLimitArchiveEntry)
+		if err != nil {
+			return emailPartContent{}, fmt.Errorf("read email attachment %s: %w", filename, err)
+		}
+		return emailPartContent{attachments: []emailAttachment{{name: filename, contentType: mediaType, data: data}}}, nil
+	}
+
+	data, err := readAllWithLimit(decoded, 0, r.limits, parserSizeLimitArchiveEntry)
+	if err != nil {
+		return emailPartContent{}, fmt.Errorf("read email body: %w", err)
+	}
+	text := decodeEmailCharset(data, params["charset"])
+	if mediaType == emailMediaTypeHTML {
+		return emailPartContent{body: renderEmailHTML(ctx, text), isHTML: true}, nil
+	}
+	return emailPartContent{body: normalizeEmailPlainText(text)}, nil
+}
+
+// walkMultipart 展开 multipart；alternative 优先取纯文本版本，其余子类型按顺序拼接正文。
+func (r emailMIMEReader) walkMultipart(ctx context.Context, mediaType, boundary string, body io.Reader, depth int) (emailPartContent, error) {
+	if boundary == "" {
+		return emailPartContent{}, nil
+	}
+	reader := multipart.NewReader(body, boundary)
+	children := make([]emailPartContent, 0)
+	for {
+		part, err := reader.NextRawPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return emailPartContent{}, fmt.Errorf("read email multipart: %w", err)
+		}
+		child, err := r.walkPart(ctx, part.Header, part, depth)
+		if err != nil {
+			return emailPartContent{}, err
+		}
+		children = append(children, child)
+	}
+	return mergeEmailParts(mediaType == "multipart/alternative", children), nil
+}
+
+func mergeEmailParts(alternative bool, children []emailPartContent) emailPartContent {
+	var merged emailPartContent
+	bodies := make([]string, 0, len(children))
+	for _, child := range children {
+		merged.attachments = append(merged.attachments, child.attachments...)
+		if strings.TrimSpace(child.body) != "" {
+			bodies = append(bodies, child.body)
+		}
+	}
+	if !alternative {
+		merged.body = strings.Join(bodies, "\n\n")
+		return merged
+	}
+	for _, child := range children {
+		if strings.TrimSpace(child.body) != "" && !child.isHTML {
+			merged.body = child.body
+			return merged
+		}
+	}
+	if len(bodies) > 0 {
+		merged.body = bodies[0]
+		merged.isHTML = true
+	}
+	return merged
+}
+
+// walkEmbeddedMessage 把转发的 message/rfc822 展开为正文段落，其附件提升到外层邮件。
+func (r emailMIMEReader) walkEmbeddedMessage(ctx context.Context, body io.Reader, depth int) (emailPartContent, error) {
+	data, err := readAllWithLimit(body, 0, r.limits, parserSizeLimitArchiveEntry)
+	if err != nil {
+		return emailPartContent{}, fmt.Errorf("read embedded email: %w", err)
+	}
+	if depth >= emailMaxMIMEDepth {
+		return emailPartContent{}, nil
+	}
+	nested, err := r.readMessage(ctx, data, depth)
+	if err != nil {
+		return emailPartContent{}, err
+	}
+	lines := []string{"Forwarded message:"}
+	if nested.subject != "" {
+		lines = append(lines, "Subject: "+nested.subject)
+	}
+	if from := nested.from.String(); from != "" {
+		lines = append(lines, "From: "+from)
+	}
+	return emailPartContent{
+		body:        strings.TrimSpace(strings.Join(lines, "\n") + "\n\n" + nested.body),
+		attachments: nested.attachments,
+	}, nil
+}
+
+func (r emailMIMEReader) decodeHeader(value string) string {
+	decoded, err := r.wordDecoder.DecodeHeader(value)
+	if err != nil {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(decoded)
+}
+
+func (r emailMIMEReader) parseAddresses(value string) []emailAddress {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	parser := mail.AddressParser{WordDecoder: r.wordDecoder}
+	if list, err := parser.ParseList(value); err == nil {
+		addresses := make([]emailAddress, 0, len(list))
+		for _, address := range list {
+			addresses = append(addresses, emailAddress{name: strings.TrimSpace(address.Name), address: address.Address})
+		}
+		return addresses
+	}
+	// 导出工具常写出不规范地址，逐个回退解析，保留无法识别的原文作为名称。
+	addresses := make([]emailAddress, 0)
+	for piece := range strings.FieldsFuncSeq(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		piece = strings.TrimSpace(piece)
+		if address, err := parser.Parse(piece); err == nil {
+			addresses = append(addresses, emailAddress{name: strings.TrimSpace(address.Name), address: address.Address})
+			continue
+		}
+		if strings.Contains(piece, "@") && !strings.ContainsAny(piece, " <>") {
+			addresses = append(addresses, emailAddress{address: piece})
+			continue
+		}
+		addresses = append(addresses, emailAddress{name: r.decodeHeader(piece)})
+	}
+	return addresses
+}
+
+func parseEmailMediaType(value string) (string, map[string]string) {
+	if strings.TrimSpace(value) == "" {
+		return emailMediaTypePlain, map[string]string{}
+	}
+	mediaType, params, err := mime.ParseMediaType(value)
+	if err != nil {
+		// 参数不规范时仍保留主类型，避免把 text/html 误判成纯文本。
+		mediaType = strings.ToLower(strings.TrimSpace(strings.Split(value, ";")[0]))
+		params = map[string]string{}
+	}
+	return mediaType, params
+}
+
+func decodeEmailTransferEncoding(body io.Reader, encoding string) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+func decodeEmailCharset(data []byte, charset string) string {
+	charset = strings.ToLower(strings.TrimSpace(charset))
+	if charset == "" || charset == "utf-8" || charset == "us-ascii" {
+		return strings.ToValidUTF8(string(data), "")
+	}
+	encoding, err := htmlindex.Get(charset)
+	if err != nil {
+		return strings.ToValidUTF8(string(data), "")
+	}
+	decoded, err := encoding.NewDecoder().Bytes(data)
+	if err != nil || !utf8.Valid(decoded) {
+		return strings.ToValidUTF8(string(data), "")
+	}
+	return string(decoded)
+}
+
+func renderEmailHTML(ctx context.Context, content string) string {
+	root, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return strings.TrimSpace(content)
+	}
+	blocks := newHTMLTextRenderer(richTextAssetLoader{}).renderBlocks(ctx, "", root, nil)
+	return strings.Join(filterNonEmptyStrings(blocks), "\n\n")
+}
+
+func normalizeEmailPlainText(content string) string {
+	return strings.TrimSpace(strings.ReplaceAll(content, "\r\n", "\n"))
+}
+
+func firstEmailID(value string) string {
+	ids := parseEmailIDs(value)
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[0]
+}
+
+func parseEmailIDs(value string) []string {
+	matches := emailIDPattern.FindAllStringSubmatch(value, -1)
+	if len(matches) == 0 {
+		return strings.Fields(value)
+	}
+	ids := make([]string, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match[1])
+	}
+	return ids
+}
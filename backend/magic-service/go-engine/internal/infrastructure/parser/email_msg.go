@@ -0,0 +1,288 @@
+package docparser
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net/mail"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/richardlehane/mscfb"
+	"golang.org/x/text/encoding/charmap"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+)
+
+// Outlook MSG（MS-OXMSG）属性 ID 与类型。
+const (
+	outlookPropSubject          = 0x0037
+	outlookPropClientSubmitTime = 0x0039
+	outlookPropTransportHeaders = 0x007D
+	outlookPropSenderName       = 0x0C1A
+	outlookPropSenderEmail      = 0x0C1F
+	outlookPropRecipientType    = 0x0C15
+	outlookPropDeliveryTime     = 0x0E06
+	outlookPropBody             = 0x1000
+	outlookPropHTML             = 0x1013
+	outlookPropMessageID        = 0x1035
+	outlookPropReferences       = 0x1039
+	outlookPropInReplyTo        = 0x1042
+	outlookPropDisplayName      = 0x3001
+	outlookPropEmailAddress     = 0x3003
+	outlookPropAttachData       = 0x3701
+	outlookPropAttachFilename   = 0x3704
+	outlookPropAttachLongName   = 0x3707
+	outlookPropAttachMIME       = 0x370E
+	outlookPropSMTPAddress      = 0x39FE
+	outlookPropSenderSMTP       = 0x5D01
+
+	outlookTypeLong    = 0x0003
+	outlookTypeSysTime = 0x0040
+	outlookTypeString8 = 0x001E
+	outlookTypeUnicode = 0x001F
+	outlookTypeBinary  = 0x0102
+
+	outlookRecipientTo = 1
+	outlookRecipientCc = 2
+
+	outlookPropertiesStream       = "__properties_version1.0"
+	outlookRecipientStoragePrefix = "__recip_version1.0_#"
+	outlookAttachStoragePrefix    = "__attach_version1.0_#"
+	// 顶层属性流头部 32 字节，收件人/附件子存储头部 8 字节，每个属性项 16 字节。
+	outlookTopPropertiesHeader = 32
+	outlookSubPropertiesHeader = 8
+	outlookPropertyEntrySize   = 16
+	outlookPropertyValueOffset = 8
+
+	// filetimeUnixEpochOffset 是 1601-01-01 到 1970-01-01 的 100ns 间隔数。
+	filetimeUnixEpochOffset = 116444736000000000
+	filetimeTicksPerSecond  = 10000000
+	filetimeNanosPerTick    = 100
+)
+
+// outlookStorage 是 MSG 中一个存储（根、收件人或附件）下的流集合。
+type outlookStorage struct {
+	streams    map[string][]byte
+	prefix     string
+	headerSize int
+}
+
+// readOutlookMessage 读取 MSG 复合文档；只展开根与一级子存储，内嵌 MSG 附件不再递归。
+func readOutlookMessage(ctx context.Context, source []byte, limits documentdomain.ResourceLimits) (*emailMessage, error) {
+	reader, err := mscfb.New(bytes.NewReader(source))
+	if err != nil {
+		return nil, fmt.Errorf("open outlook msg: %w", err)
+	}
+	budget := newArchiveEntryBudget(limits)
+	streams := make(map[string][]byte, len(reader.File))
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || len(file.Path) > 1 {
+			continue
+		}
+		name := path.Join(append(slices.Clone(file.Path), file.Name)...)
+		if err := budget.consume(name, file.Size); err != nil {
+			return nil, fmt.Errorf("check outlook msg stream limits: %w", err)
+		}
+		data, err := readAllWithLimit(file, 0, limits, parserSizeLimitArchiveEntry)
+		if err != nil {
+			return nil, fmt.Errorf("read outlook msg stream %s: %w", name, err)
+		}
+		streams[name] = data
+	}
+	return buildOutlookMessage(ctx, streams), nil
+}
+
+func buildOutlookMessage(ctx context.Context, streams map[string][]byte) *emailMessage {
+	root := outlookStorage{streams: streams, headerSize: outlookTopPropertiesHeader}
+	headers := parseOutlookTransportHeaders(root.stringProp(outlookPropTransportHeaders))
+	message := &emailMessage{
+		subject: root.stringProp(outlookPropSubject),
+		from: emailAddress{
+			name:    root.stringProp(outlookPropSenderName),
+			address: firstOutlookSMTPAddress(root.stringProp(outlookPropSenderSMTP), root.stringProp(outlookPropSenderEmail)),
+		},
+		messageID:  firstEmailID(firstNonEmptyString(root.stringProp(outlookPropMessageID), headers.Get("Message-ID"))),
+		inReplyTo:  firstEmailID(firstNonEmptyString(root.stringProp(outlookPropInReplyTo), headers.Get("In-Reply-To"))),
+		references: parseEmailIDs(firstNonEmptyString(root.stringProp(outlookPropReferences), headers.Get("References"))),
+		body:       normalizeEmailPlainText(root.stringProp(outlookPropBody)),
+	}
+	if message.body == "" {
+		html := root.stringProp(outlookPropHTML)
+		if html == "" {
+			html = decodeEmailCharset(root.binaryProp(outlookPropHTML), "")
+		}
+		message.body = renderEmailHTML(ctx, html)
+	}
+	message.date = root.timeProp(outlookPropClientSubmitTime)
+	if message.date.IsZero() {
+		message.date = root.timeProp(outlookPropDeliveryTime)
+	}
+	if message.date.IsZero() {
+		if date, err := mail.ParseDate(headers.Get("Date")); err == nil {
+			message.date = date
+		}
+	}
+	for _, storage := range root.children(outlookRecipientStoragePrefix) {
+		recipient := emailAddress{
+			name:    storage.stringProp(outlookPropDisplayName),
+			address: firstOutlookSMTPAddress(storage.stringProp(outlookPropSMTPAddress), storage.stringProp(outlookPropEmailAddress)),
+		}
+		switch storage.longProp(outlookPropRecipientType) {
+		case outlookRecipientTo:
+			message.to = append(message.to, recipient)
+		case outlookRecipientCc:
+			message.cc = append(message.cc, recipient)
+		}
+	}
+	for _, storage := range root.children(outlookAttachStoragePrefix) {
+		message.attachments = append(message.attachments, emailAttachment{
+			name: firstNonEmptyString(
+				storage.stringProp(outlookPropAttachLongName),
+				storage.stringProp(outlookPropAttachFilename),
+				storage.stringProp(outlookPropDisplayName),
+			),
+			contentType: strings.ToLower(storage.stringProp(outlookPropAttachMIME)),
+			data:        storage.binaryProp(outlookPropAttachData),
+		})
+	}
+	return message
+}
+
+func (s outlookStorage) stream(name string) ([]byte, bool) {
+	data, ok := s.streams[s.prefix+name]
+	return data, ok
+}
+
+func (s outlookStorage) stringProp(id uint16) string {
+	if data, ok := s.stream(outlookSubstreamName(id, outlookTypeUnicode)); ok {
+		return strings.TrimSpace(decodeUTF16LE(data))
+	}
+	if data, ok := s.stream(outlookSubstreamName(id, outlookTypeString8)); ok {
+		data = bytes.TrimRight(data, "\x00")
+		if utf8.Valid(data) {
+			return strings.TrimSpace(string(data))
+		}
+		decoded, err := charmap.Windows1252.NewDecoder().Bytes(data)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(decoded))
+	}
+	return ""
+}
+
+func (s outlookStorage) binaryProp(id uint16) []byte {
+	data, _ := s.stream(outlookSubstreamName(id, outlookTypeBinary))
+	return data
+}
+
+func (s outlookStorage) longProp(id uint16) uint32 {
+	value, ok := s.fixedProp(id, outlookTypeLong)
+	if !ok {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(value)
+}
+
+func (s outlookStorage) timeProp(id uint16) time.Time {
+	value, ok := s.fixedProp(id, outlookTypeSysTime)
+	if !ok {
+		return time.Time{}
+	}
+	return filetimeToTime(binary.LittleEndian.Uint64(value))
+}
+
+// fixedProp 在属性流中查找定长属性，返回 8 字节值区。
+func (s outlookStorage) fixedProp(id, propType uint16) ([]byte, bool) {
+	data, ok := s.stream(outlookPropertiesStream)
+	if !ok || len(data) < s.headerSize {
+		return nil, false
+	}
+	tag := uint32(id)<<16 | uint32(propType)
+	for offset := s.headerSize; offset+outlookPropertyEntrySize <= len(data); offset += outlookPropertyEntrySize {
+		entry := data[offset : offset+outlookPropertyEntrySize]
+		if binary.LittleEndian.Uint32(entry) == tag {
+			return entry[outlookPropertyValueOffset:], true
+		}
+	}
+	return nil, false
+}
+
+// children 返回根存储下指定前缀的子存储，按存储名排序以保持收件人/附件原始顺序。
+func (s outlookStorage) children(prefix string) []outlookStorage {
+	names := make([]string, 0)
+	for name := range s.streams {
+		storage, _, found := strings.Cut(name, "/")
+		if found && strings.HasPrefix(storage, prefix) && !slices.Contains(names, storage) {
+			names = append(names, storage)
+		}
+	}
+	slices.Sort(names)
+	storages := make([]outlookStorage, 0, len(names))
+	for _, name := range names {
+		storages = append(storages, outlookStorage{
+			streams:    s.streams,
+			prefix:     name + "/",
+			headerSize: outlookSubPropertiesHeader,
+		})
+	}
+	return storages
+}
+
+func outlookSubstreamName(id, propType uint16) string {
+	return fmt.Sprintf("__substg1.0_%04X%04X", id, propType)
+}
+
+func decodeUTF16LE(data []byte) string {
+	units := make([]uint16, 0, len(data)/2)
+	for index := 0; index+1 < len(data); index += 2 {
+		units = append(units, binary.LittleEndian.Uint16(data[index:]))
+	}
+	return strings.TrimRight(string(utf16.Decode(units)), "\x00")
+}
+
+func filetimeToTime(value uint64) time.Time {
+	if value <= filetimeUnixEpochOffset {
+		return time.Time{}
+	}
+	ticks := value - filetimeUnixEpochOffset
+	seconds := clampUint64ToInt64(ticks / filetimeTicksPerSecond)
+	nanos := clampUint64ToInt64(ticks % filetimeTicksPerSecond * filetimeNanosPerTick)
+	return time.Unix(seconds, nanos).UTC()
+}
+
+func parseOutlookTransportHeaders(raw string) mail.Header {
+	if strings.TrimSpace(raw) == "" {
+		return mail.Header{}
+	}
+	message, err := mail.ReadMessage(strings.NewReader(strings.TrimRight(raw, "\r\n") + "\r\n\r\n"))
+	if err != nil {
+		return mail.Header{}
+	}
+	return message.Header
+}
+
+// firstOutlookSMTPAddress 跳过 Exchange 内部 DN（/O=...），只保留 SMTP 地址。
+func firstOutlookSMTPAddress(candidates ...string) string {
+	for _, candidate := range candidates {
+		if strings.Contains(candidate, "@") {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func firstNonEmptyString(values ...string) string {
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
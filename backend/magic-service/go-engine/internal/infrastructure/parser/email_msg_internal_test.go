@@ -0,0 +1,87 @@
+package docparser
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+func TestBuildOutlookMessageReadsPropertiesRecipientsAndAttachments(t *testing.T) {
+	t.Parallel()
+
+	submitted := time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC)
+	streams := map[string][]byte{
+		outlookSubstreamName(outlookPropSubject, outlookTypeUnicode):     encodeTestUTF16("周会纪要"),
+		outlookSubstreamName(outlookPropSenderName, outlookTypeUnicode):  encodeTestUTF16("Alice"),
+		outlookSubstreamName(outlookPropSenderEmail, outlookTypeString8): []byte("/O=EXCHANGE/CN=ALICE"),
+		outlookSubstreamName(outlookPropSenderSMTP, outlookTypeUnicode):  encodeTestUTF16("alice@example.com"),
+		outlookSubstreamName(outlookPropBody, outlookTypeUnicode):        encodeTestUTF16("会议结论\r\n下周上线"),
+		outlookSubstreamName(outlookPropTransportHeaders, outlookTypeUnicode): encodeTestUTF16(
+			"Message-ID: <weekly@example.com>\r\nIn-Reply-To: <agenda@example.com>\r\n",
+		),
+		outlookPropertiesStream: buildTestOutlookProperties(outlookTopPropertiesHeader, map[uint32]uint64{
+			uint32(outlookPropClientSubmitTime)<<16 | outlookTypeSysTime: timeToTestFiletime(submitted),
+		}),
+
+		"__recip_version1.0_#00000000/" + outlookSubstreamName(outlookPropDisplayName, outlookTypeUnicode): encodeTestUTF16("Bob"),
+		"__recip_version1.0_#00000000/" + outlookSubstreamName(outlookPropSMTPAddress, outlookTypeUnicode): encodeTestUTF16("bob@example.com"),
+		"__recip_version1.0_#00000000/" + outlookPropertiesStream: buildTestOutlookProperties(outlookSubPropertiesHeader, map[uint32]uint64{
+			uint32(outlookPropRecipientType)<<16 | outlookTypeLong: outlookRecipientTo,
+		}),
+		"__recip_version1.0_#00000001/" + outlookSubstreamName(outlookPropEmailAddress, outlookTypeUnicode): encodeTestUTF16("carol@example.com"),
+		"__recip_version1.0_#00000001/" + outlookPropertiesStream: buildTestOutlookProperties(outlookSubPropertiesHeader, map[uint32]uint64{
+			uint32(outlookPropRecipientType)<<16 | outlookTypeLong: outlookRecipientCc,
+		}),
+
+		"__attach_version1.0_#00000000/" + outlookSubstreamName(outlookPropAttachLongName, outlookTypeUnicode): encodeTestUTF16("notes.txt"),
+		"__attach_version1.0_#00000000/" + outlookSubstreamName(outlookPropAttachData, outlookTypeBinary):      []byte("附件内容"),
+	}
+
+	message := buildOutlookMessage(context.Background(), streams)
+	if message.subject != "周会纪要" || message.body != "会议结论\n下周上线" {
+		t.Fatalf("unexpected subject/body: %q %q", message.subject, message.body)
+	}
+	if message.from != (emailAddress{name: "Alice", address: "alice@example.com"}) {
+		t.Fatalf("unexpected sender: %#v", message.from)
+	}
+	if !message.date.Equal(submitted) {
+		t.Fatalf("unexpected date: %v", message.date)
+	}
+	if message.messageID != "weekly@example.com" || message.inReplyTo != "agenda@example.com" {
+		t.Fatalf("unexpected ids: %q %q", message.messageID, message.inReplyTo)
+	}
+	if len(message.to) != 1 || message.to[0].address != "bob@example.com" ||
+		len(message.cc) != 1 || message.cc[0].address != "carol@example.com" {
+		t.Fatalf("unexpected recipients: to=%#v cc=%#v", message.to, message.cc)
+	}
+	if len(message.attachments) != 1 || message.attachments[0].name != "notes.txt" ||
+		string(message.attachments[0].data) != "附件内容" {
+		t.Fatalf("unexpected attachments: %#v", message.attachments)
+	}
+}
+
+func encodeTestUTF16(value string) []byte {
+	units := utf16.Encode([]rune(value))
+	data := make([]byte, 0, len(units)*2)
+	for _, unit := range units {
+		data = binary.LittleEndian.AppendUint16(data, unit)
+	}
+	return data
+}
+
+func buildTestOutlookProperties(headerSize int, values map[uint32]uint64) []byte {
+	data := make([]byte, headerSize)
+	for tag, value := range values {
+		entry := make([]byte, outlookPropertyEntrySize)
+		binary.LittleEndian.PutUint32(entry, tag)
+		binary.LittleEndian.PutUint64(entry[outlookPropertyValueOffset:], value)
+		data = append(data, entry...)
+	}
+	return data
+}
+
+func timeToTestFiletime(value time.Time) uint64 {
+	return uint64(value.Unix())*filetimeTicksPerSecond + filetimeUnixEpochOffset
+}
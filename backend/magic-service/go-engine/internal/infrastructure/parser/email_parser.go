@@ -0,0 +1,297 @@
+package docparser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+	"magic/internal/pkg/filetype"
+)
+
+// EmailParser 解析 EML / MBOX / MSG 邮件，每封邮件或每个会话输出一个带邮件头 metadata 的块。
+type EmailParser struct {
+	attachmentParsers []documentdomain.Parser
+	limits            documentdomain.ResourceLimits
+}
+
+const (
+	emailFormatEML  = "eml"
+	emailFormatMBOX = "mbox"
+	emailFormatMSG  = "msg"
+
+	emailTagDefault    = "email"
+	emailTagFromPrefix = "from:"
+	emailTagToPrefix   = "to:"
+	emailTagCcPrefix   = "cc:"
+)
+
+var errEmailNoMessages = errors.New("email source has no messages")
+
+type emailAddress struct {
+	name    string
+	address string
+}
+
+type emailAttachment struct {
+	name        string
+	contentType string
+	data        []byte
+}
+
+type emailMessage struct {
+	subject     string
+	from        emailAddress
+	to          []emailAddress
+	cc          []emailAddress
+	date        time.Time
+	messageID   string
+	inReplyTo   string
+	references  []string
+	body        string
+	attachments []emailAttachment
+}
+
+// emailRenderedMessage 是附件已展开后的邮件正文。
+type emailRenderedMessage struct {
+	message         *emailMessage
+	content         string
+	attachmentNames []string
+}
+
+// emailAttachmentRenderer 通过解析器注册表递归解析附件，附件总量按压缩包限制累计。
+type emailAttachmentRenderer struct {
+	service *documentdomain.ParseService
+	budget  *archiveEntryBudget
+	options documentdomain.ParseOptions
+	failed  int
+}
+
+// NewEmailParser 创建邮件解析器，附件交给 attachmentParsers 中匹配的解析器处理。
+func NewEmailParser(
+	attachmentParsers []documentdomain.Parser,
+	resourceLimits ...documentdomain.ResourceLimits,
+) *EmailParser {
+	limits := documentdomain.DefaultResourceLimits()
+	if len(resourceLimits) > 0 {
+		limits = resourceLimits[0]
+	}
+	return &EmailParser{
+		attachmentParsers: slices.Clone(attachmentParsers),
+		limits:            documentdomain.NormalizeResourceLimits(limits),
+	}
+}
+
+// Parse 解析邮件并返回最佳努力纯文本。
+func (p *EmailParser) Parse(ctx context.Context, fileURL string, file io.Reader, fileType string) (string, error) {
+	parsed, err := p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, documentdomain.DefaultParseOptions())
+	if err != nil {
+		return "", err
+	}
+	return parsed.BestEffortText(), nil
+}
+
+// ParseWithOptions 按解析选项解析邮件。
+func (p *EmailParser) ParseWithOptions(
+	ctx context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+	options documentdomain.ParseOptions,
+) (string, error) {
+	parsed, err := p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, options)
+	if err != nil {
+		return "", err
+	}
+	return parsed.BestEffortText(), nil
+}
+
+// ParseDocument 解析邮件并返回结构化结果。
+func (p *EmailParser) ParseDocument(
+	ctx context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+) (*documentdomain.ParsedDocument, error) {
+	return p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, documentdomain.DefaultParseOptions())
+}
+
+// ParseDocumentWithOptions 按解析选项解析邮件并返回结构化结果。
+//
+// 块粒度由 options.EmailGrouping 决定；附件按文件扩展名或 Content-Type 走解析器注册表，
+// 命中资源限制时整体失败，其余附件解析失败只计数不阻断邮件正文入库。
+func (p *EmailParser) ParseDocumentWithOptions(
+	ctx context.Context,
+	_ string,
+	file io.Reader,
+	fileType string,
+	options documentdomain.ParseOptions,
+) (*documentdomain.ParsedDocument, error) {
+	source, err := io.ReadAll(documentdomain.NewSourceSizeLimitedReader(file, p.limits))
+	if err != nil {
+		return nil, fmt.Errorf("read email source failed: %w", err)
+	}
+	messages, err := p.readMessages(ctx, fileType, source)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, errEmailNoMessages
+	}
+
+	renderer := &emailAttachmentRenderer{
+		service: documentdomain.NewParseServiceWithLimits(nil, p.attachmentParsers, nil, p.limits),
+		budget:  newArchiveEntryBudget(p.limits),
+		options: options,
+	}
+	rendered := make([]emailRenderedMessage, 0, len(messages))
+	for _, message := range messages {
+		item, err := renderer.render(ctx, message)
+		if err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, item)
+	}
+
+	parsed := buildEmailParsedDocument(fileType, rendered, documentdomain.NormalizeEmailGrouping(options.EmailGrouping))
+	parsed.DocumentMeta["email_attachment_failed_count"] = renderer.failed
+	return parsed, nil
+}
+
+// Supports 检查是否支持该文件类型。
+func (p *EmailParser) Supports(fileType string) bool {
+	switch strings.ToLower(strings.TrimSpace(fileType)) {
+	case emailFormatEML, emailFormatMBOX, emailFormatMSG:
+		return true
+	default:
+		return false
+	}
+}
+
+// NeedsResolvedURL 邮件解析只依赖文件流。
+func (p *EmailParser) NeedsResolvedURL() bool {
+	return false
+}
+
+func (p *EmailParser) readMessages(ctx context.Context, fileType string, source []byte) ([]*emailMessage, error) {
+	switch strings.ToLower(strings.TrimSpace(fileType)) {
+	case emailFormatMSG:
+		message, err := readOutlookMessage(ctx, source, p.limits)
+		if err != nil {
+			return nil, err
+		}
+		return []*emailMessage{message}, nil
+	case emailFormatMBOX:
+		counter := newStructuredParseCounter(p.limits, "parse_mbox_messages")
+		rawMessages := splitMboxMessages(source)
+		messages := make([]*emailMessage, 0, len(rawMessages))
+		for index, raw := range rawMessages {
+			if err := counter.observe(); err != nil {
+				return nil, err
+			}
+			message, err := parseMIMEMessage(ctx, raw, p.limits, 0)
+			if err != nil {
+				return nil, fmt.Errorf("parse mbox message %d: %w", index+1, err)
+			}
+			messages = append(messages, message)
+		}
+		return messages, nil
+	default:
+		message, err := parseMIMEMessage(ctx, source, p.limits, 0)
+		if err != nil {
+			return nil, err
+		}
+		return []*emailMessage{message}, nil
+	}
+}
+
+func (r *emailAttachmentRenderer) render(ctx context.Context, message *emailMessage) (emailRenderedMessage, error) {
+	sections := []string{strings.TrimSpace(message.body)}
+	names := make([]string, 0, len(message.attachments))
+	for index, attachment := range message.attachments {
+		name := resolveEmailAttachmentName(attachment, index)
+		names = append(names, name)
+		text, err := r.parseAttachment(ctx, name, attachment)
+		if err != nil {
+			return emailRenderedMessage{}, err
+		}
+		if text != "" {
+			sections = append(sections, "Attachment: "+name+"\n"+text)
+		}
+	}
+	return emailRenderedMessage{
+		message:         message,
+		content:         strings.Join(filterNonEmptyStrings(sections), "\n\n"),
+		attachmentNames: names,
+	}, nil
+}
+
+func (r *emailAttachmentRenderer) parseAttachment(ctx context.Context, name string, attachment emailAttachment) (string, error) {
+	if len(attachment.data) == 0 {
+		return "", nil
+	}
+	if err := r.budget.consume(name, int64(len(attachment.data))); err != nil {
+		return "", fmt.Errorf("check email attachment limits: %w", err)
+	}
+	ext := resolveEmailAttachmentExtension(name, attachment.contentType)
+	if ext == "" {
+		return "", nil
+	}
+	parsed, err := r.service.ParseDocumentReaderWithOptions(ctx, name, bytes.NewReader(attachment.data), ext, r.options)
+	switch {
+	case err == nil:
+		return strings.TrimSpace(parsed.BestEffortText()), nil
+	case errors.Is(err, documentdomain.ErrDocumentResourceLimitExceeded):
+		return "", fmt.Errorf("parse email attachment %s: %w", name, err)
+	case errors.Is(err, documentdomain.ErrNoParserFound):
+		return "", nil
+	default:
+		r.failed++
+		return "", nil
+	}
+}
+
+func resolveEmailAttachmentName(attachment emailAttachment, index int) string {
+	if name := strings.TrimSpace(path.Base(strings.ReplaceAll(attachment.name, "\\", "/"))); name != "" && name != "." && name != "/" {
+		return name
+	}
+	name := fmt.Sprintf("attachment-%d", index+1)
+	if ext := filetype.ExtensionFromContentType(attachment.contentType); ext != "" {
+		name += "." + ext
+	}
+	return name
+}
+
+func resolveEmailAttachmentExtension(name, contentType string) string {
+	if ext := filetype.ExtractExtension(name); ext != "" {
+		return ext
+	}
+	return filetype.ExtensionFromContentType(contentType)
+}
+
+func (a emailAddress) String() string {
+	switch {
+	case a.name != "" && a.address != "" && !strings.EqualFold(a.name, a.address):
+		return a.name + " <" + a.address + ">"
+	case a.address != "":
+		return a.address
+	default:
+		return a.name
+	}
+}
+
+func formatEmailAddresses(addresses []emailAddress) []string {
+	formatted := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if value := address.String(); value != "" {
+			formatted = append(formatted, value)
+		}
+	}
+	return formatted
+}
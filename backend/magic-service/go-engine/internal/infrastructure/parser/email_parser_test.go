@@ -0,0 +1,148 @@
+package docparser_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+	parser "magic/internal/infrastructure/parser"
+)
+
+const emailTestMultipartEML = "From: =?UTF-8?B?5byg5LiJ?= <Zhang.San@Example.com>\r\n" +
+	"To: Li Si <lisi@example.com>, ops@example.com\r\n" +
+	"Cc: Wang Wu <wangwu@example.com>\r\n" +
+	"Subject: =?UTF-8?B?5a2j5bqm5oql5ZGK?=\r\n" +
+	"Date: Tue, 02 Jan 2024 10:30:00 +0800\r\n" +
+	"Message-ID: <report-1@example.com>\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"outer\"\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"inner\"\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"=E6=AD=A3=E6=96=87=E6=91=98=E8=A6=81\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"\r\n" +
+	"<p>HTML 版本不应重复</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: text/csv; name=\"orders.csv\"\r\n" +
+	"Content-Disposition: attachment; filename=\"orders.csv\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"6K6i5Y2V5Y+3LOmHkeminQpTTzAwMSw0Mgo=\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/octet-stream\r\n" +
+	"Content-Disposition: attachment; filename=\"blob.bin\"\r\n" +
+	"\r\n" +
+	"binary\r\n" +
+	"--outer--\r\n"
+
+func TestEmailParser_ParseDocumentExtractsHeadersBodyAndAttachments(t *testing.T) {
+	t.Parallel()
+
+	emailParser := parser.NewEmailParser([]documentdomain.Parser{parser.NewCSVParser()})
+	parsed, err := emailParser.ParseDocument(context.Background(), "report.eml", strings.NewReader(emailTestMultipartEML), "eml")
+	if err != nil {
+		t.Fatalf("parse eml: %v", err)
+	}
+	if parsed.SourceType != documentdomain.ParsedDocumentSourceEmail || len(parsed.Blocks) != 1 {
+		t.Fatalf("unexpected parsed document: %#v", parsed)
+	}
+
+	block := parsed.Blocks[0]
+	assertOrderedSubstrings(t, block.Content, "正文摘要", "Attachment: orders.csv", "SO001")
+	if strings.Contains(block.Content, "HTML 版本不应重复") {
+		t.Fatalf("expected plain alternative to win, got %q", block.Content)
+	}
+	assertOrderedSubstrings(t, parsed.PlainText, "Subject: 季度报告", "From: 张三 <Zhang.San@Example.com>", "正文摘要")
+
+	expected := map[string]any{
+		documentdomain.ParsedMetaEmailSubject:     "季度报告",
+		documentdomain.ParsedMetaEmailFrom:        "张三 <Zhang.San@Example.com>",
+		documentdomain.ParsedMetaEmailTo:          []string{"Li Si <lisi@example.com>", "ops@example.com"},
+		documentdomain.ParsedMetaEmailCc:          []string{"Wang Wu <wangwu@example.com>"},
+		documentdomain.ParsedMetaEmailDate:        "2024-01-02T02:30:00Z",
+		documentdomain.ParsedMetaCreatedAtTS:      int64(1704162600),
+		documentdomain.ParsedMetaEmailMessageID:   "report-1@example.com",
+		documentdomain.ParsedMetaEmailAttachments: []string{"orders.csv", "blob.bin"},
+		documentdomain.ParsedMetaTags: []string{
+			"email",
+			"from:zhang.san@example.com",
+			"to:lisi@example.com",
+			"to:ops@example.com",
+			"cc:wangwu@example.com",
+		},
+	}
+	for key, want := range expected {
+		if got := block.Metadata[key]; !reflect.DeepEqual(got, want) {
+			t.Fatalf("metadata %s = %#v, want %#v", key, got, want)
+		}
+	}
+}
+
+func TestEmailParser_ParseDocumentGroupsMboxThreads(t *testing.T) {
+	t.Parallel()
+
+	source := "From alice@example.com Mon Jan  1 09:00:00 2024\n" +
+		"From: alice@example.com\nTo: bob@example.com\nSubject: Launch plan\n" +
+		"Date: Mon, 01 Jan 2024 09:00:00 +0000\nMessage-ID: <a@example.com>\n\n" +
+		"First draft.\n>From the team\n\n" +
+		"From carol@example.com Mon Jan  1 08:00:00 2024\n" +
+		"From: carol@example.com\nTo: bob@example.com\nSubject: Lunch\n" +
+		"Date: Mon, 01 Jan 2024 08:00:00 +0000\nMessage-ID: <c@example.com>\n\n" +
+		"Noodles?\n\n" +
+		"From bob@example.com Mon Jan  1 10:00:00 2024\n" +
+		"From: bob@example.com\nTo: alice@example.com\nSubject: Re: Launch plan\n" +
+		"Date: Mon, 01 Jan 2024 10:00:00 +0000\nMessage-ID: <b@example.com>\nIn-Reply-To: <a@example.com>\n\n" +
+		"Looks good.\n"
+
+	options := documentdomain.DefaultParseOptions()
+	options.EmailGrouping = documentdomain.EmailGroupingThread
+	parsed, err := parser.NewEmailParser(nil).
+		ParseDocumentWithOptions(context.Background(), "box.mbox", strings.NewReader(source), "mbox", options)
+	if err != nil {
+		t.Fatalf("parse mbox: %v", err)
+	}
+	if len(parsed.Blocks) != 2 {
+		t.Fatalf("expected 2 thread blocks, got %d: %#v", len(parsed.Blocks), parsed.Blocks)
+	}
+
+	lunch, launch := parsed.Blocks[0], parsed.Blocks[1]
+	if lunch.Metadata[documentdomain.ParsedMetaEmailSubject] != "Lunch" {
+		t.Fatalf("expected earliest thread first, got %#v", lunch.Metadata)
+	}
+	if launch.Type != documentdomain.ParsedBlockTypeEmailThread ||
+		launch.Metadata[documentdomain.ParsedMetaEmailMessageCount] != 2 ||
+		launch.Metadata[documentdomain.ParsedMetaEmailThreadID] != "a@example.com" {
+		t.Fatalf("unexpected launch thread metadata: %#v", launch.Metadata)
+	}
+	assertOrderedSubstrings(t, launch.Content, "From: alice@example.com", "First draft.\nFrom the team", "From: bob@example.com", "Looks good.")
+	tags, _ := launch.Metadata[documentdomain.ParsedMetaTags].([]string)
+	for _, want := range []string{"from:alice@example.com", "from:bob@example.com", "to:alice@example.com"} {
+		if !strings.Contains(strings.Join(tags, ","), want) {
+			t.Fatalf("expected tag %q in %#v", want, tags)
+		}
+	}
+}
+
+func TestEmailParser_Supports(t *testing.T) {
+	t.Parallel()
+
+	p := parser.NewEmailParser(nil)
+	for _, fileType := range []string{"eml", "MBOX", " msg "} {
+		if !p.Supports(fileType) {
+			t.Fatalf("expected %q supported", fileType)
+		}
+	}
+	if p.Supports("txt") {
+		t.Fatal("expected txt not supported")
+	}
+}
@@ -0,0 +1,290 @@
+package docparser
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+)
+
+// EpubParser 按 OPF spine 顺序解析 EPUB 章节。
+type EpubParser struct {
+	visualExtractor documentdomain.VisualTextExtractor
+	maxOCRPerFile   int
+	limits          documentdomain.ResourceLimits
+}
+
+const epubContainerEntry = "META-INF/container.xml"
+
+var (
+	errEpubPackageNotFound    = errors.New("epub package document not found")
+	errEpubSpineEmpty         = errors.New("epub spine has no readable chapters")
+	errEpubLinkNotSupported   = errors.New("epub archive entry has no link")
+	errEpubArchiveEntryAbsent = errors.New("epub archive entry not found")
+)
+
+type epubContainer struct {
+	Rootfiles []epubRootfile `xml:"rootfiles>rootfile"`
+}
+
+type epubRootfile struct {
+	FullPath string `xml:"full-path,attr"`
+}
+
+type epubPackage struct {
+	Manifest []epubManifestItem `xml:"manifest>item"`
+	Spine    []epubSpineItem    `xml:"spine>itemref"`
+}
+
+type epubManifestItem struct {
+	ID        string `xml:"id,attr"`
+	Href      string `xml:"href,attr"`
+	MediaType string `xml:"media-type,attr"`
+}
+
+type epubSpineItem struct {
+	IDRef  string `xml:"idref,attr"`
+	Linear string `xml:"linear,attr"`
+}
+
+// epubArchiveFetcher 让富文本资源加载器从 EPUB 压缩包内读取章节引用的图片。
+type epubArchiveFetcher struct {
+	files  []*zip.File
+	limits documentdomain.ResourceLimits
+}
+
+// NewEpubParserWithLimit 创建带单文件 OCR 限额的 EPUB 解析器。
+func NewEpubParserWithLimit(
+	ocrClient documentdomain.OCRClient,
+	maxOCRPerFile int,
+	resourceLimits ...documentdomain.ResourceLimits,
+) *EpubParser {
+	return NewEpubParserWithVisualLimit(newVisualTextExtractorFromOCR(ocrClient), maxOCRPerFile, resourceLimits...)
+}
+
+// NewEpubParserWithVisualLimit 创建带单文件视觉转文字限额的 EPUB 解析器。
+func NewEpubParserWithVisualLimit(
+	visualExtractor documentdomain.VisualTextExtractor,
+	maxOCRPerFile int,
+	resourceLimits ...documentdomain.ResourceLimits,
+) *EpubParser {
+	limits := documentdomain.DefaultResourceLimits()
+	if len(resourceLimits) > 0 {
+		limits = resourceLimits[0]
+	}
+	return &EpubParser{
+		visualExtractor: visualExtractor,
+		maxOCRPerFile:   documentdomain.NormalizeEmbeddedImageOCRLimit(maxOCRPerFile),
+		limits:          documentdomain.NormalizeResourceLimits(limits),
+	}
+}
+
+// Parse 解析 EPUB 文件并返回最佳努力纯文本。
+func (p *EpubParser) Parse(ctx context.Context, fileURL string, file io.Reader, fileType string) (string, error) {
+	parsed, err := p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, documentdomain.DefaultParseOptions())
+	if err != nil {
+		return "", err
+	}
+	return parsed.BestEffortText(), nil
+}
+
+// ParseWithOptions 按解析选项解析 EPUB 文件。
+func (p *EpubParser) ParseWithOptions(
+	ctx context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+	options documentdomain.ParseOptions,
+) (string, error) {
+	parsed, err := p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, options)
+	if err != nil {
+		return "", err
+	}
+	return parsed.BestEffortText(), nil
+}
+
+// ParseDocument 解析 EPUB 文件并返回结构化结果。
+func (p *EpubParser) ParseDocument(
+	ctx context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+) (*documentdomain.ParsedDocument, error) {
+	return p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, documentdomain.DefaultParseOptions())
+}
+
+// ParseDocumentWithOptions 按解析选项解析 EPUB 文件并返回结构化结果。
+//
+// 章节 XHTML 复用 HTML 渲染器输出 Markdown 标题，图片按章节相对路径从压缩包内读取后走内嵌图片 OCR。
+func (p *EpubParser) ParseDocumentWithOptions(
+	ctx context.Context,
+	_ string,
+	file io.Reader,
+	fileType string,
+	options documentdomain.ParseOptions,
+) (*documentdomain.ParsedDocument, error) {
+	source, err := io.ReadAll(documentdomain.NewSourceSizeLimitedReader(file, p.limits))
+	if err != nil {
+		return nil, fmt.Errorf("read epub source failed: %w", err)
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(source), int64(len(source)))
+	if err != nil {
+		return nil, fmt.Errorf("open epub zip failed: %w", err)
+	}
+	if err := checkZipArchiveLimits(zipReader.File, p.limits); err != nil {
+		return nil, fmt.Errorf("check epub archive limits: %w", err)
+	}
+	chapterPaths, err := resolveEpubChapterPaths(zipReader.File, p.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	renderer := newHTMLTextRenderer(newRichTextAssetLoader(epubArchiveFetcher{files: zipReader.File, limits: p.limits}, p.limits))
+	ocrHelper := newRichTextImageOCRHelper(p.visualExtractor, p.maxOCRPerFile, options)
+	blocks := make([]string, 0)
+	for _, chapterPath := range chapterPaths {
+		raw, err := readZipEntryWithArchiveLimit(zipReader.File, chapterPath, p.limits)
+		if err != nil {
+			return nil, fmt.Errorf("read epub chapter %s: %w", chapterPath, err)
+		}
+		root, err := html.Parse(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("parse epub chapter %s: %w", chapterPath, err)
+		}
+		blocks = append(blocks, renderer.renderBlocks(ctx, chapterPath, findEpubChapterBody(root), ocrHelper)...)
+	}
+
+	parsed := documentdomain.NewPlainTextParsedDocument(fileType, strings.Join(filterNonEmptyStrings(blocks), "\n\n"))
+	ocrHelper.apply(parsed)
+	if err := failIfEmptyDueToOCROverload(parsed, ocrHelper); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// Supports 检查是否支持该文件类型。
+func (p *EpubParser) Supports(fileType string) bool {
+	return strings.EqualFold(strings.TrimSpace(fileType), "epub")
+}
+
+// NeedsResolvedURL EPUB 解析只依赖文件流。
+func (p *EpubParser) NeedsResolvedURL() bool {
+	return false
+}
+
+func resolveEpubChapterPaths(files []*zip.File, limits documentdomain.ResourceLimits) ([]string, error) {
+	containerRaw, err := readZipEntryWithArchiveLimit(files, epubContainerEntry, limits)
+	if err != nil {
+		return nil, fmt.Errorf("read epub container: %w", err)
+	}
+	var container epubContainer
+	if err := xml.Unmarshal(containerRaw, &container); err != nil {
+		return nil, fmt.Errorf("unmarshal epub container: %w", err)
+	}
+	if len(container.Rootfiles) == 0 || strings.TrimSpace(container.Rootfiles[0].FullPath) == "" {
+		return nil, errEpubPackageNotFound
+	}
+
+	packagePath := resolveEpubEntryPath(container.Rootfiles[0].FullPath)
+	packageRaw, err := readZipEntryWithArchiveLimit(files, packagePath, limits)
+	if err != nil {
+		return nil, fmt.Errorf("read epub package %s: %w", packagePath, err)
+	}
+	var pkg epubPackage
+	if err := xml.Unmarshal(packageRaw, &pkg); err != nil {
+		return nil, fmt.Errorf("unmarshal epub package: %w", err)
+	}
+
+	manifest := make(map[string]epubManifestItem, len(pkg.Manifest))
+	for _, item := range pkg.Manifest {
+		manifest[strings.TrimSpace(item.ID)] = item
+	}
+	chapterPaths := make([]string, 0, len(pkg.Spine))
+	for _, itemRef := range pkg.Spine {
+		// linear="no" 是封面、弹出注释等辅助内容，不属于正文阅读顺序。
+		if strings.EqualFold(strings.TrimSpace(itemRef.Linear), "no") {
+			continue
+		}
+		item, ok := manifest[strings.TrimSpace(itemRef.IDRef)]
+		if !ok || !isEpubChapterMediaType(item.MediaType) || strings.TrimSpace(item.Href) == "" {
+			continue
+		}
+		chapterPaths = append(chapterPaths, resolveEpubEntryPath(path.Join(path.Dir(packagePath), item.Href)))
+	}
+	if len(chapterPaths) == 0 {
+		return nil, errEpubSpineEmpty
+	}
+	return chapterPaths, nil
+}
+
+func isEpubChapterMediaType(mediaType string) bool {
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case "application/xhtml+xml", "text/html":
+		return true
+	default:
+		return false
+	}
+}
+
+func resolveEpubEntryPath(raw string) string {
+	entryPath := strings.TrimSpace(raw)
+	if unescaped, err := url.PathUnescape(entryPath); err == nil {
+		entryPath = unescaped
+	}
+	return strings.TrimPrefix(path.Clean(entryPath), "/")
+}
+
+func findEpubChapterBody(root *html.Node) *html.Node {
+	var body *html.Node
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		for child := node.FirstChild; child != nil && body == nil; child = child.NextSibling {
+			if child.Type == html.ElementNode && strings.EqualFold(child.Data, "body") {
+				body = child
+				return
+			}
+			walk(child)
+		}
+	}
+	walk(root)
+	if body == nil {
+		return root
+	}
+	return body
+}
+
+// Fetch 读取压缩包内资源。
+func (f epubArchiveFetcher) Fetch(_ context.Context, entryPath string) (io.ReadCloser, error) {
+	data, err := readZipEntryWithEmbeddedAssetLimit(f.files, resolveEpubEntryPath(entryPath), f.limits)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// GetLink 压缩包内资源没有可访问链接。
+func (f epubArchiveFetcher) GetLink(context.Context, string, string, time.Duration) (string, error) {
+	return "", errEpubLinkNotSupported
+}
+
+// Stat 检查压缩包内资源是否存在。
+func (f epubArchiveFetcher) Stat(_ context.Context, entryPath string) error {
+	target := resolveEpubEntryPath(entryPath)
+	for _, file := range f.files {
+		if file != nil && path.Clean(file.Name) == target {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", errEpubArchiveEntryAbsent, target)
+}
@@ -0,0 +1,69 @@
+package docparser_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	parser "magic/internal/infrastructure/parser"
+)
+
+func TestEpubParser_ParseDocumentFollowsSpineOrderAndOCRsImages(t *testing.T) {
+	t.Parallel()
+
+	var image bytes.Buffer
+	writeTestPNGToBuffer(t, &image)
+	source := buildParserTestZip(t, map[string]string{
+		"mimetype": "application/epub+zip",
+		"META-INF/container.xml": `<?xml version="1.0"?>
+<container xmlns="urn:oasis:names:tc:opendocument:xmlns:container" version="1.0">
+  <rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`,
+		"OEBPS/content.opf": `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <manifest>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch1" href="text/chapter%201.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="text/chapter2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="img" href="images/figure.png" media-type="image/png"/>
+  </manifest>
+  <spine>
+    <itemref idref="cover" linear="no"/>
+    <itemref idref="ch2"/>
+    <itemref idref="ch1"/>
+  </spine>
+</package>`,
+		"OEBPS/cover.xhtml":          `<html><body><p>封面文字</p></body></html>`,
+		"OEBPS/text/chapter 1.xhtml": `<html><head><title>忽略标题</title></head><body><h1>第二章</h1><p>后读的章节</p></body></html>`,
+		"OEBPS/text/chapter2.xhtml":  `<html><body><h1>第一章</h1><p>先读的章节</p><img src="../images/figure.png"/></body></html>`,
+		"OEBPS/images/figure.png":    image.String(),
+	})
+
+	ocr := &fakeDocxOCR{textsByType: map[string]string{"png": "插图关键词"}}
+	parsed, err := parser.NewEpubParserWithLimit(ocr, 20).
+		ParseDocument(context.Background(), "book.epub", bytes.NewReader(source), "epub")
+	if err != nil {
+		t.Fatalf("parse epub: %v", err)
+	}
+
+	text := parsed.BestEffortText()
+	assertOrderedSubstrings(t, text, "# 第一章", "先读的章节", "插图关键词", "# 第二章", "后读的章节")
+	if strings.Contains(text, "封面文字") || strings.Contains(text, "忽略标题") {
+		t.Fatalf("expected non-linear cover and head to be skipped, got %q", text)
+	}
+	if ocr.callCount != 1 {
+		t.Fatalf("expected one OCR call, got %d", ocr.callCount)
+	}
+}
+
+func TestEpubParser_ParseDocumentRejectsMissingContainer(t *testing.T) {
+	t.Parallel()
+
+	source := buildParserTestZip(t, map[string]string{"mimetype": "application/epub+zip"})
+	_, err := parser.NewEpubParserWithLimit(nil, 0).
+		ParseDocument(context.Background(), "broken.epub", bytes.NewReader(source), "epub")
+	if err == nil {
+		t.Fatal("expected missing container error")
+	}
+}
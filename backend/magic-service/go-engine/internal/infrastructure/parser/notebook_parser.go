@@ -0,0 +1,270 @@
+package docparser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+)
+
+const (
+	notebookCellTypeMarkdown = "markdown"
+	notebookCellTypeCode     = "code"
+	notebookCellTypeRaw      = "raw"
+
+	// notebookOutputMaxRunes 限制单个单元格输出的保留长度，避免训练日志、大表格淹没代码本身。
+	notebookOutputMaxRunes = 2000
+)
+
+var (
+	errNotebookInvalid     = errors.New("invalid jupyter notebook")
+	notebookHeadingPattern = regexp.MustCompile(`^#{1,6}\s+(.+)$`)
+)
+
+// NotebookParser 解析 Jupyter Notebook，每个 markdown/code/raw 单元格输出一个有序块。
+type NotebookParser struct {
+	limits documentdomain.ResourceLimits
+}
+
+// notebookText 兼容 nbformat 中 string 与 []string 两种多行文本写法。
+type notebookText string
+
+type notebookFile struct {
+	Cells    []notebookCell   `json:"cells"`
+	Metadata notebookMetadata `json:"metadata"`
+}
+
+type notebookMetadata struct {
+	KernelSpec struct {
+		Language string `json:"language"`
+	} `json:"kernelspec"`
+	LanguageInfo struct {
+		Name string `json:"name"`
+	} `json:"language_info"`
+}
+
+type notebookCell struct {
+	CellType string           `json:"cell_type"`
+	Source   notebookText     `json:"source"`
+	Outputs  []notebookOutput `json:"outputs"`
+}
+
+type notebookOutput struct {
+	OutputType string                  `json:"output_type"`
+	Text       notebookText            `json:"text"`
+	Data       map[string]notebookText `json:"data"`
+	EName      string                  `json:"ename"`
+	EValue     string                  `json:"evalue"`
+}
+
+// UnmarshalJSON 解析字符串或字符串数组；其它类型（如图片 data 里的对象）按空文本处理。
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = notebookText(single)
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*t = notebookText(strings.Join(lines, ""))
+		return nil
+	}
+	*t = ""
+	return nil
+}
+
+// NewNotebookParser 创建 Jupyter Notebook 解析器。
+func NewNotebookParser(resourceLimits ...documentdomain.ResourceLimits) *NotebookParser {
+	limits := documentdomain.DefaultResourceLimits()
+	if len(resourceLimits) > 0 {
+		limits = resourceLimits[0]
+	}
+	return &NotebookParser{limits: documentdomain.NormalizeResourceLimits(limits)}
+}
+
+// Parse 解析 Notebook 并返回最佳努力纯文本。
+func (p *NotebookParser) Parse(ctx context.Context, fileURL string, file io.Reader, fileType string) (string, error) {
+	parsed, err := p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, documentdomain.DefaultParseOptions())
+	if err != nil {
+		return "", err
+	}
+	return parsed.BestEffortText(), nil
+}
+
+// ParseWithOptions 按解析选项解析 Notebook。
+func (p *NotebookParser) ParseWithOptions(
+	ctx context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+	options documentdomain.ParseOptions,
+) (string, error) {
+	parsed, err := p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, options)
+	if err != nil {
+		return "", err
+	}
+	return parsed.BestEffortText(), nil
+}
+
+// ParseDocument 解析 Notebook 并返回结构化结果。
+func (p *NotebookParser) ParseDocument(
+	ctx context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+) (*documentdomain.ParsedDocument, error) {
+	return p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, documentdomain.DefaultParseOptions())
+}
+
+// ParseDocumentWithOptions 按解析选项解析 Notebook 并返回结构化结果。
+//
+// code 单元格的文本输出（stream、text/plain、错误信息）追加在代码之后，图片等二进制输出忽略；
+// 每个块记录单元格序号、类型以及最近的 Markdown 标题，便于命中后定位回单元格。
+func (p *NotebookParser) ParseDocumentWithOptions(
+	_ context.Context,
+	_ string,
+	file io.Reader,
+	fileType string,
+	_ documentdomain.ParseOptions,
+) (*documentdomain.ParsedDocument, error) {
+	source, err := io.ReadAll(documentdomain.NewSourceSizeLimitedReader(file, p.limits))
+	if err != nil {
+		return nil, fmt.Errorf("read notebook failed: %w", err)
+	}
+	if err := documentdomain.CheckPlainTextBytes(source, p.limits, "parse_notebook"); err != nil {
+		return nil, fmt.Errorf("check notebook size: %w", err)
+	}
+	var notebook notebookFile
+	if err := json.Unmarshal(source, &notebook); err != nil {
+		return nil, fmt.Errorf("%w: %w", errNotebookInvalid, err)
+	}
+	language := resolveNotebookLanguage(notebook.Metadata)
+
+	counter := newStructuredParseCounter(p.limits, "parse_notebook_cells")
+	blocks := make([]documentdomain.ParsedBlock, 0, len(notebook.Cells))
+	texts := make([]string, 0, len(notebook.Cells))
+	section := ""
+	for index, cell := range notebook.Cells {
+		cellType := strings.ToLower(strings.TrimSpace(cell.CellType))
+		if cellType == notebookCellTypeMarkdown {
+			section = firstNonEmptyString(lastMarkdownHeading(string(cell.Source)), section)
+		}
+		content := renderNotebookCell(cellType, cell, language)
+		if content == "" {
+			continue
+		}
+		if err := counter.observe(); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, documentdomain.ParsedBlock{
+			Type:    documentdomain.ParsedBlockTypeNotebookCell,
+			Content: content,
+			Metadata: map[string]any{
+				documentdomain.ParsedMetaChunkType:         documentdomain.ParsedBlockTypeNotebookCell,
+				documentdomain.ParsedMetaNotebookCellIndex: index,
+				documentdomain.ParsedMetaNotebookCellType:  cellType,
+				documentdomain.ParsedMetaNotebookSection:   section,
+			},
+		})
+		texts = append(texts, content)
+	}
+	return &documentdomain.ParsedDocument{
+		SourceType: documentdomain.ParsedDocumentSourceNotebook,
+		PlainText:  strings.Join(texts, "\n\n"),
+		Blocks:     blocks,
+		DocumentMeta: map[string]any{
+			documentdomain.ParsedMetaSourceFormat: strings.ToLower(strings.TrimSpace(fileType)),
+			"notebook_language":                   language,
+			"notebook_cell_count":                 len(notebook.Cells),
+		},
+	}, nil
+}
+
+// Supports 检查是否支持该文件类型。
+func (p *NotebookParser) Supports(fileType string) bool {
+	return strings.ToLower(strings.TrimSpace(fileType)) == "ipynb"
+}
+
+// NeedsResolvedURL Notebook 解析只依赖文件流。
+func (p *NotebookParser) NeedsResolvedURL() bool {
+	return false
+}
+
+// resolveNotebookLanguage 优先取 language_info，缺失时回退 kernelspec，再缺省为 python。
+func resolveNotebookLanguage(metadata notebookMetadata) string {
+	return strings.ToLower(firstNonEmptyString(metadata.LanguageInfo.Name, metadata.KernelSpec.Language, "python"))
+}
+
+// renderNotebookCell 渲染单元格；code 单元格用围栏包裹代码，保持与 Markdown 单元格混排时的可读性。
+func renderNotebookCell(cellType string, cell notebookCell, language string) string {
+	source := strings.TrimSpace(strings.ReplaceAll(string(cell.Source), "\r\n", "\n"))
+	switch cellType {
+	case notebookCellTypeMarkdown, notebookCellTypeRaw:
+		return source
+	case notebookCellTypeCode:
+		parts := make([]string, 0, 2)
+		if source != "" {
+			parts = append(parts, "```"+language+"\n"+source+"\n```")
+		}
+		if output := renderNotebookOutputs(cell.Outputs); output != "" {
+			parts = append(parts, "Output:\n"+output)
+		}
+		return strings.Join(parts, "\n\n")
+	default:
+		return ""
+	}
+}
+
+func renderNotebookOutputs(outputs []notebookOutput) string {
+	texts := make([]string, 0, len(outputs))
+	for _, output := range outputs {
+		switch output.OutputType {
+		case "stream":
+			texts = append(texts, string(output.Text))
+		case "execute_result", "display_data":
+			texts = append(texts, string(firstNonEmptyNotebookText(output.Data["text/markdown"], output.Data["text/plain"])))
+		case "error":
+			texts = append(texts, strings.Trim(output.EName+": "+output.EValue, ": "))
+		}
+	}
+	rendered := strings.TrimSpace(strings.Join(filterNonEmptyStrings(texts), "\n"))
+	runes := []rune(rendered)
+	if len(runes) > notebookOutputMaxRunes {
+		return string(runes[:notebookOutputMaxRunes]) + "\n..."
+	}
+	return rendered
+}
+
+func firstNonEmptyNotebookText(values ...notebookText) notebookText {
+	for _, value := range values {
+		if strings.TrimSpace(string(value)) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// lastMarkdownHeading 返回 Markdown 文本中最后一个 ATX 标题，代码围栏内的 "#" 不算标题。
+func lastMarkdownHeading(markdown string) string {
+	heading := ""
+	inFence := false
+	for line := range strings.SplitSeq(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if match := notebookHeadingPattern.FindStringSubmatch(trimmed); match != nil {
+			heading = strings.TrimSpace(strings.TrimRight(match[1], "# "))
+		}
+	}
+	return heading
+}
@@ -0,0 +1,91 @@
+package docparser_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+	parser "magic/internal/infrastructure/parser"
+)
+
+const notebookTestSource = `{
+  "metadata": {"kernelspec": {"language": "python"}, "language_info": {"name": "python"}},
+  "nbformat": 4,
+  "cells": [
+    {"cell_type": "markdown", "source": ["# 销量分析\n", "读取数据并统计。"]},
+    {"cell_type": "code", "source": "import pandas as pd\ndf = pd.read_csv('sales.csv')", "outputs": []},
+    {"cell_type": "markdown", "source": "## 汇总\n` + "```" + `\n# 不是标题\n` + "```" + `"},
+    {"cell_type": "code", "source": ["df.sum()"], "outputs": [
+      {"output_type": "stream", "name": "stdout", "text": ["loading\n"]},
+      {"output_type": "execute_result", "data": {"text/plain": ["amount    42"], "image/png": "iVBORw0KGgo="}},
+      {"output_type": "display_data", "data": {"image/png": "iVBORw0KGgo="}},
+      {"output_type": "error", "ename": "KeyError", "evalue": "'region'"}
+    ]},
+    {"cell_type": "code", "source": "", "outputs": []},
+    {"cell_type": "raw", "source": "raw note"}
+  ]
+}`
+
+func TestNotebookParser_ParseDocumentBuildsOrderedCellBlocks(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := parser.NewNotebookParser().ParseDocument(context.Background(), "analysis.ipynb", strings.NewReader(notebookTestSource), "ipynb")
+	if err != nil {
+		t.Fatalf("parse notebook: %v", err)
+	}
+	if parsed.SourceType != documentdomain.ParsedDocumentSourceNotebook || len(parsed.Blocks) != 5 {
+		t.Fatalf("unexpected parsed document: %#v", parsed)
+	}
+
+	expected := []struct {
+		index    int
+		cellType string
+		section  string
+	}{
+		{index: 0, cellType: "markdown", section: "销量分析"},
+		{index: 1, cellType: "code", section: "销量分析"},
+		{index: 2, cellType: "markdown", section: "汇总"},
+		{index: 3, cellType: "code", section: "汇总"},
+		{index: 5, cellType: "raw", section: "汇总"},
+	}
+	for position, want := range expected {
+		block := parsed.Blocks[position]
+		if block.Type != documentdomain.ParsedBlockTypeNotebookCell ||
+			block.Metadata[documentdomain.ParsedMetaNotebookCellIndex] != want.index ||
+			block.Metadata[documentdomain.ParsedMetaNotebookCellType] != want.cellType ||
+			block.Metadata[documentdomain.ParsedMetaNotebookSection] != want.section {
+			t.Fatalf("unexpected block %d: %#v", position, block)
+		}
+	}
+
+	if parsed.Blocks[1].Content != "```python\nimport pandas as pd\ndf = pd.read_csv('sales.csv')\n```" {
+		t.Fatalf("unexpected code cell: %q", parsed.Blocks[1].Content)
+	}
+	if parsed.Blocks[3].Content != "```python\ndf.sum()\n```\n\nOutput:\nloading\namount    42\nKeyError: 'region'" {
+		t.Fatalf("unexpected code cell with outputs: %q", parsed.Blocks[3].Content)
+	}
+	if strings.Contains(parsed.PlainText, "iVBORw0KGgo") {
+		t.Fatalf("expected binary outputs to be dropped, got %q", parsed.PlainText)
+	}
+}
+
+func TestNotebookParser_RejectsInvalidNotebook(t *testing.T) {
+	t.Parallel()
+
+	notebookParser := parser.NewNotebookParser()
+	if _, err := notebookParser.ParseDocument(context.Background(), "broken.ipynb", strings.NewReader("{"), "ipynb"); err == nil {
+		t.Fatal("expected invalid notebook error")
+	}
+	if !notebookParser.Supports(" IPYNB ") || notebookParser.Supports("json") {
+		t.Fatal("unexpected notebook support result")
+	}
+
+	limits := documentdomain.DefaultResourceLimits()
+	limits.MaxPlainTextChars = 10
+	_, err := parser.NewNotebookParser(limits).ParseDocument(context.Background(), "big.ipynb", strings.NewReader(notebookTestSource), "ipynb")
+	if !errors.Is(err, documentdomain.ErrDocumentResourceLimitExceeded) {
+		t.Fatalf("expected resource limit error, got %v", err)
+	}
+}
@@ -0,0 +1,446 @@
+package docparser
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+)
+
+// OpenDocumentParser 解析 ODT/ODS/ODP 文档。
+//
+// 文本与演示文稿输出带 Markdown 标题的纯文本，供层级切片识别；表格文档复用 Excel 的表格块链路。
+type OpenDocumentParser struct {
+	visualExtractor documentdomain.VisualTextExtractor
+	maxOCRPerFile   int
+	limits          documentdomain.ResourceLimits
+}
+
+const (
+	openDocumentContentEntry       = "content.xml"
+	openDocumentFormatText         = "odt"
+	openDocumentFormatSpreadsheet  = "ods"
+	openDocumentFormatPresentation = "odp"
+	openDocumentMaxHeadingLevel    = 6
+)
+
+type openDocumentNode struct {
+	name     string
+	attrs    []xml.Attr
+	text     string
+	children []*openDocumentNode
+}
+
+type openDocumentImageLoader struct {
+	files     []*zip.File
+	limits    documentdomain.ResourceLimits
+	ocrHelper *embeddedImageOCRHelper
+}
+
+type openDocumentTextRenderer struct {
+	images *openDocumentImageLoader
+}
+
+// NewOpenDocumentParserWithLimit 创建带单文件 OCR 限额的 OpenDocument 解析器。
+func NewOpenDocumentParserWithLimit(
+	ocrClient documentdomain.OCRClient,
+	maxOCRPerFile int,
+	resourceLimits ...documentdomain.ResourceLimits,
+) *OpenDocumentParser {
+	return NewOpenDocumentParserWithVisualLimit(newVisualTextExtractorFromOCR(ocrClient), maxOCRPerFile, resourceLimits...)
+}
+
+// NewOpenDocumentParserWithVisualLimit 创建带单文件视觉转文字限额的 OpenDocument 解析器。
+func NewOpenDocumentParserWithVisualLimit(
+	visualExtractor documentdomain.VisualTextExtractor,
+	maxOCRPerFile int,
+	resourceLimits ...documentdomain.ResourceLimits,
+) *OpenDocumentParser {
+	limits := documentdomain.DefaultResourceLimits()
+	if len(resourceLimits) > 0 {
+		limits = resourceLimits[0]
+	}
+	return &OpenDocumentParser{
+		visualExtractor: visualExtractor,
+		maxOCRPerFile:   documentdomain.NormalizeEmbeddedImageOCRLimit(maxOCRPerFile),
+		limits:          documentdomain.NormalizeResourceLimits(limits),
+	}
+}
+
+// Parse 解析 OpenDocument 文件并返回最佳努力纯文本。
+func (p *OpenDocumentParser) Parse(ctx context.Context, fileURL string, file io.Reader, fileType string) (string, error) {
+	parsed, err := p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, documentdomain.DefaultParseOptions())
+	if err != nil {
+		return "", err
+	}
+	return parsed.BestEffortText(), nil
+}
+
+// ParseWithOptions 按解析选项解析 OpenDocument 文件。
+func (p *OpenDocumentParser) ParseWithOptions(
+	ctx context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+	options documentdomain.ParseOptions,
+) (string, error) {
+	parsed, err := p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, options)
+	if err != nil {
+		return "", err
+	}
+	return parsed.BestEffortText(), nil
+}
+
+// ParseDocument 解析 OpenDocument 文件并返回结构化结果。
+func (p *OpenDocumentParser) ParseDocument(
+	ctx context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+) (*documentdomain.ParsedDocument, error) {
+	return p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, documentdomain.DefaultParseOptions())
+}
+
+// ParseDocumentWithOptions 按解析选项解析 OpenDocument 文件并返回结构化结果。
+func (p *OpenDocumentParser) ParseDocumentWithOptions(
+	ctx context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+	options documentdomain.ParseOptions,
+) (*documentdomain.ParsedDocument, error) {
+	source, err := io.ReadAll(documentdomain.NewSourceSizeLimitedReader(file, p.limits))
+	if err != nil {
+		return nil, fmt.Errorf("read opendocument source failed: %w", err)
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(source), int64(len(source)))
+	if err != nil {
+		return nil, fmt.Errorf("open opendocument zip failed: %w", err)
+	}
+	if err := checkOfficeZipArchiveLimits(zipReader.File, p.limits); err != nil {
+		return nil, fmt.Errorf("check opendocument archive limits: %w", err)
+	}
+	raw, err := readZipEntryWithArchiveLimit(zipReader.File, openDocumentContentEntry, p.limits)
+	if err != nil {
+		return nil, fmt.Errorf("read opendocument content failed: %w", err)
+	}
+	root, err := parseOpenDocumentXML(raw, p.limits)
+	if err != nil {
+		return nil, fmt.Errorf("parse opendocument content failed: %w", err)
+	}
+
+	ocrHelper := newRichTextImageOCRHelper(p.visualExtractor, p.maxOCRPerFile, options)
+	renderer := openDocumentTextRenderer{images: &openDocumentImageLoader{
+		files:     zipReader.File,
+		limits:    p.limits,
+		ocrHelper: ocrHelper,
+	}}
+	body := findOpenDocumentNode(root, "body")
+
+	var parsed *documentdomain.ParsedDocument
+	switch strings.ToLower(strings.TrimSpace(fileType)) {
+	case openDocumentFormatSpreadsheet:
+		parsed, err = buildOpenDocumentSpreadsheetDocument(ctx, &openDocumentSpreadsheetReader{
+			renderer: renderer,
+			limits:   p.limits,
+		}, root, fileURL, fileType, options)
+	case openDocumentFormatPresentation:
+		var content string
+		content, err = renderer.renderPresentation(ctx, body, p.limits)
+		parsed = documentdomain.NewPlainTextParsedDocument(fileType, content)
+	default:
+		blocks := filterNonEmptyStrings(renderer.renderBlocks(ctx, body))
+		parsed = documentdomain.NewPlainTextParsedDocument(fileType, strings.Join(blocks, "\n\n"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	ocrHelper.apply(parsed)
+	if err := failIfEmptyDueToOCROverload(parsed, ocrHelper); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// Supports 检查是否支持该文件类型。
+func (p *OpenDocumentParser) Supports(fileType string) bool {
+	switch strings.ToLower(strings.TrimSpace(fileType)) {
+	case openDocumentFormatText, openDocumentFormatSpreadsheet, openDocumentFormatPresentation:
+		return true
+	default:
+		return false
+	}
+}
+
+// NeedsResolvedURL OpenDocument 解析只依赖文件流。
+func (p *OpenDocumentParser) NeedsResolvedURL() bool {
+	return false
+}
+
+// parseOpenDocumentXML 把 content.xml 读成保留混排顺序的节点树，文本节点 name 为空。
+func parseOpenDocumentXML(content []byte, limits documentdomain.ResourceLimits) (*openDocumentNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	root := &openDocumentNode{}
+	stack := []*openDocumentNode{root}
+	counter := newStructuredParseCounter(limits, "parse_opendocument_xml")
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("read opendocument xml token: %w", err)
+		}
+		parent := stack[len(stack)-1]
+		switch typed := token.(type) {
+		case xml.StartElement:
+			if err := counter.observe(); err != nil {
+				return nil, err
+			}
+			node := &openDocumentNode{name: typed.Name.Local, attrs: typed.Attr}
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.children = append(parent.children, &openDocumentNode{text: string(typed)})
+		}
+	}
+	return root, nil
+}
+
+func findOpenDocumentNode(node *openDocumentNode, name string) *openDocumentNode {
+	if node == nil {
+		return nil
+	}
+	if node.name == name {
+		return node
+	}
+	for _, child := range node.children {
+		if found := findOpenDocumentNode(child, name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// collectOpenDocumentNodes 按文档顺序收集指定元素，命中后不再向下查找。
+func collectOpenDocumentNodes(node *openDocumentNode, name string) []*openDocumentNode {
+	if node == nil {
+		return nil
+	}
+	if node.name == name {
+		return []*openDocumentNode{node}
+	}
+	nodes := make([]*openDocumentNode, 0)
+	for _, child := range node.children {
+		nodes = append(nodes, collectOpenDocumentNodes(child, name)...)
+	}
+	return nodes
+}
+
+// isSkippedOpenDocumentElement 过滤批注、脚注、修订记录和目录等会重复或打断正文的元素。
+func isSkippedOpenDocumentElement(name string) bool {
+	switch name {
+	case "annotation", "note", "notes", "tracked-changes", "sequence-decls", "variable-decls", "user-field-decls",
+		"forms", "table-of-content", "illustration-index", "table-index", "object-index", "user-index",
+		"alphabetical-index", "bibliography":
+		return true
+	default:
+		return false
+	}
+}
+
+func (r openDocumentTextRenderer) renderBlocks(ctx context.Context, node *openDocumentNode) []string {
+	if node == nil || node.name == "" || isSkippedOpenDocumentElement(node.name) {
+		return nil
+	}
+	switch node.name {
+	case "h":
+		if text := r.inlineText(ctx, node); text != "" {
+			return []string{strings.Repeat("#", resolveOpenDocumentHeadingLevel(node)) + " " + text}
+		}
+		return nil
+	case "p":
+		if text := r.inlineText(ctx, node); text != "" {
+			return []string{text}
+		}
+		return nil
+	case "table":
+		return r.renderTable(ctx, node)
+	case "frame":
+		return r.renderFrame(ctx, node)
+	}
+	blocks := make([]string, 0)
+	for _, child := range node.children {
+		blocks = append(blocks, r.renderBlocks(ctx, child)...)
+	}
+	return blocks
+}
+
+func (r openDocumentTextRenderer) renderFrame(ctx context.Context, frame *openDocumentNode) []string {
+	blocks := make([]string, 0, 1)
+	if text := r.images.recognizeFrame(ctx, frame); text != "" {
+		blocks = append(blocks, text)
+	}
+	for _, child := range frame.children {
+		if child.name == "image" {
+			continue
+		}
+		blocks = append(blocks, r.renderBlocks(ctx, child)...)
+	}
+	return blocks
+}
+
+func (r openDocumentTextRenderer) renderTable(ctx context.Context, table *openDocumentNode) []string {
+	rows := collectOpenDocumentNodes(table, "table-row")
+	blocks := make([]string, 0, len(rows))
+	for _, row := range rows {
+		cells := make([]string, 0, len(row.children))
+		for _, cell := range row.children {
+			if cell.name != "table-cell" && cell.name != "covered-table-cell" {
+				continue
+			}
+			cells = append(cells, normalizeHTMLTableCellText(r.cellText(ctx, cell)))
+		}
+		if len(filterNonEmptyStrings(cells)) == 0 {
+			continue
+		}
+		blocks = append(blocks, strings.Join(trimTrailingEmptyCells(cells), " | "))
+	}
+	return blocks
+}
+
+func (r openDocumentTextRenderer) cellText(ctx context.Context, cell *openDocumentNode) string {
+	blocks := make([]string, 0, len(cell.children))
+	for _, child := range cell.children {
+		blocks = append(blocks, r.renderBlocks(ctx, child)...)
+	}
+	return strings.TrimSpace(strings.Join(filterNonEmptyStrings(blocks), "\n"))
+}
+
+func (r openDocumentTextRenderer) renderPresentation(
+	ctx context.Context,
+	body *openDocumentNode,
+	limits documentdomain.ResourceLimits,
+) (string, error) {
+	pages := collectOpenDocumentNodes(body, "page")
+	if err := documentdomain.CheckPresentationSlideCount(len(pages), limits); err != nil {
+		return "", fmt.Errorf("check odp slide count: %w", err)
+	}
+	blocks := make([]string, 0, len(pages))
+	for index, page := range pages {
+		content := make([]string, 0, len(page.children))
+		for _, child := range page.children {
+			content = append(content, r.renderBlocks(ctx, child)...)
+		}
+		content = filterNonEmptyStrings(content)
+		if len(content) == 0 {
+			continue
+		}
+		blocks = append(blocks, fmt.Sprintf("# Slide %d\n%s", index+1, strings.Join(content, "\n")))
+	}
+	return strings.Join(blocks, "\n\n"), nil
+}
+
+func (r openDocumentTextRenderer) inlineText(ctx context.Context, node *openDocumentNode) string {
+	var builder strings.Builder
+	r.writeInline(ctx, node, &builder)
+	return normalizeOpenDocumentInlineText(builder.String())
+}
+
+func (r openDocumentTextRenderer) writeInline(ctx context.Context, node *openDocumentNode, builder *strings.Builder) {
+	for _, child := range node.children {
+		switch child.name {
+		case "":
+			// ODF 段落内的换行和缩进只是 XML 排版，真实空白由 text:s / text:tab / text:line-break 表达。
+			builder.WriteString(strings.NewReplacer("\r", " ", "\n", " ", "\t", " ").Replace(child.text))
+		case "s":
+			builder.WriteByte(' ')
+		case "tab":
+			builder.WriteByte('\t')
+		case "line-break":
+			builder.WriteByte('\n')
+		case "frame":
+			for _, block := range r.renderFrame(ctx, child) {
+				appendInlineSegment(builder, block)
+			}
+		case "p", "h":
+			builder.WriteByte('\n')
+			r.writeInline(ctx, child, builder)
+		default:
+			if !isSkippedOpenDocumentElement(child.name) {
+				r.writeInline(ctx, child, builder)
+			}
+		}
+	}
+}
+
+func normalizeOpenDocumentInlineText(raw string) string {
+	lines := strings.Split(raw, "\n")
+	cleaned := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if normalized := strings.Join(strings.Fields(line), " "); normalized != "" {
+			cleaned = append(cleaned, normalized)
+		}
+	}
+	return strings.Join(cleaned, "\n")
+}
+
+func resolveOpenDocumentHeadingLevel(node *openDocumentNode) int {
+	level, err := strconv.Atoi(resolveXMLAttrValue(node.attrs, "outline-level"))
+	if err != nil || level < 1 {
+		return 1
+	}
+	return min(level, openDocumentMaxHeadingLevel)
+}
+
+// recognizeFrame 对 draw:frame 内的图片做 OCR；同一 frame 的多张 draw:image 是同图的替代格式，只识别一次。
+func (l *openDocumentImageLoader) recognizeFrame(ctx context.Context, frame *openDocumentNode) string {
+	if l == nil || l.ocrHelper == nil {
+		return ""
+	}
+	href := selectOpenDocumentFrameImage(frame)
+	if href == "" {
+		return ""
+	}
+	entryPath := path.Clean(strings.TrimPrefix(href, "./"))
+	data, err := readZipEntryWithEmbeddedAssetLimit(l.files, entryPath, l.limits)
+	if err != nil {
+		stats := l.ocrHelper.Stats()
+		stats.Total++
+		stats.Failed++
+		return ""
+	}
+	return l.ocrHelper.recognizeBytes(ctx, data, normalizeEmbeddedOCRFormat(path.Ext(entryPath)))
+}
+
+func selectOpenDocumentFrameImage(frame *openDocumentNode) string {
+	fallback := ""
+	for _, child := range frame.children {
+		if child.name != "image" {
+			continue
+		}
+		href := resolveXMLAttrValue(child.attrs, "href")
+		if href == "" || isHTTPURL(href) {
+			continue
+		}
+		if isEmbeddedOCRFormatSupported(path.Ext(href)) {
+			return href
+		}
+		if fallback == "" {
+			fallback = href
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,130 @@
+package docparser_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+	parser "magic/internal/infrastructure/parser"
+)
+
+const openDocumentTestNamespaces = `xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+	`xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" ` +
+	`xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" ` +
+	`xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" ` +
+	`xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0" ` +
+	`xmlns:xlink="http://www.w3.org/1999/xlink"`
+
+func TestOpenDocumentParser_ParseDocumentRendersODTHeadingsTablesAndImageOCR(t *testing.T) {
+	t.Parallel()
+
+	var image bytes.Buffer
+	writeTestPNGToBuffer(t, &image)
+	content := `<office:document-content ` + openDocumentTestNamespaces + `><office:body><office:text>
+<text:h text:outline-level="1">产品手册</text:h>
+<text:p>第一段<text:s/>正文<office:annotation><text:p>批注不应出现</text:p></office:annotation></text:p>
+<text:h text:outline-level="2">参数</text:h>
+<table:table><table:table-row><table:table-cell><text:p>名称</text:p></table:table-cell><table:table-cell><text:p>值</text:p></table:table-cell></table:table-row>
+<table:table-row><table:table-cell><text:p>电压</text:p></table:table-cell><table:table-cell><text:p>220V</text:p></table:table-cell></table:table-row></table:table>
+<text:p><draw:frame><draw:image xlink:href="Pictures/chart.png"/></draw:frame></text:p>
+</office:text></office:body></office:document-content>`
+	source := buildParserTestZip(t, map[string]string{
+		"mimetype":           "application/vnd.oasis.opendocument.text",
+		"content.xml":        content,
+		"Pictures/chart.png": image.String(),
+	})
+
+	ocr := &fakeDocxOCR{textsByType: map[string]string{"png": "图表关键词"}}
+	parsed, err := parser.NewOpenDocumentParserWithLimit(ocr, 20).
+		ParseDocument(context.Background(), "manual.odt", bytes.NewReader(source), "odt")
+	if err != nil {
+		t.Fatalf("parse odt: %v", err)
+	}
+
+	text := parsed.BestEffortText()
+	assertOrderedSubstrings(t, text, "# 产品手册", "第一段 正文", "## 参数", "名称 | 值", "电压 | 220V", "图表关键词")
+	if strings.Contains(text, "批注不应出现") {
+		t.Fatalf("expected annotation to be skipped, got %q", text)
+	}
+	if got := parsed.DocumentMeta[documentdomain.ParsedMetaEmbeddedImageOCRSuccessCount]; got != 1 {
+		t.Fatalf("unexpected OCR success count: %#v", got)
+	}
+}
+
+func TestOpenDocumentParser_ParseDocumentBuildsODSTableRows(t *testing.T) {
+	t.Parallel()
+
+	content := `<office:document-content ` + openDocumentTestNamespaces + `>
+<office:automatic-styles><style:style style:name="ta2" style:family="table"><style:table-properties table:display="false"/></style:style></office:automatic-styles>
+<office:body><office:spreadsheet>
+<table:table table:name="订单" table:style-name="ta2">
+<table:table-row><table:table-cell><text:p>订单号</text:p></table:table-cell><table:table-cell><text:p>金额</text:p></table:table-cell><table:table-cell table:number-columns-repeated="16000"/></table:table-row>
+<table:table-row><table:table-cell office:value-type="string"><text:p>SO001</text:p></table:table-cell><table:table-cell office:value-type="float" office:value="42" table:formula="of:=40+2"/></table:table-row>
+<table:table-row table:number-rows-repeated="1048574"><table:table-cell table:number-columns-repeated="16384"/></table:table-row>
+</table:table>
+</office:spreadsheet></office:body></office:document-content>`
+	source := buildParserTestZip(t, map[string]string{"content.xml": content})
+
+	parsed, err := parser.NewOpenDocumentParserWithLimit(nil, 0).
+		ParseDocument(context.Background(), "orders.ods", bytes.NewReader(source), "ods")
+	if err != nil {
+		t.Fatalf("parse ods: %v", err)
+	}
+	if len(parsed.Blocks) != 1 {
+		t.Fatalf("expected 1 row block, got %d: %#v", len(parsed.Blocks), parsed.Blocks)
+	}
+	rowBlock := parsed.Blocks[0]
+	if rowBlock.Type != documentdomain.ParsedBlockTypeTableRow {
+		t.Fatalf("expected table row block, got %q", rowBlock.Type)
+	}
+	if !strings.Contains(rowBlock.Content, "订单号：SO001") || !strings.Contains(rowBlock.Content, "金额：42") {
+		t.Fatalf("unexpected row content: %q", rowBlock.Content)
+	}
+	if rowBlock.Metadata[documentdomain.ParsedMetaSheetHidden] != true {
+		t.Fatalf("expected hidden sheet metadata, got %#v", rowBlock.Metadata)
+	}
+	if rowBlock.Metadata[documentdomain.ParsedMetaHasFormula] != true {
+		t.Fatalf("expected formula metadata, got %#v", rowBlock.Metadata)
+	}
+	cellRefs, ok := rowBlock.Metadata[documentdomain.ParsedMetaCellRefs].(map[string]string)
+	if !ok || cellRefs["订单号"] != "A2" || cellRefs["金额"] != "B2" {
+		t.Fatalf("unexpected cell refs: %#v", rowBlock.Metadata[documentdomain.ParsedMetaCellRefs])
+	}
+}
+
+func TestOpenDocumentParser_ParseDocumentRendersODPSlides(t *testing.T) {
+	t.Parallel()
+
+	content := `<office:document-content ` + openDocumentTestNamespaces + `><office:body><office:presentation>
+<draw:page draw:name="page1"><draw:frame><draw:text-box><text:p>开场</text:p></draw:text-box></draw:frame></draw:page>
+<draw:page draw:name="page2"><draw:frame><draw:text-box><text:p>路线图</text:p></draw:text-box></draw:frame>
+<presentation:notes xmlns:presentation="urn:oasis:names:tc:opendocument:xmlns:presentation:1.0"><text:p>演讲备注</text:p></presentation:notes></draw:page>
+</office:presentation></office:body></office:document-content>`
+	source := buildParserTestZip(t, map[string]string{"content.xml": content})
+
+	text, err := parser.NewOpenDocumentParserWithLimit(nil, 0).
+		Parse(context.Background(), "deck.odp", bytes.NewReader(source), "odp")
+	if err != nil {
+		t.Fatalf("parse odp: %v", err)
+	}
+	assertOrderedSubstrings(t, text, "# Slide 1", "开场", "# Slide 2", "路线图")
+	if strings.Contains(text, "演讲备注") {
+		t.Fatalf("expected speaker notes to be skipped, got %q", text)
+	}
+}
+
+func TestOpenDocumentParser_Supports(t *testing.T) {
+	t.Parallel()
+
+	p := parser.NewOpenDocumentParserWithLimit(nil, 0)
+	for _, fileType := range []string{"odt", "ODS", " odp "} {
+		if !p.Supports(fileType) {
+			t.Fatalf("expected %q supported", fileType)
+		}
+	}
+	if p.Supports("docx") {
+		t.Fatal("expected docx not supported")
+	}
+}
@@ -0,0 +1,253 @@
+package docparser
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+)
+
+type openDocumentSpreadsheetReader struct {
+	renderer   openDocumentTextRenderer
+	limits     documentdomain.ResourceLimits
+	totalRows  int64
+	totalCells int64
+}
+
+type openDocumentSheet struct {
+	name   string
+	hidden bool
+	matrix [][]tabularCell
+}
+
+// openDocumentRowBuilder 按列追加单元格；连续空单元格延迟展开，避免行尾上万个重复空列被物化。
+type openDocumentRowBuilder struct {
+	cells        []tabularCell
+	pendingBlank int
+}
+
+func buildOpenDocumentSpreadsheetDocument(
+	ctx context.Context,
+	reader *openDocumentSpreadsheetReader,
+	root *openDocumentNode,
+	fileURL string,
+	fileType string,
+	options documentdomain.ParseOptions,
+) (*documentdomain.ParsedDocument, error) {
+	sheets, err := reader.readSheets(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	if !options.TableExtraction {
+		return documentdomain.NewPlainTextParsedDocument(fileType, renderOpenDocumentSheetsAsText(sheets)), nil
+	}
+
+	sourceFormat := strings.ToLower(strings.TrimSpace(fileType))
+	tables := make([]tabularTable, 0, len(sheets))
+	for _, sheet := range sheets {
+		tables = append(tables, buildTablesFromMatrix(resolveTabularFileName(fileURL), sourceFormat, sheet.name, sheet.hidden, sheet.matrix)...)
+	}
+	if len(tables) == 0 {
+		return documentdomain.NewPlainTextParsedDocument(fileType, ""), nil
+	}
+	return buildTabularParsedDocument(sourceFormat, tables), nil
+}
+
+func (r *openDocumentSpreadsheetReader) readSheets(ctx context.Context, root *openDocumentNode) ([]openDocumentSheet, error) {
+	hiddenStyles := collectOpenDocumentHiddenTableStyles(root)
+	tables := collectOpenDocumentNodes(findOpenDocumentNode(root, "spreadsheet"), "table")
+	sheets := make([]openDocumentSheet, 0, len(tables))
+	for index, table := range tables {
+		name := resolveXMLAttrValue(table.attrs, "name")
+		if name == "" {
+			name = fmt.Sprintf("Sheet%d", index+1)
+		}
+		matrix, err := r.readMatrix(ctx, table)
+		if err != nil {
+			return nil, fmt.Errorf("read ods sheet %s: %w", name, err)
+		}
+		sheets = append(sheets, openDocumentSheet{
+			name:   name,
+			hidden: hiddenStyles[resolveXMLAttrValue(table.attrs, "style-name")],
+			matrix: matrix,
+		})
+	}
+	return sheets, nil
+}
+
+func (r *openDocumentSpreadsheetReader) readMatrix(ctx context.Context, table *openDocumentNode) ([][]tabularCell, error) {
+	rows := collectOpenDocumentNodes(table, "table-row")
+	matrix := make([][]tabularCell, 0, len(rows))
+	rowIndex := 0
+	pendingBlankRows := false
+	for _, row := range rows {
+		repeat := resolveOpenDocumentRepeat(row.attrs, "number-rows-repeated")
+		var previous []tabularCell
+		if len(matrix) > 0 {
+			previous = matrix[len(matrix)-1]
+		}
+		cells, err := r.readRowCells(ctx, row, previous)
+		if err != nil {
+			return nil, err
+		}
+		if isBlankTabularRow(cells) {
+			// 空行只保留一行作为表格分隔，重复空行（常见于行尾的 1048576 行）不展开。
+			pendingBlankRows = len(matrix) > 0
+			rowIndex += repeat
+			continue
+		}
+		if pendingBlankRows {
+			matrix = append(matrix, nil)
+			pendingBlankRows = false
+		}
+		r.totalRows += int64(repeat)
+		r.totalCells += int64(repeat) * int64(len(cells))
+		if err := documentdomain.CheckTabularSize(r.totalRows, r.totalCells, r.limits, "parse_ods_rows"); err != nil {
+			return nil, fmt.Errorf("check ods table size: %w", err)
+		}
+		for copyIndex := range repeat {
+			rowCells, err := assignOpenDocumentCellRefs(cells, rowIndex+copyIndex)
+			if err != nil {
+				return nil, err
+			}
+			matrix = append(matrix, rowCells)
+		}
+		rowIndex += repeat
+	}
+	return matrix, nil
+}
+
+func (r *openDocumentSpreadsheetReader) readRowCells(
+	ctx context.Context,
+	row *openDocumentNode,
+	previous []tabularCell,
+) ([]tabularCell, error) {
+	builder := &openDocumentRowBuilder{}
+	spanRemaining := 0
+	for _, cell := range row.children {
+		if cell.name != "table-cell" && cell.name != "covered-table-cell" {
+			continue
+		}
+		repeat := resolveOpenDocumentRepeat(cell.attrs, "number-columns-repeated")
+		if err := r.checkRowWidth(builder, repeat); err != nil {
+			return nil, err
+		}
+		if cell.name == "table-cell" {
+			builder.append(tabularCell{
+				Value:      r.readCellValue(ctx, cell),
+				HasFormula: resolveXMLAttrValue(cell.attrs, "formula") != "",
+			}, repeat)
+			spanRemaining = resolveOpenDocumentRepeat(cell.attrs, "number-columns-spanned") - 1
+			continue
+		}
+		// 合并区域被覆盖的单元格：横向合并取左侧起始单元格，纵向合并取上一行同列，与 Excel 合并单元格展开口径一致。
+		for range repeat {
+			column := len(builder.cells) + builder.pendingBlank
+			var value tabularCell
+			switch {
+			case spanRemaining > 0:
+				spanRemaining--
+				if builder.pendingBlank == 0 && len(builder.cells) > 0 {
+					value = builder.cells[len(builder.cells)-1]
+				}
+			case column < len(previous):
+				value = previous[column]
+			}
+			builder.append(tabularCell{Value: value.Value, HasFormula: value.HasFormula}, 1)
+		}
+	}
+	return builder.cells, nil
+}
+
+func (r *openDocumentSpreadsheetReader) checkRowWidth(builder *openDocumentRowBuilder, repeat int) error {
+	width := int64(len(builder.cells)) + int64(builder.pendingBlank) + int64(repeat)
+	if err := documentdomain.CheckTabularSize(r.totalRows+1, r.totalCells+width, r.limits, "parse_ods_cells"); err != nil {
+		return fmt.Errorf("check ods row width: %w", err)
+	}
+	return nil
+}
+
+func (r *openDocumentSpreadsheetReader) readCellValue(ctx context.Context, cell *openDocumentNode) string {
+	if text := r.renderer.cellText(ctx, cell); text != "" {
+		return text
+	}
+	return resolveXMLAttrValue(cell.attrs, "value")
+}
+
+func (b *openDocumentRowBuilder) append(cell tabularCell, repeat int) {
+	if strings.TrimSpace(cell.Value) == "" {
+		b.pendingBlank += repeat
+		return
+	}
+	if b.pendingBlank > 0 {
+		b.cells = append(b.cells, make([]tabularCell, b.pendingBlank)...)
+		b.pendingBlank = 0
+	}
+	for range repeat {
+		b.cells = append(b.cells, cell)
+	}
+}
+
+func assignOpenDocumentCellRefs(cells []tabularCell, rowIndex int) ([]tabularCell, error) {
+	assigned := make([]tabularCell, len(cells))
+	for colIndex, cell := range cells {
+		cellRef, err := excelize.CoordinatesToCellName(colIndex+1, rowIndex+1)
+		if err != nil {
+			return nil, fmt.Errorf("build ods cell ref failed: %w", err)
+		}
+		cell.CellRef = cellRef
+		assigned[colIndex] = cell
+	}
+	return assigned, nil
+}
+
+func resolveOpenDocumentRepeat(attrs []xml.Attr, localName string) int {
+	value, err := strconv.Atoi(resolveXMLAttrValue(attrs, localName))
+	if err != nil || value < 1 {
+		return 1
+	}
+	return value
+}
+
+// collectOpenDocumentHiddenTableStyles 返回 table:display="false" 的表样式名，用于识别隐藏 sheet。
+func collectOpenDocumentHiddenTableStyles(root *openDocumentNode) map[string]bool {
+	hidden := map[string]bool{}
+	for _, style := range collectOpenDocumentNodes(findOpenDocumentNode(root, "automatic-styles"), "style") {
+		properties := findOpenDocumentNode(style, "table-properties")
+		if properties == nil || !strings.EqualFold(resolveXMLAttrValue(properties.attrs, "display"), "false") {
+			continue
+		}
+		if name := resolveXMLAttrValue(style.attrs, "name"); name != "" {
+			hidden[name] = true
+		}
+	}
+	return hidden
+}
+
+func renderOpenDocumentSheetsAsText(sheets []openDocumentSheet) string {
+	sections := make([]string, 0, len(sheets))
+	for _, sheet := range sheets {
+		lines := make([]string, 0, len(sheet.matrix)+1)
+		lines = append(lines, "Sheet: "+sheet.name)
+		for _, row := range sheet.matrix {
+			values := make([]string, 0, len(row))
+			for _, cell := range row {
+				if trimmed := strings.TrimSpace(cell.Value); trimmed != "" {
+					values = append(values, trimmed)
+				}
+			}
+			if len(values) > 0 {
+				lines = append(lines, strings.Join(values, "\t"))
+			}
+		}
+		if len(lines) > 1 {
+			sections = append(sections, strings.Join(lines, "\n"))
+		}
+	}
+	return strings.Join(sections, "\n\n")
+}
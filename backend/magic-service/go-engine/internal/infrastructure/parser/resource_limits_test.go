@@ -72,6 +72,43 @@ func TestOfficeParsersRejectArchiveResourceLimitsBeforeBusinessParse(t *testing.
 		assertParserResourceLimit(t, err)
 	})
 
+	t.Run("opendocument picture asset too large", func(t *testing.T) {
+		t.Parallel()
+
+		source := buildParserTestZip(t, map[string]string{
+			"content.xml":         "<office:document-content/>",
+			"Pictures/image1.png": "ab",
+		})
+		limits := documentdomain.ResourceLimits{
+			MaxSourceBytes:              1024 * 1024,
+			MaxArchiveUncompressedBytes: 1024 * 1024,
+			MaxArchiveEntryBytes:        1024,
+			MaxEmbeddedAssetBytes:       1,
+			MaxPresentationSlides:       300,
+		}
+		_, err := parser.NewOpenDocumentParserWithLimit(nil, 20, limits).
+			ParseDocument(context.Background(), "asset.odt", bytes.NewReader(source), "odt")
+		assertParserResourceLimit(t, err)
+	})
+
+	t.Run("email attachments exceed uncompressed budget", func(t *testing.T) {
+		t.Parallel()
+
+		attachment := func(name string) string {
+			return "--b\r\nContent-Type: text/plain\r\nContent-Disposition: attachment; filename=\"" + name + "\"\r\n\r\n12345\r\n"
+		}
+		source := "Subject: limits\r\nContent-Type: multipart/mixed; boundary=\"b\"\r\n\r\n" +
+			attachment("a.txt") + attachment("b.txt") + "--b--\r\n"
+		limits := documentdomain.ResourceLimits{
+			MaxSourceBytes:              1024 * 1024,
+			MaxArchiveUncompressedBytes: 8,
+			MaxArchiveEntryBytes:        1024,
+		}
+		_, err := parser.NewEmailParser([]documentdomain.Parser{parser.NewPlainTextParser(limits)}, limits).
+			ParseDocument(context.Background(), "limits.eml", strings.NewReader(source), "eml")
+		assertParserResourceLimit(t, err)
+	})
+
 	t.Run("pptx slide count too large", func(t *testing.T) {
 		t.Parallel()
 
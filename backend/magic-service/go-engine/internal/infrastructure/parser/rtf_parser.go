@@ -0,0 +1,598 @@
+package docparser
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+)
+
+// RTFParser 解析 RTF 文档，按段落大纲级别与标题样式还原 Markdown 标题层级。
+type RTFParser struct {
+	visualExtractor documentdomain.VisualTextExtractor
+	maxOCRPerFile   int
+	limits          documentdomain.ResourceLimits
+}
+
+type rtfDestination int
+
+const (
+	rtfDestinationText rtfDestination = iota
+	rtfDestinationSkip
+	rtfDestinationStyleSheet
+	rtfDestinationPicture
+)
+
+const (
+	rtfSignature          = "{\\rtf"
+	rtfDefaultUnicodeSkip = 1
+	rtfUnicodeWrap        = 65536
+	rtfHexByteWidth       = 2
+	rtfCodePageGBK        = 936
+	rtfCodePageBig5       = 950
+	rtfCodePageShiftJIS   = 932
+	rtfCodePageEUCKR      = 949
+	rtfCodePageCyrillic   = 1251
+	rtfCodePageUTF8       = 65001
+	rtfMaxHeadingLevel    = 6
+)
+
+var errInvalidRTFDocument = errors.New("invalid rtf document")
+
+var rtfHeadingStyleNameRegex = regexp.MustCompile(`(?i)^(?:heading|标题)\s*([1-9])$`)
+
+type rtfStyleEntry struct {
+	index        int
+	outlineLevel int
+	name         strings.Builder
+}
+
+type rtfPicture struct {
+	format string
+	data   bytes.Buffer
+}
+
+type rtfGroupState struct {
+	destination rtfDestination
+	unicodeSkip int
+	ignorable   bool
+	style       *rtfStyleEntry
+	picture     *rtfPicture
+}
+
+type rtfDocumentReader struct {
+	data         []byte
+	pos          int
+	state        rtfGroupState
+	stack        []rtfGroupState
+	decoder      *encoding.Decoder
+	pendingBytes []byte
+	skipChars    int
+	paragraph    strings.Builder
+	paraStyle    int
+	outlineLevel int
+	styleLevels  map[int]int
+	blocks       []string
+	ocrHelper    *embeddedImageOCRHelper
+	counter      *structuredParseCounter
+	limits       documentdomain.ResourceLimits
+}
+
+// NewRTFParserWithLimit 创建带单文件 OCR 限额的 RTF 解析器。
+func NewRTFParserWithLimit(
+	ocrClient documentdomain.OCRClient,
+	maxOCRPerFile int,
+	resourceLimits ...documentdomain.ResourceLimits,
+) *RTFParser {
+	return NewRTFParserWithVisualLimit(newVisualTextExtractorFromOCR(ocrClient), maxOCRPerFile, resourceLimits...)
+}
+
+// NewRTFParserWithVisualLimit 创建带单文件视觉转文字限额的 RTF 解析器。
+func NewRTFParserWithVisualLimit(
+	visualExtractor documentdomain.VisualTextExtractor,
+	maxOCRPerFile int,
+	resourceLimits ...documentdomain.ResourceLimits,
+) *RTFParser {
+	limits := documentdomain.DefaultResourceLimits()
+	if len(resourceLimits) > 0 {
+		limits = resourceLimits[0]
+	}
+	return &RTFParser{
+		visualExtractor: visualExtractor,
+		maxOCRPerFile:   documentdomain.NormalizeEmbeddedImageOCRLimit(maxOCRPerFile),
+		limits:          documentdomain.NormalizeResourceLimits(limits),
+	}
+}
+
+// Parse 解析 RTF 文件并返回最佳努力纯文本。
+func (p *RTFParser) Parse(ctx context.Context, fileURL string, file io.Reader, fileType string) (string, error) {
+	parsed, err := p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, documentdomain.DefaultParseOptions())
+	if err != nil {
+		return "", err
+	}
+	return parsed.BestEffortText(), nil
+}
+
+// ParseWithOptions 按解析选项解析 RTF 文件。
+func (p *RTFParser) ParseWithOptions(
+	ctx context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+	options documentdomain.ParseOptions,
+) (string, error) {
+	parsed, err := p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, options)
+	if err != nil {
+		return "", err
+	}
+	return parsed.BestEffortText(), nil
+}
+
+// ParseDocument 解析 RTF 文件并返回结构化结果。
+func (p *RTFParser) ParseDocument(
+	ctx context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+) (*documentdomain.ParsedDocument, error) {
+	return p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, documentdomain.DefaultParseOptions())
+}
+
+// ParseDocumentWithOptions 按解析选项解析 RTF 文件并返回结构化结果。
+func (p *RTFParser) ParseDocumentWithOptions(
+	ctx context.Context,
+	_ string,
+	file io.Reader,
+	fileType string,
+	options documentdomain.ParseOptions,
+) (*documentdomain.ParsedDocument, error) {
+	source, err := io.ReadAll(documentdomain.NewSourceSizeLimitedReader(file, p.limits))
+	if err != nil {
+		return nil, fmt.Errorf("read rtf source failed: %w", err)
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(source), []byte(rtfSignature)) {
+		return nil, errInvalidRTFDocument
+	}
+
+	ocrHelper := newRichTextImageOCRHelper(p.visualExtractor, p.maxOCRPerFile, options)
+	reader := &rtfDocumentReader{
+		data:         bytes.TrimSpace(source),
+		state:        rtfGroupState{unicodeSkip: rtfDefaultUnicodeSkip},
+		decoder:      resolveRTFCodePageDecoder(0),
+		outlineLevel: -1,
+		styleLevels:  map[int]int{},
+		ocrHelper:    ocrHelper,
+		counter:      newStructuredParseCounter(p.limits, "parse_rtf_paragraphs"),
+		limits:       p.limits,
+	}
+	if err := reader.read(ctx); err != nil {
+		return nil, fmt.Errorf("parse rtf failed: %w", err)
+	}
+
+	parsed := documentdomain.NewPlainTextParsedDocument(fileType, strings.Join(reader.blocks, "\n\n"))
+	ocrHelper.apply(parsed)
+	if err := failIfEmptyDueToOCROverload(parsed, ocrHelper); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// Supports 检查是否支持该文件类型。
+func (p *RTFParser) Supports(fileType string) bool {
+	return strings.EqualFold(strings.TrimSpace(fileType), "rtf")
+}
+
+// NeedsResolvedURL RTF 解析只依赖文件流。
+func (p *RTFParser) NeedsResolvedURL() bool {
+	return false
+}
+
+func (r *rtfDocumentReader) read(ctx context.Context) error {
+	for r.pos < len(r.data) {
+		ch := r.data[r.pos]
+		switch ch {
+		case '{':
+			r.pos++
+			r.flushText()
+			r.stack = append(r.stack, r.state)
+			r.state.ignorable = false
+		case '}':
+			r.pos++
+			if err := r.popGroup(ctx); err != nil {
+				return err
+			}
+		case '\\':
+			if err := r.readControl(); err != nil {
+				return err
+			}
+		case '\r', '\n':
+			r.pos++
+		default:
+			r.pos++
+			r.writeByte(ch)
+		}
+	}
+	return r.endParagraph()
+}
+
+func (r *rtfDocumentReader) popGroup(ctx context.Context) error {
+	r.flushText()
+	if len(r.stack) == 0 {
+		return nil
+	}
+	closed := r.state
+	r.state = r.stack[len(r.stack)-1]
+	r.stack = r.stack[:len(r.stack)-1]
+
+	if closed.style != nil && closed.style != r.state.style {
+		r.registerStyle(closed.style)
+	}
+	if closed.picture != nil && closed.picture != r.state.picture {
+		r.pendingBytes = r.pendingBytes[:0]
+		return r.recognizePicture(ctx, closed.picture)
+	}
+	return nil
+}
+
+// registerStyle 记录标题样式：优先使用样式自带的大纲级别，其次识别 "heading N" / "标题 N" 样式名。
+func (r *rtfDocumentReader) registerStyle(style *rtfStyleEntry) {
+	if style.outlineLevel >= 0 {
+		r.styleLevels[style.index] = style.outlineLevel + 1
+		return
+	}
+	name := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(style.name.String()), ";"))
+	if matches := rtfHeadingStyleNameRegex.FindStringSubmatch(name); len(matches) == 2 {
+		level, _ := strconv.Atoi(matches[1])
+		r.styleLevels[style.index] = level
+	}
+}
+
+func (r *rtfDocumentReader) recognizePicture(ctx context.Context, picture *rtfPicture) error {
+	if err := documentdomain.CheckEmbeddedAssetSize(int64(picture.data.Len()), r.limits); err != nil {
+		return fmt.Errorf("check rtf picture size: %w", err)
+	}
+	if text := r.ocrHelper.recognizeBytes(ctx, picture.data.Bytes(), picture.format); text != "" && r.state.destination == rtfDestinationText {
+		appendInlineSegment(&r.paragraph, text)
+		r.paragraph.WriteByte(' ')
+	}
+	return nil
+}
+
+func (r *rtfDocumentReader) readControl() error {
+	r.pos++
+	if r.pos >= len(r.data) {
+		return nil
+	}
+	ch := r.data[r.pos]
+	if !isRTFASCIILetter(ch) {
+		r.pos++
+		return r.handleControlSymbol(ch)
+	}
+
+	start := r.pos
+	for r.pos < len(r.data) && isRTFASCIILetter(r.data[r.pos]) {
+		r.pos++
+	}
+	word := string(r.data[start:r.pos])
+	paramStart := r.pos
+	if r.pos < len(r.data) && r.data[r.pos] == '-' {
+		r.pos++
+	}
+	for r.pos < len(r.data) && r.data[r.pos] >= '0' && r.data[r.pos] <= '9' {
+		r.pos++
+	}
+	param, paramErr := strconv.Atoi(string(r.data[paramStart:r.pos]))
+	if r.pos < len(r.data) && r.data[r.pos] == ' ' {
+		r.pos++
+	}
+	return r.handleControlWord(word, param, paramErr == nil)
+}
+
+func (r *rtfDocumentReader) handleControlSymbol(symbol byte) error {
+	switch symbol {
+	case '\\', '{', '}':
+		r.writeByte(symbol)
+	case '\'':
+		if r.pos+rtfHexByteWidth > len(r.data) {
+			return nil
+		}
+		decoded, err := hex.DecodeString(string(r.data[r.pos : r.pos+rtfHexByteWidth]))
+		r.pos += rtfHexByteWidth
+		if err == nil {
+			r.writeByte(decoded[0])
+		}
+	case '~':
+		r.writeText(" ")
+	case '_':
+		r.writeText("-")
+	case '*':
+		r.state.ignorable = true
+	case '\r', '\n':
+		return r.endParagraph()
+	}
+	return nil
+}
+
+func (r *rtfDocumentReader) handleControlWord(word string, param int, hasParam bool) error {
+	r.flushText()
+	if r.state.ignorable {
+		r.state.ignorable = false
+		// \* 标记的可忽略目标中只保留装载图片的 shppict，其余（书签、域代码、元数据等）整组跳过。
+		if word != "shppict" {
+			r.state.destination = rtfDestinationSkip
+			return nil
+		}
+	}
+	if text, ok := resolveRTFSpecialCharacter(word); ok {
+		r.writeText(text)
+		return nil
+	}
+	if isSkippedRTFDestination(word) {
+		r.state.destination = rtfDestinationSkip
+		return nil
+	}
+
+	switch word {
+	case "par", "sect", "page", "row", "nestrow":
+		return r.endParagraph()
+	case "pard":
+		r.paraStyle = 0
+		r.outlineLevel = -1
+	case "s":
+		r.handleStyleControl(param)
+	case "outlinelevel":
+		if r.state.destination == rtfDestinationStyleSheet && r.state.style != nil {
+			r.state.style.outlineLevel = param
+			return nil
+		}
+		r.outlineLevel = param
+	case "u":
+		if param < 0 {
+			param += rtfUnicodeWrap
+		}
+		r.writeText(string(rune(param)))
+		r.skipChars = r.state.unicodeSkip
+	case "uc":
+		if hasParam && param >= 0 {
+			r.state.unicodeSkip = param
+		}
+	case "ansicpg":
+		r.decoder = resolveRTFCodePageDecoder(param)
+	case "bin":
+		r.skipBinary(param)
+	default:
+		r.handlePictureControl(word)
+	}
+	return nil
+}
+
+func (r *rtfDocumentReader) handleStyleControl(index int) {
+	if r.state.destination == rtfDestinationStyleSheet {
+		r.state.style = &rtfStyleEntry{index: index, outlineLevel: -1}
+		return
+	}
+	r.paraStyle = index
+}
+
+func (r *rtfDocumentReader) handlePictureControl(word string) {
+	switch word {
+	case "stylesheet":
+		r.state.destination = rtfDestinationStyleSheet
+	case "pict":
+		if r.ocrHelper == nil {
+			r.state.destination = rtfDestinationSkip
+			return
+		}
+		r.state.destination = rtfDestinationPicture
+		r.state.picture = &rtfPicture{}
+	case "pngblip":
+		if r.state.picture != nil {
+			r.state.picture.format = embeddedOCRFormatPNG
+		}
+	case "jpegblip":
+		if r.state.picture != nil {
+			r.state.picture.format = embeddedOCRFormatJPG
+		}
+	}
+}
+
+func (r *rtfDocumentReader) skipBinary(size int) {
+	end := min(len(r.data), r.pos+max(size, 0))
+	if r.state.destination == rtfDestinationPicture && r.state.picture != nil {
+		r.state.picture.data.Write(r.data[r.pos:end])
+	}
+	r.pos = end
+}
+
+func (r *rtfDocumentReader) writeByte(ch byte) {
+	if r.skipChars > 0 {
+		r.skipChars--
+		return
+	}
+	switch r.state.destination {
+	case rtfDestinationText, rtfDestinationStyleSheet:
+		r.pendingBytes = append(r.pendingBytes, ch)
+	case rtfDestinationPicture:
+		r.appendPictureHex(ch)
+	case rtfDestinationSkip:
+	}
+}
+
+// appendPictureHex 把 \pict 中的十六进制图片数据按字节对解码。
+func (r *rtfDocumentReader) appendPictureHex(ch byte) {
+	if r.state.picture == nil || !isRTFHexDigit(ch) {
+		return
+	}
+	r.pendingBytes = append(r.pendingBytes, ch)
+	if len(r.pendingBytes) < rtfHexByteWidth {
+		return
+	}
+	if decoded, err := hex.DecodeString(string(r.pendingBytes[:rtfHexByteWidth])); err == nil {
+		r.state.picture.data.Write(decoded)
+	}
+	r.pendingBytes = r.pendingBytes[:0]
+}
+
+func (r *rtfDocumentReader) writeText(text string) {
+	if r.skipChars > 0 {
+		r.skipChars--
+		return
+	}
+	r.flushText()
+	r.appendDecoded(text)
+}
+
+func (r *rtfDocumentReader) flushText() {
+	if len(r.pendingBytes) == 0 || r.state.destination == rtfDestinationPicture {
+		return
+	}
+	// 部分生成器直接写入 UTF-8 原文而非 \'hh 转义；旧代码页的多字节序列极少恰好是合法 UTF-8。
+	if isRTFRawUTF8(r.pendingBytes) {
+		r.appendDecoded(string(r.pendingBytes))
+		r.pendingBytes = r.pendingBytes[:0]
+		return
+	}
+	decoded, err := r.decoder.Bytes(r.pendingBytes)
+	if err != nil {
+		decoded = r.pendingBytes
+	}
+	r.appendDecoded(string(decoded))
+	r.pendingBytes = r.pendingBytes[:0]
+}
+
+func (r *rtfDocumentReader) appendDecoded(text string) {
+	switch r.state.destination {
+	case rtfDestinationText:
+		r.paragraph.WriteString(text)
+	case rtfDestinationStyleSheet:
+		if r.state.style != nil {
+			r.state.style.name.WriteString(text)
+		}
+	case rtfDestinationSkip, rtfDestinationPicture:
+	}
+}
+
+func (r *rtfDocumentReader) endParagraph() error {
+	r.flushText()
+	if r.state.destination != rtfDestinationText {
+		return nil
+	}
+	text := normalizeRTFParagraphText(r.paragraph.String())
+	r.paragraph.Reset()
+	if text == "" {
+		return nil
+	}
+	if err := r.counter.observe(); err != nil {
+		return err
+	}
+	level := r.styleLevels[r.paraStyle]
+	if r.outlineLevel >= 0 {
+		level = r.outlineLevel + 1
+	}
+	if level > 0 {
+		text = strings.Repeat("#", min(level, rtfMaxHeadingLevel)) + " " + strings.ReplaceAll(text, "\n", " ")
+	}
+	r.blocks = append(r.blocks, text)
+	return nil
+}
+
+func normalizeRTFParagraphText(raw string) string {
+	lines := strings.Split(raw, "\n")
+	cleaned := make([]string, 0, len(lines))
+	for _, line := range lines {
+		normalized := strings.TrimSpace(strings.TrimSuffix(strings.Join(strings.Fields(line), " "), "|"))
+		if normalized != "" {
+			cleaned = append(cleaned, normalized)
+		}
+	}
+	return strings.Join(cleaned, "\n")
+}
+
+func resolveRTFSpecialCharacter(word string) (string, bool) {
+	switch word {
+	case "line":
+		return "\n", true
+	case "tab":
+		return "\t", true
+	case "cell", "nestcell":
+		return " | ", true
+	case "emdash":
+		return "—", true
+	case "endash":
+		return "–", true
+	case "bullet":
+		return "•", true
+	case "lquote":
+		return "‘", true
+	case "rquote":
+		return "’", true
+	case "ldblquote":
+		return "“", true
+	case "rdblquote":
+		return "”", true
+	case "emspace", "enspace", "qmspace":
+		return " ", true
+	default:
+		return "", false
+	}
+}
+
+// isSkippedRTFDestination 列出不属于正文的目标组：字体/颜色/列表表、文档信息、页眉页脚、脚注和非 PNG/JPEG 图片副本等。
+func isSkippedRTFDestination(word string) bool {
+	switch word {
+	case "fonttbl", "colortbl", "info", "header", "headerl", "headerr", "headerf", "footer", "footerl", "footerr",
+		"footerf", "footnote", "annotation", "fldinst", "object", "objdata", "nonshppict", "listtable",
+		"listoverridetable", "rsidtbl", "xmlnstbl", "generator", "themedata", "colorschememapping", "datastore",
+		"latentstyles", "pgdsctbl", "filetbl", "revtbl", "pntext", "pntxta", "pntxtb":
+		return true
+	default:
+		return false
+	}
+}
+
+func resolveRTFCodePageDecoder(codePage int) *encoding.Decoder {
+	switch codePage {
+	case rtfCodePageGBK:
+		return simplifiedchinese.GBK.NewDecoder()
+	case rtfCodePageBig5:
+		return traditionalchinese.Big5.NewDecoder()
+	case rtfCodePageShiftJIS:
+		return japanese.ShiftJIS.NewDecoder()
+	case rtfCodePageEUCKR:
+		return korean.EUCKR.NewDecoder()
+	case rtfCodePageCyrillic:
+		return charmap.Windows1251.NewDecoder()
+	case rtfCodePageUTF8:
+		return encoding.Nop.NewDecoder()
+	default:
+		return charmap.Windows1252.NewDecoder()
+	}
+}
+
+func isRTFRawUTF8(data []byte) bool {
+	return utf8.Valid(data) && slices.ContainsFunc(data, func(ch byte) bool { return ch >= utf8.RuneSelf })
+}
+
+func isRTFASCIILetter(ch byte) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isRTFHexDigit(ch byte) bool {
+	return (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
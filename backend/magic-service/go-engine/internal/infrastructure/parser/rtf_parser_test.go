@@ -0,0 +1,73 @@
+package docparser_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	parser "magic/internal/infrastructure/parser"
+)
+
+func TestRTFParser_ParseDocumentDecodesCodePageAndStyleHeadings(t *testing.T) {
+	t.Parallel()
+
+	source := `{\rtf1\ansi\ansicpg936\deff0` +
+		`{\fonttbl{\f0\fnil\fcharset134 SimSun;}}` +
+		`{\colortbl;\red0\green0\blue0;}` +
+		`{\stylesheet{\s0 Normal;}{\s1\outlinelevel0 heading 1;}{\s2 标题 2;}}` +
+		`{\info{\title 元数据标题}}` +
+		`\pard\s1 \'b8\'c5\'ca\'f6\par` +
+		`\pard\s0 \'c4\'e3\'ba\'c3\u19990?\par` +
+		`\pard\s2 Details\par` +
+		`\pard col1\cell col2\cell\row` +
+		`{\*\generator Writer;}` +
+		`}`
+
+	text, err := parser.NewRTFParserWithLimit(nil, 0).
+		Parse(context.Background(), "demo.rtf", strings.NewReader(source), "rtf")
+	if err != nil {
+		t.Fatalf("parse rtf: %v", err)
+	}
+
+	assertOrderedSubstrings(t, text, "# 概述", "你好世", "## Details", "col1 | col2")
+	for _, unexpected := range []string{"SimSun", "元数据标题", "Writer", "Normal"} {
+		if strings.Contains(text, unexpected) {
+			t.Fatalf("expected %q to be skipped, got %q", unexpected, text)
+		}
+	}
+}
+
+func TestRTFParser_ParseDocumentOCRsEmbeddedPictures(t *testing.T) {
+	t.Parallel()
+
+	var image bytes.Buffer
+	writeTestPNGToBuffer(t, &image)
+	source := `{\rtf1\ansi\pard 正文前\par` +
+		`{\*\shppict{\pict\pngblip\picw2\pich2 ` + hex.EncodeToString(image.Bytes()) + `}}` +
+		`{\nonshppict{\pict\wmetafile8 0102}}` +
+		`\pard 正文后\par}`
+
+	ocr := &fakeDocxOCR{textsByType: map[string]string{"png": "图片关键词"}}
+	text, err := parser.NewRTFParserWithLimit(ocr, 20).
+		Parse(context.Background(), "picture.rtf", strings.NewReader(source), "rtf")
+	if err != nil {
+		t.Fatalf("parse rtf: %v", err)
+	}
+
+	assertOrderedSubstrings(t, text, "正文前", "图片关键词", "正文后")
+	if ocr.callCount != 1 || len(ocr.lastTypes) != 1 || ocr.lastTypes[0] != "png" {
+		t.Fatalf("expected one png OCR call, got %d %#v", ocr.callCount, ocr.lastTypes)
+	}
+}
+
+func TestRTFParser_ParseDocumentRejectsNonRTF(t *testing.T) {
+	t.Parallel()
+
+	_, err := parser.NewRTFParserWithLimit(nil, 0).
+		Parse(context.Background(), "fake.rtf", strings.NewReader("plain text"), "rtf")
+	if err == nil {
+		t.Fatal("expected invalid rtf error")
+	}
+}
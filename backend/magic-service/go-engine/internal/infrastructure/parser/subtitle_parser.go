@@ -0,0 +1,270 @@
+package docparser
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+)
+
+const (
+	// subtitleWindowDurationMS 是合并字幕的时间窗口长度，窗口足够短才能让命中结果精确跳转到录像位置。
+	subtitleWindowDurationMS int64 = 60_000
+	// subtitleWindowMaxRunes 防止语速很快的窗口过长，超过后提前切出新窗口。
+	subtitleWindowMaxRunes = 1200
+
+	subtitleMSPerSecond = 1000
+	subtitleMSPerMinute = 60 * subtitleMSPerSecond
+	subtitleMSPerHour   = 60 * subtitleMSPerMinute
+	// subtitleFractionDigits 是毫秒字段的标准位数，不足三位的写法（如 00:01.5）按小数补齐。
+	subtitleFractionDigits = 3
+)
+
+var (
+	subtitleTimestampPattern = regexp.MustCompile(`^(?:(\d+):)?(\d{1,2}):(\d{2})(?:[,.](\d{1,3}))?`)
+	subtitleVoicePattern     = regexp.MustCompile(`<v(?:\.[\w.-]+)?\s+([^>]+)>`)
+	subtitleTagPattern       = regexp.MustCompile(`<[^>]*>|\{\\[^}]*\}`)
+	subtitleCueSeparator     = regexp.MustCompile(`\n[ \t]*\n`)
+	utf8BOM                  = []byte{0xEF, 0xBB, 0xBF}
+)
+
+// SubtitleParser 解析 SRT / WebVTT 字幕，相邻字幕按时间窗口合并为带 start_ms/end_ms 的块。
+type SubtitleParser struct {
+	limits documentdomain.ResourceLimits
+}
+
+type subtitleCue struct {
+	startMS int64
+	endMS   int64
+	text    string
+}
+
+type subtitleWindow struct {
+	startMS int64
+	endMS   int64
+	lines   []string
+	runes   int
+}
+
+// NewSubtitleParser 创建字幕解析器。
+func NewSubtitleParser(resourceLimits ...documentdomain.ResourceLimits) *SubtitleParser {
+	limits := documentdomain.DefaultResourceLimits()
+	if len(resourceLimits) > 0 {
+		limits = resourceLimits[0]
+	}
+	return &SubtitleParser{limits: documentdomain.NormalizeResourceLimits(limits)}
+}
+
+// Parse 解析字幕并返回最佳努力纯文本。
+func (p *SubtitleParser) Parse(ctx context.Context, fileURL string, file io.Reader, fileType string) (string, error) {
+	parsed, err := p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, documentdomain.DefaultParseOptions())
+	if err != nil {
+		return "", err
+	}
+	return parsed.BestEffortText(), nil
+}
+
+// ParseWithOptions 按解析选项解析字幕。
+func (p *SubtitleParser) ParseWithOptions(
+	ctx context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+	options documentdomain.ParseOptions,
+) (string, error) {
+	parsed, err := p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, options)
+	if err != nil {
+		return "", err
+	}
+	return parsed.BestEffortText(), nil
+}
+
+// ParseDocument 解析字幕并返回结构化结果。
+func (p *SubtitleParser) ParseDocument(
+	ctx context.Context,
+	fileURL string,
+	file io.Reader,
+	fileType string,
+) (*documentdomain.ParsedDocument, error) {
+	return p.ParseDocumentWithOptions(ctx, fileURL, file, fileType, documentdomain.DefaultParseOptions())
+}
+
+// ParseDocumentWithOptions 按解析选项解析字幕并返回结构化结果。
+//
+// SRT 与 VTT 都按空行分隔的 cue 解析，只认含 "-->" 的 cue，VTT 的 NOTE/STYLE/REGION 自然被跳过；
+// 非 UTF-8 内容按 GB18030 解码，兼容常见的中文字幕文件。
+func (p *SubtitleParser) ParseDocumentWithOptions(
+	_ context.Context,
+	_ string,
+	file io.Reader,
+	fileType string,
+	_ documentdomain.ParseOptions,
+) (*documentdomain.ParsedDocument, error) {
+	source, err := io.ReadAll(documentdomain.NewSourceSizeLimitedReader(file, p.limits))
+	if err != nil {
+		return nil, fmt.Errorf("read subtitle failed: %w", err)
+	}
+	if err := documentdomain.CheckPlainTextBytes(source, p.limits, "parse_subtitle"); err != nil {
+		return nil, fmt.Errorf("check subtitle size: %w", err)
+	}
+	content := decodeSubtitleText(source)
+	cues := parseSubtitleCues(content)
+	if len(cues) == 0 {
+		return documentdomain.NewPlainTextParsedDocument(fileType, strings.TrimSpace(content)), nil
+	}
+
+	counter := newStructuredParseCounter(p.limits, "parse_subtitle_windows")
+	windows := mergeSubtitleCues(cues)
+	blocks := make([]documentdomain.ParsedBlock, 0, len(windows))
+	texts := make([]string, 0, len(windows))
+	for _, window := range windows {
+		if err := counter.observe(); err != nil {
+			return nil, err
+		}
+		text := strings.Join(window.lines, "\n")
+		blocks = append(blocks, documentdomain.ParsedBlock{
+			Type:    documentdomain.ParsedBlockTypeSubtitleWindow,
+			Content: text,
+			Metadata: map[string]any{
+				documentdomain.ParsedMetaChunkType:        documentdomain.ParsedBlockTypeSubtitleWindow,
+				documentdomain.ParsedMetaStartMS:          window.startMS,
+				documentdomain.ParsedMetaEndMS:            window.endMS,
+				documentdomain.ParsedMetaSubtitleCueCount: len(window.lines),
+			},
+		})
+		texts = append(texts, text)
+	}
+	return &documentdomain.ParsedDocument{
+		SourceType: documentdomain.ParsedDocumentSourceSubtitle,
+		PlainText:  strings.Join(texts, "\n\n"),
+		Blocks:     blocks,
+		DocumentMeta: map[string]any{
+			documentdomain.ParsedMetaSourceFormat: strings.ToLower(strings.TrimSpace(fileType)),
+			"subtitle_cue_count":                  len(cues),
+			"subtitle_duration_ms":                cues[len(cues)-1].endMS,
+		},
+	}, nil
+}
+
+// Supports 检查是否支持该文件类型。
+func (p *SubtitleParser) Supports(fileType string) bool {
+	switch strings.ToLower(strings.TrimSpace(fileType)) {
+	case "srt", "vtt":
+		return true
+	default:
+		return false
+	}
+}
+
+// NeedsResolvedURL 字幕解析只依赖文件流。
+func (p *SubtitleParser) NeedsResolvedURL() bool {
+	return false
+}
+
+func decodeSubtitleText(source []byte) string {
+	source = bytes.TrimPrefix(source, utf8BOM)
+	if !utf8.Valid(source) {
+		if decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(source); err == nil {
+			source = decoded
+		}
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(string(source), "\r\n", "\n"), "\r", "\n")
+}
+
+func parseSubtitleCues(content string) []subtitleCue {
+	cues := make([]subtitleCue, 0)
+	for _, block := range subtitleCueSeparator.Split(content, -1) {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+		for index, line := range lines {
+			startMS, endMS, ok := parseSubtitleTiming(line)
+			if !ok {
+				continue
+			}
+			if text := cleanSubtitleText(lines[index+1:]); text != "" {
+				cues = append(cues, subtitleCue{startMS: startMS, endMS: endMS, text: text})
+			}
+			break
+		}
+	}
+	return cues
+}
+
+// parseSubtitleTiming 解析 "00:00:01,000 --> 00:00:04,000" 形式的时间行，VTT 行尾的位置设置会被忽略。
+func parseSubtitleTiming(line string) (int64, int64, bool) {
+	left, right, found := strings.Cut(line, "-->")
+	if !found {
+		return 0, 0, false
+	}
+	startMS, ok := parseSubtitleTimestamp(strings.TrimSpace(left))
+	if !ok {
+		return 0, 0, false
+	}
+	endMS, ok := parseSubtitleTimestamp(strings.TrimSpace(right))
+	if !ok {
+		return 0, 0, false
+	}
+	return startMS, max(endMS, startMS), true
+}
+
+func parseSubtitleTimestamp(value string) (int64, bool) {
+	match := subtitleTimestampPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, false
+	}
+	hours, _ := strconv.ParseInt(cmp.Or(match[1], "0"), 10, 64)
+	minutes, _ := strconv.ParseInt(match[2], 10, 64)
+	seconds, _ := strconv.ParseInt(match[3], 10, 64)
+	fraction := match[4]
+	if fraction != "" {
+		fraction += strings.Repeat("0", subtitleFractionDigits-len(fraction))
+	}
+	millis, _ := strconv.ParseInt(cmp.Or(fraction, "0"), 10, 64)
+	return hours*subtitleMSPerHour + minutes*subtitleMSPerMinute + seconds*subtitleMSPerSecond + millis, true
+}
+
+// cleanSubtitleText 把 VTT 说话人标签转为 "说话人: " 前缀，去掉其余样式标签和 ASS 风格的 {\an8} 控制符。
+func cleanSubtitleText(lines []string) string {
+	cleaned := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = subtitleVoicePattern.ReplaceAllString(line, "$1: ")
+		line = html.UnescapeString(subtitleTagPattern.ReplaceAllString(line, ""))
+		if trimmed := strings.Join(strings.Fields(line), " "); trimmed != "" {
+			cleaned = append(cleaned, trimmed)
+		}
+	}
+	return strings.Join(cleaned, " ")
+}
+
+// mergeSubtitleCues 按时间窗口合并相邻字幕；滚动字幕中与上一条重复的文本只保留一次。
+func mergeSubtitleCues(cues []subtitleCue) []subtitleWindow {
+	windows := make([]subtitleWindow, 0)
+	previous := ""
+	for _, cue := range cues {
+		last := len(windows) - 1
+		if last >= 0 && cue.text == previous {
+			windows[last].endMS = max(windows[last].endMS, cue.endMS)
+			continue
+		}
+		previous = cue.text
+		runes := utf8.RuneCountInString(cue.text)
+		if last < 0 || cue.startMS-windows[last].startMS >= subtitleWindowDurationMS ||
+			windows[last].runes+runes > subtitleWindowMaxRunes {
+			windows = append(windows, subtitleWindow{startMS: cue.startMS})
+			last++
+		}
+		windows[last].lines = append(windows[last].lines, cue.text)
+		windows[last].runes += runes
+		windows[last].endMS = max(windows[last].endMS, cue.endMS)
+	}
+	return windows
+}
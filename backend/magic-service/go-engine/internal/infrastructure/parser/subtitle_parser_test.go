@@ -0,0 +1,115 @@
+package docparser_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+
+	documentdomain "magic/internal/domain/knowledge/document/metadata"
+	parser "magic/internal/infrastructure/parser"
+)
+
+const subtitleTestSRT = "\xef\xbb\xbf1\r\n" +
+	"00:00:01,000 --> 00:00:04,500\r\n" +
+	"<i>大家好</i>，欢迎参加周会\r\n" +
+	"\r\n" +
+	"2\r\n" +
+	"00:00:05,000 --> 00:00:08,000\r\n" +
+	"{\\an8}先过一下上周的进度\r\n" +
+	"\r\n" +
+	"3\r\n" +
+	"00:01:02,000 --> 00:01:06,250\r\n" +
+	"接下来讨论发布计划\r\n" +
+	"第二行 &amp; 补充\r\n"
+
+const subtitleTestVTT = `WEBVTT - meeting
+
+NOTE 这一段是注释 不应入库
+
+STYLE
+::cue { color: red }
+
+intro
+00:00.500 --> 00:03.000 align:start position:10%
+<v Alice>Kickoff for the launch</v>
+
+00:03.000 --> 00:05.000
+<v.loud Bob>Thanks Alice
+
+00:05.000 --> 00:06.000
+<v.loud Bob>Thanks Alice
+
+01:00:00.000 --> 01:00:02.000
+<c.yellow>Wrap up</c>
+`
+
+func TestSubtitleParser_ParseSRTMergesCuesIntoTimeWindows(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := parser.NewSubtitleParser().ParseDocument(context.Background(), "weekly.srt", strings.NewReader(subtitleTestSRT), "srt")
+	if err != nil {
+		t.Fatalf("parse srt: %v", err)
+	}
+	if parsed.SourceType != documentdomain.ParsedDocumentSourceSubtitle || len(parsed.Blocks) != 2 {
+		t.Fatalf("unexpected parsed document: %#v", parsed)
+	}
+	assertSubtitleBlock(t, parsed.Blocks[0], "大家好，欢迎参加周会\n先过一下上周的进度", 1000, 8000, 2)
+	assertSubtitleBlock(t, parsed.Blocks[1], "接下来讨论发布计划 第二行 & 补充", 62000, 66250, 1)
+}
+
+func TestSubtitleParser_ParseVTTHandlesSpeakersNotesAndDuplicates(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := parser.NewSubtitleParser().ParseDocument(context.Background(), "meeting.vtt", strings.NewReader(subtitleTestVTT), "vtt")
+	if err != nil {
+		t.Fatalf("parse vtt: %v", err)
+	}
+	if len(parsed.Blocks) != 2 || strings.Contains(parsed.PlainText, "注释") || strings.Contains(parsed.PlainText, "color") {
+		t.Fatalf("unexpected parsed document: %#v", parsed)
+	}
+	assertSubtitleBlock(t, parsed.Blocks[0], "Alice: Kickoff for the launch\nBob: Thanks Alice", 500, 6000, 2)
+	assertSubtitleBlock(t, parsed.Blocks[1], "Wrap up", 3600000, 3602000, 1)
+}
+
+func TestSubtitleParser_DecodesGBKAndFallsBackToPlainText(t *testing.T) {
+	t.Parallel()
+
+	encoded, err := simplifiedchinese.GBK.NewEncoder().String("1\n00:00:01,000 --> 00:00:02,000\n中文字幕\n")
+	if err != nil {
+		t.Fatalf("encode gbk: %v", err)
+	}
+	subtitleParser := parser.NewSubtitleParser()
+	parsed, err := subtitleParser.ParseDocument(context.Background(), "cn.srt", strings.NewReader(encoded), "srt")
+	if err != nil {
+		t.Fatalf("parse gbk srt: %v", err)
+	}
+	if len(parsed.Blocks) != 1 || parsed.Blocks[0].Content != "中文字幕" {
+		t.Fatalf("unexpected gbk subtitle: %#v", parsed)
+	}
+
+	plain, err := subtitleParser.ParseDocument(context.Background(), "notes.srt", strings.NewReader("just some text"), "srt")
+	if err != nil {
+		t.Fatalf("parse plain srt: %v", err)
+	}
+	if plain.SourceType != documentdomain.ParsedDocumentSourceText || plain.PlainText != "just some text" {
+		t.Fatalf("expected plain text fallback, got %#v", plain)
+	}
+	if !subtitleParser.Supports("VTT") || subtitleParser.Supports("ass") {
+		t.Fatal("unexpected subtitle support result")
+	}
+}
+
+func assertSubtitleBlock(t *testing.T, block documentdomain.ParsedBlock, content string, startMS, endMS int64, cues int) {
+	t.Helper()
+
+	if block.Type != documentdomain.ParsedBlockTypeSubtitleWindow || block.Content != content {
+		t.Fatalf("unexpected subtitle block content: %#v", block)
+	}
+	if block.Metadata[documentdomain.ParsedMetaStartMS] != startMS ||
+		block.Metadata[documentdomain.ParsedMetaEndMS] != endMS ||
+		block.Metadata[documentdomain.ParsedMetaSubtitleCueCount] != cues {
+		t.Fatalf("unexpected subtitle block metadata: %#v", block.Metadata)
+	}
+}
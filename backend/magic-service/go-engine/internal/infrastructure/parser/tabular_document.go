@@ -1191,7 +1191,7 @@ func shouldBuildTabularSummary(sourceFormat string) bool {
 
 func isExcelLikeTabularSource(sourceFormat string) bool {
 	switch strings.ToLower(strings.TrimSpace(sourceFormat)) {
-	case tabularFormatXLSX, "xlsm", "xls":
+	case tabularFormatXLSX, "xlsm", "xls", openDocumentFormatSpreadsheet:
 		return true
 	default:
 		return false
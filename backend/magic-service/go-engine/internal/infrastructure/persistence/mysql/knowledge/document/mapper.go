@@ -294,7 +294,8 @@ func applyLegacySourceCompatibility(doc *docentity.KnowledgeBaseDocument, provid
 		if doc.ProjectFileID == 0 {
 			doc.ProjectFileID = parseInt64(itemRef)
 		}
-	case "", sourcebindingentity.ProviderLocalUpload:
+	case "", sourcebindingentity.ProviderLocalUpload, sourcebindingentity.ProviderWebsite, sourcebindingentity.ProviderGit,
+		sourcebindingentity.ProviderObjectStorage, sourcebindingentity.ProviderWebhook:
 		return
 	default:
 		if doc.ThirdPlatformType == "" {
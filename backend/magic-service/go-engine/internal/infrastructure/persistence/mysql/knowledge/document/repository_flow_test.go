@@ -605,7 +605,7 @@ func TestDocumentRepositoryCountByKnowledgeBaseCodesExtractsExtensionWithoutFull
 		WillReturnRows(sqlmock.NewRows([]string{"knowledge_base_code", "document_file"}).
 			AddRow("KB1", mustJSON(t, map[string]any{"third_file_extension_name": "docx"})).
 			AddRow("KB1", mustJSON(t, map[string]any{"name": "report.pdf"})).
-			AddRow("KB2", mustJSON(t, map[string]any{"url": "https://example.com/demo.js"})).
+			AddRow("KB2", mustJSON(t, map[string]any{"url": "https://example.com/demo.svg"})).
 			AddRow("KB2", mustJSON(t, map[string]any{"file_link": map[string]any{"url": "https://example.com/slides.pptx"}})).
 			AddRow("KB3", mustJSON(t, map[string]any{"name": "legacy"})),
 		)
@@ -670,8 +670,8 @@ func TestDocumentRepositoryListFiltersUnsupportedExtensionsInRepository(t *testi
 	repo, mock := testCtx.repo, testCtx.mock
 	visibleRow := sampleDocumentRowValues(t)
 	hiddenRow := sampleDocumentRowValues(t)
-	hiddenRow[8] = "DOC-JS"
-	hiddenRow[13] = mustJSON(t, map[string]any{"name": "demo.js", "url": "bucket/demo.js", "extension": "js"})
+	hiddenRow[8] = "DOC-SVG"
+	hiddenRow[13] = mustJSON(t, map[string]any{"name": "demo.svg", "url": "bucket/demo.svg", "extension": "svg"})
 
 	mock.ExpectQuery(sqlContains("ListDocumentsByOrganization")).
 		WillReturnRows(sqlmock.NewRows(documentRowColumns()).
@@ -1157,7 +1157,7 @@ func expectDocumentCountByKnowledgeBaseCodes(t *testing.T, mock sqlmock.Sqlmock)
 		WithArgs("ORG1", "KB1", "KB2").
 		WillReturnRows(sqlmock.NewRows([]string{"knowledge_base_code", "document_file"}).
 			AddRow("KB1", mustJSON(t, map[string]any{"name": "ok.md", "extension": "md"})).
-			AddRow("KB1", mustJSON(t, map[string]any{"name": "code.js", "extension": "js"})).
+			AddRow("KB1", mustJSON(t, map[string]any{"name": "icon.svg", "extension": "svg"})).
 			AddRow("KB2", mustJSON(t, map[string]any{"name": "legacy", "extension": ""})),
 		)
 }
@@ -0,0 +1,345 @@
+// Package evaluationrepo 提供知识库检索评测在 MySQL 上的仓储实现。
+package evaluationrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	evalentity "magic/internal/domain/knowledge/evaluation/entity"
+	evalrepository "magic/internal/domain/knowledge/evaluation/repository"
+	"magic/internal/infrastructure/logging"
+	mysqlclient "magic/internal/infrastructure/persistence/mysql"
+	mysqlsqlc "magic/internal/infrastructure/persistence/mysql/sqlc"
+	"magic/pkg/convert"
+)
+
+var errEvaluationRepositoryNil = errors.New("evaluation repository is nil")
+
+// Repository 实现检索评测 MySQL 仓储。
+type Repository struct {
+	client *mysqlclient.SQLCClient
+	logger *logging.SugaredLogger
+}
+
+var _ evalrepository.Repository = (*Repository)(nil)
+
+// NewRepository 创建检索评测仓储。
+func NewRepository(client *mysqlclient.SQLCClient, logger *logging.SugaredLogger) *Repository {
+	return &Repository{client: client, logger: logger}
+}
+
+// ReplaceGoldenQueries 在事务内删除评测集旧 query 并写入新 query。
+func (r *Repository) ReplaceGoldenQueries(
+	ctx context.Context,
+	scope evalrepository.QuerySetScope,
+	queries []evalentity.GoldenQuery,
+) (saved []*evalentity.GoldenQuery, err error) {
+	if r == nil || r.client == nil {
+		return nil, errEvaluationRepositoryNil
+	}
+
+	tx, err := r.client.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin replace evaluation queries tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	txQueries := r.client.Q().WithTx(tx)
+	if _, err = txQueries.DeleteKnowledgeBaseEvalQueriesBySet(ctx, mysqlsqlc.DeleteKnowledgeBaseEvalQueriesBySetParams{
+		OrganizationCode:  scope.OrganizationCode,
+		KnowledgeBaseCode: scope.KnowledgeBaseCode,
+		SetCode:           scope.SetCode,
+	}); err != nil {
+		return nil, fmt.Errorf("delete evaluation queries: %w", err)
+	}
+
+	now := time.Now()
+	saved = make([]*evalentity.GoldenQuery, 0, len(queries))
+	for _, query := range queries {
+		var item *evalentity.GoldenQuery
+		item, err = insertGoldenQuery(ctx, txQueries, scope, query, now)
+		if err != nil {
+			return nil, err
+		}
+		saved = append(saved, item)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit replace evaluation queries tx: %w", err)
+	}
+	return saved, nil
+}
+
+func insertGoldenQuery(
+	ctx context.Context,
+	queries *mysqlsqlc.Queries,
+	scope evalrepository.QuerySetScope,
+	query evalentity.GoldenQuery,
+	now time.Time,
+) (*evalentity.GoldenQuery, error) {
+	documentCodes, err := json.Marshal(query.ExpectedDocumentCodes)
+	if err != nil {
+		return nil, fmt.Errorf("marshal expected document codes: %w", err)
+	}
+	fragmentIDs, err := json.Marshal(query.ExpectedFragmentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("marshal expected fragment ids: %w", err)
+	}
+	result, err := queries.InsertKnowledgeBaseEvalQuery(ctx, mysqlsqlc.InsertKnowledgeBaseEvalQueryParams{
+		OrganizationCode:      scope.OrganizationCode,
+		KnowledgeBaseCode:     scope.KnowledgeBaseCode,
+		SetCode:               scope.SetCode,
+		QueryKey:              query.QueryKey,
+		QueryText:             query.Query,
+		ExpectedDocumentCodes: documentCodes,
+		ExpectedFragmentIds:   fragmentIDs,
+		CreatedUid:            query.CreatedUID,
+		UpdatedUid:            query.UpdatedUID,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("insert evaluation query %q: %w", query.QueryKey, err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("read evaluation query id: %w", err)
+	}
+
+	query.ID = id
+	query.OrganizationCode = scope.OrganizationCode
+	query.KnowledgeBaseCode = scope.KnowledgeBaseCode
+	query.SetCode = scope.SetCode
+	query.CreatedAt = now
+	query.UpdatedAt = now
+	return &query, nil
+}
+
+// ListGoldenQueries 按写入顺序列出评测集全部 query。
+func (r *Repository) ListGoldenQueries(
+	ctx context.Context,
+	scope evalrepository.QuerySetScope,
+) ([]*evalentity.GoldenQuery, error) {
+	if r == nil || r.client == nil {
+		return nil, errEvaluationRepositoryNil
+	}
+	rows, err := r.client.Q().ListKnowledgeBaseEvalQueriesBySet(ctx, mysqlsqlc.ListKnowledgeBaseEvalQueriesBySetParams{
+		OrganizationCode:  scope.OrganizationCode,
+		KnowledgeBaseCode: scope.KnowledgeBaseCode,
+		SetCode:           scope.SetCode,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list evaluation queries: %w", err)
+	}
+
+	queries := make([]*evalentity.GoldenQuery, 0, len(rows))
+	for _, row := range rows {
+		query := &evalentity.GoldenQuery{
+			ID:                row.ID,
+			OrganizationCode:  row.OrganizationCode,
+			KnowledgeBaseCode: row.KnowledgeBaseCode,
+			SetCode:           row.SetCode,
+			QueryKey:          row.QueryKey,
+			Query:             row.QueryText,
+			CreatedUID:        row.CreatedUid,
+			UpdatedUID:        row.UpdatedUid,
+			CreatedAt:         row.CreatedAt,
+			UpdatedAt:         row.UpdatedAt,
+		}
+		if err := unmarshalJSONColumn(row.ExpectedDocumentCodes, &query.ExpectedDocumentCodes); err != nil {
+			return nil, fmt.Errorf("decode expected document codes of query %d: %w", row.ID, err)
+		}
+		if err := unmarshalJSONColumn(row.ExpectedFragmentIds, &query.ExpectedFragmentIDs); err != nil {
+			return nil, fmt.Errorf("decode expected fragment ids of query %d: %w", row.ID, err)
+		}
+		queries = append(queries, query)
+	}
+	return queries, nil
+}
+
+// InsertRun 保存一次评测 run 及其逐 query 结果。
+func (r *Repository) InsertRun(ctx context.Context, run evalentity.Run) (*evalentity.Run, error) {
+	if r == nil || r.client == nil {
+		return nil, errEvaluationRepositoryNil
+	}
+	params, err := buildInsertRunParams(run)
+	if err != nil {
+		return nil, err
+	}
+	result, err := r.client.Q().InsertKnowledgeBaseEvalRun(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("insert evaluation run: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("read evaluation run id: %w", err)
+	}
+	run.ID = id
+	return &run, nil
+}
+
+func buildInsertRunParams(run evalentity.Run) (mysqlsqlc.InsertKnowledgeBaseEvalRunParams, error) {
+	topK, err := convert.SafeIntToUint32(run.TopK, "top_k")
+	if err != nil {
+		return mysqlsqlc.InsertKnowledgeBaseEvalRunParams{}, fmt.Errorf("convert evaluation run top_k: %w", err)
+	}
+	queryCount, err := convert.SafeIntToUint32(run.Metrics.QueryCount, "query_count")
+	if err != nil {
+		return mysqlsqlc.InsertKnowledgeBaseEvalRunParams{}, fmt.Errorf("convert evaluation run query_count: %w", err)
+	}
+	failedCount, err := convert.SafeIntToUint32(run.Metrics.FailedCount, "failed_count")
+	if err != nil {
+		return mysqlsqlc.InsertKnowledgeBaseEvalRunParams{}, fmt.Errorf("convert evaluation run failed_count: %w", err)
+	}
+	metrics, err := json.Marshal(run.Metrics)
+	if err != nil {
+		return mysqlsqlc.InsertKnowledgeBaseEvalRunParams{}, fmt.Errorf("marshal evaluation metrics: %w", err)
+	}
+	results := run.Results
+	if results == nil {
+		results = []evalentity.QueryResult{}
+	}
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		return mysqlsqlc.InsertKnowledgeBaseEvalRunParams{}, fmt.Errorf("marshal evaluation results: %w", err)
+	}
+	return mysqlsqlc.InsertKnowledgeBaseEvalRunParams{
+		OrganizationCode:  run.OrganizationCode,
+		KnowledgeBaseCode: run.KnowledgeBaseCode,
+		SetCode:           run.SetCode,
+		Label:             run.Label,
+		TopK:              topK,
+		BaselineRunID:     run.BaselineRunID,
+		QueryCount:        queryCount,
+		FailedCount:       failedCount,
+		Metrics:           metrics,
+		Results:           resultsJSON,
+		CreatedUid:        run.CreatedUID,
+		StartedAt:         run.StartedAt,
+		FinishedAt:        run.FinishedAt,
+	}, nil
+}
+
+// GetRun 按组织和 ID 读取评测 run。
+func (r *Repository) GetRun(ctx context.Context, organizationCode string, id int64) (*evalentity.Run, error) {
+	if r == nil || r.client == nil {
+		return nil, errEvaluationRepositoryNil
+	}
+	row, err := r.client.Q().GetKnowledgeBaseEvalRunByID(ctx, mysqlsqlc.GetKnowledgeBaseEvalRunByIDParams{
+		OrganizationCode: organizationCode,
+		ID:               id,
+	})
+	if err != nil {
+		return nil, mapRunLookupError(err, "get evaluation run")
+	}
+	return toRun(row)
+}
+
+// GetPreviousRun 读取同一评测集中早于 beforeID 的最近一次 run。
+func (r *Repository) GetPreviousRun(
+	ctx context.Context,
+	scope evalrepository.QuerySetScope,
+	beforeID int64,
+) (*evalentity.Run, error) {
+	if r == nil || r.client == nil {
+		return nil, errEvaluationRepositoryNil
+	}
+	row, err := r.client.Q().GetPreviousKnowledgeBaseEvalRun(ctx, mysqlsqlc.GetPreviousKnowledgeBaseEvalRunParams{
+		OrganizationCode:  scope.OrganizationCode,
+		KnowledgeBaseCode: scope.KnowledgeBaseCode,
+		SetCode:           scope.SetCode,
+		ID:                beforeID,
+	})
+	if err != nil {
+		return nil, mapRunLookupError(err, "get previous evaluation run")
+	}
+	return toRun(row)
+}
+
+// ListRuns 按 ID 倒序列出评测 run 摘要。
+func (r *Repository) ListRuns(ctx context.Context, query evalrepository.ListRunsQuery) ([]*evalentity.Run, error) {
+	if r == nil || r.client == nil {
+		return nil, errEvaluationRepositoryNil
+	}
+	rows, err := r.client.Q().ListKnowledgeBaseEvalRunSummaries(ctx, mysqlsqlc.ListKnowledgeBaseEvalRunSummariesParams{
+		OrganizationCode:  query.Scope.OrganizationCode,
+		KnowledgeBaseCode: query.Scope.KnowledgeBaseCode,
+		SetCode:           query.Scope.SetCode,
+		Limit:             query.Limit,
+		Offset:            query.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list evaluation runs: %w", err)
+	}
+
+	runs := make([]*evalentity.Run, 0, len(rows))
+	for _, row := range rows {
+		run, err := toRun(mysqlsqlc.KnowledgeBaseEvalRun{
+			ID:                row.ID,
+			OrganizationCode:  row.OrganizationCode,
+			KnowledgeBaseCode: row.KnowledgeBaseCode,
+			SetCode:           row.SetCode,
+			Label:             row.Label,
+			TopK:              row.TopK,
+			BaselineRunID:     row.BaselineRunID,
+			QueryCount:        row.QueryCount,
+			FailedCount:       row.FailedCount,
+			Metrics:           row.Metrics,
+			CreatedUid:        row.CreatedUid,
+			StartedAt:         row.StartedAt,
+			FinishedAt:        row.FinishedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+func toRun(row mysqlsqlc.KnowledgeBaseEvalRun) (*evalentity.Run, error) {
+	run := &evalentity.Run{
+		ID:                row.ID,
+		OrganizationCode:  row.OrganizationCode,
+		KnowledgeBaseCode: row.KnowledgeBaseCode,
+		SetCode:           row.SetCode,
+		Label:             row.Label,
+		TopK:              int(row.TopK),
+		BaselineRunID:     row.BaselineRunID,
+		CreatedUID:        row.CreatedUid,
+		StartedAt:         row.StartedAt,
+		FinishedAt:        row.FinishedAt,
+	}
+	if err := unmarshalJSONColumn(row.Metrics, &run.Metrics); err != nil {
+		return nil, fmt.Errorf("decode metrics of evaluation run %d: %w", row.ID, err)
+	}
+	if err := unmarshalJSONColumn(row.Results, &run.Results); err != nil {
+		return nil, fmt.Errorf("decode results of evaluation run %d: %w", row.ID, err)
+	}
+	return run, nil
+}
+
+func mapRunLookupError(err error, action string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return evalentity.ErrRunNotFound
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+func unmarshalJSONColumn(raw []byte, target any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		return fmt.Errorf("unmarshal json column: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package evaluationrepo_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	evalentity "magic/internal/domain/knowledge/evaluation/entity"
+	evalrepository "magic/internal/domain/knowledge/evaluation/repository"
+	"magic/internal/infrastructure/logging"
+	mysqlclient "magic/internal/infrastructure/persistence/mysql"
+	evaluationrepo "magic/internal/infrastructure/persistence/mysql/knowledge/evaluation"
+)
+
+var evalScope = evalrepository.QuerySetScope{OrganizationCode: "ORG1", KnowledgeBaseCode: "KB1", SetCode: "default"}
+
+func sqlContains(fragment string) string {
+	return regexp.QuoteMeta(strings.TrimSpace(fragment))
+}
+
+func newEvaluationRepository(t *testing.T) (*evaluationrepo.Repository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return evaluationrepo.NewRepository(mysqlclient.NewSQLCClientWithDB(db, nil, false), logging.New()), mock
+}
+
+func TestRepositoryReplaceGoldenQueriesReplacesSetInTransaction(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newEvaluationRepository(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(sqlContains("DELETE FROM knowledge_base_eval_queries")).
+		WithArgs("ORG1", "KB1", "default").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(sqlContains("INSERT INTO knowledge_base_eval_queries")).
+		WithArgs("ORG1", "KB1", "default", "q1", "refund policy", []byte(`["DOC1"]`), []byte(`[7]`),
+			"user-1", "user-1", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(11, 1))
+	mock.ExpectCommit()
+
+	saved, err := repo.ReplaceGoldenQueries(context.Background(), evalScope, []evalentity.GoldenQuery{{
+		QueryKey:              "q1",
+		Query:                 "refund policy",
+		ExpectedDocumentCodes: []string{"DOC1"},
+		ExpectedFragmentIDs:   []int64{7},
+		CreatedUID:            "user-1",
+		UpdatedUID:            "user-1",
+	}})
+	if err != nil {
+		t.Fatalf("ReplaceGoldenQueries returned error: %v", err)
+	}
+	if len(saved) != 1 || saved[0].ID != 11 || saved[0].KnowledgeBaseCode != "KB1" {
+		t.Fatalf("unexpected saved queries: %#v", saved)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
+
+func TestRepositoryReplaceGoldenQueriesRollsBackOnInsertError(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newEvaluationRepository(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(sqlContains("DELETE FROM knowledge_base_eval_queries")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(sqlContains("INSERT INTO knowledge_base_eval_queries")).WillReturnError(errors.New("duplicate key"))
+	mock.ExpectRollback()
+
+	if _, err := repo.ReplaceGoldenQueries(context.Background(), evalScope, []evalentity.GoldenQuery{{QueryKey: "q1"}}); err == nil {
+		t.Fatal("expected insert error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
+
+func TestRepositoryListGoldenQueriesDecodesExpectations(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newEvaluationRepository(t)
+	now := time.Now()
+	mock.ExpectQuery(sqlContains("FROM knowledge_base_eval_queries")).
+		WithArgs("ORG1", "KB1", "default").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "organization_code", "knowledge_base_code", "set_code", "query_key", "query_text",
+			"expected_document_codes", "expected_fragment_ids", "created_uid", "updated_uid", "created_at", "updated_at",
+		}).AddRow(1, "ORG1", "KB1", "default", "q1", "refund policy", []byte(`["DOC1","DOC2"]`), []byte(`[3]`), "u", "u", now, now))
+
+	queries, err := repo.ListGoldenQueries(context.Background(), evalScope)
+	if err != nil {
+		t.Fatalf("ListGoldenQueries returned error: %v", err)
+	}
+	if len(queries) != 1 || queries[0].Query != "refund policy" ||
+		!slices.Equal(queries[0].ExpectedDocumentCodes, []string{"DOC1", "DOC2"}) ||
+		!slices.Equal(queries[0].ExpectedFragmentIDs, []int64{3}) {
+		t.Fatalf("unexpected queries: %#v", queries)
+	}
+}
+
+func TestRepositoryRunRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newEvaluationRepository(t)
+	now := time.Now()
+	mock.ExpectExec(sqlContains("INSERT INTO knowledge_base_eval_runs")).
+		WithArgs("ORG1", "KB1", "default", "rrf-k60", uint32(10), int64(4), uint32(2), uint32(1),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), "user-1", now, now).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+
+	run, err := repo.InsertRun(context.Background(), evalentity.Run{
+		OrganizationCode:  "ORG1",
+		KnowledgeBaseCode: "KB1",
+		SetCode:           "default",
+		Label:             "rrf-k60",
+		TopK:              10,
+		BaselineRunID:     4,
+		Metrics:           evalentity.Metrics{QueryCount: 2, FailedCount: 1, MRR: 0.5},
+		CreatedUID:        "user-1",
+		StartedAt:         now,
+		FinishedAt:        now,
+	})
+	if err != nil || run.ID != 5 {
+		t.Fatalf("InsertRun returned run=%#v err=%v", run, err)
+	}
+
+	columns := []string{
+		"id", "organization_code", "knowledge_base_code", "set_code", "label", "top_k", "baseline_run_id",
+		"query_count", "failed_count", "metrics", "results", "created_uid", "started_at", "finished_at",
+	}
+	mock.ExpectQuery(sqlContains("FROM knowledge_base_eval_runs")).
+		WithArgs("ORG1", int64(5)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(
+			5, "ORG1", "KB1", "default", "rrf-k60", 10, 4, 2, 1,
+			[]byte(`{"query_count":2,"failed_count":1,"mrr":0.5,"cutoffs":[{"k":1,"recall":0.5,"ndcg":0.5,"hit_rate":0.5}]}`),
+			[]byte(`[{"query_key":"q1","reciprocal_rank":1}]`), "user-1", now, now,
+		))
+	loaded, err := repo.GetRun(context.Background(), "ORG1", 5)
+	if err != nil {
+		t.Fatalf("GetRun returned error: %v", err)
+	}
+	if loaded.Metrics.MRR != 0.5 || len(loaded.Metrics.Cutoffs) != 1 || len(loaded.Results) != 1 || loaded.Results[0].QueryKey != "q1" {
+		t.Fatalf("unexpected loaded run: %#v", loaded)
+	}
+
+	mock.ExpectQuery(sqlContains("AND id < ?")).
+		WithArgs("ORG1", "KB1", "default", int64(5)).
+		WillReturnError(sql.ErrNoRows)
+	if _, err := repo.GetPreviousRun(context.Background(), evalScope, 5); !errors.Is(err, evalentity.ErrRunNotFound) {
+		t.Fatalf("expected run not found, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
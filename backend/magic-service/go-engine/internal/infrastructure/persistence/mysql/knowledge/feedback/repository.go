@@ -0,0 +1,139 @@
+// Package feedbackrepo 提供知识库检索相关性反馈在 MySQL 上的仓储实现。
+package feedbackrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	feedbackentity "magic/internal/domain/knowledge/feedback/entity"
+	feedbackrepository "magic/internal/domain/knowledge/feedback/repository"
+	"magic/internal/infrastructure/logging"
+	mysqlclient "magic/internal/infrastructure/persistence/mysql"
+	mysqlsqlc "magic/internal/infrastructure/persistence/mysql/sqlc"
+)
+
+var errFeedbackRepositoryNil = errors.New("feedback repository is nil")
+
+// Repository 实现相关性反馈 MySQL 仓储。
+type Repository struct {
+	client *mysqlclient.SQLCClient
+	logger *logging.SugaredLogger
+}
+
+var _ feedbackrepository.Repository = (*Repository)(nil)
+
+// NewRepository 创建相关性反馈仓储。
+func NewRepository(client *mysqlclient.SQLCClient, logger *logging.SugaredLogger) *Repository {
+	return &Repository{client: client, logger: logger}
+}
+
+// SaveFeedback 写入反馈明细，首次写入时在同一事务内累加片段级与文档级计数。
+func (r *Repository) SaveFeedback(ctx context.Context, feedback *feedbackentity.Feedback) (recorded bool, err error) {
+	if r == nil || r.client == nil {
+		return false, errFeedbackRepositoryNil
+	}
+	if feedback == nil {
+		return false, nil
+	}
+
+	tx, err := r.client.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin save feedback tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	txQueries := r.client.Q().WithTx(tx)
+	result, err := txQueries.InsertKnowledgeBaseFeedback(ctx, mysqlsqlc.InsertKnowledgeBaseFeedbackParams{
+		OrganizationCode:  feedback.OrganizationCode,
+		KnowledgeBaseCode: feedback.KnowledgeBaseCode,
+		DocumentCode:      feedback.DocumentCode,
+		FragmentID:        feedback.FragmentID,
+		RequestID:         feedback.RequestID,
+		UserID:            feedback.UserID,
+		SignalType:        feedback.Signal,
+		CreatedAt:         feedback.CreatedAt,
+	})
+	if err != nil {
+		return false, fmt.Errorf("insert feedback: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("read feedback rows affected: %w", err)
+	}
+	if affected == 0 {
+		if err = tx.Commit(); err != nil {
+			return false, fmt.Errorf("commit duplicate feedback tx: %w", err)
+		}
+		return false, nil
+	}
+
+	if err = upsertFeedbackStats(ctx, txQueries, feedback); err != nil {
+		return false, err
+	}
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit save feedback tx: %w", err)
+	}
+	return true, nil
+}
+
+func upsertFeedbackStats(ctx context.Context, queries *mysqlsqlc.Queries, feedback *feedbackentity.Feedback) error {
+	positive, negative, answered := feedbackentity.StatDelta(feedback.Signal)
+	fragmentIDs := []int64{0}
+	if feedback.FragmentID > 0 {
+		fragmentIDs = append(fragmentIDs, feedback.FragmentID)
+	}
+	for _, fragmentID := range fragmentIDs {
+		if err := queries.UpsertKnowledgeBaseFeedbackStat(ctx, mysqlsqlc.UpsertKnowledgeBaseFeedbackStatParams{
+			OrganizationCode:  feedback.OrganizationCode,
+			KnowledgeBaseCode: feedback.KnowledgeBaseCode,
+			DocumentCode:      feedback.DocumentCode,
+			FragmentID:        fragmentID,
+			PositiveCount:     positive,
+			NegativeCount:     negative,
+			AnsweredCount:     answered,
+			CreatedAt:         feedback.CreatedAt,
+			UpdatedAt:         feedback.CreatedAt,
+		}); err != nil {
+			return fmt.Errorf("upsert feedback stat: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListStatsByDocuments 读取给定文档的文档级与片段级反馈计数。
+func (r *Repository) ListStatsByDocuments(
+	ctx context.Context,
+	query feedbackrepository.StatsQuery,
+) ([]feedbackentity.Stat, error) {
+	if r == nil || r.client == nil {
+		return nil, errFeedbackRepositoryNil
+	}
+	if len(query.DocumentCodes) == 0 {
+		return []feedbackentity.Stat{}, nil
+	}
+	rows, err := r.client.Q().ListKnowledgeBaseFeedbackStatsByDocuments(ctx, mysqlsqlc.ListKnowledgeBaseFeedbackStatsByDocumentsParams{
+		OrganizationCode:  query.OrganizationCode,
+		KnowledgeBaseCode: query.KnowledgeBaseCode,
+		DocumentCodes:     query.DocumentCodes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list feedback stats: %w", err)
+	}
+	stats := make([]feedbackentity.Stat, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, feedbackentity.Stat{
+			KnowledgeBaseCode: row.KnowledgeBaseCode,
+			DocumentCode:      row.DocumentCode,
+			FragmentID:        row.FragmentID,
+			PositiveCount:     row.PositiveCount,
+			NegativeCount:     row.NegativeCount,
+			AnsweredCount:     row.AnsweredCount,
+		})
+	}
+	return stats, nil
+}
@@ -0,0 +1,125 @@
+package feedbackrepo_test
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	feedbackentity "magic/internal/domain/knowledge/feedback/entity"
+	feedbackrepository "magic/internal/domain/knowledge/feedback/repository"
+	"magic/internal/infrastructure/logging"
+	mysqlclient "magic/internal/infrastructure/persistence/mysql"
+	feedbackrepo "magic/internal/infrastructure/persistence/mysql/knowledge/feedback"
+)
+
+var feedbackCreatedAt = time.Date(2026, 6, 15, 10, 0, 0, 0, time.UTC)
+
+func sqlContains(fragment string) string {
+	return regexp.QuoteMeta(strings.TrimSpace(fragment))
+}
+
+func newFeedbackRepository(t *testing.T) (*feedbackrepo.Repository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return feedbackrepo.NewRepository(mysqlclient.NewSQLCClientWithDB(db, nil, false), logging.New()), mock
+}
+
+func fragmentFeedback() *feedbackentity.Feedback {
+	return &feedbackentity.Feedback{
+		OrganizationCode:  "ORG1",
+		KnowledgeBaseCode: "KB1",
+		DocumentCode:      "DOC1",
+		FragmentID:        7,
+		RequestID:         "req-1",
+		UserID:            "u1",
+		Signal:            feedbackentity.SignalAnswered,
+		CreatedAt:         feedbackCreatedAt,
+	}
+}
+
+func TestRepositorySaveFeedbackUpdatesDocumentAndFragmentStats(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newFeedbackRepository(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(sqlContains("INSERT IGNORE INTO knowledge_base_feedback")).
+		WithArgs("ORG1", "KB1", "DOC1", int64(7), "req-1", "u1", feedbackentity.SignalAnswered, feedbackCreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(sqlContains("INSERT INTO knowledge_base_feedback_stats")).
+		WithArgs("ORG1", "KB1", "DOC1", int64(0), int64(0), int64(0), int64(1), feedbackCreatedAt, feedbackCreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(sqlContains("INSERT INTO knowledge_base_feedback_stats")).
+		WithArgs("ORG1", "KB1", "DOC1", int64(7), int64(0), int64(0), int64(1), feedbackCreatedAt, feedbackCreatedAt).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	recorded, err := repo.SaveFeedback(context.Background(), fragmentFeedback())
+	if err != nil || !recorded {
+		t.Fatalf("SaveFeedback() = %v, %v", recorded, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRepositorySaveFeedbackSkipsStatsForDuplicate(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newFeedbackRepository(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(sqlContains("INSERT IGNORE INTO knowledge_base_feedback")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	recorded, err := repo.SaveFeedback(context.Background(), fragmentFeedback())
+	if err != nil || recorded {
+		t.Fatalf("expected duplicate feedback to be ignored, got %v, %v", recorded, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRepositoryListStatsByDocuments(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newFeedbackRepository(t)
+	mock.ExpectQuery(sqlContains("FROM knowledge_base_feedback_stats")).
+		WithArgs("ORG1", "KB1", "DOC1", "DOC2").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"knowledge_base_code", "document_code", "fragment_id", "positive_count", "negative_count", "answered_count",
+		}).
+			AddRow("KB1", "DOC1", int64(0), int64(3), int64(1), int64(2)).
+			AddRow("KB1", "DOC1", int64(7), int64(1), int64(0), int64(2)))
+
+	stats, err := repo.ListStatsByDocuments(context.Background(), feedbackrepository.StatsQuery{
+		OrganizationCode:  "ORG1",
+		KnowledgeBaseCode: "KB1",
+		DocumentCodes:     []string{"DOC1", "DOC2"},
+	})
+	if err != nil {
+		t.Fatalf("ListStatsByDocuments() error = %v", err)
+	}
+	if len(stats) != 2 || stats[0].PositiveCount != 3 || stats[1].FragmentID != 7 || stats[1].AnsweredCount != 2 {
+		t.Fatalf("unexpected stats: %#v", stats)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+
+	empty, err := repo.ListStatsByDocuments(context.Background(), feedbackrepository.StatsQuery{KnowledgeBaseCode: "KB1"})
+	if err != nil || len(empty) != 0 {
+		t.Fatalf("expected no query for empty document codes, got %#v, %v", empty, err)
+	}
+}
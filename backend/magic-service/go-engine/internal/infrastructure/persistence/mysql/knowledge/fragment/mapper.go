@@ -119,6 +119,14 @@ func toFragmentFromListByKnowledgeAndDocumentAfterID(row mysqlsqlc.MagicFlowKnow
 	return toFragmentFromModel(row)
 }
 
+func toFragmentFromListByDocumentChunkRange(row mysqlsqlc.MagicFlowKnowledgeFragment) (*fragmodel.KnowledgeBaseFragment, error) {
+	return toFragmentFromModel(row)
+}
+
+func toFragmentFromListByDocumentParentNode(row mysqlsqlc.MagicFlowKnowledgeFragment) (*fragmodel.KnowledgeBaseFragment, error) {
+	return toFragmentFromModel(row)
+}
+
 func toFragmentFromListByKnowledgeAndDocumentFiltered(row mysqlsqlc.MagicFlowKnowledgeFragment) (*fragmodel.KnowledgeBaseFragment, error) {
 	return toFragmentFromModel(row)
 }
@@ -277,6 +277,44 @@ func (repo *FragmentRepository) ListByDocumentAfterID(
 	return mapFragmentRows(rows, toFragmentFromListByKnowledgeAndDocumentAfterID)
 }
 
+// ListContextWindow 按父节点或 chunk_index 区间查询命中片段的上下文窗口。
+func (repo *FragmentRepository) ListContextWindow(
+	ctx context.Context,
+	query fragmodel.ContextWindowQuery,
+) ([]*fragmodel.KnowledgeBaseFragment, error) {
+	limit32, err := convert.SafeIntToInt32(query.Limit, "limit")
+	if err != nil {
+		return nil, fmt.Errorf("invalid limit: %w", err)
+	}
+	knowledgeCode := strings.TrimSpace(query.KnowledgeCode)
+	documentCode := strings.TrimSpace(query.DocumentCode)
+
+	if parentNodeID := strings.TrimSpace(query.ParentNodeID); parentNodeID != "" {
+		rows, err := repo.queries.ListFragmentsByDocumentParentNode(ctx, mysqlsqlc.ListFragmentsByDocumentParentNodeParams{
+			KnowledgeCode: knowledgeCode,
+			DocumentCode:  documentCode,
+			ParentNodeID:  parentNodeID,
+			Limit:         limit32,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query fragments by parent node: %w", err)
+		}
+		return mapFragmentRows(rows, toFragmentFromListByDocumentParentNode)
+	}
+
+	rows, err := repo.queries.ListFragmentsByDocumentChunkRange(ctx, mysqlsqlc.ListFragmentsByDocumentChunkRangeParams{
+		KnowledgeCode:   knowledgeCode,
+		DocumentCode:    documentCode,
+		StartChunkIndex: query.StartChunkIndex,
+		EndChunkIndex:   query.EndChunkIndex,
+		Limit:           limit32,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fragments by chunk range: %w", err)
+	}
+	return mapFragmentRows(rows, toFragmentFromListByDocumentChunkRange)
+}
+
 // ListByKnowledgeBase 根据知识库查询片段列表
 func (repo *FragmentRepository) ListByKnowledgeBase(ctx context.Context, knowledgeCode string, offset, limit int) ([]*fragmodel.KnowledgeBaseFragment, int64, error) {
 	return repo.List(ctx, &fragmodel.Query{
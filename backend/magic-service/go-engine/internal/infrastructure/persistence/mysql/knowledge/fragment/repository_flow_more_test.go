@@ -275,6 +275,42 @@ func TestFragmentRepositoryListByDocumentAfterID(t *testing.T) {
 	assertFragmentMockExpectations(t, mock)
 }
 
+func TestFragmentRepositoryListContextWindow(t *testing.T) {
+	t.Parallel()
+
+	testCtx := newFragmentRepositoryTestContext(t)
+	repo, mock := testCtx.repo, testCtx.mock
+
+	mock.ExpectQuery(sqlContains("BETWEEN CAST(? AS SIGNED) AND CAST(? AS SIGNED)")).
+		WithArgs("KB1", "DOC1", 3, 7, mustInt32Repo(t, 5)).
+		WillReturnRows(sqlmock.NewRows(fragmentRowColumns()).AddRow(sampleFragmentRowValues(t)...))
+	fragments, err := repo.ListContextWindow(context.Background(), fragmodel.ContextWindowQuery{
+		KnowledgeCode:   "KB1",
+		DocumentCode:    "DOC1",
+		StartChunkIndex: 3,
+		EndChunkIndex:   7,
+		Limit:           5,
+	})
+	if err != nil || len(fragments) != 1 {
+		t.Fatalf("unexpected chunk range fragments=%#v err=%v", fragments, err)
+	}
+
+	mock.ExpectQuery(sqlContains("JSON_EXTRACT(metadata, '$.parent_node_id')) = CAST(? AS CHAR(255))")).
+		WithArgs("KB1", "DOC1", "node-2", mustInt32Repo(t, 20)).
+		WillReturnRows(sqlmock.NewRows(fragmentRowColumns()).AddRow(sampleFragmentRowValues(t)...))
+	fragments, err = repo.ListContextWindow(context.Background(), fragmodel.ContextWindowQuery{
+		KnowledgeCode: "KB1",
+		DocumentCode:  "DOC1",
+		ParentNodeID:  " node-2 ",
+		Limit:         20,
+	})
+	if err != nil || len(fragments) != 1 {
+		t.Fatalf("unexpected parent node fragments=%#v err=%v", fragments, err)
+	}
+
+	assertFragmentMockExpectations(t, mock)
+}
+
 func TestFragmentRepositoryListByDocumentOrdersByChunkIndex(t *testing.T) {
 	t.Parallel()
 
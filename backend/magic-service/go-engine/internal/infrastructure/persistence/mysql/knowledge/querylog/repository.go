@@ -0,0 +1,282 @@
+// Package querylogrepo 提供知识库检索 query 日志在 MySQL 上的仓储实现。
+package querylogrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
+	querylogrepository "magic/internal/domain/knowledge/querylog/repository"
+	"magic/internal/infrastructure/logging"
+	mysqlclient "magic/internal/infrastructure/persistence/mysql"
+	mysqlsqlc "magic/internal/infrastructure/persistence/mysql/sqlc"
+	"magic/pkg/convert"
+)
+
+var errQueryLogRepositoryNil = errors.New("query log repository is nil")
+
+// Repository 实现检索 query 日志 MySQL 仓储。
+type Repository struct {
+	client *mysqlclient.SQLCClient
+	logger *logging.SugaredLogger
+}
+
+var _ querylogrepository.Repository = (*Repository)(nil)
+
+// NewRepository 创建检索 query 日志仓储。
+func NewRepository(client *mysqlclient.SQLCClient, logger *logging.SugaredLogger) *Repository {
+	return &Repository{client: client, logger: logger}
+}
+
+// InsertQueryLogs 在事务内写入一次检索拆分出的全部日志及命中明细。
+func (r *Repository) InsertQueryLogs(ctx context.Context, logs []querylogentity.QueryLog) (err error) {
+	if r == nil || r.client == nil {
+		return errQueryLogRepositoryNil
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+
+	tx, err := r.client.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin insert query logs tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	txQueries := r.client.Q().WithTx(tx)
+	for _, log := range logs {
+		if err = insertQueryLog(ctx, txQueries, log); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit insert query logs tx: %w", err)
+	}
+	return nil
+}
+
+func insertQueryLog(ctx context.Context, queries *mysqlsqlc.Queries, log querylogentity.QueryLog) error {
+	resultCount, err := convert.SafeIntToUint32(log.ResultCount, "result_count")
+	if err != nil {
+		return fmt.Errorf("convert query log result_count: %w", err)
+	}
+	latencyMS, err := convert.SafeIntToUint32(convert.ClampToInt(log.LatencyMS), "latency_ms")
+	if err != nil {
+		return fmt.Errorf("convert query log latency_ms: %w", err)
+	}
+	result, err := queries.InsertKnowledgeBaseQueryLog(ctx, mysqlsqlc.InsertKnowledgeBaseQueryLogParams{
+		RequestID:         log.RequestID,
+		OrganizationCode:  log.OrganizationCode,
+		KnowledgeBaseCode: log.KnowledgeBaseCode,
+		UserID:            log.UserID,
+		Source:            log.Source,
+		AgentCode:         log.AgentCode,
+		QueryText:         log.Query,
+		QueryHash:         log.QueryHash,
+		QueryType:         log.QueryType,
+		ResultCount:       resultCount,
+		TopScore:          log.TopScore,
+		LatencyMs:         latencyMS,
+		CreatedAt:         log.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("insert query log: %w", err)
+	}
+	logID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("read query log id: %w", err)
+	}
+
+	for index, hit := range log.Hits {
+		rank, err := convert.SafeIntToUint32(index+1, "hit_rank")
+		if err != nil {
+			return fmt.Errorf("convert query log hit_rank: %w", err)
+		}
+		if err := queries.InsertKnowledgeBaseQueryLogHit(ctx, mysqlsqlc.InsertKnowledgeBaseQueryLogHitParams{
+			LogID:             logID,
+			OrganizationCode:  log.OrganizationCode,
+			KnowledgeBaseCode: log.KnowledgeBaseCode,
+			DocumentCode:      hit.DocumentCode,
+			FragmentID:        hit.FragmentID,
+			Score:             hit.Score,
+			HitRank:           rank,
+			CreatedAt:         log.CreatedAt,
+		}); err != nil {
+			return fmt.Errorf("insert query log hit: %w", err)
+		}
+	}
+	return nil
+}
+
+// TopQueries 按检索次数倒序列出窗口内的高频 query。
+func (r *Repository) TopQueries(
+	ctx context.Context,
+	query querylogrepository.AnalyticsQuery,
+) ([]querylogentity.QueryStat, error) {
+	if r == nil || r.client == nil {
+		return nil, errQueryLogRepositoryNil
+	}
+	rows, err := r.client.Q().ListKnowledgeBaseTopQueries(ctx, mysqlsqlc.ListKnowledgeBaseTopQueriesParams(toAnalyticsParams(query)))
+	if err != nil {
+		return nil, fmt.Errorf("list top queries: %w", err)
+	}
+	stats := make([]querylogentity.QueryStat, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, querylogentity.QueryStat{
+			QueryHash:       row.QueryHash,
+			Query:           row.QueryText,
+			SearchCount:     row.SearchCount,
+			ZeroResultCount: row.ZeroResultCount,
+			AvgLatencyMS:    row.AvgLatencyMs,
+			LastSearchedAt:  parseAggregateTime(row.LastSearchedAt),
+		})
+	}
+	return stats, nil
+}
+
+// ZeroResultQueries 列出窗口内没有任何命中的 query。
+func (r *Repository) ZeroResultQueries(
+	ctx context.Context,
+	query querylogrepository.AnalyticsQuery,
+) ([]querylogentity.QueryStat, error) {
+	if r == nil || r.client == nil {
+		return nil, errQueryLogRepositoryNil
+	}
+	rows, err := r.client.Q().ListKnowledgeBaseZeroResultQueries(
+		ctx,
+		mysqlsqlc.ListKnowledgeBaseZeroResultQueriesParams(toAnalyticsParams(query)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list zero result queries: %w", err)
+	}
+	stats := make([]querylogentity.QueryStat, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, querylogentity.QueryStat{
+			QueryHash:       row.QueryHash,
+			Query:           row.QueryText,
+			SearchCount:     row.SearchCount,
+			ZeroResultCount: row.SearchCount,
+			AvgLatencyMS:    row.AvgLatencyMs,
+			LastSearchedAt:  parseAggregateTime(row.LastSearchedAt),
+		})
+	}
+	return stats, nil
+}
+
+// SlowestQueries 按耗时倒序列出窗口内的单次检索。
+func (r *Repository) SlowestQueries(
+	ctx context.Context,
+	query querylogrepository.AnalyticsQuery,
+) ([]querylogentity.SlowQuery, error) {
+	if r == nil || r.client == nil {
+		return nil, errQueryLogRepositoryNil
+	}
+	rows, err := r.client.Q().ListKnowledgeBaseSlowestQueries(
+		ctx,
+		mysqlsqlc.ListKnowledgeBaseSlowestQueriesParams(toAnalyticsParams(query)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list slowest queries: %w", err)
+	}
+	queries := make([]querylogentity.SlowQuery, 0, len(rows))
+	for _, row := range rows {
+		queries = append(queries, querylogentity.SlowQuery{
+			ID:          row.ID,
+			Query:       row.QueryText,
+			QueryType:   row.QueryType,
+			Source:      row.Source,
+			ResultCount: int(row.ResultCount),
+			LatencyMS:   int64(row.LatencyMs),
+			CreatedAt:   row.CreatedAt,
+		})
+	}
+	return queries, nil
+}
+
+// MostRetrievedDocuments 按命中次数倒序列出窗口内的文档。
+func (r *Repository) MostRetrievedDocuments(
+	ctx context.Context,
+	query querylogrepository.AnalyticsQuery,
+) ([]querylogentity.DocumentUsage, error) {
+	if r == nil || r.client == nil {
+		return nil, errQueryLogRepositoryNil
+	}
+	rows, err := r.client.Q().ListKnowledgeBaseMostRetrievedDocuments(
+		ctx,
+		mysqlsqlc.ListKnowledgeBaseMostRetrievedDocumentsParams(toAnalyticsParams(query)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list most retrieved documents: %w", err)
+	}
+	documents := make([]querylogentity.DocumentUsage, 0, len(rows))
+	for _, row := range rows {
+		documents = append(documents, querylogentity.DocumentUsage{
+			DocumentCode:    row.DocumentCode,
+			HitCount:        row.HitCount,
+			QueryCount:      row.QueryCount,
+			AvgScore:        row.AvgScore,
+			LastRetrievedAt: parseAggregateTime(row.LastRetrievedAt),
+		})
+	}
+	return documents, nil
+}
+
+// NeverRetrievedDocuments 列出窗口截止前创建、窗口内从未被命中的启用文档。
+func (r *Repository) NeverRetrievedDocuments(
+	ctx context.Context,
+	query querylogrepository.AnalyticsQuery,
+) ([]querylogentity.UnusedDocument, error) {
+	if r == nil || r.client == nil {
+		return nil, errQueryLogRepositoryNil
+	}
+	rows, err := r.client.Q().ListKnowledgeBaseNeverRetrievedDocuments(
+		ctx,
+		mysqlsqlc.ListKnowledgeBaseNeverRetrievedDocumentsParams(toAnalyticsParams(query)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list never retrieved documents: %w", err)
+	}
+	documents := make([]querylogentity.UnusedDocument, 0, len(rows))
+	for _, row := range rows {
+		documents = append(documents, querylogentity.UnusedDocument{
+			DocumentCode: row.Code,
+			DocumentName: row.Name,
+			CreatedAt:    row.CreatedAt,
+		})
+	}
+	return documents, nil
+}
+
+type analyticsParams struct {
+	OrganizationCode  string    `json:"organization_code"`
+	KnowledgeBaseCode string    `json:"knowledge_base_code"`
+	Since             time.Time `json:"since"`
+	Until             time.Time `json:"until"`
+	Limit             int32     `json:"limit"`
+}
+
+func toAnalyticsParams(query querylogrepository.AnalyticsQuery) analyticsParams {
+	return analyticsParams{
+		OrganizationCode:  query.OrganizationCode,
+		KnowledgeBaseCode: query.KnowledgeBaseCode,
+		Since:             query.Since,
+		Until:             query.Until,
+		Limit:             query.Limit,
+	}
+}
+
+// parseAggregateTime 解析 MAX(created_at) 等聚合列，驱动返回 NULL 或无法识别的类型时回落为零值。
+func parseAggregateTime(value any) time.Time {
+	parsed, err := convert.ParseTimePtr(value)
+	if err != nil || parsed == nil {
+		return time.Time{}
+	}
+	return *parsed
+}
@@ -0,0 +1,158 @@
+package querylogrepo_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	querylogentity "magic/internal/domain/knowledge/querylog/entity"
+	querylogrepository "magic/internal/domain/knowledge/querylog/repository"
+	"magic/internal/infrastructure/logging"
+	mysqlclient "magic/internal/infrastructure/persistence/mysql"
+	querylogrepo "magic/internal/infrastructure/persistence/mysql/knowledge/querylog"
+)
+
+var (
+	analyticsSince = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	analyticsUntil = time.Date(2026, 6, 8, 0, 0, 0, 0, time.UTC)
+	analyticsQuery = querylogrepository.AnalyticsQuery{
+		OrganizationCode:  "ORG1",
+		KnowledgeBaseCode: "KB1",
+		Since:             analyticsSince,
+		Until:             analyticsUntil,
+		Limit:             20,
+	}
+)
+
+func sqlContains(fragment string) string {
+	return regexp.QuoteMeta(strings.TrimSpace(fragment))
+}
+
+func newQueryLogRepository(t *testing.T) (*querylogrepo.Repository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return querylogrepo.NewRepository(mysqlclient.NewSQLCClientWithDB(db, nil, false), logging.New()), mock
+}
+
+func TestRepositoryInsertQueryLogsWritesLogsAndHitsInTransaction(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newQueryLogRepository(t)
+	createdAt := analyticsSince.Add(time.Hour)
+	mock.ExpectBegin()
+	mock.ExpectExec(sqlContains("INSERT INTO knowledge_base_query_logs")).
+		WithArgs("req-1", "ORG1", "KB1", "u1", querylogentity.SourceSimilarity, "", "reset password", "hash",
+			"natural_language", uint32(2), 0.9, uint32(35), createdAt).
+		WillReturnResult(sqlmock.NewResult(41, 1))
+	mock.ExpectExec(sqlContains("INSERT INTO knowledge_base_query_log_hits")).
+		WithArgs(int64(41), "ORG1", "KB1", "DOC1", int64(7), 0.9, uint32(1), createdAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(sqlContains("INSERT INTO knowledge_base_query_log_hits")).
+		WithArgs(int64(41), "ORG1", "KB1", "DOC2", int64(8), 0.4, uint32(2), createdAt).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	err := repo.InsertQueryLogs(context.Background(), []querylogentity.QueryLog{{
+		RequestID:         "req-1",
+		OrganizationCode:  "ORG1",
+		KnowledgeBaseCode: "KB1",
+		UserID:            "u1",
+		Source:            querylogentity.SourceSimilarity,
+		Query:             "reset password",
+		QueryHash:         "hash",
+		QueryType:         "natural_language",
+		ResultCount:       2,
+		TopScore:          0.9,
+		LatencyMS:         35,
+		Hits: []querylogentity.Hit{
+			{KnowledgeBaseCode: "KB1", DocumentCode: "DOC1", FragmentID: 7, Score: 0.9},
+			{KnowledgeBaseCode: "KB1", DocumentCode: "DOC2", FragmentID: 8, Score: 0.4},
+		},
+		CreatedAt: createdAt,
+	}})
+	if err != nil {
+		t.Fatalf("InsertQueryLogs returned error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
+
+func TestRepositoryInsertQueryLogsRollsBackOnHitError(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newQueryLogRepository(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(sqlContains("INSERT INTO knowledge_base_query_logs")).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(sqlContains("INSERT INTO knowledge_base_query_log_hits")).WillReturnError(errors.New("deadlock"))
+	mock.ExpectRollback()
+
+	err := repo.InsertQueryLogs(context.Background(), []querylogentity.QueryLog{{
+		OrganizationCode:  "ORG1",
+		KnowledgeBaseCode: "KB1",
+		Hits:              []querylogentity.Hit{{DocumentCode: "DOC1"}},
+	}})
+	if err == nil {
+		t.Fatal("expected insert error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
+
+func TestRepositoryTopQueriesMapsAggregates(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newQueryLogRepository(t)
+	lastSearchedAt := analyticsUntil.Add(-time.Hour)
+	mock.ExpectQuery(sqlContains("FROM knowledge_base_query_logs")).
+		WithArgs("ORG1", "KB1", analyticsSince, analyticsUntil, int32(20)).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"query_hash", "query_text", "search_count", "zero_result_count", "avg_latency_ms", "last_searched_at",
+		}).AddRow("hash-1", "reset password", int64(12), int64(3), 41.5, lastSearchedAt))
+
+	stats, err := repo.TopQueries(context.Background(), analyticsQuery)
+	if err != nil {
+		t.Fatalf("TopQueries returned error: %v", err)
+	}
+	if len(stats) != 1 || stats[0].SearchCount != 12 || stats[0].ZeroResultCount != 3 ||
+		stats[0].AvgLatencyMS != 41.5 || !stats[0].LastSearchedAt.Equal(lastSearchedAt) {
+		t.Fatalf("unexpected stats: %#v", stats)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
+
+func TestRepositoryNeverRetrievedDocumentsBindsWindow(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := newQueryLogRepository(t)
+	createdAt := analyticsSince.Add(-24 * time.Hour)
+	mock.ExpectQuery(sqlContains("FROM knowledge_base_documents d")).
+		WithArgs("ORG1", "KB1", analyticsUntil, analyticsSince, analyticsUntil, int32(20)).
+		WillReturnRows(sqlmock.NewRows([]string{"code", "name", "created_at"}).AddRow("DOC9", "faq.md", createdAt))
+
+	documents, err := repo.NeverRetrievedDocuments(context.Background(), analyticsQuery)
+	if err != nil {
+		t.Fatalf("NeverRetrievedDocuments returned error: %v", err)
+	}
+	if len(documents) != 1 || documents[0].DocumentCode != "DOC9" || documents[0].DocumentName != "faq.md" {
+		t.Fatalf("unexpected documents: %#v", documents)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet sql expectations: %v", err)
+	}
+}
@@ -310,6 +310,26 @@ func (r *Repository) ListRealtimeProjectBindingsByProject(
 	return foldBindingRows(rows, targets), nil
 }
 
+// ListRealtimeBindingsByProvider 查询指定 provider 下所有启用实时同步的来源绑定。
+func (r *Repository) ListRealtimeBindingsByProvider(
+	ctx context.Context,
+	provider string,
+) ([]sourcebindingentity.Binding, error) {
+	provider = sourcebindingentity.NormalizeProvider(provider)
+	if r == nil || r.queries == nil || provider == "" {
+		return []sourcebindingentity.Binding{}, nil
+	}
+	rows, err := r.queries.ListRealtimeSourceBindingsCoreByProvider(ctx, provider)
+	if err != nil {
+		return nil, fmt.Errorf("list realtime source bindings by provider: %w", err)
+	}
+	targets, err := r.listBindingTargetsByBindingIDs(ctx, bindingIDsFromRows(rows))
+	if err != nil {
+		return nil, fmt.Errorf("list realtime source binding targets by provider: %w", err)
+	}
+	return foldBindingRows(rows, targets), nil
+}
+
 // ListRealtimeTeamshareBindingsByKnowledgeBase 查询 Teamshare 知识库下启用实时同步的来源绑定。
 func (r *Repository) ListRealtimeTeamshareBindingsByKnowledgeBase(
 	ctx context.Context,
@@ -393,6 +413,28 @@ func (r *Repository) MarkSourceBindingsRealtimeByIDs(ctx context.Context, bindin
 	return rows, nil
 }
 
+// UpdateBindingSyncConfig 只回写指定绑定的 sync_config，用于记录同步进度（如 Git 已同步提交）。
+func (r *Repository) UpdateBindingSyncConfig(ctx context.Context, bindingID int64, syncConfig map[string]any) error {
+	if bindingID <= 0 {
+		return nil
+	}
+	if r == nil || r.queries == nil {
+		return errSourceBindingRepositoryNil
+	}
+	syncConfigJSON, err := json.Marshal(syncConfig)
+	if err != nil {
+		return fmt.Errorf("marshal source binding sync config: %w", err)
+	}
+	if _, err := r.queries.UpdateKnowledgeSourceBindingSyncConfigByID(ctx, mysqlsqlc.UpdateKnowledgeSourceBindingSyncConfigByIDParams{
+		SyncConfig: mustNullJSON(syncConfigJSON),
+		UpdatedAt:  time.Now(),
+		ID:         bindingID,
+	}); err != nil {
+		return fmt.Errorf("update source binding sync config: %w", err)
+	}
+	return nil
+}
+
 // HasRealtimeProjectBindingForFile 判断项目文件是否命中启用的实时项目来源绑定。
 func (r *Repository) HasRealtimeProjectBindingForFile(
 	ctx context.Context,
@@ -124,6 +124,76 @@ func TestRepositoryListRealtimeProjectBindingsByProjectAcceptsNullSyncConfig(t *
 	}
 }
 
+func TestRepositoryListRealtimeBindingsByProviderLoadsWebsiteBindings(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	repo := sourcebindingrepo.NewRepository(mysqlclient.NewSQLCClientWithDB(db, nil, false))
+	now := time.Date(2026, 4, 20, 15, 30, 0, 0, time.Local)
+
+	mock.ExpectQuery(regexp.QuoteMeta("-- name: ListRealtimeSourceBindingsCoreByProvider :many")).
+		WithArgs("website").
+		WillReturnRows(sqlmock.NewRows(sourceBindingRowColumns()).AddRow(
+			int64(7),
+			"ORG1",
+			"KB1",
+			"website",
+			"site",
+			"https://docs.example.com/",
+			"realtime",
+			[]byte(`{"max_pages":50}`),
+			true,
+			"U1",
+			"U1",
+			now,
+			now,
+		))
+	mock.ExpectQuery(regexp.QuoteMeta("-- name: ListKnowledgeSourceBindingTargetsByBindingIDs :many")).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows(sourceBindingTargetRowColumns()))
+
+	bindings, err := repo.ListRealtimeBindingsByProvider(context.Background(), " Website ")
+	if err != nil {
+		t.Fatalf("ListRealtimeBindingsByProvider returned error: %v", err)
+	}
+	if len(bindings) != 1 || bindings[0].RootRef != "https://docs.example.com/" || bindings[0].SyncConfig["max_pages"] == nil {
+		t.Fatalf("unexpected bindings: %#v", bindings)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRepositoryUpdateBindingSyncConfigWritesOnlySyncConfig(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	repo := sourcebindingrepo.NewRepository(mysqlclient.NewSQLCClientWithDB(db, nil, false))
+	mock.ExpectExec(regexp.QuoteMeta("-- name: UpdateKnowledgeSourceBindingSyncConfigByID :execrows")).
+		WithArgs([]byte(`{"indexed_commit":"c0ffee","ref":"main"}`), sqlmock.AnyArg(), int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.UpdateBindingSyncConfig(context.Background(), 9, map[string]any{
+		"ref":            "main",
+		"indexed_commit": "c0ffee",
+	}); err != nil {
+		t.Fatalf("UpdateBindingSyncConfig returned error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
 func TestRepositoryListRealtimeTeamshareBindingsByKnowledgeBaseLoadsTargets(t *testing.T) {
 	t.Parallel()
 
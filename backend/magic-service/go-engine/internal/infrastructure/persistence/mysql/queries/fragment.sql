@@ -151,6 +151,32 @@ WHERE deleted_at IS NULL
 ORDER BY id ASC
 LIMIT ?;
 
+-- name: ListFragmentsByDocumentChunkRange :many
+SELECT *
+FROM magic_flow_knowledge_fragment
+WHERE deleted_at IS NULL
+  AND knowledge_code = sqlc.arg(knowledge_code)
+  AND document_code = sqlc.arg(document_code)
+  AND CAST(JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.chunk_index')) AS SIGNED)
+    BETWEEN CAST(sqlc.arg(start_chunk_index) AS SIGNED) AND CAST(sqlc.arg(end_chunk_index) AS SIGNED)
+ORDER BY
+  CAST(JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.chunk_index')) AS SIGNED) ASC,
+  id ASC
+LIMIT ?;
+
+-- name: ListFragmentsByDocumentParentNode :many
+SELECT *
+FROM magic_flow_knowledge_fragment
+WHERE deleted_at IS NULL
+  AND knowledge_code = sqlc.arg(knowledge_code)
+  AND document_code = sqlc.arg(document_code)
+  AND JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.parent_node_id')) = CAST(sqlc.arg(parent_node_id) AS CHAR(255))
+ORDER BY
+  CASE WHEN JSON_EXTRACT(metadata, '$.chunk_index') IS NULL THEN 1 ELSE 0 END ASC,
+  CAST(JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.chunk_index')) AS SIGNED) ASC,
+  id ASC
+LIMIT ?;
+
 -- name: DeleteFragmentsByKnowledgeBase :execrows
 DELETE FROM magic_flow_knowledge_fragment
 WHERE knowledge_code = ?;
@@ -0,0 +1,56 @@
+-- name: DeleteKnowledgeBaseEvalQueriesBySet :execrows
+DELETE FROM knowledge_base_eval_queries
+WHERE organization_code = ?
+  AND knowledge_base_code = ?
+  AND set_code = ?;
+
+-- name: InsertKnowledgeBaseEvalQuery :execresult
+INSERT INTO knowledge_base_eval_queries (
+    organization_code, knowledge_base_code, set_code, query_key, query_text,
+    expected_document_codes, expected_fragment_ids, created_uid, updated_uid, created_at, updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListKnowledgeBaseEvalQueriesBySet :many
+SELECT id, organization_code, knowledge_base_code, set_code, query_key, query_text, expected_document_codes, expected_fragment_ids, created_uid, updated_uid, created_at, updated_at
+FROM knowledge_base_eval_queries
+WHERE organization_code = ?
+  AND knowledge_base_code = ?
+  AND set_code = ?
+ORDER BY id ASC;
+
+-- name: InsertKnowledgeBaseEvalRun :execresult
+INSERT INTO knowledge_base_eval_runs (
+    organization_code, knowledge_base_code, set_code, label, top_k, baseline_run_id,
+    query_count, failed_count, metrics, results, created_uid, started_at, finished_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetKnowledgeBaseEvalRunByID :one
+SELECT id, organization_code, knowledge_base_code, set_code, label, top_k, baseline_run_id, query_count, failed_count, metrics, results, created_uid, started_at, finished_at
+FROM knowledge_base_eval_runs
+WHERE organization_code = ?
+  AND id = ?
+LIMIT 1;
+
+-- name: GetPreviousKnowledgeBaseEvalRun :one
+SELECT id, organization_code, knowledge_base_code, set_code, label, top_k, baseline_run_id, query_count, failed_count, metrics, results, created_uid, started_at, finished_at
+FROM knowledge_base_eval_runs
+WHERE organization_code = ?
+  AND knowledge_base_code = ?
+  AND set_code = ?
+  AND id < ?
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: ListKnowledgeBaseEvalRunSummaries :many
+SELECT id, organization_code, knowledge_base_code, set_code, label, top_k, baseline_run_id, query_count, failed_count, metrics, created_uid, started_at, finished_at
+FROM knowledge_base_eval_runs
+WHERE organization_code = ?
+  AND knowledge_base_code = ?
+  AND set_code = ?
+ORDER BY id DESC
+LIMIT ?
+OFFSET ?;
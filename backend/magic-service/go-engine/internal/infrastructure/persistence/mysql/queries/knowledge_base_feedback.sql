@@ -0,0 +1,27 @@
+-- name: InsertKnowledgeBaseFeedback :execresult
+INSERT IGNORE INTO knowledge_base_feedback (
+    organization_code, knowledge_base_code, document_code, fragment_id,
+    request_id, user_id, signal_type, created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: UpsertKnowledgeBaseFeedbackStat :exec
+INSERT INTO knowledge_base_feedback_stats (
+    organization_code, knowledge_base_code, document_code, fragment_id,
+    positive_count, negative_count, answered_count, created_at, updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON DUPLICATE KEY UPDATE
+    positive_count = positive_count + VALUES(positive_count),
+    negative_count = negative_count + VALUES(negative_count),
+    answered_count = answered_count + VALUES(answered_count),
+    updated_at = VALUES(updated_at);
+
+-- name: ListKnowledgeBaseFeedbackStatsByDocuments :many
+SELECT knowledge_base_code, document_code, fragment_id, positive_count, negative_count, answered_count
+FROM knowledge_base_feedback_stats
+WHERE organization_code = sqlc.arg(organization_code)
+  AND knowledge_base_code = sqlc.arg(knowledge_base_code)
+  AND document_code IN (sqlc.slice(document_codes));
@@ -0,0 +1,92 @@
+-- name: InsertKnowledgeBaseQueryLog :execresult
+INSERT INTO knowledge_base_query_logs (
+    request_id, organization_code, knowledge_base_code, user_id, source, agent_code,
+    query_text, query_hash, query_type, result_count, top_score, latency_ms, created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: InsertKnowledgeBaseQueryLogHit :exec
+INSERT INTO knowledge_base_query_log_hits (
+    log_id, organization_code, knowledge_base_code, document_code, fragment_id, score, hit_rank, created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListKnowledgeBaseTopQueries :many
+SELECT query_hash,
+       CAST(MAX(query_text) AS CHAR) AS query_text,
+       COUNT(*) AS search_count,
+       CAST(COALESCE(SUM(CASE WHEN result_count = 0 THEN 1 ELSE 0 END), 0) AS SIGNED) AS zero_result_count,
+       CAST(COALESCE(AVG(latency_ms), 0) AS DOUBLE) AS avg_latency_ms,
+       CAST(MAX(created_at) AS DATETIME) AS last_searched_at
+FROM knowledge_base_query_logs
+WHERE organization_code = sqlc.arg(organization_code)
+  AND knowledge_base_code = sqlc.arg(knowledge_base_code)
+  AND created_at >= sqlc.arg(since)
+  AND created_at < sqlc.arg(until)
+GROUP BY query_hash
+ORDER BY search_count DESC, last_searched_at DESC
+LIMIT sqlc.arg(limit);
+
+-- name: ListKnowledgeBaseZeroResultQueries :many
+SELECT query_hash,
+       CAST(MAX(query_text) AS CHAR) AS query_text,
+       COUNT(*) AS search_count,
+       CAST(COALESCE(AVG(latency_ms), 0) AS DOUBLE) AS avg_latency_ms,
+       CAST(MAX(created_at) AS DATETIME) AS last_searched_at
+FROM knowledge_base_query_logs
+WHERE organization_code = sqlc.arg(organization_code)
+  AND knowledge_base_code = sqlc.arg(knowledge_base_code)
+  AND created_at >= sqlc.arg(since)
+  AND created_at < sqlc.arg(until)
+  AND result_count = 0
+GROUP BY query_hash
+ORDER BY search_count DESC, last_searched_at DESC
+LIMIT sqlc.arg(limit);
+
+-- name: ListKnowledgeBaseSlowestQueries :many
+SELECT id, query_text, query_type, source, result_count, latency_ms, created_at
+FROM knowledge_base_query_logs
+WHERE organization_code = sqlc.arg(organization_code)
+  AND knowledge_base_code = sqlc.arg(knowledge_base_code)
+  AND created_at >= sqlc.arg(since)
+  AND created_at < sqlc.arg(until)
+ORDER BY latency_ms DESC, id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: ListKnowledgeBaseMostRetrievedDocuments :many
+SELECT document_code,
+       COUNT(*) AS hit_count,
+       COUNT(DISTINCT log_id) AS query_count,
+       CAST(COALESCE(AVG(score), 0) AS DOUBLE) AS avg_score,
+       CAST(MAX(created_at) AS DATETIME) AS last_retrieved_at
+FROM knowledge_base_query_log_hits
+WHERE organization_code = sqlc.arg(organization_code)
+  AND knowledge_base_code = sqlc.arg(knowledge_base_code)
+  AND created_at >= sqlc.arg(since)
+  AND created_at < sqlc.arg(until)
+  AND document_code <> ''
+GROUP BY document_code
+ORDER BY hit_count DESC, document_code ASC
+LIMIT sqlc.arg(limit);
+
+-- name: ListKnowledgeBaseNeverRetrievedDocuments :many
+SELECT d.code, d.name, d.created_at
+FROM knowledge_base_documents d
+WHERE d.organization_code = sqlc.arg(organization_code)
+  AND d.knowledge_base_code = sqlc.arg(knowledge_base_code)
+  AND d.deleted_at IS NULL
+  AND d.enabled = TRUE
+  AND d.created_at < sqlc.arg(until)
+  AND NOT EXISTS (
+    SELECT 1
+    FROM knowledge_base_query_log_hits h
+    WHERE h.organization_code = d.organization_code
+      AND h.knowledge_base_code = d.knowledge_base_code
+      AND h.document_code = d.code
+      AND h.created_at >= sqlc.arg(since)
+      AND h.created_at < sqlc.arg(until)
+  )
+ORDER BY d.created_at ASC, d.id ASC
+LIMIT sqlc.arg(limit);
@@ -214,6 +214,101 @@ CREATE TABLE IF NOT EXISTS knowledge_base_ingestion_runs (
     KEY idx_kb_ingestion_run_source (organization_code, provider, source_code, started_at)
 );
 
+CREATE TABLE IF NOT EXISTS knowledge_base_eval_queries (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    organization_code VARCHAR(64) NOT NULL,
+    knowledge_base_code VARCHAR(64) NOT NULL,
+    set_code VARCHAR(64) NOT NULL,
+    query_key VARCHAR(128) NOT NULL,
+    query_text TEXT NOT NULL,
+    expected_document_codes JSON NOT NULL,
+    expected_fragment_ids JSON NOT NULL,
+    created_uid VARCHAR(64) NOT NULL DEFAULT '',
+    updated_uid VARCHAR(64) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uniq_kb_eval_query (organization_code, knowledge_base_code, set_code, query_key)
+);
+
+CREATE TABLE IF NOT EXISTS knowledge_base_eval_runs (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    organization_code VARCHAR(64) NOT NULL,
+    knowledge_base_code VARCHAR(64) NOT NULL,
+    set_code VARCHAR(64) NOT NULL,
+    label VARCHAR(255) NOT NULL DEFAULT '',
+    top_k INT UNSIGNED NOT NULL DEFAULT 0,
+    baseline_run_id BIGINT NOT NULL DEFAULT 0,
+    query_count INT UNSIGNED NOT NULL DEFAULT 0,
+    failed_count INT UNSIGNED NOT NULL DEFAULT 0,
+    metrics JSON NOT NULL,
+    results JSON NOT NULL,
+    created_uid VARCHAR(64) NOT NULL DEFAULT '',
+    started_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_kb_eval_run_set (organization_code, knowledge_base_code, set_code, id)
+);
+
+CREATE TABLE IF NOT EXISTS knowledge_base_query_logs (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    organization_code VARCHAR(64) NOT NULL,
+    knowledge_base_code VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) NOT NULL DEFAULT '',
+    source VARCHAR(32) NOT NULL DEFAULT '',
+    agent_code VARCHAR(64) NOT NULL DEFAULT '',
+    query_text VARCHAR(2048) NOT NULL DEFAULT '',
+    query_hash CHAR(64) NOT NULL,
+    query_type VARCHAR(32) NOT NULL DEFAULT '',
+    result_count INT UNSIGNED NOT NULL DEFAULT 0,
+    top_score DOUBLE NOT NULL DEFAULT 0,
+    latency_ms INT UNSIGNED NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_kb_query_logs_kb_time (organization_code, knowledge_base_code, created_at),
+    KEY idx_kb_query_logs_kb_hash (organization_code, knowledge_base_code, query_hash)
+);
+
+CREATE TABLE IF NOT EXISTS knowledge_base_query_log_hits (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    log_id BIGINT NOT NULL,
+    organization_code VARCHAR(64) NOT NULL,
+    knowledge_base_code VARCHAR(64) NOT NULL,
+    document_code VARCHAR(255) NOT NULL DEFAULT '',
+    fragment_id BIGINT NOT NULL DEFAULT 0,
+    score DOUBLE NOT NULL DEFAULT 0,
+    hit_rank INT UNSIGNED NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_kb_query_log_hits_kb_time_doc (organization_code, knowledge_base_code, created_at, document_code),
+    KEY idx_kb_query_log_hits_log (log_id)
+);
+
+CREATE TABLE IF NOT EXISTS knowledge_base_feedback (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    organization_code VARCHAR(64) NOT NULL,
+    knowledge_base_code VARCHAR(64) NOT NULL,
+    document_code VARCHAR(255) NOT NULL,
+    fragment_id BIGINT NOT NULL DEFAULT 0,
+    request_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) NOT NULL DEFAULT '',
+    signal_type VARCHAR(32) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_kb_feedback_request_target (request_id, user_id, knowledge_base_code, document_code, fragment_id, signal_type),
+    KEY idx_kb_feedback_kb_time (organization_code, knowledge_base_code, created_at)
+);
+
+CREATE TABLE IF NOT EXISTS knowledge_base_feedback_stats (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    organization_code VARCHAR(64) NOT NULL,
+    knowledge_base_code VARCHAR(64) NOT NULL,
+    document_code VARCHAR(255) NOT NULL,
+    fragment_id BIGINT NOT NULL DEFAULT 0,
+    positive_count BIGINT NOT NULL DEFAULT 0,
+    negative_count BIGINT NOT NULL DEFAULT 0,
+    answered_count BIGINT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_kb_feedback_stats_target (organization_code, knowledge_base_code, document_code, fragment_id)
+);
+
 CREATE TABLE IF NOT EXISTS knowledge_base_bindings (
     id BIGINT AUTO_INCREMENT PRIMARY KEY,
     knowledge_base_code VARCHAR(255) NOT NULL DEFAULT '',
@@ -634,6 +634,146 @@ func (q *Queries) InsertFragment(ctx context.Context, arg InsertFragmentParams)
 	)
 }
 
+const listFragmentsByDocumentChunkRange = `-- name: ListFragmentsByDocumentChunkRange :many
+SELECT id, knowledge_code, document_code, parent_fragment_id, version, content, metadata, business_id, sync_status, sync_times, sync_status_message, point_id, vector, word_count, created_uid, updated_uid, created_at, updated_at, deleted_at
+FROM magic_flow_knowledge_fragment
+WHERE deleted_at IS NULL
+  AND knowledge_code = ?
+  AND document_code = ?
+  AND CAST(JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.chunk_index')) AS SIGNED)
+    BETWEEN CAST(? AS SIGNED) AND CAST(? AS SIGNED)
+ORDER BY
+  CAST(JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.chunk_index')) AS SIGNED) ASC,
+  id ASC
+LIMIT ?
+`
+
+type ListFragmentsByDocumentChunkRangeParams struct {
+	KnowledgeCode   string      `json:"knowledge_code"`
+	DocumentCode    string      `json:"document_code"`
+	StartChunkIndex interface{} `json:"start_chunk_index"`
+	EndChunkIndex   interface{} `json:"end_chunk_index"`
+	Limit           int32       `json:"limit"`
+}
+
+func (q *Queries) ListFragmentsByDocumentChunkRange(ctx context.Context, arg ListFragmentsByDocumentChunkRangeParams) ([]MagicFlowKnowledgeFragment, error) {
+	rows, err := q.db.QueryContext(ctx, listFragmentsByDocumentChunkRange,
+		arg.KnowledgeCode,
+		arg.DocumentCode,
+		arg.StartChunkIndex,
+		arg.EndChunkIndex,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []MagicFlowKnowledgeFragment{}
+	for rows.Next() {
+		var i MagicFlowKnowledgeFragment
+		if err := rows.Scan(
+			&i.ID,
+			&i.KnowledgeCode,
+			&i.DocumentCode,
+			&i.ParentFragmentID,
+			&i.Version,
+			&i.Content,
+			&i.Metadata,
+			&i.BusinessID,
+			&i.SyncStatus,
+			&i.SyncTimes,
+			&i.SyncStatusMessage,
+			&i.PointID,
+			&i.Vector,
+			&i.WordCount,
+			&i.CreatedUid,
+			&i.UpdatedUid,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFragmentsByDocumentParentNode = `-- name: ListFragmentsByDocumentParentNode :many
+SELECT id, knowledge_code, document_code, parent_fragment_id, version, content, metadata, business_id, sync_status, sync_times, sync_status_message, point_id, vector, word_count, created_uid, updated_uid, created_at, updated_at, deleted_at
+FROM magic_flow_knowledge_fragment
+WHERE deleted_at IS NULL
+  AND knowledge_code = ?
+  AND document_code = ?
+  AND JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.parent_node_id')) = CAST(? AS CHAR(255))
+ORDER BY
+  CASE WHEN JSON_EXTRACT(metadata, '$.chunk_index') IS NULL THEN 1 ELSE 0 END ASC,
+  CAST(JSON_UNQUOTE(JSON_EXTRACT(metadata, '$.chunk_index')) AS SIGNED) ASC,
+  id ASC
+LIMIT ?
+`
+
+type ListFragmentsByDocumentParentNodeParams struct {
+	KnowledgeCode string      `json:"knowledge_code"`
+	DocumentCode  string      `json:"document_code"`
+	ParentNodeID  interface{} `json:"parent_node_id"`
+	Limit         int32       `json:"limit"`
+}
+
+func (q *Queries) ListFragmentsByDocumentParentNode(ctx context.Context, arg ListFragmentsByDocumentParentNodeParams) ([]MagicFlowKnowledgeFragment, error) {
+	rows, err := q.db.QueryContext(ctx, listFragmentsByDocumentParentNode,
+		arg.KnowledgeCode,
+		arg.DocumentCode,
+		arg.ParentNodeID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []MagicFlowKnowledgeFragment{}
+	for rows.Next() {
+		var i MagicFlowKnowledgeFragment
+		if err := rows.Scan(
+			&i.ID,
+			&i.KnowledgeCode,
+			&i.DocumentCode,
+			&i.ParentFragmentID,
+			&i.Version,
+			&i.Content,
+			&i.Metadata,
+			&i.BusinessID,
+			&i.SyncStatus,
+			&i.SyncTimes,
+			&i.SyncStatusMessage,
+			&i.PointID,
+			&i.Vector,
+			&i.WordCount,
+			&i.CreatedUid,
+			&i.UpdatedUid,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listFragmentsByKnowledge = `-- name: ListFragmentsByKnowledge :many
 SELECT id, knowledge_code, document_code, parent_fragment_id, version, content, metadata, business_id, sync_status, sync_times, sync_status_message, point_id, vector, word_count, created_uid, updated_uid, created_at, updated_at, deleted_at
 FROM magic_flow_knowledge_fragment
@@ -70,6 +70,38 @@ type KnowledgeBaseDocument struct {
 	ThirdFileID       sql.NullString `json:"third_file_id"`
 }
 
+type KnowledgeBaseEvalQuery struct {
+	ID                    int64     `json:"id"`
+	OrganizationCode      string    `json:"organization_code"`
+	KnowledgeBaseCode     string    `json:"knowledge_base_code"`
+	SetCode               string    `json:"set_code"`
+	QueryKey              string    `json:"query_key"`
+	QueryText             string    `json:"query_text"`
+	ExpectedDocumentCodes []byte    `json:"expected_document_codes"`
+	ExpectedFragmentIds   []byte    `json:"expected_fragment_ids"`
+	CreatedUid            string    `json:"created_uid"`
+	UpdatedUid            string    `json:"updated_uid"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+type KnowledgeBaseEvalRun struct {
+	ID                int64     `json:"id"`
+	OrganizationCode  string    `json:"organization_code"`
+	KnowledgeBaseCode string    `json:"knowledge_base_code"`
+	SetCode           string    `json:"set_code"`
+	Label             string    `json:"label"`
+	TopK              uint32    `json:"top_k"`
+	BaselineRunID     int64     `json:"baseline_run_id"`
+	QueryCount        uint32    `json:"query_count"`
+	FailedCount       uint32    `json:"failed_count"`
+	Metrics           []byte    `json:"metrics"`
+	Results           []byte    `json:"results"`
+	CreatedUid        string    `json:"created_uid"`
+	StartedAt         time.Time `json:"started_at"`
+	FinishedAt        time.Time `json:"finished_at"`
+}
+
 type KnowledgeBaseIngestionItem struct {
 	ID               int64        `json:"id"`
 	OrganizationCode string       `json:"organization_code"`
@@ -144,6 +176,60 @@ type KnowledgeBaseIngestionSource struct {
 	UpdatedAt        time.Time    `json:"updated_at"`
 }
 
+type KnowledgeBaseFeedback struct {
+	ID                int64     `json:"id"`
+	OrganizationCode  string    `json:"organization_code"`
+	KnowledgeBaseCode string    `json:"knowledge_base_code"`
+	DocumentCode      string    `json:"document_code"`
+	FragmentID        int64     `json:"fragment_id"`
+	RequestID         string    `json:"request_id"`
+	UserID            string    `json:"user_id"`
+	SignalType        string    `json:"signal_type"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type KnowledgeBaseFeedbackStat struct {
+	ID                int64     `json:"id"`
+	OrganizationCode  string    `json:"organization_code"`
+	KnowledgeBaseCode string    `json:"knowledge_base_code"`
+	DocumentCode      string    `json:"document_code"`
+	FragmentID        int64     `json:"fragment_id"`
+	PositiveCount     int64     `json:"positive_count"`
+	NegativeCount     int64     `json:"negative_count"`
+	AnsweredCount     int64     `json:"answered_count"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type KnowledgeBaseQueryLog struct {
+	ID                int64     `json:"id"`
+	RequestID         string    `json:"request_id"`
+	OrganizationCode  string    `json:"organization_code"`
+	KnowledgeBaseCode string    `json:"knowledge_base_code"`
+	UserID            string    `json:"user_id"`
+	Source            string    `json:"source"`
+	AgentCode         string    `json:"agent_code"`
+	QueryText         string    `json:"query_text"`
+	QueryHash         string    `json:"query_hash"`
+	QueryType         string    `json:"query_type"`
+	ResultCount       uint32    `json:"result_count"`
+	TopScore          float64   `json:"top_score"`
+	LatencyMs         uint32    `json:"latency_ms"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type KnowledgeBaseQueryLogHit struct {
+	ID                int64     `json:"id"`
+	LogID             int64     `json:"log_id"`
+	OrganizationCode  string    `json:"organization_code"`
+	KnowledgeBaseCode string    `json:"knowledge_base_code"`
+	DocumentCode      string    `json:"document_code"`
+	FragmentID        int64     `json:"fragment_id"`
+	Score             float64   `json:"score"`
+	HitRank           uint32    `json:"hit_rank"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
 type KnowledgeSourceBinding struct {
 	ID                int64     `json:"id"`
 	OrganizationCode  string    `json:"organization_code"`
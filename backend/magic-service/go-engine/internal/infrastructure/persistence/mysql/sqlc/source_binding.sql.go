@@ -1115,6 +1115,52 @@ func (q *Queries) ListRealtimeProjectSourceBindingsCoreByProject(ctx context.Con
 	return items, nil
 }
 
+const listRealtimeSourceBindingsCoreByProvider = `-- name: ListRealtimeSourceBindingsCoreByProvider :many
+SELECT knowledge_source_bindings.id, knowledge_source_bindings.organization_code, knowledge_source_bindings.knowledge_base_code, knowledge_source_bindings.provider, knowledge_source_bindings.root_type, knowledge_source_bindings.root_ref, knowledge_source_bindings.sync_mode, knowledge_source_bindings.sync_config, knowledge_source_bindings.enabled, knowledge_source_bindings.created_uid, knowledge_source_bindings.updated_uid, knowledge_source_bindings.created_at, knowledge_source_bindings.updated_at
+FROM knowledge_source_bindings
+WHERE provider = ?
+  AND sync_mode = 'realtime'
+  AND enabled = TRUE
+ORDER BY id ASC
+`
+
+func (q *Queries) ListRealtimeSourceBindingsCoreByProvider(ctx context.Context, provider string) ([]KnowledgeSourceBinding, error) {
+	rows, err := q.db.QueryContext(ctx, listRealtimeSourceBindingsCoreByProvider, provider)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []KnowledgeSourceBinding{}
+	for rows.Next() {
+		var i KnowledgeSourceBinding
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganizationCode,
+			&i.KnowledgeBaseCode,
+			&i.Provider,
+			&i.RootType,
+			&i.RootRef,
+			&i.SyncMode,
+			&i.SyncConfig,
+			&i.Enabled,
+			&i.CreatedUid,
+			&i.UpdatedUid,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listRealtimeTeamshareSourceBindingsCoreByKnowledgeBase = `-- name: ListRealtimeTeamshareSourceBindingsCoreByKnowledgeBase :many
 SELECT knowledge_source_bindings.id, knowledge_source_bindings.organization_code, knowledge_source_bindings.knowledge_base_code, knowledge_source_bindings.provider, knowledge_source_bindings.root_type, knowledge_source_bindings.root_ref, knowledge_source_bindings.sync_mode, knowledge_source_bindings.sync_config, knowledge_source_bindings.enabled, knowledge_source_bindings.created_uid, knowledge_source_bindings.updated_uid, knowledge_source_bindings.created_at, knowledge_source_bindings.updated_at
 FROM knowledge_source_bindings
@@ -1504,6 +1550,28 @@ func (q *Queries) UpdateKnowledgeSourceBindingByID(ctx context.Context, arg Upda
 	return result.RowsAffected()
 }
 
+const updateKnowledgeSourceBindingSyncConfigByID = `-- name: UpdateKnowledgeSourceBindingSyncConfigByID :execrows
+UPDATE knowledge_source_bindings
+SET sync_config = ?,
+    updated_at = ?
+WHERE id = ?
+`
+
+type UpdateKnowledgeSourceBindingSyncConfigByIDParams struct {
+	SyncConfig []byte    `json:"sync_config"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	ID         int64     `json:"id"`
+}
+
+// 只回写 sync_config（如 Git 来源的 indexed_commit），不触碰绑定根与目标。
+func (q *Queries) UpdateKnowledgeSourceBindingSyncConfigByID(ctx context.Context, arg UpdateKnowledgeSourceBindingSyncConfigByIDParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateKnowledgeSourceBindingSyncConfigByID, arg.SyncConfig, arg.UpdatedAt, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateKnowledgeSourceBindingsSyncModeRealtimeByIDs = `-- name: UpdateKnowledgeSourceBindingsSyncModeRealtimeByIDs :execrows
 UPDATE knowledge_source_bindings
 SET sync_mode = 'realtime',
@@ -39,6 +39,7 @@ type ocrConfigResponse struct {
 		Providers    []struct {
 			Provider  string `json:"provider"`
 			Enable    bool   `json:"enable"`
+			Endpoint  string `json:"endpoint"`
 			AccessKey string `json:"access_key"`
 			SecretKey string `json:"secret_key"`
 		} `json:"providers"`
@@ -115,6 +116,7 @@ func (c *PHPOCRConfigRPCClient) GetOCRConfig(ctx context.Context) (*documentdoma
 		cfg.Providers = append(cfg.Providers, documentdomain.OCRProviderConfig{
 			Provider:  strings.TrimSpace(provider.Provider),
 			Enable:    provider.Enable,
+			Endpoint:  strings.TrimSpace(provider.Endpoint),
 			AccessKey: strings.TrimSpace(provider.AccessKey),
 			SecretKey: strings.TrimSpace(provider.SecretKey),
 		})
@@ -263,6 +265,7 @@ func (c *PHPOCRConfigRPCClient) SetFetchHookForTest(
 		out.Data.Providers = make([]struct {
 			Provider  string `json:"provider"`
 			Enable    bool   `json:"enable"`
+			Endpoint  string `json:"endpoint"`
 			AccessKey string `json:"access_key"`
 			SecretKey string `json:"secret_key"`
 		}, 0, len(cfg.Providers))
@@ -270,11 +273,13 @@ func (c *PHPOCRConfigRPCClient) SetFetchHookForTest(
 			out.Data.Providers = append(out.Data.Providers, struct {
 				Provider  string `json:"provider"`
 				Enable    bool   `json:"enable"`
+				Endpoint  string `json:"endpoint"`
 				AccessKey string `json:"access_key"`
 				SecretKey string `json:"secret_key"`
 			}{
 				Provider:  provider.Provider,
 				Enable:    provider.Enable,
+				Endpoint:  provider.Endpoint,
 				AccessKey: provider.AccessKey,
 				SecretKey: provider.SecretKey,
 			})
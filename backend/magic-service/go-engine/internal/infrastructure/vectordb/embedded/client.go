@@ -0,0 +1,427 @@
+// Package embedded 提供进程内嵌入式向量库，适用于单机部署、CI 与测试环境。
+//
+// 每个集合在内存中维护 dense 向量（小集合精确扫描，超过阈值后构建 HNSW 图）与
+// 稀疏词项倒排索引（按 Qdrant IDF modifier 打分）；配置数据目录时以追加写日志持久化，
+// 集合与 alias 登记在 catalog.json 中。行为语义与 Qdrant 后端保持一致。
+package embedded
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	shared "magic/internal/domain/knowledge/shared"
+	"magic/internal/infrastructure/logging"
+)
+
+const (
+	// DefaultExactSearchThreshold 是未配置时走精确扫描的集合点数上限。
+	DefaultExactSearchThreshold = 4096
+
+	collectionFileHashBytes = 8
+)
+
+var (
+	// ErrInvalidInput 表示参数无效。
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrCollectionNotFound 表示集合不存在，与其他向量库后端共用同一哨兵错误。
+	ErrCollectionNotFound = shared.ErrVectorCollectionNotFound
+	// ErrCollectionAlreadyExists 表示集合或同名 alias 已存在。
+	ErrCollectionAlreadyExists = errors.New("collection already exists")
+	// ErrSparseDocumentUnsupported 表示嵌入式向量库不支持服务端稀疏文本推理，需由客户端构造 sparse vector。
+	ErrSparseDocumentUnsupported = errors.New("sparse document inference is not supported by embedded vector store")
+	// ErrClientClosed 表示客户端已关闭。
+	ErrClientClosed = errors.New("embedded vector store is closed")
+)
+
+// SimilarityResult 表示相似度搜索结果。
+type SimilarityResult struct {
+	ID       string         `json:"id"`
+	Score    float64        `json:"score"`
+	Payload  map[string]any `json:"payload"`
+	PointID  string         `json:"point_id"`
+	Content  string         `json:"content"`
+	Metadata map[string]any `json:"metadata"`
+}
+
+// Config 是嵌入式向量库配置。
+type Config struct {
+	// DataDir 为空时仅保存在内存中
+	DataDir string
+	// ExactSearchThreshold 集合点数不超过该值时走精确扫描，<=0 使用默认值
+	ExactSearchThreshold int
+	// HNSWM / HNSWEfConstruction / HNSWEfSearch 为 HNSW 图参数，<=0 使用默认值
+	HNSWM              int
+	HNSWEfConstruction int
+	HNSWEfSearch       int
+}
+
+// Client 是嵌入式向量库客户端，可被多个 goroutine 并发使用。
+type Client struct {
+	mu          sync.RWMutex
+	dataDir     string
+	exactLimit  int
+	hnsw        hnswParams
+	collections map[string]*collection
+	aliases     map[string]string
+	closed      bool
+	logger      *logging.SugaredLogger
+}
+
+// NewClient 创建嵌入式向量库；配置了数据目录时从磁盘恢复全部集合。
+func NewClient(cfg *Config, logger *logging.SugaredLogger) (*Client, error) {
+	client := &Client{
+		exactLimit:  DefaultExactSearchThreshold,
+		hnsw:        hnswParams{}.normalized(),
+		collections: map[string]*collection{},
+		aliases:     map[string]string{},
+		logger:      logger,
+	}
+	if cfg != nil {
+		client.dataDir = strings.TrimSpace(cfg.DataDir)
+		if cfg.ExactSearchThreshold > 0 {
+			client.exactLimit = cfg.ExactSearchThreshold
+		}
+		client.hnsw = hnswParams{M: cfg.HNSWM, EfConstruction: cfg.HNSWEfConstruction, EfSearch: cfg.HNSWEfSearch}.normalized()
+	}
+	if client.dataDir == "" {
+		return client, nil
+	}
+	if err := client.load(); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+func (c *Client) load() error {
+	if err := os.MkdirAll(filepath.Join(c.dataDir, collectionsDirName), dataDirPermission); err != nil {
+		return fmt.Errorf("create vector data dir: %w", err)
+	}
+	catalog, err := loadCatalog(c.dataDir)
+	if err != nil {
+		return err
+	}
+	for name, meta := range catalog.Collections {
+		target := newCollection(name, meta.File, meta.VectorSize, c.exactLimit, c.hnsw)
+		if meta.PayloadSchema != nil {
+			target.payloadSchema = meta.PayloadSchema
+		}
+		path := c.collectionPath(meta.File)
+		records, err := replayLog(path, target)
+		if err != nil {
+			return err
+		}
+		if target.log, err = openPointLog(path); err != nil {
+			return err
+		}
+		target.log.records = records
+		if err := target.compactIfNeeded(); err != nil {
+			return err
+		}
+		c.collections[name] = target
+	}
+	for alias, name := range catalog.Aliases {
+		if _, ok := c.collections[name]; ok {
+			c.aliases[alias] = name
+		}
+	}
+	return nil
+}
+
+// Close 刷新并关闭所有集合日志，可重复调用。
+func (c *Client) Close() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	var errs []error
+	for _, target := range c.collections {
+		target.mu.Lock()
+		errs = append(errs, target.log.close())
+		target.mu.Unlock()
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("close embedded vector store: %w", err)
+	}
+	return nil
+}
+
+// HealthCheck 实现 health.Checker。
+func (c *Client) HealthCheck(context.Context) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return ErrClientClosed
+	}
+	return nil
+}
+
+// CreateCollection 创建集合。
+func (c *Client) CreateCollection(ctx context.Context, name string, vectorSize int64) error {
+	if vectorSize <= 0 {
+		return fmt.Errorf("%w: vector size must be positive", ErrInvalidInput)
+	}
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: collection name is required", ErrInvalidInput)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClientClosed
+	}
+	if _, exists := c.collections[name]; exists {
+		return fmt.Errorf("%w: %s", ErrCollectionAlreadyExists, name)
+	}
+	if _, isAlias := c.aliases[name]; isAlias {
+		return fmt.Errorf("%w: %s is an alias", ErrCollectionAlreadyExists, name)
+	}
+
+	target := newCollection(name, collectionFileName(name), vectorSize, c.exactLimit, c.hnsw)
+	if c.dataDir != "" {
+		path := c.collectionPath(target.file)
+		// 同名集合曾被删除时可能残留旧日志，新集合必须从空日志开始。
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("reset collection log %s: %w", name, err)
+		}
+		log, err := openPointLog(path)
+		if err != nil {
+			return err
+		}
+		target.log = log
+	}
+	c.collections[name] = target
+	if err := c.saveCatalogLocked(); err != nil {
+		delete(c.collections, name)
+		_ = target.log.close()
+		return err
+	}
+	c.logInfo(ctx, "Created embedded vector collection", "name", name, "vectorSize", vectorSize)
+	return nil
+}
+
+// CollectionExists 检查物理集合是否存在（alias 不计入）。
+func (c *Client) CollectionExists(_ context.Context, name string) (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, exists := c.collections[name]
+	return exists, nil
+}
+
+// ListCollections 列出所有物理集合名称。
+func (c *Client) ListCollections(context.Context) ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Sorted(maps.Keys(c.collections)), nil
+}
+
+// GetCollectionInfo 获取集合信息，name 可以是 alias。
+func (c *Client) GetCollectionInfo(_ context.Context, name string) (*fragmodel.VectorCollectionInfo, error) {
+	target, err := c.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	target.mu.RLock()
+	defer target.mu.RUnlock()
+	return &fragmodel.VectorCollectionInfo{
+		Name:                name,
+		VectorSize:          target.vectorSize,
+		Points:              int64(len(target.points)),
+		HasNamedDenseVector: true,
+		HasSparseVector:     true,
+		PayloadSchemaKeys:   slices.Sorted(maps.Keys(target.payloadSchema)),
+	}, nil
+}
+
+// EnsurePayloadIndexes 登记 payload 索引声明；过滤在内存中完成，这里只维护 PayloadSchemaKeys。
+func (c *Client) EnsurePayloadIndexes(_ context.Context, collectionName string, specs []shared.PayloadIndexSpec) error {
+	declared := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		normalized := spec.Normalize()
+		if !normalized.Valid() {
+			return fmt.Errorf("%w: invalid payload index spec field=%q kind=%q", ErrInvalidInput, spec.FieldName, spec.Kind)
+		}
+		declared[normalized.FieldName] = string(normalized.Kind)
+	}
+	if len(declared) == 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	target, err := c.resolveLocked(collectionName)
+	if err != nil {
+		return err
+	}
+	target.mu.Lock()
+	changed := false
+	for field, kind := range declared {
+		if _, exists := target.payloadSchema[field]; !exists {
+			target.payloadSchema[field] = kind
+			changed = true
+		}
+	}
+	target.mu.Unlock()
+	if !changed {
+		return nil
+	}
+	return c.saveCatalogLocked()
+}
+
+// DeleteCollection 删除集合及指向它的 alias，集合不存在时视为成功。
+func (c *Client) DeleteCollection(ctx context.Context, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	target, exists := c.collections[name]
+	if !exists {
+		return nil
+	}
+	delete(c.collections, name)
+	maps.DeleteFunc(c.aliases, func(_ string, collectionName string) bool { return collectionName == name })
+	if err := c.saveCatalogLocked(); err != nil {
+		return err
+	}
+
+	target.mu.Lock()
+	defer target.mu.Unlock()
+	if err := target.log.close(); err != nil {
+		return err
+	}
+	if c.dataDir != "" {
+		if err := os.Remove(c.collectionPath(target.file)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete collection %s: %w", name, err)
+		}
+	}
+	// 已持有该集合引用的并发请求只会作用在已脱离登记的内存对象上。
+	target.points, target.postings, target.index, target.log = map[string]*storedPoint{}, map[uint32]map[string]float32{}, nil, nil
+	c.logInfo(ctx, "Deleted embedded vector collection", "name", name)
+	return nil
+}
+
+// GetAliasTarget 查询 alias 当前指向的物理集合。
+func (c *Client) GetAliasTarget(_ context.Context, alias string) (string, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	target, ok := c.aliases[alias]
+	return target, ok, nil
+}
+
+// EnsureAlias 确保 alias 指向目标物理集合。
+func (c *Client) EnsureAlias(_ context.Context, alias, target string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if current, ok := c.aliases[alias]; ok && current == target {
+		return nil
+	}
+	return c.setAliasLocked(alias, target)
+}
+
+// SwapAliasAtomically 原子切换 alias 指向。
+func (c *Client) SwapAliasAtomically(_ context.Context, alias, _, newTarget string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.setAliasLocked(alias, newTarget)
+}
+
+// DeleteAlias 删除 alias。
+func (c *Client) DeleteAlias(_ context.Context, alias string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.aliases[alias]; !ok {
+		return nil
+	}
+	delete(c.aliases, alias)
+	return c.saveCatalogLocked()
+}
+
+func (c *Client) setAliasLocked(alias, target string) error {
+	if _, ok := c.collections[target]; !ok {
+		return fmt.Errorf("failed to swap alias %s to %s: %w", alias, target, ErrCollectionNotFound)
+	}
+	if _, ok := c.collections[alias]; ok {
+		return fmt.Errorf("%w: alias %s conflicts with collection", ErrCollectionAlreadyExists, alias)
+	}
+	previous, hadPrevious := c.aliases[alias]
+	c.aliases[alias] = target
+	if err := c.saveCatalogLocked(); err != nil {
+		if hadPrevious {
+			c.aliases[alias] = previous
+		} else {
+			delete(c.aliases, alias)
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *Client) resolve(name string) (*collection, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.resolveLocked(name)
+}
+
+// resolveLocked 将集合名或 alias 解析为物理集合，调用方需持有客户端锁。
+func (c *Client) resolveLocked(name string) (*collection, error) {
+	if c.closed {
+		return nil, ErrClientClosed
+	}
+	if target, ok := c.collections[name]; ok {
+		return target, nil
+	}
+	if physical, ok := c.aliases[name]; ok {
+		if target, exists := c.collections[physical]; exists {
+			return target, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrCollectionNotFound, name)
+}
+
+func (c *Client) saveCatalogLocked() error {
+	if c.dataDir == "" {
+		return nil
+	}
+	catalog := &catalogFile{
+		Collections: make(map[string]catalogCollection, len(c.collections)),
+		Aliases:     maps.Clone(c.aliases),
+	}
+	for name, target := range c.collections {
+		target.mu.RLock()
+		catalog.Collections[name] = catalogCollection{
+			File:          target.file,
+			VectorSize:    target.vectorSize,
+			PayloadSchema: maps.Clone(target.payloadSchema),
+		}
+		target.mu.RUnlock()
+	}
+	return saveCatalog(c.dataDir, catalog)
+}
+
+func (c *Client) collectionPath(file string) string {
+	return filepath.Join(c.dataDir, collectionsDirName, file)
+}
+
+func (c *Client) logInfo(ctx context.Context, msg string, keysAndValues ...any) {
+	if c.logger == nil {
+		return
+	}
+	c.logger.InfoContext(ctx, msg, keysAndValues...)
+}
+
+// collectionFileName 由集合名派生稳定且文件系统安全的日志文件名。
+func collectionFileName(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:collectionFileHashBytes]) + ".jsonl"
+}
@@ -0,0 +1,308 @@
+package embedded_test
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	shared "magic/internal/domain/knowledge/shared"
+	"magic/internal/infrastructure/logging"
+	"magic/internal/infrastructure/vectordb/embedded"
+)
+
+const testCollection = "kb_embedded"
+
+func newTestClient(t *testing.T, cfg *embedded.Config) *embedded.Client {
+	t.Helper()
+	client, err := embedded.NewClient(cfg, logging.New())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestClientPersistsAcrossReopen(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dataDir := t.TempDir()
+
+	client, err := embedded.NewClient(&embedded.Config{DataDir: dataDir}, logging.New())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := client.CreateCollection(ctx, testCollection, 2); err != nil {
+		t.Fatalf("CreateCollection: %v", err)
+	}
+	if err := client.EnsureAlias(ctx, "kb_active", testCollection); err != nil {
+		t.Fatalf("EnsureAlias: %v", err)
+	}
+	err = client.StoreHybridPoints(ctx, "kb_active",
+		[]string{"a", "b", "c"},
+		[][]float64{{1, 0}, {0, 1}, {1, 1}},
+		[]*fragmodel.SparseInput{{Vector: &fragmodel.SparseVector{Indices: []uint32{7}, Values: []float32{1}}}, nil, nil},
+		[]map[string]any{{"content": "alpha"}, {"content": "beta"}, {"content": "gamma"}},
+	)
+	if err != nil {
+		t.Fatalf("StoreHybridPoints: %v", err)
+	}
+	if err := client.SetPayloadByPointIDs(ctx, testCollection, map[string]map[string]any{"b": {"content": "beta v2"}}); err != nil {
+		t.Fatalf("SetPayloadByPointIDs: %v", err)
+	}
+	if err := client.DeletePoint(ctx, testCollection, "c"); err != nil {
+		t.Fatalf("DeletePoint: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened := newTestClient(t, &embedded.Config{DataDir: dataDir})
+	target, ok, err := reopened.GetAliasTarget(ctx, "kb_active")
+	if err != nil || !ok || target != testCollection {
+		t.Fatalf("alias not restored: target=%q ok=%v err=%v", target, ok, err)
+	}
+	existing, err := reopened.ListExistingPointIDs(ctx, "kb_active", []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("ListExistingPointIDs: %v", err)
+	}
+	if len(existing) != 2 {
+		t.Fatalf("expected points a and b after reopen, got %v", existing)
+	}
+	results, err := reopened.SearchDenseWithFilter(ctx, fragmodel.DenseSearchRequest{Collection: "kb_active", Vector: []float64{0, 1}, TopK: 1})
+	if err != nil || len(results) != 1 || results[0].Content != "beta v2" {
+		t.Fatalf("unexpected dense results after reopen: %+v err=%v", results, err)
+	}
+	sparse, err := reopened.SearchSparseWithFilter(ctx, fragmodel.SparseSearchRequest{
+		Collection: testCollection,
+		Vector:     &fragmodel.SparseVector{Indices: []uint32{7}, Values: []float32{1}},
+		TopK:       5,
+	})
+	if err != nil || len(sparse) != 1 || sparse[0].PointID != "a" {
+		t.Fatalf("unexpected sparse results after reopen: %+v err=%v", sparse, err)
+	}
+}
+
+func TestClientRecoversFromTornLogTail(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dataDir := t.TempDir()
+
+	client, err := embedded.NewClient(&embedded.Config{DataDir: dataDir}, logging.New())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := client.CreateCollection(ctx, testCollection, 2); err != nil {
+		t.Fatalf("CreateCollection: %v", err)
+	}
+	if err := client.StoreHybridPoint(ctx, testCollection, "a", []float64{1, 0}, nil, map[string]any{"content": "alpha"}); err != nil {
+		t.Fatalf("StoreHybridPoint: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	logPath := singleCollectionLog(t, dataDir)
+	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	if _, err := file.WriteString(`{"op":"upsert","id":"b","vec`); err != nil {
+		t.Fatalf("write torn record: %v", err)
+	}
+	_ = file.Close()
+
+	reopened := newTestClient(t, &embedded.Config{DataDir: dataDir})
+	if err := reopened.StoreHybridPoint(ctx, testCollection, "c", []float64{0, 1}, nil, nil); err != nil {
+		t.Fatalf("StoreHybridPoint after recovery: %v", err)
+	}
+	existing, err := reopened.ListExistingPointIDs(ctx, testCollection, []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("ListExistingPointIDs: %v", err)
+	}
+	if _, ok := existing["a"]; !ok || len(existing) != 2 {
+		t.Fatalf("expected a and c to survive torn tail, got %v", existing)
+	}
+}
+
+func TestClientRejectsCorruptedLog(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dataDir := t.TempDir()
+
+	client, err := embedded.NewClient(&embedded.Config{DataDir: dataDir}, logging.New())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := client.CreateCollection(ctx, testCollection, 2); err != nil {
+		t.Fatalf("CreateCollection: %v", err)
+	}
+	if err := client.StoreHybridPoint(ctx, testCollection, "a", []float64{1, 0}, nil, nil); err != nil {
+		t.Fatalf("StoreHybridPoint: %v", err)
+	}
+	_ = client.Close()
+
+	logPath := singleCollectionLog(t, dataDir)
+	raw, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if err := os.WriteFile(logPath, append([]byte("not json\n"), raw...), 0o600); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+	if _, err := embedded.NewClient(&embedded.Config{DataDir: dataDir}, logging.New()); err == nil {
+		t.Fatal("expected corrupted log to be rejected")
+	}
+}
+
+func TestClientCompactsOverwrittenPoints(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dataDir := t.TempDir()
+	client := newTestClient(t, &embedded.Config{DataDir: dataDir})
+	if err := client.CreateCollection(ctx, testCollection, 2); err != nil {
+		t.Fatalf("CreateCollection: %v", err)
+	}
+
+	const writes = 1500
+	ids := make([]string, writes)
+	vectors := make([][]float64, writes)
+	payloads := make([]map[string]any, writes)
+	for i := range writes {
+		ids[i] = "same"
+		vectors[i] = []float64{1, float64(i)}
+		payloads[i] = map[string]any{"version": i}
+	}
+	if err := client.StoreHybridPoints(ctx, testCollection, ids, vectors, nil, payloads); err != nil {
+		t.Fatalf("StoreHybridPoints: %v", err)
+	}
+
+	raw, err := os.ReadFile(singleCollectionLog(t, dataDir))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if lines := strings.Count(string(raw), "\n"); lines != 1 {
+		t.Fatalf("expected compacted log with 1 record, got %d", lines)
+	}
+	results, err := client.SearchDenseWithFilter(ctx, fragmodel.DenseSearchRequest{Collection: testCollection, Vector: []float64{0, 1}, TopK: 1})
+	if err != nil || len(results) != 1 || results[0].Payload["version"] != float64(writes-1) {
+		t.Fatalf("expected latest version after compaction, got %+v err=%v", results, err)
+	}
+}
+
+func TestClientCollectionAndAliasErrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client := newTestClient(t, &embedded.Config{})
+	if err := client.CreateCollection(ctx, testCollection, 2); err != nil {
+		t.Fatalf("CreateCollection: %v", err)
+	}
+	if err := client.CreateCollection(ctx, testCollection, 2); !errors.Is(err, embedded.ErrCollectionAlreadyExists) {
+		t.Fatalf("expected ErrCollectionAlreadyExists, got %v", err)
+	}
+	if err := client.EnsureAlias(ctx, "kb_alias", "missing"); !errors.Is(err, shared.ErrVectorCollectionNotFound) {
+		t.Fatalf("expected alias to missing collection to fail, got %v", err)
+	}
+	if err := client.EnsureAlias(ctx, "kb_alias", testCollection); err != nil {
+		t.Fatalf("EnsureAlias: %v", err)
+	}
+	if err := client.CreateCollection(ctx, "kb_alias", 2); !errors.Is(err, embedded.ErrCollectionAlreadyExists) {
+		t.Fatalf("expected collection named after alias to be rejected, got %v", err)
+	}
+	if err := client.StoreHybridPoint(ctx, testCollection, "a", []float64{1, 0}, &fragmodel.SparseInput{
+		Document: &fragmodel.SparseDocument{Text: "hello"},
+	}, nil); !errors.Is(err, embedded.ErrSparseDocumentUnsupported) {
+		t.Fatalf("expected ErrSparseDocumentUnsupported, got %v", err)
+	}
+	if err := client.DeletePointsByFilter(ctx, testCollection, &fragmodel.VectorFilter{}); !errors.Is(err, embedded.ErrInvalidInput) {
+		t.Fatalf("expected empty filter delete to be rejected, got %v", err)
+	}
+	if err := client.DeleteCollection(ctx, testCollection); err != nil {
+		t.Fatalf("DeleteCollection: %v", err)
+	}
+	if _, ok, _ := client.GetAliasTarget(ctx, "kb_alias"); ok {
+		t.Fatal("expected alias to be dropped with its collection")
+	}
+}
+
+// TestHNSWRecall 对比 HNSW 与精确扫描的 top10，召回率需不低于 0.9。
+func TestHNSWRecall(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	const (
+		points     = 2000
+		dimensions = 32
+		queries    = 50
+		topK       = 10
+	)
+	approximate := newTestClient(t, &embedded.Config{ExactSearchThreshold: 1})
+	exact := newTestClient(t, &embedded.Config{ExactSearchThreshold: points * 2})
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	ids := make([]string, points)
+	vectors := make([][]float64, points)
+	payloads := make([]map[string]any, points)
+	for i := range points {
+		ids[i] = "p" + strconv.Itoa(i)
+		vectors[i] = randomVector(rng, dimensions)
+		payloads[i] = map[string]any{"bucket": float64(i % 4)}
+	}
+	for _, client := range []*embedded.Client{approximate, exact} {
+		if err := client.CreateCollection(ctx, testCollection, dimensions); err != nil {
+			t.Fatalf("CreateCollection: %v", err)
+		}
+		if err := client.StoreHybridPoints(ctx, testCollection, ids, vectors, nil, payloads); err != nil {
+			t.Fatalf("StoreHybridPoints: %v", err)
+		}
+	}
+
+	hits := 0
+	for range queries {
+		request := fragmodel.DenseSearchRequest{Collection: testCollection, Vector: randomVector(rng, dimensions), TopK: topK}
+		expected := searchIDs(t, exact, request)
+		actual := searchIDs(t, approximate, request)
+		for id := range actual {
+			if _, ok := expected[id]; ok {
+				hits++
+			}
+		}
+	}
+	if recall := float64(hits) / float64(queries*topK); recall < 0.9 {
+		t.Fatalf("expected HNSW recall@%d >= 0.9, got %.3f", topK, recall)
+	}
+}
+
+func searchIDs(t *testing.T, client *embedded.Client, request fragmodel.DenseSearchRequest) map[string]struct{} {
+	t.Helper()
+	results, err := client.SearchDenseWithFilter(context.Background(), request)
+	if err != nil {
+		t.Fatalf("SearchDenseWithFilter: %v", err)
+	}
+	ids := make(map[string]struct{}, len(results))
+	for _, result := range results {
+		ids[result.PointID] = struct{}{}
+	}
+	return ids
+}
+
+func randomVector(rng *rand.Rand, dimensions int) []float64 {
+	vector := make([]float64, dimensions)
+	for i := range vector {
+		vector[i] = rng.NormFloat64()
+	}
+	return vector
+}
+
+func singleCollectionLog(t *testing.T, dataDir string) string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dataDir, "collections", "*.jsonl"))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("expected a single collection log, got %v err=%v", matches, err)
+	}
+	return matches[0]
+}
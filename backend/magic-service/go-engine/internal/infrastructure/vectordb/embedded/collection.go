@@ -0,0 +1,211 @@
+package embedded
+
+import (
+	"cmp"
+	"maps"
+	"math"
+	"slices"
+	"sync"
+
+	shared "magic/internal/domain/knowledge/shared"
+)
+
+// collection 是单个物理集合的内存状态：点、稀疏倒排索引以及按需构建的 HNSW 图。
+type collection struct {
+	mu            sync.RWMutex
+	name          string
+	file          string
+	vectorSize    int64
+	payloadSchema map[string]string
+	points        map[string]*storedPoint
+	postings      map[uint32]map[string]float32
+	index         *hnswIndex
+	hnsw          hnswParams
+	exactLimit    int
+	log           *pointLog
+}
+
+// storedPoint 是集合中的一个点；payload 始终为 JSON 解码后的结构，便于过滤与持久化。
+type storedPoint struct {
+	vector  []float32
+	payload map[string]any
+	sparse  map[uint32]float32
+}
+
+type scoredPoint struct {
+	id    string
+	score float64
+}
+
+func newCollection(name, file string, vectorSize int64, exactLimit int, params hnswParams) *collection {
+	return &collection{
+		name:          name,
+		file:          file,
+		vectorSize:    vectorSize,
+		payloadSchema: map[string]string{},
+		points:        map[string]*storedPoint{},
+		postings:      map[uint32]map[string]float32{},
+		hnsw:          params,
+		exactLimit:    exactLimit,
+	}
+}
+
+// applyUpsert 整点覆盖写入，调用方需持有写锁。
+func (c *collection) applyUpsert(id string, point *storedPoint) {
+	c.removeSparse(id)
+	c.points[id] = point
+	for term, weight := range point.sparse {
+		postings := c.postings[term]
+		if postings == nil {
+			postings = map[string]float32{}
+			c.postings[term] = postings
+		}
+		postings[id] = weight
+	}
+	if c.index != nil {
+		c.index.upsert(id, point.vector)
+		return
+	}
+	c.maybeBuildIndex()
+}
+
+// applyDelete 删除点，调用方需持有写锁。
+func (c *collection) applyDelete(id string) {
+	if _, ok := c.points[id]; !ok {
+		return
+	}
+	c.removeSparse(id)
+	delete(c.points, id)
+	if c.index == nil {
+		return
+	}
+	c.index.remove(id)
+	if len(c.points) <= c.exactLimit {
+		c.index = nil
+		return
+	}
+	if c.index.needsRebuild() {
+		c.rebuildIndex()
+	}
+}
+
+// applySetPayload 合并顶层 payload 键，调用方需持有写锁。
+func (c *collection) applySetPayload(id string, payload map[string]any) {
+	point, ok := c.points[id]
+	if !ok {
+		return
+	}
+	merged := maps.Clone(point.payload)
+	if merged == nil {
+		merged = map[string]any{}
+	}
+	maps.Copy(merged, payload)
+	point.payload = merged
+}
+
+func (c *collection) removeSparse(id string) {
+	previous, ok := c.points[id]
+	if !ok {
+		return
+	}
+	for term := range previous.sparse {
+		postings := c.postings[term]
+		delete(postings, id)
+		if len(postings) == 0 {
+			delete(c.postings, term)
+		}
+	}
+}
+
+func (c *collection) maybeBuildIndex() {
+	if c.index == nil && len(c.points) > c.exactLimit {
+		c.rebuildIndex()
+	}
+}
+
+func (c *collection) rebuildIndex() {
+	index := newHNSWIndex(c.hnsw)
+	ids := slices.Sorted(maps.Keys(c.points))
+	for _, id := range ids {
+		index.upsert(id, c.points[id].vector)
+	}
+	c.index = index
+}
+
+// searchDense 返回满足过滤与阈值的 topK 个点，调用方需持有读锁。
+//
+// 小集合或 HNSW 过滤后结果不足 topK 时回退为精确扫描，保证过滤语义不因近似检索丢失结果。
+func (c *collection) searchDense(query []float32, topK int, threshold float64, filter *shared.VectorFilter) []scoredPoint {
+	accept := func(id string) bool { return matchFilter(filter, c.points[id].payload) }
+	if c.index != nil {
+		candidates := c.index.search(query, topK, accept)
+		results := make([]scoredPoint, 0, min(topK, len(candidates)))
+		belowThreshold := false
+		for _, candidate := range candidates {
+			score := float64(1 - candidate.distance)
+			if score < threshold {
+				belowThreshold = true
+				break
+			}
+			if len(results) == topK {
+				break
+			}
+			results = append(results, scoredPoint{id: c.index.ids[candidate.node], score: score})
+		}
+		if len(results) == topK || belowThreshold {
+			return results
+		}
+	}
+	results := make([]scoredPoint, 0)
+	for id, point := range c.points {
+		score := float64(dot(query, point.vector))
+		if score < threshold || !accept(id) {
+			continue
+		}
+		results = append(results, scoredPoint{id: id, score: score})
+	}
+	return topScored(results, topK)
+}
+
+// searchSparse 按 Qdrant IDF modifier 打分：Σ q·d·ln((N - n + 0.5) / (n + 0.5) + 1)，调用方需持有读锁。
+func (c *collection) searchSparse(query map[uint32]float32, topK int, threshold float64, filter *shared.VectorFilter) []scoredPoint {
+	total := float64(len(c.points))
+	scores := map[string]float64{}
+	for term, queryWeight := range query {
+		postings := c.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log((total-df+0.5)/(df+0.5) + 1)
+		for id, weight := range postings {
+			scores[id] += float64(queryWeight) * float64(weight) * idf
+		}
+	}
+	results := make([]scoredPoint, 0, len(scores))
+	for id, score := range scores {
+		if score < threshold || !matchFilter(filter, c.points[id].payload) {
+			continue
+		}
+		results = append(results, scoredPoint{id: id, score: score})
+	}
+	return topScored(results, topK)
+}
+
+// topScored 按分数降序、point_id 升序截取前 topK 个。
+func topScored(results []scoredPoint, topK int) []scoredPoint {
+	slices.SortFunc(results, func(a, b scoredPoint) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		default:
+			return cmp.Compare(a.id, b.id)
+		}
+	})
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results
+}
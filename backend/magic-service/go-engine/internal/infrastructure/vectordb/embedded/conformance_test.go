@@ -0,0 +1,44 @@
+package embedded_test
+
+import (
+	"testing"
+
+	"magic/internal/infrastructure/logging"
+	"magic/internal/infrastructure/vectordb/embedded"
+	"magic/internal/infrastructure/vectordb/vectordbtest"
+)
+
+func TestEmbeddedConformanceInMemory(t *testing.T) {
+	t.Parallel()
+	vectordbtest.Run(t, func(t *testing.T) vectordbtest.Backend {
+		return newConformanceBackend(t, &embedded.Config{})
+	})
+}
+
+func TestEmbeddedConformanceOnDisk(t *testing.T) {
+	t.Parallel()
+	vectordbtest.Run(t, func(t *testing.T) vectordbtest.Backend {
+		return newConformanceBackend(t, &embedded.Config{DataDir: t.TempDir()})
+	})
+}
+
+// TestEmbeddedConformanceHNSW 让每个集合都走 HNSW 图，覆盖近似检索路径上的过滤与阈值语义。
+func TestEmbeddedConformanceHNSW(t *testing.T) {
+	t.Parallel()
+	vectordbtest.Run(t, func(t *testing.T) vectordbtest.Backend {
+		return newConformanceBackend(t, &embedded.Config{ExactSearchThreshold: 1})
+	})
+}
+
+func newConformanceBackend(t *testing.T, cfg *embedded.Config) vectordbtest.Backend {
+	t.Helper()
+	client, err := embedded.NewClient(cfg, logging.New())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return vectordbtest.Backend{
+		Management: embedded.NewVectorDBManagementRepository(client),
+		Data:       embedded.NewVectorDBDataRepository[vectordbtest.Payload](client),
+	}
+}
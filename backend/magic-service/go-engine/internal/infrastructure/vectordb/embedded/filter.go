@@ -0,0 +1,148 @@
+package embedded
+
+import (
+	"slices"
+	"strings"
+
+	shared "magic/internal/domain/knowledge/shared"
+)
+
+// matchFilter 在内存 payload 上评估 VectorFilter，语义对齐 Qdrant：
+// must 全部满足、should 至少满足一个、must_not 全部不满足；数组字段命中任一元素即视为匹配。
+func matchFilter(filter *shared.VectorFilter, payload map[string]any) bool {
+	if filter == nil {
+		return true
+	}
+	for _, condition := range filter.Must {
+		if isEmptyCondition(condition) {
+			continue
+		}
+		if !matchField(condition, payload) {
+			return false
+		}
+	}
+	shouldMatched, shouldDeclared := false, false
+	for _, condition := range filter.Should {
+		if isEmptyCondition(condition) {
+			continue
+		}
+		shouldDeclared = true
+		if matchField(condition, payload) {
+			shouldMatched = true
+			break
+		}
+	}
+	if shouldDeclared && !shouldMatched {
+		return false
+	}
+	for _, condition := range filter.MustNot {
+		if isEmptyCondition(condition) {
+			continue
+		}
+		if matchField(condition, payload) {
+			return false
+		}
+	}
+	return true
+}
+
+func isFilterEmpty(filter *shared.VectorFilter) bool {
+	if filter == nil {
+		return true
+	}
+	for _, group := range [][]shared.FieldFilter{filter.Must, filter.Should, filter.MustNot} {
+		for _, condition := range group {
+			if !isEmptyCondition(condition) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isEmptyCondition(condition shared.FieldFilter) bool {
+	if strings.TrimSpace(condition.Key) == "" {
+		return true
+	}
+	match := condition.Match
+	return !hasRangeBound(match.Range) && match.EqString == nil && match.EqBool == nil &&
+		match.EqFloat == nil && len(match.InStrings) == 0 && len(match.InFloats) == 0
+}
+
+func matchField(condition shared.FieldFilter, payload map[string]any) bool {
+	return slices.ContainsFunc(lookupPayloadValues(payload, condition.Key), func(value any) bool {
+		return matchValue(condition.Match, value)
+	})
+}
+
+// matchValue 按与其他后端一致的优先级（range > eq_string > eq_bool > eq_float > in_strings > in_floats）取第一个有效条件。
+func matchValue(match shared.Match, value any) bool {
+	switch {
+	case hasRangeBound(match.Range):
+		number, ok := value.(float64)
+		return ok && inRange(match.Range, number)
+	case match.EqString != nil:
+		text, ok := value.(string)
+		return ok && text == *match.EqString
+	case match.EqBool != nil:
+		flag, ok := value.(bool)
+		return ok && flag == *match.EqBool
+	case match.EqFloat != nil:
+		number, ok := value.(float64)
+		return ok && number == *match.EqFloat
+	case len(match.InStrings) > 0:
+		text, ok := value.(string)
+		return ok && slices.Contains(match.InStrings, text)
+	case len(match.InFloats) > 0:
+		number, ok := value.(float64)
+		return ok && slices.Contains(match.InFloats, number)
+	default:
+		return false
+	}
+}
+
+func hasRangeBound(r *shared.Range) bool {
+	return r != nil && (r.Lt != nil || r.Gt != nil || r.Gte != nil || r.Lte != nil)
+}
+
+func inRange(r *shared.Range, value float64) bool {
+	return (r.Lt == nil || value < *r.Lt) &&
+		(r.Gt == nil || value > *r.Gt) &&
+		(r.Gte == nil || value >= *r.Gte) &&
+		(r.Lte == nil || value <= *r.Lte)
+}
+
+// lookupPayloadValues 按点分路径取值，途经的数组会被展开，末端数组按元素返回。
+func lookupPayloadValues(payload map[string]any, key string) []any {
+	current := []any{payload}
+	for part := range strings.SplitSeq(key, ".") {
+		part = strings.TrimSuffix(strings.TrimSpace(part), "[]")
+		next := make([]any, 0, len(current))
+		for _, value := range flattenArrays(current) {
+			object, ok := value.(map[string]any)
+			if !ok {
+				continue
+			}
+			if child, exists := object[part]; exists {
+				next = append(next, child)
+			}
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		current = next
+	}
+	return flattenArrays(current)
+}
+
+func flattenArrays(values []any) []any {
+	flattened := make([]any, 0, len(values))
+	for _, value := range values {
+		if items, ok := value.([]any); ok {
+			flattened = append(flattened, items...)
+			continue
+		}
+		flattened = append(flattened, value)
+	}
+	return flattened
+}
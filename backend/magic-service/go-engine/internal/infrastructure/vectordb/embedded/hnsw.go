@@ -0,0 +1,296 @@
+package embedded
+
+import (
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+const (
+	defaultHNSWM              = 16
+	defaultHNSWEfConstruction = 128
+	defaultHNSWEfSearch       = 96
+	hnswSeedHi                = 0x6d61676963
+	hnswSeedLo                = 0x766563746f72
+)
+
+// hnswParams 是 HNSW 图的构建与检索参数。
+type hnswParams struct {
+	M              int
+	EfConstruction int
+	EfSearch       int
+}
+
+func (p hnswParams) normalized() hnswParams {
+	if p.M <= 1 {
+		p.M = defaultHNSWM
+	}
+	if p.EfConstruction <= 0 {
+		p.EfConstruction = defaultHNSWEfConstruction
+	}
+	if p.EfSearch <= 0 {
+		p.EfSearch = defaultHNSWEfSearch
+	}
+	return p
+}
+
+// hnswIndex 是基于余弦距离的 HNSW 近似最近邻图。
+//
+// 向量在写入前已归一化，距离为 1 - dot。删除与覆盖写通过墓碑标记，
+// 由集合在墓碑占比过高时整体重建。
+type hnswIndex struct {
+	params    hnswParams
+	levelMult float64
+	rng       *rand.Rand
+	nodes     []hnswNode
+	ids       []string
+	nodeOf    map[string]int32
+	entry     int32
+	maxLevel  int
+	deleted   int
+}
+
+type hnswNode struct {
+	vector    []float32
+	neighbors [][]int32
+	deleted   bool
+}
+
+type hnswCandidate struct {
+	node     int32
+	distance float32
+}
+
+func newHNSWIndex(params hnswParams) *hnswIndex {
+	params = params.normalized()
+	return &hnswIndex{
+		params:    params,
+		levelMult: 1 / math.Log(float64(params.M)),
+		rng:       rand.New(rand.NewPCG(hnswSeedHi, hnswSeedLo)),
+		nodeOf:    map[string]int32{},
+		entry:     -1,
+	}
+}
+
+func (h *hnswIndex) live() int {
+	return len(h.nodeOf)
+}
+
+// upsert 插入或覆盖一个点，覆盖时旧节点记为墓碑。
+func (h *hnswIndex) upsert(id string, vector []float32) {
+	h.remove(id)
+	node := int32(len(h.nodes))
+	level := h.randomLevel()
+	h.nodes = append(h.nodes, hnswNode{vector: vector, neighbors: make([][]int32, level+1)})
+	h.ids = append(h.ids, id)
+	h.nodeOf[id] = node
+	if h.entry < 0 {
+		h.entry, h.maxLevel = node, level
+		return
+	}
+
+	entry := h.entry
+	for layer := h.maxLevel; layer > level; layer-- {
+		entry = h.greedyClosest(vector, entry, layer)
+	}
+	for layer := min(level, h.maxLevel); layer >= 0; layer-- {
+		candidates := h.searchLayer(vector, entry, h.params.EfConstruction, layer)
+		neighbors := h.selectNeighbors(candidates, h.params.M)
+		h.nodes[node].neighbors[layer] = neighbors
+		for _, neighbor := range neighbors {
+			h.connect(neighbor, node, layer)
+		}
+		entry = candidates[0].node
+	}
+	if level > h.maxLevel {
+		h.entry, h.maxLevel = node, level
+	}
+}
+
+func (h *hnswIndex) remove(id string) {
+	node, ok := h.nodeOf[id]
+	if !ok {
+		return
+	}
+	h.nodes[node].deleted = true
+	delete(h.nodeOf, id)
+	h.deleted++
+}
+
+// needsRebuild 表示墓碑已多于存活节点，继续沿用会显著拖慢检索。
+func (h *hnswIndex) needsRebuild() bool {
+	return h.deleted > h.live()
+}
+
+// search 返回最多 ef 个存活且满足 accept 的近邻，按距离升序。
+func (h *hnswIndex) search(query []float32, ef int, accept func(id string) bool) []hnswCandidate {
+	if h.entry < 0 {
+		return nil
+	}
+	entry := h.entry
+	for layer := h.maxLevel; layer > 0; layer-- {
+		entry = h.greedyClosest(query, entry, layer)
+	}
+	candidates := h.searchLayer(query, entry, max(ef, h.params.EfSearch), 0)
+	results := candidates[:0]
+	for _, candidate := range candidates {
+		if h.nodes[candidate.node].deleted || !accept(h.ids[candidate.node]) {
+			continue
+		}
+		results = append(results, candidate)
+	}
+	return results
+}
+
+func (h *hnswIndex) randomLevel() int {
+	return int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMult))
+}
+
+func (h *hnswIndex) greedyClosest(query []float32, entry int32, layer int) int32 {
+	current := entry
+	currentDistance := h.distance(query, current)
+	for improved := true; improved; {
+		improved = false
+		for _, neighbor := range h.neighborsAt(current, layer) {
+			if distance := h.distance(query, neighbor); distance < currentDistance {
+				current, currentDistance, improved = neighbor, distance, true
+			}
+		}
+	}
+	return current
+}
+
+// searchLayer 是 HNSW 论文中的 SEARCH-LAYER，返回按距离升序的 ef 个候选（含墓碑，用于保持连通）。
+func (h *hnswIndex) searchLayer(query []float32, entry int32, ef int, layer int) []hnswCandidate {
+	visited := map[int32]struct{}{entry: {}}
+	start := hnswCandidate{node: entry, distance: h.distance(query, entry)}
+	frontier := &candidateHeap{items: []hnswCandidate{start}}
+	results := &candidateHeap{items: []hnswCandidate{start}, farthestFirst: true}
+
+	for frontier.Len() > 0 {
+		closest := heap.Pop(frontier).(hnswCandidate)
+		if results.Len() >= ef && closest.distance > results.items[0].distance {
+			break
+		}
+		for _, neighbor := range h.neighborsAt(closest.node, layer) {
+			if _, seen := visited[neighbor]; seen {
+				continue
+			}
+			visited[neighbor] = struct{}{}
+			candidate := hnswCandidate{node: neighbor, distance: h.distance(query, neighbor)}
+			if results.Len() < ef || candidate.distance < results.items[0].distance {
+				heap.Push(frontier, candidate)
+				heap.Push(results, candidate)
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+	sorted := slices.Clone(results.items)
+	slices.SortFunc(sorted, compareCandidates)
+	return sorted
+}
+
+func (h *hnswIndex) selectNeighbors(candidates []hnswCandidate, limit int) []int32 {
+	neighbors := make([]int32, 0, min(limit, len(candidates)))
+	for _, candidate := range candidates {
+		if len(neighbors) == limit {
+			break
+		}
+		neighbors = append(neighbors, candidate.node)
+	}
+	return neighbors
+}
+
+// connect 建立反向边，超出度数上限时保留距离最近的邻居。
+func (h *hnswIndex) connect(from, to int32, layer int) {
+	neighbors := append(h.nodes[from].neighbors[layer], to)
+	limit := h.params.M
+	if layer == 0 {
+		limit = 2 * h.params.M
+	}
+	if len(neighbors) > limit {
+		base := h.nodes[from].vector
+		candidates := make([]hnswCandidate, len(neighbors))
+		for i, neighbor := range neighbors {
+			candidates[i] = hnswCandidate{node: neighbor, distance: h.distance(base, neighbor)}
+		}
+		slices.SortFunc(candidates, compareCandidates)
+		neighbors = h.selectNeighbors(candidates, limit)
+	}
+	h.nodes[from].neighbors[layer] = neighbors
+}
+
+func (h *hnswIndex) neighborsAt(node int32, layer int) []int32 {
+	if layer >= len(h.nodes[node].neighbors) {
+		return nil
+	}
+	return h.nodes[node].neighbors[layer]
+}
+
+func (h *hnswIndex) distance(query []float32, node int32) float32 {
+	return 1 - dot(query, h.nodes[node].vector)
+}
+
+func compareCandidates(a, b hnswCandidate) int {
+	switch {
+	case a.distance < b.distance:
+		return -1
+	case a.distance > b.distance:
+		return 1
+	default:
+		return int(a.node - b.node)
+	}
+}
+
+// candidateHeap 默认是最小堆，farthestFirst 时为最大堆。
+type candidateHeap struct {
+	items         []hnswCandidate
+	farthestFirst bool
+}
+
+func (h *candidateHeap) Len() int { return len(h.items) }
+
+func (h *candidateHeap) Less(i, j int) bool {
+	if h.farthestFirst {
+		return h.items[i].distance > h.items[j].distance
+	}
+	return h.items[i].distance < h.items[j].distance
+}
+
+func (h *candidateHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *candidateHeap) Push(x any) { h.items = append(h.items, x.(hnswCandidate)) }
+
+func (h *candidateHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range min(len(a), len(b)) {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// normalizeVector 返回 L2 归一化后的 float32 向量；零向量保持为零。
+func normalizeVector(vector []float64) []float32 {
+	var norm float64
+	for _, value := range vector {
+		norm += value * value
+	}
+	normalized := make([]float32, len(vector))
+	if norm == 0 {
+		return normalized
+	}
+	scale := 1 / math.Sqrt(norm)
+	for i, value := range vector {
+		normalized[i] = float32(value * scale)
+	}
+	return normalized
+}
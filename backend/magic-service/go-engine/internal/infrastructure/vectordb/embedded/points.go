@@ -0,0 +1,309 @@
+package embedded
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+)
+
+// StoreHybridPoint 存储一个 dense+sparse 向量点。
+func (c *Client) StoreHybridPoint(ctx context.Context, collection, pointID string, denseVector []float64, sparseInput *fragmodel.SparseInput, payload map[string]any) error {
+	return c.StoreHybridPoints(ctx, collection,
+		[]string{pointID},
+		[][]float64{denseVector},
+		[]*fragmodel.SparseInput{sparseInput},
+		[]map[string]any{payload},
+	)
+}
+
+// StoreHybridPoints 批量 upsert dense 向量、payload 与稀疏词项；先写日志再更新内存，整批原子生效。
+func (c *Client) StoreHybridPoints(_ context.Context, collection string, pointIDs []string, denseVectors [][]float64, sparseInputs []*fragmodel.SparseInput, payloads []map[string]any) error {
+	if len(pointIDs) != len(denseVectors) || len(pointIDs) != len(payloads) {
+		return fmt.Errorf("%w: pointIDs=%d, vectors=%d, payloads=%d", ErrInvalidInput, len(pointIDs), len(denseVectors), len(payloads))
+	}
+	if len(sparseInputs) > 0 && len(sparseInputs) != len(pointIDs) {
+		return fmt.Errorf("%w: sparseInputs=%d, pointIDs=%d", ErrInvalidInput, len(sparseInputs), len(pointIDs))
+	}
+	if len(pointIDs) == 0 {
+		return nil
+	}
+	target, err := c.resolve(collection)
+	if err != nil {
+		return err
+	}
+
+	records := make([]logRecord, len(pointIDs))
+	for i, pointID := range pointIDs {
+		var sparseInput *fragmodel.SparseInput
+		if len(sparseInputs) > 0 {
+			sparseInput = sparseInputs[i]
+		}
+		point, err := buildPoint(pointID, denseVectors[i], sparseInput, payloads[i], target.vectorSize)
+		if err != nil {
+			return err
+		}
+		records[i] = upsertRecord(pointID, point)
+	}
+
+	target.mu.Lock()
+	defer target.mu.Unlock()
+	if err := target.log.append(records); err != nil {
+		return fmt.Errorf("failed to store points: %w", err)
+	}
+	for _, record := range records {
+		applyLogRecord(target, record)
+	}
+	return target.compactIfNeeded()
+}
+
+func buildPoint(pointID string, denseVector []float64, sparseInput *fragmodel.SparseInput, payload map[string]any, vectorSize int64) (*storedPoint, error) {
+	if strings.TrimSpace(pointID) == "" {
+		return nil, fmt.Errorf("%w: point id is required", ErrInvalidInput)
+	}
+	if len(denseVector) == 0 {
+		return nil, fmt.Errorf("%w: dense vector is required", ErrInvalidInput)
+	}
+	if int64(len(denseVector)) != vectorSize {
+		return nil, fmt.Errorf("%w: dense vector size %d does not match collection size %d", ErrInvalidInput, len(denseVector), vectorSize)
+	}
+	for i, value := range denseVector {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, fmt.Errorf("%w: dense vector contains non-finite value at %d", ErrInvalidInput, i)
+		}
+	}
+	sparse, err := normalizeSparseInput(sparseInput)
+	if err != nil {
+		return nil, err
+	}
+	normalizedPayload, err := normalizePayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &storedPoint{vector: normalizeVector(denseVector), payload: normalizedPayload, sparse: sparse}, nil
+}
+
+// SetPayloadByPointIDs 按 point_id 局部更新 payload，顶层键合并覆盖。
+func (c *Client) SetPayloadByPointIDs(
+	_ context.Context,
+	collection string,
+	updates map[string]map[string]any,
+) error {
+	records := make([]logRecord, 0, len(updates))
+	for _, pointID := range slices.Sorted(maps.Keys(updates)) {
+		if strings.TrimSpace(pointID) == "" || len(updates[pointID]) == 0 {
+			continue
+		}
+		payload, err := normalizePayload(updates[pointID])
+		if err != nil {
+			return err
+		}
+		records = append(records, logRecord{Op: logOpSetPayload, ID: pointID, Payload: payload})
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	target, err := c.resolve(collection)
+	if err != nil {
+		return err
+	}
+
+	target.mu.Lock()
+	defer target.mu.Unlock()
+	// 只记录真实存在的点，避免日志里堆积无效更新。
+	records = slices.DeleteFunc(records, func(record logRecord) bool {
+		_, exists := target.points[record.ID]
+		return !exists
+	})
+	if err := target.log.append(records); err != nil {
+		return fmt.Errorf("failed to set payload: %w", err)
+	}
+	for _, record := range records {
+		applyLogRecord(target, record)
+	}
+	return target.compactIfNeeded()
+}
+
+// ListExistingPointIDs 批量查询 collection 中已经存在的点 ID。
+func (c *Client) ListExistingPointIDs(_ context.Context, collection string, pointIDs []string) (map[string]struct{}, error) {
+	ids := uniqueNonEmpty(pointIDs)
+	if len(ids) == 0 {
+		return map[string]struct{}{}, nil
+	}
+	target, err := c.resolve(collection)
+	if err != nil {
+		return nil, err
+	}
+	target.mu.RLock()
+	defer target.mu.RUnlock()
+	existing := make(map[string]struct{}, len(ids))
+	for _, pointID := range ids {
+		if _, ok := target.points[pointID]; ok {
+			existing[pointID] = struct{}{}
+		}
+	}
+	return existing, nil
+}
+
+// ListPointIDsByFilter 根据 payload filter 枚举全部匹配的 point_id，按 point_id 升序返回。
+//
+// 数据全部在内存中，limit 仅为与其他后端保持签名一致，不影响返回数量。
+func (c *Client) ListPointIDsByFilter(
+	_ context.Context,
+	collection string,
+	filter *fragmodel.VectorFilter,
+	_ int,
+) ([]string, error) {
+	if strings.TrimSpace(collection) == "" {
+		return nil, fmt.Errorf("%w: collection is required", ErrInvalidInput)
+	}
+	target, err := c.resolve(collection)
+	if err != nil {
+		return nil, err
+	}
+	target.mu.RLock()
+	defer target.mu.RUnlock()
+	pointIDs := make([]string, 0)
+	for pointID, point := range target.points {
+		if matchFilter(filter, point.payload) {
+			pointIDs = append(pointIDs, pointID)
+		}
+	}
+	slices.Sort(pointIDs)
+	return pointIDs, nil
+}
+
+// DeletePoint 删除一个向量点。
+func (c *Client) DeletePoint(ctx context.Context, collection, pointID string) error {
+	return c.DeletePoints(ctx, collection, []string{pointID})
+}
+
+// DeletePoints 批量删除向量点，集合不存在时视为成功。
+func (c *Client) DeletePoints(_ context.Context, collection string, pointIDs []string) error {
+	ids := uniqueNonEmpty(pointIDs)
+	if len(ids) == 0 {
+		return nil
+	}
+	target, err := c.resolve(collection)
+	if errors.Is(err, ErrCollectionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	target.mu.Lock()
+	defer target.mu.Unlock()
+	return target.deleteLocked(ids)
+}
+
+// DeletePointsByFilter 根据过滤条件删除向量点，集合不存在时视为成功。
+//
+// 空过滤条件会被拒绝，避免误删整个集合；清空集合请使用 DeleteCollection。
+func (c *Client) DeletePointsByFilter(_ context.Context, collection string, filter *fragmodel.VectorFilter) error {
+	if isFilterEmpty(filter) {
+		return fmt.Errorf("%w: delete by filter requires at least one condition", ErrInvalidInput)
+	}
+	target, err := c.resolve(collection)
+	if errors.Is(err, ErrCollectionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	target.mu.Lock()
+	defer target.mu.Unlock()
+	ids := make([]string, 0)
+	for pointID, point := range target.points {
+		if matchFilter(filter, point.payload) {
+			ids = append(ids, pointID)
+		}
+	}
+	slices.Sort(ids)
+	return target.deleteLocked(ids)
+}
+
+// deleteLocked 记录并删除存在的点，调用方需持有集合写锁。
+func (c *collection) deleteLocked(ids []string) error {
+	ids = slices.DeleteFunc(ids, func(id string) bool {
+		_, exists := c.points[id]
+		return !exists
+	})
+	if len(ids) == 0 {
+		return nil
+	}
+	record := logRecord{Op: logOpDelete, IDs: ids}
+	if err := c.log.append([]logRecord{record}); err != nil {
+		return fmt.Errorf("failed to delete points: %w", err)
+	}
+	applyLogRecord(c, record)
+	return c.compactIfNeeded()
+}
+
+// normalizeSparseInput 校验 sparse 写入并合并重复下标；文本推理需由 Qdrant 完成，这里显式拒绝。
+func normalizeSparseInput(input *fragmodel.SparseInput) (map[uint32]float32, error) {
+	if input == nil {
+		return nil, nil
+	}
+	if input.Document != nil && strings.TrimSpace(input.Document.Text) != "" {
+		return nil, ErrSparseDocumentUnsupported
+	}
+	return normalizeSparseVector(input.Vector)
+}
+
+func normalizeSparseVector(vector *fragmodel.SparseVector) (map[uint32]float32, error) {
+	if vector == nil || len(vector.Indices) == 0 || len(vector.Values) == 0 {
+		return nil, nil
+	}
+	if len(vector.Indices) != len(vector.Values) {
+		return nil, fmt.Errorf(
+			"%w: sparse vector indices and values length mismatch: indices=%d values=%d",
+			ErrInvalidInput,
+			len(vector.Indices),
+			len(vector.Values),
+		)
+	}
+	weights := make(map[uint32]float32, len(vector.Indices))
+	for i, index := range vector.Indices {
+		weights[index] += vector.Values[i]
+	}
+	return weights, nil
+}
+
+// normalizePayload 经 JSON 往返把 payload 转为通用结构，保证过滤、持久化与重放后的值类型一致。
+func normalizePayload(payload map[string]any) (map[string]any, error) {
+	normalized := map[string]any{}
+	if len(payload) == 0 {
+		return normalized, nil
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+	if err := json.Unmarshal(encoded, &normalized); err != nil {
+		return nil, fmt.Errorf("unmarshal payload: %w", err)
+	}
+	return normalized, nil
+}
+
+func uniqueNonEmpty(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		trimmed := strings.TrimSpace(value)
+		if trimmed == "" {
+			continue
+		}
+		if _, exists := seen[trimmed]; exists {
+			continue
+		}
+		seen[trimmed] = struct{}{}
+		result = append(result, trimmed)
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+package embedded
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+)
+
+// SearchDenseWithFilter 使用余弦相似度执行 dense 检索，与 Qdrant Cosine 一致。
+func (c *Client) SearchDenseWithFilter(_ context.Context, request fragmodel.DenseSearchRequest) ([]*SimilarityResult, error) {
+	if request.TopK < 0 {
+		return nil, fmt.Errorf("%w: topK must be non-negative", ErrInvalidInput)
+	}
+	if name := strings.TrimSpace(request.VectorName); name != "" && name != fragmodel.DefaultDenseVectorName {
+		return nil, fmt.Errorf("%w: unsupported dense vector name %q", ErrInvalidInput, request.VectorName)
+	}
+	if request.TopK == 0 {
+		return []*SimilarityResult{}, nil
+	}
+	if len(request.Vector) == 0 {
+		return nil, fmt.Errorf("%w: dense vector is required", ErrInvalidInput)
+	}
+	for i, value := range request.Vector {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, fmt.Errorf("%w: dense vector contains non-finite value at %d", ErrInvalidInput, i)
+		}
+	}
+	target, err := c.resolve(request.Collection)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(request.Vector)) != target.vectorSize {
+		return nil, fmt.Errorf("%w: query vector size %d does not match collection size %d", ErrInvalidInput, len(request.Vector), target.vectorSize)
+	}
+
+	target.mu.RLock()
+	defer target.mu.RUnlock()
+	scored := target.searchDense(normalizeVector(request.Vector), request.TopK, request.ScoreThreshold, request.Filter)
+	return target.similarityResults(scored)
+}
+
+// SearchSparseWithFilter 在客户端稀疏向量上按 Qdrant IDF modifier 语义打分：
+// score = Σ q_i · d_i · ln((N - n_i + 0.5) / (n_i + 0.5) + 1)，N 为集合点数，n_i 为含该词项的点数。
+func (c *Client) SearchSparseWithFilter(_ context.Context, request fragmodel.SparseSearchRequest) ([]*SimilarityResult, error) {
+	if request.TopK < 0 {
+		return nil, fmt.Errorf("%w: topK must be non-negative", ErrInvalidInput)
+	}
+	if name := strings.TrimSpace(request.VectorName); name != "" && name != fragmodel.DefaultSparseVectorName {
+		return nil, fmt.Errorf("%w: unsupported sparse vector name %q", ErrInvalidInput, request.VectorName)
+	}
+	if request.Document != nil && strings.TrimSpace(request.Document.Text) != "" {
+		return nil, ErrSparseDocumentUnsupported
+	}
+	terms, err := normalizeSparseVector(request.Vector)
+	if err != nil {
+		return nil, err
+	}
+	if len(terms) == 0 || request.TopK == 0 {
+		return []*SimilarityResult{}, nil
+	}
+	target, err := c.resolve(request.Collection)
+	if err != nil {
+		return nil, err
+	}
+
+	target.mu.RLock()
+	defer target.mu.RUnlock()
+	scored := target.searchSparse(terms, request.TopK, request.ScoreThreshold, request.Filter)
+	return target.similarityResults(scored)
+}
+
+// similarityResults 组装检索结果；payload 深拷贝，避免调用方修改污染集合状态。调用方需持有读锁。
+func (c *collection) similarityResults(scored []scoredPoint) ([]*SimilarityResult, error) {
+	results := make([]*SimilarityResult, 0, len(scored))
+	for _, item := range scored {
+		payload, err := normalizePayload(c.points[item.id].payload)
+		if err != nil {
+			return nil, fmt.Errorf("decode payload of point %s: %w", item.id, err)
+		}
+		results = append(results, &SimilarityResult{
+			ID:       item.id,
+			Score:    item.score,
+			Payload:  payload,
+			PointID:  item.id,
+			Content:  stringFromPayload(payload, "content"),
+			Metadata: mapFromPayload(payload, "metadata"),
+		})
+	}
+	return results, nil
+}
+
+func stringFromPayload(payload map[string]any, key string) string {
+	if value, ok := payload[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+func mapFromPayload(payload map[string]any, key string) map[string]any {
+	if value, ok := payload[key].(map[string]any); ok {
+		return value
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package embedded
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	catalogFileName     = "catalog.json"
+	collectionsDirName  = "collections"
+	logFilePermission   = 0o600
+	dataDirPermission   = 0o750
+	compactMinRecords   = 1024
+	maxLogRecordBytes   = 64 << 20
+	logOpUpsert         = "upsert"
+	logOpDelete         = "delete"
+	logOpSetPayload     = "set_payload"
+	compactTempFileExt  = ".compact"
+	catalogTempFileName = catalogFileName + ".tmp"
+)
+
+// catalogFile 是持久化的集合 / alias 登记信息。
+type catalogFile struct {
+	Collections map[string]catalogCollection `json:"collections"`
+	Aliases     map[string]string            `json:"aliases"`
+}
+
+type catalogCollection struct {
+	File          string            `json:"file"`
+	VectorSize    int64             `json:"vector_size"`
+	PayloadSchema map[string]string `json:"payload_schema,omitempty"`
+}
+
+// logRecord 是集合日志中的一行（JSON Lines），按写入顺序重放即可恢复集合状态。
+type logRecord struct {
+	Op      string             `json:"op"`
+	ID      string             `json:"id,omitempty"`
+	IDs     []string           `json:"ids,omitempty"`
+	Vector  []float32          `json:"vector,omitempty"`
+	Payload map[string]any     `json:"payload,omitempty"`
+	Sparse  map[uint32]float32 `json:"sparse,omitempty"`
+}
+
+// pointLog 是集合的追加写日志；记录数远超存活点数时整体压缩为快照。
+type pointLog struct {
+	path    string
+	file    *os.File
+	writer  *bufio.Writer
+	records int
+}
+
+func openPointLog(path string) (*pointLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, logFilePermission)
+	if err != nil {
+		return nil, fmt.Errorf("open collection log %s: %w", path, err)
+	}
+	return &pointLog{path: path, file: file, writer: bufio.NewWriter(file)}, nil
+}
+
+// append 写入一批记录并 fsync，保证返回成功后进程崩溃也不丢数据。
+func (l *pointLog) append(records []logRecord) error {
+	if l == nil || len(records) == 0 {
+		return nil
+	}
+	encoder := json.NewEncoder(l.writer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("append collection log: %w", err)
+		}
+	}
+	if err := l.writer.Flush(); err != nil {
+		return fmt.Errorf("flush collection log: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("sync collection log: %w", err)
+	}
+	l.records += len(records)
+	return nil
+}
+
+func (l *pointLog) close() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	flushErr := l.writer.Flush()
+	closeErr := l.file.Close()
+	l.file = nil
+	if err := errors.Join(flushErr, closeErr); err != nil {
+		return fmt.Errorf("close collection log %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// replayLog 把日志重放进集合，返回记录数；文件不存在时视为空集合。
+func replayLog(path string, target *collection) (int, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("open collection log %s: %w", path, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogRecordBytes)
+	records := 0
+	var validBytes int64
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var record logRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			// 末尾半行来自写入中途崩溃：截断后继续追加；中间损坏则拒绝加载。
+			if scanner.Scan() {
+				return 0, fmt.Errorf("decode collection log %s record %d: %w", path, records+1, err)
+			}
+			if err := os.Truncate(path, validBytes); err != nil {
+				return 0, fmt.Errorf("truncate torn collection log %s: %w", path, err)
+			}
+			break
+		}
+		applyLogRecord(target, record)
+		validBytes += int64(len(line)) + 1
+		records++
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("read collection log %s: %w", path, err)
+	}
+	return records, nil
+}
+
+func applyLogRecord(target *collection, record logRecord) {
+	switch record.Op {
+	case logOpUpsert:
+		target.applyUpsert(record.ID, &storedPoint{vector: record.Vector, payload: record.Payload, sparse: record.Sparse})
+	case logOpDelete:
+		for _, id := range record.IDs {
+			target.applyDelete(id)
+		}
+	case logOpSetPayload:
+		target.applySetPayload(record.ID, record.Payload)
+	}
+}
+
+func upsertRecord(id string, point *storedPoint) logRecord {
+	return logRecord{Op: logOpUpsert, ID: id, Vector: point.vector, Payload: point.payload, Sparse: point.sparse}
+}
+
+// compactIfNeeded 在日志膨胀时以当前存活点重写日志，调用方需持有集合写锁。
+func (c *collection) compactIfNeeded() error {
+	if c.log == nil || c.log.records < compactMinRecords || c.log.records <= 2*len(c.points) {
+		return nil
+	}
+	tempPath := c.log.path + compactTempFileExt
+	snapshot, err := openPointLog(tempPath)
+	if err != nil {
+		return err
+	}
+	records := make([]logRecord, 0, len(c.points))
+	for _, id := range slices.Sorted(maps.Keys(c.points)) {
+		records = append(records, upsertRecord(id, c.points[id]))
+	}
+	if err := errors.Join(snapshot.append(records), snapshot.close()); err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	if err := c.log.close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, c.log.path); err != nil {
+		return fmt.Errorf("replace collection log: %w", err)
+	}
+	reopened, err := openPointLog(c.log.path)
+	if err != nil {
+		return err
+	}
+	reopened.records = len(records)
+	c.log = reopened
+	return nil
+}
+
+func loadCatalog(dataDir string) (*catalogFile, error) {
+	catalog := &catalogFile{Collections: map[string]catalogCollection{}, Aliases: map[string]string{}}
+	raw, err := os.ReadFile(filepath.Join(dataDir, catalogFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return catalog, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read vector catalog: %w", err)
+	}
+	if err := json.Unmarshal(raw, catalog); err != nil {
+		return nil, fmt.Errorf("decode vector catalog: %w", err)
+	}
+	if catalog.Collections == nil {
+		catalog.Collections = map[string]catalogCollection{}
+	}
+	if catalog.Aliases == nil {
+		catalog.Aliases = map[string]string{}
+	}
+	return catalog, nil
+}
+
+// saveCatalog 以临时文件 + rename 原子替换登记信息。
+func saveCatalog(dataDir string, catalog *catalogFile) error {
+	raw, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode vector catalog: %w", err)
+	}
+	tempPath := filepath.Join(dataDir, catalogTempFileName)
+	if err := os.WriteFile(tempPath, raw, logFilePermission); err != nil {
+		return fmt.Errorf("write vector catalog: %w", err)
+	}
+	if err := os.Rename(tempPath, filepath.Join(dataDir, catalogFileName)); err != nil {
+		return fmt.Errorf("replace vector catalog: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,522 @@
+// Package pgvector 提供基于 PostgreSQL + pgvector 扩展的向量数据库客户端。
+//
+// 每个集合对应一张点表（dense 向量 + jsonb payload）与一张稀疏词项表，
+// 集合与 alias 元数据记录在同一 schema 下的两张登记表中，语义对齐 Qdrant 的集合/alias 模型。
+package pgvector
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	shared "magic/internal/domain/knowledge/shared"
+	"magic/internal/infrastructure/logging"
+)
+
+const (
+	// DefaultSchema 是未配置 schema 时使用的 PostgreSQL schema。
+	DefaultSchema = "magic_vector"
+
+	collectionsTable = "vector_collections"
+	aliasesTable     = "vector_aliases"
+	// pgvector 的 HNSW 索引只支持不超过 2000 维的 vector 列，超出时退化为精确扫描。
+	maxHNSWDimensions     = 2000
+	maxTableNameBaseRunes = 32
+	tableNameHashBytes    = 5
+)
+
+var (
+	// ErrInvalidInput 表示参数无效。
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrCollectionNotFound 表示集合不存在，与其他向量库后端共用同一哨兵错误。
+	ErrCollectionNotFound = shared.ErrVectorCollectionNotFound
+	// ErrCollectionAlreadyExists 表示集合或同名 alias 已存在。
+	ErrCollectionAlreadyExists = errors.New("collection already exists")
+	// ErrSparseDocumentUnsupported 表示 pgvector 不支持服务端稀疏文本推理，需由客户端构造 sparse vector。
+	ErrSparseDocumentUnsupported = errors.New("sparse document inference is not supported by pgvector")
+)
+
+// SimilarityResult 表示相似度搜索结果。
+type SimilarityResult struct {
+	ID       string         `json:"id"`
+	Score    float64        `json:"score"`
+	Payload  map[string]any `json:"payload"`
+	PointID  string         `json:"point_id"`
+	Content  string         `json:"content"`
+	Metadata map[string]any `json:"metadata"`
+}
+
+// Config 是 pgvector 客户端配置。
+type Config struct {
+	Schema string
+}
+
+// Client 是 pgvector 向量库客户端。
+type Client struct {
+	db       *sql.DB
+	schema   string
+	logger   *logging.SugaredLogger
+	schemaMu sync.Mutex
+}
+
+type collectionTarget struct {
+	name       string
+	table      string
+	vectorSize int64
+}
+
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// NewClient 使用已打开的 *sql.DB 创建 pgvector 客户端，驱动由调用方注册。
+func NewClient(db *sql.DB, cfg *Config, logger *logging.SugaredLogger) (*Client, error) {
+	if db == nil {
+		return nil, fmt.Errorf("%w: db is required", ErrInvalidInput)
+	}
+	schema := DefaultSchema
+	if cfg != nil && strings.TrimSpace(cfg.Schema) != "" {
+		schema = strings.TrimSpace(cfg.Schema)
+	}
+	return &Client{db: db, schema: schema, logger: logger}, nil
+}
+
+// Close 关闭底层连接池。
+func (c *Client) Close() error {
+	if c == nil || c.db == nil {
+		return nil
+	}
+	if err := c.db.Close(); err != nil {
+		return fmt.Errorf("close pgvector db: %w", err)
+	}
+	return nil
+}
+
+// PingContext 检查数据库连通性，供健康检查复用。
+func (c *Client) PingContext(ctx context.Context) error {
+	if err := c.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping pgvector db: %w", err)
+	}
+	return nil
+}
+
+// HealthCheck 实现 health.Checker。
+func (c *Client) HealthCheck(ctx context.Context) error {
+	return c.PingContext(ctx)
+}
+
+// EnsureSchema 创建 pgvector 扩展、schema 与集合/alias 登记表，可重复执行。
+func (c *Client) EnsureSchema(ctx context.Context) error {
+	c.schemaMu.Lock()
+	defer c.schemaMu.Unlock()
+
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		`CREATE SCHEMA IF NOT EXISTS ` + quoteIdent(c.schema),
+		`CREATE TABLE IF NOT EXISTS ` + c.qualified(collectionsTable) + ` (
+			name TEXT PRIMARY KEY,
+			table_name TEXT NOT NULL UNIQUE,
+			vector_size INTEGER NOT NULL,
+			payload_schema JSONB NOT NULL DEFAULT '{}'::jsonb,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+		`CREATE TABLE IF NOT EXISTS ` + c.qualified(aliasesTable) + ` (
+			alias TEXT PRIMARY KEY,
+			collection_name TEXT NOT NULL REFERENCES ` + c.qualified(collectionsTable) + `(name) ON DELETE CASCADE
+		)`,
+	}
+	for _, statement := range statements {
+		if _, err := c.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("ensure pgvector schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreateCollection 创建集合：点表、稀疏词项表以及 HNSW / GIN 索引。
+func (c *Client) CreateCollection(ctx context.Context, name string, vectorSize int64) error {
+	c.schemaMu.Lock()
+	defer c.schemaMu.Unlock()
+
+	if vectorSize <= 0 {
+		return fmt.Errorf("%w: vector size must be positive", ErrInvalidInput)
+	}
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: collection name is required", ErrInvalidInput)
+	}
+	table := physicalTableName(name)
+	err := c.withTx(ctx, func(tx *sql.Tx) error {
+		var aliasExists bool
+		if err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM `+c.qualified(aliasesTable)+` WHERE alias = $1)`, name,
+		).Scan(&aliasExists); err != nil {
+			return fmt.Errorf("check alias %s: %w", name, err)
+		}
+		if aliasExists {
+			return fmt.Errorf("%w: %s is an alias", ErrCollectionAlreadyExists, name)
+		}
+		result, err := tx.ExecContext(ctx,
+			`INSERT INTO `+c.qualified(collectionsTable)+` (name, table_name, vector_size) VALUES ($1, $2, $3)
+			ON CONFLICT (name) DO NOTHING`,
+			name, table, vectorSize,
+		)
+		if err != nil {
+			return fmt.Errorf("register collection %s: %w", name, err)
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return fmt.Errorf("%w: %s", ErrCollectionAlreadyExists, name)
+		}
+		for _, statement := range c.createCollectionStatements(table, vectorSize) {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				return fmt.Errorf("create collection %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.logInfo(ctx, "Created pgvector collection", "name", name, "table", table, "vectorSize", vectorSize)
+	return nil
+}
+
+func (c *Client) createCollectionStatements(table string, vectorSize int64) []string {
+	points := c.qualified(table)
+	terms := c.qualified(termsTableName(table))
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE %s (
+			point_id TEXT PRIMARY KEY,
+			embedding vector(%d) NOT NULL,
+			payload JSONB NOT NULL DEFAULT '{}'::jsonb
+		)`, points, vectorSize),
+		fmt.Sprintf(`CREATE TABLE %s (
+			point_id TEXT NOT NULL REFERENCES %s(point_id) ON DELETE CASCADE,
+			term_index BIGINT NOT NULL,
+			weight REAL NOT NULL,
+			PRIMARY KEY (point_id, term_index)
+		)`, terms, points),
+		fmt.Sprintf(`CREATE INDEX %s ON %s (term_index)`, quoteIdent(termsTableName(table)+"_term"), terms),
+		// jsonb_path_ops 覆盖所有 payload 路径上的 @? 过滤，无需按字段建 btree。
+		fmt.Sprintf(`CREATE INDEX %s ON %s USING gin (payload jsonb_path_ops)`, quoteIdent(table+"_payload"), points),
+	}
+	if vectorSize <= maxHNSWDimensions {
+		statements = append(statements, fmt.Sprintf(
+			`CREATE INDEX %s ON %s USING hnsw (embedding vector_cosine_ops)`,
+			quoteIdent(table+"_hnsw"), points,
+		))
+	}
+	return statements
+}
+
+// CollectionExists 检查物理集合是否存在（alias 不计入）。
+func (c *Client) CollectionExists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+	if err := c.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM `+c.qualified(collectionsTable)+` WHERE name = $1)`, name,
+	).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check collection %s exists: %w", name, err)
+	}
+	return exists, nil
+}
+
+// ListCollections 列出所有物理集合名称。
+func (c *Client) ListCollections(ctx context.Context) ([]string, error) {
+	rows, err := c.db.QueryContext(ctx, `SELECT name FROM `+c.qualified(collectionsTable)+` ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	return scanStrings(rows)
+}
+
+// GetCollectionInfo 获取集合信息，name 可以是 alias。
+func (c *Client) GetCollectionInfo(ctx context.Context, name string) (*fragmodel.VectorCollectionInfo, error) {
+	target, err := c.resolveCollection(ctx, c.db, name)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		points    int64
+		schemaRaw []byte
+	)
+	if err := c.db.QueryRowContext(ctx,
+		`SELECT (SELECT count(*) FROM `+c.qualified(target.table)+`), payload_schema::text
+		FROM `+c.qualified(collectionsTable)+` WHERE name = $1`, target.name,
+	).Scan(&points, &schemaRaw); err != nil {
+		return nil, fmt.Errorf("failed to get collection info %s: %w", name, err)
+	}
+	schema, err := decodePayloadSchema(schemaRaw)
+	if err != nil {
+		return nil, err
+	}
+	return &fragmodel.VectorCollectionInfo{
+		Name:                name,
+		VectorSize:          target.vectorSize,
+		Points:              points,
+		HasNamedDenseVector: true,
+		HasSparseVector:     true,
+		PayloadSchemaKeys:   sortedKeys(schema),
+	}, nil
+}
+
+// EnsurePayloadIndexes 登记 payload 索引声明。
+//
+// 过滤统一走 payload 上的 GIN(jsonb_path_ops) 索引，这里只维护 payload_schema，
+// 使 GetCollectionInfo 返回的 PayloadSchemaKeys 与 Qdrant 语义一致。
+func (c *Client) EnsurePayloadIndexes(ctx context.Context, collection string, specs []shared.PayloadIndexSpec) error {
+	declared := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		normalized := spec.Normalize()
+		if !normalized.Valid() {
+			return fmt.Errorf("%w: invalid payload index spec field=%q kind=%q", ErrInvalidInput, spec.FieldName, spec.Kind)
+		}
+		declared[normalized.FieldName] = string(normalized.Kind)
+	}
+	if len(declared) == 0 {
+		return nil
+	}
+
+	c.schemaMu.Lock()
+	defer c.schemaMu.Unlock()
+
+	target, err := c.resolveCollection(ctx, c.db, collection)
+	if err != nil {
+		return err
+	}
+	declaredJSON, err := json.Marshal(declared)
+	if err != nil {
+		return fmt.Errorf("marshal payload schema: %w", err)
+	}
+	// 已登记的字段保持原有类型，与 Qdrant 已存在索引时跳过创建的行为一致。
+	if _, err := c.db.ExecContext(ctx,
+		`UPDATE `+c.qualified(collectionsTable)+` SET payload_schema = $2::jsonb || payload_schema WHERE name = $1`,
+		target.name, string(declaredJSON),
+	); err != nil {
+		return fmt.Errorf("ensure payload indexes on %s: %w", collection, err)
+	}
+	return nil
+}
+
+// DeleteCollection 删除集合及其 alias，集合不存在时视为成功。
+func (c *Client) DeleteCollection(ctx context.Context, name string) error {
+	c.schemaMu.Lock()
+	defer c.schemaMu.Unlock()
+
+	var table string
+	err := c.withTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx,
+			`DELETE FROM `+c.qualified(collectionsTable)+` WHERE name = $1 RETURNING table_name`, name,
+		).Scan(&table)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unregister collection %s: %w", name, err)
+		}
+		for _, statement := range []string{
+			`DROP TABLE IF EXISTS ` + c.qualified(termsTableName(table)),
+			`DROP TABLE IF EXISTS ` + c.qualified(table),
+		} {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				return fmt.Errorf("failed to delete collection %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if table != "" {
+		c.logInfo(ctx, "Deleted pgvector collection", "name", name, "table", table)
+	}
+	return nil
+}
+
+// GetAliasTarget 查询 alias 当前指向的物理集合。
+func (c *Client) GetAliasTarget(ctx context.Context, alias string) (string, bool, error) {
+	var target string
+	err := c.db.QueryRowContext(ctx,
+		`SELECT collection_name FROM `+c.qualified(aliasesTable)+` WHERE alias = $1`, alias,
+	).Scan(&target)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get alias %s: %w", alias, err)
+	}
+	return target, true, nil
+}
+
+// EnsureAlias 确保 alias 指向目标物理集合。
+func (c *Client) EnsureAlias(ctx context.Context, alias, target string) error {
+	c.schemaMu.Lock()
+	defer c.schemaMu.Unlock()
+
+	current, exists, err := c.GetAliasTarget(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if exists && current == target {
+		return nil
+	}
+	return c.swapAliasAtomically(ctx, alias, current, target)
+}
+
+// SwapAliasAtomically 在同一事务内删除旧 alias 并指向新物理集合。
+func (c *Client) SwapAliasAtomically(ctx context.Context, alias, oldTarget, newTarget string) error {
+	c.schemaMu.Lock()
+	defer c.schemaMu.Unlock()
+
+	return c.swapAliasAtomically(ctx, alias, oldTarget, newTarget)
+}
+
+func (c *Client) swapAliasAtomically(ctx context.Context, alias, oldTarget, newTarget string) error {
+	err := c.withTx(ctx, func(tx *sql.Tx) error {
+		if strings.TrimSpace(oldTarget) != "" {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM `+c.qualified(aliasesTable)+` WHERE alias = $1`, alias); err != nil {
+				return fmt.Errorf("delete alias: %w", err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO `+c.qualified(aliasesTable)+` (alias, collection_name) VALUES ($1, $2)`, alias, newTarget,
+		); err != nil {
+			return fmt.Errorf("create alias: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to swap alias %s from %s to %s: %w", alias, oldTarget, newTarget, err)
+	}
+	return nil
+}
+
+// DeleteAlias 删除 alias。
+func (c *Client) DeleteAlias(ctx context.Context, alias string) error {
+	c.schemaMu.Lock()
+	defer c.schemaMu.Unlock()
+
+	if _, err := c.db.ExecContext(ctx, `DELETE FROM `+c.qualified(aliasesTable)+` WHERE alias = $1`, alias); err != nil {
+		return fmt.Errorf("failed to delete alias %s: %w", alias, err)
+	}
+	return nil
+}
+
+// resolveCollection 将集合名或 alias 解析为物理点表。
+func (c *Client) resolveCollection(ctx context.Context, querier rowQuerier, name string) (collectionTarget, error) {
+	target := collectionTarget{}
+	err := querier.QueryRowContext(ctx,
+		`SELECT c.name, c.table_name, c.vector_size FROM `+c.qualified(collectionsTable)+` c WHERE c.name = $1
+		UNION ALL
+		SELECT c.name, c.table_name, c.vector_size FROM `+c.qualified(aliasesTable)+` a
+		JOIN `+c.qualified(collectionsTable)+` c ON c.name = a.collection_name WHERE a.alias = $1
+		LIMIT 1`, name,
+	).Scan(&target.name, &target.table, &target.vectorSize)
+	if errors.Is(err, sql.ErrNoRows) {
+		return collectionTarget{}, fmt.Errorf("%w: %s", ErrCollectionNotFound, name)
+	}
+	if err != nil {
+		return collectionTarget{}, fmt.Errorf("resolve collection %s: %w", name, err)
+	}
+	return target, nil
+}
+
+func (c *Client) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin pgvector transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit pgvector transaction: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) qualified(table string) string {
+	return quoteIdent(c.schema) + "." + quoteIdent(table)
+}
+
+func (c *Client) logInfo(ctx context.Context, msg string, keysAndValues ...any) {
+	if c == nil || c.logger == nil {
+		return
+	}
+	c.logger.InfoContext(ctx, msg, keysAndValues...)
+}
+
+// physicalTableName 由集合名派生稳定且不超过 PostgreSQL 标识符长度上限的表名。
+func physicalTableName(collection string) string {
+	var builder strings.Builder
+	builder.WriteString("vc_")
+	count := 0
+	for _, r := range strings.ToLower(collection) {
+		if count >= maxTableNameBaseRunes {
+			break
+		}
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			builder.WriteRune(r)
+		} else {
+			builder.WriteByte('_')
+		}
+		count++
+	}
+	sum := sha256.Sum256([]byte(collection))
+	builder.WriteByte('_')
+	builder.WriteString(hex.EncodeToString(sum[:tableNameHashBytes]))
+	return builder.String()
+}
+
+func termsTableName(table string) string {
+	return table + "_terms"
+}
+
+func quoteIdent(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func decodePayloadSchema(raw []byte) (map[string]string, error) {
+	schema := map[string]string{}
+	if len(raw) == 0 {
+		return schema, nil
+	}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("decode payload schema: %w", err)
+	}
+	return schema, nil
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func scanStrings(rows *sql.Rows) ([]string, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+	values := make([]string, 0)
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		values = append(values, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return values, nil
+}
@@ -0,0 +1,198 @@
+package pgvector_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	shared "magic/internal/domain/knowledge/shared"
+	"magic/internal/infrastructure/vectordb/pgvector"
+)
+
+func TestBuildFilterClauseTranslatesQdrantSemantics(t *testing.T) {
+	t.Parallel()
+
+	clause, args, err := pgvector.BuildFilterClauseForTest(&shared.VectorFilter{
+		Must: []shared.FieldFilter{
+			{Key: "document_code", Match: shared.Match{EqString: new(`DOC-"A"`)}},
+			{Key: "tags[]", Match: shared.Match{InStrings: []string{"a", "b"}}},
+		},
+		Should: []shared.FieldFilter{
+			{Key: "position", Match: shared.Match{Range: &shared.Range{Gt: new(1.0), Lte: new(3.5)}}},
+			{Key: "metadata.enabled", Match: shared.Match{EqBool: new(true)}},
+		},
+		MustNot: []shared.FieldFilter{
+			{Key: "position", Match: shared.Match{InFloats: []float64{7}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildFilterClauseForTest: %v", err)
+	}
+	expectedClause := "payload @? $1::jsonpath AND payload @? $2::jsonpath AND " +
+		"(payload @? $3::jsonpath OR payload @? $4::jsonpath) AND NOT (payload @? $5::jsonpath)"
+	if clause != expectedClause {
+		t.Fatalf("unexpected clause:\n%s", clause)
+	}
+	expectedArgs := []any{
+		`$."document_code" ? (@ == "DOC-\"A\"")`,
+		`$."tags" ? (@ == "a" || @ == "b")`,
+		`$."position" ? (@ > 1 && @ <= 3.5)`,
+		`$."metadata"."enabled" ? (@ == true)`,
+		`$."position" ? (@ == 7)`,
+	}
+	if !slices.Equal(args, expectedArgs) {
+		t.Fatalf("unexpected jsonpath args:\n%q", args)
+	}
+}
+
+func TestBuildFilterClauseRejectsInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	clause, args, err := pgvector.BuildFilterClauseForTest(&shared.VectorFilter{})
+	if err != nil || clause != "" || len(args) != 0 {
+		t.Fatalf("expected empty clause for empty filter, got %q %v %v", clause, args, err)
+	}
+	_, _, err = pgvector.BuildFilterClauseForTest(&shared.VectorFilter{Must: []shared.FieldFilter{
+		{Key: "position", Match: shared.Match{EqFloat: new(math.NaN())}},
+	}})
+	if !errors.Is(err, pgvector.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for NaN, got %v", err)
+	}
+	_, _, err = pgvector.BuildFilterClauseForTest(&shared.VectorFilter{Must: []shared.FieldFilter{
+		{Key: "metadata..x", Match: shared.Match{EqBool: new(true)}},
+	}})
+	if !errors.Is(err, pgvector.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for empty key segment, got %v", err)
+	}
+}
+
+func TestPhysicalTableNameIsStableAndBounded(t *testing.T) {
+	t.Parallel()
+
+	name := pgvector.PhysicalTableNameForTest("Magic-KB.Collection")
+	if name != pgvector.PhysicalTableNameForTest("Magic-KB.Collection") {
+		t.Fatal("expected stable table name")
+	}
+	if !strings.HasPrefix(name, "vc_magic_kb_collection_") {
+		t.Fatalf("unexpected table name %q", name)
+	}
+	if other := pgvector.PhysicalTableNameForTest("magic_kb_collection"); other == name {
+		t.Fatal("expected hash suffix to separate sanitized collisions")
+	}
+	if long := pgvector.PhysicalTableNameForTest(strings.Repeat("x", 200)); len(long) > 63 {
+		t.Fatalf("table name exceeds postgres identifier limit: %d", len(long))
+	}
+}
+
+func TestFormatVectorRejectsNonFiniteValues(t *testing.T) {
+	t.Parallel()
+
+	literal, err := pgvector.FormatVectorForTest([]float64{1, 0.5, -2})
+	if err != nil || literal != "[1,0.5,-2]" {
+		t.Fatalf("unexpected vector literal %q err=%v", literal, err)
+	}
+	if _, err := pgvector.FormatVectorForTest([]float64{math.Inf(1)}); !errors.Is(err, pgvector.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestSearchDenseWithFilterResolvesAliasAndDecodesPayload(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	client, err := pgvector.NewClient(db, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	table := pgvector.PhysicalTableNameForTest("kb_v2")
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT c.name, c.table_name, c.vector_size FROM "magic_vector"."vector_collections"`)).
+		WithArgs("kb").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "table_name", "vector_size"}).AddRow("kb_v2", table, int64(3)))
+	mock.ExpectQuery(`FROM "magic_vector"\."`+table+`"(?s:.*)LIMIT \$3\s*\) AS nearest\s*WHERE nearest\.score >= \$4`).
+		WithArgs("[1,0,0]", `$."document_code" ? (@ == "DOC-1")`, 5, 0.2).
+		WillReturnRows(sqlmock.NewRows([]string{"point_id", "payload", "score"}).
+			AddRow("P1", []byte(`{"content":"hello","document_code":"DOC-1","metadata":{"page":2}}`), 0.93))
+
+	results, err := client.SearchDenseWithFilter(context.Background(), shared.DenseSearchRequest{
+		Collection:     "kb",
+		Vector:         []float64{1, 0, 0},
+		TopK:           5,
+		ScoreThreshold: 0.2,
+		Filter: &shared.VectorFilter{Must: []shared.FieldFilter{
+			{Key: "document_code", Match: shared.Match{EqString: new("DOC-1")}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("SearchDenseWithFilter: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "P1" || results[0].Content != "hello" || results[0].Metadata["page"] != float64(2) {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestStoreHybridPointsRejectsSparseDocument(t *testing.T) {
+	t.Parallel()
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	client, err := pgvector.NewClient(db, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	err = client.StoreHybridPoints(context.Background(), "kb",
+		[]string{"P1"},
+		[][]float64{{1, 0, 0}},
+		[]*shared.SparseInput{{Document: &shared.SparseDocument{Text: "退款", Model: shared.DefaultSparseModelName}}},
+		[]map[string]any{{"content": "退款"}},
+	)
+	if !errors.Is(err, pgvector.ErrSparseDocumentUnsupported) {
+		t.Fatalf("expected ErrSparseDocumentUnsupported, got %v", err)
+	}
+}
+
+func TestDeletePointsByFilterRequiresCondition(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	client, err := pgvector.NewClient(db, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := client.DeletePointsByFilter(context.Background(), "kb", &shared.VectorFilter{}); !errors.Is(err, pgvector.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unexpected queries: %v", err)
+	}
+}
+
+func TestSelectSparseBackendFallsBackFromNativeBM25(t *testing.T) {
+	t.Parallel()
+
+	repo := pgvector.NewVectorDBDataRepository[map[string]any](nil)
+	selection := repo.SelectSparseBackend(shared.SparseBackendQdrantBM25ZHV1)
+	if selection.Effective != shared.SparseBackendClientBM25QdrantIDFV1 ||
+		selection.Reason != shared.SparseBackendSelectionReasonNativeBM25Unsupported || !selection.FallbackApplied() {
+		t.Fatalf("unexpected selection: %+v", selection)
+	}
+	if def := repo.DefaultSparseBackend(); def.Effective != shared.SparseBackendClientBM25QdrantIDFV1 {
+		t.Fatalf("unexpected default selection: %+v", def)
+	}
+}
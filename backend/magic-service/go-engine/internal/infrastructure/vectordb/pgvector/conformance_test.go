@@ -0,0 +1,43 @@
+package pgvector_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"magic/internal/infrastructure/logging"
+	"magic/internal/infrastructure/vectordb/pgvector"
+	"magic/internal/infrastructure/vectordb/vectordbtest"
+)
+
+// TestPGVectorConformance 需要启用 pgvector 扩展的 PostgreSQL：设置 PGVECTOR_CONFORMANCE_DSN 后运行，
+// CI 通过 make test-pgvector 与 pgvector service 容器执行。
+func TestPGVectorConformance(t *testing.T) {
+	t.Parallel()
+	dsn := os.Getenv("PGVECTOR_CONFORMANCE_DSN")
+	if dsn == "" {
+		t.Skip("PGVECTOR_CONFORMANCE_DSN not set")
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	client, err := pgvector.NewClient(db, &pgvector.Config{Schema: "magic_vector_conformance"}, logging.New())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	if err := client.EnsureSchema(context.Background()); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+
+	vectordbtest.Run(t, func(*testing.T) vectordbtest.Backend {
+		return vectordbtest.Backend{
+			Management: pgvector.NewVectorDBManagementRepository(client),
+			Data:       pgvector.NewVectorDBDataRepository[vectordbtest.Payload](client),
+		}
+	})
+}
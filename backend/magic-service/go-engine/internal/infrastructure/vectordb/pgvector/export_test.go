@@ -0,0 +1,20 @@
+package pgvector
+
+import shared "magic/internal/domain/knowledge/shared"
+
+// BuildFilterClauseForTest 暴露 buildFilterClause 供测试使用，返回 SQL 片段与占位参数。
+func BuildFilterClauseForTest(filter *shared.VectorFilter) (string, []any, error) {
+	args := &sqlArgs{}
+	clause, err := buildFilterClause(filter, "payload", args)
+	return clause, args.values, err
+}
+
+// PhysicalTableNameForTest 暴露 physicalTableName 供测试使用。
+func PhysicalTableNameForTest(collection string) string {
+	return physicalTableName(collection)
+}
+
+// FormatVectorForTest 暴露 formatVector 供测试使用。
+func FormatVectorForTest(vector []float64) (string, error) {
+	return formatVector(vector)
+}
@@ -0,0 +1,185 @@
+package pgvector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	shared "magic/internal/domain/knowledge/shared"
+)
+
+// sqlArgs 按出现顺序收集占位参数，返回 PostgreSQL 的 $n 占位符。
+type sqlArgs struct {
+	values []any
+}
+
+func (a *sqlArgs) add(value any) string {
+	a.values = append(a.values, value)
+	return "$" + strconv.Itoa(len(a.values))
+}
+
+// buildFilterClause 将 VectorFilter 翻译为 payload 上的 jsonpath 谓词，语义对齐 Qdrant：
+// must 全部满足、should 至少满足一个、must_not 全部不满足；数组字段命中任一元素即视为匹配。
+func buildFilterClause(filter *shared.VectorFilter, column string, args *sqlArgs) (string, error) {
+	if filter == nil {
+		return "", nil
+	}
+	must, err := buildFieldPredicates(filter.Must, column, args)
+	if err != nil {
+		return "", err
+	}
+	should, err := buildFieldPredicates(filter.Should, column, args)
+	if err != nil {
+		return "", err
+	}
+	mustNot, err := buildFieldPredicates(filter.MustNot, column, args)
+	if err != nil {
+		return "", err
+	}
+
+	clauses := make([]string, 0, len(must)+2)
+	clauses = append(clauses, must...)
+	if len(should) > 0 {
+		clauses = append(clauses, "("+strings.Join(should, " OR ")+")")
+	}
+	if len(mustNot) > 0 {
+		clauses = append(clauses, "NOT ("+strings.Join(mustNot, " OR ")+")")
+	}
+	return strings.Join(clauses, " AND "), nil
+}
+
+func buildFieldPredicates(filters []shared.FieldFilter, column string, args *sqlArgs) ([]string, error) {
+	predicates := make([]string, 0, len(filters))
+	for _, filter := range filters {
+		path, ok, err := buildFieldJSONPath(filter)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		predicates = append(predicates, column+" @? "+args.add(path)+"::jsonpath")
+	}
+	return predicates, nil
+}
+
+// buildFieldJSONPath 生成单字段条件的 jsonpath，条件优先级与 Qdrant 客户端一致。
+func buildFieldJSONPath(filter shared.FieldFilter) (string, bool, error) {
+	if strings.TrimSpace(filter.Key) == "" {
+		return "", false, nil
+	}
+	key, err := jsonPathKey(filter.Key)
+	if err != nil {
+		return "", false, err
+	}
+	predicate, ok, err := buildMatchPredicate(filter.Match)
+	if err != nil || !ok {
+		return "", ok, err
+	}
+	return key + " ? (" + predicate + ")", true, nil
+}
+
+func buildMatchPredicate(match shared.Match) (string, bool, error) {
+	switch {
+	case match.Range != nil && !isRangeEmpty(match.Range):
+		return rangePredicate(match.Range)
+	case match.EqString != nil:
+		literal, err := jsonPathString(*match.EqString)
+		return "@ == " + literal, err == nil, err
+	case match.EqBool != nil:
+		return "@ == " + strconv.FormatBool(*match.EqBool), true, nil
+	case match.EqFloat != nil:
+		literal, err := jsonPathNumber(*match.EqFloat)
+		return "@ == " + literal, err == nil, err
+	case len(match.InStrings) > 0:
+		alternatives := make([]string, 0, len(match.InStrings))
+		for _, value := range match.InStrings {
+			literal, err := jsonPathString(value)
+			if err != nil {
+				return "", false, err
+			}
+			alternatives = append(alternatives, "@ == "+literal)
+		}
+		return strings.Join(alternatives, " || "), true, nil
+	case len(match.InFloats) > 0:
+		alternatives := make([]string, 0, len(match.InFloats))
+		for _, value := range match.InFloats {
+			literal, err := jsonPathNumber(value)
+			if err != nil {
+				return "", false, err
+			}
+			alternatives = append(alternatives, "@ == "+literal)
+		}
+		return strings.Join(alternatives, " || "), true, nil
+	default:
+		return "", false, nil
+	}
+}
+
+func rangePredicate(r *shared.Range) (string, bool, error) {
+	bounds := []struct {
+		op    string
+		value *float64
+	}{
+		{op: "<", value: r.Lt},
+		{op: ">", value: r.Gt},
+		{op: ">=", value: r.Gte},
+		{op: "<=", value: r.Lte},
+	}
+	parts := make([]string, 0, len(bounds))
+	for _, bound := range bounds {
+		if bound.value == nil {
+			continue
+		}
+		literal, err := jsonPathNumber(*bound.value)
+		if err != nil {
+			return "", false, err
+		}
+		parts = append(parts, "@ "+bound.op+" "+literal)
+	}
+	return strings.Join(parts, " && "), len(parts) > 0, nil
+}
+
+func isRangeEmpty(r *shared.Range) bool {
+	return r == nil || (r.Lt == nil && r.Gt == nil && r.Gte == nil && r.Lte == nil)
+}
+
+// jsonPathKey 将 Qdrant 风格的点分路径（可带 [] 数组标记）转为 lax 模式 jsonpath。
+func jsonPathKey(key string) (string, error) {
+	var builder strings.Builder
+	builder.WriteString("$")
+	for part := range strings.SplitSeq(key, ".") {
+		part = strings.TrimSuffix(strings.TrimSpace(part), "[]")
+		if part == "" {
+			return "", fmt.Errorf("%w: invalid filter key %q", ErrInvalidInput, key)
+		}
+		literal, err := jsonPathString(part)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(".")
+		builder.WriteString(literal)
+	}
+	return builder.String(), nil
+}
+
+// jsonPathString 以 JSON 字符串字面量转义，jsonpath 接受相同的转义语法。
+func jsonPathString(value string) (string, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", fmt.Errorf("encode jsonpath string: %w", err)
+	}
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
+
+func jsonPathNumber(value float64) (string, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "", fmt.Errorf("%w: non-finite filter value", ErrInvalidInput)
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64), nil
+}
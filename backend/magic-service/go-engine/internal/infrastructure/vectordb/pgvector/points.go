@@ -0,0 +1,391 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+)
+
+const defaultListPointIDsLimit = 1000
+
+// sparseTerms 是合并重复下标后的稀疏向量，按 JSON 数组传入 SQL 后展开。
+type sparseTerms struct {
+	Indices []int64   `json:"indices"`
+	Values  []float64 `json:"values"`
+}
+
+// StoreHybridPoint 存储一个 dense+sparse 向量点。
+func (c *Client) StoreHybridPoint(ctx context.Context, collection, pointID string, denseVector []float64, sparseInput *fragmodel.SparseInput, payload map[string]any) error {
+	return c.StoreHybridPoints(ctx, collection,
+		[]string{pointID},
+		[][]float64{denseVector},
+		[]*fragmodel.SparseInput{sparseInput},
+		[]map[string]any{payload},
+	)
+}
+
+// StoreHybridPoints 在同一事务内批量 upsert dense 向量、payload 与稀疏词项。
+func (c *Client) StoreHybridPoints(ctx context.Context, collection string, pointIDs []string, denseVectors [][]float64, sparseInputs []*fragmodel.SparseInput, payloads []map[string]any) error {
+	if len(pointIDs) != len(denseVectors) || len(pointIDs) != len(payloads) {
+		return fmt.Errorf("%w: pointIDs=%d, vectors=%d, payloads=%d", ErrInvalidInput, len(pointIDs), len(denseVectors), len(payloads))
+	}
+	if len(sparseInputs) > 0 && len(sparseInputs) != len(pointIDs) {
+		return fmt.Errorf("%w: sparseInputs=%d, pointIDs=%d", ErrInvalidInput, len(sparseInputs), len(pointIDs))
+	}
+	if len(pointIDs) == 0 {
+		return nil
+	}
+	terms := make([]*sparseTerms, len(pointIDs))
+	for i := range sparseInputs {
+		normalized, err := normalizeSparseInput(sparseInputs[i])
+		if err != nil {
+			return err
+		}
+		terms[i] = normalized
+	}
+
+	err := c.withTx(ctx, func(tx *sql.Tx) error {
+		target, err := c.resolveCollection(ctx, tx, collection)
+		if err != nil {
+			return err
+		}
+		for i, pointID := range pointIDs {
+			if err := c.upsertPoint(ctx, tx, target, pointID, denseVectors[i], terms[i], payloads[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store points: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) upsertPoint(
+	ctx context.Context,
+	tx *sql.Tx,
+	target collectionTarget,
+	pointID string,
+	denseVector []float64,
+	terms *sparseTerms,
+	payload map[string]any,
+) error {
+	if strings.TrimSpace(pointID) == "" {
+		return fmt.Errorf("%w: point id is required", ErrInvalidInput)
+	}
+	vectorLiteral, err := formatVector(denseVector)
+	if err != nil {
+		return err
+	}
+	payloadJSON, err := marshalPayload(payload)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO `+c.qualified(target.table)+` (point_id, embedding, payload) VALUES ($1, $2::vector, $3::jsonb)
+		ON CONFLICT (point_id) DO UPDATE SET embedding = EXCLUDED.embedding, payload = EXCLUDED.payload`,
+		pointID, vectorLiteral, payloadJSON,
+	); err != nil {
+		return fmt.Errorf("upsert point %s: %w", pointID, err)
+	}
+	// upsert 语义与 Qdrant 一致：整点覆盖，未携带 sparse 时清空旧词项。
+	termsTable := c.qualified(termsTableName(target.table))
+	if _, err := tx.ExecContext(ctx, `DELETE FROM `+termsTable+` WHERE point_id = $1`, pointID); err != nil {
+		return fmt.Errorf("reset sparse terms of point %s: %w", pointID, err)
+	}
+	if terms == nil || len(terms.Indices) == 0 {
+		return nil
+	}
+	termsJSON, err := json.Marshal(terms)
+	if err != nil {
+		return fmt.Errorf("marshal sparse terms: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO `+termsTable+` (point_id, term_index, weight)
+		SELECT $1, i.value::bigint, w.value::real
+		FROM jsonb_array_elements_text($2::jsonb -> 'indices') WITH ORDINALITY AS i(value, ord)
+		JOIN jsonb_array_elements_text($2::jsonb -> 'values') WITH ORDINALITY AS w(value, ord) ON i.ord = w.ord`,
+		pointID, string(termsJSON),
+	); err != nil {
+		return fmt.Errorf("store sparse terms of point %s: %w", pointID, err)
+	}
+	return nil
+}
+
+// SetPayloadByPointIDs 按 point_id 局部更新 payload，顶层键合并覆盖。
+func (c *Client) SetPayloadByPointIDs(
+	ctx context.Context,
+	collection string,
+	updates map[string]map[string]any,
+) error {
+	pointIDs := make([]string, 0, len(updates))
+	for pointID, payload := range updates {
+		if strings.TrimSpace(pointID) == "" || len(payload) == 0 {
+			continue
+		}
+		pointIDs = append(pointIDs, pointID)
+	}
+	if len(pointIDs) == 0 {
+		return nil
+	}
+	slices.Sort(pointIDs)
+
+	return c.withTx(ctx, func(tx *sql.Tx) error {
+		target, err := c.resolveCollection(ctx, tx, collection)
+		if err != nil {
+			return err
+		}
+		for _, pointID := range pointIDs {
+			payloadJSON, err := marshalPayload(updates[pointID])
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx,
+				`UPDATE `+c.qualified(target.table)+` SET payload = payload || $2::jsonb WHERE point_id = $1`,
+				pointID, payloadJSON,
+			); err != nil {
+				return fmt.Errorf("set payload for point %s: %w", pointID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// ListExistingPointIDs 批量查询 collection 中已经存在的点 ID。
+func (c *Client) ListExistingPointIDs(ctx context.Context, collection string, pointIDs []string) (map[string]struct{}, error) {
+	ids := uniqueNonEmpty(pointIDs)
+	if len(ids) == 0 {
+		return map[string]struct{}{}, nil
+	}
+	target, err := c.resolveCollection(ctx, c.db, collection)
+	if err != nil {
+		return nil, err
+	}
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return nil, fmt.Errorf("marshal point ids: %w", err)
+	}
+	rows, err := c.db.QueryContext(ctx,
+		`SELECT point_id FROM `+c.qualified(target.table)+`
+		WHERE point_id IN (SELECT jsonb_array_elements_text($1::jsonb))`,
+		string(idsJSON),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get points: %w", err)
+	}
+	found, err := scanStrings(rows)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]struct{}, len(found))
+	for _, pointID := range found {
+		existing[pointID] = struct{}{}
+	}
+	return existing, nil
+}
+
+// ListPointIDsByFilter 根据 payload filter 批量枚举 point_id，按 point_id 键集分页直至取完。
+func (c *Client) ListPointIDsByFilter(
+	ctx context.Context,
+	collection string,
+	filter *fragmodel.VectorFilter,
+	limit int,
+) ([]string, error) {
+	if strings.TrimSpace(collection) == "" {
+		return nil, fmt.Errorf("%w: collection is required", ErrInvalidInput)
+	}
+	if limit <= 0 {
+		limit = defaultListPointIDsLimit
+	}
+	target, err := c.resolveCollection(ctx, c.db, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	pointIDs := make([]string, 0, limit)
+	cursor := ""
+	for {
+		args := &sqlArgs{}
+		where := "point_id > " + args.add(cursor)
+		clause, err := buildFilterClause(filter, "payload", args)
+		if err != nil {
+			return nil, err
+		}
+		if clause != "" {
+			where += " AND " + clause
+		}
+		rows, err := c.db.QueryContext(ctx,
+			`SELECT point_id FROM `+c.qualified(target.table)+` WHERE `+where+
+				` ORDER BY point_id LIMIT `+args.add(limit),
+			args.values...,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scroll points: %w", err)
+		}
+		page, err := scanStrings(rows)
+		if err != nil {
+			return nil, err
+		}
+		pointIDs = append(pointIDs, page...)
+		if len(page) < limit {
+			return pointIDs, nil
+		}
+		cursor = page[len(page)-1]
+	}
+}
+
+// DeletePoint 删除一个向量点。
+func (c *Client) DeletePoint(ctx context.Context, collection, pointID string) error {
+	return c.DeletePoints(ctx, collection, []string{pointID})
+}
+
+// DeletePoints 批量删除向量点，集合不存在时视为成功。
+func (c *Client) DeletePoints(ctx context.Context, collection string, pointIDs []string) error {
+	ids := uniqueNonEmpty(pointIDs)
+	if len(ids) == 0 {
+		return nil
+	}
+	target, err := c.resolveCollection(ctx, c.db, collection)
+	if errors.Is(err, ErrCollectionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return fmt.Errorf("marshal point ids: %w", err)
+	}
+	if _, err := c.db.ExecContext(ctx,
+		`DELETE FROM `+c.qualified(target.table)+` WHERE point_id IN (SELECT jsonb_array_elements_text($1::jsonb))`,
+		string(idsJSON),
+	); err != nil {
+		return fmt.Errorf("failed to delete points: %w", err)
+	}
+	return nil
+}
+
+// DeletePointsByFilter 根据过滤条件删除向量点，集合不存在时视为成功。
+//
+// 空过滤条件会被拒绝，避免误删整个集合；清空集合请使用 DeleteCollection。
+func (c *Client) DeletePointsByFilter(ctx context.Context, collection string, filter *fragmodel.VectorFilter) error {
+	args := &sqlArgs{}
+	clause, err := buildFilterClause(filter, "payload", args)
+	if err != nil {
+		return err
+	}
+	if clause == "" {
+		return fmt.Errorf("%w: delete by filter requires at least one condition", ErrInvalidInput)
+	}
+	target, err := c.resolveCollection(ctx, c.db, collection)
+	if errors.Is(err, ErrCollectionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := c.db.ExecContext(ctx,
+		`DELETE FROM `+c.qualified(target.table)+` WHERE `+clause, args.values...,
+	); err != nil {
+		return fmt.Errorf("failed to delete points by filter: %w", err)
+	}
+	return nil
+}
+
+// normalizeSparseInput 校验 sparse 写入并合并重复下标；文本推理需由 Qdrant 完成，这里显式拒绝。
+func normalizeSparseInput(input *fragmodel.SparseInput) (*sparseTerms, error) {
+	if input == nil {
+		return nil, nil
+	}
+	if input.Document != nil && strings.TrimSpace(input.Document.Text) != "" {
+		return nil, ErrSparseDocumentUnsupported
+	}
+	return normalizeSparseVector(input.Vector)
+}
+
+func normalizeSparseVector(vector *fragmodel.SparseVector) (*sparseTerms, error) {
+	if vector == nil || len(vector.Indices) == 0 || len(vector.Values) == 0 {
+		return nil, nil
+	}
+	if len(vector.Indices) != len(vector.Values) {
+		return nil, fmt.Errorf(
+			"%w: sparse vector indices and values length mismatch: indices=%d values=%d",
+			ErrInvalidInput,
+			len(vector.Indices),
+			len(vector.Values),
+		)
+	}
+	weights := make(map[int64]float64, len(vector.Indices))
+	for i, index := range vector.Indices {
+		weights[int64(index)] += float64(vector.Values[i])
+	}
+	terms := &sparseTerms{
+		Indices: make([]int64, 0, len(weights)),
+		Values:  make([]float64, 0, len(weights)),
+	}
+	for index := range weights {
+		terms.Indices = append(terms.Indices, index)
+	}
+	slices.Sort(terms.Indices)
+	for _, index := range terms.Indices {
+		terms.Values = append(terms.Values, weights[index])
+	}
+	return terms, nil
+}
+
+// formatVector 输出 pgvector 文本格式 "[v1,v2,...]"。
+func formatVector(vector []float64) (string, error) {
+	if len(vector) == 0 {
+		return "", fmt.Errorf("%w: dense vector is required", ErrInvalidInput)
+	}
+	var builder strings.Builder
+	builder.WriteByte('[')
+	for i, value := range vector {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return "", fmt.Errorf("%w: dense vector contains non-finite value at %d", ErrInvalidInput, i)
+		}
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(strconv.FormatFloat(float64(float32(value)), 'g', -1, 32))
+	}
+	builder.WriteByte(']')
+	return builder.String(), nil
+}
+
+func marshalPayload(payload map[string]any) (string, error) {
+	if payload == nil {
+		return "{}", nil
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("marshal payload: %w", err)
+	}
+	return string(encoded), nil
+}
+
+func uniqueNonEmpty(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		trimmed := strings.TrimSpace(value)
+		if trimmed == "" {
+			continue
+		}
+		if _, exists := seen[trimmed]; exists {
+			continue
+		}
+		seen[trimmed] = struct{}{}
+		result = append(result, trimmed)
+	}
+	return result
+}
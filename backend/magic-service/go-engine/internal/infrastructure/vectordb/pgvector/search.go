@@ -0,0 +1,181 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+)
+
+// SearchDenseWithFilter 使用余弦距离执行 dense 检索，score = 1 - cosine distance，与 Qdrant Cosine 一致。
+func (c *Client) SearchDenseWithFilter(ctx context.Context, request fragmodel.DenseSearchRequest) ([]*SimilarityResult, error) {
+	if request.TopK < 0 {
+		return nil, fmt.Errorf("%w: topK must be non-negative", ErrInvalidInput)
+	}
+	if name := strings.TrimSpace(request.VectorName); name != "" && name != fragmodel.DefaultDenseVectorName {
+		return nil, fmt.Errorf("%w: unsupported dense vector name %q", ErrInvalidInput, request.VectorName)
+	}
+	if request.TopK == 0 {
+		return []*SimilarityResult{}, nil
+	}
+	vectorLiteral, err := formatVector(request.Vector)
+	if err != nil {
+		return nil, err
+	}
+	target, err := c.resolveCollection(ctx, c.db, request.Collection)
+	if err != nil {
+		return nil, err
+	}
+
+	args := &sqlArgs{}
+	vectorArg := args.add(vectorLiteral) + "::vector"
+	clause, err := buildFilterClause(request.Filter, "payload", args)
+	if err != nil {
+		return nil, err
+	}
+	where := ""
+	if clause != "" {
+		where = "WHERE " + clause
+	}
+	// 分数阈值放在 LIMIT 之后的外层过滤：写进内层 WHERE 时被阈值淘汰的行也会占用 HNSW ef_search 名额，悄悄降低召回。
+	query := `SELECT point_id, payload, score FROM (
+			SELECT point_id, payload::text AS payload, 1 - (embedding <=> ` + vectorArg + `) AS score
+			FROM ` + c.qualified(target.table) + `
+			` + where + `
+			ORDER BY embedding <=> ` + vectorArg + `, point_id
+			LIMIT ` + args.add(request.TopK) + `
+		) AS nearest
+		WHERE nearest.score >= ` + args.add(request.ScoreThreshold) + `
+		ORDER BY nearest.score DESC, nearest.point_id`
+
+	rows, err := c.db.QueryContext(ctx, query, args.values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	return scanSimilarityResults(rows)
+}
+
+// SearchSparseWithFilter 在客户端稀疏向量上按 Qdrant IDF modifier 语义打分：
+// score = Σ q_i · d_i · ln((N - n_i + 0.5) / (n_i + 0.5) + 1)，N 为集合点数，n_i 为含该词项的点数。
+func (c *Client) SearchSparseWithFilter(ctx context.Context, request fragmodel.SparseSearchRequest) ([]*SimilarityResult, error) {
+	if request.TopK < 0 {
+		return nil, fmt.Errorf("%w: topK must be non-negative", ErrInvalidInput)
+	}
+	if name := strings.TrimSpace(request.VectorName); name != "" && name != fragmodel.DefaultSparseVectorName {
+		return nil, fmt.Errorf("%w: unsupported sparse vector name %q", ErrInvalidInput, request.VectorName)
+	}
+	if request.Document != nil && strings.TrimSpace(request.Document.Text) != "" {
+		return nil, ErrSparseDocumentUnsupported
+	}
+	terms, err := normalizeSparseVector(request.Vector)
+	if err != nil {
+		return nil, err
+	}
+	if terms == nil || request.TopK == 0 {
+		return []*SimilarityResult{}, nil
+	}
+	target, err := c.resolveCollection(ctx, c.db, request.Collection)
+	if err != nil {
+		return nil, err
+	}
+	termsJSON, err := json.Marshal(terms)
+	if err != nil {
+		return nil, fmt.Errorf("marshal sparse query: %w", err)
+	}
+
+	args := &sqlArgs{}
+	termsArg := args.add(string(termsJSON)) + "::jsonb"
+	where := []string{"s.score >= " + args.add(request.ScoreThreshold)}
+	clause, err := buildFilterClause(request.Filter, "p.payload", args)
+	if err != nil {
+		return nil, err
+	}
+	if clause != "" {
+		where = append(where, clause)
+	}
+	points := c.qualified(target.table)
+	termsTable := c.qualified(termsTableName(target.table))
+	query := `WITH q AS (
+			SELECT i.value::bigint AS term_index, w.value::double precision AS weight
+			FROM jsonb_array_elements_text(` + termsArg + ` -> 'indices') WITH ORDINALITY AS i(value, ord)
+			JOIN jsonb_array_elements_text(` + termsArg + ` -> 'values') WITH ORDINALITY AS w(value, ord) ON i.ord = w.ord
+		),
+		total AS (SELECT count(*)::double precision AS n FROM ` + points + `),
+		df AS (
+			SELECT t.term_index, count(*)::double precision AS n
+			FROM ` + termsTable + ` t JOIN q ON q.term_index = t.term_index
+			GROUP BY t.term_index
+		),
+		scored AS (
+			SELECT t.point_id,
+				sum(q.weight * t.weight * ln((total.n - df.n + 0.5) / (df.n + 0.5) + 1)) AS score
+			FROM ` + termsTable + ` t
+			JOIN q ON q.term_index = t.term_index
+			JOIN df ON df.term_index = t.term_index
+			CROSS JOIN total
+			GROUP BY t.point_id
+		)
+		SELECT p.point_id, p.payload::text, s.score
+		FROM scored s JOIN ` + points + ` p ON p.point_id = s.point_id
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY s.score DESC, p.point_id
+		LIMIT ` + args.add(request.TopK)
+
+	rows, err := c.db.QueryContext(ctx, query, args.values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search sparse: %w", err)
+	}
+	return scanSimilarityResults(rows)
+}
+
+func scanSimilarityResults(rows *sql.Rows) ([]*SimilarityResult, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+	results := make([]*SimilarityResult, 0)
+	for rows.Next() {
+		var (
+			pointID    string
+			payloadRaw []byte
+			score      float64
+		)
+		if err := rows.Scan(&pointID, &payloadRaw, &score); err != nil {
+			return nil, fmt.Errorf("scan search result: %w", err)
+		}
+		payload := map[string]any{}
+		if len(payloadRaw) > 0 {
+			if err := json.Unmarshal(payloadRaw, &payload); err != nil {
+				return nil, fmt.Errorf("decode payload of point %s: %w", pointID, err)
+			}
+		}
+		results = append(results, &SimilarityResult{
+			ID:       pointID,
+			Score:    score,
+			Payload:  payload,
+			PointID:  pointID,
+			Content:  stringFromPayload(payload, "content"),
+			Metadata: mapFromPayload(payload, "metadata"),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate search results: %w", err)
+	}
+	return results, nil
+}
+
+func stringFromPayload(payload map[string]any, key string) string {
+	if value, ok := payload[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+func mapFromPayload(payload map[string]any, key string) map[string]any {
+	if value, ok := payload[key].(map[string]any); ok {
+		return value
+	}
+	return nil
+}
@@ -44,8 +44,8 @@ type SimilarityResult struct {
 // ErrInvalidInput 表示参数无效
 var ErrInvalidInput = errors.New("invalid input")
 
-// ErrCollectionNotFound 表示集合不存在
-var ErrCollectionNotFound = errors.New("collection not found")
+// ErrCollectionNotFound 表示集合不存在，与其他向量库后端共用同一哨兵错误。
+var ErrCollectionNotFound = shared.ErrVectorCollectionNotFound
 
 // ErrIntegerOverflow 表示整数溢出
 var ErrIntegerOverflow = errors.New("integer overflow")
@@ -91,20 +91,21 @@ type sparseSearchLogMeta struct {
 
 // Client 是 Qdrant gRPC 客户端
 type Client struct {
-	conn        *grpc.ClientConn
-	collections pb.CollectionsClient
-	points      pb.PointsClient
-	apiKey      string
-	baseURI     string
-	grpcHost    string
-	grpcPort    int
-	httpClient  *http.Client
-	logger      *logging.SugaredLogger
-	schemaMu    sync.Mutex
-	writeSem    chan struct{}
-	logTiming   bool
-	slowLogMs   int
-	capability  atomic.Pointer[capabilitySnapshot]
+	conn              *grpc.ClientConn
+	collections       pb.CollectionsClient
+	points            pb.PointsClient
+	apiKey            string
+	baseURI           string
+	grpcHost          string
+	grpcPort          int
+	httpClient        *http.Client
+	logger            *logging.SugaredLogger
+	schemaMu          sync.Mutex
+	writeSem          chan struct{}
+	logTiming         bool
+	slowLogMs         int
+	capability        atomic.Pointer[capabilitySnapshot]
+	collectionOptions CollectionOptions
 }
 
 // Config 是 Qdrant 客户端配置
@@ -116,10 +117,15 @@ type Config struct {
 	MaxConcurrentWrites int
 	LogTimingEnabled    bool
 	LogSlowThresholdMs  int
+	Collection          CollectionOptions
 }
 
 // NewClient 创建一个新的 Qdrant 客户端
 func NewClient(cfg *Config, logger *logging.SugaredLogger) (*Client, error) {
+	collectionOptions, err := cfg.Collection.Normalize()
+	if err != nil {
+		return nil, fmt.Errorf("invalid Qdrant collection options: %w", err)
+	}
 	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
 
 	opts := []grpc.DialOption{
@@ -132,18 +138,19 @@ func NewClient(cfg *Config, logger *logging.SugaredLogger) (*Client, error) {
 	}
 
 	client := &Client{
-		conn:        conn,
-		collections: pb.NewCollectionsClient(conn),
-		points:      pb.NewPointsClient(conn),
-		apiKey:      strings.TrimSpace(cfg.Credential),
-		baseURI:     strings.TrimSpace(cfg.BaseURI),
-		grpcHost:    strings.TrimSpace(cfg.Host),
-		grpcPort:    cfg.Port,
-		httpClient:  &http.Client{Timeout: defaultProbeHTTPTimeout},
-		logger:      logger,
-		writeSem:    make(chan struct{}, normalizeMaxConcurrentWrites(cfg.MaxConcurrentWrites)),
-		logTiming:   cfg.LogTimingEnabled,
-		slowLogMs:   normalizeLogSlowThresholdMs(cfg.LogSlowThresholdMs),
+		conn:              conn,
+		collections:       pb.NewCollectionsClient(conn),
+		points:            pb.NewPointsClient(conn),
+		apiKey:            strings.TrimSpace(cfg.Credential),
+		baseURI:           strings.TrimSpace(cfg.BaseURI),
+		grpcHost:          strings.TrimSpace(cfg.Host),
+		grpcPort:          cfg.Port,
+		httpClient:        &http.Client{Timeout: defaultProbeHTTPTimeout},
+		logger:            logger,
+		writeSem:          make(chan struct{}, normalizeMaxConcurrentWrites(cfg.MaxConcurrentWrites)),
+		logTiming:         cfg.LogTimingEnabled,
+		slowLogMs:         normalizeLogSlowThresholdMs(cfg.LogSlowThresholdMs),
+		collectionOptions: collectionOptions,
 	}
 	client.initializeCapabilitySnapshot()
 	return client, nil
@@ -513,12 +520,7 @@ func (c *Client) CreateCollection(ctx context.Context, name string, vectorSize i
 		VectorsConfig: &pb.VectorsConfig{
 			Config: &pb.VectorsConfig_ParamsMap{
 				ParamsMap: &pb.VectorParamsMap{
-					Map: map[string]*pb.VectorParams{
-						fragmodel.DefaultDenseVectorName: {
-							Size:     uint64(vectorSize),
-							Distance: pb.Distance_Cosine,
-						},
-					},
+					Map: c.collectionOptions.collectionVectorParams(vectorSize),
 				},
 			},
 		},
@@ -534,7 +536,13 @@ func (c *Client) CreateCollection(ctx context.Context, name string, vectorSize i
 		return fmt.Errorf("failed to create collection %s: %w", name, err)
 	}
 
-	c.logger.InfoContext(ctx, "Created Qdrant collection", "name", name, "vectorSize", vectorSize)
+	c.logger.InfoContext(ctx, "Created Qdrant collection",
+		"name", name,
+		"vectorSize", vectorSize,
+		"onDisk", c.collectionOptions.OnDisk,
+		"quantization", c.collectionOptions.Quantization.Type,
+		"multiVectorSize", c.collectionOptions.MultiVectorSize,
+	)
 	return nil
 }
 
@@ -736,12 +744,14 @@ func (c *Client) GetCollectionInfo(ctx context.Context, name string) (*fragmodel
 		return nil, err
 	}
 	return &fragmodel.VectorCollectionInfo{
-		Name:                name,
-		VectorSize:          vectorSize,
-		Points:              points,
-		HasNamedDenseVector: hasNamedDenseVector(resp.GetResult()),
-		HasSparseVector:     hasSparseVector(resp.GetResult()),
-		PayloadSchemaKeys:   extractPayloadSchemaKeys(resp.GetResult()),
+		Name:                  name,
+		VectorSize:            vectorSize,
+		Points:                points,
+		HasNamedDenseVector:   hasNamedDenseVector(resp.GetResult()),
+		HasSparseVector:       hasSparseVector(resp.GetResult()),
+		HasMultiVector:        hasMultiVector(resp.GetResult()),
+		PayloadSchemaKeys:     extractPayloadSchemaKeys(resp.GetResult()),
+		StorageConfigMismatch: !c.collectionOptions.matchesCollection(resp.GetResult()),
 	}, nil
 }
 
@@ -944,6 +954,11 @@ func safeVectorSize(size uint64) (int64, error) {
 	return int64(size), nil
 }
 
+func hasMultiVector(info *pb.CollectionInfo) bool {
+	params := info.GetConfig().GetParams().GetVectorsConfig().GetParamsMap().GetMap()[fragmodel.DefaultMultiVectorName]
+	return params.GetMultivectorConfig() != nil
+}
+
 func hasNamedDenseVector(info *pb.CollectionInfo) bool {
 	if info == nil {
 		return false
@@ -1525,6 +1540,7 @@ func (c *Client) SearchDenseWithFilter(ctx context.Context, request fragmodel.De
 		ScoreThreshold: &threshold,
 		WithPayload:    &pb.WithPayloadSelector{SelectorOptions: &pb.WithPayloadSelector_Enable{Enable: true}},
 		VectorName:     new(orDefault(request.VectorName, fragmodel.DefaultDenseVectorName)),
+		Params:         c.collectionOptions.searchParams(),
 	}
 
 	if request.Filter != nil {
@@ -64,6 +64,7 @@ type fakePointsClient struct {
 	queryFn            fakeUnaryCall[pb.QueryPoints, pb.QueryResponse]
 	setPayloadFn       fakeUnaryCall[pb.SetPayloadPoints, pb.PointsOperationResponse]
 	createFieldIndexFn fakeUnaryCall[pb.CreateFieldIndexCollection, pb.PointsOperationResponse]
+	updateVectorsFn    fakeUnaryCall[pb.UpdatePointVectors, pb.PointsOperationResponse]
 }
 
 type fakeUnaryCall[Req any, Resp any] func(context.Context, *Req, ...grpc.CallOption) (*Resp, error)
@@ -96,6 +97,10 @@ func (f fakePointsClient) CreateFieldIndex(ctx context.Context, in *pb.CreateFie
 	return f.createFieldIndexFn(ctx, in, opts...)
 }
 
+func (f fakePointsClient) UpdateVectors(ctx context.Context, in *pb.UpdatePointVectors, opts ...grpc.CallOption) (*pb.PointsOperationResponse, error) {
+	return f.updateVectorsFn(ctx, in, opts...)
+}
+
 func collectionInfo(points uint64) *pb.CollectionInfo {
 	denseVectors := map[string]*pb.VectorParams{
 		fragmodel.DefaultDenseVectorName: {Size: testVectorSize},
@@ -0,0 +1,249 @@
+package qdrant
+
+import (
+	"fmt"
+	"strings"
+
+	pb "github.com/qdrant/go-client/qdrant"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+)
+
+const (
+	// QuantizationNone 表示不启用量化（默认）。
+	QuantizationNone = "none"
+	// QuantizationScalar 表示 int8 标量量化，内存约为原始向量的 1/4。
+	QuantizationScalar = "scalar"
+	// QuantizationProduct 表示乘积量化，压缩比由 Compression 决定。
+	QuantizationProduct = "product"
+	// QuantizationBinary 表示二值量化，适合高维且分布居中的向量。
+	QuantizationBinary = "binary"
+)
+
+var compressionRatios = map[string]pb.CompressionRatio{
+	"x4":  pb.CompressionRatio_x4,
+	"x8":  pb.CompressionRatio_x8,
+	"x16": pb.CompressionRatio_x16,
+	"x32": pb.CompressionRatio_x32,
+	"x64": pb.CompressionRatio_x64,
+}
+
+// CollectionOptions 是新建集合的存储参数与 dense 检索参数。
+//
+// 集合参数在 CreateCollection 时写入，蓝绿重建的新集合沿用同一份配置；
+// 已有集合与配置不一致时由 GetCollectionInfo 标记 StorageConfigMismatch，交由重建流程重新创建。
+type CollectionOptions struct {
+	// OnDisk 为 true 时原始 dense 向量存放在磁盘（mmap）
+	OnDisk bool
+	// HNSWM 与 HNSWEfConstruct 为 0 时使用 Qdrant 默认值
+	HNSWM           uint64
+	HNSWEfConstruct uint64
+	// HNSWEfSearch 为查询时的 hnsw_ef，0 表示由 Qdrant 决定
+	HNSWEfSearch uint64
+	Quantization QuantizationOptions
+	// MultiVectorSize 为 late-interaction 逐 token 向量维度，>0 时新建集合额外声明 MaxSim 多向量字段
+	MultiVectorSize uint64
+}
+
+// QuantizationOptions 是 dense 向量量化参数。
+type QuantizationOptions struct {
+	// Type 为 none、scalar、product 或 binary，为空视为 none
+	Type string
+	// Quantile 仅对 scalar 生效，0 表示使用 Qdrant 默认值
+	Quantile float32
+	// Compression 仅对 product 生效，取值 x4、x8、x16、x32、x64，为空时为 x16
+	Compression string
+	// AlwaysRAM 为 true 时量化向量常驻内存，与 OnDisk 搭配使用
+	AlwaysRAM bool
+	// Rescore 查询时是否用原始向量对量化候选重打分，nil 表示开启
+	Rescore *bool
+	// Oversampling 量化候选的过采样倍数，<=1 表示不过采样
+	Oversampling float64
+}
+
+// Normalize 返回归一化后的配置并校验取值。
+func (o CollectionOptions) Normalize() (CollectionOptions, error) {
+	quantization := o.Quantization
+	quantization.Type = strings.ToLower(strings.TrimSpace(quantization.Type))
+	if quantization.Type == "" {
+		quantization.Type = QuantizationNone
+	}
+	quantization.Compression = strings.ToLower(strings.TrimSpace(quantization.Compression))
+	switch quantization.Type {
+	case QuantizationNone, QuantizationBinary:
+	case QuantizationScalar:
+		if quantization.Quantile < 0 || quantization.Quantile > 1 {
+			return o, fmt.Errorf("%w: scalar quantization quantile must be within [0, 1], got %v", ErrInvalidInput, quantization.Quantile)
+		}
+	case QuantizationProduct:
+		if quantization.Compression == "" {
+			quantization.Compression = "x16"
+		}
+		if _, ok := compressionRatios[quantization.Compression]; !ok {
+			return o, fmt.Errorf("%w: unsupported product quantization compression %q", ErrInvalidInput, quantization.Compression)
+		}
+	default:
+		return o, fmt.Errorf("%w: unsupported quantization type %q", ErrInvalidInput, quantization.Type)
+	}
+	if quantization.Oversampling < 0 {
+		return o, fmt.Errorf("%w: quantization oversampling must be non-negative", ErrInvalidInput)
+	}
+	o.Quantization = quantization
+	return o, nil
+}
+
+func (o CollectionOptions) quantizationEnabled() bool {
+	return o.Quantization.Type != "" && o.Quantization.Type != QuantizationNone
+}
+
+// collectionVectorParams 构造新建集合的全部 named dense 向量配置。
+func (o CollectionOptions) collectionVectorParams(vectorSize int64) map[string]*pb.VectorParams {
+	params := map[string]*pb.VectorParams{
+		fragmodel.DefaultDenseVectorName: o.denseVectorParams(vectorSize),
+	}
+	if o.MultiVectorSize > 0 {
+		params[fragmodel.DefaultMultiVectorName] = o.multiVectorParams()
+	}
+	return params
+}
+
+// multiVectorParams 构造 late-interaction 多向量配置。
+//
+// 多向量只用于对已召回候选做 MaxSim 重排，关闭 HNSW（m=0）避免为逐 token 向量建图。
+func (o CollectionOptions) multiVectorParams() *pb.VectorParams {
+	params := &pb.VectorParams{
+		Size:              o.MultiVectorSize,
+		Distance:          pb.Distance_Cosine,
+		HnswConfig:        &pb.HnswConfigDiff{M: new(uint64(0))},
+		MultivectorConfig: &pb.MultiVectorConfig{Comparator: pb.MultiVectorComparator_MaxSim},
+	}
+	if o.OnDisk {
+		params.OnDisk = new(true)
+	}
+	return params
+}
+
+// denseVectorParams 构造带存储参数的 dense 向量配置。
+func (o CollectionOptions) denseVectorParams(vectorSize int64) *pb.VectorParams {
+	params := &pb.VectorParams{
+		Size:     uint64(vectorSize),
+		Distance: pb.Distance_Cosine,
+	}
+	if o.OnDisk {
+		params.OnDisk = new(true)
+	}
+	if o.HNSWM > 0 || o.HNSWEfConstruct > 0 {
+		params.HnswConfig = &pb.HnswConfigDiff{}
+		if o.HNSWM > 0 {
+			params.HnswConfig.M = new(o.HNSWM)
+		}
+		if o.HNSWEfConstruct > 0 {
+			params.HnswConfig.EfConstruct = new(o.HNSWEfConstruct)
+		}
+	}
+	params.QuantizationConfig = o.quantizationConfig()
+	return params
+}
+
+func (o CollectionOptions) quantizationConfig() *pb.QuantizationConfig {
+	quantization := o.Quantization
+	var alwaysRAM *bool
+	if quantization.AlwaysRAM {
+		alwaysRAM = new(true)
+	}
+	switch quantization.Type {
+	case QuantizationScalar:
+		scalar := &pb.ScalarQuantization{Type: pb.QuantizationType_Int8, AlwaysRam: alwaysRAM}
+		if quantization.Quantile > 0 {
+			scalar.Quantile = new(quantization.Quantile)
+		}
+		return &pb.QuantizationConfig{Quantization: &pb.QuantizationConfig_Scalar{Scalar: scalar}}
+	case QuantizationProduct:
+		return &pb.QuantizationConfig{Quantization: &pb.QuantizationConfig_Product{Product: &pb.ProductQuantization{
+			Compression: compressionRatios[quantization.Compression],
+			AlwaysRam:   alwaysRAM,
+		}}}
+	case QuantizationBinary:
+		return &pb.QuantizationConfig{Quantization: &pb.QuantizationConfig_Binary{Binary: &pb.BinaryQuantization{AlwaysRam: alwaysRAM}}}
+	default:
+		return nil
+	}
+}
+
+// searchParams 构造 dense 检索参数；未配置 hnsw_ef 且未启用量化时返回 nil，沿用 Qdrant 默认行为。
+func (o CollectionOptions) searchParams() *pb.SearchParams {
+	if o.HNSWEfSearch == 0 && !o.quantizationEnabled() {
+		return nil
+	}
+	params := &pb.SearchParams{}
+	if o.HNSWEfSearch > 0 {
+		params.HnswEf = new(o.HNSWEfSearch)
+	}
+	if o.quantizationEnabled() {
+		rescore := o.Quantization.Rescore == nil || *o.Quantization.Rescore
+		params.Quantization = &pb.QuantizationSearchParams{Rescore: new(rescore)}
+		if o.Quantization.Oversampling > 1 {
+			params.Quantization.Oversampling = new(o.Quantization.Oversampling)
+		}
+	}
+	return params
+}
+
+// matchesCollection 判断已有集合的 dense 向量存储参数是否与当前配置一致。
+//
+// 只比较配置显式指定的 HNSW 参数；量化与 on_disk 始终比较，以便关闭量化后也能触发重建。
+// 启用多向量时集合缺少同维度的 MaxSim 字段也视为不一致；关闭多向量时遗留字段不影响复用。
+func (o CollectionOptions) matchesCollection(info *pb.CollectionInfo) bool {
+	config := info.GetConfig()
+	if o.MultiVectorSize > 0 {
+		multi := config.GetParams().GetVectorsConfig().GetParamsMap().GetMap()[fragmodel.DefaultMultiVectorName]
+		if multi.GetMultivectorConfig() == nil || multi.GetSize() != o.MultiVectorSize {
+			return false
+		}
+	}
+	params := config.GetParams().GetVectorsConfig().GetParamsMap().GetMap()[fragmodel.DefaultDenseVectorName]
+	if params == nil {
+		params = config.GetParams().GetVectorsConfig().GetParams()
+	}
+	if params == nil {
+		return true
+	}
+	if params.GetOnDisk() != o.OnDisk {
+		return false
+	}
+	hnsw := params.GetHnswConfig()
+	if hnsw == nil {
+		hnsw = config.GetHnswConfig()
+	}
+	if o.HNSWM > 0 && hnsw.GetM() != o.HNSWM {
+		return false
+	}
+	if o.HNSWEfConstruct > 0 && hnsw.GetEfConstruct() != o.HNSWEfConstruct {
+		return false
+	}
+	quantization := params.GetQuantizationConfig()
+	if quantization == nil {
+		quantization = config.GetQuantizationConfig()
+	}
+	return quantizationMatches(quantization, o.quantizationConfig())
+}
+
+func quantizationMatches(actual, expected *pb.QuantizationConfig) bool {
+	switch want := expected.GetQuantization().(type) {
+	case nil:
+		return actual.GetQuantization() == nil
+	case *pb.QuantizationConfig_Scalar:
+		got := actual.GetScalar()
+		return got != nil && got.GetAlwaysRam() == want.Scalar.GetAlwaysRam() &&
+			(want.Scalar.Quantile == nil || got.GetQuantile() == want.Scalar.GetQuantile())
+	case *pb.QuantizationConfig_Product:
+		got := actual.GetProduct()
+		return got != nil && got.GetAlwaysRam() == want.Product.GetAlwaysRam() &&
+			got.GetCompression() == want.Product.GetCompression()
+	case *pb.QuantizationConfig_Binary:
+		got := actual.GetBinary()
+		return got != nil && got.GetAlwaysRam() == want.Binary.GetAlwaysRam()
+	default:
+		return false
+	}
+}
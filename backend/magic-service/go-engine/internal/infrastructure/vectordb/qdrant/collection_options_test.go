@@ -0,0 +1,161 @@
+package qdrant_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	qdrantpkg "magic/internal/infrastructure/vectordb/qdrant"
+)
+
+func TestCollectionOptionsNormalize(t *testing.T) {
+	t.Parallel()
+
+	normalized, err := qdrantpkg.CollectionOptions{
+		Quantization: qdrantpkg.QuantizationOptions{Type: " Product "},
+	}.Normalize()
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if normalized.Quantization.Type != qdrantpkg.QuantizationProduct || normalized.Quantization.Compression != "x16" {
+		t.Fatalf("unexpected normalized quantization: %#v", normalized.Quantization)
+	}
+
+	empty, err := qdrantpkg.CollectionOptions{}.Normalize()
+	if err != nil || empty.Quantization.Type != qdrantpkg.QuantizationNone {
+		t.Fatalf("expected empty type to normalize to none, got %#v err=%v", empty.Quantization, err)
+	}
+
+	invalid := []qdrantpkg.QuantizationOptions{
+		{Type: "fp8"},
+		{Type: qdrantpkg.QuantizationScalar, Quantile: 1.5},
+		{Type: qdrantpkg.QuantizationProduct, Compression: "x3"},
+		{Type: qdrantpkg.QuantizationBinary, Oversampling: -1},
+	}
+	for _, quantization := range invalid {
+		if _, err := (qdrantpkg.CollectionOptions{Quantization: quantization}).Normalize(); !errors.Is(err, qdrantpkg.ErrInvalidInput) {
+			t.Fatalf("expected ErrInvalidInput for %#v, got %v", quantization, err)
+		}
+	}
+}
+
+func TestClientCreateCollectionAppliesStorageOptions(t *testing.T) {
+	t.Parallel()
+
+	var created *pb.CreateCollection
+	collections := newDefaultCollectionsClient().(fakeCollectionsClient)
+	collections.createFn = func(_ context.Context, in *pb.CreateCollection, _ ...grpc.CallOption) (*pb.CollectionOperationResponse, error) {
+		created = in
+		return &pb.CollectionOperationResponse{}, nil
+	}
+	client := newClientWithClients(defaultMaxConcurrentWritesForTest, collections, fakePointsClient{})
+	if err := qdrantpkg.SetCollectionOptionsForTest(client, qdrantpkg.CollectionOptions{
+		OnDisk:          true,
+		HNSWM:           32,
+		HNSWEfConstruct: 200,
+		Quantization: qdrantpkg.QuantizationOptions{
+			Type:      qdrantpkg.QuantizationScalar,
+			Quantile:  0.99,
+			AlwaysRAM: true,
+		},
+	}); err != nil {
+		t.Fatalf("SetCollectionOptionsForTest() error = %v", err)
+	}
+
+	if err := client.CreateCollection(context.Background(), testCollectionName, 8); err != nil {
+		t.Fatalf("CreateCollection() error = %v", err)
+	}
+	params := created.GetVectorsConfig().GetParamsMap().GetMap()[fragmodel.DefaultDenseVectorName]
+	if params.GetSize() != 8 || !params.GetOnDisk() {
+		t.Fatalf("unexpected dense params: %#v", params)
+	}
+	if params.GetHnswConfig().GetM() != 32 || params.GetHnswConfig().GetEfConstruct() != 200 {
+		t.Fatalf("unexpected hnsw config: %#v", params.GetHnswConfig())
+	}
+	scalar := params.GetQuantizationConfig().GetScalar()
+	if scalar == nil || scalar.GetType() != pb.QuantizationType_Int8 || scalar.GetQuantile() != 0.99 || !scalar.GetAlwaysRam() {
+		t.Fatalf("unexpected quantization config: %#v", params.GetQuantizationConfig())
+	}
+}
+
+func TestClientSearchDenseAppliesRescoreAndOversampling(t *testing.T) {
+	t.Parallel()
+
+	var searched *pb.SearchPoints
+	client := newClientWithClients(defaultMaxConcurrentWritesForTest, newDefaultCollectionsClient(), fakePointsClient{
+		searchFn: func(_ context.Context, in *pb.SearchPoints, _ ...grpc.CallOption) (*pb.SearchResponse, error) {
+			searched = in
+			return &pb.SearchResponse{}, nil
+		},
+	})
+	if err := qdrantpkg.SetCollectionOptionsForTest(client, qdrantpkg.CollectionOptions{
+		HNSWEfSearch: 128,
+		Quantization: qdrantpkg.QuantizationOptions{Type: qdrantpkg.QuantizationBinary, Oversampling: 3},
+	}); err != nil {
+		t.Fatalf("SetCollectionOptionsForTest() error = %v", err)
+	}
+
+	if _, err := client.SearchDenseWithFilter(context.Background(), fragmodel.DenseSearchRequest{
+		Collection: testCollectionName,
+		Vector:     []float64{0.1, 0.2},
+		TopK:       5,
+	}); err != nil {
+		t.Fatalf("SearchDenseWithFilter() error = %v", err)
+	}
+	params := searched.GetParams()
+	if params.GetHnswEf() != 128 || !params.GetQuantization().GetRescore() || params.GetQuantization().GetOversampling() != 3 {
+		t.Fatalf("unexpected search params: %#v", params)
+	}
+}
+
+func TestClientSearchDenseWithoutOptionsKeepsDefaultParams(t *testing.T) {
+	t.Parallel()
+
+	var searched *pb.SearchPoints
+	client := newClientWithClients(defaultMaxConcurrentWritesForTest, newDefaultCollectionsClient(), fakePointsClient{
+		searchFn: func(_ context.Context, in *pb.SearchPoints, _ ...grpc.CallOption) (*pb.SearchResponse, error) {
+			searched = in
+			return &pb.SearchResponse{}, nil
+		},
+	})
+	if _, err := client.SearchDenseWithFilter(context.Background(), fragmodel.DenseSearchRequest{
+		Collection: testCollectionName,
+		Vector:     []float64{0.1, 0.2},
+		TopK:       5,
+	}); err != nil {
+		t.Fatalf("SearchDenseWithFilter() error = %v", err)
+	}
+	if searched.GetParams() != nil {
+		t.Fatalf("expected no search params, got %#v", searched.GetParams())
+	}
+}
+
+func TestClientGetCollectionInfoReportsStorageConfigMismatch(t *testing.T) {
+	t.Parallel()
+
+	client := newClient()
+	info, err := client.GetCollectionInfo(context.Background(), testCollectionName)
+	if err != nil {
+		t.Fatalf("GetCollectionInfo() error = %v", err)
+	}
+	if info.StorageConfigMismatch {
+		t.Fatal("expected default collection to match default options")
+	}
+
+	if err := qdrantpkg.SetCollectionOptionsForTest(client, qdrantpkg.CollectionOptions{
+		Quantization: qdrantpkg.QuantizationOptions{Type: qdrantpkg.QuantizationScalar},
+	}); err != nil {
+		t.Fatalf("SetCollectionOptionsForTest() error = %v", err)
+	}
+	info, err = client.GetCollectionInfo(context.Background(), testCollectionName)
+	if err != nil {
+		t.Fatalf("GetCollectionInfo() error = %v", err)
+	}
+	if !info.StorageConfigMismatch {
+		t.Fatal("expected quantization change to be reported as storage config mismatch")
+	}
+}
@@ -0,0 +1,38 @@
+package qdrant_test
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"magic/internal/infrastructure/logging"
+	"magic/internal/infrastructure/vectordb/qdrant"
+	"magic/internal/infrastructure/vectordb/vectordbtest"
+)
+
+// TestQdrantConformance 需要真实 Qdrant：设置 QDRANT_CONFORMANCE_HOST / QDRANT_CONFORMANCE_PORT 后运行。
+func TestQdrantConformance(t *testing.T) {
+	t.Parallel()
+	host := os.Getenv("QDRANT_CONFORMANCE_HOST")
+	port, err := strconv.Atoi(os.Getenv("QDRANT_CONFORMANCE_PORT"))
+	if host == "" || err != nil {
+		t.Skip("QDRANT_CONFORMANCE_HOST/QDRANT_CONFORMANCE_PORT not set")
+	}
+	client, err := qdrant.NewClient(&qdrant.Config{
+		Host:       host,
+		Port:       port,
+		BaseURI:    os.Getenv("QDRANT_CONFORMANCE_BASE_URI"),
+		Credential: os.Getenv("QDRANT_CONFORMANCE_API_KEY"),
+	}, logging.New())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	vectordbtest.Run(t, func(*testing.T) vectordbtest.Backend {
+		return vectordbtest.Backend{
+			Management: qdrant.NewVectorDBManagementRepository(client),
+			Data:       qdrant.NewVectorDBDataRepository[vectordbtest.Payload](client),
+		}
+	})
+}
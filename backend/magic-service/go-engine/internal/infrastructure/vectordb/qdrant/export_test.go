@@ -199,3 +199,12 @@ func CurrentSparseSearchPlanForTest(client *Client, mode string) SparseSearchPla
 func BuildTimingLogMessageForTest(duration time.Duration, operation string, keysAndValues ...any) string {
 	return buildQdrantLogMessage(duration, operation, keysAndValues...)
 }
+
+func SetCollectionOptionsForTest(client *Client, options CollectionOptions) error {
+	normalized, err := options.Normalize()
+	if err != nil {
+		return err
+	}
+	client.collectionOptions = normalized
+	return nil
+}
@@ -0,0 +1,175 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	shared "magic/internal/domain/knowledge/shared"
+)
+
+// StoreMultiVectors 通过 UpdateVectors 写入 late-interaction 多向量，保留点上已有的 dense / sparse 向量与 payload。
+//
+// 集合未声明多向量字段（尚未重建）时返回 shared.ErrMultiVectorNotConfigured，由调用方决定是否跳过。
+func (c *Client) StoreMultiVectors(ctx context.Context, collection string, pointIDs []string, multiVectors [][][]float64) error {
+	startedAt := time.Now()
+	if len(pointIDs) != len(multiVectors) {
+		return fmt.Errorf("%w: pointIDs=%d, multiVectors=%d", ErrInvalidInput, len(pointIDs), len(multiVectors))
+	}
+	if len(pointIDs) == 0 {
+		return nil
+	}
+	size, err := c.multiVectorSize(ctx, collection)
+	if err != nil {
+		return err
+	}
+
+	points := make([]*pb.PointVectors, 0, len(pointIDs))
+	for i, pointID := range pointIDs {
+		pointID = strings.TrimSpace(pointID)
+		if pointID == "" {
+			return fmt.Errorf("%w: point id is required at %d", ErrInvalidInput, i)
+		}
+		tokens, err := toFloat32MultiVector(multiVectors[i], size)
+		if err != nil {
+			return fmt.Errorf("point %s: %w", pointID, err)
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		points = append(points, &pb.PointVectors{
+			Id: &pb.PointId{PointIdOptions: &pb.PointId_Uuid{Uuid: pointID}},
+			Vectors: pb.NewVectorsMap(map[string]*pb.Vector{
+				fragmodel.DefaultMultiVectorName: pb.NewVectorMulti(tokens),
+			}),
+		})
+	}
+	if len(points) == 0 {
+		return nil
+	}
+
+	release, err := c.acquireWritePermit(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	wait := true
+	_, err = c.points.UpdateVectors(c.authContext(ctx), &pb.UpdatePointVectors{
+		CollectionName: collection,
+		Wait:           &wait,
+		Points:         points,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to update multi vectors: %w", err)
+	}
+	c.logOperationTiming(ctx, "store_multi_vectors", startedAt, err,
+		"collection", collection,
+		"point_count", len(points),
+	)
+	return err
+}
+
+// SearchMultiVectorWithFilter 使用逐 token 查询向量按 MaxSim 打分；PointIDs 非空时只在这些点内检索。
+func (c *Client) SearchMultiVectorWithFilter(ctx context.Context, request fragmodel.MultiVectorSearchRequest) ([]*SimilarityResult, error) {
+	startedAt := time.Now()
+	if request.TopK < 0 {
+		return nil, fmt.Errorf("%w: topK must be non-negative", ErrInvalidInput)
+	}
+	if request.TopK == 0 {
+		return []*SimilarityResult{}, nil
+	}
+	tokens, err := toFloat32MultiVector(request.Vectors, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: query multi vector is required", ErrInvalidInput)
+	}
+	limit, err := qdrantSearchLimit(request.TopK)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &pb.QueryPoints{
+		CollectionName: request.Collection,
+		Query:          pb.NewQueryMulti(tokens),
+		Using:          new(orDefault(request.VectorName, fragmodel.DefaultMultiVectorName)),
+		Limit:          &limit,
+		Filter:         buildMultiVectorFilter(request.Filter, request.PointIDs),
+		WithPayload:    &pb.WithPayloadSelector{SelectorOptions: &pb.WithPayloadSelector_Enable{Enable: true}},
+	}
+	if request.ScoreThreshold > 0 {
+		req.ScoreThreshold = new(float32(request.ScoreThreshold))
+	}
+
+	resp, err := c.points.Query(c.authContext(ctx), req)
+	if err != nil {
+		err = fmt.Errorf("qdrant multi vector query: %w", err)
+	}
+	c.logOperationTiming(ctx, "search_multi_vector", startedAt, err,
+		"collection", request.Collection,
+		"top_k", request.TopK,
+		"query_token_count", len(tokens),
+		"candidate_point_count", len(request.PointIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return similarityResultsFromScoredPoints(resp.GetResult()), nil
+}
+
+// multiVectorSize 读取集合多向量字段的维度，字段不存在时返回 shared.ErrMultiVectorNotConfigured。
+func (c *Client) multiVectorSize(ctx context.Context, collection string) (int64, error) {
+	resp, err := c.collections.Get(c.authContext(ctx), &pb.GetCollectionInfoRequest{CollectionName: collection})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return 0, ErrCollectionNotFound
+		}
+		return 0, fmt.Errorf("failed to get collection info %s: %w", collection, err)
+	}
+	if !hasMultiVector(resp.GetResult()) {
+		return 0, fmt.Errorf("%w: collection=%s", shared.ErrMultiVectorNotConfigured, collection)
+	}
+	params := resp.GetResult().GetConfig().GetParams().GetVectorsConfig().GetParamsMap().GetMap()[fragmodel.DefaultMultiVectorName]
+	return safeVectorSize(params.GetSize())
+}
+
+// toFloat32MultiVector 转换逐 token 向量；expectedSize>0 时校验每个 token 的维度。
+func toFloat32MultiVector(vectors [][]float64, expectedSize int64) ([][]float32, error) {
+	converted := make([][]float32, 0, len(vectors))
+	for i, vector := range vectors {
+		if len(vector) == 0 {
+			continue
+		}
+		if expectedSize > 0 && int64(len(vector)) != expectedSize {
+			return nil, fmt.Errorf("%w: multi vector token %d size %d does not match %d", ErrInvalidInput, i, len(vector), expectedSize)
+		}
+		converted = append(converted, toFloat32Vector(vector))
+	}
+	return converted, nil
+}
+
+func buildMultiVectorFilter(filter *fragmodel.VectorFilter, pointIDs []string) *pb.Filter {
+	qdrantFilter := buildQdrantFilter(filter)
+	ids := make([]*pb.PointId, 0, len(pointIDs))
+	for _, pointID := range pointIDs {
+		if trimmed := strings.TrimSpace(pointID); trimmed != "" {
+			ids = append(ids, &pb.PointId{PointIdOptions: &pb.PointId_Uuid{Uuid: trimmed}})
+		}
+	}
+	if len(ids) == 0 {
+		return qdrantFilter
+	}
+	if qdrantFilter == nil {
+		qdrantFilter = &pb.Filter{}
+	}
+	qdrantFilter.Must = append(qdrantFilter.Must, pb.NewHasID(ids...))
+	return qdrantFilter
+}
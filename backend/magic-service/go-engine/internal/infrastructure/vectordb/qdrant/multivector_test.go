@@ -0,0 +1,162 @@
+package qdrant_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc"
+
+	fragmodel "magic/internal/domain/knowledge/fragment/model"
+	"magic/internal/domain/knowledge/shared"
+	qdrantpkg "magic/internal/infrastructure/vectordb/qdrant"
+)
+
+const testMultiVectorSize uint64 = 3
+
+func multiVectorCollectionsClient() pb.CollectionsClient {
+	collections := newDefaultCollectionsClient().(fakeCollectionsClient)
+	collections.getFn = func(_ context.Context, _ *pb.GetCollectionInfoRequest, _ ...grpc.CallOption) (*pb.GetCollectionInfoResponse, error) {
+		info := collectionInfo(2)
+		info.GetConfig().GetParams().GetVectorsConfig().GetParamsMap().Map[fragmodel.DefaultMultiVectorName] = &pb.VectorParams{
+			Size:              testMultiVectorSize,
+			Distance:          pb.Distance_Cosine,
+			MultivectorConfig: &pb.MultiVectorConfig{Comparator: pb.MultiVectorComparator_MaxSim},
+		}
+		return &pb.GetCollectionInfoResponse{Result: info}, nil
+	}
+	return collections
+}
+
+func TestClientCreateCollectionDeclaresMultiVector(t *testing.T) {
+	t.Parallel()
+
+	var created *pb.CreateCollection
+	collections := newDefaultCollectionsClient().(fakeCollectionsClient)
+	collections.createFn = func(_ context.Context, in *pb.CreateCollection, _ ...grpc.CallOption) (*pb.CollectionOperationResponse, error) {
+		created = in
+		return &pb.CollectionOperationResponse{}, nil
+	}
+	client := newClientWithClients(defaultMaxConcurrentWritesForTest, collections, fakePointsClient{})
+	if err := qdrantpkg.SetCollectionOptionsForTest(client, qdrantpkg.CollectionOptions{MultiVectorSize: 128}); err != nil {
+		t.Fatalf("SetCollectionOptionsForTest() error = %v", err)
+	}
+	if err := client.CreateCollection(context.Background(), testCollectionName, 8); err != nil {
+		t.Fatalf("CreateCollection() error = %v", err)
+	}
+
+	params := created.GetVectorsConfig().GetParamsMap().GetMap()[fragmodel.DefaultMultiVectorName]
+	if params.GetSize() != 128 || params.GetMultivectorConfig().GetComparator() != pb.MultiVectorComparator_MaxSim {
+		t.Fatalf("unexpected multi vector params: %#v", params)
+	}
+	if params.GetHnswConfig().GetM() != 0 {
+		t.Fatalf("expected multi vector hnsw to be disabled, got %#v", params.GetHnswConfig())
+	}
+}
+
+func TestClientGetCollectionInfoReportsMissingMultiVector(t *testing.T) {
+	t.Parallel()
+
+	client := newClient()
+	if err := qdrantpkg.SetCollectionOptionsForTest(client, qdrantpkg.CollectionOptions{MultiVectorSize: testMultiVectorSize}); err != nil {
+		t.Fatalf("SetCollectionOptionsForTest() error = %v", err)
+	}
+	info, err := client.GetCollectionInfo(context.Background(), testCollectionName)
+	if err != nil {
+		t.Fatalf("GetCollectionInfo() error = %v", err)
+	}
+	if info.HasMultiVector || !info.StorageConfigMismatch {
+		t.Fatalf("expected missing multi vector to require rebuild, got %#v", info)
+	}
+
+	client = newClientWithClients(defaultMaxConcurrentWritesForTest, multiVectorCollectionsClient(), fakePointsClient{})
+	if err := qdrantpkg.SetCollectionOptionsForTest(client, qdrantpkg.CollectionOptions{MultiVectorSize: testMultiVectorSize}); err != nil {
+		t.Fatalf("SetCollectionOptionsForTest() error = %v", err)
+	}
+	info, err = client.GetCollectionInfo(context.Background(), testCollectionName)
+	if err != nil {
+		t.Fatalf("GetCollectionInfo() error = %v", err)
+	}
+	if !info.HasMultiVector || info.StorageConfigMismatch {
+		t.Fatalf("expected multi vector collection to match, got %#v", info)
+	}
+}
+
+func TestClientStoreMultiVectorsUpdatesNamedVector(t *testing.T) {
+	t.Parallel()
+
+	var updated *pb.UpdatePointVectors
+	client := newClientWithClients(defaultMaxConcurrentWritesForTest, multiVectorCollectionsClient(), fakePointsClient{
+		updateVectorsFn: func(_ context.Context, in *pb.UpdatePointVectors, _ ...grpc.CallOption) (*pb.PointsOperationResponse, error) {
+			updated = in
+			return &pb.PointsOperationResponse{}, nil
+		},
+	})
+
+	err := client.StoreMultiVectors(context.Background(), testCollectionName, []string{"p1"}, [][][]float64{{{1, 0, 0}, {0, 1, 0}}})
+	if err != nil {
+		t.Fatalf("StoreMultiVectors() error = %v", err)
+	}
+	if len(updated.GetPoints()) != 1 || !updated.GetWait() {
+		t.Fatalf("unexpected update request: %#v", updated)
+	}
+	vector := updated.GetPoints()[0].GetVectors().GetVectors().GetVectors()[fragmodel.DefaultMultiVectorName]
+	if len(vector.GetMultiDense().GetVectors()) != 2 {
+		t.Fatalf("expected two token vectors, got %#v", vector)
+	}
+
+	err = client.StoreMultiVectors(context.Background(), testCollectionName, []string{"p1"}, [][][]float64{{{1, 0}}})
+	if !errors.Is(err, qdrantpkg.ErrInvalidInput) {
+		t.Fatalf("expected token dimension mismatch to be rejected, got %v", err)
+	}
+}
+
+func TestClientStoreMultiVectorsRequiresMultiVectorField(t *testing.T) {
+	t.Parallel()
+
+	client := newClient()
+	err := client.StoreMultiVectors(context.Background(), testCollectionName, []string{"p1"}, [][][]float64{{{1, 0}}})
+	if !errors.Is(err, shared.ErrMultiVectorNotConfigured) {
+		t.Fatalf("expected ErrMultiVectorNotConfigured, got %v", err)
+	}
+}
+
+func TestClientSearchMultiVectorRestrictsCandidates(t *testing.T) {
+	t.Parallel()
+
+	var queried *pb.QueryPoints
+	client := newClientWithClients(defaultMaxConcurrentWritesForTest, newDefaultCollectionsClient(), fakePointsClient{
+		queryFn: func(_ context.Context, in *pb.QueryPoints, _ ...grpc.CallOption) (*pb.QueryResponse, error) {
+			queried = in
+			return &pb.QueryResponse{Result: []*pb.ScoredPoint{{
+				Id:    pb.NewID("p2"),
+				Score: 1.5,
+			}}}, nil
+		},
+	})
+
+	results, err := client.SearchMultiVectorWithFilter(context.Background(), fragmodel.MultiVectorSearchRequest{
+		Collection: testCollectionName,
+		Vectors:    [][]float64{{1, 0, 0}, {0, 1, 0}},
+		PointIDs:   []string{"p1", "p2"},
+		TopK:       2,
+		Filter: &fragmodel.VectorFilter{Must: []fragmodel.FieldFilter{{
+			Key:   "knowledge_code",
+			Match: fragmodel.Match{EqString: new("kb1")},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("SearchMultiVectorWithFilter() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "p2" || results[0].Score != 1.5 {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	if queried.GetUsing() != fragmodel.DefaultMultiVectorName || len(queried.GetQuery().GetNearest().GetMultiDense().GetVectors()) != 2 {
+		t.Fatalf("unexpected multi vector query: %#v", queried)
+	}
+	must := queried.GetFilter().GetMust()
+	if len(must) != 2 || len(must[1].GetHasId().GetHasId()) != 2 {
+		t.Fatalf("expected payload filter plus candidate id filter, got %#v", must)
+	}
+}
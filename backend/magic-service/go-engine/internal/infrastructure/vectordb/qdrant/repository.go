@@ -269,6 +269,21 @@ func (r *VectorDBDataRepository[T]) SearchSparseWithFilter(ctx context.Context,
 	return convertResults[T](results)
 }
 
+// StoreMultiVectors 写入 late-interaction 多向量。
+func (r *VectorDBDataRepository[T]) StoreMultiVectors(ctx context.Context, collection string, pointIDs []string, multiVectors [][][]float64) error {
+	return r.client.StoreMultiVectors(ctx, collection, pointIDs, multiVectors)
+}
+
+// SearchMultiVectorWithFilter 执行 late-interaction MaxSim 检索。
+func (r *VectorDBDataRepository[T]) SearchMultiVectorWithFilter(ctx context.Context, request fragmodel.MultiVectorSearchRequest) ([]*fragmodel.VectorSearchResult[T], error) {
+	results, err := r.client.SearchMultiVectorWithFilter(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertResults[T](results)
+}
+
 func (r *VectorDBDataRepository[T]) ensureVectorDimension(ctx context.Context, collection string, vectors [][]float64) error {
 	info, err := r.client.GetCollectionInfo(ctx, collection)
 	if err != nil {
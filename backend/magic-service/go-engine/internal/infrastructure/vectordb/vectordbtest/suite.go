@@ -0,0 +1,436 @@
+// Package vectordbtest 提供向量库后端共用的一致性测试套件。
+//
+// 各后端在自己的 _test 包中构造 Backend 并调用 Run，确保集合/alias 生命周期、
+// dense / sparse 打分、payload 过滤与点删除等行为与 Qdrant 语义一致。
+package vectordbtest
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	shared "magic/internal/domain/knowledge/shared"
+)
+
+const (
+	vectorSize      = 4
+	eventualTimeout = 5 * time.Second
+	eventualTick    = 50 * time.Millisecond
+	scoreTolerance  = 1e-3
+)
+
+// Payload 是套件写入的测试 payload，覆盖字符串、数组、数值、布尔与嵌套字段。
+type Payload struct {
+	Content      string         `json:"content"`
+	DocumentCode string         `json:"document_code"`
+	Tags         []string       `json:"tags"`
+	Position     int            `json:"position"`
+	Enabled      bool           `json:"enabled"`
+	Metadata     map[string]any `json:"metadata,omitempty"`
+}
+
+// Backend 是被测向量库后端的管理与数据仓储。
+type Backend struct {
+	Management shared.VectorDBManagementRepository
+	Data       shared.VectorDBDataRepository[Payload]
+}
+
+type pointIDLister interface {
+	ListPointIDsByFilter(ctx context.Context, collection string, filter *shared.VectorFilter, limit int) ([]string, error)
+}
+
+type fixture struct {
+	backend    Backend
+	collection string
+	ids        map[string]string
+}
+
+// Run 执行全部一致性用例，newBackend 为每个子测试提供后端实例。
+func Run(t *testing.T, newBackend func(t *testing.T) Backend) {
+	t.Helper()
+
+	t.Run("collection lifecycle", func(t *testing.T) { testCollectionLifecycle(t, newBackend(t)) })
+	t.Run("aliases", func(t *testing.T) { testAliases(t, newBackend(t)) })
+	t.Run("dense search", func(t *testing.T) { testDenseSearch(t, newFixture(t, newBackend(t))) })
+	t.Run("payload filters", func(t *testing.T) { testPayloadFilters(t, newFixture(t, newBackend(t))) })
+	t.Run("sparse search", func(t *testing.T) { testSparseSearch(t, newFixture(t, newBackend(t))) })
+	t.Run("payload updates and deletes", func(t *testing.T) { testPayloadUpdatesAndDeletes(t, newFixture(t, newBackend(t))) })
+	t.Run("dimension mismatch", func(t *testing.T) { testDimensionMismatch(t, newFixture(t, newBackend(t))) })
+}
+
+func testCollectionLifecycle(t *testing.T, backend Backend) {
+	ctx := context.Background()
+	name := collectionName()
+	if err := backend.Management.CreateCollection(ctx, name, vectorSize); err != nil {
+		t.Fatalf("CreateCollection: %v", err)
+	}
+	t.Cleanup(func() { _ = backend.Management.DeleteCollection(context.Background(), name) })
+
+	exists, err := backend.Management.CollectionExists(ctx, name)
+	if err != nil || !exists {
+		t.Fatalf("expected collection to exist, exists=%v err=%v", exists, err)
+	}
+	collections, err := backend.Management.ListCollections(ctx)
+	if err != nil || !slices.Contains(collections, name) {
+		t.Fatalf("expected %s in collections %v, err=%v", name, collections, err)
+	}
+	info, err := backend.Management.GetCollectionInfo(ctx, name)
+	if err != nil {
+		t.Fatalf("GetCollectionInfo: %v", err)
+	}
+	if info.VectorSize != vectorSize || !info.HasNamedDenseVector || !info.HasSparseVector {
+		t.Fatalf("unexpected collection info: %+v", info)
+	}
+
+	specs := []shared.PayloadIndexSpec{
+		{FieldName: "document_code", Kind: shared.PayloadIndexKindKeyword},
+		{FieldName: "position", Kind: shared.PayloadIndexKindInteger},
+	}
+	if err := backend.Management.EnsurePayloadIndexes(ctx, name, specs); err != nil {
+		t.Fatalf("EnsurePayloadIndexes: %v", err)
+	}
+	info, err = backend.Management.GetCollectionInfo(ctx, name)
+	if err != nil {
+		t.Fatalf("GetCollectionInfo after indexes: %v", err)
+	}
+	for _, spec := range specs {
+		if !slices.Contains(info.PayloadSchemaKeys, spec.FieldName) {
+			t.Fatalf("expected payload schema key %s, got %v", spec.FieldName, info.PayloadSchemaKeys)
+		}
+	}
+
+	if err := backend.Management.DeleteCollection(ctx, name); err != nil {
+		t.Fatalf("DeleteCollection: %v", err)
+	}
+	exists, err = backend.Management.CollectionExists(ctx, name)
+	if err != nil || exists {
+		t.Fatalf("expected collection to be deleted, exists=%v err=%v", exists, err)
+	}
+	if _, err := backend.Management.GetCollectionInfo(ctx, name); !errors.Is(err, shared.ErrVectorCollectionNotFound) {
+		t.Fatalf("expected ErrVectorCollectionNotFound, got %v", err)
+	}
+	if err := backend.Management.DeletePoints(ctx, name, []string{uuid.NewString()}); err != nil {
+		t.Fatalf("DeletePoints on missing collection should succeed, got %v", err)
+	}
+	if err := backend.Management.DeletePointsByFilter(ctx, name, mustEqString("document_code", "DOC-A")); err != nil {
+		t.Fatalf("DeletePointsByFilter on missing collection should succeed, got %v", err)
+	}
+}
+
+func testAliases(t *testing.T, backend Backend) {
+	ctx := context.Background()
+	first, second, alias := collectionName(), collectionName(), collectionName()
+	for _, name := range []string{first, second} {
+		if err := backend.Management.CreateCollection(ctx, name, vectorSize); err != nil {
+			t.Fatalf("CreateCollection %s: %v", name, err)
+		}
+		t.Cleanup(func() { _ = backend.Management.DeleteCollection(context.Background(), name) })
+	}
+	t.Cleanup(func() { _ = backend.Management.DeleteAlias(context.Background(), alias) })
+
+	if _, ok, err := backend.Management.GetAliasTarget(ctx, alias); err != nil || ok {
+		t.Fatalf("expected missing alias, ok=%v err=%v", ok, err)
+	}
+	if err := backend.Management.EnsureAlias(ctx, alias, first); err != nil {
+		t.Fatalf("EnsureAlias: %v", err)
+	}
+	assertAliasTarget(t, backend, alias, first)
+	if err := backend.Management.EnsureAlias(ctx, alias, first); err != nil {
+		t.Fatalf("EnsureAlias should be idempotent: %v", err)
+	}
+
+	// 通过 alias 写入的数据落在当前指向的物理集合。
+	pointID := uuid.NewString()
+	if err := backend.Data.StorePoint(ctx, alias, pointID, []float64{1, 0, 0, 0}, Payload{Content: "alias"}); err != nil {
+		t.Fatalf("StorePoint via alias: %v", err)
+	}
+	eventually(t, "point visible in aliased collection", func() bool {
+		existing, err := backend.Data.ListExistingPointIDs(ctx, first, []string{pointID})
+		return err == nil && len(existing) == 1
+	})
+
+	if err := backend.Management.SwapAliasAtomically(ctx, alias, first, second); err != nil {
+		t.Fatalf("SwapAliasAtomically: %v", err)
+	}
+	assertAliasTarget(t, backend, alias, second)
+	info, err := backend.Management.GetCollectionInfo(ctx, alias)
+	if err != nil || info.VectorSize != vectorSize {
+		t.Fatalf("expected alias to resolve for collection info, info=%+v err=%v", info, err)
+	}
+	collections, err := backend.Management.ListCollections(ctx)
+	if err != nil || slices.Contains(collections, alias) {
+		t.Fatalf("aliases must not be listed as collections: %v err=%v", collections, err)
+	}
+
+	if err := backend.Management.DeleteAlias(ctx, alias); err != nil {
+		t.Fatalf("DeleteAlias: %v", err)
+	}
+	if _, ok, err := backend.Management.GetAliasTarget(ctx, alias); err != nil || ok {
+		t.Fatalf("expected alias to be deleted, ok=%v err=%v", ok, err)
+	}
+}
+
+func testDenseSearch(t *testing.T, f *fixture) {
+	ctx := context.Background()
+	results, err := f.backend.Data.SearchDenseWithFilter(ctx, shared.DenseSearchRequest{
+		Collection:     f.collection,
+		VectorName:     shared.DefaultDenseVectorName,
+		Vector:         []float64{1, 0, 0, 0},
+		TopK:           3,
+		ScoreThreshold: 0.5,
+	})
+	if err != nil {
+		t.Fatalf("SearchDenseWithFilter: %v", err)
+	}
+	if got := f.names(results); !slices.Equal(got, []string{"p1", "p2"}) {
+		t.Fatalf("expected [p1 p2] above threshold, got %v", got)
+	}
+	if math.Abs(results[0].Score-1) > scoreTolerance {
+		t.Fatalf("expected cosine score 1 for identical vector, got %v", results[0].Score)
+	}
+	if expected := 0.9 / math.Sqrt(0.82); math.Abs(results[1].Score-expected) > scoreTolerance {
+		t.Fatalf("expected cosine score %v, got %v", expected, results[1].Score)
+	}
+	if results[0].Content != "first" || results[0].Payload.DocumentCode != "DOC-A" {
+		t.Fatalf("unexpected payload decoding: %+v", results[0])
+	}
+	if results[0].Metadata["section"] != "intro" {
+		t.Fatalf("expected metadata from payload, got %+v", results[0].Metadata)
+	}
+
+	limited, err := f.backend.Data.Search(ctx, f.collection, []float64{1, 0, 0, 0}, 1, 0)
+	if err != nil || len(limited) != 1 || limited[0].ID != f.ids["p1"] {
+		t.Fatalf("expected topK=1 to return p1, got %v err=%v", f.names(limited), err)
+	}
+	if _, err := f.backend.Data.SearchDenseWithFilter(ctx, shared.DenseSearchRequest{
+		Collection: f.collection, Vector: []float64{1, 0, 0, 0}, TopK: -1,
+	}); err == nil {
+		t.Fatal("expected negative topK to be rejected")
+	}
+}
+
+func testPayloadFilters(t *testing.T, f *fixture) {
+	cases := []struct {
+		name     string
+		filter   *shared.VectorFilter
+		expected []string
+	}{
+		{name: "must eq string", filter: mustEqString("document_code", "DOC-A"), expected: []string{"p1", "p2"}},
+		{name: "must in strings on array", filter: &shared.VectorFilter{Must: []shared.FieldFilter{
+			{Key: "tags", Match: shared.Match{InStrings: []string{"beta"}}},
+		}}, expected: []string{"p1", "p2"}},
+		{name: "should", filter: &shared.VectorFilter{Should: []shared.FieldFilter{
+			{Key: "document_code", Match: shared.Match{EqString: new("DOC-B")}},
+			{Key: "position", Match: shared.Match{Range: &shared.Range{Gte: new(4.0)}}},
+		}}, expected: []string{"p3", "p4"}},
+		{name: "must not bool", filter: &shared.VectorFilter{MustNot: []shared.FieldFilter{
+			{Key: "enabled", Match: shared.Match{EqBool: new(false)}},
+		}}, expected: []string{"p1", "p3", "p4"}},
+		{name: "range", filter: &shared.VectorFilter{Must: []shared.FieldFilter{
+			{Key: "position", Match: shared.Match{Range: &shared.Range{Gt: new(1.0), Lte: new(3.0)}}},
+		}}, expected: []string{"p2", "p3"}},
+		{name: "eq float", filter: &shared.VectorFilter{Must: []shared.FieldFilter{
+			{Key: "position", Match: shared.Match{EqFloat: new(3.0)}},
+		}}, expected: []string{"p3"}},
+		{name: "in floats", filter: &shared.VectorFilter{Must: []shared.FieldFilter{
+			{Key: "position", Match: shared.Match{InFloats: []float64{1, 4}}},
+		}}, expected: []string{"p1", "p4"}},
+		{name: "nested key", filter: mustEqString("metadata.section", "intro"), expected: []string{"p1"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := f.filteredNames(t, tc.filter); !slices.Equal(got, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func testSparseSearch(t *testing.T, f *fixture) {
+	ctx := context.Background()
+	results, err := f.backend.Data.SearchSparseWithFilter(ctx, shared.SparseSearchRequest{
+		Collection: f.collection,
+		VectorName: shared.DefaultSparseVectorName,
+		Vector:     &shared.SparseVector{Indices: []uint32{1, 2}, Values: []float32{2, 1}},
+		TopK:       10,
+	})
+	if err != nil {
+		t.Fatalf("SearchSparseWithFilter: %v", err)
+	}
+	got := f.names(results)
+	if len(got) != 3 || got[0] != "p1" || slices.Contains(got, "p4") {
+		t.Fatalf("expected p1 first and p4 absent, got %v", got)
+	}
+	// N=4；词项 1 的 df=1，词项 2 的 df=3，按 Qdrant IDF modifier 计算。
+	idf := func(df float64) float64 { return math.Log((4-df+0.5)/(df+0.5) + 1) }
+	if expected := 2*idf(1) + idf(3); math.Abs(results[0].Score-expected) > scoreTolerance {
+		t.Fatalf("expected sparse score %v, got %v", expected, results[0].Score)
+	}
+
+	filtered, err := f.backend.Data.SearchSparseWithFilter(ctx, shared.SparseSearchRequest{
+		Collection: f.collection,
+		Vector:     &shared.SparseVector{Indices: []uint32{2}, Values: []float32{1}},
+		TopK:       10,
+		Filter:     mustEqString("document_code", "DOC-B"),
+	})
+	if err != nil {
+		t.Fatalf("SearchSparseWithFilter with filter: %v", err)
+	}
+	if got := f.names(filtered); !slices.Equal(got, []string{"p3"}) {
+		t.Fatalf("expected filtered sparse result [p3], got %v", got)
+	}
+}
+
+func testPayloadUpdatesAndDeletes(t *testing.T, f *fixture) {
+	ctx := context.Background()
+	if err := f.backend.Data.SetPayloadByPointIDs(ctx, f.collection, map[string]map[string]any{
+		f.ids["p2"]: {"enabled": true},
+	}); err != nil {
+		t.Fatalf("SetPayloadByPointIDs: %v", err)
+	}
+	enabledFilter := &shared.VectorFilter{Must: []shared.FieldFilter{
+		{Key: "enabled", Match: shared.Match{EqBool: new(true)}},
+		{Key: "document_code", Match: shared.Match{EqString: new("DOC-A")}},
+	}}
+	f.eventuallyFiltered(t, enabledFilter, []string{"p1", "p2"})
+
+	missing := uuid.NewString()
+	existing, err := f.backend.Data.ListExistingPointIDs(ctx, f.collection, []string{f.ids["p1"], missing})
+	if err != nil {
+		t.Fatalf("ListExistingPointIDs: %v", err)
+	}
+	if _, ok := existing[f.ids["p1"]]; !ok || len(existing) != 1 {
+		t.Fatalf("expected only p1 to exist, got %v", existing)
+	}
+	if lister, ok := f.backend.Data.(pointIDLister); ok {
+		ids, err := lister.ListPointIDsByFilter(ctx, f.collection, mustEqString("document_code", "DOC-A"), 1)
+		if err != nil {
+			t.Fatalf("ListPointIDsByFilter: %v", err)
+		}
+		slices.Sort(ids)
+		expected := []string{f.ids["p1"], f.ids["p2"]}
+		slices.Sort(expected)
+		if !slices.Equal(ids, expected) {
+			t.Fatalf("expected paginated ids %v, got %v", expected, ids)
+		}
+	}
+
+	if err := f.backend.Management.DeletePoints(ctx, f.collection, []string{f.ids["p4"]}); err != nil {
+		t.Fatalf("DeletePoints: %v", err)
+	}
+	if err := f.backend.Management.DeletePointsByFilter(ctx, f.collection, mustEqString("document_code", "DOC-B")); err != nil {
+		t.Fatalf("DeletePointsByFilter: %v", err)
+	}
+	f.eventuallyFiltered(t, nil, []string{"p1", "p2"})
+}
+
+func testDimensionMismatch(t *testing.T, f *fixture) {
+	err := f.backend.Data.StorePoint(context.Background(), f.collection, uuid.NewString(), []float64{1, 0}, Payload{})
+	var mismatch *shared.VectorDimensionMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Expected != vectorSize || mismatch.Actual != 2 {
+		t.Fatalf("expected VectorDimensionMismatchError, got %v", err)
+	}
+}
+
+// newFixture 创建集合并写入四个点：p1/p2 同属 DOC-A，p3 属 DOC-B，p4 属 DOC-C 且无 sparse 词项。
+func newFixture(t *testing.T, backend Backend) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	f := &fixture{backend: backend, collection: collectionName(), ids: map[string]string{}}
+	if err := backend.Management.CreateCollection(ctx, f.collection, vectorSize); err != nil {
+		t.Fatalf("CreateCollection: %v", err)
+	}
+	t.Cleanup(func() { _ = backend.Management.DeleteCollection(context.Background(), f.collection) })
+
+	names := []string{"p1", "p2", "p3", "p4"}
+	ids := make([]string, len(names))
+	for i, name := range names {
+		ids[i] = uuid.NewString()
+		f.ids[name] = ids[i]
+	}
+	dense := [][]float64{{1, 0, 0, 0}, {0.9, 0.1, 0, 0}, {0, 1, 0, 0}, {0, 0, 1, 0}}
+	sparse := []*shared.SparseInput{
+		{Vector: &shared.SparseVector{Indices: []uint32{1, 2}, Values: []float32{1, 1}}},
+		{Vector: &shared.SparseVector{Indices: []uint32{2}, Values: []float32{1}}},
+		{Vector: &shared.SparseVector{Indices: []uint32{2, 3}, Values: []float32{1, 1}}},
+		nil,
+	}
+	payloads := []Payload{
+		{Content: "first", DocumentCode: "DOC-A", Tags: []string{"alpha", "beta"}, Position: 1, Enabled: true, Metadata: map[string]any{"section": "intro"}},
+		{Content: "second", DocumentCode: "DOC-A", Tags: []string{"beta"}, Position: 2, Enabled: false},
+		{Content: "third", DocumentCode: "DOC-B", Tags: []string{"gamma"}, Position: 3, Enabled: true},
+		{Content: "fourth", DocumentCode: "DOC-C", Tags: []string{}, Position: 4, Enabled: true},
+	}
+	if err := backend.Data.StoreHybridPoints(ctx, f.collection, ids, dense, sparse, payloads); err != nil {
+		t.Fatalf("StoreHybridPoints: %v", err)
+	}
+	f.eventuallyFiltered(t, nil, names)
+	return f
+}
+
+// filteredNames 以与所有点夹角相近的查询向量检索，返回命中点的名称（升序）。
+func (f *fixture) filteredNames(t *testing.T, filter *shared.VectorFilter) []string {
+	t.Helper()
+	results, err := f.backend.Data.SearchWithFilter(context.Background(), f.collection, []float64{1, 1, 1, 0}, len(f.ids), 0, filter)
+	if err != nil {
+		t.Fatalf("SearchWithFilter: %v", err)
+	}
+	names := f.names(results)
+	slices.Sort(names)
+	return names
+}
+
+func (f *fixture) eventuallyFiltered(t *testing.T, filter *shared.VectorFilter, expected []string) {
+	t.Helper()
+	var got []string
+	eventually(t, "filtered points "+strings.Join(expected, ","), func() bool {
+		got = f.filteredNames(t, filter)
+		return slices.Equal(got, expected)
+	})
+}
+
+func (f *fixture) names(results []*shared.VectorSearchResult[Payload]) []string {
+	byID := make(map[string]string, len(f.ids))
+	for name, id := range f.ids {
+		byID[id] = name
+	}
+	names := make([]string, 0, len(results))
+	for _, result := range results {
+		names = append(names, byID[result.ID])
+	}
+	return names
+}
+
+func assertAliasTarget(t *testing.T, backend Backend, alias, expected string) {
+	t.Helper()
+	target, ok, err := backend.Management.GetAliasTarget(context.Background(), alias)
+	if err != nil || !ok || target != expected {
+		t.Fatalf("expected alias %s -> %s, got target=%s ok=%v err=%v", alias, expected, target, ok, err)
+	}
+}
+
+// eventually 轮询条件直至成立，兼容写入异步生效的后端。
+func eventually(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(eventualTimeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(eventualTick)
+	}
+}
+
+func mustEqString(key, value string) *shared.VectorFilter {
+	return &shared.VectorFilter{Must: []shared.FieldFilter{{Key: key, Match: shared.Match{EqString: &value}}}}
+}
+
+func collectionName() string {
+	return "conformance_" + strings.ReplaceAll(uuid.NewString(), "-", "")[:16]
+}
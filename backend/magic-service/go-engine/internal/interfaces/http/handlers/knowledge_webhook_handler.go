@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	kbapp "magic/internal/application/knowledge/knowledgebase/service"
+)
+
+// 单次推送请求体上限：事件数上限乘单条内联正文上限，再留出 JSON 包装余量。
+const maxKnowledgeWebhookBodyBytes = 16 << 20
+
+// KnowledgeWebhookService 定义 HTTP 层需要的知识库 webhook 推送来源能力。
+type KnowledgeWebhookService interface {
+	IngestWebhookEvents(ctx context.Context, input kbapp.WebhookIngestInput) ([]kbapp.WebhookEventResult, error)
+}
+
+// KnowledgeWebhookHandler 处理外部系统向知识库推送条目变更的 HTTP 请求。
+type KnowledgeWebhookHandler struct {
+	service KnowledgeWebhookService
+}
+
+// NewKnowledgeWebhookHandler 创建知识库 webhook 推送 Handler。
+func NewKnowledgeWebhookHandler(service KnowledgeWebhookService) *KnowledgeWebhookHandler {
+	return &KnowledgeWebhookHandler{service: service}
+}
+
+type knowledgeWebhookEventResult struct {
+	ExternalID   string `json:"external_id"`
+	Action       string `json:"action"`
+	Status       string `json:"status"`
+	DocumentCode string `json:"document_code,omitempty"`
+}
+
+type knowledgeWebhookResponse struct {
+	Events []knowledgeWebhookEventResult `json:"events"`
+}
+
+// HandleEvents 处理 POST /api/v1/knowledge-bases/:knowledgeBaseCode/webhook-sources/:channel/events。
+//
+// 请求不走用户鉴权，只依赖通道签名；签名覆盖原始请求体，因此必须在解析 JSON 之前读取原文。
+func (h *KnowledgeWebhookHandler) HandleEvents(c *gin.Context) {
+	if h == nil || h.service == nil {
+		writeError(c, http.StatusServiceUnavailable, "knowledge webhook service unavailable")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxKnowledgeWebhookBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(c, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		writeError(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	results, err := h.service.IngestWebhookEvents(c.Request.Context(), kbapp.WebhookIngestInput{
+		KnowledgeBaseCode: c.Param("knowledgeBaseCode"),
+		Channel:           c.Param("channel"),
+		Timestamp:         c.GetHeader(kbapp.WebhookTimestampHeader),
+		Signature:         c.GetHeader(kbapp.WebhookSignatureHeader),
+		Body:              body,
+	})
+	if err != nil {
+		status, message := statusFromKnowledgeWebhookError(err)
+		writeError(c, status, message)
+		return
+	}
+
+	response := knowledgeWebhookResponse{Events: make([]knowledgeWebhookEventResult, 0, len(results))}
+	for _, result := range results {
+		response.Events = append(response.Events, knowledgeWebhookEventResult{
+			ExternalID:   result.ExternalID,
+			Action:       result.Action,
+			Status:       result.Status,
+			DocumentCode: result.DocumentCode,
+		})
+	}
+	writeSuccess(c, response)
+}
+
+func statusFromKnowledgeWebhookError(err error) (int, string) {
+	switch {
+	case errors.Is(err, kbapp.ErrWebhookSignatureInvalid):
+		return http.StatusUnauthorized, "invalid webhook signature"
+	case errors.Is(err, kbapp.ErrWebhookTimestampExpired):
+		return http.StatusUnauthorized, "webhook timestamp expired"
+	case errors.Is(err, kbapp.ErrInvalidWebhookEvent):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, kbapp.ErrWebhookSourceNotFound), errors.Is(err, kbapp.ErrInvalidWebhookSourceConfig):
+		return http.StatusNotFound, "webhook source not found"
+	case errors.Is(err, kbapp.ErrWebhookSourceDisabled):
+		return http.StatusForbidden, "webhook source disabled"
+	case errors.Is(err, kbapp.ErrWebhookSourceBusy):
+		return http.StatusConflict, "webhook source is busy, retry later"
+	default:
+		return http.StatusInternalServerError, "internal server error"
+	}
+}
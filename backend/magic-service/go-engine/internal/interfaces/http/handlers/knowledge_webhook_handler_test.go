@@ -0,0 +1,107 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	kbapp "magic/internal/application/knowledge/knowledgebase/service"
+	"magic/internal/interfaces/http/handlers"
+)
+
+type knowledgeWebhookServiceStub struct {
+	results []kbapp.WebhookEventResult
+	err     error
+	input   kbapp.WebhookIngestInput
+}
+
+func (s *knowledgeWebhookServiceStub) IngestWebhookEvents(
+	_ context.Context,
+	input kbapp.WebhookIngestInput,
+) ([]kbapp.WebhookEventResult, error) {
+	s.input = input
+	return s.results, s.err
+}
+
+func TestKnowledgeWebhookHandlerPassesRawBodyAndHeaders(t *testing.T) {
+	t.Parallel()
+
+	service := &knowledgeWebhookServiceStub{results: []kbapp.WebhookEventResult{
+		{ExternalID: "a-1", Action: "upsert", Status: kbapp.WebhookEventStatusCreated, DocumentCode: "DOC1"},
+	}}
+	body := `{"events":[{"action":"upsert","external_id":"a-1","content":"# hi"}]}`
+	recorder := performKnowledgeWebhookRequest(t, service, body)
+
+	assertHTTPStatus(t, recorder, http.StatusOK)
+	if string(service.input.Body) != body || service.input.KnowledgeBaseCode != "KB1" || service.input.Channel != "cms" ||
+		service.input.Timestamp != "1780000000" || service.input.Signature != "sha256=abc" {
+		t.Fatalf("unexpected ingest input: %#v", service.input)
+	}
+	var response struct {
+		Code int `json:"code"`
+		Data struct {
+			Events []map[string]any `json:"events"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.Code != 1000 || len(response.Data.Events) != 1 ||
+		response.Data.Events[0]["status"] != "created" || response.Data.Events[0]["document_code"] != "DOC1" {
+		t.Fatalf("unexpected response: %s", recorder.Body.String())
+	}
+}
+
+func TestKnowledgeWebhookHandlerErrorMapping(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{kbapp.ErrWebhookSignatureInvalid, http.StatusUnauthorized},
+		{kbapp.ErrWebhookTimestampExpired, http.StatusUnauthorized},
+		{fmt.Errorf("parse: %w", kbapp.ErrInvalidWebhookEvent), http.StatusBadRequest},
+		{kbapp.ErrWebhookSourceNotFound, http.StatusNotFound},
+		{fmt.Errorf("parse: %w", kbapp.ErrInvalidWebhookSourceConfig), http.StatusNotFound},
+		{kbapp.ErrWebhookSourceDisabled, http.StatusForbidden},
+		{kbapp.ErrWebhookSourceBusy, http.StatusConflict},
+		{errKnowledgeSourceFileHandlerBoom, http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		recorder := performKnowledgeWebhookRequest(t, &knowledgeWebhookServiceStub{err: tc.err}, `{"events":[]}`)
+		assertHTTPStatus(t, recorder, tc.status)
+	}
+}
+
+func performKnowledgeWebhookRequest(
+	t *testing.T,
+	service *knowledgeWebhookServiceStub,
+	body string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewKnowledgeWebhookHandler(service)
+	engine := gin.New()
+	engine.POST("/api/v1/knowledge-bases/:knowledgeBaseCode/webhook-sources/:channel/events", handler.HandleEvents)
+
+	request := httptest.NewRequestWithContext(
+		t.Context(),
+		http.MethodPost,
+		"/api/v1/knowledge-bases/KB1/webhook-sources/cms/events",
+		bytes.NewBufferString(body),
+	)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(kbapp.WebhookTimestampHeader, "1780000000")
+	request.Header.Set(kbapp.WebhookSignatureHeader, "sha256=abc")
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, request)
+	return recorder
+}
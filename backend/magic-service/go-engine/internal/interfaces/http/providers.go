@@ -2,6 +2,7 @@ package httpapi
 
 import (
 	embeddingapp "magic/internal/application/knowledge/embedding/service"
+	knowledgebaseapp "magic/internal/application/knowledge/knowledgebase/service"
 	"magic/internal/infrastructure/logging"
 	"magic/internal/interfaces/http/handlers"
 	rpchandler "magic/internal/interfaces/rpc/jsonrpc/knowledge/service"
@@ -15,13 +16,17 @@ type ServerRuntimeDeps struct {
 	debugHandler               *handlers.DebugHandler
 	magicFSFileHandler         *handlers.MagicFSFileHandler
 	knowledgeSourceFileHandler *handlers.KnowledgeSourceFileHandler
+	knowledgeWebhookHandler    *handlers.KnowledgeWebhookHandler
 }
 
 // ServerBackgroundDeps 聚合 HTTP 服务所需的后台任务依赖。
 type ServerBackgroundDeps struct {
-	cacheCleanupService *embeddingapp.EmbeddingCacheCleanupService
-	taskQueueService    TaskQueueService
-	retrievalWarmup     RetrievalWarmupService
+	cacheCleanupService   *embeddingapp.EmbeddingCacheCleanupService
+	websiteRecrawlService *knowledgebaseapp.WebsiteRecrawlService
+	gitSourceSyncService  *knowledgebaseapp.GitSourceSyncService
+	objectStorageSync     *knowledgebaseapp.ObjectStorageSyncService
+	taskQueueService      TaskQueueService
+	retrievalWarmup       RetrievalWarmupService
 }
 
 // ProvideRPCHandlers 显式构造 RPCHandlers，避免 Wire 通过 struct 字段猜测依赖。
@@ -31,13 +36,19 @@ func ProvideRPCHandlers(
 	document *rpchandler.DocumentRPCService,
 	embedding *rpchandler.EmbeddingRPCService,
 	ops *opshandler.OpsRPCService,
+	evaluation *rpchandler.EvaluationRPCService,
+	queryLog *rpchandler.QueryLogRPCService,
+	feedback *rpchandler.FeedbackRPCService,
 ) RPCHandlers {
 	return RPCHandlers{
-		Knowledge: knowledge,
-		Fragment:  fragment,
-		Document:  document,
-		Embedding: embedding,
-		Ops:       ops,
+		Knowledge:  knowledge,
+		Fragment:   fragment,
+		Document:   document,
+		Embedding:  embedding,
+		Ops:        ops,
+		Evaluation: evaluation,
+		QueryLog:   queryLog,
+		Feedback:   feedback,
 	}
 }
 
@@ -48,6 +59,7 @@ func ProvideServerRuntimeDeps(
 	debugHandler *handlers.DebugHandler,
 	magicFSFileHandler *handlers.MagicFSFileHandler,
 	knowledgeSourceFileHandler *handlers.KnowledgeSourceFileHandler,
+	knowledgeWebhookHandler *handlers.KnowledgeWebhookHandler,
 ) ServerRuntimeDeps {
 	return ServerRuntimeDeps{
 		rpcServer:                  rpcServer,
@@ -55,19 +67,26 @@ func ProvideServerRuntimeDeps(
 		debugHandler:               debugHandler,
 		magicFSFileHandler:         magicFSFileHandler,
 		knowledgeSourceFileHandler: knowledgeSourceFileHandler,
+		knowledgeWebhookHandler:    knowledgeWebhookHandler,
 	}
 }
 
 // ProvideServerBackgroundDeps 聚合后台服务依赖，避免构造函数参数过多。
 func ProvideServerBackgroundDeps(
 	cacheCleanupService *embeddingapp.EmbeddingCacheCleanupService,
+	websiteRecrawlService *knowledgebaseapp.WebsiteRecrawlService,
+	gitSourceSyncService *knowledgebaseapp.GitSourceSyncService,
+	objectStorageSync *knowledgebaseapp.ObjectStorageSyncService,
 	retrievalWarmup RetrievalWarmupService,
 	taskQueueService TaskQueueService,
 ) ServerBackgroundDeps {
 	return ServerBackgroundDeps{
-		cacheCleanupService: cacheCleanupService,
-		taskQueueService:    taskQueueService,
-		retrievalWarmup:     retrievalWarmup,
+		cacheCleanupService:   cacheCleanupService,
+		websiteRecrawlService: websiteRecrawlService,
+		gitSourceSyncService:  gitSourceSyncService,
+		objectStorageSync:     objectStorageSync,
+		taskQueueService:      taskQueueService,
+		retrievalWarmup:       retrievalWarmup,
 	}
 }
 
@@ -83,6 +102,9 @@ func ProvideServerDependencies(
 	return &ServerDependencies{
 		Config:                     config,
 		CacheCleanupService:        backgroundDeps.cacheCleanupService,
+		WebsiteRecrawlService:      backgroundDeps.websiteRecrawlService,
+		GitSourceSyncService:       backgroundDeps.gitSourceSyncService,
+		ObjectStorageSyncService:   backgroundDeps.objectStorageSync,
 		TaskQueueService:           backgroundDeps.taskQueueService,
 		RetrievalWarmup:            backgroundDeps.retrievalWarmup,
 		InfraServices:              infraServices,
@@ -93,5 +115,6 @@ func ProvideServerDependencies(
 		DebugHandler:               runtimeDeps.debugHandler,
 		MagicFSFileHandler:         runtimeDeps.magicFSFileHandler,
 		KnowledgeSourceFileHandler: runtimeDeps.knowledgeSourceFileHandler,
+		KnowledgeWebhookHandler:    runtimeDeps.knowledgeWebhookHandler,
 	}
 }
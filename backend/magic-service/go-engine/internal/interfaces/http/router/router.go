@@ -19,6 +19,7 @@ type Dependencies struct {
 	HelloHandler               HelloRouteHandler
 	MagicFSHandler             MagicFSFileRouteHandler
 	KnowledgeSourceFileHandler KnowledgeSourceFileRouteHandler
+	KnowledgeWebhookHandler    KnowledgeWebhookRouteHandler
 }
 
 // HealthRouteHandler 定义健康检查路由处理器。
@@ -51,6 +52,11 @@ type KnowledgeSourceFileRouteHandler interface {
 	SourceFileLink(*gin.Context)
 }
 
+// KnowledgeWebhookRouteHandler 定义知识库 webhook 推送来源路由处理器。
+type KnowledgeWebhookRouteHandler interface {
+	HandleEvents(*gin.Context)
+}
+
 // SetupRoutes 注册应用的全部路由
 func SetupRoutes(deps Dependencies) {
 	// 根路由
@@ -76,6 +82,12 @@ func SetupRoutes(deps Dependencies) {
 			deps.KnowledgeSourceFileHandler.SourceFileLink,
 		)
 	}
+	if deps.KnowledgeWebhookHandler != nil {
+		api.POST(
+			"/knowledge-bases/:knowledgeBaseCode/webhook-sources/:channel/events",
+			deps.KnowledgeWebhookHandler.HandleEvents,
+		)
+	}
 
 	// 未来模块占位
 	_ = api.Group("/memory")
@@ -74,6 +74,21 @@ type CacheCleanupService interface {
 	StartCleanupDaemon(ctx context.Context) error
 }
 
+// WebsiteRecrawlService 定义知识库网站来源定时重爬后台服务能力。
+type WebsiteRecrawlService interface {
+	StartRecrawlDaemon(ctx context.Context) error
+}
+
+// GitSourceSyncService 定义知识库 Git 来源定时增量同步后台服务能力。
+type GitSourceSyncService interface {
+	StartSyncDaemon(ctx context.Context) error
+}
+
+// ObjectStorageSyncService 定义知识库对象存储来源定时轮询同步后台服务能力。
+type ObjectStorageSyncService interface {
+	StartSyncDaemon(ctx context.Context) error
+}
+
 // TaskQueueService 定义后台任务队列消费能力。
 type TaskQueueService interface {
 	Start(ctx context.Context) error
@@ -89,6 +104,9 @@ type TaskQueueLifecycleService interface {
 type ServerDependencies struct {
 	Config                     *ServerConfig
 	CacheCleanupService        CacheCleanupService
+	WebsiteRecrawlService      WebsiteRecrawlService
+	GitSourceSyncService       GitSourceSyncService
+	ObjectStorageSyncService   ObjectStorageSyncService
 	TaskQueueService           TaskQueueService
 	RetrievalWarmup            RetrievalWarmupService
 	InfraServices              InfraServices
@@ -99,15 +117,19 @@ type ServerDependencies struct {
 	DebugHandler               *handlers.DebugHandler
 	MagicFSFileHandler         *handlers.MagicFSFileHandler
 	KnowledgeSourceFileHandler *handlers.KnowledgeSourceFileHandler
+	KnowledgeWebhookHandler    *handlers.KnowledgeWebhookHandler
 }
 
 // RPCHandlers RPC 处理器集合
 type RPCHandlers struct {
-	Knowledge rpcRoutes.HandlerProvider
-	Fragment  rpcRoutes.HandlerProvider
-	Document  rpcRoutes.HandlerProvider
-	Embedding rpcRoutes.HandlerProvider
-	Ops       rpcRoutes.HandlerProvider
+	Knowledge  rpcRoutes.HandlerProvider
+	Fragment   rpcRoutes.HandlerProvider
+	Document   rpcRoutes.HandlerProvider
+	Embedding  rpcRoutes.HandlerProvider
+	Ops        rpcRoutes.HandlerProvider
+	Evaluation rpcRoutes.HandlerProvider
+	QueryLog   rpcRoutes.HandlerProvider
+	Feedback   rpcRoutes.HandlerProvider
 }
 
 // InfraServices 定义 Server 依赖的基础设施服务能力，避免直接依赖基础设施层具体实现
@@ -130,11 +152,14 @@ type Server struct {
 	rpcHandlers   RPCHandlers
 
 	// 后台服务
-	cacheCleanupService CacheCleanupService
-	taskQueueService    TaskQueueService
-	retrievalWarmup     RetrievalWarmupService
-	backgroundCancel    context.CancelFunc
-	backgroundWG        sync.WaitGroup
+	cacheCleanupService   CacheCleanupService
+	websiteRecrawlService WebsiteRecrawlService
+	gitSourceSyncService  GitSourceSyncService
+	objectStorageSync     ObjectStorageSyncService
+	taskQueueService      TaskQueueService
+	retrievalWarmup       RetrievalWarmupService
+	backgroundCancel      context.CancelFunc
+	backgroundWG          sync.WaitGroup
 
 	// 处理器
 	healthHandler          *handlers.HealthHandler
@@ -143,6 +168,7 @@ type Server struct {
 	helloHandler           *handlers.HelloHandler
 	magicFSHandler         *handlers.MagicFSFileHandler
 	knowledgeSourceHandler *handlers.KnowledgeSourceFileHandler
+	knowledgeWebhook       *handlers.KnowledgeWebhookHandler
 
 	// 日志
 	logger *logging.SugaredLogger
@@ -161,6 +187,9 @@ func NewServerWithDependencies(deps *ServerDependencies) *Server {
 		config:                 deps.Config,
 		infraServices:          deps.InfraServices,
 		cacheCleanupService:    deps.CacheCleanupService,
+		websiteRecrawlService:  deps.WebsiteRecrawlService,
+		gitSourceSyncService:   deps.GitSourceSyncService,
+		objectStorageSync:      deps.ObjectStorageSyncService,
 		taskQueueService:       deps.TaskQueueService,
 		retrievalWarmup:        deps.RetrievalWarmup,
 		logger:                 deps.Logger,
@@ -173,6 +202,7 @@ func NewServerWithDependencies(deps *ServerDependencies) *Server {
 		helloHandler:           handlers.NewHelloHandler(),
 		magicFSHandler:         deps.MagicFSFileHandler,
 		knowledgeSourceHandler: deps.KnowledgeSourceFileHandler,
+		knowledgeWebhook:       deps.KnowledgeWebhookHandler,
 		stopCh:                 make(chan struct{}),
 	}
 }
@@ -230,12 +260,15 @@ func (s *Server) setupRPCRoutes() {
 	}
 
 	rpcRoutes.SetupRPCRoutes(rpcRoutes.Dependencies{
-		Server:           s.rpcServer,
-		KnowledgeHandler: s.rpcHandlers.Knowledge,
-		FragmentHandler:  s.rpcHandlers.Fragment,
-		DocumentHandler:  s.rpcHandlers.Document,
-		EmbeddingHandler: s.rpcHandlers.Embedding,
-		OpsHandler:       s.rpcHandlers.Ops,
+		Server:            s.rpcServer,
+		KnowledgeHandler:  s.rpcHandlers.Knowledge,
+		FragmentHandler:   s.rpcHandlers.Fragment,
+		DocumentHandler:   s.rpcHandlers.Document,
+		EmbeddingHandler:  s.rpcHandlers.Embedding,
+		OpsHandler:        s.rpcHandlers.Ops,
+		EvaluationHandler: s.rpcHandlers.Evaluation,
+		QueryLogHandler:   s.rpcHandlers.QueryLog,
+		FeedbackHandler:   s.rpcHandlers.Feedback,
 	})
 }
 
@@ -276,6 +309,7 @@ func (s *Server) setupRoutes() {
 		HelloHandler:               s.helloHandler,
 		MagicFSHandler:             s.magicFSHandler,
 		KnowledgeSourceFileHandler: s.knowledgeSourceHandler,
+		KnowledgeWebhookHandler:    s.knowledgeWebhook,
 	})
 
 	// 静态文件服务（如需）
@@ -373,7 +407,7 @@ func (s *Server) closeInfraServices(ctx context.Context) {
 }
 
 func (s *Server) startBackgroundServices(ctx context.Context) {
-	if s.cacheCleanupService == nil && s.retrievalWarmup == nil && s.taskQueueService == nil {
+	if s.cacheCleanupService == nil && s.websiteRecrawlService == nil && s.retrievalWarmup == nil && s.taskQueueService == nil {
 		return
 	}
 
@@ -388,6 +422,30 @@ func (s *Server) startBackgroundServices(ctx context.Context) {
 			}
 		})
 	}
+	if s.websiteRecrawlService != nil {
+		s.backgroundWG.Go(func() {
+			defer runguard.Recover(backgroundCtx, s.backgroundPanicOptions("http.background.website_recrawl", runguard.Continue))
+			if err := s.websiteRecrawlService.StartRecrawlDaemon(backgroundCtx); err != nil {
+				s.logger.KnowledgeErrorContext(backgroundCtx, "Website recrawl daemon failed", "error", err)
+			}
+		})
+	}
+	if s.gitSourceSyncService != nil {
+		s.backgroundWG.Go(func() {
+			defer runguard.Recover(backgroundCtx, s.backgroundPanicOptions("http.background.git_source_sync", runguard.Continue))
+			if err := s.gitSourceSyncService.StartSyncDaemon(backgroundCtx); err != nil {
+				s.logger.KnowledgeErrorContext(backgroundCtx, "Git source sync daemon failed", "error", err)
+			}
+		})
+	}
+	if s.objectStorageSync != nil {
+		s.backgroundWG.Go(func() {
+			defer runguard.Recover(backgroundCtx, s.backgroundPanicOptions("http.background.object_storage_sync", runguard.Continue))
+			if err := s.objectStorageSync.StartSyncDaemon(backgroundCtx); err != nil {
+				s.logger.KnowledgeErrorContext(backgroundCtx, "Object storage sync daemon failed", "error", err)
+			}
+		})
+	}
 	if s.retrievalWarmup != nil {
 		s.backgroundWG.Go(func() {
 			defer runguard.Recover(backgroundCtx, s.backgroundPanicOptions("http.background.retrieval_warmup", runguard.Continue))
@@ -584,6 +584,45 @@ func TestSimilarityFiltersCompatAcceptsStringNumericFields(t *testing.T) {
 	}
 }
 
+func TestSimilarityRequestsDecodeContextExpansion(t *testing.T) {
+	t.Parallel()
+
+	var req dto.SimilarityRequest
+	err := json.Unmarshal([]byte(`{
+		"knowledge_code":"KB-1",
+		"query":"hello",
+		"context_expansion":{"mode":"neighbor","neighbor_window":"2","max_tokens":"1500"}
+	}`), &req)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if req.ContextExpansion == nil || req.ContextExpansion.Mode != "neighbor" ||
+		req.ContextExpansion.NeighborWindow != 2 || req.ContextExpansion.MaxTokens != 1500 {
+		t.Fatalf("unexpected context_expansion %#v", req.ContextExpansion)
+	}
+
+	var runtimeReq dto.RuntimeSimilarityRequest
+	err = json.Unmarshal([]byte(`{
+		"knowledge_codes":["KB-1"],
+		"query":"hello",
+		"context_expansion":{"mode":"parent"}
+	}`), &runtimeReq)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if runtimeReq.ContextExpansion == nil || runtimeReq.ContextExpansion.Mode != "parent" {
+		t.Fatalf("unexpected runtime context_expansion %#v", runtimeReq.ContextExpansion)
+	}
+
+	var plainReq dto.SimilarityRequest
+	if err := json.Unmarshal([]byte(`{"knowledge_code":"KB-1","query":"hello","context_expansion":[]}`), &plainReq); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if plainReq.ContextExpansion != nil {
+		t.Fatalf("expected empty context_expansion to be ignored, got %#v", plainReq.ContextExpansion)
+	}
+}
+
 func TestNotifyProjectFileChangeRequestCompatAcceptsStringProjectFileID(t *testing.T) {
 	t.Parallel()
 
@@ -248,6 +248,33 @@ type AgentSimilaritySnippet struct {
 	FileKey   string  `json:"file_key,omitempty"`
 }
 
+// AgentContextAssemblyResponse 数字员工按 token 预算组装的上下文响应。
+type AgentContextAssemblyResponse struct {
+	QueryUsed      string                     `json:"query_used"`
+	Strategy       string                     `json:"strategy"`
+	Model          string                     `json:"model"`
+	MaxTokens      int                        `json:"max_tokens"`
+	TokenCount     int                        `json:"token_count"`
+	Context        string                     `json:"context"`
+	Citations      []*ContextCitationResponse `json:"citations"`
+	CandidateCount int                        `json:"candidate_count"`
+	DroppedCount   int                        `json:"dropped_count"`
+}
+
+// ContextCitationResponse 是上下文中 [index] 引用标记对应的来源片段。
+type ContextCitationResponse struct {
+	Index         int     `json:"index"`
+	CitationID    string  `json:"citation_id"`
+	FragmentID    int64   `json:"fragment_id"`
+	KnowledgeCode string  `json:"knowledge_code"`
+	DocumentCode  string  `json:"document_code"`
+	DocumentName  string  `json:"document_name"`
+	SectionPath   string  `json:"section_path,omitempty"`
+	FileKey       string  `json:"file_key,omitempty"`
+	Score         float64 `json:"score"`
+	TokenCount    int     `json:"token_count"`
+}
+
 func newOperatorInfoResponse(userID, datetime string) *OperatorInfoResponse {
 	if userID == "" {
 		return nil
@@ -1074,6 +1101,42 @@ func NewSimilarityPageResponse(results []*fragdto.SimilarityResultDTO) *Similari
 	}
 }
 
+// NewAgentContextAssemblyResponse 投影数字员工维度上下文组装结果。
+func NewAgentContextAssemblyResponse(result *fragdto.AgentContextAssemblyResultDTO) *AgentContextAssemblyResponse {
+	if result == nil {
+		return &AgentContextAssemblyResponse{Citations: []*ContextCitationResponse{}}
+	}
+	citations := make([]*ContextCitationResponse, 0, len(result.Citations))
+	for _, citation := range result.Citations {
+		if citation == nil {
+			continue
+		}
+		citations = append(citations, &ContextCitationResponse{
+			Index:         citation.Index,
+			CitationID:    citation.CitationID,
+			FragmentID:    citation.FragmentID,
+			KnowledgeCode: citation.KnowledgeCode,
+			DocumentCode:  citation.DocumentCode,
+			DocumentName:  citation.DocumentName,
+			SectionPath:   citation.SectionPath,
+			FileKey:       citation.FileKey,
+			Score:         citation.Score,
+			TokenCount:    citation.TokenCount,
+		})
+	}
+	return &AgentContextAssemblyResponse{
+		QueryUsed:      result.QueryUsed,
+		Strategy:       result.Strategy,
+		Model:          result.Model,
+		MaxTokens:      result.MaxTokens,
+		TokenCount:     result.TokenCount,
+		Context:        result.Context,
+		Citations:      citations,
+		CandidateCount: result.CandidateCount,
+		DroppedCount:   result.DroppedCount,
+	}
+}
+
 // NewAgentSimilarityResponse 投影数字员工维度知识检索结果。
 func NewAgentSimilarityResponse(result *fragdto.AgentSimilarityResultDTO) *AgentSimilarityResponse {
 	if result == nil {
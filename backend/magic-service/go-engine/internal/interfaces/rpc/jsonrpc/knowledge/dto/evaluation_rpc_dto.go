@@ -0,0 +1,103 @@
+package dto
+
+import evalapp "magic/internal/application/knowledge/evaluation"
+
+// 检索评测相关 DTO
+
+// GoldenQueryPayload 表示一条 golden query 请求体。
+type GoldenQueryPayload struct {
+	QueryKey              string   `json:"query_key"`
+	Query                 string   `json:"query"`
+	ExpectedDocumentCodes []string `json:"expected_document_codes"`
+	ExpectedFragmentIDs   []int64  `json:"expected_fragment_ids"`
+}
+
+// SaveEvaluationQuerySetRequest 全量保存评测集请求。
+type SaveEvaluationQuerySetRequest struct {
+	DataIsolation     DataIsolation        `json:"data_isolation"`
+	KnowledgeBaseCode string               `json:"knowledge_base_code"`
+	SetCode           string               `json:"set_code"`
+	Queries           []GoldenQueryPayload `json:"queries"`
+}
+
+// ListEvaluationQuerySetRequest 查询评测集请求。
+type ListEvaluationQuerySetRequest struct {
+	DataIsolation     DataIsolation `json:"data_isolation"`
+	KnowledgeBaseCode string        `json:"knowledge_base_code"`
+	SetCode           string        `json:"set_code"`
+}
+
+// RunEvaluationRequest 回放评测集请求。
+type RunEvaluationRequest struct {
+	DataIsolation     DataIsolation `json:"data_isolation"`
+	KnowledgeBaseCode string        `json:"knowledge_base_code"`
+	SetCode           string        `json:"set_code"`
+	Label             string        `json:"label"`
+	TopK              int           `json:"top_k"`
+	Cutoffs           []int         `json:"cutoffs"`
+	BaselineRunID     int64         `json:"baseline_run_id"`
+}
+
+// ListEvaluationRunsRequest 分页查询评测 run 请求。
+type ListEvaluationRunsRequest struct {
+	DataIsolation     DataIsolation `json:"data_isolation"`
+	KnowledgeBaseCode string        `json:"knowledge_base_code"`
+	SetCode           string        `json:"set_code"`
+	Limit             int           `json:"limit"`
+	Offset            int           `json:"offset"`
+}
+
+// CompareEvaluationRunsRequest 对比两次评测 run 请求。
+type CompareEvaluationRunsRequest struct {
+	DataIsolation DataIsolation `json:"data_isolation"`
+	BaseRunID     int64         `json:"base_run_id"`
+	TargetRunID   int64         `json:"target_run_id"`
+}
+
+// GoldenQueryResponse golden query 响应。
+type GoldenQueryResponse struct {
+	ID                    int64    `json:"id"`
+	KnowledgeBaseCode     string   `json:"knowledge_base_code"`
+	SetCode               string   `json:"set_code"`
+	QueryKey              string   `json:"query_key"`
+	Query                 string   `json:"query"`
+	ExpectedDocumentCodes []string `json:"expected_document_codes"`
+	ExpectedFragmentIDs   []int64  `json:"expected_fragment_ids"`
+	CreatedUID            string   `json:"created_uid"`
+	UpdatedUID            string   `json:"updated_uid"`
+	CreatedAt             string   `json:"created_at"`
+	UpdatedAt             string   `json:"updated_at"`
+}
+
+// EvaluationQuerySetResponse 评测集响应。
+type EvaluationQuerySetResponse struct {
+	KnowledgeBaseCode string                 `json:"knowledge_base_code"`
+	SetCode           string                 `json:"set_code"`
+	Queries           []*GoldenQueryResponse `json:"queries"`
+}
+
+// EvaluationRunResponse 评测 run 响应，列表接口不返回逐 query 结果。
+type EvaluationRunResponse struct {
+	ID                int64                 `json:"id"`
+	KnowledgeBaseCode string                `json:"knowledge_base_code"`
+	SetCode           string                `json:"set_code"`
+	Label             string                `json:"label"`
+	TopK              int                   `json:"top_k"`
+	BaselineRunID     int64                 `json:"baseline_run_id"`
+	Metrics           evalapp.Metrics       `json:"metrics"`
+	Results           []evalapp.QueryResult `json:"results,omitempty"`
+	CreatedUID        string                `json:"created_uid"`
+	StartedAt         string                `json:"started_at"`
+	FinishedAt        string                `json:"finished_at"`
+}
+
+// RunEvaluationResponse 回放评测集响应，不存在基线 run 时 diff 为空。
+type RunEvaluationResponse struct {
+	Run  *EvaluationRunResponse `json:"run"`
+	Diff *evalapp.RunDiff       `json:"diff"`
+}
+
+// ListEvaluationRunsResponse 评测 run 列表响应。
+type ListEvaluationRunsResponse struct {
+	List []*EvaluationRunResponse `json:"list"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+// 相关性反馈相关 DTO
+
+// FeedbackSubmitRequest 提交检索结果反馈请求。
+//
+// request_id 为产生该结果的相似度检索请求 ID；给出 fragment_id 时 document_code 可省略。
+// signal 取值：thumbs_up、thumbs_down、answered。
+type FeedbackSubmitRequest struct {
+	DataIsolation     DataIsolation `json:"data_isolation"`
+	KnowledgeBaseCode string        `json:"knowledge_base_code"`
+	DocumentCode      string        `json:"document_code"`
+	FragmentID        int64         `json:"fragment_id"`
+	RequestID         string        `json:"request_id"`
+	Signal            string        `json:"signal"`
+}
+
+// FeedbackSubmitResponse 提交反馈响应，重复提交时 recorded 为 false。
+type FeedbackSubmitResponse struct {
+	Recorded bool `json:"recorded"`
+}
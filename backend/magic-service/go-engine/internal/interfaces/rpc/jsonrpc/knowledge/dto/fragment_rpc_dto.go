@@ -320,6 +320,8 @@ type SimilarityRequest struct {
 	Debug          bool               `json:"debug,omitempty"`
 	BusinessParams BusinessParams     `json:"business_params"`
 	AcceptEncoding string             `json:"accept_encoding,omitempty"`
+	// ContextExpansion 可选，开启后为命中片段返回父章节或相邻片段上下文。
+	ContextExpansion *ContextExpansionRequest `json:"context_expansion,omitempty"`
 }
 
 // UnmarshalJSON 兼容 top_k/debug/score_threshold 传字符串。
@@ -371,17 +373,24 @@ func (r *SimilarityRequest) UnmarshalJSON(data []byte) error {
 			return fmt.Errorf("unmarshal business_params: %w", err)
 		}
 	}
+	var contextExpansion *ContextExpansionRequest
+	if field, ok := raw["context_expansion"]; ok && !pkgjsoncompat.IsEmptyObjectLikeJSON(field) {
+		if err := json.Unmarshal(field, &contextExpansion); err != nil {
+			return fmt.Errorf("unmarshal context_expansion: %w", err)
+		}
+	}
 
 	*r = SimilarityRequest{
-		DataIsolation:  dataIsolation,
-		KnowledgeCode:  knowledgeCode,
-		Query:          query,
-		TopK:           topK,
-		ScoreThreshold: scoreThreshold,
-		Filters:        filters,
-		Debug:          dereferenceBool(debug),
-		BusinessParams: businessParams,
-		AcceptEncoding: acceptEncoding,
+		DataIsolation:    dataIsolation,
+		KnowledgeCode:    knowledgeCode,
+		Query:            query,
+		TopK:             topK,
+		ScoreThreshold:   scoreThreshold,
+		Filters:          filters,
+		Debug:            dereferenceBool(debug),
+		BusinessParams:   businessParams,
+		AcceptEncoding:   acceptEncoding,
+		ContextExpansion: contextExpansion,
 	}
 	return nil
 }
@@ -397,6 +406,8 @@ type RuntimeSimilarityRequest struct {
 	MetadataFilter JSONObject     `json:"metadata_filter,omitempty"`
 	Debug          bool           `json:"debug,omitempty"`
 	BusinessParams BusinessParams `json:"business_params"`
+	// ContextExpansion 可选，开启后为命中片段返回父章节或相邻片段上下文。
+	ContextExpansion *ContextExpansionRequest `json:"context_expansion,omitempty"`
 }
 
 // UnmarshalJSON 兼容 top_k/debug/score_threshold 传字符串。
@@ -452,17 +463,24 @@ func (r *RuntimeSimilarityRequest) UnmarshalJSON(data []byte) error {
 			return fmt.Errorf("unmarshal business_params: %w", err)
 		}
 	}
+	var contextExpansion *ContextExpansionRequest
+	if field, ok := raw["context_expansion"]; ok && !pkgjsoncompat.IsEmptyObjectLikeJSON(field) {
+		if err := json.Unmarshal(field, &contextExpansion); err != nil {
+			return fmt.Errorf("unmarshal context_expansion: %w", err)
+		}
+	}
 
 	*r = RuntimeSimilarityRequest{
-		DataIsolation:  dataIsolation,
-		KnowledgeCodes: knowledgeCodes,
-		Query:          query,
-		Question:       question,
-		TopK:           topK,
-		ScoreThreshold: scoreThreshold,
-		MetadataFilter: metadataFilter,
-		Debug:          dereferenceBool(debug),
-		BusinessParams: businessParams,
+		DataIsolation:    dataIsolation,
+		KnowledgeCodes:   knowledgeCodes,
+		Query:            query,
+		Question:         question,
+		TopK:             topK,
+		ScoreThreshold:   scoreThreshold,
+		MetadataFilter:   metadataFilter,
+		Debug:            dereferenceBool(debug),
+		BusinessParams:   businessParams,
+		ContextExpansion: contextExpansion,
 	}
 	return nil
 }
@@ -535,6 +553,85 @@ type AgentSimilarityRequest struct {
 	Query         string        `json:"query" validate:"required"`
 }
 
+// AgentContextAssemblyRequest 数字员工维度按 token 预算组装上下文请求。
+type AgentContextAssemblyRequest struct {
+	DataIsolation DataIsolation `json:"data_isolation"`
+	AgentCode     string        `json:"agent_code" validate:"required"`
+	Query         string        `json:"query" validate:"required"`
+	// MaxTokens 为上下文块的 token 上限，Model 决定使用哪种分词器计数。
+	MaxTokens int    `json:"max_tokens"`
+	Model     string `json:"model,omitempty"`
+	// Strategy 取 greedy、diversity 或 mmr，为空或未知时按 greedy 处理。
+	Strategy       string   `json:"strategy,omitempty"`
+	MaxPerDocument int      `json:"max_per_document,omitempty"`
+	MMRLambda      *float64 `json:"mmr_lambda,omitempty"`
+	CandidateTopK  int      `json:"candidate_top_k,omitempty"`
+}
+
+// UnmarshalJSON 兼容 max_tokens/max_per_document/candidate_top_k/mmr_lambda 传字符串。
+func (r *AgentContextAssemblyRequest) UnmarshalJSON(data []byte) error {
+	raw, err := unmarshalRequestObject(data, "agent context assembly request")
+	if err != nil {
+		return err
+	}
+
+	var dataIsolation DataIsolation
+	if field, ok := raw["data_isolation"]; ok {
+		if err := json.Unmarshal(field, &dataIsolation); err != nil {
+			return fmt.Errorf("unmarshal data_isolation: %w", err)
+		}
+	}
+	agentCode, err := decodeRequestStringValue(raw, "agent_code")
+	if err != nil {
+		return err
+	}
+	query, err := decodeRequestStringValue(raw, "query")
+	if err != nil {
+		return err
+	}
+	maxTokens, err := decodeRequestIntValue(raw, "max_tokens")
+	if err != nil {
+		return err
+	}
+	model, err := decodeRequestStringValue(raw, "model")
+	if err != nil {
+		return err
+	}
+	strategy, err := decodeRequestStringValue(raw, "strategy")
+	if err != nil {
+		return err
+	}
+	maxPerDocument, err := decodeRequestIntValue(raw, "max_per_document")
+	if err != nil {
+		return err
+	}
+	candidateTopK, err := decodeRequestIntValue(raw, "candidate_top_k")
+	if err != nil {
+		return err
+	}
+	mmrLambdaValue, mmrLambdaProvided, err := decodeRequestFloat64(raw, "mmr_lambda")
+	if err != nil {
+		return err
+	}
+	var mmrLambda *float64
+	if mmrLambdaProvided {
+		mmrLambda = &mmrLambdaValue
+	}
+
+	*r = AgentContextAssemblyRequest{
+		DataIsolation:  dataIsolation,
+		AgentCode:      agentCode,
+		Query:          query,
+		MaxTokens:      maxTokens,
+		Model:          model,
+		Strategy:       strategy,
+		MaxPerDocument: maxPerDocument,
+		MMRLambda:      mmrLambda,
+		CandidateTopK:  candidateTopK,
+	}
+	return nil
+}
+
 // SimilarityFilters 相似度搜索可选过滤条件。
 type SimilarityFilters struct {
 	DocumentCodes []string             `json:"document_codes,omitempty"`
@@ -591,6 +688,40 @@ func (f *SimilarityFilters) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ContextExpansionRequest 检索命中的上下文扩展参数。
+type ContextExpansionRequest struct {
+	// Mode 取 parent（父章节）或 neighbor（前后相邻片段），为空时不扩展。
+	Mode           string `json:"mode"`
+	NeighborWindow int    `json:"neighbor_window,omitempty"`
+	MaxTokens      int    `json:"max_tokens,omitempty"`
+}
+
+// UnmarshalJSON 兼容 neighbor_window/max_tokens 传字符串。
+func (r *ContextExpansionRequest) UnmarshalJSON(data []byte) error {
+	raw, err := unmarshalRequestObject(data, "context expansion")
+	if err != nil {
+		return err
+	}
+	mode, err := decodeRequestStringValue(raw, "mode")
+	if err != nil {
+		return err
+	}
+	neighborWindow, err := decodeRequestIntValue(raw, "neighbor_window")
+	if err != nil {
+		return err
+	}
+	maxTokens, err := decodeRequestIntValue(raw, "max_tokens")
+	if err != nil {
+		return err
+	}
+	*r = ContextExpansionRequest{
+		Mode:           mode,
+		NeighborWindow: neighborWindow,
+		MaxTokens:      maxTokens,
+	}
+	return nil
+}
+
 // SimilarityTimeRange 时间范围过滤（Unix 秒）。
 type SimilarityTimeRange struct {
 	StartUnix int64 `json:"start_unix,omitempty"`
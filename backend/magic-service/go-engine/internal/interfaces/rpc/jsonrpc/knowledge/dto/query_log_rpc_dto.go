@@ -0,0 +1,69 @@
+package dto
+
+// 检索日志统计相关 DTO
+
+// QueryLogAnalyticsRequest 知识库检索统计请求，时间格式为 "2006-01-02 15:04:05"，缺省统计最近 7 天。
+type QueryLogAnalyticsRequest struct {
+	DataIsolation     DataIsolation `json:"data_isolation"`
+	KnowledgeBaseCode string        `json:"knowledge_base_code"`
+	StartTime         string        `json:"start_time"`
+	EndTime           string        `json:"end_time"`
+	Limit             int           `json:"limit"`
+}
+
+// QueryStatResponse 按 query 聚合的检索统计。
+type QueryStatResponse struct {
+	QueryHash       string  `json:"query_hash"`
+	Query           string  `json:"query"`
+	SearchCount     int64   `json:"search_count"`
+	ZeroResultCount int64   `json:"zero_result_count"`
+	AvgLatencyMS    float64 `json:"avg_latency_ms"`
+	LastSearchedAt  string  `json:"last_searched_at"`
+}
+
+// QueryStatListResponse query 统计列表响应。
+type QueryStatListResponse struct {
+	List []*QueryStatResponse `json:"list"`
+}
+
+// SlowQueryResponse 单次慢检索记录。
+type SlowQueryResponse struct {
+	ID          int64  `json:"id"`
+	Query       string `json:"query"`
+	QueryType   string `json:"query_type"`
+	Source      string `json:"source"`
+	ResultCount int    `json:"result_count"`
+	LatencyMS   int64  `json:"latency_ms"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// SlowQueryListResponse 慢检索列表响应。
+type SlowQueryListResponse struct {
+	List []*SlowQueryResponse `json:"list"`
+}
+
+// DocumentUsageResponse 文档命中统计。
+type DocumentUsageResponse struct {
+	DocumentCode    string  `json:"document_code"`
+	HitCount        int64   `json:"hit_count"`
+	QueryCount      int64   `json:"query_count"`
+	AvgScore        float64 `json:"avg_score"`
+	LastRetrievedAt string  `json:"last_retrieved_at"`
+}
+
+// DocumentUsageListResponse 文档命中统计列表响应。
+type DocumentUsageListResponse struct {
+	List []*DocumentUsageResponse `json:"list"`
+}
+
+// UnusedDocumentResponse 统计窗口内未被命中的文档。
+type UnusedDocumentResponse struct {
+	DocumentCode string `json:"document_code"`
+	DocumentName string `json:"document_name"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// UnusedDocumentListResponse 未命中文档列表响应。
+type UnusedDocumentListResponse struct {
+	List []*UnusedDocumentResponse `json:"list"`
+}
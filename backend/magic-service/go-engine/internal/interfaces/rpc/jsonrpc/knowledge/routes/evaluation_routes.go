@@ -0,0 +1,18 @@
+package routes
+
+import "magic/internal/constants"
+
+// RegisterEvaluationRoutes 注册知识库检索评测 RPC 路由。
+func RegisterEvaluationRoutes(router RPCRouter, h HandlerProvider) {
+	if router == nil || h == nil {
+		return
+	}
+
+	registerHandlers(router, h, []string{
+		constants.MethodEvaluationSaveQuerySet,
+		constants.MethodEvaluationListQuerySet,
+		constants.MethodEvaluationRun,
+		constants.MethodEvaluationListRuns,
+		constants.MethodEvaluationCompareRuns,
+	})
+}
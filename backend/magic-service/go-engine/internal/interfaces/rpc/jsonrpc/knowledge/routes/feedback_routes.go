@@ -0,0 +1,14 @@
+package routes
+
+import "magic/internal/constants"
+
+// RegisterFeedbackRoutes 注册检索结果相关性反馈 RPC 路由。
+func RegisterFeedbackRoutes(router RPCRouter, h HandlerProvider) {
+	if router == nil || h == nil {
+		return
+	}
+
+	registerHandlers(router, h, []string{
+		constants.MethodFeedbackSubmit,
+	})
+}
@@ -25,6 +25,7 @@ func RegisterFragmentRoutes(router RPCRouter, h HandlerProvider) {
 		constants.MethodFragmentRuntimeSimilarity,
 		constants.MethodFragmentFlowVectorSimilarityByUser,
 		constants.MethodFragmentSimilarityByAgent,
+		constants.MethodFragmentAssembleContextByAgent,
 		constants.MethodFragmentPreview,
 		constants.MethodFragmentPreviewHTTP,
 	})
@@ -0,0 +1,18 @@
+package routes
+
+import "magic/internal/constants"
+
+// RegisterQueryLogRoutes 注册知识库检索日志统计 RPC 路由。
+func RegisterQueryLogRoutes(router RPCRouter, h HandlerProvider) {
+	if router == nil || h == nil {
+		return
+	}
+
+	registerHandlers(router, h, []string{
+		constants.MethodQueryLogTopQueries,
+		constants.MethodQueryLogZeroResultQueries,
+		constants.MethodQueryLogSlowestQueries,
+		constants.MethodQueryLogMostRetrievedDocuments,
+		constants.MethodQueryLogNeverRetrievedDocuments,
+	})
+}
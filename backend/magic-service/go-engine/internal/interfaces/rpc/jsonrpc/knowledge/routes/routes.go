@@ -21,12 +21,15 @@ type HandlerProvider interface {
 
 // Dependencies 聚合 RPC 路由所需的处理器。
 type Dependencies struct {
-	Server           RPCRouter
-	KnowledgeHandler HandlerProvider
-	FragmentHandler  HandlerProvider
-	EmbeddingHandler HandlerProvider
-	DocumentHandler  HandlerProvider
-	OpsHandler       HandlerProvider
+	Server            RPCRouter
+	KnowledgeHandler  HandlerProvider
+	FragmentHandler   HandlerProvider
+	EmbeddingHandler  HandlerProvider
+	DocumentHandler   HandlerProvider
+	OpsHandler        HandlerProvider
+	EvaluationHandler HandlerProvider
+	QueryLogHandler   HandlerProvider
+	FeedbackHandler   HandlerProvider
 }
 
 // SetupRPCRoutes 注册全部 RPC 路由。
@@ -45,6 +48,9 @@ func SetupRPCRoutes(deps Dependencies) {
 	RegisterEmbeddingRoutes(deps.Server, deps.EmbeddingHandler)
 	RegisterDocumentRoutes(deps.Server, deps.DocumentHandler)
 	RegisterOpsRoutes(deps.Server, deps.OpsHandler)
+	RegisterEvaluationRoutes(deps.Server, deps.EvaluationHandler)
+	RegisterQueryLogRoutes(deps.Server, deps.QueryLogHandler)
+	RegisterFeedbackRoutes(deps.Server, deps.FeedbackHandler)
 }
 
 func registerHandlers(router RPCRouter, provider HandlerProvider, methods []string) {
@@ -49,6 +49,7 @@ func fragmentMethods() []string {
 		constants.MethodFragmentSimilarityHTTP,
 		constants.MethodFragmentRuntimeSimilarity,
 		constants.MethodFragmentSimilarityByAgent,
+		constants.MethodFragmentAssembleContextByAgent,
 		constants.MethodFragmentPreview,
 		constants.MethodFragmentPreviewHTTP,
 	}
@@ -83,6 +84,32 @@ func opsMethods() []string {
 	}
 }
 
+func evaluationMethods() []string {
+	return []string{
+		constants.MethodEvaluationSaveQuerySet,
+		constants.MethodEvaluationListQuerySet,
+		constants.MethodEvaluationRun,
+		constants.MethodEvaluationListRuns,
+		constants.MethodEvaluationCompareRuns,
+	}
+}
+
+func queryLogMethods() []string {
+	return []string{
+		constants.MethodQueryLogTopQueries,
+		constants.MethodQueryLogZeroResultQueries,
+		constants.MethodQueryLogSlowestQueries,
+		constants.MethodQueryLogMostRetrievedDocuments,
+		constants.MethodQueryLogNeverRetrievedDocuments,
+	}
+}
+
+func feedbackMethods() []string {
+	return []string{
+		constants.MethodFeedbackSubmit,
+	}
+}
+
 func TestSetupRPCRoutesRegistersAllKnowledgeHandlers(t *testing.T) {
 	t.Parallel()
 
@@ -154,14 +181,44 @@ func TestRegisterOpsRoutes(t *testing.T) {
 	assertRegisteredMethods(t, router, opsMethods())
 }
 
+func TestRegisterEvaluationRoutes(t *testing.T) {
+	t.Parallel()
+
+	router := &fakeRouter{}
+	routes.RegisterEvaluationRoutes(router, stubProvider(evaluationMethods()...))
+
+	assertRegisteredMethods(t, router, evaluationMethods())
+}
+
+func TestRegisterQueryLogRoutes(t *testing.T) {
+	t.Parallel()
+
+	router := &fakeRouter{}
+	routes.RegisterQueryLogRoutes(router, stubProvider(queryLogMethods()...))
+
+	assertRegisteredMethods(t, router, queryLogMethods())
+}
+
+func TestRegisterFeedbackRoutes(t *testing.T) {
+	t.Parallel()
+
+	router := &fakeRouter{}
+	routes.RegisterFeedbackRoutes(router, stubProvider(feedbackMethods()...))
+
+	assertRegisteredMethods(t, router, feedbackMethods())
+}
+
 func newDependencies(router *fakeRouter) routes.Dependencies {
 	return routes.Dependencies{
-		Server:           router,
-		KnowledgeHandler: stubProvider(knowledgeMethods()...),
-		FragmentHandler:  stubProvider(fragmentMethods()...),
-		EmbeddingHandler: stubProvider(embeddingMethods()...),
-		DocumentHandler:  stubProvider(documentMethods()...),
-		OpsHandler:       stubProvider(opsMethods()...),
+		Server:            router,
+		KnowledgeHandler:  stubProvider(knowledgeMethods()...),
+		FragmentHandler:   stubProvider(fragmentMethods()...),
+		EmbeddingHandler:  stubProvider(embeddingMethods()...),
+		DocumentHandler:   stubProvider(documentMethods()...),
+		OpsHandler:        stubProvider(opsMethods()...),
+		EvaluationHandler: stubProvider(evaluationMethods()...),
+		QueryLogHandler:   stubProvider(queryLogMethods()...),
+		FeedbackHandler:   stubProvider(feedbackMethods()...),
 	}
 }
 
@@ -171,13 +228,19 @@ func allKnowledgeMethods() []string {
 	embedding := embeddingMethods()
 	document := documentMethods()
 	ops := opsMethods()
-	methods := make([]string, 0, 1+len(knowledge)+len(fragment)+len(embedding)+len(document)+len(ops))
+	evaluation := evaluationMethods()
+	queryLog := queryLogMethods()
+	feedback := feedbackMethods()
+	methods := make([]string, 0, 1+len(knowledge)+len(fragment)+len(embedding)+len(document)+len(ops)+len(evaluation)+len(queryLog)+len(feedback))
 	methods = append(methods, constants.MethodPing)
 	methods = append(methods, knowledge...)
 	methods = append(methods, fragment...)
 	methods = append(methods, embedding...)
 	methods = append(methods, document...)
 	methods = append(methods, ops...)
+	methods = append(methods, evaluation...)
+	methods = append(methods, queryLog...)
+	methods = append(methods, feedback...)
 	return methods
 }
 
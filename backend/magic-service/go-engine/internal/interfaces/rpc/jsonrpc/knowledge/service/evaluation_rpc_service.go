@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+
+	evalapp "magic/internal/application/knowledge/evaluation"
+	"magic/internal/infrastructure/logging"
+	"magic/internal/interfaces/rpc/jsonrpc/knowledge/dto"
+	"magic/internal/pkg/timeformat"
+)
+
+type evaluationApplicationService interface {
+	SaveQuerySet(ctx context.Context, input evalapp.SaveQuerySetInput) ([]*evalapp.GoldenQuery, error)
+	ListQuerySet(ctx context.Context, input evalapp.QuerySetInput) ([]*evalapp.GoldenQuery, error)
+	RunEvaluation(ctx context.Context, input evalapp.RunInput) (*evalapp.RunResult, error)
+	ListRuns(ctx context.Context, input evalapp.ListRunsInput) ([]*evalapp.Run, error)
+	CompareRuns(ctx context.Context, input evalapp.CompareRunsInput) (*evalapp.RunDiff, error)
+}
+
+// EvaluationRPCService 知识库检索评测 RPC 处理器
+type EvaluationRPCService struct {
+	appService evaluationApplicationService
+	logger     *logging.SugaredLogger
+}
+
+// NewEvaluationRPCService 创建检索评测处理器
+func NewEvaluationRPCService(appService *evalapp.Service, logger *logging.SugaredLogger) *EvaluationRPCService {
+	return NewEvaluationRPCServiceWithDependencies(appService, logger)
+}
+
+// NewEvaluationRPCServiceWithDependencies 创建支持接口替身的检索评测处理器。
+func NewEvaluationRPCServiceWithDependencies(
+	appService evaluationApplicationService,
+	logger *logging.SugaredLogger,
+) *EvaluationRPCService {
+	return &EvaluationRPCService{appService: appService, logger: logger}
+}
+
+// SaveQuerySetRPC 全量保存评测集
+func (h *EvaluationRPCService) SaveQuerySetRPC(
+	ctx context.Context,
+	req *dto.SaveEvaluationQuerySetRequest,
+) (*dto.EvaluationQuerySetResponse, error) {
+	ctx = withAccessActorFromDataIsolation(ctx, req.DataIsolation)
+	queries := make([]evalapp.GoldenQueryInput, 0, len(req.Queries))
+	for _, item := range req.Queries {
+		queries = append(queries, evalapp.GoldenQueryInput{
+			QueryKey:              item.QueryKey,
+			Query:                 item.Query,
+			ExpectedDocumentCodes: item.ExpectedDocumentCodes,
+			ExpectedFragmentIDs:   item.ExpectedFragmentIDs,
+		})
+	}
+
+	saved, err := h.appService.SaveQuerySet(ctx, evalapp.SaveQuerySetInput{
+		QuerySetInput: querySetInput(req.DataIsolation, req.KnowledgeBaseCode, req.SetCode),
+		UserID:        req.DataIsolation.UserID,
+		Queries:       queries,
+	})
+	if err != nil {
+		h.logger.KnowledgeErrorContext(ctx, "Failed to save evaluation query set", "error", err)
+		return nil, mapBusinessError(ctx, err)
+	}
+	return newEvaluationQuerySetResponse(req.KnowledgeBaseCode, req.SetCode, saved), nil
+}
+
+// ListQuerySetRPC 查询评测集
+func (h *EvaluationRPCService) ListQuerySetRPC(
+	ctx context.Context,
+	req *dto.ListEvaluationQuerySetRequest,
+) (*dto.EvaluationQuerySetResponse, error) {
+	ctx = withAccessActorFromDataIsolation(ctx, req.DataIsolation)
+	queries, err := h.appService.ListQuerySet(ctx, querySetInput(req.DataIsolation, req.KnowledgeBaseCode, req.SetCode))
+	if err != nil {
+		h.logger.KnowledgeErrorContext(ctx, "Failed to list evaluation query set", "error", err)
+		return nil, mapBusinessError(ctx, err)
+	}
+	return newEvaluationQuerySetResponse(req.KnowledgeBaseCode, req.SetCode, queries), nil
+}
+
+// RunRPC 回放评测集并返回指标报告
+func (h *EvaluationRPCService) RunRPC(ctx context.Context, req *dto.RunEvaluationRequest) (*dto.RunEvaluationResponse, error) {
+	ctx = withAccessActorFromDataIsolation(ctx, req.DataIsolation)
+	result, err := h.appService.RunEvaluation(ctx, evalapp.RunInput{
+		QuerySetInput: querySetInput(req.DataIsolation, req.KnowledgeBaseCode, req.SetCode),
+		UserID:        req.DataIsolation.UserID,
+		Label:         req.Label,
+		TopK:          req.TopK,
+		Cutoffs:       req.Cutoffs,
+		BaselineRunID: req.BaselineRunID,
+	})
+	if err != nil {
+		h.logger.KnowledgeErrorContext(ctx, "Failed to run knowledge evaluation", "error", err)
+		return nil, mapBusinessError(ctx, err)
+	}
+	return &dto.RunEvaluationResponse{
+		Run:  newEvaluationRunResponse(result.Run, true),
+		Diff: result.Diff,
+	}, nil
+}
+
+// ListRunsRPC 分页查询评测 run 摘要
+func (h *EvaluationRPCService) ListRunsRPC(
+	ctx context.Context,
+	req *dto.ListEvaluationRunsRequest,
+) (*dto.ListEvaluationRunsResponse, error) {
+	ctx = withAccessActorFromDataIsolation(ctx, req.DataIsolation)
+	runs, err := h.appService.ListRuns(ctx, evalapp.ListRunsInput{
+		QuerySetInput: querySetInput(req.DataIsolation, req.KnowledgeBaseCode, req.SetCode),
+		Limit:         req.Limit,
+		Offset:        req.Offset,
+	})
+	if err != nil {
+		h.logger.KnowledgeErrorContext(ctx, "Failed to list evaluation runs", "error", err)
+		return nil, mapBusinessError(ctx, err)
+	}
+
+	list := make([]*dto.EvaluationRunResponse, 0, len(runs))
+	for _, run := range runs {
+		if run != nil {
+			list = append(list, newEvaluationRunResponse(run, false))
+		}
+	}
+	return &dto.ListEvaluationRunsResponse{List: list}, nil
+}
+
+// CompareRunsRPC 对比两次评测 run
+func (h *EvaluationRPCService) CompareRunsRPC(
+	ctx context.Context,
+	req *dto.CompareEvaluationRunsRequest,
+) (*evalapp.RunDiff, error) {
+	ctx = withAccessActorFromDataIsolation(ctx, req.DataIsolation)
+	diff, err := h.appService.CompareRuns(ctx, evalapp.CompareRunsInput{
+		OrganizationCode: req.DataIsolation.ResolveOrganizationCode(),
+		BaseRunID:        req.BaseRunID,
+		TargetRunID:      req.TargetRunID,
+	})
+	if err != nil {
+		h.logger.KnowledgeErrorContext(ctx, "Failed to compare evaluation runs", "error", err)
+		return nil, mapBusinessError(ctx, err)
+	}
+	return diff, nil
+}
+
+func querySetInput(dataIsolation dto.DataIsolation, knowledgeBaseCode, setCode string) evalapp.QuerySetInput {
+	return evalapp.QuerySetInput{
+		OrganizationCode:  dataIsolation.ResolveOrganizationCode(),
+		KnowledgeBaseCode: knowledgeBaseCode,
+		SetCode:           setCode,
+	}
+}
+
+func newEvaluationQuerySetResponse(
+	knowledgeBaseCode string,
+	setCode string,
+	queries []*evalapp.GoldenQuery,
+) *dto.EvaluationQuerySetResponse {
+	resp := &dto.EvaluationQuerySetResponse{
+		KnowledgeBaseCode: knowledgeBaseCode,
+		SetCode:           setCode,
+		Queries:           make([]*dto.GoldenQueryResponse, 0, len(queries)),
+	}
+	for _, query := range queries {
+		if query == nil {
+			continue
+		}
+		// 以落库后的规范化值为准，空 set_code 会回落到默认评测集。
+		resp.KnowledgeBaseCode = query.KnowledgeBaseCode
+		resp.SetCode = query.SetCode
+		resp.Queries = append(resp.Queries, &dto.GoldenQueryResponse{
+			ID:                    query.ID,
+			KnowledgeBaseCode:     query.KnowledgeBaseCode,
+			SetCode:               query.SetCode,
+			QueryKey:              query.QueryKey,
+			Query:                 query.Query,
+			ExpectedDocumentCodes: query.ExpectedDocumentCodes,
+			ExpectedFragmentIDs:   query.ExpectedFragmentIDs,
+			CreatedUID:            query.CreatedUID,
+			UpdatedUID:            query.UpdatedUID,
+			CreatedAt:             timeformat.FormatAPIDatetime(query.CreatedAt),
+			UpdatedAt:             timeformat.FormatAPIDatetime(query.UpdatedAt),
+		})
+	}
+	return resp
+}
+
+func newEvaluationRunResponse(run *evalapp.Run, withResults bool) *dto.EvaluationRunResponse {
+	if run == nil {
+		return nil
+	}
+	resp := &dto.EvaluationRunResponse{
+		ID:                run.ID,
+		KnowledgeBaseCode: run.KnowledgeBaseCode,
+		SetCode:           run.SetCode,
+		Label:             run.Label,
+		TopK:              run.TopK,
+		BaselineRunID:     run.BaselineRunID,
+		Metrics:           run.Metrics,
+		CreatedUID:        run.CreatedUID,
+		StartedAt:         timeformat.FormatAPIDatetime(run.StartedAt),
+		FinishedAt:        timeformat.FormatAPIDatetime(run.FinishedAt),
+	}
+	if withResults {
+		resp.Results = run.Results
+	}
+	return resp
+}
@@ -0,0 +1,165 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	evalapp "magic/internal/application/knowledge/evaluation"
+	"magic/internal/infrastructure/logging"
+	"magic/internal/interfaces/rpc/jsonrpc/knowledge/dto"
+	knowledgesvc "magic/internal/interfaces/rpc/jsonrpc/knowledge/service"
+	jsonrpc "magic/internal/pkg/jsonrpc"
+)
+
+type mockEvaluationAppService struct {
+	queries []*evalapp.GoldenQuery
+	result  *evalapp.RunResult
+	runs    []*evalapp.Run
+	diff    *evalapp.RunDiff
+	err     error
+
+	lastSave    evalapp.SaveQuerySetInput
+	lastRun     evalapp.RunInput
+	lastList    evalapp.ListRunsInput
+	lastCompare evalapp.CompareRunsInput
+}
+
+func (m *mockEvaluationAppService) SaveQuerySet(
+	_ context.Context,
+	input evalapp.SaveQuerySetInput,
+) ([]*evalapp.GoldenQuery, error) {
+	m.lastSave = input
+	return m.queries, m.err
+}
+
+func (m *mockEvaluationAppService) ListQuerySet(_ context.Context, _ evalapp.QuerySetInput) ([]*evalapp.GoldenQuery, error) {
+	return m.queries, m.err
+}
+
+func (m *mockEvaluationAppService) RunEvaluation(_ context.Context, input evalapp.RunInput) (*evalapp.RunResult, error) {
+	m.lastRun = input
+	return m.result, m.err
+}
+
+func (m *mockEvaluationAppService) ListRuns(_ context.Context, input evalapp.ListRunsInput) ([]*evalapp.Run, error) {
+	m.lastList = input
+	return m.runs, m.err
+}
+
+func (m *mockEvaluationAppService) CompareRuns(_ context.Context, input evalapp.CompareRunsInput) (*evalapp.RunDiff, error) {
+	m.lastCompare = input
+	return m.diff, m.err
+}
+
+func TestEvaluationSaveQuerySetRPCMapsInput(t *testing.T) {
+	t.Parallel()
+
+	appSvc := &mockEvaluationAppService{queries: []*evalapp.GoldenQuery{{
+		ID:                    7,
+		KnowledgeBaseCode:     "KB1",
+		SetCode:               "default",
+		QueryKey:              "q1",
+		Query:                 "how to reset password",
+		ExpectedDocumentCodes: []string{"DOC1"},
+	}}}
+	handler := knowledgesvc.NewEvaluationRPCServiceWithDependencies(appSvc, logging.New())
+
+	wrapped := jsonrpc.WrapTyped(handler.SaveQuerySetRPC)
+	raw, err := wrapped(context.Background(), "svc.knowledge.evaluation.saveQuerySet", json.RawMessage(`{
+		"data_isolation": {"organization_code": "ORG1", "user_id": "u1"},
+		"knowledge_base_code": "KB1",
+		"queries": [{"query_key": "q1", "query": "how to reset password", "expected_document_codes": ["DOC1"]}]
+	}`))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	resp, ok := raw.(*dto.EvaluationQuerySetResponse)
+	if !ok || resp.SetCode != "default" || len(resp.Queries) != 1 || resp.Queries[0].ID != 7 {
+		t.Fatalf("unexpected response: %#v", raw)
+	}
+	if appSvc.lastSave.OrganizationCode != "ORG1" || appSvc.lastSave.UserID != "u1" ||
+		appSvc.lastSave.KnowledgeBaseCode != "KB1" || len(appSvc.lastSave.Queries) != 1 {
+		t.Fatalf("unexpected save input: %#v", appSvc.lastSave)
+	}
+}
+
+func TestEvaluationRunRPCReturnsRunAndDiff(t *testing.T) {
+	t.Parallel()
+
+	appSvc := &mockEvaluationAppService{result: &evalapp.RunResult{
+		Run: &evalapp.Run{
+			ID:      12,
+			SetCode: "default",
+			TopK:    5,
+			Metrics: evalapp.Metrics{QueryCount: 1, MRR: 0.5},
+			Results: []evalapp.QueryResult{{QueryKey: "q1", ReciprocalRank: 0.5}},
+		},
+		Diff: &evalapp.RunDiff{BaseRunID: 11, TargetRunID: 12},
+	}}
+	handler := knowledgesvc.NewEvaluationRPCServiceWithDependencies(appSvc, logging.New())
+
+	resp, err := handler.RunRPC(context.Background(), &dto.RunEvaluationRequest{
+		DataIsolation:     dto.DataIsolation{OrganizationCode: "ORG1", UserID: "u1"},
+		KnowledgeBaseCode: "KB1",
+		Label:             "rerank-on",
+		TopK:              5,
+		Cutoffs:           []int{1, 5},
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if resp.Run == nil || resp.Run.ID != 12 || len(resp.Run.Results) != 1 || resp.Diff == nil || resp.Diff.BaseRunID != 11 {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+	if appSvc.lastRun.Label != "rerank-on" || appSvc.lastRun.TopK != 5 || len(appSvc.lastRun.Cutoffs) != 2 {
+		t.Fatalf("unexpected run input: %#v", appSvc.lastRun)
+	}
+}
+
+func TestEvaluationListRunsRPCOmitsQueryResults(t *testing.T) {
+	t.Parallel()
+
+	appSvc := &mockEvaluationAppService{runs: []*evalapp.Run{
+		{ID: 2, Results: []evalapp.QueryResult{{QueryKey: "q1"}}},
+		nil,
+		{ID: 1},
+	}}
+	handler := knowledgesvc.NewEvaluationRPCServiceWithDependencies(appSvc, logging.New())
+
+	resp, err := handler.ListRunsRPC(context.Background(), &dto.ListEvaluationRunsRequest{
+		DataIsolation:     dto.DataIsolation{OrganizationCode: "ORG1"},
+		KnowledgeBaseCode: "KB1",
+		Limit:             10,
+		Offset:            20,
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(resp.List) != 2 || resp.List[0].Results != nil {
+		t.Fatalf("unexpected response: %#v", resp.List)
+	}
+	if appSvc.lastList.Limit != 10 || appSvc.lastList.Offset != 20 {
+		t.Fatalf("unexpected list input: %#v", appSvc.lastList)
+	}
+}
+
+func TestEvaluationCompareRunsRPCMapsBusinessError(t *testing.T) {
+	t.Parallel()
+
+	appSvc := &mockEvaluationAppService{err: evalapp.ErrEvaluationScopeRequired}
+	handler := knowledgesvc.NewEvaluationRPCServiceWithDependencies(appSvc, logging.New())
+
+	_, err := handler.CompareRunsRPC(context.Background(), &dto.CompareEvaluationRunsRequest{
+		DataIsolation: dto.DataIsolation{OrganizationCode: "ORG1"},
+		TargetRunID:   3,
+	})
+	var bizErr *jsonrpc.BusinessError
+	if !errors.As(err, &bizErr) || bizErr.Code != jsonrpc.ErrCodeInvalidParams {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if appSvc.lastCompare.OrganizationCode != "ORG1" || appSvc.lastCompare.TargetRunID != 3 {
+		t.Fatalf("unexpected compare input: %#v", appSvc.lastCompare)
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	feedbackapp "magic/internal/application/knowledge/feedback"
+	"magic/internal/infrastructure/logging"
+	"magic/internal/interfaces/rpc/jsonrpc/knowledge/dto"
+)
+
+type feedbackApplicationService interface {
+	Submit(ctx context.Context, input feedbackapp.SubmitInput) (bool, error)
+}
+
+// FeedbackRPCService 检索结果相关性反馈 RPC 处理器
+type FeedbackRPCService struct {
+	appService feedbackApplicationService
+	logger     *logging.SugaredLogger
+}
+
+// NewFeedbackRPCService 创建相关性反馈处理器
+func NewFeedbackRPCService(appService *feedbackapp.Service, logger *logging.SugaredLogger) *FeedbackRPCService {
+	return NewFeedbackRPCServiceWithDependencies(appService, logger)
+}
+
+// NewFeedbackRPCServiceWithDependencies 创建支持接口替身的相关性反馈处理器。
+func NewFeedbackRPCServiceWithDependencies(
+	appService feedbackApplicationService,
+	logger *logging.SugaredLogger,
+) *FeedbackRPCService {
+	return &FeedbackRPCService{appService: appService, logger: logger}
+}
+
+// SubmitRPC 提交检索结果反馈
+func (h *FeedbackRPCService) SubmitRPC(
+	ctx context.Context,
+	req *dto.FeedbackSubmitRequest,
+) (*dto.FeedbackSubmitResponse, error) {
+	ctx = withAccessActorFromDataIsolation(ctx, req.DataIsolation)
+	recorded, err := h.appService.Submit(ctx, feedbackapp.SubmitInput{
+		OrganizationCode:  req.DataIsolation.ResolveOrganizationCode(),
+		KnowledgeBaseCode: req.KnowledgeBaseCode,
+		DocumentCode:      req.DocumentCode,
+		FragmentID:        req.FragmentID,
+		RequestID:         req.RequestID,
+		UserID:            req.DataIsolation.UserID,
+		Signal:            req.Signal,
+	})
+	if err != nil {
+		h.logger.KnowledgeErrorContext(ctx, "Failed to submit knowledge feedback", "error", err)
+		return nil, mapBusinessError(ctx, err)
+	}
+	return &dto.FeedbackSubmitResponse{Recorded: recorded}, nil
+}
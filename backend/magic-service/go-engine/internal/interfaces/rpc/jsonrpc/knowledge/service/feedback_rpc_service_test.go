@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	feedbackapp "magic/internal/application/knowledge/feedback"
+	"magic/internal/infrastructure/logging"
+	"magic/internal/interfaces/rpc/jsonrpc/knowledge/dto"
+	knowledgesvc "magic/internal/interfaces/rpc/jsonrpc/knowledge/service"
+	jsonrpc "magic/internal/pkg/jsonrpc"
+)
+
+type mockFeedbackAppService struct {
+	recorded  bool
+	err       error
+	lastInput feedbackapp.SubmitInput
+}
+
+func (m *mockFeedbackAppService) Submit(_ context.Context, input feedbackapp.SubmitInput) (bool, error) {
+	m.lastInput = input
+	return m.recorded, m.err
+}
+
+func TestFeedbackSubmitRPCMapsInputAndResponse(t *testing.T) {
+	t.Parallel()
+
+	appSvc := &mockFeedbackAppService{recorded: true}
+	handler := knowledgesvc.NewFeedbackRPCServiceWithDependencies(appSvc, logging.New())
+
+	wrapped := jsonrpc.WrapTyped(handler.SubmitRPC)
+	raw, err := wrapped(context.Background(), "svc.knowledge.feedback.submit", json.RawMessage(`{
+		"data_isolation": {"organization_code": "ORG1", "user_id": "u1"},
+		"knowledge_base_code": "KB1",
+		"fragment_id": 42,
+		"request_id": "req-1",
+		"signal": "thumbs_up"
+	}`))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	resp, ok := raw.(*dto.FeedbackSubmitResponse)
+	if !ok || !resp.Recorded {
+		t.Fatalf("unexpected response: %#v", raw)
+	}
+	input := appSvc.lastInput
+	if input.OrganizationCode != "ORG1" || input.UserID != "u1" || input.KnowledgeBaseCode != "KB1" ||
+		input.FragmentID != 42 || input.RequestID != "req-1" || input.Signal != "thumbs_up" {
+		t.Fatalf("unexpected submit input: %#v", input)
+	}
+}
+
+func TestFeedbackSubmitRPCMapsInvalidSignal(t *testing.T) {
+	t.Parallel()
+
+	appSvc := &mockFeedbackAppService{err: feedbackapp.ErrInvalidSignal}
+	handler := knowledgesvc.NewFeedbackRPCServiceWithDependencies(appSvc, logging.New())
+
+	wrapped := jsonrpc.WrapTyped(handler.SubmitRPC)
+	_, err := wrapped(context.Background(), "svc.knowledge.feedback.submit", json.RawMessage(`{
+		"data_isolation": {"organization_code": "ORG1"},
+		"knowledge_base_code": "KB1",
+		"document_code": "DOC1",
+		"request_id": "req-1",
+		"signal": "meh"
+	}`))
+	var bizErr *jsonrpc.BusinessError
+	if !errors.As(err, &bizErr) || bizErr.Code != jsonrpc.ErrCodeInvalidParams {
+		t.Fatalf("expected invalid params business error, got %v", err)
+	}
+}
@@ -18,6 +18,7 @@ type fragmentCoreApplicationService interface {
 	Sync(ctx context.Context, input *fragdto.SyncFragmentInput) (*fragdto.FragmentDTO, error)
 	Similarity(ctx context.Context, input *fragdto.SimilarityInput) ([]*fragdto.SimilarityResultDTO, error)
 	SimilarityByAgent(ctx context.Context, input *fragdto.AgentSimilarityInput) (*fragdto.AgentSimilarityResultDTO, error)
+	AssembleContextByAgent(ctx context.Context, input *fragdto.AgentContextAssemblyInput) (*fragdto.AgentContextAssemblyResultDTO, error)
 	PreviewV2(ctx context.Context, input *fragdto.PreviewFragmentInput) (*fragdto.FragmentPageResultDTO, error)
 }
 
@@ -244,10 +245,11 @@ func (h *FragmentRPCService) SimilarityRPC(ctx context.Context, req *dto.Similar
 		Query:            req.Query,
 		// Keep fragment similarity on the app-service default instead of inheriting
 		// the legacy caller-supplied top_k from upstream knowledge retrieve config.
-		TopK:           0,
-		ScoreThreshold: req.ScoreThreshold,
-		Filters:        toSimilarityFilterInput(req.Filters),
-		Debug:          req.Debug,
+		TopK:             0,
+		ScoreThreshold:   req.ScoreThreshold,
+		Filters:          toSimilarityFilterInput(req.Filters),
+		Debug:            req.Debug,
+		ContextExpansion: toContextExpansionInput(req.ContextExpansion),
 		BusinessParams: &ctxmeta.BusinessParams{
 			OrganizationCode: req.BusinessParams.ResolveOrganizationCode(),
 			UserID:           req.BusinessParams.UserID,
@@ -274,6 +276,7 @@ func (h *FragmentRPCService) SimilarityHTTPRPC(ctx context.Context, req *dto.Sim
 		ScoreThreshold:   req.ScoreThreshold,
 		Filters:          toSimilarityFilterInput(req.Filters),
 		Debug:            req.Debug,
+		ContextExpansion: toContextExpansionInput(req.ContextExpansion),
 		BusinessParams: &ctxmeta.BusinessParams{
 			OrganizationCode: req.BusinessParams.ResolveOrganizationCode(),
 			UserID:           req.BusinessParams.UserID,
@@ -305,6 +308,7 @@ func (h *FragmentRPCService) RuntimeSimilarityRPC(
 		ScoreThreshold:   req.ScoreThreshold,
 		MetadataFilter:   map[string]any(req.MetadataFilter),
 		Debug:            req.Debug,
+		ContextExpansion: toContextExpansionInput(req.ContextExpansion),
 		BusinessParams: &ctxmeta.BusinessParams{
 			OrganizationCode: req.BusinessParams.ResolveOrganizationCode(),
 			UserID:           req.BusinessParams.UserID,
@@ -372,6 +376,46 @@ func (h *FragmentRPCService) SimilarityByAgentRPC(
 	return dto.NewAgentSimilarityResponse(result), nil
 }
 
+// AssembleContextByAgentRPC 数字员工维度检索并按 token 预算组装带引用的上下文块。
+func (h *FragmentRPCService) AssembleContextByAgentRPC(
+	ctx context.Context,
+	req *dto.AgentContextAssemblyRequest,
+) (*dto.AgentContextAssemblyResponse, error) {
+	ctx = withAccessActorFromDataIsolation(ctx, req.DataIsolation)
+	result, err := h.appService.AssembleContextByAgent(ctx, &fragdto.AgentContextAssemblyInput{
+		OrganizationCode: req.DataIsolation.ResolveOrganizationCode(),
+		UserID:           req.DataIsolation.UserID,
+		AgentCode:        req.AgentCode,
+		Query:            req.Query,
+		MaxTokens:        req.MaxTokens,
+		Model:            req.Model,
+		Strategy:         req.Strategy,
+		MaxPerDocument:   req.MaxPerDocument,
+		MMRLambda:        req.MMRLambda,
+		CandidateTopK:    req.CandidateTopK,
+		BusinessParams: &ctxmeta.BusinessParams{
+			OrganizationCode: req.DataIsolation.ResolveOrganizationCode(),
+			UserID:           req.DataIsolation.UserID,
+		},
+	})
+	if err != nil {
+		h.logger.KnowledgeErrorContext(ctx, "Failed to assemble employee knowledge context", "error", err)
+		return nil, mapBusinessError(ctx, err)
+	}
+	return dto.NewAgentContextAssemblyResponse(result), nil
+}
+
+func toContextExpansionInput(req *dto.ContextExpansionRequest) *fragdto.ContextExpansionInput {
+	if req == nil {
+		return nil
+	}
+	return &fragdto.ContextExpansionInput{
+		Mode:           req.Mode,
+		NeighborWindow: req.NeighborWindow,
+		MaxTokens:      req.MaxTokens,
+	}
+}
+
 func toSimilarityFilterInput(filters *dto.SimilarityFilters) *fragdto.SimilarityFilterInput {
 	if filters == nil {
 		return nil
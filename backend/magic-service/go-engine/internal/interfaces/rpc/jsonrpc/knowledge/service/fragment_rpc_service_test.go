@@ -45,6 +45,7 @@ type mockFragmentAppService struct {
 	lastFlowVectorSimilarityByUser *fragdto.FlowVectorSimilarityByUserInput
 	lastFlowVectorAccessActor      ctxmeta.AccessActor
 	lastAgentSimilarity            *fragdto.AgentSimilarityInput
+	lastContextAssembly            *fragdto.AgentContextAssemblyInput
 	lastSyncInput                  *fragdto.SyncFragmentInput
 	lastShowID                     int64
 	lastDestroyID                  int64
@@ -237,6 +238,29 @@ func (m *mockFragmentAppService) FlowVectorSimilarityByUser(ctx context.Context,
 	}}, nil
 }
 
+func (m *mockFragmentAppService) AssembleContextByAgent(
+	_ context.Context,
+	input *fragdto.AgentContextAssemblyInput,
+) (*fragdto.AgentContextAssemblyResultDTO, error) {
+	m.lastContextAssembly = input
+	return &fragdto.AgentContextAssemblyResultDTO{
+		QueryUsed:  input.Query,
+		Strategy:   fragdto.ContextAssemblyStrategyMMR,
+		MaxTokens:  input.MaxTokens,
+		TokenCount: 12,
+		Context:    "[1] demo.md\nhello similarity",
+		Citations: []*fragdto.ContextCitationDTO{{
+			Index:         1,
+			CitationID:    "KB1:DOC1:8",
+			FragmentID:    8,
+			KnowledgeCode: testFragmentKBCode,
+			DocumentCode:  "DOC1",
+			DocumentName:  "demo.md",
+			TokenCount:    12,
+		}},
+	}, nil
+}
+
 func (m *mockFragmentAppService) SimilarityByAgent(_ context.Context, input *fragdto.AgentSimilarityInput) (*fragdto.AgentSimilarityResultDTO, error) {
 	m.lastAgentSimilarity = input
 	if m.agentSimilarityErr != nil {
@@ -884,6 +908,37 @@ func assertAgentSimilarityResponsePayload(t *testing.T, result *dto.AgentSimilar
 	}
 }
 
+func TestFragmentAssembleContextByAgentRPC(t *testing.T) {
+	t.Parallel()
+
+	appSvc := &mockFragmentAppService{}
+	handler := knowledgesvc.NewFragmentRPCServiceWithDependencies(appSvc, logging.New())
+	lambda := 0.5
+	result, err := handler.AssembleContextByAgentRPC(context.Background(), &dto.AgentContextAssemblyRequest{
+		DataIsolation: dto.DataIsolation{OrganizationCode: "ORG1", UserID: "U1"},
+		AgentCode:     "SMA-001",
+		Query:         "退款多久到账",
+		MaxTokens:     800,
+		Model:         "gpt-4o",
+		Strategy:      fragdto.ContextAssemblyStrategyMMR,
+		MMRLambda:     &lambda,
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	input := appSvc.lastContextAssembly
+	if input == nil || input.AgentCode != "SMA-001" || input.MaxTokens != 800 || input.Model != "gpt-4o" ||
+		input.MMRLambda == nil || *input.MMRLambda != lambda {
+		t.Fatalf("expected context assembly input to be forwarded, got %#v", input)
+	}
+	if input.BusinessParams == nil || input.BusinessParams.OrganizationCode != "ORG1" || input.BusinessParams.UserID != "U1" {
+		t.Fatalf("expected business params to be forwarded, got %#v", input.BusinessParams)
+	}
+	if result == nil || result.TokenCount != 12 || len(result.Citations) != 1 || result.Citations[0].CitationID != "KB1:DOC1:8" {
+		t.Fatalf("unexpected context assembly response: %#v", result)
+	}
+}
+
 func TestFragmentSimilarityByAgentRPCGroupsDocuments(t *testing.T) {
 	t.Parallel()
 
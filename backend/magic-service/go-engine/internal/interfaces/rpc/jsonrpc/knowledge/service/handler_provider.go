@@ -100,6 +100,7 @@ func (h *FragmentRPCService) fragmentSearchHandlers() []rpcHandlerEntry {
 		{constants.MethodFragmentSimilarityHTTP, jsonrpc.WrapTyped(h.SimilarityHTTPRPC)},
 		{constants.MethodFragmentFlowVectorSimilarityByUser, jsonrpc.WrapTyped(h.FlowVectorSimilarityByUserRPC)},
 		{constants.MethodFragmentSimilarityByAgent, jsonrpc.WrapTyped(h.SimilarityByAgentRPC)},
+		{constants.MethodFragmentAssembleContextByAgent, jsonrpc.WrapTyped(h.AssembleContextByAgentRPC)},
 		{constants.MethodFragmentPreview, jsonrpc.WrapTyped(h.PreviewRPC)},
 		{constants.MethodFragmentPreviewHTTP, jsonrpc.WrapTyped(h.PreviewHTTPRPC)},
 	}
@@ -138,6 +139,47 @@ func (h *DocumentRPCService) Handlers() map[string]jsonrpc.ServerHandler {
 	)
 }
 
+// Handlers returns all knowledge evaluation RPC handlers exposed by the service.
+func (h *EvaluationRPCService) Handlers() map[string]jsonrpc.ServerHandler {
+	if h == nil {
+		return nil
+	}
+
+	return rpcHandlerMap(
+		rpcHandlerEntry{constants.MethodEvaluationSaveQuerySet, jsonrpc.WrapTyped(h.SaveQuerySetRPC)},
+		rpcHandlerEntry{constants.MethodEvaluationListQuerySet, jsonrpc.WrapTyped(h.ListQuerySetRPC)},
+		rpcHandlerEntry{constants.MethodEvaluationRun, jsonrpc.WrapTyped(h.RunRPC)},
+		rpcHandlerEntry{constants.MethodEvaluationListRuns, jsonrpc.WrapTyped(h.ListRunsRPC)},
+		rpcHandlerEntry{constants.MethodEvaluationCompareRuns, jsonrpc.WrapTyped(h.CompareRunsRPC)},
+	)
+}
+
+// Handlers returns all knowledge query log analytics RPC handlers exposed by the service.
+func (h *QueryLogRPCService) Handlers() map[string]jsonrpc.ServerHandler {
+	if h == nil {
+		return nil
+	}
+
+	return rpcHandlerMap(
+		rpcHandlerEntry{constants.MethodQueryLogTopQueries, jsonrpc.WrapTyped(h.TopQueriesRPC)},
+		rpcHandlerEntry{constants.MethodQueryLogZeroResultQueries, jsonrpc.WrapTyped(h.ZeroResultQueriesRPC)},
+		rpcHandlerEntry{constants.MethodQueryLogSlowestQueries, jsonrpc.WrapTyped(h.SlowestQueriesRPC)},
+		rpcHandlerEntry{constants.MethodQueryLogMostRetrievedDocuments, jsonrpc.WrapTyped(h.MostRetrievedDocumentsRPC)},
+		rpcHandlerEntry{constants.MethodQueryLogNeverRetrievedDocuments, jsonrpc.WrapTyped(h.NeverRetrievedDocumentsRPC)},
+	)
+}
+
+// Handlers returns all knowledge feedback RPC handlers exposed by the service.
+func (h *FeedbackRPCService) Handlers() map[string]jsonrpc.ServerHandler {
+	if h == nil {
+		return nil
+	}
+
+	return rpcHandlerMap(
+		rpcHandlerEntry{constants.MethodFeedbackSubmit, jsonrpc.WrapTyped(h.SubmitRPC)},
+	)
+}
+
 func rpcHandlerMap(entries ...rpcHandlerEntry) map[string]jsonrpc.ServerHandler {
 	handlers := make(map[string]jsonrpc.ServerHandler, len(entries))
 	addRPCHandlers(handlers, entries...)
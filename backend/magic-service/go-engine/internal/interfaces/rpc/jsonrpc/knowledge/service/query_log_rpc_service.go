@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	querylogapp "magic/internal/application/knowledge/querylog"
+	"magic/internal/infrastructure/logging"
+	"magic/internal/interfaces/rpc/jsonrpc/knowledge/dto"
+	jsonrpc "magic/internal/pkg/jsonrpc"
+	"magic/internal/pkg/timeformat"
+)
+
+type queryLogAnalyticsApplicationService interface {
+	TopQueries(ctx context.Context, input querylogapp.AnalyticsInput) ([]querylogapp.QueryStat, error)
+	ZeroResultQueries(ctx context.Context, input querylogapp.AnalyticsInput) ([]querylogapp.QueryStat, error)
+	SlowestQueries(ctx context.Context, input querylogapp.AnalyticsInput) ([]querylogapp.SlowQuery, error)
+	MostRetrievedDocuments(ctx context.Context, input querylogapp.AnalyticsInput) ([]querylogapp.DocumentUsage, error)
+	NeverRetrievedDocuments(ctx context.Context, input querylogapp.AnalyticsInput) ([]querylogapp.UnusedDocument, error)
+}
+
+// QueryLogRPCService 知识库检索日志统计 RPC 处理器
+type QueryLogRPCService struct {
+	appService queryLogAnalyticsApplicationService
+	logger     *logging.SugaredLogger
+}
+
+// NewQueryLogRPCService 创建检索日志统计处理器
+func NewQueryLogRPCService(appService *querylogapp.AnalyticsService, logger *logging.SugaredLogger) *QueryLogRPCService {
+	return NewQueryLogRPCServiceWithDependencies(appService, logger)
+}
+
+// NewQueryLogRPCServiceWithDependencies 创建支持接口替身的检索日志统计处理器。
+func NewQueryLogRPCServiceWithDependencies(
+	appService queryLogAnalyticsApplicationService,
+	logger *logging.SugaredLogger,
+) *QueryLogRPCService {
+	return &QueryLogRPCService{appService: appService, logger: logger}
+}
+
+// TopQueriesRPC 查询高频检索 query
+func (h *QueryLogRPCService) TopQueriesRPC(
+	ctx context.Context,
+	req *dto.QueryLogAnalyticsRequest,
+) (*dto.QueryStatListResponse, error) {
+	ctx = withAccessActorFromDataIsolation(ctx, req.DataIsolation)
+	input, err := queryLogAnalyticsInput(req)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := h.appService.TopQueries(ctx, input)
+	if err != nil {
+		h.logger.KnowledgeErrorContext(ctx, "Failed to query top search queries", "error", err)
+		return nil, mapBusinessError(ctx, err)
+	}
+	return newQueryStatListResponse(stats), nil
+}
+
+// ZeroResultQueriesRPC 查询零结果检索 query
+func (h *QueryLogRPCService) ZeroResultQueriesRPC(
+	ctx context.Context,
+	req *dto.QueryLogAnalyticsRequest,
+) (*dto.QueryStatListResponse, error) {
+	ctx = withAccessActorFromDataIsolation(ctx, req.DataIsolation)
+	input, err := queryLogAnalyticsInput(req)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := h.appService.ZeroResultQueries(ctx, input)
+	if err != nil {
+		h.logger.KnowledgeErrorContext(ctx, "Failed to query zero result search queries", "error", err)
+		return nil, mapBusinessError(ctx, err)
+	}
+	return newQueryStatListResponse(stats), nil
+}
+
+// SlowestQueriesRPC 查询最慢的检索
+func (h *QueryLogRPCService) SlowestQueriesRPC(
+	ctx context.Context,
+	req *dto.QueryLogAnalyticsRequest,
+) (*dto.SlowQueryListResponse, error) {
+	ctx = withAccessActorFromDataIsolation(ctx, req.DataIsolation)
+	input, err := queryLogAnalyticsInput(req)
+	if err != nil {
+		return nil, err
+	}
+	queries, err := h.appService.SlowestQueries(ctx, input)
+	if err != nil {
+		h.logger.KnowledgeErrorContext(ctx, "Failed to query slowest search queries", "error", err)
+		return nil, mapBusinessError(ctx, err)
+	}
+
+	resp := &dto.SlowQueryListResponse{List: make([]*dto.SlowQueryResponse, 0, len(queries))}
+	for _, query := range queries {
+		resp.List = append(resp.List, &dto.SlowQueryResponse{
+			ID:          query.ID,
+			Query:       query.Query,
+			QueryType:   query.QueryType,
+			Source:      query.Source,
+			ResultCount: query.ResultCount,
+			LatencyMS:   query.LatencyMS,
+			CreatedAt:   timeformat.FormatAPIDatetime(query.CreatedAt),
+		})
+	}
+	return resp, nil
+}
+
+// MostRetrievedDocumentsRPC 查询被命中最多的文档
+func (h *QueryLogRPCService) MostRetrievedDocumentsRPC(
+	ctx context.Context,
+	req *dto.QueryLogAnalyticsRequest,
+) (*dto.DocumentUsageListResponse, error) {
+	ctx = withAccessActorFromDataIsolation(ctx, req.DataIsolation)
+	input, err := queryLogAnalyticsInput(req)
+	if err != nil {
+		return nil, err
+	}
+	documents, err := h.appService.MostRetrievedDocuments(ctx, input)
+	if err != nil {
+		h.logger.KnowledgeErrorContext(ctx, "Failed to query most retrieved documents", "error", err)
+		return nil, mapBusinessError(ctx, err)
+	}
+
+	resp := &dto.DocumentUsageListResponse{List: make([]*dto.DocumentUsageResponse, 0, len(documents))}
+	for _, document := range documents {
+		resp.List = append(resp.List, &dto.DocumentUsageResponse{
+			DocumentCode:    document.DocumentCode,
+			HitCount:        document.HitCount,
+			QueryCount:      document.QueryCount,
+			AvgScore:        document.AvgScore,
+			LastRetrievedAt: timeformat.FormatAPIDatetime(document.LastRetrievedAt),
+		})
+	}
+	return resp, nil
+}
+
+// NeverRetrievedDocumentsRPC 查询从未被命中的文档
+func (h *QueryLogRPCService) NeverRetrievedDocumentsRPC(
+	ctx context.Context,
+	req *dto.QueryLogAnalyticsRequest,
+) (*dto.UnusedDocumentListResponse, error) {
+	ctx = withAccessActorFromDataIsolation(ctx, req.DataIsolation)
+	input, err := queryLogAnalyticsInput(req)
+	if err != nil {
+		return nil, err
+	}
+	documents, err := h.appService.NeverRetrievedDocuments(ctx, input)
+	if err != nil {
+		h.logger.KnowledgeErrorContext(ctx, "Failed to query never retrieved documents", "error", err)
+		return nil, mapBusinessError(ctx, err)
+	}
+
+	resp := &dto.UnusedDocumentListResponse{List: make([]*dto.UnusedDocumentResponse, 0, len(documents))}
+	for _, document := range documents {
+		resp.List = append(resp.List, &dto.UnusedDocumentResponse{
+			DocumentCode: document.DocumentCode,
+			DocumentName: document.DocumentName,
+			CreatedAt:    timeformat.FormatAPIDatetime(document.CreatedAt),
+		})
+	}
+	return resp, nil
+}
+
+func queryLogAnalyticsInput(req *dto.QueryLogAnalyticsRequest) (querylogapp.AnalyticsInput, error) {
+	since, err := timeformat.ParseAPIDatetime(req.StartTime)
+	if err != nil {
+		return querylogapp.AnalyticsInput{}, jsonrpc.NewBusinessErrorWithMessage(jsonrpc.ErrCodeInvalidParams, fmt.Sprintf("invalid start_time: %v", err), nil)
+	}
+	until, err := timeformat.ParseAPIDatetime(req.EndTime)
+	if err != nil {
+		return querylogapp.AnalyticsInput{}, jsonrpc.NewBusinessErrorWithMessage(jsonrpc.ErrCodeInvalidParams, fmt.Sprintf("invalid end_time: %v", err), nil)
+	}
+	return querylogapp.AnalyticsInput{
+		OrganizationCode:  req.DataIsolation.ResolveOrganizationCode(),
+		KnowledgeBaseCode: req.KnowledgeBaseCode,
+		Since:             since,
+		Until:             until,
+		Limit:             req.Limit,
+	}, nil
+}
+
+func newQueryStatListResponse(stats []querylogapp.QueryStat) *dto.QueryStatListResponse {
+	resp := &dto.QueryStatListResponse{List: make([]*dto.QueryStatResponse, 0, len(stats))}
+	for _, stat := range stats {
+		resp.List = append(resp.List, &dto.QueryStatResponse{
+			QueryHash:       stat.QueryHash,
+			Query:           stat.Query,
+			SearchCount:     stat.SearchCount,
+			ZeroResultCount: stat.ZeroResultCount,
+			AvgLatencyMS:    stat.AvgLatencyMS,
+			LastSearchedAt:  timeformat.FormatAPIDatetime(stat.LastSearchedAt),
+		})
+	}
+	return resp
+}
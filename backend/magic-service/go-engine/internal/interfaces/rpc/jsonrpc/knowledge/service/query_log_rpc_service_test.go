@@ -0,0 +1,159 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	querylogapp "magic/internal/application/knowledge/querylog"
+	"magic/internal/infrastructure/logging"
+	"magic/internal/interfaces/rpc/jsonrpc/knowledge/dto"
+	knowledgesvc "magic/internal/interfaces/rpc/jsonrpc/knowledge/service"
+	jsonrpc "magic/internal/pkg/jsonrpc"
+)
+
+type mockQueryLogAnalyticsAppService struct {
+	stats     []querylogapp.QueryStat
+	slow      []querylogapp.SlowQuery
+	usage     []querylogapp.DocumentUsage
+	unused    []querylogapp.UnusedDocument
+	err       error
+	lastInput querylogapp.AnalyticsInput
+}
+
+func (m *mockQueryLogAnalyticsAppService) TopQueries(
+	_ context.Context,
+	input querylogapp.AnalyticsInput,
+) ([]querylogapp.QueryStat, error) {
+	m.lastInput = input
+	return m.stats, m.err
+}
+
+func (m *mockQueryLogAnalyticsAppService) ZeroResultQueries(
+	_ context.Context,
+	input querylogapp.AnalyticsInput,
+) ([]querylogapp.QueryStat, error) {
+	m.lastInput = input
+	return m.stats, m.err
+}
+
+func (m *mockQueryLogAnalyticsAppService) SlowestQueries(
+	_ context.Context,
+	input querylogapp.AnalyticsInput,
+) ([]querylogapp.SlowQuery, error) {
+	m.lastInput = input
+	return m.slow, m.err
+}
+
+func (m *mockQueryLogAnalyticsAppService) MostRetrievedDocuments(
+	_ context.Context,
+	input querylogapp.AnalyticsInput,
+) ([]querylogapp.DocumentUsage, error) {
+	m.lastInput = input
+	return m.usage, m.err
+}
+
+func (m *mockQueryLogAnalyticsAppService) NeverRetrievedDocuments(
+	_ context.Context,
+	input querylogapp.AnalyticsInput,
+) ([]querylogapp.UnusedDocument, error) {
+	m.lastInput = input
+	return m.unused, m.err
+}
+
+func TestQueryLogTopQueriesRPCMapsInputAndResponse(t *testing.T) {
+	t.Parallel()
+
+	lastSearchedAt := time.Date(2026, 6, 8, 10, 30, 0, 0, time.Local)
+	appSvc := &mockQueryLogAnalyticsAppService{stats: []querylogapp.QueryStat{{
+		QueryHash:      "hash",
+		Query:          "reset password",
+		SearchCount:    12,
+		AvgLatencyMS:   35.5,
+		LastSearchedAt: lastSearchedAt,
+	}}}
+	handler := knowledgesvc.NewQueryLogRPCServiceWithDependencies(appSvc, logging.New())
+
+	wrapped := jsonrpc.WrapTyped(handler.TopQueriesRPC)
+	raw, err := wrapped(context.Background(), "svc.knowledge.queryLog.topQueries", json.RawMessage(`{
+		"data_isolation": {"organization_code": "ORG1", "user_id": "u1"},
+		"knowledge_base_code": "KB1",
+		"start_time": "2026-06-01 00:00:00",
+		"limit": 5
+	}`))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	resp, ok := raw.(*dto.QueryStatListResponse)
+	if !ok || len(resp.List) != 1 || resp.List[0].SearchCount != 12 || resp.List[0].LastSearchedAt != "2026-06-08 10:30:00" {
+		t.Fatalf("unexpected response: %#v", raw)
+	}
+	input := appSvc.lastInput
+	if input.OrganizationCode != "ORG1" || input.KnowledgeBaseCode != "KB1" || input.Limit != 5 {
+		t.Fatalf("unexpected analytics input: %#v", input)
+	}
+	if input.Since.Format(time.DateTime) != "2026-06-01 00:00:00" || !input.Until.IsZero() {
+		t.Fatalf("unexpected analytics window: %v - %v", input.Since, input.Until)
+	}
+}
+
+func TestQueryLogNeverRetrievedDocumentsRPCReturnsEmptyList(t *testing.T) {
+	t.Parallel()
+
+	handler := knowledgesvc.NewQueryLogRPCServiceWithDependencies(&mockQueryLogAnalyticsAppService{}, logging.New())
+
+	wrapped := jsonrpc.WrapTyped(handler.NeverRetrievedDocumentsRPC)
+	raw, err := wrapped(context.Background(), "svc.knowledge.queryLog.neverRetrievedDocuments", json.RawMessage(`{
+		"data_isolation": {"organization_code": "ORG1"},
+		"knowledge_base_code": "KB1"
+	}`))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	resp, ok := raw.(*dto.UnusedDocumentListResponse)
+	if !ok || resp.List == nil || len(resp.List) != 0 {
+		t.Fatalf("expected empty non-nil list, got %#v", raw)
+	}
+}
+
+func TestQueryLogSlowestQueriesRPCRejectsInvalidTime(t *testing.T) {
+	t.Parallel()
+
+	appSvc := &mockQueryLogAnalyticsAppService{}
+	handler := knowledgesvc.NewQueryLogRPCServiceWithDependencies(appSvc, logging.New())
+
+	wrapped := jsonrpc.WrapTyped(handler.SlowestQueriesRPC)
+	_, err := wrapped(context.Background(), "svc.knowledge.queryLog.slowestQueries", json.RawMessage(`{
+		"data_isolation": {"organization_code": "ORG1"},
+		"knowledge_base_code": "KB1",
+		"end_time": "yesterday"
+	}`))
+	var bizErr *jsonrpc.BusinessError
+	if !errors.As(err, &bizErr) || bizErr.Code != jsonrpc.ErrCodeInvalidParams {
+		t.Fatalf("expected invalid params business error, got %v", err)
+	}
+	if appSvc.lastInput.KnowledgeBaseCode != "" {
+		t.Fatalf("expected app service not to be called, got %#v", appSvc.lastInput)
+	}
+}
+
+func TestQueryLogMostRetrievedDocumentsRPCMapsBusinessError(t *testing.T) {
+	t.Parallel()
+
+	appSvc := &mockQueryLogAnalyticsAppService{err: querylogapp.ErrAnalyticsWindowTooLarge}
+	handler := knowledgesvc.NewQueryLogRPCServiceWithDependencies(appSvc, logging.New())
+
+	wrapped := jsonrpc.WrapTyped(handler.MostRetrievedDocumentsRPC)
+	_, err := wrapped(context.Background(), "svc.knowledge.queryLog.mostRetrievedDocuments", json.RawMessage(`{
+		"data_isolation": {"organization_code": "ORG1"},
+		"knowledge_base_code": "KB1",
+		"start_time": "2026-01-01 00:00:00",
+		"end_time": "2026-06-01 00:00:00"
+	}`))
+	var bizErr *jsonrpc.BusinessError
+	if !errors.As(err, &bizErr) || bizErr.Code != jsonrpc.ErrCodeInvalidParams {
+		t.Fatalf("expected invalid params business error, got %v", err)
+	}
+}
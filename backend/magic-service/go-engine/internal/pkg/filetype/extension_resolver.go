@@ -59,6 +59,15 @@ func ExtractExtension(rawPathOrURL string) string {
 	return NormalizeExtension(filepath.Ext(cleaned))
 }
 
+// ExtensionFromContentType 从 Content-Type 推导扩展名，无法识别时返回空字符串。
+func ExtensionFromContentType(contentType string) string {
+	ext, err := extensionFromMIME(contentType)
+	if err != nil {
+		return ""
+	}
+	return ext
+}
+
 // ResolveByPHPCompatibleStrategy 以接近 PHP FileType::getType 的优先级解析扩展名。
 func ResolveByPHPCompatibleStrategy(ctx context.Context, rawPathOrURL string, fetcher fileContentFetcher) (string, error) {
 	target := strings.TrimSpace(rawPathOrURL)
@@ -220,6 +229,10 @@ func mimeExtensionOverride(contentType string) string {
 	switch contentType {
 	case "application/json":
 		return "json"
+	case "application/epub+zip":
+		return "epub"
+	case "application/mbox":
+		return "mbox"
 	case "application/msword":
 		return "doc"
 	case "application/pdf":
@@ -232,14 +245,30 @@ func mimeExtensionOverride(contentType string) string {
 		return "bmp"
 	case "application/vnd.ms-excel":
 		return "xls"
+	case "application/vnd.ms-outlook":
+		return "msg"
+	case "application/x-ipynb+json":
+		return "ipynb"
+	case "application/x-subrip", "text/srt":
+		return "srt"
 	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
 		return "xlsx"
 	case "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
 		return "docx"
+	case "application/vnd.oasis.opendocument.text":
+		return "odt"
+	case "application/vnd.oasis.opendocument.spreadsheet":
+		return "ods"
+	case "application/vnd.oasis.opendocument.presentation":
+		return "odp"
+	case "application/rtf", "text/rtf":
+		return "rtf"
 	case "application/xml":
 		return "xml"
 	case "application/zip":
 		return "zip"
+	case "message/rfc822":
+		return "eml"
 	case "text/csv":
 		return "csv"
 	case "text/html":
@@ -248,6 +277,8 @@ func mimeExtensionOverride(contentType string) string {
 		return "md"
 	case "text/plain":
 		return "txt"
+	case "text/vtt":
+		return "vtt"
 	case "text/xml":
 		return "xml"
 	default:
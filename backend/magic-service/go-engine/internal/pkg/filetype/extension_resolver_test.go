@@ -64,6 +64,22 @@ func TestExtractExtension(t *testing.T) {
 	}
 }
 
+func TestExtensionFromContentType(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		"application/x-ipynb+json":  "ipynb",
+		"application/x-subrip":      "srt",
+		"text/vtt; charset=utf-8":   "vtt",
+		"message/rfc822":            "eml",
+		"application/x-unknown-bin": "",
+	}
+	for input, want := range cases {
+		if got := filetype.ExtensionFromContentType(input); got != want {
+			t.Fatalf("ExtensionFromContentType(%q)=%q want %q", input, got, want)
+		}
+	}
+}
+
 func TestResolveByPHPCompatibleStrategy_FromLocalFile(t *testing.T) {
 	t.Parallel()
 	dir := t.TempDir()
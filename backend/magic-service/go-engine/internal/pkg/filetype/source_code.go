@@ -0,0 +1,37 @@
+package filetype
+
+import "slices"
+
+// SourceCodeExtensions 返回按源代码解析的扩展名。
+func SourceCodeExtensions() []string {
+	return []string{
+		"go",
+		"py",
+		"js",
+		"jsx",
+		"mjs",
+		"cjs",
+		"ts",
+		"tsx",
+		"java",
+		"kt",
+		"kts",
+		"scala",
+		"swift",
+		"rs",
+		"c",
+		"h",
+		"cc",
+		"cpp",
+		"cxx",
+		"hpp",
+		"hh",
+		"cs",
+		"php",
+	}
+}
+
+// IsSourceCodeExtension 判断扩展名是否按源代码解析。
+func IsSourceCodeExtension(extension string) bool {
+	return slices.Contains(SourceCodeExtensions(), NormalizeExtension(extension))
+}
@@ -7,11 +7,15 @@ import (
 
 	documentapp "magic/internal/application/knowledge/document/service"
 	embeddingapp "magic/internal/application/knowledge/embedding/service"
+	evalapp "magic/internal/application/knowledge/evaluation"
+	feedbackapp "magic/internal/application/knowledge/feedback"
 	fragmentapp "magic/internal/application/knowledge/fragment/service"
 	knowledgebaseapp "magic/internal/application/knowledge/knowledgebase/service"
+	querylogapp "magic/internal/application/knowledge/querylog"
 	rebuildapp "magic/internal/application/knowledge/rebuild"
 	documentdomain "magic/internal/domain/knowledge/document/service"
 	embeddingdomain "magic/internal/domain/knowledge/embedding"
+	evalentity "magic/internal/domain/knowledge/evaluation/entity"
 	fragmodel "magic/internal/domain/knowledge/fragment/model"
 	kbentity "magic/internal/domain/knowledge/knowledgebase/entity"
 	"magic/internal/domain/knowledge/shared"
@@ -236,6 +240,23 @@ func validationErrorList() []error {
 		kbentity.ErrDigitalEmployeeSourceTypeRequired,
 		kbentity.ErrAmbiguousFlowSourceType,
 		kbentity.ErrManualDocumentCreateNotAllowed,
+		evalapp.ErrEvaluationScopeRequired,
+		evalentity.ErrInvalidSetCode,
+		evalentity.ErrEmptyGoldenQuery,
+		evalentity.ErrGoldenQueryWithoutExpectation,
+		evalentity.ErrInvalidQueryKey,
+		evalentity.ErrDuplicateQueryKey,
+		evalentity.ErrTooManyGoldenQueries,
+		evalentity.ErrEmptyQuerySet,
+		evalentity.ErrRunScopeMismatch,
+		querylogapp.ErrAnalyticsScopeRequired,
+		querylogapp.ErrInvalidAnalyticsWindow,
+		querylogapp.ErrAnalyticsWindowTooLarge,
+		feedbackapp.ErrFeedbackTargetMismatch,
+		feedbackapp.ErrInvalidSignal,
+		feedbackapp.ErrRequestIDRequired,
+		feedbackapp.ErrRequestIDTooLong,
+		feedbackapp.ErrTargetRequired,
 	}
 }
 
@@ -254,5 +275,6 @@ func isFragmentNotFoundError(err error) bool {
 func isGenericNotFoundError(err error) bool {
 	return errors.Is(err, shared.ErrNotFound) ||
 		errors.Is(err, knowledgebaseapp.ErrRepairSourceBindingDocumentNotMapped) ||
-		errors.Is(err, knowledgebaseapp.ErrSuperMagicAgentNotFound)
+		errors.Is(err, knowledgebaseapp.ErrSuperMagicAgentNotFound) ||
+		errors.Is(err, evalentity.ErrRunNotFound)
 }
@@ -7,9 +7,12 @@ import (
 
 	documentapp "magic/internal/application/knowledge/document/service"
 	embeddingapp "magic/internal/application/knowledge/embedding/service"
+	feedbackapp "magic/internal/application/knowledge/feedback"
 	knowledgebaseapp "magic/internal/application/knowledge/knowledgebase/service"
+	querylogapp "magic/internal/application/knowledge/querylog"
 	documentdomain "magic/internal/domain/knowledge/document/service"
 	embeddingdomain "magic/internal/domain/knowledge/embedding"
+	evalentity "magic/internal/domain/knowledge/evaluation/entity"
 	fragmodel "magic/internal/domain/knowledge/fragment/model"
 	kbentity "magic/internal/domain/knowledge/knowledgebase/entity"
 	"magic/internal/domain/knowledge/shared"
@@ -291,6 +294,24 @@ func knowledgeBaseValidationErrorCases() []businessErrorCase {
 			wantCode:      jsonrpc.ErrCodeInvalidParams,
 			wantUseRawMsg: true,
 		},
+		{
+			name:          "duplicate golden query key -> invalid params",
+			err:           fmt.Errorf("golden query #2: %w", evalentity.ErrDuplicateQueryKey),
+			wantCode:      jsonrpc.ErrCodeInvalidParams,
+			wantUseRawMsg: true,
+		},
+		{
+			name:          "query log analytics window too large -> invalid params",
+			err:           fmt.Errorf("resolve analytics window: %w", querylogapp.ErrAnalyticsWindowTooLarge),
+			wantCode:      jsonrpc.ErrCodeInvalidParams,
+			wantUseRawMsg: true,
+		},
+		{
+			name:          "invalid feedback signal -> invalid params",
+			err:           fmt.Errorf("validate feedback: %w", feedbackapp.ErrInvalidSignal),
+			wantCode:      jsonrpc.ErrCodeInvalidParams,
+			wantUseRawMsg: true,
+		},
 	}
 }
 
@@ -349,6 +370,12 @@ func notFoundErrorCases() []businessErrorCase {
 			wantCode:      jsonrpc.ErrCodeNotFound,
 			wantUseRawMsg: true,
 		},
+		{
+			name:          "evaluation run not found -> knowledge validate failed",
+			err:           fmt.Errorf("load baseline evaluation run: %w", evalentity.ErrRunNotFound),
+			wantCode:      jsonrpc.ErrCodeNotFound,
+			wantUseRawMsg: true,
+		},
 	}
 }
 
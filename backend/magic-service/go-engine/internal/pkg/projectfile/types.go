@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"magic/internal/pkg/filetype"
 )
 
 // ErrFileUnavailable 表示来源绑定指向的项目文件当前不可用。
@@ -31,6 +33,18 @@ const (
 	docTypeHTM      = 11
 	docTypePPT      = 12
 	docTypeJSON     = 13
+	docTypeODT      = 14
+	docTypeODS      = 15
+	docTypeODP      = 16
+	docTypeEPUB     = 17
+	docTypeRTF      = 18
+	docTypeEML      = 19
+	docTypeMBOX     = 20
+	docTypeMSG      = 21
+	docTypeCode     = 22
+	docTypeIPYNB    = 23
+	docTypeSRT      = 24
+	docTypeVTT      = 25
 
 	// ResolveStatusActive 表示项目文件当前可正常解析。
 	ResolveStatusActive = "active"
@@ -205,7 +219,32 @@ func ResolveDocType(extension string) int {
 		return docTypePPT
 	case "json":
 		return docTypeJSON
+	case "odt":
+		return docTypeODT
+	case "ods":
+		return docTypeODS
+	case "odp":
+		return docTypeODP
+	case "epub":
+		return docTypeEPUB
+	case "rtf":
+		return docTypeRTF
+	case "eml":
+		return docTypeEML
+	case "mbox":
+		return docTypeMBOX
+	case "msg":
+		return docTypeMSG
+	case "ipynb":
+		return docTypeIPYNB
+	case "srt":
+		return docTypeSRT
+	case "vtt":
+		return docTypeVTT
 	default:
+		if filetype.IsSourceCodeExtension(extension) {
+			return docTypeCode
+		}
 		return docTypeUnknown
 	}
 }
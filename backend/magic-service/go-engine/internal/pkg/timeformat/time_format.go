@@ -1,7 +1,10 @@
 // Package timeformat provides datetime formatting helpers for API responses.
 package timeformat
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 const apiDatetimeLayout = "2006-01-02 15:04:05"
 
@@ -9,3 +12,15 @@ const apiDatetimeLayout = "2006-01-02 15:04:05"
 func FormatAPIDatetime(t time.Time) string {
 	return t.In(time.Local).Format(apiDatetimeLayout)
 }
+
+// ParseAPIDatetime parses an API datetime in local timezone; empty input yields the zero time.
+func ParseAPIDatetime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.ParseInLocation(apiDatetimeLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse api datetime %q: %w", value, err)
+	}
+	return parsed, nil
+}
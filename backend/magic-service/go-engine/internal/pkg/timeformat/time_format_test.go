@@ -0,0 +1,32 @@
+package timeformat_test
+
+import (
+	"testing"
+	"time"
+
+	"magic/internal/pkg/timeformat"
+)
+
+func TestParseAPIDatetimeRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	want := time.Date(2026, 6, 1, 8, 30, 0, 0, time.Local)
+	got, err := timeformat.ParseAPIDatetime(timeformat.FormatAPIDatetime(want))
+	if err != nil {
+		t.Fatalf("ParseAPIDatetime() error = %v", err)
+	}
+	if !got.Equal(want) {
+		t.Fatalf("ParseAPIDatetime() = %s, want %s", got, want)
+	}
+}
+
+func TestParseAPIDatetimeHandlesEmptyAndInvalid(t *testing.T) {
+	t.Parallel()
+
+	if got, err := timeformat.ParseAPIDatetime(""); err != nil || !got.IsZero() {
+		t.Fatalf("expected zero time for empty input, got %s, %v", got, err)
+	}
+	if _, err := timeformat.ParseAPIDatetime("2026/06/01"); err == nil {
+		t.Fatal("expected error for invalid datetime")
+	}
+}
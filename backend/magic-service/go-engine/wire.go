@@ -7,6 +7,7 @@ import (
 
 	documentapp "magic/internal/application/knowledge/document/service"
 	fragmentapp "magic/internal/application/knowledge/fragment/service"
+	knowledgebaseapp "magic/internal/application/knowledge/knowledgebase/service"
 	"magic/internal/config/autoload"
 	diapp "magic/internal/di/app"
 	diinfra "magic/internal/di/infra"
@@ -55,11 +56,15 @@ func InitializeApplication() (*httpserver.Server, func(), error) {
 		rpchandler.NewFragmentRPCService,
 		rpchandler.NewDocumentRPCService,
 		rpchandler.NewEmbeddingRPCService,
+		rpchandler.NewEvaluationRPCService,
+		rpchandler.NewQueryLogRPCService,
+		rpchandler.NewFeedbackRPCService,
 		opshandler.ProvideOpsRPCService,
 
 		handlers.NewDebugHandler,
 		handlers.NewMagicFSFileHandler,
 		handlers.NewKnowledgeSourceFileHandler,
+		handlers.NewKnowledgeWebhookHandler,
 
 		// 接口绑定
 		wire.Bind(new(httpserver.InfraServices), new(*health.CheckService)),
@@ -70,6 +75,7 @@ func InitializeApplication() (*httpserver.Server, func(), error) {
 		wire.Bind(new(opshandler.OfficialOrganizationMemberChecker), new(*ipcclient.PHPKnowledgeBasePermissionRPCClient)),
 		wire.Bind(new(rpchandler.KnowledgeBaseRebuildStateReader), new(*redisrebuild.Coordinator)),
 		wire.Bind(new(handlers.KnowledgeSourceFileService), new(*documentapp.KnowledgeSourceFileLinkService)),
+		wire.Bind(new(handlers.KnowledgeWebhookService), new(*knowledgebaseapp.WebhookSourceService)),
 	)
 	return nil, nil, nil
 }
@@ -56,12 +56,35 @@ func InitializeApplication() (*httpapi.Server, func(), error) {
 		cleanup()
 		return nil, nil, err
 	}
-	vectorDBManagementRepository := infra.ProvideVectorDBManagementRepository(qdrantClient)
-	fragmentVectorDBDataRepository := infra.ProvideFragmentVectorDBDataRepository(qdrantClient)
+	pgvectorClient, cleanup4, err := infra.ProvidePGVectorClient(config, sugaredLogger)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	embeddedClient, cleanup5, err := infra.ProvideEmbeddedVectorClient(config, sugaredLogger)
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	vectorDBClients := infra.ProvideVectorDBClients(qdrantClient, pgvectorClient, embeddedClient)
+	vectorDBManagementRepository := infra.ProvideVectorDBManagementRepository(vectorDBClients)
+	fragmentVectorDBDataRepository := infra.ProvideFragmentVectorDBDataRepository(vectorDBClients)
 	baseRepository := infra.ProvideKnowledgeBaseRepository(sqlcClient, client, sugaredLogger)
 	fragmentDomainConfig := knowledge.ProvideFragmentDomainConfig(embeddingDefaultModel, config)
 	segmenterProvider := knowledge.ProvideFragmentRetrievalSegmenterProvider()
-	fragmentDomainInfra := knowledge.ProvideFragmentDomainInfra(vectorDBManagementRepository, fragmentVectorDBDataRepository, baseRepository, fragmentDomainConfig, segmenterProvider, sugaredLogger)
+	rerankClient := infra.ProvideRerankClient(config, accessTokenProvider)
+	chatCompletionClient := infra.ProvideChatCompletionClient(config, accessTokenProvider)
+	multiVectorClient := infra.ProvideMultiVectorClient(config, accessTokenProvider)
+	feedbackRepository := infra.ProvideFeedbackRepository(sqlcClient, sugaredLogger)
+	feedbackAggregateReader := knowledge.ProvideFeedbackAggregateReader(feedbackRepository)
+	redisCache := infra.ProvideChunkContextCache(client)
+	fragmentRetrievalDeps := knowledge.ProvideFragmentRetrievalDeps(segmenterProvider, rerankClient, chatCompletionClient, multiVectorClient, feedbackAggregateReader, redisCache)
+	fragmentDomainInfra := knowledge.ProvideFragmentDomainInfra(vectorDBManagementRepository, fragmentVectorDBDataRepository, baseRepository, fragmentDomainConfig, fragmentRetrievalDeps, sugaredLogger)
 	fragmentDomainService := knowledge.ProvideFragmentDomainService(knowledgeBaseFragmentRepository, domainService, fragmentDomainInfra)
 	dimensionResolver := infra.ProvideEmbeddingDimensionResolver(config, embeddingService)
 	qdrantConfig := app.ProvideQdrantConfig(config)
@@ -75,43 +98,50 @@ func InitializeApplication() (*httpapi.Server, func(), error) {
 	phpocrConfigRPCClient := infra.ProvideOCRConfigProvider(server, sugaredLogger)
 	ocrResultCacheRepository := infra.ProvideOCRResultCacheRepository(sqlcClient, sugaredLogger)
 	volcengineOCRClient := infra.ProvideVolcengineOCRClient(config, client, phpocrConfigRPCClient, phpocrConfigRPCClient, ocrResultCacheRepository, sugaredLogger)
+	pdFiumPageRenderer := infra.ProvidePDFiumPageRenderer()
+	localOCRClient := infra.ProvideLocalOCRClient(config, client, phpocrConfigRPCClient, phpocrConfigRPCClient, ocrResultCacheRepository, pdFiumPageRenderer, sugaredLogger)
+	providerRoutingClient := infra.ProvideOCRClient(phpocrConfigRPCClient, volcengineOCRClient, localOCRClient)
 	visionConfig := infra.ProvideKnowledgeVisualUnderstandingConfig(config)
 	openAICompatibleVisionTextClient := infra.ProvideOpenAICompatibleVisionTextClient(visionConfig, sugaredLogger)
-	pdFiumPageRenderer := infra.ProvidePDFiumPageRenderer()
 	modelVisualTextExtractor := infra.ProvideModelVisualTextExtractor(openAICompatibleVisionTextClient, pdFiumPageRenderer, visionConfig, config, sugaredLogger)
-	visualTextExtractor := infra.ProvideConfigurableVisualTextExtractor(phpaiAbilityConfigRPCClient, phpModelCallConfigRPCClient, volcengineOCRClient, modelVisualTextExtractor, sugaredLogger)
+	visualTextExtractor := infra.ProvideConfigurableVisualTextExtractor(phpaiAbilityConfigRPCClient, phpModelCallConfigRPCClient, providerRoutingClient, modelVisualTextExtractor, sugaredLogger)
 	v := infra.ProvideDocumentParsers(config, phpFileRPCClient, visualTextExtractor)
 	parseService := infra.ProvideDocumentParseService(config, phpFileRPCClient, v, sugaredLogger)
 	phpKnowledgeBasePermissionRPCClient := infra.ProvideKnowledgeBasePermissionPort(server, sugaredLogger)
 	phpSuperMagicAgentRPCClient := infra.ProvideSuperMagicAgentPort(server, sugaredLogger)
 	phpProjectFileRPCClient := infra.ProvideProjectFilePort(server, sugaredLogger)
 	phpThirdPlatformDocumentRPCClient := infra.ProvideThirdPlatformDocumentPort(server, client, sugaredLogger)
-	basePortDeps := knowledge.ProvideKnowledgeBasePortDeps(phpKnowledgeBasePermissionRPCClient, phpSuperMagicAgentRPCClient, phpProjectFileRPCClient, phpThirdPlatformDocumentRPCClient)
+	gitrepoClient := infra.ProvideGitRepositoryClient()
+	objectstorageClient := infra.ProvideObjectStorageClient(config)
+	basePortDeps := knowledge.ProvideKnowledgeBasePortDeps(phpKnowledgeBasePermissionRPCClient, phpSuperMagicAgentRPCClient, phpProjectFileRPCClient, phpThirdPlatformDocumentRPCClient, gitrepoClient, objectstorageClient)
 	registry := knowledge.ProvideThirdPlatformProviderRegistry(phpThirdPlatformDocumentRPCClient, sugaredLogger)
 	knowledgebasebindingrepoRepository := infra.ProvideKnowledgeBaseBindingRepository(sqlcClient)
 	supermagicagentrepoRepository := infra.ProvideSuperMagicAgentRepository(sqlcClient)
 	baseBindingDeps := knowledge.ProvideKnowledgeBaseBindingDeps(knowledgebasebindingrepoRepository, supermagicagentrepoRepository)
 	manualFragmentCoordinator := infra.ProvideManualFragmentCoordinator(sqlcClient, sugaredLogger)
 	tokenizerService := infra.ProvideTokenizer()
-	fragmentAppRuntimeDeps := knowledge.ProvideFragmentAppRuntimeDeps(manualFragmentCoordinator, tokenizerService, embeddingDefaultModel)
+	querylogrepositoryRepository := infra.ProvideQueryLogRepository(sqlcClient, sugaredLogger)
+	recorder := knowledge.ProvideQueryLogRecorder(querylogrepositoryRepository, config, sugaredLogger)
+	fragmentAppRuntimeDeps := knowledge.ProvideFragmentAppRuntimeDeps(manualFragmentCoordinator, tokenizerService, embeddingDefaultModel, recorder)
 	userRepository := infra.ProvideContactUserRepository(sqlcClient)
 	userDomainService := infra.ProvideContactUserDomainService(userRepository)
 	appDeps := knowledge.ProvideFragmentAppDeps(parseService, basePortDeps, registry, baseBindingDeps, fragmentAppRuntimeDeps, userDomainService)
 	fragmentAppService := knowledge.ProvideFragmentAppService(fragmentDomainService, knowledgebaseDomainService, documentDomainService, appDeps, client, sugaredLogger)
-	rabbitMQBroker, cleanup4, err := infra.ProvideDocumentSyncRabbitMQBroker(config, sugaredLogger)
+	rabbitMQBroker, cleanup6, err := infra.ProvideDocumentSyncRabbitMQBroker(config, sugaredLogger)
 	if err != nil {
+		cleanup5()
+		cleanup4()
 		cleanup3()
 		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
 	runtime := knowledge.ProvideDocumentSyncRuntime(rabbitMQBroker, config, sugaredLogger, server, client)
-	serverBackgroundDeps := httpapi.ProvideServerBackgroundDeps(embeddingCacheCleanupService, fragmentAppService, runtime)
-	checkService := infra.ProvideHealthCheckService(sqlcClient, client, repository, qdrantClient, rabbitMQBroker)
+	checkService := infra.ProvideHealthCheckService(sqlcClient, client, repository, vectorDBClients, rabbitMQBroker)
 	metrics := infra.ProvideMetrics()
 	docappAppDeps := knowledge.ProvideDocumentAppDeps(parseService, basePortDeps, registry, tokenizerService, userDomainService, sqlcClient)
 	redisProgressStore := knowledge.ProvideKnowledgeRevectorizeProgressStore(client)
-	documentAppRuntimeDeps := knowledge.ProvideDocumentAppRuntimeDeps(config, phpFileRPCClient, runtime, redisProgressStore, client)
+	documentAppRuntimeDeps := knowledge.ProvideDocumentAppRuntimeDeps(config, phpFileRPCClient, runtime, redisProgressStore, client, gitrepoClient, objectstorageClient)
 	documentAppService := knowledge.ProvideDocumentAppService(documentDomainService, knowledgebaseDomainService, fragmentDomainService, docappAppDeps, sugaredLogger, documentAppRuntimeDeps)
 	baseDocumentFlowDeps := knowledge.ProvideKnowledgeBaseDocumentFlowDeps(documentAppService, documentDomainService, fragmentDomainService, parseService)
 	sourcebindingrepoRepository := infra.ProvideSourceBindingRepository(sqlcClient, client, config)
@@ -124,6 +154,48 @@ func InitializeApplication() (*httpapi.Server, func(), error) {
 	baseCoordinatorDeps := knowledge.ProvideKnowledgeBaseCoordinatorDeps(knowledgeBaseDestroyCoordinator, knowledgeBaseWriteCoordinator)
 	baseDeps := knowledge.ProvideKnowledgeBaseAppDeps(sourcebindingrepoRepository, baseDomainDeps, basePortDeps, baseCoordinatorDeps, baseBindingDeps)
 	knowledgeBaseAppService := knowledge.ProvideKnowledgeBaseAppService(knowledgebaseDomainService, baseDocumentFlowDeps, baseDeps, client, sugaredLogger, embeddingDefaultModel)
+	websiteRecrawlService, err := knowledge.ProvideWebsiteRecrawlService(knowledgeBaseAppService, sourcebindingrepoRepository, singlePodJobRunner, sugaredLogger)
+	if err != nil {
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	gitSourceSyncService, err := knowledge.ProvideGitSourceSyncService(knowledgeBaseAppService, sourcebindingrepoRepository, singlePodJobRunner, sugaredLogger)
+	if err != nil {
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	objectStorageSyncService, err := knowledge.ProvideObjectStorageSyncService(knowledgeBaseAppService, sourcebindingrepoRepository, singlePodJobRunner, sugaredLogger)
+	if err != nil {
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	webhookcallbackClient := infra.ProvideWebhookCallbackClient()
+	webhookSourceService, err := knowledge.ProvideWebhookSourceService(knowledgeBaseAppService, documentAppService, sourcebindingrepoRepository, singlePodJobRunner, webhookcallbackClient, sugaredLogger)
+	if err != nil {
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	serverBackgroundDeps := httpapi.ProvideServerBackgroundDeps(embeddingCacheCleanupService, websiteRecrawlService, gitSourceSyncService, objectStorageSyncService, fragmentAppService, runtime)
 	knowledgeRevectorizeAppService := knowledge.ProvideKnowledgeRevectorizeAppService(knowledgeBaseAppService, documentAppService, redisProgressStore, sugaredLogger)
 	mySQLStore := infra.ProvideKnowledgeRebuildStore(sqlcClient, client, sugaredLogger)
 	coordinator := infra.ProvideVectorRebuildCoordinator(client, sugaredLogger)
@@ -131,6 +203,8 @@ func InitializeApplication() (*httpapi.Server, func(), error) {
 	runnerDeps := rebuild.ProvideKnowledgeRebuildRunnerDeps(mySQLStore, coordinator, vectorDBManagementRepository, appDocumentResyncer, dimensionResolver)
 	runner, err := rebuild.ProvideKnowledgeRebuildRunner(runnerDeps, config, sugaredLogger)
 	if err != nil {
+		cleanup6()
+		cleanup5()
 		cleanup4()
 		cleanup3()
 		cleanup2()
@@ -146,7 +220,14 @@ func InitializeApplication() (*httpapi.Server, func(), error) {
 	embeddingAppService := knowledge.ProvideEmbeddingAppService(domainService, sugaredLogger, embeddingDefaultModel)
 	embeddingRPCService := service.NewEmbeddingRPCService(embeddingAppService, sugaredLogger)
 	opsRPCService := service2.ProvideOpsRPCService(config, client, redisLockManager, phpKnowledgeBasePermissionRPCClient, sugaredLogger)
-	rpcHandlers := httpapi.ProvideRPCHandlers(knowledgeBaseRPCService, fragmentRPCService, documentRPCService, embeddingRPCService, opsRPCService)
+	evaluationRepository := infra.ProvideEvaluationRepository(sqlcClient, sugaredLogger)
+	evaluationService := knowledge.ProvideEvaluationAppService(evaluationRepository, fragmentAppService, sugaredLogger)
+	evaluationRPCService := service.NewEvaluationRPCService(evaluationService, sugaredLogger)
+	analyticsService := knowledge.ProvideQueryLogAnalyticsService(querylogrepositoryRepository, sugaredLogger)
+	queryLogRPCService := service.NewQueryLogRPCService(analyticsService, sugaredLogger)
+	feedbackService := knowledge.ProvideFeedbackAppService(feedbackRepository, knowledgeBaseFragmentRepository, sugaredLogger)
+	feedbackRPCService := service.NewFeedbackRPCService(feedbackService, sugaredLogger)
+	rpcHandlers := httpapi.ProvideRPCHandlers(knowledgeBaseRPCService, fragmentRPCService, documentRPCService, embeddingRPCService, opsRPCService, evaluationRPCService, queryLogRPCService, feedbackRPCService)
 	debugHandler := handlers.NewDebugHandler(embeddingAppService)
 	magicfsRepository := infra.ProvideMagicFSRepository(sqlcClient)
 	phpMagicFSFileRPCClient := infra.ProvideMagicFSFilePort(server, sugaredLogger)
@@ -155,10 +236,13 @@ func InitializeApplication() (*httpapi.Server, func(), error) {
 	phpWebAuthRPCClient := infra.ProvideWebAuthProvider(server, sugaredLogger)
 	knowledgeSourceFileLinkService := knowledge.ProvideKnowledgeSourceFileLinkService(phpWebAuthRPCClient, documentDomainService, knowledgebaseDomainService, phpFileRPCClient, basePortDeps, registry)
 	knowledgeSourceFileHandler := handlers.NewKnowledgeSourceFileHandler(knowledgeSourceFileLinkService)
-	serverRuntimeDeps := httpapi.ProvideServerRuntimeDeps(server, rpcHandlers, debugHandler, magicFSFileHandler, knowledgeSourceFileHandler)
+	knowledgeWebhookHandler := handlers.NewKnowledgeWebhookHandler(webhookSourceService)
+	serverRuntimeDeps := httpapi.ProvideServerRuntimeDeps(server, rpcHandlers, debugHandler, magicFSFileHandler, knowledgeSourceFileHandler, knowledgeWebhookHandler)
 	serverDependencies := httpapi.ProvideServerDependencies(serverConfig, serverBackgroundDeps, checkService, sugaredLogger, metrics, serverRuntimeDeps)
 	httpapiServer := httpapi.NewServerWithDependencies(serverDependencies)
 	return httpapiServer, func() {
+		cleanup6()
+		cleanup5()
 		cleanup4()
 		cleanup3()
 		cleanup2()
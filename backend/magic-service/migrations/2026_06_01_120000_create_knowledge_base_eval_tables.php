@@ -0,0 +1,59 @@
+<?php
+
+declare(strict_types=1);
+/**
+ * Copyright (c) The Magic , Distributed under the software license
+ */
+use Hyperf\Database\Migrations\Migration;
+use Hyperf\Database\Schema\Blueprint;
+use Hyperf\Database\Schema\Schema;
+
+return new class extends Migration {
+    public function up(): void
+    {
+        if (! Schema::hasTable('knowledge_base_eval_queries')) {
+            Schema::create('knowledge_base_eval_queries', function (Blueprint $table) {
+                $table->bigIncrements('id');
+                $table->string('organization_code', 64);
+                $table->string('knowledge_base_code', 64);
+                $table->string('set_code', 64);
+                $table->string('query_key', 128);
+                $table->text('query_text');
+                $table->json('expected_document_codes');
+                $table->json('expected_fragment_ids');
+                $table->string('created_uid', 64)->default('');
+                $table->string('updated_uid', 64)->default('');
+                $table->datetimes();
+
+                $table->unique(['organization_code', 'knowledge_base_code', 'set_code', 'query_key'], 'uniq_kb_eval_query');
+            });
+        }
+
+        if (! Schema::hasTable('knowledge_base_eval_runs')) {
+            Schema::create('knowledge_base_eval_runs', function (Blueprint $table) {
+                $table->bigIncrements('id');
+                $table->string('organization_code', 64);
+                $table->string('knowledge_base_code', 64);
+                $table->string('set_code', 64);
+                $table->string('label', 255)->default('');
+                $table->unsignedInteger('top_k')->default(0);
+                $table->bigInteger('baseline_run_id')->default(0);
+                $table->unsignedInteger('query_count')->default(0);
+                $table->unsignedInteger('failed_count')->default(0);
+                $table->json('metrics');
+                $table->json('results');
+                $table->string('created_uid', 64)->default('');
+                $table->dateTime('started_at');
+                $table->dateTime('finished_at');
+
+                $table->index(['organization_code', 'knowledge_base_code', 'set_code', 'id'], 'idx_kb_eval_run_set');
+            });
+        }
+    }
+
+    public function down(): void
+    {
+        Schema::dropIfExists('knowledge_base_eval_runs');
+        Schema::dropIfExists('knowledge_base_eval_queries');
+    }
+};
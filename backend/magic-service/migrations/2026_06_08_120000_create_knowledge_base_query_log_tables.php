@@ -0,0 +1,59 @@
+<?php
+
+declare(strict_types=1);
+/**
+ * Copyright (c) The Magic , Distributed under the software license
+ */
+use Hyperf\Database\Migrations\Migration;
+use Hyperf\Database\Schema\Blueprint;
+use Hyperf\Database\Schema\Schema;
+
+return new class extends Migration {
+    public function up(): void
+    {
+        if (! Schema::hasTable('knowledge_base_query_logs')) {
+            Schema::create('knowledge_base_query_logs', function (Blueprint $table) {
+                $table->bigIncrements('id');
+                $table->string('request_id', 64)->default('');
+                $table->string('organization_code', 64);
+                $table->string('knowledge_base_code', 64);
+                $table->string('user_id', 64)->default('');
+                $table->string('source', 32)->default('');
+                $table->string('agent_code', 64)->default('');
+                $table->string('query_text', 2048)->default('');
+                $table->char('query_hash', 64);
+                $table->string('query_type', 32)->default('');
+                $table->unsignedInteger('result_count')->default(0);
+                $table->double('top_score')->default(0);
+                $table->unsignedInteger('latency_ms')->default(0);
+                $table->dateTime('created_at');
+
+                $table->index(['organization_code', 'knowledge_base_code', 'created_at'], 'idx_kb_query_logs_kb_time');
+                $table->index(['organization_code', 'knowledge_base_code', 'query_hash'], 'idx_kb_query_logs_kb_hash');
+            });
+        }
+
+        if (! Schema::hasTable('knowledge_base_query_log_hits')) {
+            Schema::create('knowledge_base_query_log_hits', function (Blueprint $table) {
+                $table->bigIncrements('id');
+                $table->bigInteger('log_id');
+                $table->string('organization_code', 64);
+                $table->string('knowledge_base_code', 64);
+                $table->string('document_code', 255)->default('');
+                $table->bigInteger('fragment_id')->default(0);
+                $table->double('score')->default(0);
+                $table->unsignedInteger('hit_rank')->default(0);
+                $table->dateTime('created_at');
+
+                $table->index(['organization_code', 'knowledge_base_code', 'created_at', 'document_code'], 'idx_kb_query_log_hits_kb_time_doc');
+                $table->index(['log_id'], 'idx_kb_query_log_hits_log');
+            });
+        }
+    }
+
+    public function down(): void
+    {
+        Schema::dropIfExists('knowledge_base_query_log_hits');
+        Schema::dropIfExists('knowledge_base_query_logs');
+    }
+};
@@ -0,0 +1,54 @@
+<?php
+
+declare(strict_types=1);
+/**
+ * Copyright (c) The Magic , Distributed under the software license
+ */
+use Hyperf\Database\Migrations\Migration;
+use Hyperf\Database\Schema\Blueprint;
+use Hyperf\Database\Schema\Schema;
+
+return new class extends Migration {
+    public function up(): void
+    {
+        if (! Schema::hasTable('knowledge_base_feedback')) {
+            Schema::create('knowledge_base_feedback', function (Blueprint $table) {
+                $table->bigIncrements('id');
+                $table->string('organization_code', 64);
+                $table->string('knowledge_base_code', 64);
+                $table->string('document_code', 255);
+                $table->bigInteger('fragment_id')->default(0);
+                $table->string('request_id', 64);
+                $table->string('user_id', 64)->default('');
+                $table->string('signal_type', 32);
+                $table->dateTime('created_at');
+
+                $table->unique(['request_id', 'user_id', 'knowledge_base_code', 'document_code', 'fragment_id', 'signal_type'], 'uk_kb_feedback_request_target');
+                $table->index(['organization_code', 'knowledge_base_code', 'created_at'], 'idx_kb_feedback_kb_time');
+            });
+        }
+
+        if (! Schema::hasTable('knowledge_base_feedback_stats')) {
+            Schema::create('knowledge_base_feedback_stats', function (Blueprint $table) {
+                $table->bigIncrements('id');
+                $table->string('organization_code', 64);
+                $table->string('knowledge_base_code', 64);
+                $table->string('document_code', 255);
+                $table->bigInteger('fragment_id')->default(0);
+                $table->bigInteger('positive_count')->default(0);
+                $table->bigInteger('negative_count')->default(0);
+                $table->bigInteger('answered_count')->default(0);
+                $table->dateTime('created_at');
+                $table->dateTime('updated_at');
+
+                $table->unique(['organization_code', 'knowledge_base_code', 'document_code', 'fragment_id'], 'uk_kb_feedback_stats_target');
+            });
+        }
+    }
+
+    public function down(): void
+    {
+        Schema::dropIfExists('knowledge_base_feedback_stats');
+        Schema::dropIfExists('knowledge_base_feedback');
+    }
+};